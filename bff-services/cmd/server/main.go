@@ -1,26 +1,87 @@
 package main
 
 import (
+	"bff-services/internal/apikeys"
+	"bff-services/internal/audit"
 	"bff-services/internal/cache"
 	"bff-services/internal/config"
+	"bff-services/internal/consent"
+	"bff-services/internal/graphql"
+	"bff-services/internal/maintenance"
+	"bff-services/internal/metrics"
 	"bff-services/internal/server"
 	"bff-services/internal/services"
 	"context"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
 	"time"
+	_ "time/tzdata" // timezone names sent by clients must resolve in minimal containers
 
+	"github.com/ductan2/microservice-app/shared/chaos"
+	"github.com/ductan2/microservice-app/shared/envconfig"
+	"github.com/ductan2/microservice-app/shared/flags"
+	"github.com/ductan2/microservice-app/shared/flags/redisstore"
+	"github.com/ductan2/microservice-app/shared/health"
+	"github.com/ductan2/microservice-app/shared/internalauth"
+	"github.com/ductan2/microservice-app/shared/lifecycle"
+	"github.com/ductan2/microservice-app/shared/logging"
+	sharedmetrics "github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/ductan2/microservice-app/shared/ratelimit"
+	ratelimitredis "github.com/ductan2/microservice-app/shared/ratelimit/redisstore"
+	"github.com/ductan2/microservice-app/shared/secrets"
+	"github.com/ductan2/microservice-app/shared/telemetry"
 	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
 )
 
+// drainDelay is how long readiness fails before the server stops accepting connections,
+// so the load balancer stops routing requests here first.
+const drainDelay = 2 * time.Second
+
 func main() {
+	// Settings such as JWT_SECRET=secret://bff-services/jwt are read from the secrets
+	// manager named by SECRETS_PROVIDER
+	secretStore, secretsErr := secrets.FromEnv()
+	if secretStore != nil {
+		envconfig.UseSecrets(secretStore)
+	}
+	// "bff-services config validate" checks the configuration of a deployment and exits
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(envconfig.Command(os.Args[2:], os.Stdout, func() (any, error) { return config.Read() }))
+	}
+	configs, err := config.Load()
+	logging.Setup(logging.ConfigFromEnv("bff-services"))
+	if secretsErr != nil {
+		logging.Fatal("invalid secrets configuration", "error", secretsErr)
+	}
+	if err != nil {
+		logging.Fatal("invalid configuration", "error", err)
+	}
+	// Components are stopped in the reverse order they are added, on SIGINT or SIGTERM
+	app := lifecycle.New(lifecycle.Config{})
+
+	// SIGHUP reloads the configuration; the log settings follow it
+	configs.OnReload(func(_, _ *config.Config) {
+		logging.Setup(logging.ConfigFromEnv("bff-services"))
+	})
+	app.Add(lifecycle.Worker("config-reload", configs.WatchSignals))
+	if secretStore != nil {
+		// A rotated secret is picked up by reloading the configuration
+		secretStore.OnRotate(func(string) {
+			if err := configs.Reload(); err != nil {
+				slog.Error("failed to reload configuration after a secret rotation", "error", err)
+			}
+		})
+		app.Add(lifecycle.Worker("secrets", secretStore.Run))
+	}
+	slog.Info("configuration loaded", "config", envconfig.Values(configs.Current()))
 	port := config.GetPort()
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	// Export traces to the OpenTelemetry collector
+	tracer := telemetry.Setup(telemetry.ConfigFromEnv("bff-services"))
+	app.Add(lifecycle.Component{Name: "tracer", Stop: tracer.Shutdown})
+	sharedmetrics.SetBuildInfo("bff-services")
 
 	// Initialize Redis client
 	redisConfig := config.GetRedisConfig()
@@ -29,41 +90,140 @@ func main() {
 		Password: redisConfig.Password,
 		DB:       0,
 	})
-	defer redisClient.Close()
+	app.Add(lifecycle.Closer("redis", redisClient))
+	sharedmetrics.RegisterPool("redis", redisPoolStats(redisClient))
 
 	// Test Redis connection
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := redisClient.Ping(ctx).Err(); err != nil {
-		log.Printf("Warning: Redis connection failed: %v", err)
+		slog.Warn("redis connection failed", "error", err)
 	} else {
-		log.Println("Redis connected successfully")
+		slog.Info("redis connected")
 	}
 
+	// Feature flags are shared with the other services through Redis
+	flagStore := redisstore.New(redisClient)
+
 	// Initialize session cache
 	sessionCache := cache.NewSessionCache(redisClient)
+	sessionConfig := config.GetSessionConfig()
+	sessionCache.SetPolicy(cache.SessionPolicy{
+		IdleTimeout:      sessionConfig.IdleTimeout,
+		AbsoluteLifetime: sessionConfig.AbsoluteLifetime,
+		ExpiryWarning:    sessionConfig.ExpiryWarning,
+	})
+	profileCache := cache.NewProfileCache(redisClient)
+	// Anonymous routes are limited per client IP; a Redis blip lets requests through
+	rateLimiter := ratelimit.New(ratelimitredis.New(redisClient), ratelimit.Config{
+		Overrides: config.GetRateLimits(),
+		FailOpen:  true,
+	})
+	guestConfig := config.GetGuestConfig()
+
+	// Calls to the services carry a service token signed with the shared keys
+	internalAuth, err := internalauth.ConfigFromEnv("bff-services")
+	if err != nil {
+		logging.Fatal("invalid internal auth configuration", "error", err)
+	}
+	if internalAuth.Development {
+		slog.Warn("INTERNAL_AUTH_KEYS is not set; service tokens are signed with the development key")
+	}
+	signer, err := internalauth.NewSigner(internalAuth)
+	if err != nil {
+		logging.Fatal("failed to create service token signer", "error", err)
+	}
 
-	userService := services.NewUserServiceClient(config.GetUserServiceURL(), nil)
-	contentService := services.NewContentServiceClient(config.GetContentServiceURL(), nil)
+	// Outside production, faults can be injected into the calls to the services
+	chaosConfig, err := chaos.ConfigFromEnv()
+	if err != nil {
+		logging.Fatal("invalid chaos configuration", "error", err)
+	}
+	injector := chaos.New(chaosConfig)
+	if injector != nil {
+		slog.Warn("fault injection is enabled", "rules", chaosConfig.Rules.String(), "headers", chaosConfig.Headers)
+	}
+	serviceClient := func(service string, timeout time.Duration) *http.Client {
+		return &http.Client{Timeout: timeout, Transport: internalauth.NewTransport(signer, service, metrics.NewTransport(service, injector.Transport(service, nil)))}
+	}
+
+	userService := services.NewUserServiceClient(config.GetUserServiceURL(), serviceClient("user-services", 10*time.Second))
+	contentService := services.NewContentServiceClient(config.GetContentServiceURL(), serviceClient("content-services", 10*time.Second))
 	contentService.SetRedisClient(redisClient)
-	lessonService := services.NewLessonServiceClient(config.GetLessonServiceURL(), nil)
-	quizAttemptService := services.NewQuizAttemptServiceClient(config.GetLessonServiceURL(), nil)
-	notificationService := services.NewNotificationServiceClient(config.GetNotificationServiceURL(), nil)
-	orderService := services.NewOrderServiceClient(config.GetOrderServiceURL(), nil)
-	paymentService := services.NewPaymentServiceClient(config.GetOrderServiceURL(), nil)
-	couponService := services.NewCouponServiceClient(config.GetOrderServiceURL(), nil)
+	lessonService := services.NewLessonServiceClient(config.GetLessonServiceURL(), serviceClient("lesson-services", 10*time.Second))
+	quizAttemptService := services.NewQuizAttemptServiceClient(config.GetLessonServiceURL(), serviceClient("lesson-services", 10*time.Second))
+	notificationService := services.NewNotificationServiceClient(config.GetNotificationServiceURL(), serviceClient("notification-services", 10*time.Second))
+	orderService := services.NewOrderServiceClient(config.GetOrderServiceURL(), serviceClient("order-services", 10*time.Second))
+	paymentService := services.NewPaymentServiceClient(config.GetOrderServiceURL(), serviceClient("order-services", 10*time.Second))
+	couponService := services.NewCouponServiceClient(config.GetOrderServiceURL(), serviceClient("order-services", 10*time.Second))
+	searchService := services.NewSearchServiceClient(config.GetSearchServiceURL(), serviceClient("search-services", 5*time.Second))
+	// The dashboard is served without recommendations rather than waiting on them
+	recommendationService := services.NewRecommendationServiceClient(config.GetRecommendationServiceURL(), serviceClient("recommendation-services", 2*time.Second))
+	geoIPService := services.NewGeoIPClient(config.GetGeoIPServiceURL(), metrics.NewHTTPClient("geoip", 2*time.Second))
+	geoIPService.SetRedisClient(redisClient)
+
+	var identityService services.IdentityService
+	if grpcURL := config.GetUserServiceGRPCURL(); grpcURL != "" {
+		opts := append(metrics.GRPCDialOptions("user-services-grpc"), grpc.WithChainUnaryInterceptor(chaosInterceptor(injector, "user-services-grpc")))
+		identityClient, err := services.NewIdentityClient(grpcURL, signer, opts...)
+		if err != nil {
+			logging.Fatal("failed to create identity client", "error", err)
+		}
+		app.Add(lifecycle.Closer("user-services-grpc", identityClient))
+		identityService = identityClient
+	}
+
+	graphQLAllowlist, err := graphql.LoadAllowlist(config.GetGraphQLAllowlistPath())
+	if err != nil {
+		logging.Fatal("failed to load GraphQL allowlist", "error", err)
+	}
+	slog.Info("loaded allowlisted GraphQL operations", "count", graphQLAllowlist.Len())
+
+	auditStore := audit.NewRedisStore(redisClient)
+
+	// Redis holds the sessions, so the BFF cannot serve without it; a downstream service
+	// being down only turns its features off
+	checker := health.New(health.Config{Service: "bff-services"})
+	checker.Register("redis", health.Critical, func(ctx context.Context) error {
+		return redisClient.Ping(ctx).Err()
+	})
+	checker.Register("user-services", health.Optional, health.HTTPGet(nil, config.GetUserServiceURL()+health.LivePath))
+	checker.Register("content-services", health.Optional, health.HTTPGet(nil, config.GetContentServiceURL()+health.LivePath))
+	checker.Register("order-services", health.Optional, health.HTTPGet(nil, config.GetOrderServiceURL()+health.LivePath))
+	checker.Register("search-services", health.Optional, health.HTTPGet(nil, config.GetSearchServiceURL()+health.LivePath))
+	checker.Register("recommendation-services", health.Optional, health.HTTPGet(nil, config.GetRecommendationServiceURL()+health.LivePath))
 
 	addr := ":" + port
 	r := server.NewRouter(server.Deps{
-		UserService:         userService,
-		ContentService:      contentService,
-		LessonService:       lessonService,
-		QuizAttemptService:  quizAttemptService,
-		NotificationService: notificationService,
-		OrderService:        orderService,
-		PaymentService:      paymentService,
-		CouponService:       couponService,
-		SessionCache:        sessionCache,
+		UserService:           userService,
+		IdentityService:       identityService,
+		ContentService:        contentService,
+		LessonService:         lessonService,
+		QuizAttemptService:    quizAttemptService,
+		NotificationService:   notificationService,
+		OrderService:          orderService,
+		PaymentService:        paymentService,
+		CouponService:         couponService,
+		SearchService:         searchService,
+		RecommendationService: recommendationService,
+		GeoIPService:          geoIPService,
+		SessionCache:          sessionCache,
+		ProfileCache:          profileCache,
+		StreakCache:           cache.NewStreakCacheService(redisClient),
+		FeedCache:             cache.NewFeedCache(redisClient),
+		GraphQLAllowlist:      graphQLAllowlist,
+		AuditRecorder:         auditStore,
+		AuditReader:           auditStore,
+		FeatureFlags:          flags.New(flagStore, flags.Config{}),
+		FeatureFlagStore:      flagStore,
+		APIKeys:               apikeys.NewStore(redisClient, config.GetRateLimits()),
+		RateLimiter:           rateLimiter,
+		Maintenance:           maintenance.NewStore(redisClient),
+		Consent:               consent.NewStore(redisClient),
+		GuestCache:            cache.NewGuestCache(redisClient, guestConfig.SessionTTL),
+		GuestSampleLessons:    guestConfig.SampleLessons,
+		Chaos:                 injector,
+		Health:                checker,
 	})
 
 	srv := &http.Server{
@@ -71,21 +231,38 @@ func main() {
 		Handler: r,
 	}
 
-	log.Printf("Starting server on %s", addr)
-	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Printf("Server error: %v", err)
-			os.Exit(1)
+	app.Add(lifecycle.HTTPServer("http", srv))
+	app.Add(lifecycle.Drain(checker.Shutdown, drainDelay))
+
+	slog.Info("starting server", "addr", addr)
+	if err := app.Run(context.Background()); err != nil {
+		logging.Fatal("server stopped with errors", "error", err)
+	}
+}
+
+// redisPoolStats reads the stats of the connection pool of client for the db_pool_*
+// metrics.
+func redisPoolStats(client *redis.Client) func() sharedmetrics.PoolStats {
+	return func() sharedmetrics.PoolStats {
+		s := client.PoolStats()
+		return sharedmetrics.PoolStats{
+			Open:         int(s.TotalConns),
+			InUse:        max(int(s.TotalConns)-int(s.IdleConns), 0),
+			Idle:         int(s.IdleConns),
+			MaxOpen:      client.Options().PoolSize,
+			WaitCount:    int64(s.WaitCount),
+			WaitDuration: time.Duration(s.WaitDurationNs),
 		}
-	}()
+	}
+}
 
-	// Wait for interrupt signal then attempt graceful shutdown
-	<-quit
-	log.Println("Shutting down server gracefully...")
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer shutdownCancel()
-	if err := srv.Shutdown(shutdownCtx); err != nil {
-		log.Printf("Server forced to shutdown: %v", err)
+// chaosInterceptor injects the faults of injector into the gRPC calls to target, as
+// injector.Transport does for HTTP calls.
+func chaosInterceptor(injector *chaos.Injector, target string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		call := chaos.Handler(injector, target, func(ctx context.Context, req any) error {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		})
+		return call(ctx, req)
 	}
-	log.Println("Server exited")
 }
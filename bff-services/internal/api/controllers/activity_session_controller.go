@@ -2,9 +2,8 @@ package controllers
 
 import (
 	"net/http"
-	"strconv"
-	"time"
 
+	"github.com/ductan2/microservice-app/shared/queryparams"
 	"github.com/gin-gonic/gin"
 
 	"bff-services/internal/api/dto"
@@ -83,34 +82,24 @@ func (a *ActivitySessionController) GetSessions(c *gin.Context) {
 		return
 	}
 
-	pageStr := c.DefaultQuery("page", "1")
-	limitStr := c.DefaultQuery("limit", "20")
-
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page <= 0 {
-		page = 1
-	}
-
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 || limit > 100 {
-		limit = 20
+	page, ok := parsePageRequest(c, 20, 100)
+	if !ok {
+		return
 	}
 
-	var startDate, endDate *time.Time
-	if startDateStr := c.Query("start_date"); startDateStr != "" {
-		start, err := time.Parse(time.RFC3339, startDateStr)
-		if err == nil {
-			startDate = &start
-		}
+	query := c.Request.URL.Query()
+	startDate, err := queryparams.Time(query, "start_date")
+	if err != nil {
+		failQueryParam(c, err)
+		return
 	}
-	if endDateStr := c.Query("end_date"); endDateStr != "" {
-		end, err := time.Parse(time.RFC3339, endDateStr)
-		if err == nil {
-			endDate = &end
-		}
+	endDate, err := queryparams.Time(query, "end_date")
+	if err != nil {
+		failQueryParam(c, err)
+		return
 	}
 
-	resp, err := a.userService.GetActivitySessions(c, userID, email, sessionID, page, limit, startDate, endDate)
+	resp, err := a.userService.GetActivitySessions(c, userID, email, sessionID, page.Number(), page.Limit, startDate, endDate)
 	if err != nil {
 		utils.Fail(c, "Unable to fetch sessions", http.StatusBadGateway, err.Error())
 		return
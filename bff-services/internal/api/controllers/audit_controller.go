@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"bff-services/internal/api/dto"
+	"bff-services/internal/audit"
+	"bff-services/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditController exposes the gateway audit log to administrators.
+type AuditController struct {
+	reader audit.Reader
+}
+
+// NewAuditController constructs a new AuditController.
+func NewAuditController(reader audit.Reader) *AuditController {
+	return &AuditController{reader: reader}
+}
+
+// ListAuditLogs returns audit entries newest first, filtered by actor, impersonating
+// administrator, action, target, outcome and time range. Pass next_cursor back as cursor
+// to read older entries.
+func (a *AuditController) ListAuditLogs(c *gin.Context) {
+	var q dto.AuditLogQuery
+	if !bindQuery(c, &q) {
+		return
+	}
+
+	query := audit.Query{
+		ActorID:        q.ActorID,
+		ImpersonatorID: q.ImpersonatorID,
+		Action:         q.Action,
+		TargetID:       q.TargetID,
+		Outcome:        q.Outcome,
+		Cursor:         q.Cursor,
+	}
+	if q.Limit != nil {
+		query.Limit = *q.Limit
+	}
+	// The formats were checked during binding.
+	if q.From != "" {
+		query.From, _ = time.Parse(time.RFC3339, q.From)
+	}
+	if q.To != "" {
+		query.To, _ = time.Parse(time.RFC3339, q.To)
+	}
+
+	page, err := a.reader.Query(c.Request.Context(), query)
+	if err != nil {
+		utils.Fail(c, "Unable to query audit log", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	utils.Success(c, page)
+}
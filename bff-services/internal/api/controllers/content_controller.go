@@ -10,6 +10,7 @@ import (
 
 	"bff-services/internal/api/dto"
 	"bff-services/internal/config"
+	"bff-services/internal/graphql"
 	middleware "bff-services/internal/middlewares"
 	"bff-services/internal/services"
 	"bff-services/internal/utils"
@@ -23,11 +24,12 @@ const maxImageUploadSize = 50 << 20 // 50 MiB
 // ContentController proxies content operations to the content service.
 type ContentController struct {
 	contentService services.ContentService
+	allowlist      *graphql.Allowlist
 }
 
 // NewContentController constructs a new ContentController.
-func NewContentController(contentService services.ContentService) *ContentController {
-	return &ContentController{contentService: contentService}
+func NewContentController(contentService services.ContentService, allowlist *graphql.Allowlist) *ContentController {
+	return &ContentController{contentService: contentService, allowlist: allowlist}
 }
 
 func (c *ContentController) ProxyGraphQL(ctx *gin.Context) {
@@ -45,7 +47,7 @@ func (c *ContentController) ProxyGraphQL(ctx *gin.Context) {
 		return
 	}
 
-	resp, err := c.contentService.ExecuteGraphQL(ctx.Request.Context(), token, payload)
+	resp, err := c.contentService.ExecuteGraphQL(ctx.Request.Context(), token, payload, nil)
 	if err != nil {
 		utils.Fail(ctx, "Unable to execute GraphQL request", http.StatusBadGateway, err.Error())
 		return
@@ -53,6 +55,87 @@ func (c *ContentController) ProxyGraphQL(ctx *gin.Context) {
 	respondWithServiceResponse(ctx, resp)
 }
 
+// ProxyPublicGraphQL forwards GraphQL operations from public clients. Anonymous users and
+// learners may only run operations registered in the allowlist; staff roles may send
+// arbitrary documents. The caller's identity is forwarded through internal headers.
+func (c *ContentController) ProxyPublicGraphQL(ctx *gin.Context) {
+	if c.contentService == nil {
+		utils.Fail(ctx, "Content service unavailable", http.StatusServiceUnavailable, "content service not configured")
+		return
+	}
+
+	var payload dto.PersistedGraphQLRequest
+	if err := ctx.ShouldBindJSON(&payload); err != nil {
+		utils.Fail(ctx, "Invalid GraphQL request", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	query := strings.TrimSpace(payload.Query)
+	hash := ""
+	if payload.Extensions.PersistedQuery != nil {
+		hash = strings.ToLower(strings.TrimSpace(payload.Extensions.PersistedQuery.Sha256Hash))
+	}
+	if query == "" && hash == "" {
+		utils.Fail(ctx, "Invalid GraphQL request", http.StatusBadRequest, "query or persisted query hash is required")
+		return
+	}
+	if query != "" && hash != "" && graphql.HashQuery(query) != hash {
+		utils.Fail(ctx, "Invalid GraphQL request", http.StatusBadRequest, "persisted query hash mismatch")
+		return
+	}
+
+	role := middleware.GetUserRole(ctx)
+	if !isStaffRole(role) || query == "" {
+		if hash == "" {
+			hash = graphql.HashQuery(query)
+		}
+		registered, ok := c.allowlist.Lookup(hash)
+		if !ok {
+			utils.Fail(ctx, "Forbidden", http.StatusForbidden, "graphql operation is not allowed")
+			return
+		}
+		query = registered
+	}
+
+	headers := http.Header{}
+	if userID, exists := ctx.Get(middleware.ContextUserIDKey()); exists {
+		if id, ok := userID.(uuid.UUID); ok {
+			headers.Set("X-User-ID", id.String())
+		}
+		headers.Set("X-User-Email", ctx.GetString(middleware.ContextUserEmailKey()))
+		if sessionID, ok := ctx.Value(middleware.ContextSessionIDKey()).(uuid.UUID); ok {
+			headers.Set("X-Session-ID", sessionID.String())
+		}
+	}
+	if role != "" {
+		headers.Set("X-User-Role", role)
+	}
+	if orgID, orgRole := middleware.GetOrganizationContext(ctx); orgID != "" {
+		headers.Set("X-Organization-ID", orgID)
+		headers.Set("X-Organization-Role", orgRole)
+	}
+
+	request := dto.GraphQLRequest{
+		Query:         query,
+		OperationName: payload.OperationName,
+		Variables:     payload.Variables,
+	}
+	resp, err := c.contentService.ExecuteGraphQL(ctx.Request.Context(), getOptionalBearerToken(ctx), request, headers)
+	if err != nil {
+		utils.Fail(ctx, "Unable to execute GraphQL request", http.StatusBadGateway, err.Error())
+		return
+	}
+	respondWithServiceResponse(ctx, resp)
+}
+
+func isStaffRole(role string) bool {
+	switch role {
+	case middleware.RoleTeacher, middleware.RoleAdmin, middleware.RoleSuperAdmin:
+		return true
+	}
+	return false
+}
+
 func (c *ContentController) UploadImages(ctx *gin.Context) {
 	if c.contentService == nil {
 		utils.Fail(ctx, "Content service unavailable", http.StatusServiceUnavailable, "content service not configured")
@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"testing"
+
+	"bff-services/internal/api/dto"
+)
+
+func goalsOf(minutes int) *dto.UserGoalsResponse {
+	goals := &dto.UserGoalsResponse{}
+	goals.LearningGoals.DailyMinutes = minutes
+	return goals
+}
+
+func feedKinds(feed *dto.ContinueFeed) []string {
+	kinds := make([]string, len(feed.Items))
+	for i, item := range feed.Items {
+		kinds[i] = item.Kind
+	}
+	return kinds
+}
+
+// TestBuildContinueFeedOrder checks the order of the feed items for each state of the
+// daily goal and the sources that failed.
+func TestBuildContinueFeedOrder(t *testing.T) {
+	enrollments := []dto.CourseEnrollmentResponse{
+		{ID: "e1", CourseID: "not-started", Status: "enrolled"},
+		{ID: "e2", CourseID: "under-way", Status: "in_progress", ProgressPercent: 40},
+		{ID: "e3", CourseID: "done", Status: "completed", ProgressPercent: 100},
+		{ID: "e4", CourseID: "resumed", Status: "enrolled", ProgressPercent: 10},
+	}
+
+	cases := []struct {
+		name        string
+		enrollments []dto.CourseEnrollmentResponse
+		cards       *dto.CardStatsResponse
+		activity    *dto.DailyActivityResponse
+		goals       *dto.UserGoalsResponse
+		wantKinds   []string
+		wantCourses []string
+		wantGoal    *dto.DailyGoalStatus
+	}{
+		{
+			name:        "UnmetGoalFirst",
+			enrollments: enrollments,
+			cards:       &dto.CardStatsResponse{DueCards: 12},
+			activity:    &dto.DailyActivityResponse{Minutes: 5},
+			goals:       goalsOf(15),
+			wantKinds:   []string{dto.FeedItemDailyGoal, dto.FeedItemReviewFlashcards, dto.FeedItemContinueCourse, dto.FeedItemContinueCourse, dto.FeedItemStartCourse},
+			wantCourses: []string{"under-way", "resumed", "not-started"},
+			wantGoal:    &dto.DailyGoalStatus{TargetMinutes: 15, MinutesToday: 5, RemainingMinutes: 10},
+		},
+		{
+			name:        "MetGoalLast",
+			enrollments: enrollments[:1],
+			cards:       &dto.CardStatsResponse{DueCards: 3},
+			activity:    &dto.DailyActivityResponse{Minutes: 20},
+			goals:       goalsOf(15),
+			wantKinds:   []string{dto.FeedItemReviewFlashcards, dto.FeedItemStartCourse, dto.FeedItemDailyGoal},
+			wantCourses: []string{"not-started"},
+			wantGoal:    &dto.DailyGoalStatus{TargetMinutes: 15, MinutesToday: 20, Met: true},
+		},
+		{
+			name:        "NoReviewsDueAndNoGoal",
+			enrollments: enrollments[1:2],
+			cards:       &dto.CardStatsResponse{DueCards: 0},
+			activity:    &dto.DailyActivityResponse{Minutes: 5},
+			goals:       goalsOf(0),
+			wantKinds:   []string{dto.FeedItemContinueCourse},
+			wantCourses: []string{"under-way"},
+		},
+		{
+			name:      "ActivityFailedLeavesGoalOut",
+			cards:     &dto.CardStatsResponse{DueCards: 1},
+			goals:     goalsOf(15),
+			wantKinds: []string{dto.FeedItemReviewFlashcards},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			feed := buildContinueFeed(tc.enrollments, tc.cards, tc.activity, tc.goals)
+
+			kinds := feedKinds(feed)
+			if len(kinds) != len(tc.wantKinds) {
+				t.Fatalf("kinds = %v, want %v", kinds, tc.wantKinds)
+			}
+			for i := range kinds {
+				if kinds[i] != tc.wantKinds[i] {
+					t.Fatalf("kinds = %v, want %v", kinds, tc.wantKinds)
+				}
+			}
+
+			var courses []string
+			for _, item := range feed.Items {
+				if item.CourseID != "" {
+					courses = append(courses, item.CourseID)
+				}
+			}
+			if len(courses) != len(tc.wantCourses) {
+				t.Fatalf("courses = %v, want %v", courses, tc.wantCourses)
+			}
+			for i := range courses {
+				if courses[i] != tc.wantCourses[i] {
+					t.Fatalf("courses = %v, want %v", courses, tc.wantCourses)
+				}
+			}
+
+			switch {
+			case tc.wantGoal == nil && feed.Goal != nil:
+				t.Fatalf("goal = %+v, want none", *feed.Goal)
+			case tc.wantGoal != nil && (feed.Goal == nil || *feed.Goal != *tc.wantGoal):
+				t.Fatalf("goal = %+v, want %+v", feed.Goal, *tc.wantGoal)
+			}
+		})
+	}
+}
+
+// TestBuildContinueFeedLimitsCourses checks that the feed lists feedCourseLimit courses.
+func TestBuildContinueFeedLimitsCourses(t *testing.T) {
+	var enrollments []dto.CourseEnrollmentResponse
+	for i := 0; i < feedCourseLimit+3; i++ {
+		enrollments = append(enrollments, dto.CourseEnrollmentResponse{Status: "in_progress"})
+	}
+
+	feed := buildContinueFeed(enrollments, nil, nil, nil)
+	if len(feed.Items) != feedCourseLimit {
+		t.Fatalf("items = %d, want %d", len(feed.Items), feedCourseLimit)
+	}
+}
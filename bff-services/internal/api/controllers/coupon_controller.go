@@ -40,11 +40,11 @@ func (cpc *CouponController) ListAvailableCoupons(c *gin.Context) {
 		return
 	}
 
-	var query dto.CouponListQuery
-	if err := c.ShouldBindQuery(&query); err != nil {
-		utils.Fail(c, "Invalid query parameters", http.StatusBadRequest, err.Error())
+	page, ok := parsePageRequest(c, 20, 100)
+	if !ok {
 		return
 	}
+	query := dto.CouponListQuery{Limit: page.Limit, Offset: page.Offset}
 
 	resp, err := cpc.couponService.ListAvailableCoupons(c.Request.Context(), token, userID, email, sessionID, query)
 	if err != nil {
@@ -52,7 +52,7 @@ func (cpc *CouponController) ListAvailableCoupons(c *gin.Context) {
 		return
 	}
 
-	respondWithServiceResponse(c, resp)
+	respondWithPage(c, resp, page)
 }
 
 func (cpc *CouponController) GetCoupon(c *gin.Context) {
@@ -1,15 +1,23 @@
 package controllers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/sync/errgroup"
 
 	"bff-services/internal/api/dto"
+	"bff-services/internal/cache"
+	"bff-services/internal/i18n"
 	middleware "bff-services/internal/middlewares"
 	"bff-services/internal/services"
 	"bff-services/internal/types"
@@ -18,20 +26,32 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// dashboardRecommendationLimit is how many recommendations the dashboard shows.
+const dashboardRecommendationLimit = 5
+
 // DashboardController aggregates data from multiple services for dashboard views.
 type DashboardController struct {
-	userService   services.UserService
-	lessonService services.LessonService
+	userService           services.UserService
+	lessonService         services.LessonService
+	recommendationService services.RecommendationService
+	feedCache             *cache.FeedCache
 }
 
-// NewDashboardController constructs a new DashboardController.
-func NewDashboardController(userService services.UserService, lessonService services.LessonService) *DashboardController {
+// NewDashboardController constructs a new DashboardController. recommendationService may
+// be nil, the summary then has no recommendations.
+func NewDashboardController(userService services.UserService, lessonService services.LessonService, recommendationService services.RecommendationService) *DashboardController {
 	return &DashboardController{
-		userService:   userService,
-		lessonService: lessonService,
+		userService:           userService,
+		lessonService:         lessonService,
+		recommendationService: recommendationService,
 	}
 }
 
+// SetFeedCache enables caching of the continue-learning feed.
+func (d *DashboardController) SetFeedCache(feedCache *cache.FeedCache) {
+	d.feedCache = feedCache
+}
+
 func (d *DashboardController) GetSummary(c *gin.Context) {
 	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
 	if !ok {
@@ -47,6 +67,15 @@ func (d *DashboardController) GetSummary(c *gin.Context) {
 		streak       dto.UserStreakResponse
 	)
 
+	// Recommendations are optional: they are fetched alongside and left out when
+	// recommendation-services fails, rather than failing the summary
+	var recommendations *dto.DashboardRecommendations
+	recommended := make(chan struct{})
+	go func(ctx context.Context) {
+		defer close(recommended)
+		recommendations = d.getRecommendations(ctx, userID)
+	}(ctx)
+
 	g, ctx := errgroup.WithContext(ctx)
 
 	g.Go(func() error {
@@ -122,10 +151,30 @@ func (d *DashboardController) GetSummary(c *gin.Context) {
 		LongestStreakDays:  streak.LongestLen,
 		LastStreakActivity: streak.LastDay,
 	}
+	<-recommended
+	summary.Recommendations = recommendations
 
 	utils.Success(c, summary)
 }
 
+// getRecommendations returns the courses and lessons recommended to the user, or nil when
+// recommendation-services is not configured or fails.
+func (d *DashboardController) getRecommendations(ctx context.Context, userID string) *dto.DashboardRecommendations {
+	if d.recommendationService == nil {
+		return nil
+	}
+	query := url.Values{"limit": {strconv.Itoa(dashboardRecommendationLimit)}}
+	resp, err := d.recommendationService.GetRecommendations(ctx, userID, query)
+	if err == nil {
+		var data *dto.DashboardRecommendations
+		if data, err = decodeDataResponse[dto.DashboardRecommendations](resp); err == nil {
+			return data
+		}
+	}
+	slog.WarnContext(ctx, "dashboard served without recommendations", "user_id", userID, "error", err)
+	return nil
+}
+
 type serviceResponse[T any] struct {
 	Status  string `json:"status"`
 	Data    T      `json:"data"`
@@ -162,6 +211,202 @@ func decodeServiceResponse[T any](resp *types.HTTPResponse) (*T, error) {
 	return &envelope.Data, nil
 }
 
+// GetContinueFeed returns the user's continue-learning feed: today's goal, due flashcard
+// reviews and enrolled courses in the order to take them.
+func (d *DashboardController) GetContinueFeed(c *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	load := func(ctx context.Context) (*dto.ContinueFeed, error) {
+		return d.loadContinueFeed(ctx, userID, email, sessionID)
+	}
+
+	var (
+		feed *dto.ContinueFeed
+		err  error
+	)
+	if d.feedCache == nil {
+		feed, err = load(ctx)
+	} else {
+		feed, err = d.feedCache.Feed(ctx, userID, i18n.TimezoneFromContext(ctx), load)
+	}
+	if err != nil {
+		utils.Fail(c, "Unable to build continue-learning feed", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	utils.Success(c, feed)
+}
+
+// feedEnrollmentLimit bounds the enrollments read for the feed, and feedCourseLimit the
+// courses it lists.
+const (
+	feedEnrollmentLimit = 50
+	feedCourseLimit     = 5
+)
+
+// loadContinueFeed reads the sources of the feed concurrently. A source that fails leaves
+// its items out and marks the feed partial; the feed fails only when they all do.
+func (d *DashboardController) loadContinueFeed(ctx context.Context, userID, email, sessionID string) (*dto.ContinueFeed, error) {
+	var (
+		enrollments []dto.CourseEnrollmentResponse
+		cards       *dto.CardStatsResponse
+		activity    *dto.DailyActivityResponse
+		goals       *dto.UserGoalsResponse
+
+		mu     sync.Mutex
+		failed []error
+	)
+	fail := func(source string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		failed = append(failed, fmt.Errorf("%s: %w", source, err))
+	}
+
+	var g errgroup.Group
+	g.Go(func() error {
+		data, err := decodeServiceResult[[]dto.CourseEnrollmentResponse](d.lessonService.ListMyEnrollments(ctx, userID, email, sessionID, "", feedEnrollmentLimit, 0))
+		if err != nil {
+			fail("enrollments", err)
+			return nil
+		}
+		enrollments = *data
+		return nil
+	})
+	g.Go(func() error {
+		data, err := decodeServiceResult[dto.CardStatsResponse](d.lessonService.GetMyCardStats(ctx, userID, email, sessionID))
+		if err != nil {
+			fail("flashcard reviews", err)
+			return nil
+		}
+		cards = data
+		return nil
+	})
+	g.Go(func() error {
+		data, err := decodeServiceResult[dto.DailyActivityResponse](d.lessonService.GetDailyActivityToday(ctx, userID, email, sessionID))
+		if err != nil {
+			fail("today's activity", err)
+			return nil
+		}
+		activity = data
+		return nil
+	})
+	g.Go(func() error {
+		data, err := decodeServiceResult[dto.UserGoalsResponse](d.userService.GetPreferences(ctx, userID, email, sessionID))
+		if err != nil {
+			fail("learning goals", err)
+			return nil
+		}
+		goals = data
+		return nil
+	})
+	_ = g.Wait()
+
+	if len(failed) == 4 {
+		return nil, errors.Join(failed...)
+	}
+	if len(failed) > 0 {
+		slog.WarnContext(ctx, "continue-learning feed is partial", "user_id", userID, "error", errors.Join(failed...))
+	}
+
+	feed := buildContinueFeed(enrollments, cards, activity, goals)
+	feed.Partial = len(failed) > 0
+	feed.GeneratedAt = time.Now().UTC()
+	return feed, nil
+}
+
+// buildContinueFeed orders what the user should do next. An unmet daily goal comes first,
+// as the reason to study today, and a met one last. Due reviews come before courses: they
+// are short and the cards are forgotten while they wait. Courses under way come before
+// those not started, each the most recently accessed first. Sources that failed are nil.
+func buildContinueFeed(enrollments []dto.CourseEnrollmentResponse, cards *dto.CardStatsResponse, activity *dto.DailyActivityResponse, goals *dto.UserGoalsResponse) *dto.ContinueFeed {
+	feed := &dto.ContinueFeed{Items: []dto.FeedItem{}}
+
+	var goal *dto.FeedItem
+	if activity != nil && goals != nil && goals.LearningGoals.DailyMinutes > 0 {
+		target := goals.LearningGoals.DailyMinutes
+		status := dto.DailyGoalStatus{
+			TargetMinutes:    target,
+			MinutesToday:     activity.Minutes,
+			RemainingMinutes: max(target-activity.Minutes, 0),
+		}
+		status.Met = status.RemainingMinutes == 0
+		feed.Goal = &status
+		goal = &dto.FeedItem{Kind: dto.FeedItemDailyGoal, RemainingMinutes: status.RemainingMinutes, Completed: status.Met}
+	}
+
+	if goal != nil && !goal.Completed {
+		feed.Items = append(feed.Items, *goal)
+	}
+	if cards != nil && cards.DueCards > 0 {
+		feed.Items = append(feed.Items, dto.FeedItem{Kind: dto.FeedItemReviewFlashcards, DueCards: cards.DueCards})
+	}
+
+	// The lesson service lists the enrollments by last access
+	var started, notStarted []dto.FeedItem
+	for _, enrollment := range enrollments {
+		item := dto.FeedItem{
+			CourseID:       enrollment.CourseID,
+			EnrollmentID:   enrollment.ID,
+			LastAccessedAt: enrollment.LastAccessedAt,
+		}
+		switch {
+		case enrollment.Status == "in_progress" || (enrollment.Status == "enrolled" && enrollment.ProgressPercent > 0):
+			progress := enrollment.ProgressPercent
+			item.Kind = dto.FeedItemContinueCourse
+			item.ProgressPercent = &progress
+			started = append(started, item)
+		case enrollment.Status == "enrolled":
+			item.Kind = dto.FeedItemStartCourse
+			notStarted = append(notStarted, item)
+		}
+	}
+	courses := append(started, notStarted...)
+	feed.Items = append(feed.Items, courses[:min(len(courses), feedCourseLimit)]...)
+
+	if goal != nil && goal.Completed {
+		feed.Items = append(feed.Items, *goal)
+	}
+	return feed
+}
+
+// decodeServiceResult decodes the response of a service call, failing with the call.
+func decodeServiceResult[T any](resp *types.HTTPResponse, err error) (*T, error) {
+	if err != nil {
+		return nil, err
+	}
+	return decodeServiceResponse[T](resp)
+}
+
+type dataResponse[T any] struct {
+	Data T `json:"data"`
+}
+
+// decodeDataResponse decodes the {"data": ...} envelope of the Go services that answer
+// errors as {"error": {...}} with an error status.
+func decodeDataResponse[T any](resp *types.HTTPResponse) (*T, error) {
+	if resp == nil {
+		return nil, fmt.Errorf("empty response")
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		body := strings.TrimSpace(string(resp.Body))
+		if len(body) > 256 {
+			body = body[:256] + "..."
+		}
+		return nil, fmt.Errorf("remote status %d: %s", resp.StatusCode, body)
+	}
+
+	var envelope dataResponse[T]
+	if err := json.Unmarshal(resp.Body, &envelope); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	return &envelope.Data, nil
+}
+
 func minutesFromMillis(ms int64) int64 {
 	if ms <= 0 {
 		return 0
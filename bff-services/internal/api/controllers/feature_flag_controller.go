@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"bff-services/internal/api/dto"
+	middleware "bff-services/internal/middlewares"
+	"bff-services/internal/utils"
+	"bff-services/internal/validation"
+
+	"github.com/ductan2/microservice-app/shared/flags"
+	"github.com/gin-gonic/gin"
+)
+
+// FeatureFlagController exposes the flags evaluated for the current user and lets admins
+// edit their definitions.
+type FeatureFlagController struct {
+	store  flags.Store
+	client *flags.Client
+}
+
+// NewFeatureFlagController constructs a new FeatureFlagController.
+func NewFeatureFlagController(store flags.Store, client *flags.Client) *FeatureFlagController {
+	return &FeatureFlagController{store: store, client: client}
+}
+
+// GetMyFlags returns every feature flag evaluated for the authenticated user, so clients
+// can toggle UI for features that are being rolled out gradually.
+func (f *FeatureFlagController) GetMyFlags(c *gin.Context) {
+	if _, _, _, ok := middleware.GetUserContextFromMiddleware(c); !ok {
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	c.JSON(http.StatusOK, utils.BaseResponse{
+		Status: "success",
+		Data:   gin.H{"flags": middleware.GetFeatureFlags(c)},
+	})
+}
+
+// ListFlags returns every flag definition, sorted by name. It reads the store rather than
+// the snapshot so admins see their changes at once.
+func (f *FeatureFlagController) ListFlags(c *gin.Context) {
+	defined, err := f.store.List(c.Request.Context())
+	if err != nil {
+		utils.Fail(c, "Unable to load feature flags", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	list := make([]flags.Flag, 0, len(defined))
+	for _, flag := range defined {
+		list = append(list, flag)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	c.Header("Cache-Control", "no-store")
+	utils.Success(c, list)
+}
+
+// PutFlag creates or replaces a flag. The other services apply the change within their
+// refresh interval.
+func (f *FeatureFlagController) PutFlag(c *gin.Context) {
+	var req dto.FeatureFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request payload", http.StatusBadRequest, validation.Describe(err))
+		return
+	}
+
+	now := time.Now().UTC()
+	flag := flags.Flag{
+		Name:           c.Param("name"),
+		Description:    req.Description,
+		Enabled:        *req.Enabled,
+		RolloutPercent: req.RolloutPercent,
+		Users:          req.Users,
+		Roles:          req.Roles,
+		UpdatedAt:      &now,
+	}
+	if err := flag.Validate(); err != nil {
+		utils.Fail(c, "Invalid feature flag", http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := f.store.Put(c.Request.Context(), flag); err != nil {
+		utils.Fail(c, "Unable to update feature flag", http.StatusInternalServerError, err.Error())
+		return
+	}
+	f.client.Invalidate()
+
+	utils.Success(c, flag)
+}
+
+// DeleteFlag removes a flag, turning the feature off everywhere.
+func (f *FeatureFlagController) DeleteFlag(c *gin.Context) {
+	name := c.Param("name")
+	if err := f.store.Delete(c.Request.Context(), name); err != nil {
+		if errors.Is(err, flags.ErrNotFound) {
+			utils.Fail(c, "Feature flag not found", http.StatusNotFound, name)
+			return
+		}
+		utils.Fail(c, "Unable to delete feature flag", http.StatusInternalServerError, err.Error())
+		return
+	}
+	f.client.Invalidate()
+
+	utils.Success(c, gin.H{"name": name, "deleted": true})
+}
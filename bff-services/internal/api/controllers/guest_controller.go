@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"bff-services/internal/api/dto"
+	"bff-services/internal/cache"
+	"bff-services/internal/i18n"
+	middleware "bff-services/internal/middlewares"
+	"bff-services/internal/services"
+	"bff-services/internal/utils"
+	"bff-services/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GuestController manages anonymous guest sessions and the limited routes they unlock.
+type GuestController struct {
+	guestCache    *cache.GuestCache
+	lessonService services.LessonService
+	sampleLessons int
+}
+
+// NewGuestController constructs a new GuestController. sampleLessons caps how many
+// lessons of a course a guest may preview.
+func NewGuestController(guestCache *cache.GuestCache, lessonService services.LessonService, sampleLessons int) *GuestController {
+	return &GuestController{
+		guestCache:    guestCache,
+		lessonService: lessonService,
+		sampleLessons: sampleLessons,
+	}
+}
+
+// CreateSession starts a guest session for an anonymous visitor.
+func (g *GuestController) CreateSession(c *gin.Context) {
+	ctx := c.Request.Context()
+	session, err := g.guestCache.Create(ctx, i18n.LocaleFromContext(ctx), i18n.TimezoneFromContext(ctx))
+	if err != nil {
+		utils.Fail(c, "Unable to start guest session", http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	utils.Created(c, g.sessionResponse(session))
+}
+
+// GetSession returns the caller's guest session.
+func (g *GuestController) GetSession(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	utils.Success(c, g.sessionResponse(middleware.GetGuestSession(c)))
+}
+
+// UpdateSession stores the guest's course selection so it survives sign-up.
+func (g *GuestController) UpdateSession(c *gin.Context) {
+	var req dto.UpdateGuestSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request payload", http.StatusBadRequest, validation.Describe(err))
+		return
+	}
+
+	session := middleware.GetGuestSession(c)
+	session.SelectedCourseID = req.SelectedCourseID
+	if err := g.guestCache.Save(c.Request.Context(), session); err != nil {
+		if errors.Is(err, cache.ErrGuestSessionNotFound) {
+			utils.FailWithCode(c, "Guest session expired", http.StatusUnauthorized, "GUEST_SESSION_EXPIRED", nil)
+			return
+		}
+		utils.Fail(c, "Unable to update guest session", http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	utils.Success(c, g.sessionResponse(session))
+}
+
+// ListSampleLessons returns the first lessons of a course, ordered by position, so a
+// guest can preview it before signing up.
+func (g *GuestController) ListSampleLessons(c *gin.Context) {
+	var params dto.CourseIDParam
+	if !bindURI(c, &params) {
+		return
+	}
+
+	resp, err := g.lessonService.ListCourseLessonsByCourseID(c.Request.Context(), params.CourseID)
+	if err != nil {
+		utils.Fail(c, "Unable to fetch course lessons", http.StatusBadGateway, err.Error())
+		return
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		respondWithServiceError(c, resp)
+		return
+	}
+
+	var envelope struct {
+		Data []json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body, &envelope); err != nil {
+		utils.Fail(c, "Unable to fetch course lessons", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	type sampleLesson struct {
+		raw json.RawMessage
+		ord int
+	}
+	ordered := make([]sampleLesson, len(envelope.Data))
+	for i, raw := range envelope.Data {
+		var item struct {
+			Ord int `json:"ord"`
+		}
+		_ = json.Unmarshal(raw, &item)
+		ordered[i] = sampleLesson{raw: raw, ord: item.Ord}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].ord < ordered[j].ord })
+
+	lessons := make([]json.RawMessage, 0, g.sampleLessons)
+	for _, lesson := range ordered {
+		if len(lessons) == g.sampleLessons {
+			break
+		}
+		lessons = append(lessons, lesson.raw)
+	}
+
+	utils.Success(c, dto.GuestSampleLessonsResponse{
+		CourseID:     params.CourseID,
+		Lessons:      lessons,
+		TotalLessons: len(ordered),
+	})
+}
+
+// ConvertSession carries guest state over to the account the guest just signed up for
+// or logged into: the selected course becomes an enrollment. The guest session is
+// deleted afterwards so it cannot be converted twice.
+func (g *GuestController) ConvertSession(c *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+	session := middleware.GetGuestSession(c)
+
+	result := dto.GuestConversionResponse{}
+	if session.SelectedCourseID != "" {
+		resp, err := g.lessonService.EnrollCourse(c.Request.Context(), userID, email, sessionID, dto.CourseEnrollmentCreate{CourseID: session.SelectedCourseID})
+		if err != nil {
+			utils.Fail(c, "Unable to enroll", http.StatusBadGateway, err.Error())
+			return
+		}
+		// An existing enrollment already reflects the guest's choice.
+		if resp.StatusCode >= http.StatusBadRequest && resp.StatusCode != http.StatusConflict {
+			respondWithServiceError(c, resp)
+			return
+		}
+		result.EnrolledCourseID = session.SelectedCourseID
+	}
+
+	if err := g.guestCache.Delete(c.Request.Context(), session.ID); err != nil {
+		slog.WarnContext(c.Request.Context(), "failed to delete converted guest session", "guest_session_id", session.ID, "error", err)
+	}
+
+	utils.Success(c, result)
+}
+
+func (g *GuestController) sessionResponse(session *cache.GuestSession) dto.GuestSessionResponse {
+	return dto.GuestSessionResponse{
+		GuestID:          session.ID.String(),
+		SelectedCourseID: session.SelectedCourseID,
+		CreatedAt:        session.CreatedAt,
+		// Every guest request slides the TTL, so the session lives ttl from now.
+		ExpiresAt: time.Now().UTC().Add(g.guestCache.TTL()),
+	}
+}
@@ -15,4 +15,10 @@ type Controllers struct {
 	Order           *OrderController
 	Payment         *PaymentController
 	Coupon          *CouponController
+	Search          *SearchController
+	FeatureFlag     *FeatureFlagController
+	Partner         *PartnerController
+	Audit           *AuditController
+	Maintenance     *MaintenanceController
+	Guest           *GuestController
 }
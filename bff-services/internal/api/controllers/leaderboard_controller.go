@@ -2,7 +2,6 @@ package controllers
 
 import (
 	"net/http"
-	"strconv"
 
 	middleware "bff-services/internal/middlewares"
 	"bff-services/internal/services"
@@ -26,31 +25,12 @@ func (l *LeaderboardController) GetCurrentWeeklyLeaderboard(c *gin.Context) {
 		return
 	}
 
-	limit := 100
-	offset := 0
-
-	if limitStr := c.Query("limit"); limitStr != "" {
-		parsed, err := strconv.Atoi(limitStr)
-		if err != nil || parsed < 1 {
-			utils.Fail(c, "Invalid limit parameter", http.StatusBadRequest, "limit must be a positive integer")
-			return
-		}
-		if parsed > 500 {
-			parsed = 500
-		}
-		limit = parsed
-	}
-
-	if offsetStr := c.Query("offset"); offsetStr != "" {
-		parsed, err := strconv.Atoi(offsetStr)
-		if err != nil || parsed < 0 {
-			utils.Fail(c, "Invalid offset parameter", http.StatusBadRequest, "offset must be non-negative")
-			return
-		}
-		offset = parsed
+	page, ok := parsePageRequest(c, 100, 500)
+	if !ok {
+		return
 	}
 
-	resp, err := l.leaderboardService.GetCurrentWeeklyLeaderboard(c.Request.Context(), limit, offset)
+	resp, err := l.leaderboardService.GetCurrentWeeklyLeaderboard(c.Request.Context(), page.Limit, page.Offset)
 	if err != nil {
 		utils.Fail(c, "Unable to fetch weekly leaderboard", http.StatusBadGateway, err.Error())
 		return
@@ -64,31 +44,12 @@ func (l *LeaderboardController) GetCurrentMonthlyLeaderboard(c *gin.Context) {
 		return
 	}
 
-	limit := 100
-	offset := 0
-
-	if limitStr := c.Query("limit"); limitStr != "" {
-		parsed, err := strconv.Atoi(limitStr)
-		if err != nil || parsed < 1 {
-			utils.Fail(c, "Invalid limit parameter", http.StatusBadRequest, "limit must be a positive integer")
-			return
-		}
-		if parsed > 500 {
-			parsed = 500
-		}
-		limit = parsed
-	}
-
-	if offsetStr := c.Query("offset"); offsetStr != "" {
-		parsed, err := strconv.Atoi(offsetStr)
-		if err != nil || parsed < 0 {
-			utils.Fail(c, "Invalid offset parameter", http.StatusBadRequest, "offset must be non-negative")
-			return
-		}
-		offset = parsed
+	page, ok := parsePageRequest(c, 100, 500)
+	if !ok {
+		return
 	}
 
-	resp, err := l.leaderboardService.GetCurrentMonthlyLeaderboard(c.Request.Context(), limit, offset)
+	resp, err := l.leaderboardService.GetCurrentMonthlyLeaderboard(c.Request.Context(), page.Limit, page.Offset)
 	if err != nil {
 		utils.Fail(c, "Unable to fetch monthly leaderboard", http.StatusBadGateway, err.Error())
 		return
@@ -102,31 +63,12 @@ func (l *LeaderboardController) GetWeeklyLeaderboardHistory(c *gin.Context) {
 		return
 	}
 
-	limit := 10
-	offset := 0
-
-	if limitStr := c.Query("limit"); limitStr != "" {
-		parsed, err := strconv.Atoi(limitStr)
-		if err != nil || parsed < 1 {
-			utils.Fail(c, "Invalid limit parameter", http.StatusBadRequest, "limit must be a positive integer")
-			return
-		}
-		if parsed > 52 {
-			parsed = 52
-		}
-		limit = parsed
-	}
-
-	if offsetStr := c.Query("offset"); offsetStr != "" {
-		parsed, err := strconv.Atoi(offsetStr)
-		if err != nil || parsed < 0 {
-			utils.Fail(c, "Invalid offset parameter", http.StatusBadRequest, "offset must be non-negative")
-			return
-		}
-		offset = parsed
+	page, ok := parsePageRequest(c, 10, 52)
+	if !ok {
+		return
 	}
 
-	resp, err := l.leaderboardService.GetWeeklyLeaderboardHistory(c.Request.Context(), limit, offset)
+	resp, err := l.leaderboardService.GetWeeklyLeaderboardHistory(c.Request.Context(), page.Limit, page.Offset)
 	if err != nil {
 		utils.Fail(c, "Unable to fetch weekly leaderboard history", http.StatusBadGateway, err.Error())
 		return
@@ -140,31 +82,12 @@ func (l *LeaderboardController) GetMonthlyLeaderboardHistory(c *gin.Context) {
 		return
 	}
 
-	limit := 12
-	offset := 0
-
-	if limitStr := c.Query("limit"); limitStr != "" {
-		parsed, err := strconv.Atoi(limitStr)
-		if err != nil || parsed < 1 {
-			utils.Fail(c, "Invalid limit parameter", http.StatusBadRequest, "limit must be a positive integer")
-			return
-		}
-		if parsed > 60 {
-			parsed = 60
-		}
-		limit = parsed
-	}
-
-	if offsetStr := c.Query("offset"); offsetStr != "" {
-		parsed, err := strconv.Atoi(offsetStr)
-		if err != nil || parsed < 0 {
-			utils.Fail(c, "Invalid offset parameter", http.StatusBadRequest, "offset must be non-negative")
-			return
-		}
-		offset = parsed
+	page, ok := parsePageRequest(c, 12, 60)
+	if !ok {
+		return
 	}
 
-	resp, err := l.leaderboardService.GetMonthlyLeaderboardHistory(c.Request.Context(), limit, offset)
+	resp, err := l.leaderboardService.GetMonthlyLeaderboardHistory(c.Request.Context(), page.Limit, page.Offset)
 	if err != nil {
 		utils.Fail(c, "Unable to fetch monthly leaderboard history", http.StatusBadGateway, err.Error())
 		return
@@ -199,30 +122,17 @@ func (l *LeaderboardController) GetWeekLeaderboard(c *gin.Context) {
 		return
 	}
 
-	var limit *int
-	if limitStr := c.Query("limit"); limitStr != "" {
-		parsed, err := strconv.Atoi(limitStr)
-		if err != nil || parsed < 1 {
-			utils.Fail(c, "Invalid limit parameter", http.StatusBadRequest, "limit must be a positive integer")
-			return
-		}
-		if parsed > 500 {
-			parsed = 500
-		}
-		limit = &parsed
+	// Without a limit the leaderboard service applies its own default
+	page, ok := parsePageRequest(c, 0, 500)
+	if !ok {
+		return
 	}
-
-	offset := 0
-	if offsetStr := c.Query("offset"); offsetStr != "" {
-		parsed, err := strconv.Atoi(offsetStr)
-		if err != nil || parsed < 0 {
-			utils.Fail(c, "Invalid offset parameter", http.StatusBadRequest, "offset must be non-negative")
-			return
-		}
-		offset = parsed
+	var limit *int
+	if page.Limit > 0 {
+		limit = &page.Limit
 	}
 
-	resp, err := l.leaderboardService.GetWeekLeaderboard(c.Request.Context(), weekKey, limit, offset)
+	resp, err := l.leaderboardService.GetWeekLeaderboard(c.Request.Context(), weekKey, limit, page.Offset)
 	if err != nil {
 		utils.Fail(c, "Unable to fetch week leaderboard", http.StatusBadGateway, err.Error())
 		return
@@ -242,30 +152,17 @@ func (l *LeaderboardController) GetMonthLeaderboard(c *gin.Context) {
 		return
 	}
 
-	var limit *int
-	if limitStr := c.Query("limit"); limitStr != "" {
-		parsed, err := strconv.Atoi(limitStr)
-		if err != nil || parsed < 1 {
-			utils.Fail(c, "Invalid limit parameter", http.StatusBadRequest, "limit must be a positive integer")
-			return
-		}
-		if parsed > 500 {
-			parsed = 500
-		}
-		limit = &parsed
+	// Without a limit the leaderboard service applies its own default
+	page, ok := parsePageRequest(c, 0, 500)
+	if !ok {
+		return
 	}
-
-	offset := 0
-	if offsetStr := c.Query("offset"); offsetStr != "" {
-		parsed, err := strconv.Atoi(offsetStr)
-		if err != nil || parsed < 0 {
-			utils.Fail(c, "Invalid offset parameter", http.StatusBadRequest, "offset must be non-negative")
-			return
-		}
-		offset = parsed
+	var limit *int
+	if page.Limit > 0 {
+		limit = &page.Limit
 	}
 
-	resp, err := l.leaderboardService.GetMonthLeaderboard(c.Request.Context(), monthKey, limit, offset)
+	resp, err := l.leaderboardService.GetMonthLeaderboard(c.Request.Context(), monthKey, limit, page.Offset)
 	if err != nil {
 		utils.Fail(c, "Unable to fetch month leaderboard", http.StatusBadGateway, err.Error())
 		return
@@ -1,14 +1,17 @@
 package controllers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"io"
+	"log/slog"
 	"net/http"
-	"strconv"
 	"strings"
 
 	"bff-services/internal/api/dto"
 	"bff-services/internal/cache"
+	"bff-services/internal/i18n"
 	middleware "bff-services/internal/middlewares"
 	"bff-services/internal/services"
 	"bff-services/internal/types"
@@ -21,6 +24,8 @@ import (
 type LessonController struct {
 	lessonService      services.LessonService
 	streakCacheService *cache.StreakCacheService
+	feedCache          *cache.FeedCache
+	profileEnricher    *services.ProfileEnricher
 }
 
 // NewLessonController constructs a new LessonController.
@@ -36,6 +41,17 @@ func NewLessonControllerWithCache(lessonService services.LessonService, streakCa
 	}
 }
 
+// SetProfileEnricher enables display name and avatar enrichment of leaderboard responses.
+func (l *LessonController) SetProfileEnricher(enricher *services.ProfileEnricher) {
+	l.profileEnricher = enricher
+}
+
+// SetFeedCache enables dropping the cached continue-learning feed when the user's
+// activity or enrollments change.
+func (l *LessonController) SetFeedCache(feedCache *cache.FeedCache) {
+	l.feedCache = feedCache
+}
+
 func (l *LessonController) GetDailyActivityToday(c *gin.Context) {
 	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
 	if !ok {
@@ -57,11 +73,11 @@ func (l *LessonController) GetDailyActivityByDate(c *gin.Context) {
 		return
 	}
 
-	activityDate := c.Param("activity_date")
-	if activityDate == "" {
-		utils.Fail(c, "activity_date is required", http.StatusBadRequest, "missing activity date")
+	var params dto.ActivityDateParam
+	if !bindURI(c, &params) {
 		return
 	}
+	activityDate := params.ActivityDate
 
 	resp, err := l.lessonService.GetDailyActivityByDate(c.Request.Context(), userID, email, sessionID, activityDate)
 	if err != nil {
@@ -78,11 +94,20 @@ func (l *LessonController) GetDailyActivityRange(c *gin.Context) {
 		return
 	}
 
+	var query dto.DateRangeQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+	if query.DateFrom != "" && query.DateTo != "" && query.DateFrom > query.DateTo {
+		utils.Fail(c, "Invalid query parameters", http.StatusBadRequest, "date_from must not be after date_to")
+		return
+	}
+
 	resp, err := l.lessonService.GetDailyActivityRange(
 		c.Request.Context(),
 		userID, email, sessionID,
-		c.Query("date_from"),
-		c.Query("date_to"),
+		query.DateFrom,
+		query.DateTo,
 	)
 	if err != nil {
 		utils.Fail(c, "Unable to fetch activity range", http.StatusBadGateway, err.Error())
@@ -113,6 +138,11 @@ func (l *LessonController) GetDailyActivityMonth(c *gin.Context) {
 		return
 	}
 
+	var query dto.MonthQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
 	resp, err := l.lessonService.GetDailyActivityMonth(
 		c.Request.Context(),
 		userID, email, sessionID,
@@ -159,114 +189,132 @@ func (l *LessonController) IncrementDailyActivity(c *gin.Context) {
 		utils.Fail(c, "Unable to update activity", http.StatusBadGateway, err.Error())
 		return
 	}
+	l.invalidateStreak(c.Request.Context(), userID, resp)
+	l.invalidateFeed(c.Request.Context(), userID, resp)
 
 	respondWithServiceResponse(c, resp)
 }
 
+var (
+	errStreakUnavailable = errors.New("unable to fetch user streak or week activity")
+	errInvalidStreak     = errors.New("invalid streak response")
+	errInvalidWeek       = errors.New("invalid week activity response")
+)
+
 func (l *LessonController) GetMyStreak(c *gin.Context) {
 	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
 	if !ok {
 		return
 	}
-	var cachedStreak *cache.StreakData
-	var cachedActivity []cache.ActivityData
-	var cacheErr error
 
-	if l.streakCacheService != nil {
-		cachedStreak, cacheErr = l.streakCacheService.GetCachedStreak(c.Request.Context(), userID)
-		if cacheErr == nil && cachedStreak != nil {
-			cachedActivity, cacheErr = l.streakCacheService.GetCachedWeekActivity(c.Request.Context(), userID)
-			if cacheErr == nil && cachedActivity != nil {
-				l.respondWithStreakData(c, cachedStreak, cachedActivity)
-				return
-			}
-		}
+	type streakResult struct {
+		data *cache.StreakData
+		err  error
 	}
-
-	type result struct {
-		resp *types.HTTPResponse
+	type weekResult struct {
+		data []cache.ActivityData
 		err  error
 	}
 
-	streakChan := make(chan result, 1)
-	weekActivityChan := make(chan result, 1)
+	streakChan := make(chan streakResult, 1)
+	weekActivityChan := make(chan weekResult, 1)
 
 	go func() {
-		resp, err := l.lessonService.GetMyStreak(c.Request.Context(), userID, email, sessionID)
-		streakChan <- result{resp, err}
+		data, err := l.myStreak(c.Request.Context(), userID, email, sessionID)
+		streakChan <- streakResult{data, err}
 	}()
 
 	go func() {
-		resp, err := l.lessonService.GetDailyActivityWeek(c.Request.Context(), userID, email, sessionID)
-		weekActivityChan <- result{resp, err}
+		data, err := l.myWeekActivity(c.Request.Context(), userID, email, sessionID)
+		weekActivityChan <- weekResult{data, err}
 	}()
 
-	streakResult := <-streakChan
-	weekActivityResult := <-weekActivityChan
-
-	streakResp := streakResult.resp
-	errStreak := streakResult.err
-	weekActivityResp := weekActivityResult.resp
-	errWeek := weekActivityResult.err
+	streak := <-streakChan
+	week := <-weekActivityChan
 
-	if errStreak != nil || errWeek != nil || streakResp == nil || weekActivityResp == nil || streakResp.StatusCode >= 400 || weekActivityResp.StatusCode >= 400 {
+	switch {
+	case streak.err == nil && week.err == nil:
+		l.respondWithStreakData(c, streak.data, week.data)
+	case errors.Is(streak.err, errInvalidStreak) && !errors.Is(week.err, errStreakUnavailable):
+		c.JSON(http.StatusBadGateway, gin.H{"status": "failed", "message": "Invalid streak response"})
+	case errors.Is(week.err, errInvalidWeek) && streak.err == nil:
+		c.JSON(http.StatusBadGateway, gin.H{"status": "failed", "message": "Invalid week activity response"})
+	default:
 		c.JSON(http.StatusBadGateway, gin.H{
 			"status":  "failed",
 			"message": "Unable to fetch user streak or week activity",
 		})
-		return
 	}
+}
 
-	var streakPayload struct {
-		Status string `json:"status"`
-		Data   struct {
-			CurrentLen int     `json:"current_len"`
-			LongestLen int     `json:"longest_len"`
-			LastDay    *string `json:"last_day"`
-		} `json:"data"`
-	}
-	if err := json.Unmarshal(streakResp.Body, &streakPayload); err != nil || strings.ToLower(streakPayload.Status) != "success" {
-		c.JSON(http.StatusBadGateway, gin.H{"status": "failed", "message": "Invalid streak response"})
-		return
+// myStreak returns the streak of the user from the streak cache, loading it from the
+// lesson service on a miss.
+func (l *LessonController) myStreak(ctx context.Context, userID, email, sessionID string) (*cache.StreakData, error) {
+	load := func(ctx context.Context) (*cache.StreakData, error) {
+		resp, err := l.lessonService.GetMyStreak(ctx, userID, email, sessionID)
+		if err != nil || resp == nil || resp.StatusCode >= 400 {
+			return nil, errStreakUnavailable
+		}
+		var payload struct {
+			Status string           `json:"status"`
+			Data   cache.StreakData `json:"data"`
+		}
+		if err := json.Unmarshal(resp.Body, &payload); err != nil || strings.ToLower(payload.Status) != "success" {
+			return nil, errInvalidStreak
+		}
+		return &payload.Data, nil
 	}
 
-	var weekPayload struct {
-		Status string `json:"status"`
-		Data   []struct {
-			ActivityDate     string `json:"activity_dt"`
-			LessonsCompleted int    `json:"lessons_completed"`
-			QuizzesCompleted int    `json:"quizzes_completed"`
-			Minutes          int    `json:"minutes"`
-		} `json:"data"`
-	}
-	if err := json.Unmarshal(weekActivityResp.Body, &weekPayload); err != nil || strings.ToLower(weekPayload.Status) != "success" {
-		c.JSON(http.StatusBadGateway, gin.H{"status": "failed", "message": "Invalid week activity response"})
-		return
+	if l.streakCacheService == nil {
+		return load(ctx)
 	}
+	return l.streakCacheService.Streak(ctx, userID, load)
+}
 
-	// Convert API response to cache format and store in Redis
-	streakData := &cache.StreakData{
-		CurrentLen: streakPayload.Data.CurrentLen,
-		LongestLen: streakPayload.Data.LongestLen,
-		LastDay:    streakPayload.Data.LastDay,
+// myWeekActivity returns the activity of the user over the last week from the streak
+// cache, loading it from the lesson service on a miss.
+func (l *LessonController) myWeekActivity(ctx context.Context, userID, email, sessionID string) ([]cache.ActivityData, error) {
+	load := func(ctx context.Context) ([]cache.ActivityData, error) {
+		resp, err := l.lessonService.GetDailyActivityWeek(ctx, userID, email, sessionID)
+		if err != nil || resp == nil || resp.StatusCode >= 400 {
+			return nil, errStreakUnavailable
+		}
+		var payload struct {
+			Status string               `json:"status"`
+			Data   []cache.ActivityData `json:"data"`
+		}
+		if err := json.Unmarshal(resp.Body, &payload); err != nil || strings.ToLower(payload.Status) != "success" {
+			return nil, errInvalidWeek
+		}
+		return payload.Data, nil
 	}
 
-	activityData := make([]cache.ActivityData, 0, len(weekPayload.Data))
-	for _, d := range weekPayload.Data {
-		activityData = append(activityData, cache.ActivityData{
-			ActivityDate:     d.ActivityDate,
-			LessonsCompleted: d.LessonsCompleted,
-			QuizzesCompleted: d.QuizzesCompleted,
-			Minutes:          d.Minutes,
-		})
+	if l.streakCacheService == nil {
+		return load(ctx)
 	}
+	return l.streakCacheService.WeekActivity(ctx, userID, load)
+}
 
-	if l.streakCacheService != nil {
-		go l.streakCacheService.CacheStreak(c.Request.Context(), userID, streakData)
-		go l.streakCacheService.CacheWeekActivity(c.Request.Context(), userID, activityData)
+// invalidateStreak drops the cached streak and activity of the user once a change was
+// accepted, so GetMyStreak does not serve them until they expire.
+func (l *LessonController) invalidateStreak(ctx context.Context, userID string, resp *types.HTTPResponse) {
+	if l.streakCacheService == nil || resp == nil || resp.StatusCode >= 400 {
+		return
+	}
+	if err := l.streakCacheService.InvalidateAllUserCache(ctx, userID); err != nil {
+		slog.WarnContext(ctx, "failed to invalidate streak cache", "user_id", userID, "error", err)
 	}
+}
 
-	l.respondWithStreakData(c, streakData, activityData)
+// invalidateFeed drops the cached continue-learning feed of the user once a change was
+// accepted, so the next feed shows it.
+func (l *LessonController) invalidateFeed(ctx context.Context, userID string, resp *types.HTTPResponse) {
+	if l.feedCache == nil || resp == nil || resp.StatusCode >= 400 {
+		return
+	}
+	if err := l.feedCache.Invalidate(ctx, userID, i18n.TimezoneFromContext(ctx)); err != nil {
+		slog.WarnContext(ctx, "failed to invalidate continue-learning feed", "user_id", userID, "error", err)
+	}
 }
 
 // respondWithStreakData formats and sends streak response
@@ -317,6 +365,7 @@ func (l *LessonController) CheckMyStreak(c *gin.Context) {
 		utils.Fail(c, "Unable to check streak", http.StatusBadGateway, err.Error())
 		return
 	}
+	l.invalidateStreak(c.Request.Context(), userID, resp)
 
 	respondWithServiceResponse(c, resp)
 }
@@ -342,27 +391,18 @@ func (l *LessonController) GetStreakLeaderboard(c *gin.Context) {
 		return
 	}
 
-	limitStr := c.Query("limit")
-	limit := 0
-	if limitStr != "" {
-		parsed, err := strconv.Atoi(limitStr)
-		if err != nil || parsed < 1 {
-			utils.Fail(c, "Invalid limit parameter", http.StatusBadRequest, "limit must be a positive integer")
-			return
-		}
-		if parsed > 200 {
-			parsed = 200
-		}
-		limit = parsed
+	page, ok := parsePageRequest(c, 50, 200)
+	if !ok {
+		return
 	}
 
-	resp, err := l.lessonService.GetStreakLeaderboard(c.Request.Context(), userID, email, sessionID, limit)
+	resp, err := l.lessonService.GetStreakLeaderboard(c.Request.Context(), userID, email, sessionID, page.Limit, page.Offset)
 	if err != nil {
 		utils.Fail(c, "Unable to fetch streak leaderboard", http.StatusBadGateway, err.Error())
 		return
 	}
 
-	respondWithServiceResponse(c, resp)
+	respondWithEnrichedPage(c, l.profileEnricher, resp, page)
 }
 
 func (l *LessonController) GetStreakByUserID(c *gin.Context) {
@@ -370,13 +410,12 @@ func (l *LessonController) GetStreakByUserID(c *gin.Context) {
 		return
 	}
 
-	targetID := c.Param("user_id")
-	if targetID == "" {
-		utils.Fail(c, "User ID is required", http.StatusBadRequest, "missing user_id path parameter")
+	var params dto.UserIDParam
+	if !bindURI(c, &params) {
 		return
 	}
 
-	resp, err := l.lessonService.GetUserStreak(c.Request.Context(), targetID)
+	resp, err := l.lessonService.GetUserStreak(c.Request.Context(), params.UserID)
 	if err != nil {
 		utils.Fail(c, "Unable to fetch user streak", http.StatusBadGateway, err.Error())
 		return
@@ -390,37 +429,18 @@ func (l *LessonController) GetCurrentWeeklyLeaderboard(c *gin.Context) {
 		return
 	}
 
-	limit := 100
-	offset := 0
-
-	if limitStr := c.Query("limit"); limitStr != "" {
-		parsed, err := strconv.Atoi(limitStr)
-		if err != nil || parsed < 1 {
-			utils.Fail(c, "Invalid limit parameter", http.StatusBadRequest, "limit must be a positive integer")
-			return
-		}
-		if parsed > 500 {
-			parsed = 500
-		}
-		limit = parsed
-	}
-
-	if offsetStr := c.Query("offset"); offsetStr != "" {
-		parsed, err := strconv.Atoi(offsetStr)
-		if err != nil || parsed < 0 {
-			utils.Fail(c, "Invalid offset parameter", http.StatusBadRequest, "offset must be non-negative")
-			return
-		}
-		offset = parsed
+	page, ok := parsePageRequest(c, 100, 500)
+	if !ok {
+		return
 	}
 
-	resp, err := l.lessonService.GetCurrentWeeklyLeaderboard(c.Request.Context(), limit, offset)
+	resp, err := l.lessonService.GetCurrentWeeklyLeaderboard(c.Request.Context(), page.Limit, page.Offset)
 	if err != nil {
 		utils.Fail(c, "Unable to fetch weekly leaderboard", http.StatusBadGateway, err.Error())
 		return
 	}
 
-	respondWithServiceResponse(c, resp)
+	respondWithEnrichedPage(c, l.profileEnricher, resp, page)
 }
 
 func (l *LessonController) GetCurrentMonthlyLeaderboard(c *gin.Context) {
@@ -428,37 +448,18 @@ func (l *LessonController) GetCurrentMonthlyLeaderboard(c *gin.Context) {
 		return
 	}
 
-	limit := 100
-	offset := 0
-
-	if limitStr := c.Query("limit"); limitStr != "" {
-		parsed, err := strconv.Atoi(limitStr)
-		if err != nil || parsed < 1 {
-			utils.Fail(c, "Invalid limit parameter", http.StatusBadRequest, "limit must be a positive integer")
-			return
-		}
-		if parsed > 500 {
-			parsed = 500
-		}
-		limit = parsed
-	}
-
-	if offsetStr := c.Query("offset"); offsetStr != "" {
-		parsed, err := strconv.Atoi(offsetStr)
-		if err != nil || parsed < 0 {
-			utils.Fail(c, "Invalid offset parameter", http.StatusBadRequest, "offset must be non-negative")
-			return
-		}
-		offset = parsed
+	page, ok := parsePageRequest(c, 100, 500)
+	if !ok {
+		return
 	}
 
-	resp, err := l.lessonService.GetCurrentMonthlyLeaderboard(c.Request.Context(), limit, offset)
+	resp, err := l.lessonService.GetCurrentMonthlyLeaderboard(c.Request.Context(), page.Limit, page.Offset)
 	if err != nil {
 		utils.Fail(c, "Unable to fetch monthly leaderboard", http.StatusBadGateway, err.Error())
 		return
 	}
 
-	respondWithServiceResponse(c, resp)
+	respondWithEnrichedPage(c, l.profileEnricher, resp, page)
 }
 
 func (l *LessonController) GetWeeklyLeaderboardHistory(c *gin.Context) {
@@ -466,37 +467,18 @@ func (l *LessonController) GetWeeklyLeaderboardHistory(c *gin.Context) {
 		return
 	}
 
-	limit := 10
-	offset := 0
-
-	if limitStr := c.Query("limit"); limitStr != "" {
-		parsed, err := strconv.Atoi(limitStr)
-		if err != nil || parsed < 1 {
-			utils.Fail(c, "Invalid limit parameter", http.StatusBadRequest, "limit must be a positive integer")
-			return
-		}
-		if parsed > 52 {
-			parsed = 52
-		}
-		limit = parsed
-	}
-
-	if offsetStr := c.Query("offset"); offsetStr != "" {
-		parsed, err := strconv.Atoi(offsetStr)
-		if err != nil || parsed < 0 {
-			utils.Fail(c, "Invalid offset parameter", http.StatusBadRequest, "offset must be non-negative")
-			return
-		}
-		offset = parsed
+	page, ok := parsePageRequest(c, 10, 52)
+	if !ok {
+		return
 	}
 
-	resp, err := l.lessonService.GetWeeklyLeaderboardHistory(c.Request.Context(), limit, offset)
+	resp, err := l.lessonService.GetWeeklyLeaderboardHistory(c.Request.Context(), page.Limit, page.Offset)
 	if err != nil {
 		utils.Fail(c, "Unable to fetch weekly leaderboard history", http.StatusBadGateway, err.Error())
 		return
 	}
 
-	respondWithServiceResponse(c, resp)
+	respondWithEnrichedPage(c, l.profileEnricher, resp, page)
 }
 
 func (l *LessonController) GetMonthlyLeaderboardHistory(c *gin.Context) {
@@ -504,37 +486,18 @@ func (l *LessonController) GetMonthlyLeaderboardHistory(c *gin.Context) {
 		return
 	}
 
-	limit := 12
-	offset := 0
-
-	if limitStr := c.Query("limit"); limitStr != "" {
-		parsed, err := strconv.Atoi(limitStr)
-		if err != nil || parsed < 1 {
-			utils.Fail(c, "Invalid limit parameter", http.StatusBadRequest, "limit must be a positive integer")
-			return
-		}
-		if parsed > 60 {
-			parsed = 60
-		}
-		limit = parsed
-	}
-
-	if offsetStr := c.Query("offset"); offsetStr != "" {
-		parsed, err := strconv.Atoi(offsetStr)
-		if err != nil || parsed < 0 {
-			utils.Fail(c, "Invalid offset parameter", http.StatusBadRequest, "offset must be non-negative")
-			return
-		}
-		offset = parsed
+	page, ok := parsePageRequest(c, 12, 60)
+	if !ok {
+		return
 	}
 
-	resp, err := l.lessonService.GetMonthlyLeaderboardHistory(c.Request.Context(), limit, offset)
+	resp, err := l.lessonService.GetMonthlyLeaderboardHistory(c.Request.Context(), page.Limit, page.Offset)
 	if err != nil {
 		utils.Fail(c, "Unable to fetch monthly leaderboard history", http.StatusBadGateway, err.Error())
 		return
 	}
 
-	respondWithServiceResponse(c, resp)
+	respondWithEnrichedPage(c, l.profileEnricher, resp, page)
 }
 
 func (l *LessonController) GetUserLeaderboardHistory(c *gin.Context) {
@@ -557,42 +520,24 @@ func (l *LessonController) GetWeekLeaderboard(c *gin.Context) {
 		return
 	}
 
-	weekKey := c.Param("week_key")
-	if weekKey == "" {
-		utils.Fail(c, "Week key is required", http.StatusBadRequest, "missing week_key path parameter")
+	var params dto.WeekKeyParam
+	if !bindURI(c, &params) {
 		return
 	}
+	weekKey := params.WeekKey
 
-	var limit *int
-	if limitStr := c.Query("limit"); limitStr != "" {
-		parsed, err := strconv.Atoi(limitStr)
-		if err != nil || parsed < 1 {
-			utils.Fail(c, "Invalid limit parameter", http.StatusBadRequest, "limit must be a positive integer")
-			return
-		}
-		if parsed > 500 {
-			parsed = 500
-		}
-		limit = &parsed
-	}
-
-	offset := 0
-	if offsetStr := c.Query("offset"); offsetStr != "" {
-		parsed, err := strconv.Atoi(offsetStr)
-		if err != nil || parsed < 0 {
-			utils.Fail(c, "Invalid offset parameter", http.StatusBadRequest, "offset must be non-negative")
-			return
-		}
-		offset = parsed
+	page, ok := parsePageRequest(c, 100, 500)
+	if !ok {
+		return
 	}
 
-	resp, err := l.lessonService.GetWeekLeaderboard(c.Request.Context(), weekKey, limit, offset)
+	resp, err := l.lessonService.GetWeekLeaderboard(c.Request.Context(), weekKey, &page.Limit, page.Offset)
 	if err != nil {
 		utils.Fail(c, "Unable to fetch week leaderboard", http.StatusBadGateway, err.Error())
 		return
 	}
 
-	respondWithServiceResponse(c, resp)
+	respondWithEnrichedPage(c, l.profileEnricher, resp, page)
 }
 
 func (l *LessonController) GetMonthLeaderboard(c *gin.Context) {
@@ -600,42 +545,24 @@ func (l *LessonController) GetMonthLeaderboard(c *gin.Context) {
 		return
 	}
 
-	monthKey := c.Param("month_key")
-	if monthKey == "" {
-		utils.Fail(c, "Month key is required", http.StatusBadRequest, "missing month_key path parameter")
+	var params dto.MonthKeyParam
+	if !bindURI(c, &params) {
 		return
 	}
+	monthKey := params.MonthKey
 
-	var limit *int
-	if limitStr := c.Query("limit"); limitStr != "" {
-		parsed, err := strconv.Atoi(limitStr)
-		if err != nil || parsed < 1 {
-			utils.Fail(c, "Invalid limit parameter", http.StatusBadRequest, "limit must be a positive integer")
-			return
-		}
-		if parsed > 500 {
-			parsed = 500
-		}
-		limit = &parsed
-	}
-
-	offset := 0
-	if offsetStr := c.Query("offset"); offsetStr != "" {
-		parsed, err := strconv.Atoi(offsetStr)
-		if err != nil || parsed < 0 {
-			utils.Fail(c, "Invalid offset parameter", http.StatusBadRequest, "offset must be non-negative")
-			return
-		}
-		offset = parsed
+	page, ok := parsePageRequest(c, 100, 500)
+	if !ok {
+		return
 	}
 
-	resp, err := l.lessonService.GetMonthLeaderboard(c.Request.Context(), monthKey, limit, offset)
+	resp, err := l.lessonService.GetMonthLeaderboard(c.Request.Context(), monthKey, &page.Limit, page.Offset)
 	if err != nil {
 		utils.Fail(c, "Unable to fetch month leaderboard", http.StatusBadGateway, err.Error())
 		return
 	}
 
-	respondWithServiceResponse(c, resp)
+	respondWithEnrichedPage(c, l.profileEnricher, resp, page)
 }
 
 func (l *LessonController) ListMyEnrollments(c *gin.Context) {
@@ -644,26 +571,21 @@ func (l *LessonController) ListMyEnrollments(c *gin.Context) {
 		return
 	}
 
-	status := c.Query("status")
-	limit := 0
-	if v := c.Query("limit"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil {
-			limit = n
-		}
+	var query dto.EnrollmentListQuery
+	if !bindQuery(c, &query) {
+		return
 	}
-	offset := 0
-	if v := c.Query("offset"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil {
-			offset = n
-		}
+	page, ok := parsePageRequest(c, 100, 100)
+	if !ok {
+		return
 	}
 
-	resp, err := l.lessonService.ListMyEnrollments(c.Request.Context(), userID, email, sessionID, status, limit, offset)
+	resp, err := l.lessonService.ListMyEnrollments(c.Request.Context(), userID, email, sessionID, query.Status, page.Limit, page.Offset)
 	if err != nil {
 		utils.Fail(c, "Unable to fetch enrollments", http.StatusBadGateway, err.Error())
 		return
 	}
-	respondWithServiceResponse(c, resp)
+	respondWithPage(c, resp, page)
 }
 
 func (l *LessonController) EnrollCourse(c *gin.Context) {
@@ -681,6 +603,7 @@ func (l *LessonController) EnrollCourse(c *gin.Context) {
 		utils.Fail(c, "Unable to enroll", http.StatusBadGateway, err.Error())
 		return
 	}
+	l.invalidateFeed(c.Request.Context(), userID, resp)
 	respondWithServiceResponse(c, resp)
 }
 
@@ -722,6 +645,7 @@ func (l *LessonController) UpdateEnrollment(c *gin.Context) {
 		utils.Fail(c, "Unable to update enrollment", http.StatusBadGateway, err.Error())
 		return
 	}
+	l.invalidateFeed(c.Request.Context(), userID, resp)
 	respondWithServiceResponse(c, resp)
 }
 
@@ -740,6 +664,7 @@ func (l *LessonController) CancelEnrollment(c *gin.Context) {
 		utils.Fail(c, "Unable to cancel enrollment", http.StatusBadGateway, err.Error())
 		return
 	}
+	l.invalidateFeed(c.Request.Context(), userID, resp)
 	respondWithServiceResponse(c, resp)
 }
 
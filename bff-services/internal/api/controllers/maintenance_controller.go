@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"net/http"
+	"sort"
+
+	"bff-services/internal/api/dto"
+	"bff-services/internal/maintenance"
+	"bff-services/internal/utils"
+	"bff-services/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaintenanceController reports and toggles maintenance mode and kill switches.
+type MaintenanceController struct {
+	store *maintenance.Store
+}
+
+// NewMaintenanceController constructs a new MaintenanceController.
+func NewMaintenanceController(store *maintenance.Store) *MaintenanceController {
+	return &MaintenanceController{store: store}
+}
+
+// GetStatus is the public status endpoint clients poll to show a maintenance banner or
+// hide disabled features. It stays reachable during maintenance.
+func (m *MaintenanceController) GetStatus(c *gin.Context) {
+	ctx := c.Request.Context()
+	status := dto.ServiceStatusResponse{Disabled: []string{}}
+
+	if state, on := m.store.Active(ctx, maintenance.Global); on {
+		status.Maintenance = true
+		status.Message = state.Message
+	}
+	for _, name := range m.store.ActiveNames(ctx) {
+		if name != maintenance.Global {
+			status.Disabled = append(status.Disabled, name)
+		}
+	}
+	sort.Strings(status.Disabled)
+
+	c.Header("Cache-Control", "no-store")
+	utils.Success(c, status)
+}
+
+// ListSwitches returns every configured switch, including expired ones.
+func (m *MaintenanceController) ListSwitches(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	utils.Success(c, m.store.States(c.Request.Context()))
+}
+
+// SetSwitch turns a switch on or off.
+func (m *MaintenanceController) SetSwitch(c *gin.Context) {
+	var req dto.MaintenanceSwitchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request payload", http.StatusBadRequest, validation.Describe(err))
+		return
+	}
+
+	state := maintenance.State{
+		Enabled:    *req.Enabled,
+		Message:    req.Message,
+		RetryAfter: req.RetryAfter,
+		Until:      req.Until,
+	}
+	if err := m.store.Set(c.Request.Context(), req.Name, state); err != nil {
+		utils.Fail(c, "Unable to update maintenance switch", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.Success(c, gin.H{"name": req.Name, "state": state})
+}
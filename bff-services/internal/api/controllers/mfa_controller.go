@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"errors"
+	"io"
 	"net/http"
 
 	"bff-services/internal/api/dto"
@@ -98,3 +100,243 @@ func (m *MFAController) Methods(c *gin.Context) {
 
 	respondWithServiceResponse(c, resp)
 }
+
+// BeginPasskeyRegistration returns the options for navigator.credentials.create().
+func (m *MFAController) BeginPasskeyRegistration(c *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	var req dto.PasskeyRegistrationBeginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := m.userService.BeginPasskeyRegistration(c.Request.Context(), userID, email, sessionID, req)
+	if err != nil {
+		utils.Fail(c, "Unable to start passkey registration", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	respondWithServiceResponse(c, resp)
+}
+
+// FinishPasskeyRegistration stores the passkey created by the browser.
+func (m *MFAController) FinishPasskeyRegistration(c *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	var req dto.PasskeyRegistrationFinishRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := m.userService.FinishPasskeyRegistration(c.Request.Context(), userID, email, sessionID, req)
+	if err != nil {
+		utils.Fail(c, "Unable to register passkey", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+// Passkeys lists the caller's passkeys with their nicknames and last use.
+func (m *MFAController) Passkeys(c *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	resp, err := m.userService.ListPasskeys(c.Request.Context(), userID, email, sessionID)
+	if err != nil {
+		utils.Fail(c, "Unable to fetch passkeys", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+// RenamePasskey changes a passkey's device nickname.
+func (m *MFAController) RenamePasskey(c *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	var params dto.PasskeyIDParam
+	if !bindURI(c, &params) {
+		return
+	}
+
+	var req dto.PasskeyRenameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := m.userService.RenamePasskey(c.Request.Context(), userID, email, sessionID, params.ID, req)
+	if err != nil {
+		utils.Fail(c, "Unable to rename passkey", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+// RevokePasskey removes a passkey after re-checking the password.
+func (m *MFAController) RevokePasskey(c *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	var params dto.PasskeyIDParam
+	if !bindURI(c, &params) {
+		return
+	}
+
+	var req dto.PasskeyRevokeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := m.userService.RevokePasskey(c.Request.Context(), userID, email, sessionID, params.ID, req)
+	if err != nil {
+		utils.Fail(c, "Unable to revoke passkey", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+// ReorderMethods sets which MFA method is offered first at login and the fallbacks.
+func (m *MFAController) ReorderMethods(c *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	var req dto.MFAOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := m.userService.ReorderMFAMethods(c.Request.Context(), userID, email, sessionID, req)
+	if err != nil {
+		utils.Fail(c, "Unable to reorder MFA methods", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+// SetupPhone adds a phone number for SMS or voice codes and sends it a confirmation code.
+func (m *MFAController) SetupPhone(c *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	var req dto.PhoneMFASetupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := m.userService.SetupPhoneMFA(c.Request.Context(), userID, email, sessionID, req)
+	if err != nil {
+		utils.Fail(c, "Unable to set up phone MFA", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+// ResendPhoneCode sends a new code to a phone number, optionally as a call instead of SMS.
+func (m *MFAController) ResendPhoneCode(c *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	var params dto.MFAMethodIDParam
+	if !bindURI(c, &params) {
+		return
+	}
+
+	var req dto.OTPSendRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := m.userService.ResendPhoneCode(c.Request.Context(), userID, email, sessionID, params.ID, req)
+	if err != nil {
+		utils.Fail(c, "Unable to send verification code", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+// ConfirmPhone verifies a phone number with the code sent to it.
+func (m *MFAController) ConfirmPhone(c *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	var params dto.MFAMethodIDParam
+	if !bindURI(c, &params) {
+		return
+	}
+
+	var req dto.PhoneMFAConfirmRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := m.userService.ConfirmPhoneMFA(c.Request.Context(), userID, email, sessionID, params.ID, req)
+	if err != nil {
+		utils.Fail(c, "Unable to verify phone number", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+// RevokePhone removes a phone number from MFA after re-checking the password.
+func (m *MFAController) RevokePhone(c *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	var params dto.MFAMethodIDParam
+	if !bindURI(c, &params) {
+		return
+	}
+
+	var req dto.PhoneMFARevokeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := m.userService.RevokePhoneMFA(c.Request.Context(), userID, email, sessionID, params.ID, req)
+	if err != nil {
+		utils.Fail(c, "Unable to remove phone number", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
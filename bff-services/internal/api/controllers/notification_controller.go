@@ -2,12 +2,13 @@ package controllers
 
 import (
 	"net/http"
-	"strconv"
 
 	"bff-services/internal/api/dto"
+	middleware "bff-services/internal/middlewares"
 	"bff-services/internal/services"
 	"bff-services/internal/utils"
 
+	"github.com/ductan2/microservice-app/shared/queryparams"
 	"github.com/gin-gonic/gin"
 )
 
@@ -131,40 +132,28 @@ func (n *NotificationController) GetUserNotifications(c *gin.Context) {
 		return
 	}
 
-	// Parse query parameters
-	limitStr := c.DefaultQuery("limit", "50")
-	offsetStr := c.DefaultQuery("offset", "0")
-	isReadStr := c.Query("is_read")
+	n.listNotifications(c, userID)
+}
 
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		utils.Fail(c, "Invalid limit parameter", http.StatusBadRequest, "limit must be a number")
+func (n *NotificationController) listNotifications(c *gin.Context, userID string) {
+	page, ok := parsePageRequest(c, 50, 100)
+	if !ok {
 		return
 	}
 
-	offset, err := strconv.Atoi(offsetStr)
+	isRead, err := queryparams.Bool(c.Request.URL.Query(), "is_read")
 	if err != nil {
-		utils.Fail(c, "Invalid offset parameter", http.StatusBadRequest, "offset must be a number")
+		failQueryParam(c, err)
 		return
 	}
 
-	var isRead *bool
-	if isReadStr != "" {
-		parsed, err := strconv.ParseBool(isReadStr)
-		if err != nil {
-			utils.Fail(c, "Invalid is_read parameter", http.StatusBadRequest, "is_read must be true or false")
-			return
-		}
-		isRead = &parsed
-	}
-
-	resp, err := n.notificationService.GetUserNotifications(c.Request.Context(), userID, limit, offset, isRead)
+	resp, err := n.notificationService.GetUserNotifications(c.Request.Context(), userID, page.Limit, page.Offset, isRead)
 	if err != nil {
 		utils.Fail(c, "Unable to get user notifications", http.StatusBadGateway, err.Error())
 		return
 	}
 
-	respondWithServiceResponse(c, resp)
+	respondWithPage(c, resp, page)
 }
 
 func (n *NotificationController) MarkNotificationsAsRead(c *gin.Context) {
@@ -174,6 +163,10 @@ func (n *NotificationController) MarkNotificationsAsRead(c *gin.Context) {
 		return
 	}
 
+	n.markAsRead(c, userID)
+}
+
+func (n *NotificationController) markAsRead(c *gin.Context, userID string) {
 	var req dto.MarkAsReadRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.Fail(c, "Invalid request data", http.StatusBadRequest, err.Error())
@@ -207,12 +200,16 @@ func (n *NotificationController) GetUnreadCount(c *gin.Context) {
 
 func (n *NotificationController) DeleteUserNotification(c *gin.Context) {
 	userID := c.Param("userId")
-	notificationID := c.Param("notificationId")
-
 	if userID == "" {
 		utils.Fail(c, "User ID is required", http.StatusBadRequest, "missing user id")
 		return
 	}
+
+	n.deleteNotification(c, userID)
+}
+
+func (n *NotificationController) deleteNotification(c *gin.Context, userID string) {
+	notificationID := c.Param("notificationId")
 	if notificationID == "" {
 		utils.Fail(c, "Notification ID is required", http.StatusBadRequest, "missing notification id")
 		return
@@ -227,6 +224,90 @@ func (n *NotificationController) DeleteUserNotification(c *gin.Context) {
 	respondWithServiceResponse(c, resp)
 }
 
+// ListMyNotifications returns the authenticated user's in-app notifications.
+func (n *NotificationController) ListMyNotifications(c *gin.Context) {
+	userID, _, _, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	n.listNotifications(c, userID)
+}
+
+// MarkMyNotificationsAsRead marks the given notifications of the authenticated user as read.
+func (n *NotificationController) MarkMyNotificationsAsRead(c *gin.Context) {
+	userID, _, _, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	n.markAsRead(c, userID)
+}
+
+// GetMyUnreadCount returns the unread notification count of the authenticated user.
+func (n *NotificationController) GetMyUnreadCount(c *gin.Context) {
+	userID, _, _, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	resp, err := n.notificationService.GetUnreadCount(c.Request.Context(), userID)
+	if err != nil {
+		utils.Fail(c, "Unable to get unread count", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+// DeleteMyNotification removes a notification from the authenticated user's inbox.
+func (n *NotificationController) DeleteMyNotification(c *gin.Context) {
+	userID, _, _, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	n.deleteNotification(c, userID)
+}
+
+// GetMyPreferences returns the authenticated user's notification preferences.
+func (n *NotificationController) GetMyPreferences(c *gin.Context) {
+	userID, _, _, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	resp, err := n.notificationService.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		utils.Fail(c, "Unable to get notification preferences", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+// UpdateMyPreferences updates the authenticated user's notification preferences.
+func (n *NotificationController) UpdateMyPreferences(c *gin.Context) {
+	userID, _, _, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := n.notificationService.UpdatePreferences(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.Fail(c, "Unable to update notification preferences", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
 // Bulk operations
 func (n *NotificationController) SendNotificationToUsers(c *gin.Context) {
 	templateID := c.Param("templateId")
@@ -249,3 +330,92 @@ func (n *NotificationController) SendNotificationToUsers(c *gin.Context) {
 
 	respondWithServiceResponse(c, resp)
 }
+
+// Message template endpoints (admin)
+
+// ListMessageTemplates lists the localized message templates, filtered by type, channel
+// and locale.
+func (n *NotificationController) ListMessageTemplates(c *gin.Context) {
+	var query dto.MessageTemplateListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.Fail(c, "Invalid query parameters", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := n.notificationService.ListMessageTemplates(c.Request.Context(), query)
+	if err != nil {
+		utils.Fail(c, "Unable to list message templates", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+func (n *NotificationController) GetMessageTemplate(c *gin.Context) {
+	resp, err := n.notificationService.GetMessageTemplate(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.Fail(c, "Unable to get message template", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+func (n *NotificationController) CreateMessageTemplate(c *gin.Context) {
+	var req dto.CreateMessageTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := n.notificationService.CreateMessageTemplate(c.Request.Context(), req)
+	if err != nil {
+		utils.Fail(c, "Unable to create message template", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+func (n *NotificationController) UpdateMessageTemplate(c *gin.Context) {
+	var req dto.UpdateMessageTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := n.notificationService.UpdateMessageTemplate(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		utils.Fail(c, "Unable to update message template", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+func (n *NotificationController) DeleteMessageTemplate(c *gin.Context) {
+	resp, err := n.notificationService.DeleteMessageTemplate(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.Fail(c, "Unable to delete message template", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+// PreviewMessageTemplate renders a message template with sample data.
+func (n *NotificationController) PreviewMessageTemplate(c *gin.Context) {
+	var req dto.PreviewMessageTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := n.notificationService.PreviewMessageTemplate(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		utils.Fail(c, "Unable to preview message template", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
@@ -1,11 +1,13 @@
 package controllers
 
 import (
+	"context"
 	"net/http"
 
 	"bff-services/internal/api/dto"
 	middleware "bff-services/internal/middlewares"
 	"bff-services/internal/services"
+	"bff-services/internal/types"
 	"bff-services/internal/utils"
 
 	"github.com/gin-gonic/gin"
@@ -78,13 +80,19 @@ func (o *OrderController) ListOrders(c *gin.Context) {
 		return
 	}
 
+	page, ok := parsePageRequest(c, 20, 100)
+	if !ok {
+		return
+	}
+	query.Limit, query.Offset, query.Page = page.Limit, page.Offset, 0
+
 	resp, err := o.orderService.ListOrders(c.Request.Context(), token, userID, email, sessionID, query)
 	if err != nil {
 		utils.Fail(c, "Unable to list orders", http.StatusBadGateway, err.Error())
 		return
 	}
 
-	respondWithServiceResponse(c, resp)
+	respondWithPage(c, resp, page)
 }
 
 func (o *OrderController) GetOrder(c *gin.Context) {
@@ -156,3 +164,270 @@ func (o *OrderController) CancelOrder(c *gin.Context) {
 
 	respondWithServiceResponse(c, resp)
 }
+
+func (o *OrderController) RequestRefund(c *gin.Context) {
+	if o.orderService == nil {
+		utils.Fail(c, "Order service unavailable", http.StatusServiceUnavailable, "order service not configured")
+		return
+	}
+
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	token := getOptionalBearerToken(c)
+	if token == "" {
+		utils.Fail(c, "Unauthorized", http.StatusUnauthorized, "missing bearer token")
+		return
+	}
+
+	orderID := c.Param("id")
+	if orderID == "" {
+		utils.Fail(c, "Order ID is required", http.StatusBadRequest, "missing order id")
+		return
+	}
+
+	var req dto.RefundOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := o.orderService.RequestRefund(c.Request.Context(), token, userID, email, sessionID, orderID, req)
+	if err != nil {
+		utils.Fail(c, "Unable to request refund", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+func (o *OrderController) ListRefunds(c *gin.Context) {
+	if o.orderService == nil {
+		utils.Fail(c, "Order service unavailable", http.StatusServiceUnavailable, "order service not configured")
+		return
+	}
+
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	token := getOptionalBearerToken(c)
+	if token == "" {
+		utils.Fail(c, "Unauthorized", http.StatusUnauthorized, "missing bearer token")
+		return
+	}
+
+	page, ok := parsePageRequest(c, 20, 100)
+	if !ok {
+		return
+	}
+	query := dto.RefundListQuery{Limit: page.Limit, Offset: page.Offset}
+
+	resp, err := o.orderService.ListRefunds(c.Request.Context(), token, userID, email, sessionID, query)
+	if err != nil {
+		utils.Fail(c, "Unable to list refunds", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithPage(c, resp, page)
+}
+
+// AdminListOrders lists orders across all users. Access is enforced by the admin route group.
+func (o *OrderController) AdminListOrders(c *gin.Context) {
+	if o.orderService == nil {
+		utils.Fail(c, "Order service unavailable", http.StatusServiceUnavailable, "order service not configured")
+		return
+	}
+
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	token := getOptionalBearerToken(c)
+	if token == "" {
+		utils.Fail(c, "Unauthorized", http.StatusUnauthorized, "missing bearer token")
+		return
+	}
+
+	page, ok := parsePageRequest(c, 20, 100)
+	if !ok {
+		return
+	}
+	query := dto.AdminOrderListQuery{
+		Limit:  page.Limit,
+		Offset: page.Offset,
+		UserID: c.Query("user_id"),
+		Status: c.Query("status"),
+	}
+
+	resp, err := o.orderService.AdminListOrders(c.Request.Context(), token, userID, email, sessionID, middleware.GetUserRole(c), query)
+	if err != nil {
+		utils.Fail(c, "Unable to list orders", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithPage(c, resp, page)
+}
+
+// AdminUpdateOrder changes an order's status, failure reason or metadata.
+func (o *OrderController) AdminUpdateOrder(c *gin.Context) {
+	if o.orderService == nil {
+		utils.Fail(c, "Order service unavailable", http.StatusServiceUnavailable, "order service not configured")
+		return
+	}
+
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	token := getOptionalBearerToken(c)
+	if token == "" {
+		utils.Fail(c, "Unauthorized", http.StatusUnauthorized, "missing bearer token")
+		return
+	}
+
+	orderID := c.Param("id")
+	if orderID == "" {
+		utils.Fail(c, "Order ID is required", http.StatusBadRequest, "missing order id")
+		return
+	}
+
+	var req dto.AdminUpdateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := o.orderService.AdminUpdateOrder(c.Request.Context(), token, userID, email, sessionID, middleware.GetUserRole(c), orderID, req)
+	if err != nil {
+		utils.Fail(c, "Unable to update order", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+// AdminGetOrderStats returns aggregate order statistics.
+func (o *OrderController) AdminGetOrderStats(c *gin.Context) {
+	if o.orderService == nil {
+		utils.Fail(c, "Order service unavailable", http.StatusServiceUnavailable, "order service not configured")
+		return
+	}
+
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	token := getOptionalBearerToken(c)
+	if token == "" {
+		utils.Fail(c, "Unauthorized", http.StatusUnauthorized, "missing bearer token")
+		return
+	}
+
+	var query dto.OrderStatsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.Fail(c, "Invalid query parameters", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := o.orderService.AdminGetOrderStats(c.Request.Context(), token, userID, email, sessionID, middleware.GetUserRole(c), query)
+	if err != nil {
+		utils.Fail(c, "Unable to fetch order statistics", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+// AdminListSagas lists the purchase sagas of order-service; stuck=true keeps the
+// failed ones and those running for too long.
+func (o *OrderController) AdminListSagas(c *gin.Context) {
+	if o.orderService == nil {
+		utils.Fail(c, "Order service unavailable", http.StatusServiceUnavailable, "order service not configured")
+		return
+	}
+
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	token := getOptionalBearerToken(c)
+	if token == "" {
+		utils.Fail(c, "Unauthorized", http.StatusUnauthorized, "missing bearer token")
+		return
+	}
+
+	page, ok := parsePageRequest(c, 20, 100)
+	if !ok {
+		return
+	}
+	var query dto.AdminSagaListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.Fail(c, "Invalid query parameters", http.StatusBadRequest, err.Error())
+		return
+	}
+	query.Limit = page.Limit
+	query.Offset = page.Offset
+
+	resp, err := o.orderService.AdminListSagas(c.Request.Context(), token, userID, email, sessionID, middleware.GetUserRole(c), query)
+	if err != nil {
+		utils.Fail(c, "Unable to list sagas", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithPage(c, resp, page)
+}
+
+// AdminGetSaga returns a saga with the state of each of its steps.
+func (o *OrderController) AdminGetSaga(c *gin.Context) {
+	o.adminSagaAction(c, "Unable to fetch saga", services.OrderService.AdminGetSaga)
+}
+
+// AdminRetrySaga runs the failed step, or the failed compensation, of a saga again.
+func (o *OrderController) AdminRetrySaga(c *gin.Context) {
+	o.adminSagaAction(c, "Unable to retry saga", services.OrderService.AdminRetrySaga)
+}
+
+// AdminCompensateSaga undoes the completed steps of a failed or completed saga.
+func (o *OrderController) AdminCompensateSaga(c *gin.Context) {
+	o.adminSagaAction(c, "Unable to compensate saga", services.OrderService.AdminCompensateSaga)
+}
+
+func (o *OrderController) adminSagaAction(c *gin.Context, failure string, action func(svc services.OrderService, ctx context.Context, token, userID, email, sessionID, role, sagaID string) (*types.HTTPResponse, error)) {
+	if o.orderService == nil {
+		utils.Fail(c, "Order service unavailable", http.StatusServiceUnavailable, "order service not configured")
+		return
+	}
+
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	token := getOptionalBearerToken(c)
+	if token == "" {
+		utils.Fail(c, "Unauthorized", http.StatusUnauthorized, "missing bearer token")
+		return
+	}
+
+	sagaID := c.Param("id")
+	if sagaID == "" {
+		utils.Fail(c, "Saga ID is required", http.StatusBadRequest, "missing saga id")
+		return
+	}
+
+	resp, err := action(o.orderService, c.Request.Context(), token, userID, email, sessionID, middleware.GetUserRole(c), sagaID)
+	if err != nil {
+		utils.Fail(c, failure, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
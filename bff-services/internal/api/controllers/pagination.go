@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"bff-services/internal/api/dto"
+	"bff-services/internal/types"
+	"bff-services/internal/utils"
+
+	"github.com/ductan2/microservice-app/shared/queryparams"
+	"github.com/gin-gonic/gin"
+)
+
+// pageRequest is the normalized pagination request parsed from client parameters.
+// It can be translated to limit/offset or page/page_size for each downstream.
+type pageRequest = queryparams.Page
+
+// parsePageRequest reads `limit` and `cursor` from the query string. The legacy
+// `offset`, `page` and `page_size` parameters are still honoured when no cursor is sent.
+// It writes a 400 response and returns false on invalid input.
+func parsePageRequest(c *gin.Context, defaultLimit, maxLimit int) (pageRequest, bool) {
+	page, err := queryparams.ParsePage(c.Request.URL.Query(), queryparams.PageOptions{DefaultLimit: defaultLimit, MaxLimit: maxLimit})
+	if err != nil {
+		failQueryParam(c, err)
+		return page, false
+	}
+	return page, true
+}
+
+// failQueryParam writes the 400 response for a query parameter queryparams rejected.
+func failQueryParam(c *gin.Context, err error) {
+	if qerr, ok := queryparams.AsError(err); ok {
+		utils.Fail(c, qerr.Message(), http.StatusBadRequest, qerr.Reason)
+		return
+	}
+	utils.Fail(c, "Invalid query parameters", http.StatusBadRequest, err.Error())
+}
+
+// respondWithPage converts a downstream list response into dto.PageEnvelope. Error
+// responses and bodies without a recognizable list are forwarded unchanged.
+func respondWithPage(c *gin.Context, resp *types.HTTPResponse, req pageRequest) {
+	if resp == nil || resp.StatusCode != http.StatusOK || len(resp.Body) == 0 {
+		respondWithServiceResponse(c, resp)
+		return
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(resp.Body, &document); err != nil {
+		respondWithServiceResponse(c, resp)
+		return
+	}
+	respondWithPageDocument(c, resp, document, req)
+}
+
+// respondWithPageDocument is respondWithPage for the decoded body of resp.
+func respondWithPageDocument(c *gin.Context, resp *types.HTTPResponse, document interface{}, req pageRequest) {
+	items, total, ok := extractPage(document)
+	if !ok {
+		respondWithServiceResponse(c, resp)
+		return
+	}
+
+	utils.Success(c, newPageEnvelope(items, len(items), total, req))
+}
+
+// newPageEnvelope builds the envelope for a page of count items, deriving the next
+// cursor from the total when known and from a full page otherwise.
+func newPageEnvelope(items interface{}, count int, total *int64, req pageRequest) dto.PageEnvelope {
+	envelope := dto.PageEnvelope{Items: items, Total: total}
+	hasMore := count >= req.Limit
+	if total != nil {
+		hasMore = int64(req.Offset+count) < *total
+	}
+	if hasMore && count > 0 {
+		next := queryparams.EncodeCursor(req.Offset + count)
+		envelope.NextCursor = &next
+	}
+	return envelope
+}
+
+// extractPage locates the list and total count in the common downstream shapes:
+// a bare array, {data: [...]}, {data: {<name>: [...], total}}, {items, total},
+// and totals nested under meta/pagination blocks.
+func extractPage(document interface{}) ([]interface{}, *int64, bool) {
+	switch v := document.(type) {
+	case []interface{}:
+		return v, nil, true
+	case map[string]interface{}:
+		total := findTotal(v)
+		if data, exists := v["data"]; exists {
+			if items, nested, ok := extractPage(data); ok {
+				if nested != nil {
+					total = nested
+				}
+				return items, total, true
+			}
+		}
+		for _, key := range []string{"items", "results", "records"} {
+			if items, ok := v[key].([]interface{}); ok {
+				return items, total, true
+			}
+		}
+		// Fall back to the only array-valued field, e.g. {"orders": [...]}
+		var found []interface{}
+		count := 0
+		for _, value := range v {
+			if items, ok := value.([]interface{}); ok {
+				found = items
+				count++
+			}
+		}
+		if count == 1 {
+			return found, total, true
+		}
+	}
+	return nil, nil, false
+}
+
+func findTotal(v map[string]interface{}) *int64 {
+	for _, key := range []string{"total", "total_count", "totalCount", "count"} {
+		if n, ok := v[key].(float64); ok {
+			total := int64(n)
+			return &total
+		}
+	}
+	for _, key := range []string{"meta", "pagination"} {
+		if nested, ok := v[key].(map[string]interface{}); ok {
+			if total := findTotal(nested); total != nil {
+				return total
+			}
+		}
+	}
+	return nil
+}
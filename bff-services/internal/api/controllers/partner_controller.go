@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"bff-services/internal/api/dto"
+	"bff-services/internal/apikeys"
+	middleware "bff-services/internal/middlewares"
+	"bff-services/internal/services"
+	"bff-services/internal/utils"
+	"bff-services/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// partnerExportEnrollmentLimit caps the enrollments included in a progress export.
+const partnerExportEnrollmentLimit = 500
+
+// PartnerController serves partner integrations authenticated by API key, and the admin
+// endpoints that manage those keys.
+type PartnerController struct {
+	lessonService services.LessonService
+	keyStore      *apikeys.Store
+}
+
+// NewPartnerController constructs a new PartnerController.
+func NewPartnerController(lessonService services.LessonService, keyStore *apikeys.Store) *PartnerController {
+	return &PartnerController{
+		lessonService: lessonService,
+		keyStore:      keyStore,
+	}
+}
+
+// IssueKey creates a partner API key. The plaintext key is only returned in this response.
+func (p *PartnerController) IssueKey(c *gin.Context) {
+	userID, _, _, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	var req dto.IssueAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request payload", http.StatusBadRequest, validation.Describe(err))
+		return
+	}
+
+	plaintext, key, err := p.keyStore.Issue(c.Request.Context(), apikeys.IssueParams{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		RateLimit: req.RateLimit,
+		CreatedBy: userID,
+	})
+	if err != nil {
+		utils.Fail(c, "Unable to issue API key", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	utils.Created(c, dto.IssuedAPIKeyResponse{APIKey: plaintext, Key: key})
+}
+
+// ListKeys returns every issued partner key without its secret.
+func (p *PartnerController) ListKeys(c *gin.Context) {
+	keys, err := p.keyStore.List(c.Request.Context())
+	if err != nil {
+		utils.Fail(c, "Unable to list API keys", http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.Success(c, keys)
+}
+
+// RevokeKey revokes a partner key. Requests using it are rejected immediately.
+func (p *PartnerController) RevokeKey(c *gin.Context) {
+	var param dto.APIKeyIDParam
+	if !bindURI(c, &param) {
+		return
+	}
+
+	key, err := p.keyStore.Revoke(c.Request.Context(), param.ID)
+	if errors.Is(err, apikeys.ErrKeyNotFound) {
+		utils.Fail(c, "API key not found", http.StatusNotFound, nil)
+		return
+	}
+	if err != nil {
+		utils.Fail(c, "Unable to revoke API key", http.StatusInternalServerError, err.Error())
+		return
+	}
+	utils.Success(c, key)
+}
+
+// ProvisionEnrollment enrolls a user in a course on behalf of a partner school.
+func (p *PartnerController) ProvisionEnrollment(c *gin.Context) {
+	key, ok := middleware.GetPartnerKey(c)
+	if !ok {
+		utils.Fail(c, "API key required", http.StatusUnauthorized, nil)
+		return
+	}
+
+	var req dto.PartnerEnrollmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request payload", http.StatusBadRequest, validation.Describe(err))
+		return
+	}
+
+	// The lesson service identifies the acting user by internal headers; the key ID stands
+	// in for the session so downstream logs can be traced back to the partner.
+	resp, err := p.lessonService.EnrollCourse(c.Request.Context(), req.UserID, "", key.ID, dto.CourseEnrollmentCreate{CourseID: req.CourseID})
+	if err != nil {
+		utils.Fail(c, "Unable to enroll", http.StatusBadGateway, err.Error())
+		return
+	}
+	respondWithServiceResponse(c, resp)
+}
+
+// ExportProgress returns a user's enrollments, lesson statistics and points.
+func (p *PartnerController) ExportProgress(c *gin.Context) {
+	key, ok := middleware.GetPartnerKey(c)
+	if !ok {
+		utils.Fail(c, "API key required", http.StatusUnauthorized, nil)
+		return
+	}
+
+	var param dto.UserIDParam
+	if !bindURI(c, &param) {
+		return
+	}
+	userID := param.UserID
+
+	export := dto.PartnerProgressExport{UserID: userID}
+	g, ctx := errgroup.WithContext(c.Request.Context())
+
+	g.Go(func() error {
+		resp, err := p.lessonService.ListMyEnrollments(ctx, userID, "", key.ID, "", partnerExportEnrollmentLimit, 0)
+		if err != nil {
+			return fmt.Errorf("enrollments request: %w", err)
+		}
+		data, err := decodeServiceResponse[json.RawMessage](resp)
+		if err != nil {
+			return fmt.Errorf("enrollments decode: %w", err)
+		}
+		export.Enrollments = *data
+		return nil
+	})
+
+	g.Go(func() error {
+		resp, err := p.lessonService.GetUserLessonStats(ctx, userID, "", key.ID)
+		if err != nil {
+			return fmt.Errorf("lesson stats request: %w", err)
+		}
+		data, err := decodeServiceResponse[dto.UserLessonStatsResponse](resp)
+		if err != nil {
+			return fmt.Errorf("lesson stats decode: %w", err)
+		}
+		export.LessonStats = *data
+		return nil
+	})
+
+	g.Go(func() error {
+		resp, err := p.lessonService.GetUserPoints(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("user points request: %w", err)
+		}
+		data, err := decodeServiceResponse[dto.UserPointsResponse](resp)
+		if err != nil {
+			return fmt.Errorf("user points decode: %w", err)
+		}
+		export.Points = *data
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		utils.Fail(c, "Unable to export progress", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	export.ExportedAt = time.Now().UTC()
+	utils.Success(c, export)
+}
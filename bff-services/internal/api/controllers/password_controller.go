@@ -28,7 +28,7 @@ func (p *PasswordController) RequestReset(c *gin.Context) {
 		return
 	}
 
-	resp, err := p.userService.RequestPasswordReset(c.Request.Context(), req)
+	resp, err := p.userService.RequestPasswordReset(c.Request.Context(), req, c.ClientIP())
 	if err != nil {
 		utils.Fail(c, "Unable to initiate password reset", http.StatusBadGateway, err.Error())
 		return
@@ -180,13 +180,19 @@ func (p *PaymentController) GetPaymentHistory(c *gin.Context) {
 		return
 	}
 
+	page, ok := parsePageRequest(c, 20, 100)
+	if !ok {
+		return
+	}
+	query.Limit, query.Offset, query.Page = page.Limit, page.Offset, 0
+
 	resp, err := p.paymentService.GetPaymentHistory(c.Request.Context(), token, userID, email, sessionID, query)
 	if err != nil {
 		utils.Fail(c, "Unable to fetch payment history", http.StatusBadGateway, err.Error())
 		return
 	}
 
-	respondWithServiceResponse(c, resp)
+	respondWithPage(c, resp, page)
 }
 
 func (p *PaymentController) GetStripeConfig(c *gin.Context) {
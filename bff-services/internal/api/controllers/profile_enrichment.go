@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"bff-services/internal/cache"
+	"bff-services/internal/services"
+	"bff-services/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondWithEnrichedPage merges display names and avatars into every JSON object
+// carrying a "user_id" field of a downstream list response, then responds with its page
+// like respondWithPage.
+func respondWithEnrichedPage(c *gin.Context, enricher *services.ProfileEnricher, resp *types.HTTPResponse, req pageRequest) {
+	if enricher == nil || resp == nil || resp.StatusCode != http.StatusOK || len(resp.Body) == 0 {
+		respondWithPage(c, resp, req)
+		return
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(resp.Body, &document); err != nil {
+		respondWithServiceResponse(c, resp)
+		return
+	}
+
+	var ids []string
+	collectUserIDs(document, &ids)
+	if len(ids) > 0 {
+		mergeProfiles(document, enricher.Resolve(c.Request.Context(), ids))
+	}
+	respondWithPageDocument(c, resp, document, req)
+}
+
+func collectUserIDs(node interface{}, ids *[]string) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if id, ok := v["user_id"].(string); ok && id != "" {
+			*ids = append(*ids, id)
+		}
+		for _, child := range v {
+			collectUserIDs(child, ids)
+		}
+	case []interface{}:
+		for _, child := range v {
+			collectUserIDs(child, ids)
+		}
+	}
+}
+
+func mergeProfiles(node interface{}, profiles map[string]cache.ProfileSnippet) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if id, ok := v["user_id"].(string); ok {
+			if profile, found := profiles[id]; found {
+				if _, exists := v["display_name"]; !exists {
+					v["display_name"] = profile.DisplayName
+				}
+				if _, exists := v["avatar_url"]; !exists {
+					v["avatar_url"] = profile.AvatarURL
+				}
+			}
+		}
+		for _, child := range v {
+			mergeProfiles(child, profiles)
+		}
+	case []interface{}:
+		for _, child := range v {
+			mergeProfiles(child, profiles)
+		}
+	}
+}
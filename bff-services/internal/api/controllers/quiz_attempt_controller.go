@@ -2,13 +2,13 @@ package controllers
 
 import (
 	"net/http"
-	"strconv"
 
 	"bff-services/internal/api/dto"
 	middleware "bff-services/internal/middlewares"
 	"bff-services/internal/services"
 	"bff-services/internal/utils"
 
+	"github.com/ductan2/microservice-app/shared/queryparams"
 	"github.com/gin-gonic/gin"
 )
 
@@ -120,41 +120,18 @@ func (q *QuizAttemptController) GetUserQuizHistory(c *gin.Context) {
 		return
 	}
 
-	limitStr := c.DefaultQuery("limit", "50")
-	offsetStr := c.DefaultQuery("offset", "0")
-
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		utils.Fail(c, "Invalid limit parameter", http.StatusBadRequest, "limit must be a number")
-		return
-	}
-	if limit < 1 || limit > 200 {
-		utils.Fail(c, "Invalid limit parameter", http.StatusBadRequest, "limit must be between 1 and 200")
+	page, ok := parsePageRequest(c, 50, 200)
+	if !ok {
 		return
 	}
 
-	offset, err := strconv.Atoi(offsetStr)
+	passed, err := queryparams.Bool(c.Request.URL.Query(), "passed")
 	if err != nil {
-		utils.Fail(c, "Invalid offset parameter", http.StatusBadRequest, "offset must be a number")
+		failQueryParam(c, err)
 		return
 	}
-	if offset < 0 {
-		utils.Fail(c, "Invalid offset parameter", http.StatusBadRequest, "offset cannot be negative")
-		return
-	}
-
-	passedParam := c.Query("passed")
-	var passed *bool
-	if passedParam != "" {
-		value, err := strconv.ParseBool(passedParam)
-		if err != nil {
-			utils.Fail(c, "Invalid passed parameter", http.StatusBadRequest, "passed must be true or false")
-			return
-		}
-		passed = &value
-	}
 
-	resp, err := q.quizAttemptService.GetUserQuizHistory(c.Request.Context(), userID, email, sessionID, passed, limit, offset)
+	resp, err := q.quizAttemptService.GetUserQuizHistory(c.Request.Context(), userID, email, sessionID, passed, page.Limit, page.Offset)
 	if err != nil {
 		utils.Fail(c, "Unable to fetch quiz history", http.StatusBadGateway, err.Error())
 		return
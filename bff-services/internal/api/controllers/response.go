@@ -16,6 +16,11 @@ func respondWithServiceResponse(c *gin.Context, resp *types.HTTPResponse) {
 		return
 	}
 
+	if resp.StatusCode >= http.StatusBadRequest {
+		respondWithServiceError(c, resp)
+		return
+	}
+
 	if resp.Headers != nil {
 		// Forward upstream headers except those managed by Gin automatically.
 		for key, values := range resp.Headers {
@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"net/http"
+
+	"bff-services/internal/api/dto"
+	"bff-services/internal/services"
+	"bff-services/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchController proxies catalog searches to the search service.
+type SearchController struct {
+	searchService services.SearchService
+}
+
+// NewSearchController constructs a new SearchController.
+func NewSearchController(searchService services.SearchService) *SearchController {
+	return &SearchController{
+		searchService: searchService,
+	}
+}
+
+// Search finds the published courses, lessons and flashcard sets matching the query,
+// with facet counts by type, topic and level. Validation errors come from search-services.
+func (sc *SearchController) Search(c *gin.Context) {
+	if sc.searchService == nil {
+		utils.Fail(c, "Search service unavailable", http.StatusServiceUnavailable, "search service not configured")
+		return
+	}
+
+	resp, err := sc.searchService.Search(c.Request.Context(), c.Request.URL.Query())
+	if err != nil {
+		utils.Fail(c, "Unable to search content", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+// Suggest completes the prefix typed in the search box with course and lesson titles and
+// glossary terms. Validation errors come from search-services.
+func (sc *SearchController) Suggest(c *gin.Context) {
+	if sc.searchService == nil {
+		utils.Fail(c, "Search service unavailable", http.StatusServiceUnavailable, "search service not configured")
+		return
+	}
+
+	resp, err := sc.searchService.Suggest(c.Request.Context(), c.Request.URL.Query())
+	if err != nil {
+		utils.Fail(c, "Unable to suggest content", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+// Synonym rule endpoints (admin)
+
+// ListSynonyms lists the search dictionary: the built-in rules, then those admins added.
+func (sc *SearchController) ListSynonyms(c *gin.Context) {
+	resp, err := sc.searchService.ListSynonyms(c.Request.Context())
+	if err != nil {
+		utils.Fail(c, "Unable to list synonym rules", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+func (sc *SearchController) GetSynonym(c *gin.Context) {
+	resp, err := sc.searchService.GetSynonym(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.Fail(c, "Unable to get synonym rule", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+func (sc *SearchController) CreateSynonym(c *gin.Context) {
+	var req dto.SynonymRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := sc.searchService.CreateSynonym(c.Request.Context(), req)
+	if err != nil {
+		utils.Fail(c, "Unable to create synonym rule", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+func (sc *SearchController) UpdateSynonym(c *gin.Context) {
+	var req dto.SynonymRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := sc.searchService.UpdateSynonym(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		utils.Fail(c, "Unable to update synonym rule", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+func (sc *SearchController) DeleteSynonym(c *gin.Context) {
+	resp, err := sc.searchService.DeleteSynonym(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.Fail(c, "Unable to delete synonym rule", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"bff-services/internal/types"
+	"bff-services/internal/utils"
+
+	"github.com/ductan2/microservice-app/shared/apperr"
+	"github.com/gin-gonic/gin"
+)
+
+// maxPlainErrorLength bounds non-JSON downstream error bodies echoed back as the message.
+const maxPlainErrorLength = 200
+
+// respondWithServiceError translates a downstream error response into the BFF error
+// envelope. Each service reports errors differently:
+//
+//	user-service:          {status, message, error_code, details}
+//	order-service:         {success: false, error: {code, message, details}}
+//	lesson-service:        {status, message, error} or FastAPI's {detail}
+//	notification-service:  {error, details} or {success: false, message}
+//
+// Client errors keep their status; downstream server errors become 502 (503 and 504 are
+// kept) and their details are logged rather than exposed.
+func respondWithServiceError(c *gin.Context, resp *types.HTTPResponse) {
+	status := mapServiceErrorStatus(resp.StatusCode)
+	code, message, details := parseServiceError(resp.Body)
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		slog.ErrorContext(c.Request.Context(), "downstream error", "status", resp.StatusCode, "method", c.Request.Method, "path", c.Request.URL.Path, "body", truncate(string(resp.Body), 1000))
+		code, details = "", nil
+		message = "Upstream service error"
+		if status == http.StatusServiceUnavailable {
+			message = "Service temporarily unavailable"
+		}
+	}
+
+	if code == "" {
+		code = string(apperr.CodeForHTTPStatus(status))
+	}
+	if message == "" {
+		message = http.StatusText(status)
+	}
+
+	if retryAfter := resp.Headers.Get("Retry-After"); retryAfter != "" {
+		c.Header("Retry-After", retryAfter)
+	}
+	if status == http.StatusUnauthorized {
+		if challenge := resp.Headers.Get("WWW-Authenticate"); challenge != "" {
+			c.Header("WWW-Authenticate", challenge)
+		}
+	}
+
+	utils.FailWithCode(c, message, status, code, details)
+}
+
+func mapServiceErrorStatus(status int) int {
+	switch {
+	case status < http.StatusInternalServerError:
+		return status
+	case status == http.StatusServiceUnavailable, status == http.StatusGatewayTimeout:
+		return status
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// parseServiceError extracts the error code, message and details from any of the
+// downstream error formats. Missing parts are returned empty.
+func parseServiceError(body []byte) (code, message string, details interface{}) {
+	var document map[string]interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		text := strings.TrimSpace(string(body))
+		if len(text) <= maxPlainErrorLength {
+			message = text
+		}
+		return "", message, nil
+	}
+
+	switch errValue := document["error"].(type) {
+	case map[string]interface{}:
+		code = stringField(errValue, "code")
+		message = stringField(errValue, "message")
+		details = errValue["details"]
+	case string:
+		if message == "" {
+			message = errValue
+		}
+	case nil:
+	default:
+		details = errValue
+	}
+
+	if code == "" {
+		code = firstNonEmpty(stringField(document, "error_code"), stringField(document, "code"))
+	}
+	if msg := stringField(document, "message"); msg != "" {
+		// A top-level message is the most specific text when the error field carried only details.
+		if message == "" || details != nil {
+			message = msg
+		}
+	}
+
+	switch detail := document["detail"].(type) {
+	case string:
+		if message == "" {
+			message = detail
+		}
+	case nil:
+	default:
+		if details == nil {
+			details = detail
+		}
+	}
+
+	if details == nil {
+		if v, ok := document["details"]; ok {
+			details = v
+		} else if v, ok := document["errors"]; ok {
+			details = v
+		}
+	}
+
+	return normalizeErrorCode(code), message, details
+}
+
+func stringField(m map[string]interface{}, key string) string {
+	if v, ok := m[key].(string); ok {
+		return strings.TrimSpace(v)
+	}
+	return ""
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// normalizeErrorCode turns codes like "session-not-found" into "SESSION_NOT_FOUND".
+func normalizeErrorCode(code string) string {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return ""
+	}
+	return strings.ToUpper(strings.NewReplacer("-", "_", " ", "_", ".", "_").Replace(code))
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bff-services/internal/types"
+	"bff-services/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestRespondWithServiceErrorContract feeds every documented downstream error shape
+// through the translator and checks the resulting BFF envelope.
+func TestRespondWithServiceErrorContract(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		name        string
+		status      int
+		header      http.Header
+		body        string
+		wantStatus  int
+		wantCode    string
+		wantMessage string
+		wantDetails bool
+		wantHeaders map[string]string
+	}{
+		{
+			name:        "user-service",
+			status:      http.StatusBadRequest,
+			body:        `{"status":"error","message":"invalid input","error_code":"validation-failed","details":{"email":"required"}}`,
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    "VALIDATION_FAILED",
+			wantMessage: "invalid input",
+			wantDetails: true,
+		},
+		{
+			name:        "order-service",
+			status:      http.StatusNotFound,
+			body:        `{"success":false,"error":{"code":"ORDER_NOT_FOUND","message":"order not found","details":{"order_id":"o-1"}}}`,
+			wantStatus:  http.StatusNotFound,
+			wantCode:    "ORDER_NOT_FOUND",
+			wantMessage: "order not found",
+			wantDetails: true,
+		},
+		{
+			name:        "lesson-service",
+			status:      http.StatusConflict,
+			body:        `{"status":"error","message":"Conflict","error":"Already enrolled in this course"}`,
+			wantStatus:  http.StatusConflict,
+			wantCode:    "CONFLICT",
+			wantMessage: "Already enrolled in this course",
+		},
+		{
+			name:        "fastapi detail string",
+			status:      http.StatusNotFound,
+			body:        `{"detail":"Enrollment not found"}`,
+			wantStatus:  http.StatusNotFound,
+			wantCode:    "NOT_FOUND",
+			wantMessage: "Enrollment not found",
+		},
+		{
+			name:        "fastapi validation detail",
+			status:      http.StatusUnprocessableEntity,
+			body:        `{"detail":[{"loc":["body","limit"],"msg":"value is not a valid integer"}]}`,
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantCode:    "VALIDATION_FAILED",
+			wantMessage: "Unprocessable Entity",
+			wantDetails: true,
+		},
+		{
+			name:        "notification-service error",
+			status:      http.StatusBadRequest,
+			body:        `{"error":"Invalid preference","details":[{"path":"channel"}]}`,
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    "BAD_REQUEST",
+			wantMessage: "Invalid preference",
+			wantDetails: true,
+		},
+		{
+			name:        "notification-service success false",
+			status:      http.StatusForbidden,
+			body:        `{"success":false,"message":"Not your notification"}`,
+			wantStatus:  http.StatusForbidden,
+			wantCode:    "FORBIDDEN",
+			wantMessage: "Not your notification",
+		},
+		{
+			name:        "plain text",
+			status:      http.StatusTooManyRequests,
+			header:      http.Header{"Retry-After": {"30"}},
+			body:        "slow down",
+			wantStatus:  http.StatusTooManyRequests,
+			wantCode:    "RATE_LIMITED",
+			wantMessage: "slow down",
+			wantHeaders: map[string]string{"Retry-After": "30"},
+		},
+		{
+			name:        "unauthorized challenge",
+			status:      http.StatusUnauthorized,
+			header:      http.Header{"Www-Authenticate": {`Bearer realm="api"`}},
+			body:        `{"status":"error","message":"token expired","error_code":"TOKEN_EXPIRED"}`,
+			wantStatus:  http.StatusUnauthorized,
+			wantCode:    "TOKEN_EXPIRED",
+			wantMessage: "token expired",
+			wantHeaders: map[string]string{"WWW-Authenticate": `Bearer realm="api"`},
+		},
+		{
+			name:        "server error is masked",
+			status:      http.StatusInternalServerError,
+			body:        `{"status":"error","message":"pq: relation users does not exist","error_code":"DB_ERROR"}`,
+			wantStatus:  http.StatusBadGateway,
+			wantCode:    "UPSTREAM_ERROR",
+			wantMessage: "Upstream service error",
+		},
+		{
+			name:        "unavailable is kept",
+			status:      http.StatusServiceUnavailable,
+			body:        "upstream connect error",
+			wantStatus:  http.StatusServiceUnavailable,
+			wantCode:    "SERVICE_UNAVAILABLE",
+			wantMessage: "Service temporarily unavailable",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/test", nil)
+
+			header := tc.header
+			if header == nil {
+				header = http.Header{}
+			}
+			respondWithServiceError(c, &types.HTTPResponse{StatusCode: tc.status, Body: []byte(tc.body), Headers: header})
+
+			if recorder.Code != tc.wantStatus {
+				t.Errorf("status: want %d, got %d", tc.wantStatus, recorder.Code)
+			}
+
+			var envelope struct {
+				Status  string          `json:"status"`
+				Message string          `json:"message"`
+				Error   utils.ErrorBody `json:"error"`
+			}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &envelope); err != nil {
+				t.Fatalf("response is not a JSON envelope: %v (%s)", err, recorder.Body.String())
+			}
+			if envelope.Status != "error" {
+				t.Errorf("status field: want error, got %q", envelope.Status)
+			}
+			if envelope.Error.Code != tc.wantCode {
+				t.Errorf("code: want %q, got %q", tc.wantCode, envelope.Error.Code)
+			}
+			if envelope.Error.Message != tc.wantMessage || envelope.Message != tc.wantMessage {
+				t.Errorf("message: want %q, got %q / %q", tc.wantMessage, envelope.Message, envelope.Error.Message)
+			}
+			if (envelope.Error.Details != nil) != tc.wantDetails {
+				t.Errorf("details: want present=%v, got %v", tc.wantDetails, envelope.Error.Details)
+			}
+			for key, want := range tc.wantHeaders {
+				if got := recorder.Header().Get(key); got != want {
+					t.Errorf("header %s: want %q, got %q", key, want, got)
+				}
+			}
+		})
+	}
+}
@@ -1,8 +1,13 @@
 package controllers
 
 import (
+	"context"
+	"encoding/json"
+	"log/slog"
 	"net/http"
+	"sync"
 
+	"bff-services/internal/api/dto"
 	middleware "bff-services/internal/middlewares"
 	"bff-services/internal/services"
 	"bff-services/internal/utils"
@@ -12,12 +17,13 @@ import (
 
 // SessionController handles user session management operations.
 type SessionController struct {
-	userService services.UserService
+	userService  services.UserService
+	geoIPService services.GeoIPService
 }
 
 // NewSessionController constructs a new SessionController.
-func NewSessionController(userService services.UserService) *SessionController {
-	return &SessionController{userService: userService}
+func NewSessionController(userService services.UserService, geoIPService services.GeoIPService) *SessionController {
+	return &SessionController{userService: userService, geoIPService: geoIPService}
 }
 
 func (s *SessionController) List(c *gin.Context) {
@@ -91,3 +97,98 @@ func (s *SessionController) ListByUserID(c *gin.Context) {
 
 	respondWithServiceResponse(c, resp)
 }
+
+// ListDevices returns the caller's active sessions with their device details and
+// approximate location, as recorded by user-services when each session was created.
+// Sessions recorded without a location are looked up from their IP address.
+func (s *SessionController) ListDevices(c *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	resp, err := s.userService.GetSessions(c.Request.Context(), userID, email, sessionID)
+	if err != nil {
+		utils.Fail(c, "Unable to fetch sessions", http.StatusBadGateway, err.Error())
+		return
+	}
+	if resp.StatusCode != http.StatusOK {
+		respondWithServiceResponse(c, resp)
+		return
+	}
+
+	var sessionsResponse struct {
+		Data []dto.UpstreamSession `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body, &sessionsResponse); err != nil {
+		utils.Fail(c, "Unable to fetch sessions", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	devices := make([]dto.DeviceSessionResponse, len(sessionsResponse.Data))
+	var wg sync.WaitGroup
+	for i, session := range sessionsResponse.Data {
+		devices[i] = dto.DeviceSessionResponse{
+			ID:         session.ID,
+			Label:      session.Label,
+			Browser:    session.Browser,
+			OS:         session.OS,
+			DeviceType: session.DeviceType,
+			UserAgent:  session.UserAgent,
+			LastIP:     session.IPAddr,
+			Location:   session.Location,
+			CreatedAt:  session.CreatedAt,
+			ExpiresAt:  session.ExpiresAt,
+			IsCurrent:  session.ID == sessionID,
+		}
+		// Older user-services releases do not describe sessions themselves
+		if session.DeviceType == "" {
+			info := utils.ParseUserAgent(session.UserAgent)
+			devices[i].Browser, devices[i].OS, devices[i].DeviceType = info.Browser, info.OS, info.DeviceType
+		}
+
+		if session.Location != nil || session.IPAddr == nil || s.geoIPService == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(index int, ip string) {
+			defer wg.Done()
+			devices[index].Location = s.lookupLocation(c.Request.Context(), ip)
+		}(i, *session.IPAddr)
+	}
+	wg.Wait()
+
+	utils.Success(c, devices)
+}
+
+// RevokeDevice signs out a single device. The current session must be ended via logout.
+func (s *SessionController) RevokeDevice(c *gin.Context) {
+	_, _, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	if c.Param("id") == sessionID {
+		utils.Fail(c, "Cannot revoke the current session", http.StatusBadRequest, "use logout to end the current session")
+		return
+	}
+
+	s.Delete(c)
+}
+
+func (s *SessionController) lookupLocation(ctx context.Context, ip string) *dto.DeviceLocation {
+	location, err := s.geoIPService.Lookup(ctx, ip)
+	if err != nil {
+		slog.WarnContext(ctx, "geoip lookup failed", "ip", ip, "error", err)
+		return nil
+	}
+	if location == nil {
+		return nil
+	}
+	return &dto.DeviceLocation{
+		City:        location.City,
+		Region:      location.Region,
+		Country:     location.Country,
+		CountryCode: location.CountryCode,
+	}
+}
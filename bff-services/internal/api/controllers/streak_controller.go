@@ -3,13 +3,13 @@ package controllers
 import (
 	"io"
 	"net/http"
-	"strconv"
 
 	"bff-services/internal/api/dto"
 	middleware "bff-services/internal/middlewares"
 	"bff-services/internal/services"
 	"bff-services/internal/utils"
 
+	"github.com/ductan2/microservice-app/shared/queryparams"
 	"github.com/gin-gonic/gin"
 )
 
@@ -90,18 +90,11 @@ func (s *StreakController) GetStreakLeaderboard(c *gin.Context) {
 		return
 	}
 
-	limitStr := c.Query("limit")
-	limit := 0
-	if limitStr != "" {
-		parsed, err := strconv.Atoi(limitStr)
-		if err != nil || parsed < 1 {
-			utils.Fail(c, "Invalid limit parameter", http.StatusBadRequest, "limit must be a positive integer")
-			return
-		}
-		if parsed > 200 {
-			parsed = 200
-		}
-		limit = parsed
+	// 0 leaves the limit to the streak service
+	limit, err := queryparams.Int(c.Request.URL.Query(), "limit", 0, 1, 200)
+	if err != nil {
+		failQueryParam(c, err)
+		return
 	}
 
 	resp, err := s.streakService.GetStreakLeaderboard(c.Request.Context(), userID, email, sessionID, limit)
@@ -1,23 +1,35 @@
 package controllers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"log/slog"
+	"mime"
 	"net/http"
 	"sync"
 
 	"bff-services/internal/api/dto"
+	"bff-services/internal/cache"
 	middleware "bff-services/internal/middlewares"
 	"bff-services/internal/services"
+	"bff-services/internal/types"
 	"bff-services/internal/utils"
+	"bff-services/internal/validation"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
+// maxUserImportSize bounds the CSV file or JSON body of a bulk user import.
+const maxUserImportSize = 10 << 20 // 10 MiB
+
 // UserController handles user authentication, profile, and management operations.
 type UserController struct {
 	userService   services.UserService
 	lessonService services.LessonService
+	profileCache  *cache.ProfileCache
 }
 
 // NewUserController constructs a new UserController.
@@ -28,6 +40,23 @@ func NewUserController(userService services.UserService, lessonService services.
 	}
 }
 
+// SetProfileCache enables dropping the cached profile snippet of the user when their
+// name or avatar changes.
+func (u *UserController) SetProfileCache(profileCache *cache.ProfileCache) {
+	u.profileCache = profileCache
+}
+
+// invalidateProfile drops the cached profile snippet of the user once a change was
+// accepted, so enriched responses show it.
+func (u *UserController) invalidateProfile(ctx context.Context, userID string, resp *types.HTTPResponse) {
+	if u.profileCache == nil || resp == nil || resp.StatusCode >= 400 {
+		return
+	}
+	if err := u.profileCache.Invalidate(ctx, userID); err != nil {
+		slog.WarnContext(ctx, "failed to invalidate profile snippet", "user_id", userID, "error", err)
+	}
+}
+
 func (u *UserController) Register(c *gin.Context) {
 	var req dto.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -35,7 +64,7 @@ func (u *UserController) Register(c *gin.Context) {
 		return
 	}
 
-	resp, err := u.userService.Register(c.Request.Context(), req)
+	resp, err := u.userService.Register(c.Request.Context(), req, c.ClientIP())
 	if err != nil {
 		utils.Fail(c, "Unable to register user", http.StatusBadGateway, err.Error())
 		return
@@ -60,6 +89,98 @@ func (u *UserController) Login(c *gin.Context) {
 	respondWithServiceResponse(c, resp)
 }
 
+// BeginPasskeyLogin issues a passkey challenge, for passwordless login or as the second
+// factor of a password login.
+func (u *UserController) BeginPasskeyLogin(c *gin.Context) {
+	var req dto.PasskeyLoginBeginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := u.userService.BeginPasskeyLogin(c.Request.Context(), req, c.ClientIP())
+	if err != nil {
+		utils.Fail(c, "Unable to start passkey login", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	respondWithServiceResponse(c, resp)
+}
+
+// SendLoginOTP texts or calls a login code to the user's verified phone number after
+// /users/login answered MFA_REQUIRED.
+func (u *UserController) SendLoginOTP(c *gin.Context) {
+	var req dto.LoginOTPSendRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := u.userService.SendLoginOTP(c.Request.Context(), req, c.ClientIP())
+	if err != nil {
+		utils.Fail(c, "Unable to send verification code", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+// FinishPasskeyLogin signs the user in with a passkey assertion alone.
+func (u *UserController) FinishPasskeyLogin(c *gin.Context) {
+	var req dto.WebAuthnAssertion
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := u.userService.FinishPasskeyLogin(c.Request.Context(), req, c.GetHeader("User-Agent"), c.ClientIP())
+	if err != nil {
+		utils.Fail(c, "Unable to login", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	respondWithServiceResponse(c, resp)
+}
+
+// RequestPasswordlessLogin emails a single-use login link or code. The answer is the same
+// whether or not the email belongs to an account.
+func (u *UserController) RequestPasswordlessLogin(c *gin.Context) {
+	var req dto.PasswordlessLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := u.userService.RequestPasswordlessLogin(c.Request.Context(), req, c.ClientIP())
+	if err != nil {
+		utils.Fail(c, "Unable to send login email", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	respondWithServiceResponse(c, resp)
+}
+
+// VerifyPasswordlessLogin signs the user in with an emailed login link or code.
+func (u *UserController) VerifyPasswordlessLogin(c *gin.Context) {
+	var req dto.PasswordlessVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := u.userService.VerifyPasswordlessLogin(c.Request.Context(), req, c.GetHeader("User-Agent"), c.ClientIP())
+	if err != nil {
+		utils.Fail(c, "Unable to login", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	respondWithServiceResponse(c, resp)
+}
+
 func (u *UserController) Logout(c *gin.Context) {
 	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
 	if !ok {
@@ -75,6 +196,58 @@ func (u *UserController) Logout(c *gin.Context) {
 	respondWithServiceResponse(c, resp)
 }
 
+// RefreshToken rotates the caller's refresh token and returns a new token pair.
+func (u *UserController) RefreshToken(c *gin.Context) {
+	var req dto.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := u.userService.RefreshToken(c.Request.Context(), req, c.ClientIP())
+	if err != nil {
+		utils.Fail(c, "Unable to refresh token", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	respondWithServiceResponse(c, resp)
+}
+
+// RequestAccountUnlock asks for an unlock link for an account locked out after failed logins.
+func (u *UserController) RequestAccountUnlock(c *gin.Context) {
+	var req dto.AccountUnlockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := u.userService.RequestAccountUnlock(c.Request.Context(), req, c.ClientIP())
+	if err != nil {
+		utils.Fail(c, "Unable to request account unlock", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+// ConfirmAccountUnlock lifts a lockout with the token from the unlock email.
+func (u *UserController) ConfirmAccountUnlock(c *gin.Context) {
+	var req dto.AccountUnlockConfirmRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := u.userService.ConfirmAccountUnlock(c.Request.Context(), req, c.ClientIP())
+	if err != nil {
+		utils.Fail(c, "Unable to unlock account", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
 func (u *UserController) VerifyEmail(c *gin.Context) {
 	token := c.Query("token")
 	fmt.Println("token", token)
@@ -125,303 +298,1780 @@ func (u *UserController) UpdateProfile(c *gin.Context) {
 		utils.Fail(c, "Unable to update profile", http.StatusBadGateway, err.Error())
 		return
 	}
+	u.invalidateProfile(c.Request.Context(), userID, resp)
 
 	respondWithServiceResponse(c, resp)
 }
 
-// Users management methods
-func (u *UserController) ListUsersWithProgress(ctx *gin.Context) {
-	// Get query parameters
-	page := ctx.DefaultQuery("page", "1")
-	pageSize := ctx.DefaultQuery("page_size", "20")
-	status := ctx.Query("status")
-	search := ctx.Query("search")
-	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+// GetPreferences returns the caller's notification channels, locale, time zone, theme
+// and learning goals.
+func (u *UserController) GetPreferences(c *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
 	if !ok {
 		return
 	}
 
-	// Call user service to get list of users
-	userResp, err := u.userService.GetUsers(ctx.Request.Context(), page, pageSize, status, search, userID, email, sessionID)
+	resp, err := u.userService.GetPreferences(c.Request.Context(), userID, email, sessionID)
 	if err != nil {
-		utils.Fail(ctx, "Failed to fetch users", http.StatusInternalServerError, err.Error())
+		utils.Fail(c, "Unable to fetch preferences", http.StatusBadGateway, err.Error())
 		return
 	}
 
-	if userResp.StatusCode != http.StatusOK {
-		ctx.Data(userResp.StatusCode, "application/json", userResp.Body)
+	respondWithServiceResponse(c, resp)
+}
+
+// UpdatePreferences changes the preferences present in the body. Unknown fields are
+// rejected so a misspelt preference is not silently dropped.
+func (u *UserController) UpdatePreferences(c *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
 		return
 	}
 
-	// Parse user service response
-	var usersResponse struct {
-		Status string `json:"status"`
-		Data   struct {
-			Data       []dto.UserData `json:"data"`
-			Page       int            `json:"page"`
-			PageSize   int            `json:"page_size"`
-			Total      int            `json:"total"`
-			TotalPages int            `json:"total_pages"`
-		} `json:"data"`
+	var req dto.UpdatePreferencesRequest
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, validation.Describe(err))
+		return
 	}
 
-	if err := json.Unmarshal(userResp.Body, &usersResponse); err != nil {
-		utils.Fail(ctx, "Failed to parse users data", http.StatusInternalServerError, err.Error())
+	resp, err := u.userService.UpdatePreferences(c.Request.Context(), userID, email, sessionID, req)
+	if err != nil {
+		utils.Fail(c, "Unable to update preferences", http.StatusBadGateway, err.Error())
 		return
 	}
 
-	users := usersResponse.Data.Data
-	result := make([]dto.UserWithProgressResponse, len(users))
+	respondWithServiceResponse(c, resp)
+}
 
-	// Fetch points and streak for each user concurrently
-	var wg sync.WaitGroup
-	for i, user := range users {
-		wg.Add(1)
-		go func(index int, userData dto.UserData) {
-			defer wg.Done()
+// GetOnboarding returns the caller's progress through the first-run steps, which the
+// app uses to decide which onboarding screen to show.
+func (u *UserController) GetOnboarding(c *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
 
-			points := 0
-			streak := 0
+	resp, err := u.userService.GetOnboarding(c.Request.Context(), userID, email, sessionID)
+	if err != nil {
+		utils.Fail(c, "Unable to fetch onboarding progress", http.StatusBadGateway, err.Error())
+		return
+	}
 
-			// Fetch points
-			if pointsResp, err := u.lessonService.GetUserPoints(ctx.Request.Context(), userData.ID); err == nil && pointsResp.StatusCode == http.StatusOK {
-				var pointsData dto.PointsData
-				if err := json.Unmarshal(pointsResp.Body, &pointsData); err == nil {
-					points = pointsData.Lifetime
-				}
-			}
+	respondWithServiceResponse(c, resp)
+}
 
-			// Fetch streak
-			if streakResp, err := u.lessonService.GetUserStreak(ctx.Request.Context(), userData.ID); err == nil && streakResp.StatusCode == http.StatusOK {
-				var streakData dto.StreakData
-				if err := json.Unmarshal(streakResp.Body, &streakData); err == nil {
-					streak = streakData.CurrentLen
-				}
-			}
+// AdvanceOnboarding completes or skips one of the caller's onboarding steps. The body is
+// optional; without it the step is completed.
+func (u *UserController) AdvanceOnboarding(c *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
 
-			profile := dto.UserProfile{}
-			if userData.Profile != nil {
-				profile = dto.UserProfile{
-					DisplayName: userData.Profile.DisplayName,
-					AvatarURL:   userData.Profile.AvatarURL,
-				}
-			}
+	var params dto.OnboardingStepParam
+	if !bindURI(c, &params) {
+		return
+	}
+	var req dto.AdvanceOnboardingRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.Fail(c, "Invalid request data", http.StatusBadRequest, validation.Describe(err))
+			return
+		}
+	}
 
-			result[index] = dto.UserWithProgressResponse{
-				ID:            userData.ID,
-				Email:         userData.Email,
-				Status:        userData.Status,
-				Role:          userData.Role,
-				CreatedAt:     userData.CreatedAt,
-				LastLoginAt:   userData.LastLoginAt,
-				LastLoginIP:   userData.LastLoginIP,
-				LockoutUntil:  userData.LockoutUntil,
-				DeletedAt:     userData.DeletedAt,
-				EmailVerified: userData.EmailVerified,
-				Profile:       profile,
-				Points:        points,
-				Streak:        streak,
-			}
-		}(i, user)
+	resp, err := u.userService.AdvanceOnboarding(c.Request.Context(), userID, email, sessionID, params.Step, req)
+	if err != nil {
+		utils.Fail(c, "Unable to advance onboarding", http.StatusBadGateway, err.Error())
+		return
 	}
 
-	wg.Wait()
+	respondWithServiceResponse(c, resp)
+}
 
-	// Return aggregated response with pagination info
-	response := gin.H{
-		"status": "success",
-		"data": gin.H{
-			"users":       result,
-			"page":        usersResponse.Data.Page,
-			"page_size":   usersResponse.Data.PageSize,
-			"total":       usersResponse.Data.Total,
-			"total_pages": usersResponse.Data.TotalPages,
-		},
+// CheckUsernameAvailability tells sign-up and profile forms whether a username is valid
+// and free.
+func (u *UserController) CheckUsernameAvailability(c *gin.Context) {
+	var query dto.UsernameAvailabilityQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	resp, err := u.userService.CheckUsernameAvailability(c.Request.Context(), query.Username, c.ClientIP())
+	if err != nil {
+		utils.Fail(c, "Unable to check username", http.StatusBadGateway, err.Error())
+		return
 	}
 
-	ctx.JSON(http.StatusOK, response)
+	respondWithServiceResponse(c, resp)
 }
 
-func (u *UserController) GetUserById(ctx *gin.Context) {
-	// Extract user context from middleware
-	userIDValue, exists := ctx.Get(middleware.ContextUserIDKey())
-	if !exists {
-		utils.Fail(ctx, "Unauthorized", http.StatusUnauthorized, "user context not found")
+// GetLoginIdentifiers returns what the caller can sign in with: their email, username
+// and masked phone number.
+func (u *UserController) GetLoginIdentifiers(c *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
 		return
 	}
-	emailValue, exists := ctx.Get(middleware.ContextUserEmailKey())
-	if !exists {
-		utils.Fail(ctx, "Unauthorized", http.StatusUnauthorized, "email context not found")
+
+	resp, err := u.userService.GetLoginIdentifiers(c.Request.Context(), userID, email, sessionID)
+	if err != nil {
+		utils.Fail(c, "Unable to fetch login identifiers", http.StatusBadGateway, err.Error())
 		return
 	}
-	sessionIDValue, exists := ctx.Get(middleware.ContextSessionIDKey())
-	if !exists {
-		utils.Fail(ctx, "Unauthorized", http.StatusUnauthorized, "session context not found")
+
+	respondWithServiceResponse(c, resp)
+}
+
+// SetUsername claims or changes the caller's username.
+func (u *UserController) SetUsername(c *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
 		return
 	}
 
-	userID := utils.NormalizeUUIDOrString(userIDValue)
-	email := utils.NormalizeString(emailValue)
-	sessionID := utils.NormalizeUUIDOrString(sessionIDValue)
-	UserFindID := ctx.Param("id")
-	// Call user service using internal headers
-	userResp, err := u.userService.GetUserById(ctx.Request.Context(), userID, email, sessionID, UserFindID)
-	if err != nil || userResp == nil {
-		utils.Fail(ctx, "Failed to fetch profile", http.StatusBadGateway, utils.ErrString(err))
+	var req dto.SetUsernameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, validation.Describe(err))
 		return
 	}
-	if userResp.StatusCode != http.StatusOK {
-		ctx.Data(userResp.StatusCode, "application/json", userResp.Body)
+
+	resp, err := u.userService.SetUsername(c.Request.Context(), userID, email, sessionID, req)
+	if err != nil {
+		utils.Fail(c, "Unable to set username", http.StatusBadGateway, err.Error())
 		return
 	}
-	// Fetch points and streak concurrently, but don't reshape; return raw bodies
-	var (
-		pointsBody json.RawMessage
-		streakBody json.RawMessage
-	)
-	var wg sync.WaitGroup
+
+	respondWithServiceResponse(c, resp)
+}
+
+func (u *UserController) RemoveUsername(c *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	resp, err := u.userService.RemoveUsername(c.Request.Context(), userID, email, sessionID)
+	if err != nil {
+		utils.Fail(c, "Unable to remove username", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+// CheckPhoneAvailability tells whether the caller can sign in with a phone number. It
+// needs a signed-in caller so it cannot be used to find out who has an account.
+func (u *UserController) CheckPhoneAvailability(c *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	var query dto.PhoneAvailabilityQuery
+	if !bindQuery(c, &query) {
+		return
+	}
+
+	resp, err := u.userService.CheckPhoneAvailability(c.Request.Context(), userID, email, sessionID, query.PhoneNumber)
+	if err != nil {
+		utils.Fail(c, "Unable to check phone number", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+// StartPhoneVerification texts or calls a code to the phone number the caller wants to
+// sign in with. The number is saved once VerifyPhone confirms the code.
+func (u *UserController) StartPhoneVerification(c *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	var req dto.PhoneVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, validation.Describe(err))
+		return
+	}
+
+	resp, err := u.userService.StartPhoneVerification(c.Request.Context(), userID, email, sessionID, req)
+	if err != nil {
+		utils.Fail(c, "Unable to send verification code", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+func (u *UserController) VerifyPhone(c *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	var req dto.VerifyPhoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, validation.Describe(err))
+		return
+	}
+
+	resp, err := u.userService.VerifyPhone(c.Request.Context(), userID, email, sessionID, req)
+	if err != nil {
+		utils.Fail(c, "Unable to verify phone number", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+func (u *UserController) RemovePhone(c *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	resp, err := u.userService.RemovePhone(c.Request.Context(), userID, email, sessionID)
+	if err != nil {
+		utils.Fail(c, "Unable to remove phone number", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(c, resp)
+}
+
+// CreateAvatarUpload starts an avatar upload. The caller PUTs the image to the returned
+// URL with the returned headers, then completes the upload.
+func (u *UserController) CreateAvatarUpload(c *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateAvatarUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Fail(c, "Invalid request data", http.StatusBadRequest, validation.Describe(err))
+		return
+	}
+
+	resp, err := u.userService.CreateAvatarUpload(c.Request.Context(), userID, email, sessionID, req)
+	if err != nil {
+		utils.Fail(c, "Unable to start avatar upload", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	// The response carries a presigned upload URL
+	c.Header("Cache-Control", "no-store")
+	respondWithServiceResponse(c, resp)
+}
+
+// CompleteAvatarUpload sets an uploaded image as the caller's avatar once user-service
+// has checked and resized it.
+func (u *UserController) CompleteAvatarUpload(c *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	var params dto.AvatarUploadIDParam
+	if !bindURI(c, &params) {
+		return
+	}
+
+	resp, err := u.userService.CompleteAvatarUpload(c.Request.Context(), userID, email, sessionID, params.ID)
+	if err != nil {
+		utils.Fail(c, "Unable to complete avatar upload", http.StatusBadGateway, err.Error())
+		return
+	}
+	u.invalidateProfile(c.Request.Context(), userID, resp)
+
+	respondWithServiceResponse(c, resp)
+}
+
+// RemoveAvatar clears the caller's avatar.
+func (u *UserController) RemoveAvatar(c *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(c)
+	if !ok {
+		return
+	}
+
+	resp, err := u.userService.RemoveAvatar(c.Request.Context(), userID, email, sessionID)
+	if err != nil {
+		utils.Fail(c, "Unable to remove avatar", http.StatusBadGateway, err.Error())
+		return
+	}
+	u.invalidateProfile(c.Request.Context(), userID, resp)
+
+	respondWithServiceResponse(c, resp)
+}
+
+// Users management methods
+
+// ListUsersWithProgress lists users with their points and streak. The filters, sort and
+// cursor are passed through to user-service, which pages by keyset; the legacy `page`
+// parameter is still honoured when no cursor is sent.
+func (u *UserController) ListUsersWithProgress(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var query dto.AdminUserQuery
+	if !bindQuery(ctx, &query) {
+		return
+	}
+	if query.Limit > 0 {
+		query.PageSize = query.Limit
+	}
+	if query.PageSize == 0 {
+		query.PageSize = 20
+	}
+	query.PageSize = min(query.PageSize, 100)
+
+	// Call user service to get list of users
+	userResp, err := u.userService.GetUsers(ctx.Request.Context(), query, userID, email, sessionID)
+	if err != nil {
+		utils.Fail(ctx, "Failed to fetch users", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if userResp.StatusCode != http.StatusOK {
+		ctx.Data(userResp.StatusCode, "application/json", userResp.Body)
+		return
+	}
+
+	// Parse user service response
+	var usersResponse struct {
+		Status string `json:"status"`
+		Data   struct {
+			Data       []dto.UserData `json:"data"`
+			Page       int            `json:"page"`
+			PageSize   int            `json:"page_size"`
+			Total      int            `json:"total"`
+			TotalPages int            `json:"total_pages"`
+			NextCursor string         `json:"next_cursor"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(userResp.Body, &usersResponse); err != nil {
+		utils.Fail(ctx, "Failed to parse users data", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	users := usersResponse.Data.Data
+	result := make([]dto.UserWithProgressResponse, len(users))
+
+	// Fetch points and streak for each user concurrently
+	var wg sync.WaitGroup
+	for i, user := range users {
+		wg.Add(1)
+		go func(index int, userData dto.UserData) {
+			defer wg.Done()
+
+			points := 0
+			streak := 0
+
+			// Fetch points
+			if pointsResp, err := u.lessonService.GetUserPoints(ctx.Request.Context(), userData.ID); err == nil && pointsResp.StatusCode == http.StatusOK {
+				var pointsData dto.PointsData
+				if err := json.Unmarshal(pointsResp.Body, &pointsData); err == nil {
+					points = pointsData.Lifetime
+				}
+			}
+
+			// Fetch streak
+			if streakResp, err := u.lessonService.GetUserStreak(ctx.Request.Context(), userData.ID); err == nil && streakResp.StatusCode == http.StatusOK {
+				var streakData dto.StreakData
+				if err := json.Unmarshal(streakResp.Body, &streakData); err == nil {
+					streak = streakData.CurrentLen
+				}
+			}
+
+			profile := dto.UserProfile{}
+			if userData.Profile != nil {
+				profile = dto.UserProfile{
+					DisplayName: userData.Profile.DisplayName,
+					AvatarURL:   userData.Profile.AvatarURL,
+				}
+			}
+
+			result[index] = dto.UserWithProgressResponse{
+				ID:            userData.ID,
+				Email:         userData.Email,
+				Status:        userData.Status,
+				Role:          userData.Role,
+				CreatedAt:     userData.CreatedAt,
+				LastLoginAt:   userData.LastLoginAt,
+				LastLoginIP:   userData.LastLoginIP,
+				LockoutUntil:  userData.LockoutUntil,
+				DeletedAt:     userData.DeletedAt,
+				EmailVerified: userData.EmailVerified,
+				Profile:       profile,
+				Points:        points,
+				Streak:        streak,
+			}
+		}(i, user)
+	}
+
+	wg.Wait()
+
+	total := int64(usersResponse.Data.Total)
+	envelope := dto.PageEnvelope{Items: result, Total: &total}
+	if next := usersResponse.Data.NextCursor; next != "" {
+		envelope.NextCursor = &next
+	}
+	utils.Success(ctx, envelope)
+}
+
+func (u *UserController) GetUserById(ctx *gin.Context) {
+	// Extract user context from middleware
+	userIDValue, exists := ctx.Get(middleware.ContextUserIDKey())
+	if !exists {
+		utils.Fail(ctx, "Unauthorized", http.StatusUnauthorized, "user context not found")
+		return
+	}
+	emailValue, exists := ctx.Get(middleware.ContextUserEmailKey())
+	if !exists {
+		utils.Fail(ctx, "Unauthorized", http.StatusUnauthorized, "email context not found")
+		return
+	}
+	sessionIDValue, exists := ctx.Get(middleware.ContextSessionIDKey())
+	if !exists {
+		utils.Fail(ctx, "Unauthorized", http.StatusUnauthorized, "session context not found")
+		return
+	}
+
+	userID := utils.NormalizeUUIDOrString(userIDValue)
+	email := utils.NormalizeString(emailValue)
+	sessionID := utils.NormalizeUUIDOrString(sessionIDValue)
+	UserFindID := ctx.Param("id")
+	// Call user service using internal headers
+	userResp, err := u.userService.GetUserById(ctx.Request.Context(), userID, email, sessionID, UserFindID)
+	if err != nil || userResp == nil {
+		utils.Fail(ctx, "Failed to fetch profile", http.StatusBadGateway, utils.ErrString(err))
+		return
+	}
+	if userResp.StatusCode != http.StatusOK {
+		ctx.Data(userResp.StatusCode, "application/json", userResp.Body)
+		return
+	}
+	// Fetch points and streak concurrently, but don't reshape; return raw bodies
+	var (
+		pointsBody json.RawMessage
+		streakBody json.RawMessage
+	)
+	var wg sync.WaitGroup
 	wg.Add(2)
 	go func() {
 		defer wg.Done()
 		if resp, err := u.lessonService.GetUserPoints(ctx.Request.Context(), UserFindID); err == nil && resp.StatusCode == http.StatusOK {
 			pointsBody = json.RawMessage(resp.Body)
 		}
-	}()
-	go func() {
-		defer wg.Done()
-		if resp, err := u.lessonService.GetUserStreak(ctx.Request.Context(), UserFindID); err == nil && resp.StatusCode == http.StatusOK {
-			streakBody = json.RawMessage(resp.Body)
+	}()
+	go func() {
+		defer wg.Done()
+		if resp, err := u.lessonService.GetUserStreak(ctx.Request.Context(), UserFindID); err == nil && resp.StatusCode == http.StatusOK {
+			streakBody = json.RawMessage(resp.Body)
+		}
+	}()
+	wg.Wait()
+
+	// Extract only inner data from user-service envelope {status,data}
+	var userEnvelope map[string]interface{}
+	_ = json.Unmarshal(userResp.Body, &userEnvelope)
+	userInner := userEnvelope["data"]
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"status": "success",
+		"data": gin.H{
+			"user":   userInner,
+			"points": pointsBody,
+			"streak": streakBody,
+		},
+	})
+}
+
+func (u *UserController) UpdateUserRole(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	targetID := ctx.Param("id")
+	if targetID == "" {
+		utils.Fail(ctx, "User ID is required", http.StatusBadRequest, "missing user ID")
+		return
+	}
+	if !ok {
+		return
+	}
+	var req dto.UpdateUserRoleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+	userResp, err := u.userService.UpdateUserRoleWithContext(ctx.Request.Context(), userID, email, sessionID, targetID, req)
+	if err != nil {
+		utils.Fail(ctx, "Failed to update user role", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, userResp)
+}
+
+func (u *UserController) LockAccount(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	targetID := ctx.Param("id")
+	if targetID == "" {
+		utils.Fail(ctx, "User ID is required", http.StatusBadRequest, "missing user ID")
+		return
+	}
+
+	reason := ctx.Query("reason")
+
+	resp, err := u.userService.LockAccountWithContext(ctx.Request.Context(), userID, email, sessionID, targetID, reason)
+	if err != nil {
+		utils.Fail(ctx, "Failed to lock account", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+func (u *UserController) UnlockAccount(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	targetID := ctx.Param("id")
+	if targetID == "" {
+		utils.Fail(ctx, "User ID is required", http.StatusBadRequest, "missing user ID")
+		return
+	}
+
+	reason := ctx.Query("reason")
+
+	resp, err := u.userService.UnlockAccountWithContext(ctx.Request.Context(), userID, email, sessionID, targetID, reason)
+	if err != nil {
+		utils.Fail(ctx, "Failed to unlock account", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+func (u *UserController) SoftDeleteAccount(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	targetID := ctx.Param("id")
+	if targetID == "" {
+		utils.Fail(ctx, "User ID is required", http.StatusBadRequest, "missing user ID")
+		return
+	}
+
+	reason := ctx.Query("reason")
+
+	resp, err := u.userService.SoftDeleteAccountWithContext(ctx.Request.Context(), userID, email, sessionID, targetID, reason)
+	if err != nil {
+		utils.Fail(ctx, "Failed to delete account", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+func (u *UserController) RestoreAccount(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	targetID := ctx.Param("id")
+	if targetID == "" {
+		utils.Fail(ctx, "User ID is required", http.StatusBadRequest, "missing user ID")
+		return
+	}
+
+	reason := ctx.Query("reason")
+
+	resp, err := u.userService.RestoreAccountWithContext(ctx.Request.Context(), userID, email, sessionID, targetID, reason)
+	if err != nil {
+		utils.Fail(ctx, "Failed to restore account", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+// Security audit log methods
+
+// GetMyAuditLogs lists the security events on the caller's account (logins, password
+// and MFA changes, profile edits and admin actions), newest first.
+func (u *UserController) GetMyAuditLogs(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	query, page, ok := bindSecurityAuditQuery(ctx)
+	if !ok {
+		return
+	}
+
+	resp, err := u.userService.GetMyAuditLogs(ctx.Request.Context(), userID, email, sessionID, query)
+	if err != nil {
+		utils.Fail(ctx, "Unable to fetch audit log", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	respondWithPage(ctx, resp, page)
+}
+
+// GetLoginHistory lists the successful and failed sign-ins on the caller's account with
+// their time, IP address and device, so users can spot sign-ins that were not theirs.
+func (u *UserController) GetLoginHistory(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var query dto.LoginHistoryQuery
+	if !bindQuery(ctx, &query) {
+		return
+	}
+	page, ok := parsePageRequest(ctx, 20, 100)
+	if !ok {
+		return
+	}
+	query.Page, query.PageSize = page.Number(), page.Limit
+
+	resp, err := u.userService.GetLoginHistory(ctx.Request.Context(), userID, email, sessionID, query)
+	if err != nil {
+		utils.Fail(ctx, "Unable to fetch login history", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	respondWithPage(ctx, resp, page)
+}
+
+// GetUserMetrics returns the headline user metrics of the back-office overview page:
+// daily signups and active users, verification and MFA adoption rates, and locked
+// accounts (admin only).
+func (u *UserController) GetUserMetrics(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var query dto.UserMetricsQuery
+	if !bindQuery(ctx, &query) {
+		return
+	}
+
+	resp, err := u.userService.GetUserMetrics(ctx.Request.Context(), userID, email, sessionID, query)
+	if err != nil {
+		utils.Fail(ctx, "Unable to fetch user metrics", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+// GetUserAuditLogs lists the security events on another user's account (admin only).
+func (u *UserController) GetUserAuditLogs(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var params dto.TargetUserIDParam
+	if !bindURI(ctx, &params) {
+		return
+	}
+	query, page, ok := bindSecurityAuditQuery(ctx)
+	if !ok {
+		return
+	}
+
+	resp, err := u.userService.GetUserAuditLogs(ctx.Request.Context(), userID, email, sessionID, params.ID, query)
+	if err != nil {
+		utils.Fail(ctx, "Unable to fetch audit log", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	respondWithPage(ctx, resp, page)
+}
+
+// ListSecurityAuditLogs searches security events across all accounts by action, affected
+// user, actor and time range (admin only).
+func (u *UserController) ListSecurityAuditLogs(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	query, page, ok := bindSecurityAuditQuery(ctx)
+	if !ok {
+		return
+	}
+
+	resp, err := u.userService.ListSecurityAuditLogs(ctx.Request.Context(), userID, email, sessionID, query)
+	if err != nil {
+		utils.Fail(ctx, "Unable to fetch audit log", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	respondWithPage(ctx, resp, page)
+}
+
+// Data export methods
+
+// RequestDataExport starts an export of everything the platform holds about the caller.
+// Poll GetDataExport until it is ready, then download the archive.
+func (u *UserController) RequestDataExport(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	resp, err := u.userService.RequestDataExport(ctx.Request.Context(), userID, email, sessionID)
+	if err != nil {
+		utils.Fail(ctx, "Unable to request data export", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+// ListDataExports lists the caller's recent data exports.
+func (u *UserController) ListDataExports(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	resp, err := u.userService.ListDataExports(ctx.Request.Context(), userID, email, sessionID)
+	if err != nil {
+		utils.Fail(ctx, "Unable to fetch data exports", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	respondWithServiceResponse(ctx, resp)
+}
+
+// GetDataExport reports the status of one of the caller's data exports.
+func (u *UserController) GetDataExport(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var params dto.DataExportIDParam
+	if !bindURI(ctx, &params) {
+		return
+	}
+
+	resp, err := u.userService.GetDataExport(ctx.Request.Context(), userID, email, sessionID, params.ID)
+	if err != nil {
+		utils.Fail(ctx, "Unable to fetch data export", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	respondWithServiceResponse(ctx, resp)
+}
+
+// DownloadDataExport streams a finished data export's zip archive to the caller.
+func (u *UserController) DownloadDataExport(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var params dto.DataExportIDParam
+	if !bindURI(ctx, &params) {
+		return
+	}
+
+	resp, err := u.userService.DownloadDataExport(ctx.Request.Context(), userID, email, sessionID, params.ID)
+	if err != nil {
+		utils.Fail(ctx, "Unable to download data export", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+// Erasure methods
+
+// RequestErasure deletes the caller's account after confirming their password. Their
+// personal data is erased across all services once the retention window ends; until
+// then the user can recover it through RecoverAccount.
+func (u *UserController) RequestErasure(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var req dto.ErasureSelfRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := u.userService.RequestErasure(ctx.Request.Context(), userID, email, sessionID, req)
+	if err != nil {
+		utils.Fail(ctx, "Unable to request account erasure", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+// DeactivateAccount deactivates the caller's account after confirming their password.
+// They are signed out everywhere; signing in again before the cooling-off period ends
+// reactivates the account, otherwise it is erased like after RequestErasure.
+func (u *UserController) DeactivateAccount(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var req dto.DeactivateAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := u.userService.DeactivateAccount(ctx.Request.Context(), userID, email, sessionID, req)
+	if err != nil {
+		utils.Fail(ctx, "Unable to deactivate account", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+// RecoverAccount restores an account the user deleted, cancelling its erasure, as long as
+// the retention window has not ended. Accounts deleted by an admin are left to admins.
+func (u *UserController) RecoverAccount(ctx *gin.Context) {
+	var req dto.ErasureRecoverRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := u.userService.RecoverAccount(ctx.Request.Context(), req, ctx.ClientIP())
+	if err != nil {
+		utils.Fail(ctx, "Unable to recover account", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+// ScheduleUserErasure deletes a user's account and schedules the erasure of their data
+// (admin).
+func (u *UserController) ScheduleUserErasure(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var params dto.TargetUserIDParam
+	if !bindURI(ctx, &params) {
+		return
+	}
+	var req dto.ErasureAdminRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	resp, err := u.userService.ScheduleUserErasure(ctx.Request.Context(), userID, email, sessionID, params.ID, req)
+	if err != nil {
+		utils.Fail(ctx, "Unable to schedule account erasure", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+// StartImpersonation opens a time-boxed session as a user so support can reproduce their
+// problem (admin). The response carries the session's tokens, so it is never cached.
+func (u *UserController) StartImpersonation(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var params dto.TargetUserIDParam
+	if !bindURI(ctx, &params) {
+		return
+	}
+	var req dto.StartImpersonationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := u.userService.StartImpersonation(ctx.Request.Context(), userID, email, sessionID, params.ID, req)
+	if err != nil {
+		utils.Fail(ctx, "Unable to start impersonation", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	respondWithServiceResponse(ctx, resp)
+}
+
+// EndImpersonation ends an impersonation session before it expires (admin).
+func (u *UserController) EndImpersonation(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var params dto.ImpersonationIDParam
+	if !bindURI(ctx, &params) {
+		return
+	}
+
+	resp, err := u.userService.EndImpersonation(ctx.Request.Context(), userID, email, sessionID, params.ID)
+	if err != nil {
+		utils.Fail(ctx, "Unable to end impersonation", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+// ListErasures lists erasure requests with their completion reports (admin).
+func (u *UserController) ListErasures(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var query dto.ErasureQuery
+	if !bindQuery(ctx, &query) {
+		return
+	}
+	page, ok := parsePageRequest(ctx, 20, 100)
+	if !ok {
+		return
+	}
+	query.Page, query.PageSize = page.Number(), page.Limit
+
+	resp, err := u.userService.ListErasures(ctx.Request.Context(), userID, email, sessionID, query)
+	if err != nil {
+		utils.Fail(ctx, "Unable to fetch erasure requests", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	respondWithPage(ctx, resp, page)
+}
+
+// GetErasure returns the completion report of one erasure request (admin).
+func (u *UserController) GetErasure(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var params dto.ErasureIDParam
+	if !bindURI(ctx, &params) {
+		return
+	}
+
+	resp, err := u.userService.GetErasure(ctx.Request.Context(), userID, email, sessionID, params.ID)
+	if err != nil {
+		utils.Fail(ctx, "Unable to fetch erasure request", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	respondWithServiceResponse(ctx, resp)
+}
+
+// ImportUsers creates invited accounts in bulk (admin). It takes a JSON body, a text/csv
+// body with the defaults in the query string, or a multipart form with the CSV in a
+// "file" field and the defaults as form fields, and returns user-service's per-row
+// report.
+func (u *UserController) ImportUsers(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxUserImportSize)
+
+	var options dto.UserImportRequest
+	var csv []byte
+	mediaType, _, _ := mime.ParseMediaType(ctx.GetHeader("Content-Type"))
+	switch mediaType {
+	case "multipart/form-data":
+		if err := ctx.ShouldBind(&options); err != nil {
+			utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+			return
+		}
+		file, err := ctx.FormFile("file")
+		if err != nil {
+			utils.Fail(ctx, "A CSV file is required", http.StatusBadRequest, err.Error())
+			return
 		}
-	}()
-	wg.Wait()
+		f, err := file.Open()
+		if err != nil {
+			utils.Fail(ctx, "Invalid CSV file", http.StatusBadRequest, err.Error())
+			return
+		}
+		defer f.Close()
+		if csv, err = io.ReadAll(f); err != nil {
+			utils.Fail(ctx, "Invalid CSV file", http.StatusBadRequest, err.Error())
+			return
+		}
+	case "text/csv":
+		if !bindQuery(ctx, &options) {
+			return
+		}
+		var err error
+		if csv, err = io.ReadAll(ctx.Request.Body); err != nil {
+			utils.Fail(ctx, "Invalid CSV file", http.StatusBadRequest, err.Error())
+			return
+		}
+	default:
+		if err := ctx.ShouldBindJSON(&options); err != nil {
+			utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+			return
+		}
+		resp, err := u.userService.ImportUsers(ctx.Request.Context(), userID, email, sessionID, options)
+		if err != nil {
+			utils.Fail(ctx, "Unable to import users", http.StatusBadGateway, err.Error())
+			return
+		}
+		respondWithServiceResponse(ctx, resp)
+		return
+	}
+
+	resp, err := u.userService.ImportUsersCSV(ctx.Request.Context(), userID, email, sessionID, options, csv)
+	if err != nil {
+		utils.Fail(ctx, "Unable to import users", http.StatusBadGateway, err.Error())
+		return
+	}
+	respondWithServiceResponse(ctx, resp)
+}
+
+// CreateOrganization creates an organization, optionally with its first owner (admin).
+func (u *UserController) CreateOrganization(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateOrganizationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := u.userService.CreateOrganization(ctx.Request.Context(), userID, email, sessionID, req)
+	if err != nil {
+		utils.Fail(ctx, "Unable to create organization", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+// ListOrganizations lists organizations with their seat usage (admin).
+func (u *UserController) ListOrganizations(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var query dto.OrganizationQuery
+	if !bindQuery(ctx, &query) {
+		return
+	}
+	page, ok := parsePageRequest(ctx, 20, 100)
+	if !ok {
+		return
+	}
+	query.Page, query.PageSize = page.Number(), page.Limit
+
+	resp, err := u.userService.ListOrganizations(ctx.Request.Context(), userID, email, sessionID, query)
+	if err != nil {
+		utils.Fail(ctx, "Unable to fetch organizations", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithPage(ctx, resp, page)
+}
+
+// UpdateOrganization renames an organization or changes its seat limit (admin).
+func (u *UserController) UpdateOrganization(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var params dto.OrganizationIDParam
+	if !bindURI(ctx, &params) {
+		return
+	}
+	var req dto.UpdateOrganizationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := u.userService.UpdateOrganization(ctx.Request.Context(), userID, email, sessionID, params.ID, req)
+	if err != nil {
+		utils.Fail(ctx, "Unable to update organization", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+// GetOrganization returns an organization the caller belongs to, with its seat usage.
+func (u *UserController) GetOrganization(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var params dto.OrganizationIDParam
+	if !bindURI(ctx, &params) {
+		return
+	}
+
+	resp, err := u.userService.GetOrganization(ctx.Request.Context(), userID, email, sessionID, params.ID)
+	if err != nil {
+		utils.Fail(ctx, "Unable to fetch organization", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+// ListOrganizationMembers lists an organization's members. Only its owners and
+// managers, and admins, may see them.
+func (u *UserController) ListOrganizationMembers(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var params dto.OrganizationIDParam
+	if !bindURI(ctx, &params) {
+		return
+	}
+	var query dto.OrganizationMemberQuery
+	if !bindQuery(ctx, &query) {
+		return
+	}
+	page, ok := parsePageRequest(ctx, 20, 100)
+	if !ok {
+		return
+	}
+	query.Page, query.PageSize = page.Number(), page.Limit
+
+	resp, err := u.userService.ListOrganizationMembers(ctx.Request.Context(), userID, email, sessionID, params.ID, query)
+	if err != nil {
+		utils.Fail(ctx, "Unable to fetch organization members", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	respondWithPage(ctx, resp, page)
+}
+
+// AddOrganizationMember adds an existing account to an organization. Owners may add
+// any role, managers only learners.
+func (u *UserController) AddOrganizationMember(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var params dto.OrganizationIDParam
+	if !bindURI(ctx, &params) {
+		return
+	}
+	var req dto.AddOrganizationMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := u.userService.AddOrganizationMember(ctx.Request.Context(), userID, email, sessionID, params.ID, req)
+	if err != nil {
+		utils.Fail(ctx, "Unable to add organization member", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+// UpdateOrganizationMember changes a member's role.
+func (u *UserController) UpdateOrganizationMember(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var params dto.OrganizationMemberParam
+	if !bindURI(ctx, &params) {
+		return
+	}
+	var req dto.UpdateOrganizationMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := u.userService.UpdateOrganizationMember(ctx.Request.Context(), userID, email, sessionID, params.ID, params.UserID, req)
+	if err != nil {
+		utils.Fail(ctx, "Unable to update organization member", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+// RemoveOrganizationMember removes a member from an organization; members may remove
+// themselves to leave it.
+func (u *UserController) RemoveOrganizationMember(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var params dto.OrganizationMemberParam
+	if !bindURI(ctx, &params) {
+		return
+	}
+
+	resp, err := u.userService.RemoveOrganizationMember(ctx.Request.Context(), userID, email, sessionID, params.ID, params.UserID)
+	if err != nil {
+		utils.Fail(ctx, "Unable to remove organization member", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+// ListMyOrganizations lists the organizations the caller belongs to and their role in
+// each.
+func (u *UserController) ListMyOrganizations(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	resp, err := u.userService.ListMyOrganizations(ctx.Request.Context(), userID, email, sessionID)
+	if err != nil {
+		utils.Fail(ctx, "Unable to fetch organizations", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+// CreateInvitation invites someone without an account to register. user-service
+// decides who may invite: admins anyone, organization owners and managers students
+// into their organization.
+func (u *UserController) CreateInvitation(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateInvitationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := u.userService.CreateInvitation(ctx.Request.Context(), userID, email, sessionID, req)
+	if err != nil {
+		utils.Fail(ctx, "Unable to create invitation", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+// ListInvitations lists invitations, newest first.
+func (u *UserController) ListInvitations(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var query dto.InvitationQuery
+	if !bindQuery(ctx, &query) {
+		return
+	}
+	page, ok := parsePageRequest(ctx, 20, 100)
+	if !ok {
+		return
+	}
+	query.Page, query.PageSize = page.Number(), page.Limit
+
+	resp, err := u.userService.ListInvitations(ctx.Request.Context(), userID, email, sessionID, query)
+	if err != nil {
+		utils.Fail(ctx, "Unable to fetch invitations", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	respondWithPage(ctx, resp, page)
+}
+
+// ResendInvitation emails a pending invitation again; the previous link stops working.
+func (u *UserController) ResendInvitation(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var params dto.InvitationIDParam
+	if !bindURI(ctx, &params) {
+		return
+	}
 
-	// Extract only inner data from user-service envelope {status,data}
-	var userEnvelope map[string]interface{}
-	_ = json.Unmarshal(userResp.Body, &userEnvelope)
-	userInner := userEnvelope["data"]
+	resp, err := u.userService.ResendInvitation(ctx.Request.Context(), userID, email, sessionID, params.ID)
+	if err != nil {
+		utils.Fail(ctx, "Unable to resend invitation", http.StatusBadGateway, err.Error())
+		return
+	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"status": "success",
-		"data": gin.H{
-			"user":   userInner,
-			"points": pointsBody,
-			"streak": streakBody,
-		},
-	})
+	respondWithServiceResponse(ctx, resp)
 }
 
-func (u *UserController) UpdateUserRole(ctx *gin.Context) {
+// RevokeInvitation cancels a pending invitation.
+func (u *UserController) RevokeInvitation(ctx *gin.Context) {
 	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
-	targetID := ctx.Param("id")
-	if targetID == "" {
-		utils.Fail(ctx, "User ID is required", http.StatusBadRequest, "missing user ID")
+	if !ok {
+		return
+	}
+
+	var params dto.InvitationIDParam
+	if !bindURI(ctx, &params) {
+		return
+	}
+
+	resp, err := u.userService.RevokeInvitation(ctx.Request.Context(), userID, email, sessionID, params.ID)
+	if err != nil {
+		utils.Fail(ctx, "Unable to revoke invitation", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+// PreviewInvitation shows who an invitation link is for before it is accepted.
+func (u *UserController) PreviewInvitation(ctx *gin.Context) {
+	token := ctx.Query("token")
+	if token == "" {
+		utils.Fail(ctx, "Invitation token is required", http.StatusBadRequest, "missing token")
+		return
+	}
+
+	resp, err := u.userService.PreviewInvitation(ctx.Request.Context(), token, ctx.ClientIP())
+	if err != nil {
+		utils.Fail(ctx, "Unable to fetch invitation", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	respondWithServiceResponse(ctx, resp)
+}
+
+// AcceptInvitation sets the invitee's password and creates their account. They sign in
+// afterwards like any other user.
+func (u *UserController) AcceptInvitation(ctx *gin.Context) {
+	var req dto.AcceptInvitationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := u.userService.AcceptInvitation(ctx.Request.Context(), req, ctx.ClientIP())
+	if err != nil {
+		utils.Fail(ctx, "Unable to accept invitation", http.StatusBadGateway, err.Error())
 		return
 	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+// CreateAccessToken creates a personal access token for scripts calling the API as the
+// caller. The token is in this response only.
+func (u *UserController) CreateAccessToken(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
 	if !ok {
 		return
 	}
-	var req dto.UpdateUserRoleRequest
+
+	var req dto.CreateAccessTokenRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
 		return
 	}
-	userResp, err := u.userService.UpdateUserRoleWithContext(ctx.Request.Context(), userID, email, sessionID, targetID, req)
+
+	resp, err := u.userService.CreateAccessToken(ctx.Request.Context(), userID, email, sessionID, req)
 	if err != nil {
-		utils.Fail(ctx, "Failed to update user role", http.StatusBadRequest, err.Error())
+		utils.Fail(ctx, "Unable to create access token", http.StatusBadGateway, err.Error())
 		return
 	}
 
-	respondWithServiceResponse(ctx, userResp)
+	ctx.Header("Cache-Control", "no-store")
+	respondWithServiceResponse(ctx, resp)
 }
 
-func (u *UserController) LockAccount(ctx *gin.Context) {
+// ListAccessTokens lists the caller's personal access tokens, newest first, without
+// their secrets.
+func (u *UserController) ListAccessTokens(ctx *gin.Context) {
 	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
 	if !ok {
 		return
 	}
 
-	targetID := ctx.Param("id")
-	if targetID == "" {
-		utils.Fail(ctx, "User ID is required", http.StatusBadRequest, "missing user ID")
+	resp, err := u.userService.ListAccessTokens(ctx.Request.Context(), userID, email, sessionID)
+	if err != nil {
+		utils.Fail(ctx, "Unable to fetch access tokens", http.StatusBadGateway, err.Error())
 		return
 	}
 
-	reason := ctx.Query("reason")
+	respondWithServiceResponse(ctx, resp)
+}
 
-	resp, err := u.userService.LockAccountWithContext(ctx.Request.Context(), userID, email, sessionID, targetID, reason)
+// RevokeAccessToken revokes one of the caller's personal access tokens; requests using
+// it are rejected from then on.
+func (u *UserController) RevokeAccessToken(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var params dto.AccessTokenIDParam
+	if !bindURI(ctx, &params) {
+		return
+	}
+
+	resp, err := u.userService.RevokeAccessToken(ctx.Request.Context(), userID, email, sessionID, params.ID)
 	if err != nil {
-		utils.Fail(ctx, "Failed to lock account", http.StatusBadGateway, err.Error())
+		utils.Fail(ctx, "Unable to revoke access token", http.StatusBadGateway, err.Error())
 		return
 	}
 
 	respondWithServiceResponse(ctx, resp)
 }
 
-func (u *UserController) UnlockAccount(ctx *gin.Context) {
+// RegisterPushToken registers the device token of the caller's app install for push
+// notifications, or refreshes it when already registered.
+func (u *UserController) RegisterPushToken(ctx *gin.Context) {
 	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
 	if !ok {
 		return
 	}
 
-	targetID := ctx.Param("id")
-	if targetID == "" {
-		utils.Fail(ctx, "User ID is required", http.StatusBadRequest, "missing user ID")
+	var req dto.RegisterPushTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
 		return
 	}
 
-	reason := ctx.Query("reason")
+	resp, err := u.userService.RegisterPushToken(ctx.Request.Context(), userID, email, sessionID, req)
+	if err != nil {
+		utils.Fail(ctx, "Unable to register push token", http.StatusBadGateway, err.Error())
+		return
+	}
 
-	resp, err := u.userService.UnlockAccountWithContext(ctx.Request.Context(), userID, email, sessionID, targetID, reason)
+	respondWithServiceResponse(ctx, resp)
+}
+
+// ListPushTokens lists the devices registered for the caller's push notifications, most
+// recently registered first.
+func (u *UserController) ListPushTokens(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	resp, err := u.userService.ListPushTokens(ctx.Request.Context(), userID, email, sessionID)
 	if err != nil {
-		utils.Fail(ctx, "Failed to unlock account", http.StatusBadGateway, err.Error())
+		utils.Fail(ctx, "Unable to fetch push tokens", http.StatusBadGateway, err.Error())
 		return
 	}
 
 	respondWithServiceResponse(ctx, resp)
 }
 
-func (u *UserController) SoftDeleteAccount(ctx *gin.Context) {
+// UnregisterPushToken stops push notifications to one of the caller's devices.
+func (u *UserController) UnregisterPushToken(ctx *gin.Context) {
 	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
 	if !ok {
 		return
 	}
 
-	targetID := ctx.Param("id")
-	if targetID == "" {
-		utils.Fail(ctx, "User ID is required", http.StatusBadRequest, "missing user ID")
+	var params dto.PushTokenIDParam
+	if !bindURI(ctx, &params) {
 		return
 	}
 
-	reason := ctx.Query("reason")
+	resp, err := u.userService.UnregisterPushToken(ctx.Request.Context(), userID, email, sessionID, params.ID)
+	if err != nil {
+		utils.Fail(ctx, "Unable to unregister push token", http.StatusBadGateway, err.Error())
+		return
+	}
 
-	resp, err := u.userService.SoftDeleteAccountWithContext(ctx.Request.Context(), userID, email, sessionID, targetID, reason)
+	respondWithServiceResponse(ctx, resp)
+}
+
+// UnregisterPushTokenByValue stops push notifications to the device holding a token, as
+// apps do when the user signs out.
+func (u *UserController) UnregisterPushTokenByValue(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var req dto.UnregisterPushTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := u.userService.UnregisterPushTokenByValue(ctx.Request.Context(), userID, email, sessionID, req)
 	if err != nil {
-		utils.Fail(ctx, "Failed to delete account", http.StatusBadGateway, err.Error())
+		utils.Fail(ctx, "Unable to unregister push token", http.StatusBadGateway, err.Error())
 		return
 	}
 
 	respondWithServiceResponse(ctx, resp)
 }
 
-func (u *UserController) RestoreAccount(ctx *gin.Context) {
+// ListPolicies returns the current version of the terms of service and privacy policy.
+func (u *UserController) ListPolicies(ctx *gin.Context) {
+	resp, err := u.userService.ListPolicies(ctx.Request.Context())
+	if err != nil {
+		utils.Fail(ctx, "Unable to fetch policies", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+// ListPolicyVersions lists every published version of a policy document (admin).
+func (u *UserController) ListPolicyVersions(ctx *gin.Context) {
 	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
 	if !ok {
 		return
 	}
 
-	targetID := ctx.Param("id")
-	if targetID == "" {
-		utils.Fail(ctx, "User ID is required", http.StatusBadRequest, "missing user ID")
+	var params dto.PolicyDocumentParam
+	if !bindURI(ctx, &params) {
 		return
 	}
 
-	reason := ctx.Query("reason")
+	resp, err := u.userService.ListPolicyVersions(ctx.Request.Context(), userID, email, sessionID, params.Document)
+	if err != nil {
+		utils.Fail(ctx, "Unable to fetch policy versions", http.StatusBadGateway, err.Error())
+		return
+	}
 
-	resp, err := u.userService.RestoreAccountWithContext(ctx.Request.Context(), userID, email, sessionID, targetID, reason)
+	respondWithServiceResponse(ctx, resp)
+}
+
+// PublishPolicy publishes a new version of a policy document (admin). Unless
+// requires_consent is false, users are asked to accept it on their next request.
+func (u *UserController) PublishPolicy(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var req dto.PublishPolicyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := u.userService.PublishPolicy(ctx.Request.Context(), userID, email, sessionID, req)
 	if err != nil {
-		utils.Fail(ctx, "Failed to restore account", http.StatusBadGateway, err.Error())
+		utils.Fail(ctx, "Unable to publish policy", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+// GetConsents shows which policy versions the caller accepted, which still need their
+// consent, and their consent history.
+func (u *UserController) GetConsents(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	resp, err := u.userService.GetConsents(ctx.Request.Context(), userID, email, sessionID)
+	if err != nil {
+		utils.Fail(ctx, "Unable to fetch consents", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+// RecordConsent records the caller accepting the current version of policy documents.
+func (u *UserController) RecordConsent(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var req dto.RecordConsentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := u.userService.RecordConsent(ctx.Request.Context(), userID, email, sessionID, req)
+	if err != nil {
+		utils.Fail(ctx, "Unable to record consent", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+// RequestAccountMerge starts merging a duplicate account into the caller's. Codes are
+// emailed to both accounts and must be entered through ConfirmAccountMerge.
+func (u *UserController) RequestAccountMerge(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var req dto.RequestAccountMergeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := u.userService.RequestAccountMerge(ctx.Request.Context(), userID, email, sessionID, req)
+	if err != nil {
+		utils.Fail(ctx, "Unable to request account merge", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+// GetAccountMerge reports the status of one of the caller's account merges.
+func (u *UserController) GetAccountMerge(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var params dto.AccountMergeIDParam
+	if !bindURI(ctx, &params) {
+		return
+	}
+
+	resp, err := u.userService.GetAccountMerge(ctx.Request.Context(), userID, email, sessionID, params.ID)
+	if err != nil {
+		utils.Fail(ctx, "Unable to fetch account merge", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	respondWithServiceResponse(ctx, resp)
+}
+
+// ConfirmAccountMerge checks the codes sent to both accounts and merges the duplicate
+// into the caller's account. The duplicate's sessions end and the account is deleted.
+func (u *UserController) ConfirmAccountMerge(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var params dto.AccountMergeIDParam
+	if !bindURI(ctx, &params) {
+		return
+	}
+	var req dto.ConfirmAccountMergeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := u.userService.ConfirmAccountMerge(ctx.Request.Context(), userID, email, sessionID, params.ID, req)
+	if err != nil {
+		utils.Fail(ctx, "Unable to merge accounts", http.StatusBadGateway, err.Error())
+		return
+	}
+
+	respondWithServiceResponse(ctx, resp)
+}
+
+// CancelAccountMerge cancels a pending account merge; the emailed codes stop working.
+func (u *UserController) CancelAccountMerge(ctx *gin.Context) {
+	userID, email, sessionID, ok := middleware.GetUserContextFromMiddleware(ctx)
+	if !ok {
+		return
+	}
+
+	var params dto.AccountMergeIDParam
+	if !bindURI(ctx, &params) {
+		return
+	}
+
+	resp, err := u.userService.CancelAccountMerge(ctx.Request.Context(), userID, email, sessionID, params.ID)
+	if err != nil {
+		utils.Fail(ctx, "Unable to cancel account merge", http.StatusBadGateway, err.Error())
 		return
 	}
 
 	respondWithServiceResponse(ctx, resp)
 }
+
+// bindSecurityAuditQuery reads the audit filters and the shared pagination parameters,
+// translated to the page/page_size pair user-service expects.
+func bindSecurityAuditQuery(ctx *gin.Context) (dto.SecurityAuditQuery, pageRequest, bool) {
+	var query dto.SecurityAuditQuery
+	if !bindQuery(ctx, &query) {
+		return query, pageRequest{}, false
+	}
+	page, ok := parsePageRequest(ctx, 20, 100)
+	if !ok {
+		return query, page, false
+	}
+	query.Page, query.PageSize = page.Number(), page.Limit
+	return query, page, true
+}
@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"bff-services/internal/api/dto"
+	"bff-services/internal/cache"
+	middleware "bff-services/internal/middlewares"
+	"bff-services/internal/services"
+	"bff-services/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// fakeRedis answers the commands of the profile cache from memory, as a client hook,
+// so the tests need no server.
+type fakeRedis struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func newFakeRedis(t *testing.T) (*fakeRedis, *redis.Client) {
+	f := &fakeRedis{values: map[string]string{}}
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0"})
+	client.AddHook(f)
+	t.Cleanup(func() { _ = client.Close() })
+	return f, client
+}
+
+func (f *fakeRedis) DialHook(redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, errors.New("fake redis does not dial")
+	}
+}
+
+func (f *fakeRedis) ProcessHook(redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		f.process(cmd)
+		return cmd.Err()
+	}
+}
+
+func (f *fakeRedis) ProcessPipelineHook(redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			f.process(cmd)
+		}
+		return nil
+	}
+}
+
+func (f *fakeRedis) process(cmd redis.Cmder) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	args := cmd.Args()
+	switch strings.ToLower(cmd.Name()) {
+	case "set":
+		f.values[fmt.Sprint(args[1])] = fmt.Sprint(args[2])
+		cmd.(*redis.StatusCmd).SetVal("OK")
+	case "mget":
+		values := make([]interface{}, 0, len(args)-1)
+		for _, arg := range args[1:] {
+			if v, ok := f.values[fmt.Sprint(arg)]; ok {
+				values = append(values, v)
+			} else {
+				values = append(values, nil)
+			}
+		}
+		cmd.(*redis.SliceCmd).SetVal(values)
+	case "del":
+		var n int64
+		for _, arg := range args[1:] {
+			if _, ok := f.values[fmt.Sprint(arg)]; ok {
+				delete(f.values, fmt.Sprint(arg))
+				n++
+			}
+		}
+		cmd.(*redis.IntCmd).SetVal(n)
+	default:
+		cmd.SetErr(fmt.Errorf("fake redis: unsupported command %s", cmd.Name()))
+	}
+}
+
+// profileUserService answers the profile and avatar calls with a fixed status.
+type profileUserService struct {
+	services.UserService
+	status int
+}
+
+func (s *profileUserService) respond() (*types.HTTPResponse, error) {
+	return &types.HTTPResponse{StatusCode: s.status, Body: []byte(`{"status":"success","data":{}}`)}, nil
+}
+
+func (s *profileUserService) UpdateProfileWithContext(ctx context.Context, userID, email, sessionID string, payload dto.UpdateProfileRequest) (*types.HTTPResponse, error) {
+	return s.respond()
+}
+
+func (s *profileUserService) CompleteAvatarUpload(ctx context.Context, userID, email, sessionID, uploadID string) (*types.HTTPResponse, error) {
+	return s.respond()
+}
+
+func (s *profileUserService) RemoveAvatar(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error) {
+	return s.respond()
+}
+
+// TestProfileChangesInvalidateSnippet checks that accepted profile and avatar changes
+// drop the cached profile snippet of the user, and rejected ones keep it.
+func TestProfileChangesInvalidateSnippet(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.MustParse("6f1c1a8e-0c5e-4f4e-9a51-3d2a7f0b9c11")
+
+	cases := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		status     int
+		wantCached bool
+	}{
+		{name: "UpdateProfile", method: http.MethodPut, path: "/profile", body: `{"display_name":"Ada"}`, status: http.StatusOK},
+		{name: "CompleteAvatarUpload", method: http.MethodPost, path: "/avatar/uploads/9b2f1c3e-7d4a-4c1b-8e5f-2a6d0c9b8e71/complete", status: http.StatusOK},
+		{name: "RemoveAvatar", method: http.MethodDelete, path: "/avatar", status: http.StatusOK},
+		{name: "RejectedUpdateKeepsSnippet", method: http.MethodPut, path: "/profile", body: `{"display_name":"Ada"}`, status: http.StatusUnprocessableEntity, wantCached: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, client := newFakeRedis(t)
+			profileCache := cache.NewProfileCache(client)
+			ctx := context.Background()
+			if err := profileCache.SetMany(ctx, []cache.ProfileSnippet{{UserID: userID.String(), DisplayName: "Old name"}}); err != nil {
+				t.Fatalf("seed cache: %v", err)
+			}
+
+			controller := NewUserController(&profileUserService{status: tc.status}, nil)
+			controller.SetProfileCache(profileCache)
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set(middleware.ContextUserIDKey(), userID)
+				c.Set(middleware.ContextUserEmailKey(), "ada@example.com")
+				c.Set(middleware.ContextSessionIDKey(), uuid.New())
+			})
+			router.PUT("/profile", controller.UpdateProfile)
+			router.POST("/avatar/uploads/:id/complete", controller.CompleteAvatarUpload)
+			router.DELETE("/avatar", controller.RemoveAvatar)
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tc.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tc.status, rec.Body.String())
+			}
+
+			snippets, err := profileCache.GetMany(ctx, []string{userID.String()})
+			if err != nil {
+				t.Fatalf("read cache: %v", err)
+			}
+			if _, cached := snippets[userID.String()]; cached != tc.wantCached {
+				t.Fatalf("snippet cached = %v, want %v", cached, tc.wantCached)
+			}
+		})
+	}
+}
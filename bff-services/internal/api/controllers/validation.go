@@ -0,0 +1,30 @@
+package controllers
+
+import (
+	"net/http"
+
+	"bff-services/internal/utils"
+	"bff-services/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bindQuery binds and validates query parameters into obj. It writes a 400 response
+// and returns false on invalid input.
+func bindQuery(c *gin.Context, obj interface{}) bool {
+	if err := c.ShouldBindQuery(obj); err != nil {
+		utils.Fail(c, "Invalid query parameters", http.StatusBadRequest, validation.Describe(err))
+		return false
+	}
+	return true
+}
+
+// bindURI binds and validates path parameters into obj. It writes a 400 response and
+// returns false on invalid input.
+func bindURI(c *gin.Context, obj interface{}) bool {
+	if err := c.ShouldBindUri(obj); err != nil {
+		utils.Fail(c, "Invalid path parameters", http.StatusBadRequest, validation.Describe(err))
+		return false
+	}
+	return true
+}
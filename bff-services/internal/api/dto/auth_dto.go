@@ -7,9 +7,47 @@ type RegisterRequest struct {
 	Password string `json:"password" binding:"required,min=8"`
 }
 
-// LoginRequest represents payload for user login via the BFF.
+// LoginRequest represents payload for user login via the BFF. Identifier is the user's
+// email, username or verified phone number and may be sent instead of Email.
 type LoginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
-	MFACode  string `json:"mfa_code,omitempty"`
+	Email      string `json:"email,omitempty" binding:"required_without=Identifier,omitempty,email"`
+	Identifier string `json:"identifier,omitempty" binding:"required_without=Email"`
+	Password   string `json:"password" binding:"required"`
+	MFACode    string `json:"mfa_code,omitempty"`
+	// WebAuthn answers a passkey challenge instead of MFACode.
+	WebAuthn *WebAuthnAssertion `json:"webauthn,omitempty"`
+}
+
+// PasswordlessLoginRequest asks for an emailed login link or 6-digit login code.
+// BindDevice makes the login usable only with the device token returned to this client.
+type PasswordlessLoginRequest struct {
+	Email      string `json:"email" binding:"required,email"`
+	Method     string `json:"method,omitempty" binding:"omitempty,oneof=link code"`
+	BindDevice bool   `json:"bind_device,omitempty"`
+}
+
+// PasswordlessVerifyRequest completes a passwordless login with the token of the emailed
+// link, or with the email and the emailed code.
+type PasswordlessVerifyRequest struct {
+	Token       string             `json:"token,omitempty" binding:"required_without=Email"`
+	Email       string             `json:"email,omitempty" binding:"required_without=Token,omitempty,email"`
+	Code        string             `json:"code,omitempty" binding:"required_with=Email,omitempty,len=6,numeric"`
+	DeviceToken string             `json:"device_token,omitempty"`
+	MFACode     string             `json:"mfa_code,omitempty"`
+	WebAuthn    *WebAuthnAssertion `json:"webauthn,omitempty"`
+}
+
+// RefreshTokenRequest exchanges a refresh token for a new access/refresh token pair.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// AccountUnlockRequest asks user-services to email an unlock link for a locked-out account.
+type AccountUnlockRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// AccountUnlockConfirmRequest lifts a lockout with the token from the unlock email.
+type AccountUnlockConfirmRequest struct {
+	Token string `json:"token" binding:"required"`
 }
@@ -39,3 +39,17 @@ type GraphQLRequest struct {
 	OperationName string                 `json:"operationName,omitempty"`
 	Variables     map[string]interface{} `json:"variables,omitempty"`
 }
+
+// PersistedGraphQLRequest represents a GraphQL operation sent to the public proxy.
+// Clients may send the full query, only the persisted query hash, or both.
+type PersistedGraphQLRequest struct {
+	Query         string                 `json:"query,omitempty"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	Extensions    struct {
+		PersistedQuery *struct {
+			Version    int    `json:"version"`
+			Sha256Hash string `json:"sha256Hash"`
+		} `json:"persistedQuery,omitempty"`
+	} `json:"extensions,omitempty"`
+}
@@ -11,6 +11,31 @@ type DashboardSummary struct {
 	CurrentStreakDays  int             `json:"current_streak_days"`
 	LongestStreakDays  int             `json:"longest_streak_days"`
 	LastStreakActivity *string         `json:"last_streak_activity,omitempty"`
+	// Recommendations are left out when recommendation-services is unavailable
+	Recommendations *DashboardRecommendations `json:"recommendations,omitempty"`
+}
+
+// DashboardRecommendations mirrors the recommendation service payload: the next courses
+// and lessons for the user, the best first. Personalized is false for a user without
+// progress yet, who gets the most popular content.
+type DashboardRecommendations struct {
+	Items        []RecommendedItem `json:"items"`
+	Personalized bool              `json:"personalized"`
+}
+
+// RecommendedItem is a course or lesson recommended to the user, with the reasons it is:
+// topic, level or popular.
+type RecommendedItem struct {
+	ID            string   `json:"id"`
+	Type          string   `json:"type"`
+	Title         string   `json:"title"`
+	TopicID       *string  `json:"topic_id,omitempty"`
+	LevelID       *string  `json:"level_id,omitempty"`
+	IsFeatured    bool     `json:"is_featured,omitempty"`
+	AverageRating float64  `json:"average_rating,omitempty"`
+	ReviewCount   int      `json:"review_count,omitempty"`
+	Score         float64  `json:"score"`
+	Reasons       []string `json:"reasons"`
 }
 
 // PointsBreakdown reports the lifetime/weekly/monthly point totals.
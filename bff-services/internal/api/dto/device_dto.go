@@ -0,0 +1,39 @@
+package dto
+
+// UpstreamSession mirrors a session entry returned by user-services. The device and
+// location are recorded when the session is created.
+type UpstreamSession struct {
+	ID         string          `json:"id"`
+	UserAgent  string          `json:"user_agent,omitempty"`
+	IPAddr     *string         `json:"ip_addr,omitempty"`
+	Label      string          `json:"label"`
+	Browser    string          `json:"browser"`
+	OS         string          `json:"os"`
+	DeviceType string          `json:"device_type"`
+	Location   *DeviceLocation `json:"location,omitempty"`
+	CreatedAt  string          `json:"created_at"`
+	ExpiresAt  string          `json:"expires_at"`
+}
+
+// DeviceLocation is the approximate location of a device's last IP address.
+type DeviceLocation struct {
+	City        string `json:"city,omitempty"`
+	Region      string `json:"region,omitempty"`
+	Country     string `json:"country,omitempty"`
+	CountryCode string `json:"country_code,omitempty"`
+}
+
+// DeviceSessionResponse represents an active session as shown on the account devices page.
+type DeviceSessionResponse struct {
+	ID         string          `json:"id"`
+	Label      string          `json:"label,omitempty"`
+	Browser    string          `json:"browser"`
+	OS         string          `json:"os"`
+	DeviceType string          `json:"device_type"`
+	UserAgent  string          `json:"user_agent,omitempty"`
+	LastIP     *string         `json:"last_ip,omitempty"`
+	Location   *DeviceLocation `json:"location,omitempty"`
+	CreatedAt  string          `json:"created_at"`
+	ExpiresAt  string          `json:"expires_at"`
+	IsCurrent  bool            `json:"is_current"`
+}
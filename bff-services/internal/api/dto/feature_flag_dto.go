@@ -0,0 +1,11 @@
+package dto
+
+// FeatureFlagRequest defines a feature flag; the name is taken from the path. Listed
+// users and roles get the feature first, then RolloutPercent of the remaining users.
+type FeatureFlagRequest struct {
+	Description    string   `json:"description" binding:"omitempty,max=500"`
+	Enabled        *bool    `json:"enabled" binding:"required"`
+	RolloutPercent int      `json:"rollout_percent" binding:"min=0,max=100"`
+	Users          []string `json:"users" binding:"omitempty,max=1000,dive,required,max=64"`
+	Roles          []string `json:"roles" binding:"omitempty,max=20,dive,required,max=50"`
+}
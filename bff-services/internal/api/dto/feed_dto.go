@@ -0,0 +1,73 @@
+package dto
+
+import "time"
+
+// The kinds of the items of the continue-learning feed.
+const (
+	FeedItemDailyGoal        = "daily_goal"
+	FeedItemReviewFlashcards = "review_flashcards"
+	FeedItemContinueCourse   = "continue_course"
+	FeedItemStartCourse      = "start_course"
+)
+
+// ContinueFeed is what the user should do next, the most pressing first. Partial is set
+// when a source of the feed failed, so its items are missing.
+type ContinueFeed struct {
+	Items       []FeedItem       `json:"items"`
+	Goal        *DailyGoalStatus `json:"goal,omitempty"`
+	Partial     bool             `json:"partial"`
+	GeneratedAt time.Time        `json:"generated_at"`
+}
+
+// FeedItem is an entry of the continue-learning feed. Kind tells which fields are set:
+// the course of a course item, the due cards of a review, the minutes left of the goal.
+type FeedItem struct {
+	Kind             string  `json:"kind"`
+	CourseID         string  `json:"course_id,omitempty"`
+	EnrollmentID     string  `json:"enrollment_id,omitempty"`
+	ProgressPercent  *int    `json:"progress_percent,omitempty"`
+	LastAccessedAt   *string `json:"last_accessed_at,omitempty"`
+	DueCards         int     `json:"due_cards,omitempty"`
+	RemainingMinutes int     `json:"remaining_minutes,omitempty"`
+	// Completed marks a daily goal already met today
+	Completed bool `json:"completed,omitempty"`
+}
+
+// DailyGoalStatus reports the user's study minutes today against their daily goal.
+type DailyGoalStatus struct {
+	TargetMinutes    int  `json:"target_minutes"`
+	MinutesToday     int  `json:"minutes_today"`
+	RemainingMinutes int  `json:"remaining_minutes"`
+	Met              bool `json:"met"`
+}
+
+// CourseEnrollmentResponse mirrors the lesson service enrollment payload.
+type CourseEnrollmentResponse struct {
+	ID              string  `json:"id"`
+	CourseID        string  `json:"course_id"`
+	Status          string  `json:"status"`
+	ProgressPercent int     `json:"progress_percent"`
+	LastAccessedAt  *string `json:"last_accessed_at,omitempty"`
+	EnrolledAt      string  `json:"enrolled_at"`
+}
+
+// CardStatsResponse mirrors the spaced repetition stats payload of the lesson service.
+type CardStatsResponse struct {
+	TotalCards int `json:"total_cards"`
+	DueCards   int `json:"due_cards"`
+}
+
+// DailyActivityResponse mirrors the daily activity payload of the lesson service.
+type DailyActivityResponse struct {
+	ActivityDate     string `json:"activity_dt"`
+	LessonsCompleted int    `json:"lessons_completed"`
+	QuizzesCompleted int    `json:"quizzes_completed"`
+	Minutes          int    `json:"minutes"`
+}
+
+// UserGoalsResponse mirrors the learning goals of the user service preferences payload.
+type UserGoalsResponse struct {
+	LearningGoals struct {
+		DailyMinutes int `json:"daily_minutes"`
+	} `json:"learning_goals"`
+}
@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// UpdateGuestSessionRequest records what a guest picked while browsing. An empty
+// SelectedCourseID clears the selection.
+type UpdateGuestSessionRequest struct {
+	SelectedCourseID string `json:"selected_course_id" binding:"omitempty,uuid4"`
+}
+
+// GuestSessionResponse describes an anonymous guest session. Clients send GuestID in
+// the X-Guest-Session header on guest routes.
+type GuestSessionResponse struct {
+	GuestID          string    `json:"guest_id"`
+	SelectedCourseID string    `json:"selected_course_id,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+// CourseIDParam is the `:course_id` path parameter.
+type CourseIDParam struct {
+	CourseID string `uri:"course_id" binding:"required,uuid"`
+}
+
+// GuestSampleLessonsResponse lists the first lessons of a course a guest may preview.
+type GuestSampleLessonsResponse struct {
+	CourseID     string            `json:"course_id"`
+	Lessons      []json.RawMessage `json:"lessons"`
+	TotalLessons int               `json:"total_lessons"`
+}
+
+// GuestConversionResponse reports what guest state was carried over to the account.
+type GuestConversionResponse struct {
+	EnrolledCourseID string `json:"enrolled_course_id,omitempty"`
+}
@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+// MaintenanceSwitchRequest turns a maintenance switch on or off. Name is "global", a
+// named switch such as "checkout", or a route switch like "route:GET /api/v1/orders".
+type MaintenanceSwitchRequest struct {
+	Name       string     `json:"name" binding:"required,max=300"`
+	Enabled    *bool      `json:"enabled" binding:"required"`
+	Message    string     `json:"message" binding:"omitempty,max=500"`
+	RetryAfter int        `json:"retry_after" binding:"omitempty,min=0,max=86400"`
+	Until      *time.Time `json:"until,omitempty"`
+}
+
+// ServiceStatusResponse tells clients whether the platform or parts of it are disabled.
+type ServiceStatusResponse struct {
+	Maintenance bool     `json:"maintenance"`
+	Message     string   `json:"message,omitempty"`
+	Disabled    []string `json:"disabled"`
+}
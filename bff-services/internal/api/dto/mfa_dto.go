@@ -1,5 +1,7 @@
 package dto
 
+import "encoding/json"
+
 // MFASetupRequest represents payload to setup MFA.
 type MFASetupRequest struct {
 	Type  string `json:"type" binding:"required"`
@@ -17,3 +19,84 @@ type MFADisableRequest struct {
 	MethodID string `json:"method_id" binding:"required"`
 	Password string `json:"password" binding:"required"`
 }
+
+// PasskeyLoginBeginRequest starts a passkey login. Without an email the browser offers
+// every discoverable passkey for the site.
+type PasskeyLoginBeginRequest struct {
+	Email string `json:"email" binding:"omitempty,email"`
+}
+
+// PasskeyRegistrationBeginRequest starts registering a passkey under a device nickname.
+type PasskeyRegistrationBeginRequest struct {
+	Label string `json:"label" binding:"omitempty,max=64"`
+}
+
+// PasskeyRegistrationFinishRequest completes passkey registration. Credential is the
+// JSON form of the created PublicKeyCredential and is verified by the user service.
+type PasskeyRegistrationFinishRequest struct {
+	ChallengeID string          `json:"challenge_id" binding:"required,uuid"`
+	Credential  json.RawMessage `json:"credential" binding:"required"`
+}
+
+// WebAuthnAssertion answers a passkey login challenge. Credential is the JSON form of
+// the PublicKeyCredential returned by navigator.credentials.get().
+type WebAuthnAssertion struct {
+	ChallengeID string          `json:"challenge_id" binding:"required,uuid"`
+	Credential  json.RawMessage `json:"credential" binding:"required"`
+}
+
+// PasskeyIDParam is the `:id` path parameter of passkey routes.
+type PasskeyIDParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// PasskeyRenameRequest changes a passkey's device nickname.
+type PasskeyRenameRequest struct {
+	Label string `json:"label" binding:"required,max=64"`
+}
+
+// PasskeyRevokeRequest revokes a passkey; the password is re-checked.
+type PasskeyRevokeRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// MFAOrderRequest sets the fallback order of the caller's MFA methods, primary first.
+type MFAOrderRequest struct {
+	MethodIDs []string `json:"method_ids" binding:"required,min=1,dive,uuid"`
+}
+
+// MFAMethodIDParam is the `:id` path parameter of phone MFA routes.
+type MFAMethodIDParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// PhoneMFASetupRequest adds a phone number (E.164) for SMS or voice codes.
+type PhoneMFASetupRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required,e164"`
+	Channel     string `json:"channel,omitempty" binding:"omitempty,oneof=sms voice"`
+	Label       string `json:"label,omitempty" binding:"omitempty,max=64"`
+}
+
+// PhoneMFAConfirmRequest confirms a phone number with the code sent to it.
+type PhoneMFAConfirmRequest struct {
+	Code string `json:"code" binding:"required,numeric,min=4,max=10"`
+}
+
+// PhoneMFARevokeRequest removes a phone number; the password is re-checked.
+type PhoneMFARevokeRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// OTPSendRequest asks for a new code, optionally on the other channel.
+type OTPSendRequest struct {
+	Channel string `json:"channel,omitempty" binding:"omitempty,oneof=sms voice"`
+}
+
+// LoginOTPSendRequest asks for a login code after /users/login answered MFA_REQUIRED.
+// Identifier is the email, username or phone number the login used.
+type LoginOTPSendRequest struct {
+	Email      string `json:"email,omitempty" binding:"required_without=Identifier,omitempty,email"`
+	Identifier string `json:"identifier,omitempty" binding:"required_without=Email"`
+	Password   string `json:"password" binding:"required"`
+	Channel    string `json:"channel,omitempty" binding:"omitempty,oneof=sms voice"`
+}
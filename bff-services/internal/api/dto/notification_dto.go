@@ -1,5 +1,7 @@
 package dto
 
+import "encoding/json"
+
 // CreateNotificationTemplateRequest represents payload for creating a notification template
 type CreateNotificationTemplateRequest struct {
 	Type  string                 `json:"type" binding:"required"`
@@ -31,6 +33,51 @@ type SendNotificationToUsersRequest struct {
 	UserIDs []string `json:"user_ids" binding:"required,min=1"`
 }
 
+// UpdateNotificationPreferencesRequest represents payload for updating a user's notification preferences.
+// Omitted fields keep their current value.
+type UpdateNotificationPreferencesRequest struct {
+	InAppEnabled    *bool           `json:"in_app_enabled,omitempty"`
+	EmailEnabled    *bool           `json:"email_enabled,omitempty"`
+	PushEnabled     *bool           `json:"push_enabled,omitempty"`
+	Types           map[string]bool `json:"types,omitempty"`
+	QuietHoursStart *string         `json:"quiet_hours_start,omitempty" binding:"omitempty,datetime=15:04"`
+	QuietHoursEnd   *string         `json:"quiet_hours_end,omitempty" binding:"omitempty,datetime=15:04"`
+	// Digest gathers low-priority email and push notifications in one email: off, daily or weekly
+	Digest *string `json:"digest,omitempty" binding:"omitempty,oneof=off daily weekly"`
+}
+
+// MessageTemplateListQuery filters the message templates; every field is optional.
+type MessageTemplateListQuery struct {
+	Type    string `form:"type"`
+	Channel string `form:"channel" binding:"omitempty,oneof=email push in_app"`
+	Locale  string `form:"locale"`
+}
+
+// CreateMessageTemplateRequest is the copy of a notification type on a channel in one
+// locale. Title and body may use {{variables}} from the notification data; HTML is for
+// email templates only.
+type CreateMessageTemplateRequest struct {
+	Type    string  `json:"type" binding:"required"`
+	Channel string  `json:"channel" binding:"required,oneof=email push in_app"`
+	Locale  string  `json:"locale" binding:"required"`
+	Title   string  `json:"title" binding:"required"`
+	Body    string  `json:"body" binding:"required"`
+	HTML    *string `json:"html,omitempty"`
+}
+
+// UpdateMessageTemplateRequest changes the copy of a message template. Omitted fields keep
+// their value; an explicit null html removes the HTML body.
+type UpdateMessageTemplateRequest struct {
+	Title *string         `json:"title,omitempty"`
+	Body  *string         `json:"body,omitempty"`
+	HTML  json.RawMessage `json:"html,omitempty"`
+}
+
+// PreviewMessageTemplateRequest is the sample data a template is rendered with.
+type PreviewMessageTemplateRequest struct {
+	Data map[string]interface{} `json:"data"`
+}
+
 // NotificationTemplateResponse represents a notification template
 type NotificationTemplateResponse struct {
 	ID        string                 `json:"id"`
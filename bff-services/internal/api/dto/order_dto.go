@@ -21,12 +21,26 @@ type CancelOrderRequest struct {
 	Reason string `json:"reason" binding:"required"`
 }
 
+// RefundOrderRequest captures the payload to request a refund for a paid order.
+// Amount is in minor units; when omitted the full payment amount is refunded.
+type RefundOrderRequest struct {
+	Reason string `json:"reason" binding:"required,oneof=dissatisfaction technical_issues duplicate_purchase accidental_purchase course_not_as_described other"`
+	Amount *int64 `json:"amount,omitempty" binding:"omitempty,min=1"`
+}
+
+// RefundListQuery captures pagination query params for listing refund requests.
+type RefundListQuery struct {
+	Limit  int `form:"limit"`
+	Offset int `form:"offset"`
+}
+
 // OrderListQuery captures the supported query parameters for listing orders.
 type OrderListQuery struct {
 	Limit     int    `form:"limit"`
 	Offset    int    `form:"offset"`
 	Page      int    `form:"page"`
 	Status    string `form:"status"`
+	Sort      string `form:"sort"`
 	SortBy    string `form:"sort_by"`
 	SortOrder string `form:"sort_order"`
 }
@@ -65,3 +79,35 @@ type CouponListQuery struct {
 	Offset int `form:"offset"`
 	Page   int `form:"page"`
 }
+
+// AdminOrderListQuery captures query params for listing orders across all users.
+type AdminOrderListQuery struct {
+	Limit  int    `form:"limit"`
+	Offset int    `form:"offset"`
+	UserID string `form:"user_id"`
+	Status string `form:"status"`
+}
+
+// AdminUpdateOrderRequest captures the fields an administrator may change on an order.
+type AdminUpdateOrderRequest struct {
+	Status        *string                 `json:"status,omitempty" binding:"omitempty,oneof=created pending_payment paid failed cancelled refunded"`
+	FailureReason *string                 `json:"failure_reason,omitempty" binding:"omitempty,max=500"`
+	Metadata      *map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// AdminSagaListQuery captures the filters supported by the admin saga listing.
+type AdminSagaListQuery struct {
+	Limit   int    `form:"limit"`
+	Offset  int    `form:"offset"`
+	Type    string `form:"type"`
+	Status  string `form:"status"`
+	OrderID string `form:"order_id"`
+	Stuck   bool   `form:"stuck"`
+}
+
+// OrderStatsQuery captures the filters supported by the order statistics endpoint.
+type OrderStatsQuery struct {
+	UserID    string `form:"user_id"`
+	StartDate string `form:"start_date"`
+	EndDate   string `form:"end_date"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+// PageEnvelope is the single pagination shape returned by every BFF list endpoint,
+// regardless of how the downstream service paginates.
+type PageEnvelope struct {
+	Items      interface{} `json:"items"`
+	Total      *int64      `json:"total,omitempty"`
+	NextCursor *string     `json:"next_cursor"`
+}
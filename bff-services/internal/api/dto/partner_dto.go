@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"bff-services/internal/apikeys"
+)
+
+// IssueAPIKeyRequest is the admin payload for issuing a partner API key.
+type IssueAPIKeyRequest struct {
+	Name      string   `json:"name" binding:"required,max=100"`
+	Scopes    []string `json:"scopes" binding:"required,min=1,dive,oneof=enrollments:write progress:read"`
+	RateLimit int      `json:"rate_limit" binding:"omitempty,min=1,max=6000"`
+}
+
+// IssuedAPIKeyResponse returns a newly issued key. APIKey is the plaintext secret and is
+// only ever returned here.
+type IssuedAPIKeyResponse struct {
+	APIKey string       `json:"api_key"`
+	Key    *apikeys.Key `json:"key"`
+}
+
+// APIKeyIDParam is the `:id` path parameter of API key admin routes.
+type APIKeyIDParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// PartnerEnrollmentRequest provisions a course enrollment for a user on a partner's behalf.
+type PartnerEnrollmentRequest struct {
+	UserID   string `json:"user_id" binding:"required,uuid"`
+	CourseID string `json:"course_id" binding:"required,uuid4"`
+}
+
+// PartnerProgressExport is a user's learning progress as exported to partners.
+type PartnerProgressExport struct {
+	UserID      string                  `json:"user_id"`
+	Enrollments json.RawMessage         `json:"enrollments"`
+	LessonStats UserLessonStatsResponse `json:"lesson_stats"`
+	Points      UserPointsResponse      `json:"points"`
+	ExportedAt  time.Time               `json:"exported_at"`
+}
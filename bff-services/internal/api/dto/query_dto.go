@@ -0,0 +1,111 @@
+package dto
+
+// DateRangeQuery bounds activity lookups to an inclusive date range.
+type DateRangeQuery struct {
+	DateFrom string `form:"date_from" binding:"omitempty,date"`
+	DateTo   string `form:"date_to" binding:"omitempty,date"`
+}
+
+// MonthQuery selects a calendar month; both fields default to the current month downstream.
+type MonthQuery struct {
+	Year  int `form:"year" binding:"omitempty,min=2000,max=9999"`
+	Month int `form:"month" binding:"omitempty,min=1,max=12"`
+}
+
+// EnrollmentListQuery filters the caller's course enrollments. The page is read with
+// the other list parameters, see queryparams.ParsePage.
+type EnrollmentListQuery struct {
+	Status string `form:"status"`
+}
+
+// UserIDParam is the `:user_id` path parameter.
+type UserIDParam struct {
+	UserID string `uri:"user_id" binding:"required,uuid"`
+}
+
+// WeekKeyParam is the `:week_key` path parameter of weekly leaderboards.
+type WeekKeyParam struct {
+	WeekKey string `uri:"week_key" binding:"required,week_key"`
+}
+
+// MonthKeyParam is the `:month_key` path parameter of monthly leaderboards.
+type MonthKeyParam struct {
+	MonthKey string `uri:"month_key" binding:"required,month_key"`
+}
+
+// ActivityDateParam is the `:activity_date` path parameter.
+type ActivityDateParam struct {
+	ActivityDate string `uri:"activity_date" binding:"required,date"`
+}
+
+// AuditLogQuery filters the admin audit log. From and To are RFC 3339 timestamps.
+type AuditLogQuery struct {
+	ActorID        string `form:"actor_id" binding:"omitempty,max=128"`
+	ImpersonatorID string `form:"impersonator_id" binding:"omitempty,max=128"`
+	Action         string `form:"action" binding:"omitempty,max=256"`
+	TargetID       string `form:"target_id" binding:"omitempty,max=128"`
+	Outcome        string `form:"outcome" binding:"omitempty,oneof=success denied failure"`
+	From           string `form:"from" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To             string `form:"to" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Limit          *int   `form:"limit" binding:"omitempty,min=1,max=200"`
+	Cursor         string `form:"cursor" binding:"omitempty,max=64"`
+}
+
+// SecurityAuditQuery filters the account security events kept by user-service. Action
+// matches exactly or, when it ends in ".*", a whole category such as "mfa.*". UserID and
+// ActorID only apply to the admin listing. From and To are RFC 3339 timestamps. Page and
+// PageSize are filled from the shared pagination parameters.
+type SecurityAuditQuery struct {
+	Action   string `form:"action" binding:"omitempty,max=100"`
+	UserID   string `form:"user_id" binding:"omitempty,uuid"`
+	ActorID  string `form:"actor_id" binding:"omitempty,uuid"`
+	From     string `form:"from" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To       string `form:"to" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Page     int    `form:"-"`
+	PageSize int    `form:"-"`
+}
+
+// LoginHistoryQuery filters the caller's sign-in history. Result is success or failure;
+// From and To are RFC 3339 timestamps. Page and PageSize are filled from the shared
+// pagination parameters.
+type LoginHistoryQuery struct {
+	Result   string `form:"result" binding:"omitempty,oneof=success failure"`
+	From     string `form:"from" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To       string `form:"to" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Page     int    `form:"-"`
+	PageSize int    `form:"-"`
+}
+
+// UserMetricsQuery selects how many days, up to and including today (UTC), the daily
+// series of the admin dashboard's user metrics cover.
+type UserMetricsQuery struct {
+	Days int `form:"days" binding:"omitempty,min=1,max=90"`
+}
+
+// TargetUserIDParam is the `:id` path parameter of admin user routes.
+type TargetUserIDParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// AdminUserQuery filters, sorts and pages the admin user listing. The boolean filters
+// take "true" or "false" and the ranges are RFC 3339 timestamps, From inclusive and To
+// exclusive. Cursor is the next_cursor of the previous page, passed through to
+// user-service as is; Page and PageSize are the legacy page-based parameters.
+type AdminUserQuery struct {
+	Status        string `form:"status" binding:"omitempty,oneof=active invited locked disabled deleted"`
+	Role          string `form:"role" binding:"omitempty,oneof=student teacher admin super-admin"`
+	Search        string `form:"search" binding:"omitempty,max=254"`
+	EmailVerified string `form:"email_verified" binding:"omitempty,oneof=true false"`
+	MFAEnabled    string `form:"mfa_enabled" binding:"omitempty,oneof=true false"`
+	Locked        string `form:"locked" binding:"omitempty,oneof=true false"`
+	CreatedFrom   string `form:"created_from" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	CreatedTo     string `form:"created_to" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	LastLoginFrom string `form:"last_login_from" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	LastLoginTo   string `form:"last_login_to" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Sort          string `form:"sort" binding:"omitempty,oneof=created_at last_login_at email role status"`
+	Order         string `form:"order" binding:"omitempty,oneof=asc desc"`
+	Limit         int    `form:"limit" binding:"omitempty,min=1"`
+	PageSize      int    `form:"page_size" binding:"omitempty,min=1"`
+	Page          int    `form:"page" binding:"omitempty,min=1"`
+	Cursor        string `form:"cursor" binding:"omitempty,max=512"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+// SynonymRuleRequest is an entry of the search dictionary. Its terms match each other in
+// searches; its misspellings are corrected to its first term. search-services validates
+// the bounds of each.
+type SynonymRuleRequest struct {
+	Terms        []string `json:"terms" binding:"required,min=1,max=20"`
+	Misspellings []string `json:"misspellings,omitempty" binding:"omitempty,max=20"`
+}
@@ -8,6 +8,86 @@ type UpdateProfileRequest struct {
 	TimeZone    string `json:"time_zone,omitempty"`
 }
 
+// UpdatePreferencesRequest changes some of the caller's preferences; omitted fields keep
+// their value. user-service validates time zones, reminder times and reminder days.
+type UpdatePreferencesRequest struct {
+	Locale        *string                       `json:"locale,omitempty" binding:"omitempty,len=2,alpha,lowercase"`
+	TimeZone      *string                       `json:"time_zone,omitempty" binding:"omitempty,max=64"`
+	Theme         *string                       `json:"theme,omitempty" binding:"omitempty,oneof=system light dark"`
+	Notifications *NotificationPreferencesPatch `json:"notifications,omitempty"`
+	LearningGoals *LearningGoalsPatch           `json:"learning_goals,omitempty"`
+}
+
+// NotificationPreferencesPatch changes some notification channels.
+type NotificationPreferencesPatch struct {
+	Email *bool `json:"email,omitempty"`
+	Push  *bool `json:"push,omitempty"`
+	SMS   *bool `json:"sms,omitempty"`
+	InApp *bool `json:"in_app,omitempty"`
+}
+
+// LearningGoalsPatch changes some learning goals. An empty ReminderTime turns reminders
+// off; ReminderDays replaces the whole list.
+type LearningGoalsPatch struct {
+	DailyMinutes  *int      `json:"daily_minutes,omitempty" binding:"omitempty,min=0,max=1440"`
+	WeeklyLessons *int      `json:"weekly_lessons,omitempty" binding:"omitempty,min=0,max=100"`
+	ReminderTime  *string   `json:"reminder_time,omitempty"`
+	ReminderDays  *[]string `json:"reminder_days,omitempty"`
+}
+
+// OnboardingStepParam is the `:step` path parameter of onboarding routes.
+type OnboardingStepParam struct {
+	Step string `uri:"step" binding:"required,oneof=email_verified profile_completed level_test_taken first_lesson_started"`
+}
+
+// AdvanceOnboardingRequest completes or skips an onboarding step; Status defaults to
+// completed. Only the level test can be skipped.
+type AdvanceOnboardingRequest struct {
+	Status string `json:"status,omitempty" binding:"omitempty,oneof=completed skipped"`
+}
+
+// UsernameAvailabilityQuery asks whether a username can be claimed.
+type UsernameAvailabilityQuery struct {
+	Username string `form:"username" binding:"required,max=64"`
+}
+
+// PhoneAvailabilityQuery asks whether a phone number can be added for signing in.
+type PhoneAvailabilityQuery struct {
+	PhoneNumber string `form:"phone_number" binding:"required,max=32"`
+}
+
+// SetUsernameRequest claims or changes the caller's username. user-service checks the
+// format, reserved names and availability.
+type SetUsernameRequest struct {
+	Username string `json:"username" binding:"required,max=64"`
+}
+
+// PhoneVerificationRequest sends a code to a phone number the caller wants to sign in
+// with. The number must include its country code.
+type PhoneVerificationRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required,max=32"`
+	Channel     string `json:"channel,omitempty" binding:"omitempty,oneof=sms voice"`
+}
+
+// VerifyPhoneRequest confirms a phone number with the code sent to it.
+type VerifyPhoneRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required,max=32"`
+	Code        string `json:"code" binding:"required,numeric"`
+}
+
+// CreateAvatarUploadRequest describes the image the caller is about to upload as their
+// avatar. The returned upload URL only accepts a file of this type and size;
+// user-service enforces the size limit.
+type CreateAvatarUploadRequest struct {
+	ContentType string `json:"content_type" binding:"required,oneof=image/jpeg image/png image/gif"`
+	SizeBytes   int64  `json:"size_bytes" binding:"required,min=1"`
+}
+
+// AvatarUploadIDParam is the `:id` path parameter of avatar upload routes.
+type AvatarUploadIDParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
 type UserWithProgressResponse struct {
 	ID            string      `json:"id"`
 	Email         string      `json:"email"`
@@ -65,3 +145,246 @@ type StreakData struct {
 type UpdateUserRoleRequest struct {
 	Role string `json:"role" binding:"required,oneof=student teacher admin super-admin"`
 }
+
+// DataExportIDParam is the `:id` path parameter of data export routes.
+type DataExportIDParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// ErasureSelfRequest confirms the caller's password before their account is scheduled
+// for erasure.
+type ErasureSelfRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// DeactivateAccountRequest confirms the caller's password before their account is
+// deactivated. The reason is optional feedback on why they are leaving.
+type DeactivateAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+	Reason   string `json:"reason,omitempty" binding:"omitempty,max=500"`
+}
+
+// ErasureRecoverRequest restores an account its owner deleted, before the retention
+// window ends and their data is erased.
+type ErasureRecoverRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// ErasureAdminRequest schedules the erasure of another user's account.
+type ErasureAdminRequest struct {
+	Reason string `json:"reason" binding:"omitempty,max=500"`
+}
+
+// ErasureQuery filters the erasure request listing. Page and PageSize are filled from
+// the shared pagination parameters.
+type ErasureQuery struct {
+	Kind     string `form:"kind" binding:"omitempty,oneof=erasure deactivation"`
+	Status   string `form:"status" binding:"omitempty,oneof=scheduled cancelled anonymized completed incomplete"`
+	UserID   string `form:"user_id" binding:"omitempty,uuid"`
+	Page     int    `form:"-"`
+	PageSize int    `form:"-"`
+}
+
+// UserImportRequest provisions accounts in bulk (admin). For a CSV upload only the
+// defaults are read from the form or query string. Rows are validated by user-service,
+// which reports each one's outcome.
+type UserImportRequest struct {
+	Organization string          `json:"organization,omitempty" form:"organization" binding:"omitempty,max=200"`
+	Role         string          `json:"role,omitempty" form:"role" binding:"omitempty,oneof=student teacher"`
+	SendInvites  *bool           `json:"send_invites,omitempty" form:"send_invites"`
+	Users        []UserImportRow `json:"users" form:"-"`
+}
+
+// UserImportRow is one account to import.
+type UserImportRow struct {
+	Email        string `json:"email"`
+	Name         string `json:"name,omitempty"`
+	Role         string `json:"role,omitempty"`
+	Organization string `json:"organization,omitempty"`
+}
+
+// ErasureIDParam is the `:id` path parameter of erasure request routes.
+type ErasureIDParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// CreateOrganizationRequest creates an organization (admin). OwnerID makes an existing
+// account its first owner.
+type CreateOrganizationRequest struct {
+	Name      string `json:"name" binding:"required,min=1,max=200"`
+	SeatLimit *int   `json:"seat_limit,omitempty" binding:"omitempty,min=0"`
+	OwnerID   string `json:"owner_id,omitempty" binding:"omitempty,uuid"`
+}
+
+// UpdateOrganizationRequest renames an organization or changes its seat limit (admin).
+type UpdateOrganizationRequest struct {
+	Name           *string `json:"name,omitempty" binding:"omitempty,min=1,max=200"`
+	SeatLimit      *int    `json:"seat_limit,omitempty" binding:"omitempty,min=0"`
+	ClearSeatLimit bool    `json:"clear_seat_limit,omitempty"`
+}
+
+// OrganizationQuery filters the organization listing. Page and PageSize are filled
+// from the shared pagination parameters.
+type OrganizationQuery struct {
+	Search   string `form:"search" binding:"omitempty,max=200"`
+	Page     int    `form:"-"`
+	PageSize int    `form:"-"`
+}
+
+// OrganizationMemberQuery filters an organization's member listing. Page and PageSize
+// are filled from the shared pagination parameters.
+type OrganizationMemberQuery struct {
+	Role     string `form:"role" binding:"omitempty,oneof=owner manager learner"`
+	Page     int    `form:"-"`
+	PageSize int    `form:"-"`
+}
+
+// AddOrganizationMemberRequest adds an existing account, by ID or email, to an
+// organization.
+type AddOrganizationMemberRequest struct {
+	UserID string `json:"user_id,omitempty" binding:"required_without=Email,omitempty,uuid"`
+	Email  string `json:"email,omitempty" binding:"required_without=UserID,omitempty,email"`
+	Role   string `json:"role" binding:"required,oneof=owner manager learner"`
+}
+
+// UpdateOrganizationMemberRequest changes a member's role.
+type UpdateOrganizationMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=owner manager learner"`
+}
+
+// OrganizationIDParam is the `:id` path parameter of organization routes.
+type OrganizationIDParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// OrganizationMemberParam is the `:id` and `:user_id` path parameters of organization
+// member routes.
+type OrganizationMemberParam struct {
+	ID     string `uri:"id" binding:"required,uuid"`
+	UserID string `uri:"user_id" binding:"required,uuid"`
+}
+
+// CreateInvitationRequest invites someone without an account to register. OrgRole
+// requires OrganizationID; user-service defaults Role to student and OrgRole to learner.
+type CreateInvitationRequest struct {
+	Email          string `json:"email" binding:"required,email"`
+	Role           string `json:"role,omitempty" binding:"omitempty,oneof=student teacher"`
+	OrganizationID string `json:"organization_id,omitempty" binding:"required_with=OrgRole,omitempty,uuid"`
+	OrgRole        string `json:"org_role,omitempty" binding:"omitempty,oneof=owner manager learner"`
+}
+
+// InvitationQuery filters the invitation listing. Page and PageSize are filled from the
+// shared pagination parameters.
+type InvitationQuery struct {
+	OrganizationID string `form:"organization_id" binding:"omitempty,uuid"`
+	Status         string `form:"status" binding:"omitempty,oneof=pending accepted revoked expired"`
+	Email          string `form:"email" binding:"omitempty,max=320"`
+	Page           int    `form:"-"`
+	PageSize       int    `form:"-"`
+}
+
+// InvitationIDParam is the `:id` path parameter of invitation routes.
+type InvitationIDParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// CreateAccessTokenRequest creates a personal access token. Scopes are read and write;
+// write implies read.
+type CreateAccessTokenRequest struct {
+	Name          string   `json:"name" binding:"required,min=1,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,dive,oneof=read write"`
+	ExpiresInDays int      `json:"expires_in_days,omitempty" binding:"omitempty,min=1,max=3650"`
+}
+
+// AccessTokenIDParam is the `:id` path parameter of access token routes.
+type AccessTokenIDParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// RegisterPushTokenRequest registers the device token of an app install for push
+// notifications.
+type RegisterPushTokenRequest struct {
+	Provider    string `json:"provider" binding:"required,oneof=fcm apns"`
+	Token       string `json:"token" binding:"required,max=4096"`
+	Platform    string `json:"platform" binding:"required,oneof=ios android web"`
+	AppID       string `json:"app_id,omitempty" binding:"omitempty,max=255"`
+	AppVersion  string `json:"app_version,omitempty" binding:"omitempty,max=64"`
+	OSVersion   string `json:"os_version,omitempty" binding:"omitempty,max=64"`
+	DeviceModel string `json:"device_model,omitempty" binding:"omitempty,max=128"`
+}
+
+// UnregisterPushTokenRequest removes a device token by its value, e.g. when signing out.
+type UnregisterPushTokenRequest struct {
+	Provider string `json:"provider" binding:"required,oneof=fcm apns"`
+	Token    string `json:"token" binding:"required,max=4096"`
+}
+
+// PushTokenIDParam is the `:id` path parameter of push token routes.
+type PushTokenIDParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// PublishPolicyRequest publishes a new version of the terms of service or privacy policy
+// (admin). RequiresConsent defaults to true.
+type PublishPolicyRequest struct {
+	Document        string `json:"document" binding:"required,oneof=terms privacy"`
+	Version         string `json:"version" binding:"required,max=64"`
+	URL             string `json:"url" binding:"required,url,max=2048"`
+	Summary         string `json:"summary,omitempty" binding:"omitempty,max=2000"`
+	RequiresConsent *bool  `json:"requires_consent,omitempty"`
+}
+
+// PolicyDocumentParam is the `:document` path parameter of policy routes.
+type PolicyDocumentParam struct {
+	Document string `uri:"document" binding:"required,oneof=terms privacy"`
+}
+
+// ConsentItem accepts the current version of a policy document.
+type ConsentItem struct {
+	Document string `json:"document" binding:"required,oneof=terms privacy"`
+	Version  string `json:"version" binding:"required,max=64"`
+}
+
+// RecordConsentRequest records the caller accepting one or more policy documents.
+type RecordConsentRequest struct {
+	Consents []ConsentItem `json:"consents" binding:"required,min=1,max=2,dive"`
+}
+
+// RequestAccountMergeRequest names the duplicate account to merge into the caller's.
+type RequestAccountMergeRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ConfirmAccountMergeRequest carries the codes emailed to the caller's account
+// (target_code) and to the account being merged (source_code).
+type ConfirmAccountMergeRequest struct {
+	TargetCode string `json:"target_code" binding:"required,max=16"`
+	SourceCode string `json:"source_code" binding:"required,max=16"`
+}
+
+// AccountMergeIDParam is the `:id` path parameter of account merge routes.
+type AccountMergeIDParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// StartImpersonationRequest opens a session as the user in the path. Reason is recorded
+// in the audit log; DurationMinutes defaults to the user-service setting.
+type StartImpersonationRequest struct {
+	Reason          string `json:"reason" binding:"required,min=5,max=500"`
+	DurationMinutes int    `json:"duration_minutes,omitempty" binding:"omitempty,min=1,max=1440"`
+}
+
+// ImpersonationIDParam is the `:id` path parameter of impersonation routes, the ID of
+// the impersonation session.
+type ImpersonationIDParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// AcceptInvitationRequest completes the registration of an invitee with the token from
+// their invitation email.
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Name     string `json:"name,omitempty" binding:"omitempty,max=200"`
+}
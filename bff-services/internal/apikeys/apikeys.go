@@ -0,0 +1,263 @@
+package apikeys
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/ratelimit"
+	"github.com/ductan2/microservice-app/shared/ratelimit/redisstore"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Scopes a partner key can be granted. Each partner endpoint requires exactly one scope.
+const (
+	ScopeEnrollmentsWrite = "enrollments:write"
+	ScopeProgressRead     = "progress:read"
+)
+
+// DefaultRateLimit is the per-key request budget per minute when none is given at issuance.
+const DefaultRateLimit = 60
+
+// RatePolicy names the token bucket of the partner keys. An override in RATE_LIMITS
+// replaces the budget of every key, such as to throttle partners during an incident.
+const RatePolicy = "partner.api_key"
+
+// keyPrefix marks partner keys so they are recognisable in logs and secret scanners.
+const keyPrefix = "pk_"
+
+const (
+	indexKey      = "api_keys"
+	displayLength = 8
+)
+
+var (
+	// ErrKeyNotFound is returned when a presented key does not match any issued key.
+	ErrKeyNotFound = errors.New("api key not found")
+	// ErrKeyRevoked is returned when a presented key has been revoked.
+	ErrKeyRevoked = errors.New("api key revoked")
+)
+
+// Key is an issued partner key. The plaintext secret is never stored; keys are looked
+// up by the SHA-256 hash of the presented value.
+type Key struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	Scopes    []string   `json:"scopes"`
+	RateLimit int        `json:"rate_limit"`
+	CreatedBy string     `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// HasScope reports whether the key was granted scope.
+func (k *Key) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidScope reports whether scope is one the gateway knows about.
+func IsValidScope(scope string) bool {
+	return scope == ScopeEnrollmentsWrite || scope == ScopeProgressRead
+}
+
+// IssueParams describes a key to be issued.
+type IssueParams struct {
+	Name      string
+	Scopes    []string
+	RateLimit int
+	CreatedBy string
+}
+
+// Store persists partner keys in Redis:
+//
+//	api_key:<sha256>                  JSON-encoded Key
+//	api_keys                          hash of key ID -> sha256, used for listing and revocation
+//	ratelimit:partner.api_key:<id>    token bucket of the key, see shared/ratelimit
+type Store struct {
+	redisClient *redis.Client
+	limiter     *ratelimit.Limiter
+}
+
+// NewStore creates a key store backed by redisClient. Rate limits fail open, so a Redis
+// blip does not lock partners out; overrides are the RATE_LIMITS policies.
+func NewStore(redisClient *redis.Client, overrides ratelimit.Policies) *Store {
+	return &Store{
+		redisClient: redisClient,
+		limiter: ratelimit.New(redisstore.New(redisClient), ratelimit.Config{
+			Overrides: overrides,
+			FailOpen:  true,
+		}),
+	}
+}
+
+func hashKey(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
+
+func recordKey(hash string) string {
+	return "api_key:" + hash
+}
+
+// Issue creates a new key and returns its plaintext value, which is shown only once.
+func (s *Store) Issue(ctx context.Context, params IssueParams) (string, *Key, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, fmt.Errorf("generate api key: %w", err)
+	}
+	plaintext := keyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	rateLimit := params.RateLimit
+	if rateLimit <= 0 {
+		rateLimit = DefaultRateLimit
+	}
+
+	key := &Key{
+		ID:        uuid.NewString(),
+		Name:      params.Name,
+		Prefix:    plaintext[:len(keyPrefix)+displayLength],
+		Scopes:    params.Scopes,
+		RateLimit: rateLimit,
+		CreatedBy: params.CreatedBy,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	data, err := json.Marshal(key)
+	if err != nil {
+		return "", nil, err
+	}
+
+	hash := hashKey(plaintext)
+	pipe := s.redisClient.TxPipeline()
+	pipe.Set(ctx, recordKey(hash), data, 0)
+	pipe.HSet(ctx, indexKey, key.ID, hash)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", nil, fmt.Errorf("store api key: %w", err)
+	}
+
+	return plaintext, key, nil
+}
+
+// Lookup resolves a presented plaintext key.
+func (s *Store) Lookup(ctx context.Context, plaintext string) (*Key, error) {
+	if !strings.HasPrefix(plaintext, keyPrefix) {
+		return nil, ErrKeyNotFound
+	}
+
+	data, err := s.redisClient.Get(ctx, recordKey(hashKey(plaintext))).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var key Key
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("decode api key: %w", err)
+	}
+	if key.RevokedAt != nil {
+		return nil, ErrKeyRevoked
+	}
+	return &key, nil
+}
+
+// List returns all issued keys, including revoked ones, newest first.
+func (s *Store) List(ctx context.Context) ([]Key, error) {
+	index, err := s.redisClient.HGetAll(ctx, indexKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(index) == 0 {
+		return []Key{}, nil
+	}
+
+	recordKeys := make([]string, 0, len(index))
+	for _, hash := range index {
+		recordKeys = append(recordKeys, recordKey(hash))
+	}
+
+	values, err := s.redisClient.MGet(ctx, recordKeys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]Key, 0, len(values))
+	for _, value := range values {
+		raw, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var key Key
+		if err := json.Unmarshal([]byte(raw), &key); err != nil {
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+// Revoke marks a key as revoked. The record is kept so the key stays visible in listings.
+func (s *Store) Revoke(ctx context.Context, id string) (*Key, error) {
+	hash, err := s.redisClient.HGet(ctx, indexKey, id).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.redisClient.Get(ctx, recordKey(hash)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var key Key
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("decode api key: %w", err)
+	}
+	if key.RevokedAt == nil {
+		now := time.Now().UTC()
+		key.RevokedAt = &now
+		updated, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.redisClient.Set(ctx, recordKey(hash), updated, 0).Err(); err != nil {
+			return nil, err
+		}
+	}
+	return &key, nil
+}
+
+// Allow spends a token of the key's bucket, which refills RateLimit tokens per minute.
+func (s *Store) Allow(ctx context.Context, key *Key) (ratelimit.Result, error) {
+	return s.limiter.Allow(ctx, ratelimit.Policy{
+		Name:      RatePolicy,
+		Algorithm: ratelimit.TokenBucket,
+		Limit:     key.RateLimit,
+		Window:    time.Minute,
+	}, key.ID)
+}
@@ -0,0 +1,79 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Entry describes a single privileged action performed through the gateway.
+// ImpersonatorID is set when an administrator made the request through an impersonation
+// session of ActorID.
+type Entry struct {
+	ID          string    `json:"id,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+	RequestID   string    `json:"request_id,omitempty"`
+	ActorID     string    `json:"actor_id"`
+	ActorEmail  string    `json:"actor_email,omitempty"`
+	ActorRole   string    `json:"actor_role,omitempty"`
+	SessionID   string    `json:"session_id,omitempty"`
+	Action      string    `json:"action"`
+	Method      string    `json:"method"`
+	Path        string    `json:"path"`
+	TargetID    string    `json:"target_id,omitempty"`
+	PayloadHash string    `json:"payload_hash,omitempty"`
+	StatusCode  int       `json:"status_code"`
+	Outcome     string    `json:"outcome"`
+	ClientIP    string    `json:"client_ip,omitempty"`
+	UserAgent   string    `json:"user_agent,omitempty"`
+	DurationMs  int64     `json:"duration_ms"`
+
+	ImpersonatorID string `json:"impersonator_id,omitempty"`
+}
+
+// Outcomes recorded for an entry, derived from the response status.
+const (
+	OutcomeSuccess = "success"
+	OutcomeDenied  = "denied"
+	OutcomeFailure = "failure"
+)
+
+// OutcomeForStatus classifies a response status: 401 and 403 are denials, any other
+// status of 400 and above is a failure.
+func OutcomeForStatus(status int) string {
+	switch {
+	case status == http.StatusUnauthorized, status == http.StatusForbidden:
+		return OutcomeDenied
+	case status >= http.StatusBadRequest:
+		return OutcomeFailure
+	default:
+		return OutcomeSuccess
+	}
+}
+
+// Recorder persists audit entries. Implementations must not block the request path
+// for long and should never fail the audited action.
+type Recorder interface {
+	Record(ctx context.Context, entry Entry)
+}
+
+// LogRecorder writes audit entries to the structured log, where they are picked up by the
+// centralized log pipeline.
+type LogRecorder struct{}
+
+// NewLogRecorder constructs a LogRecorder.
+func NewLogRecorder() *LogRecorder {
+	return &LogRecorder{}
+}
+
+// Record writes the entry to the log.
+func (r *LogRecorder) Record(ctx context.Context, entry Entry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to marshal audit entry", "error", err)
+		return
+	}
+	slog.InfoContext(ctx, "audit entry", "audit", json.RawMessage(data))
+}
@@ -0,0 +1,175 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// StreamKey is the Redis stream holding audit entries. Entries are only ever appended;
+// the gateway never edits or trims the stream.
+const StreamKey = "audit_log"
+
+const (
+	defaultQueryLimit = 50
+	maxQueryLimit     = 200
+	// maxScanPerQuery bounds how many entries a filtered query reads before returning a
+	// partial page with a cursor, so sparse filters cannot scan the whole stream at once.
+	maxScanPerQuery = 5000
+	scanBatchSize   = 500
+)
+
+// Query filters audit entries. Empty fields match everything. Results are returned newest
+// first; Cursor is the NextCursor of a previous page.
+type Query struct {
+	ActorID        string
+	ImpersonatorID string
+	Action         string
+	TargetID       string
+	Outcome        string
+	From           time.Time
+	To             time.Time
+	Limit          int
+	Cursor         string
+}
+
+// Page is one page of query results. NextCursor is empty when there are no older entries.
+type Page struct {
+	Entries    []Entry `json:"entries"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+// Reader queries recorded audit entries.
+type Reader interface {
+	Query(ctx context.Context, q Query) (*Page, error)
+}
+
+// RedisStore appends audit entries to a Redis stream and serves queries from it.
+type RedisStore struct {
+	redisClient *redis.Client
+	fallback    *LogRecorder
+}
+
+// NewRedisStore creates an audit store backed by redisClient.
+func NewRedisStore(redisClient *redis.Client) *RedisStore {
+	return &RedisStore{
+		redisClient: redisClient,
+		fallback:    NewLogRecorder(),
+	}
+}
+
+// Record appends entry to the stream. When Redis is unavailable the entry is written to
+// the log instead so it can still be recovered from the log pipeline.
+func (s *RedisStore) Record(ctx context.Context, entry Entry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to marshal audit entry", "error", err)
+		return
+	}
+
+	// The request context may already be cancelled by the time the handler finishes.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 2*time.Second)
+	defer cancel()
+
+	err = s.redisClient.XAdd(ctx, &redis.XAddArgs{
+		Stream: StreamKey,
+		Values: map[string]interface{}{"entry": data},
+	}).Err()
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to append audit entry", "stream", StreamKey, "error", err)
+		s.fallback.Record(ctx, entry)
+	}
+}
+
+// Query returns entries matching q, newest first.
+func (s *RedisStore) Query(ctx context.Context, q Query) (*Page, error) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultQueryLimit
+	}
+	if limit > maxQueryLimit {
+		limit = maxQueryLimit
+	}
+
+	// Stream IDs are "<unix ms>-<seq>", so time bounds map directly onto ID ranges.
+	upper := "+"
+	if !q.To.IsZero() {
+		upper = strconv.FormatInt(q.To.UnixMilli(), 10)
+	}
+	if q.Cursor != "" {
+		upper = "(" + q.Cursor
+	}
+	lower := "-"
+	if !q.From.IsZero() {
+		lower = strconv.FormatInt(q.From.UnixMilli(), 10)
+	}
+
+	page := &Page{Entries: make([]Entry, 0, limit)}
+	scanned := 0
+	for scanned < maxScanPerQuery {
+		messages, err := s.redisClient.XRevRangeN(ctx, StreamKey, upper, lower, scanBatchSize).Result()
+		if err != nil {
+			return nil, fmt.Errorf("read audit stream: %w", err)
+		}
+
+		for _, message := range messages {
+			scanned++
+			page.NextCursor = message.ID
+
+			entry, ok := decodeMessage(message)
+			if !ok || !q.matches(entry) {
+				continue
+			}
+			page.Entries = append(page.Entries, entry)
+			if len(page.Entries) == limit {
+				return page, nil
+			}
+		}
+
+		if len(messages) < scanBatchSize {
+			// Reached the oldest entry in range.
+			page.NextCursor = ""
+			return page, nil
+		}
+		upper = "(" + messages[len(messages)-1].ID
+	}
+
+	return page, nil
+}
+
+func decodeMessage(message redis.XMessage) (Entry, bool) {
+	raw, ok := message.Values["entry"].(string)
+	if !ok {
+		return Entry{}, false
+	}
+	var entry Entry
+	if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+		return Entry{}, false
+	}
+	entry.ID = message.ID
+	return entry, true
+}
+
+func (q Query) matches(entry Entry) bool {
+	if q.ActorID != "" && entry.ActorID != q.ActorID {
+		return false
+	}
+	if q.ImpersonatorID != "" && entry.ImpersonatorID != q.ImpersonatorID {
+		return false
+	}
+	if q.Action != "" && entry.Action != q.Action {
+		return false
+	}
+	if q.TargetID != "" && entry.TargetID != q.TargetID {
+		return false
+	}
+	if q.Outcome != "" && entry.Outcome != q.Outcome {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bff-services/internal/api/dto"
+
+	"github.com/ductan2/microservice-app/shared/rediscache"
+	"github.com/redis/go-redis/v9"
+)
+
+// FeedCache caches the continue-learning feed of each user, by time zone since "today"
+// depends on it. Entries are short-lived and dropped when the user's activity or
+// enrollments change through the BFF.
+type FeedCache struct {
+	cache *rediscache.Cache
+	ttl   time.Duration
+}
+
+// NewFeedCache creates a new feed cache. Without Redis it loads every request.
+func NewFeedCache(redisClient *redis.Client) *FeedCache {
+	f := &FeedCache{
+		ttl: time.Minute, // Due reviews change without going through the BFF
+	}
+	if redisClient != nil {
+		f.cache = rediscache.New(redisClient, rediscache.Options{Name: "continue_feed", Jitter: 0.1})
+	}
+	return f
+}
+
+// GetFeedCacheKey returns the cache key for a user's feed in a time zone
+func (f *FeedCache) GetFeedCacheKey(userID, timezone string) string {
+	return fmt.Sprintf("continue_feed:%s:%s", userID, timezone)
+}
+
+// Feed returns the cached feed of a user, loading it with load on a miss
+func (f *FeedCache) Feed(ctx context.Context, userID, timezone string, load func(context.Context) (*dto.ContinueFeed, error)) (*dto.ContinueFeed, error) {
+	if f.cache == nil {
+		return load(ctx)
+	}
+	return rediscache.GetOrLoad(ctx, f.cache, f.GetFeedCacheKey(userID, timezone), f.ttl, load)
+}
+
+// Invalidate removes the cached feed of a user in a time zone
+func (f *FeedCache) Invalidate(ctx context.Context, userID, timezone string) error {
+	if f.cache == nil {
+		return nil
+	}
+	return f.cache.Delete(ctx, f.GetFeedCacheKey(userID, timezone))
+}
@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"bff-services/internal/config"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrGuestSessionNotFound is returned when a guest session is unknown or has expired.
+var ErrGuestSessionNotFound = errors.New("guest session not found")
+
+// GuestSession is the state kept for an anonymous visitor until they sign up.
+type GuestSession struct {
+	ID               uuid.UUID `json:"id"`
+	SelectedCourseID string    `json:"selected_course_id,omitempty"`
+	Locale           string    `json:"locale,omitempty"`
+	Timezone         string    `json:"timezone,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// GuestCache stores guest sessions in Redis. Sessions expire after ttl of inactivity.
+type GuestCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewGuestCache creates a new guest session cache. ttl defaults to 2h and is capped at
+// config.MaxGuestSessionTTL.
+func NewGuestCache(client *redis.Client, ttl time.Duration) *GuestCache {
+	if ttl <= 0 {
+		ttl = 2 * time.Hour
+	}
+	if ttl > config.MaxGuestSessionTTL {
+		ttl = config.MaxGuestSessionTTL
+	}
+	return &GuestCache{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+// TTL returns how long a guest session lives without activity.
+func (gc *GuestCache) TTL() time.Duration {
+	return gc.ttl
+}
+
+func guestSessionKey(id uuid.UUID) string {
+	return fmt.Sprintf("guest_session:%s", id.String())
+}
+
+// Create starts a new guest session.
+func (gc *GuestCache) Create(ctx context.Context, locale, timezone string) (*GuestSession, error) {
+	now := time.Now().UTC()
+	session := &GuestSession{
+		ID:        uuid.New(),
+		Locale:    locale,
+		Timezone:  timezone,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	jsonData, err := json.Marshal(session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal guest session: %w", err)
+	}
+	if err := gc.client.Set(ctx, guestSessionKey(session.ID), jsonData, gc.ttl).Err(); err != nil {
+		return nil, fmt.Errorf("failed to store guest session in Redis: %w", err)
+	}
+	return session, nil
+}
+
+// Get retrieves a guest session and slides its TTL.
+func (gc *GuestCache) Get(ctx context.Context, id uuid.UUID) (*GuestSession, error) {
+	key := guestSessionKey(id)
+
+	val, err := gc.client.GetEx(ctx, key, gc.ttl).Result()
+	if err == redis.Nil {
+		return nil, ErrGuestSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get guest session from Redis: %w", err)
+	}
+
+	var session GuestSession
+	if err := json.Unmarshal([]byte(val), &session); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal guest session: %w", err)
+	}
+	return &session, nil
+}
+
+// Save overwrites an existing guest session. Sessions that expired in the meantime are
+// not recreated.
+func (gc *GuestCache) Save(ctx context.Context, session *GuestSession) error {
+	session.UpdatedAt = time.Now().UTC()
+
+	jsonData, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal guest session: %w", err)
+	}
+
+	ok, err := gc.client.SetXX(ctx, guestSessionKey(session.ID), jsonData, gc.ttl).Result()
+	if err != nil {
+		return fmt.Errorf("failed to store guest session in Redis: %w", err)
+	}
+	if !ok {
+		return ErrGuestSessionNotFound
+	}
+	return nil
+}
+
+// Delete removes a guest session, e.g. once it has been converted into an account.
+func (gc *GuestCache) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := gc.client.Del(ctx, guestSessionKey(id)).Err(); err != nil {
+		return fmt.Errorf("failed to delete guest session from Redis: %w", err)
+	}
+	return nil
+}
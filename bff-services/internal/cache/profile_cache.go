@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ProfileSnippet is the public subset of a user profile shown next to user IDs.
+type ProfileSnippet struct {
+	UserID      string `json:"user_id"`
+	DisplayName string `json:"display_name"`
+	AvatarURL   string `json:"avatar_url"`
+}
+
+// ProfileCache stores profile snippets in Redis so list responses can be enriched cheaply.
+type ProfileCache struct {
+	redisClient *redis.Client
+	ttl         time.Duration
+}
+
+// NewProfileCache creates a new profile snippet cache
+func NewProfileCache(redisClient *redis.Client) *ProfileCache {
+	return &ProfileCache{
+		redisClient: redisClient,
+		ttl:         15 * time.Minute, // Display names and avatars change rarely
+	}
+}
+
+// GetProfileCacheKey returns the cache key for a user's profile snippet
+func (p *ProfileCache) GetProfileCacheKey(userID string) string {
+	return fmt.Sprintf("profile_snippet:%s", userID)
+}
+
+// GetMany returns the cached snippets for the given user IDs, keyed by user ID.
+// Missing or unreadable entries are simply absent from the result.
+func (p *ProfileCache) GetMany(ctx context.Context, userIDs []string) (map[string]ProfileSnippet, error) {
+	result := make(map[string]ProfileSnippet, len(userIDs))
+	if p == nil || p.redisClient == nil || len(userIDs) == 0 {
+		return result, nil
+	}
+
+	keys := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		keys[i] = p.GetProfileCacheKey(id)
+	}
+
+	values, err := p.redisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		return result, err
+	}
+
+	for i, value := range values {
+		raw, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var snippet ProfileSnippet
+		if err := json.Unmarshal([]byte(raw), &snippet); err != nil {
+			continue
+		}
+		result[userIDs[i]] = snippet
+	}
+
+	return result, nil
+}
+
+// SetMany stores profile snippets in a single pipeline
+func (p *ProfileCache) SetMany(ctx context.Context, snippets []ProfileSnippet) error {
+	if p == nil || p.redisClient == nil || len(snippets) == 0 {
+		return nil
+	}
+
+	pipe := p.redisClient.Pipeline()
+	for _, snippet := range snippets {
+		data, err := json.Marshal(snippet)
+		if err != nil {
+			return err
+		}
+		pipe.Set(ctx, p.GetProfileCacheKey(snippet.UserID), string(data), p.ttl)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Invalidate removes a user's cached snippet, e.g. after a profile update
+func (p *ProfileCache) Invalidate(ctx context.Context, userID string) error {
+	if p == nil || p.redisClient == nil {
+		return nil
+	}
+	return p.redisClient.Del(ctx, p.GetProfileCacheKey(userID)).Err()
+}
@@ -0,0 +1,24 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Sessions revoked by user-services (logout, refresh-token reuse) are listed under this
+// key until every access token issued for them has expired. The format must stay in
+// sync with user-services/internal/cache.
+func revokedSessionKey(sessionID uuid.UUID) string {
+	return fmt.Sprintf("revoked_session:%s", sessionID.String())
+}
+
+// IsSessionRevoked reports whether a session is on the revocation list.
+func (sc *SessionCache) IsSessionRevoked(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	exists, err := sc.client.Exists(ctx, revokedSessionKey(sessionID)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check revocation list: %w", err)
+	}
+	return exists > 0, nil
+}
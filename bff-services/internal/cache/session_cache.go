@@ -2,10 +2,11 @@ package cache
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/ductan2/microservice-app/shared/rediscache"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 )
@@ -14,33 +15,123 @@ import (
 type SessionData struct {
 	UserID    uuid.UUID `json:"user_id"`
 	Email     string    `json:"email"`
+	Role      string    `json:"role,omitempty"`
 	UserAgent string    `json:"user_agent"`
 	IPAddr    string    `json:"ip_addr"`
 	CreatedAt time.Time `json:"created_at"`
+	// LastSeenAt is maintained by the BFF when sliding expiry is enabled.
+	LastSeenAt time.Time `json:"last_seen_at,omitzero"`
+	// ImpersonatorID is the administrator acting as the user in an impersonation
+	// session, which ends at ExpiresAt however active it is.
+	ImpersonatorID *uuid.UUID `json:"impersonator_id,omitempty"`
+	ExpiresAt      time.Time  `json:"expires_at,omitzero"`
+	// Consents is when the user last accepted each policy document, maintained by
+	// user-service. It is nil for impersonation sessions, which are not checked.
+	Consents map[string]time.Time `json:"consents"`
 }
 
-// SessionCache provides Redis operations for session management
+// ErrSessionExpired is returned by Touch when a session is past its absolute lifetime.
+var ErrSessionExpired = errors.New("session expired")
+
+// SessionPolicy configures sliding expiry. The zero value disables it, leaving the TTL
+// set at login untouched.
+type SessionPolicy struct {
+	IdleTimeout      time.Duration
+	AbsoluteLifetime time.Duration
+	// TouchInterval throttles Redis writes: activity within this interval of the last
+	// recorded one does not rewrite the session.
+	TouchInterval time.Duration
+	// ExpiryWarning is how long before the hard deadline clients are warned.
+	ExpiryWarning time.Duration
+}
+
+// SessionCache provides Redis operations for session management. Sessions are read and
+// written through rediscache for the hit and miss metrics, without TTL jitter: their TTL
+// is the lifetime of the session.
 type SessionCache struct {
 	client *redis.Client
+	cache  *rediscache.Cache
+	policy SessionPolicy
 }
 
 // NewSessionCache creates a new session cache instance
 func NewSessionCache(client *redis.Client) *SessionCache {
 	return &SessionCache{
 		client: client,
+		cache:  rediscache.New(client, rediscache.Options{Name: "session"}),
+	}
+}
+
+// SetPolicy enables sliding expiry with the given idle and absolute limits.
+func (sc *SessionCache) SetPolicy(policy SessionPolicy) {
+	if policy.TouchInterval <= 0 {
+		policy.TouchInterval = time.Minute
+	}
+	sc.policy = policy
+}
+
+// AbsoluteDeadline returns when the session must end regardless of activity: the earlier
+// of its fixed end, if it has one, and the configured absolute lifetime. It is the zero
+// time when neither applies.
+func (sc *SessionCache) AbsoluteDeadline(data *SessionData) time.Time {
+	if data == nil {
+		return time.Time{}
+	}
+	deadline := data.ExpiresAt
+	if sc.policy.AbsoluteLifetime > 0 && !data.CreatedAt.IsZero() {
+		if lifetime := data.CreatedAt.Add(sc.policy.AbsoluteLifetime); deadline.IsZero() || lifetime.Before(deadline) {
+			deadline = lifetime
+		}
+	}
+	return deadline
+}
+
+// ExpiryWarning returns the configured warning window before the hard deadline.
+func (sc *SessionCache) ExpiryWarning() time.Duration {
+	return sc.policy.ExpiryWarning
+}
+
+// Touch records activity on a session and slides its Redis TTL to the idle timeout,
+// never past the absolute deadline. Sessions past that deadline are deleted and
+// ErrSessionExpired is returned. Touch is a no-op when sliding expiry is disabled.
+func (sc *SessionCache) Touch(ctx context.Context, sessionID uuid.UUID, data *SessionData) error {
+	if sc.policy.IdleTimeout <= 0 || data == nil {
+		return nil
+	}
+
+	now := time.Now()
+	deadline := sc.AbsoluteDeadline(data)
+	if !deadline.IsZero() && !now.Before(deadline) {
+		if err := sc.DeleteSession(ctx, sessionID); err != nil {
+			return err
+		}
+		return ErrSessionExpired
 	}
+
+	if !data.LastSeenAt.IsZero() && now.Sub(data.LastSeenAt) < sc.policy.TouchInterval {
+		return nil
+	}
+
+	ttl := sc.policy.IdleTimeout
+	if !deadline.IsZero() && deadline.Sub(now) < ttl {
+		ttl = deadline.Sub(now)
+	}
+
+	data.LastSeenAt = now
+
+	// Replace so a session revoked concurrently is not resurrected.
+	key := fmt.Sprintf("session:%s", sessionID.String())
+	if _, err := rediscache.Replace(ctx, sc.cache, key, data, ttl); err != nil {
+		return fmt.Errorf("failed to refresh session in Redis: %w", err)
+	}
+	return nil
 }
 
 // StoreSession saves a session to Redis with TTL matching JWT expiration
 func (sc *SessionCache) StoreSession(ctx context.Context, sessionID uuid.UUID, data SessionData, ttl time.Duration) error {
 	key := fmt.Sprintf("session:%s", sessionID.String())
 
-	jsonData, err := json.Marshal(data)
-	if err != nil {
-		return fmt.Errorf("failed to marshal session data: %w", err)
-	}
-
-	err = sc.client.Set(ctx, key, jsonData, ttl).Err()
+	err := rediscache.Set(ctx, sc.cache, key, data, ttl)
 	if err != nil {
 		return fmt.Errorf("failed to store session in Redis: %w", err)
 	}
@@ -52,17 +143,12 @@ func (sc *SessionCache) StoreSession(ctx context.Context, sessionID uuid.UUID, d
 func (sc *SessionCache) GetSession(ctx context.Context, sessionID uuid.UUID) (*SessionData, error) {
 	key := fmt.Sprintf("session:%s", sessionID.String())
 
-	val, err := sc.client.Get(ctx, key).Result()
-	if err == redis.Nil {
-		return nil, fmt.Errorf("session not found")
-	}
+	data, ok, err := rediscache.Get[SessionData](ctx, sc.cache, key)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get session from Redis: %w", err)
 	}
-
-	var data SessionData
-	if err := json.Unmarshal([]byte(val), &data); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal session data: %w", err)
+	if !ok {
+		return nil, fmt.Errorf("session not found")
 	}
 
 	return &data, nil
@@ -72,7 +158,7 @@ func (sc *SessionCache) GetSession(ctx context.Context, sessionID uuid.UUID) (*S
 func (sc *SessionCache) DeleteSession(ctx context.Context, sessionID uuid.UUID) error {
 	key := fmt.Sprintf("session:%s", sessionID.String())
 
-	err := sc.client.Del(ctx, key).Err()
+	err := sc.cache.Delete(ctx, key)
 	if err != nil {
 		return fmt.Errorf("failed to delete session from Redis: %w", err)
 	}
@@ -92,7 +178,7 @@ func (sc *SessionCache) DeleteAllUserSessions(ctx context.Context, sessionIDs []
 		keys[i] = fmt.Sprintf("session:%s", id.String())
 	}
 
-	err := sc.client.Del(ctx, keys...).Err()
+	err := sc.cache.Delete(ctx, keys...)
 	if err != nil {
 		return fmt.Errorf("failed to delete user sessions from Redis: %w", err)
 	}
@@ -104,10 +190,10 @@ func (sc *SessionCache) DeleteAllUserSessions(ctx context.Context, sessionIDs []
 func (sc *SessionCache) SessionExists(ctx context.Context, sessionID uuid.UUID) (bool, error) {
 	key := fmt.Sprintf("session:%s", sessionID.String())
 
-	exists, err := sc.client.Exists(ctx, key).Result()
+	exists, err := sc.cache.Exists(ctx, key)
 	if err != nil {
 		return false, fmt.Errorf("failed to check session existence: %w", err)
 	}
 
-	return exists > 0, nil
+	return exists, nil
 }
@@ -2,16 +2,18 @@ package cache
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/ductan2/microservice-app/shared/rediscache"
 	"github.com/redis/go-redis/v9"
 )
 
-// StreakCacheService handles Redis caching for streak and activity data
+// StreakCacheService handles Redis caching for streak and activity data. Entries are
+// loaded through rediscache, which spreads their TTLs, loads a missing entry once for
+// concurrent requests and refreshes hot entries before they expire.
 type StreakCacheService struct {
-	redisClient *redis.Client
+	cache       *rediscache.Cache
 	streakTTL   time.Duration
 	activityTTL time.Duration
 }
@@ -36,13 +38,17 @@ type WeekActivityData struct {
 	Data []ActivityData `json:"data"`
 }
 
-// NewStreakCacheService creates a new streak cache service
+// NewStreakCacheService creates a new streak cache service. Without Redis it loads every
+// request.
 func NewStreakCacheService(redisClient *redis.Client) *StreakCacheService {
-	return &StreakCacheService{
-		redisClient: redisClient,
+	s := &StreakCacheService{
 		streakTTL:   5 * time.Minute,  // Cache streak for 5 minutes
 		activityTTL: 10 * time.Minute, // Cache weekly activity for 10 minutes
 	}
+	if redisClient != nil {
+		s.cache = rediscache.New(redisClient, rediscache.Options{Name: "streak", Jitter: 0.1, Beta: 1})
+	}
+	return s
 }
 
 // GetStreakCacheKey returns the cache key for a user's streak
@@ -55,113 +61,46 @@ func (s *StreakCacheService) GetWeekActivityCacheKey(userID string) string {
 	return fmt.Sprintf("week_activity:%s", userID)
 }
 
-// GetCachedStreak retrieves cached streak data for a user
-func (s *StreakCacheService) GetCachedStreak(ctx context.Context, userID string) (*StreakData, error) {
-	if s.redisClient == nil {
-		return nil, nil // Return nil without error if Redis is not available
-	}
-
-	key := s.GetStreakCacheKey(userID)
-	val, err := s.redisClient.Get(ctx, key).Result()
-	if err != nil {
-		if err == redis.Nil {
-			return nil, nil // Cache miss, not an error
-		}
-		return nil, err // Redis error
-	}
-
-	var streakData StreakData
-	if err := json.Unmarshal([]byte(val), &streakData); err != nil {
-		return nil, err
-	}
-
-	return &streakData, nil
-}
-
-// CacheStreak stores streak data for a user
-func (s *StreakCacheService) CacheStreak(ctx context.Context, userID string, streak *StreakData) error {
-	if s.redisClient == nil {
-		return nil // Skip if Redis is not available
-	}
-
-	key := s.GetStreakCacheKey(userID)
-	data, err := json.Marshal(streak)
-	if err != nil {
-		return err
-	}
-
-	return s.redisClient.Set(ctx, key, string(data), s.streakTTL).Err()
-}
-
-// GetCachedWeekActivity retrieves cached weekly activity data for a user
-func (s *StreakCacheService) GetCachedWeekActivity(ctx context.Context, userID string) ([]ActivityData, error) {
-	if s.redisClient == nil {
-		return nil, nil // Return nil without error if Redis is not available
-	}
-
-	key := s.GetWeekActivityCacheKey(userID)
-	val, err := s.redisClient.Get(ctx, key).Result()
-	if err != nil {
-		if err == redis.Nil {
-			return nil, nil // Cache miss, not an error
-		}
-		return nil, err // Redis error
+// Streak returns the cached streak of a user, loading it with load on a miss
+func (s *StreakCacheService) Streak(ctx context.Context, userID string, load func(context.Context) (*StreakData, error)) (*StreakData, error) {
+	if s.cache == nil {
+		return load(ctx)
 	}
-
-	var activityData WeekActivityData
-	if err := json.Unmarshal([]byte(val), &activityData); err != nil {
-		return nil, err
-	}
-
-	return activityData.Data, nil
+	return rediscache.GetOrLoad(ctx, s.cache, s.GetStreakCacheKey(userID), s.streakTTL, load)
 }
 
-// CacheWeekActivity stores weekly activity data for a user
-func (s *StreakCacheService) CacheWeekActivity(ctx context.Context, userID string, activities []ActivityData) error {
-	if s.redisClient == nil {
-		return nil // Skip if Redis is not available
-	}
-
-	key := s.GetWeekActivityCacheKey(userID)
-	data, err := json.Marshal(WeekActivityData{Data: activities})
-	if err != nil {
-		return err
+// WeekActivity returns the cached weekly activity of a user, loading it with load on a miss
+func (s *StreakCacheService) WeekActivity(ctx context.Context, userID string, load func(context.Context) ([]ActivityData, error)) ([]ActivityData, error) {
+	if s.cache == nil {
+		return load(ctx)
 	}
-
-	return s.redisClient.Set(ctx, key, string(data), s.activityTTL).Err()
+	week, err := rediscache.GetOrLoad(ctx, s.cache, s.GetWeekActivityCacheKey(userID), s.activityTTL, func(ctx context.Context) (WeekActivityData, error) {
+		activities, err := load(ctx)
+		return WeekActivityData{Data: activities}, err
+	})
+	return week.Data, err
 }
 
 // InvalidateStreakCache removes streak cache for a user
 func (s *StreakCacheService) InvalidateStreakCache(ctx context.Context, userID string) error {
-	if s.redisClient == nil {
+	if s.cache == nil {
 		return nil
 	}
-
-	key := s.GetStreakCacheKey(userID)
-	return s.redisClient.Del(ctx, key).Err()
+	return s.cache.Delete(ctx, s.GetStreakCacheKey(userID))
 }
 
 // InvalidateWeekActivityCache removes weekly activity cache for a user
 func (s *StreakCacheService) InvalidateWeekActivityCache(ctx context.Context, userID string) error {
-	if s.redisClient == nil {
+	if s.cache == nil {
 		return nil
 	}
-
-	key := s.GetWeekActivityCacheKey(userID)
-	return s.redisClient.Del(ctx, key).Err()
+	return s.cache.Delete(ctx, s.GetWeekActivityCacheKey(userID))
 }
 
 // InvalidateAllUserCache invalidates all cache for a user
 func (s *StreakCacheService) InvalidateAllUserCache(ctx context.Context, userID string) error {
-	if s.redisClient == nil {
+	if s.cache == nil {
 		return nil
 	}
-
-	streakErr := s.InvalidateStreakCache(ctx, userID)
-	weekErr := s.InvalidateWeekActivityCache(ctx, userID)
-
-	if streakErr != nil {
-		return streakErr
-	}
-	return weekErr
+	return s.cache.Delete(ctx, s.GetStreakCacheKey(userID), s.GetWeekActivityCacheKey(userID))
 }
@@ -1,90 +1,180 @@
 package config
 
 import (
-	"log"
-	"os"
-	"strings"
+	"errors"
+	"fmt"
+	"sync/atomic"
 
-	"github.com/joho/godotenv"
+	"github.com/ductan2/microservice-app/shared/envconfig"
+	"github.com/ductan2/microservice-app/shared/ratelimit"
+	"github.com/ductan2/microservice-app/shared/requestmeta"
 )
 
-func init() {
-	// Load .env if present; non-fatal if missing (e.g., in production/containers)
-	if err := godotenv.Load(); err != nil {
-		// It's okay if .env is not present; PORT can still be provided by the environment
+// Config is the configuration of the BFF, read from the environment and .env.
+type Config struct {
+	Port           string `env:"PORT" envDefault:"8010"`
+	UserServiceURL string `env:"USER_SERVICE_URL" envDefault:"http://localhost:8001"`
+	// UserServiceGRPCURL is the address of the user-services gRPC identity API, such as
+	// http://localhost:9001. An empty value keeps identity lookups on REST.
+	UserServiceGRPCURL     string `env:"USER_SERVICE_GRPC_URL"`
+	ContentServiceURL      string `env:"CONTENT_SERVICE_URL" envDefault:"http://localhost/api/content"`
+	LessonServiceURL       string `env:"LESSON_SERVICE_URL" envDefault:"http://localhost:8005"`
+	NotificationServiceURL string `env:"NOTIFICATION_SERVICE_URL" envDefault:"http://localhost:8003"`
+	OrderServiceURL        string `env:"ORDER_SERVICE_URL" envDefault:"http://localhost:8006"`
+	SearchServiceURL       string `env:"SEARCH_SERVICE_URL" envDefault:"http://localhost:8007"`
+	// RecommendationServiceURL serves the recommendations merged into the dashboard
+	RecommendationServiceURL string `env:"RECOMMENDATION_SERVICE_URL" envDefault:"http://localhost:8008"`
+	// CORSURLs lists the allowed CORS origins; CORS_URL is the older single origin
+	CORSURLs []string `env:"CORS_URLS"`
+	CORSURL  string   `env:"CORS_URL" envDefault:"http://localhost:3000"`
+	// GraphQLAllowlistPath is the JSON manifest with persisted GraphQL operations
+	GraphQLAllowlistPath string `env:"GRAPHQL_ALLOWLIST_PATH" envDefault:"graphql-allowlist.json"`
+	// GeoIPServiceURL is the ip-api compatible endpoint used to geo-resolve session IPs.
+	// An empty value disables lookups.
+	GeoIPServiceURL string `env:"GEOIP_SERVICE_URL"`
+	// CompressionMinBytes is the response size from which compressible bodies are encoded
+	CompressionMinBytes int `env:"COMPRESSION_MIN_BYTES" envDefault:"1024"`
+	// RateLimits override rate limit policies by name, see shared/ratelimit
+	RateLimits ratelimit.Policies `env:"RATE_LIMITS"`
+	// TrustedProxies are the networks whose X-Forwarded-For is trusted, Traefik's in
+	// production; the client IP of logs and rate limits is read past them
+	TrustedProxies requestmeta.Proxies `env:"TRUSTED_PROXIES" envDefault:"127.0.0.0/8,::1/128,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16,fc00::/7"`
+
+	Redis   RedisConfig
+	JWT     JWTConfig
+	Session SessionConfig
+	Guest   GuestConfig
+}
+
+// Endpoints lists the services the BFF calls, dialed by
+// "bff-services config validate -connect".
+func (c *Config) Endpoints() []envconfig.Endpoint {
+	endpoints := []envconfig.Endpoint{
+		{Name: "user-service", Address: c.UserServiceURL},
+		{Name: "content-service", Address: c.ContentServiceURL},
+		{Name: "lesson-service", Address: c.LessonServiceURL},
+		{Name: "notification-service", Address: c.NotificationServiceURL},
+		{Name: "order-service", Address: c.OrderServiceURL},
+		{Name: "search-service", Address: c.SearchServiceURL},
+		{Name: "recommendation-service", Address: c.RecommendationServiceURL},
+	}
+	if c.UserServiceGRPCURL != "" {
+		endpoints = append(endpoints, envconfig.Endpoint{Name: "user-service-grpc", Address: c.UserServiceGRPCURL})
+	}
+	if c.Redis.Host != "" {
+		endpoints = append(endpoints, envconfig.Endpoint{Name: "redis", Address: c.Redis.Addr})
 	}
+	if c.GeoIPServiceURL != "" {
+		endpoints = append(endpoints, envconfig.Endpoint{Name: "geoip", Address: c.GeoIPServiceURL})
+	}
+	return endpoints
 }
 
-// GetPort returns the port from the PORT env var. Falls back to 8001 if unset.
-func GetPort() string {
-	if p := os.Getenv("PORT"); p != "" {
-		return p
+// Validate checks the values no env tag can express.
+func (c *Config) Validate() error {
+	var errs []error
+	if c.CompressionMinBytes < 0 {
+		errs = append(errs, errors.New("COMPRESSION_MIN_BYTES must not be negative"))
+	}
+	if c.Session.IdleTimeout < 0 || c.Session.AbsoluteLifetime < 0 || c.Session.ExpiryWarning < 0 {
+		errs = append(errs, errors.New("SESSION_IDLE_TIMEOUT, SESSION_ABSOLUTE_LIFETIME and SESSION_EXPIRY_WARNING must not be negative"))
 	}
-	return "8010"
+	if c.Guest.SessionTTL < 0 || c.Guest.SampleLessons < 0 {
+		errs = append(errs, errors.New("GUEST_SESSION_TTL and GUEST_SAMPLE_LESSONS must not be negative"))
+	}
+	if c.Guest.SessionTTL > MaxGuestSessionTTL {
+		errs = append(errs, fmt.Errorf("GUEST_SESSION_TTL must be at most %s", MaxGuestSessionTTL))
+	}
+	return errors.Join(errs...)
 }
 
-func GetUserServiceURL() string {
-	log.Println("USER_SERVICE_URL", os.Getenv("USER_SERVICE_URL"))
-	if v := os.Getenv("USER_SERVICE_URL"); v != "" {
+var configs atomic.Pointer[envconfig.Reloader[Config]]
 
-		return v
+// Load loads the configuration returned by the getters of this package. The returned
+// reloader replaces it on reload: CORS origins and JWT settings apply at once, the
+// others are read at startup.
+func Load() (*envconfig.Reloader[Config], error) {
+	reloader, err := envconfig.NewReloader(load)
+	if err != nil {
+		return nil, err
 	}
-	return "http://localhost:8001"
+	configs.Store(reloader)
+	return reloader, nil
 }
 
-func GetContentServiceURL() string {
-	if v := os.Getenv("CONTENT_SERVICE_URL"); v != "" {
-		return v
+// Read reads and validates the configuration without keeping it, for
+// "bff-services config validate".
+func Read() (*Config, error) {
+	return load()
+}
+
+func load() (*Config, error) {
+	cfg := &Config{}
+	err := envconfig.Load(cfg)
+	cfg.Redis.Addr = cfg.Redis.Host + ":" + cfg.Redis.Port
+	if err != nil {
+		return cfg, err
 	}
-	return "http://localhost/api/content"
+	return cfg, cfg.Validate()
 }
 
-func GetLessonServiceURL() string {
-	if v := os.Getenv("LESSON_SERVICE_URL"); v != "" {
-		return v
+// Current returns the configuration loaded last. Before Load, as in tests, it reads the
+// environment on every call and ignores invalid values.
+func Current() *Config {
+	if reloader := configs.Load(); reloader != nil {
+		return reloader.Current()
 	}
-	return "http://localhost:8005"
+	cfg, _ := load()
+	return cfg
+}
+
+// GetPort returns the port from the PORT env var. Falls back to 8010 if unset.
+func GetPort() string {
+	return Current().Port
+}
+
+func GetUserServiceURL() string {
+	return Current().UserServiceURL
+}
+
+// GetUserServiceGRPCURL returns the address of the user-services gRPC identity API, such
+// as http://localhost:9001. An empty value keeps identity lookups on REST.
+func GetUserServiceGRPCURL() string {
+	return Current().UserServiceGRPCURL
+}
+
+func GetContentServiceURL() string {
+	return Current().ContentServiceURL
+}
+
+func GetLessonServiceURL() string {
+	return Current().LessonServiceURL
 }
 
 func GetNotificationServiceURL() string {
-	if v := os.Getenv("NOTIFICATION_SERVICE_URL"); v != "" {
-		return v
-	}
-	return "http://localhost:8003"
+	return Current().NotificationServiceURL
 }
 
 func GetOrderServiceURL() string {
-	if v := os.Getenv("ORDER_SERVICE_URL"); v != "" {
-		return v
-	}
-	return "http://localhost:8006"
+	return Current().OrderServiceURL
+}
+
+func GetSearchServiceURL() string {
+	return Current().SearchServiceURL
+}
+
+func GetRecommendationServiceURL() string {
+	return Current().RecommendationServiceURL
 }
 
 // GetCORSOrigins returns allowed CORS origins from env CORS_URLS (comma-separated)
 // Falls back to single env CORS_URL, then default http://localhost:3000.
 func GetCORSOrigins() []string {
-	// Highest priority: CORS_URLS (comma-separated)
-	if v := os.Getenv("CORS_URLS"); v != "" {
-		parts := strings.Split(v, ",")
-		allowed := make([]string, 0, len(parts))
-		for _, p := range parts {
-			trimmed := strings.TrimSpace(p)
-			if trimmed != "" {
-				allowed = append(allowed, trimmed)
-			}
-		}
-		if len(allowed) > 0 {
-			return allowed
-		}
-	}
-
-	// Next: legacy/single CORS_URL
-	if v := os.Getenv("CORS_URL"); v != "" {
-		return []string{v}
+	cfg := Current()
+	if len(cfg.CORSURLs) > 0 {
+		return cfg.CORSURLs
 	}
-
-	// Default
-	return []string{"http://localhost:3000"}
+	return []string{cfg.CORSURL}
 }
 
 // IsOriginAllowed checks whether the provided origin is in the configured allowlist.
@@ -102,13 +192,40 @@ func IsOriginAllowed(origin string) bool {
 
 // Redis
 type RedisConfig struct {
-	Addr     string
-	Password string
+	Host     string `env:"REDIS_HOST"`
+	Port     string `env:"REDIS_PORT"`
+	Password string `env:"REDIS_PASSWORD,secret"`
+	// Addr is Host:Port
+	Addr string
 }
 
 func GetRedisConfig() RedisConfig {
-	return RedisConfig{
-		Addr:     os.Getenv("REDIS_HOST") + ":" + os.Getenv("REDIS_PORT"),
-		Password: os.Getenv("REDIS_PASSWORD"),
-	}
+	return Current().Redis
+}
+
+// GetGraphQLAllowlistPath returns the path of the JSON manifest with persisted GraphQL operations.
+func GetGraphQLAllowlistPath() string {
+	return Current().GraphQLAllowlistPath
+}
+
+// GetGeoIPServiceURL returns the ip-api compatible endpoint used to geo-resolve session IPs.
+// An empty value disables lookups.
+func GetGeoIPServiceURL() string {
+	return Current().GeoIPServiceURL
+}
+
+// GetCompressionMinBytes returns the response size from which compressible bodies are encoded.
+// Falls back to 1024 bytes when unset.
+func GetCompressionMinBytes() int {
+	return Current().CompressionMinBytes
+}
+
+// GetTrustedProxies returns the networks whose X-Forwarded-For is trusted.
+func GetTrustedProxies() requestmeta.Proxies {
+	return Current().TrustedProxies
+}
+
+// GetRateLimits returns the rate limit policy overrides from RATE_LIMITS.
+func GetRateLimits() ratelimit.Policies {
+	return Current().RateLimits
 }
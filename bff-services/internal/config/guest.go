@@ -0,0 +1,22 @@
+package config
+
+import "time"
+
+// MaxGuestSessionTTL caps the inactivity TTL of guest sessions, GUEST_SESSION_TTL.
+// Anyone can start one, so the sessions an abandoned client leaves in Redis must not
+// linger.
+const MaxGuestSessionTTL = 24 * time.Hour
+
+// GuestConfig controls anonymous browsing sessions.
+type GuestConfig struct {
+	// SessionTTL ends a guest session after this long without requests, at most
+	// MaxGuestSessionTTL.
+	SessionTTL time.Duration `env:"GUEST_SESSION_TTL" envDefault:"2h"`
+	// SampleLessons is how many lessons of a course a guest may preview.
+	SampleLessons int `env:"GUEST_SAMPLE_LESSONS" envDefault:"3"`
+}
+
+// GetGuestConfig reads guest settings, falling back to a 2h session and 3 sample lessons.
+func GetGuestConfig() GuestConfig {
+	return Current().Guest
+}
@@ -1,32 +1,13 @@
 package config
 
-import (
-	"os"
-	"time"
-)
+import "time"
 
 // JWTConfig contains signing secret and token lifetime.
 type JWTConfig struct {
-	Secret    string
-	ExpiresIn time.Duration
+	Secret    string        `env:"JWT_SECRET,secret" envDefault:"change-me-dev-secret"`
+	ExpiresIn time.Duration `env:"JWT_EXPIRES_IN" envDefault:"24h"`
 }
 
 func GetJWTConfig() JWTConfig {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "change-me-dev-secret"
-	}
-	dur := os.Getenv("JWT_EXPIRES_IN")
-	if dur == "" {
-		dur = "24h"
-	}
-	exp, err := time.ParseDuration(dur)
-	if err != nil {
-		// Fallback if invalid duration
-		exp = 24 * time.Hour
-	}
-	return JWTConfig{
-		Secret:    secret,
-		ExpiresIn: exp,
-	}
+	return Current().JWT
 }
@@ -0,0 +1,22 @@
+package config
+
+import "time"
+
+// SessionConfig controls how long an authenticated session stays valid in the BFF.
+type SessionConfig struct {
+	// IdleTimeout ends a session after this long without authenticated requests.
+	// Each request slides the window forward. Zero disables sliding expiry.
+	IdleTimeout time.Duration `env:"SESSION_IDLE_TIMEOUT" envDefault:"2h"`
+	// AbsoluteLifetime caps a session's total age regardless of activity.
+	AbsoluteLifetime time.Duration `env:"SESSION_ABSOLUTE_LIFETIME" envDefault:"24h"`
+	// ExpiryWarning is how long before the absolute deadline responses start
+	// carrying X-Session-Expiring so clients can prompt for re-authentication.
+	ExpiryWarning time.Duration `env:"SESSION_EXPIRY_WARNING" envDefault:"10m"`
+}
+
+// GetSessionConfig reads session lifetime settings, falling back to a 2h idle timeout,
+// a 24h absolute lifetime and a 10m warning window. Access tokens are refreshed within
+// these limits; once the session ends the user must log in again.
+func GetSessionConfig() SessionConfig {
+	return Current().Session
+}
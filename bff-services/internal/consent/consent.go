@@ -0,0 +1,96 @@
+package consent
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisKey is the hash of the policy documents users must have accepted, written by
+// user-service whenever a version is published, one JSON-encoded Policy per document:
+//
+//	HSET policy_versions terms '{"version":"2025-01","url":"https://…","required_since":"2025-01-06T09:00:00Z"}'
+//
+// The format must stay in sync with user-services/internal/cache.
+const RedisKey = "policy_versions"
+
+// Policy is the current version of a policy document. Users must have accepted the
+// document at or after RequiredSince.
+type Policy struct {
+	Document      string    `json:"document"`
+	Version       string    `json:"version"`
+	URL           string    `json:"url"`
+	RequiredSince time.Time `json:"required_since"`
+}
+
+// Store loads the required policy versions from Redis and keeps a short-lived snapshot so
+// checks do not hit Redis on every request.
+type Store struct {
+	redisClient *redis.Client
+	refresh     time.Duration
+
+	mu       sync.RWMutex
+	policies map[string]Policy
+	loadedAt time.Time
+}
+
+// NewStore creates a policy store backed by redisClient.
+func NewStore(redisClient *redis.Client) *Store {
+	return &Store{
+		redisClient: redisClient,
+		refresh:     30 * time.Second,
+	}
+}
+
+// Policies returns every document users must have accepted. When Redis is unavailable
+// the last snapshot is served.
+func (s *Store) Policies(ctx context.Context) map[string]Policy {
+	s.mu.RLock()
+	policies, fresh := s.policies, time.Since(s.loadedAt) < s.refresh
+	s.mu.RUnlock()
+	if fresh || s.redisClient == nil {
+		return policies
+	}
+
+	raw, err := s.redisClient.HGetAll(ctx, RedisKey).Result()
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to load consent policy versions", "error", err)
+		return policies
+	}
+
+	loaded := make(map[string]Policy, len(raw))
+	for document, value := range raw {
+		var policy Policy
+		if err := json.Unmarshal([]byte(value), &policy); err != nil {
+			slog.WarnContext(ctx, "invalid consent policy version", "document", document, "error", err)
+			continue
+		}
+		policy.Document = document
+		loaded[document] = policy
+	}
+
+	s.mu.Lock()
+	s.policies = loaded
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return loaded
+}
+
+// Pending returns the documents a user who last accepted each document at the given
+// times must accept again, ordered by document.
+func (s *Store) Pending(ctx context.Context, accepted map[string]time.Time) []Policy {
+	pending := []Policy{}
+	for document, policy := range s.Policies(ctx) {
+		if acceptedAt, ok := accepted[document]; !ok || acceptedAt.Before(policy.RequiredSince) {
+			pending = append(pending, policy)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Document < pending[j].Document })
+	return pending
+}
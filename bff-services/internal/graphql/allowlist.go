@@ -0,0 +1,75 @@
+package graphql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Allowlist holds the pre-registered GraphQL operations that untrusted clients may execute,
+// keyed by the SHA-256 hash of the operation document.
+type Allowlist struct {
+	operations map[string]string
+}
+
+// LoadAllowlist reads a JSON manifest mapping operation hashes to query documents.
+// A missing file yields an empty allowlist so the BFF still boots in development.
+func LoadAllowlist(path string) (*Allowlist, error) {
+	allowlist := &Allowlist{operations: make(map[string]string)}
+	if strings.TrimSpace(path) == "" {
+		return allowlist, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return allowlist, nil
+		}
+		return nil, fmt.Errorf("read graphql allowlist: %w", err)
+	}
+
+	var manifest map[string]string
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, fmt.Errorf("parse graphql allowlist: %w", err)
+	}
+
+	for hash, query := range manifest {
+		query = strings.TrimSpace(query)
+		if query == "" {
+			continue
+		}
+		if HashQuery(query) != strings.ToLower(hash) {
+			return nil, fmt.Errorf("graphql allowlist entry %s does not match its query hash", hash)
+		}
+		allowlist.operations[strings.ToLower(hash)] = query
+	}
+
+	return allowlist, nil
+}
+
+// Lookup returns the registered query document for the given hash.
+func (a *Allowlist) Lookup(hash string) (string, bool) {
+	if a == nil {
+		return "", false
+	}
+	query, ok := a.operations[strings.ToLower(strings.TrimSpace(hash))]
+	return query, ok
+}
+
+// Len returns the number of registered operations.
+func (a *Allowlist) Len() int {
+	if a == nil {
+		return 0
+	}
+	return len(a.operations)
+}
+
+// HashQuery returns the hex-encoded SHA-256 hash used to register an operation.
+func HashQuery(query string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(query)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,458 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: content/v1/content.proto
+
+package contentv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Course is the catalog entry of a course.
+type Course struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title       string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Description string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	// Empty when the course has no topic, level or instructor.
+	TopicId      string `protobuf:"bytes,4,opt,name=topic_id,json=topicId,proto3" json:"topic_id,omitempty"`
+	LevelId      string `protobuf:"bytes,5,opt,name=level_id,json=levelId,proto3" json:"level_id,omitempty"`
+	InstructorId string `protobuf:"bytes,6,opt,name=instructor_id,json=instructorId,proto3" json:"instructor_id,omitempty"`
+	ThumbnailUrl string `protobuf:"bytes,7,opt,name=thumbnail_url,json=thumbnailUrl,proto3" json:"thumbnail_url,omitempty"`
+	IsPublished  bool   `protobuf:"varint,8,opt,name=is_published,json=isPublished,proto3" json:"is_published,omitempty"`
+	IsFeatured   bool   `protobuf:"varint,9,opt,name=is_featured,json=isFeatured,proto3" json:"is_featured,omitempty"`
+	// In cents, the minor unit order-services charges in.
+	Price         int64   `protobuf:"varint,10,opt,name=price,proto3" json:"price,omitempty"`
+	DurationHours int32   `protobuf:"varint,11,opt,name=duration_hours,json=durationHours,proto3" json:"duration_hours,omitempty"`
+	AverageRating float64 `protobuf:"fixed64,12,opt,name=average_rating,json=averageRating,proto3" json:"average_rating,omitempty"`
+	ReviewCount   int32   `protobuf:"varint,13,opt,name=review_count,json=reviewCount,proto3" json:"review_count,omitempty"`
+	// Set while the course is published.
+	PublishedAt   *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=published_at,json=publishedAt,proto3" json:"published_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Course) Reset() {
+	*x = Course{}
+	mi := &file_content_v1_content_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Course) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Course) ProtoMessage() {}
+
+func (x *Course) ProtoReflect() protoreflect.Message {
+	mi := &file_content_v1_content_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Course.ProtoReflect.Descriptor instead.
+func (*Course) Descriptor() ([]byte, []int) {
+	return file_content_v1_content_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Course) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Course) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Course) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Course) GetTopicId() string {
+	if x != nil {
+		return x.TopicId
+	}
+	return ""
+}
+
+func (x *Course) GetLevelId() string {
+	if x != nil {
+		return x.LevelId
+	}
+	return ""
+}
+
+func (x *Course) GetInstructorId() string {
+	if x != nil {
+		return x.InstructorId
+	}
+	return ""
+}
+
+func (x *Course) GetThumbnailUrl() string {
+	if x != nil {
+		return x.ThumbnailUrl
+	}
+	return ""
+}
+
+func (x *Course) GetIsPublished() bool {
+	if x != nil {
+		return x.IsPublished
+	}
+	return false
+}
+
+func (x *Course) GetIsFeatured() bool {
+	if x != nil {
+		return x.IsFeatured
+	}
+	return false
+}
+
+func (x *Course) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *Course) GetDurationHours() int32 {
+	if x != nil {
+		return x.DurationHours
+	}
+	return 0
+}
+
+func (x *Course) GetAverageRating() float64 {
+	if x != nil {
+		return x.AverageRating
+	}
+	return 0
+}
+
+func (x *Course) GetReviewCount() int32 {
+	if x != nil {
+		return x.ReviewCount
+	}
+	return 0
+}
+
+func (x *Course) GetPublishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PublishedAt
+	}
+	return nil
+}
+
+type GetCourseRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CourseId      string                 `protobuf:"bytes,1,opt,name=course_id,json=courseId,proto3" json:"course_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCourseRequest) Reset() {
+	*x = GetCourseRequest{}
+	mi := &file_content_v1_content_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCourseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCourseRequest) ProtoMessage() {}
+
+func (x *GetCourseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_content_v1_content_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCourseRequest.ProtoReflect.Descriptor instead.
+func (*GetCourseRequest) Descriptor() ([]byte, []int) {
+	return file_content_v1_content_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetCourseRequest) GetCourseId() string {
+	if x != nil {
+		return x.CourseId
+	}
+	return ""
+}
+
+type GetCourseResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Course        *Course                `protobuf:"bytes,1,opt,name=course,proto3" json:"course,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCourseResponse) Reset() {
+	*x = GetCourseResponse{}
+	mi := &file_content_v1_content_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCourseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCourseResponse) ProtoMessage() {}
+
+func (x *GetCourseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_content_v1_content_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCourseResponse.ProtoReflect.Descriptor instead.
+func (*GetCourseResponse) Descriptor() ([]byte, []int) {
+	return file_content_v1_content_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetCourseResponse) GetCourse() *Course {
+	if x != nil {
+		return x.Course
+	}
+	return nil
+}
+
+type BatchGetCoursesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CourseIds     []string               `protobuf:"bytes,1,rep,name=course_ids,json=courseIds,proto3" json:"course_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetCoursesRequest) Reset() {
+	*x = BatchGetCoursesRequest{}
+	mi := &file_content_v1_content_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetCoursesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetCoursesRequest) ProtoMessage() {}
+
+func (x *BatchGetCoursesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_content_v1_content_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetCoursesRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetCoursesRequest) Descriptor() ([]byte, []int) {
+	return file_content_v1_content_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BatchGetCoursesRequest) GetCourseIds() []string {
+	if x != nil {
+		return x.CourseIds
+	}
+	return nil
+}
+
+type BatchGetCoursesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The courses found, in the order of the request.
+	Courses []*Course `protobuf:"bytes,1,rep,name=courses,proto3" json:"courses,omitempty"`
+	// The requested IDs that are not valid UUIDs or belong to no course.
+	MissingCourseIds []string `protobuf:"bytes,2,rep,name=missing_course_ids,json=missingCourseIds,proto3" json:"missing_course_ids,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *BatchGetCoursesResponse) Reset() {
+	*x = BatchGetCoursesResponse{}
+	mi := &file_content_v1_content_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetCoursesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetCoursesResponse) ProtoMessage() {}
+
+func (x *BatchGetCoursesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_content_v1_content_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetCoursesResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetCoursesResponse) Descriptor() ([]byte, []int) {
+	return file_content_v1_content_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BatchGetCoursesResponse) GetCourses() []*Course {
+	if x != nil {
+		return x.Courses
+	}
+	return nil
+}
+
+func (x *BatchGetCoursesResponse) GetMissingCourseIds() []string {
+	if x != nil {
+		return x.MissingCourseIds
+	}
+	return nil
+}
+
+var File_content_v1_content_proto protoreflect.FileDescriptor
+
+const file_content_v1_content_proto_rawDesc = "" +
+	"\n" +
+	"\x18content/v1/content.proto\x12\n" +
+	"content.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xda\x03\n" +
+	"\x06Course\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x19\n" +
+	"\btopic_id\x18\x04 \x01(\tR\atopicId\x12\x19\n" +
+	"\blevel_id\x18\x05 \x01(\tR\alevelId\x12#\n" +
+	"\rinstructor_id\x18\x06 \x01(\tR\finstructorId\x12#\n" +
+	"\rthumbnail_url\x18\a \x01(\tR\fthumbnailUrl\x12!\n" +
+	"\fis_published\x18\b \x01(\bR\visPublished\x12\x1f\n" +
+	"\vis_featured\x18\t \x01(\bR\n" +
+	"isFeatured\x12\x14\n" +
+	"\x05price\x18\n" +
+	" \x01(\x03R\x05price\x12%\n" +
+	"\x0eduration_hours\x18\v \x01(\x05R\rdurationHours\x12%\n" +
+	"\x0eaverage_rating\x18\f \x01(\x01R\raverageRating\x12!\n" +
+	"\freview_count\x18\r \x01(\x05R\vreviewCount\x12=\n" +
+	"\fpublished_at\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\vpublishedAt\"/\n" +
+	"\x10GetCourseRequest\x12\x1b\n" +
+	"\tcourse_id\x18\x01 \x01(\tR\bcourseId\"?\n" +
+	"\x11GetCourseResponse\x12*\n" +
+	"\x06course\x18\x01 \x01(\v2\x12.content.v1.CourseR\x06course\"7\n" +
+	"\x16BatchGetCoursesRequest\x12\x1d\n" +
+	"\n" +
+	"course_ids\x18\x01 \x03(\tR\tcourseIds\"u\n" +
+	"\x17BatchGetCoursesResponse\x12,\n" +
+	"\acourses\x18\x01 \x03(\v2\x12.content.v1.CourseR\acourses\x12,\n" +
+	"\x12missing_course_ids\x18\x02 \x03(\tR\x10missingCourseIds2\xb9\x01\n" +
+	"\x11CourseReadService\x12H\n" +
+	"\tGetCourse\x12\x1c.content.v1.GetCourseRequest\x1a\x1d.content.v1.GetCourseResponse\x12Z\n" +
+	"\x0fBatchGetCourses\x12\".content.v1.BatchGetCoursesRequest\x1a#.content.v1.BatchGetCoursesResponseB&Z$bff-services/internal/grpc/contentv1b\x06proto3"
+
+var (
+	file_content_v1_content_proto_rawDescOnce sync.Once
+	file_content_v1_content_proto_rawDescData []byte
+)
+
+func file_content_v1_content_proto_rawDescGZIP() []byte {
+	file_content_v1_content_proto_rawDescOnce.Do(func() {
+		file_content_v1_content_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_content_v1_content_proto_rawDesc), len(file_content_v1_content_proto_rawDesc)))
+	})
+	return file_content_v1_content_proto_rawDescData
+}
+
+var file_content_v1_content_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_content_v1_content_proto_goTypes = []any{
+	(*Course)(nil),                  // 0: content.v1.Course
+	(*GetCourseRequest)(nil),        // 1: content.v1.GetCourseRequest
+	(*GetCourseResponse)(nil),       // 2: content.v1.GetCourseResponse
+	(*BatchGetCoursesRequest)(nil),  // 3: content.v1.BatchGetCoursesRequest
+	(*BatchGetCoursesResponse)(nil), // 4: content.v1.BatchGetCoursesResponse
+	(*timestamppb.Timestamp)(nil),   // 5: google.protobuf.Timestamp
+}
+var file_content_v1_content_proto_depIdxs = []int32{
+	5, // 0: content.v1.Course.published_at:type_name -> google.protobuf.Timestamp
+	0, // 1: content.v1.GetCourseResponse.course:type_name -> content.v1.Course
+	0, // 2: content.v1.BatchGetCoursesResponse.courses:type_name -> content.v1.Course
+	1, // 3: content.v1.CourseReadService.GetCourse:input_type -> content.v1.GetCourseRequest
+	3, // 4: content.v1.CourseReadService.BatchGetCourses:input_type -> content.v1.BatchGetCoursesRequest
+	2, // 5: content.v1.CourseReadService.GetCourse:output_type -> content.v1.GetCourseResponse
+	4, // 6: content.v1.CourseReadService.BatchGetCourses:output_type -> content.v1.BatchGetCoursesResponse
+	5, // [5:7] is the sub-list for method output_type
+	3, // [3:5] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_content_v1_content_proto_init() }
+func file_content_v1_content_proto_init() {
+	if File_content_v1_content_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_content_v1_content_proto_rawDesc), len(file_content_v1_content_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_content_v1_content_proto_goTypes,
+		DependencyIndexes: file_content_v1_content_proto_depIdxs,
+		MessageInfos:      file_content_v1_content_proto_msgTypes,
+	}.Build()
+	File_content_v1_content_proto = out.File
+	file_content_v1_content_proto_goTypes = nil
+	file_content_v1_content_proto_depIdxs = nil
+}
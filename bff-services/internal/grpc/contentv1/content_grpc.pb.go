@@ -0,0 +1,173 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: content/v1/content.proto
+
+package contentv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	CourseReadService_GetCourse_FullMethodName       = "/content.v1.CourseReadService/GetCourse"
+	CourseReadService_BatchGetCourses_FullMethodName = "/content.v1.CourseReadService/BatchGetCourses"
+)
+
+// CourseReadServiceClient is the client API for CourseReadService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// CourseReadService resolves courses for the BFF and other internal services without
+// going through GraphQL. Calls carry a service token issued for content-services.
+type CourseReadServiceClient interface {
+	// GetCourse returns one course, published or not, or NOT_FOUND. Deleted courses are
+	// not found.
+	GetCourse(ctx context.Context, in *GetCourseRequest, opts ...grpc.CallOption) (*GetCourseResponse, error)
+	// BatchGetCourses returns the courses found among up to 100 IDs, as
+	// POST /internal/courses/batch does.
+	BatchGetCourses(ctx context.Context, in *BatchGetCoursesRequest, opts ...grpc.CallOption) (*BatchGetCoursesResponse, error)
+}
+
+type courseReadServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCourseReadServiceClient(cc grpc.ClientConnInterface) CourseReadServiceClient {
+	return &courseReadServiceClient{cc}
+}
+
+func (c *courseReadServiceClient) GetCourse(ctx context.Context, in *GetCourseRequest, opts ...grpc.CallOption) (*GetCourseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetCourseResponse)
+	err := c.cc.Invoke(ctx, CourseReadService_GetCourse_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *courseReadServiceClient) BatchGetCourses(ctx context.Context, in *BatchGetCoursesRequest, opts ...grpc.CallOption) (*BatchGetCoursesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchGetCoursesResponse)
+	err := c.cc.Invoke(ctx, CourseReadService_BatchGetCourses_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CourseReadServiceServer is the server API for CourseReadService service.
+// All implementations must embed UnimplementedCourseReadServiceServer
+// for forward compatibility.
+//
+// CourseReadService resolves courses for the BFF and other internal services without
+// going through GraphQL. Calls carry a service token issued for content-services.
+type CourseReadServiceServer interface {
+	// GetCourse returns one course, published or not, or NOT_FOUND. Deleted courses are
+	// not found.
+	GetCourse(context.Context, *GetCourseRequest) (*GetCourseResponse, error)
+	// BatchGetCourses returns the courses found among up to 100 IDs, as
+	// POST /internal/courses/batch does.
+	BatchGetCourses(context.Context, *BatchGetCoursesRequest) (*BatchGetCoursesResponse, error)
+	mustEmbedUnimplementedCourseReadServiceServer()
+}
+
+// UnimplementedCourseReadServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedCourseReadServiceServer struct{}
+
+func (UnimplementedCourseReadServiceServer) GetCourse(context.Context, *GetCourseRequest) (*GetCourseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCourse not implemented")
+}
+func (UnimplementedCourseReadServiceServer) BatchGetCourses(context.Context, *BatchGetCoursesRequest) (*BatchGetCoursesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetCourses not implemented")
+}
+func (UnimplementedCourseReadServiceServer) mustEmbedUnimplementedCourseReadServiceServer() {}
+func (UnimplementedCourseReadServiceServer) testEmbeddedByValue()                           {}
+
+// UnsafeCourseReadServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CourseReadServiceServer will
+// result in compilation errors.
+type UnsafeCourseReadServiceServer interface {
+	mustEmbedUnimplementedCourseReadServiceServer()
+}
+
+func RegisterCourseReadServiceServer(s grpc.ServiceRegistrar, srv CourseReadServiceServer) {
+	// If the following call pancis, it indicates UnimplementedCourseReadServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&CourseReadService_ServiceDesc, srv)
+}
+
+func _CourseReadService_GetCourse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCourseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CourseReadServiceServer).GetCourse(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CourseReadService_GetCourse_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CourseReadServiceServer).GetCourse(ctx, req.(*GetCourseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CourseReadService_BatchGetCourses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetCoursesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CourseReadServiceServer).BatchGetCourses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CourseReadService_BatchGetCourses_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CourseReadServiceServer).BatchGetCourses(ctx, req.(*BatchGetCoursesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CourseReadService_ServiceDesc is the grpc.ServiceDesc for CourseReadService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CourseReadService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "content.v1.CourseReadService",
+	HandlerType: (*CourseReadServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetCourse",
+			Handler:    _CourseReadService_GetCourse_Handler,
+		},
+		{
+			MethodName: "BatchGetCourses",
+			Handler:    _CourseReadService_BatchGetCourses_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "content/v1/content.proto",
+}
@@ -0,0 +1,535 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: progress/v1/progress.proto
+
+package progressv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// EnrollmentStatus is where a user is in a course.
+type EnrollmentStatus int32
+
+const (
+	EnrollmentStatus_ENROLLMENT_STATUS_UNSPECIFIED EnrollmentStatus = 0
+	EnrollmentStatus_ENROLLMENT_STATUS_ENROLLED    EnrollmentStatus = 1
+	EnrollmentStatus_ENROLLMENT_STATUS_IN_PROGRESS EnrollmentStatus = 2
+	EnrollmentStatus_ENROLLMENT_STATUS_COMPLETED   EnrollmentStatus = 3
+	EnrollmentStatus_ENROLLMENT_STATUS_CANCELLED   EnrollmentStatus = 4
+)
+
+// Enum value maps for EnrollmentStatus.
+var (
+	EnrollmentStatus_name = map[int32]string{
+		0: "ENROLLMENT_STATUS_UNSPECIFIED",
+		1: "ENROLLMENT_STATUS_ENROLLED",
+		2: "ENROLLMENT_STATUS_IN_PROGRESS",
+		3: "ENROLLMENT_STATUS_COMPLETED",
+		4: "ENROLLMENT_STATUS_CANCELLED",
+	}
+	EnrollmentStatus_value = map[string]int32{
+		"ENROLLMENT_STATUS_UNSPECIFIED": 0,
+		"ENROLLMENT_STATUS_ENROLLED":    1,
+		"ENROLLMENT_STATUS_IN_PROGRESS": 2,
+		"ENROLLMENT_STATUS_COMPLETED":   3,
+		"ENROLLMENT_STATUS_CANCELLED":   4,
+	}
+)
+
+func (x EnrollmentStatus) Enum() *EnrollmentStatus {
+	p := new(EnrollmentStatus)
+	*p = x
+	return p
+}
+
+func (x EnrollmentStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EnrollmentStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_progress_v1_progress_proto_enumTypes[0].Descriptor()
+}
+
+func (EnrollmentStatus) Type() protoreflect.EnumType {
+	return &file_progress_v1_progress_proto_enumTypes[0]
+}
+
+func (x EnrollmentStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EnrollmentStatus.Descriptor instead.
+func (EnrollmentStatus) EnumDescriptor() ([]byte, []int) {
+	return file_progress_v1_progress_proto_rawDescGZIP(), []int{0}
+}
+
+// Streak counts the consecutive days a user learned on.
+type Streak struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	UserId      string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	CurrentDays int32                  `protobuf:"varint,2,opt,name=current_days,json=currentDays,proto3" json:"current_days,omitempty"`
+	LongestDays int32                  `protobuf:"varint,3,opt,name=longest_days,json=longestDays,proto3" json:"longest_days,omitempty"`
+	// The last day with activity, YYYY-MM-DD in UTC; empty when there was none.
+	LastDay       string `protobuf:"bytes,4,opt,name=last_day,json=lastDay,proto3" json:"last_day,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Streak) Reset() {
+	*x = Streak{}
+	mi := &file_progress_v1_progress_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Streak) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Streak) ProtoMessage() {}
+
+func (x *Streak) ProtoReflect() protoreflect.Message {
+	mi := &file_progress_v1_progress_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Streak.ProtoReflect.Descriptor instead.
+func (*Streak) Descriptor() ([]byte, []int) {
+	return file_progress_v1_progress_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Streak) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Streak) GetCurrentDays() int32 {
+	if x != nil {
+		return x.CurrentDays
+	}
+	return 0
+}
+
+func (x *Streak) GetLongestDays() int32 {
+	if x != nil {
+		return x.LongestDays
+	}
+	return 0
+}
+
+func (x *Streak) GetLastDay() string {
+	if x != nil {
+		return x.LastDay
+	}
+	return ""
+}
+
+type BatchGetStreaksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserIds       []string               `protobuf:"bytes,1,rep,name=user_ids,json=userIds,proto3" json:"user_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetStreaksRequest) Reset() {
+	*x = BatchGetStreaksRequest{}
+	mi := &file_progress_v1_progress_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetStreaksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetStreaksRequest) ProtoMessage() {}
+
+func (x *BatchGetStreaksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_progress_v1_progress_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetStreaksRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetStreaksRequest) Descriptor() ([]byte, []int) {
+	return file_progress_v1_progress_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *BatchGetStreaksRequest) GetUserIds() []string {
+	if x != nil {
+		return x.UserIds
+	}
+	return nil
+}
+
+type BatchGetStreaksResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The streaks of the users who have one, in no particular order. Users without
+	// activity are left out rather than listed with zero days.
+	Streaks       []*Streak `protobuf:"bytes,1,rep,name=streaks,proto3" json:"streaks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetStreaksResponse) Reset() {
+	*x = BatchGetStreaksResponse{}
+	mi := &file_progress_v1_progress_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetStreaksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetStreaksResponse) ProtoMessage() {}
+
+func (x *BatchGetStreaksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_progress_v1_progress_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetStreaksResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetStreaksResponse) Descriptor() ([]byte, []int) {
+	return file_progress_v1_progress_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BatchGetStreaksResponse) GetStreaks() []*Streak {
+	if x != nil {
+		return x.Streaks
+	}
+	return nil
+}
+
+// CourseProgress is the enrollment of a user in a course.
+type CourseProgress struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	UserId   string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	CourseId string                 `protobuf:"bytes,2,opt,name=course_id,json=courseId,proto3" json:"course_id,omitempty"`
+	Status   EnrollmentStatus       `protobuf:"varint,3,opt,name=status,proto3,enum=progress.v1.EnrollmentStatus" json:"status,omitempty"`
+	// From 0 to 100.
+	ProgressPercent int32                  `protobuf:"varint,4,opt,name=progress_percent,json=progressPercent,proto3" json:"progress_percent,omitempty"`
+	EnrolledAt      *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=enrolled_at,json=enrolledAt,proto3" json:"enrolled_at,omitempty"`
+	// Set once the course is completed.
+	CompletedAt    *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	LastAccessedAt *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=last_accessed_at,json=lastAccessedAt,proto3" json:"last_accessed_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CourseProgress) Reset() {
+	*x = CourseProgress{}
+	mi := &file_progress_v1_progress_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CourseProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CourseProgress) ProtoMessage() {}
+
+func (x *CourseProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_progress_v1_progress_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CourseProgress.ProtoReflect.Descriptor instead.
+func (*CourseProgress) Descriptor() ([]byte, []int) {
+	return file_progress_v1_progress_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CourseProgress) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CourseProgress) GetCourseId() string {
+	if x != nil {
+		return x.CourseId
+	}
+	return ""
+}
+
+func (x *CourseProgress) GetStatus() EnrollmentStatus {
+	if x != nil {
+		return x.Status
+	}
+	return EnrollmentStatus_ENROLLMENT_STATUS_UNSPECIFIED
+}
+
+func (x *CourseProgress) GetProgressPercent() int32 {
+	if x != nil {
+		return x.ProgressPercent
+	}
+	return 0
+}
+
+func (x *CourseProgress) GetEnrolledAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EnrolledAt
+	}
+	return nil
+}
+
+func (x *CourseProgress) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+func (x *CourseProgress) GetLastAccessedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastAccessedAt
+	}
+	return nil
+}
+
+type GetCourseProgressRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	CourseId      string                 `protobuf:"bytes,2,opt,name=course_id,json=courseId,proto3" json:"course_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCourseProgressRequest) Reset() {
+	*x = GetCourseProgressRequest{}
+	mi := &file_progress_v1_progress_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCourseProgressRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCourseProgressRequest) ProtoMessage() {}
+
+func (x *GetCourseProgressRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_progress_v1_progress_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCourseProgressRequest.ProtoReflect.Descriptor instead.
+func (*GetCourseProgressRequest) Descriptor() ([]byte, []int) {
+	return file_progress_v1_progress_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetCourseProgressRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetCourseProgressRequest) GetCourseId() string {
+	if x != nil {
+		return x.CourseId
+	}
+	return ""
+}
+
+type GetCourseProgressResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Progress      *CourseProgress        `protobuf:"bytes,1,opt,name=progress,proto3" json:"progress,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCourseProgressResponse) Reset() {
+	*x = GetCourseProgressResponse{}
+	mi := &file_progress_v1_progress_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCourseProgressResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCourseProgressResponse) ProtoMessage() {}
+
+func (x *GetCourseProgressResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_progress_v1_progress_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCourseProgressResponse.ProtoReflect.Descriptor instead.
+func (*GetCourseProgressResponse) Descriptor() ([]byte, []int) {
+	return file_progress_v1_progress_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetCourseProgressResponse) GetProgress() *CourseProgress {
+	if x != nil {
+		return x.Progress
+	}
+	return nil
+}
+
+var File_progress_v1_progress_proto protoreflect.FileDescriptor
+
+const file_progress_v1_progress_proto_rawDesc = "" +
+	"\n" +
+	"\x1aprogress/v1/progress.proto\x12\vprogress.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x82\x01\n" +
+	"\x06Streak\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12!\n" +
+	"\fcurrent_days\x18\x02 \x01(\x05R\vcurrentDays\x12!\n" +
+	"\flongest_days\x18\x03 \x01(\x05R\vlongestDays\x12\x19\n" +
+	"\blast_day\x18\x04 \x01(\tR\alastDay\"3\n" +
+	"\x16BatchGetStreaksRequest\x12\x19\n" +
+	"\buser_ids\x18\x01 \x03(\tR\auserIds\"H\n" +
+	"\x17BatchGetStreaksResponse\x12-\n" +
+	"\astreaks\x18\x01 \x03(\v2\x13.progress.v1.StreakR\astreaks\"\xea\x02\n" +
+	"\x0eCourseProgress\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tcourse_id\x18\x02 \x01(\tR\bcourseId\x125\n" +
+	"\x06status\x18\x03 \x01(\x0e2\x1d.progress.v1.EnrollmentStatusR\x06status\x12)\n" +
+	"\x10progress_percent\x18\x04 \x01(\x05R\x0fprogressPercent\x12;\n" +
+	"\venrolled_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"enrolledAt\x12=\n" +
+	"\fcompleted_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\x12D\n" +
+	"\x10last_accessed_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x0elastAccessedAt\"P\n" +
+	"\x18GetCourseProgressRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tcourse_id\x18\x02 \x01(\tR\bcourseId\"T\n" +
+	"\x19GetCourseProgressResponse\x127\n" +
+	"\bprogress\x18\x01 \x01(\v2\x1b.progress.v1.CourseProgressR\bprogress*\xba\x01\n" +
+	"\x10EnrollmentStatus\x12!\n" +
+	"\x1dENROLLMENT_STATUS_UNSPECIFIED\x10\x00\x12\x1e\n" +
+	"\x1aENROLLMENT_STATUS_ENROLLED\x10\x01\x12!\n" +
+	"\x1dENROLLMENT_STATUS_IN_PROGRESS\x10\x02\x12\x1f\n" +
+	"\x1bENROLLMENT_STATUS_COMPLETED\x10\x03\x12\x1f\n" +
+	"\x1bENROLLMENT_STATUS_CANCELLED\x10\x042\xd3\x01\n" +
+	"\x0fProgressService\x12\\\n" +
+	"\x0fBatchGetStreaks\x12#.progress.v1.BatchGetStreaksRequest\x1a$.progress.v1.BatchGetStreaksResponse\x12b\n" +
+	"\x11GetCourseProgress\x12%.progress.v1.GetCourseProgressRequest\x1a&.progress.v1.GetCourseProgressResponseB'Z%bff-services/internal/grpc/progressv1b\x06proto3"
+
+var (
+	file_progress_v1_progress_proto_rawDescOnce sync.Once
+	file_progress_v1_progress_proto_rawDescData []byte
+)
+
+func file_progress_v1_progress_proto_rawDescGZIP() []byte {
+	file_progress_v1_progress_proto_rawDescOnce.Do(func() {
+		file_progress_v1_progress_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_progress_v1_progress_proto_rawDesc), len(file_progress_v1_progress_proto_rawDesc)))
+	})
+	return file_progress_v1_progress_proto_rawDescData
+}
+
+var file_progress_v1_progress_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_progress_v1_progress_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_progress_v1_progress_proto_goTypes = []any{
+	(EnrollmentStatus)(0),             // 0: progress.v1.EnrollmentStatus
+	(*Streak)(nil),                    // 1: progress.v1.Streak
+	(*BatchGetStreaksRequest)(nil),    // 2: progress.v1.BatchGetStreaksRequest
+	(*BatchGetStreaksResponse)(nil),   // 3: progress.v1.BatchGetStreaksResponse
+	(*CourseProgress)(nil),            // 4: progress.v1.CourseProgress
+	(*GetCourseProgressRequest)(nil),  // 5: progress.v1.GetCourseProgressRequest
+	(*GetCourseProgressResponse)(nil), // 6: progress.v1.GetCourseProgressResponse
+	(*timestamppb.Timestamp)(nil),     // 7: google.protobuf.Timestamp
+}
+var file_progress_v1_progress_proto_depIdxs = []int32{
+	1, // 0: progress.v1.BatchGetStreaksResponse.streaks:type_name -> progress.v1.Streak
+	0, // 1: progress.v1.CourseProgress.status:type_name -> progress.v1.EnrollmentStatus
+	7, // 2: progress.v1.CourseProgress.enrolled_at:type_name -> google.protobuf.Timestamp
+	7, // 3: progress.v1.CourseProgress.completed_at:type_name -> google.protobuf.Timestamp
+	7, // 4: progress.v1.CourseProgress.last_accessed_at:type_name -> google.protobuf.Timestamp
+	4, // 5: progress.v1.GetCourseProgressResponse.progress:type_name -> progress.v1.CourseProgress
+	2, // 6: progress.v1.ProgressService.BatchGetStreaks:input_type -> progress.v1.BatchGetStreaksRequest
+	5, // 7: progress.v1.ProgressService.GetCourseProgress:input_type -> progress.v1.GetCourseProgressRequest
+	3, // 8: progress.v1.ProgressService.BatchGetStreaks:output_type -> progress.v1.BatchGetStreaksResponse
+	6, // 9: progress.v1.ProgressService.GetCourseProgress:output_type -> progress.v1.GetCourseProgressResponse
+	8, // [8:10] is the sub-list for method output_type
+	6, // [6:8] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_progress_v1_progress_proto_init() }
+func file_progress_v1_progress_proto_init() {
+	if File_progress_v1_progress_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_progress_v1_progress_proto_rawDesc), len(file_progress_v1_progress_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_progress_v1_progress_proto_goTypes,
+		DependencyIndexes: file_progress_v1_progress_proto_depIdxs,
+		EnumInfos:         file_progress_v1_progress_proto_enumTypes,
+		MessageInfos:      file_progress_v1_progress_proto_msgTypes,
+	}.Build()
+	File_progress_v1_progress_proto = out.File
+	file_progress_v1_progress_proto_goTypes = nil
+	file_progress_v1_progress_proto_depIdxs = nil
+}
@@ -0,0 +1,171 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: progress/v1/progress.proto
+
+package progressv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ProgressService_BatchGetStreaks_FullMethodName   = "/progress.v1.ProgressService/BatchGetStreaks"
+	ProgressService_GetCourseProgress_FullMethodName = "/progress.v1.ProgressService/GetCourseProgress"
+)
+
+// ProgressServiceClient is the client API for ProgressService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ProgressService resolves learning progress, served by lesson-services, for the BFF
+// and other internal services. Calls carry a service token issued for lesson-services.
+type ProgressServiceClient interface {
+	// BatchGetStreaks returns the streaks of up to 100 users.
+	BatchGetStreaks(ctx context.Context, in *BatchGetStreaksRequest, opts ...grpc.CallOption) (*BatchGetStreaksResponse, error)
+	// GetCourseProgress returns the progress of a user in a course, or NOT_FOUND when
+	// they are not enrolled.
+	GetCourseProgress(ctx context.Context, in *GetCourseProgressRequest, opts ...grpc.CallOption) (*GetCourseProgressResponse, error)
+}
+
+type progressServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewProgressServiceClient(cc grpc.ClientConnInterface) ProgressServiceClient {
+	return &progressServiceClient{cc}
+}
+
+func (c *progressServiceClient) BatchGetStreaks(ctx context.Context, in *BatchGetStreaksRequest, opts ...grpc.CallOption) (*BatchGetStreaksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchGetStreaksResponse)
+	err := c.cc.Invoke(ctx, ProgressService_BatchGetStreaks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *progressServiceClient) GetCourseProgress(ctx context.Context, in *GetCourseProgressRequest, opts ...grpc.CallOption) (*GetCourseProgressResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetCourseProgressResponse)
+	err := c.cc.Invoke(ctx, ProgressService_GetCourseProgress_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ProgressServiceServer is the server API for ProgressService service.
+// All implementations must embed UnimplementedProgressServiceServer
+// for forward compatibility.
+//
+// ProgressService resolves learning progress, served by lesson-services, for the BFF
+// and other internal services. Calls carry a service token issued for lesson-services.
+type ProgressServiceServer interface {
+	// BatchGetStreaks returns the streaks of up to 100 users.
+	BatchGetStreaks(context.Context, *BatchGetStreaksRequest) (*BatchGetStreaksResponse, error)
+	// GetCourseProgress returns the progress of a user in a course, or NOT_FOUND when
+	// they are not enrolled.
+	GetCourseProgress(context.Context, *GetCourseProgressRequest) (*GetCourseProgressResponse, error)
+	mustEmbedUnimplementedProgressServiceServer()
+}
+
+// UnimplementedProgressServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedProgressServiceServer struct{}
+
+func (UnimplementedProgressServiceServer) BatchGetStreaks(context.Context, *BatchGetStreaksRequest) (*BatchGetStreaksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetStreaks not implemented")
+}
+func (UnimplementedProgressServiceServer) GetCourseProgress(context.Context, *GetCourseProgressRequest) (*GetCourseProgressResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCourseProgress not implemented")
+}
+func (UnimplementedProgressServiceServer) mustEmbedUnimplementedProgressServiceServer() {}
+func (UnimplementedProgressServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeProgressServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ProgressServiceServer will
+// result in compilation errors.
+type UnsafeProgressServiceServer interface {
+	mustEmbedUnimplementedProgressServiceServer()
+}
+
+func RegisterProgressServiceServer(s grpc.ServiceRegistrar, srv ProgressServiceServer) {
+	// If the following call pancis, it indicates UnimplementedProgressServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ProgressService_ServiceDesc, srv)
+}
+
+func _ProgressService_BatchGetStreaks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetStreaksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProgressServiceServer).BatchGetStreaks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProgressService_BatchGetStreaks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProgressServiceServer).BatchGetStreaks(ctx, req.(*BatchGetStreaksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProgressService_GetCourseProgress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCourseProgressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProgressServiceServer).GetCourseProgress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProgressService_GetCourseProgress_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProgressServiceServer).GetCourseProgress(ctx, req.(*GetCourseProgressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ProgressService_ServiceDesc is the grpc.ServiceDesc for ProgressService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ProgressService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "progress.v1.ProgressService",
+	HandlerType: (*ProgressServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "BatchGetStreaks",
+			Handler:    _ProgressService_BatchGetStreaks_Handler,
+		},
+		{
+			MethodName: "GetCourseProgress",
+			Handler:    _ProgressService_GetCourseProgress_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "progress/v1/progress.proto",
+}
@@ -0,0 +1,17 @@
+package i18n
+
+// catalogs maps a locale to translations keyed by the English message used in code.
+// English needs no catalog: untranslated messages are returned unchanged.
+var catalogs = map[string]map[string]string{
+	"vi": messagesVI,
+}
+
+// Translate returns the localized form of message, falling back to the original text.
+func Translate(locale, message string) string {
+	if catalog, ok := catalogs[locale]; ok {
+		if translated, ok := catalog[message]; ok {
+			return translated
+		}
+	}
+	return message
+}
@@ -0,0 +1,92 @@
+package i18n
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale is used when the client sends no supported language.
+const DefaultLocale = "en"
+
+// supportedLocales lists the locales the BFF has message catalogs for.
+var supportedLocales = map[string]struct{}{
+	"en": {},
+	"vi": {},
+}
+
+type localeContextKey struct{}
+
+// WithLocale stores the negotiated locale in ctx so service clients can forward it.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeContextKey{}, locale)
+}
+
+// LocaleFromContext returns the negotiated locale, or DefaultLocale when none is set.
+func LocaleFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return DefaultLocale
+	}
+	if locale, ok := ctx.Value(localeContextKey{}).(string); ok && locale != "" {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// ParseAcceptLanguage picks the best supported locale from an Accept-Language header,
+// honouring q-values and falling back from regional tags (vi-VN) to the base language.
+func ParseAcceptLanguage(header string) string {
+	type candidate struct {
+		tag     string
+		quality float64
+		order   int
+	}
+
+	var candidates []candidate
+	for i, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+		candidates = append(candidates, candidate{tag: tag, quality: quality, order: i})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+
+	for _, c := range candidates {
+		if locale, ok := Normalize(c.tag); ok {
+			return locale
+		}
+	}
+	return DefaultLocale
+}
+
+// Normalize maps a language tag to a supported locale.
+func Normalize(tag string) (string, bool) {
+	tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	if _, ok := supportedLocales[tag]; ok {
+		return tag, true
+	}
+	if base, _, found := strings.Cut(tag, "-"); found {
+		if _, ok := supportedLocales[base]; ok {
+			return base, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,334 @@
+package i18n
+
+var messagesVI = map[string]string{
+	// Authentication and authorization
+	"Unauthorized":               "Chưa xác thực",
+	"Forbidden":                  "Không có quyền truy cập",
+	"Failed to verify user role": "Không thể xác minh vai trò người dùng",
+
+	// Request validation
+	"Invalid request data":           "Dữ liệu yêu cầu không hợp lệ",
+	"Invalid request payload":        "Nội dung yêu cầu không hợp lệ",
+	"Invalid query parameters":       "Tham số truy vấn không hợp lệ",
+	"Invalid path parameters":        "Tham số đường dẫn không hợp lệ",
+	"Validation failed":              "Dữ liệu không hợp lệ",
+	"Invalid limit parameter":        "Tham số limit không hợp lệ",
+	"Invalid offset parameter":       "Tham số offset không hợp lệ",
+	"Invalid page parameter":         "Tham số page không hợp lệ",
+	"Invalid cursor parameter":       "Tham số cursor không hợp lệ",
+	"Invalid is_read parameter":      "Tham số is_read không hợp lệ",
+	"Invalid page_size parameter":    "Tham số page_size không hợp lệ",
+	"Invalid passed parameter":       "Tham số passed không hợp lệ",
+	"Invalid start_date parameter":   "Tham số start_date không hợp lệ",
+	"Invalid end_date parameter":     "Tham số end_date không hợp lệ",
+	"Invalid multipart payload":      "Dữ liệu tải lên không hợp lệ",
+	"Invalid GraphQL request":        "Yêu cầu GraphQL không hợp lệ",
+	"Invalid X-Chaos header":         "Header X-Chaos không hợp lệ",
+	"No images provided":             "Chưa chọn ảnh nào",
+	"User ID is required":            "Thiếu mã người dùng",
+	"Order ID is required":           "Thiếu mã đơn hàng",
+	"Coupon ID is required":          "Thiếu mã phiếu giảm giá",
+	"Session ID is required":         "Thiếu mã phiên",
+	"Lesson ID is required":          "Thiếu mã bài học",
+	"Quiz ID is required":            "Thiếu mã bài kiểm tra",
+	"Notification ID is required":    "Thiếu mã thông báo",
+	"Verification token is required": "Thiếu mã xác minh",
+
+	// Downstream availability
+	"Order service unavailable":   "Dịch vụ đơn hàng tạm thời không khả dụng",
+	"Payment service unavailable": "Dịch vụ thanh toán tạm thời không khả dụng",
+	"Coupon service unavailable":  "Dịch vụ phiếu giảm giá tạm thời không khả dụng",
+	"Content service unavailable": "Dịch vụ nội dung tạm thời không khả dụng",
+	"Content service error":       "Dịch vụ nội dung gặp lỗi",
+
+	// Account
+	"Unable to login":                   "Không thể đăng nhập",
+	"Unable to logout":                  "Không thể đăng xuất",
+	"Unable to refresh token":           "Không thể làm mới phiên đăng nhập",
+	"Unable to request account unlock":  "Không thể yêu cầu mở khóa tài khoản",
+	"Unable to unlock account":          "Không thể mở khóa tài khoản",
+	"Unable to register user":           "Không thể đăng ký tài khoản",
+	"Unable to verify email":            "Không thể xác minh email",
+	"Unable to fetch profile":           "Không thể tải hồ sơ",
+	"Unable to update profile":          "Không thể cập nhật hồ sơ",
+	"Unable to change password":         "Không thể đổi mật khẩu",
+	"Unable to initiate password reset": "Không thể yêu cầu đặt lại mật khẩu",
+	"Unable to confirm password reset":  "Không thể đặt lại mật khẩu",
+	"Unable to setup MFA":               "Không thể thiết lập xác thực đa yếu tố",
+	"Unable to verify MFA":              "Không thể xác minh xác thực đa yếu tố",
+	"Unable to disable MFA":             "Không thể tắt xác thực đa yếu tố",
+	"Unable to fetch sessions":          "Không thể tải danh sách phiên đăng nhập",
+	"Unable to revoke session":          "Không thể thu hồi phiên đăng nhập",
+	"Unable to revoke sessions":         "Không thể thu hồi các phiên đăng nhập",
+
+	// Passkeys
+	"Unable to start passkey login":        "Không thể bắt đầu đăng nhập bằng khóa truy cập",
+	"Unable to start passkey registration": "Không thể bắt đầu đăng ký khóa truy cập",
+	"Unable to register passkey":           "Không thể đăng ký khóa truy cập",
+	"Unable to fetch passkeys":             "Không thể tải danh sách khóa truy cập",
+	"Unable to rename passkey":             "Không thể đổi tên khóa truy cập",
+	"Unable to revoke passkey":             "Không thể thu hồi khóa truy cập",
+
+	// Phone MFA
+	"Unable to send verification code": "Không thể gửi mã xác minh",
+	"Unable to set up phone MFA":       "Không thể thiết lập xác thực qua điện thoại",
+	"Unable to verify phone number":    "Không thể xác minh số điện thoại",
+	"Unable to remove phone number":    "Không thể xóa số điện thoại",
+	"Unable to reorder MFA methods":    "Không thể sắp xếp lại các phương thức xác thực",
+
+	// Commerce
+	"Unable to create order":          "Không thể tạo đơn hàng",
+	"Unable to list orders":           "Không thể tải danh sách đơn hàng",
+	"Unable to fetch order":           "Không thể tải đơn hàng",
+	"Unable to cancel order":          "Không thể hủy đơn hàng",
+	"Unable to request refund":        "Không thể gửi yêu cầu hoàn tiền",
+	"Unable to list refunds":          "Không thể tải danh sách hoàn tiền",
+	"Unable to create payment intent": "Không thể khởi tạo thanh toán",
+	"Unable to confirm payment":       "Không thể xác nhận thanh toán",
+	"Unable to fetch payment history": "Không thể tải lịch sử thanh toán",
+	"Unable to fetch coupons":         "Không thể tải danh sách phiếu giảm giá",
+	"Unable to validate coupon":       "Không thể kiểm tra phiếu giảm giá",
+
+	// Learning
+	"Unable to fetch weekly leaderboard":  "Không thể tải bảng xếp hạng tuần",
+	"Unable to fetch monthly leaderboard": "Không thể tải bảng xếp hạng tháng",
+	"Unable to fetch streak leaderboard":  "Không thể tải bảng xếp hạng chuỗi ngày học",
+	"Unable to fetch today's activity":    "Không thể tải hoạt động hôm nay",
+	"Unable to enroll":                    "Không thể đăng ký khóa học",
+	"Unable to start quiz attempt":        "Không thể bắt đầu bài kiểm tra",
+	"Unable to submit quiz attempt":       "Không thể nộp bài kiểm tra",
+	"Unable to build dashboard summary":   "Không thể tải bảng điều khiển",
+
+	// Notifications
+	"Unable to get user notifications":          "Không thể tải thông báo",
+	"Unable to mark notifications as read":      "Không thể đánh dấu thông báo đã đọc",
+	"Unable to get unread count":                "Không thể tải số thông báo chưa đọc",
+	"Unable to get notification preferences":    "Không thể tải cài đặt thông báo",
+	"Unable to update notification preferences": "Không thể cập nhật cài đặt thông báo",
+
+	// Audit log
+	"Unable to query audit log":     "Không thể truy vấn nhật ký kiểm toán",
+	"Unable to fetch audit log":     "Không thể tải nhật ký bảo mật",
+	"Failed to retrieve audit logs": "Không thể tải nhật ký bảo mật",
+	"Unable to fetch login history": "Không thể tải lịch sử đăng nhập",
+
+	// Admin dashboard
+	"Unable to fetch user metrics":    "Không thể tải số liệu người dùng",
+	"Failed to retrieve user metrics": "Không thể tải số liệu người dùng",
+
+	// Data export
+	"Unable to request data export":                                 "Không thể yêu cầu xuất dữ liệu",
+	"Unable to fetch data exports":                                  "Không thể tải danh sách bản xuất dữ liệu",
+	"Unable to fetch data export":                                   "Không thể tải bản xuất dữ liệu",
+	"Unable to download data export":                                "Không thể tải xuống bản xuất dữ liệu",
+	"Failed to request data export":                                 "Không thể yêu cầu xuất dữ liệu",
+	"Failed to retrieve data exports":                               "Không thể tải danh sách bản xuất dữ liệu",
+	"Failed to retrieve data export":                                "Không thể tải bản xuất dữ liệu",
+	"Failed to download data export":                                "Không thể tải xuống bản xuất dữ liệu",
+	"A data export was requested recently. Please try again later.": "Bạn vừa yêu cầu xuất dữ liệu. Vui lòng thử lại sau.",
+	"Data export is not ready yet":                                  "Bản xuất dữ liệu chưa sẵn sàng",
+	"Data export has expired":                                       "Bản xuất dữ liệu đã hết hạn",
+
+	// Preferences
+	"Unable to fetch preferences":    "Không thể tải tùy chọn",
+	"Unable to update preferences":   "Không thể cập nhật tùy chọn",
+	"Failed to retrieve preferences": "Không thể tải tùy chọn",
+	"Failed to update preferences":   "Không thể cập nhật tùy chọn",
+
+	// Onboarding
+	"Unable to fetch onboarding progress":       "Không thể tải tiến độ làm quen",
+	"Unable to advance onboarding":              "Không thể cập nhật tiến độ làm quen",
+	"Failed to retrieve onboarding progress":    "Không thể tải tiến độ làm quen",
+	"Failed to advance onboarding":              "Không thể cập nhật tiến độ làm quen",
+	"Unknown onboarding step":                   "Bước làm quen không tồn tại",
+	"Only the level test can be skipped":        "Chỉ có thể bỏ qua bài kiểm tra trình độ",
+	"The onboarding step has not been done yet": "Bước làm quen này chưa được thực hiện",
+
+	// Avatar
+	"Unable to start avatar upload":                                "Không thể bắt đầu tải ảnh đại diện lên",
+	"Unable to complete avatar upload":                             "Không thể hoàn tất tải ảnh đại diện lên",
+	"Unable to remove avatar":                                      "Không thể xóa ảnh đại diện",
+	"Failed to start avatar upload":                                "Không thể bắt đầu tải ảnh đại diện lên",
+	"Failed to complete avatar upload":                             "Không thể hoàn tất tải ảnh đại diện lên",
+	"Failed to remove avatar":                                      "Không thể xóa ảnh đại diện",
+	"Image is too large":                                           "Ảnh quá lớn",
+	"Image cannot be used as an avatar":                            "Không thể dùng ảnh này làm ảnh đại diện",
+	"The image has not been uploaded yet":                          "Ảnh chưa được tải lên",
+	"Avatar upload is already completed or has expired":            "Lượt tải ảnh đại diện đã hoàn tất hoặc đã hết hạn",
+	"Too many avatar uploads in progress. Please try again later.": "Có quá nhiều lượt tải ảnh đại diện đang diễn ra. Vui lòng thử lại sau.",
+	"Avatar uploads are not available":                             "Chức năng tải ảnh đại diện hiện không khả dụng",
+
+	// Right to erasure
+	"Unable to request account erasure":              "Không thể yêu cầu xóa tài khoản",
+	"Unable to schedule account erasure":             "Không thể lên lịch xóa tài khoản",
+	"Unable to fetch erasure requests":               "Không thể tải danh sách yêu cầu xóa dữ liệu",
+	"Unable to fetch erasure request":                "Không thể tải yêu cầu xóa dữ liệu",
+	"Failed to request account erasure":              "Không thể yêu cầu xóa tài khoản",
+	"Failed to schedule account erasure":             "Không thể lên lịch xóa tài khoản",
+	"Failed to retrieve erasure requests":            "Không thể tải danh sách yêu cầu xóa dữ liệu",
+	"Failed to retrieve erasure request":             "Không thể tải yêu cầu xóa dữ liệu",
+	"Account has been erased":                        "Tài khoản đã bị xóa vĩnh viễn",
+	"Account has been erased and cannot be restored": "Tài khoản đã bị xóa vĩnh viễn và không thể khôi phục",
+	"Unable to recover account":                      "Không thể khôi phục tài khoản",
+	"Failed to recover account":                      "Không thể khôi phục tài khoản",
+	"Account cannot be recovered":                    "Tài khoản không thể được khôi phục",
+
+	// Bulk user import
+	"Unable to import users":        "Không thể nhập danh sách người dùng",
+	"Failed to import users":        "Không thể nhập danh sách người dùng",
+	"A CSV file is required":        "Cần tải lên tệp CSV",
+	"Invalid CSV file":              "Tệp CSV không hợp lệ",
+	"Too many users in one import":  "Quá nhiều người dùng trong một lần nhập",
+	"At least one user is required": "Cần ít nhất một người dùng",
+
+	// Organizations
+	"Unable to create organization":                        "Không thể tạo tổ chức",
+	"Failed to create organization":                        "Không thể tạo tổ chức",
+	"Unable to fetch organizations":                        "Không thể tải danh sách tổ chức",
+	"Failed to retrieve organizations":                     "Không thể tải danh sách tổ chức",
+	"Unable to update organization":                        "Không thể cập nhật tổ chức",
+	"Failed to update organization":                        "Không thể cập nhật tổ chức",
+	"Unable to fetch organization":                         "Không thể tải thông tin tổ chức",
+	"Failed to retrieve organization":                      "Không thể tải thông tin tổ chức",
+	"Unable to fetch organization members":                 "Không thể tải danh sách thành viên tổ chức",
+	"Failed to retrieve organization members":              "Không thể tải danh sách thành viên tổ chức",
+	"Unable to add organization member":                    "Không thể thêm thành viên vào tổ chức",
+	"Failed to add organization member":                    "Không thể thêm thành viên vào tổ chức",
+	"Unable to update organization member":                 "Không thể cập nhật thành viên tổ chức",
+	"Failed to update organization member":                 "Không thể cập nhật thành viên tổ chức",
+	"Unable to remove organization member":                 "Không thể xóa thành viên khỏi tổ chức",
+	"Failed to remove organization member":                 "Không thể xóa thành viên khỏi tổ chức",
+	"Member removed":                                       "Đã xóa thành viên",
+	"Organization not found":                               "Không tìm thấy tổ chức",
+	"Organization member not found":                        "Không tìm thấy thành viên tổ chức",
+	"An organization with this name already exists":        "Đã có tổ chức với tên này",
+	"The user is already a member of this organization":    "Người dùng đã là thành viên của tổ chức này",
+	"The organization has no seats left":                   "Tổ chức đã hết chỗ",
+	"The seat limit is lower than the seats already taken": "Giới hạn chỗ thấp hơn số chỗ đã sử dụng",
+	"An organization must keep at least one owner":         "Tổ chức phải có ít nhất một chủ sở hữu",
+	"You are not allowed to manage this organization":      "Bạn không có quyền quản lý tổ chức này",
+
+	// Invitations
+	"Unable to create invitation":                               "Không thể tạo lời mời",
+	"Failed to create invitation":                               "Không thể tạo lời mời",
+	"Unable to fetch invitations":                               "Không thể tải danh sách lời mời",
+	"Failed to retrieve invitations":                            "Không thể tải danh sách lời mời",
+	"Unable to resend invitation":                               "Không thể gửi lại lời mời",
+	"Failed to resend invitation":                               "Không thể gửi lại lời mời",
+	"Unable to revoke invitation":                               "Không thể thu hồi lời mời",
+	"Failed to revoke invitation":                               "Không thể thu hồi lời mời",
+	"Unable to fetch invitation":                                "Không thể tải lời mời",
+	"Failed to retrieve invitation":                             "Không thể tải lời mời",
+	"Unable to accept invitation":                               "Không thể chấp nhận lời mời",
+	"Failed to accept invitation":                               "Không thể chấp nhận lời mời",
+	"Invitation token is required":                              "Cần có mã lời mời",
+	"Invitation revoked":                                        "Đã thu hồi lời mời",
+	"Invitation not found":                                      "Không tìm thấy lời mời",
+	"An invitation is already pending for this email":           "Email này đã có lời mời đang chờ",
+	"The invitation has already been accepted or revoked":       "Lời mời đã được chấp nhận hoặc đã bị thu hồi",
+	"Invalid invitation link":                                   "Liên kết lời mời không hợp lệ",
+	"The invitation has expired":                                "Lời mời đã hết hạn",
+	"You are not allowed to manage this invitation":             "Bạn không có quyền quản lý lời mời này",
+	"The invitation was sent recently. Please try again later.": "Lời mời vừa được gửi. Vui lòng thử lại sau.",
+
+	// Passwordless login
+	"Unable to send login email":                                  "Không thể gửi email đăng nhập",
+	"Failed to send login email":                                  "Không thể gửi email đăng nhập",
+	"Invalid or expired login link":                               "Liên kết đăng nhập không hợp lệ hoặc đã hết hạn",
+	"Invalid or expired login code":                               "Mã đăng nhập không hợp lệ hoặc đã hết hạn",
+	"The login must be completed on the device that requested it": "Cần hoàn tất đăng nhập trên thiết bị đã yêu cầu",
+	"A sign-in email was sent recently. Please try again later.":  "Email đăng nhập vừa được gửi. Vui lòng thử lại sau.",
+
+	// Password reset
+	"Invalid or expired reset link": "Liên kết đặt lại mật khẩu không hợp lệ hoặc đã hết hạn",
+	"The reset link must be opened in the browser and network it was requested from": "Cần mở liên kết đặt lại mật khẩu trên trình duyệt và mạng đã yêu cầu",
+
+	// Access tokens
+	"Unable to create access token":                       "Không thể tạo mã truy cập",
+	"Failed to create access token":                       "Không thể tạo mã truy cập",
+	"Unable to fetch access tokens":                       "Không thể tải danh sách mã truy cập",
+	"Failed to retrieve access tokens":                    "Không thể tải danh sách mã truy cập",
+	"Unable to revoke access token":                       "Không thể thu hồi mã truy cập",
+	"Failed to revoke access token":                       "Không thể thu hồi mã truy cập",
+	"Access token revoked":                                "Đã thu hồi mã truy cập",
+	"Access token not found":                              "Không tìm thấy mã truy cập",
+	"Invalid or expired access token":                     "Mã truy cập không hợp lệ hoặc đã hết hạn",
+	"Unable to verify access token":                       "Không thể xác minh mã truy cập",
+	"Access tokens are not available":                     "Mã truy cập hiện không khả dụng",
+	"Access tokens cannot be used for this endpoint":      "Không thể dùng mã truy cập cho chức năng này",
+	"Access token does not grant access to this endpoint": "Mã truy cập không có quyền dùng chức năng này",
+	"Too many active access tokens; revoke one first":     "Có quá nhiều mã truy cập đang hoạt động; hãy thu hồi bớt một mã",
+
+	// Account merges
+	"Unable to request account merge":                                     "Không thể yêu cầu gộp tài khoản",
+	"Failed to request account merge":                                     "Không thể yêu cầu gộp tài khoản",
+	"Unable to fetch account merge":                                       "Không thể tải yêu cầu gộp tài khoản",
+	"Failed to retrieve account merge":                                    "Không thể tải yêu cầu gộp tài khoản",
+	"Unable to merge accounts":                                            "Không thể gộp tài khoản",
+	"Failed to merge accounts":                                            "Không thể gộp tài khoản",
+	"Unable to cancel account merge":                                      "Không thể hủy gộp tài khoản",
+	"Failed to cancel account merge":                                      "Không thể hủy gộp tài khoản",
+	"Account merge cancelled":                                             "Đã hủy gộp tài khoản",
+	"Account merge not found":                                             "Không tìm thấy yêu cầu gộp tài khoản",
+	"Invalid merge ID":                                                    "Mã yêu cầu gộp tài khoản không hợp lệ",
+	"An account cannot be merged into itself":                             "Không thể gộp một tài khoản vào chính nó",
+	"Administrator accounts cannot be merged":                             "Không thể gộp tài khoản quản trị viên",
+	"The account merge has already been completed or cancelled":           "Yêu cầu gộp tài khoản đã hoàn tất hoặc đã bị hủy",
+	"The account merge codes have expired":                                "Mã xác minh gộp tài khoản đã hết hạn",
+	"Invalid verification code":                                           "Mã xác minh không hợp lệ",
+	"One of the accounts can no longer be merged":                         "Một trong hai tài khoản không còn gộp được",
+	"An account merge was requested recently. Please try again later.":    "Bạn vừa yêu cầu gộp tài khoản. Vui lòng thử lại sau.",
+	"Account has been merged into another account and cannot be restored": "Tài khoản đã được gộp vào tài khoản khác và không thể khôi phục",
+
+	// Impersonation
+	"Unable to start impersonation":                              "Không thể bắt đầu phiên đóng vai người dùng",
+	"Failed to start impersonation":                              "Không thể bắt đầu phiên đóng vai người dùng",
+	"Unable to end impersonation":                                "Không thể kết thúc phiên đóng vai người dùng",
+	"Failed to end impersonation":                                "Không thể kết thúc phiên đóng vai người dùng",
+	"Impersonation ended":                                        "Đã kết thúc phiên đóng vai người dùng",
+	"Impersonation session not found":                            "Không tìm thấy phiên đóng vai người dùng",
+	"Invalid session ID":                                         "Mã phiên không hợp lệ",
+	"You are not permitted to impersonate users":                 "Bạn không có quyền đóng vai người dùng",
+	"Administrators and your own account cannot be impersonated": "Không thể đóng vai quản trị viên hoặc chính tài khoản của bạn",
+	"Only active accounts can be impersonated":                   "Chỉ có thể đóng vai tài khoản đang hoạt động",
+	"This endpoint is not available while impersonating a user":  "Không thể dùng chức năng này khi đang đóng vai người dùng",
+
+	// Maintenance
+	"We are performing scheduled maintenance, please try again later": "Hệ thống đang bảo trì, vui lòng thử lại sau",
+	"This feature is temporarily unavailable, please try again later": "Tính năng này tạm thời không khả dụng, vui lòng thử lại sau",
+	"Unable to update maintenance switch":                             "Không thể cập nhật trạng thái bảo trì",
+
+	// Partner API
+	"API key required":                               "Yêu cầu khóa API",
+	"Invalid API key":                                "Khóa API không hợp lệ",
+	"Unable to verify API key":                       "Không thể xác minh khóa API",
+	"API key does not grant access to this endpoint": "Khóa API không có quyền truy cập endpoint này",
+	"Rate limit exceeded":                            "Vượt quá giới hạn yêu cầu",
+	"Partner API is not available":                   "API đối tác hiện không khả dụng",
+	"Unable to issue API key":                        "Không thể tạo khóa API",
+	"Unable to list API keys":                        "Không thể tải danh sách khóa API",
+	"Unable to revoke API key":                       "Không thể thu hồi khóa API",
+	"API key not found":                              "Không tìm thấy khóa API",
+	"Unable to export progress":                      "Không thể xuất tiến độ học tập",
+
+	// Guest browsing
+	"Guest browsing is not available": "Chế độ khách hiện không khả dụng",
+	"Guest session required":          "Yêu cầu phiên khách",
+	"Guest session expired":           "Phiên khách đã hết hạn",
+	"Unable to start guest session":   "Không thể bắt đầu phiên khách",
+	"Unable to update guest session":  "Không thể cập nhật phiên khách",
+
+	// Terms of service and privacy policy consents
+	"Please accept the updated terms to continue":           "Vui lòng chấp nhận điều khoản mới để tiếp tục",
+	"Unable to fetch policies":                              "Không thể tải điều khoản và chính sách",
+	"Unable to fetch policy versions":                       "Không thể tải các phiên bản chính sách",
+	"Unable to publish policy":                              "Không thể công bố chính sách",
+	"Unable to fetch consents":                              "Không thể tải lịch sử đồng ý",
+	"Unable to record consent":                              "Không thể ghi nhận sự đồng ý",
+	"A newer version of the policy has been published":      "Đã có phiên bản chính sách mới hơn",
+	"This version of the policy has already been published": "Phiên bản chính sách này đã được công bố",
+
+	// Downstream errors
+	"Upstream service error":          "Dịch vụ gặp sự cố, vui lòng thử lại sau",
+	"Service temporarily unavailable": "Dịch vụ tạm thời không khả dụng",
+}
@@ -0,0 +1,42 @@
+package i18n
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// DefaultTimezone is used when the client does not send a valid IANA timezone.
+const DefaultTimezone = "UTC"
+
+type timezoneContextKey struct{}
+
+// WithTimezone stores the caller's IANA timezone in ctx so service clients can forward it.
+func WithTimezone(ctx context.Context, timezone string) context.Context {
+	return context.WithValue(ctx, timezoneContextKey{}, timezone)
+}
+
+// TimezoneFromContext returns the caller's timezone, or DefaultTimezone when none is set.
+func TimezoneFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return DefaultTimezone
+	}
+	if tz, ok := ctx.Value(timezoneContextKey{}).(string); ok && tz != "" {
+		return tz
+	}
+	return DefaultTimezone
+}
+
+// ParseTimezone validates an IANA timezone name such as "Pacific/Auckland". The
+// server-dependent "Local" zone is rejected.
+func ParseTimezone(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.EqualFold(name, "Local") {
+		return "", false
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return "", false
+	}
+	return loc.String(), true
+}
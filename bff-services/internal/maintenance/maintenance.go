@@ -0,0 +1,144 @@
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisKey is the hash holding switch states, one JSON-encoded State per switch:
+//
+//	HSET maintenance global '{"enabled":true,"message":"Back at 02:00 UTC","retry_after":1800}'
+//	HSET maintenance checkout '{"enabled":true}'
+//	HSET maintenance "route:GET /api/v1/leaderboards/weekly/current" '{"enabled":true}'
+const RedisKey = "maintenance"
+
+// Switch names. Global turns on maintenance mode for every non-exempt route; the named
+// switches are attached to route groups in the routes package.
+const (
+	Global             = "global"
+	SwitchCheckout     = "checkout"
+	SwitchLeaderboards = "leaderboards"
+)
+
+// RouteSwitch returns the switch name that disables a single route, identified by method
+// and route template, without a deploy.
+func RouteSwitch(method, route string) string {
+	return "route:" + method + " " + route
+}
+
+// State is the state of one switch. A switch with an Until in the past is off.
+type State struct {
+	Enabled    bool       `json:"enabled"`
+	Message    string     `json:"message,omitempty"`
+	RetryAfter int        `json:"retry_after,omitempty"`
+	Until      *time.Time `json:"until,omitempty"`
+}
+
+// Active reports whether the switch is currently on.
+func (s State) Active(now time.Time) bool {
+	return s.Enabled && (s.Until == nil || now.Before(*s.Until))
+}
+
+// Store loads switch states from Redis and keeps a short-lived snapshot so checks do not
+// hit Redis on every request.
+type Store struct {
+	redisClient *redis.Client
+	refresh     time.Duration
+
+	mu       sync.RWMutex
+	states   map[string]State
+	loadedAt time.Time
+}
+
+// NewStore creates a switch store backed by redisClient.
+func NewStore(redisClient *redis.Client) *Store {
+	return &Store{
+		redisClient: redisClient,
+		refresh:     5 * time.Second, // Incident toggles must take effect quickly
+	}
+}
+
+// States returns every configured switch. When Redis is unavailable the last snapshot is
+// served so a Redis blip neither opens nor closes the gateway.
+func (s *Store) States(ctx context.Context) map[string]State {
+	s.mu.RLock()
+	states, fresh := s.states, time.Since(s.loadedAt) < s.refresh
+	s.mu.RUnlock()
+	if fresh || s.redisClient == nil {
+		return states
+	}
+
+	raw, err := s.redisClient.HGetAll(ctx, RedisKey).Result()
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to load maintenance switches", "error", err)
+		return states
+	}
+
+	loaded := make(map[string]State, len(raw))
+	for name, value := range raw {
+		var state State
+		if err := json.Unmarshal([]byte(value), &state); err != nil {
+			slog.WarnContext(ctx, "invalid maintenance switch state", "switch", name, "error", err)
+			continue
+		}
+		loaded[name] = state
+	}
+
+	s.mu.Lock()
+	s.states = loaded
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return loaded
+}
+
+// Active returns the state of the named switch and whether it is currently on.
+func (s *Store) Active(ctx context.Context, name string) (State, bool) {
+	state, ok := s.States(ctx)[name]
+	if !ok || !state.Active(time.Now()) {
+		return State{}, false
+	}
+	return state, true
+}
+
+// ActiveNames returns the names of all switches that are currently on.
+func (s *Store) ActiveNames(ctx context.Context) []string {
+	now := time.Now()
+	names := []string{}
+	for name, state := range s.States(ctx) {
+		if state.Active(now) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Set stores the state of the named switch. Disabling a switch removes it.
+func (s *Store) Set(ctx context.Context, name string, state State) error {
+	var err error
+	if state.Enabled {
+		var data []byte
+		data, err = json.Marshal(state)
+		if err != nil {
+			return err
+		}
+		err = s.redisClient.HSet(ctx, RedisKey, name, data).Err()
+	} else {
+		err = s.redisClient.HDel(ctx, RedisKey, name).Err()
+	}
+	if err != nil {
+		return err
+	}
+
+	// Drop this instance's snapshot so the change applies here immediately; other
+	// instances pick it up on their next refresh.
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+	return nil
+}
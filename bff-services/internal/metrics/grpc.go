@@ -0,0 +1,27 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/ductan2/microservice-app/shared/metrics"
+)
+
+// GRPCDialOptions instruments the calls of a gRPC client to a downstream service like
+// NewTransport does for HTTP, recording them in the grpc_client_* families.
+func GRPCDialOptions(service string) []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			start := time.Now()
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			metrics.GRPCClientRequestDuration.WithLabelValues(service, method).Observe(time.Since(start).Seconds())
+			metrics.GRPCClientRequestsTotal.WithLabelValues(service, method, status.Code(err).String()).Inc()
+			return err
+		}),
+	}
+}
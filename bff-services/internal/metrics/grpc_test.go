@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/ductan2/microservice-app/shared/telemetry"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+)
+
+// TestGRPCDialOptions checks that gRPC calls to a service are counted per method and
+// status and carry the trace of the request.
+func TestGRPCDialOptions(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var traceparent string
+	server := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if values := md.Get(telemetry.TraceparentHeader); len(values) > 0 {
+			traceparent = values[0]
+		}
+		return handler(ctx, req)
+	}))
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(lis)
+	defer server.Stop()
+
+	opts := append(GRPCDialOptions("user-services-grpc"), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.NewClient(lis.Addr().String(), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	calls := metrics.GRPCClientRequestsTotal.WithLabelValues("user-services-grpc", "/grpc.health.v1.Health/Check", "OK")
+	before := testutil.ToFloat64(calls)
+
+	ctx, span := telemetry.Start(context.Background(), "GET /api/v1/leaderboard", trace.SpanKindServer)
+	defer span.End()
+	if _, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := testutil.ToFloat64(calls) - before; got != 1 {
+		t.Fatalf("counted %v calls to user-services-grpc, want 1", got)
+	}
+	remote := trace.SpanContextFromContext(telemetry.ContextWithTraceparent(context.Background(), traceparent))
+	if remote.TraceID() != span.SpanContext().TraceID() {
+		t.Fatalf("call sent traceparent %q, want trace %s", traceparent, span.SpanContext().TraceID())
+	}
+}
@@ -0,0 +1,23 @@
+// Package metrics builds the instrumented HTTP clients of the BFF. The metric families
+// themselves are the ones shared by every service, in shared/metrics.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/ductan2/microservice-app/shared/telemetry"
+)
+
+// NewTransport instruments outbound calls to a downstream service: it records request
+// counts and latency, and traces each call with a client span whose context it
+// propagates in the traceparent header.
+func NewTransport(service string, base http.RoundTripper) http.RoundTripper {
+	return metrics.NewTransport(service, telemetry.NewTransport(base))
+}
+
+// NewHTTPClient builds an http.Client whose calls to service are instrumented.
+func NewHTTPClient(service string, timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: NewTransport(service, nil)}
+}
@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/ductan2/microservice-app/shared/telemetry"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestNewHTTPClient checks that calls to a service are counted per service and carry
+// the trace of the request.
+func TestNewHTTPClient(t *testing.T) {
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get(telemetry.TraceparentHeader)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	calls := metrics.HTTPClientRequestsTotal.WithLabelValues("content-services", http.MethodGet, "202")
+	before := testutil.ToFloat64(calls)
+
+	ctx, span := telemetry.Start(context.Background(), "GET /api/v1/courses", trace.SpanKindServer)
+	defer span.End()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	resp, err := NewHTTPClient("content-services", time.Second).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if got := testutil.ToFloat64(calls) - before; got != 1 {
+		t.Fatalf("counted %v calls to content-services, want 1", got)
+	}
+	remote := trace.SpanContextFromContext(telemetry.ContextWithTraceparent(context.Background(), traceparent))
+	if remote.TraceID() != span.SpanContext().TraceID() {
+		t.Fatalf("call sent traceparent %q, want trace %s", traceparent, span.SpanContext().TraceID())
+	}
+}
@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"bff-services/internal/services"
+	"bff-services/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AccessTokenPrefix marks personal access tokens, which are sent as bearer tokens in
+// place of a session JWT.
+const AccessTokenPrefix = "uat_"
+
+// Scopes a personal access token can be granted.
+const (
+	AccessTokenScopeRead  = "read"
+	AccessTokenScopeWrite = "write"
+)
+
+const contextAccessTokenKey = "accessToken"
+
+// accessTokenDeniedPrefixes are the routes personal access tokens may not use whatever
+// their scopes: managing credentials, sessions and the account itself needs a signed-in
+// session, so a leaked token cannot be turned into a takeover.
+var accessTokenDeniedPrefixes = []string{
+	"/api/v1/admin",
+	"/api/v1/auth",
+	"/api/v1/users/logout",
+	"/api/v1/users/me/access-tokens",
+	"/api/v1/users/me/push-tokens",
+	"/api/v1/users/me/consents",
+	"/api/v1/users/me/merges",
+	"/api/v1/users/profile/data-exports",
+	"/api/v1/users/profile/erasure",
+	"/api/v1/users/profile/deactivate",
+	"/api/v1/password",
+	"/api/v1/mfa",
+	"/api/v1/sessions",
+	"/api/v1/account/devices",
+}
+
+// AccessTokenIdentity is the user a personal access token acts as.
+type AccessTokenIdentity struct {
+	TokenID          uuid.UUID  `json:"token_id"`
+	UserID           uuid.UUID  `json:"user_id"`
+	Email            string     `json:"email"`
+	Role             string     `json:"role"`
+	Scopes           []string   `json:"scopes"`
+	OrganizationID   *uuid.UUID `json:"organization_id,omitempty"`
+	OrganizationRole string     `json:"organization_role,omitempty"`
+}
+
+// AccessTokens resolves personal access tokens presented as bearer tokens, so that
+// AuthRequired and OptionalAuth accept them alongside session JWTs. The token must
+// carry the scope the request method needs, read for safe methods and write for the
+// rest, and may not reach the routes in accessTokenDeniedPrefixes. Requests with any
+// other Authorization header pass through untouched.
+func AccessTokens(userService services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := bearerAccessToken(c.GetHeader("Authorization"))
+		if !ok {
+			c.Next()
+			return
+		}
+		if userService == nil {
+			utils.FailWithCode(c, "Access tokens are not available", http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", nil)
+			c.Abort()
+			return
+		}
+
+		path := c.FullPath()
+		if path == "" {
+			path = c.Request.URL.Path
+		}
+		for _, prefix := range accessTokenDeniedPrefixes {
+			if path == prefix || strings.HasPrefix(path, prefix+"/") {
+				utils.FailWithCode(c, "Access tokens cannot be used for this endpoint", http.StatusForbidden, "ACCESS_TOKEN_NOT_ALLOWED", nil)
+				c.Abort()
+				return
+			}
+		}
+
+		resp, err := userService.IntrospectAccessToken(c.Request.Context(), token, c.ClientIP())
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "access token introspection failed", "error", err)
+			utils.FailWithCode(c, "Unable to verify access token", http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", nil)
+			c.Abort()
+			return
+		}
+		switch {
+		case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusNotFound:
+			utils.FailWithCode(c, "Invalid or expired access token", http.StatusUnauthorized, "INVALID_ACCESS_TOKEN", nil)
+			c.Abort()
+			return
+		case resp.StatusCode != http.StatusOK:
+			slog.ErrorContext(c.Request.Context(), "access token introspection failed", "status", resp.StatusCode)
+			utils.FailWithCode(c, "Unable to verify access token", http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", nil)
+			c.Abort()
+			return
+		}
+
+		var body struct {
+			Data AccessTokenIdentity `json:"data"`
+		}
+		if err := json.Unmarshal(resp.Body, &body); err != nil || body.Data.UserID == uuid.Nil {
+			slog.ErrorContext(c.Request.Context(), "unreadable access token introspection response", "error", err)
+			utils.FailWithCode(c, "Unable to verify access token", http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", nil)
+			c.Abort()
+			return
+		}
+		identity := &body.Data
+
+		scope := requiredAccessTokenScope(c.Request.Method)
+		if !slices.Contains(identity.Scopes, scope) {
+			utils.FailWithCode(c, "Access token does not grant access to this endpoint", http.StatusForbidden, "INSUFFICIENT_SCOPE", gin.H{"required_scope": scope})
+			c.Abort()
+			return
+		}
+
+		c.Set(contextAccessTokenKey, identity)
+		c.Next()
+	}
+}
+
+// GetAccessToken returns the personal access token that authenticated the request, if
+// one did.
+func GetAccessToken(c *gin.Context) (*AccessTokenIdentity, bool) {
+	value, ok := c.Get(contextAccessTokenKey)
+	if !ok {
+		return nil, false
+	}
+	identity, ok := value.(*AccessTokenIdentity)
+	return identity, ok && identity != nil
+}
+
+// bearerAccessToken extracts a personal access token from an Authorization header.
+func bearerAccessToken(authHeader string) (string, bool) {
+	scheme, token, ok := strings.Cut(authHeader, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, strings.HasPrefix(token, AccessTokenPrefix)
+}
+
+// requiredAccessTokenScope is the scope a request with method needs.
+func requiredAccessTokenScope(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return AccessTokenScopeRead
+	}
+	return AccessTokenScopeWrite
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"bff-services/internal/apikeys"
+	"bff-services/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// APIKeyHeader carries partner API keys.
+	APIKeyHeader = "X-API-Key"
+
+	contextPartnerKey = "partnerKey"
+)
+
+// APIKeyRequired authenticates partner requests by API key instead of a user session.
+// The key must carry scope and is rate limited per key; when the limiter's Redis call
+// fails the request is let through rather than failing every partner call.
+func APIKeyRequired(store *apikeys.Store, scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if store == nil {
+			utils.FailWithCode(c, "Partner API is not available", http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", nil)
+			c.Abort()
+			return
+		}
+
+		presented := strings.TrimSpace(c.GetHeader(APIKeyHeader))
+		if presented == "" {
+			utils.FailWithCode(c, "API key required", http.StatusUnauthorized, "API_KEY_REQUIRED", nil)
+			c.Abort()
+			return
+		}
+
+		key, err := store.Lookup(c.Request.Context(), presented)
+		switch {
+		case errors.Is(err, apikeys.ErrKeyNotFound), errors.Is(err, apikeys.ErrKeyRevoked):
+			utils.FailWithCode(c, "Invalid API key", http.StatusUnauthorized, "INVALID_API_KEY", nil)
+			c.Abort()
+			return
+		case err != nil:
+			slog.ErrorContext(c.Request.Context(), "API key lookup failed", "error", err)
+			utils.FailWithCode(c, "Unable to verify API key", http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", nil)
+			c.Abort()
+			return
+		}
+
+		if !key.HasScope(scope) {
+			utils.FailWithCode(c, "API key does not grant access to this endpoint", http.StatusForbidden, "INSUFFICIENT_SCOPE", gin.H{"required_scope": scope})
+			c.Abort()
+			return
+		}
+
+		rate, err := store.Allow(c.Request.Context(), key)
+		if err != nil {
+			slog.WarnContext(c.Request.Context(), "API key rate limit check failed", "api_key_id", key.ID, "error", err)
+		} else {
+			rate.SetHeaders(c.Writer.Header())
+		}
+		if !rate.Allowed {
+			utils.FailWithCode(c, "Rate limit exceeded", http.StatusTooManyRequests, "RATE_LIMITED", nil)
+			c.Abort()
+			return
+		}
+
+		c.Set(contextPartnerKey, key)
+		c.Next()
+	}
+}
+
+// GetPartnerKey returns the API key that authenticated the current request.
+func GetPartnerKey(c *gin.Context) (*apikeys.Key, bool) {
+	value, ok := c.Get(contextPartnerKey)
+	if !ok {
+		return nil, false
+	}
+	key, ok := value.(*apikeys.Key)
+	return key, ok && key != nil
+}
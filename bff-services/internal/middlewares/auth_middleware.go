@@ -2,15 +2,19 @@ package middleware
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	"bff-services/internal/cache"
 	"bff-services/internal/services"
+	"bff-services/internal/tracing"
 	"bff-services/internal/utils"
 
+	"github.com/ductan2/microservice-app/shared/logging"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -20,6 +24,10 @@ const (
 	contextUserEmailKey = "userEmail"
 	contextSessionIDKey = "sessionID"
 	contextUserRoleKey  = "userRole"
+	contextOrgIDKey     = "organizationID"
+	contextOrgRoleKey   = "organizationRole"
+	// contextImpersonatorIDKey holds the administrator behind an impersonation session
+	contextImpersonatorIDKey = "impersonatorID"
 )
 
 const (
@@ -40,44 +48,169 @@ func AuthRequired(sessionCache *cache.SessionCache) gin.HandlerFunc {
 			return
 		}
 
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
-			utils.Fail(c, "Unauthorized", http.StatusUnauthorized, "invalid authorization header format")
+		claims, session, reason := authenticate(c, sessionCache, authHeader)
+		if claims == nil {
+			utils.Fail(c, "Unauthorized", http.StatusUnauthorized, reason)
 			c.Abort()
 			return
 		}
 
-		claims, err := utils.ValidateJWT(strings.TrimSpace(parts[1]))
-		if err != nil {
-			utils.Fail(c, "Unauthorized", http.StatusUnauthorized, err.Error())
-			c.Abort()
+		setUserContext(c, claims, session)
+		if !allowImpersonatedRequest(c) || !allowWithoutConsent(c, session) {
 			return
 		}
+		c.Next()
+	}
+}
 
-		// Check if session exists in Redis
-		log.Println("claims.SessionID", claims.SessionID)
-		sessionData, err := sessionCache.GetSession(c.Request.Context(), claims.SessionID)
-		if err != nil {
-			utils.Fail(c, "Unauthorized", http.StatusUnauthorized, "session not found or expired")
-			c.Abort()
+// OptionalAuth populates the user context when a valid Bearer token is supplied and
+// lets anonymous requests through. Invalid credentials are still rejected so clients
+// do not silently fall back to anonymous access.
+func OptionalAuth(sessionCache *cache.SessionCache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			c.Next()
 			return
 		}
 
-		// Additional validation: ensure userID in session matches JWT claims
-		if sessionData.UserID != claims.UserID {
-			utils.Fail(c, "Unauthorized", http.StatusUnauthorized, "session user mismatch")
+		claims, session, reason := authenticate(c, sessionCache, authHeader)
+		if claims == nil {
+			utils.Fail(c, "Unauthorized", http.StatusUnauthorized, reason)
 			c.Abort()
 			return
 		}
 
-		c.Set(contextUserIDKey, claims.UserID)
-		c.Set(contextUserEmailKey, claims.Email)
-		c.Set(contextSessionIDKey, claims.SessionID)
-
+		setUserContext(c, claims, session)
+		if !allowImpersonatedRequest(c) || !allowWithoutConsent(c, session) {
+			return
+		}
 		c.Next()
 	}
 }
 
+// authenticate validates the Authorization header and the backing Redis session.
+// It returns nil claims together with a failure reason when validation fails.
+func authenticate(c *gin.Context, sessionCache *cache.SessionCache, authHeader string) (*utils.Claims, *cache.SessionData, string) {
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return nil, nil, "invalid authorization header format"
+	}
+
+	// Personal access tokens were verified by AccessTokens; the token ID stands in for
+	// the session ID and there is no session to slide
+	if strings.HasPrefix(strings.TrimSpace(parts[1]), AccessTokenPrefix) {
+		identity, ok := GetAccessToken(c)
+		if !ok {
+			return nil, nil, "invalid access token"
+		}
+		claims := &utils.Claims{
+			UserID:           identity.UserID,
+			Email:            identity.Email,
+			SessionID:        identity.TokenID,
+			OrganizationID:   identity.OrganizationID,
+			OrganizationRole: identity.OrganizationRole,
+		}
+		return claims, &cache.SessionData{UserID: identity.UserID, Email: identity.Email, Role: identity.Role}, ""
+	}
+
+	claims, err := utils.ValidateJWT(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return nil, nil, err.Error()
+	}
+
+	// Reject tokens of sessions revoked by logout or refresh-token reuse detection
+	revoked, err := sessionCache.IsSessionRevoked(c.Request.Context(), claims.SessionID)
+	if err != nil {
+		return nil, nil, "unable to verify session"
+	}
+	if revoked {
+		return nil, nil, "session revoked"
+	}
+
+	// Check if session exists in Redis
+	sessionData, err := sessionCache.GetSession(c.Request.Context(), claims.SessionID)
+	if err != nil {
+		return nil, nil, "session not found or expired"
+	}
+
+	// Additional validation: ensure userID in session matches JWT claims
+	if sessionData.UserID != claims.UserID {
+		return nil, nil, "session user mismatch"
+	}
+	if !sameImpersonator(claims.ImpersonatorID, sessionData.ImpersonatorID) {
+		return nil, nil, "session impersonator mismatch"
+	}
+
+	// Slide the idle window; a failed refresh is not worth rejecting the request over.
+	if err := sessionCache.Touch(c.Request.Context(), claims.SessionID, sessionData); err != nil {
+		if errors.Is(err, cache.ErrSessionExpired) {
+			return nil, nil, "session expired"
+		}
+		slog.WarnContext(c.Request.Context(), "failed to refresh session", "session_id", claims.SessionID, "error", err)
+	}
+	setSessionExpiryHeaders(c, sessionCache, sessionData)
+
+	return claims, sessionData, ""
+}
+
+// setSessionExpiryHeaders tells the client when the session ends for good, i.e. its
+// absolute deadline, and flags responses within the warning window so the client can
+// re-authenticate proactively. Access token expiry is not reported: short-lived tokens
+// are renewed through /auth/refresh without ending the session.
+func setSessionExpiryHeaders(c *gin.Context, sessionCache *cache.SessionCache, sessionData *cache.SessionData) {
+	deadline := sessionCache.AbsoluteDeadline(sessionData)
+	if deadline.IsZero() {
+		return
+	}
+
+	c.Header("X-Session-Expires-At", deadline.UTC().Format(time.RFC3339))
+	if warning := sessionCache.ExpiryWarning(); warning > 0 && time.Until(deadline) <= warning {
+		c.Header("X-Session-Expiring", "true")
+	}
+}
+
+func setUserContext(c *gin.Context, claims *utils.Claims, session *cache.SessionData) {
+	c.Set(contextUserIDKey, claims.UserID)
+	c.Set(contextUserEmailKey, claims.Email)
+	c.Request = c.Request.WithContext(logging.WithUserID(c.Request.Context(), claims.UserID.String()))
+	c.Set(contextSessionIDKey, claims.SessionID)
+	if session != nil && session.Role != "" {
+		c.Set(contextUserRoleKey, session.Role)
+	}
+	if claims.OrganizationID != nil {
+		c.Set(contextOrgIDKey, claims.OrganizationID.String())
+		c.Set(contextOrgRoleKey, claims.OrganizationRole)
+	}
+	if session != nil && session.ImpersonatorID != nil {
+		impersonatorID := session.ImpersonatorID.String()
+		c.Set(contextImpersonatorIDKey, impersonatorID)
+		c.Request = c.Request.WithContext(tracing.WithImpersonator(c.Request.Context(), impersonatorID))
+		c.Header("X-Impersonated-By", impersonatorID)
+	}
+}
+
+func sameImpersonator(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// GetImpersonatorID returns the administrator acting as the authenticated user through
+// an impersonation session, or an empty string for the user's own sessions.
+func GetImpersonatorID(c *gin.Context) string {
+	return c.GetString(contextImpersonatorIDKey)
+}
+
+// GetOrganizationContext returns the caller's primary organization and their role in it
+// as carried in the access token, or empty strings for users outside any organization.
+// The token may lag a membership change until it is refreshed, so user-service remains
+// the authority for anything the role permits.
+func GetOrganizationContext(c *gin.Context) (orgID, role string) {
+	return c.GetString(contextOrgIDKey), c.GetString(contextOrgRoleKey)
+}
+
 // ContextUserIDKey exposes the context key used to store the authenticated user ID.
 func ContextUserIDKey() string {
 	return contextUserIDKey
@@ -143,43 +276,81 @@ func AdminRequired(userService services.UserService) gin.HandlerFunc {
 			return
 		}
 
-		// Get user information from user-service to check role
-		userResp, err := userService.GetUserById(c.Request.Context(), userID, email, sessionID, userID)
+		role, status, err := fetchUserRole(c, userService, userID, email, sessionID)
 		if err != nil {
-			utils.Fail(c, "Failed to verify user role", http.StatusInternalServerError, err.Error())
+			utils.Fail(c, "Failed to verify user role", status, err.Error())
 			c.Abort()
 			return
 		}
 
-		if userResp.StatusCode != http.StatusOK {
-			utils.Fail(c, "Failed to verify user role", userResp.StatusCode, "unable to fetch user data")
+		// Check if user is admin or super-admin
+		if role != RoleAdmin && role != RoleSuperAdmin {
+			utils.Fail(c, "Forbidden", http.StatusForbidden, "admin or super-admin role required")
 			c.Abort()
 			return
 		}
 
-		// Parse user data to get role
-		var userData struct {
-			Data struct {
-				Role string `json:"role"`
-			} `json:"data"`
-		}
+		// Store role in context for later use if needed
+		c.Set(contextUserRoleKey, role)
 
-		if err := json.Unmarshal(userResp.Body, &userData); err != nil {
-			utils.Fail(c, "Failed to parse user data", http.StatusInternalServerError, err.Error())
-			c.Abort()
-			return
-		}
+		c.Next()
+	}
+}
 
-		// Check if user is admin or super-admin
-		if userData.Data.Role != RoleAdmin && userData.Data.Role != RoleSuperAdmin {
-			utils.Fail(c, "Forbidden", http.StatusForbidden, "admin or super-admin role required")
-			c.Abort()
+// LoadUserRole resolves the role of an authenticated user and stores it in the context.
+// Anonymous requests pass through untouched and lookup failures leave the role unset,
+// so handlers must treat a missing role as the least privileged one.
+func LoadUserRole(userService services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDValue, exists := c.Get(contextUserIDKey)
+		if !exists || userService == nil || GetUserRole(c) != "" {
+			c.Next()
 			return
 		}
 
-		// Store role in context for later use if needed
-		c.Set(contextUserRoleKey, userData.Data.Role)
+		userID, _ := userIDValue.(uuid.UUID)
+		email := c.GetString(contextUserEmailKey)
+		sessionID, _ := c.Value(contextSessionIDKey).(uuid.UUID)
+
+		role, _, err := fetchUserRole(c, userService, userID.String(), email, sessionID.String())
+		if err != nil {
+			slog.WarnContext(c.Request.Context(), "unable to resolve user role", "error", err)
+		} else {
+			c.Set(contextUserRoleKey, role)
+		}
 
 		c.Next()
 	}
 }
+
+// GetUserRole returns the role stored by AdminRequired or LoadUserRole, or an empty string.
+func GetUserRole(c *gin.Context) string {
+	return c.GetString(contextUserRoleKey)
+}
+
+// fetchUserRole looks up the user's role from user-service, returning the HTTP status to
+// surface when the lookup fails.
+func fetchUserRole(c *gin.Context, userService services.UserService, userID, email, sessionID string) (string, int, error) {
+	// Get user information from user-service to check role
+	userResp, err := userService.GetUserById(c.Request.Context(), userID, email, sessionID, userID)
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+
+	if userResp.StatusCode != http.StatusOK {
+		return "", userResp.StatusCode, fmt.Errorf("unable to fetch user data")
+	}
+
+	// Parse user data to get role
+	var userData struct {
+		Data struct {
+			Role string `json:"role"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(userResp.Body, &userData); err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+
+	return userData.Data.Role, http.StatusOK, nil
+}
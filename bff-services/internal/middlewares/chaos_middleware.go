@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+
+	"bff-services/internal/utils"
+
+	"github.com/ductan2/microservice-app/shared/chaos"
+	"github.com/gin-gonic/gin"
+)
+
+// Chaos attaches the faults of the X-Chaos header to the request context, where the
+// transports of the service clients inject them. A nil injector, as in production,
+// ignores the header.
+func Chaos(injector *chaos.Injector) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, err := injector.WithHeader(c.Request.Context(), c.Request.Header)
+		if err != nil {
+			utils.Fail(c, "Invalid X-Chaos header", http.StatusBadRequest, err.Error())
+			c.Abort()
+			return
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,202 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// encoder is the part of gzip.Writer and brotli.Writer the middleware uses.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// encoderPools keeps the writers of each supported coding. Brotli runs at level 4, which
+// compresses JSON better than gzip at a similar cost; higher levels are too slow for
+// responses built on every request.
+var encoderPools = map[string]*sync.Pool{
+	"br": {New: func() interface{} {
+		return brotli.NewWriterLevel(io.Discard, 4)
+	}},
+	"gzip": {New: func() interface{} {
+		w, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+		return w
+	}},
+}
+
+// supportedEncodings lists the codings the middleware produces, the preferred first.
+var supportedEncodings = []string{"br", "gzip"}
+
+// Compression encodes compressible responses (JSON and text) whose body reaches minBytes
+// with brotli or gzip, whichever the client's Accept-Encoding prefers; brotli wins ties.
+// Smaller bodies are sent as-is since the framing overhead outweighs the savings.
+func Compression(minBytes int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodHead || c.GetHeader("Upgrade") != "" {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		coding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if coding == "" {
+			c.Next()
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: c.Writer, coding: coding, minBytes: minBytes}
+		c.Writer = cw
+		defer cw.finish()
+
+		c.Next()
+	}
+}
+
+// negotiateEncoding picks the supported coding with the highest q-value in the
+// Accept-Encoding header, or "" to send the body as-is. A coding not listed takes the
+// q-value of "*", if any.
+func negotiateEncoding(header string) string {
+	qualities := map[string]float64{}
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		if name == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					q = v
+				}
+			}
+		}
+		qualities[name] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, coding := range supportedEncodings {
+		q, ok := qualities[coding]
+		if !ok {
+			q = qualities["*"]
+		}
+		if q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+func isCompressibleContentType(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	switch {
+	case mediaType == "text/event-stream":
+		return false
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	case mediaType == "application/json", strings.HasSuffix(mediaType, "+json"):
+		return true
+	case mediaType == "application/javascript", mediaType == "application/xml":
+		return true
+	}
+	return false
+}
+
+// compressWriter buffers the body until it can decide whether compression is worthwhile,
+// then either streams through an encoder or flushes the buffer untouched.
+type compressWriter struct {
+	gin.ResponseWriter
+	coding   string
+	minBytes int
+	buf      bytes.Buffer
+	enc      encoder
+	decided  bool
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.decided {
+		if w.enc != nil {
+			return w.enc.Write(data)
+		}
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.buf.Write(data)
+	if w.buf.Len() >= w.minBytes {
+		if err := w.decide(); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush forces a decision so streamed responses are not held in the buffer.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		_ = w.decide()
+	}
+	if w.enc != nil {
+		_ = w.enc.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.Hijack()
+}
+
+func (w *compressWriter) decide() error {
+	w.decided = true
+
+	header := w.Header()
+	status := w.Status()
+	if w.buf.Len() >= w.minBytes &&
+		header.Get("Content-Encoding") == "" &&
+		status != http.StatusNoContent && status != http.StatusNotModified &&
+		isCompressibleContentType(header.Get("Content-Type")) {
+		header.Set("Content-Encoding", w.coding)
+		header.Del("Content-Length")
+
+		enc := encoderPools[w.coding].Get().(encoder)
+		enc.Reset(w.ResponseWriter)
+		w.enc = enc
+		_, err := w.enc.Write(w.buf.Bytes())
+		w.buf.Reset()
+		return err
+	}
+
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+func (w *compressWriter) finish() {
+	if !w.decided {
+		_ = w.decide()
+	}
+	if w.enc != nil {
+		_ = w.enc.Close()
+		encoderPools[w.coding].Put(w.enc)
+		w.enc = nil
+	}
+}
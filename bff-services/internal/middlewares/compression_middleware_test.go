@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	cases := []struct {
+		header string
+		want   string
+	}{
+		{header: "", want: ""},
+		{header: "gzip", want: "gzip"},
+		{header: "br", want: "br"},
+		{header: "GZIP", want: "gzip"},
+		{header: "gzip, deflate, br", want: "br"},
+		{header: "deflate", want: ""},
+		{header: "br;q=0, gzip", want: "gzip"},
+		{header: "br;q=0, gzip;q=0", want: ""},
+		{header: "gzip;q=0.5, br;q=0.8", want: "br"},
+		{header: "gzip;q=0.9, br;q=0.5", want: "gzip"},
+		{header: "gzip;q=1.0, br;q=1.0", want: "br"},
+		{header: "*", want: "br"},
+		{header: "*;q=0", want: ""},
+		{header: "*;q=0, gzip", want: "gzip"},
+		{header: "br;q=0, *", want: "gzip"},
+		{header: "identity", want: ""},
+		{header: "identity, *;q=0", want: ""},
+		{header: "gzip;q=invalid", want: "gzip"},
+	}
+
+	for _, tc := range cases {
+		if got := negotiateEncoding(tc.header); got != tc.want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", tc.header, got, tc.want)
+		}
+	}
+}
+
+func compressionRouter(minBytes int, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Compression(minBytes))
+	router.GET("/", handler)
+	return router
+}
+
+func decodeBody(t *testing.T, coding string, body io.Reader) string {
+	t.Helper()
+	var reader io.Reader
+	switch coding {
+	case "br":
+		reader = brotli.NewReader(body)
+	case "gzip":
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			t.Fatalf("gzip reader: %v", err)
+		}
+		defer gz.Close()
+		reader = gz
+	default:
+		reader = body
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("decode %q body: %v", coding, err)
+	}
+	return string(data)
+}
+
+// TestCompression checks which responses are encoded, and that encoded bodies decode to
+// what the handler wrote.
+func TestCompression(t *testing.T) {
+	largeJSON := `{"items":"` + strings.Repeat("leaderboard ", 200) + `"}`
+
+	cases := []struct {
+		name           string
+		acceptEncoding string
+		contentType    string
+		encoding       string
+		body           string
+		wantEncoding   string
+	}{
+		{name: "BrotliPreferred", acceptEncoding: "gzip, br", contentType: "application/json", body: largeJSON, wantEncoding: "br"},
+		{name: "GzipWhenBrotliRefused", acceptEncoding: "br;q=0, gzip", contentType: "application/json", body: largeJSON, wantEncoding: "gzip"},
+		{name: "Wildcard", acceptEncoding: "*", contentType: "text/plain; charset=utf-8", body: largeJSON, wantEncoding: "br"},
+		{name: "IdentityOnly", acceptEncoding: "identity", contentType: "application/json", body: largeJSON},
+		{name: "NoAcceptEncoding", contentType: "application/json", body: largeJSON},
+		{name: "BelowThreshold", acceptEncoding: "br, gzip", contentType: "application/json", body: `{"ok":true}`},
+		{name: "NotCompressible", acceptEncoding: "br, gzip", contentType: "image/png", body: largeJSON},
+		{name: "AlreadyEncoded", acceptEncoding: "br, gzip", contentType: "application/json", encoding: "deflate", body: largeJSON, wantEncoding: "deflate"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := compressionRouter(1024, func(c *gin.Context) {
+				if tc.encoding != "" {
+					c.Header("Content-Encoding", tc.encoding)
+				}
+				c.Data(http.StatusOK, tc.contentType, []byte(tc.body))
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tc.acceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Content-Encoding"); got != tc.wantEncoding {
+				t.Fatalf("Content-Encoding = %q, want %q", got, tc.wantEncoding)
+			}
+			if got := rec.Header().Get("Vary"); got != "Accept-Encoding" {
+				t.Fatalf("Vary = %q, want Accept-Encoding", got)
+			}
+
+			coding := tc.wantEncoding
+			if tc.encoding != "" {
+				// The handler's own encoding is passed through untouched
+				coding = ""
+			}
+			if got := decodeBody(t, coding, rec.Body); got != tc.body {
+				t.Fatalf("body = %.60q..., want %.60q...", got, tc.body)
+			}
+		})
+	}
+}
+
+// TestCompressionStreamsEvents checks that server-sent events are neither encoded nor
+// held back until the handler returns.
+func TestCompressionStreamsEvents(t *testing.T) {
+	release := make(chan struct{})
+	router := compressionRouter(1024, func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("data: first\n\n")
+		c.Writer.Flush()
+		<-release
+		_, _ = c.Writer.WriteString("data: second\n\n")
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept-Encoding", "br, gzip")
+	resp, err := (&http.Transport{DisableCompression: true}).RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if got := resp.Header.Get("Content-Encoding"); got != "" {
+		t.Fatalf("Content-Encoding = %q, want none", got)
+	}
+
+	lines := make(chan string)
+	go func() {
+		line, _ := bufio.NewReader(resp.Body).ReadString('\n')
+		lines <- line
+	}()
+	select {
+	case line := <-lines:
+		if line != "data: first\n" {
+			t.Fatalf("first line = %q", line)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("first event was not flushed before the handler returned")
+	}
+	close(release)
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"bff-services/internal/cache"
+	"bff-services/internal/consent"
+	"bff-services/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const contextConsentStoreKey = "consentStore"
+
+// consentExemptPrefixes stay reachable for users who have not accepted the current terms
+// of service or privacy policy: reading and accepting them, signing out, and the profile
+// routes through which a user who does not agree exports their data or closes the
+// account.
+var consentExemptPrefixes = []string{
+	"/api/v1/policies",
+	"/api/v1/users/me/consents",
+	"/api/v1/users/logout",
+	"/api/v1/users/profile",
+	"/api/v1/sessions",
+}
+
+// Consent makes the required policy versions available to AuthRequired and OptionalAuth,
+// which refuse sessions of users who have not accepted a version published with
+// requires_consent since they last accepted the document.
+func Consent(store *consent.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if store != nil {
+			c.Set(contextConsentStoreKey, store)
+		}
+		c.Next()
+	}
+}
+
+// allowWithoutConsent answers 403 CONSENT_REQUIRED, listing the documents to accept, when
+// the session's user must accept a newer policy version first. Sessions without recorded
+// consents (impersonation sessions and personal access tokens) are not checked. It
+// reports whether the request may continue.
+func allowWithoutConsent(c *gin.Context, session *cache.SessionData) bool {
+	if session == nil || session.Consents == nil {
+		return true
+	}
+	value, _ := c.Get(contextConsentStoreKey)
+	store, ok := value.(*consent.Store)
+	if !ok || store == nil {
+		return true
+	}
+
+	path := c.FullPath()
+	if path == "" {
+		path = c.Request.URL.Path
+	}
+	for _, prefix := range consentExemptPrefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+
+	pending := store.Pending(c.Request.Context(), session.Consents)
+	if len(pending) == 0 {
+		return true
+	}
+	utils.FailWithCode(c, "Please accept the updated terms to continue", http.StatusForbidden, "CONSENT_REQUIRED", gin.H{"documents": pending})
+	c.Abort()
+	return false
+}
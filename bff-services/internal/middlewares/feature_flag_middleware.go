@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+
+	"bff-services/internal/utils"
+
+	"github.com/ductan2/microservice-app/shared/flags"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	contextFeatureClientKey = "featureFlagClient"
+	contextFeatureFlagsKey  = "featureFlags"
+)
+
+// FeatureFlags makes the flag client available to handlers. Flags are evaluated lazily,
+// on first use, so the user context set by AuthRequired is taken into account.
+func FeatureFlags(client *flags.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if client != nil {
+			c.Set(contextFeatureClientKey, client)
+		}
+		c.Next()
+	}
+}
+
+// GetFeatureFlags returns every flag evaluated for the current caller, caching the result
+// on the request.
+func GetFeatureFlags(c *gin.Context) map[string]bool {
+	if cached, ok := c.Get(contextFeatureFlagsKey); ok {
+		if flags, ok := cached.(map[string]bool); ok {
+			return flags
+		}
+	}
+
+	value, ok := c.Get(contextFeatureClientKey)
+	client, _ := value.(*flags.Client)
+	if !ok || client == nil {
+		return map[string]bool{}
+	}
+
+	subject := flags.Subject{Role: GetUserRole(c)}
+	if v, ok := c.Get(contextUserIDKey); ok {
+		subject.UserID = utils.NormalizeUUIDOrString(v)
+	}
+
+	evaluated := client.Evaluate(c.Request.Context(), subject)
+	c.Set(contextFeatureFlagsKey, evaluated)
+	return evaluated
+}
+
+// IsFeatureEnabled reports whether the named flag is on for the current caller.
+func IsFeatureEnabled(c *gin.Context, name string) bool {
+	return GetFeatureFlags(c)[name]
+}
+
+// RequireFeature hides a route behind a flag, answering 404 while it is off so that
+// unreleased endpoints are indistinguishable from missing ones.
+func RequireFeature(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !IsFeatureEnabled(c, name) {
+			utils.Fail(c, "Not found", http.StatusNotFound, "feature not available")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
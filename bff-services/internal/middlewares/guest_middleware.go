@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"bff-services/internal/cache"
+	"bff-services/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// GuestSessionHeader carries the ID of an anonymous guest session.
+	GuestSessionHeader = "X-Guest-Session"
+
+	contextGuestSessionKey = "guestSession"
+)
+
+// GuestSessionRequired loads the guest session named by the X-Guest-Session header.
+// Guest routes are limited to browsing, so an unknown or expired session is rejected
+// and the client is expected to start a new one.
+func GuestSessionRequired(guestCache *cache.GuestCache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session, ok := loadGuestSession(c, guestCache)
+		if !ok {
+			c.Abort()
+			return
+		}
+
+		c.Set(contextGuestSessionKey, session)
+		c.Next()
+	}
+}
+
+func loadGuestSession(c *gin.Context, guestCache *cache.GuestCache) (*cache.GuestSession, bool) {
+	if guestCache == nil {
+		utils.FailWithCode(c, "Guest browsing is not available", http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", nil)
+		return nil, false
+	}
+
+	raw := strings.TrimSpace(c.GetHeader(GuestSessionHeader))
+	if raw == "" {
+		utils.FailWithCode(c, "Guest session required", http.StatusUnauthorized, "GUEST_SESSION_REQUIRED", nil)
+		return nil, false
+	}
+
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		utils.FailWithCode(c, "Guest session expired", http.StatusUnauthorized, "GUEST_SESSION_EXPIRED", nil)
+		return nil, false
+	}
+
+	session, err := guestCache.Get(c.Request.Context(), id)
+	switch {
+	case errors.Is(err, cache.ErrGuestSessionNotFound):
+		utils.FailWithCode(c, "Guest session expired", http.StatusUnauthorized, "GUEST_SESSION_EXPIRED", nil)
+		return nil, false
+	case err != nil:
+		slog.ErrorContext(c.Request.Context(), "failed to load guest session", "guest_session_id", id, "error", err)
+		utils.FailWithCode(c, "Guest browsing is not available", http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", nil)
+		return nil, false
+	}
+
+	return session, true
+}
+
+// GetGuestSession returns the guest session loaded by GuestSessionRequired, or nil.
+func GetGuestSession(c *gin.Context) *cache.GuestSession {
+	if value, exists := c.Get(contextGuestSessionKey); exists {
+		if session, ok := value.(*cache.GuestSession); ok {
+			return session
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"bff-services/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	contextLocaleKey   = "locale"
+	contextTimezoneKey = "timezone"
+)
+
+// Locale negotiates the response language from Accept-Language and stores it on the
+// request context, where utils.Fail and the downstream service clients pick it up.
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := i18n.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+
+		c.Set(contextLocaleKey, locale)
+		c.Request = c.Request.WithContext(i18n.WithLocale(c.Request.Context(), locale))
+		c.Header("Content-Language", locale)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+
+		c.Next()
+	}
+}
+
+// GetLocale returns the negotiated locale for the current request.
+func GetLocale(c *gin.Context) string {
+	if locale := c.GetString(contextLocaleKey); locale != "" {
+		return locale
+	}
+	return i18n.DefaultLocale
+}
+
+// Timezone reads the caller's IANA timezone from the X-Timezone header (or the `tz`
+// query parameter) and stores it on the request context. Service clients forward it as
+// X-User-Timezone so "today", week and month boundaries follow the user's calendar.
+// Invalid or missing values fall back to UTC.
+func Timezone() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw := c.GetHeader("X-Timezone")
+		if raw == "" {
+			raw = c.Query("tz")
+		}
+
+		tz, ok := i18n.ParseTimezone(raw)
+		if !ok {
+			tz = i18n.DefaultTimezone
+		}
+
+		c.Set(contextTimezoneKey, tz)
+		c.Request = c.Request.WithContext(i18n.WithTimezone(c.Request.Context(), tz))
+		c.Header("X-Timezone", tz)
+
+		c.Next()
+	}
+}
+
+// GetTimezone returns the resolved timezone for the current request.
+func GetTimezone(c *gin.Context) string {
+	if tz := c.GetString(contextTimezoneKey); tz != "" {
+		return tz
+	}
+	return i18n.DefaultTimezone
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"time"
+
+	"bff-services/internal/tracing"
+
+	"github.com/ductan2/microservice-app/shared/logging"
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/ductan2/microservice-app/shared/requestmeta"
+	"github.com/ductan2/microservice-app/shared/telemetry"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// RequestMeta propagates the caller's X-Request-ID or assigns a new one, and resolves the
+// client IP past proxies (see shared/requestmeta). The ID is echoed on the response,
+// forwarded to downstream services and included in error envelopes.
+func RequestMeta(proxies requestmeta.Proxies) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = requestmeta.Attach(c.Writer, c.Request, proxies)
+
+		c.Next()
+	}
+}
+
+// ClientInfo stores the caller's IP and user agent on the request context. The user
+// service client forwards them so security events are attributed to the end user's
+// device rather than to the gateway.
+func ClientInfo() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(tracing.WithClient(c.Request.Context(), tracing.Client{
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}))
+
+		c.Next()
+	}
+}
+
+// Tracing continues the caller's W3C trace (or starts a new one) with an otelgin server
+// span for this request, stored on the context so service clients propagate it
+// downstream.
+func Tracing() gin.HandlerFunc {
+	return otelgin.Middleware("bff-services")
+}
+
+// TraceID echoes the trace ID of the request in X-Trace-ID to help correlate client
+// reports with logs. It runs after Tracing.
+func TraceID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if traceID := telemetry.TraceID(c.Request.Context()); traceID != "" {
+			c.Header("X-Trace-ID", traceID)
+		}
+
+		c.Next()
+	}
+}
+
+// RequestLog writes a structured access log record for each request once it has been
+// served, with the request, user and trace IDs the inner middlewares stored on its
+// context.
+func RequestLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		logging.LogRequest(c.Request.Context(), logging.HTTPRequest{
+			Method:   c.Request.Method,
+			Route:    c.FullPath(),
+			Path:     c.Request.URL.Path,
+			Status:   c.Writer.Status(),
+			Latency:  time.Since(start),
+			ClientIP: c.ClientIP(),
+		})
+	}
+}
+
+// Metrics records request count and latency per matched route. Unmatched paths are
+// grouped under a single label to keep cardinality bounded.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		metrics.ObserveHTTPServer(c.Request.Method, c.FullPath(), c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// TestObservability checks that a request continues the caller's trace, echoes its ID
+// in X-Trace-ID and is counted under its route template.
+func TestObservability(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Tracing(), TraceID(), Metrics())
+	router.GET("/api/v1/courses/:id", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	counted := func(route, status string) float64 {
+		return testutil.ToFloat64(metrics.HTTPServerRequestsTotal.WithLabelValues(http.MethodGet, route, status))
+	}
+	before, beforeUnmatched := counted("/api/v1/courses/:id", "200"), counted("unmatched", "404")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/courses/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("X-Trace-ID"); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("X-Trace-ID = %q, want the caller's trace", got)
+	}
+	if got := counted("/api/v1/courses/:id", "200") - before; got != 1 {
+		t.Fatalf("counted %v requests under the route template, want 1", got)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/courses/42/unknown", nil))
+	if rec.Header().Get("X-Trace-ID") == "" {
+		t.Fatalf("request without traceparent got no trace ID")
+	}
+	if got := counted("unmatched", "404") - beforeUnmatched; got != 1 {
+		t.Fatalf("counted %v unmatched requests, want 1", got)
+	}
+}
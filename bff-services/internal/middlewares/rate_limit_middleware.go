@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"bff-services/internal/utils"
+
+	"github.com/ductan2/microservice-app/shared/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitByIP limits the requests of each client IP with policy, for routes that are
+// open to anonymous callers. Without a limiter requests pass through; when the limiter's
+// Redis call fails the limiter decides, see ratelimit.Config.FailOpen.
+func RateLimitByIP(limiter *ratelimit.Limiter, policy ratelimit.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limiter == nil {
+			c.Next()
+			return
+		}
+
+		result, err := limiter.Allow(c.Request.Context(), policy, "ip:"+c.ClientIP())
+		if err != nil {
+			slog.WarnContext(c.Request.Context(), "rate limit check failed", "policy", policy.Name, "error", err)
+		} else {
+			result.SetHeaders(c.Writer.Header())
+		}
+		if !result.Allowed {
+			utils.FailWithCode(c, "Too many requests. Please try again later.", http.StatusTooManyRequests, "RATE_LIMITED", nil)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
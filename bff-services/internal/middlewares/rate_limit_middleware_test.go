@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// TestRateLimitByIP checks that each client IP gets its own budget and that requests
+// over it are rejected with 429 and Retry-After.
+func TestRateLimitByIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	policy := ratelimit.Policy{Name: "test.guest", Algorithm: ratelimit.SlidingWindow, Limit: 2, Window: time.Hour}
+	limiter := ratelimit.New(ratelimit.NewMemoryStore(), ratelimit.Config{})
+
+	router := gin.New()
+	router.POST("/guest/session", RateLimitByIP(limiter, policy), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	send := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/guest/session", nil)
+		req.RemoteAddr = ip + ":41000"
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := send("203.0.113.7"); rec.Code != http.StatusCreated {
+			t.Fatalf("request %d: status = %d, want %d", i+1, rec.Code, http.StatusCreated)
+		}
+	}
+
+	rec := send("203.0.113.7")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("over the limit: status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Fatal("over the limit: Retry-After is not set")
+	}
+
+	if rec := send("198.51.100.20"); rec.Code != http.StatusCreated {
+		t.Fatalf("other IP: status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+}
+
+// TestRateLimitByIPWithoutLimiter checks that routes stay open when no limiter is set.
+func TestRateLimitByIPWithoutLimiter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	policy := ratelimit.Policy{Name: "test.guest", Algorithm: ratelimit.SlidingWindow, Limit: 1, Window: time.Hour}
+
+	router := gin.New()
+	router.POST("/guest/session", RateLimitByIP(nil, policy), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/guest/session", nil))
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("request %d: status = %d, want %d", i+1, rec.Code, http.StatusCreated)
+		}
+	}
+}
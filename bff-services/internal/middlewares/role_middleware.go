@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"bff-services/internal/audit"
+	"bff-services/internal/services"
+	"bff-services/internal/tracing"
+	"bff-services/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleRequired allows the request only when the authenticated user holds one of roles.
+// The role is read from the Redis session (populated at login); sessions created before
+// roles were cached fall back to a user-service lookup. Must be used after AuthRequired.
+func RoleRequired(userService services.UserService, roles ...string) gin.HandlerFunc {
+	allowed := make(map[string]struct{}, len(roles))
+	for _, role := range roles {
+		allowed[role] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		userID, email, sessionID, ok := GetUserContextFromMiddleware(c)
+		if !ok {
+			c.Abort()
+			return
+		}
+
+		role := GetUserRole(c)
+		if role == "" {
+			resolved, status, err := fetchUserRole(c, userService, userID, email, sessionID)
+			if err != nil {
+				utils.Fail(c, "Failed to verify user role", status, err.Error())
+				c.Abort()
+				return
+			}
+			role = resolved
+			c.Set(contextUserRoleKey, role)
+		}
+
+		if _, ok := allowed[role]; !ok {
+			utils.Fail(c, "Forbidden", http.StatusForbidden, "insufficient role")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// AuditLog records every request passing through it once the handler has completed,
+// including requests rejected by later auth or role checks. The action is the matched
+// route template, e.g. "PUT /api/v1/admin/users/:id/role". Request bodies are recorded
+// only as a SHA-256 hash so the log proves what was sent without storing personal data.
+func AuditLog(recorder audit.Recorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		payloadHash := hashRequestBody(c)
+		c.Set(contextAuditedKey, true)
+		c.Next()
+
+		if recorder == nil {
+			return
+		}
+		recorder.Record(c.Request.Context(), auditEntry(c, start, payloadHash))
+	}
+}
+
+// auditEntry describes the completed request for the audit log.
+func auditEntry(c *gin.Context, start time.Time, payloadHash string) audit.Entry {
+	status := c.Writer.Status()
+	entry := audit.Entry{
+		Timestamp:      start.UTC(),
+		RequestID:      tracing.RequestIDFromContext(c.Request.Context()),
+		ActorEmail:     c.GetString(contextUserEmailKey),
+		ActorRole:      GetUserRole(c),
+		ImpersonatorID: GetImpersonatorID(c),
+		Action:         c.Request.Method + " " + c.FullPath(),
+		Method:         c.Request.Method,
+		Path:           c.Request.URL.Path,
+		TargetID:       auditTargetID(c),
+		PayloadHash:    payloadHash,
+		StatusCode:     status,
+		Outcome:        audit.OutcomeForStatus(status),
+		ClientIP:       c.ClientIP(),
+		UserAgent:      c.Request.UserAgent(),
+		DurationMs:     time.Since(start).Milliseconds(),
+	}
+	if v, ok := c.Get(contextUserIDKey); ok {
+		entry.ActorID = utils.NormalizeUUIDOrString(v)
+	}
+	if v, ok := c.Get(contextSessionIDKey); ok {
+		entry.SessionID = utils.NormalizeUUIDOrString(v)
+	}
+	if key, ok := GetPartnerKey(c); ok {
+		entry.ActorID = "api_key:" + key.ID
+		entry.ActorRole = "partner"
+	}
+	return entry
+}
+
+// hashRequestBody returns the hex SHA-256 of the request body and restores the body for
+// the handler. Requests without a body yield an empty hash.
+func hashRequestBody(c *gin.Context) string {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return ""
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	_ = c.Request.Body.Close()
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil || len(body) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// auditTargetID returns the resource the request acts on, taken from the route's ID
+// parameter.
+func auditTargetID(c *gin.Context) string {
+	for _, name := range []string{"id", "user_id"} {
+		if value := c.Param(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
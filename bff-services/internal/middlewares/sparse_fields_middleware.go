@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"bff-services/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResponseShapeMobile is the X-Response-Shape value for low-bandwidth clients.
+const ResponseShapeMobile = "mobile"
+
+// mobileListExcludedFields are dropped from list items in the mobile shape. They hold
+// the heavy lesson/content bodies that are only needed on detail screens.
+var mobileListExcludedFields = []string{
+	"content", "body", "html", "transcript", "sections", "questions",
+	"answers", "exercises", "vocabulary", "attachments", "media", "metadata",
+}
+
+// SparseFields prunes successful JSON responses when the client asks for a reduced
+// payload, either with `fields=id,title,author.name` or `X-Response-Shape: mobile`.
+// Requests without either pass through untouched and unbuffered.
+func SparseFields() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", "X-Response-Shape")
+
+		selection := utils.ParseFieldSelection(c.Query("fields"))
+		mobile := strings.EqualFold(strings.TrimSpace(c.GetHeader("X-Response-Shape")), ResponseShapeMobile)
+		if selection == nil && !mobile {
+			c.Next()
+			return
+		}
+
+		bw := &bufferedWriter{ResponseWriter: c.Writer}
+		c.Writer = bw
+		c.Next()
+		c.Writer = bw.ResponseWriter
+
+		body := bw.buf.Bytes()
+		status := bw.Status()
+		if status >= http.StatusOK && status < http.StatusMultipleChoices && isJSONContentType(bw.Header().Get("Content-Type")) {
+			var document interface{}
+			if err := json.Unmarshal(body, &document); err == nil {
+				if selection != nil {
+					document = utils.SelectFields(document, selection)
+				}
+				if mobile {
+					document = utils.DropListFields(document, mobileListExcludedFields)
+				}
+				if pruned, err := json.Marshal(document); err == nil {
+					body = pruned
+				}
+			}
+		}
+
+		bw.Header().Del("Content-Length")
+		if len(body) > 0 {
+			_, _ = bw.ResponseWriter.Write(body)
+		}
+	}
+}
+
+func isJSONContentType(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// bufferedWriter holds the whole body so it can be rewritten after the handler returns.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	buf bytes.Buffer
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.buf.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.buf.WriteString(s)
+}
+
+// Flush is a no-op: the body is only sent once it has been pruned.
+func (w *bufferedWriter) Flush() {}
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"bff-services/internal/api/controllers"
+	"bff-services/internal/cache"
+	middleware "bff-services/internal/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupAccessTokenRoutes configures management of personal access tokens. Tokens are
+// managed from a signed-in session only; middleware.AccessTokens refuses these routes
+// to the tokens themselves.
+func SetupAccessTokenRoutes(api *gin.RouterGroup, controllers *controllers.Controllers, sessionCache *cache.SessionCache) {
+	if controllers == nil || controllers.User == nil || sessionCache == nil {
+		return
+	}
+
+	tokens := api.Group("/users/me/access-tokens")
+	tokens.Use(middleware.AuthRequired(sessionCache))
+	{
+		tokens.POST("", controllers.User.CreateAccessToken)
+		tokens.GET("", controllers.User.ListAccessTokens)
+		tokens.DELETE("/:id", controllers.User.RevokeAccessToken)
+	}
+}
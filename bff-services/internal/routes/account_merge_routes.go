@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"bff-services/internal/api/controllers"
+	"bff-services/internal/cache"
+	middleware "bff-services/internal/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupAccountMergeRoutes configures merging a duplicate account into the caller's,
+// which needs a signed-in session; middleware.AccessTokens refuses these routes to
+// personal access tokens.
+func SetupAccountMergeRoutes(api *gin.RouterGroup, controllers *controllers.Controllers, sessionCache *cache.SessionCache) {
+	if controllers == nil || controllers.User == nil || sessionCache == nil {
+		return
+	}
+
+	merges := api.Group("/users/me/merges")
+	merges.Use(middleware.AuthRequired(sessionCache))
+	{
+		merges.POST("", controllers.User.RequestAccountMerge)
+		merges.GET("/:id", controllers.User.GetAccountMerge)
+		merges.POST("/:id/confirm", controllers.User.ConfirmAccountMerge)
+		merges.DELETE("/:id", controllers.User.CancelAccountMerge)
+	}
+}
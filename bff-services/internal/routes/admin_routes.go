@@ -0,0 +1,135 @@
+package routes
+
+import (
+	"bff-services/internal/api/controllers"
+	"bff-services/internal/audit"
+	"bff-services/internal/cache"
+	middleware "bff-services/internal/middlewares"
+	"bff-services/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupAdminRoutes configures the consolidated /admin group. Every route requires an
+// admin or super-admin role. Every request, including rejected ones, is recorded in the
+// audit log, so the audit middleware runs before the auth checks.
+func SetupAdminRoutes(api *gin.RouterGroup, controllers *controllers.Controllers, sessionCache *cache.SessionCache, userService services.UserService, recorder audit.Recorder) {
+	if controllers == nil || sessionCache == nil {
+		return
+	}
+
+	admin := api.Group("/admin")
+	admin.Use(middleware.AuditLog(recorder))
+	admin.Use(middleware.AuthRequired(sessionCache))
+	admin.Use(middleware.RoleRequired(userService, middleware.RoleAdmin, middleware.RoleSuperAdmin))
+
+	if controllers.User != nil {
+		users := admin.Group("/users")
+		{
+			users.GET("", controllers.User.ListUsersWithProgress)
+			users.POST("/import", controllers.User.ImportUsers)
+			users.PUT("/:id/role", controllers.User.UpdateUserRole)
+			users.POST("/:id/lock", controllers.User.LockAccount)
+			users.POST("/:id/unlock", controllers.User.UnlockAccount)
+			users.DELETE("/:id", controllers.User.SoftDeleteAccount)
+			users.POST("/:id/restore", controllers.User.RestoreAccount)
+			users.GET("/:id/audit-logs", controllers.User.GetUserAuditLogs)
+			users.POST("/:id/erasure", controllers.User.ScheduleUserErasure)
+			users.POST("/:id/impersonations", controllers.User.StartImpersonation)
+		}
+		// Support impersonation; user-service further restricts it to
+		// IMPERSONATION_ALLOWED_ROLES
+		admin.DELETE("/impersonations/:id", controllers.User.EndImpersonation)
+		// Right-to-erasure requests and their completion reports
+		admin.GET("/erasures", controllers.User.ListErasures)
+		admin.GET("/erasures/:id", controllers.User.GetErasure)
+		// Headline user metrics of the back-office overview page
+		admin.GET("/dashboard/users", controllers.User.GetUserMetrics)
+		// Terms of service and privacy policy versions users must accept
+		admin.POST("/policies", controllers.User.PublishPolicy)
+		admin.GET("/policies/:document/versions", controllers.User.ListPolicyVersions)
+		// Account security events recorded by user-service, as opposed to the gateway
+		// request log under /audit-logs
+		admin.GET("/security-audit-logs", controllers.User.ListSecurityAuditLogs)
+
+		orgs := admin.Group("/organizations")
+		{
+			orgs.GET("", controllers.User.ListOrganizations)
+			orgs.POST("", controllers.User.CreateOrganization)
+			orgs.PATCH("/:id", controllers.User.UpdateOrganization)
+		}
+	}
+
+	if controllers.Order != nil {
+		orders := admin.Group("/orders")
+		{
+			orders.GET("", controllers.Order.AdminListOrders)
+			orders.GET("/stats", controllers.Order.AdminGetOrderStats)
+			orders.PUT("/:id", controllers.Order.AdminUpdateOrder)
+		}
+		// Purchase sagas run by order-service after payment
+		sagas := admin.Group("/sagas")
+		{
+			sagas.GET("", controllers.Order.AdminListSagas)
+			sagas.GET("/:id", controllers.Order.AdminGetSaga)
+			sagas.POST("/:id/retry", controllers.Order.AdminRetrySaga)
+			sagas.POST("/:id/compensate", controllers.Order.AdminCompensateSaga)
+		}
+	}
+
+	if controllers.Audit != nil {
+		admin.GET("/audit-logs", controllers.Audit.ListAuditLogs)
+	}
+
+	if controllers.Maintenance != nil {
+		admin.GET("/maintenance", controllers.Maintenance.ListSwitches)
+		admin.PUT("/maintenance", controllers.Maintenance.SetSwitch)
+	}
+
+	if controllers.FeatureFlag != nil {
+		flags := admin.Group("/flags")
+		{
+			flags.GET("", controllers.FeatureFlag.ListFlags)
+			flags.PUT("/:name", controllers.FeatureFlag.PutFlag)
+			flags.DELETE("/:name", controllers.FeatureFlag.DeleteFlag)
+		}
+	}
+
+	if controllers.Partner != nil {
+		keys := admin.Group("/api-keys")
+		{
+			keys.GET("", controllers.Partner.ListKeys)
+			keys.POST("", controllers.Partner.IssueKey)
+			keys.DELETE("/:id", controllers.Partner.RevokeKey)
+		}
+	}
+
+	if controllers.Content != nil {
+		admin.POST("/content/graphql", controllers.Content.ProxyGraphQL)
+	}
+
+	if controllers.Notification != nil {
+		// Localized copy of the notifications, rendered by notification-services
+		templates := admin.Group("/notification-templates")
+		{
+			templates.GET("", controllers.Notification.ListMessageTemplates)
+			templates.POST("", controllers.Notification.CreateMessageTemplate)
+			templates.GET("/:id", controllers.Notification.GetMessageTemplate)
+			templates.PUT("/:id", controllers.Notification.UpdateMessageTemplate)
+			templates.DELETE("/:id", controllers.Notification.DeleteMessageTemplate)
+			templates.POST("/:id/preview", controllers.Notification.PreviewMessageTemplate)
+		}
+	}
+
+	if controllers.Search != nil {
+		// Synonyms and misspelling corrections of the catalog search
+		synonyms := admin.Group("/search/synonyms")
+		{
+			synonyms.GET("", controllers.Search.ListSynonyms)
+			synonyms.POST("", controllers.Search.CreateSynonym)
+			synonyms.GET("/:id", controllers.Search.GetSynonym)
+			synonyms.PUT("/:id", controllers.Search.UpdateSynonym)
+			synonyms.DELETE("/:id", controllers.Search.DeleteSynonym)
+		}
+	}
+}
@@ -17,8 +17,18 @@ func SetupAuthRoutes(api *gin.RouterGroup, controllers *controllers.Controllers,
 	// Public authentication routes
 	api.POST("/users/register", controllers.User.Register)
 	api.POST("/users/login", controllers.User.Login)
-	api.POST("/users/logout", controllers.User.Logout)
+	api.POST("/users/login/otp/send", controllers.User.SendLoginOTP)
+	api.POST("/users/login/webauthn/begin", controllers.User.BeginPasskeyLogin)
+	api.POST("/users/login/webauthn/finish", controllers.User.FinishPasskeyLogin)
+	api.POST("/users/login/passwordless", controllers.User.RequestPasswordlessLogin)
+	api.POST("/users/login/passwordless/verify", controllers.User.VerifyPasswordlessLogin)
+	api.POST("/users/logout", middleware.AuthRequired(sessionCache), controllers.User.Logout)
+	api.POST("/auth/refresh", controllers.User.RefreshToken)
 	api.GET("/users/verify-email", controllers.User.VerifyEmail)
+	api.POST("/users/unlock/request", controllers.User.RequestAccountUnlock)
+	api.POST("/users/unlock/confirm", controllers.User.ConfirmAccountUnlock)
+	api.POST("/users/recover", controllers.User.RecoverAccount)
+	api.GET("/users/identifiers/username/availability", controllers.User.CheckUsernameAvailability)
 
 	// Protected profile routes
 	profile := api.Group("/users/profile")
@@ -26,5 +36,48 @@ func SetupAuthRoutes(api *gin.RouterGroup, controllers *controllers.Controllers,
 	{
 		profile.GET("", controllers.User.GetProfile)
 		profile.PUT("", controllers.User.UpdateProfile)
+		profile.GET("/audit-logs", controllers.User.GetMyAuditLogs)
+		profile.POST("/data-exports", controllers.User.RequestDataExport)
+		profile.GET("/data-exports", controllers.User.ListDataExports)
+		profile.GET("/data-exports/:id", controllers.User.GetDataExport)
+		profile.GET("/data-exports/:id/download", controllers.User.DownloadDataExport)
+		profile.POST("/erasure", controllers.User.RequestErasure)
+		profile.POST("/deactivate", controllers.User.DeactivateAccount)
+	}
+
+	api.GET("/users/me/login-history", middleware.AuthRequired(sessionCache), controllers.User.GetLoginHistory)
+
+	preferences := api.Group("/users/me/preferences")
+	preferences.Use(middleware.AuthRequired(sessionCache))
+	{
+		preferences.GET("", controllers.User.GetPreferences)
+		preferences.PATCH("", controllers.User.UpdatePreferences)
+	}
+
+	onboarding := api.Group("/users/me/onboarding")
+	onboarding.Use(middleware.AuthRequired(sessionCache))
+	{
+		onboarding.GET("", controllers.User.GetOnboarding)
+		onboarding.POST("/steps/:step", controllers.User.AdvanceOnboarding)
+	}
+
+	identifiers := api.Group("/users/me/identifiers")
+	identifiers.Use(middleware.AuthRequired(sessionCache))
+	{
+		identifiers.GET("", controllers.User.GetLoginIdentifiers)
+		identifiers.PUT("/username", controllers.User.SetUsername)
+		identifiers.DELETE("/username", controllers.User.RemoveUsername)
+		identifiers.GET("/phone/availability", controllers.User.CheckPhoneAvailability)
+		identifiers.POST("/phone", controllers.User.StartPhoneVerification)
+		identifiers.POST("/phone/verify", controllers.User.VerifyPhone)
+		identifiers.DELETE("/phone", controllers.User.RemovePhone)
+	}
+
+	avatar := api.Group("/users/me/avatar")
+	avatar.Use(middleware.AuthRequired(sessionCache))
+	{
+		avatar.POST("/uploads", controllers.User.CreateAvatarUpload)
+		avatar.POST("/uploads/:id/complete", controllers.User.CompleteAvatarUpload)
+		avatar.DELETE("", controllers.User.RemoveAvatar)
 	}
 }
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"bff-services/internal/api/controllers"
+	"bff-services/internal/cache"
+	middleware "bff-services/internal/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupConsentRoutes configures the public list of current policy versions and the
+// caller's consents. middleware.Consent exempts both from the consent check so users can
+// read and accept new versions; impersonation sessions and access tokens are refused the
+// consent routes so nobody accepts on the user's behalf.
+func SetupConsentRoutes(api *gin.RouterGroup, controllers *controllers.Controllers, sessionCache *cache.SessionCache) {
+	if controllers == nil || controllers.User == nil || sessionCache == nil {
+		return
+	}
+
+	api.GET("/policies", controllers.User.ListPolicies)
+
+	consents := api.Group("/users/me/consents")
+	consents.Use(middleware.AuthRequired(sessionCache))
+	{
+		consents.GET("", controllers.User.GetConsents)
+		consents.POST("", controllers.User.RecordConsent)
+	}
+}
@@ -4,12 +4,13 @@ import (
 	"bff-services/internal/api/controllers"
 	"bff-services/internal/cache"
 	middleware "bff-services/internal/middlewares"
+	"bff-services/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
 
 // SetupContentRoutes configures content-related routes
-func SetupContentRoutes(api *gin.RouterGroup, controllers *controllers.Controllers, sessionCache *cache.SessionCache) {
+func SetupContentRoutes(api *gin.RouterGroup, controllers *controllers.Controllers, sessionCache *cache.SessionCache, userService services.UserService) {
 	if controllers == nil || controllers.Content == nil || sessionCache == nil {
 		return
 	}
@@ -19,6 +20,13 @@ func SetupContentRoutes(api *gin.RouterGroup, controllers *controllers.Controlle
 		content.POST("/graphql", controllers.Content.ProxyGraphQL)
 	}
 
+	// Public GraphQL proxy restricted to allowlisted operations for anonymous users and learners
+	api.POST("/graphql",
+		middleware.OptionalAuth(sessionCache),
+		middleware.LoadUserRole(userService),
+		controllers.Content.ProxyPublicGraphQL,
+	)
+
 	// Protected media upload routes
 	media := content.Group("/media")
 	media.Use(middleware.AuthRequired(sessionCache))
@@ -3,6 +3,7 @@ package routes
 import (
 	"bff-services/internal/api/controllers"
 	"bff-services/internal/cache"
+	"bff-services/internal/maintenance"
 	middleware "bff-services/internal/middlewares"
 
 	"github.com/gin-gonic/gin"
@@ -19,7 +20,7 @@ func SetupCouponRoutes(api *gin.RouterGroup, controllers *controllers.Controller
 	{
 		coupons.GET("", controllers.Coupon.ListAvailableCoupons)
 		coupons.GET("/:id", controllers.Coupon.GetCoupon)
-		coupons.POST("/validate", controllers.Coupon.ValidateCoupon)
+		coupons.POST("/validate", middleware.KillSwitch(maintenance.SwitchCheckout), controllers.Coupon.ValidateCoupon)
 		coupons.GET("/usage", controllers.Coupon.GetUserCouponUsage)
 	}
 }
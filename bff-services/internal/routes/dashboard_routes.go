@@ -19,5 +19,6 @@ func SetupDashboardRoutes(api *gin.RouterGroup, controllers *controllers.Control
 	dashboard.Use(middleware.AuthRequired(sessionCache))
 	{
 		dashboard.GET("/summary", controllers.Dashboard.GetSummary)
+		dashboard.GET("/continue", controllers.Dashboard.GetContinueFeed)
 	}
 }
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"bff-services/internal/api/controllers"
+	"bff-services/internal/cache"
+	middleware "bff-services/internal/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupFeatureFlagRoutes configures the per-user feature flag endpoint
+func SetupFeatureFlagRoutes(api *gin.RouterGroup, controllers *controllers.Controllers, sessionCache *cache.SessionCache) {
+	if controllers == nil || controllers.FeatureFlag == nil || sessionCache == nil {
+		return
+	}
+
+	me := api.Group("/me")
+	me.Use(middleware.AuthRequired(sessionCache))
+	{
+		me.GET("/flags", controllers.FeatureFlag.GetMyFlags)
+	}
+}
@@ -0,0 +1,52 @@
+package routes
+
+import (
+	"time"
+
+	"bff-services/internal/api/controllers"
+	"bff-services/internal/cache"
+	middleware "bff-services/internal/middlewares"
+
+	"github.com/ductan2/microservice-app/shared/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// guestSessionPolicy bounds how many guest sessions a client IP starts, since starting
+// one needs no account and stores a session in Redis. RATE_LIMITS overrides it.
+var guestSessionPolicy = ratelimit.Policy{
+	Name:      "guest.session",
+	Algorithm: ratelimit.SlidingWindow,
+	Limit:     30,
+	Window:    time.Hour,
+}
+
+// SetupGuestRoutes configures anonymous guest browsing. A guest session unlocks a small
+// set of read-only routes; everything else still requires signing up.
+func SetupGuestRoutes(api *gin.RouterGroup, controllers *controllers.Controllers, sessionCache *cache.SessionCache, guestCache *cache.GuestCache, limiter *ratelimit.Limiter) {
+	if controllers == nil || controllers.Guest == nil || sessionCache == nil || guestCache == nil {
+		return
+	}
+
+	guest := api.Group("/guest")
+	guest.POST("/session", middleware.RateLimitByIP(limiter, guestSessionPolicy), controllers.Guest.CreateSession)
+
+	// Carry the guest's selections over once they have an account
+	guest.POST("/session/convert",
+		middleware.AuthRequired(sessionCache),
+		middleware.GuestSessionRequired(guestCache),
+		controllers.Guest.ConvertSession,
+	)
+
+	browsing := guest.Group("")
+	browsing.Use(middleware.GuestSessionRequired(guestCache))
+	{
+		browsing.GET("/session", controllers.Guest.GetSession)
+		browsing.PUT("/session", controllers.Guest.UpdateSession)
+		browsing.GET("/courses/:course_id/sample-lessons", controllers.Guest.ListSampleLessons)
+
+		// Content listings go through the allowlisted GraphQL proxy as an anonymous caller
+		if controllers.Content != nil {
+			browsing.POST("/graphql", controllers.Content.ProxyPublicGraphQL)
+		}
+	}
+}
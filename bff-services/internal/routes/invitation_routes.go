@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"bff-services/internal/api/controllers"
+	"bff-services/internal/cache"
+	middleware "bff-services/internal/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupInvitationRoutes configures invitation management, which user-service
+// authorizes for admins and organization owners and managers, and the public preview
+// and accept routes the emailed link leads to.
+func SetupInvitationRoutes(api *gin.RouterGroup, controllers *controllers.Controllers, sessionCache *cache.SessionCache) {
+	if controllers == nil || controllers.User == nil || sessionCache == nil {
+		return
+	}
+
+	// Public routes, authorized by the signed token
+	api.GET("/invitations/preview", controllers.User.PreviewInvitation)
+	api.POST("/invitations/accept", controllers.User.AcceptInvitation)
+
+	invitations := api.Group("/invitations")
+	invitations.Use(middleware.AuthRequired(sessionCache))
+	{
+		invitations.POST("", controllers.User.CreateInvitation)
+		invitations.GET("", controllers.User.ListInvitations)
+		invitations.POST("/:id/resend", controllers.User.ResendInvitation)
+		invitations.DELETE("/:id", controllers.User.RevokeInvitation)
+	}
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"bff-services/internal/api/controllers"
 	"bff-services/internal/cache"
+	"bff-services/internal/maintenance"
 	middleware "bff-services/internal/middlewares"
 
 	"github.com/gin-gonic/gin"
@@ -36,13 +37,14 @@ func SetupLessonRoutes(api *gin.RouterGroup, controllers *controllers.Controller
 			streaks.GET("/user/me", controllers.Lesson.GetMyStreak)
 			streaks.POST("/user/me/check", controllers.Lesson.CheckMyStreak)
 			streaks.GET("/user/me/status", controllers.Lesson.GetMyStreakStatus)
-			streaks.GET("/leaderboard", controllers.Lesson.GetStreakLeaderboard)
+			streaks.GET("/leaderboard", middleware.KillSwitch(maintenance.SwitchLeaderboards), controllers.Lesson.GetStreakLeaderboard)
 			streaks.GET("/user/:user_id", controllers.Lesson.GetStreakByUserID)
 		}
 	}
 
 	// Leaderboard routes (protected)
 	leaderboards := api.Group("/leaderboards")
+	leaderboards.Use(middleware.KillSwitch(maintenance.SwitchLeaderboards))
 	leaderboards.Use(middleware.AuthRequired(sessionCache))
 	{
 		leaderboards.GET("/weekly/current", controllers.Lesson.GetCurrentWeeklyLeaderboard)
@@ -26,5 +26,21 @@ func SetupMFARoutes(api *gin.RouterGroup, controllers *controllers.Controllers,
 		protectedMFA.POST("/setup", controllers.MFA.Setup)
 		protectedMFA.POST("/verify", controllers.MFA.Verify)
 		protectedMFA.POST("/disable", controllers.MFA.Disable)
+
+		// Passkeys (WebAuthn credentials)
+		protectedMFA.POST("/webauthn/register/begin", controllers.MFA.BeginPasskeyRegistration)
+		protectedMFA.POST("/webauthn/register/finish", controllers.MFA.FinishPasskeyRegistration)
+		protectedMFA.GET("/webauthn/credentials", controllers.MFA.Passkeys)
+		protectedMFA.PATCH("/webauthn/credentials/:id", controllers.MFA.RenamePasskey)
+		protectedMFA.POST("/webauthn/credentials/:id/revoke", controllers.MFA.RevokePasskey)
+
+		// Phone numbers for SMS/voice codes
+		protectedMFA.POST("/phone", controllers.MFA.SetupPhone)
+		protectedMFA.POST("/phone/:id/send", controllers.MFA.ResendPhoneCode)
+		protectedMFA.POST("/phone/:id/verify", controllers.MFA.ConfirmPhone)
+		protectedMFA.POST("/phone/:id/revoke", controllers.MFA.RevokePhone)
+
+		// Primary method first, then fallbacks
+		protectedMFA.PUT("/methods/order", controllers.MFA.ReorderMethods)
 	}
 }
@@ -28,6 +28,18 @@ func SetupNotificationRoutes(api *gin.RouterGroup, controllers *controllers.Cont
 		}
 	}
 
+	// Authenticated user's own notifications and preferences
+	me := api.Group("/notifications/me")
+	me.Use(middleware.AuthRequired(sessionCache))
+	{
+		me.GET("", controllers.Notification.ListMyNotifications)
+		me.PUT("/read", controllers.Notification.MarkMyNotificationsAsRead)
+		me.GET("/unread-count", controllers.Notification.GetMyUnreadCount)
+		me.DELETE("/:notificationId", controllers.Notification.DeleteMyNotification)
+		me.GET("/preferences", controllers.Notification.GetMyPreferences)
+		me.PUT("/preferences", controllers.Notification.UpdateMyPreferences)
+	}
+
 	// Protected user notification routes
 	userNotifications := api.Group("/notifications/users/:userId")
 	userNotifications.Use(middleware.AuthRequired(sessionCache))
@@ -3,6 +3,7 @@ package routes
 import (
 	"bff-services/internal/api/controllers"
 	"bff-services/internal/cache"
+	"bff-services/internal/maintenance"
 	middleware "bff-services/internal/middlewares"
 
 	"github.com/gin-gonic/gin"
@@ -17,9 +18,16 @@ func SetupOrderRoutes(api *gin.RouterGroup, controllers *controllers.Controllers
 	orders := api.Group("/orders")
 	orders.Use(middleware.AuthRequired(sessionCache))
 	{
-		orders.POST("", controllers.Order.CreateOrder)
+		orders.POST("", middleware.KillSwitch(maintenance.SwitchCheckout), controllers.Order.CreateOrder)
 		orders.GET("", controllers.Order.ListOrders)
 		orders.GET("/:id", controllers.Order.GetOrder)
 		orders.POST("/:id/cancel", controllers.Order.CancelOrder)
+		orders.POST("/:id/refund", controllers.Order.RequestRefund)
+	}
+
+	refunds := api.Group("/refunds")
+	refunds.Use(middleware.AuthRequired(sessionCache))
+	{
+		refunds.GET("", controllers.Order.ListRefunds)
 	}
 }
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"bff-services/internal/api/controllers"
+	"bff-services/internal/cache"
+	middleware "bff-services/internal/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupOrganizationRoutes configures the routes organization members use to manage
+// their own organization. user-service authorizes each call against the caller's
+// membership; creating and listing organizations lives under /admin.
+func SetupOrganizationRoutes(api *gin.RouterGroup, controllers *controllers.Controllers, sessionCache *cache.SessionCache) {
+	if controllers == nil || controllers.User == nil || sessionCache == nil {
+		return
+	}
+
+	orgs := api.Group("/organizations")
+	orgs.Use(middleware.AuthRequired(sessionCache))
+	{
+		orgs.GET("/:id", controllers.User.GetOrganization)
+		orgs.GET("/:id/members", controllers.User.ListOrganizationMembers)
+		orgs.POST("/:id/members", controllers.User.AddOrganizationMember)
+		orgs.PATCH("/:id/members/:user_id", controllers.User.UpdateOrganizationMember)
+		orgs.DELETE("/:id/members/:user_id", controllers.User.RemoveOrganizationMember)
+	}
+
+	api.GET("/users/me/organizations", middleware.AuthRequired(sessionCache), controllers.User.ListMyOrganizations)
+}
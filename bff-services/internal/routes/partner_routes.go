@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"bff-services/internal/api/controllers"
+	"bff-services/internal/apikeys"
+	"bff-services/internal/audit"
+	middleware "bff-services/internal/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupPartnerRoutes configures the partner API. Routes authenticate by API key rather
+// than a user session, each requiring its own scope, and every call is audited.
+func SetupPartnerRoutes(api *gin.RouterGroup, controllers *controllers.Controllers, keyStore *apikeys.Store, recorder audit.Recorder) {
+	if controllers == nil || controllers.Partner == nil || keyStore == nil {
+		return
+	}
+
+	partner := api.Group("/partner")
+	partner.Use(middleware.AuditLog(recorder))
+	{
+		partner.POST("/enrollments", middleware.APIKeyRequired(keyStore, apikeys.ScopeEnrollmentsWrite), controllers.Partner.ProvisionEnrollment)
+		partner.GET("/users/:user_id/progress", middleware.APIKeyRequired(keyStore, apikeys.ScopeProgressRead), controllers.Partner.ExportProgress)
+	}
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"bff-services/internal/api/controllers"
 	"bff-services/internal/cache"
+	"bff-services/internal/maintenance"
 	middleware "bff-services/internal/middlewares"
 
 	"github.com/gin-gonic/gin"
@@ -24,8 +25,8 @@ func SetupPaymentRoutes(api *gin.RouterGroup, controllers *controllers.Controlle
 	protected := api.Group("/")
 	protected.Use(middleware.AuthRequired(sessionCache))
 	{
-		protected.POST("/orders/:id/pay", controllers.Payment.CreatePaymentIntent)
-		protected.POST("/payments/:payment_intent_id/confirm", controllers.Payment.ConfirmPayment)
+		protected.POST("/orders/:id/pay", middleware.KillSwitch(maintenance.SwitchCheckout), controllers.Payment.CreatePaymentIntent)
+		protected.POST("/payments/:payment_intent_id/confirm", middleware.KillSwitch(maintenance.SwitchCheckout), controllers.Payment.ConfirmPayment)
 		protected.GET("/orders/:id/payment", controllers.Payment.GetPaymentByOrderID)
 		protected.GET("/payment-methods", controllers.Payment.GetPaymentMethods)
 		protected.GET("/payments", controllers.Payment.GetPaymentHistory)
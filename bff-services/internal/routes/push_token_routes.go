@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"bff-services/internal/api/controllers"
+	"bff-services/internal/cache"
+	middleware "bff-services/internal/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupPushTokenRoutes configures the registry of device tokens the apps push
+// notifications to. middleware.AccessTokens and impersonation sessions are refused these
+// routes, so neither can have a user's notifications sent to another device.
+func SetupPushTokenRoutes(api *gin.RouterGroup, controllers *controllers.Controllers, sessionCache *cache.SessionCache) {
+	if controllers == nil || controllers.User == nil || sessionCache == nil {
+		return
+	}
+
+	tokens := api.Group("/users/me/push-tokens")
+	tokens.Use(middleware.AuthRequired(sessionCache))
+	{
+		tokens.POST("", controllers.User.RegisterPushToken)
+		tokens.GET("", controllers.User.ListPushTokens)
+		tokens.POST("/unregister", controllers.User.UnregisterPushTokenByValue)
+		tokens.DELETE("/:id", controllers.User.UnregisterPushToken)
+	}
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"bff-services/internal/api/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupSearchRoutes configures the public catalog search and its typeahead: they only
+// find published content, so no session is required.
+func SetupSearchRoutes(api *gin.RouterGroup, controllers *controllers.Controllers) {
+	if controllers == nil || controllers.Search == nil {
+		return
+	}
+
+	api.GET("/search", controllers.Search.Search)
+	api.GET("/search/suggest", controllers.Search.Suggest)
+}
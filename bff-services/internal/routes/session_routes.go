@@ -29,5 +29,14 @@ func SetupSessionRoutes(api *gin.RouterGroup, controllers *controllers.Controlle
 			protectedSessions.DELETE("/:id", controllers.Session.Delete)
 			protectedSessions.POST("/revoke-all", controllers.Session.RevokeAll)
 		}
+
+		// Account page "my devices"
+		devices := api.Group("/account/devices")
+		devices.Use(middleware.AuthRequired(sessionCache))
+		{
+			devices.GET("", controllers.Session.ListDevices)
+			devices.DELETE("/:id", controllers.Session.RevokeDevice)
+			devices.POST("/revoke-all", controllers.Session.RevokeAll)
+		}
 	}
 }
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"bff-services/internal/api/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupStatusRoutes configures the public platform status endpoint
+func SetupStatusRoutes(api *gin.RouterGroup, controllers *controllers.Controllers) {
+	if controllers == nil || controllers.Maintenance == nil {
+		return
+	}
+
+	api.GET("/status", controllers.Maintenance.GetStatus)
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"bff-services/internal/api/controllers"
+	"bff-services/internal/services"
 )
 
 // initControllers initializes all controllers based on available services
@@ -12,19 +13,25 @@ func initControllers(deps Deps) *controllers.Controllers {
 	if deps.UserService != nil {
 		ctrl.Password = controllers.NewPasswordController(deps.UserService)
 		ctrl.MFA = controllers.NewMFAController(deps.UserService)
-		ctrl.Session = controllers.NewSessionController(deps.UserService)
+		ctrl.Session = controllers.NewSessionController(deps.UserService, deps.GeoIPService)
 		ctrl.ActivitySession = controllers.NewActivitySessionController(deps.UserService)
 	}
 
 	// Initialize user controller (requires both UserService and LessonService)
 	if deps.UserService != nil && deps.LessonService != nil {
 		ctrl.User = controllers.NewUserController(deps.UserService, deps.LessonService)
-		ctrl.Dashboard = controllers.NewDashboardController(deps.UserService, deps.LessonService)
+		if deps.ProfileCache != nil {
+			ctrl.User.SetProfileCache(deps.ProfileCache)
+		}
+		ctrl.Dashboard = controllers.NewDashboardController(deps.UserService, deps.LessonService, deps.RecommendationService)
+		if deps.FeedCache != nil {
+			ctrl.Dashboard.SetFeedCache(deps.FeedCache)
+		}
 	}
 
 	// Initialize other service controllers
 	if deps.ContentService != nil {
-		ctrl.Content = controllers.NewContentController(deps.ContentService)
+		ctrl.Content = controllers.NewContentController(deps.ContentService, deps.GraphQLAllowlist)
 	}
 
 	if deps.NotificationService != nil {
@@ -32,7 +39,17 @@ func initControllers(deps Deps) *controllers.Controllers {
 	}
 
 	if deps.LessonService != nil {
-		ctrl.Lesson = controllers.NewLessonController(deps.LessonService)
+		ctrl.Lesson = controllers.NewLessonControllerWithCache(deps.LessonService, deps.StreakCache)
+		if deps.FeedCache != nil {
+			ctrl.Lesson.SetFeedCache(deps.FeedCache)
+		}
+		if deps.UserService != nil && deps.ProfileCache != nil {
+			enricher := services.NewProfileEnricher(deps.UserService, deps.ProfileCache)
+			if deps.IdentityService != nil {
+				enricher.SetIdentityService(deps.IdentityService)
+			}
+			ctrl.Lesson.SetProfileEnricher(enricher)
+		}
 	}
 
 	if deps.QuizAttemptService != nil {
@@ -51,5 +68,29 @@ func initControllers(deps Deps) *controllers.Controllers {
 		ctrl.Coupon = controllers.NewCouponController(deps.CouponService)
 	}
 
+	if deps.SearchService != nil {
+		ctrl.Search = controllers.NewSearchController(deps.SearchService)
+	}
+
+	if deps.LessonService != nil && deps.APIKeys != nil {
+		ctrl.Partner = controllers.NewPartnerController(deps.LessonService, deps.APIKeys)
+	}
+
+	if deps.AuditReader != nil {
+		ctrl.Audit = controllers.NewAuditController(deps.AuditReader)
+	}
+
+	if deps.Maintenance != nil {
+		ctrl.Maintenance = controllers.NewMaintenanceController(deps.Maintenance)
+	}
+
+	if deps.LessonService != nil && deps.GuestCache != nil {
+		ctrl.Guest = controllers.NewGuestController(deps.GuestCache, deps.LessonService, deps.GuestSampleLessons)
+	}
+
+	if deps.FeatureFlags != nil {
+		ctrl.FeatureFlag = controllers.NewFeatureFlagController(deps.FeatureFlagStore, deps.FeatureFlags)
+	}
+
 	return ctrl
 }
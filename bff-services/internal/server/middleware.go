@@ -1,16 +1,39 @@
 package server
 
 import (
+	"log/slog"
+
 	"bff-services/internal/config"
+	middleware "bff-services/internal/middlewares"
 
 	"github.com/gin-gonic/gin"
 )
 
 // setupGlobalMiddlewares configures global middlewares for the router
-func setupGlobalMiddlewares(r *gin.Engine) {
-	r.Use(gin.Logger())
+func setupGlobalMiddlewares(r *gin.Engine, deps Deps) {
+	// Context.ClientIP reads X-Forwarded-For past the trusted proxies only
+	if err := config.GetTrustedProxies().Configure(r); err != nil {
+		slog.Warn("trusted proxies not set", "error", err)
+	}
+
+	r.Use(middleware.RequestLog())
 	r.Use(gin.Recovery())
+	r.Use(middleware.RequestMeta(config.GetTrustedProxies()))
+	r.Use(middleware.ClientInfo())
+	r.Use(middleware.Tracing())
+	r.Use(middleware.TraceID())
+	r.Use(middleware.Metrics())
+	r.Use(middleware.Chaos(deps.Chaos))
 	r.Use(corsMiddleware())
+	r.Use(middleware.Locale())
+	r.Use(middleware.Timezone())
+	r.Use(middleware.Compression(config.GetCompressionMinBytes()))
+	r.Use(middleware.SparseFields())
+	r.Use(middleware.FeatureFlags(deps.FeatureFlags))
+	r.Use(middleware.Maintenance(deps.Maintenance))
+	r.Use(middleware.Consent(deps.Consent))
+	r.Use(middleware.AccessTokens(deps.UserService))
+	r.Use(middleware.ImpersonationAudit(deps.AuditRecorder))
 }
 
 // corsMiddleware returns a CORS middleware function
@@ -24,8 +47,8 @@ func corsMiddleware() gin.HandlerFunc {
 		}
 
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization")
-		c.Header("Access-Control-Expose-Headers", "Content-Length")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization, Accept-Language, traceparent, X-Response-Shape, X-Timezone, X-Request-ID, X-Guest-Session, X-Chaos")
+		c.Header("Access-Control-Expose-Headers", "Content-Length, Content-Language, X-Trace-ID, X-Session-Expires-At, X-Session-Expiring, X-Impersonated-By, X-Timezone, X-Request-ID")
 		c.Header("Access-Control-Allow-Credentials", "true")
 
 		if c.Request.Method == "OPTIONS" {
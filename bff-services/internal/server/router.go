@@ -1,16 +1,31 @@
 package server
 
 import (
+	"log/slog"
+
 	"bff-services/internal/api/controllers"
+	"bff-services/internal/apikeys"
+	"bff-services/internal/audit"
 	"bff-services/internal/cache"
+	"bff-services/internal/consent"
+	"bff-services/internal/graphql"
+	"bff-services/internal/maintenance"
 	"bff-services/internal/routes"
 	"bff-services/internal/services"
+	"bff-services/internal/validation"
 
+	"github.com/ductan2/microservice-app/shared/chaos"
+	"github.com/ductan2/microservice-app/shared/flags"
+	"github.com/ductan2/microservice-app/shared/health"
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/ductan2/microservice-app/shared/ratelimit"
 	"github.com/gin-gonic/gin"
 )
 
 type Deps struct {
-	UserService         services.UserService
+	UserService services.UserService
+	// IdentityService is nil when USER_SERVICE_GRPC_URL is not set
+	IdentityService     services.IdentityService
 	ContentService      services.ContentService
 	LessonService       services.LessonService
 	QuizAttemptService  services.QuizAttemptService
@@ -18,17 +33,49 @@ type Deps struct {
 	OrderService        services.OrderService
 	PaymentService      services.PaymentService
 	CouponService       services.CouponService
-	SessionCache        *cache.SessionCache
+	SearchService       services.SearchService
+	// RecommendationService is optional: the dashboard is served without recommendations
+	RecommendationService services.RecommendationService
+	GeoIPService          services.GeoIPService
+	SessionCache          *cache.SessionCache
+	ProfileCache          *cache.ProfileCache
+	StreakCache           *cache.StreakCacheService
+	FeedCache             *cache.FeedCache
+	GraphQLAllowlist      *graphql.Allowlist
+	AuditRecorder         audit.Recorder
+	AuditReader           audit.Reader
+	FeatureFlags          *flags.Client
+	FeatureFlagStore      flags.Store
+	APIKeys               *apikeys.Store
+	// RateLimiter limits anonymous routes per client IP, nil to leave them unlimited
+	RateLimiter        *ratelimit.Limiter
+	Maintenance        *maintenance.Store
+	Consent            *consent.Store
+	GuestCache         *cache.GuestCache
+	GuestSampleLessons int
+	// Chaos injects faults outside production, nil when CHAOS_ENABLED is off
+	Chaos *chaos.Injector
+	// Health serves /livez, /readyz and /healthz
+	Health *health.Checker
 }
 
 func NewRouter(deps Deps) *gin.Engine {
 	r := gin.New()
 
+	// Register custom binding rules before any route binds parameters
+	if err := validation.Register(); err != nil {
+		slog.Warn("custom validators not registered", "error", err)
+	}
+
 	// Setup global middlewares
-	setupGlobalMiddlewares(r)
+	setupGlobalMiddlewares(r, deps)
 
-	// Setup health check
+	// Setup health checks
 	r.GET("/health", controllers.Health)
+	r.GET(health.LivePath, gin.WrapH(deps.Health.Live()))
+	r.GET(health.ReadyPath, gin.WrapH(deps.Health.Ready()))
+	r.GET(health.HealthPath, gin.WrapH(deps.Health.Health()))
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
 
 	// Initialize controllers
 	ctrl := initControllers(deps)
@@ -58,14 +105,26 @@ func setupAPIRoutes(r *gin.Engine, controllers *controllers.Controllers, deps De
 	routes.SetupPasswordRoutes(api, controllers, deps.SessionCache)
 	routes.SetupMFARoutes(api, controllers, deps.SessionCache)
 	routes.SetupSessionRoutes(api, controllers, deps.SessionCache)
-	routes.SetupContentRoutes(api, controllers, deps.SessionCache)
+	routes.SetupContentRoutes(api, controllers, deps.SessionCache, deps.UserService)
 	routes.SetupLessonRoutes(api, controllers, deps.SessionCache)
 	routes.SetupQuizAttemptRoutes(api, controllers, deps.SessionCache)
 	routes.SetupUserRoutes(api, controllers, deps.SessionCache, deps.UserService)
+	routes.SetupOrganizationRoutes(api, controllers, deps.SessionCache)
+	routes.SetupInvitationRoutes(api, controllers, deps.SessionCache)
+	routes.SetupAccessTokenRoutes(api, controllers, deps.SessionCache)
+	routes.SetupPushTokenRoutes(api, controllers, deps.SessionCache)
+	routes.SetupConsentRoutes(api, controllers, deps.SessionCache)
+	routes.SetupAccountMergeRoutes(api, controllers, deps.SessionCache)
 	routes.SetupNotificationRoutes(api, controllers, deps.SessionCache)
 	routes.SetupActivitySessionRoutes(api, controllers, deps.SessionCache)
 	routes.SetupDashboardRoutes(api, controllers, deps.SessionCache)
 	routes.SetupOrderRoutes(api, controllers, deps.SessionCache)
 	routes.SetupPaymentRoutes(api, controllers, deps.SessionCache)
 	routes.SetupCouponRoutes(api, controllers, deps.SessionCache)
+	routes.SetupFeatureFlagRoutes(api, controllers, deps.SessionCache)
+	routes.SetupSearchRoutes(api, controllers)
+	routes.SetupStatusRoutes(api, controllers)
+	routes.SetupAdminRoutes(api, controllers, deps.SessionCache, deps.UserService, deps.AuditRecorder)
+	routes.SetupPartnerRoutes(api, controllers, deps.APIKeys, deps.AuditRecorder)
+	routes.SetupGuestRoutes(api, controllers, deps.SessionCache, deps.GuestCache, deps.RateLimiter)
 }
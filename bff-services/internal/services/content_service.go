@@ -8,7 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"mime/multipart"
 	"net/http"
 	"regexp"
@@ -17,13 +17,15 @@ import (
 	"time"
 
 	"bff-services/internal/api/dto"
+	"bff-services/internal/i18n"
+	"bff-services/internal/tracing"
 	"bff-services/internal/types"
 	"github.com/redis/go-redis/v9"
 )
 
 // ContentService defines the contract for interacting with the content service GraphQL API.
 type ContentService interface {
-	ExecuteGraphQL(ctx context.Context, token string, payload dto.GraphQLRequest) (*types.HTTPResponse, error)
+	ExecuteGraphQL(ctx context.Context, token string, payload dto.GraphQLRequest, headers http.Header) (*types.HTTPResponse, error)
 	UploadMediaBatch(ctx context.Context, token string, opts MediaBatchUploadOptions) (*types.HTTPResponse, error)
 }
 
@@ -91,7 +93,7 @@ func (c *ContentServiceClient) SetRedisClient(redisClient *redis.Client) {
 }
 
 // ExecuteGraphQL forwards raw GraphQL operations to the content service.
-func (c *ContentServiceClient) ExecuteGraphQL(ctx context.Context, token string, payload dto.GraphQLRequest) (*types.HTTPResponse, error) {
+func (c *ContentServiceClient) ExecuteGraphQL(ctx context.Context, token string, payload dto.GraphQLRequest, headers http.Header) (*types.HTTPResponse, error) {
 	query := strings.TrimSpace(payload.Query)
 	if query == "" {
 		return nil, fmt.Errorf("graphql query is required")
@@ -111,7 +113,7 @@ func (c *ContentServiceClient) ExecuteGraphQL(ctx context.Context, token string,
 		if _, isCacheable := gqlCacheOps[opName]; isCacheable {
 			cacheKey := generateCacheKey(opName, request.Variables)
 			if cached, err := c.redisClient.Get(ctx, cacheKey).Result(); err == nil {
-				log.Printf("Cache hit for operation: %s", opName)
+				slog.DebugContext(ctx, "GraphQL cache hit", "operation", opName)
 				return &types.HTTPResponse{
 					StatusCode: http.StatusOK,
 					Body:       []byte(cached),
@@ -121,10 +123,10 @@ func (c *ContentServiceClient) ExecuteGraphQL(ctx context.Context, token string,
 		}
 	}
 
-	return c.sendGraphQLRequest(ctx, request, token)
+	return c.sendGraphQLRequest(ctx, request, token, headers)
 }
 
-func (c *ContentServiceClient) sendGraphQLRequest(ctx context.Context, payload graphQLRequest, token string) (*types.HTTPResponse, error) {
+func (c *ContentServiceClient) sendGraphQLRequest(ctx context.Context, payload graphQLRequest, token string, headers http.Header) (*types.HTTPResponse, error) {
 	if c.baseURL == "" {
 		return nil, fmt.Errorf("content service base URL is not configured")
 	}
@@ -140,7 +142,17 @@ func (c *ContentServiceClient) sendGraphQLRequest(ctx context.Context, payload g
 		return nil, fmt.Errorf("create graphql request: %w", err)
 	}
 
+	for key, values := range headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", i18n.LocaleFromContext(ctx))
+	req.Header.Set("X-User-Timezone", i18n.TimezoneFromContext(ctx))
+	if requestID := tracing.RequestIDFromContext(ctx); requestID != "" {
+		req.Header.Set("X-Request-ID", requestID)
+	}
 	if token != "" {
 		req.Header.Set("Authorization", "Bearer "+token)
 	}
@@ -170,7 +182,7 @@ func (c *ContentServiceClient) sendGraphQLRequest(ctx context.Context, payload g
 			// Cache asynchronously to avoid blocking response
 			go func() {
 				c.redisClient.Set(context.Background(), cacheKey, string(respBody), ttl)
-				log.Printf("Cached response for operation: %s with TTL: %v", opName, ttl)
+				slog.DebugContext(ctx, "cached GraphQL response", "operation", opName, "ttl", ttl)
 			}()
 		}
 	}
@@ -258,6 +270,11 @@ func (c *ContentServiceClient) UploadMediaBatch(ctx context.Context, token strin
 	}
 
 	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept-Language", i18n.LocaleFromContext(ctx))
+	req.Header.Set("X-User-Timezone", i18n.TimezoneFromContext(ctx))
+	if requestID := tracing.RequestIDFromContext(ctx); requestID != "" {
+		req.Header.Set("X-Request-ID", requestID)
+	}
 	if token != "" {
 		req.Header.Set("Authorization", "Bearer "+token)
 	}
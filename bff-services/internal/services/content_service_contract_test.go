@@ -0,0 +1,142 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"bff-services/internal/api/dto"
+	"bff-services/internal/types"
+)
+
+func TestContentServiceContract(t *testing.T) {
+	stub := newStubService(t)
+	client := NewContentServiceClient(stub.URL()+"/", nil)
+
+	cases := []contractCase{
+		{
+			name: "ExecuteGraphQL",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.ExecuteGraphQL(ctx, "jwt-token", dto.GraphQLRequest{
+					Query:         "  query GetTopics { topics { id } }  ",
+					OperationName: "GetTopics",
+					Variables:     map[string]interface{}{"first": 10},
+				}, http.Header{"X-Client-Version": {"web-1.2.0"}})
+			},
+			method: http.MethodPost,
+			path:   "/graphql",
+			headers: map[string]string{
+				"Authorization":    "Bearer jwt-token",
+				"Content-Type":     "application/json",
+				"X-Client-Version": "web-1.2.0",
+			},
+			bodyContains: []string{
+				`"query":"query GetTopics { topics { id } }"`,
+				`"operationName":"GetTopics"`,
+				`"variables":{"first":10}`,
+			},
+		},
+		{
+			name: "ExecuteGraphQLAnonymous",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.ExecuteGraphQL(ctx, "", dto.GraphQLRequest{Query: "{ levels { id } }"}, nil)
+			},
+			method:       http.MethodPost,
+			path:         "/graphql",
+			bodyContains: []string{`"query":"{ levels { id } }"`},
+		},
+		{
+			name: "UploadMediaBatch",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.UploadMediaBatch(ctx, "jwt-token", MediaBatchUploadOptions{
+					Files:      []*multipart.FileHeader{newTestFileHeader(t, "cover.png", "image/png", "png-bytes")},
+					Kind:       "image",
+					UploadedBy: stubUserID,
+					FolderID:   "folder-1",
+				})
+			},
+			method:       http.MethodPost,
+			path:         "/graphql",
+			headers:      map[string]string{"Authorization": "Bearer jwt-token"},
+			bodyContains: []string{`"kind":"IMAGE"`, `"folderId":"folder-1"`, `"filename":"cover.png"`, "png-bytes"},
+		},
+	}
+
+	runContractCases(t, stub, cases)
+
+	stub.reset()
+	if _, err := client.ExecuteGraphQL(contractContext(), "", dto.GraphQLRequest{}, nil); err == nil {
+		t.Error("ExecuteGraphQL: expected error for empty query")
+	}
+	if _, err := client.UploadMediaBatch(contractContext(), "", MediaBatchUploadOptions{}); err == nil {
+		t.Error("UploadMediaBatch: expected error when no files are given")
+	}
+	if len(stub.requests) != 0 {
+		t.Errorf("expected no downstream requests, got %d", len(stub.requests))
+	}
+}
+
+func TestContentServiceUploadIsGraphQLMultipart(t *testing.T) {
+	stub := newStubService(t)
+	client := NewContentServiceClient(stub.URL(), nil)
+
+	_, err := client.UploadMediaBatch(contractContext(), "", MediaBatchUploadOptions{
+		Files: []*multipart.FileHeader{
+			newTestFileHeader(t, "a.png", "image/png", "a"),
+			newTestFileHeader(t, "b.bin", "", "b"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req := stub.lastRequest(t)
+	mediaType, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		t.Fatalf("expected multipart/form-data, got %q", req.Header.Get("Content-Type"))
+	}
+
+	reader := multipart.NewReader(strings.NewReader(req.Body), params["boundary"])
+	form, err := reader.ReadForm(1 << 20)
+	if err != nil {
+		t.Fatalf("read form: %v", err)
+	}
+	if got := form.Value["map"]; len(got) != 1 || got[0] != `{"0":["variables.inputs.0.file"],"1":["variables.inputs.1.file"]}` {
+		t.Errorf("unexpected upload map: %v", got)
+	}
+	if ops := form.Value["operations"]; len(ops) != 1 || !strings.Contains(ops[0], `"mimeType":"application/octet-stream"`) {
+		t.Errorf("operations should default the mime type: %v", ops)
+	}
+	if len(form.File["0"]) != 1 || len(form.File["1"]) != 1 {
+		t.Errorf("expected file parts 0 and 1, got %v", form.File)
+	}
+}
+
+// newTestFileHeader builds a multipart.FileHeader the way gin hands uploads to controllers.
+func newTestFileHeader(t *testing.T, filename, contentType, content string) *multipart.FileHeader {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	header := make(map[string][]string)
+	header["Content-Disposition"] = []string{`form-data; name="file"; filename="` + filename + `"`}
+	if contentType != "" {
+		header["Content-Type"] = []string{contentType}
+	}
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		t.Fatalf("create part: %v", err)
+	}
+	_, _ = part.Write([]byte(content))
+	_ = writer.Close()
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatalf("read form: %v", err)
+	}
+	return form.File["file"][0]
+}
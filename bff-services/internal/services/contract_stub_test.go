@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"bff-services/internal/i18n"
+	"bff-services/internal/tracing"
+	"bff-services/internal/types"
+)
+
+// Identity forwarded by the BFF in every contract case that acts on behalf of a user.
+const (
+	stubUserID    = "6f1c2a4e-0000-4000-8000-000000000001"
+	stubEmail     = "learner@example.com"
+	stubSessionID = "sess-123"
+	stubRequestID = "req-contract-1"
+)
+
+// recordedRequest is what a stub service saw for one BFF call.
+type recordedRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Header http.Header
+	Body   string
+}
+
+// stubResponse is the canned reply a stub service sends back.
+type stubResponse struct {
+	Status int
+	Header http.Header
+	Body   string
+}
+
+// stubService is an httptest server standing in for a downstream service. It records
+// every request and answers with the configured response.
+type stubService struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	requests []recordedRequest
+	response stubResponse
+}
+
+func newStubService(t *testing.T) *stubService {
+	t.Helper()
+	stub := &stubService{response: stubResponse{Status: http.StatusOK, Body: `{"status":"success","data":{}}`}}
+	stub.server = httptest.NewServer(http.HandlerFunc(stub.handle))
+	t.Cleanup(stub.server.Close)
+	return stub
+}
+
+func (s *stubService) handle(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, recordedRequest{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.RawQuery,
+		Header: r.Header.Clone(),
+		Body:   string(body),
+	})
+	resp := s.response
+	s.mu.Unlock()
+
+	for key, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.WriteHeader(resp.Status)
+	_, _ = io.WriteString(w, resp.Body)
+}
+
+// respond sets the response for subsequent requests.
+func (s *stubService) respond(resp stubResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.response = resp
+}
+
+// reset clears recorded requests and restores the default success response.
+func (s *stubService) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+	s.response = stubResponse{Status: http.StatusOK, Body: `{"status":"success","data":{}}`}
+}
+
+// lastRequest returns the single request recorded since the last reset.
+func (s *stubService) lastRequest(t *testing.T) recordedRequest {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.requests) != 1 {
+		t.Fatalf("expected exactly 1 downstream request, got %d", len(s.requests))
+	}
+	return s.requests[0]
+}
+
+func (s *stubService) URL() string {
+	return s.server.URL
+}
+
+// contractContext carries the locale, timezone and request ID the BFF forwards downstream.
+func contractContext() context.Context {
+	ctx := i18n.WithLocale(context.Background(), "vi")
+	ctx = i18n.WithTimezone(ctx, "Asia/Ho_Chi_Minh")
+	return tracing.WithRequestID(ctx, stubRequestID)
+}
+
+// contractCase describes the request one client method must send.
+type contractCase struct {
+	name   string
+	call   func(ctx context.Context) (*types.HTTPResponse, error)
+	method string
+	path   string
+	query  string
+	// header values that must be present on the downstream request.
+	headers map[string]string
+	// body fragments that must appear in the JSON payload.
+	bodyContains []string
+	// authenticated cases must forward the internal identity headers.
+	authenticated bool
+}
+
+// documentedErrors are the error bodies downstream services are documented to return.
+// Client methods must hand every one of them back untouched instead of failing.
+var documentedErrors = []stubResponse{
+	{Status: http.StatusBadRequest, Body: `{"status":"error","message":"invalid input","error_code":"validation-failed","details":{"email":"required"}}`},
+	{Status: http.StatusNotFound, Body: `{"success":false,"error":{"code":"ORDER_NOT_FOUND","message":"order not found"}}`},
+	{Status: http.StatusUnprocessableEntity, Body: `{"detail":[{"loc":["body","limit"],"msg":"value is not a valid integer"}]}`},
+	{Status: http.StatusConflict, Body: `{"error":"duplicate enrollment","details":"already enrolled"}`},
+	{Status: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"30"}}, Body: `{"status":"error","message":"too many requests"}`},
+	{Status: http.StatusServiceUnavailable, Header: http.Header{"Content-Type": {"text/plain"}}, Body: "upstream connect error"},
+}
+
+// runContractCases asserts each case sends the expected request and tolerates every
+// documented error shape.
+func runContractCases(t *testing.T, stub *stubService, cases []contractCase) {
+	t.Helper()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			stub.reset()
+			ctx := contractContext()
+
+			resp, err := tc.call(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("expected status 200, got %d", resp.StatusCode)
+			}
+
+			req := stub.lastRequest(t)
+			if req.Method != tc.method {
+				t.Errorf("method: want %s, got %s", tc.method, req.Method)
+			}
+			if req.Path != tc.path {
+				t.Errorf("path: want %s, got %s", tc.path, req.Path)
+			}
+			if req.Query != tc.query {
+				t.Errorf("query: want %q, got %q", tc.query, req.Query)
+			}
+
+			assertForwardedHeaders(t, req.Header)
+			if tc.authenticated {
+				assertHeader(t, req.Header, "X-User-ID", stubUserID)
+				assertHeader(t, req.Header, "X-User-Email", stubEmail)
+				assertHeader(t, req.Header, "X-Session-ID", stubSessionID)
+			} else if req.Header.Get("X-User-ID") != "" {
+				t.Errorf("unauthenticated call leaked X-User-ID %q", req.Header.Get("X-User-ID"))
+			}
+			for key, value := range tc.headers {
+				assertHeader(t, req.Header, key, value)
+			}
+			for _, fragment := range tc.bodyContains {
+				if !strings.Contains(req.Body, fragment) {
+					t.Errorf("body %s does not contain %s", req.Body, fragment)
+				}
+			}
+
+			for _, documented := range documentedErrors {
+				stub.respond(documented)
+				resp, err := tc.call(ctx)
+				if err != nil {
+					t.Fatalf("status %d: unexpected error: %v", documented.Status, err)
+				}
+				if resp.StatusCode != documented.Status {
+					t.Errorf("status: want %d, got %d", documented.Status, resp.StatusCode)
+				}
+				if string(resp.Body) != documented.Body {
+					t.Errorf("status %d: body was altered: %s", documented.Status, resp.Body)
+				}
+				if retryAfter := documented.Header.Get("Retry-After"); retryAfter != "" && resp.Headers.Get("Retry-After") != retryAfter {
+					t.Errorf("status %d: Retry-After header not preserved", documented.Status)
+				}
+			}
+		})
+	}
+}
+
+// assertForwardedHeaders checks the headers every downstream call carries.
+func assertForwardedHeaders(t *testing.T, header http.Header) {
+	t.Helper()
+	assertHeader(t, header, "Accept-Language", "vi")
+	assertHeader(t, header, "X-User-Timezone", "Asia/Ho_Chi_Minh")
+	assertHeader(t, header, "X-Request-ID", stubRequestID)
+}
+
+func assertHeader(t *testing.T, header http.Header, key, want string) {
+	t.Helper()
+	if got := header.Get(key); got != want {
+		t.Errorf("header %s: want %q, got %q", key, want, got)
+	}
+}
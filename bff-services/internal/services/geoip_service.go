@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// GeoLocation is the approximate location resolved for an IP address.
+type GeoLocation struct {
+	City        string `json:"city,omitempty"`
+	Region      string `json:"region,omitempty"`
+	Country     string `json:"country,omitempty"`
+	CountryCode string `json:"country_code,omitempty"`
+}
+
+// GeoIPService resolves IP addresses to approximate locations.
+type GeoIPService interface {
+	Lookup(ctx context.Context, ip string) (*GeoLocation, error)
+}
+
+// GeoIPClient resolves locations through an ip-api compatible HTTP endpoint
+// (GET {baseURL}/{ip} returning city/regionName/country/countryCode).
+type GeoIPClient struct {
+	baseURL     string
+	httpClient  *http.Client
+	redisClient *redis.Client
+	cacheTTL    time.Duration
+}
+
+// NewGeoIPClient constructs a new GeoIPClient. An empty baseURL disables lookups.
+func NewGeoIPClient(baseURL string, httpClient *http.Client) *GeoIPClient {
+	trimmed := strings.TrimRight(baseURL, "/")
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 2 * time.Second}
+	}
+	return &GeoIPClient{
+		baseURL:    trimmed,
+		httpClient: httpClient,
+		cacheTTL:   24 * time.Hour,
+	}
+}
+
+// SetRedisClient sets the Redis client used to cache lookups
+func (c *GeoIPClient) SetRedisClient(redisClient *redis.Client) {
+	c.redisClient = redisClient
+}
+
+// Lookup returns the location of ip. Private and loopback addresses resolve to a
+// "Local network" placeholder without calling the provider.
+func (c *GeoIPClient) Lookup(ctx context.Context, ip string) (*GeoLocation, error) {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return nil, fmt.Errorf("invalid ip address %q", ip)
+	}
+	if parsed.IsLoopback() || parsed.IsPrivate() || parsed.IsLinkLocalUnicast() {
+		return &GeoLocation{Country: "Local network"}, nil
+	}
+	if c.baseURL == "" {
+		return nil, nil
+	}
+
+	cacheKey := "geoip:" + parsed.String()
+	if c.redisClient != nil {
+		if cached, err := c.redisClient.Get(ctx, cacheKey).Result(); err == nil {
+			var location GeoLocation
+			if err := json.Unmarshal([]byte(cached), &location); err == nil {
+				return &location, nil
+			}
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/"+url.PathEscape(parsed.String()), nil)
+	if err != nil {
+		return nil, fmt.Errorf("create geoip request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("perform geoip request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read geoip response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("geoip provider returned status %d", resp.StatusCode)
+	}
+
+	var payload struct {
+		Status      string `json:"status"`
+		City        string `json:"city"`
+		RegionName  string `json:"regionName"`
+		Country     string `json:"country"`
+		CountryCode string `json:"countryCode"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("decode geoip response: %w", err)
+	}
+	if payload.Status != "" && payload.Status != "success" {
+		return nil, nil
+	}
+
+	location := &GeoLocation{
+		City:        payload.City,
+		Region:      payload.RegionName,
+		Country:     payload.Country,
+		CountryCode: payload.CountryCode,
+	}
+
+	if c.redisClient != nil {
+		if data, err := json.Marshal(location); err == nil {
+			if err := c.redisClient.Set(ctx, cacheKey, string(data), c.cacheTTL).Err(); err != nil {
+				slog.WarnContext(ctx, "failed to cache geoip lookup", "error", err)
+			}
+		}
+	}
+
+	return location, nil
+}
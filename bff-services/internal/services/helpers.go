@@ -8,11 +8,29 @@ import (
 	"io"
 	"net/http"
 
+	"bff-services/internal/i18n"
+	"bff-services/internal/tracing"
 	"bff-services/internal/types"
 )
 
 // doRequest performs HTTP requests for service clients
 func doRequest(ctx context.Context, baseURL, method, path string, httpClient *http.Client, payload interface{}, headers http.Header) (*types.HTTPResponse, error) {
+	var body []byte
+	contentType := ""
+	if payload != nil {
+		var err error
+		body, err = json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("marshal payload: %w", err)
+		}
+		contentType = "application/json"
+	}
+	return doRawRequest(ctx, baseURL, method, path, httpClient, contentType, body, headers)
+}
+
+// doRawRequest performs an HTTP request whose body is sent as is with contentType, for
+// payloads that are not JSON.
+func doRawRequest(ctx context.Context, baseURL, method, path string, httpClient *http.Client, contentType string, body []byte, headers http.Header) (*types.HTTPResponse, error) {
 	if baseURL == "" {
 		return nil, fmt.Errorf("service base URL is not configured")
 	}
@@ -20,11 +38,7 @@ func doRequest(ctx context.Context, baseURL, method, path string, httpClient *ht
 	endpoint := baseURL + path
 
 	var bodyReader io.Reader
-	if payload != nil {
-		body, err := json.Marshal(payload)
-		if err != nil {
-			return nil, fmt.Errorf("marshal payload: %w", err)
-		}
+	if contentType != "" {
 		bodyReader = bytes.NewReader(body)
 	}
 
@@ -32,8 +46,13 @@ func doRequest(ctx context.Context, baseURL, method, path string, httpClient *ht
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
-	if payload != nil {
-		req.Header.Set("Content-Type", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept-Language", i18n.LocaleFromContext(ctx))
+	req.Header.Set("X-User-Timezone", i18n.TimezoneFromContext(ctx))
+	if requestID := tracing.RequestIDFromContext(ctx); requestID != "" {
+		req.Header.Set("X-Request-ID", requestID)
 	}
 
 	for key, values := range headers {
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"bff-services/internal/grpc/identityv1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/ductan2/microservice-app/shared/internalauth"
+)
+
+// identityTimeout bounds each call to the identity API.
+const identityTimeout = 2 * time.Second
+
+// IdentityService resolves users and sessions through the user-services gRPC API.
+type IdentityService interface {
+	GetUserByID(ctx context.Context, userID string) (*identityv1.User, error)
+	BatchGetUsers(ctx context.Context, userIDs []string) (*identityv1.BatchGetUsersResponse, error)
+	ValidateSession(ctx context.Context, sessionID, userID string) (*identityv1.ValidateSessionResponse, error)
+}
+
+// IsIdentityNotFound reports whether err is a NOT_FOUND status.
+func IsIdentityNotFound(err error) bool {
+	return status.Code(err) == codes.NotFound
+}
+
+// IdentityClient calls identity.v1.UserIdentityService with the generated client.
+type IdentityClient struct {
+	conn   *grpc.ClientConn
+	client identityv1.UserIdentityServiceClient
+}
+
+// NewIdentityClient constructs a new IdentityClient for the gRPC server at address, such
+// as http://user-services:9001 or user-services:9001. Every call carries a service token
+// from signer; opts add interceptors and stats handlers. The connection is only made on
+// the first call.
+func NewIdentityClient(address string, signer *internalauth.Signer, opts ...grpc.DialOption) (*IdentityClient, error) {
+	target := address
+	if u, err := url.Parse(address); err == nil && u.Host != "" {
+		target = u.Host
+	}
+
+	opts = append([]grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(serviceToken{signer: signer, audience: "user-services"}),
+	}, opts...)
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create identity client for %s: %w", address, err)
+	}
+	return &IdentityClient{
+		conn:   conn,
+		client: identityv1.NewUserIdentityServiceClient(conn),
+	}, nil
+}
+
+// Close closes the connection to user-services.
+func (c *IdentityClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *IdentityClient) GetUserByID(ctx context.Context, userID string) (*identityv1.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, identityTimeout)
+	defer cancel()
+
+	resp, err := c.client.GetUserByID(ctx, &identityv1.GetUserByIDRequest{UserId: userID})
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetUser(), nil
+}
+
+func (c *IdentityClient) BatchGetUsers(ctx context.Context, userIDs []string) (*identityv1.BatchGetUsersResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, identityTimeout)
+	defer cancel()
+
+	return c.client.BatchGetUsers(ctx, &identityv1.BatchGetUsersRequest{UserIds: userIDs})
+}
+
+func (c *IdentityClient) ValidateSession(ctx context.Context, sessionID, userID string) (*identityv1.ValidateSessionResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, identityTimeout)
+	defer cancel()
+
+	return c.client.ValidateSession(ctx, &identityv1.ValidateSessionRequest{SessionId: sessionID, UserId: userID})
+}
+
+// serviceToken sends a service token issued for audience in the metadata of every
+// call, as internalauth.Transport does in the headers of REST calls.
+type serviceToken struct {
+	signer   *internalauth.Signer
+	audience string
+}
+
+func (t serviceToken) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	token, err := t.signer.Sign(t.audience, nil)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{strings.ToLower(internalauth.Header): token}, nil
+}
+
+// RequireTransportSecurity allows the token over the cleartext connections between
+// services, as for REST calls.
+func (t serviceToken) RequireTransportSecurity() bool {
+	return false
+}
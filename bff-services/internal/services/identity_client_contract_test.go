@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"bff-services/internal/grpc/identityv1"
+
+	"github.com/ductan2/microservice-app/shared/internalauth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// grpcStub is a gRPC server answering the identity API like user-services.
+type grpcStub struct {
+	identityv1.UnimplementedUserIdentityServiceServer
+	addr string
+
+	method   string
+	metadata metadata.MD
+	deadline bool
+	request  proto.Message
+	err      error
+	response proto.Message
+}
+
+func newGRPCStub(t *testing.T) *grpcStub {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub := &grpcStub{addr: lis.Addr().String()}
+	server := grpc.NewServer(grpc.UnaryInterceptor(stub.record))
+	identityv1.RegisterUserIdentityServiceServer(server, stub)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return stub
+}
+
+func (s *grpcStub) record(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	s.method = info.FullMethod
+	s.metadata, _ = metadata.FromIncomingContext(ctx)
+	_, s.deadline = ctx.Deadline()
+	s.request = req.(proto.Message)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.response, nil
+}
+
+// testAuthConfig holds the keys the test identity client signs its calls with.
+func testAuthConfig(service string) internalauth.Config {
+	return internalauth.Config{Service: service, Keys: map[string][]byte{"test": []byte("identity-client-test-key-32-bytes")}, KeyID: "test"}
+}
+
+func newTestIdentityClient(t *testing.T, stub *grpcStub) *IdentityClient {
+	t.Helper()
+	signer, err := internalauth.NewSigner(testAuthConfig("bff-services"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewIdentityClient("http://"+stub.addr, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestIdentityClientContract(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("BatchGetUsers", func(t *testing.T) {
+		stub := newGRPCStub(t)
+		stub.response = &identityv1.BatchGetUsersResponse{
+			Users:          []*identityv1.User{{Id: stubUserID, DisplayName: "Ann"}},
+			MissingUserIds: []string{"missing"},
+		}
+
+		resp, err := newTestIdentityClient(t, stub).BatchGetUsers(ctx, []string{stubUserID, "missing"})
+		if err != nil {
+			t.Fatalf("BatchGetUsers failed: %v", err)
+		}
+
+		if stub.method != identityv1.UserIdentityService_BatchGetUsers_FullMethodName {
+			t.Fatalf("unexpected method %s", stub.method)
+		}
+		header := http.Header{}
+		for key, values := range stub.metadata {
+			header[http.CanonicalHeaderKey(key)] = values
+		}
+		verifier, _ := internalauth.NewVerifier(testAuthConfig("user-services"))
+		if claims, err := verifier.Verify(header.Get(internalauth.Header), header); err != nil || claims.Issuer != "bff-services" {
+			t.Errorf("service token not accepted by user-services: %v", err)
+		}
+		if !stub.deadline {
+			t.Errorf("call sent without a deadline")
+		}
+
+		sent := stub.request.(*identityv1.BatchGetUsersRequest)
+		if len(sent.GetUserIds()) != 2 {
+			t.Fatalf("unexpected request message %v", sent)
+		}
+		if len(resp.GetUsers()) != 1 || resp.GetUsers()[0].GetDisplayName() != "Ann" || len(resp.GetMissingUserIds()) != 1 {
+			t.Fatalf("unexpected response %v", resp)
+		}
+	})
+
+	t.Run("ValidateSession", func(t *testing.T) {
+		stub := newGRPCStub(t)
+		stub.response = &identityv1.ValidateSessionResponse{
+			Valid:  true,
+			Status: identityv1.SessionStatus_SESSION_STATUS_ACTIVE,
+			User:   &identityv1.User{Id: stubUserID},
+		}
+
+		resp, err := newTestIdentityClient(t, stub).ValidateSession(ctx, stubSessionID, stubUserID)
+		if err != nil {
+			t.Fatalf("ValidateSession failed: %v", err)
+		}
+		if stub.method != identityv1.UserIdentityService_ValidateSession_FullMethodName {
+			t.Fatalf("unexpected method %s", stub.method)
+		}
+		sent := stub.request.(*identityv1.ValidateSessionRequest)
+		if sent.GetSessionId() != stubSessionID || sent.GetUserId() != stubUserID {
+			t.Fatalf("unexpected request message %v", sent)
+		}
+		if !resp.GetValid() || resp.GetUser().GetId() != stubUserID {
+			t.Fatalf("unexpected response %v", resp)
+		}
+	})
+
+	t.Run("GetUserByID not found", func(t *testing.T) {
+		stub := newGRPCStub(t)
+		stub.err = status.Error(codes.NotFound, "user not found")
+
+		_, err := newTestIdentityClient(t, stub).GetUserByID(ctx, stubUserID)
+		if !IsIdentityNotFound(err) {
+			t.Fatalf("expected a NOT_FOUND status, got %v", err)
+		}
+		if message := status.Convert(err).Message(); message != "user not found" {
+			t.Errorf("message = %q, want the server's", message)
+		}
+	})
+}
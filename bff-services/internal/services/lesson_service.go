@@ -19,7 +19,7 @@ type LessonService interface {
 	GetMyStreak(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error)
 	CheckMyStreak(ctx context.Context, userID, email, sessionID string, payload *dto.StreakCheckRequest) (*types.HTTPResponse, error)
 	GetMyStreakStatus(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error)
-	GetStreakLeaderboard(ctx context.Context, userID, email, sessionID string, limit int) (*types.HTTPResponse, error)
+	GetStreakLeaderboard(ctx context.Context, userID, email, sessionID string, limit, offset int) (*types.HTTPResponse, error)
 	GetUserLessonStats(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error)
 	GetDailyActivityToday(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error)
 	GetDailyActivityByDate(ctx context.Context, userID, email, sessionID, activityDate string) (*types.HTTPResponse, error)
@@ -43,6 +43,9 @@ type LessonService interface {
 	UpdateEnrollment(ctx context.Context, enrollmentID, userID, email, sessionID string, payload dto.CourseEnrollmentUpdate) (*types.HTTPResponse, error)
 	CancelEnrollment(ctx context.Context, enrollmentID, userID, email, sessionID string) (*types.HTTPResponse, error)
 
+	// Spaced repetition
+	GetMyCardStats(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error)
+
 	// Course lessons
 	ListCourseLessonsByCourseID(ctx context.Context, courseID string) (*types.HTTPResponse, error)
 	CreateCourseLesson(ctx context.Context, payload dto.CourseLessonCreate) (*types.HTTPResponse, error)
@@ -100,10 +103,17 @@ func (c *LessonServiceClient) GetMyStreakStatus(ctx context.Context, userID, ema
 	return c.doRequest(ctx, http.MethodGet, "/api/v1/progress/streaks/user/me/status", nil, internalAuthHeaders(userID, email, sessionID))
 }
 
-func (c *LessonServiceClient) GetStreakLeaderboard(ctx context.Context, userID, email, sessionID string, limit int) (*types.HTTPResponse, error) {
+func (c *LessonServiceClient) GetStreakLeaderboard(ctx context.Context, userID, email, sessionID string, limit, offset int) (*types.HTTPResponse, error) {
 	path := "/api/v1/progress/streaks/leaderboard"
+	query := url.Values{}
 	if limit > 0 {
-		path += fmt.Sprintf("?limit=%d", limit)
+		query.Add("limit", fmt.Sprintf("%d", limit))
+	}
+	if offset > 0 {
+		query.Add("offset", fmt.Sprintf("%d", offset))
+	}
+	if len(query) > 0 {
+		path += "?" + query.Encode()
 	}
 	return c.doRequest(ctx, http.MethodGet, path, nil, internalAuthHeaders(userID, email, sessionID))
 }
@@ -304,6 +314,12 @@ func (c *LessonServiceClient) CancelEnrollment(ctx context.Context, enrollmentID
 	return c.doRequest(ctx, http.MethodPost, path, nil, internalAuthHeaders(userID, email, sessionID))
 }
 
+// Spaced repetition
+// GetMyCardStats returns the counts of the user's flashcard reviews, due ones included.
+func (c *LessonServiceClient) GetMyCardStats(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodGet, "/api/v1/api/spaced-repetition/cards/user/me/stats", nil, internalAuthHeaders(userID, email, sessionID))
+}
+
 // Course lessons (no auth required on service side)
 func (c *LessonServiceClient) ListCourseLessonsByCourseID(ctx context.Context, courseID string) (*types.HTTPResponse, error) {
 	path := "/api/course-lessons/by-course/" + courseID
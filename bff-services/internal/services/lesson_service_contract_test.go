@@ -0,0 +1,327 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"bff-services/internal/api/dto"
+	"bff-services/internal/types"
+)
+
+func TestLessonServiceContract(t *testing.T) {
+	stub := newStubService(t)
+	client := NewLessonServiceClient(stub.URL()+"/", nil)
+
+	activityDate := "2026-03-04"
+	status := "in_progress"
+	ord := 3
+	limit := 25
+	const courseID = "0b8f4f3e-5b8a-4d2c-9a51-3f0e6f1b2c01"
+
+	cases := []contractCase{
+		{
+			name: "GetUserPoints",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetUserPoints(ctx, "user-2")
+			},
+			method: http.MethodGet,
+			path:   "/api/v1/progress/points/user/user-2",
+		},
+		{
+			name: "GetUserStreak",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetUserStreak(ctx, "user-2")
+			},
+			method: http.MethodGet,
+			path:   "/api/v1/progress/streaks/user/user-2",
+		},
+		{
+			name: "GetMyStreak",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetMyStreak(ctx, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/progress/streaks/user/me",
+			authenticated: true,
+		},
+		{
+			name: "CheckMyStreak",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.CheckMyStreak(ctx, stubUserID, stubEmail, stubSessionID, &dto.StreakCheckRequest{ActivityDate: &activityDate})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/progress/streaks/user/me/check",
+			bodyContains:  []string{`"activity_date":"2026-03-04"`},
+			authenticated: true,
+		},
+		{
+			name: "GetMyStreakStatus",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetMyStreakStatus(ctx, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/progress/streaks/user/me/status",
+			authenticated: true,
+		},
+		{
+			name: "GetStreakLeaderboard",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetStreakLeaderboard(ctx, stubUserID, stubEmail, stubSessionID, 10, 20)
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/progress/streaks/leaderboard",
+			query:         "limit=10&offset=20",
+			authenticated: true,
+		},
+		{
+			name: "GetUserLessonStats",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetUserLessonStats(ctx, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/api/user-lessons/stats",
+			authenticated: true,
+		},
+		{
+			name: "GetDailyActivityToday",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetDailyActivityToday(ctx, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/progress/daily-activity/user/me/today",
+			authenticated: true,
+		},
+		{
+			name: "GetDailyActivityByDate",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetDailyActivityByDate(ctx, stubUserID, stubEmail, stubSessionID, activityDate)
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/progress/daily-activity/user/me/date/2026-03-04",
+			authenticated: true,
+		},
+		{
+			name: "GetDailyActivityRange",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetDailyActivityRange(ctx, stubUserID, stubEmail, stubSessionID, "2026-03-01", "2026-03-07")
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/progress/daily-activity/user/me/range",
+			query:         "date_from=2026-03-01&date_to=2026-03-07",
+			authenticated: true,
+		},
+		{
+			name: "GetDailyActivityWeek",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetDailyActivityWeek(ctx, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/progress/daily-activity/user/me/week",
+			authenticated: true,
+		},
+		{
+			name: "GetDailyActivityMonth",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetDailyActivityMonth(ctx, stubUserID, stubEmail, stubSessionID, "2026", "3")
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/progress/daily-activity/user/me/month",
+			query:         "month=3&year=2026",
+			authenticated: true,
+		},
+		{
+			name: "GetDailyActivitySummary",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetDailyActivitySummary(ctx, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/progress/daily-activity/user/me/stats/summary",
+			authenticated: true,
+		},
+		{
+			name: "IncrementDailyActivity",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.IncrementDailyActivity(ctx, stubUserID, stubEmail, stubSessionID, dto.DailyActivityIncrementRequest{Field: "minutes", Amount: 5})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/progress/daily-activity/increment",
+			bodyContains:  []string{`"field":"minutes"`, `"amount":5`},
+			authenticated: true,
+		},
+		{
+			name: "GetCurrentWeeklyLeaderboard",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetCurrentWeeklyLeaderboard(ctx, 20, 40)
+			},
+			method: http.MethodGet,
+			path:   "/api/v1/leaderboards/weekly/current",
+			query:  "limit=20&offset=40",
+		},
+		{
+			name: "GetCurrentMonthlyLeaderboard",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetCurrentMonthlyLeaderboard(ctx, 20, 0)
+			},
+			method: http.MethodGet,
+			path:   "/api/v1/leaderboards/monthly/current",
+			query:  "limit=20",
+		},
+		{
+			name: "GetWeeklyLeaderboardHistory",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetWeeklyLeaderboardHistory(ctx, 0, 0)
+			},
+			method: http.MethodGet,
+			path:   "/api/v1/leaderboards/weekly/history",
+		},
+		{
+			name: "GetMonthlyLeaderboardHistory",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetMonthlyLeaderboardHistory(ctx, 5, 10)
+			},
+			method: http.MethodGet,
+			path:   "/api/v1/leaderboards/monthly/history",
+			query:  "limit=5&offset=10",
+		},
+		{
+			name: "GetUserLeaderboardHistory",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetUserLeaderboardHistory(ctx, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/leaderboards/user/me/history",
+			authenticated: true,
+		},
+		{
+			name: "GetWeekLeaderboard",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetWeekLeaderboard(ctx, "2026-W10", &limit, 0)
+			},
+			method: http.MethodGet,
+			path:   "/api/v1/leaderboards/week/2026-W10",
+			query:  "limit=25",
+		},
+		{
+			name: "GetMonthLeaderboard",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetMonthLeaderboard(ctx, "2026-03", nil, 50)
+			},
+			method: http.MethodGet,
+			path:   "/api/v1/leaderboards/month/2026-03",
+			query:  "offset=50",
+		},
+		{
+			name: "ListMyEnrollments",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.ListMyEnrollments(ctx, stubUserID, stubEmail, stubSessionID, status, 10, 20)
+			},
+			method:        http.MethodGet,
+			path:          "/api/course-enrollments/me",
+			query:         "limit=10&offset=20&status=in_progress",
+			authenticated: true,
+		},
+		{
+			name: "EnrollCourse",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.EnrollCourse(ctx, stubUserID, stubEmail, stubSessionID, dto.CourseEnrollmentCreate{CourseID: courseID})
+			},
+			method:        http.MethodPost,
+			path:          "/api/course-enrollments",
+			bodyContains:  []string{`"course_id":"` + courseID + `"`},
+			authenticated: true,
+		},
+		{
+			name: "GetEnrollment",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetEnrollment(ctx, "enr-1", stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodGet,
+			path:          "/api/course-enrollments/enr-1",
+			authenticated: true,
+		},
+		{
+			name: "UpdateEnrollment",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.UpdateEnrollment(ctx, "enr-1", stubUserID, stubEmail, stubSessionID, dto.CourseEnrollmentUpdate{Status: &status})
+			},
+			method:        http.MethodPut,
+			path:          "/api/course-enrollments/enr-1",
+			bodyContains:  []string{`"status":"in_progress"`},
+			authenticated: true,
+		},
+		{
+			name: "CancelEnrollment",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.CancelEnrollment(ctx, "enr-1", stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodPost,
+			path:          "/api/course-enrollments/enr-1/cancel",
+			authenticated: true,
+		},
+		{
+			name: "GetMyCardStats",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetMyCardStats(ctx, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/api/spaced-repetition/cards/user/me/stats",
+			authenticated: true,
+		},
+		{
+			name: "ListCourseLessonsByCourseID",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.ListCourseLessonsByCourseID(ctx, courseID)
+			},
+			method: http.MethodGet,
+			path:   "/api/course-lessons/by-course/" + courseID,
+		},
+		{
+			name: "CreateCourseLesson",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.CreateCourseLesson(ctx, dto.CourseLessonCreate{CourseID: courseID, LessonID: courseID, Ord: 1, IsRequired: true})
+			},
+			method:       http.MethodPost,
+			path:         "/api/course-lessons",
+			bodyContains: []string{`"ord":1`, `"is_required":true`},
+		},
+		{
+			name: "UpdateCourseLesson",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.UpdateCourseLesson(ctx, "row-1", dto.CourseLessonUpdate{Ord: &ord})
+			},
+			method:       http.MethodPut,
+			path:         "/api/course-lessons/row-1",
+			bodyContains: []string{`"ord":3`},
+		},
+		{
+			name: "DeleteCourseLesson",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.DeleteCourseLesson(ctx, "row-1")
+			},
+			method: http.MethodDelete,
+			path:   "/api/course-lessons/row-1",
+		},
+	}
+
+	runContractCases(t, stub, cases)
+}
+
+func TestLessonServiceRejectsMissingArguments(t *testing.T) {
+	stub := newStubService(t)
+	client := NewLessonServiceClient(stub.URL(), nil)
+	ctx := contractContext()
+
+	if _, err := client.GetUserPoints(ctx, ""); err == nil {
+		t.Error("GetUserPoints: expected error for empty user id")
+	}
+	if _, err := client.GetDailyActivityByDate(ctx, stubUserID, stubEmail, stubSessionID, ""); err == nil {
+		t.Error("GetDailyActivityByDate: expected error for empty date")
+	}
+	if _, err := client.GetWeekLeaderboard(ctx, "", nil, 0); err == nil {
+		t.Error("GetWeekLeaderboard: expected error for empty week key")
+	}
+	if len(stub.requests) != 0 {
+		t.Errorf("expected no downstream requests, got %d", len(stub.requests))
+	}
+}
@@ -27,8 +27,20 @@ type NotificationService interface {
 	GetUnreadCount(ctx context.Context, userID string) (*types.HTTPResponse, error)
 	DeleteUserNotification(ctx context.Context, userID, notificationID string) (*types.HTTPResponse, error)
 
+	// Notification preferences
+	GetPreferences(ctx context.Context, userID string) (*types.HTTPResponse, error)
+	UpdatePreferences(ctx context.Context, userID string, payload dto.UpdateNotificationPreferencesRequest) (*types.HTTPResponse, error)
+
 	// Bulk operations
 	SendNotificationToUsers(ctx context.Context, templateID string, payload dto.SendNotificationToUsersRequest) (*types.HTTPResponse, error)
+
+	// Message templates: the localized copy of each notification type and channel
+	ListMessageTemplates(ctx context.Context, query dto.MessageTemplateListQuery) (*types.HTTPResponse, error)
+	GetMessageTemplate(ctx context.Context, id string) (*types.HTTPResponse, error)
+	CreateMessageTemplate(ctx context.Context, payload dto.CreateMessageTemplateRequest) (*types.HTTPResponse, error)
+	UpdateMessageTemplate(ctx context.Context, id string, payload dto.UpdateMessageTemplateRequest) (*types.HTTPResponse, error)
+	DeleteMessageTemplate(ctx context.Context, id string) (*types.HTTPResponse, error)
+	PreviewMessageTemplate(ctx context.Context, id string, payload dto.PreviewMessageTemplateRequest) (*types.HTTPResponse, error)
 }
 
 type NotificationServiceClient struct {
@@ -133,6 +145,23 @@ func (c *NotificationServiceClient) DeleteUserNotification(ctx context.Context,
 	return c.doRequest(ctx, http.MethodDelete, path, nil, nil)
 }
 
+// Notification preference methods
+func (c *NotificationServiceClient) GetPreferences(ctx context.Context, userID string) (*types.HTTPResponse, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user id is required")
+	}
+	path := "/api/notifications/users/" + url.PathEscape(userID) + "/preferences"
+	return c.doRequest(ctx, http.MethodGet, path, nil, nil)
+}
+
+func (c *NotificationServiceClient) UpdatePreferences(ctx context.Context, userID string, payload dto.UpdateNotificationPreferencesRequest) (*types.HTTPResponse, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user id is required")
+	}
+	path := "/api/notifications/users/" + url.PathEscape(userID) + "/preferences"
+	return c.doRequest(ctx, http.MethodPut, path, payload, nil)
+}
+
 // Bulk operations
 func (c *NotificationServiceClient) SendNotificationToUsers(ctx context.Context, templateID string, payload dto.SendNotificationToUsersRequest) (*types.HTTPResponse, error) {
 	if templateID == "" {
@@ -142,6 +171,57 @@ func (c *NotificationServiceClient) SendNotificationToUsers(ctx context.Context,
 	return c.doRequest(ctx, http.MethodPost, path, payload, nil)
 }
 
+// Message template methods
+func (c *NotificationServiceClient) ListMessageTemplates(ctx context.Context, query dto.MessageTemplateListQuery) (*types.HTTPResponse, error) {
+	params := url.Values{}
+	if query.Type != "" {
+		params.Set("type", query.Type)
+	}
+	if query.Channel != "" {
+		params.Set("channel", query.Channel)
+	}
+	if query.Locale != "" {
+		params.Set("locale", query.Locale)
+	}
+	path := "/api/v1/message-templates"
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+	return c.doRequest(ctx, http.MethodGet, path, nil, nil)
+}
+
+func (c *NotificationServiceClient) GetMessageTemplate(ctx context.Context, id string) (*types.HTTPResponse, error) {
+	if id == "" {
+		return nil, fmt.Errorf("template id is required")
+	}
+	return c.doRequest(ctx, http.MethodGet, "/api/v1/message-templates/"+url.PathEscape(id), nil, nil)
+}
+
+func (c *NotificationServiceClient) CreateMessageTemplate(ctx context.Context, payload dto.CreateMessageTemplateRequest) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/message-templates", payload, nil)
+}
+
+func (c *NotificationServiceClient) UpdateMessageTemplate(ctx context.Context, id string, payload dto.UpdateMessageTemplateRequest) (*types.HTTPResponse, error) {
+	if id == "" {
+		return nil, fmt.Errorf("template id is required")
+	}
+	return c.doRequest(ctx, http.MethodPut, "/api/v1/message-templates/"+url.PathEscape(id), payload, nil)
+}
+
+func (c *NotificationServiceClient) DeleteMessageTemplate(ctx context.Context, id string) (*types.HTTPResponse, error) {
+	if id == "" {
+		return nil, fmt.Errorf("template id is required")
+	}
+	return c.doRequest(ctx, http.MethodDelete, "/api/v1/message-templates/"+url.PathEscape(id), nil, nil)
+}
+
+func (c *NotificationServiceClient) PreviewMessageTemplate(ctx context.Context, id string, payload dto.PreviewMessageTemplateRequest) (*types.HTTPResponse, error) {
+	if id == "" {
+		return nil, fmt.Errorf("template id is required")
+	}
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/message-templates/"+url.PathEscape(id)+"/preview", payload, nil)
+}
+
 func (c *NotificationServiceClient) doRequest(ctx context.Context, method, path string, payload interface{}, headers http.Header) (*types.HTTPResponse, error) {
 	return doRequest(ctx, c.baseURL, method, path, c.httpClient, payload, headers)
 }
@@ -18,6 +18,15 @@ type OrderService interface {
 	ListOrders(ctx context.Context, token, userID, email, sessionID string, query dto.OrderListQuery) (*types.HTTPResponse, error)
 	GetOrder(ctx context.Context, token, userID, email, sessionID, orderID string) (*types.HTTPResponse, error)
 	CancelOrder(ctx context.Context, token, userID, email, sessionID, orderID string, payload dto.CancelOrderRequest) (*types.HTTPResponse, error)
+	RequestRefund(ctx context.Context, token, userID, email, sessionID, orderID string, payload dto.RefundOrderRequest) (*types.HTTPResponse, error)
+	ListRefunds(ctx context.Context, token, userID, email, sessionID string, query dto.RefundListQuery) (*types.HTTPResponse, error)
+	AdminListOrders(ctx context.Context, token, userID, email, sessionID, role string, query dto.AdminOrderListQuery) (*types.HTTPResponse, error)
+	AdminUpdateOrder(ctx context.Context, token, userID, email, sessionID, role, orderID string, payload dto.AdminUpdateOrderRequest) (*types.HTTPResponse, error)
+	AdminGetOrderStats(ctx context.Context, token, userID, email, sessionID, role string, query dto.OrderStatsQuery) (*types.HTTPResponse, error)
+	AdminListSagas(ctx context.Context, token, userID, email, sessionID, role string, query dto.AdminSagaListQuery) (*types.HTTPResponse, error)
+	AdminGetSaga(ctx context.Context, token, userID, email, sessionID, role, sagaID string) (*types.HTTPResponse, error)
+	AdminRetrySaga(ctx context.Context, token, userID, email, sessionID, role, sagaID string) (*types.HTTPResponse, error)
+	AdminCompensateSaga(ctx context.Context, token, userID, email, sessionID, role, sagaID string) (*types.HTTPResponse, error)
 }
 
 type OrderServiceClient struct {
@@ -59,6 +68,9 @@ func (c *OrderServiceClient) ListOrders(ctx context.Context, token, userID, emai
 	if strings.TrimSpace(query.Status) != "" {
 		params.Set("status", strings.TrimSpace(query.Status))
 	}
+	if strings.TrimSpace(query.Sort) != "" {
+		params.Set("sort", strings.TrimSpace(query.Sort))
+	}
 	if strings.TrimSpace(query.SortBy) != "" {
 		params.Set("sort_by", strings.TrimSpace(query.SortBy))
 	}
@@ -90,6 +102,147 @@ func (c *OrderServiceClient) CancelOrder(ctx context.Context, token, userID, ema
 	return c.doRequest(ctx, http.MethodPost, path, payload, headers)
 }
 
+func (c *OrderServiceClient) RequestRefund(ctx context.Context, token, userID, email, sessionID, orderID string, payload dto.RefundOrderRequest) (*types.HTTPResponse, error) {
+	if orderID == "" {
+		return nil, fmt.Errorf("order id is required")
+	}
+	headers := c.combineHeaders(token, userID, email, sessionID)
+	path := "/api/v1/orders/" + url.PathEscape(orderID) + "/refund"
+	return c.doRequest(ctx, http.MethodPost, path, payload, headers)
+}
+
+func (c *OrderServiceClient) ListRefunds(ctx context.Context, token, userID, email, sessionID string, query dto.RefundListQuery) (*types.HTTPResponse, error) {
+	headers := c.combineHeaders(token, userID, email, sessionID)
+	path := "/api/v1/refunds"
+
+	params := url.Values{}
+	if query.Limit > 0 {
+		params.Set("limit", fmt.Sprintf("%d", query.Limit))
+	}
+	if query.Offset > 0 {
+		params.Set("offset", fmt.Sprintf("%d", query.Offset))
+	}
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+
+	return c.doRequest(ctx, http.MethodGet, path, nil, headers)
+}
+
+func (c *OrderServiceClient) AdminListOrders(ctx context.Context, token, userID, email, sessionID, role string, query dto.AdminOrderListQuery) (*types.HTTPResponse, error) {
+	headers := c.adminHeaders(token, userID, email, sessionID, role)
+	path := "/api/v1/admin/orders"
+
+	params := url.Values{}
+	if query.Limit > 0 {
+		params.Set("limit", fmt.Sprintf("%d", query.Limit))
+	}
+	if query.Offset > 0 {
+		params.Set("offset", fmt.Sprintf("%d", query.Offset))
+	}
+	if strings.TrimSpace(query.UserID) != "" {
+		params.Set("user_id", strings.TrimSpace(query.UserID))
+	}
+	if strings.TrimSpace(query.Status) != "" {
+		params.Set("status", strings.TrimSpace(query.Status))
+	}
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+
+	return c.doRequest(ctx, http.MethodGet, path, nil, headers)
+}
+
+func (c *OrderServiceClient) AdminUpdateOrder(ctx context.Context, token, userID, email, sessionID, role, orderID string, payload dto.AdminUpdateOrderRequest) (*types.HTTPResponse, error) {
+	if orderID == "" {
+		return nil, fmt.Errorf("order id is required")
+	}
+	headers := c.adminHeaders(token, userID, email, sessionID, role)
+	path := "/api/v1/admin/orders/" + url.PathEscape(orderID)
+	return c.doRequest(ctx, http.MethodPut, path, payload, headers)
+}
+
+func (c *OrderServiceClient) AdminGetOrderStats(ctx context.Context, token, userID, email, sessionID, role string, query dto.OrderStatsQuery) (*types.HTTPResponse, error) {
+	headers := c.adminHeaders(token, userID, email, sessionID, role)
+	path := "/api/v1/admin/orders/stats"
+
+	params := url.Values{}
+	if strings.TrimSpace(query.UserID) != "" {
+		params.Set("user_id", strings.TrimSpace(query.UserID))
+	}
+	if strings.TrimSpace(query.StartDate) != "" {
+		params.Set("start_date", strings.TrimSpace(query.StartDate))
+	}
+	if strings.TrimSpace(query.EndDate) != "" {
+		params.Set("end_date", strings.TrimSpace(query.EndDate))
+	}
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+
+	return c.doRequest(ctx, http.MethodGet, path, nil, headers)
+}
+
+func (c *OrderServiceClient) AdminListSagas(ctx context.Context, token, userID, email, sessionID, role string, query dto.AdminSagaListQuery) (*types.HTTPResponse, error) {
+	headers := c.adminHeaders(token, userID, email, sessionID, role)
+	path := "/api/v1/admin/sagas"
+
+	params := url.Values{}
+	if query.Limit > 0 {
+		params.Set("limit", fmt.Sprintf("%d", query.Limit))
+	}
+	if query.Offset > 0 {
+		params.Set("offset", fmt.Sprintf("%d", query.Offset))
+	}
+	if strings.TrimSpace(query.Type) != "" {
+		params.Set("type", strings.TrimSpace(query.Type))
+	}
+	if strings.TrimSpace(query.Status) != "" {
+		params.Set("status", strings.TrimSpace(query.Status))
+	}
+	if strings.TrimSpace(query.OrderID) != "" {
+		params.Set("order_id", strings.TrimSpace(query.OrderID))
+	}
+	if query.Stuck {
+		params.Set("stuck", "true")
+	}
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+
+	return c.doRequest(ctx, http.MethodGet, path, nil, headers)
+}
+
+func (c *OrderServiceClient) AdminGetSaga(ctx context.Context, token, userID, email, sessionID, role, sagaID string) (*types.HTTPResponse, error) {
+	return c.sagaRequest(ctx, http.MethodGet, token, userID, email, sessionID, role, sagaID, "")
+}
+
+func (c *OrderServiceClient) AdminRetrySaga(ctx context.Context, token, userID, email, sessionID, role, sagaID string) (*types.HTTPResponse, error) {
+	return c.sagaRequest(ctx, http.MethodPost, token, userID, email, sessionID, role, sagaID, "/retry")
+}
+
+func (c *OrderServiceClient) AdminCompensateSaga(ctx context.Context, token, userID, email, sessionID, role, sagaID string) (*types.HTTPResponse, error) {
+	return c.sagaRequest(ctx, http.MethodPost, token, userID, email, sessionID, role, sagaID, "/compensate")
+}
+
+func (c *OrderServiceClient) sagaRequest(ctx context.Context, method, token, userID, email, sessionID, role, sagaID, action string) (*types.HTTPResponse, error) {
+	if sagaID == "" {
+		return nil, fmt.Errorf("saga id is required")
+	}
+	headers := c.adminHeaders(token, userID, email, sessionID, role)
+	path := "/api/v1/admin/sagas/" + url.PathEscape(sagaID) + action
+	return c.doRequest(ctx, method, path, nil, headers)
+}
+
+// adminHeaders adds the caller's role, already verified by the gateway, to the usual auth headers.
+func (c *OrderServiceClient) adminHeaders(token, userID, email, sessionID, role string) http.Header {
+	headers := c.combineHeaders(token, userID, email, sessionID)
+	if role != "" {
+		headers.Set("X-User-Role", role)
+	}
+	return headers
+}
+
 func (c *OrderServiceClient) combineHeaders(token, userID, email, sessionID string) http.Header {
 	headers := internalAuthHeaders(userID, email, sessionID)
 	if token != "" {
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"bff-services/internal/cache"
+
+	"github.com/ductan2/microservice-app/shared/batch"
+)
+
+// ProfileEnricher resolves display names and avatars for user IDs, using Redis as a
+// read-through cache in front of user-services.
+type ProfileEnricher struct {
+	userService     UserService
+	identityService IdentityService
+	profileCache    *cache.ProfileCache
+}
+
+// NewProfileEnricher constructs a new ProfileEnricher.
+func NewProfileEnricher(userService UserService, profileCache *cache.ProfileCache) *ProfileEnricher {
+	return &ProfileEnricher{
+		userService:  userService,
+		profileCache: profileCache,
+	}
+}
+
+// SetIdentityService makes cache misses resolve through the user-services gRPC API. The
+// REST batch endpoint remains the fallback when a gRPC call fails.
+func (e *ProfileEnricher) SetIdentityService(identityService IdentityService) {
+	e.identityService = identityService
+}
+
+// Resolve returns profile snippets for the given user IDs. Cache misses are looked up in
+// batches with the service token of the BFF; IDs that cannot be resolved are omitted
+// rather than failing the request.
+func (e *ProfileEnricher) Resolve(ctx context.Context, ids []string) map[string]cache.ProfileSnippet {
+	ids = uniqueStrings(ids)
+
+	profiles, err := e.profileCache.GetMany(ctx, ids)
+	if err != nil {
+		slog.WarnContext(ctx, "profile cache lookup failed", "error", err)
+	}
+
+	var missing []string
+	for _, id := range ids {
+		if _, ok := profiles[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return profiles
+	}
+
+	fetched, ok := e.batchFetchProfiles(ctx, missing)
+	if !ok {
+		fetched = e.fetchProfiles(ctx, missing)
+	}
+
+	for _, snippet := range fetched {
+		profiles[snippet.UserID] = snippet
+	}
+	if err := e.profileCache.SetMany(ctx, fetched); err != nil {
+		slog.WarnContext(ctx, "failed to cache profile snippets", "error", err)
+	}
+
+	return profiles
+}
+
+// batchFetchProfiles resolves ids through the identity service. It reports false when
+// the identity service is not configured or a call fails.
+func (e *ProfileEnricher) batchFetchProfiles(ctx context.Context, ids []string) ([]cache.ProfileSnippet, bool) {
+	if e.identityService == nil {
+		return nil, false
+	}
+
+	var fetched []cache.ProfileSnippet
+	for _, chunk := range batch.Chunks(ids, batch.MaxSize) {
+		resp, err := e.identityService.BatchGetUsers(ctx, chunk)
+		if err != nil {
+			slog.WarnContext(ctx, "identity batch lookup failed, falling back to REST", "error", err)
+			return nil, false
+		}
+		for _, user := range resp.GetUsers() {
+			fetched = append(fetched, cache.ProfileSnippet{
+				UserID:      user.GetId(),
+				DisplayName: user.GetDisplayName(),
+				AvatarURL:   user.GetAvatarUrl(),
+			})
+		}
+	}
+	return fetched, true
+}
+
+// fetchProfiles resolves ids through the user-services batch endpoint, batch.MaxSize
+// users per call. The profiles of the batches answered before a failure are kept.
+func (e *ProfileEnricher) fetchProfiles(ctx context.Context, ids []string) []cache.ProfileSnippet {
+	resp, err := batch.Fetch(ctx, ids, batch.MaxSize, e.fetchBatch)
+	if err != nil {
+		slog.WarnContext(ctx, "user batch lookup failed", "error", err)
+	}
+
+	fetched := make([]cache.ProfileSnippet, 0, len(resp.Items))
+	for _, user := range resp.Items {
+		fetched = append(fetched, cache.ProfileSnippet{
+			UserID:      user.ID,
+			DisplayName: user.Profile.DisplayName,
+			AvatarURL:   user.Profile.AvatarURL,
+		})
+	}
+	return fetched
+}
+
+// batchUser is the part of a user of the batch endpoint the enricher reads.
+type batchUser struct {
+	ID      string `json:"id"`
+	Profile struct {
+		DisplayName string `json:"display_name"`
+		AvatarURL   string `json:"avatar_url"`
+	} `json:"profile"`
+}
+
+func (e *ProfileEnricher) fetchBatch(ctx context.Context, ids []string) (batch.Response[batchUser], error) {
+	var envelope struct {
+		Data batch.Response[batchUser] `json:"data"`
+	}
+	resp, err := e.userService.BatchGetUsers(ctx, ids)
+	if err != nil {
+		return envelope.Data, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return envelope.Data, fmt.Errorf("user-services answered %d", resp.StatusCode)
+	}
+	if err := json.Unmarshal(resp.Body, &envelope); err != nil {
+		return envelope.Data, fmt.Errorf("decode user batch: %w", err)
+	}
+	return envelope.Data, nil
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		result = append(result, v)
+	}
+	return result
+}
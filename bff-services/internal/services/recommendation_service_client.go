@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"bff-services/internal/types"
+)
+
+// recommendationParams are the query parameters forwarded to recommendation-services.
+var recommendationParams = []string{"type", "limit"}
+
+// RecommendationService reads the courses and lessons recommended to a learner from
+// recommendation-services.
+type RecommendationService interface {
+	GetRecommendations(ctx context.Context, userID string, query url.Values) (*types.HTTPResponse, error)
+}
+
+type RecommendationServiceClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewRecommendationServiceClient returns a client for the recommendation endpoint.
+func NewRecommendationServiceClient(baseURL string, httpClient *http.Client) *RecommendationServiceClient {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 3 * time.Second}
+	}
+	return &RecommendationServiceClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: httpClient,
+	}
+}
+
+// GetRecommendations forwards the known recommendation parameters of query for the
+// learner userID.
+func (c *RecommendationServiceClient) GetRecommendations(ctx context.Context, userID string, query url.Values) (*types.HTTPResponse, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user id is required")
+	}
+	path := "/api/v1/users/" + url.PathEscape(userID) + "/recommendations"
+	return doRequest(ctx, c.baseURL, http.MethodGet, withParams(path, query, recommendationParams), c.httpClient, nil, nil)
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"bff-services/internal/types"
+)
+
+func TestRecommendationServiceContract(t *testing.T) {
+	stub := newStubService(t)
+	client := NewRecommendationServiceClient(stub.URL()+"/", nil)
+
+	cases := []contractCase{
+		{
+			name: "GetRecommendations",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetRecommendations(ctx, "user-1", url.Values{})
+			},
+			method: http.MethodGet,
+			path:   "/api/v1/users/user-1/recommendations",
+		},
+		{
+			name: "GetRecommendationsForwardsKnownParams",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetRecommendations(ctx, "user-1", url.Values{
+					"type":  {"course"},
+					"limit": {"5"},
+					"sort":  {"newest"},
+				})
+			},
+			method: http.MethodGet,
+			path:   "/api/v1/users/user-1/recommendations",
+			query:  "limit=5&type=course",
+		},
+	}
+
+	runContractCases(t, stub, cases)
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"bff-services/internal/api/dto"
+	"bff-services/internal/types"
+)
+
+// searchParams are the query parameters forwarded to search-services; anything else the
+// client sends is dropped.
+var searchParams = []string{"q", "type", "topic_id", "level_id", "featured", "fuzzy", "sort", "page", "page_size"}
+
+// suggestParams are the query parameters forwarded to the suggestions of search-services.
+// The locale comes from the Accept-Language the BFF negotiated unless the client sets it.
+var suggestParams = []string{"q", "type", "locale", "size", "fuzzy"}
+
+// SearchService searches the published catalog through search-services.
+type SearchService interface {
+	Search(ctx context.Context, query url.Values) (*types.HTTPResponse, error)
+	Suggest(ctx context.Context, query url.Values) (*types.HTTPResponse, error)
+
+	// Synonym rules: the dictionary searches are expanded and corrected with (admin)
+	ListSynonyms(ctx context.Context) (*types.HTTPResponse, error)
+	GetSynonym(ctx context.Context, id string) (*types.HTTPResponse, error)
+	CreateSynonym(ctx context.Context, payload dto.SynonymRuleRequest) (*types.HTTPResponse, error)
+	UpdateSynonym(ctx context.Context, id string, payload dto.SynonymRuleRequest) (*types.HTTPResponse, error)
+	DeleteSynonym(ctx context.Context, id string) (*types.HTTPResponse, error)
+}
+
+type SearchServiceClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewSearchServiceClient returns a client for the search endpoint.
+func NewSearchServiceClient(baseURL string, httpClient *http.Client) *SearchServiceClient {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 5 * time.Second}
+	}
+	return &SearchServiceClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: httpClient,
+	}
+}
+
+// Search forwards the known search parameters of query. The catalog is public, so no user
+// is passed along.
+func (c *SearchServiceClient) Search(ctx context.Context, query url.Values) (*types.HTTPResponse, error) {
+	return doRequest(ctx, c.baseURL, http.MethodGet, withParams("/api/v1/search", query, searchParams), c.httpClient, nil, nil)
+}
+
+// Suggest forwards the known suggestion parameters of query, the prefix typed in the
+// search box.
+func (c *SearchServiceClient) Suggest(ctx context.Context, query url.Values) (*types.HTTPResponse, error) {
+	return doRequest(ctx, c.baseURL, http.MethodGet, withParams("/api/v1/suggest", query, suggestParams), c.httpClient, nil, nil)
+}
+
+// Synonym rule methods
+func (c *SearchServiceClient) ListSynonyms(ctx context.Context) (*types.HTTPResponse, error) {
+	return doRequest(ctx, c.baseURL, http.MethodGet, "/api/v1/admin/synonyms", c.httpClient, nil, nil)
+}
+
+func (c *SearchServiceClient) GetSynonym(ctx context.Context, id string) (*types.HTTPResponse, error) {
+	if id == "" {
+		return nil, fmt.Errorf("synonym rule id is required")
+	}
+	return doRequest(ctx, c.baseURL, http.MethodGet, "/api/v1/admin/synonyms/"+url.PathEscape(id), c.httpClient, nil, nil)
+}
+
+func (c *SearchServiceClient) CreateSynonym(ctx context.Context, payload dto.SynonymRuleRequest) (*types.HTTPResponse, error) {
+	return doRequest(ctx, c.baseURL, http.MethodPost, "/api/v1/admin/synonyms", c.httpClient, payload, nil)
+}
+
+func (c *SearchServiceClient) UpdateSynonym(ctx context.Context, id string, payload dto.SynonymRuleRequest) (*types.HTTPResponse, error) {
+	if id == "" {
+		return nil, fmt.Errorf("synonym rule id is required")
+	}
+	return doRequest(ctx, c.baseURL, http.MethodPut, "/api/v1/admin/synonyms/"+url.PathEscape(id), c.httpClient, payload, nil)
+}
+
+func (c *SearchServiceClient) DeleteSynonym(ctx context.Context, id string) (*types.HTTPResponse, error) {
+	if id == "" {
+		return nil, fmt.Errorf("synonym rule id is required")
+	}
+	return doRequest(ctx, c.baseURL, http.MethodDelete, "/api/v1/admin/synonyms/"+url.PathEscape(id), c.httpClient, nil, nil)
+}
+
+// withParams adds the parameters of query named in names to path.
+func withParams(path string, query url.Values, names []string) string {
+	params := url.Values{}
+	for _, name := range names {
+		if values, ok := query[name]; ok {
+			params[name] = values
+		}
+	}
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+	return path
+}
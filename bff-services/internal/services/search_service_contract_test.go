@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"bff-services/internal/api/dto"
+	"bff-services/internal/types"
+)
+
+func TestSearchServiceContract(t *testing.T) {
+	stub := newStubService(t)
+	client := NewSearchServiceClient(stub.URL()+"/", nil)
+
+	cases := []contractCase{
+		{
+			name: "SearchWithoutQuery",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.Search(ctx, url.Values{})
+			},
+			method: http.MethodGet,
+			path:   "/api/v1/search",
+		},
+		{
+			name: "SearchForwardsKnownParams",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.Search(ctx, url.Values{
+					"q":         {"present perfect"},
+					"type":      {"course", "lesson"},
+					"page_size": {"10"},
+					"debug":     {"true"},
+				})
+			},
+			method: http.MethodGet,
+			path:   "/api/v1/search",
+			query:  "page_size=10&q=present+perfect&type=course&type=lesson",
+		},
+		{
+			name: "SuggestForwardsKnownParams",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.Suggest(ctx, url.Values{
+					"q":      {"perf"},
+					"type":   {"course,glossary"},
+					"size":   {"5"},
+					"sort":   {"newest"},
+					"locale": {"vi"},
+				})
+			},
+			method: http.MethodGet,
+			path:   "/api/v1/suggest",
+			query:  "locale=vi&q=perf&size=5&type=course%2Cglossary",
+		},
+		{
+			name: "CreateSynonym",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.CreateSynonym(ctx, dto.SynonymRuleRequest{Terms: []string{"ielts"}, Misspellings: []string{"ilets"}})
+			},
+			method:       http.MethodPost,
+			path:         "/api/v1/admin/synonyms",
+			bodyContains: []string{`"terms":["ielts"]`, `"misspellings":["ilets"]`},
+		},
+		{
+			name: "DeleteSynonym",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.DeleteSynonym(ctx, "5b1c7a9e-0d2f-4c38-9a51-6e0f3d2b8c47")
+			},
+			method: http.MethodDelete,
+			path:   "/api/v1/admin/synonyms/5b1c7a9e-0d2f-4c38-9a51-6e0f3d2b8c47",
+		},
+	}
+
+	runContractCases(t, stub, cases)
+}
@@ -5,34 +5,127 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"bff-services/internal/api/dto"
+	"bff-services/internal/tracing"
 	"bff-services/internal/types"
+
+	"github.com/ductan2/microservice-app/shared/batch"
 )
 
 type UserService interface {
-	Register(ctx context.Context, payload dto.RegisterRequest) (*types.HTTPResponse, error)
+	Register(ctx context.Context, payload dto.RegisterRequest, clientIP string) (*types.HTTPResponse, error)
 	Login(ctx context.Context, payload dto.LoginRequest, userAgent, clientIP string) (*types.HTTPResponse, error)
 	Logout(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error)
+	RefreshToken(ctx context.Context, payload dto.RefreshTokenRequest, clientIP string) (*types.HTTPResponse, error)
+	RequestPasswordlessLogin(ctx context.Context, payload dto.PasswordlessLoginRequest, clientIP string) (*types.HTTPResponse, error)
+	VerifyPasswordlessLogin(ctx context.Context, payload dto.PasswordlessVerifyRequest, userAgent, clientIP string) (*types.HTTPResponse, error)
+	RequestAccountUnlock(ctx context.Context, payload dto.AccountUnlockRequest, clientIP string) (*types.HTTPResponse, error)
+	ConfirmAccountUnlock(ctx context.Context, payload dto.AccountUnlockConfirmRequest, clientIP string) (*types.HTTPResponse, error)
 	VerifyEmail(ctx context.Context, token string) (*types.HTTPResponse, error)
-	RequestPasswordReset(ctx context.Context, payload dto.PasswordResetRequest) (*types.HTTPResponse, error)
+	RequestPasswordReset(ctx context.Context, payload dto.PasswordResetRequest, clientIP string) (*types.HTTPResponse, error)
 	ConfirmPasswordReset(ctx context.Context, payload dto.PasswordResetConfirmRequest) (*types.HTTPResponse, error)
 	ChangePassword(ctx context.Context, userID, email, sessionID string, payload dto.ChangePasswordRequest) (*types.HTTPResponse, error)
 	SetupMFA(ctx context.Context, userID, email, sessionID string, payload dto.MFASetupRequest) (*types.HTTPResponse, error)
 	VerifyMFA(ctx context.Context, userID, email, sessionID string, payload dto.MFAVerifyRequest) (*types.HTTPResponse, error)
 	DisableMFA(ctx context.Context, userID, email, sessionID string, payload dto.MFADisableRequest) (*types.HTTPResponse, error)
 	GetMFAMethods(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error)
+	BeginPasskeyLogin(ctx context.Context, payload dto.PasskeyLoginBeginRequest, clientIP string) (*types.HTTPResponse, error)
+	FinishPasskeyLogin(ctx context.Context, payload dto.WebAuthnAssertion, userAgent, clientIP string) (*types.HTTPResponse, error)
+	BeginPasskeyRegistration(ctx context.Context, userID, email, sessionID string, payload dto.PasskeyRegistrationBeginRequest) (*types.HTTPResponse, error)
+	FinishPasskeyRegistration(ctx context.Context, userID, email, sessionID string, payload dto.PasskeyRegistrationFinishRequest) (*types.HTTPResponse, error)
+	ListPasskeys(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error)
+	RenamePasskey(ctx context.Context, userID, email, sessionID, passkeyID string, payload dto.PasskeyRenameRequest) (*types.HTTPResponse, error)
+	RevokePasskey(ctx context.Context, userID, email, sessionID, passkeyID string, payload dto.PasskeyRevokeRequest) (*types.HTTPResponse, error)
+	SendLoginOTP(ctx context.Context, payload dto.LoginOTPSendRequest, clientIP string) (*types.HTTPResponse, error)
+	SetupPhoneMFA(ctx context.Context, userID, email, sessionID string, payload dto.PhoneMFASetupRequest) (*types.HTTPResponse, error)
+	ResendPhoneCode(ctx context.Context, userID, email, sessionID, methodID string, payload dto.OTPSendRequest) (*types.HTTPResponse, error)
+	ConfirmPhoneMFA(ctx context.Context, userID, email, sessionID, methodID string, payload dto.PhoneMFAConfirmRequest) (*types.HTTPResponse, error)
+	RevokePhoneMFA(ctx context.Context, userID, email, sessionID, methodID string, payload dto.PhoneMFARevokeRequest) (*types.HTTPResponse, error)
+	ReorderMFAMethods(ctx context.Context, userID, email, sessionID string, payload dto.MFAOrderRequest) (*types.HTTPResponse, error)
+	GetMyAuditLogs(ctx context.Context, userID, email, sessionID string, query dto.SecurityAuditQuery) (*types.HTTPResponse, error)
+	GetUserAuditLogs(ctx context.Context, userID, email, sessionID, targetID string, query dto.SecurityAuditQuery) (*types.HTTPResponse, error)
+	GetLoginHistory(ctx context.Context, userID, email, sessionID string, query dto.LoginHistoryQuery) (*types.HTTPResponse, error)
+	GetUserMetrics(ctx context.Context, userID, email, sessionID string, query dto.UserMetricsQuery) (*types.HTTPResponse, error)
+	ListSecurityAuditLogs(ctx context.Context, userID, email, sessionID string, query dto.SecurityAuditQuery) (*types.HTTPResponse, error)
+	RequestDataExport(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error)
+	ListDataExports(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error)
+	GetDataExport(ctx context.Context, userID, email, sessionID, exportID string) (*types.HTTPResponse, error)
+	DownloadDataExport(ctx context.Context, userID, email, sessionID, exportID string) (*types.HTTPResponse, error)
+	RequestErasure(ctx context.Context, userID, email, sessionID string, payload dto.ErasureSelfRequest) (*types.HTTPResponse, error)
+	DeactivateAccount(ctx context.Context, userID, email, sessionID string, payload dto.DeactivateAccountRequest) (*types.HTTPResponse, error)
+	ScheduleUserErasure(ctx context.Context, userID, email, sessionID, targetID string, payload dto.ErasureAdminRequest) (*types.HTTPResponse, error)
+	ListErasures(ctx context.Context, userID, email, sessionID string, query dto.ErasureQuery) (*types.HTTPResponse, error)
+	GetErasure(ctx context.Context, userID, email, sessionID, erasureID string) (*types.HTTPResponse, error)
+	RecoverAccount(ctx context.Context, payload dto.ErasureRecoverRequest, clientIP string) (*types.HTTPResponse, error)
+	ImportUsers(ctx context.Context, userID, email, sessionID string, payload dto.UserImportRequest) (*types.HTTPResponse, error)
+	ImportUsersCSV(ctx context.Context, userID, email, sessionID string, options dto.UserImportRequest, csv []byte) (*types.HTTPResponse, error)
+	CreateOrganization(ctx context.Context, userID, email, sessionID string, payload dto.CreateOrganizationRequest) (*types.HTTPResponse, error)
+	ListOrganizations(ctx context.Context, userID, email, sessionID string, query dto.OrganizationQuery) (*types.HTTPResponse, error)
+	UpdateOrganization(ctx context.Context, userID, email, sessionID, orgID string, payload dto.UpdateOrganizationRequest) (*types.HTTPResponse, error)
+	GetOrganization(ctx context.Context, userID, email, sessionID, orgID string) (*types.HTTPResponse, error)
+	ListOrganizationMembers(ctx context.Context, userID, email, sessionID, orgID string, query dto.OrganizationMemberQuery) (*types.HTTPResponse, error)
+	AddOrganizationMember(ctx context.Context, userID, email, sessionID, orgID string, payload dto.AddOrganizationMemberRequest) (*types.HTTPResponse, error)
+	UpdateOrganizationMember(ctx context.Context, userID, email, sessionID, orgID, memberID string, payload dto.UpdateOrganizationMemberRequest) (*types.HTTPResponse, error)
+	RemoveOrganizationMember(ctx context.Context, userID, email, sessionID, orgID, memberID string) (*types.HTTPResponse, error)
+	ListMyOrganizations(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error)
+	CreateInvitation(ctx context.Context, userID, email, sessionID string, payload dto.CreateInvitationRequest) (*types.HTTPResponse, error)
+	ListInvitations(ctx context.Context, userID, email, sessionID string, query dto.InvitationQuery) (*types.HTTPResponse, error)
+	ResendInvitation(ctx context.Context, userID, email, sessionID, invitationID string) (*types.HTTPResponse, error)
+	RevokeInvitation(ctx context.Context, userID, email, sessionID, invitationID string) (*types.HTTPResponse, error)
+	PreviewInvitation(ctx context.Context, token, clientIP string) (*types.HTTPResponse, error)
+	AcceptInvitation(ctx context.Context, payload dto.AcceptInvitationRequest, clientIP string) (*types.HTTPResponse, error)
+	CreateAccessToken(ctx context.Context, userID, email, sessionID string, payload dto.CreateAccessTokenRequest) (*types.HTTPResponse, error)
+	ListAccessTokens(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error)
+	RevokeAccessToken(ctx context.Context, userID, email, sessionID, tokenID string) (*types.HTTPResponse, error)
+	// IntrospectAccessToken resolves a personal access token to its user, authenticating
+	// as the BFF with the internal service token.
+	IntrospectAccessToken(ctx context.Context, token, clientIP string) (*types.HTTPResponse, error)
+	RegisterPushToken(ctx context.Context, userID, email, sessionID string, payload dto.RegisterPushTokenRequest) (*types.HTTPResponse, error)
+	ListPushTokens(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error)
+	UnregisterPushToken(ctx context.Context, userID, email, sessionID, tokenID string) (*types.HTTPResponse, error)
+	UnregisterPushTokenByValue(ctx context.Context, userID, email, sessionID string, payload dto.UnregisterPushTokenRequest) (*types.HTTPResponse, error)
+	ListPolicies(ctx context.Context) (*types.HTTPResponse, error)
+	ListPolicyVersions(ctx context.Context, userID, email, sessionID, document string) (*types.HTTPResponse, error)
+	PublishPolicy(ctx context.Context, userID, email, sessionID string, payload dto.PublishPolicyRequest) (*types.HTTPResponse, error)
+	GetConsents(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error)
+	RecordConsent(ctx context.Context, userID, email, sessionID string, payload dto.RecordConsentRequest) (*types.HTTPResponse, error)
+	RequestAccountMerge(ctx context.Context, userID, email, sessionID string, payload dto.RequestAccountMergeRequest) (*types.HTTPResponse, error)
+	GetAccountMerge(ctx context.Context, userID, email, sessionID, mergeID string) (*types.HTTPResponse, error)
+	ConfirmAccountMerge(ctx context.Context, userID, email, sessionID, mergeID string, payload dto.ConfirmAccountMergeRequest) (*types.HTTPResponse, error)
+	CancelAccountMerge(ctx context.Context, userID, email, sessionID, mergeID string) (*types.HTTPResponse, error)
+	StartImpersonation(ctx context.Context, userID, email, sessionID, targetID string, payload dto.StartImpersonationRequest) (*types.HTTPResponse, error)
+	EndImpersonation(ctx context.Context, userID, email, sessionID, impersonationID string) (*types.HTTPResponse, error)
 	GetSessions(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error)
 	DeleteSession(ctx context.Context, userID, email, sessionID, deleteSessionID string) (*types.HTTPResponse, error)
 	RevokeAllSessions(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error)
 	ListSessionsByUserID(ctx context.Context, targetUserID, userID, email, sessionID string) (*types.HTTPResponse, error)
-	GetUsers(ctx context.Context, page, pageSize, status, search, userID, email, sessionID string) (*types.HTTPResponse, error)
+	GetUsers(ctx context.Context, query dto.AdminUserQuery, userID, email, sessionID string) (*types.HTTPResponse, error)
 	GetUserById(ctx context.Context, userID, email, sessionID, UserFindID string) (*types.HTTPResponse, error)
+	// BatchGetUsers resolves up to batch.MaxSize users in one call to the internal batch
+	// endpoint, authenticated by the service token alone.
+	BatchGetUsers(ctx context.Context, ids []string) (*types.HTTPResponse, error)
 	// New methods for internal communication with user context
 	GetProfileWithContext(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error)
 	UpdateProfileWithContext(ctx context.Context, userID, email, sessionID string, payload dto.UpdateProfileRequest) (*types.HTTPResponse, error)
+	GetPreferences(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error)
+	UpdatePreferences(ctx context.Context, userID, email, sessionID string, payload dto.UpdatePreferencesRequest) (*types.HTTPResponse, error)
+	GetOnboarding(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error)
+	AdvanceOnboarding(ctx context.Context, userID, email, sessionID, step string, payload dto.AdvanceOnboardingRequest) (*types.HTTPResponse, error)
+	CheckUsernameAvailability(ctx context.Context, username, clientIP string) (*types.HTTPResponse, error)
+	GetLoginIdentifiers(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error)
+	SetUsername(ctx context.Context, userID, email, sessionID string, payload dto.SetUsernameRequest) (*types.HTTPResponse, error)
+	RemoveUsername(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error)
+	CheckPhoneAvailability(ctx context.Context, userID, email, sessionID, phone string) (*types.HTTPResponse, error)
+	StartPhoneVerification(ctx context.Context, userID, email, sessionID string, payload dto.PhoneVerificationRequest) (*types.HTTPResponse, error)
+	VerifyPhone(ctx context.Context, userID, email, sessionID string, payload dto.VerifyPhoneRequest) (*types.HTTPResponse, error)
+	RemovePhone(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error)
+	CreateAvatarUpload(ctx context.Context, userID, email, sessionID string, payload dto.CreateAvatarUploadRequest) (*types.HTTPResponse, error)
+	CompleteAvatarUpload(ctx context.Context, userID, email, sessionID, uploadID string) (*types.HTTPResponse, error)
+	RemoveAvatar(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error)
 	UpdateUserRoleWithContext(ctx context.Context, userID, email, sessionID string, targetID string, payload dto.UpdateUserRoleRequest) (*types.HTTPResponse, error)
 	LockAccountWithContext(ctx context.Context, userID, email, sessionID, targetID, reason string) (*types.HTTPResponse, error)
 	UnlockAccountWithContext(ctx context.Context, userID, email, sessionID, targetID, reason string) (*types.HTTPResponse, error)
@@ -62,8 +155,12 @@ func NewUserServiceClient(baseURL string, httpClient *http.Client) *UserServiceC
 	}
 }
 
-func (c *UserServiceClient) Register(ctx context.Context, payload dto.RegisterRequest) (*types.HTTPResponse, error) {
-	return c.doRequest(ctx, http.MethodPost, "/api/v1/users/register", payload, nil)
+func (c *UserServiceClient) Register(ctx context.Context, payload dto.RegisterRequest, clientIP string) (*types.HTTPResponse, error) {
+	headers := http.Header{}
+	if clientIP != "" {
+		headers.Set("X-Forwarded-For", clientIP)
+	}
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/users/register", payload, headers)
 }
 
 func (c *UserServiceClient) Login(ctx context.Context, payload dto.LoginRequest, userAgent, clientIP string) (*types.HTTPResponse, error) {
@@ -81,6 +178,49 @@ func (c *UserServiceClient) Logout(ctx context.Context, userID, email, sessionID
 	return c.doRequest(ctx, http.MethodPost, "/api/v1/users/logout", nil, internalAuthHeaders(userID, email, sessionID))
 }
 
+func (c *UserServiceClient) RefreshToken(ctx context.Context, payload dto.RefreshTokenRequest, clientIP string) (*types.HTTPResponse, error) {
+	headers := http.Header{}
+	if clientIP != "" {
+		headers.Set("X-Forwarded-For", clientIP)
+	}
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/auth/refresh", payload, headers)
+}
+
+func (c *UserServiceClient) RequestPasswordlessLogin(ctx context.Context, payload dto.PasswordlessLoginRequest, clientIP string) (*types.HTTPResponse, error) {
+	headers := http.Header{}
+	if clientIP != "" {
+		headers.Set("X-Forwarded-For", clientIP)
+	}
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/users/login/passwordless", payload, headers)
+}
+
+func (c *UserServiceClient) VerifyPasswordlessLogin(ctx context.Context, payload dto.PasswordlessVerifyRequest, userAgent, clientIP string) (*types.HTTPResponse, error) {
+	headers := http.Header{}
+	if userAgent != "" {
+		headers.Set("User-Agent", userAgent)
+	}
+	if clientIP != "" {
+		headers.Set("X-Forwarded-For", clientIP)
+	}
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/users/login/passwordless/verify", payload, headers)
+}
+
+func (c *UserServiceClient) RequestAccountUnlock(ctx context.Context, payload dto.AccountUnlockRequest, clientIP string) (*types.HTTPResponse, error) {
+	headers := http.Header{}
+	if clientIP != "" {
+		headers.Set("X-Forwarded-For", clientIP)
+	}
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/users/unlock/request", payload, headers)
+}
+
+func (c *UserServiceClient) ConfirmAccountUnlock(ctx context.Context, payload dto.AccountUnlockConfirmRequest, clientIP string) (*types.HTTPResponse, error) {
+	headers := http.Header{}
+	if clientIP != "" {
+		headers.Set("X-Forwarded-For", clientIP)
+	}
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/users/unlock/confirm", payload, headers)
+}
+
 func (c *UserServiceClient) VerifyEmail(ctx context.Context, token string) (*types.HTTPResponse, error) {
 	if token == "" {
 		return nil, fmt.Errorf("verification token is required")
@@ -90,8 +230,12 @@ func (c *UserServiceClient) VerifyEmail(ctx context.Context, token string) (*typ
 	return c.doRequest(ctx, http.MethodGet, path, nil, nil)
 }
 
-func (c *UserServiceClient) RequestPasswordReset(ctx context.Context, payload dto.PasswordResetRequest) (*types.HTTPResponse, error) {
-	return c.doRequest(ctx, http.MethodPost, "/api/v1/users/password/reset/request", payload, nil)
+func (c *UserServiceClient) RequestPasswordReset(ctx context.Context, payload dto.PasswordResetRequest, clientIP string) (*types.HTTPResponse, error) {
+	headers := http.Header{}
+	if clientIP != "" {
+		headers.Set("X-Forwarded-For", clientIP)
+	}
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/users/password/reset/request", payload, headers)
 }
 
 func (c *UserServiceClient) ConfirmPasswordReset(ctx context.Context, payload dto.PasswordResetConfirmRequest) (*types.HTTPResponse, error) {
@@ -118,6 +262,486 @@ func (c *UserServiceClient) GetMFAMethods(ctx context.Context, userID, email, se
 	return c.doRequest(ctx, http.MethodGet, "/api/v1/mfa/methods", nil, internalAuthHeaders(userID, email, sessionID))
 }
 
+func (c *UserServiceClient) BeginPasskeyLogin(ctx context.Context, payload dto.PasskeyLoginBeginRequest, clientIP string) (*types.HTTPResponse, error) {
+	headers := http.Header{}
+	if clientIP != "" {
+		headers.Set("X-Forwarded-For", clientIP)
+	}
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/users/login/webauthn/begin", payload, headers)
+}
+
+func (c *UserServiceClient) FinishPasskeyLogin(ctx context.Context, payload dto.WebAuthnAssertion, userAgent, clientIP string) (*types.HTTPResponse, error) {
+	headers := http.Header{}
+	if userAgent != "" {
+		headers.Set("User-Agent", userAgent)
+	}
+	if clientIP != "" {
+		headers.Set("X-Forwarded-For", clientIP)
+	}
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/users/login/webauthn/finish", payload, headers)
+}
+
+func (c *UserServiceClient) BeginPasskeyRegistration(ctx context.Context, userID, email, sessionID string, payload dto.PasskeyRegistrationBeginRequest) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/mfa/webauthn/register/begin", payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) FinishPasskeyRegistration(ctx context.Context, userID, email, sessionID string, payload dto.PasskeyRegistrationFinishRequest) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/mfa/webauthn/register/finish", payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) ListPasskeys(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodGet, "/api/v1/mfa/webauthn/credentials", nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) RenamePasskey(ctx context.Context, userID, email, sessionID, passkeyID string, payload dto.PasskeyRenameRequest) (*types.HTTPResponse, error) {
+	path := "/api/v1/mfa/webauthn/credentials/" + url.PathEscape(passkeyID)
+	return c.doRequest(ctx, http.MethodPatch, path, payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) RevokePasskey(ctx context.Context, userID, email, sessionID, passkeyID string, payload dto.PasskeyRevokeRequest) (*types.HTTPResponse, error) {
+	path := "/api/v1/mfa/webauthn/credentials/" + url.PathEscape(passkeyID) + "/revoke"
+	return c.doRequest(ctx, http.MethodPost, path, payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) SendLoginOTP(ctx context.Context, payload dto.LoginOTPSendRequest, clientIP string) (*types.HTTPResponse, error) {
+	headers := http.Header{}
+	if clientIP != "" {
+		headers.Set("X-Forwarded-For", clientIP)
+	}
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/users/login/otp/send", payload, headers)
+}
+
+func (c *UserServiceClient) SetupPhoneMFA(ctx context.Context, userID, email, sessionID string, payload dto.PhoneMFASetupRequest) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/mfa/phone", payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) ResendPhoneCode(ctx context.Context, userID, email, sessionID, methodID string, payload dto.OTPSendRequest) (*types.HTTPResponse, error) {
+	path := "/api/v1/mfa/phone/" + url.PathEscape(methodID) + "/send"
+	return c.doRequest(ctx, http.MethodPost, path, payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) ConfirmPhoneMFA(ctx context.Context, userID, email, sessionID, methodID string, payload dto.PhoneMFAConfirmRequest) (*types.HTTPResponse, error) {
+	path := "/api/v1/mfa/phone/" + url.PathEscape(methodID) + "/verify"
+	return c.doRequest(ctx, http.MethodPost, path, payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) RevokePhoneMFA(ctx context.Context, userID, email, sessionID, methodID string, payload dto.PhoneMFARevokeRequest) (*types.HTTPResponse, error) {
+	path := "/api/v1/mfa/phone/" + url.PathEscape(methodID) + "/revoke"
+	return c.doRequest(ctx, http.MethodPost, path, payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) ReorderMFAMethods(ctx context.Context, userID, email, sessionID string, payload dto.MFAOrderRequest) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPut, "/api/v1/mfa/methods/order", payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+// GetMyAuditLogs lists the security events recorded on the caller's own account.
+func (c *UserServiceClient) GetMyAuditLogs(ctx context.Context, userID, email, sessionID string, query dto.SecurityAuditQuery) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodGet, withSecurityAuditQuery("/api/v1/audit/me", query), nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+// GetLoginHistory lists the successful and failed sign-ins on the caller's account.
+func (c *UserServiceClient) GetLoginHistory(ctx context.Context, userID, email, sessionID string, query dto.LoginHistoryQuery) (*types.HTTPResponse, error) {
+	params := url.Values{}
+	if query.Page > 0 {
+		params.Set("page", fmt.Sprintf("%d", query.Page))
+	}
+	if query.PageSize > 0 {
+		params.Set("page_size", fmt.Sprintf("%d", query.PageSize))
+	}
+	for key, value := range map[string]string{
+		"result": query.Result,
+		"from":   query.From,
+		"to":     query.To,
+	} {
+		if value != "" {
+			params.Set(key, value)
+		}
+	}
+	path := "/api/v1/users/me/login-history"
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+	return c.doRequest(ctx, http.MethodGet, path, nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+// GetUserMetrics returns the headline user metrics of the back-office overview page (admin).
+func (c *UserServiceClient) GetUserMetrics(ctx context.Context, userID, email, sessionID string, query dto.UserMetricsQuery) (*types.HTTPResponse, error) {
+	path := "/api/v1/dashboard/users"
+	if query.Days > 0 {
+		path += "?days=" + fmt.Sprintf("%d", query.Days)
+	}
+	return c.doRequest(ctx, http.MethodGet, path, nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+// GetUserAuditLogs lists the security events recorded on another user's account (admin).
+func (c *UserServiceClient) GetUserAuditLogs(ctx context.Context, userID, email, sessionID, targetID string, query dto.SecurityAuditQuery) (*types.HTTPResponse, error) {
+	path := withSecurityAuditQuery("/api/v1/audit/users/"+url.PathEscape(targetID), query)
+	return c.doRequest(ctx, http.MethodGet, path, nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+// ListSecurityAuditLogs searches security events across all accounts (admin).
+func (c *UserServiceClient) ListSecurityAuditLogs(ctx context.Context, userID, email, sessionID string, query dto.SecurityAuditQuery) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodGet, withSecurityAuditQuery("/api/v1/audit/logs", query), nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+// RequestDataExport starts an export of the caller's data, or returns the one in progress.
+func (c *UserServiceClient) RequestDataExport(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/data-exports", nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) ListDataExports(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodGet, "/api/v1/data-exports", nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) GetDataExport(ctx context.Context, userID, email, sessionID, exportID string) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodGet, "/api/v1/data-exports/"+url.PathEscape(exportID), nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+// DownloadDataExport fetches a finished export's zip archive; the body is the archive
+// and the headers carry its content type and file name.
+func (c *UserServiceClient) DownloadDataExport(ctx context.Context, userID, email, sessionID, exportID string) (*types.HTTPResponse, error) {
+	path := "/api/v1/data-exports/" + url.PathEscape(exportID) + "/download"
+	return c.doRequest(ctx, http.MethodGet, path, nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+func withSecurityAuditQuery(path string, query dto.SecurityAuditQuery) string {
+	params := url.Values{}
+	if query.Page > 0 {
+		params.Set("page", fmt.Sprintf("%d", query.Page))
+	}
+	if query.PageSize > 0 {
+		params.Set("page_size", fmt.Sprintf("%d", query.PageSize))
+	}
+	for key, value := range map[string]string{
+		"action":   query.Action,
+		"user_id":  query.UserID,
+		"actor_id": query.ActorID,
+		"from":     query.From,
+		"to":       query.To,
+	} {
+		if value != "" {
+			params.Set(key, value)
+		}
+	}
+	if len(params) == 0 {
+		return path
+	}
+	return path + "?" + params.Encode()
+}
+
+// RequestErasure deletes the caller's account and schedules the erasure of their data
+// once the retention window ends.
+func (c *UserServiceClient) RequestErasure(ctx context.Context, userID, email, sessionID string, payload dto.ErasureSelfRequest) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/erasure/me", payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+// DeactivateAccount deactivates the caller's account. It is erased once the cooling-off
+// period ends unless the user signs in again before then.
+func (c *UserServiceClient) DeactivateAccount(ctx context.Context, userID, email, sessionID string, payload dto.DeactivateAccountRequest) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/erasure/me/deactivate", payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+// ScheduleUserErasure schedules the erasure of another user's account (admin).
+func (c *UserServiceClient) ScheduleUserErasure(ctx context.Context, userID, email, sessionID, targetID string, payload dto.ErasureAdminRequest) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/erasure/users/"+url.PathEscape(targetID), payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+// ListErasures lists erasure requests with their completion reports (admin).
+func (c *UserServiceClient) ListErasures(ctx context.Context, userID, email, sessionID string, query dto.ErasureQuery) (*types.HTTPResponse, error) {
+	params := url.Values{}
+	if query.Page > 0 {
+		params.Set("page", fmt.Sprintf("%d", query.Page))
+	}
+	if query.PageSize > 0 {
+		params.Set("page_size", fmt.Sprintf("%d", query.PageSize))
+	}
+	if query.Kind != "" {
+		params.Set("kind", query.Kind)
+	}
+	if query.Status != "" {
+		params.Set("status", query.Status)
+	}
+	if query.UserID != "" {
+		params.Set("user_id", query.UserID)
+	}
+	path := "/api/v1/erasure/requests"
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+	return c.doRequest(ctx, http.MethodGet, path, nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) GetErasure(ctx context.Context, userID, email, sessionID, erasureID string) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodGet, "/api/v1/erasure/requests/"+url.PathEscape(erasureID), nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+// RecoverAccount restores an account the user deleted themselves while its erasure is
+// still pending. The user is signed out, so the request is public.
+func (c *UserServiceClient) RecoverAccount(ctx context.Context, payload dto.ErasureRecoverRequest, clientIP string) (*types.HTTPResponse, error) {
+	headers := http.Header{}
+	if clientIP != "" {
+		headers.Set("X-Forwarded-For", clientIP)
+	}
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/erasure/recover", payload, headers)
+}
+
+// ImportUsers creates invited accounts in bulk from a JSON list (admin).
+func (c *UserServiceClient) ImportUsers(ctx context.Context, userID, email, sessionID string, payload dto.UserImportRequest) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/users/import", payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+// ImportUsersCSV creates invited accounts in bulk from a CSV file, with the import's
+// defaults taken from options (admin).
+func (c *UserServiceClient) ImportUsersCSV(ctx context.Context, userID, email, sessionID string, options dto.UserImportRequest, csv []byte) (*types.HTTPResponse, error) {
+	params := url.Values{}
+	if options.Organization != "" {
+		params.Set("organization", options.Organization)
+	}
+	if options.Role != "" {
+		params.Set("role", options.Role)
+	}
+	if options.SendInvites != nil {
+		params.Set("send_invites", strconv.FormatBool(*options.SendInvites))
+	}
+	path := "/api/v1/users/import"
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+	return c.doRawRequest(ctx, http.MethodPost, path, "text/csv", csv, internalAuthHeaders(userID, email, sessionID))
+}
+
+// CreateOrganization creates an organization, optionally with its first owner (admin).
+func (c *UserServiceClient) CreateOrganization(ctx context.Context, userID, email, sessionID string, payload dto.CreateOrganizationRequest) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/organizations", payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+// ListOrganizations lists organizations with their seat usage (admin).
+func (c *UserServiceClient) ListOrganizations(ctx context.Context, userID, email, sessionID string, query dto.OrganizationQuery) (*types.HTTPResponse, error) {
+	params := url.Values{}
+	if query.Page > 0 {
+		params.Set("page", fmt.Sprintf("%d", query.Page))
+	}
+	if query.PageSize > 0 {
+		params.Set("page_size", fmt.Sprintf("%d", query.PageSize))
+	}
+	if query.Search != "" {
+		params.Set("search", query.Search)
+	}
+	path := "/api/v1/organizations"
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+	return c.doRequest(ctx, http.MethodGet, path, nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+// UpdateOrganization renames an organization or changes its seat limit (admin).
+func (c *UserServiceClient) UpdateOrganization(ctx context.Context, userID, email, sessionID, orgID string, payload dto.UpdateOrganizationRequest) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPatch, "/api/v1/organizations/"+url.PathEscape(orgID), payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+// GetOrganization returns an organization the caller belongs to, with its seat usage.
+func (c *UserServiceClient) GetOrganization(ctx context.Context, userID, email, sessionID, orgID string) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodGet, "/api/v1/organizations/"+url.PathEscape(orgID), nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+// ListOrganizationMembers lists an organization's members; user-service restricts it to
+// owners, managers and admins.
+func (c *UserServiceClient) ListOrganizationMembers(ctx context.Context, userID, email, sessionID, orgID string, query dto.OrganizationMemberQuery) (*types.HTTPResponse, error) {
+	params := url.Values{}
+	if query.Page > 0 {
+		params.Set("page", fmt.Sprintf("%d", query.Page))
+	}
+	if query.PageSize > 0 {
+		params.Set("page_size", fmt.Sprintf("%d", query.PageSize))
+	}
+	if query.Role != "" {
+		params.Set("role", query.Role)
+	}
+	path := "/api/v1/organizations/" + url.PathEscape(orgID) + "/members"
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+	return c.doRequest(ctx, http.MethodGet, path, nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) AddOrganizationMember(ctx context.Context, userID, email, sessionID, orgID string, payload dto.AddOrganizationMemberRequest) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/organizations/"+url.PathEscape(orgID)+"/members", payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) UpdateOrganizationMember(ctx context.Context, userID, email, sessionID, orgID, memberID string, payload dto.UpdateOrganizationMemberRequest) (*types.HTTPResponse, error) {
+	path := "/api/v1/organizations/" + url.PathEscape(orgID) + "/members/" + url.PathEscape(memberID)
+	return c.doRequest(ctx, http.MethodPatch, path, payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) RemoveOrganizationMember(ctx context.Context, userID, email, sessionID, orgID, memberID string) (*types.HTTPResponse, error) {
+	path := "/api/v1/organizations/" + url.PathEscape(orgID) + "/members/" + url.PathEscape(memberID)
+	return c.doRequest(ctx, http.MethodDelete, path, nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+// ListMyOrganizations lists the organizations the caller belongs to.
+func (c *UserServiceClient) ListMyOrganizations(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodGet, "/api/v1/users/me/organizations", nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+// CreateInvitation invites someone to register; user-service allows admins, and
+// organization owners and managers for their organization.
+func (c *UserServiceClient) CreateInvitation(ctx context.Context, userID, email, sessionID string, payload dto.CreateInvitationRequest) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/invitations", payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+// ListInvitations lists invitations; callers other than admins must filter by an
+// organization they manage.
+func (c *UserServiceClient) ListInvitations(ctx context.Context, userID, email, sessionID string, query dto.InvitationQuery) (*types.HTTPResponse, error) {
+	params := url.Values{}
+	if query.Page > 0 {
+		params.Set("page", fmt.Sprintf("%d", query.Page))
+	}
+	if query.PageSize > 0 {
+		params.Set("page_size", fmt.Sprintf("%d", query.PageSize))
+	}
+	if query.OrganizationID != "" {
+		params.Set("organization_id", query.OrganizationID)
+	}
+	if query.Status != "" {
+		params.Set("status", query.Status)
+	}
+	if query.Email != "" {
+		params.Set("email", query.Email)
+	}
+	path := "/api/v1/invitations"
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+	return c.doRequest(ctx, http.MethodGet, path, nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) ResendInvitation(ctx context.Context, userID, email, sessionID, invitationID string) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/invitations/"+url.PathEscape(invitationID)+"/resend", nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) RevokeInvitation(ctx context.Context, userID, email, sessionID, invitationID string) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodDelete, "/api/v1/invitations/"+url.PathEscape(invitationID), nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+// PreviewInvitation describes the invitation behind an emailed link.
+func (c *UserServiceClient) PreviewInvitation(ctx context.Context, token, clientIP string) (*types.HTTPResponse, error) {
+	headers := http.Header{}
+	if clientIP != "" {
+		headers.Set("X-Forwarded-For", clientIP)
+	}
+	return c.doRequest(ctx, http.MethodGet, "/api/v1/invitations/preview?token="+url.QueryEscape(token), nil, headers)
+}
+
+// AcceptInvitation completes an invitee's registration.
+func (c *UserServiceClient) AcceptInvitation(ctx context.Context, payload dto.AcceptInvitationRequest, clientIP string) (*types.HTTPResponse, error) {
+	headers := http.Header{}
+	if clientIP != "" {
+		headers.Set("X-Forwarded-For", clientIP)
+	}
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/invitations/accept", payload, headers)
+}
+
+// CreateAccessToken creates a personal access token; the response carries the token
+// itself, which is never shown again.
+func (c *UserServiceClient) CreateAccessToken(ctx context.Context, userID, email, sessionID string, payload dto.CreateAccessTokenRequest) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/users/me/access-tokens", payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) ListAccessTokens(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodGet, "/api/v1/users/me/access-tokens", nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) RevokeAccessToken(ctx context.Context, userID, email, sessionID, tokenID string) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodDelete, "/api/v1/users/me/access-tokens/"+url.PathEscape(tokenID), nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) IntrospectAccessToken(ctx context.Context, token, clientIP string) (*types.HTTPResponse, error) {
+	payload := map[string]string{"token": token}
+	if clientIP != "" {
+		payload["ip"] = clientIP
+	}
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/internal/access-tokens/introspect", payload, nil)
+}
+
+// RegisterPushToken registers or refreshes the device token of an app install; user-service
+// answers 201 for a new token and 200 for a known one.
+func (c *UserServiceClient) RegisterPushToken(ctx context.Context, userID, email, sessionID string, payload dto.RegisterPushTokenRequest) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/users/me/push-tokens", payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) ListPushTokens(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodGet, "/api/v1/users/me/push-tokens", nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) UnregisterPushToken(ctx context.Context, userID, email, sessionID, tokenID string) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodDelete, "/api/v1/users/me/push-tokens/"+url.PathEscape(tokenID), nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+// UnregisterPushTokenByValue removes a device token by its value, succeeding when it was
+// not registered.
+func (c *UserServiceClient) UnregisterPushTokenByValue(ctx context.Context, userID, email, sessionID string, payload dto.UnregisterPushTokenRequest) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/users/me/push-tokens/unregister", payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+// ListPolicies returns the current version of the terms of service and privacy policy.
+func (c *UserServiceClient) ListPolicies(ctx context.Context) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodGet, "/api/v1/policies", nil, nil)
+}
+
+// ListPolicyVersions lists every published version of a policy document (admin).
+func (c *UserServiceClient) ListPolicyVersions(ctx context.Context, userID, email, sessionID, document string) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodGet, "/api/v1/policies/"+url.PathEscape(document)+"/versions", nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+// PublishPolicy publishes a new version of a policy document (admin).
+func (c *UserServiceClient) PublishPolicy(ctx context.Context, userID, email, sessionID string, payload dto.PublishPolicyRequest) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/policies", payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+// GetConsents returns which policy versions the caller accepted and which still need
+// their consent.
+func (c *UserServiceClient) GetConsents(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodGet, "/api/v1/users/me/consents", nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+// RecordConsent records the caller accepting the current version of policy documents;
+// user-service updates their sessions so the BFF lets them through again.
+func (c *UserServiceClient) RecordConsent(ctx context.Context, userID, email, sessionID string, payload dto.RecordConsentRequest) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/users/me/consents", payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+// RequestAccountMerge starts merging the account registered with another email into
+// the caller's; user-service emails a code to both addresses.
+func (c *UserServiceClient) RequestAccountMerge(ctx context.Context, userID, email, sessionID string, payload dto.RequestAccountMergeRequest) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/users/me/merges", payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) GetAccountMerge(ctx context.Context, userID, email, sessionID, mergeID string) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodGet, "/api/v1/users/me/merges/"+url.PathEscape(mergeID), nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) ConfirmAccountMerge(ctx context.Context, userID, email, sessionID, mergeID string, payload dto.ConfirmAccountMergeRequest) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/users/me/merges/"+url.PathEscape(mergeID)+"/confirm", payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) CancelAccountMerge(ctx context.Context, userID, email, sessionID, mergeID string) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodDelete, "/api/v1/users/me/merges/"+url.PathEscape(mergeID), nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+// StartImpersonation opens a time-boxed session as another user for support (admin).
+func (c *UserServiceClient) StartImpersonation(ctx context.Context, userID, email, sessionID, targetID string, payload dto.StartImpersonationRequest) (*types.HTTPResponse, error) {
+	body := map[string]any{"user_id": targetID, "reason": payload.Reason}
+	if payload.DurationMinutes > 0 {
+		body["duration_minutes"] = payload.DurationMinutes
+	}
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/impersonations", body, internalAuthHeaders(userID, email, sessionID))
+}
+
+// EndImpersonation ends an impersonation session before it expires (admin).
+func (c *UserServiceClient) EndImpersonation(ctx context.Context, userID, email, sessionID, impersonationID string) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodDelete, "/api/v1/impersonations/"+url.PathEscape(impersonationID), nil, internalAuthHeaders(userID, email, sessionID))
+}
+
 func (c *UserServiceClient) GetSessions(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error) {
 	return c.doRequest(ctx, http.MethodGet, "/api/v1/sessions", nil, internalAuthHeaders(userID, email, sessionID))
 }
@@ -138,23 +762,38 @@ func (c *UserServiceClient) ListSessionsByUserID(ctx context.Context, targetUser
 	return c.doRequest(ctx, http.MethodPost, "/api/v1/sessions/user/"+targetUserID, nil, internalAuthHeaders(userID, email, sessionID))
 }
 
-func (c *UserServiceClient) GetUsers(ctx context.Context, page, pageSize, status, search, userID, email, sessionID string) (*types.HTTPResponse, error) {
-	path := "/api/v1/users"
-	query := url.Values{}
-	if page != "" {
-		query.Add("page", page)
-	}
-	if pageSize != "" {
-		query.Add("page_size", pageSize)
+// GetUsers lists users for the admin console. Only the filters set in query are sent.
+func (c *UserServiceClient) GetUsers(ctx context.Context, query dto.AdminUserQuery, userID, email, sessionID string) (*types.HTTPResponse, error) {
+	params := url.Values{}
+	if query.Page > 0 {
+		params.Set("page", fmt.Sprintf("%d", query.Page))
 	}
-	if status != "" {
-		query.Add("status", status)
+	if query.PageSize > 0 {
+		params.Set("page_size", fmt.Sprintf("%d", query.PageSize))
 	}
-	if search != "" {
-		query.Add("search", search)
+	for key, value := range map[string]string{
+		"status":          query.Status,
+		"role":            query.Role,
+		"search":          query.Search,
+		"email_verified":  query.EmailVerified,
+		"mfa_enabled":     query.MFAEnabled,
+		"locked":          query.Locked,
+		"created_from":    query.CreatedFrom,
+		"created_to":      query.CreatedTo,
+		"last_login_from": query.LastLoginFrom,
+		"last_login_to":   query.LastLoginTo,
+		"sort":            query.Sort,
+		"order":           query.Order,
+		"cursor":          query.Cursor,
+	} {
+		if value != "" {
+			params.Set(key, value)
+		}
 	}
-	if len(query) > 0 {
-		path += "?" + query.Encode()
+
+	path := "/api/v1/users"
+	if len(params) > 0 {
+		path += "?" + params.Encode()
 	}
 	return c.doRequest(ctx, http.MethodGet, path, nil, internalAuthHeaders(userID, email, sessionID))
 }
@@ -164,6 +803,10 @@ func (c *UserServiceClient) GetUserById(ctx context.Context, userID, email, sess
 	return c.doRequest(ctx, http.MethodGet, path, nil, internalAuthHeaders(userID, email, sessionID))
 }
 
+func (c *UserServiceClient) BatchGetUsers(ctx context.Context, ids []string) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/internal/users/batch", batch.Request{IDs: ids}, nil)
+}
+
 func (c *UserServiceClient) GetProfileWithContext(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error) {
 	return c.doRequest(ctx, http.MethodGet, "/api/v1/users/profile", nil, internalAuthHeaders(userID, email, sessionID))
 }
@@ -172,6 +815,83 @@ func (c *UserServiceClient) UpdateProfileWithContext(ctx context.Context, userID
 	return c.doRequest(ctx, http.MethodPut, "/api/v1/users/profile", payload, internalAuthHeaders(userID, email, sessionID))
 }
 
+func (c *UserServiceClient) GetPreferences(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodGet, "/api/v1/users/me/preferences", nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) UpdatePreferences(ctx context.Context, userID, email, sessionID string, payload dto.UpdatePreferencesRequest) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPatch, "/api/v1/users/me/preferences", payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+// GetOnboarding returns the caller's progress through the first-run steps.
+func (c *UserServiceClient) GetOnboarding(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodGet, "/api/v1/users/me/onboarding", nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+// AdvanceOnboarding completes or skips one of the caller's onboarding steps.
+func (c *UserServiceClient) AdvanceOnboarding(ctx context.Context, userID, email, sessionID, step string, payload dto.AdvanceOnboardingRequest) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/users/me/onboarding/steps/"+url.PathEscape(step), payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+// CheckUsernameAvailability tells whether a username is valid and free, for sign-up and
+// profile forms.
+func (c *UserServiceClient) CheckUsernameAvailability(ctx context.Context, username, clientIP string) (*types.HTTPResponse, error) {
+	headers := http.Header{}
+	if clientIP != "" {
+		headers.Set("X-Forwarded-For", clientIP)
+	}
+	return c.doRequest(ctx, http.MethodGet, "/api/v1/users/identifiers/username/availability?username="+url.QueryEscape(username), nil, headers)
+}
+
+// GetLoginIdentifiers returns the caller's email, username and masked phone number.
+func (c *UserServiceClient) GetLoginIdentifiers(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodGet, "/api/v1/users/me/identifiers", nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) SetUsername(ctx context.Context, userID, email, sessionID string, payload dto.SetUsernameRequest) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPut, "/api/v1/users/me/identifiers/username", payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) RemoveUsername(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodDelete, "/api/v1/users/me/identifiers/username", nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+// CheckPhoneAvailability tells whether the caller can sign in with a phone number.
+func (c *UserServiceClient) CheckPhoneAvailability(ctx context.Context, userID, email, sessionID, phone string) (*types.HTTPResponse, error) {
+	path := "/api/v1/users/me/identifiers/phone/availability?phone_number=" + url.QueryEscape(phone)
+	return c.doRequest(ctx, http.MethodGet, path, nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+// StartPhoneVerification has user-service send a code to the phone number the caller
+// wants to sign in with.
+func (c *UserServiceClient) StartPhoneVerification(ctx context.Context, userID, email, sessionID string, payload dto.PhoneVerificationRequest) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/users/me/identifiers/phone", payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) VerifyPhone(ctx context.Context, userID, email, sessionID string, payload dto.VerifyPhoneRequest) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/users/me/identifiers/phone/verify", payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) RemovePhone(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodDelete, "/api/v1/users/me/identifiers/phone", nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+// CreateAvatarUpload returns a presigned URL the caller uploads their new avatar to.
+func (c *UserServiceClient) CreateAvatarUpload(ctx context.Context, userID, email, sessionID string, payload dto.CreateAvatarUploadRequest) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodPost, "/api/v1/users/me/avatar/uploads", payload, internalAuthHeaders(userID, email, sessionID))
+}
+
+// CompleteAvatarUpload has user-service process an uploaded image and set it as the
+// caller's avatar.
+func (c *UserServiceClient) CompleteAvatarUpload(ctx context.Context, userID, email, sessionID, uploadID string) (*types.HTTPResponse, error) {
+	path := "/api/v1/users/me/avatar/uploads/" + url.PathEscape(uploadID) + "/complete"
+	return c.doRequest(ctx, http.MethodPost, path, nil, internalAuthHeaders(userID, email, sessionID))
+}
+
+func (c *UserServiceClient) RemoveAvatar(ctx context.Context, userID, email, sessionID string) (*types.HTTPResponse, error) {
+	return c.doRequest(ctx, http.MethodDelete, "/api/v1/users/me/avatar", nil, internalAuthHeaders(userID, email, sessionID))
+}
+
 func (c *UserServiceClient) UpdateUserRoleWithContext(ctx context.Context, userID, email, sessionID string, targetID string, payload dto.UpdateUserRoleRequest) (*types.HTTPResponse, error) {
 	return c.doRequest(ctx, http.MethodPut, fmt.Sprintf("/api/v1/users/%s/role", targetID), payload, internalAuthHeaders(userID, email, sessionID))
 }
@@ -242,6 +962,36 @@ func appendReason(path, reason string) string {
 	return path + sep + "reason=" + url.QueryEscape(reason)
 }
 
+// doRequest forwards the end user's IP and user agent with every call, unless the method
+// already set them, and the administrator behind an impersonation session, so
+// user-service can record them in its audit log.
 func (c *UserServiceClient) doRequest(ctx context.Context, method, path string, payload interface{}, headers http.Header) (*types.HTTPResponse, error) {
-	return doRequest(ctx, c.baseURL, method, path, c.httpClient, payload, headers)
+	return doRequest(ctx, c.baseURL, method, path, c.httpClient, payload, withClientHeaders(ctx, headers))
+}
+
+// doRawRequest is doRequest for a body that is not JSON.
+func (c *UserServiceClient) doRawRequest(ctx context.Context, method, path, contentType string, body []byte, headers http.Header) (*types.HTTPResponse, error) {
+	return doRawRequest(ctx, c.baseURL, method, path, c.httpClient, contentType, body, withClientHeaders(ctx, headers))
+}
+
+func withClientHeaders(ctx context.Context, headers http.Header) http.Header {
+	client := tracing.ClientFromContext(ctx)
+	if client.IP != "" || client.UserAgent != "" {
+		if headers == nil {
+			headers = http.Header{}
+		}
+		if client.IP != "" && headers.Get("X-Forwarded-For") == "" {
+			headers.Set("X-Forwarded-For", client.IP)
+		}
+		if client.UserAgent != "" && headers.Get("User-Agent") == "" {
+			headers.Set("User-Agent", client.UserAgent)
+		}
+	}
+	if impersonatorID := tracing.ImpersonatorFromContext(ctx); impersonatorID != "" {
+		if headers == nil {
+			headers = http.Header{}
+		}
+		headers.Set("X-Impersonator-ID", impersonatorID)
+	}
+	return headers
 }
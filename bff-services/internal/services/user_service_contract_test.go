@@ -0,0 +1,1179 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"bff-services/internal/api/dto"
+	"bff-services/internal/tracing"
+	"bff-services/internal/types"
+)
+
+func TestUserServiceContract(t *testing.T) {
+	stub := newStubService(t)
+	client := NewUserServiceClient(stub.URL()+"/", nil)
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	cases := []contractCase{
+		{
+			name: "Register",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.Register(ctx, dto.RegisterRequest{Email: stubEmail, Name: "Learner", Password: "s3cret-pass"}, "203.0.113.7")
+			},
+			method:       http.MethodPost,
+			path:         "/api/v1/users/register",
+			headers:      map[string]string{"Content-Type": "application/json", "X-Forwarded-For": "203.0.113.7"},
+			bodyContains: []string{`"email":"learner@example.com"`, `"name":"Learner"`},
+		},
+		{
+			name: "Login",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.Login(ctx, dto.LoginRequest{Email: stubEmail, Password: "s3cret-pass", MFACode: "123456"}, "Mozilla/5.0", "203.0.113.7")
+			},
+			method:       http.MethodPost,
+			path:         "/api/v1/users/login",
+			headers:      map[string]string{"User-Agent": "Mozilla/5.0", "X-Forwarded-For": "203.0.113.7"},
+			bodyContains: []string{`"mfa_code":"123456"`},
+		},
+		{
+			name: "LoginWithIdentifier",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.Login(ctx, dto.LoginRequest{Identifier: "learner_01", Password: "s3cret-pass"}, "Mozilla/5.0", "203.0.113.7")
+			},
+			method:       http.MethodPost,
+			path:         "/api/v1/users/login",
+			headers:      map[string]string{"User-Agent": "Mozilla/5.0", "X-Forwarded-For": "203.0.113.7"},
+			bodyContains: []string{`"identifier":"learner_01"`},
+		},
+		{
+			name: "RequestPasswordlessLogin",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.RequestPasswordlessLogin(ctx, dto.PasswordlessLoginRequest{Email: stubEmail, Method: "code", BindDevice: true}, "203.0.113.7")
+			},
+			method:       http.MethodPost,
+			path:         "/api/v1/users/login/passwordless",
+			headers:      map[string]string{"X-Forwarded-For": "203.0.113.7"},
+			bodyContains: []string{`"method":"code"`, `"bind_device":true`},
+		},
+		{
+			name: "VerifyPasswordlessLogin",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.VerifyPasswordlessLogin(ctx, dto.PasswordlessVerifyRequest{Token: "link-1", DeviceToken: "device-1"}, "Mozilla/5.0", "203.0.113.7")
+			},
+			method:       http.MethodPost,
+			path:         "/api/v1/users/login/passwordless/verify",
+			headers:      map[string]string{"User-Agent": "Mozilla/5.0", "X-Forwarded-For": "203.0.113.7"},
+			bodyContains: []string{`"token":"link-1"`, `"device_token":"device-1"`},
+		},
+		{
+			name: "Logout",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.Logout(ctx, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/users/logout",
+			authenticated: true,
+		},
+		{
+			name: "RefreshToken",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.RefreshToken(ctx, dto.RefreshTokenRequest{RefreshToken: "rt-1"}, "203.0.113.7")
+			},
+			method:       http.MethodPost,
+			path:         "/api/v1/auth/refresh",
+			headers:      map[string]string{"X-Forwarded-For": "203.0.113.7"},
+			bodyContains: []string{`"refresh_token":"rt-1"`},
+		},
+		{
+			name: "RequestAccountUnlock",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.RequestAccountUnlock(ctx, dto.AccountUnlockRequest{Email: stubEmail}, "203.0.113.7")
+			},
+			method:       http.MethodPost,
+			path:         "/api/v1/users/unlock/request",
+			headers:      map[string]string{"X-Forwarded-For": "203.0.113.7"},
+			bodyContains: []string{`"email":"` + stubEmail + `"`},
+		},
+		{
+			name: "ConfirmAccountUnlock",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.ConfirmAccountUnlock(ctx, dto.AccountUnlockConfirmRequest{Token: "unlock-1"}, "203.0.113.7")
+			},
+			method:       http.MethodPost,
+			path:         "/api/v1/users/unlock/confirm",
+			headers:      map[string]string{"X-Forwarded-For": "203.0.113.7"},
+			bodyContains: []string{`"token":"unlock-1"`},
+		},
+		{
+			name: "VerifyEmail",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.VerifyEmail(ctx, "tok en/1")
+			},
+			method: http.MethodGet,
+			path:   "/api/v1/users/verify-email",
+			query:  "token=tok+en%2F1",
+		},
+		{
+			name: "RequestPasswordReset",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.RequestPasswordReset(ctx, dto.PasswordResetRequest{Email: stubEmail}, "203.0.113.7")
+			},
+			method:       http.MethodPost,
+			path:         "/api/v1/users/password/reset/request",
+			headers:      map[string]string{"X-Forwarded-For": "203.0.113.7"},
+			bodyContains: []string{`"email":"learner@example.com"`},
+		},
+		{
+			name: "ConfirmPasswordReset",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.ConfirmPasswordReset(ctx, dto.PasswordResetConfirmRequest{Token: "reset-token", NewPassword: "n3w-password"})
+			},
+			method:       http.MethodPost,
+			path:         "/api/v1/users/password/reset/confirm",
+			bodyContains: []string{`"token":"reset-token"`, `"new_password":"n3w-password"`},
+		},
+		{
+			name: "ChangePassword",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.ChangePassword(ctx, stubUserID, stubEmail, stubSessionID, dto.ChangePasswordRequest{OldPassword: "old-password", NewPassword: "n3w-password"})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/users/password/change",
+			bodyContains:  []string{`"old_password":"old-password"`},
+			authenticated: true,
+		},
+		{
+			name: "SetupMFA",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.SetupMFA(ctx, stubUserID, stubEmail, stubSessionID, dto.MFASetupRequest{Type: "totp", Label: "phone"})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/mfa/setup",
+			bodyContains:  []string{`"type":"totp"`},
+			authenticated: true,
+		},
+		{
+			name: "VerifyMFA",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.VerifyMFA(ctx, stubUserID, stubEmail, stubSessionID, dto.MFAVerifyRequest{MethodID: "m-1", Code: "123456"})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/mfa/verify",
+			bodyContains:  []string{`"method_id":"m-1"`},
+			authenticated: true,
+		},
+		{
+			name: "DisableMFA",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.DisableMFA(ctx, stubUserID, stubEmail, stubSessionID, dto.MFADisableRequest{MethodID: "m-1", Password: "s3cret-pass"})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/mfa/disable",
+			authenticated: true,
+		},
+		{
+			name: "GetMFAMethods",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetMFAMethods(ctx, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/mfa/methods",
+			authenticated: true,
+		},
+		{
+			name: "BeginPasskeyLogin",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.BeginPasskeyLogin(ctx, dto.PasskeyLoginBeginRequest{Email: stubEmail}, "203.0.113.7")
+			},
+			method:       http.MethodPost,
+			path:         "/api/v1/users/login/webauthn/begin",
+			headers:      map[string]string{"X-Forwarded-For": "203.0.113.7"},
+			bodyContains: []string{`"email":"learner@example.com"`},
+		},
+		{
+			name: "FinishPasskeyLogin",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.FinishPasskeyLogin(ctx, dto.WebAuthnAssertion{ChallengeID: "c-1", Credential: []byte(`{"id":"cred-1","type":"public-key"}`)}, "Mozilla/5.0", "203.0.113.7")
+			},
+			method:       http.MethodPost,
+			path:         "/api/v1/users/login/webauthn/finish",
+			headers:      map[string]string{"User-Agent": "Mozilla/5.0", "X-Forwarded-For": "203.0.113.7"},
+			bodyContains: []string{`"challenge_id":"c-1"`, `"credential":{"id":"cred-1","type":"public-key"}`},
+		},
+		{
+			name: "BeginPasskeyRegistration",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.BeginPasskeyRegistration(ctx, stubUserID, stubEmail, stubSessionID, dto.PasskeyRegistrationBeginRequest{Label: "Work laptop"})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/mfa/webauthn/register/begin",
+			bodyContains:  []string{`"label":"Work laptop"`},
+			authenticated: true,
+		},
+		{
+			name: "FinishPasskeyRegistration",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.FinishPasskeyRegistration(ctx, stubUserID, stubEmail, stubSessionID, dto.PasskeyRegistrationFinishRequest{ChallengeID: "c-1", Credential: []byte(`{"id":"cred-1"}`)})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/mfa/webauthn/register/finish",
+			bodyContains:  []string{`"challenge_id":"c-1"`, `"credential":{"id":"cred-1"}`},
+			authenticated: true,
+		},
+		{
+			name: "ListPasskeys",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.ListPasskeys(ctx, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/mfa/webauthn/credentials",
+			authenticated: true,
+		},
+		{
+			name: "RenamePasskey",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.RenamePasskey(ctx, stubUserID, stubEmail, stubSessionID, "pk-1", dto.PasskeyRenameRequest{Label: "Phone"})
+			},
+			method:        http.MethodPatch,
+			path:          "/api/v1/mfa/webauthn/credentials/pk-1",
+			bodyContains:  []string{`"label":"Phone"`},
+			authenticated: true,
+		},
+		{
+			name: "RevokePasskey",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.RevokePasskey(ctx, stubUserID, stubEmail, stubSessionID, "pk-1", dto.PasskeyRevokeRequest{Password: "s3cret-pass"})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/mfa/webauthn/credentials/pk-1/revoke",
+			authenticated: true,
+		},
+		{
+			name: "SendLoginOTP",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.SendLoginOTP(ctx, dto.LoginOTPSendRequest{Email: stubEmail, Password: "s3cret-pass", Channel: "voice"}, "203.0.113.7")
+			},
+			method:       http.MethodPost,
+			path:         "/api/v1/users/login/otp/send",
+			headers:      map[string]string{"X-Forwarded-For": "203.0.113.7"},
+			bodyContains: []string{`"email":"learner@example.com"`, `"channel":"voice"`},
+		},
+		{
+			name: "SetupPhoneMFA",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.SetupPhoneMFA(ctx, stubUserID, stubEmail, stubSessionID, dto.PhoneMFASetupRequest{PhoneNumber: "+84901234567", Channel: "sms"})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/mfa/phone",
+			bodyContains:  []string{`"phone_number":"+84901234567"`, `"channel":"sms"`},
+			authenticated: true,
+		},
+		{
+			name: "ResendPhoneCode",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.ResendPhoneCode(ctx, stubUserID, stubEmail, stubSessionID, "ph-1", dto.OTPSendRequest{Channel: "voice"})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/mfa/phone/ph-1/send",
+			bodyContains:  []string{`"channel":"voice"`},
+			authenticated: true,
+		},
+		{
+			name: "ConfirmPhoneMFA",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.ConfirmPhoneMFA(ctx, stubUserID, stubEmail, stubSessionID, "ph-1", dto.PhoneMFAConfirmRequest{Code: "123456"})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/mfa/phone/ph-1/verify",
+			bodyContains:  []string{`"code":"123456"`},
+			authenticated: true,
+		},
+		{
+			name: "RevokePhoneMFA",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.RevokePhoneMFA(ctx, stubUserID, stubEmail, stubSessionID, "ph-1", dto.PhoneMFARevokeRequest{Password: "s3cret-pass"})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/mfa/phone/ph-1/revoke",
+			authenticated: true,
+		},
+		{
+			name: "ReorderMFAMethods",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.ReorderMFAMethods(ctx, stubUserID, stubEmail, stubSessionID, dto.MFAOrderRequest{MethodIDs: []string{"m-2", "m-1"}})
+			},
+			method:        http.MethodPut,
+			path:          "/api/v1/mfa/methods/order",
+			bodyContains:  []string{`"method_ids":["m-2","m-1"]`},
+			authenticated: true,
+		},
+		{
+			name: "GetMyAuditLogs",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				ctx = tracing.WithClient(ctx, tracing.Client{IP: "198.51.100.4", UserAgent: "Mozilla/5.0"})
+				return client.GetMyAuditLogs(ctx, stubUserID, stubEmail, stubSessionID, dto.SecurityAuditQuery{Action: "mfa.*", Page: 2, PageSize: 10})
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/audit/me",
+			query:         "action=mfa.%2A&page=2&page_size=10",
+			headers:       map[string]string{"User-Agent": "Mozilla/5.0", "X-Forwarded-For": "198.51.100.4"},
+			authenticated: true,
+		},
+		{
+			name: "GetLoginHistory",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetLoginHistory(ctx, stubUserID, stubEmail, stubSessionID, dto.LoginHistoryQuery{Result: "failure", Page: 1, PageSize: 20})
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/users/me/login-history",
+			query:         "page=1&page_size=20&result=failure",
+			authenticated: true,
+		},
+		{
+			name: "GetUserMetrics",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetUserMetrics(ctx, stubUserID, stubEmail, stubSessionID, dto.UserMetricsQuery{Days: 7})
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/dashboard/users",
+			query:         "days=7",
+			authenticated: true,
+		},
+		{
+			name: "GetUserAuditLogs",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetUserAuditLogs(ctx, stubUserID, stubEmail, stubSessionID, "user-2", dto.SecurityAuditQuery{From: "2026-01-01T00:00:00Z", Page: 1, PageSize: 20})
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/audit/users/user-2",
+			query:         "from=2026-01-01T00%3A00%3A00Z&page=1&page_size=20",
+			authenticated: true,
+		},
+		{
+			name: "ListSecurityAuditLogs",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.ListSecurityAuditLogs(ctx, stubUserID, stubEmail, stubSessionID, dto.SecurityAuditQuery{Action: "user.role_changed", ActorID: "actor-1", UserID: "user-2"})
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/audit/logs",
+			query:         "action=user.role_changed&actor_id=actor-1&user_id=user-2",
+			authenticated: true,
+		},
+		{
+			name: "RequestDataExport",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.RequestDataExport(ctx, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/data-exports",
+			authenticated: true,
+		},
+		{
+			name: "ListDataExports",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.ListDataExports(ctx, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/data-exports",
+			authenticated: true,
+		},
+		{
+			name: "GetDataExport",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetDataExport(ctx, stubUserID, stubEmail, stubSessionID, "export-1")
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/data-exports/export-1",
+			authenticated: true,
+		},
+		{
+			name: "DownloadDataExport",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.DownloadDataExport(ctx, stubUserID, stubEmail, stubSessionID, "export-1")
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/data-exports/export-1/download",
+			authenticated: true,
+		},
+		{
+			name: "GetPreferences",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetPreferences(ctx, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/users/me/preferences",
+			authenticated: true,
+		},
+		{
+			name: "UpdatePreferences",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				theme, email := "dark", false
+				return client.UpdatePreferences(ctx, stubUserID, stubEmail, stubSessionID, dto.UpdatePreferencesRequest{
+					Theme:         &theme,
+					Notifications: &dto.NotificationPreferencesPatch{Email: &email},
+				})
+			},
+			method:        http.MethodPatch,
+			path:          "/api/v1/users/me/preferences",
+			authenticated: true,
+			bodyContains:  []string{`"theme":"dark"`, `"notifications":{"email":false}`},
+		},
+		{
+			name: "GetOnboarding",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetOnboarding(ctx, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/users/me/onboarding",
+			authenticated: true,
+		},
+		{
+			name: "AdvanceOnboarding",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.AdvanceOnboarding(ctx, stubUserID, stubEmail, stubSessionID, "level_test_taken", dto.AdvanceOnboardingRequest{Status: "skipped"})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/users/me/onboarding/steps/level_test_taken",
+			authenticated: true,
+			bodyContains:  []string{`"status":"skipped"`},
+		},
+		{
+			name: "CheckUsernameAvailability",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.CheckUsernameAvailability(ctx, "learner_01", "203.0.113.7")
+			},
+			method:  http.MethodGet,
+			path:    "/api/v1/users/identifiers/username/availability",
+			query:   "username=learner_01",
+			headers: map[string]string{"X-Forwarded-For": "203.0.113.7"},
+		},
+		{
+			name: "GetLoginIdentifiers",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetLoginIdentifiers(ctx, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/users/me/identifiers",
+			authenticated: true,
+		},
+		{
+			name: "SetUsername",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.SetUsername(ctx, stubUserID, stubEmail, stubSessionID, dto.SetUsernameRequest{Username: "learner_01"})
+			},
+			method:        http.MethodPut,
+			path:          "/api/v1/users/me/identifiers/username",
+			authenticated: true,
+			bodyContains:  []string{`"username":"learner_01"`},
+		},
+		{
+			name: "RemoveUsername",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.RemoveUsername(ctx, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodDelete,
+			path:          "/api/v1/users/me/identifiers/username",
+			authenticated: true,
+		},
+		{
+			name: "CheckPhoneAvailability",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.CheckPhoneAvailability(ctx, stubUserID, stubEmail, stubSessionID, "+84901234567")
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/users/me/identifiers/phone/availability",
+			query:         "phone_number=%2B84901234567",
+			authenticated: true,
+		},
+		{
+			name: "StartPhoneVerification",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.StartPhoneVerification(ctx, stubUserID, stubEmail, stubSessionID, dto.PhoneVerificationRequest{PhoneNumber: "+84901234567", Channel: "sms"})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/users/me/identifiers/phone",
+			authenticated: true,
+			bodyContains:  []string{`"phone_number":"+84901234567"`, `"channel":"sms"`},
+		},
+		{
+			name: "VerifyPhone",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.VerifyPhone(ctx, stubUserID, stubEmail, stubSessionID, dto.VerifyPhoneRequest{PhoneNumber: "+84901234567", Code: "123456"})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/users/me/identifiers/phone/verify",
+			authenticated: true,
+			bodyContains:  []string{`"code":"123456"`},
+		},
+		{
+			name: "RemovePhone",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.RemovePhone(ctx, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodDelete,
+			path:          "/api/v1/users/me/identifiers/phone",
+			authenticated: true,
+		},
+		{
+			name: "CreateAvatarUpload",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.CreateAvatarUpload(ctx, stubUserID, stubEmail, stubSessionID, dto.CreateAvatarUploadRequest{
+					ContentType: "image/png",
+					SizeBytes:   2048,
+				})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/users/me/avatar/uploads",
+			authenticated: true,
+			bodyContains:  []string{`"content_type":"image/png"`, `"size_bytes":2048`},
+		},
+		{
+			name: "CompleteAvatarUpload",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.CompleteAvatarUpload(ctx, stubUserID, stubEmail, stubSessionID, "upload-1")
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/users/me/avatar/uploads/upload-1/complete",
+			authenticated: true,
+		},
+		{
+			name: "RemoveAvatar",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.RemoveAvatar(ctx, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodDelete,
+			path:          "/api/v1/users/me/avatar",
+			authenticated: true,
+		},
+		{
+			name: "RequestErasure",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.RequestErasure(ctx, stubUserID, stubEmail, stubSessionID, dto.ErasureSelfRequest{Password: "secret-pw"})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/erasure/me",
+			authenticated: true,
+			bodyContains:  []string{`"password":"secret-pw"`},
+		},
+		{
+			name: "DeactivateAccount",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.DeactivateAccount(ctx, stubUserID, stubEmail, stubSessionID, dto.DeactivateAccountRequest{Password: "secret-pw", Reason: "taking a break"})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/erasure/me/deactivate",
+			authenticated: true,
+			bodyContains:  []string{`"password":"secret-pw"`, `"reason":"taking a break"`},
+		},
+		{
+			name: "ScheduleUserErasure",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.ScheduleUserErasure(ctx, stubUserID, stubEmail, stubSessionID, "user-2", dto.ErasureAdminRequest{Reason: "support ticket"})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/erasure/users/user-2",
+			authenticated: true,
+			bodyContains:  []string{`"reason":"support ticket"`},
+		},
+		{
+			name: "ListErasures",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.ListErasures(ctx, stubUserID, stubEmail, stubSessionID, dto.ErasureQuery{Kind: "deactivation", Status: "incomplete", Page: 2, PageSize: 50})
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/erasure/requests",
+			query:         "kind=deactivation&page=2&page_size=50&status=incomplete",
+			authenticated: true,
+		},
+		{
+			name: "GetErasure",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetErasure(ctx, stubUserID, stubEmail, stubSessionID, "erasure-1")
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/erasure/requests/erasure-1",
+			authenticated: true,
+		},
+		{
+			name: "RecoverAccount",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.RecoverAccount(ctx, dto.ErasureRecoverRequest{Email: stubEmail, Password: "secret-pw"}, "203.0.113.7")
+			},
+			method:       http.MethodPost,
+			path:         "/api/v1/erasure/recover",
+			headers:      map[string]string{"X-Forwarded-For": "203.0.113.7"},
+			bodyContains: []string{`"email":"` + stubEmail + `"`, `"password":"secret-pw"`},
+		},
+		{
+			name: "ImportUsers",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.ImportUsers(ctx, stubUserID, stubEmail, stubSessionID, dto.UserImportRequest{
+					Organization: "Acme School",
+					Users:        []dto.UserImportRow{{Email: "ann@example.com", Name: "Ann", Role: "teacher"}},
+				})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/users/import",
+			authenticated: true,
+			bodyContains:  []string{`"organization":"Acme School"`, `"users":[{"email":"ann@example.com","name":"Ann","role":"teacher"}]`},
+		},
+		{
+			name: "ImportUsersCSV",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				sendInvites := false
+				return client.ImportUsersCSV(ctx, stubUserID, stubEmail, stubSessionID, dto.UserImportRequest{Role: "student", SendInvites: &sendInvites}, []byte("email,name\nann@example.com,Ann\n"))
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/users/import",
+			query:         "role=student&send_invites=false",
+			headers:       map[string]string{"Content-Type": "text/csv"},
+			authenticated: true,
+			bodyContains:  []string{"email,name\nann@example.com,Ann\n"},
+		},
+		{
+			name: "CreateOrganization",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				seats := 30
+				return client.CreateOrganization(ctx, stubUserID, stubEmail, stubSessionID, dto.CreateOrganizationRequest{Name: "Acme School", SeatLimit: &seats, OwnerID: stubUserID})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/organizations",
+			authenticated: true,
+			bodyContains:  []string{`"name":"Acme School"`, `"seat_limit":30`, `"owner_id":"` + stubUserID + `"`},
+		},
+		{
+			name: "ListOrganizations",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.ListOrganizations(ctx, stubUserID, stubEmail, stubSessionID, dto.OrganizationQuery{Search: "acme", Page: 2, PageSize: 10})
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/organizations",
+			query:         "page=2&page_size=10&search=acme",
+			authenticated: true,
+		},
+		{
+			name: "UpdateOrganization",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.UpdateOrganization(ctx, stubUserID, stubEmail, stubSessionID, "org-1", dto.UpdateOrganizationRequest{ClearSeatLimit: true})
+			},
+			method:        http.MethodPatch,
+			path:          "/api/v1/organizations/org-1",
+			authenticated: true,
+			bodyContains:  []string{`"clear_seat_limit":true`},
+		},
+		{
+			name: "GetOrganization",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetOrganization(ctx, stubUserID, stubEmail, stubSessionID, "org-1")
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/organizations/org-1",
+			authenticated: true,
+		},
+		{
+			name: "ListOrganizationMembers",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.ListOrganizationMembers(ctx, stubUserID, stubEmail, stubSessionID, "org-1", dto.OrganizationMemberQuery{Role: "learner", Page: 1, PageSize: 20})
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/organizations/org-1/members",
+			query:         "page=1&page_size=20&role=learner",
+			authenticated: true,
+		},
+		{
+			name: "AddOrganizationMember",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.AddOrganizationMember(ctx, stubUserID, stubEmail, stubSessionID, "org-1", dto.AddOrganizationMemberRequest{Email: "ann@example.com", Role: "learner"})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/organizations/org-1/members",
+			authenticated: true,
+			bodyContains:  []string{`"email":"ann@example.com"`, `"role":"learner"`},
+		},
+		{
+			name: "UpdateOrganizationMember",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.UpdateOrganizationMember(ctx, stubUserID, stubEmail, stubSessionID, "org-1", "user-2", dto.UpdateOrganizationMemberRequest{Role: "manager"})
+			},
+			method:        http.MethodPatch,
+			path:          "/api/v1/organizations/org-1/members/user-2",
+			authenticated: true,
+			bodyContains:  []string{`"role":"manager"`},
+		},
+		{
+			name: "RemoveOrganizationMember",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.RemoveOrganizationMember(ctx, stubUserID, stubEmail, stubSessionID, "org-1", "user-2")
+			},
+			method:        http.MethodDelete,
+			path:          "/api/v1/organizations/org-1/members/user-2",
+			authenticated: true,
+		},
+		{
+			name: "ListMyOrganizations",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.ListMyOrganizations(ctx, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/users/me/organizations",
+			authenticated: true,
+		},
+		{
+			name: "CreateInvitation",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.CreateInvitation(ctx, stubUserID, stubEmail, stubSessionID, dto.CreateInvitationRequest{Email: "invitee@example.com", OrganizationID: "org-1", OrgRole: "learner"})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/invitations",
+			bodyContains:  []string{`"email":"invitee@example.com"`, `"organization_id":"org-1"`, `"org_role":"learner"`},
+			authenticated: true,
+		},
+		{
+			name: "ListInvitations",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.ListInvitations(ctx, stubUserID, stubEmail, stubSessionID, dto.InvitationQuery{OrganizationID: "org-1", Status: "expired", Page: 2, PageSize: 10})
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/invitations",
+			query:         "organization_id=org-1&page=2&page_size=10&status=expired",
+			authenticated: true,
+		},
+		{
+			name: "ResendInvitation",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.ResendInvitation(ctx, stubUserID, stubEmail, stubSessionID, "inv-1")
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/invitations/inv-1/resend",
+			authenticated: true,
+		},
+		{
+			name: "RevokeInvitation",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.RevokeInvitation(ctx, stubUserID, stubEmail, stubSessionID, "inv-1")
+			},
+			method:        http.MethodDelete,
+			path:          "/api/v1/invitations/inv-1",
+			authenticated: true,
+		},
+		{
+			name: "PreviewInvitation",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.PreviewInvitation(ctx, "abc.def", "203.0.113.7")
+			},
+			method:  http.MethodGet,
+			path:    "/api/v1/invitations/preview",
+			query:   "token=abc.def",
+			headers: map[string]string{"X-Forwarded-For": "203.0.113.7"},
+		},
+		{
+			name: "AcceptInvitation",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.AcceptInvitation(ctx, dto.AcceptInvitationRequest{Token: "abc.def", Password: "S3cure-passphrase", Name: "Learner"}, "203.0.113.7")
+			},
+			method:       http.MethodPost,
+			path:         "/api/v1/invitations/accept",
+			headers:      map[string]string{"X-Forwarded-For": "203.0.113.7"},
+			bodyContains: []string{`"token":"abc.def"`, `"name":"Learner"`},
+		},
+		{
+			name: "CreateAccessToken",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.CreateAccessToken(ctx, stubUserID, stubEmail, stubSessionID, dto.CreateAccessTokenRequest{Name: "ci", Scopes: []string{"read"}, ExpiresInDays: 30})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/users/me/access-tokens",
+			bodyContains:  []string{`"name":"ci"`, `"scopes":["read"]`, `"expires_in_days":30`},
+			authenticated: true,
+		},
+		{
+			name: "ListAccessTokens",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.ListAccessTokens(ctx, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/users/me/access-tokens",
+			authenticated: true,
+		},
+		{
+			name: "RevokeAccessToken",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.RevokeAccessToken(ctx, stubUserID, stubEmail, stubSessionID, "pat-1")
+			},
+			method:        http.MethodDelete,
+			path:          "/api/v1/users/me/access-tokens/pat-1",
+			authenticated: true,
+		},
+		{
+			name: "IntrospectAccessToken",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.IntrospectAccessToken(ctx, "uat_abc123", "203.0.113.7")
+			},
+			method:       http.MethodPost,
+			path:         "/api/v1/internal/access-tokens/introspect",
+			bodyContains: []string{`"token":"uat_abc123"`, `"ip":"203.0.113.7"`},
+		},
+		{
+			name: "RegisterPushToken",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.RegisterPushToken(ctx, stubUserID, stubEmail, stubSessionID, dto.RegisterPushTokenRequest{Provider: "fcm", Token: "fcm-token-1", Platform: "android", AppVersion: "3.2.0"})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/users/me/push-tokens",
+			authenticated: true,
+			bodyContains:  []string{`"provider":"fcm"`, `"token":"fcm-token-1"`, `"platform":"android"`, `"app_version":"3.2.0"`},
+		},
+		{
+			name: "ListPushTokens",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.ListPushTokens(ctx, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/users/me/push-tokens",
+			authenticated: true,
+		},
+		{
+			name: "UnregisterPushToken",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.UnregisterPushToken(ctx, stubUserID, stubEmail, stubSessionID, "push-1")
+			},
+			method:        http.MethodDelete,
+			path:          "/api/v1/users/me/push-tokens/push-1",
+			authenticated: true,
+		},
+		{
+			name: "UnregisterPushTokenByValue",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.UnregisterPushTokenByValue(ctx, stubUserID, stubEmail, stubSessionID, dto.UnregisterPushTokenRequest{Provider: "apns", Token: "ab12"})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/users/me/push-tokens/unregister",
+			authenticated: true,
+			bodyContains:  []string{`"provider":"apns"`, `"token":"ab12"`},
+		},
+		{
+			name: "ListPolicies",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.ListPolicies(ctx)
+			},
+			method: http.MethodGet,
+			path:   "/api/v1/policies",
+		},
+		{
+			name: "ListPolicyVersions",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.ListPolicyVersions(ctx, stubUserID, stubEmail, stubSessionID, "terms")
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/policies/terms/versions",
+			authenticated: true,
+		},
+		{
+			name: "PublishPolicy",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				requiresConsent := false
+				return client.PublishPolicy(ctx, stubUserID, stubEmail, stubSessionID, dto.PublishPolicyRequest{Document: "privacy", Version: "2025-02", URL: "https://example.com/privacy", RequiresConsent: &requiresConsent})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/policies",
+			authenticated: true,
+			bodyContains:  []string{`"document":"privacy"`, `"version":"2025-02"`, `"url":"https://example.com/privacy"`, `"requires_consent":false`},
+		},
+		{
+			name: "GetConsents",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetConsents(ctx, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/users/me/consents",
+			authenticated: true,
+		},
+		{
+			name: "RecordConsent",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.RecordConsent(ctx, stubUserID, stubEmail, stubSessionID, dto.RecordConsentRequest{Consents: []dto.ConsentItem{{Document: "terms", Version: "2025-01"}}})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/users/me/consents",
+			authenticated: true,
+			bodyContains:  []string{`"consents":[{"document":"terms","version":"2025-01"}]`},
+		},
+		{
+			name: "RequestAccountMerge",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.RequestAccountMerge(ctx, stubUserID, stubEmail, stubSessionID, dto.RequestAccountMergeRequest{Email: "old@example.com"})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/users/me/merges",
+			bodyContains:  []string{`"email":"old@example.com"`},
+			authenticated: true,
+		},
+		{
+			name: "GetAccountMerge",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetAccountMerge(ctx, stubUserID, stubEmail, stubSessionID, "merge-1")
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/users/me/merges/merge-1",
+			authenticated: true,
+		},
+		{
+			name: "ConfirmAccountMerge",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.ConfirmAccountMerge(ctx, stubUserID, stubEmail, stubSessionID, "merge-1", dto.ConfirmAccountMergeRequest{TargetCode: "123456", SourceCode: "654321"})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/users/me/merges/merge-1/confirm",
+			bodyContains:  []string{`"target_code":"123456"`, `"source_code":"654321"`},
+			authenticated: true,
+		},
+		{
+			name: "CancelAccountMerge",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.CancelAccountMerge(ctx, stubUserID, stubEmail, stubSessionID, "merge-1")
+			},
+			method:        http.MethodDelete,
+			path:          "/api/v1/users/me/merges/merge-1",
+			authenticated: true,
+		},
+		{
+			name: "StartImpersonation",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.StartImpersonation(ctx, stubUserID, stubEmail, stubSessionID, "user-2", dto.StartImpersonationRequest{Reason: "reproduce ticket 42", DurationMinutes: 20})
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/impersonations",
+			authenticated: true,
+			bodyContains:  []string{`"user_id":"user-2"`, `"reason":"reproduce ticket 42"`, `"duration_minutes":20`},
+		},
+		{
+			name: "EndImpersonation",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.EndImpersonation(ctx, stubUserID, stubEmail, stubSessionID, "session-2")
+			},
+			method:        http.MethodDelete,
+			path:          "/api/v1/impersonations/session-2",
+			authenticated: true,
+		},
+		{
+			name: "ImpersonatedRequest",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetSessions(tracing.WithImpersonator(ctx, "admin-1"), stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/sessions",
+			headers:       map[string]string{"X-Impersonator-ID": "admin-1"},
+			authenticated: true,
+		},
+		{
+			name: "GetSessions",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetSessions(ctx, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/sessions",
+			authenticated: true,
+		},
+		{
+			name: "DeleteSession",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.DeleteSession(ctx, stubUserID, stubEmail, stubSessionID, "sess-456")
+			},
+			method:        http.MethodDelete,
+			path:          "/api/v1/sessions/sess-456",
+			authenticated: true,
+		},
+		{
+			name: "RevokeAllSessions",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.RevokeAllSessions(ctx, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/sessions/revoke-all",
+			authenticated: true,
+		},
+		{
+			name: "ListSessionsByUserID",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.ListSessionsByUserID(ctx, "user-2", stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/sessions/user/user-2",
+			authenticated: true,
+		},
+		{
+			name: "GetUsers",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetUsers(ctx, dto.AdminUserQuery{
+					PageSize:    20,
+					Status:      "active",
+					Role:        "teacher",
+					Search:      "ann",
+					MFAEnabled:  "true",
+					CreatedFrom: "2024-01-01T00:00:00Z",
+					Sort:        "last_login_at",
+					Order:       "asc",
+					Cursor:      "eyJzIjoibGFzdF9sb2dpbl9hdCJ9",
+				}, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/users",
+			query:         "created_from=2024-01-01T00%3A00%3A00Z&cursor=eyJzIjoibGFzdF9sb2dpbl9hdCJ9&mfa_enabled=true&order=asc&page_size=20&role=teacher&search=ann&sort=last_login_at&status=active",
+			authenticated: true,
+		},
+		{
+			name: "GetUserById",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetUserById(ctx, stubUserID, stubEmail, stubSessionID, "user-2")
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/users/user-2",
+			authenticated: true,
+		},
+		{
+			name: "BatchGetUsers",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.BatchGetUsers(ctx, []string{"user-2", "user-3"})
+			},
+			method:       http.MethodPost,
+			path:         "/api/v1/internal/users/batch",
+			bodyContains: []string{`"ids":["user-2","user-3"]`},
+		},
+		{
+			name: "GetProfileWithContext",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetProfileWithContext(ctx, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/users/profile",
+			authenticated: true,
+		},
+		{
+			name: "UpdateProfileWithContext",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.UpdateProfileWithContext(ctx, stubUserID, stubEmail, stubSessionID, dto.UpdateProfileRequest{DisplayName: "Ann", TimeZone: "Asia/Ho_Chi_Minh"})
+			},
+			method:        http.MethodPut,
+			path:          "/api/v1/users/profile",
+			bodyContains:  []string{`"display_name":"Ann"`, `"time_zone":"Asia/Ho_Chi_Minh"`},
+			authenticated: true,
+		},
+		{
+			name: "UpdateUserRoleWithContext",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.UpdateUserRoleWithContext(ctx, stubUserID, stubEmail, stubSessionID, "user-2", dto.UpdateUserRoleRequest{Role: "teacher"})
+			},
+			method:        http.MethodPut,
+			path:          "/api/v1/users/user-2/role",
+			bodyContains:  []string{`"role":"teacher"`},
+			authenticated: true,
+		},
+		{
+			name: "LockAccountWithContext",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.LockAccountWithContext(ctx, stubUserID, stubEmail, stubSessionID, "user-2", "abuse report")
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/users/user-2/lock",
+			query:         "reason=abuse+report",
+			authenticated: true,
+		},
+		{
+			name: "UnlockAccountWithContext",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.UnlockAccountWithContext(ctx, stubUserID, stubEmail, stubSessionID, "user-2", "")
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/users/user-2/unlock",
+			authenticated: true,
+		},
+		{
+			name: "SoftDeleteAccountWithContext",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.SoftDeleteAccountWithContext(ctx, stubUserID, stubEmail, stubSessionID, "user-2", "gdpr")
+			},
+			method:        http.MethodDelete,
+			path:          "/api/v1/users/user-2/delete",
+			query:         "reason=gdpr",
+			authenticated: true,
+		},
+		{
+			name: "RestoreAccountWithContext",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.RestoreAccountWithContext(ctx, stubUserID, stubEmail, stubSessionID, "user-2", "")
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/users/user-2/restore",
+			authenticated: true,
+		},
+		{
+			name: "StartActivitySession",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.StartActivitySession(ctx, dto.StartSessionRequest{}, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/activity-sessions/start",
+			bodyContains:  []string{`"session_id"`},
+			authenticated: true,
+		},
+		{
+			name: "EndActivitySession",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.EndActivitySession(ctx, dto.EndSessionRequest{}, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/activity-sessions/end",
+			authenticated: true,
+		},
+		{
+			name: "GetActivitySessions",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetActivitySessions(ctx, stubUserID, stubEmail, stubSessionID, 1, 10, &from, &to)
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/activity-sessions",
+			query:         "end_date=2026-01-31T00%3A00%3A00Z&limit=10&page=1&start_date=2026-01-01T00%3A00%3A00Z",
+			authenticated: true,
+		},
+		{
+			name: "GetSessionStats",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.GetSessionStats(ctx, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodGet,
+			path:          "/api/v1/activity-sessions/stats",
+			authenticated: true,
+		},
+		{
+			name: "UpdateActivitySession",
+			call: func(ctx context.Context) (*types.HTTPResponse, error) {
+				return client.UpdateActivitySession(ctx, dto.UpdateSessionRequest{}, stubUserID, stubEmail, stubSessionID)
+			},
+			method:        http.MethodPost,
+			path:          "/api/v1/activity-sessions/update",
+			authenticated: true,
+		},
+	}
+
+	runContractCases(t, stub, cases)
+}
+
+func TestUserServiceRejectsMissingArguments(t *testing.T) {
+	stub := newStubService(t)
+	client := NewUserServiceClient(stub.URL(), nil)
+	ctx := contractContext()
+
+	if _, err := client.VerifyEmail(ctx, ""); err == nil {
+		t.Error("VerifyEmail: expected error for empty token")
+	}
+	if _, err := client.DeleteSession(ctx, stubUserID, stubEmail, stubSessionID, ""); err == nil {
+		t.Error("DeleteSession: expected error for empty session id")
+	}
+	if len(stub.requests) != 0 {
+		t.Errorf("expected no downstream requests, got %d", len(stub.requests))
+	}
+}
@@ -0,0 +1,63 @@
+// Package tracing carries the request metadata the service clients forward downstream:
+// the request ID, the end user's client and the impersonating administrator. Spans and
+// trace propagation live in the shared telemetry module, and the request ID is kept by
+// the shared logging module so every log line of the request carries it.
+package tracing
+
+import (
+	"context"
+
+	"github.com/ductan2/microservice-app/shared/logging"
+)
+
+type clientContextKey struct{}
+
+type impersonatorContextKey struct{}
+
+// Client identifies the end user's client behind a request.
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+// WithRequestID stores the request ID on ctx so it can be forwarded, reported in errors
+// and logged.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return logging.WithRequestID(ctx, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored on ctx, or "" when none is set.
+func RequestIDFromContext(ctx context.Context) string {
+	return logging.RequestIDFromContext(ctx)
+}
+
+// WithClient stores the end user's IP and user agent on ctx so service clients can pass
+// them on for downstream audit logs.
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientContextKey{}, client)
+}
+
+// ClientFromContext returns the client stored on ctx, or a zero Client when none is set.
+func ClientFromContext(ctx context.Context) Client {
+	if ctx == nil {
+		return Client{}
+	}
+	client, _ := ctx.Value(clientContextKey{}).(Client)
+	return client
+}
+
+// WithImpersonator records the administrator behind an impersonation session on ctx so
+// service clients can pass them on for downstream audit logs.
+func WithImpersonator(ctx context.Context, impersonatorID string) context.Context {
+	return context.WithValue(ctx, impersonatorContextKey{}, impersonatorID)
+}
+
+// ImpersonatorFromContext returns the impersonating administrator stored on ctx, or ""
+// for requests outside an impersonation session.
+func ImpersonatorFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	impersonatorID, _ := ctx.Value(impersonatorContextKey{}).(string)
+	return impersonatorID
+}
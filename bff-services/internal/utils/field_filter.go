@@ -0,0 +1,135 @@
+package utils
+
+import "strings"
+
+// FieldSelection is a parsed `fields=` expression. A nil child means the whole
+// value is kept; a non-nil child narrows a nested object further.
+type FieldSelection map[string]FieldSelection
+
+// ParseFieldSelection parses a comma-separated list of dotted paths such as
+// "id,title,author.name". It returns nil when no usable field is given.
+func ParseFieldSelection(expr string) FieldSelection {
+	var selection FieldSelection
+	for _, raw := range strings.Split(expr, ",") {
+		path := strings.TrimSpace(raw)
+		if path == "" {
+			continue
+		}
+		if selection == nil {
+			selection = FieldSelection{}
+		}
+
+		addFieldPath(selection, strings.Split(path, "."))
+	}
+	return selection
+}
+
+// addFieldPath records a dotted path. A shorter path wins, so "author" keeps the whole
+// object even when "author.name" is also requested.
+func addFieldPath(node FieldSelection, parts []string) {
+	for i, part := range parts {
+		child, exists := node[part]
+		if i == len(parts)-1 {
+			node[part] = nil
+			return
+		}
+		if exists && child == nil {
+			return
+		}
+		if child == nil {
+			child = FieldSelection{}
+			node[part] = child
+		}
+		node = child
+	}
+}
+
+// SelectFields keeps only the selected fields of every resource in document. Response
+// wrappers ({status, data} and {items, total, next_cursor}) are preserved and the
+// selection is applied to the resources they carry.
+func SelectFields(document interface{}, selection FieldSelection) interface{} {
+	return transformResources(document, func(resource map[string]interface{}) map[string]interface{} {
+		return selectObject(resource, selection)
+	}, false)
+}
+
+// DropListFields removes the given fields from resources returned inside lists, leaving
+// single-resource responses untouched.
+func DropListFields(document interface{}, fields []string) interface{} {
+	return transformResources(document, func(resource map[string]interface{}) map[string]interface{} {
+		for _, field := range fields {
+			delete(resource, field)
+		}
+		return resource
+	}, true)
+}
+
+func transformResources(node interface{}, fn func(map[string]interface{}) map[string]interface{}, listsOnly bool) interface{} {
+	switch v := node.(type) {
+	case []interface{}:
+		for i, item := range v {
+			if obj, ok := item.(map[string]interface{}); ok {
+				v[i] = fn(obj)
+			}
+		}
+		return v
+	case map[string]interface{}:
+		if key, ok := wrappedKey(v); ok {
+			v[key] = transformResources(v[key], fn, listsOnly)
+			return v
+		}
+		if listsOnly {
+			return v
+		}
+		return fn(v)
+	}
+	return node
+}
+
+// wrappedKey reports which key of obj carries the payload when obj is a response wrapper.
+func wrappedKey(obj map[string]interface{}) (string, bool) {
+	if _, ok := obj["data"]; ok {
+		_, hasStatus := obj["status"]
+		_, hasSuccess := obj["success"]
+		if hasStatus || hasSuccess {
+			return "data", true
+		}
+	}
+	if _, ok := obj["items"].([]interface{}); ok {
+		for key := range obj {
+			if key != "items" && key != "total" && key != "next_cursor" {
+				return "", false
+			}
+		}
+		return "items", true
+	}
+	return "", false
+}
+
+func selectObject(obj map[string]interface{}, selection FieldSelection) map[string]interface{} {
+	result := make(map[string]interface{}, len(selection))
+	for field, child := range selection {
+		value, ok := obj[field]
+		if !ok {
+			continue
+		}
+		if child == nil {
+			result[field] = value
+			continue
+		}
+		switch nested := value.(type) {
+		case map[string]interface{}:
+			result[field] = selectObject(nested, child)
+		case []interface{}:
+			for i, item := range nested {
+				if itemObj, ok := item.(map[string]interface{}); ok {
+					nested[i] = selectObject(itemObj, child)
+				}
+			}
+			result[field] = nested
+		default:
+			result[field] = value
+		}
+	}
+	return result
+}
@@ -11,10 +11,18 @@ import (
 	"github.com/google/uuid"
 )
 
+// Claims are the access token claims issued by user-service. OrganizationID and
+// OrganizationRole are the user's primary organization and their role in it, and are
+// absent for users outside any organization.
 type Claims struct {
-	UserID    uuid.UUID `json:"user_id"`
-	Email     string    `json:"email"`
-	SessionID uuid.UUID `json:"session_id"`
+	UserID           uuid.UUID  `json:"user_id"`
+	Email            string     `json:"email"`
+	SessionID        uuid.UUID  `json:"session_id"`
+	OrganizationID   *uuid.UUID `json:"org_id,omitempty"`
+	OrganizationRole string     `json:"org_role,omitempty"`
+	// ImpersonatorID marks tokens of impersonation sessions with the administrator
+	// acting as the user
+	ImpersonatorID *uuid.UUID `json:"impersonator_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -3,6 +3,10 @@ package utils
 import (
 	"net/http"
 
+	"bff-services/internal/i18n"
+	"bff-services/internal/tracing"
+
+	"github.com/ductan2/microservice-app/shared/apperr"
 	"github.com/gin-gonic/gin"
 )
 
@@ -13,6 +17,10 @@ type BaseResponse struct {
 	Error   interface{} `json:"error,omitempty"`
 }
 
+// ErrorBody is the single error shape returned by the BFF, whether the error was raised
+// locally or translated from a downstream service.
+type ErrorBody = apperr.Body
+
 func Success(c *gin.Context, data interface{}) {
 	c.JSON(http.StatusOK, BaseResponse{
 		Status: "success",
@@ -27,13 +35,24 @@ func Created(c *gin.Context, data interface{}) {
 	})
 }
 
+// Fail writes an error response, localizing message to the negotiated request locale.
+// err, typically the underlying error text, is reported as the error details. The error
+// code is the canonical code of the status.
 func Fail(c *gin.Context, message string, code int, err interface{}) {
-	resp := BaseResponse{
+	FailWithCode(c, message, code, string(apperr.CodeForHTTPStatus(code)), err)
+}
+
+// FailWithCode is Fail with an explicit machine-readable error code.
+func FailWithCode(c *gin.Context, message string, status int, errorCode string, details interface{}) {
+	message = i18n.Translate(i18n.LocaleFromContext(c.Request.Context()), message)
+	c.JSON(status, BaseResponse{
 		Status:  "error",
 		Message: message,
-	}
-	if err != nil {
-		resp.Error = err
-	}
-	c.JSON(code, resp)
+		Error: ErrorBody{
+			Code:      errorCode,
+			Message:   message,
+			Details:   details,
+			RequestID: tracing.RequestIDFromContext(c.Request.Context()),
+		},
+	})
 }
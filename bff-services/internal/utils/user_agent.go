@@ -0,0 +1,65 @@
+package utils
+
+import "strings"
+
+// DeviceInfo is a coarse, human-readable description of a User-Agent string.
+type DeviceInfo struct {
+	Browser    string `json:"browser"`
+	OS         string `json:"os"`
+	DeviceType string `json:"device_type"`
+}
+
+// ParseUserAgent extracts browser, operating system and device type from a User-Agent.
+// It only recognizes the common families shown on the account page; anything else
+// is reported as "Unknown".
+func ParseUserAgent(ua string) DeviceInfo {
+	info := DeviceInfo{Browser: "Unknown", OS: "Unknown", DeviceType: "desktop"}
+	if strings.TrimSpace(ua) == "" {
+		info.DeviceType = "unknown"
+		return info
+	}
+	lower := strings.ToLower(ua)
+
+	switch {
+	case strings.Contains(lower, "edg/"):
+		info.Browser = "Edge"
+	case strings.Contains(lower, "opr/") || strings.Contains(lower, "opera"):
+		info.Browser = "Opera"
+	case strings.Contains(lower, "samsungbrowser"):
+		info.Browser = "Samsung Internet"
+	case strings.Contains(lower, "firefox/") || strings.Contains(lower, "fxios"):
+		info.Browser = "Firefox"
+	case strings.Contains(lower, "chrome/") || strings.Contains(lower, "crios"):
+		info.Browser = "Chrome"
+	case strings.Contains(lower, "safari/"):
+		info.Browser = "Safari"
+	case strings.Contains(lower, "okhttp") || strings.Contains(lower, "dart/") || strings.Contains(lower, "cfnetwork"):
+		info.Browser = "Mobile App"
+	case strings.Contains(lower, "postman") || strings.Contains(lower, "curl/"):
+		info.Browser = "API Client"
+	}
+
+	switch {
+	case strings.Contains(lower, "iphone") || strings.Contains(lower, "ipad") || strings.Contains(lower, "ios"):
+		info.OS = "iOS"
+	case strings.Contains(lower, "android"):
+		info.OS = "Android"
+	case strings.Contains(lower, "windows"):
+		info.OS = "Windows"
+	case strings.Contains(lower, "mac os") || strings.Contains(lower, "macintosh"):
+		info.OS = "macOS"
+	case strings.Contains(lower, "cros"):
+		info.OS = "ChromeOS"
+	case strings.Contains(lower, "linux"):
+		info.OS = "Linux"
+	}
+
+	switch {
+	case strings.Contains(lower, "ipad") || strings.Contains(lower, "tablet"):
+		info.DeviceType = "tablet"
+	case strings.Contains(lower, "mobi") || strings.Contains(lower, "iphone") || strings.Contains(lower, "android"):
+		info.DeviceType = "mobile"
+	}
+
+	return info
+}
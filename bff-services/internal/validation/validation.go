@@ -0,0 +1,100 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	weekKeyPattern  = regexp.MustCompile(`^\d{4}-W(0[1-9]|[1-4]\d|5[0-3])$`)
+	monthKeyPattern = regexp.MustCompile(`^\d{4}-(0[1-9]|1[0-2])$`)
+)
+
+// Register adds the BFF's custom rules to gin's validator so they can be used in
+// `binding` tags:
+//
+//	week_key   ISO week as used by leaderboards, e.g. 2025-W07
+//	month_key  calendar month, e.g. 2025-02
+//	date       calendar date, e.g. 2025-02-14
+func Register() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("validation: unexpected validator engine")
+	}
+
+	// Report form/uri/json names in errors instead of Go field names.
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"form", "uri", "json"} {
+			name := strings.Split(field.Tag.Get(tag), ",")[0]
+			if name != "" && name != "-" {
+				return name
+			}
+		}
+		return field.Name
+	})
+
+	rules := map[string]validator.Func{
+		"week_key":  func(fl validator.FieldLevel) bool { return weekKeyPattern.MatchString(fl.Field().String()) },
+		"month_key": func(fl validator.FieldLevel) bool { return monthKeyPattern.MatchString(fl.Field().String()) },
+		"date": func(fl validator.FieldLevel) bool {
+			_, err := time.Parse("2006-01-02", fl.Field().String())
+			return err == nil
+		},
+	}
+	for tag, fn := range rules {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			return fmt.Errorf("validation: register %s: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+// Describe turns a binding error into a short client-facing explanation such as
+// "limit must be at least 1; user_id must be a valid UUID".
+func Describe(err error) string {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		messages := make([]string, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			messages = append(messages, describeField(fe))
+		}
+		return strings.Join(messages, "; ")
+	}
+
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		return fmt.Sprintf("%q is not a valid number", numErr.Num)
+	}
+	return err.Error()
+}
+
+func describeField(fe validator.FieldError) string {
+	field := fe.Field()
+	switch fe.Tag() {
+	case "required":
+		return field + " is required"
+	case "min":
+		return fmt.Sprintf("%s must be at least %s", field, fe.Param())
+	case "max":
+		return fmt.Sprintf("%s must be at most %s", field, fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", field, strings.ReplaceAll(fe.Param(), " ", ", "))
+	case "uuid", "uuid4":
+		return field + " must be a valid UUID"
+	case "week_key":
+		return field + " must be an ISO week such as 2025-W07"
+	case "month_key":
+		return field + " must be a month such as 2025-02"
+	case "date":
+		return field + " must be a date such as 2025-02-14"
+	}
+	return fmt.Sprintf("%s failed %s validation", field, fe.Tag())
+}
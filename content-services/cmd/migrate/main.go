@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"content-services/internal/db"
+
+	"github.com/ductan2/microservice-app/shared/logging"
+	"github.com/ductan2/microservice-app/shared/migrate"
+)
+
+func main() {
+	var dir string
+	flag.StringVar(&dir, "dir", "", "path to a migrations directory to use instead of the embedded migrations (defaults to MIGRATIONS_DIR)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: migrate [-dir path] [command]\n%s\n", migrate.Usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	logging.Setup(logging.ConfigFromEnv("content-services-migrate"))
+
+	client, err := db.NewMongoClient(context.Background())
+	if err != nil {
+		logging.Fatal("failed to connect to database", "error", err)
+	}
+	defer client.Disconnect(context.Background())
+
+	migrator, err := db.Migrator(db.GetDatabase(client), dir)
+	if err != nil {
+		logging.Fatal("failed to load migrations", "error", err)
+	}
+	if err := migrate.Command(context.Background(), migrator, flag.Args(), os.Stdout); err != nil {
+		logging.Fatal("migration failed", "error", err)
+	}
+}
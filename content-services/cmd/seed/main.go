@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"content-services/internal/db"
+	"content-services/internal/models"
+	"content-services/internal/repository"
+	"content-services/internal/taxonomy"
+	"content-services/internal/types"
+
+	"github.com/ductan2/microservice-app/shared/logging"
+	"github.com/ductan2/microservice-app/shared/seed"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func main() {
+	var cfg seed.Config
+	cfg.RegisterFlags(flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: seed [flags]\nWrites the courses, lessons, quizzes, enrollments and reviews of the seed plan; run the same flags against every service.\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	logging.Setup(logging.ConfigFromEnv("content-services-seed"))
+
+	ctx := context.Background()
+	client, err := db.NewMongoClient(ctx)
+	if err != nil {
+		logging.Fatal("failed to connect to database", "error", err)
+	}
+	defer client.Disconnect(ctx)
+
+	s := newSeeder(db.GetDatabase(client))
+	plan := seed.New(cfg)
+	if err := s.taxonomy(ctx, plan); err != nil {
+		logging.Fatal("failed to seed topics and levels", "error", err)
+	}
+
+	created := 0
+	for _, course := range plan.Courses() {
+		ok, err := s.course(ctx, course)
+		if err != nil {
+			logging.Fatal("failed to seed course", "course_id", course.ID, "error", err)
+		}
+		if ok {
+			created++
+		}
+	}
+	orders := plan.Orders()
+	for _, order := range orders {
+		if err := s.enrollment(ctx, order); err != nil {
+			logging.Fatal("failed to seed enrollment", "order_id", order.ID, "error", err)
+		}
+	}
+	slog.Info("seeded content", "courses", cfg.Courses, "new_courses", created, "enrollments", len(orders))
+}
+
+type seeder struct {
+	taxonomyStore *taxonomy.Store
+	courses       repository.CourseRepository
+	courseLessons repository.CourseLessonRepository
+	lessons       repository.LessonRepository
+	sections      repository.LessonSectionRepository
+	quizzes       repository.QuizRepository
+	questions     repository.QuizQuestionRepository
+	options       repository.QuestionOptionRepository
+	reviews       repository.CourseReviewRepository
+	enrollments   *mongo.Collection
+
+	topicIDs map[string]uuid.UUID
+	levelIDs map[string]uuid.UUID
+}
+
+func newSeeder(database *mongo.Database) *seeder {
+	return &seeder{
+		taxonomyStore: taxonomy.NewStore(database),
+		courses:       repository.NewCourseRepository(database),
+		courseLessons: repository.NewCourseLessonRepository(database),
+		lessons:       repository.NewLessonRepository(database),
+		sections:      repository.NewLessonSectionRepository(database),
+		quizzes:       repository.NewQuizRepository(database),
+		questions:     repository.NewQuizQuestionRepository(database),
+		options:       repository.NewQuestionOptionRepository(database),
+		reviews:       repository.NewCourseReviewRepository(database),
+		// No repository writes enrollments; IsUserEnrolled reads this collection
+		enrollments: database.Collection("course_enrollments"),
+		topicIDs:    make(map[string]uuid.UUID),
+		levelIDs:    make(map[string]uuid.UUID),
+	}
+}
+
+// taxonomy looks up the topics and levels of the plan by slug and code, creating the
+// missing ones.
+func (s *seeder) taxonomy(ctx context.Context, plan *seed.Plan) error {
+	for _, t := range plan.Topics() {
+		topic, err := s.taxonomyStore.GetTopicBySlug(ctx, t.Slug)
+		if errors.Is(err, taxonomy.ErrNotFound) {
+			topic, err = s.taxonomyStore.CreateTopic(ctx, t.Slug, t.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if s.topicIDs[t.Slug], err = uuid.Parse(topic.ID); err != nil {
+			return err
+		}
+	}
+	for _, l := range plan.Levels() {
+		level, err := s.taxonomyStore.GetLevelByCode(ctx, l.Code)
+		if errors.Is(err, taxonomy.ErrNotFound) {
+			level, err = s.taxonomyStore.CreateLevel(ctx, l.Code, l.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if s.levelIDs[l.Code], err = uuid.Parse(level.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// course saves a published course with its lessons and quizzes and returns whether the
+// course is new. The course itself is written last, so a run that failed part way is
+// completed by the next one.
+func (s *seeder) course(ctx context.Context, c seed.Course) (bool, error) {
+	courseID := uuid.MustParse(c.ID)
+	if _, err := s.courses.GetByID(ctx, courseID); err == nil {
+		return false, nil
+	} else if !errors.Is(err, types.ErrCourseNotFound) {
+		return false, err
+	}
+
+	topicID, levelID := s.topicIDs[c.Topic.Slug], s.levelIDs[c.Level.Code]
+	var instructorID *uuid.UUID
+	if c.InstructorID != "" {
+		id := uuid.MustParse(c.InstructorID)
+		instructorID = &id
+	}
+	published := sql.NullTime{Time: c.PublishedAt, Valid: true}
+
+	for ord, l := range c.Lessons {
+		// The section and the course entry are the only ones of the lesson, so they share its ID
+		lessonID := uuid.MustParse(l.ID)
+		err := s.lessons.Create(ctx, &models.Lesson{
+			ID:          lessonID,
+			Code:        l.Code,
+			Title:       l.Title,
+			Description: l.Body,
+			TopicID:     &topicID,
+			LevelID:     &levelID,
+			IsPublished: true,
+			Version:     1,
+			CreatedBy:   instructorID,
+			CreatedAt:   c.PublishedAt,
+			UpdatedAt:   c.PublishedAt,
+			PublishedAt: published,
+		})
+		if err := existing(err); err != nil {
+			return false, err
+		}
+		err = s.sections.Create(ctx, &models.LessonSection{
+			ID:        lessonID,
+			LessonID:  lessonID,
+			Ord:       1,
+			Type:      "text",
+			Body:      map[string]any{"text": l.Body},
+			CreatedAt: c.PublishedAt,
+		})
+		if err := existing(err); err != nil {
+			return false, err
+		}
+		if err := s.quiz(ctx, lessonID, topicID, levelID, l.Quiz, c.PublishedAt); err != nil {
+			return false, err
+		}
+		err = s.courseLessons.Create(ctx, &models.CourseLesson{
+			ID:         lessonID,
+			CourseID:   courseID,
+			LessonID:   lessonID,
+			Ord:        ord + 1,
+			IsRequired: true,
+			CreatedAt:  c.PublishedAt,
+		})
+		if err := existing(err); err != nil {
+			return false, err
+		}
+	}
+
+	err := s.courses.Create(ctx, &models.Course{
+		ID:            courseID,
+		Title:         c.Title,
+		Description:   c.Description,
+		TopicID:       &topicID,
+		LevelID:       &levelID,
+		InstructorID:  instructorID,
+		IsPublished:   true,
+		IsFeatured:    c.Featured,
+		Price:         float64(c.PriceCents) / 100,
+		DurationHours: len(c.Lessons),
+		CreatedAt:     c.PublishedAt,
+		UpdatedAt:     c.PublishedAt,
+		PublishedAt:   published,
+	})
+	return err == nil, err
+}
+
+func (s *seeder) quiz(ctx context.Context, lessonID, topicID, levelID uuid.UUID, q seed.Quiz, createdAt time.Time) error {
+	quizID := uuid.MustParse(q.ID)
+	err := s.quizzes.Create(ctx, &models.Quiz{
+		ID:          quizID,
+		LessonID:    &lessonID,
+		Title:       q.Title,
+		TotalPoints: len(q.Questions),
+		CreatedAt:   createdAt,
+		TopicID:     &topicID,
+		LevelID:     &levelID,
+	})
+	if err := existing(err); err != nil {
+		return err
+	}
+	for ord, question := range q.Questions {
+		questionID := uuid.MustParse(question.ID)
+		err := s.questions.Create(ctx, &models.QuizQuestion{
+			ID:     questionID,
+			QuizID: quizID,
+			Ord:    ord + 1,
+			Type:   "mcq",
+			Prompt: question.Prompt,
+			Points: 1,
+		})
+		if err := existing(err); err != nil {
+			return err
+		}
+		for o, option := range question.Options {
+			err := s.options.Create(ctx, &models.QuestionOption{
+				ID:         uuid.MustParse(option.ID),
+				QuestionID: questionID,
+				Ord:        o + 1,
+				Label:      option.Label,
+				IsCorrect:  option.Correct,
+			})
+			if err := existing(err); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// existing ignores the error of a document seeded before.
+func existing(err error) error {
+	if mongo.IsDuplicateKeyError(err) || errors.Is(err, types.ErrDuplicateCode) || errors.Is(err, types.ErrCourseLessonExists) {
+		return nil
+	}
+	return err
+}
+
+// enrollment enrolls the buyer of a paid order in its course and saves their review.
+func (s *seeder) enrollment(ctx context.Context, o seed.Order) error {
+	_, err := s.enrollments.InsertOne(ctx, bson.M{
+		"_id":        o.ID,
+		"course_id":  o.Course.ID,
+		"user_id":    o.UserID,
+		"order_id":   o.ID,
+		"created_at": o.PaidAt,
+	})
+	if err := existing(err); err != nil {
+		return err
+	}
+	if o.Review == nil {
+		return nil
+	}
+	return s.reviews.Upsert(ctx, &models.CourseReview{
+		ID:        uuid.MustParse(o.ID),
+		CourseID:  uuid.MustParse(o.Course.ID),
+		UserID:    uuid.MustParse(o.UserID),
+		Rating:    o.Review.Rating,
+		Comment:   o.Review.Comment,
+		CreatedAt: o.PaidAt,
+		UpdatedAt: o.PaidAt,
+	})
+}
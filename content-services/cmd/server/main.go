@@ -10,40 +10,100 @@ import (
 	"content-services/internal/service"
 	"content-services/internal/storage"
 	"content-services/internal/taxonomy"
+	"content-services/internal/utils"
 	"context"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
-	"log"
-
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/ductan2/microservice-app/shared/envconfig"
+	"github.com/ductan2/microservice-app/shared/events"
+	"github.com/ductan2/microservice-app/shared/health"
+	"github.com/ductan2/microservice-app/shared/internalauth"
+	"github.com/ductan2/microservice-app/shared/lifecycle"
+	"github.com/ductan2/microservice-app/shared/logging"
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/ductan2/microservice-app/shared/outbox"
+	"github.com/ductan2/microservice-app/shared/secrets"
+	"github.com/ductan2/microservice-app/shared/telemetry"
 	"github.com/gin-gonic/gin"
+	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+// drainDelay is how long readiness fails before the server stops accepting connections,
+// so the load balancer stops routing requests here first.
+const drainDelay = 2 * time.Second
+
 func main() {
-	// Determine port (defaults to 8001)
+	// Settings such as JWT_SECRET=secret://content-services/jwt are read from the secrets
+	// manager named by SECRETS_PROVIDER
+	secretStore, secretsErr := secrets.FromEnv()
+	if secretStore != nil {
+		envconfig.UseSecrets(secretStore)
+	}
+	// "content-services config validate" checks the configuration of a deployment and exits
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(envconfig.Command(os.Args[2:], os.Stdout, func() (any, error) { return config.Read() }))
+	}
+	configs, err := config.Load()
+	logging.Setup(logging.ConfigFromEnv("content-services"))
+	if secretsErr != nil {
+		logging.Fatal("invalid secrets configuration", "error", secretsErr)
+	}
+	if err != nil {
+		logging.Fatal("invalid configuration", "error", err)
+	}
+	// Components are stopped in the reverse order they are added, on SIGINT or SIGTERM
+	app := lifecycle.New(lifecycle.Config{})
+
+	// SIGHUP reloads the configuration; the log settings follow it
+	configs.OnReload(func(_, _ *config.Config) {
+		logging.Setup(logging.ConfigFromEnv("content-services"))
+	})
+	app.Add(lifecycle.Worker("config-reload", configs.WatchSignals))
+	if secretStore != nil {
+		// A rotated secret is picked up by reloading the configuration
+		secretStore.OnRotate(func(string) {
+			if err := configs.Reload(); err != nil {
+				slog.Error("failed to reload configuration after a secret rotation", "error", err)
+			}
+		})
+		app.Add(lifecycle.Worker("secrets", secretStore.Run))
+	}
+	slog.Info("configuration loaded", "config", envconfig.Values(configs.Current()))
+
+	// Determine port (defaults to 8004)
 	port := config.GetPort()
 
+	// Export traces to the OpenTelemetry collector
+	tracer := telemetry.Setup(telemetry.ConfigFromEnv("content-services"))
+	app.Add(lifecycle.Component{Name: "tracer", Stop: tracer.Shutdown})
+	metrics.SetBuildInfo("content-services")
+
 	addr := ":" + port
 	// Init Mongo
 	mongoClient, err := db.NewMongoClient(context.Background())
 	if err != nil {
-		log.Fatalf("mongo connect error: %v", err)
+		logging.Fatal("mongo connect error", "error", err)
 	}
+	app.Add(lifecycle.Component{Name: "mongodb", Stop: mongoClient.Disconnect})
 	database := db.GetDatabase(mongoClient)
 
-	// Prepare taxonomy store backed by MongoDB
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	taxonomyStore, err := taxonomy.NewStore(ctx, database)
+	// Apply the migrations, or only check for drift without MIGRATE_ON_START
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	err = db.RunMigrations(ctx, database, config.Current().MigrateOnStart)
 	cancel()
 	if err != nil {
-		log.Fatalf("taxonomy store init error: %v", err)
+		logging.Fatal("failed to run database migrations", "error", err)
 	}
 
+	// Prepare taxonomy store backed by MongoDB
+	taxonomyStore := taxonomy.NewStore(database)
+
 	// Build GraphQL server
 	mediaRepo := repository.NewMediaRepository(database)
 	folderRepo := repository.NewFolderRepository(database)
@@ -58,11 +118,28 @@ func main() {
 	optionRepo := repository.NewQuestionOptionRepository(database)
 	flashcardSetRepo := repository.NewFlashcardSetRepository(database)
 	flashcardRepo := repository.NewFlashcardRepository(database)
-	// Note: outboxRepo would need a separate database connection for transactional outbox pattern
-	// For now, passing nil - this should be properly implemented later
-	var outboxRepo repository.OutboxRepository = nil
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	outboxRepo, err := repository.NewOutboxRepository(ctx, database)
+	cancel()
+	if err != nil {
+		logging.Fatal("outbox init error", "error", err)
+	}
+	metrics.Default.OnScrape(func(ctx context.Context) {
+		stats, err := outboxRepo.Store().Stats(ctx)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to read outbox stats", "error", err)
+			return
+		}
+		metrics.QueueDepth.WithLabelValues("outbox").Set(float64(stats.Pending))
+	})
 	var tagRepo repository.TagRepository = nil
 
+	// Publish content events from the outbox
+	rabbitConn, err := startOutboxRelay(app, outboxRepo)
+	if err != nil {
+		slog.Warn("outbox relay not started, events wait in the outbox", "error", err)
+	}
+
 	s3Client, err := storage.NewS3Client(context.Background(), storage.S3Config{
 		Endpoint:        config.GetS3Endpoint(),
 		Region:          config.GetS3Region(),
@@ -73,15 +150,31 @@ func main() {
 		PresignExpires:  config.GetS3PresignTTL(),
 	})
 	if err != nil {
-		log.Fatalf("s3 init error: %v", err)
+		logging.Fatal("s3 init error", "error", err)
 	}
+
+	// Content is served from MongoDB; events wait in the outbox while RabbitMQ is down
+	// and only media uploads need S3
+	checker := health.New(health.Config{Service: "content-services"})
+	checker.Register("mongodb", health.Critical, func(ctx context.Context) error {
+		return mongoClient.Ping(ctx, nil)
+	})
+	checker.Register("rabbitmq", health.Optional, func(context.Context) error {
+		if rabbitConn == nil || rabbitConn.IsClosed() {
+			return fmt.Errorf("connection closed")
+		}
+		return nil
+	})
+	checker.Register("s3", health.Optional, s3Client.Ping)
+
 	mediaService := service.NewMediaService(mediaRepo, s3Client, config.GetS3PresignTTL())
 	folderService := service.NewFolderService(folderRepo)
 	lessonService := service.NewLessonService(lessonRepo, sectionRepo, outboxRepo)
-	courseService := service.NewCourseService(courseRepo, courseLessonRepo, lessonRepo)
+	courseService := service.NewCourseService(courseRepo, courseLessonRepo, lessonRepo, outboxRepo)
 	courseReviewService := service.NewCourseReviewService(courseReviewRepo, courseRepo, courseEnrollmentRepo)
 	quizService := service.NewQuizService(quizRepo, quizQuestionRepo, optionRepo, tagRepo, outboxRepo)
-	flashcardService := service.NewFlashcardService(flashcardSetRepo, flashcardRepo, tagRepo)
+	flashcardService := service.NewFlashcardService(flashcardSetRepo, flashcardRepo, tagRepo, outboxRepo)
+	taxonomyStore.RecordEvents(service.EventRecorder(outboxRepo))
 
 	resolver := &gqlresolver.Resolver{
 		DB:                  database,
@@ -97,8 +190,23 @@ func main() {
 	}
 	gqlSrv := generated.NewExecutableSchema(generated.Config{Resolvers: resolver})
 	graphqlHandler := handler.NewDefaultServer(gqlSrv)
+	graphqlHandler.SetErrorPresenter(utils.ErrorPresenter)
+
+	// The BFF and other services authenticate with service tokens signed with the shared keys
+	internalAuth, err := internalauth.ConfigFromEnv("content-services")
+	if err != nil {
+		logging.Fatal("invalid internal auth configuration", "error", err)
+	}
+	if internalAuth.Development {
+		slog.Warn("INTERNAL_AUTH_KEYS is not set; service tokens are signed with the development key")
+	}
+	verifier, err := internalauth.NewVerifier(internalAuth)
+	if err != nil {
+		logging.Fatal("failed to create service token verifier", "error", err)
+	}
 
-	r := server.NewRouter(graphqlHandler)
+	r := server.NewRouter(graphqlHandler, verifier, checker, config.GetTrustedProxies())
+	server.RegisterInternalRoutes(r, courseService)
 	if config.GetGraphQLPlaygroundEnabled() {
 		// Expose playground at root
 		r.GET("/", func(c *gin.Context) {
@@ -115,23 +223,46 @@ func main() {
 		IdleTimeout:       60 * time.Second,
 	}
 
-	// Start server in background
-	go func() {
-		log.Printf("Starting server on %s", addr)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("server error: %v", err)
-		}
-	}()
+	app.Add(lifecycle.HTTPServer("http", srv))
+	app.Add(lifecycle.Drain(checker.Shutdown, drainDelay))
 
-	// Graceful shutdown
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
-	<-stop
+	slog.Info("starting server", "addr", addr)
+	if err := app.Run(context.Background()); err != nil {
+		logging.Fatal("server stopped with errors", "error", err)
+	}
+}
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	if err := srv.Shutdown(shutdownCtx); err != nil {
-		log.Printf("graceful shutdown failed: %v", err)
+// startOutboxRelay adds to app a relay publishing the outbox to the content.events
+// exchange, with the event type as routing key. It returns the connection to RabbitMQ.
+func startOutboxRelay(app *lifecycle.Manager, outboxRepo repository.OutboxRepository) (*amqp.Connection, error) {
+	conn, err := amqp.Dial(config.GetRabbitMQURL())
+	if err != nil {
+		return nil, err
+	}
+	channel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, err
 	}
-	log.Println("server stopped")
+	publisher, err := outbox.NewAMQPPublisher(channel, outbox.ExchangePerTopic, 5*time.Second)
+	if err == nil {
+		err = publisher.DeclareExchanges("content.events")
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	relay := outbox.NewRelay(outboxRepo.Store(), publisher, outbox.Config{
+		PollInterval: config.GetOutboxPollInterval(),
+		BatchSize:    100,
+		MaxAttempts:  config.GetOutboxMaxAttempts(),
+		BackoffBase:  5 * time.Second,
+		BackoffMax:   10 * time.Minute,
+		Retention:    config.GetOutboxRetention(),
+		Validator:    events.Schemas,
+	})
+	app.Add(lifecycle.Closer("rabbitmq", conn))
+	app.Add(lifecycle.Worker("outbox-relay", relay.Start))
+	return conn, nil
 }
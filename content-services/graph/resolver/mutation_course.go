@@ -5,6 +5,7 @@ import (
 	"content-services/internal/models"
 	"content-services/internal/service"
 	"content-services/internal/taxonomy"
+	"content-services/internal/utils"
 	"context"
 	"errors"
 
@@ -206,7 +207,11 @@ func (r *mutationResolver) DeleteCourse(ctx context.Context, id string) (bool, e
 		return false, gqlerror.Errorf("invalid course ID: %v", err)
 	}
 
-	if err := r.CourseService.DeleteCourse(ctx, courseID); err != nil {
+	var deletedBy string
+	if userID, ok, _ := utils.UserIDFromContextOptional(ctx); ok {
+		deletedBy = userID.String()
+	}
+	if err := r.CourseService.DeleteCourse(ctx, courseID, deletedBy); err != nil {
 		return false, mapCourseError(err)
 	}
 
@@ -3,7 +3,6 @@ package resolver
 import (
 	"content-services/graph/model"
 	"context"
-	"fmt"
 
 	"github.com/google/uuid"
 	"github.com/vektah/gqlparser/v2/gqlerror"
@@ -21,8 +20,6 @@ func (r *queryResolver) Course(ctx context.Context, id string) (*model.Course, e
 	}
 
 	course, err := r.CourseService.GetCourseByID(ctx, courseID)
-	fmt.Println("course", course)
-	fmt.Println("err", err)
 	if err != nil {
 		return nil, mapCourseError(err)
 	}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"context"
+	"io/fs"
+	"os"
+
+	"content-services/migrations"
+
+	"github.com/ductan2/microservice-app/shared/migrate"
+	"github.com/ductan2/microservice-app/shared/migrate/mongodriver"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Migrator returns the migrator of the migrations embedded in the binary, or of the
+// directory dir, MIGRATIONS_DIR when empty, to try migrations without rebuilding.
+func Migrator(database *mongo.Database, dir string) (*migrate.Migrator, error) {
+	if dir == "" {
+		dir = os.Getenv("MIGRATIONS_DIR")
+	}
+	var fsys fs.FS = migrations.FS
+	if dir != "" {
+		fsys = os.DirFS(dir)
+	}
+	files, err := migrate.Load(fsys, mongodriver.Ext)
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(mongodriver.New(database, mongodriver.DefaultCollection), files), nil
+}
+
+// RunMigrations applies the pending migrations when apply is set, then refuses to go on
+// if the database does not match the migrations of this build.
+func RunMigrations(ctx context.Context, database *mongo.Database, apply bool) error {
+	migrator, err := Migrator(database, "")
+	if err != nil {
+		return err
+	}
+	return migrator.Startup(ctx, apply)
+}
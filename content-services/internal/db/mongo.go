@@ -6,13 +6,21 @@ import (
 
 	"content-services/internal/config"
 
+	"github.com/ductan2/microservice-app/shared/metrics"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-// NewMongoClient creates a new Mongo client with sane defaults.
+// NewMongoClient creates a new Mongo client with sane defaults. Its commands are traced
+// and its connection pool is exported as the "mongodb" pool metrics.
 func NewMongoClient(ctx context.Context) (*mongo.Client, error) {
-	clientOptions := options.Client().ApplyURI(config.GetMongoURI())
+	pool := &poolCounter{maxOpen: defaultMaxPoolSize}
+	clientOptions := options.Client().ApplyURI(config.GetMongoURI()).
+		SetMonitor(newCommandMonitor()).
+		SetPoolMonitor(newPoolMonitor(pool))
+	if clientOptions.MaxPoolSize != nil {
+		pool.maxOpen = int(*clientOptions.MaxPoolSize)
+	}
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 	client, err := mongo.Connect(ctx, clientOptions)
@@ -22,6 +30,7 @@ func NewMongoClient(ctx context.Context) (*mongo.Client, error) {
 	if err := client.Ping(ctx, nil); err != nil {
 		return nil, err
 	}
+	metrics.RegisterPool("mongodb", pool.stats)
 	return client, nil
 }
 
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"sync/atomic"
+
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// defaultMaxPoolSize is the pool size limit of the driver when none is configured.
+const defaultMaxPoolSize = 100
+
+// poolCounter follows the connection pool through the driver's pool events, which is the
+// only way the driver reports its pool, for the db_pool_* metrics.
+type poolCounter struct {
+	open    atomic.Int64
+	inUse   atomic.Int64
+	maxOpen int
+}
+
+func newPoolMonitor(counter *poolCounter) *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(evt *event.PoolEvent) {
+			switch evt.Type {
+			case event.ConnectionCreated:
+				counter.open.Add(1)
+			case event.ConnectionClosed:
+				counter.open.Add(-1)
+			case event.GetSucceeded:
+				counter.inUse.Add(1)
+			case event.ConnectionReturned:
+				counter.inUse.Add(-1)
+			}
+		},
+	}
+}
+
+func (p *poolCounter) stats() metrics.PoolStats {
+	open, inUse := int(p.open.Load()), int(p.inUse.Load())
+	return metrics.PoolStats{
+		Open:    open,
+		InUse:   inUse,
+		Idle:    max(open-inUse, 0),
+		MaxOpen: p.maxOpen,
+	}
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/ductan2/microservice-app/shared/telemetry"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// commandTracer turns the commands sent to MongoDB into client spans, children of the
+// span on the context of the operation. The driver reports the start and end of a
+// command separately, so the open spans are kept by request ID.
+type commandTracer struct {
+	spans sync.Map // int64 request ID -> trace.Span
+}
+
+func newCommandMonitor() *event.CommandMonitor {
+	t := &commandTracer{}
+	return &event.CommandMonitor{
+		Started:   t.started,
+		Succeeded: t.succeeded,
+		Failed:    t.failed,
+	}
+}
+
+func (t *commandTracer) started(ctx context.Context, evt *event.CommandStartedEvent) {
+	_, span := telemetry.Start(ctx, evt.CommandName, trace.SpanKindClient)
+	span.SetAttributes(
+		attribute.String("db.system", "mongodb"),
+		attribute.String("db.namespace", evt.DatabaseName),
+		attribute.String("db.operation.name", evt.CommandName),
+	)
+	if collection, ok := evt.Command.Lookup(evt.CommandName).StringValueOK(); ok {
+		span.SetAttributes(attribute.String("db.collection.name", collection))
+	}
+	t.spans.Store(evt.RequestID, span)
+}
+
+func (t *commandTracer) succeeded(_ context.Context, evt *event.CommandSucceededEvent) {
+	if value, ok := t.spans.LoadAndDelete(evt.RequestID); ok {
+		value.(trace.Span).End()
+	}
+}
+
+func (t *commandTracer) failed(_ context.Context, evt *event.CommandFailedEvent) {
+	if value, ok := t.spans.LoadAndDelete(evt.RequestID); ok {
+		span := value.(trace.Span)
+		telemetry.SetError(span, errors.New(evt.Failure))
+		span.End()
+	}
+}
@@ -190,11 +190,11 @@ type ContentTag struct {
 
 // Outbox for event-driven architecture
 type Outbox struct {
-	ID          int64          `gorm:"primaryKey;autoIncrement" json:"id"`
-	AggregateID uuid.UUID      `gorm:"type:uuid;not null" json:"aggregate_id"`
-	Topic       string         `gorm:"type:text;not null" json:"topic"` // content.events
-	Type        string         `gorm:"type:text;not null" json:"type"`  // LessonPublished, QuizCreated
-	Payload     map[string]any `gorm:"type:jsonb;not null" json:"payload"`
-	CreatedAt   time.Time      `gorm:"default:now();not null" json:"created_at"`
-	PublishedAt sql.NullTime   `gorm:"index:outbox_unpub_idx,where:published_at IS NULL" json:"published_at,omitempty"`
+	ID          int64        `gorm:"primaryKey;autoIncrement" json:"id"`
+	AggregateID uuid.UUID    `gorm:"type:uuid;not null" json:"aggregate_id"`
+	Topic       string       `gorm:"type:text;not null" json:"topic"`    // content.events
+	Type        string       `gorm:"type:text;not null" json:"type"`     // LessonPublished, QuizCreated
+	Payload     []byte       `gorm:"type:jsonb;not null" json:"payload"` // JSON, see shared/events
+	CreatedAt   time.Time    `gorm:"default:now();not null" json:"created_at"`
+	PublishedAt sql.NullTime `gorm:"index:outbox_unpub_idx,where:published_at IS NULL" json:"published_at,omitempty"`
 }
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ductan2/microservice-app/shared/softdelete"
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -24,14 +25,22 @@ type CourseFilter struct {
 	Search       string
 }
 
+// CourseRepository stores courses. Deleted courses are kept (see shared/softdelete) and
+// are not found by any method but Delete and Restore.
 type CourseRepository interface {
 	Create(ctx context.Context, course *models.Course) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Course, error)
+	// GetByIDs returns the courses among ids that exist, in no particular order
+	GetByIDs(ctx context.Context, ids []uuid.UUID) ([]models.Course, error)
 	List(ctx context.Context, filter *CourseFilter, sort *SortOption, limit, offset int) ([]models.Course, int64, error)
 	Update(ctx context.Context, course *models.Course) error
 	Publish(ctx context.Context, id uuid.UUID, publishedAt time.Time) error
 	Unpublish(ctx context.Context, id uuid.UUID, updatedAt time.Time) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	// Delete soft-deletes a course; deleting a deleted course keeps its first mark
+	Delete(ctx context.Context, id uuid.UUID, mark softdelete.Mark) error
+	// Restore clears the deletion of a course; restoring a course that is not deleted
+	// does nothing
+	Restore(ctx context.Context, id uuid.UUID) error
 }
 
 type CourseLessonFilter struct {
@@ -81,6 +90,8 @@ type courseDoc struct {
 	CreatedAt     time.Time  `bson:"created_at"`
 	UpdatedAt     time.Time  `bson:"updated_at"`
 	PublishedAt   *time.Time `bson:"published_at,omitempty"`
+	DeletedAt     *time.Time `bson:"deleted_at,omitempty"`
+	DeletedBy     string     `bson:"deleted_by,omitempty"`
 }
 
 func courseDocFromModel(course *models.Course) *courseDoc {
@@ -168,7 +179,7 @@ func (r *courseRepository) Create(ctx context.Context, course *models.Course) er
 
 func (r *courseRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Course, error) {
 	var doc courseDoc
-	err := r.collection.FindOne(ctx, bson.M{"_id": id.String()}).Decode(&doc)
+	err := r.collection.FindOne(ctx, active(bson.M{"_id": id.String()})).Decode(&doc)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, types.ErrCourseNotFound
@@ -178,8 +189,34 @@ func (r *courseRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.C
 	return doc.toModel(), nil
 }
 
+func (r *courseRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]models.Course, error) {
+	items := []models.Course{}
+	if len(ids) == 0 {
+		return items, nil
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = id.String()
+	}
+
+	cursor, err := r.collection.Find(ctx, active(bson.M{"_id": bson.M{"$in": keys}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc courseDoc
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		items = append(items, *doc.toModel())
+	}
+	return items, cursor.Err()
+}
+
 func (r *courseRepository) List(ctx context.Context, filter *CourseFilter, sort *SortOption, limit, offset int) ([]models.Course, int64, error) {
-	filterDoc := bson.M{}
+	filterDoc := active(bson.M{})
 
 	if filter != nil {
 		if filter.TopicID != nil {
@@ -285,7 +322,7 @@ func (r *courseRepository) Update(ctx context.Context, course *models.Course) er
 		update["$unset"] = unset
 	}
 
-	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": course.ID.String()}, update)
+	res, err := r.collection.UpdateOne(ctx, active(bson.M{"_id": course.ID.String()}), update)
 	if err != nil {
 		return err
 	}
@@ -303,7 +340,7 @@ func (r *courseRepository) Publish(ctx context.Context, id uuid.UUID, publishedA
 			"updated_at":   publishedAt,
 		},
 	}
-	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id.String()}, update)
+	res, err := r.collection.UpdateOne(ctx, active(bson.M{"_id": id.String()}), update)
 	if err != nil {
 		return err
 	}
@@ -323,7 +360,7 @@ func (r *courseRepository) Unpublish(ctx context.Context, id uuid.UUID, updatedA
 			"published_at": "",
 		},
 	}
-	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id.String()}, update)
+	res, err := r.collection.UpdateOne(ctx, active(bson.M{"_id": id.String()}), update)
 	if err != nil {
 		return err
 	}
@@ -333,17 +370,45 @@ func (r *courseRepository) Unpublish(ctx context.Context, id uuid.UUID, updatedA
 	return nil
 }
 
-func (r *courseRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": id.String()})
+func (r *courseRepository) Delete(ctx context.Context, id uuid.UUID, mark softdelete.Mark) error {
+	res, err := r.collection.UpdateOne(ctx, active(bson.M{"_id": id.String()}), mark.MongoUpdate())
 	if err != nil {
 		return err
 	}
-	if res.DeletedCount == 0 {
+	if res.MatchedCount == 0 {
+		return r.exists(ctx, id)
+	}
+	return nil
+}
+
+func (r *courseRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id.String()}, softdelete.MongoRestore())
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
 		return types.ErrCourseNotFound
 	}
 	return nil
 }
 
+// exists returns types.ErrCourseNotFound unless a course, deleted or not, has id.
+func (r *courseRepository) exists(ctx context.Context, id uuid.UUID) error {
+	n, err := r.collection.CountDocuments(ctx, bson.M{"_id": id.String()}, options.Count().SetLimit(1))
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return types.ErrCourseNotFound
+	}
+	return nil
+}
+
+// active restricts a filter to the documents that are not deleted.
+func active(filter bson.M) bson.M {
+	return softdelete.MongoScope(filter, softdelete.Active)
+}
+
 type courseLessonDoc struct {
 	ID         string    `bson:"_id"`
 	CourseID   string    `bson:"course_id"`
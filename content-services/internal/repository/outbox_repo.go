@@ -4,40 +4,47 @@ import (
 	"content-services/internal/models"
 	"context"
 
-	"gorm.io/gorm"
+	"github.com/ductan2/microservice-app/shared/outbox"
+	"github.com/ductan2/microservice-app/shared/outbox/mongostore"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type OutboxRepository interface {
+	// Create adds an event to the outbox collection. Called with the session context of
+	// a transaction, the event is committed with it.
 	Create(ctx context.Context, event *models.Outbox) error
-	GetUnpublished(ctx context.Context, limit int) ([]models.Outbox, error)
-	MarkAsPublished(ctx context.Context, id int64) error
-	DeletePublished(ctx context.Context, olderThanID int64) error
+	// Store exposes the outbox collection to the relay that publishes it
+	Store() outbox.Store
 }
 
 type outboxRepository struct {
-	db *gorm.DB
+	store *mongostore.Store
 }
 
-func NewOutboxRepository(db *gorm.DB) OutboxRepository {
-	return &outboxRepository{db: db}
+// NewOutboxRepository creates the indexes the outbox relay queries by.
+func NewOutboxRepository(ctx context.Context, db *mongo.Database) (OutboxRepository, error) {
+	store := mongostore.New(db, mongostore.DefaultCollection)
+	if err := store.EnsureIndexes(ctx); err != nil {
+		return nil, err
+	}
+	return &outboxRepository{store: store}, nil
 }
 
 func (r *outboxRepository) Create(ctx context.Context, event *models.Outbox) error {
-	// TODO: implement
+	msg := &outbox.Message{
+		AggregateID: event.AggregateID,
+		Topic:       event.Topic,
+		Type:        event.Type,
+		Payload:     event.Payload,
+		CreatedAt:   event.CreatedAt,
+	}
+	if err := r.store.Add(ctx, msg); err != nil {
+		return err
+	}
+	event.CreatedAt = msg.CreatedAt
 	return nil
 }
 
-func (r *outboxRepository) GetUnpublished(ctx context.Context, limit int) ([]models.Outbox, error) {
-	// TODO: implement - WHERE published_at IS NULL ORDER BY id LIMIT
-	return nil, nil
-}
-
-func (r *outboxRepository) MarkAsPublished(ctx context.Context, id int64) error {
-	// TODO: implement - UPDATE published_at = now()
-	return nil
-}
-
-func (r *outboxRepository) DeletePublished(ctx context.Context, olderThanID int64) error {
-	// TODO: implement - cleanup old published events
-	return nil
+func (r *outboxRepository) Store() outbox.Store {
+	return r.store
 }
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"content-services/internal/service"
+	"content-services/internal/types"
+
+	"github.com/ductan2/microservice-app/shared/apperr"
+	"github.com/ductan2/microservice-app/shared/batch"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RegisterInternalRoutes adds the REST endpoints other services call besides GraphQL,
+// such as the BFF resolving the courses of a page in one call. They answer
+// {"data": ...} or {"error": {...}}, and only requests with a service token.
+func RegisterInternalRoutes(r *gin.Engine, courses service.CourseService) {
+	internal := r.Group("/internal")
+	internal.Use(serviceAuthRequired())
+	{
+		internal.POST("/courses/batch", batchGetCourses(courses))     // POST /internal/courses/batch
+		internal.POST("/courses/:id/restore", restoreCourse(courses)) // POST /internal/courses/:id/restore
+	}
+}
+
+// serviceAuthRequired refuses the requests serviceAuth served anonymously.
+func serviceAuthRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get("calling_service"); !ok {
+			failJSON(c, apperr.New(apperr.Unauthorized, "internal service credentials are required"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// batchGetCourses resolves up to batch.MaxSize courses, published or not, in one query.
+func batchGetCourses(courses service.CourseService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ids, err := batch.Decode(c.Request.Body, batch.MaxSize)
+		if err != nil {
+			failJSON(c, apperr.New(apperr.BadRequest, err.Error()))
+			return
+		}
+
+		result, err := courses.BatchGetCourses(c.Request.Context(), ids)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "batch course lookup failed", "error", err)
+			failJSON(c, apperr.Wrap(err, apperr.Internal, "failed to retrieve courses"))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": result})
+	}
+}
+
+// restoreCourse restores a deleted course, with its lessons; the GraphQL API has no
+// mutation for it, so admin tools call this endpoint.
+func restoreCourse(courses service.CourseService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			failJSON(c, apperr.New(apperr.BadRequest, "invalid course ID"))
+			return
+		}
+
+		course, err := courses.RestoreCourse(c.Request.Context(), id)
+		if err != nil {
+			if errors.Is(err, types.ErrCourseNotFound) {
+				failJSON(c, apperr.New(apperr.NotFound, "course not found"))
+				return
+			}
+			slog.ErrorContext(c.Request.Context(), "course restore failed", "course_id", id, "error", err)
+			failJSON(c, apperr.Wrap(err, apperr.Internal, "failed to restore course"))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": course})
+	}
+}
+
+func failJSON(c *gin.Context, err *apperr.Error) {
+	c.AbortWithStatusJSON(err.HTTPStatus(), gin.H{"error": err.Public()})
+}
@@ -1,22 +1,45 @@
 package server
 
 import (
+	"log/slog"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/ductan2/microservice-app/shared/apperr"
+	"github.com/ductan2/microservice-app/shared/health"
+	"github.com/ductan2/microservice-app/shared/internalauth"
+	"github.com/ductan2/microservice-app/shared/logging"
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/ductan2/microservice-app/shared/requestmeta"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-// NewRouter configures routes and middleware and returns a Gin engine.
-func NewRouter(graphqlHandler http.Handler) *gin.Engine {
+// NewRouter configures routes and middleware and returns a Gin engine. verifier checks
+// the service tokens of the calls from the BFF and other services; checker serves
+// /livez, /readyz and /healthz. The client IP is read from X-Forwarded-For past proxies.
+func NewRouter(graphqlHandler http.Handler, verifier *internalauth.Verifier, checker *health.Checker, proxies requestmeta.Proxies) *gin.Engine {
 	r := gin.New()
+	if err := proxies.Configure(r); err != nil {
+		slog.Warn("trusted proxies not set", "error", err)
+	}
 	// Middlewares
-	r.Use(gin.Logger())
+	r.Use(requestLog())
+	r.Use(tracing())
+	r.Use(observe())
+	r.Use(serviceAuth(verifier))
+	r.Use(requestContext(proxies))
 	r.Use(gin.Recovery())
 
 	// Routes
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
+	r.GET(health.LivePath, gin.WrapH(checker.Live()))
+	r.GET(health.ReadyPath, gin.WrapH(checker.Ready()))
+	r.GET(health.HealthPath, gin.WrapH(checker.Health()))
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
 
 	if graphqlHandler != nil {
 		r.Any("/graphql", gin.WrapH(graphqlHandler))
@@ -24,3 +47,74 @@ func NewRouter(graphqlHandler http.Handler) *gin.Engine {
 
 	return r
 }
+
+// tracing starts the server span of each request with otelgin, continuing the trace of the caller's
+// traceparent header. Resolvers reach it through the request context.
+func tracing() gin.HandlerFunc {
+	return otelgin.Middleware("content-services")
+}
+
+// observe records the count and latency of each request by matched route.
+func observe() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		metrics.ObserveHTTPServer(c.Request.Method, c.FullPath(), c.Writer.Status(), time.Since(start))
+	}
+}
+
+// serviceAuth checks the service token of the request before the user identity headers
+// are read. Requests without one are served anonymously, their identity headers removed;
+// an invalid token is refused with a GraphQL error.
+func serviceAuth(verifier *internalauth.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := verifier.Authenticate(c.Request)
+		if err != nil {
+			slog.WarnContext(c.Request.Context(), "rejected service token", "error", err, "path", c.Request.URL.Path)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"errors": []gin.H{{
+					"message":    "invalid internal service credentials",
+					"extensions": gin.H{"code": string(apperr.Unauthorized)},
+				}},
+			})
+			return
+		}
+		if claims != nil {
+			c.Set("calling_service", claims.Issuer)
+		}
+		c.Next()
+	}
+}
+
+// requestContext stores the request ID forwarded by the BFF, or a new one, the client IP
+// and the user ID on the request context, so the log records of the request carry them.
+func requestContext(proxies requestmeta.Proxies) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = requestmeta.Attach(c.Writer, c.Request, proxies)
+		ctx := c.Request.Context()
+		if userID := strings.TrimSpace(c.GetHeader("X-User-ID")); userID != "" {
+			ctx = logging.WithUserID(ctx, userID)
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// requestLog writes an access log record for each request once it has been served.
+func requestLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		logging.LogRequest(c.Request.Context(), logging.HTTPRequest{
+			Method:   c.Request.Method,
+			Route:    c.FullPath(),
+			Path:     c.Request.URL.Path,
+			Status:   c.Writer.Status(),
+			Latency:  time.Since(start),
+			ClientIP: c.ClientIP(),
+		})
+	}
+}
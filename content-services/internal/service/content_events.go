@@ -0,0 +1,82 @@
+package service
+
+import (
+	"content-services/internal/models"
+	"content-services/internal/repository"
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/events"
+	"github.com/google/uuid"
+)
+
+// recordEvent adds a content event to the outbox. The change it reports is already
+// saved, so a failure is logged rather than returned.
+func recordEvent(ctx context.Context, outboxRepo repository.OutboxRepository, aggregateID uuid.UUID, event events.Event) {
+	if outboxRepo == nil {
+		return
+	}
+	payload, err := events.Marshal(event)
+	if err == nil {
+		err = outboxRepo.Create(ctx, &models.Outbox{
+			AggregateID: aggregateID,
+			Topic:       event.Topic(),
+			Type:        event.EventType(),
+			Payload:     payload,
+			CreatedAt:   time.Now().UTC(),
+		})
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "failed to record content event", "event_type", event.EventType(), "aggregate_id", aggregateID, "error", err)
+	}
+}
+
+// EventRecorder records content events to outboxRepo, for the packages outside this one
+// that change content, such as the taxonomy store.
+func EventRecorder(outboxRepo repository.OutboxRepository) func(ctx context.Context, aggregateID uuid.UUID, event events.Event) {
+	return func(ctx context.Context, aggregateID uuid.UUID, event events.Event) {
+		recordEvent(ctx, outboxRepo, aggregateID, event)
+	}
+}
+
+// publishedAt is the time t of a published lesson or course, nil otherwise.
+func publishedAt(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func lessonDetails(lesson *models.Lesson) events.LessonDetails {
+	return events.LessonDetails{
+		Code:        lesson.Code,
+		Description: lesson.Description,
+		TopicID:     lesson.TopicID,
+		LevelID:     lesson.LevelID,
+	}
+}
+
+func courseDetails(course *models.Course) events.CourseDetails {
+	return events.CourseDetails{
+		Description:   course.Description,
+		TopicID:       course.TopicID,
+		LevelID:       course.LevelID,
+		InstructorID:  course.InstructorID,
+		ThumbnailURL:  course.ThumbnailURL,
+		IsFeatured:    course.IsFeatured,
+		Price:         course.Price,
+		DurationHours: course.DurationHours,
+		AverageRating: course.AverageRating,
+		ReviewCount:   course.ReviewCount,
+	}
+}
+
+func flashcardSetDetails(set *models.FlashcardSet) events.FlashcardSetDetails {
+	return events.FlashcardSetDetails{
+		Description: set.Description,
+		TopicID:     set.TopicID,
+		LevelID:     set.LevelID,
+	}
+}
@@ -7,6 +7,9 @@ import (
 	"database/sql"
 	"time"
 
+	"github.com/ductan2/microservice-app/shared/batch"
+	"github.com/ductan2/microservice-app/shared/events"
+	"github.com/ductan2/microservice-app/shared/softdelete"
 	"github.com/google/uuid"
 )
 
@@ -30,11 +33,17 @@ type CourseLessonUpdate struct {
 type CourseService interface {
 	CreateCourse(ctx context.Context, course *models.Course) (*models.Course, error)
 	GetCourseByID(ctx context.Context, id uuid.UUID) (*models.Course, error)
+	// BatchGetCourses resolves the courses of ids, checked by batch.IDs, in one query.
+	// IDs that are not UUIDs are reported missing.
+	BatchGetCourses(ctx context.Context, ids []string) (batch.Response[models.Course], error)
 	ListCourses(ctx context.Context, filter *repository.CourseFilter, sort *repository.SortOption, page, pageSize int) ([]models.Course, int64, error)
 	UpdateCourse(ctx context.Context, id uuid.UUID, updates *CourseUpdate) (*models.Course, error)
 	PublishCourse(ctx context.Context, id uuid.UUID) (*models.Course, error)
 	UnpublishCourse(ctx context.Context, id uuid.UUID) (*models.Course, error)
-	DeleteCourse(ctx context.Context, id uuid.UUID) error
+	// DeleteCourse soft-deletes a course, keeping its lessons for RestoreCourse. deletedBy
+	// is the ID of the user deleting it, "" for the system.
+	DeleteCourse(ctx context.Context, id uuid.UUID, deletedBy string) error
+	RestoreCourse(ctx context.Context, id uuid.UUID) (*models.Course, error)
 
 	AddCourseLesson(ctx context.Context, courseID uuid.UUID, lesson *models.CourseLesson) (*models.CourseLesson, error)
 	UpdateCourseLesson(ctx context.Context, id uuid.UUID, updates *CourseLessonUpdate) (*models.CourseLesson, error)
@@ -47,13 +56,15 @@ type courseService struct {
 	courseRepo       repository.CourseRepository
 	courseLessonRepo repository.CourseLessonRepository
 	lessonRepo       repository.LessonRepository
+	outboxRepo       repository.OutboxRepository
 }
 
-func NewCourseService(courseRepo repository.CourseRepository, courseLessonRepo repository.CourseLessonRepository, lessonRepo repository.LessonRepository) CourseService {
+func NewCourseService(courseRepo repository.CourseRepository, courseLessonRepo repository.CourseLessonRepository, lessonRepo repository.LessonRepository, outboxRepo repository.OutboxRepository) CourseService {
 	return &courseService{
 		courseRepo:       courseRepo,
 		courseLessonRepo: courseLessonRepo,
 		lessonRepo:       lessonRepo,
+		outboxRepo:       outboxRepo,
 	}
 }
 
@@ -79,6 +90,23 @@ func (s *courseService) GetCourseByID(ctx context.Context, id uuid.UUID) (*model
 	return s.courseRepo.GetByID(ctx, id)
 }
 
+func (s *courseService) BatchGetCourses(ctx context.Context, ids []string) (batch.Response[models.Course], error) {
+	// requested maps each course ID to the way the caller spelled it
+	requested := make(map[uuid.UUID]string, len(ids))
+	courseIDs := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if courseID, err := uuid.Parse(id); err == nil {
+			requested[courseID] = id
+			courseIDs = append(courseIDs, courseID)
+		}
+	}
+	courses, err := s.courseRepo.GetByIDs(ctx, courseIDs)
+	if err != nil {
+		return batch.Response[models.Course]{}, err
+	}
+	return batch.Collect(ids, courses, func(course models.Course) string { return requested[course.ID] }), nil
+}
+
 func (s *courseService) ListCourses(ctx context.Context, filter *repository.CourseFilter, sort *repository.SortOption, page, pageSize int) ([]models.Course, int64, error) {
 	if page < 1 {
 		page = 1
@@ -143,6 +171,8 @@ func (s *courseService) UpdateCourse(ctx context.Context, id uuid.UUID, updates
 		return nil, err
 	}
 
+	s.recordUpdated(ctx, course)
+
 	return course, nil
 }
 
@@ -151,7 +181,19 @@ func (s *courseService) PublishCourse(ctx context.Context, id uuid.UUID) (*model
 	if err := s.courseRepo.Publish(ctx, id, now); err != nil {
 		return nil, err
 	}
-	return s.courseRepo.GetByID(ctx, id)
+	course, err := s.courseRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	recordEvent(ctx, s.outboxRepo, id, &events.CoursePublished{
+		CourseID:      id,
+		Title:         course.Title,
+		PublishedAt:   course.PublishedAt.Time,
+		CourseDetails: courseDetails(course),
+	})
+
+	return course, nil
 }
 
 func (s *courseService) UnpublishCourse(ctx context.Context, id uuid.UUID) (*models.Course, error) {
@@ -159,19 +201,51 @@ func (s *courseService) UnpublishCourse(ctx context.Context, id uuid.UUID) (*mod
 	if err := s.courseRepo.Unpublish(ctx, id, now); err != nil {
 		return nil, err
 	}
-	return s.courseRepo.GetByID(ctx, id)
+	course, err := s.courseRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	recordEvent(ctx, s.outboxRepo, id, &events.CourseUnpublished{CourseID: id})
+
+	return course, nil
 }
 
-func (s *courseService) DeleteCourse(ctx context.Context, id uuid.UUID) error {
-	if err := s.courseRepo.Delete(ctx, id); err != nil {
+func (s *courseService) DeleteCourse(ctx context.Context, id uuid.UUID, deletedBy string) error {
+	if err := s.courseRepo.Delete(ctx, id, softdelete.Now(deletedBy)); err != nil {
 		return err
 	}
-	if s.courseLessonRepo != nil {
-		_ = s.courseLessonRepo.DeleteByCourseID(ctx, id)
-	}
+
+	recordEvent(ctx, s.outboxRepo, id, &events.CourseDeleted{CourseID: id})
+
 	return nil
 }
 
+func (s *courseService) RestoreCourse(ctx context.Context, id uuid.UUID) (*models.Course, error) {
+	if err := s.courseRepo.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+	course, err := s.courseRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordUpdated(ctx, course)
+
+	return course, nil
+}
+
+// recordUpdated records a CourseUpdated event with the current fields of course.
+func (s *courseService) recordUpdated(ctx context.Context, course *models.Course) {
+	recordEvent(ctx, s.outboxRepo, course.ID, &events.CourseUpdated{
+		CourseID:      course.ID,
+		Title:         course.Title,
+		IsPublished:   course.IsPublished,
+		PublishedAt:   publishedAt(course.PublishedAt),
+		CourseDetails: courseDetails(course),
+	})
+}
+
 func (s *courseService) AddCourseLesson(ctx context.Context, courseID uuid.UUID, lesson *models.CourseLesson) (*models.CourseLesson, error) {
 	if _, err := s.courseRepo.GetByID(ctx, courseID); err != nil {
 		return nil, err
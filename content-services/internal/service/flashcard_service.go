@@ -7,6 +7,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/ductan2/microservice-app/shared/events"
 	"github.com/google/uuid"
 )
 
@@ -29,20 +30,23 @@ type FlashcardService interface {
 }
 
 type flashcardService struct {
-	setRepo  repository.FlashcardSetRepository
-	cardRepo repository.FlashcardRepository
-	tagRepo  repository.TagRepository
+	setRepo    repository.FlashcardSetRepository
+	cardRepo   repository.FlashcardRepository
+	tagRepo    repository.TagRepository
+	outboxRepo repository.OutboxRepository
 }
 
 func NewFlashcardService(
 	setRepo repository.FlashcardSetRepository,
 	cardRepo repository.FlashcardRepository,
 	tagRepo repository.TagRepository,
+	outboxRepo repository.OutboxRepository,
 ) FlashcardService {
 	return &flashcardService{
-		setRepo:  setRepo,
-		cardRepo: cardRepo,
-		tagRepo:  tagRepo,
+		setRepo:    setRepo,
+		cardRepo:   cardRepo,
+		tagRepo:    tagRepo,
+		outboxRepo: outboxRepo,
 	}
 }
 
@@ -69,6 +73,12 @@ func (s *flashcardService) CreateSet(ctx context.Context, set *models.FlashcardS
 			}
 		}
 	}
+	recordEvent(ctx, s.outboxRepo, set.ID, &events.FlashcardSetCreated{
+		SetID:               set.ID,
+		Title:               set.Title,
+		CreatedAt:           set.CreatedAt,
+		FlashcardSetDetails: flashcardSetDetails(set),
+	})
 	return set, nil
 }
 
@@ -102,6 +112,12 @@ func (s *flashcardService) UpdateSet(ctx context.Context, id uuid.UUID, updates
 	if err := s.setRepo.Update(ctx, current); err != nil {
 		return nil, err
 	}
+	recordEvent(ctx, s.outboxRepo, id, &events.FlashcardSetUpdated{
+		SetID:               id,
+		Title:               current.Title,
+		CreatedAt:           current.CreatedAt,
+		FlashcardSetDetails: flashcardSetDetails(current),
+	})
 	return current, nil
 }
 
@@ -109,6 +125,7 @@ func (s *flashcardService) DeleteSet(ctx context.Context, id uuid.UUID) error {
 	if err := s.setRepo.Delete(ctx, id); err != nil {
 		return err
 	}
+	recordEvent(ctx, s.outboxRepo, id, &events.FlashcardSetDeleted{SetID: id})
 	if s.tagRepo != nil {
 		tags, err := s.tagRepo.GetContentTags(ctx, "flashcard_set", id)
 		if err != nil {
@@ -137,6 +154,7 @@ func (s *flashcardService) AddCard(ctx context.Context, setID uuid.UUID, card *m
 	if err := s.cardRepo.Create(ctx, card); err != nil {
 		return nil, err
 	}
+	s.recordCardSaved(ctx, card)
 	return card, nil
 }
 
@@ -170,9 +188,19 @@ func (s *flashcardService) UpdateCard(ctx context.Context, id uuid.UUID, updates
 	if err := s.cardRepo.Update(ctx, current); err != nil {
 		return nil, err
 	}
+	s.recordCardSaved(ctx, current)
 	return current, nil
 }
 
+func (s *flashcardService) recordCardSaved(ctx context.Context, card *models.Flashcard) {
+	recordEvent(ctx, s.outboxRepo, card.SetID, &events.FlashcardSaved{
+		FlashcardID: card.ID,
+		SetID:       card.SetID,
+		FrontText:   card.FrontText,
+		BackText:    card.BackText,
+	})
+}
+
 func (s *flashcardService) ReorderCards(ctx context.Context, setID uuid.UUID, cardIDs []uuid.UUID) ([]models.Flashcard, error) {
 	if err := s.cardRepo.Reorder(ctx, setID, cardIDs); err != nil {
 		return nil, err
@@ -182,7 +210,15 @@ func (s *flashcardService) ReorderCards(ctx context.Context, setID uuid.UUID, ca
 }
 
 func (s *flashcardService) DeleteCard(ctx context.Context, id uuid.UUID) error {
-	return s.cardRepo.Delete(ctx, id)
+	card, err := s.cardRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.cardRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	recordEvent(ctx, s.outboxRepo, card.SetID, &events.FlashcardDeleted{FlashcardID: id, SetID: card.SetID})
+	return nil
 }
 
 func (s *flashcardService) GetSetCards(ctx context.Context, setID uuid.UUID) ([]models.Flashcard, error) {
@@ -6,6 +6,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/ductan2/microservice-app/shared/events"
 	"github.com/google/uuid"
 )
 
@@ -63,15 +64,7 @@ func (s *lessonService) CreateLesson(ctx context.Context, lesson *models.Lesson,
 
 	// TODO: Add tags to content_tags table if tagIDs provided
 
-	// TODO: Publish outbox event for lesson created
-	// event := &models.Outbox{
-	// 	AggregateID: lesson.ID,
-	// 	Topic:       "content.events",
-	// 	Type:        "LessonCreated",
-	// 	Payload:     map[string]any{"lesson_id": lesson.ID.String(), "title": lesson.Title},
-	// 	CreatedAt:   now,
-	// }
-	// s.outboxRepo.Create(ctx, event)
+	recordEvent(ctx, s.outboxRepo, lesson.ID, &events.LessonCreated{LessonID: lesson.ID, Title: lesson.Title})
 
 	return lesson, nil
 }
@@ -132,7 +125,13 @@ func (s *lessonService) UpdateLesson(ctx context.Context, id uuid.UUID, updates
 		return nil, err
 	}
 
-	// TODO: Publish outbox event for lesson updated
+	recordEvent(ctx, s.outboxRepo, id, &events.LessonUpdated{
+		LessonID:      id,
+		Title:         existing.Title,
+		IsPublished:   existing.IsPublished,
+		PublishedAt:   publishedAt(existing.PublishedAt),
+		LessonDetails: lessonDetails(existing),
+	})
 
 	return existing, nil
 }
@@ -149,19 +148,12 @@ func (s *lessonService) PublishLesson(ctx context.Context, id uuid.UUID) (*model
 		return nil, err
 	}
 
-	// TODO: Publish outbox event for lesson published
-	// event := &models.Outbox{
-	// 	AggregateID: id,
-	// 	Topic:       "content.events",
-	// 	Type:        "LessonPublished",
-	// 	Payload: map[string]any{
-	// 		"lesson_id": id.String(),
-	// 		"title":     lesson.Title,
-	// 		"published_at": lesson.PublishedAt.Time,
-	// 	},
-	// 	CreatedAt: time.Now().UTC(),
-	// }
-	// s.outboxRepo.Create(ctx, event)
+	recordEvent(ctx, s.outboxRepo, id, &events.LessonPublished{
+		LessonID:      id,
+		Title:         lesson.Title,
+		PublishedAt:   lesson.PublishedAt.Time,
+		LessonDetails: lessonDetails(lesson),
+	})
 
 	return lesson, nil
 }
@@ -176,7 +168,7 @@ func (s *lessonService) UnpublishLesson(ctx context.Context, id uuid.UUID) (*mod
 		return nil, err
 	}
 
-	// TODO: Publish outbox event for lesson unpublished
+	recordEvent(ctx, s.outboxRepo, id, &events.LessonUnpublished{LessonID: id})
 
 	return lesson, nil
 }
@@ -187,15 +179,7 @@ func (s *lessonService) DeleteLesson(ctx context.Context, id uuid.UUID) error {
 		return err
 	}
 
-	// TODO: Publish outbox event for lesson deleted
-	// event := &models.Outbox{
-	// 	AggregateID: id,
-	// 	Topic:       "content.events",
-	// 	Type:        "LessonDeleted",
-	// 	Payload:     map[string]any{"lesson_id": id.String()},
-	// 	CreatedAt:   time.Now().UTC(),
-	// }
-	// s.outboxRepo.Create(ctx, event)
+	recordEvent(ctx, s.outboxRepo, id, &events.LessonDeleted{LessonID: id})
 
 	return nil
 }
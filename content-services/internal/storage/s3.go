@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
 	"net/url"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/ductan2/microservice-app/shared/metrics"
 )
 
 // S3Config contains the configuration needed to connect to an S3 compatible service.
@@ -38,7 +40,9 @@ func NewS3Client(ctx context.Context, cfg S3Config) (*S3Client, error) {
 		return nil, fmt.Errorf("s3: bucket is required")
 	}
 
-	loadOpts := []func(*config.LoadOptions) error{}
+	loadOpts := []func(*config.LoadOptions) error{
+		config.WithHTTPClient(&http.Client{Transport: metrics.NewTransport("s3", nil)}),
+	}
 	if cfg.Region != "" {
 		region := cfg.Region
 		loadOpts = append(loadOpts, func(o *config.LoadOptions) error {
@@ -99,6 +103,12 @@ func NewS3Client(ctx context.Context, cfg S3Config) (*S3Client, error) {
 	}, nil
 }
 
+// Ping checks that the bucket exists and is reachable with the configured credentials.
+func (c *S3Client) Ping(ctx context.Context) error {
+	_, err := c.client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(c.bucket)})
+	return err
+}
+
 // PutObject uploads data to the configured bucket at the provided key.
 func (c *S3Client) PutObject(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
 	if c == nil {
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ductan2/microservice-app/shared/events"
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -25,83 +26,44 @@ type Store struct {
 	levels  *mongo.Collection
 	tags    *mongo.Collection
 	lessons *mongo.Collection
+
+	// record adds the events of topic and level changes to the outbox; see RecordEvents
+	record func(ctx context.Context, aggregateID uuid.UUID, event events.Event)
 }
 
-// NewStore prepares a taxonomy store backed by Mongo collections and ensures indexes.
-func NewStore(ctx context.Context, db *mongo.Database) (*Store, error) {
-	store := &Store{
+// NewStore returns a taxonomy store backed by Mongo collections. Their indexes are
+// created by the migrations of the service.
+func NewStore(db *mongo.Database) *Store {
+	return &Store{
 		topics:  db.Collection("topics"),
 		levels:  db.Collection("levels"),
 		tags:    db.Collection("tags"),
 		lessons: db.Collection("lessons"),
 	}
+}
 
-	if err := store.ensureIndexes(ctx); err != nil {
-		return nil, err
+// RecordEvents makes the store report the topics and levels it saves or deletes to
+// record, such as service.EventRecorder, for search-services to name them in its facets.
+func (s *Store) RecordEvents(record func(ctx context.Context, aggregateID uuid.UUID, event events.Event)) {
+	s.record = record
+}
+
+// recordEvent reports a change of a taxonomy document, when RecordEvents was set. IDs
+// are UUIDs: the event of a document with another ID fails validation and is logged.
+func (s *Store) recordEvent(ctx context.Context, id uuid.UUID, event events.Event) {
+	if s.record != nil {
+		s.record(ctx, id, event)
 	}
+}
 
-	return store, nil
+func (s *Store) recordTopic(ctx context.Context, doc *Topic) {
+	topicID, _ := uuid.Parse(doc.ID)
+	s.recordEvent(ctx, topicID, &events.TopicSaved{TopicID: topicID, Slug: doc.Slug, Name: doc.Name})
 }
 
-func (s *Store) ensureIndexes(ctx context.Context) error {
-	models := []struct {
-		collection *mongo.Collection
-		indexes    []mongo.IndexModel
-	}{
-		{
-			collection: s.topics,
-			indexes: []mongo.IndexModel{
-				{Keys: bson.D{{Key: "slug", Value: 1}}, Options: options.Index().SetUnique(true)},
-				{Keys: bson.D{{Key: "created_at", Value: -1}}},
-			},
-		},
-		{
-			collection: s.levels,
-			indexes: []mongo.IndexModel{
-				{Keys: bson.D{{Key: "code", Value: 1}}, Options: options.Index().SetUnique(true)},
-				{Keys: bson.D{{Key: "name", Value: 1}}},
-			},
-		},
-		{
-			collection: s.tags,
-			indexes: []mongo.IndexModel{
-				{Keys: bson.D{{Key: "slug", Value: 1}}, Options: options.Index().SetUnique(true)},
-				{Keys: bson.D{{Key: "name", Value: 1}}},
-			},
-		},
-		{
-			collection: s.lessons,
-			indexes: []mongo.IndexModel{
-				{
-					Keys:    bson.D{{Key: "code", Value: 1}},
-					Options: options.Index().SetUnique(true).SetSparse(true),
-				},
-				{
-					Keys: bson.D{
-						{Key: "topic_id", Value: 1},
-						{Key: "level_id", Value: 1},
-						{Key: "is_published", Value: 1},
-					},
-				},
-				{Keys: bson.D{{Key: "created_at", Value: -1}}},
-				{Keys: bson.D{{Key: "updated_at", Value: -1}}},
-			},
-		},
-	}
-
-	for _, model := range models {
-		if len(model.indexes) == 0 {
-			continue
-		}
-		if _, err := model.collection.Indexes().CreateMany(ctx, model.indexes); err != nil {
-			if mongo.IsDuplicateKeyError(err) {
-				// Index already exists, ignore.
-				continue
-			}
-			return err
-		}
-	}
-	return nil
+func (s *Store) recordLevel(ctx context.Context, doc *Level) {
+	levelID, _ := uuid.Parse(doc.ID)
+	s.recordEvent(ctx, levelID, &events.LevelSaved{LevelID: levelID, Code: doc.Code, Name: doc.Name})
 }
 
 // Topic represents a topic document stored in Mongo.
@@ -162,6 +124,7 @@ func (s *Store) CreateTopic(ctx context.Context, slug, name string) (*Topic, err
 		}
 		return nil, err
 	}
+	s.recordTopic(ctx, &doc)
 	return &doc, nil
 }
 
@@ -194,6 +157,7 @@ func (s *Store) UpdateTopic(ctx context.Context, id string, slug, name *string)
 	if err := res.Decode(&doc); err != nil {
 		return nil, err
 	}
+	s.recordTopic(ctx, &doc)
 	return &doc, nil
 }
 
@@ -280,6 +244,8 @@ func (s *Store) DeleteTopic(ctx context.Context, id string) error {
 	if res.DeletedCount == 0 {
 		return ErrNotFound
 	}
+	topicID, _ := uuid.Parse(id)
+	s.recordEvent(ctx, topicID, &events.TopicDeleted{TopicID: topicID})
 	return nil
 }
 
@@ -296,6 +262,7 @@ func (s *Store) CreateLevel(ctx context.Context, code, name string) (*Level, err
 		}
 		return nil, err
 	}
+	s.recordLevel(ctx, &doc)
 	return &doc, nil
 }
 
@@ -327,6 +294,7 @@ func (s *Store) UpdateLevel(ctx context.Context, id string, code, name *string)
 	if err := res.Decode(&doc); err != nil {
 		return nil, err
 	}
+	s.recordLevel(ctx, &doc)
 	return &doc, nil
 }
 
@@ -413,6 +381,8 @@ func (s *Store) DeleteLevel(ctx context.Context, id string) error {
 	if res.DeletedCount == 0 {
 		return ErrNotFound
 	}
+	levelID, _ := uuid.Parse(id)
+	s.recordEvent(ctx, levelID, &events.LevelDeleted{LevelID: levelID})
 	return nil
 }
 
@@ -1,14 +1,53 @@
 package utils
 
 import (
+	"content-services/internal/taxonomy"
+	"content-services/internal/types"
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 
-	"content-services/internal/taxonomy"
-	"content-services/internal/types"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/ductan2/microservice-app/shared/apperr"
 	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
+// gqlError builds a GraphQL error carrying the canonical code of the shared error model,
+// and the reason when set, in its extensions so clients branch on them rather than on
+// the message.
+func gqlError(code apperr.Code, reason, format string, args ...interface{}) *gqlerror.Error {
+	err := gqlerror.Errorf(format, args...)
+	err.Extensions = map[string]interface{}{"code": string(code)}
+	if reason != "" {
+		err.Extensions["reason"] = reason
+	}
+	return err
+}
+
+// ErrorPresenter presents the *apperr.Error values returned by resolvers with their code
+// and reason in the extensions, hiding the message of internal errors. Other errors get
+// the default presentation.
+func ErrorPresenter(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+	appErr, ok := apperr.As(err)
+	if !ok {
+		return gqlErr
+	}
+	if appErr.Internal() {
+		slog.ErrorContext(ctx, "graphql internal error", "error", err, "path", gqlErr.Path.String())
+	}
+	gqlErr.Message = appErr.Public().Message
+	if gqlErr.Extensions == nil {
+		gqlErr.Extensions = map[string]interface{}{}
+	}
+	gqlErr.Extensions["code"] = string(appErr.Code)
+	if appErr.Reason != "" {
+		gqlErr.Extensions["reason"] = appErr.Reason
+	}
+	return gqlErr
+}
+
 // MapTaxonomyError maps taxonomy store errors to GraphQL errors
 func MapTaxonomyError(resource string, err error) error {
 	if err == nil {
@@ -16,9 +55,9 @@ func MapTaxonomyError(resource string, err error) error {
 	}
 	switch {
 	case errors.Is(err, taxonomy.ErrDuplicate):
-		return gqlerror.Errorf("%s already exists", resource)
+		return gqlError(apperr.Conflict, "", "%s already exists", resource)
 	case errors.Is(err, taxonomy.ErrNotFound):
-		return gqlerror.Errorf("%s not found", resource)
+		return gqlError(apperr.NotFound, "", "%s not found", resource)
 	default:
 		return err
 	}
@@ -32,11 +71,11 @@ func MapLessonError(err error) error {
 
 	switch {
 	case errors.Is(err, types.ErrLessonNotFound):
-		return gqlerror.Errorf("lesson not found")
+		return gqlError(apperr.NotFound, "LESSON_NOT_FOUND", "lesson not found")
 	case errors.Is(err, types.ErrDuplicateCode):
-		return gqlerror.Errorf("lesson code already exists")
+		return gqlError(apperr.Conflict, "DUPLICATE_LESSON_CODE", "lesson code already exists")
 	case errors.Is(err, types.ErrAlreadyPublished):
-		return gqlerror.Errorf("lesson is already published")
+		return gqlError(apperr.Conflict, "LESSON_ALREADY_PUBLISHED", "lesson is already published")
 	default:
 		return err
 	}
@@ -50,7 +89,7 @@ func MapLessonSectionError(err error) error {
 
 	switch {
 	case errors.Is(err, types.ErrLessonSectionNotFound):
-		return gqlerror.Errorf("lesson section not found")
+		return gqlError(apperr.NotFound, "LESSON_SECTION_NOT_FOUND", "lesson section not found")
 	default:
 		return MapLessonError(err)
 	}
@@ -64,7 +103,7 @@ func MapCourseError(err error) error {
 
 	switch {
 	case errors.Is(err, types.ErrCourseNotFound):
-		return gqlerror.Errorf("course not found")
+		return gqlError(apperr.NotFound, "COURSE_NOT_FOUND", "course not found")
 	default:
 		return err
 	}
@@ -78,11 +117,11 @@ func MapCourseLessonError(err error) error {
 
 	switch {
 	case errors.Is(err, types.ErrCourseLessonNotFound):
-		return gqlerror.Errorf("course lesson not found")
+		return gqlError(apperr.NotFound, "COURSE_LESSON_NOT_FOUND", "course lesson not found")
 	case errors.Is(err, types.ErrCourseLessonExists):
-		return gqlerror.Errorf("course lesson already exists")
+		return gqlError(apperr.Conflict, "COURSE_LESSON_EXISTS", "course lesson already exists")
 	case errors.Is(err, types.ErrLessonNotFound):
-		return gqlerror.Errorf("lesson not found")
+		return gqlError(apperr.NotFound, "LESSON_NOT_FOUND", "lesson not found")
 	default:
 		return MapCourseError(err)
 	}
@@ -96,11 +135,11 @@ func MapCourseReviewError(err error) error {
 
 	switch {
 	case errors.Is(err, types.ErrCourseReviewInvalidRating):
-		return gqlerror.Errorf("rating must be between 1 and 5")
+		return gqlError(apperr.BadRequest, "INVALID_RATING", "rating must be between 1 and 5")
 	case errors.Is(err, types.ErrCourseReviewNotEnrolled):
-		return gqlerror.Errorf("enrollment required to review this course")
+		return gqlError(apperr.Forbidden, "ENROLLMENT_REQUIRED", "enrollment required to review this course")
 	case errors.Is(err, types.ErrCourseReviewNotFound):
-		return gqlerror.Errorf("course review not found")
+		return gqlError(apperr.NotFound, "COURSE_REVIEW_NOT_FOUND", "course review not found")
 	default:
 		return err
 	}
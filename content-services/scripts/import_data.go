@@ -122,10 +122,10 @@ func main() {
 
 	// Initialize repositories and taxonomy store
 	ctx := context.Background()
-	store, err := taxonomy.NewStore(ctx, mongoDB)
-	if err != nil {
-		log.Fatalf("Error initializing taxonomy store: %v", err)
+	if err := db.RunMigrations(ctx, mongoDB, true); err != nil {
+		log.Fatalf("Error migrating MongoDB: %v", err)
 	}
+	store := taxonomy.NewStore(mongoDB)
 	folderRepo := repository.NewFolderRepository(mongoDB)
 	mediaRepo := repository.NewMediaRepository(mongoDB)
 	lessonRepo := repository.NewLessonRepository(mongoDB)
@@ -13,6 +13,7 @@ fi
 USER_DB="${POSTGRES_DB_USER_SERVICES:-lms_user_services}"
 ORDER_DB="${POSTGRES_DB_ORDER_SERVICES:-lms_order_serivecs}"
 LESSON_DB="${POSTGRES_DB_LESSON_SERVICES:-lms_lesson_service}"
+RECOMMENDATION_DB="${POSTGRES_DB_RECOMMENDATION_SERVICES:-lms_recommendation_services}"
 
 POSTGRES_USER="${POSTGRES_USER:-user}"
 POSTGRES_PASSWORD="${POSTGRES_PASSWORD:-password}"
@@ -53,12 +54,14 @@ create_db() {
 create_db "$USER_DB"
 create_db "$ORDER_DB"
 create_db "$LESSON_DB"
+create_db "$RECOMMENDATION_DB"
 
 echo ""
 echo "✅ Databases created/verified successfully:"
 echo "   - $USER_DB (user-services)"
 echo "   - $ORDER_DB (order-services)"
 echo "   - $LESSON_DB (lesson-services)"
+echo "   - $RECOMMENDATION_DB (recommendation-services)"
 echo ""
 echo "You can verify by running:"
 echo "  docker exec -it postgres psql -U $POSTGRES_USER -c '\l'"
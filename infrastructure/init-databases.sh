@@ -8,6 +8,7 @@ set -e
 USER_DB="${POSTGRES_DB_USER_SERVICES:-lms_english_app}"
 ORDER_DB="${POSTGRES_DB_ORDER_SERVICES:-lms_order_serivecs}"
 LESSON_DB="${POSTGRES_DB_LESSON_SERVICES:-lms_lesson_service}"
+RECOMMENDATION_DB="${POSTGRES_DB_RECOMMENDATION_SERVICES:-lms_recommendation_services}"
 
 psql -v ON_ERROR_STOP=1 --username "$POSTGRES_USER" --dbname "$POSTGRES_DB" <<-EOSQL
     -- Create database for user-services
@@ -19,14 +20,19 @@ psql -v ON_ERROR_STOP=1 --username "$POSTGRES_USER" --dbname "$POSTGRES_DB" <<-E
     -- Create database for lesson-services
     CREATE DATABASE "$LESSON_DB";
     
+    -- Create database for recommendation-services
+    CREATE DATABASE "$RECOMMENDATION_DB";
+    
     -- Grant all privileges to the postgres user
     GRANT ALL PRIVILEGES ON DATABASE "$USER_DB" TO "$POSTGRES_USER";
     GRANT ALL PRIVILEGES ON DATABASE "$ORDER_DB" TO "$POSTGRES_USER";
     GRANT ALL PRIVILEGES ON DATABASE "$LESSON_DB" TO "$POSTGRES_USER";
+    GRANT ALL PRIVILEGES ON DATABASE "$RECOMMENDATION_DB" TO "$POSTGRES_USER";
 EOSQL
 
 echo "✅ Databases created successfully:"
 echo "   - $USER_DB (user-services)"
 echo "   - $ORDER_DB (order-services)"
 echo "   - $LESSON_DB (lesson-services)"
+echo "   - $RECOMMENDATION_DB (recommendation-services)"
 
@@ -0,0 +1,50 @@
+"""Add the columns outbox-relay keeps the delivery state of the outbox in
+
+Revision ID: 3f2a8c1d7e94
+Revises: bb1d5c959a8a
+Create Date: 2026-10-16 09:12:40.518233
+
+"""
+
+import sqlalchemy as sa
+from alembic import op
+
+# revision identifiers, used by Alembic.
+revision = "3f2a8c1d7e94"
+down_revision = "bb1d5c959a8a"
+branch_labels = None
+depends_on = None
+
+
+def upgrade() -> None:
+    # outbox-relay claims the due rows of the outbox (see shared/outbox/gormstore)
+    op.alter_column("outbox", "id", type_=sa.BigInteger())
+    op.add_column("outbox", sa.Column("attempts", sa.Integer(), nullable=False, server_default="0"))
+    op.add_column("outbox", sa.Column("last_error", sa.Text(), nullable=True))
+    op.add_column(
+        "outbox",
+        sa.Column(
+            "next_attempt_at",
+            sa.DateTime(timezone=True),
+            nullable=False,
+            server_default=sa.func.now(),
+        ),
+    )
+    op.add_column("outbox", sa.Column("failed_at", sa.DateTime(timezone=True), nullable=True))
+    op.add_column("outbox", sa.Column("trace_parent", sa.Text(), nullable=False, server_default=""))
+    op.create_index(
+        "outbox_due_idx",
+        "outbox",
+        ["next_attempt_at", "created_at"],
+        postgresql_where=sa.text("published_at IS NULL AND failed_at IS NULL"),
+    )
+
+
+def downgrade() -> None:
+    op.drop_index("outbox_due_idx", table_name="outbox")
+    op.drop_column("outbox", "trace_parent")
+    op.drop_column("outbox", "failed_at")
+    op.drop_column("outbox", "next_attempt_at")
+    op.drop_column("outbox", "last_error")
+    op.drop_column("outbox", "attempts")
+    op.alter_column("outbox", "id", type_=sa.Integer())
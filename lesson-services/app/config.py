@@ -1,5 +1,6 @@
 import os
 from typing import Optional
+from urllib.parse import quote
 
 from fastapi import status
 from pydantic_settings import BaseSettings
@@ -19,13 +20,24 @@ class Settings(BaseSettings):
     redis_host: str = "localhost"
     redis_exporter_port: int = 9121
     
-    # RabbitMQ settings (for future use)
+    # RabbitMQ settings
+    rabbitmq_host: str = "localhost"
     rabbitmq_user: str = "user"
     rabbitmq_password: str = "password"
     rabbitmq_vhost: str = "/"
     rabbitmq_port: int = 5672
     rabbitmq_mgmt_port: int = 15672
     rabbitmq_exporter_port: int = 9419
+    # user.erasure_requested is consumed from the exchange of user-services
+    rabbitmq_user_events_exchange: str = "notifications"
+    rabbitmq_erasure_queue: str = "lesson-services.user-erasures"
+    erasure_consumer_enabled: bool = True
+
+    # Service tokens of shared/internalauth, for the calls to user-services
+    user_service_url: str = "http://user-services:8001"
+    internal_auth_keys: str = ""
+    internal_auth_key_id: str = ""
+    internal_auth_ttl: str = "1m"
     
     # Application settings
     secret_key: str = "your-secret-key-change-in-production"
@@ -45,6 +57,11 @@ class Settings(BaseSettings):
             return f"redis://:{self.redis_password}@{self.redis_host}:{self.redis_port}"
         return f"redis://{self.redis_host}:{self.redis_port}"
     
+    @property
+    def rabbitmq_url(self) -> str:
+        vhost = quote(self.rabbitmq_vhost, safe="")
+        return f"amqp://{self.rabbitmq_user}:{self.rabbitmq_password}@{self.rabbitmq_host}:{self.rabbitmq_port}/{vhost}"
+
     class Config:
         env_file = ".env"
         extra = "ignore"  # Ignore extra environment variables
@@ -0,0 +1,82 @@
+"""Service tokens of the Go services (shared/internalauth): HS256 JWTs in the
+X-Service-Token header, naming the calling service (iss) and the one called (aud).
+lesson-services only signs them, for its calls to user-services; keep in step with
+notification-services/src/internalAuth.ts."""
+import base64
+import hashlib
+import hmac
+import json
+import logging
+import re
+import secrets
+import time
+from typing import Dict, Tuple
+
+from app.config import settings
+
+logger = logging.getLogger(__name__)
+
+SERVICE = "lesson-services"
+HEADER = "X-Service-Token"
+
+# Every service falls back to the same key without INTERNAL_AUTH_KEYS, outside production
+_DEVELOPMENT_KEY_ID = "development"
+_DEVELOPMENT_KEY = b"internal-auth-development-key-do-not-use-in-production"
+_MIN_KEY_LENGTH = 32
+
+
+def _parse_duration(value: str) -> int:
+    """Seconds of the Go durations of INTERNAL_AUTH_TTL, such as "90s" or "1m"."""
+    match = re.fullmatch(r"(\d+)(s|m|h)", value.strip())
+    if not match:
+        return 60
+    return int(match.group(1)) * {"s": 1, "m": 60, "h": 3600}[match.group(2)]
+
+
+def _load_keys() -> Tuple[Dict[str, bytes], str]:
+    raw = settings.internal_auth_keys.strip()
+    if not raw:
+        if settings.environment.lower() == "production":
+            raise RuntimeError("INTERNAL_AUTH_KEYS must be set in production")
+        logger.warning("INTERNAL_AUTH_KEYS is not set; service tokens are signed with the development key")
+        return {_DEVELOPMENT_KEY_ID: _DEVELOPMENT_KEY}, _DEVELOPMENT_KEY_ID
+
+    keys: Dict[str, bytes] = {}
+    first = ""
+    for pair in raw.split(","):
+        key_id, _, secret = pair.strip().partition(":")
+        if not key_id or not secret:
+            raise RuntimeError("INTERNAL_AUTH_KEYS must list id:secret pairs")
+        if len(secret) < _MIN_KEY_LENGTH:
+            raise RuntimeError(f"INTERNAL_AUTH_KEYS: key {key_id} is shorter than {_MIN_KEY_LENGTH} bytes")
+        keys[key_id] = secret.encode()
+        first = first or key_id
+    signing_key_id = settings.internal_auth_key_id or first
+    if signing_key_id not in keys:
+        raise RuntimeError(f"INTERNAL_AUTH_KEY_ID {signing_key_id} is not in INTERNAL_AUTH_KEYS")
+    return keys, signing_key_id
+
+
+_keys, _signing_key_id = _load_keys()
+_ttl_seconds = _parse_duration(settings.internal_auth_ttl)
+
+
+def _encode(value: bytes) -> str:
+    return base64.urlsafe_b64encode(value).rstrip(b"=").decode()
+
+
+def sign_service_token(audience: str) -> str:
+    """Return a token for a call to audience. Calls made for no user carry no identity
+    claims."""
+    now = int(time.time())
+    header = {"alg": "HS256", "typ": "JWT", "kid": _signing_key_id}
+    claims = {
+        "iss": SERVICE,
+        "aud": audience,
+        "iat": now,
+        "exp": now + _ttl_seconds,
+        "jti": secrets.token_hex(16),
+    }
+    signing_input = ".".join(_encode(json.dumps(part, separators=(",", ":")).encode()) for part in (header, claims))
+    signature = hmac.new(_keys[_signing_key_id], signing_input.encode(), hashlib.sha256).digest()
+    return f"{signing_input}.{_encode(signature)}"
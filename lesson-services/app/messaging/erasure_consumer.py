@@ -0,0 +1,133 @@
+"""Consumes user.erasure_requested from the exchange of user-services: when an erasure
+lists lessons, the personal data of the user is scrubbed and the erasure confirmed
+with POST callback_path on user-services, with the number of rows changed.
+
+The consumer runs in a thread of its own, reconnecting while RabbitMQ is down. Scrubbing
+and confirming again change nothing, so a message delivered twice needs no inbox."""
+import json
+import logging
+import threading
+from typing import Optional
+from uuid import UUID
+
+import httpx
+import pika
+from pika.adapters.blocking_connection import BlockingChannel, BlockingConnection
+
+from app.config import settings
+from app.database.connection import SessionLocal
+from app.internal_auth import HEADER, sign_service_token
+from app.services.erasure_service import ErasureService
+
+logger = logging.getLogger(__name__)
+
+ROUTING_KEY = "user.erasure_requested"
+# The name user-services waits for in ERASURE_SERVICES
+SERVICE_NAME = "lessons"
+RECONNECT_DELAY_SECONDS = 10
+RETRY_DELAY_SECONDS = 10
+ACK_TIMEOUT_SECONDS = 10
+
+
+class PermanentError(Exception):
+    """An error a retry cannot fix; the message is dropped."""
+
+
+class ErasureConsumer:
+    def __init__(self) -> None:
+        self._stopping = threading.Event()
+        self._thread: Optional[threading.Thread] = None
+        self._connection: Optional[BlockingConnection] = None
+        self._channel: Optional[BlockingChannel] = None
+
+    def start(self) -> None:
+        self._thread = threading.Thread(target=self._run, name="erasure-consumer", daemon=True)
+        self._thread.start()
+
+    def stop(self) -> None:
+        """Stop consuming once the message in hand is handled."""
+        self._stopping.set()
+        connection, channel = self._connection, self._channel
+        if connection is not None and channel is not None and connection.is_open:
+            connection.add_callback_threadsafe(channel.stop_consuming)
+        if self._thread is not None:
+            self._thread.join(timeout=30)
+
+    def _run(self) -> None:
+        while not self._stopping.is_set():
+            try:
+                self._consume()
+            except Exception:
+                if self._stopping.is_set():
+                    return
+                logger.warning("Erasure consumer stopped; reconnecting", exc_info=True)
+            self._stopping.wait(RECONNECT_DELAY_SECONDS)
+
+    def _consume(self) -> None:
+        self._connection = pika.BlockingConnection(pika.URLParameters(settings.rabbitmq_url))
+        try:
+            channel = self._channel = self._connection.channel()
+            channel.exchange_declare(settings.rabbitmq_user_events_exchange, exchange_type="topic", durable=True)
+            channel.queue_declare(settings.rabbitmq_erasure_queue, durable=True)
+            channel.queue_bind(settings.rabbitmq_erasure_queue, settings.rabbitmq_user_events_exchange, routing_key=ROUTING_KEY)
+            channel.basic_qos(prefetch_count=1)
+            channel.basic_consume(settings.rabbitmq_erasure_queue, self._on_message)
+            logger.info("Consuming %s from %s", ROUTING_KEY, settings.rabbitmq_erasure_queue)
+            channel.start_consuming()
+        finally:
+            if self._connection.is_open:
+                self._connection.close()
+            self._connection, self._channel = None, None
+
+    def _on_message(self, channel: BlockingChannel, method, properties, body: bytes) -> None:
+        try:
+            self.handle(body)
+        except PermanentError:
+            logger.error("Dropping erasure event %s", properties.message_id, exc_info=True)
+            channel.basic_reject(method.delivery_tag, requeue=False)
+            return
+        except Exception:
+            logger.warning("Failed to handle erasure event %s; retrying", properties.message_id, exc_info=True)
+            # Requeued after a pause, so a failing database or user-services is not hammered
+            self._stopping.wait(RETRY_DELAY_SECONDS)
+            channel.basic_nack(method.delivery_tag, requeue=True)
+            return
+        channel.basic_ack(method.delivery_tag)
+
+    def handle(self, body: bytes) -> None:
+        try:
+            event = json.loads(body)
+            erasure_id = UUID(event["erasure_id"])
+            user_id = UUID(event["user_id"])
+            services = event.get("services") or []
+            callback_path = event["callback_path"]
+        except (ValueError, KeyError, TypeError) as exc:
+            raise PermanentError(f"invalid UserErasureRequested payload: {exc}") from exc
+        if SERVICE_NAME not in services:
+            return
+
+        db = SessionLocal()
+        try:
+            affected = ErasureService(db).scrub(user_id)
+        finally:
+            db.close()
+        self._acknowledge(callback_path, affected)
+        logger.info("Erased user data of %s for erasure %s: %d records", user_id, erasure_id, affected)
+
+    def _acknowledge(self, callback_path: str, affected: int) -> None:
+        """Confirm the erasure; a refused confirmation would be refused again."""
+        response = httpx.post(
+            settings.user_service_url.rstrip("/") + callback_path,
+            json={"service": SERVICE_NAME, "status": "completed", "records_affected": affected},
+            headers={HEADER: sign_service_token("user-services")},
+            timeout=ACK_TIMEOUT_SECONDS,
+        )
+        if response.is_success:
+            return
+        error = f"erasure ack returned status {response.status_code}: {response.text[:1024]}"
+        if response.status_code in (400, 404, 409):
+            raise PermanentError(error)
+        raise RuntimeError(error)
+
+
+erasure_consumer = ErasureConsumer()
@@ -0,0 +1,22 @@
+from datetime import date, datetime, timezone
+from typing import Optional
+from zoneinfo import ZoneInfo, ZoneInfoNotFoundError
+
+from fastapi import Header
+
+
+def get_user_today(
+    x_user_timezone: Optional[str] = Header(default=None),
+) -> date:
+    """Dependency returning the current date in the caller's timezone.
+
+    The BFF forwards the user's IANA timezone in X-User-Timezone so that "today",
+    week and month boundaries follow the user's calendar; UTC is used otherwise.
+    """
+    tz = timezone.utc
+    if x_user_timezone:
+        try:
+            tz = ZoneInfo(x_user_timezone)
+        except (ZoneInfoNotFoundError, ValueError):
+            pass
+    return datetime.now(tz).date()
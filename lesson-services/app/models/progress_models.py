@@ -185,4 +185,10 @@ class Outbox(Base):
     type = Column(Text, nullable=False)
     payload = Column(JSONB, nullable=False)
     created_at = Column(DateTime(timezone=True), nullable=False, server_default=func.now())
-    published_at = Column(DateTime(timezone=True))
\ No newline at end of file
+    published_at = Column(DateTime(timezone=True))
+    # Delivery state kept by outbox-relay, see shared/outbox/gormstore
+    attempts = Column(Integer, nullable=False, server_default="0")
+    last_error = Column(Text)
+    next_attempt_at = Column(DateTime(timezone=True), nullable=False, server_default=func.now())
+    failed_at = Column(DateTime(timezone=True))
+    trace_parent = Column(Text, nullable=False, server_default="")
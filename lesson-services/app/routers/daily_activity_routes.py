@@ -15,6 +15,7 @@ from app.schemas.daily_activity_schema import (
 )
 from app.services.daily_activity_service import DailyActivityService
 from app.middlewares.auth_middleware import get_current_user_id
+from app.middlewares.timezone_middleware import get_user_today
 from app.routers.base import ApiResponseRoute
 
 
@@ -44,11 +45,12 @@ def _empty_activity(user_id: UUID, activity_dt: date) -> DailyActivityResponse:
 @router.get("/user/me/today", response_model=DailyActivityResponse)
 def get_today_activity(
     user_id: UUID = Depends(get_current_user_id),
+    today: date = Depends(get_user_today),
     service: DailyActivityService = Depends(get_daily_activity_service)
 ) -> DailyActivityResponse:
-    activity = service.get_today_activity(user_id)
+    activity = service.get_today_activity(user_id, today)
     if activity is None:
-        return _empty_activity(user_id, date.today())
+        return _empty_activity(user_id, today)
     return DailyActivityResponse.model_validate(activity)
 
 
@@ -72,9 +74,10 @@ def get_activity_range(
     date_from: Optional[date] = Query(default=None),
     date_to: Optional[date] = Query(default=None),
     user_id: UUID = Depends(get_current_user_id),
+    today: date = Depends(get_user_today),
     service: DailyActivityService = Depends(get_daily_activity_service),
 ) -> List[DailyActivityResponse]:
-    end_date = date_to or date.today()
+    end_date = date_to or today
     start_date = date_from or (end_date - timedelta(days=29))
     if start_date > end_date:
         raise HTTPException(
@@ -88,9 +91,10 @@ def get_activity_range(
 @router.get("/user/me/week", response_model=List[DailyActivityResponse])
 def get_week_activity(
     user_id: UUID = Depends(get_current_user_id),
+    today: date = Depends(get_user_today),
     service: DailyActivityService = Depends(get_daily_activity_service)
 ) -> List[DailyActivityResponse]:
-    activities = service.get_week_activity(user_id)
+    activities = service.get_week_activity(user_id, today)
     return [DailyActivityResponse.model_validate(activity) for activity in activities]
 
 
@@ -99,9 +103,9 @@ def get_month_activity(
     year: Optional[int] = Query(default=None),
     month: Optional[int] = Query(default=None, ge=1, le=12),
     user_id: UUID = Depends(get_current_user_id),
+    today: date = Depends(get_user_today),
     service: DailyActivityService = Depends(get_daily_activity_service),
 ) -> DailyActivityMonthSummary:
-    today = date.today()
     target_year = year or today.year
     target_month = month or today.month
     summary = service.get_month_activity(user_id, target_year, target_month)
@@ -119,9 +123,10 @@ def get_month_activity(
 @router.get("/user/me/stats/summary", response_model=DailyActivitySummary)
 def get_activity_summary(
     user_id: UUID = Depends(get_current_user_id),
+    today: date = Depends(get_user_today),
     service: DailyActivityService = Depends(get_daily_activity_service)
 ) -> DailyActivitySummary:
-    summary = service.get_activity_summary(user_id)
+    summary = service.get_activity_summary(user_id, today)
     return DailyActivitySummary(
         lifetime=DailyTotals(**summary["lifetime"]),
         last_7_days=DailyTotals(**summary["last_7_days"]),
@@ -140,12 +145,13 @@ def get_activity_summary(
 def increment_activity(
     payload: DailyActivityIncrementRequest, 
     user_id: UUID = Depends(get_current_user_id),
+    today: date = Depends(get_user_today),
     service: DailyActivityService = Depends(get_daily_activity_service)
 ) -> DailyActivityResponse:
     try:
         activity = service.increment_activity(
             user_id=user_id,
-            activity_date=payload.activity_dt or date.today(),
+            activity_date=payload.activity_dt or today,
             field=payload.field.lower(),
             amount=payload.amount,
         )
@@ -1,5 +1,6 @@
 from __future__ import annotations
 
+from datetime import date
 from typing import List, Optional
 from uuid import UUID
 
@@ -15,6 +16,7 @@ from app.schemas.user_streak_schema import (
 )
 from app.services.user_streak_service import UserStreakService
 from app.middlewares.auth_middleware import get_current_user_id
+from app.middlewares.timezone_middleware import get_user_today
 from app.routers.base import ApiResponseRoute
 
 
@@ -49,27 +51,30 @@ def get_user_streak_by_id(
 def check_user_streak(
     payload: Optional[StreakCheckRequest] = None,
     user_id: UUID = Depends(get_current_user_id),
+    today: date = Depends(get_user_today),
     service: UserStreakService = Depends(get_user_streak_service),
 ) -> UserStreakResponse:
-    activity_date = payload.activity_date if payload else None
+    activity_date = payload.activity_date if payload and payload.activity_date else today
     return service.check_and_update_streak(user_id, activity_date=activity_date)
 
 
 @router.get("/user/me/status", response_model=UserStreakStatusResponse)
 def get_streak_status(
     user_id: UUID = Depends(get_current_user_id),
+    today: date = Depends(get_user_today),
     service: UserStreakService = Depends(get_user_streak_service),
 ) -> UserStreakStatusResponse:
-    status_data = service.get_streak_status(user_id)
+    status_data = service.get_streak_status(user_id, today)
     return UserStreakStatusResponse(**status_data)
 
 
 @router.get("/leaderboard", response_model=List[StreakLeaderboardEntry])
 def get_streak_leaderboard(
     limit: int = Query(default=50, ge=1, le=200),
+    offset: int = Query(default=0, ge=0),
     service: UserStreakService = Depends(get_user_streak_service),
 ) -> List[StreakLeaderboardEntry]:
-    records = service.get_streak_leaderboard(limit=limit)
+    records = service.get_streak_leaderboard(limit=limit, offset=offset)
     return [
         StreakLeaderboardEntry(
             rank=index,
@@ -78,5 +83,5 @@ def get_streak_leaderboard(
             longest_len=record.longest_len,
             last_day=record.last_day,
         )
-        for index, record in enumerate(records, start=1)
+        for index, record in enumerate(records, start=offset + 1)
     ]
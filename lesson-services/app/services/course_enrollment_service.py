@@ -14,11 +14,13 @@ from app.schemas.course_enrollment_schema import (
     CourseEnrollmentUpdate,
     EnrollmentStatus,
 )
+from app.services.outbox_service import OutboxService
 
 
 class CourseEnrollmentService:
     def __init__(self, db: Session):
         self.db = db
+        self.outbox = OutboxService(db)
 
     def get_by_id(self, enrollment_id: UUID, user_id: Optional[UUID] = None) -> Optional[CourseEnrollmentResponse]:
         query = self.db.query(CourseEnrollment).filter(CourseEnrollment.id == enrollment_id)
@@ -68,6 +70,11 @@ class CourseEnrollmentService:
             last_accessed_at=datetime.utcnow(),
         )
         self.db.add(row)
+        self.outbox.create_message(
+            user_id,
+            "CourseEnrolled",
+            {"user_id": user_id, "course_id": row.course_id, "enrolled_at": row.enrolled_at},
+        )
         self.db.commit()
         self.db.refresh(row)
         return CourseEnrollmentResponse.model_validate(row)
@@ -98,6 +105,11 @@ class CourseEnrollmentService:
             row.started_at = datetime.utcnow()
         if getattr(row, "status", None) == EnrollmentStatus.COMPLETED.value and row.completed_at is None:
             row.completed_at = datetime.utcnow()
+            self.outbox.create_message(
+                user_id,
+                "CourseCompleted",
+                {"user_id": user_id, "course_id": row.course_id, "completed_at": row.completed_at},
+            )
 
         self.db.commit()
         self.db.refresh(row)
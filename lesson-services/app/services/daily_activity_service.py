@@ -35,8 +35,10 @@ class DailyActivityService:
 
         return activity
 
-    def get_today_activity(self, user_id: UUID) -> Optional[DailyActivity]:
-        return self.get_activity_by_date(user_id, date.today())
+    def get_today_activity(
+        self, user_id: UUID, today: Optional[date] = None
+    ) -> Optional[DailyActivity]:
+        return self.get_activity_by_date(user_id, today or date.today())
 
     def get_activity_by_date(self, user_id: UUID, activity_date: date) -> Optional[DailyActivity]:
         return (
@@ -62,8 +64,10 @@ class DailyActivityService:
             .all()
         )
 
-    def get_week_activity(self, user_id: UUID) -> List[DailyActivity]:
-        today = date.today()
+    def get_week_activity(
+        self, user_id: UUID, today: Optional[date] = None
+    ) -> List[DailyActivity]:
+        today = today or date.today()
         start_of_week = today - timedelta(days=today.weekday())
         end_of_week = start_of_week + timedelta(days=6)
         activities = self.get_activity_range(user_id, start_of_week, end_of_week)
@@ -115,7 +119,9 @@ class DailyActivityService:
             "days": days,
         }
 
-    def get_activity_summary(self, user_id: UUID) -> Dict[str, object]:
+    def get_activity_summary(
+        self, user_id: UUID, today: Optional[date] = None
+    ) -> Dict[str, object]:
         activities = (
             self.db.query(DailyActivity)
             .filter(DailyActivity.user_id == user_id)
@@ -125,7 +131,7 @@ class DailyActivityService:
 
         lifetime_totals = self._aggregate_totals(activities)
 
-        today = date.today()
+        today = today or date.today()
         last_7_start = today - timedelta(days=6)
         last_30_start = today - timedelta(days=29)
 
@@ -0,0 +1,32 @@
+from uuid import UUID
+
+from sqlalchemy import select, update
+from sqlalchemy.orm import Session
+
+from app.models.progress_models import DimUser, ProgressEvent, QuizAnswer, QuizAttempt
+
+
+class ErasureService:
+    """Removes the personal data of an erased user. Progress, points, streaks and
+    leaderboards are kept under the user ID, which names no one once user-services has
+    anonymized the account."""
+
+    def __init__(self, db: Session):
+        self.db = db
+
+    def scrub(self, user_id: UUID) -> int:
+        """Delete the profile of the user, clear the free-text quiz answers and empty the
+        payloads of the raw progress events, in one transaction. Returns the rows changed;
+        running it again leaves nothing more to change."""
+        profiles = self.db.query(DimUser).filter(DimUser.user_id == user_id).delete(synchronize_session=False)
+        attempts = select(QuizAttempt.id).where(QuizAttempt.user_id == user_id)
+        answers = self.db.execute(
+            update(QuizAnswer)
+            .where(QuizAnswer.attempt_id.in_(attempts), QuizAnswer.text_answer.is_not(None))
+            .values(text_answer=None)
+        ).rowcount
+        events = self.db.execute(
+            update(ProgressEvent).where(ProgressEvent.user_id == user_id).values(payload={})
+        ).rowcount
+        self.db.commit()
+        return profiles + answers + events
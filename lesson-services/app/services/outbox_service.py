@@ -1,11 +1,29 @@
 from sqlalchemy.orm import Session
 from typing import List, Optional, Dict, Any
-from uuid import UUID
-from datetime import datetime, timedelta
+from uuid import UUID, uuid4
+from datetime import datetime, timedelta, timezone
 
-# from app.models.progress_models import Outbox
+from app.models.progress_models import Outbox
 # from app.schemas.outbox_schema import OutboxCreate
 
+PROGRESS_TOPIC = "progress.events"
+
+# Schema versions of the progress events, see shared/events/progress.go
+SCHEMA_VERSIONS = {
+    "CourseEnrolled": 1,
+    "CourseCompleted": 1,
+    "LessonCompleted": 1,
+    "QuizSubmitted": 1,
+}
+
+
+def _timestamp(value: datetime) -> str:
+    """RFC 3339 form of a timestamp; naive timestamps are UTC, like datetime.utcnow()."""
+    if value.tzinfo is None:
+        value = value.replace(tzinfo=timezone.utc)
+    return value.astimezone(timezone.utc).isoformat().replace("+00:00", "Z")
+
+
 class OutboxService:
     """
     Outbox Pattern Service for reliable event publishing
@@ -15,13 +33,41 @@ class OutboxService:
     
     Flow:
     1. When domain event occurs, write to outbox table in same transaction
-    2. Background worker polls outbox for unpublished messages
-    3. Worker publishes to RabbitMQ and marks as published
+    2. outbox-relay polls outbox for unpublished messages
+    3. The relay publishes to RabbitMQ and marks as published
     4. Old published messages are periodically cleaned up
     """
     
     def __init__(self, db: Session):
         self.db = db
+
+    def create_message(
+        self, aggregate_id: UUID, event_type: str, payload: Dict[str, Any], topic: str = PROGRESS_TOPIC
+    ) -> Outbox:
+        """Add an event to the outbox in the transaction of the caller, which commits it.
+
+        The payload is stamped with the metadata of the contract of shared/events
+        (event_id, event_type, schema_version, occurred_at); UUIDs and timestamps in it
+        are written as strings.
+        """
+        body = {
+            "event_id": str(uuid4()),
+            "event_type": event_type,
+            "schema_version": SCHEMA_VERSIONS[event_type],
+            "occurred_at": _timestamp(datetime.utcnow()),
+        }
+        for field, value in payload.items():
+            if value is None:
+                continue
+            if isinstance(value, UUID):
+                value = str(value)
+            elif isinstance(value, datetime):
+                value = _timestamp(value)
+            body[field] = value
+
+        message = Outbox(aggregate_id=aggregate_id, topic=topic, type=event_type, payload=body)
+        self.db.add(message)
+        return message
     
     # get_pending_messages(limit: int = 100) -> List[Outbox]
     # Logic: Get unpublished messages for processing
@@ -36,19 +82,6 @@ class OutboxService:
     # - Query outbox by id
     # - Return message or None
     
-    # create_message(aggregate_id: UUID, topic: str, event_type: str, payload: Dict[str, Any]) -> Outbox
-    # Logic: Create outbox message in same transaction as domain event
-    # - Create outbox record with:
-    #   - aggregate_id: ID of aggregate (user_id, lesson_id, etc.)
-    #   - topic: message queue topic/exchange ('user.progress', 'lesson.completed')
-    #   - type: event type ('LessonCompleted', 'QuizSubmitted', 'StreakUpdated')
-    #   - payload: JSONB event data
-    #   - created_at: current timestamp
-    #   - published_at: NULL (unpublished)
-    # - Insert into database
-    # - DO NOT commit here - let caller commit with domain transaction
-    # - Return created outbox record
-    
     # mark_as_published(outbox_id: int) -> Optional[Outbox]
     # Logic: Mark message as successfully published
     # - Find outbox record by id
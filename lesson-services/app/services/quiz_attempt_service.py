@@ -12,11 +12,13 @@ from app.schemas import (
     QuizAttemptCreate,
     QuizAttemptSubmit,
 )
+from app.services.outbox_service import OutboxService
 
 
 class QuizAttemptService:
     def __init__(self, db: Session):
         self.db = db
+        self.outbox = OutboxService(db)
 
     def start_quiz(self, attempt_data: QuizAttemptCreate) -> QuizAttempt:
         last_attempt = (
@@ -81,6 +83,21 @@ class QuizAttemptService:
             max_points = attempt.max_points or 0
             attempt.passed = max_points == 0 or submission.total_points >= max_points
 
+        self.outbox.create_message(
+            attempt.user_id,
+            "QuizSubmitted",
+            {
+                "user_id": attempt.user_id,
+                "quiz_id": attempt.quiz_id,
+                "attempt_id": attempt.id,
+                "lesson_id": attempt.lesson_id,
+                "points": max(attempt.total_points or 0, 0),
+                "max_points": max(attempt.max_points or 0, 0),
+                "passed": bool(attempt.passed),
+                "submitted_at": submitted_at,
+            },
+        )
+
         self.db.commit()
         self.db.refresh(attempt)
         return attempt
@@ -15,6 +15,7 @@ from app.schemas import (
     UserLessonStats,
     UserLessonUpdate,
 )
+from app.services.outbox_service import OutboxService
 
 
 class UserLessonService:
@@ -22,6 +23,7 @@ class UserLessonService:
 
     def __init__(self, db: Session):
         self.db = db
+        self.outbox = OutboxService(db)
 
     def _status_value(self, status: LessonStatus | str | None) -> Optional[str]:
         if status is None:
@@ -82,6 +84,7 @@ class UserLessonService:
         lesson = self.get_user_lesson(user_id, lesson_id)
         if not lesson:
             return None
+        was_completed = lesson.status == LessonStatus.COMPLETED.value
 
         payload = update.model_dump(exclude_unset=True)
 
@@ -103,6 +106,9 @@ class UserLessonService:
         ):
             lesson.completed_at = datetime.utcnow()
 
+        if lesson.status == LessonStatus.COMPLETED.value and not was_completed:
+            self._record_completed(lesson)
+
         self.db.commit()
         self.db.refresh(lesson)
         return lesson
@@ -118,6 +124,7 @@ class UserLessonService:
         lesson = self.get_user_lesson(user_id, lesson_id)
         if not lesson:
             return None
+        was_completed = lesson.status == LessonStatus.COMPLETED.value
 
         if completion.last_section_ord is not None:
             lesson.last_section_ord = completion.last_section_ord
@@ -127,10 +134,27 @@ class UserLessonService:
         lesson.status = LessonStatus.COMPLETED.value
         lesson.completed_at = completion.completed_at or datetime.utcnow()
 
+        if not was_completed:
+            self._record_completed(lesson)
+
         self.db.commit()
         self.db.refresh(lesson)
         return lesson
 
+    def _record_completed(self, lesson: UserLesson) -> None:
+        """Publish LessonCompleted with the commit of the caller, once per lesson and user."""
+
+        self.outbox.create_message(
+            lesson.user_id,
+            "LessonCompleted",
+            {
+                "user_id": lesson.user_id,
+                "lesson_id": lesson.lesson_id,
+                "score": max(lesson.score_total or 0, 0),
+                "completed_at": lesson.completed_at,
+            },
+        )
+
     def abandon_lesson(self, user_id: UUID, lesson_id: UUID) -> Optional[UserLesson]:
         """Mark a lesson as abandoned for the user."""
 
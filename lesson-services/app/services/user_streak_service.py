@@ -97,9 +97,11 @@ class UserStreakService:
         self.db.refresh(streak)
         return streak
 
-    def get_streak_status(self, user_id: UUID) -> Dict[str, object]:
+    def get_streak_status(
+        self, user_id: UUID, today: Optional[date] = None
+    ) -> Dict[str, object]:
         streak = self.get_or_create_streak(user_id)
-        today = date.today()
+        today = today or date.today()
         has_activity_today = self._has_activity(user_id, today)
         last_day = streak.last_day
         days_since_last: Optional[int] = None
@@ -126,11 +128,12 @@ class UserStreakService:
             "days_since_last": days_since_last,
         }
 
-    def get_streak_leaderboard(self, limit: int = 50) -> List[UserStreak]:
+    def get_streak_leaderboard(self, limit: int = 50, offset: int = 0) -> List[UserStreak]:
         return (
             self.db.query(UserStreak)
             .filter(UserStreak.current_len > 0)
             .order_by(desc(UserStreak.current_len), desc(UserStreak.last_day))
+            .offset(offset)
             .limit(limit)
             .all()
         )
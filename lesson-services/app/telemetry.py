@@ -0,0 +1,29 @@
+"""Distributed tracing: requests continue the W3C trace of their traceparent header and
+each request and SQL statement becomes a span, exported to the OpenTelemetry collector
+when OTEL_EXPORTER_OTLP_ENDPOINT is set."""
+import logging
+import os
+
+from fastapi import FastAPI
+from opentelemetry import trace
+from opentelemetry.exporter.otlp.proto.http.trace_exporter import OTLPSpanExporter
+from opentelemetry.instrumentation.fastapi import FastAPIInstrumentor
+from opentelemetry.instrumentation.sqlalchemy import SQLAlchemyInstrumentor
+from opentelemetry.sdk.resources import SERVICE_NAME, Resource
+from opentelemetry.sdk.trace import TracerProvider
+from opentelemetry.sdk.trace.export import BatchSpanProcessor
+from sqlalchemy.engine import Engine
+
+logger = logging.getLogger(__name__)
+
+
+def setup_tracing(app: FastAPI, engine: Engine) -> None:
+    service_name = os.getenv("OTEL_SERVICE_NAME", "lesson-services")
+    provider = TracerProvider(resource=Resource.create({SERVICE_NAME: service_name}))
+    if os.getenv("OTEL_EXPORTER_OTLP_ENDPOINT") or os.getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"):
+        provider.add_span_processor(BatchSpanProcessor(OTLPSpanExporter()))
+        logger.info("Exporting traces of %s", service_name)
+    trace.set_tracer_provider(provider)
+
+    FastAPIInstrumentor.instrument_app(app, tracer_provider=provider, excluded_urls="api/v1/health")
+    SQLAlchemyInstrumentor().instrument(engine=engine, tracer_provider=provider)
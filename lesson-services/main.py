@@ -3,7 +3,11 @@ from fastapi.middleware.cors import CORSMiddleware
 from app.middlewares.auth_middleware import InternalAuthRequired
 from app.database.migrations import run_database_migrations
 from app.config import settings
+from app.database.connection import engine
+from app.messaging.erasure_consumer import erasure_consumer
+from app.telemetry import setup_tracing
 from app.routers import (
+    course_enrollment_routes,
     daily_activity_routes,
     health_routes,
     leaderboard_routes,
@@ -36,12 +40,27 @@ app.add_middleware(
 # Add authentication middleware (except for health check)
 app.add_middleware(InternalAuthRequired)
 
+# Trace requests and SQL statements
+setup_tracing(app, engine)
+
 
 @app.on_event("startup")
 async def apply_database_migrations() -> None:
     if settings.run_migrations_on_startup:
         run_database_migrations()
 
+
+@app.on_event("startup")
+async def start_erasure_consumer() -> None:
+    if settings.erasure_consumer_enabled:
+        erasure_consumer.start()
+
+
+@app.on_event("shutdown")
+async def stop_erasure_consumer() -> None:
+    if settings.erasure_consumer_enabled:
+        erasure_consumer.stop()
+
 app.include_router(health_routes.router, prefix="/api/v1", tags=["health"])
 app.include_router(daily_activity_routes.router, prefix="/api/v1", tags=["daily-activity"])
 app.include_router(leaderboard_routes.router, prefix="/api/v1", tags=["leaderboard"])
@@ -54,6 +73,8 @@ app.include_router(sr_review_routes.router, prefix="/api/v1", tags=["sr-review"]
 app.include_router(user_lesson_routes.router, prefix="/api/v1", tags=["user-lesson"])
 app.include_router(user_points_routes.router, prefix="/api/v1", tags=["user-points"])
 app.include_router(user_streak_routes.router, prefix="/api/v1", tags=["user-streak"])
+# The BFF calls the enrollments at /api/course-enrollments
+app.include_router(course_enrollment_routes.router, tags=["course-enrollment"])
 
 if __name__ == "__main__":
     import uvicorn
@@ -0,0 +1,112 @@
+import { config } from '../config';
+import { HEADER, signServiceToken } from '../internalAuth';
+
+const TIMEOUT_MS = 10_000;
+
+export interface PushTarget {
+    id: string;
+    provider: 'fcm' | 'apns';
+    token: string;
+    platform: string;
+    app_id?: string;
+}
+
+export interface UserContact {
+    email: string | null;
+    name: string | null;
+    locale: string | null;
+}
+
+// The confirmation of an erasure, see POST /api/v1/internal/erasures/:id/acks
+export interface ErasureAck {
+    service: string;
+    status: 'completed' | 'failed';
+    records_affected: number;
+    detail?: string;
+}
+
+// The PublicUser of user-services, as far as notifications need it
+interface BatchUser {
+    id: string;
+    email: string;
+    username?: string;
+    status: string;
+    profile?: { display_name?: string; locale?: string };
+}
+
+// UserServiceError is a failed call to user-services; status is 0 when it could not be
+// reached. 4xx answers other than 429 will not change on retry.
+export class UserServiceError extends Error {
+    constructor(message: string, readonly status: number) {
+        super(message);
+    }
+
+    get retryable(): boolean {
+        return this.status === 0 || this.status === 429 || this.status >= 500;
+    }
+}
+
+// UserServiceClient reads the contact details of users from the internal endpoints of
+// user-services, with a service token for each call.
+export class UserServiceClient {
+    constructor(private readonly baseURL: string = config.USER_SERVICE_URL) {}
+
+    // getContact returns the email address, name and locale of a user, or null for an
+    // unknown user.
+    async getContact(userId: string): Promise<UserContact | null> {
+        const result = await this.request<{ items: BatchUser[] }>('POST', '/api/v1/internal/users/batch', {
+            ids: [userId],
+        });
+        const user = result.items.find((item) => item.id === userId);
+        if (!user) return null;
+        return {
+            email: user.email || null,
+            name: user.profile?.display_name || user.username || null,
+            locale: user.profile?.locale || null,
+        };
+    }
+
+    async getPushTargets(userId: string): Promise<PushTarget[]> {
+        const result = await this.request<{ tokens: PushTarget[] }>(
+            'GET',
+            `/api/v1/internal/push-tokens/users/${encodeURIComponent(userId)}`
+        );
+        return result.tokens ?? [];
+    }
+
+    // reportInvalidTokens tells user-services the tokens a push provider rejected, so they
+    // are deleted.
+    async reportInvalidTokens(provider: string, tokens: string[], reason: string): Promise<void> {
+        if (tokens.length === 0) return;
+        await this.request('POST', '/api/v1/internal/push-tokens/invalid', { provider, tokens, reason });
+    }
+
+    // acknowledgeErasure confirms to user-services that the data of an erased user is
+    // scrubbed here, at the callback path of the erasure event.
+    async acknowledgeErasure(callbackPath: string, ack: ErasureAck): Promise<void> {
+        await this.request('POST', callbackPath, ack);
+    }
+
+    private async request<T>(method: string, path: string, body?: unknown): Promise<T> {
+        let response: Response;
+        try {
+            response = await fetch(`${this.baseURL}${path}`, {
+                method,
+                headers: {
+                    'Content-Type': 'application/json',
+                    [HEADER]: signServiceToken('user-services'),
+                },
+                body: body === undefined ? undefined : JSON.stringify(body),
+                signal: AbortSignal.timeout(TIMEOUT_MS),
+            });
+        } catch (err) {
+            throw new UserServiceError(`user-services unreachable: ${(err as Error).message}`, 0);
+        }
+        if (!response.ok) {
+            throw new UserServiceError(`user-services ${method} ${path} returned ${response.status}`, response.status);
+        }
+        // user-services wraps its answers in { status, data }
+        const payload = (await response.json()) as { data: T };
+        return payload.data;
+    }
+}
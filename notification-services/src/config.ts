@@ -1,8 +1,43 @@
 import 'dotenv/config';
+import { readFileSync } from 'fs';
 import { z } from 'zod';
 
+// Secrets can be mounted as files instead of kept in .env, such as by Docker and
+// Kubernetes secrets or Vault Agent: SMTP_PASS_FILE=/run/secrets/smtp_pass sets SMTP_PASS.
+const FILE_SECRETS = [
+  'SENDGRID_API_KEY',
+  'SMTP_USER',
+  'SMTP_PASS',
+  'POSTGRES_PASSWORD',
+  'RABBITMQ_URL',
+  'INTERNAL_AUTH_KEYS',
+  'FCM_SERVICE_ACCOUNT',
+];
+
+function readSecretFiles(env: NodeJS.ProcessEnv): NodeJS.ProcessEnv {
+  const resolved = { ...env };
+  for (const key of FILE_SECRETS) {
+    const file = env[`${key}_FILE`];
+    if (!file) continue;
+    try {
+      resolved[key] = readFileSync(file, 'utf8').replace(/\r?\n$/, '');
+    } catch (err) {
+      // eslint-disable-next-line no-console
+      console.error(`Could not read ${key}_FILE ${file}:`, (err as Error).message);
+      process.exit(1);
+    }
+  }
+  return resolved;
+}
+
+const booleanFlag = z
+  .union([z.string(), z.boolean()])
+  .transform((v) => (typeof v === 'string' ? v === 'true' : !!v))
+  .default(false);
+
 const EnvSchema = z.object({
   PORT: z.coerce.number().int().positive().default(3000),
+  ENVIRONMENT: z.string().default('development'),
   EMAIL_PROVIDER: z.enum(['sendgrid', 'smtp']).default('smtp'),
 
   SENDGRID_API_KEY: z.string().optional(),
@@ -24,8 +59,48 @@ const EnvSchema = z.object({
   RABBITMQ_EMAIL_QUEUE: z.string().default('notifications.email'),
   RABBITMQ_EMAIL_ROUTING_KEY: z.string().default('email.send'),
   RABBITMQ_USER_EVENTS_QUEUE: z.string().default('notifications.user_events'),
-  RABBITMQ_USER_EVENTS_ROUTING_KEY: z.string().default('user.created,user.password_reset,user.email_verification'),
+  RABBITMQ_USER_EVENTS_ROUTING_KEY: z.string().default('user.created,user.password_reset,user.password_reset_completed,user.email_verification,user.account_unlock,user.passwordless_login'),
   RABBITMQ_PREFETCH: z.coerce.number().int().positive().default(10),
+  // Notification requests published by the services instead of POST /api/v1/notifications
+  RABBITMQ_NOTIFICATION_QUEUE: z.string().default('notifications.requests'),
+  RABBITMQ_NOTIFICATION_ROUTING_KEY: z.string().default('notification.send'),
+  // Order events turned into notifications, for an order-services run with NOTIFICATIONS_VIA_EVENTS=true
+  ORDER_EVENTS_ENABLED: booleanFlag,
+  RABBITMQ_ORDER_EVENTS_EXCHANGE: z.string().default('order.events'),
+  RABBITMQ_ORDER_EVENTS_QUEUE: z.string().default('notifications.order_events'),
+  RABBITMQ_ORDER_EVENTS_ROUTING_KEY: z.string().default('order.paid,order.cancelled,order.failed,order.refunded,order.refund_rejected,order.refund_completed'),
+  // user.erasure_requested of user-services, on RABBITMQ_EXCHANGE
+  RABBITMQ_ERASURE_QUEUE: z.string().default('notifications.user_erasures'),
+
+  // Deliveries: each channel of a notification is sent by the worker, and retried with
+  // exponential backoff up to DELIVERY_MAX_ATTEMPTS times
+  DELIVERY_MAX_ATTEMPTS: z.coerce.number().int().positive().default(6),
+  DELIVERY_RETRY_BASE_SECONDS: z.coerce.number().int().positive().default(30),
+  DELIVERY_POLL_INTERVAL_MS: z.coerce.number().int().positive().default(5000),
+  DELIVERY_BATCH_SIZE: z.coerce.number().int().positive().default(20),
+
+  // Digests: how often the due digests are composed, how many users per pass, and how
+  // many notifications a digest lists before summing up the rest
+  DIGEST_INTERVAL_MS: z.coerce.number().int().positive().default(15 * 60 * 1000),
+  DIGEST_BATCH_SIZE: z.coerce.number().int().positive().default(50),
+  DIGEST_MAX_ITEMS: z.coerce.number().int().positive().default(20),
+
+  // The locale of the message templates for users without one, and the last fallback
+  NOTIFICATION_DEFAULT_LOCALE: z.string().default('en').transform((locale) => locale.toLowerCase()),
+
+  // Push: 'log' only logs the pushes, 'fcm' sends them with the FCM HTTP v1 API
+  PUSH_PROVIDER: z.enum(['log', 'fcm']).default('log'),
+  FCM_PROJECT_ID: z.string().optional(),
+  // The JSON key of a service account allowed to send FCM messages
+  FCM_SERVICE_ACCOUNT: z.string().optional(),
+
+  // user-services resolves the email address and push tokens of a user
+  USER_SERVICE_URL: z.string().default('http://user-services:8001'),
+
+  // Service tokens (see shared/internalauth): comma separated id:secret pairs
+  INTERNAL_AUTH_KEYS: z.string().optional(),
+  INTERNAL_AUTH_KEY_ID: z.string().optional(),
+  INTERNAL_AUTH_TTL: z.string().default('1m'),
 
   // PostgreSQL
   POSTGRES_USER: z.string().default('user'),
@@ -34,9 +109,15 @@ const EnvSchema = z.object({
   POSTGRES_HOST: z.string().default('localhost'),
   POSTGRES_PORT: z.coerce.number().int().positive().default(5432),
   POSTGRES_SSLMODE: z.string().default('disable'),
+
+  // OpenTelemetry: consumer spans are exported when an OTLP endpoint is set
+  OTEL_SERVICE_NAME: z.string().default('notification-services'),
+  OTEL_EXPORTER_OTLP_ENDPOINT: z.string().optional(),
+  OTEL_EXPORTER_OTLP_TRACES_ENDPOINT: z.string().optional(),
+  OTEL_SDK_DISABLED: booleanFlag,
 });
 
-const parsed = EnvSchema.safeParse(process.env);
+const parsed = EnvSchema.safeParse(readSecretFiles(process.env));
 if (!parsed.success) {
   // eslint-disable-next-line no-console
   console.error('Invalid environment variables:', parsed.error.flatten().fieldErrors);
@@ -55,6 +55,100 @@ export async function initDatabase() {
       CREATE INDEX IF NOT EXISTS idx_user_notifications_created_at ON user_notifications(created_at);
     `);
 
+        // Notifications sent by the services, and their delivery on each channel
+        await db.query(`
+      CREATE TABLE IF NOT EXISTS notifications (
+        id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+        user_id UUID NOT NULL,
+        type TEXT NOT NULL,
+        title TEXT NOT NULL,
+        message TEXT NOT NULL,
+        data JSONB NOT NULL DEFAULT '{}',
+        priority TEXT NOT NULL DEFAULT 'normal',
+        source TEXT NOT NULL,
+        idempotency_key TEXT UNIQUE,
+        created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+      );
+    `);
+
+        await db.query(`
+      CREATE TABLE IF NOT EXISTS notification_deliveries (
+        id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+        notification_id UUID NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+        channel TEXT NOT NULL,
+        status TEXT NOT NULL DEFAULT 'pending',
+        attempts INT NOT NULL DEFAULT 0,
+        next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+        last_error TEXT,
+        sent_at TIMESTAMPTZ,
+        updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+        UNIQUE (notification_id, channel)
+      );
+    `);
+
+        await db.query(`
+      CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id, created_at);
+    `);
+
+        await db.query(`
+      CREATE INDEX IF NOT EXISTS idx_notification_deliveries_due
+        ON notification_deliveries(next_attempt_at) WHERE status IN ('pending', 'sending');
+    `);
+
+        // The in-app copy of a notification is a template of its own, once per notification
+        await db.query(`
+      CREATE UNIQUE INDEX IF NOT EXISTS idx_notification_templates_notification_id
+        ON notification_templates ((data->>'notification_id'));
+    `);
+
+        // The locale a sender asked for; without one the locale of the user applies
+        await db.query(`
+      ALTER TABLE notifications ADD COLUMN IF NOT EXISTS locale TEXT;
+    `);
+
+        // The copy of each notification type, per channel and locale
+        await db.query(`
+      CREATE TABLE IF NOT EXISTS message_templates (
+        id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+        type TEXT NOT NULL,
+        channel TEXT NOT NULL,
+        locale TEXT NOT NULL,
+        title TEXT NOT NULL,
+        body TEXT NOT NULL,
+        html TEXT,
+        created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+        updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+        UNIQUE (type, channel, locale)
+      );
+    `);
+
+        // Notification preferences of the users, and the digests gathering their
+        // low-priority notifications
+        await db.query(`
+      CREATE TABLE IF NOT EXISTS notification_preferences (
+        user_id UUID PRIMARY KEY,
+        in_app_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+        email_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+        push_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+        types JSONB NOT NULL DEFAULT '{}',
+        quiet_hours_start TEXT,
+        quiet_hours_end TEXT,
+        digest TEXT NOT NULL DEFAULT 'off',
+        last_digest_at TIMESTAMPTZ,
+        updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+      );
+    `);
+
+        await db.query(`
+      ALTER TABLE notification_deliveries
+        ADD COLUMN IF NOT EXISTS digest_id UUID REFERENCES notifications(id) ON DELETE SET NULL;
+    `);
+
+        await db.query(`
+      CREATE INDEX IF NOT EXISTS idx_notification_deliveries_held
+        ON notification_deliveries(notification_id) WHERE status = 'digest';
+    `);
+
         logger.info('Database initialized successfully');
     } catch (error) {
         logger.error({ error }, 'Failed to initialize database');
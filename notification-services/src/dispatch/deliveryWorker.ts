@@ -0,0 +1,105 @@
+import { config } from '../config';
+import { logger } from '../logger';
+import { Channel } from '../models/notification';
+import { DeliveryRepository, DueDelivery } from '../repositories/deliveryRepository';
+import { EmailDispatcher } from './emailDispatcher';
+import { InAppDispatcher } from './inAppDispatcher';
+import { PushDispatcher } from './pushDispatcher';
+import { ChannelDispatcher, PermanentError } from './types';
+
+// How long a claimed delivery stays with this worker before another may claim it
+const LEASE_SECONDS = 300;
+// The delay between two attempts doubles up to this
+const MAX_RETRY_DELAY_SECONDS = 6 * 3600;
+
+// retryDelay returns the delay before the attempt after the given one: the base delay,
+// doubled on each attempt, with up to 20% jitter.
+export function retryDelay(attempts: number, baseSeconds: number = config.DELIVERY_RETRY_BASE_SECONDS): number {
+  const delay = Math.min(baseSeconds * 2 ** Math.max(attempts - 1, 0), MAX_RETRY_DELAY_SECONDS);
+  return Math.round(delay * (1 + Math.random() * 0.2));
+}
+
+// DeliveryWorker sends the due deliveries every DELIVERY_POLL_INTERVAL_MS, or at once
+// when nudged after a new notification. Several instances share the work: each claims
+// the deliveries it sends.
+export class DeliveryWorker {
+  private readonly dispatchers: Map<Channel, ChannelDispatcher>;
+  private timer: NodeJS.Timeout | null = null;
+  private running: Promise<void> | null = null;
+  private again = false;
+  private stopped = false;
+
+  constructor(
+    private readonly repository: DeliveryRepository = new DeliveryRepository(),
+    dispatchers: ChannelDispatcher[] = [new EmailDispatcher(), new PushDispatcher(), new InAppDispatcher()]
+  ) {
+    this.dispatchers = new Map(dispatchers.map((d) => [d.channel, d]));
+  }
+
+  start() {
+    this.timer = setInterval(() => this.nudge(), config.DELIVERY_POLL_INTERVAL_MS);
+    this.nudge();
+    logger.info({ intervalMs: config.DELIVERY_POLL_INTERVAL_MS }, 'Delivery worker started');
+  }
+
+  // nudge runs a pass now, or right after the one in progress.
+  nudge() {
+    if (this.stopped) return;
+    if (this.running) {
+      this.again = true;
+      return;
+    }
+    this.running = this.drain()
+      .catch((err) => logger.error({ err }, 'Delivery pass failed'))
+      .finally(() => {
+        this.running = null;
+        if (this.again) {
+          this.again = false;
+          this.nudge();
+        }
+      });
+  }
+
+  // stop waits for the deliveries in progress; those not started stay due.
+  async stop() {
+    this.stopped = true;
+    if (this.timer) clearInterval(this.timer);
+    await this.running;
+  }
+
+  private async drain() {
+    for (;;) {
+      const due = await this.repository.claimDue(config.DELIVERY_BATCH_SIZE, LEASE_SECONDS);
+      await Promise.all(due.map((delivery) => this.deliver(delivery)));
+      if (due.length < config.DELIVERY_BATCH_SIZE || this.stopped) return;
+    }
+  }
+
+  private async deliver(delivery: DueDelivery) {
+    const log = logger.child({
+      deliveryId: delivery.id,
+      notificationId: delivery.notification.id,
+      channel: delivery.channel,
+      attempt: delivery.attempts,
+    });
+    const dispatcher = this.dispatchers.get(delivery.channel);
+    try {
+      if (!dispatcher) {
+        throw new PermanentError(`no dispatcher for channel ${delivery.channel}`);
+      }
+      await dispatcher.dispatch(delivery.notification);
+      await this.repository.markSent(delivery.id);
+      log.info({ type: delivery.notification.type }, 'Notification delivered');
+    } catch (err) {
+      const message = err instanceof Error ? err.message : String(err);
+      if (err instanceof PermanentError || delivery.attempts >= config.DELIVERY_MAX_ATTEMPTS) {
+        await this.repository.markFailed(delivery.id, message);
+        log.error({ err }, 'Notification delivery failed');
+        return;
+      }
+      const delay = retryDelay(delivery.attempts);
+      await this.repository.markRetry(delivery.id, message, delay);
+      log.warn({ err, retryInSeconds: delay }, 'Notification delivery will be retried');
+    }
+  }
+}
@@ -0,0 +1,74 @@
+import { config } from '../config';
+import { logger } from '../logger';
+import { ComposedDigest, DigestRepository, DueDigest } from '../repositories/digestRepository';
+
+// composeDigest writes the email of a digest: the title and message of each notification,
+// up to DIGEST_MAX_ITEMS, then how many more there were. The items are in its data for
+// the message template of the digest type.
+export function composeDigest(digest: DueDigest, maxItems: number = config.DIGEST_MAX_ITEMS): ComposedDigest {
+  const listed = digest.items.slice(0, maxItems);
+  const more = digest.items.length - listed.length;
+  const lines = listed.map((item) => `- ${item.title}: ${item.message}`);
+  if (more > 0) {
+    lines.push(`...and ${more} more`);
+  }
+  const period = digest.frequency === 'weekly' ? 'week' : 'day';
+
+  return {
+    title: `Your ${digest.frequency} summary: ${digest.items.length} notification${digest.items.length === 1 ? '' : 's'}`,
+    message: `Here is what happened this ${period}:\n\n${lines.join('\n')}`,
+    data: {
+      frequency: digest.frequency,
+      count: digest.items.length,
+      more,
+      items: listed,
+    },
+  };
+}
+
+// DigestScheduler composes the due digests every DIGEST_INTERVAL_MS. The digests are
+// notifications of their own, sent by the delivery worker it wakes up.
+export class DigestScheduler {
+  private timer: NodeJS.Timeout | null = null;
+  private running: Promise<void> | null = null;
+
+  constructor(
+    private readonly onComposed: () => void = () => undefined,
+    private readonly repository: DigestRepository = new DigestRepository()
+  ) {}
+
+  start() {
+    this.timer = setInterval(() => this.tick(), config.DIGEST_INTERVAL_MS);
+    this.tick();
+    logger.info({ intervalMs: config.DIGEST_INTERVAL_MS }, 'Digest scheduler started');
+  }
+
+  async stop() {
+    if (this.timer) clearInterval(this.timer);
+    this.timer = null;
+    await this.running;
+  }
+
+  private tick() {
+    if (this.running) return;
+    this.running = this.run()
+      .catch((err) => logger.error({ err }, 'Digest pass failed'))
+      .finally(() => {
+        this.running = null;
+      });
+  }
+
+  // run composes the due digests, a batch of users at a time, and returns how many.
+  async run(): Promise<number> {
+    let composed = 0;
+    for (;;) {
+      const ids = await this.repository.composeDue(config.DIGEST_BATCH_SIZE, (digest) => composeDigest(digest));
+      composed += ids.length;
+      if (ids.length > 0) {
+        logger.info({ digests: ids.length }, 'Digests composed');
+        this.onComposed();
+      }
+      if (ids.length < config.DIGEST_BATCH_SIZE || !this.timer) return composed;
+    }
+  }
+}
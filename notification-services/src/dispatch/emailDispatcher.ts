@@ -0,0 +1,49 @@
+import { UserContact, UserServiceClient, UserServiceError } from '../clients/userServiceClient';
+import { EmailService } from '../email/EmailService';
+import { buildNotificationEmailTemplate } from '../email/templates';
+import { TemplateRenderer } from '../templates/renderer';
+import { getString } from '../utils/convert';
+import { ChannelDispatcher, DispatchedNotification, PermanentError } from './types';
+
+// EmailDispatcher emails a notification to the address of the user in user-services, or
+// to data.email when the sender gave one, with the email template of its type.
+export class EmailDispatcher implements ChannelDispatcher {
+  readonly channel = 'email' as const;
+
+  constructor(
+    private readonly emailService: EmailService = new EmailService(),
+    private readonly users: UserServiceClient = new UserServiceClient(),
+    private readonly renderer: TemplateRenderer = new TemplateRenderer(undefined, users)
+  ) {}
+
+  async dispatch(notification: DispatchedNotification): Promise<void> {
+    const given = getString(notification.data, 'email');
+    // The user is looked up for the address, or for the locale when the sender gave both
+    const contact = given && notification.locale ? null : await this.lookup(notification.user_id, !given);
+    const to = given ?? (contact?.email as string);
+
+    const message = await this.renderer.render(notification, this.channel, contact);
+    const email = message.html
+      ? { subject: message.title, html: message.html, text: message.body }
+      : buildNotificationEmailTemplate({ title: message.title, message: message.body });
+    await this.emailService.send({ to, ...email });
+  }
+
+  // lookup returns the user; without required an unknown user or failed call is null.
+  private async lookup(userId: string, required: boolean): Promise<UserContact | null> {
+    let contact: UserContact | null;
+    try {
+      contact = await this.users.getContact(userId);
+    } catch (err) {
+      if (!required) return null;
+      if (err instanceof UserServiceError && !err.retryable) {
+        throw new PermanentError(err.message);
+      }
+      throw err;
+    }
+    if (required && !contact?.email) {
+      throw new PermanentError(`user ${userId} has no email address`);
+    }
+    return contact;
+  }
+}
@@ -0,0 +1,19 @@
+import { NotificationRepository } from '../repositories/notificationRepository';
+import { TemplateRenderer } from '../templates/renderer';
+import { ChannelDispatcher, DispatchedNotification } from './types';
+
+// InAppDispatcher puts a notification in the user's inbox, listed by
+// GET /api/notifications/users/:userId/notifications, with the in-app template of its type.
+export class InAppDispatcher implements ChannelDispatcher {
+  readonly channel = 'in_app' as const;
+
+  constructor(
+    private readonly repository: NotificationRepository = new NotificationRepository(),
+    private readonly renderer: TemplateRenderer = new TemplateRenderer()
+  ) {}
+
+  async dispatch(notification: DispatchedNotification): Promise<void> {
+    const message = await this.renderer.render(notification, this.channel);
+    await this.repository.createInAppNotification({ ...notification, title: message.title, message: message.body });
+  }
+}
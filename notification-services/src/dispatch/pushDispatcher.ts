@@ -0,0 +1,54 @@
+import { UserServiceClient, UserServiceError } from '../clients/userServiceClient';
+import { config } from '../config';
+import { logger } from '../logger';
+import { FcmProvider } from '../push/FcmProvider';
+import { LogPushProvider } from '../push/LogPushProvider';
+import { PushProvider } from '../push/PushProvider';
+import { TemplateRenderer } from '../templates/renderer';
+import { ChannelDispatcher, DispatchedNotification, PermanentError } from './types';
+
+// PushDispatcher pushes a notification to the devices the user registered in
+// user-services, and reports the tokens the provider rejected back to it.
+export class PushDispatcher implements ChannelDispatcher {
+  readonly channel = 'push' as const;
+  private readonly provider: PushProvider;
+
+  constructor(
+    provider?: PushProvider,
+    private readonly users: UserServiceClient = new UserServiceClient(),
+    private readonly renderer: TemplateRenderer = new TemplateRenderer(undefined, users)
+  ) {
+    this.provider = provider ?? (config.PUSH_PROVIDER === 'fcm' ? new FcmProvider() : new LogPushProvider());
+  }
+
+  async dispatch(notification: DispatchedNotification): Promise<void> {
+    let targets;
+    try {
+      targets = await this.users.getPushTargets(notification.user_id);
+    } catch (err) {
+      if (err instanceof UserServiceError && !err.retryable) {
+        throw new PermanentError(err.message);
+      }
+      throw err;
+    }
+
+    const tokens = targets.filter((t) => t.provider === this.provider.tokenProvider).map((t) => t.token);
+    if (tokens.length === 0) {
+      // Nothing to retry: the user has no device to push to
+      logger.debug({ userId: notification.user_id, notificationId: notification.id }, 'No push target');
+      return;
+    }
+
+    const message = await this.renderer.render(notification, this.channel);
+    const result = await this.provider.send(tokens, {
+      title: message.title,
+      body: message.body,
+      data: { notification_id: notification.id, type: notification.type },
+    });
+    if (result.invalidTokens.length > 0) {
+      await this.users
+        .reportInvalidTokens(this.provider.tokenProvider, result.invalidTokens, 'rejected by the push provider')
+        .catch((err) => logger.warn({ err }, 'Failed to report invalid push tokens'));
+    }
+  }
+}
@@ -0,0 +1,14 @@
+import { Channel, Notification } from '../models/notification';
+
+export type DispatchedNotification = Omit<Notification, 'deliveries'>;
+
+// ChannelDispatcher sends notifications on one channel. A failure is retried with
+// backoff, unless it is a PermanentError.
+export interface ChannelDispatcher {
+  readonly channel: Channel;
+  dispatch(notification: DispatchedNotification): Promise<void>;
+}
+
+// PermanentError is a failure retrying will not fix, such as a user without an email
+// address; the delivery fails at once.
+export class PermanentError extends Error {}
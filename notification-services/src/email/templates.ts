@@ -9,6 +9,19 @@ export interface PasswordResetEmailParams {
   name?: string;
   resetLink: string;
   expiresInMinutes?: number;
+  // Device and IP address the reset was requested from
+  requestedFrom?: string;
+  // The link only works in the browser and network that requested it
+  sameDeviceRequired?: boolean;
+  appName?: string;
+  supportEmail?: string;
+}
+
+export interface PasswordResetCompletedEmailParams {
+  name?: string;
+  // Device and IP address the password was reset from
+  changedFrom?: string;
+  sessionsRevoked?: boolean;
   appName?: string;
   supportEmail?: string;
 }
@@ -20,6 +33,23 @@ export interface EmailVerificationParams {
   supportEmail?: string;
 }
 
+export interface AccountUnlockEmailParams {
+  name?: string;
+  unlockLink: string;
+  expiresInMinutes?: number;
+  appName?: string;
+  supportEmail?: string;
+}
+
+export interface PasswordlessLoginEmailParams {
+  name?: string;
+  loginLink?: string;
+  loginCode?: string;
+  expiresInMinutes?: number;
+  appName?: string;
+  supportEmail?: string;
+}
+
 export function buildUserRegistrationEmailTemplate(params: UserRegistrationEmailParams) {
   const {
     name,
@@ -100,11 +130,14 @@ export function buildPasswordResetEmailTemplate(params: PasswordResetEmailParams
     name,
     resetLink,
     expiresInMinutes = 60,
+    requestedFrom,
+    sameDeviceRequired = false,
     appName = 'English Learning App',
     supportEmail = 'support@example.com',
   } = params;
 
   const displayName = name || 'there';
+  const origin = requestedFrom ? ` from ${requestedFrom}` : '';
 
   return {
     subject: `Reset Your ${appName} Password`,
@@ -131,7 +164,7 @@ export function buildPasswordResetEmailTemplate(params: PasswordResetEmailParams
           </div>
           <div class="content">
             <h2>Hi ${displayName},</h2>
-            <p>We received a request to reset your password for your ${appName} account.</p>
+            <p>We received a request${origin} to reset your password for your ${appName} account.</p>
             <p>Click the button below to reset your password:</p>
             <p style="text-align: center;">
               <a href="${resetLink}" class="button">Reset Password</a>
@@ -139,7 +172,8 @@ export function buildPasswordResetEmailTemplate(params: PasswordResetEmailParams
             <div class="warning">
               <p><strong>⚠️ Important:</strong></p>
               <ul style="margin: 5px 0;">
-                <li>This link will expire in ${expiresInMinutes} minutes</li>
+                <li>This link will expire in ${expiresInMinutes} minutes and can only be used once</li>
+                ${sameDeviceRequired ? '<li>Open it in the same browser and network you requested it from</li>' : ''}
                 <li>If you didn't request this, you can safely ignore this email</li>
                 <li>Your password will remain unchanged</li>
               </ul>
@@ -168,12 +202,12 @@ export function buildPasswordResetEmailTemplate(params: PasswordResetEmailParams
 
 Hi ${displayName},
 
-We received a request to reset your password for your ${appName} account.
+We received a request${origin} to reset your password for your ${appName} account.
 
 Reset your password by clicking this link:
 ${resetLink}
 
-This link will expire in ${expiresInMinutes} minutes.
+This link will expire in ${expiresInMinutes} minutes and can only be used once.${sameDeviceRequired ? ' Open it in the same browser and network you requested it from.' : ''}
 
 If you didn't request this, you can safely ignore this email and your password will remain unchanged.
 
@@ -184,6 +218,76 @@ ${appName} Team`,
   };
 }
 
+export function buildPasswordResetCompletedEmailTemplate(params: PasswordResetCompletedEmailParams) {
+  const {
+    name,
+    changedFrom,
+    sessionsRevoked = true,
+    appName = 'English Learning App',
+    supportEmail = 'support@example.com',
+  } = params;
+
+  const displayName = name || 'there';
+  const origin = changedFrom ? ` from ${changedFrom}` : '';
+  const sessions = sessionsRevoked
+    ? 'You have been signed out on all your devices. Sign in again with your new password.'
+    : 'Sign in again with your new password.';
+
+  return {
+    subject: `Your ${appName} password was changed`,
+    html: `
+      <!DOCTYPE html>
+      <html>
+      <head>
+        <meta charset="utf-8">
+        <meta name="viewport" content="width=device-width, initial-scale=1.0">
+        <style>
+          body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; line-height: 1.6; color: #333; }
+          .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+          .header { background: linear-gradient(135deg, #f093fb 0%, #f5576c 100%); color: white; padding: 30px; text-align: center; border-radius: 10px 10px 0 0; }
+          .content { background: #ffffff; padding: 30px; border: 1px solid #e0e0e0; border-top: none; }
+          .warning { background: #fff3cd; border-left: 4px solid #ffc107; padding: 15px; margin: 20px 0; }
+          .footer { text-align: center; margin-top: 30px; color: #666; font-size: 14px; }
+        </style>
+      </head>
+      <body>
+        <div class="container">
+          <div class="header">
+            <h1>🔐 Password Changed</h1>
+          </div>
+          <div class="content">
+            <h2>Hi ${displayName},</h2>
+            <p>The password of your ${appName} account was reset${origin}.</p>
+            <p>${sessions}</p>
+            <div class="warning">
+              <p><strong>⚠️ Didn't do this?</strong></p>
+              <p style="margin: 5px 0;">Someone else may have access to your email. Reset your password again right away and contact us at <a href="mailto:${supportEmail}">${supportEmail}</a>.</p>
+            </div>
+            <p><strong>Best regards,</strong><br>${appName} Team</p>
+          </div>
+          <div class="footer">
+            <p>Need help? Contact us at <a href="mailto:${supportEmail}">${supportEmail}</a></p>
+            <p>&copy; ${new Date().getFullYear()} ${appName}. All rights reserved.</p>
+          </div>
+        </div>
+      </body>
+      </html>
+    `,
+    text: `Password Changed
+
+Hi ${displayName},
+
+The password of your ${appName} account was reset${origin}.
+
+${sessions}
+
+Didn't do this? Someone else may have access to your email. Reset your password again right away and contact us at ${supportEmail}.
+
+Best regards,
+${appName} Team`,
+  };
+}
+
 export function buildEmailVerificationTemplate(params: EmailVerificationParams) {
   const {
     name,
@@ -255,3 +359,239 @@ Best regards,
 ${appName} Team`,
   };
 }
+
+export function buildAccountUnlockEmailTemplate(params: AccountUnlockEmailParams) {
+  const {
+    name,
+    unlockLink,
+    expiresInMinutes = 60,
+    appName = 'English Learning App',
+    supportEmail = 'support@example.com',
+  } = params;
+
+  const displayName = name || 'there';
+
+  return {
+    subject: `Unlock Your ${appName} Account`,
+    html: `
+      <!DOCTYPE html>
+      <html>
+      <head>
+        <meta charset="utf-8">
+        <meta name="viewport" content="width=device-width, initial-scale=1.0">
+        <style>
+          body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; line-height: 1.6; color: #333; }
+          .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+          .header { background: linear-gradient(135deg, #f6d365 0%, #fda085 100%); color: white; padding: 30px; text-align: center; border-radius: 10px 10px 0 0; }
+          .content { background: #ffffff; padding: 30px; border: 1px solid #e0e0e0; border-top: none; }
+          .button { display: inline-block; padding: 12px 30px; background: #fda085; color: white; text-decoration: none; border-radius: 5px; margin: 20px 0; }
+          .warning { background: #fff3cd; border-left: 4px solid #ffc107; padding: 15px; margin: 20px 0; }
+          .footer { text-align: center; margin-top: 30px; color: #666; font-size: 14px; }
+        </style>
+      </head>
+      <body>
+        <div class="container">
+          <div class="header">
+            <h1>🔓 Unlock Your Account</h1>
+          </div>
+          <div class="content">
+            <h2>Hi ${displayName},</h2>
+            <p>Your ${appName} account was temporarily locked after too many failed sign-in attempts.</p>
+            <p>If this was you, click the button below to unlock it right away:</p>
+            <p style="text-align: center;">
+              <a href="${unlockLink}" class="button">Unlock Account</a>
+            </p>
+            <div class="warning">
+              <p><strong>⚠️ Important:</strong></p>
+              <ul style="margin: 5px 0;">
+                <li>This link will expire in ${expiresInMinutes} minutes</li>
+                <li>If you didn't try to sign in, someone may be guessing your password. Consider changing it.</li>
+              </ul>
+            </div>
+            <p><strong>Best regards,</strong><br>${appName} Team</p>
+          </div>
+          <div class="footer">
+            <p>If the button doesn't work, copy and paste this link into your browser:</p>
+            <p style="word-break: break-all; color: #fda085;">${unlockLink}</p>
+            <br>
+            <p>Need help? Contact us at <a href="mailto:${supportEmail}">${supportEmail}</a></p>
+            <p>&copy; ${new Date().getFullYear()} ${appName}. All rights reserved.</p>
+          </div>
+        </div>
+      </body>
+      </html>
+    `,
+    text: `Unlock Your Account
+
+Hi ${displayName},
+
+Your ${appName} account was temporarily locked after too many failed sign-in attempts.
+
+If this was you, unlock it by clicking this link:
+${unlockLink}
+
+This link will expire in ${expiresInMinutes} minutes.
+
+If you didn't try to sign in, someone may be guessing your password. Consider changing it.
+
+For help, contact us at ${supportEmail}
+
+Best regards,
+${appName} Team`,
+  };
+}
+
+export function buildPasswordlessLoginEmailTemplate(params: PasswordlessLoginEmailParams) {
+  const {
+    name,
+    loginLink,
+    loginCode,
+    expiresInMinutes = 15,
+    appName = 'English Learning App',
+    supportEmail = 'support@example.com',
+  } = params;
+
+  const displayName = name || 'there';
+  const action = loginCode
+    ? `<p>Enter this code to sign in:</p>
+            <p style="text-align: center;"><span class="code">${loginCode}</span></p>`
+    : `<p>Click the button below to sign in:</p>
+            <p style="text-align: center;">
+              <a href="${loginLink}" class="button">Sign In</a>
+            </p>`;
+  const footer = loginCode
+    ? ''
+    : `<p>If the button doesn't work, copy and paste this link into your browser:</p>
+            <p style="word-break: break-all; color: #4facfe;">${loginLink}</p>
+            <br>`;
+
+  return {
+    subject: loginCode ? `Your ${appName} sign-in code: ${loginCode}` : `Sign in to ${appName}`,
+    html: `
+      <!DOCTYPE html>
+      <html>
+      <head>
+        <meta charset="utf-8">
+        <meta name="viewport" content="width=device-width, initial-scale=1.0">
+        <style>
+          body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; line-height: 1.6; color: #333; }
+          .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+          .header { background: linear-gradient(135deg, #4facfe 0%, #00f2fe 100%); color: white; padding: 30px; text-align: center; border-radius: 10px 10px 0 0; }
+          .content { background: #ffffff; padding: 30px; border: 1px solid #e0e0e0; border-top: none; }
+          .button { display: inline-block; padding: 12px 30px; background: #4facfe; color: white; text-decoration: none; border-radius: 5px; margin: 20px 0; }
+          .code { display: inline-block; padding: 12px 24px; font-size: 28px; font-weight: bold; letter-spacing: 6px; background: #f1f8ff; border-radius: 5px; margin: 20px 0; }
+          .warning { background: #fff3cd; border-left: 4px solid #ffc107; padding: 15px; margin: 20px 0; }
+          .footer { text-align: center; margin-top: 30px; color: #666; font-size: 14px; }
+        </style>
+      </head>
+      <body>
+        <div class="container">
+          <div class="header">
+            <h1>🔑 Sign In</h1>
+          </div>
+          <div class="content">
+            <h2>Hi ${displayName},</h2>
+            <p>We received a request to sign in to your ${appName} account without a password.</p>
+            ${action}
+            <div class="warning">
+              <p><strong>⚠️ Important:</strong></p>
+              <ul style="margin: 5px 0;">
+                <li>This ${loginCode ? 'code' : 'link'} will expire in ${expiresInMinutes} minutes and can only be used once</li>
+                <li>Never share it with anyone</li>
+                <li>If you didn't try to sign in, you can safely ignore this email</li>
+              </ul>
+            </div>
+            <p><strong>Best regards,</strong><br>${appName} Team</p>
+          </div>
+          <div class="footer">
+            ${footer}
+            <p>Need help? Contact us at <a href="mailto:${supportEmail}">${supportEmail}</a></p>
+            <p>&copy; ${new Date().getFullYear()} ${appName}. All rights reserved.</p>
+          </div>
+        </div>
+      </body>
+      </html>
+    `,
+    text: `Sign In
+
+Hi ${displayName},
+
+We received a request to sign in to your ${appName} account without a password.
+
+${loginCode ? `Your sign-in code is: ${loginCode}` : `Sign in by clicking this link:\n${loginLink}`}
+
+This ${loginCode ? 'code' : 'link'} will expire in ${expiresInMinutes} minutes and can only be used once. Never share it with anyone.
+
+If you didn't try to sign in, you can safely ignore this email.
+
+For help, contact us at ${supportEmail}
+
+Best regards,
+${appName} Team`,
+  };
+}
+
+export interface NotificationEmailParams {
+  title: string;
+  message: string;
+  appName?: string;
+  supportEmail?: string;
+}
+
+// escapeHtml escapes the text of notifications, which can carry values typed by users
+// such as refund reasons.
+export function escapeHtml(value: string): string {
+  return value
+    .replace(/&/g, '&amp;')
+    .replace(/</g, '&lt;')
+    .replace(/>/g, '&gt;')
+    .replace(/"/g, '&quot;')
+    .replace(/'/g, '&#39;');
+}
+
+// buildNotificationEmailTemplate is the email of a notification sent by another service,
+// such as an order confirmation, with its title and message.
+export function buildNotificationEmailTemplate(params: NotificationEmailParams) {
+  const { title, message, appName = 'English Learning App', supportEmail = 'support@example.com' } = params;
+
+  return {
+    subject: title,
+    html: `
+      <!DOCTYPE html>
+      <html>
+      <head>
+        <meta charset="utf-8">
+        <meta name="viewport" content="width=device-width, initial-scale=1.0">
+        <style>
+          body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; line-height: 1.6; color: #333; }
+          .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+          .header { background: linear-gradient(135deg, #667eea 0%, #764ba2 100%); color: white; padding: 30px; text-align: center; border-radius: 10px 10px 0 0; }
+          .content { background: #ffffff; padding: 30px; border: 1px solid #e0e0e0; border-top: none; }
+          .footer { text-align: center; margin-top: 30px; color: #666; font-size: 14px; }
+        </style>
+      </head>
+      <body>
+        <div class="container">
+          <div class="header">
+            <h1>${escapeHtml(title)}</h1>
+          </div>
+          <div class="content">
+            <p>${escapeHtml(message).replace(/\n/g, '<br>')}</p>
+            <p><strong>Best regards,</strong><br>${appName} Team</p>
+          </div>
+          <div class="footer">
+            <p>Need help? Contact us at <a href="mailto:${supportEmail}">${supportEmail}</a></p>
+            <p>&copy; ${new Date().getFullYear()} ${appName}. All rights reserved.</p>
+          </div>
+        </div>
+      </body>
+      </html>
+    `,
+    text: `${title}
+
+${message}
+
+Best regards,
+${appName} Team`,
+  };
+}
@@ -3,15 +3,26 @@ import { config } from './config';
 import { logger } from './logger';
 import { router } from './routes/emailRoutes';
 import { notificationRouter } from './routes/notificationRoutes';
-import { initRabbitEmailConsumer, closeRabbit } from './messaging/rabbitmq';
+import { createDeliveryRouter } from './routes/deliveryRoutes';
+import { createMessageTemplateRouter } from './routes/messageTemplateRoutes';
+import { initRabbitConsumers, closeRabbit } from './messaging/rabbitmq';
+import { DeliveryService } from './services/deliveryService';
+import { DeliveryWorker } from './dispatch/deliveryWorker';
+import { DigestScheduler } from './dispatch/digestScheduler';
 import { initDatabase } from './database/connection';
+import { shutdownTracing } from './tracing';
 
 async function main() {
   // Initialize database
   await initDatabase();
 
+  // Deliveries are sent by the worker; new notifications wake it up
+  const worker = new DeliveryWorker();
+  const deliveryService = new DeliveryService(undefined, () => worker.nudge());
+  const digests = new DigestScheduler(() => worker.nudge());
+
   // Initialize RabbitMQ
-  await initRabbitEmailConsumer();
+  await initRabbitConsumers(deliveryService);
 
   const app = express();
   app.use(express.json());
@@ -19,10 +30,14 @@ async function main() {
   // Routes
   app.use('/email', router);
   app.use('/api/notifications', notificationRouter);
+  app.use('/api/v1/notifications', createDeliveryRouter(deliveryService));
+  app.use('/api/v1/message-templates', createMessageTemplateRouter());
 
   const server = app.listen(config.PORT, () => {
     logger.info({ port: config.PORT }, 'Notification service listening');
   });
+  worker.start();
+  digests.start();
 
   process.on('SIGTERM', async () => {
     logger.info('SIGTERM received, shutting down');
@@ -31,6 +46,9 @@ async function main() {
     } catch (e) {
       logger.warn({ err: e }, 'Error closing RabbitMQ');
     }
+    await digests.stop();
+    await worker.stop();
+    await shutdownTracing();
     server.close(() => process.exit(0));
   });
 }
@@ -0,0 +1,145 @@
+import { createHmac, randomBytes, timingSafeEqual } from 'crypto';
+import { NextFunction, Request, Response } from 'express';
+import { config } from './config';
+import { logger } from './logger';
+
+// Service tokens of the Go services (shared/internalauth): HS256 JWTs in the
+// X-Service-Token header, naming the calling service (iss) and the one called (aud).
+// Keep the two implementations in step.
+
+export const SERVICE = 'notification-services';
+export const HEADER = 'x-service-token';
+
+// Every service falls back to the same key without INTERNAL_AUTH_KEYS, outside production
+const DEVELOPMENT_KEY_ID = 'development';
+const DEVELOPMENT_KEY = 'internal-auth-development-key-do-not-use-in-production';
+const MIN_KEY_LENGTH = 32;
+const CLOCK_SKEW_SECONDS = 30;
+
+interface Keys {
+  keys: Map<string, Buffer>;
+  signingKeyId: string;
+  ttlSeconds: number;
+  development: boolean;
+}
+
+export interface ServiceClaims {
+  iss: string;
+  aud: string;
+  iat: number;
+  exp: number;
+  jti: string;
+  sub?: string;
+}
+
+function loadKeys(): Keys {
+  const ttlSeconds = parseDuration(config.INTERNAL_AUTH_TTL) ?? 60;
+  const raw = config.INTERNAL_AUTH_KEYS?.trim();
+  if (!raw) {
+    if (config.ENVIRONMENT.toLowerCase() === 'production') {
+      throw new Error('INTERNAL_AUTH_KEYS must be set in production');
+    }
+    return {
+      keys: new Map([[DEVELOPMENT_KEY_ID, Buffer.from(DEVELOPMENT_KEY)]]),
+      signingKeyId: DEVELOPMENT_KEY_ID,
+      ttlSeconds,
+      development: true,
+    };
+  }
+
+  const keys = new Map<string, Buffer>();
+  let first = '';
+  for (const pair of raw.split(',')) {
+    const [id, secret] = pair.trim().split(/:(.*)/s, 2);
+    if (!id || !secret) {
+      throw new Error('INTERNAL_AUTH_KEYS must list id:secret pairs');
+    }
+    if (secret.length < MIN_KEY_LENGTH) {
+      throw new Error(`INTERNAL_AUTH_KEYS: key ${id} is shorter than ${MIN_KEY_LENGTH} bytes`);
+    }
+    keys.set(id, Buffer.from(secret));
+    first = first || id;
+  }
+  const signingKeyId = config.INTERNAL_AUTH_KEY_ID || first;
+  if (!keys.has(signingKeyId)) {
+    throw new Error(`INTERNAL_AUTH_KEY_ID ${signingKeyId} is not in INTERNAL_AUTH_KEYS`);
+  }
+  return { keys, signingKeyId, ttlSeconds, development: false };
+}
+
+const authKeys = loadKeys();
+if (authKeys.development) {
+  logger.warn('INTERNAL_AUTH_KEYS is not set; service tokens are signed with the development key');
+}
+
+// parseDuration reads the Go durations of INTERNAL_AUTH_TTL, such as "90s" or "1m".
+function parseDuration(value: string): number | undefined {
+  const match = /^(\d+)(s|m|h)$/.exec(value.trim());
+  if (!match) return undefined;
+  const unit = { s: 1, m: 60, h: 3600 }[match[2] as 's' | 'm' | 'h'];
+  return Number(match[1]) * unit;
+}
+
+function encode(value: unknown): string {
+  return Buffer.from(JSON.stringify(value)).toString('base64url');
+}
+
+function sign(key: Buffer, input: string): Buffer {
+  return createHmac('sha256', key).update(input).digest();
+}
+
+// signServiceToken returns a token for a call to audience. Calls made for no user carry
+// no identity claims.
+export function signServiceToken(audience: string): string {
+  const now = Math.floor(Date.now() / 1000);
+  const claims: ServiceClaims = {
+    iss: SERVICE,
+    aud: audience,
+    iat: now,
+    exp: now + authKeys.ttlSeconds,
+    jti: randomBytes(16).toString('hex'),
+  };
+  const input = `${encode({ alg: 'HS256', typ: 'JWT', kid: authKeys.signingKeyId })}.${encode(claims)}`;
+  const key = authKeys.keys.get(authKeys.signingKeyId) as Buffer;
+  return `${input}.${sign(key, input).toString('base64url')}`;
+}
+
+// verifyServiceToken checks a token presented to this service and returns its claims.
+export function verifyServiceToken(token: string): ServiceClaims {
+  const parts = token.split('.');
+  if (parts.length !== 3) throw new Error('malformed service token');
+  const head = JSON.parse(Buffer.from(parts[0], 'base64url').toString('utf8')) as { alg?: string; kid?: string };
+  if (head.alg !== 'HS256') throw new Error('malformed service token');
+  const key = authKeys.keys.get(head.kid ?? '');
+  if (!key) throw new Error('unknown service token key');
+
+  const expected = sign(key, `${parts[0]}.${parts[1]}`);
+  const signature = Buffer.from(parts[2], 'base64url');
+  if (signature.length !== expected.length || !timingSafeEqual(signature, expected)) {
+    throw new Error('invalid service token signature');
+  }
+
+  const claims = JSON.parse(Buffer.from(parts[1], 'base64url').toString('utf8')) as ServiceClaims;
+  const now = Math.floor(Date.now() / 1000);
+  if (!claims.iss || now > claims.exp + CLOCK_SKEW_SECONDS || now + CLOCK_SKEW_SECONDS < claims.iat) {
+    throw new Error('expired service token');
+  }
+  if (claims.aud !== SERVICE) throw new Error('service token issued for another service');
+  return claims;
+}
+
+// serviceAuthRequired restricts a route to the other services. The calling service is
+// left in res.locals.service.
+export function serviceAuthRequired(req: Request, res: Response, next: NextFunction) {
+  const token = req.header(HEADER);
+  if (!token) {
+    return res.status(401).json({ success: false, error: 'Service token required' });
+  }
+  try {
+    res.locals.service = verifyServiceToken(token).iss;
+    next();
+  } catch (err) {
+    logger.warn({ err }, 'Rejected service token');
+    return res.status(401).json({ success: false, error: 'Invalid service token' });
+  }
+}
@@ -0,0 +1,45 @@
+import { UserServiceClient } from '../clients/userServiceClient';
+import { ErasureRepository } from '../repositories/erasureRepository';
+import { getString } from '../utils/convert';
+import { validateUserEvent } from './userEventSchemas';
+
+// The name user-services waits for in ERASURE_SERVICES
+const SERVICE_NAME = 'notifications';
+
+// InvalidErasureEvent is a payload that breaks the contract of UserErasureRequested; a
+// retry would not fix it.
+export class InvalidErasureEvent extends Error {}
+
+export interface ErasureResult {
+  erasureId: string;
+  recordsAffected: number;
+}
+
+// handleErasureRequested scrubs the notifications of a user erased by user-services and
+// confirms it at the callback of the event. It returns null for an erasure that does
+// not wait for this service. Scrubbing and confirming again change nothing, so a
+// redelivered event is handled again.
+export async function handleErasureRequested(
+  payload: Record<string, unknown>,
+  repository: ErasureRepository = new ErasureRepository(),
+  users: UserServiceClient = new UserServiceClient()
+): Promise<ErasureResult | null> {
+  try {
+    validateUserEvent('UserErasureRequested', payload);
+  } catch (err) {
+    throw new InvalidErasureEvent((err as Error).message);
+  }
+  const services = payload['services'] as string[];
+  if (!services.includes(SERVICE_NAME)) {
+    return null;
+  }
+
+  const erasureId = getString(payload, 'erasure_id') as string;
+  const recordsAffected = await repository.scrub(getString(payload, 'user_id') as string);
+  await users.acknowledgeErasure(getString(payload, 'callback_path') as string, {
+    service: SERVICE_NAME,
+    status: 'completed',
+    records_affected: recordsAffected,
+  });
+  return { erasureId, recordsAffected };
+}
@@ -0,0 +1,113 @@
+import { NotificationRequest, NotificationRequestSchema } from '../models/notification';
+import { getNumber, getString } from '../utils/convert';
+
+// The order events of shared/events this service turns into notifications, when
+// ORDER_EVENTS_ENABLED replaces the HTTP calls of order-services. Only schema version 1
+// is known: a newer payload is rejected rather than misread.
+const SCHEMA_VERSION = 1;
+
+// formatAmount renders an amount in cents, such as 1999 USD as "$19.99".
+function formatAmount(cents: number | undefined, currency: string | undefined): string {
+  if (cents === undefined) return '';
+  try {
+    return new Intl.NumberFormat('en-US', { style: 'currency', currency: (currency || 'USD').toUpperCase() }).format(
+      cents / 100
+    );
+  } catch {
+    return `${(cents / 100).toFixed(2)} ${currency ?? ''}`.trim();
+  }
+}
+
+// notificationFromOrderEvent returns the notification of an order event, or null for an
+// event that notifies nobody.
+export function notificationFromOrderEvent(
+  eventType: string,
+  payload: Record<string, unknown>
+): NotificationRequest | null {
+  const version = getNumber(payload, 'schema_version') ?? 1;
+  if (version > SCHEMA_VERSION) {
+    throw new Error(`${eventType} schema version ${version} is newer than ${SCHEMA_VERSION}`);
+  }
+
+  const orderId = getString(payload, 'order_id');
+  const userId = getString(payload, 'user_id');
+  if (!orderId || !userId) {
+    throw new Error(`${eventType} payload is missing order_id or user_id`);
+  }
+  const amount = formatAmount(
+    getNumber(payload, 'total_amount', 'amount'),
+    getString(payload, 'currency')
+  );
+
+  let request: Partial<NotificationRequest>;
+  switch (eventType) {
+    case 'order.paid':
+      request = {
+        type: 'payment_confirmation',
+        title: 'Payment Successful',
+        message: `Your payment of ${amount} for order #${orderId} has been processed successfully.`,
+        data: { order_id: orderId, payment_id: getString(payload, 'payment_id'), items: payload['items'] },
+        channels: ['email', 'push', 'in_app'],
+        priority: 'high',
+      };
+      break;
+    case 'order.cancelled': {
+      const reason = getString(payload, 'reason');
+      request = {
+        type: 'order_cancelled',
+        title: 'Order Cancelled',
+        message: `Your order #${orderId} has been cancelled.${reason ? ` Reason: ${reason}` : ''}`,
+        data: { order_id: orderId, reason },
+        channels: ['email', 'in_app'],
+        priority: 'normal',
+      };
+      break;
+    }
+    case 'order.failed':
+      request = {
+        type: 'payment_failed',
+        title: 'Payment Failed',
+        message: `Your payment for order #${orderId} could not be processed. Please try again.`,
+        data: { order_id: orderId, reason: getString(payload, 'failure_reason') },
+        channels: ['email', 'push', 'in_app'],
+        priority: 'high',
+      };
+      break;
+    case 'order.refunded':
+      request = {
+        type: 'refund_processed',
+        title: 'Refund Processed',
+        message: `Your refund for order #${orderId} has been processed. Amount: ${amount}`,
+        data: { order_id: orderId, refund_id: getString(payload, 'refund_id') },
+        channels: ['email', 'push', 'in_app'],
+        priority: 'high',
+      };
+      break;
+    case 'order.refund_rejected': {
+      const reason = getString(payload, 'admin_reason');
+      request = {
+        type: 'refund_rejected',
+        title: 'Refund Request Rejected',
+        message: `Your refund request for order #${orderId} has been rejected.${reason ? ` Reason: ${reason}` : ''}`,
+        data: { order_id: orderId, refund_id: getString(payload, 'refund_id') },
+        channels: ['email', 'in_app'],
+        priority: 'normal',
+      };
+      break;
+    }
+    case 'order.refund_completed':
+      request = {
+        type: 'refund_completed',
+        title: 'Refund Completed',
+        message: `Refund for order #${orderId} has been completed by the payment processor.`,
+        data: { order_id: orderId, refund_id: getString(payload, 'refund_id') },
+        channels: ['email', 'push', 'in_app'],
+        priority: 'normal',
+      };
+      break;
+    default:
+      return null;
+  }
+
+  return NotificationRequestSchema.parse({ ...request, user_id: userId });
+}
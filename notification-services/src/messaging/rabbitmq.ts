@@ -2,14 +2,21 @@ import { connect, Channel, ChannelModel } from 'amqplib';
 import { config } from '../config';
 import { logger } from '../logger';
 import { EmailService } from '../email/EmailService';
-import { buildPasswordResetEmailTemplate, buildUserRegistrationEmailTemplate, buildEmailVerificationTemplate } from '../email/templates';
+import { buildPasswordResetEmailTemplate, buildPasswordResetCompletedEmailTemplate, buildUserRegistrationEmailTemplate, buildEmailVerificationTemplate, buildAccountUnlockEmailTemplate, buildPasswordlessLoginEmailTemplate } from '../email/templates';
 import { EmailPayload } from '../email/types';
 import { getString, getNumber } from '../utils/convert';
+import { validateUserEvent } from './userEventSchemas';
+import { startConsumerSpan } from '../tracing';
+import { NotificationRequest, NotificationRequestSchema } from '../models/notification';
+import { DeliveryService } from '../services/deliveryService';
+import { notificationFromOrderEvent } from './orderEvents';
+import { handleErasureRequested, InvalidErasureEvent } from './erasures';
+import { UserServiceError } from '../clients/userServiceClient';
 
 let connection: ChannelModel | null = null;
 let channel: Channel | null = null;
 
-export async function initRabbitConsumers() {
+export async function initRabbitConsumers(deliveryService: DeliveryService = new DeliveryService()) {
   if (connection && channel) return; // already initialized
 
   logger.info({ url: config.RABBITMQ_URL }, 'Connecting to RabbitMQ');
@@ -24,6 +31,17 @@ export async function initRabbitConsumers() {
   // Initialize User Events Consumer
   await initUserEventsConsumer(channel);
 
+  // Initialize Notification Requests Consumer
+  await initNotificationConsumer(channel, deliveryService);
+
+  // Initialize User Erasures Consumer
+  await initErasureConsumer(channel);
+
+  // Initialize Order Events Consumer, when it replaces the HTTP calls of order-services
+  if (config.ORDER_EVENTS_ENABLED) {
+    await initOrderEventsConsumer(channel, deliveryService);
+  }
+
   // Handle connection close/errors
   connection.on('error', (err) => logger.error({ err }, 'RabbitMQ connection error'));
   connection.on('close', () => logger.warn('RabbitMQ connection closed'));
@@ -47,15 +65,21 @@ async function initEmailConsumer(ch: Channel) {
     config.RABBITMQ_EMAIL_QUEUE,
     async (msg) => {
       if (!msg) return;
+      const span = startConsumerSpan(`${config.RABBITMQ_EMAIL_QUEUE} process`, msg.properties?.headers);
+      span.setAttribute('messaging.system', 'rabbitmq');
+      span.setAttribute('messaging.destination.name', config.RABBITMQ_EMAIL_QUEUE);
       try {
         const content = msg.content.toString('utf8');
         const payload: EmailPayload = JSON.parse(content);
         await service.send(payload);
         ch.ack(msg);
       } catch (err: unknown) {
-        logger.error({ err }, 'Failed to process email message');
+        span.setError(err);
+        logger.error({ err, trace_id: span.context.traceId }, 'Failed to process email message');
         // discard the message to avoid infinite redelivery loop
         ch.nack(msg, false, false);
+      } finally {
+        span.end();
       }
     },
     { noAck: false }
@@ -88,6 +112,12 @@ async function initUserEventsConsumer(ch: Channel) {
     config.RABBITMQ_USER_EVENTS_QUEUE,
     async (msg) => {
       if (!msg) return;
+      // Continue the trace of the request that raised the event
+      const span = startConsumerSpan(`${msg.fields.routingKey} process`, msg.properties?.headers);
+      span.setAttribute('messaging.system', 'rabbitmq');
+      span.setAttribute('messaging.destination.name', config.RABBITMQ_USER_EVENTS_QUEUE);
+      span.setAttribute('messaging.rabbitmq.destination.routing_key', msg.fields.routingKey);
+      const log = logger.child({ trace_id: span.context.traceId });
       try {
         const content = msg.content.toString('utf8');
         const payload = JSON.parse(content) as Record<string, unknown>;
@@ -99,12 +129,15 @@ async function initUserEventsConsumer(ch: Channel) {
           (typeof payloadType === 'string' ? payloadType : undefined) ||
           msg.fields.routingKey;
 
-        logger.info({ payload, eventType }, 'Received user event');
+        log.info({ payload, eventType }, 'Received user event');
+
+        // Rejects payloads that break the event contract of the producer
+        validateUserEvent(eventType, payload);
 
         const email = buildEmailFromUserEvent(eventType, payload);
 
         if (!email) {
-          logger.warn({ eventType, payload }, 'No email generated for user event');
+          log.warn({ eventType, payload }, 'No email generated for user event');
           ch.ack(msg);
           return;
         }
@@ -113,11 +146,14 @@ async function initUserEventsConsumer(ch: Channel) {
         ch.ack(msg);
 
         const recipients = Array.isArray(email.to) ? email.to : [email.to];
-        logger.info({ email: recipients, eventType }, 'User event email sent successfully');
+        log.info({ email: recipients, eventType }, 'User event email sent successfully');
       } catch (err: unknown) {
-        logger.error({ err }, 'Failed to process user event');
+        span.setError(err);
+        log.error({ err }, 'Failed to process user event');
         // discard the message to avoid infinite redelivery loop
         ch.nack(msg, false, false);
+      } finally {
+        span.end();
       }
     },
     { noAck: false }
@@ -126,6 +162,173 @@ async function initUserEventsConsumer(ch: Channel) {
   logger.info('User events consumer initialized');
 }
 
+// initNotificationConsumer queues the notification requests published on the exchange,
+// the asynchronous form of POST /api/v1/notifications. The message id is the
+// idempotency key and the app id names the sending service.
+async function initNotificationConsumer(ch: Channel, deliveryService: DeliveryService) {
+  await ch.assertQueue(config.RABBITMQ_NOTIFICATION_QUEUE, { durable: true });
+  await ch.bindQueue(
+    config.RABBITMQ_NOTIFICATION_QUEUE,
+    config.RABBITMQ_EXCHANGE,
+    config.RABBITMQ_NOTIFICATION_ROUTING_KEY
+  );
+
+  await ch.prefetch(config.RABBITMQ_PREFETCH);
+
+  await ch.consume(
+    config.RABBITMQ_NOTIFICATION_QUEUE,
+    async (msg) => {
+      if (!msg) return;
+      const span = startConsumerSpan(`${config.RABBITMQ_NOTIFICATION_QUEUE} process`, msg.properties?.headers);
+      span.setAttribute('messaging.system', 'rabbitmq');
+      span.setAttribute('messaging.destination.name', config.RABBITMQ_NOTIFICATION_QUEUE);
+      const log = logger.child({ trace_id: span.context.traceId });
+      try {
+        const parsed = NotificationRequestSchema.safeParse(JSON.parse(msg.content.toString('utf8')));
+        if (!parsed.success) {
+          log.error({ issues: parsed.error.errors }, 'Invalid notification request');
+          ch.nack(msg, false, false);
+          return;
+        }
+        const source =
+          msg.properties?.appId ||
+          (msg.properties?.headers?.source as string | undefined) ||
+          'rabbitmq';
+        await deliveryService.submit(parsed.data, source, msg.properties?.messageId || undefined);
+        ch.ack(msg);
+      } catch (err: unknown) {
+        span.setError(err);
+        if (err instanceof SyntaxError) {
+          log.error({ err }, 'Invalid notification request');
+          ch.nack(msg, false, false);
+          return;
+        }
+        // The request is valid: keep it until it can be stored
+        log.error({ err }, 'Failed to queue notification request');
+        ch.nack(msg, false, true);
+      } finally {
+        span.end();
+      }
+    },
+    { noAck: false }
+  );
+
+  logger.info('Notification requests consumer initialized');
+}
+
+// initOrderEventsConsumer turns the order events of order-services into notifications.
+// The event id is the idempotency key, so a redelivered event notifies once.
+async function initOrderEventsConsumer(ch: Channel, deliveryService: DeliveryService) {
+  await ch.assertExchange(config.RABBITMQ_ORDER_EVENTS_EXCHANGE, 'topic', { durable: true });
+  await ch.assertQueue(config.RABBITMQ_ORDER_EVENTS_QUEUE, { durable: true });
+  const routingKeys = config.RABBITMQ_ORDER_EVENTS_ROUTING_KEY.split(',')
+    .map((key) => key.trim())
+    .filter(Boolean);
+
+  await Promise.all(
+    routingKeys.map((key) =>
+      ch.bindQueue(config.RABBITMQ_ORDER_EVENTS_QUEUE, config.RABBITMQ_ORDER_EVENTS_EXCHANGE, key)
+    )
+  );
+
+  await ch.prefetch(config.RABBITMQ_PREFETCH);
+
+  await ch.consume(
+    config.RABBITMQ_ORDER_EVENTS_QUEUE,
+    async (msg) => {
+      if (!msg) return;
+      const span = startConsumerSpan(`${msg.fields.routingKey} process`, msg.properties?.headers);
+      span.setAttribute('messaging.system', 'rabbitmq');
+      span.setAttribute('messaging.destination.name', config.RABBITMQ_ORDER_EVENTS_QUEUE);
+      span.setAttribute('messaging.rabbitmq.destination.routing_key', msg.fields.routingKey);
+      const log = logger.child({ trace_id: span.context.traceId });
+      let request: NotificationRequest | null;
+      let eventId: string | undefined;
+      try {
+        const payload = JSON.parse(msg.content.toString('utf8')) as Record<string, unknown>;
+        const eventType = getString(payload, 'event_type') || msg.properties?.type || msg.fields.routingKey;
+        eventId = getString(payload, 'event_id') || msg.properties?.messageId || undefined;
+        request = notificationFromOrderEvent(eventType, payload);
+        if (!request) {
+          log.warn({ eventType }, 'No notification for order event');
+          ch.ack(msg);
+          span.end();
+          return;
+        }
+      } catch (err: unknown) {
+        span.setError(err);
+        log.error({ err }, 'Invalid order event');
+        // discard the message to avoid infinite redelivery loop
+        ch.nack(msg, false, false);
+        span.end();
+        return;
+      }
+
+      try {
+        await deliveryService.submit(request, 'order-services', eventId);
+        ch.ack(msg);
+      } catch (err: unknown) {
+        span.setError(err);
+        log.error({ err }, 'Failed to queue order event notification');
+        ch.nack(msg, false, true);
+      } finally {
+        span.end();
+      }
+    },
+    { noAck: false }
+  );
+
+  logger.info({ routingKeys }, 'Order events consumer initialized');
+}
+
+// initErasureConsumer scrubs the notifications of the users erased by user-services and
+// confirms each erasure. Failures of the database or of user-services are retried.
+async function initErasureConsumer(ch: Channel) {
+  await ch.assertQueue(config.RABBITMQ_ERASURE_QUEUE, { durable: true });
+  await ch.bindQueue(config.RABBITMQ_ERASURE_QUEUE, config.RABBITMQ_EXCHANGE, 'user.erasure_requested');
+
+  await ch.prefetch(config.RABBITMQ_PREFETCH);
+
+  await ch.consume(
+    config.RABBITMQ_ERASURE_QUEUE,
+    async (msg) => {
+      if (!msg) return;
+      const span = startConsumerSpan(`${msg.fields.routingKey} process`, msg.properties?.headers);
+      span.setAttribute('messaging.system', 'rabbitmq');
+      span.setAttribute('messaging.destination.name', config.RABBITMQ_ERASURE_QUEUE);
+      span.setAttribute('messaging.rabbitmq.destination.routing_key', msg.fields.routingKey);
+      const log = logger.child({ trace_id: span.context.traceId });
+      try {
+        const payload = JSON.parse(msg.content.toString('utf8')) as Record<string, unknown>;
+        const result = await handleErasureRequested(payload);
+        ch.ack(msg);
+        if (result) {
+          log.info({ erasure_id: result.erasureId, records_affected: result.recordsAffected }, 'Erased user notifications');
+        }
+      } catch (err: unknown) {
+        span.setError(err);
+        const permanent =
+          err instanceof SyntaxError ||
+          err instanceof InvalidErasureEvent ||
+          (err instanceof UserServiceError && !err.retryable);
+        if (permanent) {
+          log.error({ err }, 'Invalid erasure event');
+          ch.nack(msg, false, false);
+          return;
+        }
+        // The erasure is valid: keep it until it is scrubbed and confirmed
+        log.error({ err }, 'Failed to handle erasure event');
+        ch.nack(msg, false, true);
+      } finally {
+        span.end();
+      }
+    },
+    { noAck: false }
+  );
+
+  logger.info('User erasures consumer initialized');
+}
+
 // Keep the old function name for backward compatibility
 export const initRabbitEmailConsumer = initRabbitConsumers;
 
@@ -172,6 +375,8 @@ function buildEmailFromUserEvent(
             'expires_in',
             'expiresIn'
           ),
+          requestedFrom: describeOrigin(payload),
+          sameDeviceRequired: payload['same_device_required'] === true,
           appName: getString(payload, 'appName'),
           supportEmail: getString(payload, 'support_email', 'supportEmail'),
         }),
@@ -194,6 +399,57 @@ function buildEmailFromUserEvent(
         }),
       };
     }
+    case 'passwordresetcompleted':
+    case 'user.password_reset_completed': {
+      return {
+        to: email,
+        ...buildPasswordResetCompletedEmailTemplate({
+          name: getString(payload, 'name'),
+          changedFrom: describeOrigin(payload),
+          sessionsRevoked: payload['sessions_revoked'] !== false,
+          appName: getString(payload, 'appName'),
+          supportEmail: getString(payload, 'support_email', 'supportEmail'),
+        }),
+      };
+    }
+    case 'accountunlockrequested':
+    case 'user.account_unlock':
+    case 'user.accountunlock': {
+      const unlockLink = getString(payload, 'unlock_link', 'unlockLink');
+      if (!unlockLink) {
+        throw new Error('Account unlock event payload is missing unlock link');
+      }
+      return {
+        to: email,
+        ...buildAccountUnlockEmailTemplate({
+          name: getString(payload, 'name'),
+          unlockLink,
+          expiresInMinutes: getNumber(payload, 'expires_in_minutes', 'expiresInMinutes'),
+          appName: getString(payload, 'appName'),
+          supportEmail: getString(payload, 'support_email', 'supportEmail'),
+        }),
+      };
+    }
+    case 'passwordlessloginrequested':
+    case 'user.passwordless_login':
+    case 'user.passwordlesslogin': {
+      const loginLink = getString(payload, 'login_link', 'loginLink');
+      const loginCode = getString(payload, 'login_code', 'loginCode');
+      if (!loginLink && !loginCode) {
+        throw new Error('Passwordless login event payload is missing login link and code');
+      }
+      return {
+        to: email,
+        ...buildPasswordlessLoginEmailTemplate({
+          name: getString(payload, 'name'),
+          loginLink,
+          loginCode,
+          expiresInMinutes: getNumber(payload, 'expires_in_minutes', 'expiresInMinutes'),
+          appName: getString(payload, 'appName'),
+          supportEmail: getString(payload, 'support_email', 'supportEmail'),
+        }),
+      };
+    }
     default:
       return null;
   }
@@ -224,4 +480,15 @@ export async function closeRabbit() {
       connection = null;
     }
   }
-}
\ No newline at end of file
+}
+
+// describeOrigin names the device and IP address a request came from, e.g.
+// "Chrome on macOS (203.0.113.7)".
+function describeOrigin(payload: Record<string, unknown>): string | undefined {
+  const device = getString(payload, 'device');
+  const ip = getString(payload, 'ip_addr', 'ipAddr');
+  if (device && ip) {
+    return `${device} (${ip})`;
+  }
+  return device ?? ip;
+}
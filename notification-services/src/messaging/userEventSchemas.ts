@@ -0,0 +1,138 @@
+import { z } from 'zod';
+
+// Schemas of the user events this service consumes. They mirror the event contract of the
+// Go services in shared/events (user.go): update both when an event changes.
+
+const meta = {
+  event_id: z.string().optional(),
+  event_type: z.string().optional(),
+  schema_version: z.number().int().positive().optional(),
+  occurred_at: z.string().optional(),
+};
+
+const requestOrigin = {
+  ip_addr: z.string().optional(),
+  device: z.string().optional(),
+  requested_at: z.string().min(1),
+};
+
+const email = z.string().min(1);
+const name = z.string().optional();
+const expiresInMinutes = z.number().int().positive();
+
+interface UserEventContract {
+  version: number;
+  schema: z.ZodTypeAny;
+}
+
+const contracts: Record<string, UserEventContract> = {
+  usercreated: {
+    version: 1,
+    schema: z.object({ ...meta, user_id: z.string().uuid(), email, name }).passthrough(),
+  },
+  emailverificationrequested: {
+    version: 1,
+    schema: z
+      .object({ ...meta, user_id: z.string().uuid(), email, name, verification_link: z.string().min(1) })
+      .passthrough(),
+  },
+  passwordresetrequested: {
+    version: 1,
+    schema: z
+      .object({
+        ...meta,
+        ...requestOrigin,
+        email,
+        name,
+        reset_link: z.string().min(1),
+        expires_in_minutes: expiresInMinutes,
+        same_device_required: z.boolean().optional(),
+      })
+      .passthrough(),
+  },
+  passwordresetcompleted: {
+    version: 1,
+    schema: z
+      .object({ ...meta, ...requestOrigin, email, name, sessions_revoked: z.boolean().optional() })
+      .passthrough(),
+  },
+  accountunlockrequested: {
+    version: 1,
+    schema: z
+      .object({ ...meta, email, name, unlock_link: z.string().min(1), expires_in_minutes: expiresInMinutes })
+      .passthrough(),
+  },
+  passwordlessloginrequested: {
+    version: 1,
+    schema: z
+      .object({
+        ...meta,
+        email,
+        name,
+        method: z.string().min(1),
+        login_link: z.string().optional(),
+        login_code: z.string().optional(),
+        expires_in_minutes: expiresInMinutes,
+      })
+      .passthrough()
+      .refine((payload) => !!payload.login_link || !!payload.login_code, {
+        message: 'login_link or login_code is required',
+      }),
+  },
+  // Not emailed: the services holding personal data scrub it, see erasures.ts
+  usererasurerequested: {
+    version: 1,
+    schema: z
+      .object({
+        ...meta,
+        erasure_id: z.string().uuid(),
+        user_id: z.string().uuid(),
+        email_sha256: z.string().optional(),
+        requested_at: z.string().min(1),
+        services: z.array(z.string()).min(1),
+        callback_path: z.string().min(1),
+        deadline: z.string().min(1),
+      })
+      .passthrough(),
+  },
+};
+
+// Events arrive with their type or, from older producers, only their routing key
+const routingKeys: Record<string, string> = {
+  'user.created': 'usercreated',
+  'user.email_verification': 'emailverificationrequested',
+  'user.password_reset': 'passwordresetrequested',
+  'user.password_reset_completed': 'passwordresetcompleted',
+  'user.account_unlock': 'accountunlockrequested',
+  'user.passwordless_login': 'passwordlessloginrequested',
+  'user.erasure_requested': 'usererasurerequested',
+};
+
+/**
+ * validateUserEvent checks a user event against its contract and throws when the payload
+ * has a schema version newer than this service knows, or misses required fields.
+ * Payloads without a schema_version were published before the contract and are checked
+ * as version 1. Events without a contract are left to the caller.
+ */
+export function validateUserEvent(eventType: string | undefined, payload: Record<string, unknown>): void {
+  const normalizedType = eventType?.toLowerCase() ?? '';
+  const contract = contracts[routingKeys[normalizedType] ?? normalizedType];
+  if (!contract) {
+    return;
+  }
+
+  const version = typeof payload['schema_version'] === 'number' ? payload['schema_version'] : 1;
+  if (version > contract.version) {
+    throw new Error(
+      `Unsupported ${eventType} schema version ${version}, supported up to ${contract.version}`
+    );
+  }
+
+  const result = contract.schema.safeParse(payload);
+  if (!result.success) {
+    const problems = result.error.issues
+      .map((issue) => (issue.path.length > 0 ? `${issue.path.join('.')}: ${issue.message}` : issue.message))
+      .join('; ');
+    throw new Error(`Invalid ${eventType} event: ${problems}`);
+  }
+}
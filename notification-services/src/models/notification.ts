@@ -43,6 +43,145 @@ export const MarkAsReadSchema = z.object({
     notification_ids: z.array(z.string().uuid()).min(1),
 });
 
+// Notification requests: the NotificationRequest of order-services, posted to
+// POST /api/v1/notifications or published with the notification.send routing key
+export const CHANNELS = ['email', 'push', 'in_app'] as const;
+export const PRIORITIES = ['low', 'normal', 'medium', 'high', 'urgent'] as const;
+
+// Locales are language tags such as "en" or "pt-br", kept in lower case
+export const LocaleSchema = z
+    .string()
+    .regex(/^[a-z]{2,3}(-[a-z0-9]{2,8})?$/i, 'must be a language tag such as en or pt-BR')
+    .transform((locale) => locale.toLowerCase());
+
+export const NotificationRequestSchema = z.object({
+    user_id: z.string().uuid(),
+    type: z.string().min(1).max(100),
+    title: z.string().min(1).max(255),
+    message: z.string().min(1).max(5000),
+    data: z.record(z.any()).nullish().transform((data) => data ?? {}),
+    channels: z.array(z.enum(CHANNELS)).min(1).default(['in_app']).transform((channels) => [...new Set(channels)]),
+    priority: z.enum(PRIORITIES).default('normal'),
+    // The locale of the message templates; the locale of the user by default
+    locale: LocaleSchema.optional(),
+});
+
+export type Channel = (typeof CHANNELS)[number];
+export type Priority = (typeof PRIORITIES)[number];
+export type NotificationRequest = z.infer<typeof NotificationRequestSchema>;
+
+// A delivery is pending until the worker sends it, then sent, or failed once it ran out
+// of attempts or the channel refused it for good. A low-priority delivery for a user with
+// a digest waits as digest until the digest including it is composed, then is digested.
+export type DeliveryStatus = 'pending' | 'sending' | 'sent' | 'failed' | 'digest' | 'digested';
+
+export interface Delivery {
+    id: string;
+    channel: Channel;
+    status: DeliveryStatus;
+    attempts: number;
+    next_attempt_at: string;
+    last_error: string | null;
+    sent_at: string | null;
+    digest_id: string | null;
+}
+
+export interface Notification {
+    id: string;
+    user_id: string;
+    type: string;
+    title: string;
+    message: string;
+    data: Record<string, any>;
+    priority: Priority;
+    locale: string | null;
+    source: string;
+    created_at: string;
+    deliveries: Delivery[];
+}
+
+// Notification preferences of a user. digest gathers the low-priority email and push
+// notifications in one email a day or a week.
+export const DIGEST_FREQUENCIES = ['off', 'daily', 'weekly'] as const;
+
+const QuietHour = z.string().regex(/^([01]\d|2[0-3]):[0-5]\d$/, 'must be HH:MM');
+
+export const UpdateNotificationPreferencesSchema = z
+    .object({
+        in_app_enabled: z.boolean(),
+        email_enabled: z.boolean(),
+        push_enabled: z.boolean(),
+        types: z.record(z.boolean()),
+        quiet_hours_start: QuietHour.nullable(),
+        quiet_hours_end: QuietHour.nullable(),
+        digest: z.enum(DIGEST_FREQUENCIES),
+    })
+    .partial();
+
+export type DigestFrequency = (typeof DIGEST_FREQUENCIES)[number];
+export type UpdateNotificationPreferences = z.infer<typeof UpdateNotificationPreferencesSchema>;
+
+export interface NotificationPreferences {
+    user_id: string;
+    in_app_enabled: boolean;
+    email_enabled: boolean;
+    push_enabled: boolean;
+    types: Record<string, boolean>;
+    quiet_hours_start: string | null;
+    quiet_hours_end: string | null;
+    digest: DigestFrequency;
+    last_digest_at: string | null;
+    updated_at: string | null;
+}
+
+// Message templates: the copy of a notification type on a channel in one locale. The
+// title is the email subject or push title; the body is the plain text, and html the
+// email body (the plain text is laid out when it is missing).
+export const PUSH_BODY_MAX_LENGTH = 240;
+
+const MessageTemplateFields = z.object({
+    type: z.string().min(1).max(100),
+    channel: z.enum(CHANNELS),
+    locale: LocaleSchema,
+    title: z.string().min(1).max(255),
+    body: z.string().min(1).max(20000),
+    html: z.string().min(1).max(100000).nullish(),
+});
+
+// The variables and channel rules of a template are checked by MessageTemplateService
+export const CreateMessageTemplateSchema = MessageTemplateFields;
+
+// The type, channel and locale of a template name it and do not change
+export const UpdateMessageTemplateSchema = MessageTemplateFields.pick({ title: true, body: true, html: true })
+    .partial()
+    .refine((update) => Object.keys(update).length > 0, 'nothing to update');
+
+export const ListMessageTemplatesQuerySchema = z.object({
+    type: z.string().min(1).optional(),
+    channel: z.enum(CHANNELS).optional(),
+    locale: LocaleSchema.optional(),
+});
+
+export const PreviewMessageTemplateSchema = z.object({
+    data: z.record(z.any()).default({}),
+});
+
+export interface MessageTemplate {
+    id: string;
+    type: string;
+    channel: Channel;
+    locale: string;
+    title: string;
+    body: string;
+    html: string | null;
+    created_at: string;
+    updated_at: string;
+}
+
+export type CreateMessageTemplate = z.infer<typeof CreateMessageTemplateSchema>;
+export type UpdateMessageTemplate = z.infer<typeof UpdateMessageTemplateSchema>;
+export type ListMessageTemplatesQuery = z.infer<typeof ListMessageTemplatesQuerySchema>;
+
 // Response Schemas
 export const NotificationTemplateWithCountSchema = NotificationTemplateSchema.extend({
     user_count: z.number().int().min(0),
@@ -0,0 +1,102 @@
+import { createSign } from 'crypto';
+import { config } from '../config';
+import { PushProvider } from './PushProvider';
+import { PushMessage, PushResult } from './types';
+
+const TOKEN_URL = 'https://oauth2.googleapis.com/token';
+const SCOPE = 'https://www.googleapis.com/auth/firebase.messaging';
+const TIMEOUT_MS = 10_000;
+
+interface ServiceAccount {
+  client_email: string;
+  private_key: string;
+}
+
+// FcmProvider sends pushes with the FCM HTTP v1 API, authenticated with the OAuth token
+// of a service account. FCM also delivers to iOS apps through their FCM tokens.
+export class FcmProvider implements PushProvider {
+  readonly tokenProvider = 'fcm' as const;
+  private readonly account: ServiceAccount;
+  private readonly projectId: string;
+  private accessToken: { value: string; expiresAt: number } | null = null;
+
+  constructor() {
+    if (!config.FCM_PROJECT_ID || !config.FCM_SERVICE_ACCOUNT) {
+      throw new Error('PUSH_PROVIDER=fcm needs FCM_PROJECT_ID and FCM_SERVICE_ACCOUNT');
+    }
+    this.projectId = config.FCM_PROJECT_ID;
+    this.account = JSON.parse(config.FCM_SERVICE_ACCOUNT) as ServiceAccount;
+  }
+
+  async send(tokens: string[], message: PushMessage): Promise<PushResult> {
+    const accessToken = await this.getAccessToken();
+    const invalidTokens: string[] = [];
+    let lastError: Error | null = null;
+    let sent = 0;
+
+    for (const token of tokens) {
+      const response = await fetch(`https://fcm.googleapis.com/v1/projects/${this.projectId}/messages:send`, {
+        method: 'POST',
+        headers: { Authorization: `Bearer ${accessToken}`, 'Content-Type': 'application/json' },
+        body: JSON.stringify({
+          message: {
+            token,
+            notification: { title: message.title, body: message.body },
+            data: message.data,
+          },
+        }),
+        signal: AbortSignal.timeout(TIMEOUT_MS),
+      });
+      if (response.ok) {
+        sent++;
+        continue;
+      }
+      const details = await response.text();
+      // UNREGISTERED (404) and INVALID_ARGUMENT (400) name a token that will never work
+      if (response.status === 404 || (response.status === 400 && details.includes('INVALID_ARGUMENT'))) {
+        invalidTokens.push(token);
+        continue;
+      }
+      lastError = new Error(`FCM returned ${response.status}: ${details.slice(0, 200)}`);
+    }
+
+    // A push reaching one device of the user is sent; otherwise the delivery is retried
+    if (sent === 0 && lastError) {
+      throw lastError;
+    }
+    return { invalidTokens };
+  }
+
+  private async getAccessToken(): Promise<string> {
+    const now = Math.floor(Date.now() / 1000);
+    if (this.accessToken && this.accessToken.expiresAt - 60 > now) {
+      return this.accessToken.value;
+    }
+
+    const encode = (value: unknown) => Buffer.from(JSON.stringify(value)).toString('base64url');
+    const input = `${encode({ alg: 'RS256', typ: 'JWT' })}.${encode({
+      iss: this.account.client_email,
+      scope: SCOPE,
+      aud: TOKEN_URL,
+      iat: now,
+      exp: now + 3600,
+    })}`;
+    const signature = createSign('RSA-SHA256').update(input).sign(this.account.private_key).toString('base64url');
+
+    const response = await fetch(TOKEN_URL, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/x-www-form-urlencoded' },
+      body: new URLSearchParams({
+        grant_type: 'urn:ietf:params:oauth:grant-type:jwt-bearer',
+        assertion: `${input}.${signature}`,
+      }).toString(),
+      signal: AbortSignal.timeout(TIMEOUT_MS),
+    });
+    if (!response.ok) {
+      throw new Error(`FCM authentication failed with ${response.status}`);
+    }
+    const token = (await response.json()) as { access_token: string; expires_in: number };
+    this.accessToken = { value: token.access_token, expiresAt: now + token.expires_in };
+    return token.access_token;
+  }
+}
@@ -0,0 +1,13 @@
+import { logger } from '../logger';
+import { PushProvider } from './PushProvider';
+import { PushMessage, PushResult } from './types';
+
+// LogPushProvider logs the pushes instead of sending them, for local environments.
+export class LogPushProvider implements PushProvider {
+  readonly tokenProvider = 'fcm' as const;
+
+  async send(tokens: string[], message: PushMessage): Promise<PushResult> {
+    logger.info({ tokens: tokens.length, title: message.title }, 'Push not sent (PUSH_PROVIDER=log)');
+    return { invalidTokens: [] };
+  }
+}
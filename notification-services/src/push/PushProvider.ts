@@ -0,0 +1,7 @@
+import { PushMessage, PushResult } from './types';
+
+export interface PushProvider {
+  // Providers of this kind of token, as registered in user-services
+  readonly tokenProvider: 'fcm' | 'apns';
+  send(tokens: string[], message: PushMessage): Promise<PushResult>;
+}
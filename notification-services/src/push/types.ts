@@ -0,0 +1,11 @@
+export interface PushMessage {
+  title: string;
+  body: string;
+  // Values delivered to the app with the notification; FCM only carries strings
+  data: Record<string, string>;
+}
+
+export interface PushResult {
+  // Tokens the provider reports as unregistered or invalid, to delete
+  invalidTokens: string[];
+}
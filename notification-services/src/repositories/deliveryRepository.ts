@@ -0,0 +1,188 @@
+import { db } from '../database/connection';
+import { Channel, Delivery, Notification, NotificationRequest } from '../models/notification';
+
+// A delivery claimed by the worker, with the notification it sends
+export interface DueDelivery {
+    id: string;
+    channel: Channel;
+    attempts: number;
+    notification: Omit<Notification, 'deliveries'>;
+}
+
+// Higher priorities are sent first when deliveries pile up
+const PRIORITY_RANK = `CASE n.priority WHEN 'urgent' THEN 4 WHEN 'high' THEN 3 WHEN 'medium' THEN 2 WHEN 'normal' THEN 1 ELSE 0 END`;
+
+function toNotification(row: any): Omit<Notification, 'deliveries'> {
+    return {
+        id: row.id,
+        user_id: row.user_id,
+        type: row.type,
+        title: row.title,
+        message: row.message,
+        data: row.data ?? {},
+        priority: row.priority,
+        locale: row.locale ?? null,
+        source: row.source,
+        created_at: row.created_at.toISOString(),
+    };
+}
+
+function toDelivery(row: any): Delivery {
+    return {
+        id: row.id,
+        channel: row.channel,
+        status: row.status,
+        attempts: row.attempts,
+        next_attempt_at: row.next_attempt_at.toISOString(),
+        last_error: row.last_error,
+        sent_at: row.sent_at ? row.sent_at.toISOString() : null,
+        digest_id: row.digest_id ?? null,
+    };
+}
+
+export class DeliveryRepository {
+    // create stores a notification with a pending delivery per channel, or one held for
+    // the digest of the user on the held channels. A request with the idempotency key of
+    // an earlier one returns that notification instead, with created false.
+    async create(
+        request: NotificationRequest,
+        source: string,
+        idempotencyKey?: string,
+        held: Channel[] = []
+    ): Promise<{ notification: Notification; created: boolean }> {
+        const client = await db.connect();
+        try {
+            await client.query('BEGIN');
+            const inserted = await client.query(
+                `INSERT INTO notifications (user_id, type, title, message, data, priority, locale, source, idempotency_key)
+                 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+                 ON CONFLICT (idempotency_key) DO NOTHING
+                 RETURNING *`,
+                [
+                    request.user_id,
+                    request.type,
+                    request.title,
+                    request.message,
+                    JSON.stringify(request.data),
+                    request.priority,
+                    request.locale ?? null,
+                    source,
+                    idempotencyKey ?? null,
+                ]
+            );
+            if (inserted.rows.length === 0) {
+                await client.query('ROLLBACK');
+                const existing = await this.findByIdempotencyKey(idempotencyKey as string);
+                if (!existing) throw new Error('Notification with the idempotency key vanished');
+                return { notification: existing, created: false };
+            }
+
+            const row = inserted.rows[0];
+            const deliveries = await client.query(
+                `INSERT INTO notification_deliveries (notification_id, channel, status)
+                 SELECT $1, channel, CASE WHEN channel = ANY($3::text[]) THEN 'digest' ELSE 'pending' END
+                 FROM unnest($2::text[]) AS channel
+                 RETURNING *`,
+                [row.id, request.channels, held]
+            );
+            await client.query('COMMIT');
+            return {
+                notification: { ...toNotification(row), deliveries: deliveries.rows.map(toDelivery) },
+                created: true,
+            };
+        } catch (error) {
+            await client.query('ROLLBACK').catch(() => undefined);
+            throw error;
+        } finally {
+            client.release();
+        }
+    }
+
+    async getById(id: string): Promise<Notification | null> {
+        const result = await db.query('SELECT * FROM notifications WHERE id = $1', [id]);
+        if (result.rows.length === 0) return null;
+        return this.withDeliveries(result.rows[0]);
+    }
+
+    async findByIdempotencyKey(key: string): Promise<Notification | null> {
+        const result = await db.query('SELECT * FROM notifications WHERE idempotency_key = $1', [key]);
+        if (result.rows.length === 0) return null;
+        return this.withDeliveries(result.rows[0]);
+    }
+
+    private async withDeliveries(row: any): Promise<Notification> {
+        const deliveries = await db.query(
+            'SELECT * FROM notification_deliveries WHERE notification_id = $1 ORDER BY channel',
+            [row.id]
+        );
+        return { ...toNotification(row), deliveries: deliveries.rows.map(toDelivery) };
+    }
+
+    // claimDue marks up to limit due deliveries as sending and returns them. A delivery
+    // claimed by a worker that died is claimed again once leaseSeconds passed.
+    async claimDue(limit: number, leaseSeconds: number): Promise<DueDelivery[]> {
+        const result = await db.query(
+            `WITH due AS (
+               SELECT d.id
+               FROM notification_deliveries d
+               JOIN notifications n ON n.id = d.notification_id
+               WHERE d.status IN ('pending', 'sending') AND d.next_attempt_at <= NOW()
+               ORDER BY ${PRIORITY_RANK} DESC, d.next_attempt_at
+               LIMIT $1
+               FOR UPDATE OF d SKIP LOCKED
+             )
+             UPDATE notification_deliveries d
+             SET status = 'sending', attempts = d.attempts + 1,
+                 next_attempt_at = NOW() + make_interval(secs => $2), updated_at = NOW()
+             FROM due, notifications n
+             WHERE d.id = due.id AND n.id = d.notification_id
+             RETURNING d.id AS delivery_id, d.channel, d.attempts, n.*`,
+            [limit, leaseSeconds]
+        );
+        return result.rows.map((row: any) => ({
+            id: row.delivery_id,
+            channel: row.channel,
+            attempts: row.attempts,
+            notification: toNotification(row),
+        }));
+    }
+
+    async markSent(id: string): Promise<void> {
+        await db.query(
+            `UPDATE notification_deliveries
+             SET status = 'sent', sent_at = NOW(), last_error = NULL, updated_at = NOW()
+             WHERE id = $1`,
+            [id]
+        );
+    }
+
+    async markRetry(id: string, error: string, delaySeconds: number): Promise<void> {
+        await db.query(
+            `UPDATE notification_deliveries
+             SET status = 'pending', last_error = $2, next_attempt_at = NOW() + make_interval(secs => $3), updated_at = NOW()
+             WHERE id = $1`,
+            [id, error, delaySeconds]
+        );
+    }
+
+    async markFailed(id: string, error: string): Promise<void> {
+        await db.query(
+            `UPDATE notification_deliveries
+             SET status = 'failed', last_error = $2, updated_at = NOW()
+             WHERE id = $1`,
+            [id, error]
+        );
+    }
+
+    // retryFailed puts the failed deliveries of a notification back in the queue, with
+    // their attempts reset, and returns how many.
+    async retryFailed(notificationId: string): Promise<number> {
+        const result = await db.query(
+            `UPDATE notification_deliveries
+             SET status = 'pending', attempts = 0, next_attempt_at = NOW(), updated_at = NOW()
+             WHERE notification_id = $1 AND status = 'failed'`,
+            [notificationId]
+        );
+        return result.rowCount ?? 0;
+    }
+}
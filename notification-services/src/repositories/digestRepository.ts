@@ -0,0 +1,131 @@
+import { PoolClient } from 'pg';
+import { db } from '../database/connection';
+import { DigestFrequency } from '../models/notification';
+
+// A notification gathered in a digest
+export interface DigestItem {
+    notification_id: string;
+    type: string;
+    title: string;
+    message: string;
+    created_at: string;
+}
+
+// A digest to write: the user, its frequency and the notifications it gathers
+export interface DueDigest {
+    user_id: string;
+    frequency: Exclude<DigestFrequency, 'off'>;
+    items: DigestItem[];
+}
+
+// The title, message and data of the notification a digest is sent as
+export interface ComposedDigest {
+    title: string;
+    message: string;
+    data: Record<string, unknown>;
+}
+
+const PERIOD = `CASE p.digest WHEN 'weekly' THEN INTERVAL '7 days' ELSE INTERVAL '1 day' END`;
+
+export class DigestRepository {
+    // composeDue writes the digests of up to limit users whose period ended: a day or a
+    // week after their last digest, or after the oldest notification held for them. Each
+    // digest is stored as an email notification, and the deliveries it gathers are marked
+    // digested in the same transaction, so a notification is in one digest only. Returns
+    // the ids of the digest notifications.
+    async composeDue(
+        limit: number,
+        compose: (digest: DueDigest) => ComposedDigest
+    ): Promise<string[]> {
+        const client = await db.connect();
+        try {
+            await client.query('BEGIN');
+            const due = await client.query(
+                `SELECT p.user_id, p.digest
+                 FROM notification_preferences p
+                 WHERE p.digest IN ('daily', 'weekly')
+                   AND COALESCE(p.last_digest_at, (
+                         SELECT MIN(d.updated_at)
+                         FROM notification_deliveries d
+                         JOIN notifications n ON n.id = d.notification_id
+                         WHERE n.user_id = p.user_id AND d.status = 'digest'
+                       )) <= NOW() - ${PERIOD}
+                   AND EXISTS (
+                         SELECT 1
+                         FROM notification_deliveries d
+                         JOIN notifications n ON n.id = d.notification_id
+                         WHERE n.user_id = p.user_id AND d.status = 'digest'
+                       )
+                 ORDER BY p.last_digest_at NULLS FIRST
+                 LIMIT $1
+                 FOR UPDATE OF p SKIP LOCKED`,
+                [limit]
+            );
+
+            const ids: string[] = [];
+            for (const row of due.rows) {
+                ids.push(await this.composeOne(client, row.user_id, row.digest, compose));
+            }
+            await client.query('COMMIT');
+            return ids;
+        } catch (error) {
+            await client.query('ROLLBACK').catch(() => undefined);
+            throw error;
+        } finally {
+            client.release();
+        }
+    }
+
+    private async composeOne(
+        client: PoolClient,
+        userId: string,
+        frequency: DueDigest['frequency'],
+        compose: (digest: DueDigest) => ComposedDigest
+    ): Promise<string> {
+        // Taking the held deliveries first leaves those held meanwhile for the next digest
+        const taken = await client.query(
+            `UPDATE notification_deliveries d
+             SET status = 'digested', updated_at = NOW()
+             FROM notifications n
+             WHERE n.id = d.notification_id AND n.user_id = $1 AND d.status = 'digest'
+             RETURNING d.id, d.notification_id`,
+            [userId]
+        );
+        const held = await client.query(
+            `SELECT id, type, title, message, created_at
+             FROM notifications
+             WHERE id = ANY($1::uuid[])
+             ORDER BY created_at`,
+            [[...new Set(taken.rows.map((row: any) => row.notification_id))]]
+        );
+        const items: DigestItem[] = held.rows.map((row: any) => ({
+            notification_id: row.id,
+            type: row.type,
+            title: row.title,
+            message: row.message,
+            created_at: row.created_at.toISOString(),
+        }));
+        const digest = compose({ user_id: userId, frequency, items });
+
+        const inserted = await client.query(
+            `INSERT INTO notifications (user_id, type, title, message, data, priority, source)
+             VALUES ($1, 'digest', $2, $3, $4, 'normal', 'notification-services')
+             RETURNING id`,
+            [userId, digest.title, digest.message, JSON.stringify(digest.data)]
+        );
+        const digestId: string = inserted.rows[0].id;
+        await client.query(
+            `INSERT INTO notification_deliveries (notification_id, channel) VALUES ($1, 'email')`,
+            [digestId]
+        );
+        await client.query('UPDATE notification_deliveries SET digest_id = $2 WHERE id = ANY($1::uuid[])', [
+            taken.rows.map((row: any) => row.id),
+            digestId,
+        ]);
+        await client.query(
+            'UPDATE notification_preferences SET last_digest_at = NOW() WHERE user_id = $1',
+            [userId]
+        );
+        return digestId;
+    }
+}
@@ -0,0 +1,45 @@
+import { db } from '../database/connection';
+
+export class ErasureRepository {
+    // scrub removes the personal data of an erased user in one transaction: the
+    // preferences and in-app notifications are deleted, the notifications sent to the user
+    // keep their type and channels without their content, and deliveries not sent yet are
+    // given up. It returns the rows changed.
+    async scrub(userId: string): Promise<number> {
+        const client = await db.connect();
+        try {
+            await client.query('BEGIN');
+            const preferences = await client.query('DELETE FROM notification_preferences WHERE user_id = $1', [userId]);
+            const inApp = await client.query(
+                `DELETE FROM notification_templates t
+                 USING user_notifications u
+                 WHERE u.notification_id = t.id AND u.user_id = $1`,
+                [userId]
+            );
+            const deliveries = await client.query(
+                `UPDATE notification_deliveries d
+                 SET status = 'failed', last_error = 'user erased', updated_at = NOW()
+                 FROM notifications n
+                 WHERE d.notification_id = n.id AND n.user_id = $1 AND d.status IN ('pending', 'sending', 'digest')`,
+                [userId]
+            );
+            const notifications = await client.query(
+                `UPDATE notifications SET title = '', message = '', data = '{}', locale = NULL
+                 WHERE user_id = $1`,
+                [userId]
+            );
+            await client.query('COMMIT');
+            return (
+                (preferences.rowCount ?? 0) +
+                (inApp.rowCount ?? 0) +
+                (deliveries.rowCount ?? 0) +
+                (notifications.rowCount ?? 0)
+            );
+        } catch (error) {
+            await client.query('ROLLBACK').catch(() => undefined);
+            throw error;
+        } finally {
+            client.release();
+        }
+    }
+}
@@ -0,0 +1,106 @@
+import { db } from '../database/connection';
+import {
+    Channel,
+    CreateMessageTemplate,
+    ListMessageTemplatesQuery,
+    MessageTemplate,
+    UpdateMessageTemplate,
+} from '../models/notification';
+
+// The PostgreSQL error of a unique constraint
+const UNIQUE_VIOLATION = '23505';
+
+// TemplateExistsError is a template created for a type, channel and locale that have one.
+export class TemplateExistsError extends Error {}
+
+function toMessageTemplate(row: any): MessageTemplate {
+    return {
+        id: row.id,
+        type: row.type,
+        channel: row.channel,
+        locale: row.locale,
+        title: row.title,
+        body: row.body,
+        html: row.html,
+        created_at: row.created_at.toISOString(),
+        updated_at: row.updated_at.toISOString(),
+    };
+}
+
+export class MessageTemplateRepository {
+    async list(query: ListMessageTemplatesQuery): Promise<MessageTemplate[]> {
+        const conditions: string[] = [];
+        const values: string[] = [];
+        for (const column of ['type', 'channel', 'locale'] as const) {
+            const value = query[column];
+            if (value) {
+                values.push(value);
+                conditions.push(`${column} = $${values.length}`);
+            }
+        }
+        const where = conditions.length > 0 ? `WHERE ${conditions.join(' AND ')}` : '';
+        const result = await db.query(
+            `SELECT * FROM message_templates ${where} ORDER BY type, channel, locale`,
+            values
+        );
+        return result.rows.map(toMessageTemplate);
+    }
+
+    async getById(id: string): Promise<MessageTemplate | null> {
+        const result = await db.query('SELECT * FROM message_templates WHERE id = $1', [id]);
+        return result.rows.length > 0 ? toMessageTemplate(result.rows[0]) : null;
+    }
+
+    // findAll returns the templates of a type on a channel, in every locale.
+    async findAll(type: string, channel: Channel): Promise<MessageTemplate[]> {
+        const result = await db.query(
+            'SELECT * FROM message_templates WHERE type = $1 AND channel = $2',
+            [type, channel]
+        );
+        return result.rows.map(toMessageTemplate);
+    }
+
+    async create(template: CreateMessageTemplate): Promise<MessageTemplate> {
+        try {
+            const result = await db.query(
+                `INSERT INTO message_templates (type, channel, locale, title, body, html)
+                 VALUES ($1, $2, $3, $4, $5, $6)
+                 RETURNING *`,
+                [template.type, template.channel, template.locale, template.title, template.body, template.html ?? null]
+            );
+            return toMessageTemplate(result.rows[0]);
+        } catch (error: any) {
+            if (error?.code === UNIQUE_VIOLATION) {
+                throw new TemplateExistsError(
+                    `a ${template.channel} template of ${template.type} in ${template.locale} exists`
+                );
+            }
+            throw error;
+        }
+    }
+
+    // update changes the copy of a template; an html of null removes the html body.
+    async update(id: string, update: UpdateMessageTemplate): Promise<MessageTemplate | null> {
+        const sets: string[] = [];
+        const values: unknown[] = [];
+        for (const column of ['title', 'body', 'html'] as const) {
+            if (update[column] !== undefined) {
+                values.push(update[column]);
+                sets.push(`${column} = $${values.length}`);
+            }
+        }
+        values.push(id);
+        const result = await db.query(
+            `UPDATE message_templates SET ${sets.join(', ')}, updated_at = NOW()
+             WHERE id = $${values.length}
+             RETURNING *`,
+            values
+        );
+        return result.rows.length > 0 ? toMessageTemplate(result.rows[0]) : null;
+    }
+
+    async delete(id: string): Promise<boolean> {
+        const result = await db.query('DELETE FROM message_templates WHERE id = $1', [id]);
+        return (result.rowCount ?? 0) > 0;
+    }
+}
@@ -67,6 +67,7 @@ export class NotificationRepository {
         COUNT(un.id) as user_count
       FROM notification_templates nt
       LEFT JOIN user_notifications un ON nt.id = un.notification_id
+      WHERE nt.data->>'notification_id' IS NULL
       GROUP BY nt.id, nt.type, nt.title, nt.body, nt.data, nt.created_at
       ORDER BY nt.created_at DESC
     `;
@@ -180,6 +181,44 @@ export class NotificationRepository {
         }
     }
 
+    // createInAppNotification puts a notification sent by a service in the user's inbox,
+    // as a template of its own read through the user notification endpoints. Running it
+    // again for the same notification changes nothing.
+    async createInAppNotification(notification: {
+        id: string;
+        user_id: string;
+        type: string;
+        title: string;
+        message: string;
+        data: Record<string, any>;
+    }): Promise<void> {
+        const query = `
+      WITH template AS (
+        INSERT INTO notification_templates (type, title, body, data)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT ((data->>'notification_id')) DO NOTHING
+        RETURNING id
+      )
+      INSERT INTO user_notifications (user_id, notification_id)
+      SELECT $5, id FROM template
+    `;
+
+        const values = [
+            notification.type,
+            notification.title,
+            notification.message,
+            JSON.stringify({ ...notification.data, notification_id: notification.id }),
+            notification.user_id,
+        ];
+
+        try {
+            await db.query(query, values);
+        } catch (error) {
+            logger.error({ error, notificationId: notification.id }, 'Failed to create in-app notification');
+            throw error;
+        }
+    }
+
     async getUserNotifications(
         userId: string,
         limit: number = 50,
@@ -0,0 +1,102 @@
+import { db } from '../database/connection';
+import { DigestFrequency, NotificationPreferences, UpdateNotificationPreferences } from '../models/notification';
+
+function toPreferences(row: any): NotificationPreferences {
+    return {
+        user_id: row.user_id,
+        in_app_enabled: row.in_app_enabled,
+        email_enabled: row.email_enabled,
+        push_enabled: row.push_enabled,
+        types: row.types ?? {},
+        quiet_hours_start: row.quiet_hours_start,
+        quiet_hours_end: row.quiet_hours_end,
+        digest: row.digest,
+        last_digest_at: row.last_digest_at ? row.last_digest_at.toISOString() : null,
+        updated_at: row.updated_at ? row.updated_at.toISOString() : null,
+    };
+}
+
+// The preferences of a user who never changed them
+function defaults(userId: string): NotificationPreferences {
+    return {
+        user_id: userId,
+        in_app_enabled: true,
+        email_enabled: true,
+        push_enabled: true,
+        types: {},
+        quiet_hours_start: null,
+        quiet_hours_end: null,
+        digest: 'off',
+        last_digest_at: null,
+        updated_at: null,
+    };
+}
+
+export class PreferencesRepository {
+    async get(userId: string): Promise<NotificationPreferences> {
+        const result = await db.query('SELECT * FROM notification_preferences WHERE user_id = $1', [userId]);
+        return result.rows.length > 0 ? toPreferences(result.rows[0]) : defaults(userId);
+    }
+
+    async getDigest(userId: string): Promise<DigestFrequency> {
+        const result = await db.query('SELECT digest FROM notification_preferences WHERE user_id = $1', [userId]);
+        return result.rows.length > 0 ? result.rows[0].digest : 'off';
+    }
+
+    // update changes some preferences of a user. Turning the digest off releases the
+    // notifications it held, to be sent one by one; released is how many deliveries.
+    async update(
+        userId: string,
+        update: UpdateNotificationPreferences
+    ): Promise<{ preferences: NotificationPreferences; released: number }> {
+        const merged = { ...(await this.get(userId)), ...update };
+        const client = await db.connect();
+        try {
+            await client.query('BEGIN');
+            const result = await client.query(
+                `INSERT INTO notification_preferences
+                   (user_id, in_app_enabled, email_enabled, push_enabled, types, quiet_hours_start, quiet_hours_end, digest)
+                 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+                 ON CONFLICT (user_id) DO UPDATE SET
+                   in_app_enabled = EXCLUDED.in_app_enabled,
+                   email_enabled = EXCLUDED.email_enabled,
+                   push_enabled = EXCLUDED.push_enabled,
+                   types = EXCLUDED.types,
+                   quiet_hours_start = EXCLUDED.quiet_hours_start,
+                   quiet_hours_end = EXCLUDED.quiet_hours_end,
+                   digest = EXCLUDED.digest,
+                   updated_at = NOW()
+                 RETURNING *`,
+                [
+                    userId,
+                    merged.in_app_enabled,
+                    merged.email_enabled,
+                    merged.push_enabled,
+                    JSON.stringify(merged.types),
+                    merged.quiet_hours_start,
+                    merged.quiet_hours_end,
+                    merged.digest,
+                ]
+            );
+
+            let released = 0;
+            if (merged.digest === 'off') {
+                const held = await client.query(
+                    `UPDATE notification_deliveries d
+                     SET status = 'pending', next_attempt_at = NOW(), updated_at = NOW()
+                     FROM notifications n
+                     WHERE n.id = d.notification_id AND n.user_id = $1 AND d.status = 'digest'`,
+                    [userId]
+                );
+                released = held.rowCount ?? 0;
+            }
+            await client.query('COMMIT');
+            return { preferences: toPreferences(result.rows[0]), released };
+        } catch (error) {
+            await client.query('ROLLBACK').catch(() => undefined);
+            throw error;
+        } finally {
+            client.release();
+        }
+    }
+}
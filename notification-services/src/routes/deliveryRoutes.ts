@@ -0,0 +1,94 @@
+import { Router } from 'express';
+import { z } from 'zod';
+import { logger } from '../logger';
+import { serviceAuthRequired } from '../internalAuth';
+import { NotificationRequestSchema } from '../models/notification';
+import { DeliveryService } from '../services/deliveryService';
+
+const IdParamsSchema = z.object({ id: z.string().uuid() });
+
+// createDeliveryRouter serves the notifications the other services send, mounted on
+// /api/v1/notifications. Every route needs a service token.
+export function createDeliveryRouter(service: DeliveryService): Router {
+    const router = Router();
+    router.use(serviceAuthRequired);
+
+    // POST /api/v1/notifications: the NotificationRequest of order-services. An
+    // Idempotency-Key header makes a repeated request return the first notification.
+    router.post('/', async (req, res) => {
+        const parsed = NotificationRequestSchema.safeParse(req.body);
+        if (!parsed.success) {
+            return res.status(400).json({
+                error: 'Validation error',
+                details: parsed.error.errors,
+            });
+        }
+        try {
+            const { notification, created } = await service.submit(
+                parsed.data,
+                res.locals.service as string,
+                req.header('idempotency-key') || undefined
+            );
+            res.status(created ? 201 : 200).json({
+                success: true,
+                data: notification,
+            });
+        } catch (error) {
+            logger.error({ error }, 'Failed to queue notification');
+            res.status(500).json({
+                success: false,
+                error: 'Failed to queue notification',
+            });
+        }
+    });
+
+    // GET /api/v1/notifications/:id: the notification with the state of each delivery
+    router.get('/:id', async (req, res) => {
+        const params = IdParamsSchema.safeParse(req.params);
+        if (!params.success) {
+            return res.status(400).json({ error: 'Invalid parameters', details: params.error.errors });
+        }
+        try {
+            const notification = await service.getNotification(params.data.id);
+            if (!notification) {
+                return res.status(404).json({
+                    success: false,
+                    error: 'Notification not found',
+                });
+            }
+            res.json({
+                success: true,
+                data: notification,
+            });
+        } catch (error) {
+            logger.error({ error }, 'Failed to get notification');
+            res.status(500).json({
+                success: false,
+                error: 'Failed to get notification',
+            });
+        }
+    });
+
+    // POST /api/v1/notifications/:id/retry: queue the failed deliveries again
+    router.post('/:id/retry', async (req, res) => {
+        const params = IdParamsSchema.safeParse(req.params);
+        if (!params.success) {
+            return res.status(400).json({ error: 'Invalid parameters', details: params.error.errors });
+        }
+        try {
+            const retried = await service.retryFailed(params.data.id);
+            res.json({
+                success: true,
+                data: { retried_count: retried },
+            });
+        } catch (error) {
+            logger.error({ error }, 'Failed to retry notification');
+            res.status(500).json({
+                success: false,
+                error: 'Failed to retry notification',
+            });
+        }
+    });
+
+    return router;
+}
@@ -0,0 +1,187 @@
+import { Router } from 'express';
+import { z } from 'zod';
+import { logger } from '../logger';
+import { serviceAuthRequired } from '../internalAuth';
+import {
+    CreateMessageTemplateSchema,
+    ListMessageTemplatesQuerySchema,
+    PreviewMessageTemplateSchema,
+    UpdateMessageTemplateSchema,
+} from '../models/notification';
+import { TemplateExistsError } from '../repositories/messageTemplateRepository';
+import { MessageTemplateService, TemplateValidationError } from '../services/messageTemplateService';
+
+const IdParamsSchema = z.object({ id: z.string().uuid() });
+
+// createMessageTemplateRouter serves the message templates to the back office through
+// the BFF, mounted on /api/v1/message-templates. Every route needs a service token.
+export function createMessageTemplateRouter(service: MessageTemplateService = new MessageTemplateService()): Router {
+    const router = Router();
+    router.use(serviceAuthRequired);
+
+    // GET /api/v1/message-templates?type=&channel=&locale=
+    router.get('/', async (req, res) => {
+        const query = ListMessageTemplatesQuerySchema.safeParse(req.query);
+        if (!query.success) {
+            return res.status(400).json({ error: 'Invalid query parameters', details: query.error.errors });
+        }
+        try {
+            const templates = await service.list(query.data);
+            res.json({
+                success: true,
+                data: templates,
+            });
+        } catch (error) {
+            logger.error({ error }, 'Failed to list message templates');
+            res.status(500).json({
+                success: false,
+                error: 'Failed to list message templates',
+            });
+        }
+    });
+
+    router.get('/:id', async (req, res) => {
+        const params = IdParamsSchema.safeParse(req.params);
+        if (!params.success) {
+            return res.status(400).json({ error: 'Invalid parameters', details: params.error.errors });
+        }
+        try {
+            const template = await service.get(params.data.id);
+            if (!template) {
+                return res.status(404).json({
+                    success: false,
+                    error: 'Message template not found',
+                });
+            }
+            res.json({
+                success: true,
+                data: template,
+            });
+        } catch (error) {
+            logger.error({ error }, 'Failed to get message template');
+            res.status(500).json({
+                success: false,
+                error: 'Failed to get message template',
+            });
+        }
+    });
+
+    // POST /api/v1/message-templates: one template per type, channel and locale
+    router.post('/', async (req, res) => {
+        const parsed = CreateMessageTemplateSchema.safeParse(req.body);
+        if (!parsed.success) {
+            return res.status(400).json({ error: 'Validation error', details: parsed.error.errors });
+        }
+        try {
+            const template = await service.create(parsed.data);
+            res.status(201).json({
+                success: true,
+                data: template,
+            });
+        } catch (error) {
+            if (error instanceof TemplateValidationError) {
+                return res.status(400).json({ error: 'Validation error', details: error.details });
+            }
+            if (error instanceof TemplateExistsError) {
+                return res.status(409).json({ success: false, error: error.message });
+            }
+            logger.error({ error }, 'Failed to create message template');
+            res.status(500).json({
+                success: false,
+                error: 'Failed to create message template',
+            });
+        }
+    });
+
+    router.put('/:id', async (req, res) => {
+        const params = IdParamsSchema.safeParse(req.params);
+        if (!params.success) {
+            return res.status(400).json({ error: 'Invalid parameters', details: params.error.errors });
+        }
+        const parsed = UpdateMessageTemplateSchema.safeParse(req.body);
+        if (!parsed.success) {
+            return res.status(400).json({ error: 'Validation error', details: parsed.error.errors });
+        }
+        try {
+            const template = await service.update(params.data.id, parsed.data);
+            if (!template) {
+                return res.status(404).json({
+                    success: false,
+                    error: 'Message template not found',
+                });
+            }
+            res.json({
+                success: true,
+                data: template,
+            });
+        } catch (error) {
+            if (error instanceof TemplateValidationError) {
+                return res.status(400).json({ error: 'Validation error', details: error.details });
+            }
+            logger.error({ error }, 'Failed to update message template');
+            res.status(500).json({
+                success: false,
+                error: 'Failed to update message template',
+            });
+        }
+    });
+
+    router.delete('/:id', async (req, res) => {
+        const params = IdParamsSchema.safeParse(req.params);
+        if (!params.success) {
+            return res.status(400).json({ error: 'Invalid parameters', details: params.error.errors });
+        }
+        try {
+            const deleted = await service.delete(params.data.id);
+            if (!deleted) {
+                return res.status(404).json({
+                    success: false,
+                    error: 'Message template not found',
+                });
+            }
+            res.json({
+                success: true,
+                message: 'Message template deleted',
+            });
+        } catch (error) {
+            logger.error({ error }, 'Failed to delete message template');
+            res.status(500).json({
+                success: false,
+                error: 'Failed to delete message template',
+            });
+        }
+    });
+
+    // POST /api/v1/message-templates/:id/preview: the template rendered with { data }
+    router.post('/:id/preview', async (req, res) => {
+        const params = IdParamsSchema.safeParse(req.params);
+        if (!params.success) {
+            return res.status(400).json({ error: 'Invalid parameters', details: params.error.errors });
+        }
+        const parsed = PreviewMessageTemplateSchema.safeParse(req.body ?? {});
+        if (!parsed.success) {
+            return res.status(400).json({ error: 'Validation error', details: parsed.error.errors });
+        }
+        try {
+            const rendered = await service.preview(params.data.id, parsed.data.data);
+            if (!rendered) {
+                return res.status(404).json({
+                    success: false,
+                    error: 'Message template not found',
+                });
+            }
+            res.json({
+                success: true,
+                data: rendered,
+            });
+        } catch (error) {
+            logger.error({ error }, 'Failed to preview message template');
+            res.status(500).json({
+                success: false,
+                error: 'Failed to preview message template',
+            });
+        }
+    });
+
+    return router;
+}
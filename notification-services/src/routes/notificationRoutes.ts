@@ -7,6 +7,7 @@ import {
     UpdateNotificationTemplateSchema,
     CreateUserNotificationSchema,
     MarkAsReadSchema,
+    UpdateNotificationPreferencesSchema,
 } from '../models/notification';
 
 const router = Router();
@@ -320,4 +321,45 @@ router.post('/templates/:templateId/send',
     }
 );
 
+// Notification Preferences Routes
+router.get('/users/:userId/preferences',
+    validateParams(z.object({ userId: z.string().uuid() })),
+    async (req, res) => {
+        try {
+            const preferences = await notificationService.getPreferences(req.params.userId);
+            res.json({
+                success: true,
+                data: preferences,
+            });
+        } catch (error) {
+            logger.error({ error }, 'Failed to get notification preferences');
+            res.status(500).json({
+                success: false,
+                error: 'Failed to get notification preferences',
+            });
+        }
+    }
+);
+
+// Omitted fields keep their value; digest is off, daily or weekly
+router.put('/users/:userId/preferences',
+    validateParams(z.object({ userId: z.string().uuid() })),
+    validateBody(UpdateNotificationPreferencesSchema),
+    async (req, res) => {
+        try {
+            const preferences = await notificationService.updatePreferences(req.params.userId, req.body);
+            res.json({
+                success: true,
+                data: preferences,
+            });
+        } catch (error) {
+            logger.error({ error }, 'Failed to update notification preferences');
+            res.status(500).json({
+                success: false,
+                error: 'Failed to update notification preferences',
+            });
+        }
+    }
+);
+
 export { router as notificationRouter };
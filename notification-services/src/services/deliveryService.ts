@@ -0,0 +1,65 @@
+import { logger } from '../logger';
+import { Channel, Notification, NotificationRequest } from '../models/notification';
+import { DeliveryRepository } from '../repositories/deliveryRepository';
+import { PreferencesRepository } from '../repositories/preferencesRepository';
+
+// The channels a digest gathers; in-app notifications reach the inbox at once
+const DIGEST_CHANNELS: Channel[] = ['email', 'push'];
+
+export class DeliveryService {
+    constructor(
+        private readonly repository: DeliveryRepository = new DeliveryRepository(),
+        // Called when deliveries are queued, to send them without waiting for the next poll
+        private readonly onQueued: () => void = () => undefined,
+        private readonly preferences: PreferencesRepository = new PreferencesRepository()
+    ) {}
+
+    // submit stores a notification sent by a service and queues a delivery on each of its
+    // channels. A request repeating the idempotency key of an earlier one is not sent
+    // again: created is false and the earlier notification is returned. Keys are scoped to
+    // the sending service. The email and push deliveries of a low-priority notification
+    // wait for the digest of a user who has one.
+    async submit(
+        request: NotificationRequest,
+        source: string,
+        idempotencyKey?: string
+    ): Promise<{ notification: Notification; created: boolean }> {
+        const key = idempotencyKey ? `${source}:${idempotencyKey}` : undefined;
+        const held = await this.heldChannels(request);
+        const result = await this.repository.create(request, source, key, held);
+        if (result.created) {
+            logger.info(
+                {
+                    notificationId: result.notification.id,
+                    type: request.type,
+                    channels: request.channels,
+                    held,
+                    source,
+                },
+                'Notification queued'
+            );
+            this.onQueued();
+        }
+        return result;
+    }
+
+    private async heldChannels(request: NotificationRequest): Promise<Channel[]> {
+        if (request.priority !== 'low') return [];
+        const digest = await this.preferences.getDigest(request.user_id);
+        if (digest === 'off') return [];
+        return request.channels.filter((channel) => DIGEST_CHANNELS.includes(channel));
+    }
+
+    async getNotification(id: string): Promise<Notification | null> {
+        return this.repository.getById(id);
+    }
+
+    // retryFailed queues the failed deliveries of a notification again and returns how many.
+    async retryFailed(id: string): Promise<number> {
+        const count = await this.repository.retryFailed(id);
+        if (count > 0) {
+            this.onQueued();
+        }
+        return count;
+    }
+}
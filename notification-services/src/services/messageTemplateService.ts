@@ -0,0 +1,77 @@
+import { logger } from '../logger';
+import {
+    CreateMessageTemplate,
+    ListMessageTemplatesQuery,
+    MessageTemplate,
+    PUSH_BODY_MAX_LENGTH,
+    UpdateMessageTemplate,
+} from '../models/notification';
+import { MessageTemplateRepository } from '../repositories/messageTemplateRepository';
+import { RenderedMessage, renderTemplate, templateErrors } from '../templates/renderer';
+
+// TemplateValidationError lists the problems of a template that was refused.
+export class TemplateValidationError extends Error {
+    constructor(readonly details: string[]) {
+        super(details.join('; '));
+    }
+}
+
+// checkTemplate refuses the templates a notification could not be rendered with.
+function checkTemplate(template: Pick<MessageTemplate, 'channel' | 'title' | 'body' | 'html'>) {
+    const details: string[] = [];
+    for (const field of ['title', 'body', 'html'] as const) {
+        const text = template[field];
+        if (text) {
+            details.push(...templateErrors(text).map((error) => `${field}: ${error}`));
+        }
+    }
+    if (template.channel !== 'email' && template.html) {
+        details.push('html: only email templates have an html body');
+    }
+    if (template.channel === 'push' && template.body.length > PUSH_BODY_MAX_LENGTH) {
+        details.push(`body: push bodies are at most ${PUSH_BODY_MAX_LENGTH} characters`);
+    }
+    if (details.length > 0) {
+        throw new TemplateValidationError(details);
+    }
+}
+
+export class MessageTemplateService {
+    constructor(private readonly repository: MessageTemplateRepository = new MessageTemplateRepository()) {}
+
+    async list(query: ListMessageTemplatesQuery): Promise<MessageTemplate[]> {
+        return this.repository.list(query);
+    }
+
+    async get(id: string): Promise<MessageTemplate | null> {
+        return this.repository.getById(id);
+    }
+
+    async create(template: CreateMessageTemplate): Promise<MessageTemplate> {
+        checkTemplate({ ...template, html: template.html ?? null });
+        const created = await this.repository.create(template);
+        logger.info(
+            { templateId: created.id, type: created.type, channel: created.channel, locale: created.locale },
+            'Message template created'
+        );
+        return created;
+    }
+
+    async update(id: string, update: UpdateMessageTemplate): Promise<MessageTemplate | null> {
+        const current = await this.repository.getById(id);
+        if (!current) return null;
+        checkTemplate({ ...current, ...update, html: update.html === undefined ? current.html : update.html });
+        return this.repository.update(id, update);
+    }
+
+    async delete(id: string): Promise<boolean> {
+        return this.repository.delete(id);
+    }
+
+    // preview renders a template with sample data, as a notification would use it.
+    async preview(id: string, data: Record<string, unknown>): Promise<RenderedMessage | null> {
+        const template = await this.repository.getById(id);
+        if (!template) return null;
+        return renderTemplate(template, data);
+    }
+}
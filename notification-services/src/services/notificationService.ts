@@ -1,5 +1,6 @@
 import { v4 as uuidv4 } from 'uuid';
 import { NotificationRepository } from '../repositories/notificationRepository';
+import { PreferencesRepository } from '../repositories/preferencesRepository';
 import { logger } from '../logger';
 import {
     NotificationTemplate,
@@ -9,13 +10,17 @@ import {
     CreateUserNotification,
     NotificationTemplateWithCount,
     UserNotificationWithTemplate,
+    NotificationPreferences,
+    UpdateNotificationPreferences,
 } from '../models/notification';
 
 export class NotificationService {
     private repository: NotificationRepository;
+    private preferences: PreferencesRepository;
 
     constructor() {
         this.repository = new NotificationRepository();
+        this.preferences = new PreferencesRepository();
     }
 
     // Notification Template Services
@@ -179,4 +184,25 @@ export class NotificationService {
             throw error;
         }
     }
+
+    // Notification Preferences Services
+    async getPreferences(userId: string): Promise<NotificationPreferences> {
+        try {
+            return await this.preferences.get(userId);
+        } catch (error) {
+            logger.error({ error, userId }, 'Failed to get notification preferences');
+            throw error;
+        }
+    }
+
+    async updatePreferences(userId: string, update: UpdateNotificationPreferences): Promise<NotificationPreferences> {
+        try {
+            const { preferences, released } = await this.preferences.update(userId, update);
+            logger.info({ userId, digest: preferences.digest, released }, 'Notification preferences updated');
+            return preferences;
+        } catch (error) {
+            logger.error({ error, userId }, 'Failed to update notification preferences');
+            throw error;
+        }
+    }
 }
@@ -0,0 +1,126 @@
+import { UserContact, UserServiceClient } from '../clients/userServiceClient';
+import { config } from '../config';
+import { escapeHtml } from '../email/templates';
+import { logger } from '../logger';
+import { Channel, MessageTemplate, Notification } from '../models/notification';
+import { MessageTemplateRepository } from '../repositories/messageTemplateRepository';
+
+// {{name}} or {{order.total}}: a variable of the notification, looked up by path
+const VARIABLE = /\{\{\s*([A-Za-z0-9_]+(?:\.[A-Za-z0-9_]+)*)\s*\}\}/g;
+
+export type RenderedNotification = Omit<Notification, 'deliveries'>;
+
+// RenderedMessage is the copy of a notification on a channel. html is only set for the
+// email templates that have one.
+export interface RenderedMessage {
+  title: string;
+  body: string;
+  html?: string;
+  locale?: string;
+}
+
+// templateErrors lists what is wrong with the {{variables}} of a template text.
+export function templateErrors(text: string): string[] {
+  const rest = text.replace(VARIABLE, '');
+  const errors: string[] = [];
+  if (rest.includes('{{') || rest.includes('}}')) {
+    errors.push('variables are written {{name}} or {{path.to.name}}');
+  }
+  return errors;
+}
+
+function lookup(variables: Record<string, unknown>, path: string): unknown {
+  let value: unknown = variables;
+  for (const key of path.split('.')) {
+    if (value === null || typeof value !== 'object') return undefined;
+    value = (value as Record<string, unknown>)[key];
+  }
+  return value;
+}
+
+// renderText replaces the variables of a text; unknown variables render empty. HTML
+// escapes the values, so the data of a notification cannot inject markup.
+export function renderText(text: string, variables: Record<string, unknown>, html = false): string {
+  return text.replace(VARIABLE, (_, path: string) => {
+    const value = lookup(variables, path);
+    if (value === undefined || value === null) return '';
+    const rendered = typeof value === 'object' ? JSON.stringify(value) : String(value);
+    return html ? escapeHtml(rendered) : rendered;
+  });
+}
+
+// localeCandidates lists the locales to try in order: each wanted locale, then its
+// language ("pt-br", then "pt"), then the default locale.
+export function localeCandidates(...wanted: (string | null | undefined)[]): string[] {
+  const candidates: string[] = [];
+  for (const locale of [...wanted, config.NOTIFICATION_DEFAULT_LOCALE]) {
+    if (!locale) continue;
+    const normalized = locale.toLowerCase().replace('_', '-');
+    for (const candidate of [normalized, normalized.split('-')[0]]) {
+      if (!candidates.includes(candidate)) candidates.push(candidate);
+    }
+  }
+  return candidates;
+}
+
+// TemplateRenderer writes the copy of a notification from the message template of its
+// type, channel and locale. A notification without a template keeps the title and
+// message its sender wrote.
+export class TemplateRenderer {
+  constructor(
+    private readonly repository: MessageTemplateRepository = new MessageTemplateRepository(),
+    private readonly users: UserServiceClient = new UserServiceClient()
+  ) {}
+
+  // render returns the copy of a notification on a channel. contact is the user when the
+  // caller looked them up already; otherwise user-services is only asked for the locale
+  // when the notification has none and a template exists.
+  async render(notification: RenderedNotification, channel: Channel, contact?: UserContact | null): Promise<RenderedMessage> {
+    const templates = await this.repository.findAll(notification.type, channel);
+    if (templates.length === 0) {
+      return { title: notification.title, body: notification.message };
+    }
+
+    if (contact === undefined && !notification.locale) {
+      contact = await this.users.getContact(notification.user_id).catch((err) => {
+        logger.warn({ err, userId: notification.user_id }, 'Could not read the locale of the user');
+        return null;
+      });
+    }
+    const template = this.select(templates, localeCandidates(notification.locale, contact?.locale));
+    if (!template) {
+      return { title: notification.title, body: notification.message };
+    }
+    return renderTemplate(template, variablesOf(notification, contact ?? null));
+  }
+
+  private select(templates: MessageTemplate[], candidates: string[]): MessageTemplate | undefined {
+    for (const locale of candidates) {
+      const template = templates.find((t) => t.locale === locale);
+      if (template) return template;
+    }
+    return undefined;
+  }
+}
+
+// variablesOf returns the variables a template of the notification can use: the data of
+// its sender, its own fields and the user.
+export function variablesOf(notification: RenderedNotification, contact: UserContact | null): Record<string, unknown> {
+  return {
+    ...notification.data,
+    notification_id: notification.id,
+    type: notification.type,
+    title: notification.title,
+    message: notification.message,
+    user: { id: notification.user_id, name: contact?.name ?? '', email: contact?.email ?? '' },
+  };
+}
+
+export function renderTemplate(template: MessageTemplate, variables: Record<string, unknown>): RenderedMessage {
+  return {
+    title: renderText(template.title, variables),
+    body: renderText(template.body, variables),
+    html: template.html ? renderText(template.html, variables, true) : undefined,
+    locale: template.locale,
+  };
+}
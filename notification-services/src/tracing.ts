@@ -0,0 +1,182 @@
+import { randomBytes } from 'crypto';
+import { config } from './config';
+import { logger } from './logger';
+
+// Distributed tracing of the consumed messages. The Go services publish their events with
+// a W3C traceparent header (shared/telemetry); each message handled here becomes a
+// consumer span in that trace, exported to the OpenTelemetry collector over OTLP/HTTP
+// JSON when OTEL_EXPORTER_OTLP_ENDPOINT is set.
+
+export const TRACEPARENT_HEADER = 'traceparent';
+
+const SPAN_KIND_CONSUMER = 5;
+const STATUS_ERROR = 2;
+const FLUSH_INTERVAL_MS = 5000;
+const MAX_QUEUED_SPANS = 2048;
+
+export interface SpanContext {
+  traceId: string;
+  spanId: string;
+  sampled: boolean;
+}
+
+type AttributeValue = string | number | boolean;
+
+interface FinishedSpan {
+  context: SpanContext;
+  parentSpanId?: string;
+  name: string;
+  kind: number;
+  startNanos: bigint;
+  endNanos: bigint;
+  attributes: Record<string, AttributeValue>;
+  error?: string;
+}
+
+export function parseTraceparent(value: unknown): SpanContext | undefined {
+  if (typeof value !== 'string') return undefined;
+  const match = /^([0-9a-f]{2})-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})/.exec(value.trim().toLowerCase());
+  if (!match || match[1] === 'ff' || /^0+$/.test(match[2]) || /^0+$/.test(match[3])) {
+    return undefined;
+  }
+  return { traceId: match[2], spanId: match[3], sampled: (parseInt(match[4], 16) & 1) === 1 };
+}
+
+export class Span {
+  readonly context: SpanContext;
+  private readonly startNanos = nowNanos();
+  private readonly attributes: Record<string, AttributeValue> = {};
+  private error?: string;
+  private ended = false;
+
+  constructor(private readonly name: string, private readonly kind: number, private readonly parent?: SpanContext) {
+    this.context = {
+      traceId: parent?.traceId ?? randomBytes(16).toString('hex'),
+      spanId: randomBytes(8).toString('hex'),
+      sampled: parent?.sampled ?? true,
+    };
+  }
+
+  setAttribute(key: string, value: AttributeValue) {
+    this.attributes[key] = value;
+  }
+
+  setError(err: unknown) {
+    this.error = err instanceof Error ? err.message : String(err);
+  }
+
+  end() {
+    if (this.ended) return;
+    this.ended = true;
+    if (!this.context.sampled) return;
+    exporter.add({
+      context: this.context,
+      parentSpanId: this.parent?.spanId,
+      name: this.name,
+      kind: this.kind,
+      startNanos: this.startNanos,
+      endNanos: nowNanos(),
+      attributes: this.attributes,
+      error: this.error,
+    });
+  }
+}
+
+// startConsumerSpan continues the trace of a RabbitMQ message, or starts a new one when
+// its headers carry none.
+export function startConsumerSpan(name: string, headers: Record<string, unknown> | undefined): Span {
+  return new Span(name, SPAN_KIND_CONSUMER, parseTraceparent(headers?.[TRACEPARENT_HEADER]));
+}
+
+function nowNanos(): bigint {
+  return BigInt(Date.now()) * 1_000_000n;
+}
+
+function toValue(value: AttributeValue) {
+  if (typeof value === 'boolean') return { boolValue: value };
+  if (typeof value === 'number') return Number.isInteger(value) ? { intValue: String(value) } : { doubleValue: value };
+  return { stringValue: value };
+}
+
+class Exporter {
+  private queue: FinishedSpan[] = [];
+  private timer?: NodeJS.Timeout;
+
+  constructor(private readonly endpoint: string | undefined, private readonly serviceName: string) {
+    if (endpoint) {
+      this.timer = setInterval(() => void this.flush(), FLUSH_INTERVAL_MS);
+      this.timer.unref();
+      logger.info({ endpoint, serviceName }, 'Exporting traces');
+    }
+  }
+
+  add(span: FinishedSpan) {
+    if (!this.endpoint) return;
+    if (this.queue.length >= MAX_QUEUED_SPANS) {
+      logger.warn('Dropped a span, the trace export queue is full');
+      return;
+    }
+    this.queue.push(span);
+  }
+
+  async flush() {
+    if (!this.endpoint || this.queue.length === 0) return;
+    const batch = this.queue;
+    this.queue = [];
+
+    const body = {
+      resourceSpans: [
+        {
+          resource: { attributes: [{ key: 'service.name', value: { stringValue: this.serviceName } }] },
+          scopeSpans: [
+            {
+              scope: { name: 'notification-services/tracing' },
+              spans: batch.map((span) => ({
+                traceId: span.context.traceId,
+                spanId: span.context.spanId,
+                parentSpanId: span.parentSpanId,
+                name: span.name,
+                kind: span.kind,
+                startTimeUnixNano: span.startNanos.toString(),
+                endTimeUnixNano: span.endNanos.toString(),
+                attributes: Object.entries(span.attributes).map(([key, value]) => ({ key, value: toValue(value) })),
+                status: span.error ? { code: STATUS_ERROR, message: span.error } : {},
+              })),
+            },
+          ],
+        },
+      ],
+    };
+
+    try {
+      const res = await fetch(`${this.endpoint}/v1/traces`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify(body),
+      });
+      if (!res.ok) {
+        logger.warn({ status: res.status, spans: batch.length }, 'Failed to export spans');
+      }
+    } catch (err) {
+      logger.warn({ err, spans: batch.length }, 'Failed to export spans');
+    }
+  }
+
+  async shutdown() {
+    if (this.timer) clearInterval(this.timer);
+    await this.flush();
+  }
+}
+
+function exportEndpoint(): string | undefined {
+  if (config.OTEL_SDK_DISABLED) return undefined;
+  const traces = config.OTEL_EXPORTER_OTLP_TRACES_ENDPOINT?.replace(/\/$/, '').replace(/\/v1\/traces$/, '');
+  return traces || config.OTEL_EXPORTER_OTLP_ENDPOINT?.replace(/\/$/, '') || undefined;
+}
+
+const exporter = new Exporter(exportEndpoint(), config.OTEL_SERVICE_NAME);
+
+// shutdownTracing exports the spans still queued.
+export function shutdownTracing(): Promise<void> {
+  return exporter.shutdown();
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"order-services/internal/db"
+	"order-services/internal/models"
+
+	"github.com/ductan2/microservice-app/shared/logging"
+	"github.com/ductan2/microservice-app/shared/seed"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// batchSize is how many orders are written per transaction.
+const batchSize = 500
+
+func main() {
+	var cfg seed.Config
+	cfg.RegisterFlags(flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: seed [flags]\nWrites the paid orders and payments of the seed plan; run the same flags against every service.\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	logging.Setup(logging.ConfigFromEnv("order-services-seed"))
+
+	gormDB, err := db.ConnectPostgres()
+	if err != nil {
+		logging.Fatal("failed to connect to database", "error", err)
+	}
+	sqlDB, err := gormDB.DB()
+	if err == nil {
+		defer sqlDB.Close()
+	}
+
+	orders := seed.New(cfg).Orders()
+	for batch := range slices.Chunk(orders, batchSize) {
+		if err := writeOrders(gormDB, batch); err != nil {
+			logging.Fatal("failed to seed orders", "error", err)
+		}
+	}
+	slog.Info("seeded orders", "orders", len(orders))
+}
+
+// writeOrders saves a batch of paid orders with their items and payments. Rows seeded
+// before are left as they are, so the command can be run again, at the same or a larger
+// scale. No events are written to the outbox: the other services seed their side of the
+// same plan themselves.
+func writeOrders(gormDB *gorm.DB, batch []seed.Order) error {
+	var (
+		orders   []models.Order
+		items    []models.OrderItem
+		payments []models.Payment
+	)
+	for _, o := range batch {
+		orderID := uuid.MustParse(o.ID)
+		paidAt := sql.NullTime{Time: o.PaidAt, Valid: true}
+		orders = append(orders, models.Order{
+			ID:              orderID,
+			UserID:          uuid.MustParse(o.UserID),
+			TotalAmount:     o.Course.PriceCents,
+			Currency:        seed.Currency,
+			Status:          models.OrderStatusPaid,
+			PaymentIntentID: o.PaymentIntentID,
+			CustomerEmail:   o.Email,
+			CustomerName:    o.Name,
+			PaidAt:          paidAt,
+			Metadata:        map[string]any{"source": "seed"},
+			CreatedAt:       o.CreatedAt,
+			UpdatedAt:       o.PaidAt,
+		})
+
+		item := models.OrderItem{
+			// The only item of the order shares its ID
+			ID:                orderID,
+			OrderID:           orderID,
+			CourseID:          uuid.MustParse(o.Course.ID),
+			CourseTitle:       o.Course.Title,
+			CourseDescription: o.Course.Description,
+			PriceSnapshot:     o.Course.PriceCents,
+			OriginalPrice:     o.Course.PriceCents,
+			Quantity:          1,
+			ItemType:          models.OrderItemTypeCourse,
+			CreatedAt:         o.CreatedAt,
+			UpdatedAt:         o.CreatedAt,
+		}
+		if o.Course.InstructorID != "" {
+			item.InstructorID = uuid.MustParse(o.Course.InstructorID)
+		}
+		items = append(items, item)
+
+		payments = append(payments, models.Payment{
+			ID:                    uuid.MustParse(o.PaymentID),
+			OrderID:               orderID,
+			StripePaymentIntentID: o.PaymentIntentID,
+			Amount:                o.Course.PriceCents,
+			Currency:              seed.Currency,
+			Status:                models.PaymentStatusSucceeded,
+			PaymentMethodType:     "card",
+			StripeChargeID:        o.ChargeID,
+			ProcessedAt:           paidAt,
+			CreatedAt:             o.CreatedAt,
+			UpdatedAt:             o.PaidAt,
+		})
+	}
+
+	return gormDB.Transaction(func(tx *gorm.DB) error {
+		tx = tx.Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Session(&gorm.Session{})
+		for _, rows := range []any{&orders, &items, &payments} {
+			if err := tx.CreateInBatches(rows, batchSize).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
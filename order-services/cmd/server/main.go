@@ -3,13 +3,12 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
+	"order-services/internal/cache"
 	"order-services/internal/config"
 	"order-services/internal/controllers"
 	"order-services/internal/db"
@@ -17,21 +16,93 @@ import (
 	"order-services/internal/router"
 	"order-services/internal/services"
 
+	"github.com/ductan2/microservice-app/shared/consumer"
+	"github.com/ductan2/microservice-app/shared/envconfig"
+	"github.com/ductan2/microservice-app/shared/flags"
+	"github.com/ductan2/microservice-app/shared/flags/redisstore"
+	"github.com/ductan2/microservice-app/shared/health"
+	"github.com/ductan2/microservice-app/shared/inbox"
+	"github.com/ductan2/microservice-app/shared/inbox/gormstore"
+	"github.com/ductan2/microservice-app/shared/internalauth"
+	"github.com/ductan2/microservice-app/shared/lifecycle"
+	"github.com/ductan2/microservice-app/shared/logging"
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/ductan2/microservice-app/shared/ratelimit"
+	ratelimitredis "github.com/ductan2/microservice-app/shared/ratelimit/redisstore"
+	"github.com/ductan2/microservice-app/shared/retention"
+	"github.com/ductan2/microservice-app/shared/scheduler"
+	schedulerredis "github.com/ductan2/microservice-app/shared/scheduler/redisstore"
+	"github.com/ductan2/microservice-app/shared/secrets"
+	"github.com/ductan2/microservice-app/shared/telemetry"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
+	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+// drainDelay is how long readiness fails before the server stops accepting connections,
+// so the load balancer stops routing requests here first.
+const drainDelay = 2 * time.Second
+
+// inboxCleanupInterval is how often the IDs of erasure events older than the retention
+// are deleted.
+const inboxCleanupInterval = time.Hour
+
+// rabbitMQRetryDelay is the wait before the erasure consumer reconnects to RabbitMQ.
+const rabbitMQRetryDelay = 10 * time.Second
+
 func main() {
+	// Settings such as JWT_SECRET=secret://order-services/jwt are read from the secrets
+	// manager named by SECRETS_PROVIDER
+	secretStore, secretsErr := secrets.FromEnv()
+	if secretStore != nil {
+		envconfig.UseSecrets(secretStore)
+	}
+	// "order-services config validate" checks the configuration of a deployment and exits
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(envconfig.Command(os.Args[2:], os.Stdout, func() (any, error) {
+			cfg, err := config.Read()
+			if err != nil {
+				return cfg, err
+			}
+			return cfg, cfg.ValidateConfig()
+		}))
+	}
 	// Load environment variables
-	if err := godotenv.Load(); err != nil {
-		log.Println("Warning: Could not load .env file")
+	configs, err := config.Load()
+	logging.Setup(logging.ConfigFromEnv("order-services"))
+	if secretsErr != nil {
+		logging.Fatal("invalid secrets configuration", "error", secretsErr)
+	}
+	if err != nil {
+		logging.Fatal("invalid configuration", "error", err)
 	}
+	// Components are stopped in the reverse order they are added, on SIGINT or SIGTERM
+	app := lifecycle.New(lifecycle.Config{})
 
-	cfg := config.GetConfig()
+	// SIGHUP reloads the configuration; the log settings follow it
+	configs.OnReload(func(_, _ *config.Config) {
+		logging.Setup(logging.ConfigFromEnv("order-services"))
+	})
+	app.Add(lifecycle.Worker("config-reload", configs.WatchSignals))
+	if secretStore != nil {
+		// A rotated secret is picked up by reloading the configuration
+		secretStore.OnRotate(func(string) {
+			if err := configs.Reload(); err != nil {
+				slog.Error("failed to reload configuration after a secret rotation", "error", err)
+			}
+		})
+		app.Add(lifecycle.Worker("secrets", secretStore.Run))
+	}
+
+	cfg := configs.Current()
+	slog.Info("configuration loaded", "config", envconfig.Values(cfg))
+
+	// Export traces to the OpenTelemetry collector
+	tracer := telemetry.Setup(telemetry.ConfigFromEnv("order-services"))
+	app.Add(lifecycle.Component{Name: "tracer", Stop: tracer.Shutdown})
+	metrics.SetBuildInfo("order-services")
 
 	// Initialize dependencies
-	engine, cleanup := buildServer(cfg)
-	defer cleanup()
+	engine, checker := buildServer(cfg, app)
 
 	// Set Gin mode
 	if os.Getenv("GIN_MODE") == "release" {
@@ -47,78 +118,229 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
-	// Start server in a goroutine
-	go func() {
-		log.Printf("Order Services server starting on port %s", cfg.Port)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
-		}
-	}()
-
-	// Wait for interrupt signal to gracefully shutdown the server
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-
-	log.Println("Shutting down server...")
-
 	// Give outstanding requests 30 seconds to complete
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	app.Add(lifecycle.HTTPServer("http", server).WithTimeout(30 * time.Second))
+	app.Add(lifecycle.Drain(checker.Shutdown, drainDelay))
 
-	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+	slog.Info("order services server starting", "port", cfg.Port)
+	if err := app.Run(context.Background()); err != nil {
+		logging.Fatal("server stopped with errors", "error", err)
 	}
-
-	log.Println("Server exited")
 }
 
-func buildServer(cfg *config.Config) (*gin.Engine, func()) {
+// buildServer wires the router and the components it depends on, which app stops once
+// the server drained.
+func buildServer(cfg *config.Config, app *lifecycle.Manager) (*gin.Engine, *health.Checker) {
 	gormDB, err := db.ConnectPostgres()
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		logging.Fatal("failed to connect to database", "error", err)
 	}
 
-	if err := db.RunMigrations(gormDB, ""); err != nil {
-		log.Fatalf("Failed to run database migrations: %v", err)
+	if err := db.RunMigrations(context.Background(), gormDB, cfg.MigrateOnStart); err != nil {
+		logging.Fatal("failed to run database migrations", "error", err)
 	}
 
 	sqlDB, err := gormDB.DB()
 	if err != nil {
-		log.Fatalf("Failed to get sql.DB: %v", err)
+		logging.Fatal("failed to get sql.DB", "error", err)
+	}
+	metrics.RegisterPool("postgres", metrics.SQLPool(sqlDB))
+	app.Add(lifecycle.Closer("postgres", sqlDB))
+
+	// Route the listings, stats and exports to the read replicas, if any
+	replicas, err := db.ConnectReplicas(gormDB)
+	if err != nil {
+		logging.Fatal("failed to connect to the read replicas", "error", err)
+	}
+	if replicas != nil {
+		app.Add(lifecycle.Worker("read-replicas", replicas.Run))
+		app.Add(lifecycle.Closer("postgres-replicas", replicas))
+	}
+
+	// Calls between services carry service tokens signed with the shared keys
+	internalAuth, err := internalauth.ConfigFromEnv("order-services")
+	if err != nil {
+		logging.Fatal("invalid internal auth configuration", "error", err)
+	}
+	if internalAuth.Development {
+		slog.Warn("INTERNAL_AUTH_KEYS is not set; service tokens are signed with the development key")
+	}
+	signer, err := internalauth.NewSigner(internalAuth)
+	if err != nil {
+		logging.Fatal("failed to create service token signer", "error", err)
+	}
+	verifier, err := internalauth.NewVerifier(internalAuth)
+	if err != nil {
+		logging.Fatal("failed to create service token verifier", "error", err)
 	}
 
+	// Feature flags are read from the Redis shared with the BFF, which edits them. Without
+	// Redis every flag is off and the service runs the features it ships by default
+	var flagSource flags.Source
+	var rateLimiter *ratelimit.Limiter
+	var jobStore scheduler.Store = scheduler.NewMemoryStore()
+	redisClient, err := cache.NewRedisClient(context.Background())
+	if err != nil {
+		slog.Warn("redis unavailable; feature flags and rate limits are off, scheduled jobs run on every replica", "error", err)
+	} else {
+		app.Add(lifecycle.Closer("redis", redisClient))
+		flagSource = redisstore.New(redisClient)
+		// Payment requests pass while Redis is unreachable, Stripe still guards the charges
+		rateLimiter = ratelimit.New(ratelimitredis.New(redisClient), ratelimit.Config{
+			Overrides: cfg.RateLimits,
+			FailOpen:  true,
+		})
+		// One replica runs each occurrence of a scheduled job
+		jobStore = schedulerredis.New(redisClient)
+	}
+	flagClient := flags.New(flagSource, flags.Config{})
+
 	// Repositories
 	orderRepo := repositories.NewOrderRepository(gormDB)
 	orderItemRepo := repositories.NewOrderItemRepository(gormDB)
 	couponRepo := repositories.NewCouponRepository(gormDB)
 	paymentRepo := repositories.NewPaymentRepository(gormDB)
-	outboxRepo := repositories.NewOutboxRepository(sqlDB)
+	outboxRepo := repositories.NewOutboxRepository(gormDB)
 	webhookRepo := repositories.NewWebhookEventRepository(sqlDB)
-	courseRepo := repositories.NewCourseRepository(cfg.CourseServiceURL)
+	courseRepo := repositories.NewCourseRepository(cfg.CourseServiceURL, signer)
+	invoiceRepo := repositories.NewInvoiceRepository(gormDB)
+	sagaRepo := repositories.NewSagaRepository(gormDB)
+	refundRepo := repositories.NewRefundRepository(sqlDB)
 
 	// Services
-	orderService := services.NewOrderService(orderRepo, orderItemRepo, couponRepo, courseRepo, outboxRepo, cfg)
+	orderService := services.NewOrderService(orderRepo, orderItemRepo, couponRepo, courseRepo, outboxRepo, flagClient, cfg)
 	couponService := services.NewCouponService(couponRepo, orderRepo)
-	paymentService := services.NewPaymentService(orderRepo, paymentRepo, outboxRepo, webhookRepo, cfg)
+	enrollmentService := services.NewEnrollmentService(cfg, signer)
+	notificationService := services.NewNotificationService(cfg, signer)
+	sagaService := services.NewSagaService(sagaRepo, cfg,
+		services.NewPurchaseSaga(orderRepo, invoiceRepo, enrollmentService, notificationService),
+	)
+	paymentService := services.NewPaymentService(orderRepo, paymentRepo, outboxRepo, webhookRepo, sagaService, cfg)
+	outboxService := services.NewOutboxService(outboxRepo, cfg)
+	refundService := services.NewRefundService(orderRepo, paymentRepo, refundRepo, outboxRepo, notificationService, cfg)
+
+	// Publish outbox events in the background, unless the standalone outbox-relay does
+	if cfg.OutboxRelayEnabled {
+		if err := outboxService.StartEventPublisher(context.Background()); err != nil {
+			logging.Fatal("failed to start outbox publisher", "error", err)
+		}
+		app.Add(lifecycle.Hook("outbox-publisher", outboxService.StopEventPublisher))
+	} else {
+		slog.Info("outbox relay disabled; events are published by outbox-relay")
+	}
+	metrics.Default.OnScrape(func(ctx context.Context) {
+		stats, err := outboxService.GetEventStats(ctx)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to read outbox stats", "error", err)
+			return
+		}
+		metrics.QueueDepth.WithLabelValues("outbox").Set(float64(stats.PendingEvents))
+	})
+
+	// Run purchase sagas in the background; a step in flight is retried after restart
+	sagaService.StartOrchestrator(context.Background())
+	app.Add(lifecycle.Hook("saga-orchestrator", sagaService.StopOrchestrator))
+
+	// Periodic jobs, see shared/scheduler
+	jobs := scheduler.New(jobStore, scheduler.Config{Prefix: "scheduler:order-services"})
+	if err := jobs.Register(scheduler.Job{
+		Name:     "orders.expire",
+		Schedule: cfg.ExpiredOrdersSchedule,
+		Run:      orderService.ProcessExpiredOrders,
+		Timeout:  time.Minute,
+	}); err != nil {
+		logging.Fatal("failed to register scheduled job", "error", err)
+	}
+	purger, err := retention.New(sqlDB, retention.Config{DryRun: cfg.RetentionDryRun, Overrides: cfg.RetentionOverrides}, repositories.RetentionRules()...)
+	if err != nil {
+		logging.Fatal("invalid retention rules", "error", err)
+	}
+	if err := jobs.Register(scheduler.Job{
+		Name:     "retention.purge",
+		Schedule: cfg.RetentionSchedule,
+		Run:      purger.Run,
+		Timeout:  30 * time.Minute,
+	}); err != nil {
+		logging.Fatal("failed to register scheduled job", "error", err)
+	}
+	app.Add(lifecycle.Worker("scheduler", jobs.Run))
+
+	// Erasures of user-services are consumed from its exchange; while RabbitMQ is down
+	// they wait in their queue and the consumer reconnects
+	erasureService := services.NewErasureService(repositories.NewErasureRepository(gormDB), cfg, signer)
+	inboxStore := gormstore.New(gormDB, "")
+	dedup := inbox.New(inboxStore, inbox.Config{Consumer: services.ErasureConsumer})
+	erasures := dedup.Handler(erasureService.HandleErasureRequested)
+	app.Add(lifecycle.Worker("user-erasures", func(ctx context.Context) {
+		for {
+			if err := consumeErasures(ctx, cfg, erasures); err != nil && ctx.Err() == nil {
+				slog.WarnContext(ctx, "erasure consumer stopped", "error", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(rabbitMQRetryDelay):
+			}
+		}
+	}))
+	app.Add(lifecycle.Worker("inbox-cleanup", func(ctx context.Context) {
+		ticker := time.NewTicker(inboxCleanupInterval)
+		defer ticker.Stop()
+		for {
+			if err := inboxStore.DeleteProcessedBefore(ctx, time.Now().Add(-cfg.InboxRetention)); err != nil && ctx.Err() == nil {
+				slog.WarnContext(ctx, "failed to clean up the inbox", "error", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}))
+
+	// Orders are written to PostgreSQL; events wait in the outbox while RabbitMQ is down
+	checker := health.New(health.Config{Service: "order-services"})
+	checker.Register("postgres", health.Critical, sqlDB.PingContext)
+	if cfg.OutboxRelayEnabled {
+		checker.Register("rabbitmq", health.Optional, outboxService.CheckPublisher)
+	}
 
 	// Controllers
 	orderController := controllers.NewOrderController(orderService)
 	couponController := controllers.NewCouponController(couponService)
 	paymentController := controllers.NewPaymentController(paymentService, cfg)
+	sagaController := controllers.NewSagaController(sagaService)
+	refundController := controllers.NewRefundController(refundService)
 
 	engine := router.NewRouter(router.Dependencies{
 		OrderController:   orderController,
 		PaymentController: paymentController,
 		CouponController:  couponController,
+		SagaController:    sagaController,
+		RefundController:  refundController,
 		JWTSecret:         cfg.JWTSecret,
+		ServiceVerifier:   verifier,
+		Health:            checker,
+		RateLimiter:       rateLimiter,
+		TrustedProxies:    cfg.TrustedProxies,
 	})
 
-	cleanup := func() {
-		if err := sqlDB.Close(); err != nil {
-			log.Printf("Error closing database: %v", err)
-		}
+	return engine, checker
+}
+
+// consumeErasures handles the user.erasure_requested events until ctx is cancelled or
+// the connection to RabbitMQ closes.
+func consumeErasures(ctx context.Context, cfg *config.Config, handler consumer.Handler) error {
+	conn, err := amqp.Dial(cfg.RabbitMQURL())
+	if err != nil {
+		return fmt.Errorf("failed to connect to RabbitMQ: %w", err)
 	}
+	defer conn.Close()
 
-	return engine, cleanup
+	c := consumer.New(conn, consumer.Config{
+		Exchange: cfg.UserEventsExchange,
+		Queue:    cfg.ErasureQueue,
+	})
+	c.Handle("user.erasure_requested", handler)
+	return c.Run(ctx)
 }
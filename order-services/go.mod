@@ -3,50 +3,160 @@ module order-services
 go 1.24.6
 
 require (
-	github.com/gin-gonic/gin v1.9.1
+	github.com/ductan2/microservice-app/shared/apperr v0.0.0
+	github.com/ductan2/microservice-app/shared/consumer v0.0.0
+	github.com/ductan2/microservice-app/shared/dbreplica v0.0.0
+	github.com/ductan2/microservice-app/shared/envconfig v0.0.0
+	github.com/ductan2/microservice-app/shared/events v0.0.0
+	github.com/ductan2/microservice-app/shared/flags v0.0.0
+	github.com/ductan2/microservice-app/shared/flags/redisstore v0.0.0
+	github.com/ductan2/microservice-app/shared/health v0.0.0
+	github.com/ductan2/microservice-app/shared/ids v0.0.0
+	github.com/ductan2/microservice-app/shared/inbox v0.0.0
+	github.com/ductan2/microservice-app/shared/internalauth v0.0.0
+	github.com/ductan2/microservice-app/shared/lifecycle v0.0.0
+	github.com/ductan2/microservice-app/shared/logging v0.0.0
+	github.com/ductan2/microservice-app/shared/metrics v0.0.0
+	github.com/ductan2/microservice-app/shared/migrate v0.0.0
+	github.com/ductan2/microservice-app/shared/money v0.0.0
+	github.com/ductan2/microservice-app/shared/outbox v0.0.0
+	github.com/ductan2/microservice-app/shared/queryparams v0.0.0
+	github.com/ductan2/microservice-app/shared/ratelimit v0.0.0
+	github.com/ductan2/microservice-app/shared/ratelimit/redisstore v0.0.0
+	github.com/ductan2/microservice-app/shared/requestmeta v0.0.0
+	github.com/ductan2/microservice-app/shared/retention v0.0.0
+	github.com/ductan2/microservice-app/shared/saga v0.0.0
+	github.com/ductan2/microservice-app/shared/scheduler v0.0.0
+	github.com/ductan2/microservice-app/shared/scheduler/redisstore v0.0.0
+	github.com/ductan2/microservice-app/shared/secrets v0.0.0
+	github.com/ductan2/microservice-app/shared/seed v0.0.0
+	github.com/ductan2/microservice-app/shared/softdelete v0.0.0
+	github.com/ductan2/microservice-app/shared/telemetry v0.0.0
+	github.com/gin-gonic/gin v1.11.0
 	github.com/golang-jwt/jwt/v5 v5.3.0
 	github.com/google/uuid v1.6.0
-	github.com/joho/godotenv v1.5.1
-	github.com/rabbitmq/amqp091-go v1.9.0
-	github.com/redis/go-redis/v9 v9.7.0
+	github.com/rabbitmq/amqp091-go v1.10.0
+	github.com/redis/go-redis/v9 v9.14.0
 	github.com/stripe/stripe-go/v78 v78.0.0
+	go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin v0.64.0
+	google.golang.org/protobuf v1.36.10
 	gorm.io/driver/postgres v1.5.9
-	gorm.io/gorm v1.25.12
+	gorm.io/gorm v1.31.0
 )
 
 require (
-	github.com/bytedance/sonic v1.9.1 // indirect
-	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/aws/aws-sdk-go-v2 v1.41.1 // indirect
+	github.com/aws/aws-sdk-go-v2/config v1.32.7 // indirect
+	github.com/aws/aws-sdk-go-v2/credentials v1.19.7 // indirect
+	github.com/aws/aws-sdk-go-v2/feature/ec2/imds v1.18.17 // indirect
+	github.com/aws/aws-sdk-go-v2/internal/configsources v1.4.17 // indirect
+	github.com/aws/aws-sdk-go-v2/internal/endpoints/v2 v2.7.17 // indirect
+	github.com/aws/aws-sdk-go-v2/internal/ini v1.8.4 // indirect
+	github.com/aws/aws-sdk-go-v2/service/internal/accept-encoding v1.13.4 // indirect
+	github.com/aws/aws-sdk-go-v2/service/internal/presigned-url v1.13.17 // indirect
+	github.com/aws/aws-sdk-go-v2/service/secretsmanager v1.41.1 // indirect
+	github.com/aws/aws-sdk-go-v2/service/signin v1.0.5 // indirect
+	github.com/aws/aws-sdk-go-v2/service/ssm v1.44.7 // indirect
+	github.com/aws/aws-sdk-go-v2/service/sso v1.30.9 // indirect
+	github.com/aws/aws-sdk-go-v2/service/ssooidc v1.35.13 // indirect
+	github.com/aws/aws-sdk-go-v2/service/sts v1.41.6 // indirect
+	github.com/aws/smithy-go v1.24.0 // indirect
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/bytedance/gopkg v0.1.3 // indirect
+	github.com/bytedance/sonic v1.14.2 // indirect
+	github.com/bytedance/sonic/loader v0.4.0 // indirect
+	github.com/cenkalti/backoff/v5 v5.0.3 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
 	github.com/chenzhuoyu/base64x v0.0.0-20221115062448-fe3a3abad311 // indirect
+	github.com/cloudwego/base64x v0.1.6 // indirect
 	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
-	github.com/gabriel-vasile/mimetype v1.4.2 // indirect
-	github.com/gin-contrib/sse v0.1.0 // indirect
+	github.com/felixge/httpsnoop v1.0.4 // indirect
+	github.com/gabriel-vasile/mimetype v1.4.11 // indirect
+	github.com/gin-contrib/sse v1.1.0 // indirect
+	github.com/go-logr/logr v1.4.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
 	github.com/go-playground/locales v0.14.1 // indirect
 	github.com/go-playground/universal-translator v0.18.1 // indirect
-	github.com/go-playground/validator/v10 v10.14.0 // indirect
-	github.com/goccy/go-json v0.10.2 // indirect
+	github.com/go-playground/validator/v10 v10.28.0 // indirect
+	github.com/goccy/go-json v0.10.5 // indirect
+	github.com/goccy/go-yaml v1.19.0 // indirect
+	github.com/grpc-ecosystem/grpc-gateway/v2 v2.27.3 // indirect
 	github.com/jackc/pgpassfile v1.0.0 // indirect
 	github.com/jackc/pgservicefile v0.0.0-20221227161230-091c0ba34f0a // indirect
 	github.com/jackc/pgx/v5 v5.5.5 // indirect
 	github.com/jackc/puddle/v2 v2.2.1 // indirect
 	github.com/jinzhu/inflection v1.0.0 // indirect
 	github.com/jinzhu/now v1.1.5 // indirect
+	github.com/jmespath/go-jmespath v0.4.0 // indirect
+	github.com/joho/godotenv v1.5.1 // indirect
 	github.com/json-iterator/go v1.1.12 // indirect
-	github.com/klauspost/cpuid/v2 v2.2.4 // indirect
-	github.com/leodido/go-urn v1.2.4 // indirect
-	github.com/mattn/go-isatty v0.0.19 // indirect
+	github.com/klauspost/cpuid/v2 v2.3.0 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	github.com/leodido/go-urn v1.4.0 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
 	github.com/modern-go/reflect2 v1.0.2 // indirect
-	github.com/pelletier/go-toml/v2 v2.0.8 // indirect
+	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
+	github.com/pelletier/go-toml/v2 v2.2.4 // indirect
+	github.com/prometheus/client_golang v1.23.2 // indirect
+	github.com/prometheus/client_model v0.6.2 // indirect
+	github.com/prometheus/common v0.66.1 // indirect
+	github.com/prometheus/procfs v0.16.1 // indirect
+	github.com/quic-go/qpack v0.6.0 // indirect
+	github.com/quic-go/quic-go v0.57.1 // indirect
 	github.com/rogpeppe/go-internal v1.14.1 // indirect
 	github.com/twitchyliquid64/golang-asm v0.15.1 // indirect
-	github.com/ugorji/go/codec v1.2.11 // indirect
-	golang.org/x/arch v0.3.0 // indirect
-	golang.org/x/crypto v0.17.0 // indirect
-	golang.org/x/net v0.10.0 // indirect
-	golang.org/x/sync v0.1.0 // indirect
-	golang.org/x/sys v0.26.0 // indirect
-	golang.org/x/text v0.14.0 // indirect
-	google.golang.org/protobuf v1.30.0 // indirect
+	github.com/ugorji/go/codec v1.3.1 // indirect
+	go.opentelemetry.io/auto/sdk v1.2.1 // indirect
+	go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp v0.64.0 // indirect
+	go.opentelemetry.io/otel v1.39.0 // indirect
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace v1.39.0 // indirect
+	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp v1.39.0 // indirect
+	go.opentelemetry.io/otel/metric v1.39.0 // indirect
+	go.opentelemetry.io/otel/sdk v1.39.0 // indirect
+	go.opentelemetry.io/otel/trace v1.39.0 // indirect
+	go.opentelemetry.io/proto/otlp v1.9.0 // indirect
+	go.yaml.in/yaml/v2 v2.4.2 // indirect
+	golang.org/x/arch v0.23.0 // indirect
+	golang.org/x/crypto v0.45.0 // indirect
+	golang.org/x/net v0.47.0 // indirect
+	golang.org/x/sync v0.18.0 // indirect
+	golang.org/x/sys v0.39.0 // indirect
+	golang.org/x/text v0.31.0 // indirect
+	google.golang.org/genproto/googleapis/api v0.0.0-20251202230838-ff82c1b0f217 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20251202230838-ff82c1b0f217 // indirect
+	google.golang.org/grpc v1.77.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace (
+	github.com/ductan2/microservice-app/shared/apperr => ../shared/apperr
+	github.com/ductan2/microservice-app/shared/consumer => ../shared/consumer
+	github.com/ductan2/microservice-app/shared/dbreplica => ../shared/dbreplica
+	github.com/ductan2/microservice-app/shared/envconfig => ../shared/envconfig
+	github.com/ductan2/microservice-app/shared/events => ../shared/events
+	github.com/ductan2/microservice-app/shared/flags => ../shared/flags
+	github.com/ductan2/microservice-app/shared/flags/redisstore => ../shared/flags/redisstore
+	github.com/ductan2/microservice-app/shared/health => ../shared/health
+	github.com/ductan2/microservice-app/shared/ids => ../shared/ids
+	github.com/ductan2/microservice-app/shared/inbox => ../shared/inbox
+	github.com/ductan2/microservice-app/shared/internalauth => ../shared/internalauth
+	github.com/ductan2/microservice-app/shared/lifecycle => ../shared/lifecycle
+	github.com/ductan2/microservice-app/shared/logging => ../shared/logging
+	github.com/ductan2/microservice-app/shared/metrics => ../shared/metrics
+	github.com/ductan2/microservice-app/shared/migrate => ../shared/migrate
+	github.com/ductan2/microservice-app/shared/money => ../shared/money
+	github.com/ductan2/microservice-app/shared/outbox => ../shared/outbox
+	github.com/ductan2/microservice-app/shared/queryparams => ../shared/queryparams
+	github.com/ductan2/microservice-app/shared/ratelimit => ../shared/ratelimit
+	github.com/ductan2/microservice-app/shared/ratelimit/redisstore => ../shared/ratelimit/redisstore
+	github.com/ductan2/microservice-app/shared/requestmeta => ../shared/requestmeta
+	github.com/ductan2/microservice-app/shared/retention => ../shared/retention
+	github.com/ductan2/microservice-app/shared/saga => ../shared/saga
+	github.com/ductan2/microservice-app/shared/scheduler => ../shared/scheduler
+	github.com/ductan2/microservice-app/shared/scheduler/redisstore => ../shared/scheduler/redisstore
+	github.com/ductan2/microservice-app/shared/secrets => ../shared/secrets
+	github.com/ductan2/microservice-app/shared/seed => ../shared/seed
+	github.com/ductan2/microservice-app/shared/softdelete => ../shared/softdelete
+	github.com/ductan2/microservice-app/shared/telemetry => ../shared/telemetry
+)
@@ -1,8 +1,8 @@
 package cache
 
 import (
+	"log/slog"
 	"context"
-	"log"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -25,6 +25,6 @@ func NewRedisClient(ctx context.Context) (*redis.Client, error) {
 		return nil, err
 	}
 
-	log.Println("✅ Connected to Redis")
+	slog.Info("connected to Redis")
 	return client, nil
 }
\ No newline at end of file
@@ -2,114 +2,140 @@ package config
 
 import (
 	"fmt"
-	"os"
-	"strconv"
-
-	"github.com/joho/godotenv"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/envconfig"
+	"github.com/ductan2/microservice-app/shared/ratelimit"
+	"github.com/ductan2/microservice-app/shared/requestmeta"
+	"github.com/ductan2/microservice-app/shared/retention"
+	"github.com/ductan2/microservice-app/shared/scheduler"
 )
 
 // Config holds all application configuration
 type Config struct {
 	// Server
-	Port string
+	Port string `env:"PORT" envDefault:"8006"`
+	// TrustedProxies are the networks whose X-Forwarded-For is trusted; the client IP of
+	// logs and rate limits is read past them
+	TrustedProxies requestmeta.Proxies `env:"TRUSTED_PROXIES" envDefault:"127.0.0.0/8,::1/128,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16,fc00::/7"`
 
 	// External services
-	CourseServiceURL string
+	CourseServiceURL       string `env:"COURSE_SERVICE_URL" envDefault:"http://localhost:8010"`
+	NotificationServiceURL string `env:"NOTIFICATION_SERVICE_URL" envDefault:"http://notification-services:8003"`
+	UserServiceURL         string `env:"USER_SERVICE_URL" envDefault:"http://user-services:8001"`
+	// NotificationsViaEvents leaves the notifications of the order events (paid, cancelled,
+	// failed and refunds) to notification-services consuming order.events
+	NotificationsViaEvents bool `env:"NOTIFICATIONS_VIA_EVENTS" envDefault:"false"`
 
 	// Database
-	DBHost     string
-	DBPort     string
-	DBUser     string
-	DBPassword string
-	DBName     string
+	DBHost     string `env:"DB_HOST" envDefault:"localhost"`
+	DBPort     string `env:"DB_PORT" envDefault:"5432"`
+	DBUser     string `env:"DB_USER" envDefault:"user"`
+	DBPassword string `env:"DB_PASSWORD,secret" envDefault:"password"`
+	DBName     string `env:"DB_NAME" envDefault:"lms_order_serivecs"`
+	// MigrateOnStart applies pending migrations at startup; when unset, migrations are
+	// left to cmd/migrate and the server only refuses to start on drift
+	MigrateOnStart bool `env:"MIGRATE_ON_START" envDefault:"true"`
+	// DBReplicaURLs are read replicas (postgres:// URLs) serving the order listings,
+	// stats and exports; without any, every query goes to the primary
+	DBReplicaURLs []string `env:"DB_REPLICA_URLS,secret"`
+	// DBReplicaMaxLag takes a replica further behind the primary out of the rotation
+	DBReplicaMaxLag time.Duration `env:"DB_REPLICA_MAX_LAG" envDefault:"30s"`
 
 	// Redis
-	RedisHost     string
-	RedisPort     string
-	RedisPassword string
+	RedisHost     string `env:"REDIS_HOST" envDefault:"localhost"`
+	RedisPort     string `env:"REDIS_PORT" envDefault:"6379"`
+	RedisPassword string `env:"REDIS_PASSWORD,secret" envDefault:"redis_password"`
 
 	// RabbitMQ
-	RabbitMQHost     string
-	RabbitMQPort     string
-	RabbitMQUser     string
-	RabbitMQPassword string
-	RabbitMQVHost    string
+	RabbitMQHost     string `env:"RABBITMQ_HOST" envDefault:"localhost"`
+	RabbitMQPort     string `env:"RABBITMQ_PORT" envDefault:"5672"`
+	RabbitMQUser     string `env:"RABBITMQ_USER" envDefault:"user"`
+	RabbitMQPassword string `env:"RABBITMQ_PASSWORD,secret" envDefault:"password"`
+	RabbitMQVHost    string `env:"RABBITMQ_VHOST" envDefault:"/"`
+
+	// Erasures: user.erasure_requested is consumed from the exchange of user-services
+	// into ErasureQueue; the IDs of the handled events are kept InboxRetention
+	UserEventsExchange string        `env:"RABBITMQ_USER_EVENTS_EXCHANGE" envDefault:"notifications"`
+	ErasureQueue       string        `env:"RABBITMQ_ERASURE_QUEUE" envDefault:"order-services.user-erasures"`
+	InboxRetention     time.Duration `env:"INBOX_RETENTION" envDefault:"168h"`
+
+	// Outbox
+	OutboxBatchSize     int `env:"OUTBOX_BATCH_SIZE" envDefault:"100"`   // events published per poll
+	OutboxMaxAttempts   int `env:"OUTBOX_MAX_ATTEMPTS" envDefault:"10"`  // failed publishes before an event is parked
+	OutboxRetentionDays int `env:"OUTBOX_RETENTION_DAYS" envDefault:"7"` // how long published events are kept
+	// OutboxRelayEnabled publishes the events from the service; false leaves them to outbox-relay
+	OutboxRelayEnabled bool `env:"OUTBOX_RELAY_ENABLED" envDefault:"true"`
+
+	// Sagas
+	SagaMaxAttempts       int `env:"SAGA_MAX_ATTEMPTS" envDefault:"5"`         // failed runs of a step before its saga is parked
+	SagaStuckAfterMinutes int `env:"SAGA_STUCK_AFTER_MINUTES" envDefault:"60"` // running sagas older than this are listed as stuck
+
+	// Rate limits: overrides of the payments.intent and payments.confirm policies, see
+	// shared/ratelimit
+	RateLimits ratelimit.Policies `env:"RATE_LIMITS"`
+
+	// Scheduled jobs, see shared/scheduler: pending orders past their expiry are cancelled
+	ExpiredOrdersSchedule scheduler.Spec `env:"EXPIRED_ORDERS_SCHEDULE" envDefault:"*/5 * * * *"`
+
+	// Data retention, see shared/retention: rows past the age of their rule are purged on
+	// RetentionSchedule, or only counted and logged when RetentionDryRun is set
+	RetentionSchedule  scheduler.Spec      `env:"RETENTION_SCHEDULE" envDefault:"30 3 * * *"`
+	RetentionDryRun    bool                `env:"RETENTION_DRY_RUN" envDefault:"false"`
+	RetentionOverrides retention.Overrides `env:"RETENTION_OVERRIDES"`
 
 	// Stripe
-	StripeSecretKey      string
-	StripeWebhookSecret  string
-	StripePublishableKey string
+	StripeSecretKey      string `env:"STRIPE_SECRET_KEY,secret"`
+	StripeWebhookSecret  string `env:"STRIPE_WEBHOOK_SECRET,secret"`
+	StripePublishableKey string `env:"STRIPE_PUBLISHABLE_KEY"`
 
 	// JWT
-	JWTSecret string
+	JWTSecret string `env:"JWT_SECRET,secret" envDefault:"your-secret-key-change-in-production"`
 
 	// Application
-	AppName          string
-	AppVersion       string
-	LogLevel         string
-	Environment      string
-	OrderExpiresIn   int // order expiration in hours
-	RefundWindowDays int
+	AppName          string `env:"APP_NAME" envDefault:"order-services"`
+	AppVersion       string `env:"APP_VERSION" envDefault:"1.0.0"`
+	LogLevel         string `env:"LOG_LEVEL" envDefault:"info"`
+	Environment      string `env:"ENVIRONMENT" envDefault:"development"`
+	OrderExpiresIn   int    `env:"ORDER_EXPIRES_IN" envDefault:"24"` // order expiration in hours
+	RefundWindowDays int    `env:"REFUND_WINDOW_DAYS" envDefault:"30"`
 }
 
-var cfg *Config
+var configs atomic.Pointer[envconfig.Reloader[Config]]
 
-func init() {
-	// Load .env if present; non-fatal if missing (e.g., in production/containers)
-	if err := godotenv.Load(); err != nil {
-		// It's okay if .env is not present; environment variables can be provided directly
+// Load reads the configuration from the environment and .env, and keeps it for
+// GetConfig. The returned reloader replaces it on reload.
+func Load() (*envconfig.Reloader[Config], error) {
+	reloader, err := envconfig.NewReloader(loadConfig)
+	if err != nil {
+		return nil, err
 	}
-	cfg = loadConfig()
+	configs.Store(reloader)
+	return reloader, nil
+}
+
+// Read reads the configuration from the environment and .env without keeping it, for
+// "order-services config validate".
+func Read() (*Config, error) {
+	return loadConfig()
 }
 
 // loadConfig loads configuration from environment variables
-func loadConfig() *Config {
-	return &Config{
-		// Server
-		Port: getEnv("PORT", "8006"),
-
-		// External services
-		CourseServiceURL: getEnv("COURSE_SERVICE_URL", "http://localhost:8010"),
-
-		// Database
-		DBHost:     getEnv("DB_HOST", "localhost"),
-		DBPort:     getEnv("DB_PORT", "5432"),
-		DBUser:     getEnv("DB_USER", "user"),
-		DBPassword: getEnv("DB_PASSWORD", "password"),
-		DBName:     getEnv("DB_NAME", "lms_order_serivecs"),
-
-		// Redis
-		RedisHost:     getEnv("REDIS_HOST", "localhost"),
-		RedisPort:     getEnv("REDIS_PORT", "6379"),
-		RedisPassword: getEnv("REDIS_PASSWORD", "redis_password"),
-
-		// RabbitMQ
-		RabbitMQHost:     getEnv("RABBITMQ_HOST", "localhost"),
-		RabbitMQPort:     getEnv("RABBITMQ_PORT", "5672"),
-		RabbitMQUser:     getEnv("RABBITMQ_USER", "user"),
-		RabbitMQPassword: getEnv("RABBITMQ_PASSWORD", "password"),
-		RabbitMQVHost:    getEnv("RABBITMQ_VHOST", "/"),
-
-		// Stripe
-		StripeSecretKey:      getEnv("STRIPE_SECRET_KEY", ""),
-		StripeWebhookSecret:  getEnv("STRIPE_WEBHOOK_SECRET", ""),
-		StripePublishableKey: getEnv("STRIPE_PUBLISHABLE_KEY", ""),
-
-		// JWT
-		JWTSecret: getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
-
-		// Application
-		AppName:          getEnv("APP_NAME", "order-services"),
-		AppVersion:       getEnv("APP_VERSION", "1.0.0"),
-		LogLevel:         getEnv("LOG_LEVEL", "info"),
-		Environment:      getEnv("ENVIRONMENT", "development"),
-		OrderExpiresIn:   getEnvInt("ORDER_EXPIRES_IN", 24), // 24 hours default
-		RefundWindowDays: getEnvInt("REFUND_WINDOW_DAYS", 30),
-	}
+func loadConfig() (*Config, error) {
+	cfg := &Config{}
+	err := envconfig.Load(cfg)
+	return cfg, err
 }
 
-// GetConfig returns the application configuration
+// GetConfig returns the application configuration. Before Load it reads the
+// environment and ignores invalid values.
 func GetConfig() *Config {
+	if reloader := configs.Load(); reloader != nil {
+		return reloader.Current()
+	}
+	cfg, _ := loadConfig()
 	return cfg
 }
 
@@ -134,6 +160,22 @@ func (c *Config) RabbitMQURL() string {
 		c.RabbitMQUser, c.RabbitMQPassword, c.RabbitMQHost, c.RabbitMQPort, c.RabbitMQVHost)
 }
 
+// Endpoints lists the dependencies the service connects to, dialed by
+// "order-services config validate -connect".
+func (c *Config) Endpoints() []envconfig.Endpoint {
+	endpoints := []envconfig.Endpoint{
+		{Name: "postgres", Address: net.JoinHostPort(c.DBHost, c.DBPort)},
+		{Name: "redis", Address: c.RedisAddr()},
+		{Name: "rabbitmq", Address: c.RabbitMQURL()},
+		{Name: "course-service", Address: c.CourseServiceURL},
+		{Name: "notification-service", Address: c.NotificationServiceURL},
+	}
+	for i, replica := range c.DBReplicaURLs {
+		endpoints = append(endpoints, envconfig.Endpoint{Name: fmt.Sprintf("postgres-replica-%d", i+1), Address: replica})
+	}
+	return endpoints
+}
+
 // IsDevelopment returns true if running in development mode
 func (c *Config) IsDevelopment() bool {
 	return c.Environment == "development"
@@ -159,29 +201,3 @@ func (c *Config) ValidateConfig() error {
 	}
 	return nil
 }
-
-// Helper functions
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
-func getEnvInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue
-		}
-	}
-	return defaultValue
-}
-
-func getEnvBool(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
-		if boolValue, err := strconv.ParseBool(value); err == nil {
-			return boolValue
-		}
-	}
-	return defaultValue
-}
@@ -3,6 +3,7 @@ package controllers
 import (
 	"net/http"
 
+	"github.com/ductan2/microservice-app/shared/queryparams"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
@@ -98,8 +99,8 @@ func (c *CouponController) ValidateCoupon(ctx *gin.Context) {
 // @Router /api/v1/coupons [get]
 func (c *CouponController) ListAvailableCoupons(ctx *gin.Context) {
 	// Parse pagination parameters
-	var pagination dto.PaginationParams
-	if err := ctx.ShouldBindQuery(&pagination); err != nil {
+	page, err := queryparams.ParsePage(ctx.Request.URL.Query(), listPageOptions)
+	if err != nil {
 		utils.ValidationError(ctx, err)
 		return
 	}
@@ -146,17 +147,7 @@ func (c *CouponController) ListAvailableCoupons(ctx *gin.Context) {
 	}
 
 	// Apply pagination
-	limit := pagination.Limit
-	if limit <= 0 {
-		limit = 20
-	} else if limit > 100 {
-		limit = 100
-	}
-
-	offset := pagination.Offset
-	if pagination.Page > 0 {
-		offset = (pagination.Page - 1) * limit
-	}
+	limit, offset := page.Limit, page.Offset
 
 	// Simple pagination for now (in production, you'd implement this in the service)
 	total := int64(len(couponResponses))
@@ -323,9 +314,9 @@ func (c *CouponController) UpdateCoupon(ctx *gin.Context) {
 	utils.ErrorResponse(ctx, http.StatusNotImplemented, dto.ErrCodeInternalError, "UpdateCoupon not implemented")
 }
 
-// DeleteCoupon deletes a coupon (admin only)
+// DeleteCoupon soft-deletes a coupon (admin only)
 // @Summary Delete coupon
-// @Description Deletes a coupon (admin only)
+// @Description Soft-deletes a coupon, which can no longer be redeemed and frees its code; deleting it again succeeds (admin only)
 // @Tags coupons
 // @Produce json
 // @Param id path string true "Coupon ID"
@@ -345,15 +336,71 @@ func (c *CouponController) DeleteCoupon(ctx *gin.Context) {
 	}
 
 	// Parse coupon ID
-	_, err := uuid.Parse(ctx.Param("id"))
+	couponID, err := uuid.Parse(ctx.Param("id"))
 	if err != nil {
 		utils.ErrorResponse(ctx, http.StatusBadRequest, dto.ErrCodeBadRequest, "Invalid coupon ID")
 		return
 	}
 
-	// This would need to be implemented in CouponService
-	// For now, return not implemented
-	utils.ErrorResponse(ctx, http.StatusNotImplemented, dto.ErrCodeInternalError, "DeleteCoupon not implemented")
+	adminID, _ := ctx.Get("user_id")
+	deletedBy, _ := adminID.(string)
+	if err := c.couponService.DeleteCoupon(ctx, couponID, deletedBy); err != nil {
+		if utils.IsNotFoundError(err) {
+			utils.ErrorResponse(ctx, http.StatusNotFound, dto.ErrCodeCouponNotFound, "Coupon not found")
+		} else {
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, dto.ErrCodeInternalError, "Failed to delete coupon")
+		}
+		return
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, gin.H{"message": "Coupon deleted"})
+}
+
+// RestoreCoupon restores a soft-deleted coupon (admin only)
+// @Summary Restore coupon
+// @Description Restores a deleted coupon; restoring a coupon that is not deleted succeeds. Fails with 409 when another coupon took its code (admin only)
+// @Tags coupons
+// @Produce json
+// @Param id path string true "Coupon ID"
+// @Param Authorization header string true "Bearer JWT token"
+// @Success 200 {object} dto.APIResponse{data=dto.CouponResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 401 {object} dto.APIResponse
+// @Failure 403 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 409 {object} dto.APIResponse
+// @Failure 500 {object} dto.APIResponse
+// @Router /api/v1/admin/coupons/{id}/restore [post]
+func (c *CouponController) RestoreCoupon(ctx *gin.Context) {
+	// Check if user is admin
+	if !utils.IsAdmin(ctx) {
+		utils.ErrorResponse(ctx, http.StatusForbidden, dto.ErrCodeForbidden, "Admin access required")
+		return
+	}
+
+	// Parse coupon ID
+	couponID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, dto.ErrCodeBadRequest, "Invalid coupon ID")
+		return
+	}
+
+	coupon, err := c.couponService.RestoreCoupon(ctx, couponID)
+	if err != nil {
+		switch {
+		case utils.IsNotFoundError(err):
+			utils.ErrorResponse(ctx, http.StatusNotFound, dto.ErrCodeCouponNotFound, "Coupon not found")
+		case utils.IsConflictError(err):
+			utils.ErrorResponse(ctx, http.StatusConflict, dto.ErrCodeCouponCodeTaken, err.Error())
+		default:
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, dto.ErrCodeInternalError, "Failed to restore coupon")
+		}
+		return
+	}
+
+	var response dto.CouponResponse
+	response.FromModel(coupon)
+	utils.SuccessResponse(ctx, http.StatusOK, response)
 }
 
 // GetUserCouponUsage retrieves a user's coupon usage history
@@ -2,8 +2,8 @@ package controllers
 
 import (
 	"net/http"
-	"time"
 
+	"github.com/ductan2/microservice-app/shared/queryparams"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
@@ -12,6 +12,15 @@ import (
 	"order-services/pkg/utils"
 )
 
+// listPageOptions bounds the pagination of the list endpoints
+var listPageOptions = queryparams.PageOptions{DefaultLimit: 20, MaxLimit: 100}
+
+// orderSortOptions are the sorts of the order list, newest first by default
+var orderSortOptions = queryparams.SortOptions{
+	Fields:  []string{"created_at", "total_amount", "status"},
+	Default: queryparams.Sort{Field: "created_at", Desc: true},
+}
+
 // OrderController handles order-related HTTP requests
 type OrderController struct {
 	orderService services.OrderService
@@ -160,7 +169,8 @@ func (c *OrderController) GetOrder(ctx *gin.Context) {
 // @Param offset query int false "Number of items to skip (default: 0)"
 // @Param page query int false "Page number (alternative to offset)"
 // @Param status query string false "Filter by status"
-// @Param sort_by query string false "Sort field (default: created_at)"
+// @Param sort query string false "Sort field prefixed with - for descending order, e.g. -total_amount (alternative to sort_by and sort_order)"
+// @Param sort_by query string false "Sort field: created_at, total_amount or status (default: created_at)"
 // @Param sort_order query string false "Sort order (asc or desc, default: desc)"
 // @Param Authorization header string true "Bearer JWT token"
 // @Success 200 {object} dto.APIResponse{data=dto.OrderListResponse}
@@ -170,23 +180,17 @@ func (c *OrderController) GetOrder(ctx *gin.Context) {
 // @Router /api/v1/orders [get]
 func (c *OrderController) ListOrders(ctx *gin.Context) {
 	// Parse pagination parameters
-	var pagination dto.PaginationParams
-	if err := ctx.ShouldBindQuery(&pagination); err != nil {
+	page, err := queryparams.ParsePage(ctx.Request.URL.Query(), listPageOptions)
+	if err != nil {
 		utils.ValidationError(ctx, err)
 		return
 	}
+	limit, offset := page.Limit, page.Offset
 
-	// Set defaults
-	limit := pagination.Limit
-	if limit <= 0 {
-		limit = 20
-	} else if limit > 100 {
-		limit = 100
-	}
-
-	offset := pagination.Offset
-	if pagination.Page > 0 {
-		offset = (pagination.Page - 1) * limit
+	sort, err := queryparams.ParseSort(ctx.Request.URL.Query(), orderSortOptions)
+	if err != nil {
+		utils.ValidationError(ctx, err)
+		return
 	}
 
 	// Get user ID from JWT token
@@ -203,7 +207,7 @@ func (c *OrderController) ListOrders(ctx *gin.Context) {
 	}
 
 	// Get orders
-	orders, total, err := c.orderService.ListUserOrders(ctx, userUUID, limit, offset)
+	orders, total, err := c.orderService.ListUserOrders(ctx, userUUID, sort, limit, offset)
 	if err != nil {
 		utils.ErrorResponse(ctx, http.StatusInternalServerError, dto.ErrCodeInternalError, "Failed to retrieve orders")
 		return
@@ -335,6 +339,8 @@ func (c *OrderController) UpdateOrder(ctx *gin.Context) {
 		if err != nil {
 			if utils.IsNotFoundError(err) {
 				utils.ErrorResponse(ctx, http.StatusNotFound, dto.ErrCodeOrderNotFound, "Order not found")
+			} else if utils.IsConflictError(err) {
+				utils.ErrorResponse(ctx, http.StatusConflict, dto.ErrCodeInvalidOrderStatus, err.Error())
 			} else if utils.IsValidationError(err) {
 				utils.ErrorResponse(ctx, http.StatusBadRequest, dto.ErrCodeInvalidOrderStatus, err.Error())
 			} else {
@@ -449,24 +455,12 @@ func (c *OrderController) ListAllOrders(ctx *gin.Context) {
 	}
 
 	// Parse pagination parameters
-	var pagination dto.PaginationParams
-	if err := ctx.ShouldBindQuery(&pagination); err != nil {
+	page, err := queryparams.ParsePage(ctx.Request.URL.Query(), listPageOptions)
+	if err != nil {
 		utils.ValidationError(ctx, err)
 		return
 	}
-
-	// Set defaults
-	limit := pagination.Limit
-	if limit <= 0 {
-		limit = 20
-	} else if limit > 100 {
-		limit = 100
-	}
-
-	offset := pagination.Offset
-	if pagination.Page > 0 {
-		offset = (pagination.Page - 1) * limit
-	}
+	limit, offset := page.Limit, page.Offset
 
 	// Parse user ID filter
 	var userID *uuid.UUID
@@ -494,22 +488,3 @@ func (c *OrderController) ListAllOrders(ctx *gin.Context) {
 	meta := dto.CalculatePagination(int(response.Total), limit, offset)
 	utils.SuccessResponseWithMeta(ctx, http.StatusOK, response, meta)
 }
-
-// Health check for order service
-func (c *OrderController) Health(ctx *gin.Context) {
-	health := dto.HealthResponse{
-		Status:    "healthy",
-		Timestamp: time.Now(),
-		Version:   "1.0.0",
-		Services: map[string]string{
-			"order_service": "healthy",
-		},
-		Checks: map[string]bool{
-			"database": false, // Would check database connectivity
-			"redis":    false, // Would check Redis connectivity
-			"rabbitmq": false, // Would check RabbitMQ connectivity
-		},
-	}
-
-	utils.SuccessResponse(ctx, http.StatusOK, health)
-}
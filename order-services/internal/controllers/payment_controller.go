@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/ductan2/microservice-app/shared/queryparams"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/stripe/stripe-go/v78/paymentintent"
@@ -378,24 +379,12 @@ func (c *PaymentController) GetPaymentMethods(ctx *gin.Context) {
 // @Router /api/v1/payments [get]
 func (c *PaymentController) GetPaymentHistory(ctx *gin.Context) {
 	// Parse pagination parameters
-	var pagination dto.PaginationParams
-	if err := ctx.ShouldBindQuery(&pagination); err != nil {
+	page, err := queryparams.ParsePage(ctx.Request.URL.Query(), listPageOptions)
+	if err != nil {
 		utils.ValidationError(ctx, err)
 		return
 	}
-
-	// Set defaults
-	limit := pagination.Limit
-	if limit <= 0 {
-		limit = 20
-	} else if limit > 100 {
-		limit = 100
-	}
-
-	offset := pagination.Offset
-	if pagination.Page > 0 {
-		offset = (pagination.Page - 1) * limit
-	}
+	limit, offset := page.Limit, page.Offset
 
 	// Get user ID from JWT token
 	_, exists := ctx.Get("user_id")
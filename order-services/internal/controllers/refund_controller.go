@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/ductan2/microservice-app/shared/apperr"
+	"github.com/ductan2/microservice-app/shared/queryparams"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"order-services/internal/dto"
+	"order-services/internal/services"
+	"order-services/pkg/utils"
+)
+
+// RefundController handles the refund requests of customers
+type RefundController struct {
+	refundService services.RefundService
+}
+
+// NewRefundController creates a new refund controller instance
+func NewRefundController(refundService services.RefundService) *RefundController {
+	return &RefundController{
+		refundService: refundService,
+	}
+}
+
+// RequestRefund asks for a refund of a paid order
+// @Summary Request a refund
+// @Description Creates a refund request for a paid order of the authenticated user, to be reviewed by an admin
+// @Tags refunds
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param request body dto.RefundOrderRequest true "Refund request"
+// @Param Authorization header string true "Bearer JWT token"
+// @Success 201 {object} dto.APIResponse{data=dto.RefundRequestResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 401 {object} dto.APIResponse
+// @Failure 403 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 409 {object} dto.APIResponse
+// @Failure 500 {object} dto.APIResponse
+// @Router /api/v1/orders/{id}/refund [post]
+func (c *RefundController) RequestRefund(ctx *gin.Context) {
+	// Parse order ID
+	orderID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, dto.ErrCodeBadRequest, "Invalid order ID")
+		return
+	}
+
+	var req dto.RefundOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.ValidationError(ctx, err)
+		return
+	}
+
+	// Get user ID from JWT token
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, dto.ErrCodeUnauthorized, "User not authenticated")
+		return
+	}
+
+	userUUID, err := uuid.Parse(userID.(string))
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, dto.ErrCodeBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Create refund request
+	refund, err := c.refundService.CreateRefundRequest(ctx, orderID, userUUID, req.Reason, req.Amount)
+	if err != nil {
+		if utils.IsNotFoundError(err) {
+			utils.ErrorResponse(ctx, http.StatusNotFound, dto.ErrCodeOrderNotFound, "Order not found")
+		} else if utils.IsUnauthorizedError(err) {
+			utils.ErrorResponse(ctx, http.StatusForbidden, dto.ErrCodeForbidden, "Access denied")
+		} else if utils.IsValidationError(err) {
+			utils.ErrorResponse(ctx, http.StatusBadRequest, apperr.From(err).ErrorCode(), err.Error())
+		} else if utils.IsConflictError(err) {
+			utils.ErrorResponse(ctx, http.StatusConflict, apperr.From(err).ErrorCode(), err.Error())
+		} else {
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, dto.ErrCodeInternalError, "Failed to request refund")
+		}
+		return
+	}
+
+	// Convert to response
+	var response dto.RefundRequestResponse
+	response.FromModel(refund)
+
+	utils.SuccessResponse(ctx, http.StatusCreated, response, "Refund requested successfully")
+}
+
+// ListRefunds retrieves a paginated list of the user's refund requests
+// @Summary List user refund requests
+// @Description Retrieves the refund requests of the authenticated user, newest first
+// @Tags refunds
+// @Produce json
+// @Param limit query int false "Number of items per page (default: 20, max: 100)"
+// @Param offset query int false "Number of items to skip (default: 0)"
+// @Param page query int false "Page number (alternative to offset)"
+// @Param Authorization header string true "Bearer JWT token"
+// @Success 200 {object} dto.APIResponse{data=dto.RefundRequestListResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 401 {object} dto.APIResponse
+// @Failure 500 {object} dto.APIResponse
+// @Router /api/v1/refunds [get]
+func (c *RefundController) ListRefunds(ctx *gin.Context) {
+	// Parse pagination parameters
+	page, err := queryparams.ParsePage(ctx.Request.URL.Query(), listPageOptions)
+	if err != nil {
+		utils.ValidationError(ctx, err)
+		return
+	}
+	limit, offset := page.Limit, page.Offset
+
+	// Get user ID from JWT token
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		utils.ErrorResponse(ctx, http.StatusUnauthorized, dto.ErrCodeUnauthorized, "User not authenticated")
+		return
+	}
+
+	userUUID, err := uuid.Parse(userID.(string))
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, dto.ErrCodeBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Get refund requests
+	refunds, total, err := c.refundService.GetRefundRequests(ctx, userUUID, limit, offset)
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusInternalServerError, dto.ErrCodeInternalError, "Failed to retrieve refunds")
+		return
+	}
+
+	// Convert to response
+	refundResponses := make([]dto.RefundRequestResponse, len(refunds))
+	for i, refund := range refunds {
+		refundResponses[i].FromModel(&refund)
+	}
+
+	// Create paginated response
+	meta := dto.CalculatePagination(int(total), limit, offset)
+	response := dto.RefundRequestListResponse{
+		Refunds: refundResponses,
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+	}
+
+	utils.SuccessResponseWithMeta(ctx, http.StatusOK, response, meta)
+}
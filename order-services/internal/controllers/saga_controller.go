@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ductan2/microservice-app/shared/queryparams"
+	"github.com/ductan2/microservice-app/shared/saga"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"order-services/internal/dto"
+	"order-services/internal/services"
+	"order-services/pkg/utils"
+)
+
+// SagaController handles the admin requests on sagas
+type SagaController struct {
+	sagaService services.SagaService
+}
+
+// NewSagaController creates a new saga controller instance
+func NewSagaController(sagaService services.SagaService) *SagaController {
+	return &SagaController{
+		sagaService: sagaService,
+	}
+}
+
+// ListSagas lists sagas, such as the stuck purchase sagas (admin only)
+// @Summary List sagas
+// @Description Lists sagas newest first; stuck=true lists the failed ones and those running for too long
+// @Tags sagas
+// @Produce json
+// @Param type query string false "Saga type, e.g. purchase"
+// @Param status query string false "Filter by status"
+// @Param order_id query string false "Filter by order ID"
+// @Param stuck query bool false "Only stuck sagas"
+// @Param limit query int false "Number of items per page (default: 20, max: 100)"
+// @Param offset query int false "Number of items to skip (default: 0)"
+// @Param Authorization header string true "Bearer JWT token"
+// @Success 200 {object} dto.APIResponse{data=dto.SagaListResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 401 {object} dto.APIResponse
+// @Failure 403 {object} dto.APIResponse
+// @Failure 500 {object} dto.APIResponse
+// @Router /api/v1/admin/sagas [get]
+func (c *SagaController) ListSagas(ctx *gin.Context) {
+	var query dto.SagaListQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		utils.ValidationError(ctx, err)
+		return
+	}
+	page, err := queryparams.ParsePage(ctx.Request.URL.Query(), listPageOptions)
+	if err != nil {
+		utils.ValidationError(ctx, err)
+		return
+	}
+	query.Limit, query.Offset = page.Limit, page.Offset
+
+	sagas, total, err := c.sagaService.ListSagas(ctx, query)
+	if err != nil {
+		if utils.IsValidationError(err) {
+			utils.ErrorResponse(ctx, http.StatusBadRequest, dto.ErrCodeBadRequest, err.Error())
+		} else {
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, dto.ErrCodeInternalError, "Failed to list sagas")
+		}
+		return
+	}
+
+	response := dto.SagaListResponse{
+		Sagas:  make([]dto.SagaResponse, len(sagas)),
+		Total:  total,
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	}
+	for i, inst := range sagas {
+		response.Sagas[i].FromModel(inst, c.sagaService.IsStuck(inst))
+	}
+
+	utils.SuccessResponse(ctx, http.StatusOK, response)
+}
+
+// GetSaga retrieves a saga with the state of its steps (admin only)
+// @Summary Get saga
+// @Tags sagas
+// @Produce json
+// @Param id path string true "Saga ID"
+// @Param Authorization header string true "Bearer JWT token"
+// @Success 200 {object} dto.APIResponse{data=dto.SagaResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 500 {object} dto.APIResponse
+// @Router /api/v1/admin/sagas/{id} [get]
+func (c *SagaController) GetSaga(ctx *gin.Context) {
+	c.respond(ctx, c.sagaService.GetSaga)
+}
+
+// RetrySaga resumes a stuck saga at the step that failed (admin only)
+// @Summary Retry saga
+// @Description Runs the failed step, or the failed compensation, of a saga again
+// @Tags sagas
+// @Produce json
+// @Param id path string true "Saga ID"
+// @Param Authorization header string true "Bearer JWT token"
+// @Success 200 {object} dto.APIResponse{data=dto.SagaResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 409 {object} dto.APIResponse
+// @Failure 500 {object} dto.APIResponse
+// @Router /api/v1/admin/sagas/{id}/retry [post]
+func (c *SagaController) RetrySaga(ctx *gin.Context) {
+	c.respond(ctx, c.sagaService.RetrySaga)
+}
+
+// CompensateSaga undoes the completed steps of a failed or completed saga (admin only)
+// @Summary Compensate saga
+// @Description Revokes the enrollments and voids the invoice of a purchase saga
+// @Tags sagas
+// @Produce json
+// @Param id path string true "Saga ID"
+// @Param Authorization header string true "Bearer JWT token"
+// @Success 200 {object} dto.APIResponse{data=dto.SagaResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Failure 409 {object} dto.APIResponse
+// @Failure 500 {object} dto.APIResponse
+// @Router /api/v1/admin/sagas/{id}/compensate [post]
+func (c *SagaController) CompensateSaga(ctx *gin.Context) {
+	c.respond(ctx, c.sagaService.CompensateSaga)
+}
+
+// respond runs an action on the saga of the id parameter and returns the saga
+func (c *SagaController) respond(ctx *gin.Context, action func(ctx context.Context, id uuid.UUID) (*saga.Instance, error)) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(ctx, http.StatusBadRequest, dto.ErrCodeBadRequest, "Invalid saga ID")
+		return
+	}
+
+	inst, err := action(ctx, id)
+	if err != nil {
+		if utils.IsNotFoundError(err) {
+			utils.ErrorResponse(ctx, http.StatusNotFound, dto.ErrCodeSagaNotFound, "Saga not found")
+		} else if utils.IsConflictError(err) {
+			utils.ErrorResponse(ctx, http.StatusConflict, dto.ErrCodeSagaInvalidState, err.Error())
+		} else {
+			utils.ErrorResponse(ctx, http.StatusInternalServerError, dto.ErrCodeInternalError, "Failed to process saga")
+		}
+		return
+	}
+
+	var response dto.SagaResponse
+	response.FromModel(inst, c.sagaService.IsStuck(inst))
+	utils.SuccessResponse(ctx, http.StatusOK, response)
+}
@@ -1,39 +1,83 @@
 package db
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"io/fs"
+	"log/slog"
+	"net/url"
 	"os"
-	"path/filepath"
-	"sort"
-	"strconv"
-	"strings"
 	"time"
 
 	"order-services/internal/config"
+	"order-services/migrations"
+
+	"github.com/ductan2/microservice-app/shared/dbreplica"
+	"github.com/ductan2/microservice-app/shared/ids/gormids"
+	"github.com/ductan2/microservice-app/shared/migrate"
+	"github.com/ductan2/microservice-app/shared/telemetry/gormtrace"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
-const (
-	defaultMigrationsDir = "migrations"
-	migrationsTableName  = "schema_migrations"
-)
+// ConnectPostgres creates a GORM connection to PostgreSQL
+func ConnectPostgres() (*gorm.DB, error) {
+	cfg := config.GetConfig()
+
+	db, err := open(cfg, cfg.DatabaseURL())
+	if err != nil {
+		return nil, err
+	}
 
-type migrationFile struct {
-	version    string
-	versionNum int
-	name       string
-	path       string
+	slog.Info("connected to PostgreSQL")
+	return db, nil
 }
 
-// ConnectPostgres creates a GORM connection to PostgreSQL
-func ConnectPostgres() (*gorm.DB, error) {
+// ConnectReplicas connects to the read replicas of DB_REPLICA_URLS and installs them on
+// primary, where the listings, stats and exports of the repositories read through
+// dbreplica.Read. Without replicas it returns nil and every query stays on primary.
+func ConnectReplicas(primary *gorm.DB) (*dbreplica.Pool, error) {
 	cfg := config.GetConfig()
-	dsn := cfg.DatabaseURL()
+	if len(cfg.DBReplicaURLs) == 0 {
+		return nil, nil
+	}
 
+	replicas := make([]dbreplica.Replica, 0, len(cfg.DBReplicaURLs))
+	for _, dsn := range cfg.DBReplicaURLs {
+		name := dsn
+		if u, err := url.Parse(dsn); err == nil {
+			name = u.Host
+		}
+		db, err := open(cfg, dsn)
+		if err != nil {
+			closeReplicas(replicas)
+			return nil, fmt.Errorf("read replica %s: %w", name, err)
+		}
+		replicas = append(replicas, dbreplica.Replica{Name: name, DB: db})
+	}
+
+	pool := dbreplica.New(replicas, dbreplica.Config{MaxLag: cfg.DBReplicaMaxLag})
+	if err := primary.Use(pool); err != nil {
+		closeReplicas(replicas)
+		return nil, fmt.Errorf("failed to install read replicas: %w", err)
+	}
+	slog.Info("connected to PostgreSQL read replicas", "count", len(replicas))
+	return pool, nil
+}
+
+func closeReplicas(replicas []dbreplica.Replica) {
+	for _, replica := range replicas {
+		if sqlDB, err := replica.DB.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	}
+}
+
+// open connects to the database of dsn with the configuration and plugins of the
+// service, shared by the primary and its replicas.
+func open(cfg *config.Config, dsn string) (*gorm.DB, error) {
 	// Configure GORM logger based on environment
 	logLevel := logger.Silent
 	if cfg.IsDevelopment() {
@@ -53,6 +97,16 @@ func ConnectPostgres() (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	// Trace the queries of each request
+	if err := db.Use(gormtrace.Plugin{}); err != nil {
+		return nil, fmt.Errorf("failed to install tracing: %w", err)
+	}
+
+	// New records get time-sortable UUIDv7 keys
+	if err := db.Use(gormids.Plugin{}); err != nil {
+		return nil, fmt.Errorf("failed to install ID generation: %w", err)
+	}
+
 	// Get underlying SQL DB to configure connection pool
 	sqlDB, err := db.DB()
 	if err != nil {
@@ -67,188 +121,53 @@ func ConnectPostgres() (*gorm.DB, error) {
 
 	// Test connection
 	if err := sqlDB.Ping(); err != nil {
+		_ = sqlDB.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
-
-	log.Println("✅ Connected to PostgreSQL with GORM")
 	return db, nil
 }
 
-// RunMigrations executes SQL files stored in the migrations directory.
-// Files should follow the pattern: <version>_<description>.up.sql (e.g. 0001_initial.up.sql).
-func RunMigrations(gormDB *gorm.DB, dirOverride string) error {
-	if err := ensureUUIDExtension(gormDB); err != nil {
-		return err
-	}
-
-	dir := resolveMigrationsDir(dirOverride)
-	migrations, err := loadMigrationFiles(dir)
-	if err != nil {
-		return err
-	}
-	if len(migrations) == 0 {
-		log.Printf("⚠️  No migration files found in %s", dir)
-		return nil
-	}
-
-	if err := ensureMigrationsTable(gormDB); err != nil {
-		return err
+// Migrator returns the migrator of the migrations embedded in the binary, or of the
+// directory dir, MIGRATIONS_DIR when empty, to try migrations without rebuilding.
+func Migrator(gormDB *gorm.DB, dir string) (*migrate.Migrator, error) {
+	if dir == "" {
+		dir = os.Getenv("MIGRATIONS_DIR")
 	}
-
-	appliedCount := 0
-	for _, m := range migrations {
-		applied, err := isMigrationApplied(gormDB, m.version)
-		if err != nil {
-			return err
-		}
-		if applied {
-			continue
-		}
-		if err := applyMigrationFile(gormDB, m); err != nil {
-			return err
-		}
-		log.Printf("✅ Applied migration %s", m.name)
-		appliedCount++
-	}
-
-	if appliedCount == 0 {
-		log.Println("✅ Database already up to date")
-	} else {
-		log.Printf("✅ Completed %d migration(s)", appliedCount)
-	}
-	return nil
-}
-
-func resolveMigrationsDir(dir string) string {
+	var fsys fs.FS = migrations.FS
 	if dir != "" {
-		return dir
-	}
-	if envDir := os.Getenv("MIGRATIONS_DIR"); envDir != "" {
-		return envDir
-	}
-	return defaultMigrationsDir
-}
-
-func ensureUUIDExtension(db *gorm.DB) error {
-	if err := db.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`).Error; err != nil {
-		return fmt.Errorf("failed to enable uuid-ossp extension: %w", err)
-	}
-	return nil
-}
-
-func ensureMigrationsTable(db *gorm.DB) error {
-	createStmt := fmt.Sprintf(`
-		CREATE TABLE IF NOT EXISTS %s (
-			version TEXT PRIMARY KEY,
-			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
-		);`, migrationsTableName)
-	if err := db.Exec(createStmt).Error; err != nil {
-		return fmt.Errorf("failed to create %s table: %w", migrationsTableName, err)
+		fsys = os.DirFS(dir)
 	}
-	return nil
-}
-
-func loadMigrationFiles(dir string) ([]migrationFile, error) {
-	entries, err := os.ReadDir(dir)
+	files, err := migrate.Load(fsys, ".sql")
 	if err != nil {
-		return nil, fmt.Errorf("failed to read migrations directory %s: %w", dir, err)
-	}
-
-	files := make([]migrationFile, 0, len(entries))
-	seen := make(map[string]struct{})
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-		name := entry.Name()
-		lower := strings.ToLower(name)
-		if !strings.HasSuffix(lower, ".sql") {
-			continue
-		}
-		if strings.Contains(lower, ".down.") {
-			continue
-		}
-		version, versionNum := parseMigrationVersion(name)
-		if version == "" {
-			log.Printf("⚠️  Skipping migration with unrecognized name: %s", name)
-			continue
-		}
-		if _, exists := seen[version]; exists {
-			return nil, fmt.Errorf("duplicate migration version detected: %s", version)
-		}
-		seen[version] = struct{}{}
-		files = append(files, migrationFile{
-			version:    version,
-			versionNum: versionNum,
-			name:       name,
-			path:       filepath.Join(dir, name),
-		})
-	}
-
-	sort.Slice(files, func(i, j int) bool {
-		if files[i].versionNum == files[j].versionNum {
-			return files[i].name < files[j].name
-		}
-		return files[i].versionNum < files[j].versionNum
-	})
-
-	return files, nil
-}
-
-func parseMigrationVersion(name string) (string, int) {
-	name = strings.TrimSpace(name)
-	if name == "" {
-		return "", 0
-	}
-	parts := strings.SplitN(name, "_", 2)
-	raw := parts[0]
-	numStr := strings.TrimLeft(raw, "0")
-	if numStr == "" {
-		numStr = "0"
+		return nil, err
 	}
-	num, err := strconv.Atoi(numStr)
+	sqlDB, err := gormDB.DB()
 	if err != nil {
-		return "", 0
+		return nil, err
 	}
-	return raw, num
+	return migrate.New(migrate.NewPostgres(sqlDB, migrate.DefaultTable), files), nil
 }
 
-func isMigrationApplied(db *gorm.DB, version string) (bool, error) {
-	var count int64
-	query := fmt.Sprintf("SELECT COUNT(1) FROM %s WHERE version = ?", migrationsTableName)
-	if err := db.Raw(query, version).Scan(&count).Error; err != nil {
-		return false, fmt.Errorf("failed to check migration %s: %w", version, err)
+// RunMigrations applies the pending migrations when apply is set, then refuses to go on
+// if the database does not match the migrations of this build.
+func RunMigrations(ctx context.Context, gormDB *gorm.DB, apply bool) error {
+	if apply {
+		if err := EnsureUUIDExtension(gormDB); err != nil {
+			return err
+		}
 	}
-	return count > 0, nil
-}
-
-func applyMigrationFile(db *gorm.DB, m migrationFile) error {
-	content, err := os.ReadFile(m.path)
+	migrator, err := Migrator(gormDB, "")
 	if err != nil {
-		return fmt.Errorf("failed to read migration %s: %w", m.name, err)
-	}
-
-	sql := strings.TrimSpace(string(content))
-	if sql == "" {
-		log.Printf("⚠️  Migration %s is empty, marking as applied", m.name)
-		return recordMigration(db, m.version)
+		return err
 	}
-
-	return db.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Exec(sql).Error; err != nil {
-			return fmt.Errorf("migration %s failed: %w", m.name, err)
-		}
-		if err := recordMigration(tx, m.version); err != nil {
-			return err
-		}
-		return nil
-	})
+	return migrator.Startup(ctx, apply)
 }
 
-func recordMigration(db *gorm.DB, version string) error {
-	stmt := fmt.Sprintf("INSERT INTO %s (version) VALUES (?)", migrationsTableName)
-	if err := db.Exec(stmt, version).Error; err != nil {
-		return fmt.Errorf("failed to record migration %s: %w", version, err)
+// EnsureUUIDExtension enables uuid-ossp, which the migrations use for default IDs,
+// before they are applied.
+func EnsureUUIDExtension(db *gorm.DB) error {
+	if err := db.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`).Error; err != nil {
+		return fmt.Errorf("failed to enable uuid-ossp extension: %w", err)
 	}
 	return nil
 }
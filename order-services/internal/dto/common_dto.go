@@ -2,6 +2,8 @@ package dto
 
 import (
 	"time"
+
+	"github.com/ductan2/microservice-app/shared/apperr"
 )
 
 // APIResponse represents a standard API response wrapper
@@ -49,15 +51,6 @@ type ValidationErrorResponse struct {
 	Errors  []ValidationError `json:"errors"`
 }
 
-// HealthResponse represents health check response
-type HealthResponse struct {
-	Status    string            `json:"status"`
-	Timestamp time.Time         `json:"timestamp"`
-	Version   string            `json:"version"`
-	Services  map[string]string `json:"services"`
-	Checks    map[string]bool   `json:"checks"`
-}
-
 // StatusResponse represents a simple status response
 type StatusResponse struct {
 	Status  string `json:"status"`
@@ -222,16 +215,16 @@ func NewValidationError(field, message string, value interface{}) ValidationErro
 
 // Error codes
 const (
-	// General error codes
-	ErrCodeInternalError      = "INTERNAL_ERROR"
-	ErrCodeBadRequest         = "BAD_REQUEST"
-	ErrCodeUnauthorized       = "UNAUTHORIZED"
-	ErrCodeForbidden          = "FORBIDDEN"
-	ErrCodeNotFound           = "NOT_FOUND"
-	ErrCodeConflict           = "CONFLICT"
-	ErrCodeValidationFailed   = "VALIDATION_FAILED"
-	ErrCodeRateLimitExceeded  = "RATE_LIMIT_EXCEEDED"
-	ErrCodeServiceUnavailable = "SERVICE_UNAVAILABLE"
+	// General error codes, the canonical codes of the shared error model
+	ErrCodeInternalError      = string(apperr.Internal)
+	ErrCodeBadRequest         = string(apperr.BadRequest)
+	ErrCodeUnauthorized       = string(apperr.Unauthorized)
+	ErrCodeForbidden          = string(apperr.Forbidden)
+	ErrCodeNotFound           = string(apperr.NotFound)
+	ErrCodeConflict           = string(apperr.Conflict)
+	ErrCodeValidationFailed   = string(apperr.ValidationFailed)
+	ErrCodeRateLimitExceeded  = string(apperr.RateLimited)
+	ErrCodeServiceUnavailable = string(apperr.Unavailable)
 
 	// Order-specific error codes
 	ErrCodeOrderNotFound       = "ORDER_NOT_FOUND"
@@ -249,6 +242,15 @@ const (
 	ErrCodeWebhookSignature    = "WEBHOOK_SIGNATURE_INVALID"
 	ErrCodeDuplicateWebhook    = "DUPLICATE_WEBHOOK"
 	ErrCodeStripeError         = "STRIPE_ERROR"
+	ErrCodeOrderNotPaid        = "ORDER_NOT_PAID"
+
+	// Refund-specific error codes
+	ErrCodeRefundNotFound         = "REFUND_NOT_FOUND"
+	ErrCodeRefundAlreadyProcessed = "REFUND_ALREADY_PROCESSED"
+	ErrCodeRefundAmountExceeded   = "REFUND_AMOUNT_EXCEEDED"
+	ErrCodeRefundWindowExpired    = "REFUND_WINDOW_EXPIRED"
+	ErrCodeInvalidRefundReason    = "INVALID_REFUND_REASON"
+	ErrCodeRefundNotAllowed       = "REFUND_NOT_ALLOWED"
 
 	// Coupon-specific error codes
 	ErrCodeCouponNotFound      = "COUPON_NOT_FOUND"
@@ -260,12 +262,18 @@ const (
 	ErrCodeMinimumAmountNotMet = "MINIMUM_AMOUNT_NOT_MET"
 	ErrCodeFirstTimeOnly       = "FIRST_TIME_ONLY"
 	ErrCodeCourseNotApplicable = "COURSE_NOT_APPLICABLE"
+	ErrCodeInvalidCoupon       = "INVALID_COUPON"
+	ErrCodeCouponCodeTaken     = "COUPON_CODE_TAKEN"
 
 	// Event-specific error codes
 	ErrCodeEventNotFound       = "EVENT_NOT_FOUND"
 	ErrCodeEventPublishFailed  = "EVENT_PUBLISH_FAILED"
 	ErrCodeQueueConnection     = "QUEUE_CONNECTION_FAILED"
 	ErrCodeQueueChannel        = "QUEUE_CHANNEL_FAILED"
+
+	// Saga-specific error codes
+	ErrCodeSagaNotFound     = "SAGA_NOT_FOUND"
+	ErrCodeSagaInvalidState = "SAGA_INVALID_STATE"
 )
 
 // Common validation messages
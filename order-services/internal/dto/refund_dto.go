@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"order-services/internal/models"
+)
+
+// RefundOrderRequest represents a customer's request to refund an order
+type RefundOrderRequest struct {
+	Reason string `json:"reason" validate:"required,oneof=dissatisfaction technical_issues duplicate_purchase accidental_purchase course_not_as_described other"`
+	Amount *int64 `json:"amount,omitempty" validate:"omitempty,min=1"` // in cents, the full payment when not specified
+}
+
+// RefundRequestResponse represents a refund request in the response
+type RefundRequestResponse struct {
+	ID             uuid.UUID  `json:"id"`
+	OrderID        uuid.UUID  `json:"order_id"`
+	Amount         int64      `json:"amount"` // in cents
+	Reason         string     `json:"reason"`
+	ReasonCategory string     `json:"reason_category,omitempty"`
+	Status         string     `json:"status"`
+	AdminReason    *string    `json:"admin_reason,omitempty"`
+	ProcessedAt    *time.Time `json:"processed_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// RefundRequestListResponse represents a paginated list of refund requests
+type RefundRequestListResponse struct {
+	Refunds []RefundRequestResponse `json:"refunds"`
+	Total   int64                   `json:"total"`
+	Limit   int                     `json:"limit"`
+	Offset  int                     `json:"offset"`
+}
+
+// FromModel converts a RefundRequest model to RefundRequestResponse
+func (r *RefundRequestResponse) FromModel(refund *models.RefundRequest) {
+	if refund == nil {
+		return
+	}
+
+	r.ID = refund.ID
+	r.OrderID = refund.OrderID
+	r.Amount = refund.Amount
+	r.Reason = refund.Reason
+	r.ReasonCategory = refund.ReasonCategory
+	r.Status = refund.Status
+	r.AdminReason = refund.AdminReason
+	r.CreatedAt = refund.CreatedAt
+	r.UpdatedAt = refund.UpdatedAt
+
+	if refund.ProcessedAt.Valid {
+		r.ProcessedAt = &refund.ProcessedAt.Time
+	}
+}
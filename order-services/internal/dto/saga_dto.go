@@ -0,0 +1,65 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/saga"
+	"github.com/google/uuid"
+)
+
+// SagaListQuery represents the filters of the admin saga list
+type SagaListQuery struct {
+	Type    string `json:"type" form:"type" query:"type" validate:"omitempty"`
+	Status  string `json:"status" form:"status" query:"status" validate:"omitempty,oneof=running completed failed compensating compensated compensation_failed"`
+	OrderID string `json:"order_id" form:"order_id" query:"order_id" validate:"omitempty,uuid"`
+	// Stuck lists the parked sagas and those running for longer than SAGA_STUCK_AFTER_MINUTES
+	Stuck  bool `json:"stuck" form:"stuck" query:"stuck"`
+	Limit  int  `json:"limit" form:"limit" query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset int  `json:"offset" form:"offset" query:"offset" validate:"omitempty,min=0"`
+}
+
+// SagaResponse represents a saga in API responses
+type SagaResponse struct {
+	ID            uuid.UUID        `json:"id"`
+	Type          string           `json:"type"`
+	OrderID       uuid.UUID        `json:"order_id"`
+	Status        string           `json:"status"`
+	Stuck         bool             `json:"stuck"`
+	CurrentStep   string           `json:"current_step,omitempty"`
+	Steps         []saga.StepState `json:"steps"`
+	Attempts      int              `json:"attempts"`
+	LastError     string           `json:"last_error,omitempty"`
+	NextAttemptAt *time.Time       `json:"next_attempt_at,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+	FinishedAt    *time.Time       `json:"finished_at,omitempty"`
+}
+
+// FromModel converts a saga to its response; stuck is decided by the saga service
+func (r *SagaResponse) FromModel(inst *saga.Instance, stuck bool) {
+	r.ID = inst.ID
+	r.Type = inst.Type
+	r.OrderID = inst.AggregateID
+	r.Status = string(inst.Status)
+	r.Stuck = stuck
+	r.Steps = inst.Steps
+	r.Attempts = inst.Attempts
+	r.LastError = inst.LastError
+	r.CreatedAt = inst.CreatedAt
+	r.UpdatedAt = inst.UpdatedAt
+	r.FinishedAt = inst.FinishedAt
+	if inst.Step >= 0 && inst.Step < len(inst.Steps) && (inst.Status.Active() || inst.Status.Parked()) {
+		r.CurrentStep = inst.Steps[inst.Step].Name
+	}
+	if inst.Status.Active() {
+		r.NextAttemptAt = &inst.NextAttemptAt
+	}
+}
+
+// SagaListResponse represents a page of sagas
+type SagaListResponse struct {
+	Sagas  []SagaResponse `json:"sagas"`
+	Total  int64          `json:"total"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+}
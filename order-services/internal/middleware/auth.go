@@ -1,12 +1,14 @@
 package middleware
 
 import (
-	"fmt"
-	"math/rand"
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/ductan2/microservice-app/shared/logging"
+	"github.com/ductan2/microservice-app/shared/ratelimit"
+	"github.com/ductan2/microservice-app/shared/requestmeta"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -91,6 +93,7 @@ func JWTAuth(secretKey string) gin.HandlerFunc {
 		c.Set("user_role", claims.Role)
 		c.Set("user_email", claims.Email)
 		c.Set("token", tokenString)
+		c.Request = c.Request.WithContext(logging.WithUserID(c.Request.Context(), claims.UserID))
 
 		c.Next()
 	}
@@ -158,23 +161,19 @@ func OptionalAuth(secretKey string) gin.HandlerFunc {
 		c.Set("user_role", claims.Role)
 		c.Set("user_email", claims.Email)
 		c.Set("token", tokenString)
+		c.Request = c.Request.WithContext(logging.WithUserID(c.Request.Context(), claims.UserID))
 
 		c.Next()
 	}
 }
 
-// RequestID middleware adds a unique request ID to each request
-func RequestID() gin.HandlerFunc {
-	return gin.HandlerFunc(func(c *gin.Context) {
-		requestID := c.GetHeader("X-Request-ID")
-		if requestID == "" {
-			// Generate a simple request ID (in production, use proper UUID)
-			requestID = generateRequestID()
-		}
-		c.Set("request_id", requestID)
-		c.Header("X-Request-ID", requestID)
+// RequestMeta stores the X-Request-ID forwarded by the BFF, or a new one, and the client
+// IP resolved past proxies on the request context (see shared/requestmeta)
+func RequestMeta(proxies requestmeta.Proxies) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = requestmeta.Attach(c.Writer, c.Request, proxies)
 		c.Next()
-	})
+	}
 }
 
 // CORS middleware handles Cross-Origin Resource Sharing
@@ -200,41 +199,57 @@ func CORS() gin.HandlerFunc {
 	}
 }
 
-// Logging middleware for request logging
+// Logging middleware writes an access log record for each request once it has been
+// served, with the request, user and trace IDs stored on its context
 func Logging() gin.HandlerFunc {
-	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
-		return fmt.Sprintf("[%s] %s %s %d %s %s\n",
-			param.TimeStamp.Format("2006-01-02 15:04:05"),
-			param.Method,
-			param.Path,
-			param.StatusCode,
-			param.Latency,
-			param.ClientIP,
-		)
-	})
-}
-
-// RateLimit middleware for basic rate limiting (placeholder)
-func RateLimit() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// TODO: Implement proper rate limiting using Redis
-		// For now, just pass through
+		start := time.Now()
 		c.Next()
+
+		logging.LogRequest(c.Request.Context(), logging.HTTPRequest{
+			Method:   c.Request.Method,
+			Route:    c.FullPath(),
+			Path:     c.Request.URL.Path,
+			Status:   c.Writer.Status(),
+			Latency:  time.Since(start),
+			ClientIP: c.ClientIP(),
+		})
 	}
 }
 
-// Helper function to generate request IDs
-func generateRequestID() string {
-	// In production, use proper UUID generation
-	// This is a simple placeholder implementation
-	return time.Now().Format("20060102150405") + "-" + randomString(6)
-}
+// RateLimit limits the requests of each user, or of each client IP before
+// authentication, with policy. Without a limiter (Redis is down at startup) requests
+// pass through.
+func RateLimit(limiter *ratelimit.Limiter, policy ratelimit.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limiter == nil {
+			c.Next()
+			return
+		}
+
+		key := "ip:" + c.ClientIP()
+		if userID := c.GetString("user_id"); userID != "" {
+			key = "user:" + userID
+		}
 
-func randomString(length int) string {
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	b := make([]byte, length)
-	for i := range b {
-		b[i] = charset[rand.Intn(len(charset))]
+		result, err := limiter.Allow(c.Request.Context(), policy, key)
+		if err != nil {
+			slog.WarnContext(c.Request.Context(), "rate limit check failed", "policy", policy.Name, "error", err)
+		} else {
+			result.SetHeaders(c.Writer.Header())
+		}
+		if !result.Allowed {
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"success": false,
+				"error": gin.H{
+					"code":    "RATE_LIMITED",
+					"message": "Too many requests. Please try again later.",
+				},
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
 	}
-	return string(b)
-}
\ No newline at end of file
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/ductan2/microservice-app/shared/internalauth"
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceAuth checks the service token of calls from other services (shared/internalauth)
+// and stores the calling service under "calling_service". Requests without a token go on
+// with their user identity headers removed; an invalid token is refused.
+func ServiceAuth(verifier *internalauth.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := verifier.Authenticate(c.Request)
+		if err != nil {
+			slog.WarnContext(c.Request.Context(), "rejected service token", "error", err, "path", c.Request.URL.Path)
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error": gin.H{
+					"code":    "UNAUTHORIZED",
+					"message": "Invalid internal service credentials",
+				},
+			})
+			c.Abort()
+			return
+		}
+		if claims != nil {
+			c.Set("calling_service", claims.Issuer)
+		}
+		c.Next()
+	}
+}
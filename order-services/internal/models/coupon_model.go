@@ -4,13 +4,14 @@ import (
 	"database/sql"
 	"time"
 
+	"github.com/ductan2/microservice-app/shared/softdelete/gormsoft"
 	"github.com/google/uuid"
 )
 
 // Coupon represents discount coupons that can be applied to orders
 type Coupon struct {
 	ID                    uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
-	Code                  string       `gorm:"type:text;uniqueIndex;not null" json:"code"`
+	Code                  string       `gorm:"type:text;not null;uniqueIndex:coupons_code_active_idx,where:deleted_at IS NULL" json:"code"`
 	Name                  string       `gorm:"type:text;not null" json:"name"`
 	Description           string       `gorm:"type:text" json:"description,omitempty"`
 	Type                  string       `gorm:"type:varchar(20);not null;check:type IN ('percentage','fixed_amount')" json:"type"`
@@ -29,6 +30,8 @@ type Coupon struct {
 	IsActive              bool         `gorm:"default:true;not null" json:"is_active"`
 	CreatedAt             time.Time    `json:"created_at"`
 	UpdatedAt             time.Time    `json:"updated_at"`
+	// A deleted coupon cannot be redeemed and frees its code
+	gormsoft.Fields
 
 	// Relationships
 	CouponRedemptions     []CouponRedemption `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:CouponID;references:ID" json:"coupon_redemptions,omitempty"`
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ductan2/microservice-app/shared/money"
+)
+
+// DefaultCurrency is the currency courses are priced and orders are placed in.
+const DefaultCurrency = money.USD
+
+// CurrencyOf returns the currency of a stored code, DefaultCurrency when it is empty.
+func CurrencyOf(code string) money.Currency {
+	if code == "" {
+		return DefaultCurrency
+	}
+	return money.Currency(code)
+}
+
+// Total returns the amount due for the order.
+func (o *Order) Total() money.Money {
+	return money.New(o.TotalAmount, CurrencyOf(o.Currency))
+}
+
+// Total returns the amount of the payment.
+func (p *Payment) Total() money.Money {
+	return money.New(p.Amount, CurrencyOf(p.Currency))
+}
+
+// Total returns the amount invoiced, tax included.
+func (i *Invoice) Total() money.Money {
+	return money.New(i.TotalAmount, CurrencyOf(i.Currency))
+}
+
+// Tax returns the tax included in the total of the invoice.
+func (i *Invoice) Tax() money.Money {
+	return money.New(i.TaxAmount, CurrencyOf(i.Currency))
+}
+
+// Total returns the amount refunded, in the currency of the order when it is loaded.
+func (r *RefundRequest) Total() money.Money {
+	return money.New(r.Amount, CurrencyOf(r.Order.Currency))
+}
+
+// Discount returns the discount of the coupon on amount. A percentage is rounded down
+// and no discount exceeds amount; a fixed amount in another currency is refused.
+func (c *Coupon) Discount(amount money.Money) (money.Money, error) {
+	var discount money.Money
+	var err error
+	switch c.Type {
+	case CouponTypePercentage:
+		if c.PercentOff == nil {
+			return money.Money{}, errors.New("percentage coupon missing percent_off value")
+		}
+		discount, err = amount.Percent(int64(*c.PercentOff))
+	case CouponTypeFixedAmount:
+		if c.AmountOff == nil {
+			return money.Money{}, errors.New("fixed amount coupon missing amount_off value")
+		}
+		discount = money.New(*c.AmountOff, CurrencyOf(c.Currency))
+	default:
+		return money.Money{}, fmt.Errorf("unknown coupon type: %s", c.Type)
+	}
+	if err != nil {
+		return money.Money{}, err
+	}
+	return discount.Min(amount)
+}
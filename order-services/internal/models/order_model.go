@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"time"
 
+	"github.com/ductan2/microservice-app/shared/softdelete/gormsoft"
 	"github.com/google/uuid"
 )
 
@@ -27,7 +28,7 @@ type Order struct {
 	Metadata          map[string]any `gorm:"type:jsonb;default:'{}'" json:"metadata"`
 	CreatedAt         time.Time    `json:"created_at"`
 	UpdatedAt         time.Time    `json:"updated_at"`
-	DeletedAt         sql.NullTime `gorm:"type:timestamptz" json:"deleted_at,omitempty"`
+	gormsoft.Fields
 
 	// Relationships
 	OrderItems        []OrderItem `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:OrderID;references:ID" json:"order_items,omitempty"`
@@ -15,5 +15,14 @@ type Outbox struct {
 	Type        string       `gorm:"type:text;not null" json:"type"`
 	Payload     []byte       `gorm:"type:jsonb" json:"payload"`
 	CreatedAt   time.Time    `gorm:"default:now();not null" json:"created_at"`
-	PublishedAt sql.NullTime `gorm:"index:outbox_unpublished_idx,where:published_at IS NULL" json:"published_at,omitempty"`
-}
\ No newline at end of file
+	PublishedAt sql.NullTime `json:"published_at,omitempty"`
+	// Attempts counts failed publishes; the event is retried from NextAttemptAt on and
+	// parked with FailedAt set once OUTBOX_MAX_ATTEMPTS is reached
+	Attempts      int          `gorm:"default:0;not null" json:"attempts"`
+	LastError     string       `gorm:"type:text" json:"last_error,omitempty"`
+	NextAttemptAt time.Time    `gorm:"default:now();not null" json:"next_attempt_at"`
+	FailedAt      sql.NullTime `json:"failed_at,omitempty"`
+	// TraceParent continues the trace of the request that wrote the event when it is
+	// published
+	TraceParent string `gorm:"type:text;not null;default:''" json:"-"`
+}
@@ -16,7 +16,7 @@ type Payment struct {
 	Currency              string       `gorm:"type:varchar(3);default:'USD';not null" json:"currency"`
 	Status                string       `gorm:"type:varchar(50);not null;check:status IN ('requires_payment_method','requires_confirmation','requires_action','processing','succeeded','canceled','failed')" json:"status"`
 	PaymentMethod         string       `gorm:"type:text" json:"payment_method,omitempty"`
-	PaymentMethodType     string       `gorm:"type:varchar(50);json:"payment_method_type,omitempty"` // card, ideal, etc.
+	PaymentMethodType     string       `gorm:"type:varchar(50)" json:"payment_method_type,omitempty"` // card, ideal, etc.
 	StripeChargeID        string       `gorm:"type:text;index:payments_charge_idx" json:"stripe_charge_id,omitempty"`
 	StripeReceiptURL      string       `gorm:"type:text" json:"stripe_receipt_url,omitempty"`
 	FailureMessage        string       `gorm:"type:text" json:"failure_message,omitempty"`
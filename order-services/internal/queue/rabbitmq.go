@@ -1,8 +1,8 @@
 package queue
 
 import (
+	"log/slog"
 	"context"
-	"log"
 	"time"
 
 	"order-services/internal/config"
@@ -58,7 +58,7 @@ func NewRabbitMQ(ctx context.Context) (*amqp.Connection, *amqp.Channel, error) {
 		return nil, nil, err
 	}
 
-	log.Println("✅ Connected to RabbitMQ")
+	slog.Info("connected to RabbitMQ")
 	return conn, ch, nil
 }
 
@@ -198,6 +198,6 @@ func SetupExchangesAndQueues(ch *amqp.Channel) error {
 		return err
 	}
 
-	log.Println("✅ RabbitMQ exchanges and queues setup completed")
+	slog.Info("RabbitMQ exchanges and queues set up")
 	return nil
 }
\ No newline at end of file
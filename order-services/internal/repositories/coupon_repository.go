@@ -7,6 +7,9 @@ import (
 
 	"gorm.io/gorm"
 
+	"github.com/ductan2/microservice-app/shared/dbreplica"
+	"github.com/ductan2/microservice-app/shared/softdelete"
+	"github.com/ductan2/microservice-app/shared/softdelete/gormsoft"
 	"github.com/google/uuid"
 	"order-services/internal/models"
 )
@@ -15,10 +18,15 @@ import (
 type CouponRepository interface {
 	Create(ctx context.Context, coupon *models.Coupon) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Coupon, error)
+	// GetByIDInScope is GetByID seeing the coupons of scope, such as deleted ones
+	GetByIDInScope(ctx context.Context, id uuid.UUID, scope softdelete.Scope) (*models.Coupon, error)
 	GetByCode(ctx context.Context, code string) (*models.Coupon, error)
 	GetActiveCoupons(ctx context.Context) ([]models.Coupon, error)
 	Update(ctx context.Context, coupon *models.Coupon) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	// Delete soft-deletes a coupon; it reports false when it was already deleted
+	Delete(ctx context.Context, id uuid.UUID, mark softdelete.Mark) (bool, error)
+	// Restore clears the deletion of a coupon; it reports false when it was not deleted
+	Restore(ctx context.Context, id uuid.UUID) (bool, error)
 	GetUserRedemptionCount(ctx context.Context, couponID, userID uuid.UUID) (int, error)
 	CreateRedemption(ctx context.Context, redemption *models.CouponRedemption) error
 	GetRedemptionsByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]models.CouponRedemption, int64, error)
@@ -51,10 +59,16 @@ func (r *couponRepository) Create(ctx context.Context, coupon *models.Coupon) er
 	return r.db.WithContext(ctx).Create(coupon).Error
 }
 
-// GetByID retrieves a coupon by ID
+// GetByID retrieves a coupon by ID, nil when it does not exist or is deleted
 func (r *couponRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Coupon, error) {
+	return r.GetByIDInScope(ctx, id, softdelete.Active)
+}
+
+// GetByIDInScope retrieves a coupon by ID among the coupons of scope
+func (r *couponRepository) GetByIDInScope(ctx context.Context, id uuid.UUID, scope softdelete.Scope) (*models.Coupon, error) {
 	var coupon models.Coupon
 	err := r.db.WithContext(ctx).
+		Scopes(gormsoft.Scope(scope)).
 		Where("id = ?", id).
 		First(&coupon).Error
 
@@ -71,6 +85,7 @@ func (r *couponRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.C
 func (r *couponRepository) GetByCode(ctx context.Context, code string) (*models.Coupon, error) {
 	var coupon models.Coupon
 	err := r.db.WithContext(ctx).
+		Scopes(gormsoft.Active).
 		Where("code = ?", code).
 		First(&coupon).Error
 
@@ -89,6 +104,7 @@ func (r *couponRepository) GetActiveCoupons(ctx context.Context) ([]models.Coupo
 	now := time.Now()
 
 	err := r.db.WithContext(ctx).
+		Scopes(gormsoft.Active).
 		Where("is_active = ? AND valid_from <= ? AND (expires_at IS NULL OR expires_at > ?)",
 			true, now, now).
 		Order("created_at DESC").
@@ -102,9 +118,16 @@ func (r *couponRepository) Update(ctx context.Context, coupon *models.Coupon) er
 	return r.db.WithContext(ctx).Save(coupon).Error
 }
 
-// Delete deletes a coupon by ID
-func (r *couponRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&models.Coupon{}, id).Error
+// Delete soft-deletes a coupon by ID
+func (r *couponRepository) Delete(ctx context.Context, id uuid.UUID, mark softdelete.Mark) (bool, error) {
+	n, err := gormsoft.Delete(r.db.WithContext(ctx).Model(&models.Coupon{}).Where("id = ?", id), mark)
+	return n > 0, err
+}
+
+// Restore restores a soft-deleted coupon by ID
+func (r *couponRepository) Restore(ctx context.Context, id uuid.UUID) (bool, error) {
+	n, err := gormsoft.Restore(r.db.WithContext(ctx).Model(&models.Coupon{}).Where("id = ?", id))
+	return n > 0, err
 }
 
 // GetUserRedemptionCount gets the number of times a user has used a specific coupon
@@ -179,16 +202,19 @@ func (r *couponRepository) UpdateUsageCount(ctx context.Context, couponID uuid.U
 // GetCouponStats retrieves aggregated coupon statistics
 func (r *couponRepository) GetCouponStats(ctx context.Context, timeRange *TimeRange) (*CouponStats, error) {
 	var stats CouponStats
+	// Stats read from a replica when the service has some
+	db := dbreplica.Read(r.db.WithContext(ctx))
 
 	// Get total coupons
-	err := r.db.WithContext(ctx).Model(&models.Coupon{}).Count(&stats.TotalCoupons).Error
+	err := db.Model(&models.Coupon{}).Scopes(gormsoft.Active).Count(&stats.TotalCoupons).Error
 	if err != nil {
 		return nil, err
 	}
 
 	// Get active coupons
 	now := time.Now()
-	err = r.db.WithContext(ctx).Model(&models.Coupon{}).
+	err = db.Model(&models.Coupon{}).
+		Scopes(gormsoft.Active).
 		Where("is_active = ? AND valid_from <= ? AND (expires_at IS NULL OR expires_at > ?)",
 			true, now, now).
 		Count(&stats.ActiveCoupons).Error
@@ -197,14 +223,14 @@ func (r *couponRepository) GetCouponStats(ctx context.Context, timeRange *TimeRa
 	}
 
 	// Get total redemptions
-	err = r.db.WithContext(ctx).Model(&models.CouponRedemption{}).Count(&stats.TotalRedemptions).Error
+	err = db.Model(&models.CouponRedemption{}).Count(&stats.TotalRedemptions).Error
 	if err != nil {
 		return nil, err
 	}
 
 	// Get total discount amount
 	var totalDiscount int64
-	err = r.db.WithContext(ctx).Model(&models.CouponRedemption{}).
+	err = db.Model(&models.CouponRedemption{}).
 		Select("COALESCE(SUM(discount_amount), 0)").
 		Scan(&totalDiscount).Error
 	if err != nil {
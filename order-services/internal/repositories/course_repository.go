@@ -6,6 +6,9 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/ductan2/microservice-app/shared/internalauth"
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/ductan2/microservice-app/shared/telemetry"
 	"github.com/google/uuid"
 )
 
@@ -36,11 +39,12 @@ type courseRepository struct {
 }
 
 // NewCourseRepository creates a new course repository
-func NewCourseRepository(baseURL string) CourseRepository {
+func NewCourseRepository(baseURL string, signer *internalauth.Signer) CourseRepository {
 	return &courseRepository{
 		baseURL: baseURL,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: internalauth.NewTransport(signer, "content-services", metrics.NewTransport("content-services", telemetry.NewTransport(nil))),
 		},
 	}
 }
@@ -55,8 +59,6 @@ func (r *courseRepository) GetByID(ctx context.Context, courseID uuid.UUID) (*Co
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	// Add internal service authentication if needed
-	req.Header.Set("X-Internal-Service", "order-service")
 
 	resp, err := r.httpClient.Do(req)
 	if err != nil {
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErasureRepository removes the personal data of an erased user. Orders, invoices and
+// fraud logs are kept for the accounts, without the person.
+type ErasureRepository interface {
+	// Scrub pseudonymizes the orders of userID, and the orders placed with the address
+	// whose hex SHA-256 is emailSHA256 when it is set, and clears the billing addresses of
+	// their invoices and the IPs, user agents and details of their fraud logs. It returns
+	// the rows updated; rows of userID are updated again on a retry.
+	Scrub(ctx context.Context, userID uuid.UUID, emailSHA256 string) (int64, error)
+}
+
+// erasureRepository implements ErasureRepository
+type erasureRepository struct {
+	db *gorm.DB
+}
+
+// NewErasureRepository creates a new erasure repository
+func NewErasureRepository(db *gorm.DB) ErasureRepository {
+	return &erasureRepository{db: db}
+}
+
+// Scrub runs in one transaction and includes soft-deleted orders
+func (r *erasureRepository) Scrub(ctx context.Context, userID uuid.UUID, emailSHA256 string) (int64, error) {
+	var affected int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The orders of the user, and those placed with the same address from another
+		// account; their address becomes erased-<user_id>@erased.invalid like an account's
+		orders := tx.Exec(`
+			UPDATE orders SET
+				customer_email = 'erased-' || user_id::text || '@erased.invalid',
+				customer_name = NULL,
+				updated_at = NOW()
+			WHERE user_id = ?
+				OR (? <> '' AND encode(sha256(convert_to(lower(trim(customer_email)), 'UTF8')), 'hex') = ?)`,
+			userID, emailSHA256, emailSHA256)
+		if orders.Error != nil {
+			return fmt.Errorf("failed to scrub orders: %w", orders.Error)
+		}
+		invoices := tx.Exec(`
+			UPDATE invoices SET billing_address = '{}'::jsonb, updated_at = NOW()
+			WHERE user_id = ?`, userID)
+		if invoices.Error != nil {
+			return fmt.Errorf("failed to scrub invoices: %w", invoices.Error)
+		}
+		fraudLogs := tx.Exec(`
+			UPDATE fraud_logs SET ip_address = NULL, user_agent = NULL, details = '{}'::jsonb, updated_at = NOW()
+			WHERE user_id = ?`, userID)
+		if fraudLogs.Error != nil {
+			return fmt.Errorf("failed to scrub fraud logs: %w", fraudLogs.Error)
+		}
+		affected = orders.RowsAffected + invoices.RowsAffected + fraudLogs.RowsAffected
+		return nil
+	})
+	return affected, err
+}
@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/money"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"order-services/internal/models"
+)
+
+// InvoiceRepository interface for invoice data access
+type InvoiceRepository interface {
+	// CreateForOrder issues the invoice of a paid order, or returns the one it already has
+	CreateForOrder(ctx context.Context, order *models.Order) (*models.Invoice, error)
+	GetByOrderID(ctx context.Context, orderID uuid.UUID) (*models.Invoice, error)
+	// Void voids the invoice of an order, if it has one
+	Void(ctx context.Context, orderID uuid.UUID) error
+}
+
+// invoiceRepository implements InvoiceRepository
+type invoiceRepository struct {
+	db *gorm.DB
+}
+
+// NewInvoiceRepository creates a new invoice repository
+func NewInvoiceRepository(db *gorm.DB) InvoiceRepository {
+	return &invoiceRepository{db: db}
+}
+
+// CreateForOrder creates the invoice of an order; the unique order_id makes it idempotent
+func (r *invoiceRepository) CreateForOrder(ctx context.Context, order *models.Order) (*models.Invoice, error) {
+	// An invoice is only issued in a currency whose minor unit is known, so its amounts
+	// print correctly
+	total := order.Total()
+	currency, err := money.ParseCurrency(string(total.Currency()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invoice: %w", err)
+	}
+
+	issuedAt := time.Now()
+	invoice := &models.Invoice{
+		OrderID:        order.ID,
+		UserID:         order.UserID,
+		InvoiceNumber:  invoiceNumber(order.ID, issuedAt),
+		TotalAmount:    total.Amount(),
+		Currency:       string(currency),
+		BillingAddress: "{}",
+		TaxBreakdown:   "{}",
+		Status:         models.InvoiceStatusPaid,
+		IssuedAt:       issuedAt,
+		DueAt:          issuedAt,
+		PaidAt:         order.PaidAt,
+	}
+	for _, payment := range order.Payments {
+		if payment.Status == models.PaymentStatusSucceeded {
+			invoice.StripeChargeID = payment.StripeChargeID
+		}
+	}
+
+	err = r.db.WithContext(ctx).
+		Omit(clause.Associations).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "order_id"}}, DoNothing: true}).
+		Create(invoice).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invoice: %w", err)
+	}
+	return r.GetByOrderID(ctx, order.ID)
+}
+
+// GetByOrderID retrieves the invoice of an order
+func (r *invoiceRepository) GetByOrderID(ctx context.Context, orderID uuid.UUID) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := r.db.WithContext(ctx).Where("order_id = ?", orderID).First(&invoice).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// Void marks the invoice of an order void
+func (r *invoiceRepository) Void(ctx context.Context, orderID uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&models.Invoice{}).
+		Where("order_id = ? AND status <> ?", orderID, models.InvoiceStatusVoid).
+		Update("status", models.InvoiceStatusVoid).Error
+}
+
+// invoiceNumber numbers invoices by issue date and order, e.g. INV-20250314-3F2A9C1E
+func invoiceNumber(orderID uuid.UUID, issuedAt time.Time) string {
+	return fmt.Sprintf("INV-%s-%s", issuedAt.UTC().Format("20060102"), strings.ToUpper(orderID.String()[:8]))
+}
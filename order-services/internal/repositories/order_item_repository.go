@@ -6,6 +6,7 @@ import (
 
 	"gorm.io/gorm"
 
+	"github.com/ductan2/microservice-app/shared/dbreplica"
 	"github.com/google/uuid"
 	"order-services/internal/models"
 )
@@ -130,8 +131,11 @@ func (r *orderItemRepository) GetCourseSalesStats(ctx context.Context, courseID
 		RecentSales   int64 `json:"recent_sales"`
 	}
 
+	// Stats read from a replica when the service has some
+	db := dbreplica.Read(r.db.WithContext(ctx))
+
 	// Get total sales and revenue from paid orders only
-	query := r.db.WithContext(ctx).Table("order_items oi").
+	query := db.Table("order_items oi").
 		Select("COUNT(*) as total_sales, COALESCE(SUM(oi.price_snapshot), 0) as total revenue").
 		Joins("INNER JOIN orders o ON oi.order_id = o.id").
 		Where("oi.course_id = ? AND o.status = ?", courseID, models.OrderStatusPaid)
@@ -142,7 +146,7 @@ func (r *orderItemRepository) GetCourseSalesStats(ctx context.Context, courseID
 
 	// Get unique student count (users who actually purchased the course)
 	var studentCount int64
-	if err := r.db.WithContext(ctx).Table("order_items oi").
+	if err := db.Table("order_items oi").
 		Select("COUNT(DISTINCT o.user_id)").
 		Joins("INNER JOIN orders o ON oi.order_id = o.id").
 		Where("oi.course_id = ? AND o.status = ?", courseID, models.OrderStatusPaid).
@@ -152,7 +156,7 @@ func (r *orderItemRepository) GetCourseSalesStats(ctx context.Context, courseID
 	stats.TotalStudents = studentCount
 
 	// Get recent sales (last 30 days)
-	if err := r.db.WithContext(ctx).Table("order_items oi").
+	if err := db.Table("order_items oi").
 		Count(&stats.RecentSales).
 		Joins("INNER JOIN orders o ON oi.order_id = o.id").
 		Where("oi.course_id = ? AND o.status = ? AND o.created_at >= NOW() - INTERVAL '30 days'",
@@ -10,6 +10,10 @@ import (
 
 	"order-services/internal/models"
 
+	"github.com/ductan2/microservice-app/shared/dbreplica"
+	"github.com/ductan2/microservice-app/shared/queryparams"
+	"github.com/ductan2/microservice-app/shared/softdelete"
+	"github.com/ductan2/microservice-app/shared/softdelete/gormsoft"
 	"github.com/google/uuid"
 )
 
@@ -17,7 +21,7 @@ import (
 type OrderRepository interface {
 	Create(ctx context.Context, order *models.Order) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Order, error)
-	GetByUserID(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]models.Order, int64, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID, sort queryparams.Sort, limit int, offset int) ([]models.Order, int64, error)
 	GetByPaymentIntentID(ctx context.Context, paymentIntentID string) (*models.Order, error)
 	GetPendingOrders(ctx context.Context, olderThan time.Time) ([]models.Order, error)
 	UpdateStatus(ctx context.Context, id uuid.UUID, status string, timestamp *sql.NullTime, reason string) error
@@ -25,7 +29,7 @@ type OrderRepository interface {
 	MarkAsPaid(ctx context.Context, id uuid.UUID, paymentIntentID string) error
 	MarkAsFailed(ctx context.Context, id uuid.UUID, reason string) error
 	MarkAsCancelled(ctx context.Context, id uuid.UUID, reason string) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	Delete(ctx context.Context, id uuid.UUID, mark softdelete.Mark) (bool, error)
 	GetOrderStats(ctx context.Context, userID *uuid.UUID, timeRange *TimeRange) (*OrderStats, error)
 	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
 	UserHasPreviousOrders(ctx context.Context, userID uuid.UUID) (bool, error)
@@ -68,15 +72,21 @@ func (r *orderRepository) getDB(ctx context.Context) *gorm.DB {
 	return r.db
 }
 
+// reader returns the connection of the listings and stats: a read replica when the
+// service has some, unless ctx carries a transaction.
+func (r *orderRepository) reader(ctx context.Context) *gorm.DB {
+	return dbreplica.Read(r.getDB(ctx).WithContext(ctx))
+}
+
 // Create creates a new order
 func (r *orderRepository) Create(ctx context.Context, order *models.Order) error {
 	return r.getDB(ctx).WithContext(ctx).Create(order).Error
 }
 
-// GetByID retrieves an order by ID
+// GetByID retrieves an order by ID, nil when it is deleted
 func (r *orderRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Order, error) {
 	var order models.Order
-	err := r.getDB(ctx).WithContext(ctx).
+	err := r.getDB(ctx).WithContext(ctx).Scopes(gormsoft.Active).
 		Preload("OrderItems").
 		Preload("Payments").
 		Preload("CouponRedemptions").
@@ -92,23 +102,26 @@ func (r *orderRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Or
 	return &order, nil
 }
 
-// GetByUserID retrieves orders for a user with pagination
-func (r *orderRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]models.Order, int64, error) {
+// GetByUserID retrieves orders for a user with pagination. sort must come from
+// queryparams.ParseSort, which only accepts known columns.
+func (r *orderRepository) GetByUserID(ctx context.Context, userID uuid.UUID, sort queryparams.Sort, limit int, offset int) ([]models.Order, int64, error) {
 	var orders []models.Order
 	var total int64
 
-	err := r.getDB(ctx).WithContext(ctx).Model(&models.Order{}).
+	db := r.reader(ctx)
+	err := db.Model(&models.Order{}).Scopes(gormsoft.Active).
 		Where("user_id = ?", userID).
 		Count(&total).Error
 	if err != nil {
 		return nil, 0, err
 	}
 
-	err = r.getDB(ctx).WithContext(ctx).
+	err = db.Scopes(gormsoft.Active).
 		Preload("OrderItems").
 		Preload("Payments").
 		Where("user_id = ?", userID).
-		Order("created_at DESC").
+		Order(sort.OrderBy()).
+		Order("id").
 		Limit(limit).
 		Offset(offset).
 		Find(&orders).Error
@@ -221,9 +234,11 @@ func (r *orderRepository) MarkAsCancelled(ctx context.Context, id uuid.UUID, rea
 		}).Error
 }
 
-// Delete deletes an order by ID
-func (r *orderRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.getDB(ctx).WithContext(ctx).Delete(&models.Order{}, id).Error
+// Delete soft-deletes an order by ID. It reports whether the order was marked: false
+// when it was already deleted or does not exist.
+func (r *orderRepository) Delete(ctx context.Context, id uuid.UUID, mark softdelete.Mark) (bool, error) {
+	n, err := gormsoft.Delete(r.getDB(ctx).WithContext(ctx).Model(&models.Order{}).Where("id = ?", id), mark)
+	return n > 0, err
 }
 
 // GetOrderStats retrieves aggregated order statistics
@@ -239,7 +254,7 @@ func (r *orderRepository) GetOrderStats(ctx context.Context, userID *uuid.UUID,
 	}
 
 	// Build query with optional filters
-	query := r.getDB(ctx).WithContext(ctx).Model(&models.Order{})
+	query := r.reader(ctx).Model(&models.Order{})
 
 	if userID != nil {
 		query = query.Where("user_id = ?", *userID)
@@ -7,6 +7,7 @@ import (
 
 	"gorm.io/gorm"
 
+	"github.com/ductan2/microservice-app/shared/dbreplica"
 	"github.com/google/uuid"
 	"order-services/internal/models"
 )
@@ -185,8 +186,8 @@ func (r *paymentRepository) GetPaymentsByUserID(ctx context.Context, userID uuid
 func (r *paymentRepository) GetPaymentStats(ctx context.Context, userID *uuid.UUID, timeRange *TimeRange) (*PaymentStats, error) {
 	var stats PaymentStats
 
-	// Build base query
-	baseQuery := r.db.WithContext(ctx).Model(&models.Payment{}).
+	// Build base query, on a replica when the service has some
+	baseQuery := dbreplica.Read(r.db.WithContext(ctx)).Model(&models.Payment{}).
 		Joins("JOIN orders ON payments.order_id = orders.id")
 
 	if userID != nil {
@@ -48,8 +48,8 @@ func NewRefundRepository(db *sql.DB) RefundRepository {
 // Create creates a new refund request
 func (r *refundRepository) Create(ctx context.Context, refund *models.RefundRequest) error {
 	query := `
-		INSERT INTO refund_requests (order_id, user_id, amount, reason, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO refund_requests (order_id, user_id, amount, reason, reason_category, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id`
 
 	err := r.db.QueryRowContext(ctx, query,
@@ -57,6 +57,7 @@ func (r *refundRepository) Create(ctx context.Context, refund *models.RefundRequ
 		refund.UserID,
 		refund.Amount,
 		refund.Reason,
+		refund.ReasonCategory,
 		refund.Status,
 		time.Now(),
 		time.Now(),
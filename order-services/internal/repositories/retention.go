@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/retention"
+)
+
+// RetentionRules are how long the service keeps its rows, enforced by the
+// retention.purge job. RETENTION_OVERRIDES changes their ages or turns them off.
+func RetentionRules() []retention.Rule {
+	return []retention.Rule{
+		// Stripe retries a webhook for 3 days; processed events are only kept to
+		// investigate payments
+		{Name: "webhook_events", Table: "webhook_events", Column: "created_at", MaxAge: 90 * 24 * time.Hour, Where: "processed"},
+	}
+}
@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ductan2/microservice-app/shared/saga"
+	"github.com/ductan2/microservice-app/shared/saga/gormstore"
+	"gorm.io/gorm"
+)
+
+// SagaRepository interface for saga data access
+type SagaRepository interface {
+	// Start adds a saga, inside the transaction started by WithTx of another repository
+	// when ctx carries one. A saga already started for the aggregate is left as it is.
+	Start(ctx context.Context, inst *saga.Instance) error
+	// Store exposes the sagas table to the orchestrator that runs them
+	Store() saga.Store
+}
+
+// sagaRepository implements SagaRepository on the shared saga store
+type sagaRepository struct {
+	store *gormstore.Store
+}
+
+// NewSagaRepository creates a new saga repository
+func NewSagaRepository(db *gorm.DB) SagaRepository {
+	return &sagaRepository{
+		store: gormstore.New(db, gormstore.DefaultTable),
+	}
+}
+
+// Start creates a saga
+func (r *sagaRepository) Start(ctx context.Context, inst *saga.Instance) error {
+	store := r.store
+	if tx, ok := ctx.Value("tx").(*gorm.DB); ok {
+		store = store.WithTx(tx)
+	}
+
+	if err := store.Create(ctx, inst); err != nil && !errors.Is(err, saga.ErrExists) {
+		return err
+	}
+	return nil
+}
+
+// Store returns the saga store
+func (r *sagaRepository) Store() saga.Store {
+	return r.store
+}
@@ -9,6 +9,13 @@ import (
 	"order-services/internal/models"
 )
 
+// dbQuerier is an interface that both *sql.DB and *sql.Tx implement
+type dbQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
 // WebhookEventRepository interface for webhook event data access
 type WebhookEventRepository interface {
 	Create(ctx context.Context, event *models.WebhookEvent) error
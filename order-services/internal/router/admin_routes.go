@@ -9,9 +9,20 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func registerAdminRoutes(group *gin.RouterGroup, orderCtrl *controllers.OrderController, couponCtrl *controllers.CouponController) {
+func registerAdminRoutes(group *gin.RouterGroup, orderCtrl *controllers.OrderController, couponCtrl *controllers.CouponController, sagaCtrl *controllers.SagaController) {
 	registerAdminOrderRoutes(group, orderCtrl)
 	registerAdminCouponRoutes(group, couponCtrl)
+	registerAdminSagaRoutes(group, sagaCtrl)
+}
+
+func registerAdminSagaRoutes(group *gin.RouterGroup, ctrl *controllers.SagaController) {
+	if ctrl == nil {
+		return
+	}
+	group.GET("/sagas", ctrl.ListSagas)
+	group.GET("/sagas/:id", ctrl.GetSaga)
+	group.POST("/sagas/:id/retry", ctrl.RetrySaga)
+	group.POST("/sagas/:id/compensate", ctrl.CompensateSaga)
 }
 
 func registerAdminOrderRoutes(group *gin.RouterGroup, ctrl *controllers.OrderController) {
@@ -50,6 +61,7 @@ func registerAdminCouponRoutes(group *gin.RouterGroup, ctrl *controllers.CouponC
 		group.POST("/coupons", ctrl.CreateCoupon)
 		group.PUT("/coupons/:id", ctrl.UpdateCoupon)
 		group.DELETE("/coupons/:id", ctrl.DeleteCoupon)
+		group.POST("/coupons/:id/restore", ctrl.RestoreCoupon)
 		group.GET("/coupons/stats", ctrl.GetCouponStats)
 		group.POST("/coupons/bulk", ctrl.CreateBulkCoupons)
 		return
@@ -64,6 +76,9 @@ func registerAdminCouponRoutes(group *gin.RouterGroup, ctrl *controllers.CouponC
 	group.DELETE("/coupons/:id", func(c *gin.Context) {
 		utils.ErrorResponse(c, http.StatusNotImplemented, "NOT_IMPLEMENTED", "Delete coupon endpoint - implementation pending")
 	})
+	group.POST("/coupons/:id/restore", func(c *gin.Context) {
+		utils.ErrorResponse(c, http.StatusNotImplemented, "NOT_IMPLEMENTED", "Restore coupon endpoint - implementation pending")
+	})
 	group.GET("/coupons/stats", func(c *gin.Context) {
 		utils.SuccessResponse(c, http.StatusOK, gin.H{
 			"total_coupons":         0,
@@ -2,31 +2,18 @@ package router
 
 import (
 	"net/http"
-	"time"
-
-	"order-services/internal/controllers"
-	"order-services/pkg/utils"
 
+	"github.com/ductan2/microservice-app/shared/health"
 	"github.com/gin-gonic/gin"
 )
 
-func registerHealthRoutes(r *gin.Engine, orderCtrl *controllers.OrderController) {
-	if orderCtrl != nil {
-		r.GET("/health", orderCtrl.Health)
-		return
-	}
-
+// registerHealthRoutes serves /livez, /readyz and /healthz from checker, and /health for
+// the clients predating them.
+func registerHealthRoutes(r *gin.Engine, checker *health.Checker) {
 	r.GET("/health", func(ctx *gin.Context) {
-		utils.SuccessResponse(ctx, http.StatusOK, gin.H{
-			"status":    "healthy",
-			"timestamp": time.Now(),
-			"version":   "1.0.0",
-			"service":   "order-services",
-			"checks": gin.H{
-				"database": "unknown",
-				"redis":    "unknown",
-				"rabbitmq": "unknown",
-			},
-		})
+		ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
+	r.GET(health.LivePath, gin.WrapH(checker.Live()))
+	r.GET(health.ReadyPath, gin.WrapH(checker.Ready()))
+	r.GET(health.HealthPath, gin.WrapH(checker.Health()))
 }
@@ -2,17 +2,39 @@ package router
 
 import (
 	"net/http"
+	"time"
 
 	"order-services/internal/controllers"
+	"order-services/internal/middleware"
 	"order-services/pkg/utils"
 
+	"github.com/ductan2/microservice-app/shared/ratelimit"
 	"github.com/gin-gonic/gin"
 )
 
-func registerPaymentRoutes(group *gin.RouterGroup, ctrl *controllers.PaymentController) {
+// Payment calls reach Stripe, so each user gets a small budget of them. RATE_LIMITS
+// overrides the policies by name.
+var (
+	paymentIntentPolicy = ratelimit.Policy{
+		Name:      "payments.intent",
+		Algorithm: ratelimit.TokenBucket,
+		Limit:     5,
+		Window:    time.Minute,
+		Burst:     3,
+	}
+	paymentConfirmPolicy = ratelimit.Policy{
+		Name:      "payments.confirm",
+		Algorithm: ratelimit.TokenBucket,
+		Limit:     10,
+		Window:    time.Minute,
+		Burst:     5,
+	}
+)
+
+func registerPaymentRoutes(group *gin.RouterGroup, ctrl *controllers.PaymentController, limiter *ratelimit.Limiter) {
 	if ctrl != nil {
-		group.POST("/orders/:id/pay", ctrl.CreatePaymentIntent)
-		group.POST("/payments/:payment_intent_id/confirm", ctrl.ConfirmPayment)
+		group.POST("/orders/:id/pay", middleware.RateLimit(limiter, paymentIntentPolicy), ctrl.CreatePaymentIntent)
+		group.POST("/payments/:payment_intent_id/confirm", middleware.RateLimit(limiter, paymentConfirmPolicy), ctrl.ConfirmPayment)
 		group.GET("/payments/:payment_intent_id", ctrl.GetPayment)
 		group.GET("/orders/:id/payment", ctrl.GetPaymentByOrderID)
 		group.GET("/payment-methods", ctrl.GetPaymentMethods)
@@ -0,0 +1,16 @@
+package router
+
+import (
+	"order-services/internal/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func registerRefundRoutes(group *gin.RouterGroup, ctrl *controllers.RefundController) {
+	if ctrl == nil {
+		return
+	}
+
+	group.POST("/orders/:id/refund", ctrl.RequestRefund)
+	group.GET("/refunds", ctrl.ListRefunds)
+}
@@ -1,9 +1,16 @@
 package router
 
 import (
+	"log/slog"
+
 	"order-services/internal/controllers"
 	"order-services/internal/middleware"
 
+	"github.com/ductan2/microservice-app/shared/health"
+	"github.com/ductan2/microservice-app/shared/internalauth"
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/ductan2/microservice-app/shared/ratelimit"
+	"github.com/ductan2/microservice-app/shared/requestmeta"
 	"github.com/gin-gonic/gin"
 )
 
@@ -12,21 +19,43 @@ type Dependencies struct {
 	OrderController   *controllers.OrderController
 	PaymentController *controllers.PaymentController
 	CouponController  *controllers.CouponController
+	SagaController    *controllers.SagaController
+	RefundController  *controllers.RefundController
 	JWTSecret         string
+	// ServiceVerifier checks the service tokens of calls from other services
+	ServiceVerifier *internalauth.Verifier
+	// Health serves /livez, /readyz and /healthz
+	Health *health.Checker
+	// RateLimiter limits the payment routes; nil turns the limits off
+	RateLimiter *ratelimit.Limiter
+	// TrustedProxies are the networks whose X-Forwarded-For is trusted
+	TrustedProxies requestmeta.Proxies
 }
 
 // NewRouter initializes the Gin router with all routes and middleware.
 func NewRouter(deps Dependencies) *gin.Engine {
 	r := gin.New()
+	// Handlers pass the gin.Context on as a context.Context; let it reach the request
+	// context, where the trace is stored
+	r.ContextWithFallback = true
+	// Context.ClientIP, which keys the rate limits, reads X-Forwarded-For past the trusted
+	// proxies only
+	if err := deps.TrustedProxies.Configure(r); err != nil {
+		slog.Warn("trusted proxies not set", "error", err)
+	}
 
 	// Global middleware
+	r.Use(middleware.Tracing())
+	r.Use(middleware.Metrics())
 	r.Use(middleware.Logging())
 	r.Use(gin.Recovery())
 	r.Use(middleware.CORS())
-	r.Use(middleware.RequestID())
+	r.Use(middleware.RequestMeta(deps.TrustedProxies))
+	r.Use(middleware.ServiceAuth(deps.ServiceVerifier))
 
-	// Health route
-	registerHealthRoutes(r, deps.OrderController)
+	// Health routes
+	registerHealthRoutes(r, deps.Health)
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
 
 	// API v1 routes
 	v1 := r.Group("/api/v1")
@@ -39,13 +68,14 @@ func NewRouter(deps Dependencies) *gin.Engine {
 	protected.Use(middleware.JWTAuth(deps.JWTSecret))
 
 	registerOrderRoutes(protected, deps.OrderController)
-	registerPaymentRoutes(protected, deps.PaymentController)
+	registerPaymentRoutes(protected, deps.PaymentController, deps.RateLimiter)
 	registerCouponRoutes(protected, deps.CouponController)
+	registerRefundRoutes(protected, deps.RefundController)
 
 	// Admin routes
 	admin := protected.Group("/admin")
 	admin.Use(middleware.AdminOnly())
-	registerAdminRoutes(admin, deps.OrderController, deps.CouponController)
+	registerAdminRoutes(admin, deps.OrderController, deps.CouponController, deps.SagaController)
 
 	return r
 }
@@ -2,26 +2,30 @@ package services
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"time"
 
+	"github.com/ductan2/microservice-app/shared/apperr"
+	"github.com/ductan2/microservice-app/shared/money"
+	"github.com/ductan2/microservice-app/shared/softdelete"
 	"github.com/google/uuid"
 
+	"order-services/internal/dto"
 	"order-services/internal/models"
 	"order-services/internal/repositories"
 )
 
 var (
-	ErrCouponNotFound      = errors.New("coupon not found")
-	ErrCouponExpired       = errors.New("coupon has expired")
-	ErrCouponInactive      = errors.New("coupon is not active")
-	ErrCouponNotStarted    = errors.New("coupon has not started yet")
-	ErrCouponUsageExceeded = errors.New("coupon usage limit exceeded")
-	ErrUserUsageExceeded   = errors.New("user has exceeded coupon usage limit")
-	ErrMinimumAmountNotMet = errors.New("minimum order amount not met")
-	ErrFirstTimeOnly       = errors.New("coupon is for first-time customers only")
-	ErrCourseNotApplicable = errors.New("coupon not applicable to this course")
+	ErrCouponNotFound      = apperr.New(apperr.NotFound, "coupon not found").WithReason(dto.ErrCodeCouponNotFound)
+	ErrCouponExpired       = apperr.New(apperr.BadRequest, "coupon has expired").WithReason(dto.ErrCodeCouponExpired)
+	ErrCouponInactive      = apperr.New(apperr.BadRequest, "coupon is not active").WithReason(dto.ErrCodeCouponInactive)
+	ErrCouponNotStarted    = apperr.New(apperr.BadRequest, "coupon has not started yet").WithReason(dto.ErrCodeCouponNotStarted)
+	ErrCouponUsageExceeded = apperr.New(apperr.BadRequest, "coupon usage limit exceeded").WithReason(dto.ErrCodeCouponUsageExceeded)
+	ErrUserUsageExceeded   = apperr.New(apperr.BadRequest, "user has exceeded coupon usage limit").WithReason(dto.ErrCodeUserUsageExceeded)
+	ErrMinimumAmountNotMet = apperr.New(apperr.BadRequest, "minimum order amount not met").WithReason(dto.ErrCodeMinimumAmountNotMet)
+	ErrFirstTimeOnly       = apperr.New(apperr.BadRequest, "coupon is for first-time customers only").WithReason(dto.ErrCodeFirstTimeOnly)
+	ErrCourseNotApplicable = apperr.New(apperr.BadRequest, "coupon not applicable to this course").WithReason(dto.ErrCodeCourseNotApplicable)
+	ErrCouponCodeTaken     = apperr.New(apperr.Conflict, "another coupon uses this code").WithReason(dto.ErrCodeCouponCodeTaken)
 )
 
 // CouponService defines the business logic interface for coupon management
@@ -33,6 +37,11 @@ type CouponService interface {
 	CalculateDiscount(ctx context.Context, coupon *models.Coupon, orderAmount int64) (int64, error)
 	ListAvailableCoupons(ctx context.Context, userID uuid.UUID) ([]models.Coupon, error)
 	GetCoupon(ctx context.Context, couponID uuid.UUID) (*models.Coupon, error)
+	// DeleteCoupon soft-deletes a coupon, which can no longer be redeemed; deleting it
+	// again keeps the first deletion. deletedBy is the admin's user ID.
+	DeleteCoupon(ctx context.Context, couponID uuid.UUID, deletedBy string) error
+	// RestoreCoupon restores a deleted coupon, unless a newer coupon took its code.
+	RestoreCoupon(ctx context.Context, couponID uuid.UUID) (*models.Coupon, error)
 }
 
 // couponService implements the coupon business logic
@@ -138,37 +147,18 @@ func (s *couponService) CheckCouponAvailability(ctx context.Context, couponID uu
 	return nil
 }
 
-// CalculateDiscount calculates the discount amount for a coupon
+// CalculateDiscount calculates the discount amount for a coupon on an order amount in
+// models.DefaultCurrency
 func (s *couponService) CalculateDiscount(ctx context.Context, coupon *models.Coupon, orderAmount int64) (int64, error) {
 	if coupon == nil {
 		return 0, ErrCouponNotFound
 	}
 
-	var discountAmount int64
-
-	switch coupon.Type {
-	case models.CouponTypePercentage:
-		if coupon.PercentOff == nil {
-			return 0, fmt.Errorf("percentage coupon missing percent_off value")
-		}
-		discountAmount = (orderAmount * int64(*coupon.PercentOff)) / 100
-
-	case models.CouponTypeFixedAmount:
-		if coupon.AmountOff == nil {
-			return 0, fmt.Errorf("fixed amount coupon missing amount_off value")
-		}
-		discountAmount = *coupon.AmountOff
-
-	default:
-		return 0, fmt.Errorf("unknown coupon type: %s", coupon.Type)
-	}
-
-	// Ensure discount doesn't exceed order amount
-	if discountAmount > orderAmount {
-		discountAmount = orderAmount
+	discount, err := coupon.Discount(money.New(orderAmount, models.DefaultCurrency))
+	if err != nil {
+		return 0, err
 	}
-
-	return discountAmount, nil
+	return discount.Amount(), nil
 }
 
 // ListAvailableCoupons returns a list of coupons currently available for a user
@@ -224,6 +214,51 @@ func (s *couponService) GetCoupon(ctx context.Context, couponID uuid.UUID) (*mod
 	return coupon, nil
 }
 
+// DeleteCoupon soft-deletes a coupon
+func (s *couponService) DeleteCoupon(ctx context.Context, couponID uuid.UUID, deletedBy string) error {
+	coupon, err := s.couponRepo.GetByIDInScope(ctx, couponID, softdelete.All)
+	if err != nil {
+		return fmt.Errorf("failed to get coupon: %w", err)
+	}
+	if coupon == nil {
+		return ErrCouponNotFound
+	}
+
+	if _, err := s.couponRepo.Delete(ctx, couponID, softdelete.Now(deletedBy)); err != nil {
+		return fmt.Errorf("failed to delete coupon: %w", err)
+	}
+	return nil
+}
+
+// RestoreCoupon restores a soft-deleted coupon
+func (s *couponService) RestoreCoupon(ctx context.Context, couponID uuid.UUID) (*models.Coupon, error) {
+	coupon, err := s.couponRepo.GetByIDInScope(ctx, couponID, softdelete.All)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get coupon: %w", err)
+	}
+	if coupon == nil {
+		return nil, ErrCouponNotFound
+	}
+	if !coupon.IsDeleted() {
+		return coupon, nil
+	}
+
+	// Deleting the coupon freed its code
+	taken, err := s.couponRepo.GetByCode(ctx, coupon.Code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get coupon: %w", err)
+	}
+	if taken != nil {
+		return nil, ErrCouponCodeTaken
+	}
+
+	if _, err := s.couponRepo.Restore(ctx, couponID); err != nil {
+		return nil, fmt.Errorf("failed to restore coupon: %w", err)
+	}
+	coupon.Clear()
+	return coupon, nil
+}
+
 // Helper methods
 
 // validateUserRestrictions validates user-specific coupon restrictions
@@ -5,10 +5,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 
+	"github.com/ductan2/microservice-app/shared/internalauth"
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/ductan2/microservice-app/shared/telemetry"
 	"github.com/google/uuid"
 
 	"order-services/internal/config"
@@ -18,6 +21,8 @@ import (
 // EnrollmentService handles integration with the lesson enrollment service
 type EnrollmentService interface {
 	CreateEnrollmentsForPaidOrder(ctx context.Context, order *models.Order) error
+	// RevokeEnrollmentsForOrder cancels the enrollments created for an order
+	RevokeEnrollmentsForOrder(ctx context.Context, orderID uuid.UUID) error
 	GetUserEnrollments(ctx context.Context, userID uuid.UUID) ([]Enrollment, error)
 	CheckExistingEnrollment(ctx context.Context, userID, courseID uuid.UUID) (bool, error)
 }
@@ -41,11 +46,12 @@ type enrollmentService struct {
 }
 
 // NewEnrollmentService creates a new enrollment service instance
-func NewEnrollmentService(config *config.Config) EnrollmentService {
+func NewEnrollmentService(config *config.Config, signer *internalauth.Signer) EnrollmentService {
 	return &enrollmentService{
 		baseURL: getEnrollmentServiceURL(config),
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: internalauth.NewTransport(signer, "lesson-services", metrics.NewTransport("lesson-services", telemetry.NewTransport(nil))),
 		},
 		config: config,
 	}
@@ -71,11 +77,11 @@ func (s *enrollmentService) CreateEnrollmentsForPaidOrder(ctx context.Context, o
 		// Check if user is already enrolled
 		alreadyEnrolled, err := s.CheckExistingEnrollment(ctx, order.UserID, item.CourseID)
 		if err != nil {
-			log.Printf("Warning: Failed to check existing enrollment for user %s, course %s: %v",
-				order.UserID, item.CourseID, err)
+			slog.WarnContext(ctx, "failed to check existing enrollment",
+				"user_id", order.UserID, "course_id", item.CourseID, "error", err)
 			// Continue with enrollment attempt
 		} else if alreadyEnrolled {
-			log.Printf("Info: User %s already enrolled in course %s", order.UserID, item.CourseID)
+			slog.InfoContext(ctx, "user already enrolled in course", "user_id", order.UserID, "course_id", item.CourseID)
 			continue // Skip existing enrollment
 		}
 
@@ -88,7 +94,7 @@ func (s *enrollmentService) CreateEnrollmentsForPaidOrder(ctx context.Context, o
 	}
 
 	if len(enrollmentRequests) == 0 {
-		log.Printf("Info: No new enrollments to create for order %s", order.ID)
+		slog.InfoContext(ctx, "no new enrollments to create", "order_id", order.ID)
 		return nil
 	}
 
@@ -100,6 +106,29 @@ func (s *enrollmentService) CreateEnrollmentsForPaidOrder(ctx context.Context, o
 	return s.createBatchEnrollments(ctx, batchRequest)
 }
 
+// RevokeEnrollmentsForOrder cancels the enrollments of an order; an order without
+// enrollments is not an error
+func (s *enrollmentService) RevokeEnrollmentsForOrder(ctx context.Context, orderID uuid.UUID) error {
+	url := fmt.Sprintf("%s/api/v1/enrollments/order/%s", s.baseURL, orderID.String())
+
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to revoke enrollments: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	}
+	return fmt.Errorf("enrollment service returned status: %d", resp.StatusCode)
+}
+
 // GetUserEnrollments retrieves all enrollments for a user
 func (s *enrollmentService) GetUserEnrollments(ctx context.Context, userID uuid.UUID) ([]Enrollment, error) {
 	url := fmt.Sprintf("%s/api/v1/enrollments/user/%s", s.baseURL, userID.String())
@@ -110,8 +139,6 @@ func (s *enrollmentService) GetUserEnrollments(ctx context.Context, userID uuid.
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Internal-Service", "order-service")
-	req.Header.Set("Authorization", "Bearer "+s.getInternalAuthToken())
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
@@ -155,8 +182,6 @@ func (s *enrollmentService) CheckExistingEnrollment(ctx context.Context, userID,
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Internal-Service", "order-service")
-	req.Header.Set("Authorization", "Bearer "+s.getInternalAuthToken())
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
@@ -220,8 +245,6 @@ func (s *enrollmentService) createBatchEnrollments(ctx context.Context, request
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Internal-Service", "order-service")
-	req.Header.Set("Authorization", "Bearer "+s.getInternalAuthToken())
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
@@ -236,12 +259,6 @@ func (s *enrollmentService) createBatchEnrollments(ctx context.Context, request
 	return nil
 }
 
-func (s *enrollmentService) getInternalAuthToken() string {
-	// In production, this would use proper internal service authentication
-	// For now, return a mock token
-	return "internal-service-token"
-}
-
 func getEnrollmentServiceURL(config *config.Config) string {
 	// In production, this would come from environment variables or service discovery
 	// For now, return a default URL
@@ -270,13 +287,19 @@ func (s *MockEnrollmentService) CreateEnrollmentsForPaidOrder(ctx context.Contex
 		if item.ItemType == models.OrderItemTypeCourse {
 			key := fmt.Sprintf("%s:%s", order.UserID, item.CourseID)
 			s.enrollments[key] = true
-			log.Printf("Mock: Created enrollment for user %s in course %s", order.UserID, item.CourseID)
+			slog.InfoContext(ctx, "mock: created enrollment", "user_id", order.UserID, "course_id", item.CourseID)
 		}
 	}
 
 	return nil
 }
 
+// RevokeEnrollmentsForOrder cancels enrollments of an order (mock implementation)
+func (s *MockEnrollmentService) RevokeEnrollmentsForOrder(ctx context.Context, orderID uuid.UUID) error {
+	slog.InfoContext(ctx, "mock: revoked enrollments", "order_id", orderID)
+	return nil
+}
+
 // GetUserEnrollments retrieves enrollments for a user (mock implementation)
 func (s *MockEnrollmentService) GetUserEnrollments(ctx context.Context, userID uuid.UUID) ([]Enrollment, error) {
 	var enrollments []Enrollment
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/consumer"
+	"github.com/ductan2/microservice-app/shared/events"
+	"github.com/ductan2/microservice-app/shared/internalauth"
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/ductan2/microservice-app/shared/telemetry"
+
+	"order-services/internal/config"
+	"order-services/internal/repositories"
+)
+
+const (
+	// ErasureConsumer names the erasure consumer in the inbox
+	ErasureConsumer = "order-services.erasures"
+	// erasureServiceName is the name user-services waits for in ERASURE_SERVICES
+	erasureServiceName = "orders"
+)
+
+// ErasureService scrubs the orders of erased users and confirms it to user-services
+type ErasureService interface {
+	// HandleErasureRequested handles a user.erasure_requested event. Payloads that do not
+	// decode are dead-lettered; database and callback failures are retried.
+	HandleErasureRequested(ctx context.Context, msg consumer.Message) error
+}
+
+// erasureService implements ErasureService
+type erasureService struct {
+	erasureRepo repositories.ErasureRepository
+	baseURL     string
+	httpClient  *http.Client
+}
+
+// NewErasureService creates a new erasure service instance
+func NewErasureService(erasureRepo repositories.ErasureRepository, cfg *config.Config, signer *internalauth.Signer) ErasureService {
+	return &erasureService{
+		erasureRepo: erasureRepo,
+		baseURL:     strings.TrimRight(cfg.UserServiceURL, "/"),
+		httpClient: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: internalauth.NewTransport(signer, "user-services", metrics.NewTransport("user-services", telemetry.NewTransport(nil))),
+		},
+	}
+}
+
+// erasureAck is the confirmation posted to the callback of an erasure
+type erasureAck struct {
+	Service         string `json:"service"`
+	Status          string `json:"status"`
+	RecordsAffected int64  `json:"records_affected"`
+	Detail          string `json:"detail,omitempty"`
+}
+
+// HandleErasureRequested scrubs the orders before confirming them, so a confirmation that
+// fails is retried with the scrub, which changes nothing the second time
+func (s *erasureService) HandleErasureRequested(ctx context.Context, msg consumer.Message) error {
+	var event events.UserErasureRequested
+	if err := events.Unmarshal(msg.Body, &event); err != nil {
+		return consumer.Permanent(err)
+	}
+	if !event.Requests(erasureServiceName) {
+		return nil
+	}
+
+	affected, err := s.erasureRepo.Scrub(ctx, event.UserID, event.EmailSHA256)
+	if err != nil {
+		return err
+	}
+	if err := s.acknowledge(ctx, event.CallbackPath, erasureAck{
+		Service:         erasureServiceName,
+		Status:          "completed",
+		RecordsAffected: affected,
+	}); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "erased user data", "erasure_id", event.ErasureID, "user_id", event.UserID, "records_affected", affected)
+	return nil
+}
+
+// acknowledge posts ack to the callback path of the erasure on user-services. A refused
+// confirmation is permanent; it would be refused again.
+func (s *erasureService) acknowledge(ctx context.Context, callbackPath string, ack erasureAck) error {
+	body, err := json.Marshal(ack)
+	if err != nil {
+		return fmt.Errorf("failed to marshal erasure ack: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+callbackPath, bytes.NewReader(body))
+	if err != nil {
+		return consumer.Permanent(fmt.Errorf("failed to create request: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send erasure ack: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	err = fmt.Errorf("erasure ack returned status %d: %s", resp.StatusCode, detail)
+	switch resp.StatusCode {
+	case http.StatusBadRequest, http.StatusNotFound, http.StatusConflict:
+		return consumer.Permanent(err)
+	}
+	return err
+}
@@ -5,10 +5,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 
+	"github.com/ductan2/microservice-app/shared/internalauth"
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/ductan2/microservice-app/shared/money"
+	"github.com/ductan2/microservice-app/shared/telemetry"
 	"github.com/google/uuid"
 	"github.com/stripe/stripe-go/v78"
 
@@ -20,6 +24,7 @@ import (
 type NotificationService interface {
 	SendOrderConfirmation(ctx context.Context, order *models.Order) error
 	SendPaymentConfirmation(ctx context.Context, order *models.Order, payment *models.Payment) error
+	SendCourseWelcome(ctx context.Context, order *models.Order) error
 	SendOrderCancelled(ctx context.Context, order *models.Order, reason string) error
 	SendPaymentFailed(ctx context.Context, order *models.Order, payment *models.Payment, reason string) error
 	SendCouponRedeemed(ctx context.Context, userID uuid.UUID, coupon *models.Coupon, orderID uuid.UUID) error
@@ -38,11 +43,12 @@ type notificationService struct {
 }
 
 // NewNotificationService creates a new notification service instance
-func NewNotificationService(config *config.Config) NotificationService {
+func NewNotificationService(config *config.Config, signer *internalauth.Signer) NotificationService {
 	return &notificationService{
 		baseURL: getNotificationServiceURL(config),
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: internalauth.NewTransport(signer, "notification-services", metrics.NewTransport("notification-services", telemetry.NewTransport(nil))),
 		},
 		config: config,
 	}
@@ -51,10 +57,10 @@ func NewNotificationService(config *config.Config) NotificationService {
 // SendOrderConfirmation sends an order confirmation notification
 func (s *notificationService) SendOrderConfirmation(ctx context.Context, order *models.Order) error {
 	notification := NotificationRequest{
-		UserID:  order.ID, // Use order ID as notification user identifier
+		UserID:  order.UserID,
 		Type:    "order_confirmation",
 		Title:   "Order Confirmation",
-		Message: fmt.Sprintf("Your order #%s has been created successfully. Total: $%.2f", order.ID.String(), float64(order.TotalAmount)/100),
+		Message: fmt.Sprintf("Your order #%s has been created successfully. Total: %s", order.ID.String(), order.Total()),
 		Data: map[string]interface{}{
 			"order_id":     order.ID,
 			"total_amount": order.TotalAmount,
@@ -75,7 +81,7 @@ func (s *notificationService) SendPaymentConfirmation(ctx context.Context, order
 		UserID:  order.UserID,
 		Type:    "payment_confirmation",
 		Title:   "Payment Successful",
-		Message: fmt.Sprintf("Payment of $%.2f for order #%s has been processed successfully", float64(payment.Amount)/100, order.ID.String()),
+		Message: fmt.Sprintf("Payment of %s for order #%s has been processed successfully", payment.Total(), order.ID.String()),
 		Data: map[string]interface{}{
 			"order_id":          order.ID,
 			"payment_id":        payment.ID,
@@ -92,6 +98,36 @@ func (s *notificationService) SendPaymentConfirmation(ctx context.Context, order
 	return s.sendNotification(ctx, notification)
 }
 
+// SendCourseWelcome welcomes the buyer of a paid order to the courses they enrolled in
+func (s *notificationService) SendCourseWelcome(ctx context.Context, order *models.Order) error {
+	courses := make([]map[string]interface{}, 0, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		if item.ItemType != models.OrderItemTypeCourse {
+			continue
+		}
+		courses = append(courses, map[string]interface{}{
+			"course_id":       item.CourseID,
+			"course_title":    item.CourseTitle,
+			"instructor_name": item.InstructorName,
+		})
+	}
+
+	notification := NotificationRequest{
+		UserID:  order.UserID,
+		Type:    "course_welcome",
+		Title:   "Welcome to your new course",
+		Message: fmt.Sprintf("You are enrolled in the courses of order #%s. Happy learning!", order.ID.String()),
+		Data: map[string]interface{}{
+			"order_id": order.ID,
+			"courses":  courses,
+		},
+		Channels: []string{"email", "push"},
+		Priority: "normal",
+	}
+
+	return s.sendNotification(ctx, notification)
+}
+
 // SendOrderCancelled sends an order cancellation notification
 func (s *notificationService) SendOrderCancelled(ctx context.Context, order *models.Order, reason string) error {
 	notification := NotificationRequest{
@@ -142,7 +178,7 @@ func (s *notificationService) SendCouponRedeemed(ctx context.Context, userID uui
 	if coupon.Type == "percentage" && coupon.PercentOff != nil {
 		discountText = fmt.Sprintf("%d%% off", *coupon.PercentOff)
 	} else if coupon.Type == "fixed_amount" && coupon.AmountOff != nil {
-		discountText = fmt.Sprintf("$%.2f off", float64(*coupon.AmountOff)/100)
+		discountText = fmt.Sprintf("%s off", money.New(*coupon.AmountOff, models.CurrencyOf(coupon.Currency)))
 	}
 
 	notification := NotificationRequest{
@@ -158,7 +194,7 @@ func (s *notificationService) SendCouponRedeemed(ctx context.Context, userID uui
 			"order_id":    orderID,
 		},
 		Channels: []string{"email", "push"},
-		Priority: "normal",
+		Priority: "low", // gathered in the digest of users who have one
 	}
 
 	return s.sendNotification(ctx, notification)
@@ -205,12 +241,11 @@ func (s *notificationService) SendNewRefundRequest(ctx context.Context, refundRe
 
 // SendRefundProcessed notifies the user when a refund has been processed
 func (s *notificationService) SendRefundProcessed(ctx context.Context, refundRequest *models.RefundRequest, stripeRefund *stripe.Refund) error {
-	refundAmount := float64(refundRequest.Amount) / 100
 	notification := NotificationRequest{
 		UserID:  refundRequest.UserID,
 		Type:    "refund_processed",
 		Title:   "Refund Processed",
-		Message: fmt.Sprintf("Your refund for order %s has been processed. Amount: $%.2f", refundRequest.OrderID.String(), refundAmount),
+		Message: fmt.Sprintf("Your refund for order %s has been processed. Amount: %s", refundRequest.OrderID.String(), refundRequest.Total()),
 		Data: map[string]interface{}{
 			"refund_id":        refundRequest.ID,
 			"order_id":         refundRequest.OrderID,
@@ -281,7 +316,22 @@ type NotificationRequest struct {
 
 // Helper methods
 
+// eventNotifications are the notification types notification-services raises itself
+// from order.events when NOTIFICATIONS_VIA_EVENTS is set.
+var eventNotifications = map[string]bool{
+	"payment_confirmation": true,
+	"order_cancelled":      true,
+	"payment_failed":       true,
+	"refund_processed":     true,
+	"refund_rejected":      true,
+	"refund_completed":     true,
+}
+
 func (s *notificationService) sendNotification(ctx context.Context, notification NotificationRequest) error {
+	if s.config.NotificationsViaEvents && eventNotifications[notification.Type] {
+		return nil
+	}
+
 	url := fmt.Sprintf("%s/api/v1/notifications", s.baseURL)
 
 	// Marshal request body
@@ -296,8 +346,6 @@ func (s *notificationService) sendNotification(ctx context.Context, notification
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-Internal-Service", "order-service")
-	req.Header.Set("Authorization", "Bearer "+s.getInternalAuthToken())
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
@@ -309,22 +357,14 @@ func (s *notificationService) sendNotification(ctx context.Context, notification
 		return fmt.Errorf("notification service returned status: %d", resp.StatusCode)
 	}
 
-	log.Printf("Notification sent: type=%s, user_id=%s, title=%s",
-		notification.Type, notification.UserID, notification.Title)
+	slog.InfoContext(ctx, "notification sent",
+		"type", notification.Type, "user_id", notification.UserID, "title", notification.Title)
 
 	return nil
 }
 
-func (s *notificationService) getInternalAuthToken() string {
-	// In production, this would use proper internal service authentication
-	// For now, return a mock token
-	return "internal-service-token"
-}
-
 func getNotificationServiceURL(config *config.Config) string {
-	// In production, this would come from environment variables or service discovery
-	// For now, return a default URL
-	return "http://notification-services:8007"
+	return config.NotificationServiceURL
 }
 
 // MockNotificationService implements a mock notification service for testing
@@ -358,7 +398,7 @@ func (s *MockNotificationService) SendOrderConfirmation(ctx context.Context, ord
 		Message: fmt.Sprintf("Order #%s created", order.ID.String()),
 	}
 	s.notifications = append(s.notifications, notification)
-	log.Printf("Mock: Order confirmation sent for order %s", order.ID)
+	slog.InfoContext(ctx, "mock: order confirmation sent", "order_id", order.ID)
 	return nil
 }
 
@@ -368,10 +408,23 @@ func (s *MockNotificationService) SendPaymentConfirmation(ctx context.Context, o
 		UserID:  order.UserID,
 		Type:    "payment_confirmation",
 		Title:   "Payment Successful",
-		Message: fmt.Sprintf("Payment of $%.2f processed", float64(payment.Amount)/100),
+		Message: fmt.Sprintf("Payment of %s processed", payment.Total()),
+	}
+	s.notifications = append(s.notifications, notification)
+	slog.InfoContext(ctx, "mock: payment confirmation sent", "order_id", order.ID)
+	return nil
+}
+
+// SendCourseWelcome sends course welcome (mock implementation)
+func (s *MockNotificationService) SendCourseWelcome(ctx context.Context, order *models.Order) error {
+	notification := NotificationRequest{
+		UserID:  order.UserID,
+		Type:    "course_welcome",
+		Title:   "Welcome to your new course",
+		Message: fmt.Sprintf("Enrolled in the courses of order #%s", order.ID.String()),
 	}
 	s.notifications = append(s.notifications, notification)
-	log.Printf("Mock: Payment confirmation sent for order %s", order.ID)
+	slog.InfoContext(ctx, "mock: course welcome sent", "order_id", order.ID)
 	return nil
 }
 
@@ -384,7 +437,7 @@ func (s *MockNotificationService) SendOrderCancelled(ctx context.Context, order
 		Message: fmt.Sprintf("Order #%s cancelled: %s", order.ID.String(), reason),
 	}
 	s.notifications = append(s.notifications, notification)
-	log.Printf("Mock: Order cancellation sent for order %s", order.ID)
+	slog.InfoContext(ctx, "mock: order cancellation sent", "order_id", order.ID)
 	return nil
 }
 
@@ -397,7 +450,7 @@ func (s *MockNotificationService) SendPaymentFailed(ctx context.Context, order *
 		Message: fmt.Sprintf("Payment failed for order #%s: %s", order.ID.String(), reason),
 	}
 	s.notifications = append(s.notifications, notification)
-	log.Printf("Mock: Payment failure sent for order %s", order.ID)
+	slog.InfoContext(ctx, "mock: payment failure sent", "order_id", order.ID)
 	return nil
 }
 
@@ -410,7 +463,7 @@ func (s *MockNotificationService) SendCouponRedeemed(ctx context.Context, userID
 		Message: fmt.Sprintf("Coupon '%s' applied to order %s", coupon.Code, orderID.String()),
 	}
 	s.notifications = append(s.notifications, notification)
-	log.Printf("Mock: Coupon redemption sent for coupon %s", coupon.Code)
+	slog.InfoContext(ctx, "mock: coupon redemption sent", "coupon", coupon.Code)
 	return nil
 }
 
@@ -423,7 +476,7 @@ func (s *MockNotificationService) SendLowBalanceWarning(ctx context.Context, use
 		Message: "Your account balance is low",
 	}
 	s.notifications = append(s.notifications, notification)
-	log.Printf("Mock: Low balance warning sent for user %s", userID)
+	slog.InfoContext(ctx, "mock: low balance warning sent", "user_id", userID)
 	return nil
 }
 
@@ -436,7 +489,7 @@ func (s *MockNotificationService) SendNewRefundRequest(ctx context.Context, refu
 		Message: fmt.Sprintf("Refund request for order %s", refundRequest.OrderID.String()),
 	}
 	s.notifications = append(s.notifications, notification)
-	log.Printf("Mock: Refund request notification sent for refund %s", refundRequest.ID)
+	slog.InfoContext(ctx, "mock: refund request notification sent", "refund_id", refundRequest.ID)
 	return nil
 }
 
@@ -449,7 +502,7 @@ func (s *MockNotificationService) SendRefundProcessed(ctx context.Context, refun
 		Message: fmt.Sprintf("Refund processed for order %s", refundRequest.OrderID.String()),
 	}
 	s.notifications = append(s.notifications, notification)
-	log.Printf("Mock: Refund processed notification sent for refund %s", refundRequest.ID)
+	slog.InfoContext(ctx, "mock: refund processed notification sent", "refund_id", refundRequest.ID)
 	return nil
 }
 
@@ -462,7 +515,7 @@ func (s *MockNotificationService) SendRefundRejected(ctx context.Context, refund
 		Message: fmt.Sprintf("Refund rejected for order %s: %s", refundRequest.OrderID.String(), adminReason),
 	}
 	s.notifications = append(s.notifications, notification)
-	log.Printf("Mock: Refund rejected notification sent for refund %s", refundRequest.ID)
+	slog.InfoContext(ctx, "mock: refund rejected notification sent", "refund_id", refundRequest.ID)
 	return nil
 }
 
@@ -475,6 +528,6 @@ func (s *MockNotificationService) SendRefundCompleted(ctx context.Context, refun
 		Message: fmt.Sprintf("Refund completed for order %s", refundRequest.OrderID.String()),
 	}
 	s.notifications = append(s.notifications, notification)
-	log.Printf("Mock: Refund completed notification sent for refund %s", refundRequest.ID)
+	slog.InfoContext(ctx, "mock: refund completed notification sent", "refund_id", refundRequest.ID)
 	return nil
 }
@@ -3,34 +3,39 @@ package services
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
-	"errors"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"order-services/internal/config"
+	"order-services/internal/dto"
 	"order-services/internal/models"
 	"order-services/internal/repositories"
 
+	"github.com/ductan2/microservice-app/shared/apperr"
+	"github.com/ductan2/microservice-app/shared/events"
+	"github.com/ductan2/microservice-app/shared/flags"
+	"github.com/ductan2/microservice-app/shared/money"
+	"github.com/ductan2/microservice-app/shared/queryparams"
 	"github.com/google/uuid"
 )
 
 var (
-	ErrOrderNotFound      = errors.New("order not found")
-	ErrInvalidOrderStatus = errors.New("invalid order status")
-	ErrOrderExpired       = errors.New("order has expired")
-	ErrEmptyOrder         = errors.New("order must contain at least one item")
-	ErrUnauthorizedOrder  = errors.New("unauthorized to access this order")
-	ErrInvalidCourse      = errors.New("invalid course data")
-	ErrPaymentRequired    = errors.New("payment required for this operation")
-	ErrInvalidCoupon      = errors.New("invalid coupon")
+	ErrOrderNotFound      = apperr.New(apperr.NotFound, "order not found").WithReason(dto.ErrCodeOrderNotFound)
+	ErrInvalidOrderStatus = apperr.New(apperr.Conflict, "invalid order status").WithReason(dto.ErrCodeInvalidOrderStatus)
+	ErrOrderExpired       = apperr.New(apperr.Conflict, "order has expired").WithReason(dto.ErrCodeOrderExpired)
+	ErrEmptyOrder         = apperr.New(apperr.BadRequest, "order must contain at least one item").WithReason(dto.ErrCodeEmptyOrder)
+	ErrUnauthorizedOrder  = apperr.New(apperr.Forbidden, "unauthorized to access this order")
+	ErrInvalidCourse      = apperr.New(apperr.BadRequest, "invalid course data").WithReason(dto.ErrCodeInvalidCourse)
+	ErrPaymentRequired    = apperr.New(apperr.Conflict, "payment required for this operation").WithReason(dto.ErrCodePaymentRequired)
+	ErrInvalidCoupon      = apperr.New(apperr.BadRequest, "invalid coupon").WithReason(dto.ErrCodeInvalidCoupon)
 )
 
 // OrderService defines the business logic interface for order management
 type OrderService interface {
 	CreateOrder(ctx context.Context, req *CreateOrderRequest) (*models.Order, error)
 	GetOrder(ctx context.Context, orderID, userID uuid.UUID) (*models.Order, error)
-	ListUserOrders(ctx context.Context, userID uuid.UUID, limit, offset int) ([]models.Order, int64, error)
+	ListUserOrders(ctx context.Context, userID uuid.UUID, sort queryparams.Sort, limit, offset int) ([]models.Order, int64, error)
 	CancelOrder(ctx context.Context, orderID, userID uuid.UUID, reason string) error
 	UpdateOrderStatus(ctx context.Context, orderID uuid.UUID, status string, reason string) error
 	ProcessExpiredOrders(ctx context.Context) error
@@ -61,9 +66,16 @@ type orderService struct {
 	couponRepo    repositories.CouponRepository
 	courseRepo    repositories.CourseRepository
 	outboxRepo    repositories.OutboxRepository
+	flags         *flags.Client
 	config        *config.Config
 }
 
+// Checkout flows recorded in the metadata of each order while the new flow rolls out
+const (
+	checkoutFlowLegacy = "legacy"
+	checkoutFlowNew    = "new"
+)
+
 // NewOrderService creates a new order service instance
 func NewOrderService(
 	orderRepo repositories.OrderRepository,
@@ -71,6 +83,7 @@ func NewOrderService(
 	couponRepo repositories.CouponRepository,
 	courseRepo repositories.CourseRepository,
 	outboxRepo repositories.OutboxRepository,
+	flagClient *flags.Client,
 	config *config.Config,
 ) OrderService {
 	return &orderService{
@@ -79,6 +92,7 @@ func NewOrderService(
 		couponRepo:    couponRepo,
 		courseRepo:    courseRepo,
 		outboxRepo:    outboxRepo,
+		flags:         flagClient,
 		config:        config,
 	}
 }
@@ -98,17 +112,18 @@ func (s *orderService) CreateOrder(ctx context.Context, req *CreateOrderRequest)
 
 	// Apply coupon discount if provided
 	var coupon *models.Coupon
-	var discountAmount int64 = 0
+	discount := money.Zero(totalAmount.Currency())
 	if req.CouponCode != nil {
-		coupon, discountAmount, err = s.validateAndApplyCoupon(ctx, *req.CouponCode, req.UserID, totalAmount)
+		coupon, discount, err = s.validateAndApplyCoupon(ctx, *req.CouponCode, req.UserID, totalAmount)
 		if err != nil {
 			return nil, fmt.Errorf("coupon validation failed: %w", err)
 		}
 	}
 
-	finalAmount := totalAmount - discountAmount
-	if finalAmount < 0 {
-		finalAmount = 0
+	// The discount never exceeds the total
+	finalAmount, err := totalAmount.Sub(discount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply discount: %w", err)
 	}
 
 	// Set expiration time
@@ -121,8 +136,8 @@ func (s *orderService) CreateOrder(ctx context.Context, req *CreateOrderRequest)
 	// Create order
 	order := &models.Order{
 		UserID:        req.UserID,
-		TotalAmount:   finalAmount,
-		Currency:      "USD",
+		TotalAmount:   finalAmount.Amount(),
+		Currency:      string(finalAmount.Currency()),
 		Status:        models.OrderStatusCreated,
 		CustomerEmail: req.CustomerEmail,
 		ExpiresAt:     expiresAtSql,
@@ -133,6 +148,14 @@ func (s *orderService) CreateOrder(ctx context.Context, req *CreateOrderRequest)
 		order.CustomerName = *req.CustomerName
 	}
 
+	// The new checkout flow ships dark behind a flag; each order records the flow that
+	// created it so the rollout can be compared with the legacy flow
+	order.Metadata = make(map[string]any, len(req.Metadata)+1)
+	for key, value := range req.Metadata {
+		order.Metadata[key] = value
+	}
+	order.Metadata["checkout_flow"] = s.checkoutFlow(ctx, req.UserID)
+
 	// Start transaction
 	err = s.orderRepo.WithTx(ctx, func(ctx context.Context) error {
 		// Create order
@@ -141,9 +164,9 @@ func (s *orderService) CreateOrder(ctx context.Context, req *CreateOrderRequest)
 		}
 
 		// Create order items
-		for _, item := range orderItems {
-			item.OrderID = order.ID
-			if err := s.orderItemRepo.Create(ctx, &item); err != nil {
+		for i := range orderItems {
+			orderItems[i].OrderID = order.ID
+			if err := s.orderItemRepo.Create(ctx, &orderItems[i]); err != nil {
 				return fmt.Errorf("failed to create order item: %w", err)
 			}
 		}
@@ -154,7 +177,7 @@ func (s *orderService) CreateOrder(ctx context.Context, req *CreateOrderRequest)
 				CouponID:       coupon.ID,
 				UserID:         req.UserID,
 				OrderID:        order.ID,
-				DiscountAmount: discountAmount,
+				DiscountAmount: discount.Amount(),
 			}
 			if err := s.couponRepo.CreateRedemption(ctx, redemption); err != nil {
 				return fmt.Errorf("failed to create coupon redemption: %w", err)
@@ -162,11 +185,9 @@ func (s *orderService) CreateOrder(ctx context.Context, req *CreateOrderRequest)
 		}
 
 		// Create order.created event
-		event := &models.Outbox{
-			AggregateID: order.ID,
-			Topic:       "order.events",
-			Type:        "order.created",
-			Payload:     s.createOrderEventPayload(order, orderItems, coupon),
+		event, err := newOutboxEvent(order.ID, orderCreatedEvent(order, orderItems, coupon))
+		if err != nil {
+			return err
 		}
 		if err := s.outboxRepo.Create(ctx, event); err != nil {
 			return fmt.Errorf("failed to create outbox event: %w", err)
@@ -188,6 +209,14 @@ func (s *orderService) CreateOrder(ctx context.Context, req *CreateOrderRequest)
 	return order, nil
 }
 
+// checkoutFlow returns the checkout flow userID is rolled out to.
+func (s *orderService) checkoutFlow(ctx context.Context, userID uuid.UUID) string {
+	if s.flags.IsEnabled(ctx, flags.NewCheckoutFlow, flags.Subject{UserID: userID.String()}) {
+		return checkoutFlowNew
+	}
+	return checkoutFlowLegacy
+}
+
 // GetOrder retrieves an order by ID with authorization check
 func (s *orderService) GetOrder(ctx context.Context, orderID, userID uuid.UUID) (*models.Order, error) {
 	if err := s.ValidateOrderAccess(ctx, orderID, userID); err != nil {
@@ -207,15 +236,18 @@ func (s *orderService) GetOrder(ctx context.Context, orderID, userID uuid.UUID)
 }
 
 // ListUserOrders retrieves paginated orders for a user
-func (s *orderService) ListUserOrders(ctx context.Context, userID uuid.UUID, limit, offset int) ([]models.Order, int64, error) {
+func (s *orderService) ListUserOrders(ctx context.Context, userID uuid.UUID, sort queryparams.Sort, limit, offset int) ([]models.Order, int64, error) {
 	if limit <= 0 || limit > 100 {
 		limit = 20 // default limit
 	}
 	if offset < 0 {
 		offset = 0
 	}
+	if sort.Field == "" {
+		sort = queryparams.Sort{Field: "created_at", Desc: true}
+	}
 
-	orders, total, err := s.orderRepo.GetByUserID(ctx, userID, limit, offset)
+	orders, total, err := s.orderRepo.GetByUserID(ctx, userID, sort, limit, offset)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to list user orders: %w", err)
 	}
@@ -244,11 +276,14 @@ func (s *orderService) CancelOrder(ctx context.Context, orderID, userID uuid.UUI
 		}
 
 		// Create order.cancelled event
-		event := &models.Outbox{
-			AggregateID: orderID,
-			Topic:       "order.events",
-			Type:        "order.cancelled",
-			Payload:     s.createOrderCancelledEventPayload(order, reason),
+		event, err := newOutboxEvent(orderID, &events.OrderCancelled{
+			OrderID:     orderID,
+			UserID:      order.UserID,
+			Reason:      reason,
+			CancelledAt: time.Now(),
+		})
+		if err != nil {
+			return err
 		}
 		if err := s.outboxRepo.Create(ctx, event); err != nil {
 			return fmt.Errorf("failed to create cancellation event: %w", err)
@@ -293,20 +328,26 @@ func (s *orderService) UpdateOrderStatus(ctx context.Context, orderID uuid.UUID,
 	}
 
 	// Create status change event
-	eventType := fmt.Sprintf("order.%s", status)
-	event := &models.Outbox{
-		AggregateID: orderID,
-		Topic:       "order.events",
-		Type:        eventType,
-		Payload:     s.createOrderStatusEventPayload(order, status, reason),
+	event, err := newOutboxEvent(orderID, &events.OrderStatusChanged{
+		OrderID:        orderID,
+		UserID:         order.UserID,
+		PreviousStatus: order.Status,
+		NewStatus:      status,
+		Reason:         reason,
+		UpdatedAt:      now,
+	})
+	if err != nil {
+		return err
 	}
 
 	return s.outboxRepo.Create(ctx, event)
 }
 
-// ProcessExpiredOrders finds and processes orders that have expired
+// ProcessExpiredOrders cancels the orders still pending payment past their expiry. It
+// runs as the orders.expire scheduled job.
 func (s *orderService) ProcessExpiredOrders(ctx context.Context) error {
-	expiredTime := time.Now().Add(-time.Hour) // Orders expired more than 1 hour ago
+	// Orders expire ORDER_EXPIRES_IN hours after they are created
+	expiredTime := time.Now().Add(-time.Duration(s.config.OrderExpiresIn) * time.Hour)
 	expiredOrders, err := s.orderRepo.GetPendingOrders(ctx, expiredTime)
 	if err != nil {
 		return fmt.Errorf("failed to get expired orders: %w", err)
@@ -314,8 +355,8 @@ func (s *orderService) ProcessExpiredOrders(ctx context.Context) error {
 
 	for _, order := range expiredOrders {
 		if err := s.UpdateOrderStatus(ctx, order.ID, models.OrderStatusCancelled, "Order expired"); err != nil {
-			// Log error but continue processing other orders
-			// TODO: Add proper logging
+			// Continue with the other orders; this one is retried on the next run
+			slog.WarnContext(ctx, "failed to cancel expired order", "order_id", order.ID, "error", err)
 			continue
 		}
 	}
@@ -358,19 +399,19 @@ func (s *orderService) validateCreateOrderRequest(ctx context.Context, req *Crea
 	return nil
 }
 
-func (s *orderService) calculateOrderTotal(ctx context.Context, req *CreateOrderRequest) (int64, []models.OrderItem, error) {
-	var total int64
+func (s *orderService) calculateOrderTotal(ctx context.Context, req *CreateOrderRequest) (money.Money, []models.OrderItem, error) {
+	total := money.Zero(models.DefaultCurrency)
 	var orderItems []models.OrderItem
 
 	for _, itemReq := range req.Items {
 		// Get course information
 		course, err := s.courseRepo.GetByID(ctx, itemReq.CourseID)
 		if err != nil {
-			return 0, nil, fmt.Errorf("failed to get course %s: %w", itemReq.CourseID, err)
+			return money.Money{}, nil, fmt.Errorf("failed to get course %s: %w", itemReq.CourseID, err)
 		}
 
 		if course == nil || !course.IsActive {
-			return 0, nil, fmt.Errorf("%w: course %s not found or inactive", ErrInvalidCourse, itemReq.CourseID)
+			return money.Money{}, nil, fmt.Errorf("%w: course %s not found or inactive", ErrInvalidCourse, itemReq.CourseID)
 		}
 
 		price := course.Price
@@ -378,8 +419,13 @@ func (s *orderService) calculateOrderTotal(ctx context.Context, req *CreateOrder
 			price = itemReq.PriceSnapshot
 		}
 
-		itemTotal := price * int64(itemReq.Quantity)
-		total += itemTotal
+		itemTotal, err := money.New(price, models.DefaultCurrency).Multiply(int64(itemReq.Quantity))
+		if err == nil {
+			total, err = total.Add(itemTotal)
+		}
+		if err != nil {
+			return money.Money{}, nil, fmt.Errorf("failed to add course %s to the total: %w", itemReq.CourseID, err)
+		}
 
 		orderItem := models.OrderItem{
 			CourseID:          itemReq.CourseID,
@@ -399,28 +445,30 @@ func (s *orderService) calculateOrderTotal(ctx context.Context, req *CreateOrder
 	return total, orderItems, nil
 }
 
-func (s *orderService) validateAndApplyCoupon(ctx context.Context, code string, userID uuid.UUID, orderAmount int64) (*models.Coupon, int64, error) {
+func (s *orderService) validateAndApplyCoupon(ctx context.Context, code string, userID uuid.UUID, orderAmount money.Money) (*models.Coupon, money.Money, error) {
 	coupon, err := s.couponRepo.GetByCode(ctx, code)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to get coupon: %w", err)
+		return nil, money.Money{}, fmt.Errorf("failed to get coupon: %w", err)
 	}
 
 	if coupon == nil {
-		return nil, 0, fmt.Errorf("%w: coupon not found", ErrInvalidCoupon)
+		return nil, money.Money{}, fmt.Errorf("%w: coupon not found", ErrInvalidCoupon)
 	}
 
 	// Validate coupon availability
 	if err := s.validateCouponAvailability(coupon); err != nil {
-		return nil, 0, err
+		return nil, money.Money{}, err
 	}
 
 	// Validate user restrictions
-	if err := s.validateCouponUserRestrictions(ctx, coupon, userID, orderAmount); err != nil {
-		return nil, 0, err
+	if err := s.validateCouponUserRestrictions(ctx, coupon, userID, orderAmount.Amount()); err != nil {
+		return nil, money.Money{}, err
 	}
 
-	// Calculate discount
-	discount := s.calculateDiscount(coupon, orderAmount)
+	discount, err := coupon.Discount(orderAmount)
+	if err != nil {
+		return nil, money.Money{}, fmt.Errorf("%w: %v", ErrInvalidCoupon, err)
+	}
 
 	return coupon, discount, nil
 }
@@ -472,28 +520,6 @@ func (s *orderService) validateCouponUserRestrictions(ctx context.Context, coupo
 	return nil
 }
 
-func (s *orderService) calculateDiscount(coupon *models.Coupon, orderAmount int64) int64 {
-	var discount int64
-
-	switch coupon.Type {
-	case models.CouponTypePercentage:
-		if coupon.PercentOff != nil {
-			discount = (orderAmount * int64(*coupon.PercentOff)) / 100
-		}
-	case models.CouponTypeFixedAmount:
-		if coupon.AmountOff != nil {
-			discount = *coupon.AmountOff
-		}
-	}
-
-	// Ensure discount doesn't exceed order amount
-	if discount > orderAmount {
-		discount = orderAmount
-	}
-
-	return discount
-}
-
 func (s *orderService) canCancelOrder(order *models.Order) bool {
 	return order.Status == models.OrderStatusCreated || order.Status == models.OrderStatusPendingPayment
 }
@@ -522,62 +548,40 @@ func (s *orderService) isValidStatusTransition(currentStatus, newStatus string)
 	return false
 }
 
-// Event payload creation methods
-func (s *orderService) createOrderEventPayload(order *models.Order, items []models.OrderItem, coupon *models.Coupon) []byte {
-	payload := map[string]interface{}{
-		"order_id":     order.ID,
-		"user_id":      order.UserID,
-		"total_amount": order.TotalAmount,
-		"currency":     order.Currency,
-		"status":       order.Status,
-		"items":        items,
-		"created_at":   order.CreatedAt,
-		"expires_at":   order.ExpiresAt,
+// orderCreatedEvent describes a new order with its items and the coupon applied to it
+func orderCreatedEvent(order *models.Order, items []models.OrderItem, coupon *models.Coupon) *events.OrderCreated {
+	event := &events.OrderCreated{
+		OrderID:     order.ID,
+		UserID:      order.UserID,
+		TotalAmount: order.TotalAmount,
+		Currency:    order.Currency,
+		Status:      order.Status,
+		Items:       eventItems(items),
+		CreatedAt:   order.CreatedAt,
 	}
-
-	if coupon != nil {
-		payload["coupon"] = map[string]interface{}{
-			"id":   coupon.ID,
-			"code": coupon.Code,
-		}
+	if order.ExpiresAt.Valid {
+		event.ExpiresAt = &order.ExpiresAt.Time
 	}
-
-	// Convert to JSON (simplified for example)
-	// In production, use proper JSON marshaling
-	return s.marshalPayload(payload)
-}
-
-func (s *orderService) createOrderCancelledEventPayload(order *models.Order, reason string) []byte {
-	payload := map[string]interface{}{
-		"order_id":     order.ID,
-		"user_id":      order.UserID,
-		"status":       order.Status,
-		"reason":       reason,
-		"cancelled_at": time.Now(),
-	}
-
-	// Convert to JSON (simplified for example)
-	return s.marshalPayload(payload)
-}
-
-func (s *orderService) createOrderStatusEventPayload(order *models.Order, status, reason string) []byte {
-	payload := map[string]interface{}{
-		"order_id":        order.ID,
-		"user_id":         order.UserID,
-		"previous_status": order.Status,
-		"new_status":      status,
-		"reason":          reason,
-		"updated_at":      time.Now(),
+	if coupon != nil {
+		event.Coupon = &events.CouponRef{ID: coupon.ID, Code: coupon.Code}
 	}
-
-	// Convert to JSON (simplified for example)
-	return s.marshalPayload(payload)
+	return event
 }
 
-func (s *orderService) marshalPayload(payload map[string]interface{}) []byte {
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return []byte(`{"error": "failed to marshal payload"}`)
+// eventItems converts order items to the items of the order events
+func eventItems(items []models.OrderItem) []events.OrderItem {
+	converted := make([]events.OrderItem, len(items))
+	for i, item := range items {
+		converted[i] = events.OrderItem{
+			ID:            item.ID,
+			CourseID:      item.CourseID,
+			CourseTitle:   item.CourseTitle,
+			InstructorID:  item.InstructorID,
+			PriceSnapshot: item.PriceSnapshot,
+			OriginalPrice: item.OriginalPrice,
+			Quantity:      item.Quantity,
+			ItemType:      item.ItemType,
+		}
 	}
-	return data
+	return converted
 }
@@ -3,45 +3,62 @@ package services
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"log"
 	"time"
 
+	"github.com/ductan2/microservice-app/shared/apperr"
+	"github.com/ductan2/microservice-app/shared/events"
+	"github.com/ductan2/microservice-app/shared/outbox"
 	"github.com/google/uuid"
 	amqp "github.com/rabbitmq/amqp091-go"
 
 	"order-services/internal/config"
+	"order-services/internal/dto"
 	"order-services/internal/models"
 	"order-services/internal/repositories"
 )
 
 var (
-	ErrEventNotFound      = errors.New("event not found")
-	ErrEventPublishFailed = errors.New("failed to publish event")
-	ErrQueueConnection    = errors.New("failed to connect to message queue")
-	ErrQueueChannel       = errors.New("failed to create channel")
+	ErrQueueConnection = apperr.New(apperr.Unavailable, "failed to connect to message queue").WithReason(dto.ErrCodeQueueConnection)
+	ErrQueueChannel    = apperr.New(apperr.Unavailable, "failed to create channel").WithReason(dto.ErrCodeQueueChannel)
 )
 
+const (
+	outboxPollInterval   = 5 * time.Second
+	outboxBackoffBase    = 5 * time.Second
+	outboxBackoffMax     = 10 * time.Minute
+	outboxConfirmTimeout = 5 * time.Second
+	// outboxLease hides a claimed batch from the other relays of the outbox
+	outboxLease = time.Minute
+)
+
+// defaultExchanges are the exchanges the order service publishes to
+var defaultExchanges = []string{
+	"order.events",
+	"payment.events",
+	"coupon.events",
+	"user.notifications", // For user notifications
+}
+
 // OutboxService defines the business logic interface for outbox pattern event publishing
 type OutboxService interface {
-	PublishEvents(ctx context.Context) error
 	CreateEvent(ctx context.Context, aggregateID uuid.UUID, topic, eventType string, payload interface{}) error
-	PublishEvent(ctx context.Context, event *models.Outbox) error
-	GetUnpublishedEvents(ctx context.Context, limit int) ([]models.Outbox, error)
-	MarkAsPublished(ctx context.Context, eventID int64) error
+	GetEventStats(ctx context.Context) (*OutboxStats, error)
 	StartEventPublisher(ctx context.Context) error
 	StopEventPublisher()
+	// CheckPublisher reports whether the publisher is connected to RabbitMQ
+	CheckPublisher(ctx context.Context) error
 }
 
-// outboxService implements the outbox pattern business logic
+// outboxService writes events through the outbox repository and runs the shared outbox
+// relay, which publishes each event to the exchange named after its topic with its type
+// as routing key
 type outboxService struct {
 	outboxRepo repositories.OutboxRepository
 	config     *config.Config
 	conn       *amqp.Connection
 	channel    *amqp.Channel
-	stopChan   chan bool
-	running    bool
+	relay      *outbox.Relay
 }
 
 // NewOutboxService creates a new outbox service instance
@@ -52,42 +69,7 @@ func NewOutboxService(
 	return &outboxService{
 		outboxRepo: outboxRepo,
 		config:     config,
-		stopChan:   make(chan bool),
-		running:    false,
-	}
-}
-
-// PublishEvents processes and publishes unpublished events from the outbox
-func (s *outboxService) PublishEvents(ctx context.Context) error {
-	// Get unpublished events
-	events, err := s.GetUnpublishedEvents(ctx, 100) // Process in batches of 100
-	if err != nil {
-		return fmt.Errorf("failed to get unpublished events: %w", err)
-	}
-
-	if len(events) == 0 {
-		return nil // No events to publish
 	}
-
-	// Ensure RabbitMQ connection is established
-	if err := s.ensureConnection(); err != nil {
-		return fmt.Errorf("failed to ensure RabbitMQ connection: %w", err)
-	}
-
-	// Publish each event
-	for _, event := range events {
-		if err := s.PublishEvent(ctx, &event); err != nil {
-			log.Printf("Failed to publish event %d: %v", event.ID, err)
-			continue // Continue with other events
-		}
-
-		// Mark as published
-		if err := s.MarkAsPublished(ctx, event.ID); err != nil {
-			log.Printf("Failed to mark event %d as published: %v", event.ID, err)
-		}
-	}
-
-	return nil
 }
 
 // CreateEvent creates a new outbox event
@@ -108,89 +90,73 @@ func (s *outboxService) CreateEvent(ctx context.Context, aggregateID uuid.UUID,
 	return s.outboxRepo.Create(ctx, event)
 }
 
-// PublishEvent publishes a single event to RabbitMQ
-func (s *outboxService) PublishEvent(ctx context.Context, event *models.Outbox) error {
-	if s.channel == nil {
-		return ErrQueueConnection
-	}
-
-	// Declare exchange if it doesn't exist
-	err := s.channel.ExchangeDeclare(
-		event.Topic, // name
-		"topic",     // type
-		true,        // durable
-		false,       // auto-deleted
-		false,       // internal
-		false,       // no-wait
-		nil,         // arguments
-	)
+// newOutboxEvent encodes an event of the shared contract (see shared/events) into an
+// outbox event, failing when the event does not validate
+func newOutboxEvent(aggregateID uuid.UUID, event events.Event) (*models.Outbox, error) {
+	payload, err := events.Marshal(event)
 	if err != nil {
-		return fmt.Errorf("failed to declare exchange: %w", err)
+		return nil, fmt.Errorf("failed to build %s event: %w", event.EventType(), err)
 	}
 
-	// Publish message
-	err = s.channel.PublishWithContext(
-		ctx,
-		event.Topic, // exchange
-		event.Type,  // routing key
-		false,       // mandatory
-		false,       // immediate
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        event.Payload,
-			MessageId:   fmt.Sprintf("%d", event.ID),
-			Timestamp:   time.Now(),
-			Headers: amqp.Table{
-				"event_type":   event.Type,
-				"aggregate_id": event.AggregateID.String(),
-				"topic":        event.Topic,
-			},
-		},
-	)
-	if err != nil {
-		return fmt.Errorf("%w: %v", ErrEventPublishFailed, err)
-	}
-
-	return nil
-}
-
-// GetUnpublishedEvents retrieves events that haven't been published yet
-func (s *outboxService) GetUnpublishedEvents(ctx context.Context, limit int) ([]models.Outbox, error) {
-	return s.outboxRepo.GetUnpublishedEvents(ctx, limit)
-}
-
-// MarkAsPublished marks an event as published
-func (s *outboxService) MarkAsPublished(ctx context.Context, eventID int64) error {
-	return s.outboxRepo.MarkAsPublished(ctx, eventID)
+	return &models.Outbox{
+		AggregateID: aggregateID,
+		Topic:       event.Topic(),
+		Type:        event.EventType(),
+		Payload:     payload,
+	}, nil
 }
 
-// StartEventPublisher starts the background event publisher
+// StartEventPublisher connects to RabbitMQ and starts the outbox relay in the background
 func (s *outboxService) StartEventPublisher(ctx context.Context) error {
-	if s.running {
+	if s.relay != nil {
 		return fmt.Errorf("event publisher is already running")
 	}
 
-	// Initialize RabbitMQ connection
-	if err := s.ensureConnection(); err != nil {
-		return fmt.Errorf("failed to initialize RabbitMQ connection: %w", err)
+	conn, err := amqp.Dial(s.config.RabbitMQURL())
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrQueueConnection, err)
+	}
+	channel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("%w: %v", ErrQueueChannel, err)
 	}
 
-	s.running = true
+	publisher, err := outbox.NewAMQPPublisher(channel, outbox.ExchangePerTopic, outboxConfirmTimeout)
+	if err == nil {
+		err = publisher.DeclareExchanges(defaultExchanges...)
+	}
+	if err != nil {
+		channel.Close()
+		conn.Close()
+		return err
+	}
 
-	// Start background publisher goroutine
-	go s.eventPublisherLoop(ctx)
+	s.conn = conn
+	s.channel = channel
+	s.relay = outbox.NewRelay(s.outboxRepo.Store(), publisher, outbox.Config{
+		PollInterval: outboxPollInterval,
+		BatchSize:    s.config.OutboxBatchSize,
+		MaxAttempts:  s.config.OutboxMaxAttempts,
+		BackoffBase:  outboxBackoffBase,
+		BackoffMax:   outboxBackoffMax,
+		Retention:    time.Duration(s.config.OutboxRetentionDays) * 24 * time.Hour,
+		Lease:        outboxLease,
+		Validator:    events.Schemas,
+	})
+	go s.relay.Start(ctx)
 
 	return nil
 }
 
 // StopEventPublisher stops the background event publisher
 func (s *outboxService) StopEventPublisher() {
-	if !s.running {
+	if s.relay == nil {
 		return
 	}
 
-	s.running = false
-	close(s.stopChan)
+	s.relay.Stop()
+	s.relay = nil
 
 	// Close RabbitMQ connection
 	if s.channel != nil {
@@ -203,90 +169,11 @@ func (s *outboxService) StopEventPublisher() {
 	}
 }
 
-// eventPublisherLoop runs the background event publishing loop
-func (s *outboxService) eventPublisherLoop(ctx context.Context) {
-	ticker := time.NewTicker(5 * time.Second) // Process events every 5 seconds
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-s.stopChan:
-			return
-		case <-ticker.C:
-			if err := s.PublishEvents(ctx); err != nil {
-				log.Printf("Failed to publish events: %v", err)
-			}
-		}
-	}
-}
-
-// ensureConnection ensures RabbitMQ connection is established
-func (s *outboxService) ensureConnection() error {
-	// Check if connection is already established
-	if s.conn != nil && !s.conn.IsClosed() {
-		return nil
-	}
-
-	// Connect to RabbitMQ
-	conn, err := amqp.Dial(s.config.RabbitMQURL())
-	if err != nil {
-		return fmt.Errorf("%w: %v", ErrQueueConnection, err)
-	}
-	s.conn = conn
-
-	// Create channel
-	channel, err := conn.Channel()
-	if err != nil {
-		conn.Close()
-		s.conn = nil
-		return fmt.Errorf("%w: %v", ErrQueueChannel, err)
+func (s *outboxService) CheckPublisher(_ context.Context) error {
+	// The connection heartbeats, so a lost broker closes it
+	if s.conn == nil || s.conn.IsClosed() {
+		return fmt.Errorf("connection closed")
 	}
-	s.channel = channel
-
-	// Set QoS to control how many messages are processed at once
-	err = s.channel.Qos(
-		100,   // prefetch count
-		0,     // prefetch size
-		false, // global
-	)
-	if err != nil {
-		log.Printf("Warning: Failed to set QoS: %v", err)
-	}
-
-	// Declare default exchanges
-	if err := s.declareDefaultExchanges(); err != nil {
-		log.Printf("Warning: Failed to declare default exchanges: %v", err)
-	}
-
-	return nil
-}
-
-// declareDefaultExchanges declares the default exchanges used by the order service
-func (s *outboxService) declareDefaultExchanges() error {
-	exchanges := []string{
-		"order.events",
-		"payment.events",
-		"coupon.events",
-		"user.notifications", // For user notifications
-	}
-
-	for _, exchange := range exchanges {
-		err := s.channel.ExchangeDeclare(
-			exchange, // name
-			"topic",  // type
-			true,     // durable
-			false,    // auto-deleted
-			false,    // internal
-			false,    // no-wait
-			nil,      // arguments
-		)
-		if err != nil {
-			return fmt.Errorf("failed to declare exchange %s: %w", exchange, err)
-		}
-	}
-
 	return nil
 }
 
@@ -364,34 +251,24 @@ func (s *outboxService) CreateUserNotificationEvent(ctx context.Context, userID
 
 // GetEventStats returns statistics about outbox events
 func (s *outboxService) GetEventStats(ctx context.Context) (*OutboxStats, error) {
-	unpublishedCount, err := s.outboxRepo.CountUnpublishedEvents(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to count unpublished events: %w", err)
-	}
-
-	totalCount, err := s.outboxRepo.CountTotalEvents(ctx)
+	stats, err := s.outboxRepo.Store().Stats(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to count total events: %w", err)
+		return nil, fmt.Errorf("failed to get outbox stats: %w", err)
 	}
 
-	stats := &OutboxStats{
-		TotalEvents:       totalCount,
-		UnpublishedEvents: unpublishedCount,
-		PublishedEvents:   totalCount - unpublishedCount,
-	}
-
-	return stats, nil
+	return &OutboxStats{
+		PendingEvents:  stats.Pending,
+		RetryingEvents: stats.Retrying,
+		FailedEvents:   stats.Failed,
+		LagSeconds:     stats.Lag(time.Now()).Seconds(),
+	}, nil
 }
 
-// OutboxStats represents outbox event statistics
+// OutboxStats represents outbox event statistics; failed events were parked after
+// OUTBOX_MAX_ATTEMPTS failed publishes
 type OutboxStats struct {
-	TotalEvents       int64 `json:"total_events"`
-	UnpublishedEvents int64 `json:"unpublished_events"`
-	PublishedEvents   int64 `json:"published_events"`
-}
-
-// CleanupOldEvents removes old published events from the outbox table
-func (s *outboxService) CleanupOldEvents(ctx context.Context, olderThan time.Duration) error {
-	cutoffTime := time.Now().Add(-olderThan)
-	return s.outboxRepo.DeleteOldPublishedEvents(ctx, cutoffTime)
+	PendingEvents  int64   `json:"pending_events"`
+	RetryingEvents int64   `json:"retrying_events"`
+	FailedEvents   int64   `json:"failed_events"`
+	LagSeconds     float64 `json:"lag_seconds"`
 }
@@ -4,28 +4,30 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
+	"github.com/ductan2/microservice-app/shared/apperr"
+	"github.com/ductan2/microservice-app/shared/events"
 	"github.com/google/uuid"
 	"github.com/stripe/stripe-go/v78"
 	"github.com/stripe/stripe-go/v78/paymentintent"
 	"github.com/stripe/stripe-go/v78/webhook"
 
 	"order-services/internal/config"
+	"order-services/internal/dto"
 	"order-services/internal/models"
 	"order-services/internal/repositories"
 )
 
 var (
-	ErrPaymentNotFound      = errors.New("payment not found")
-	ErrInvalidPaymentIntent = errors.New("invalid payment intent")
-	ErrWebhookSignature     = errors.New("invalid webhook signature")
-	ErrDuplicateWebhook     = errors.New("duplicate webhook event")
-	ErrPaymentFailed        = errors.New("payment failed")
-	ErrOrderNotPaid         = errors.New("order is not paid")
+	ErrPaymentNotFound      = apperr.New(apperr.NotFound, "payment not found").WithReason(dto.ErrCodePaymentNotFound)
+	ErrInvalidPaymentIntent = apperr.New(apperr.BadRequest, "invalid payment intent").WithReason(dto.ErrCodeInvalidPaymentIntent)
+	ErrWebhookSignature     = apperr.New(apperr.BadRequest, "invalid webhook signature").WithReason(dto.ErrCodeWebhookSignature)
+	ErrDuplicateWebhook     = apperr.New(apperr.Conflict, "duplicate webhook event").WithReason(dto.ErrCodeDuplicateWebhook)
+	ErrPaymentFailed        = apperr.New(apperr.Conflict, "payment failed").WithReason(dto.ErrCodePaymentFailed)
+	ErrOrderNotPaid         = apperr.New(apperr.Conflict, "order is not paid").WithReason(dto.ErrCodeOrderNotPaid)
 )
 
 // PaymentService defines the business logic interface for payment processing
@@ -44,6 +46,7 @@ type paymentService struct {
 	paymentRepo   repositories.PaymentRepository
 	outboxRepo    repositories.OutboxRepository
 	webhookRepo   repositories.WebhookEventRepository
+	sagaService   SagaService
 	stripeKey     string
 	webhookSecret string
 }
@@ -54,6 +57,7 @@ func NewPaymentService(
 	paymentRepo repositories.PaymentRepository,
 	outboxRepo repositories.OutboxRepository,
 	webhookRepo repositories.WebhookEventRepository,
+	sagaService SagaService,
 	config *config.Config,
 ) PaymentService {
 	// Set Stripe key
@@ -64,6 +68,7 @@ func NewPaymentService(
 		paymentRepo:   paymentRepo,
 		outboxRepo:    outboxRepo,
 		webhookRepo:   webhookRepo,
+		sagaService:   sagaService,
 		stripeKey:     config.StripeSecretKey,
 		webhookSecret: config.StripeWebhookSecret,
 	}
@@ -147,11 +152,9 @@ func (s *paymentService) CreatePaymentIntent(ctx context.Context, orderID, userI
 		}
 
 		// Create payment.created event
-		event := &models.Outbox{
-			AggregateID: orderID,
-			Topic:       "payment.events",
-			Type:        "payment.created",
-			Payload:     s.createPaymentEventPayload(payment, "created"),
+		event, err := newOutboxEvent(orderID, &events.PaymentCreated{Payment: paymentDetails(payment)})
+		if err != nil {
+			return err
 		}
 		if err := s.outboxRepo.Create(ctx, event); err != nil {
 			return fmt.Errorf("failed to create payment event: %w", err)
@@ -208,7 +211,7 @@ func (s *paymentService) ProcessWebhook(ctx context.Context, webhookBody []byte,
 
 	if err != nil {
 		// Log error but don't fail the webhook response to avoid retry storms
-		log.Printf("Error processing webhook %s: %v", event.ID, err)
+		slog.ErrorContext(ctx, "failed to process webhook", "event_id", event.ID, "error", err)
 		return nil // Return success to Stripe
 	}
 
@@ -228,7 +231,7 @@ func (s *paymentService) processStripeEvent(ctx context.Context, event stripe.Ev
 		return s.handlePaymentIntentRequiresAction(ctx, event)
 	default:
 		// Log unhandled event type but don't return error
-		log.Printf("Unhandled webhook event type: %s", event.Type)
+		slog.InfoContext(ctx, "unhandled webhook event type", "event_type", event.Type)
 		return nil
 	}
 }
@@ -314,27 +317,38 @@ func (s *paymentService) HandlePaymentSuccess(ctx context.Context, paymentIntent
 		}
 
 		// Create payment.succeeded event
-		paymentEvent := &models.Outbox{
-			AggregateID: order.ID,
-			Topic:       "payment.events",
-			Type:        "payment.succeeded",
-			Payload:     s.createPaymentEventPayload(payment, "succeeded"),
+		paymentEvent, err := newOutboxEvent(order.ID, &events.PaymentSucceeded{Payment: paymentDetails(payment)})
+		if err != nil {
+			return err
 		}
 		if err := s.outboxRepo.Create(ctx, paymentEvent); err != nil {
 			return fmt.Errorf("failed to create payment success event: %w", err)
 		}
 
 		// Create order.paid event (for enrollment service)
-		orderEvent := &models.Outbox{
-			AggregateID: order.ID,
-			Topic:       "order.events",
-			Type:        "order.paid",
-			Payload:     s.createOrderPaidEventPayload(order, payment),
+		orderEvent, err := newOutboxEvent(order.ID, &events.OrderPaid{
+			OrderID:         order.ID,
+			UserID:          order.UserID,
+			PaymentID:       payment.ID,
+			TotalAmount:     order.TotalAmount,
+			Currency:        order.Currency,
+			PaymentIntentID: payment.StripePaymentIntentID,
+			Items:           eventItems(order.OrderItems),
+			PaidAt:          time.Now(),
+		})
+		if err != nil {
+			return err
 		}
 		if err := s.outboxRepo.Create(ctx, orderEvent); err != nil {
 			return fmt.Errorf("failed to create order paid event: %w", err)
 		}
 
+		// Enrollment, the welcome notification and the invoice run as a saga, started
+		// with the payment so that a paid order is never left without one
+		if err := s.sagaService.StartSaga(ctx, PurchaseSagaType, order.ID); err != nil {
+			return fmt.Errorf("failed to start purchase saga: %w", err)
+		}
+
 		return nil
 	})
 }
@@ -374,22 +388,29 @@ func (s *paymentService) HandlePaymentFailure(ctx context.Context, paymentIntent
 		}
 
 		// Create payment.failed event
-		paymentEvent := &models.Outbox{
-			AggregateID: order.ID,
-			Topic:       "payment.events",
-			Type:        "payment.failed",
-			Payload:     s.createPaymentFailedEventPayload(payment, failureReason),
+		paymentEvent, err := newOutboxEvent(order.ID, &events.PaymentFailed{
+			Payment:       paymentDetails(payment),
+			FailureReason: failureReason,
+			FailedAt:      time.Now(),
+		})
+		if err != nil {
+			return err
 		}
 		if err := s.outboxRepo.Create(ctx, paymentEvent); err != nil {
 			return fmt.Errorf("failed to create payment failed event: %w", err)
 		}
 
 		// Create order.failed event
-		orderEvent := &models.Outbox{
-			AggregateID: order.ID,
-			Topic:       "order.events",
-			Type:        "order.failed",
-			Payload:     s.createOrderFailedEventPayload(order, failureReason),
+		orderEvent, err := newOutboxEvent(order.ID, &events.OrderFailed{
+			OrderID:       order.ID,
+			UserID:        order.UserID,
+			TotalAmount:   order.TotalAmount,
+			Currency:      order.Currency,
+			FailureReason: failureReason,
+			FailedAt:      time.Now(),
+		})
+		if err != nil {
+			return err
 		}
 		if err := s.outboxRepo.Create(ctx, orderEvent); err != nil {
 			return fmt.Errorf("failed to create order failed event: %w", err)
@@ -473,73 +494,15 @@ func (s *paymentService) UpdateOrderStatus(ctx context.Context, paymentIntentID,
 	return s.orderRepo.UpdateStatus(ctx, payment.OrderID, status, timestamp, reason)
 }
 
-// Event payload creation methods
-func (s *paymentService) createPaymentEventPayload(payment *models.Payment, eventType string) []byte {
-	payload := map[string]interface{}{
-		"payment_id":        payment.ID,
-		"order_id":          payment.OrderID,
-		"stripe_payment_id": payment.StripePaymentIntentID,
-		"amount":            payment.Amount,
-		"currency":          payment.Currency,
-		"status":            payment.Status,
-		"event_type":        eventType,
-		"created_at":        payment.CreatedAt,
-	}
-
-	// Convert to JSON (simplified for example)
-	return s.marshalPayload(payload)
-}
-
-func (s *paymentService) createPaymentFailedEventPayload(payment *models.Payment, reason string) []byte {
-	payload := map[string]interface{}{
-		"payment_id":        payment.ID,
-		"order_id":          payment.OrderID,
-		"stripe_payment_id": payment.StripePaymentIntentID,
-		"amount":            payment.Amount,
-		"currency":          payment.Currency,
-		"status":            payment.Status,
-		"failure_reason":    reason,
-		"failed_at":         time.Now(),
-	}
-
-	// Convert to JSON (simplified for example)
-	return s.marshalPayload(payload)
-}
-
-func (s *paymentService) createOrderPaidEventPayload(order *models.Order, payment *models.Payment) []byte {
-	payload := map[string]interface{}{
-		"order_id":          order.ID,
-		"user_id":           order.UserID,
-		"payment_id":        payment.ID,
-		"total_amount":      order.TotalAmount,
-		"currency":          order.Currency,
-		"payment_intent_id": payment.StripePaymentIntentID,
-		"paid_at":           time.Now(),
-		"items":             order.OrderItems,
-	}
-
-	// Convert to JSON (simplified for example)
-	return s.marshalPayload(payload)
-}
-
-func (s *paymentService) createOrderFailedEventPayload(order *models.Order, reason string) []byte {
-	payload := map[string]interface{}{
-		"order_id":       order.ID,
-		"user_id":        order.UserID,
-		"total_amount":   order.TotalAmount,
-		"currency":       order.Currency,
-		"failure_reason": reason,
-		"failed_at":      time.Now(),
-	}
-
-	// Convert to JSON (simplified for example)
-	return s.marshalPayload(payload)
-}
-
-func (s *paymentService) marshalPayload(payload map[string]interface{}) []byte {
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return []byte(`{"error": "failed to marshal payload"}`)
+// paymentDetails describes a payment in the payment events
+func paymentDetails(payment *models.Payment) events.Payment {
+	return events.Payment{
+		PaymentID:       payment.ID,
+		OrderID:         payment.OrderID,
+		StripePaymentID: payment.StripePaymentIntentID,
+		Amount:          payment.Amount,
+		Currency:        payment.Currency,
+		Status:          payment.Status,
+		CreatedAt:       payment.CreatedAt,
 	}
-	return data
 }
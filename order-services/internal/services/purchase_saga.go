@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/saga"
+	"github.com/google/uuid"
+
+	"order-services/internal/models"
+	"order-services/internal/repositories"
+)
+
+// PurchaseSagaType is the saga started for every paid order
+const PurchaseSagaType = "purchase"
+
+// Steps of the purchase saga, in the order they run
+const (
+	PurchaseStepEnroll  = "enroll"
+	PurchaseStepWelcome = "welcome_notification"
+	PurchaseStepInvoice = "invoice"
+)
+
+// purchaseSaga drives a paid order through enrollment in its courses, the welcome
+// notification and its invoice. Compensating it voids the invoice and revokes the
+// enrollments; the notification has nothing to undo.
+type purchaseSaga struct {
+	orderRepo           repositories.OrderRepository
+	invoiceRepo         repositories.InvoiceRepository
+	enrollmentService   EnrollmentService
+	notificationService NotificationService
+}
+
+// NewPurchaseSaga returns the definition of the purchase saga
+func NewPurchaseSaga(
+	orderRepo repositories.OrderRepository,
+	invoiceRepo repositories.InvoiceRepository,
+	enrollmentService EnrollmentService,
+	notificationService NotificationService,
+) saga.Definition {
+	p := &purchaseSaga{
+		orderRepo:           orderRepo,
+		invoiceRepo:         invoiceRepo,
+		enrollmentService:   enrollmentService,
+		notificationService: notificationService,
+	}
+
+	return saga.Definition{
+		Type: PurchaseSagaType,
+		Steps: []saga.Step{
+			{
+				Name:       PurchaseStepEnroll,
+				Do:         p.enroll,
+				Compensate: p.enrollmentService.RevokeEnrollmentsForOrder,
+				Timeout:    45 * time.Second, // the enrollment client gives up after 30s
+			},
+			{
+				Name:    PurchaseStepWelcome,
+				Do:      p.welcome,
+				Timeout: 45 * time.Second,
+			},
+			{
+				Name:       PurchaseStepInvoice,
+				Do:         p.invoice,
+				Compensate: p.invoiceRepo.Void,
+				Timeout:    10 * time.Second,
+			},
+		},
+	}
+}
+
+// paidOrder loads the order of the saga. An order that is gone or no longer paid, such
+// as one refunded meanwhile, stops the saga for good.
+func (p *purchaseSaga) paidOrder(ctx context.Context, orderID uuid.UUID) (*models.Order, error) {
+	order, err := p.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	if order == nil {
+		return nil, saga.Permanent(ErrOrderNotFound)
+	}
+	if order.Status != models.OrderStatusPaid {
+		return nil, saga.Permanent(fmt.Errorf("order is %s, not paid", order.Status))
+	}
+	return order, nil
+}
+
+func (p *purchaseSaga) enroll(ctx context.Context, orderID uuid.UUID) error {
+	order, err := p.paidOrder(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	// Courses the buyer is already enrolled in are skipped, so a retry does not enroll twice
+	return p.enrollmentService.CreateEnrollmentsForPaidOrder(ctx, order)
+}
+
+func (p *purchaseSaga) welcome(ctx context.Context, orderID uuid.UUID) error {
+	order, err := p.paidOrder(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	return p.notificationService.SendCourseWelcome(ctx, order)
+}
+
+func (p *purchaseSaga) invoice(ctx context.Context, orderID uuid.UUID) error {
+	order, err := p.paidOrder(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	_, err = p.invoiceRepo.CreateForOrder(ctx, order)
+	return err
+}
@@ -1,29 +1,31 @@
 package services
 
 import (
+	"log/slog"
 	"context"
-	"errors"
 	"fmt"
-	"log"
 	"time"
 
+	"github.com/ductan2/microservice-app/shared/apperr"
+	"github.com/ductan2/microservice-app/shared/events"
 	"github.com/google/uuid"
 	"github.com/stripe/stripe-go/v78"
 	"github.com/stripe/stripe-go/v78/refund"
 
 	"order-services/internal/config"
+	"order-services/internal/dto"
 	"order-services/internal/models"
 	"order-services/internal/repositories"
 	"order-services/internal/types"
 )
 
 var (
-	ErrRefundNotFound       = errors.New("refund not found")
-	ErrRefundAlreadyProcessed = errors.New("refund already processed")
-	ErrRefundAmountExceedsPayment = errors.New("refund amount exceeds payment amount")
-	ErrRefundWindowExpired  = errors.New("refund window has expired")
-	ErrInvalidRefundReason  = errors.New("invalid refund reason")
-	ErrRefundNotAllowed      = errors.New("refund not allowed for this order status")
+	ErrRefundNotFound = apperr.New(apperr.NotFound, "refund not found").WithReason(dto.ErrCodeRefundNotFound)
+	ErrRefundAlreadyProcessed = apperr.New(apperr.Conflict, "refund already processed").WithReason(dto.ErrCodeRefundAlreadyProcessed)
+	ErrRefundAmountExceedsPayment = apperr.New(apperr.BadRequest, "refund amount exceeds payment amount").WithReason(dto.ErrCodeRefundAmountExceeded)
+	ErrRefundWindowExpired = apperr.New(apperr.Conflict, "refund window has expired").WithReason(dto.ErrCodeRefundWindowExpired)
+	ErrInvalidRefundReason = apperr.New(apperr.BadRequest, "invalid refund reason").WithReason(dto.ErrCodeInvalidRefundReason)
+	ErrRefundNotAllowed = apperr.New(apperr.Conflict, "refund not allowed for this order status").WithReason(dto.ErrCodeRefundNotAllowed)
 )
 
 // RefundService defines the business logic interface for refund processing
@@ -74,7 +76,8 @@ func NewRefundService(
 // CreateRefundRequest creates a new refund request
 func (s *refundService) CreateRefundRequest(ctx context.Context, orderID, userID uuid.UUID, reason string, amount *int64) (*models.RefundRequest, error) {
 	// Validate reason
-	if !isValidRefundReason(reason) {
+	category, ok := refundReasonCategories[reason]
+	if !ok {
 		return nil, ErrInvalidRefundReason
 	}
 
@@ -120,7 +123,7 @@ func (s *refundService) CreateRefundRequest(ctx context.Context, orderID, userID
 	}
 
 	if refundAmount <= 0 {
-		return nil, fmt.Errorf("refund amount must be positive")
+		return nil, apperr.New(apperr.BadRequest, "refund amount must be positive")
 	}
 
 	if refundAmount > payment.Amount {
@@ -139,11 +142,12 @@ func (s *refundService) CreateRefundRequest(ctx context.Context, orderID, userID
 
 	// Create refund request
 	refundRequest := &models.RefundRequest{
-		OrderID: orderID,
-		UserID:  userID,
-		Amount:  refundAmount,
-		Reason:  reason,
-		Status:  models.RefundStatusPending,
+		OrderID:        orderID,
+		UserID:         userID,
+		Amount:         refundAmount,
+		Reason:         reason,
+		ReasonCategory: category,
+		Status:         models.RefundStatusPending,
 	}
 
 	// Save refund request
@@ -156,7 +160,7 @@ func (s *refundService) CreateRefundRequest(ctx context.Context, orderID, userID
 	err = s.notificationService.SendNewRefundRequest(ctx, refundRequest)
 	if err != nil {
 		// Log error but don't fail the request
-		log.Printf("Warning: Failed to send refund request notification: %v", err)
+		slog.WarnContext(ctx, "failed to send refund request notification", "error", err)
 	}
 
 	return refundRequest, nil
@@ -209,15 +213,15 @@ func (s *refundService) ProcessRefundRequest(ctx context.Context, refundID uuid.
 		return fmt.Errorf("failed to update refund status: %w", err)
 	}
 
-	// Create refund event payload
-	eventData := map[string]interface{}{
-		"refund_id":     refundID,
-		"order_id":      refundRequest.OrderID,
-		"user_id":       refundRequest.UserID,
-		"amount":        refundRequest.Amount,
-		"status":        newStatus,
-		"admin_reason":  adminReason,
-		"processed_at":  time.Now(),
+	// Describe the refund for its events
+	refundDetails := events.Refund{
+		RefundID:    refundID,
+		OrderID:     refundRequest.OrderID,
+		UserID:      refundRequest.UserID,
+		Amount:      refundRequest.Amount,
+		Status:      newStatus,
+		AdminReason: adminReason,
+		ProcessedAt: time.Now(),
 	}
 
 	// Process refund if approved
@@ -229,16 +233,11 @@ func (s *refundService) ProcessRefundRequest(ctx context.Context, refundID uuid.
 			s.refundRepo.UpdateStatus(ctx, refundID, models.RefundStatusFailed, fmt.Sprintf("Stripe processing failed: %v", err))
 
 			// Create failed refund event
-			eventData["status"] = models.RefundStatusFailed
-			eventData["error"] = err.Error()
-
-			outboxEvent := &models.Outbox{
-				AggregateID: refundRequest.OrderID,
-				Topic:       "order.events",
-				Type:        "order.refund_failed",
-				Payload:     s.createEventPayload(eventData),
-			}
-			s.outboxRepo.Create(ctx, outboxEvent)
+			refundDetails.Status = models.RefundStatusFailed
+			s.recordEvent(ctx, refundRequest.OrderID, &events.OrderRefundFailed{
+				Refund: refundDetails,
+				Error:  err.Error(),
+			})
 
 			return fmt.Errorf("failed to process Stripe refund: %w", err)
 		}
@@ -246,46 +245,35 @@ func (s *refundService) ProcessRefundRequest(ctx context.Context, refundID uuid.
 		// Update refund request with Stripe refund ID
 		err = s.refundRepo.UpdateStripeRefundID(ctx, refundID, stripeRefund.ID)
 		if err != nil {
-			log.Printf("Warning: Failed to update refund request with Stripe refund ID: %v", err)
+			slog.WarnContext(ctx, "failed to update refund request with Stripe refund ID", "error", err)
 		}
 
 		// Mark order as refunded
 		err = s.orderRepo.UpdateStatus(ctx, refundRequest.OrderID, models.OrderStatusRefunded, nil, "Refund processed")
 		if err != nil {
-			log.Printf("Warning: Failed to update order status to refunded: %v", err)
+			slog.WarnContext(ctx, "failed to update order status to refunded", "error", err)
 		}
 
 		// Create refund events
-		outboxEvent := &models.Outbox{
-			AggregateID: refundRequest.OrderID,
-			Topic:       "order.events",
-			Type:        "order.refunded",
-			Payload:     s.createEventPayload(eventData),
-		}
-		s.outboxRepo.Create(ctx, outboxEvent)
+		s.recordEvent(ctx, refundRequest.OrderID, &events.OrderRefunded{
+			Refund:         refundDetails,
+			StripeRefundID: stripeRefund.ID,
+		})
 
 		// Send notifications
 		err = s.notificationService.SendRefundProcessed(ctx, refundRequest, stripeRefund)
 		if err != nil {
-			log.Printf("Warning: Failed to send refund processed notification: %v", err)
+			slog.WarnContext(ctx, "failed to send refund processed notification", "error", err)
 		}
 
 	} else {
 		// Rejected refund
-		eventData["status"] = models.RefundStatusRejected
-
-		outboxEvent := &models.Outbox{
-			AggregateID: refundRequest.OrderID,
-			Topic:       "order.events",
-			Type:        "order.refund_rejected",
-			Payload:     s.createEventPayload(eventData),
-		}
-		s.outboxRepo.Create(ctx, outboxEvent)
+		s.recordEvent(ctx, refundRequest.OrderID, &events.OrderRefundRejected{Refund: refundDetails})
 
 		// Send rejection notification
 		err = s.notificationService.SendRefundRejected(ctx, refundRequest, adminReason)
 		if err != nil {
-			log.Printf("Warning: Failed to send refund rejected notification: %v", err)
+			slog.WarnContext(ctx, "failed to send refund rejected notification", "error", err)
 		}
 	}
 
@@ -329,25 +317,22 @@ func (s *refundService) HandleStripeRefundWebhook(ctx context.Context, stripeRef
 			}
 
 			// Create success event
-			eventData := map[string]interface{}{
-				"refund_id":       refundRequest.ID,
-				"stripe_refund_id": stripeRefund.ID,
-				"status":         models.RefundStatusProcessed,
-				"processed_at":   time.Now(),
-			}
-
-			outboxEvent := &models.Outbox{
-				AggregateID: refundRequest.OrderID,
-				Topic:       "order.events",
-				Type:        "order.refund_completed",
-				Payload:     s.createEventPayload(eventData),
-			}
-			s.outboxRepo.Create(ctx, outboxEvent)
+			s.recordEvent(ctx, refundRequest.OrderID, &events.OrderRefundCompleted{
+				Refund: events.Refund{
+					RefundID:    refundRequest.ID,
+					OrderID:     refundRequest.OrderID,
+					UserID:      refundRequest.UserID,
+					Amount:      refundRequest.Amount,
+					Status:      models.RefundStatusProcessed,
+					ProcessedAt: time.Now(),
+				},
+				StripeRefundID: stripeRefund.ID,
+			})
 
 			// Send completion notification
 			err = s.notificationService.SendRefundCompleted(ctx, refundRequest, stripeRefund)
 			if err != nil {
-				log.Printf("Warning: Failed to send refund completed notification: %v", err)
+				slog.WarnContext(ctx, "failed to send refund completed notification", "error", err)
 			}
 		}
 	}
@@ -394,26 +379,25 @@ func (s *refundService) canRefundOrder(order *models.Order) bool {
 	return true
 }
 
-func isValidRefundReason(reason string) bool {
-	validReasons := []string{
-		"dissatisfaction",
-		"technical_issues",
-		"duplicate_purchase",
-		"accidental_purchase",
-		"course_not_as_described",
-		"other",
-	}
-
-	for _, validReason := range validReasons {
-		if reason == validReason {
-			return true
-		}
-	}
-
-	return false
+// refundReasonCategories maps the reasons a customer can give to the reason_category
+// of the refund request
+var refundReasonCategories = map[string]string{
+	"dissatisfaction":         "quality",
+	"technical_issues":        "technical",
+	"duplicate_purchase":      "duplicate",
+	"accidental_purchase":     "accidental",
+	"course_not_as_described": "content",
+	"other":                   "other",
 }
 
-func (s *refundService) createEventPayload(data map[string]interface{}) []byte {
-	// In production, use proper JSON marshaling
-	return []byte(fmt.Sprintf(`{"event": "order.refund_updated", "data": %v}`, data))
+// recordEvent adds a refund event to the outbox. The refund is already processed by
+// Stripe, so a failure is logged rather than returned
+func (s *refundService) recordEvent(ctx context.Context, orderID uuid.UUID, event events.Event) {
+	outboxEvent, err := newOutboxEvent(orderID, event)
+	if err == nil {
+		err = s.outboxRepo.Create(ctx, outboxEvent)
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "failed to record order event", "event_type", event.EventType(), "order_id", orderID, "error", err)
+	}
 }
\ No newline at end of file
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/apperr"
+	"github.com/ductan2/microservice-app/shared/saga"
+	"github.com/google/uuid"
+
+	"order-services/internal/config"
+	"order-services/internal/dto"
+	"order-services/internal/repositories"
+)
+
+var (
+	ErrSagaNotFound     = apperr.New(apperr.NotFound, "saga not found").WithReason(dto.ErrCodeSagaNotFound)
+	ErrSagaInvalidState = apperr.New(apperr.Conflict, "saga cannot be changed in its current state").WithReason(dto.ErrCodeSagaInvalidState)
+)
+
+const (
+	sagaPollInterval = 5 * time.Second
+	sagaBackoffBase  = 10 * time.Second
+	sagaBackoffMax   = 10 * time.Minute
+)
+
+// SagaService runs the sagas of the order service and lets admins inspect and resume them
+type SagaService interface {
+	StartOrchestrator(ctx context.Context)
+	StopOrchestrator()
+	// StartSaga starts a saga of the given type for an aggregate, inside the
+	// transaction of ctx when it carries one
+	StartSaga(ctx context.Context, sagaType string, aggregateID uuid.UUID) error
+	ListSagas(ctx context.Context, query dto.SagaListQuery) ([]*saga.Instance, int64, error)
+	GetSaga(ctx context.Context, id uuid.UUID) (*saga.Instance, error)
+	// RetrySaga resumes a parked saga at the step that failed
+	RetrySaga(ctx context.Context, id uuid.UUID) (*saga.Instance, error)
+	// CompensateSaga undoes the completed steps of a failed or completed saga
+	CompensateSaga(ctx context.Context, id uuid.UUID) (*saga.Instance, error)
+	// IsStuck reports whether a saga is parked or has been running longer than
+	// SAGA_STUCK_AFTER_MINUTES
+	IsStuck(inst *saga.Instance) bool
+}
+
+// sagaService runs the shared saga orchestrator on the sagas table
+type sagaService struct {
+	sagaRepo     repositories.SagaRepository
+	orchestrator *saga.Orchestrator
+	definitions  map[string]saga.Definition
+	config       *config.Config
+}
+
+// NewSagaService creates a saga service running the given saga definitions
+func NewSagaService(sagaRepo repositories.SagaRepository, config *config.Config, definitions ...saga.Definition) SagaService {
+	byType := make(map[string]saga.Definition, len(definitions))
+	for _, def := range definitions {
+		byType[def.Type] = def
+	}
+
+	return &sagaService{
+		sagaRepo: sagaRepo,
+		orchestrator: saga.New(sagaRepo.Store(), saga.Config{
+			PollInterval: sagaPollInterval,
+			MaxAttempts:  config.SagaMaxAttempts,
+			BackoffBase:  sagaBackoffBase,
+			BackoffMax:   sagaBackoffMax,
+		}, definitions...),
+		definitions: byType,
+		config:      config,
+	}
+}
+
+// StartOrchestrator runs the due sagas in the background
+func (s *sagaService) StartOrchestrator(ctx context.Context) {
+	go s.orchestrator.Start(ctx)
+}
+
+// StopOrchestrator stops the background orchestrator
+func (s *sagaService) StopOrchestrator() {
+	s.orchestrator.Stop()
+}
+
+// StartSaga creates a saga that the orchestrator picks up on its next poll
+func (s *sagaService) StartSaga(ctx context.Context, sagaType string, aggregateID uuid.UUID) error {
+	def, ok := s.definitions[sagaType]
+	if !ok {
+		return fmt.Errorf("unknown saga type %q", sagaType)
+	}
+	return s.sagaRepo.Start(ctx, def.New(aggregateID))
+}
+
+// ListSagas lists sagas, newest first
+func (s *sagaService) ListSagas(ctx context.Context, query dto.SagaListQuery) ([]*saga.Instance, int64, error) {
+	filter := saga.Filter{
+		Type:   query.Type,
+		Status: saga.Status(query.Status),
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	}
+	if query.OrderID != "" {
+		orderID, err := uuid.Parse(query.OrderID)
+		if err != nil {
+			return nil, 0, apperr.New(apperr.BadRequest, "invalid order id").WithReason(dto.ErrCodeBadRequest)
+		}
+		filter.AggregateID = orderID
+	}
+	if query.Stuck {
+		filter.Stuck = true
+		filter.StuckBefore = s.stuckBefore()
+	}
+	return s.sagaRepo.Store().List(ctx, filter)
+}
+
+// GetSaga retrieves a saga by ID
+func (s *sagaService) GetSaga(ctx context.Context, id uuid.UUID) (*saga.Instance, error) {
+	inst, err := s.sagaRepo.Store().Get(ctx, id)
+	return inst, sagaError(err)
+}
+
+// RetrySaga resumes a failed saga, or one whose compensation failed
+func (s *sagaService) RetrySaga(ctx context.Context, id uuid.UUID) (*saga.Instance, error) {
+	inst, err := s.orchestrator.Retry(ctx, id)
+	return inst, sagaError(err)
+}
+
+// CompensateSaga starts compensating a saga
+func (s *sagaService) CompensateSaga(ctx context.Context, id uuid.UUID) (*saga.Instance, error) {
+	inst, err := s.orchestrator.Compensate(ctx, id)
+	return inst, sagaError(err)
+}
+
+func (s *sagaService) IsStuck(inst *saga.Instance) bool {
+	return inst.Status.Parked() || inst.Status.Active() && inst.CreatedAt.Before(s.stuckBefore())
+}
+
+func (s *sagaService) stuckBefore() time.Time {
+	return time.Now().Add(-time.Duration(s.config.SagaStuckAfterMinutes) * time.Minute)
+}
+
+// sagaError maps the errors of the saga package to the errors of the service
+func sagaError(err error) error {
+	switch {
+	case errors.Is(err, saga.ErrNotFound):
+		return ErrSagaNotFound
+	case errors.Is(err, saga.ErrInvalidState):
+		return ErrSagaInvalidState
+	}
+	return err
+}
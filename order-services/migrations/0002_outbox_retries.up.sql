@@ -0,0 +1,18 @@
+-- Outbox retries ---------------------------------------------------------------
+-- Columns the shared outbox relay (shared/outbox) needs: a failed publish is retried
+-- with exponential backoff, attempts counting the failures and next_attempt_at holding
+-- the event back until its backoff has passed. After OUTBOX_MAX_ATTEMPTS failures the
+-- event is parked with failed_at set; clearing failed_at requeues it.
+ALTER TABLE outbox
+    ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS last_error TEXT,
+    ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ADD COLUMN IF NOT EXISTS failed_at TIMESTAMPTZ;
+
+DROP INDEX IF EXISTS outbox_unpublished_idx;
+CREATE INDEX IF NOT EXISTS outbox_due_idx
+    ON outbox (next_attempt_at, created_at)
+    WHERE published_at IS NULL AND failed_at IS NULL;
+CREATE INDEX IF NOT EXISTS outbox_published_idx
+    ON outbox (published_at)
+    WHERE published_at IS NOT NULL;
@@ -0,0 +1,24 @@
+-- Sagas ----------------------------------------------------------------------------
+-- State of the sagas run by shared/saga: the purchase saga of every paid order. steps
+-- records the progress of each step; the orchestrator claims due sagas by leasing them
+-- with locked_until, so replicas do not run the same saga.
+CREATE TABLE IF NOT EXISTS sagas (
+    id UUID PRIMARY KEY,
+    type TEXT NOT NULL,
+    aggregate_id UUID NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('running','completed','failed','compensating','compensated','compensation_failed')),
+    step INT NOT NULL DEFAULT 0,
+    steps JSONB NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    locked_until TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS sagas_type_aggregate_idx ON sagas (type, aggregate_id);
+CREATE INDEX IF NOT EXISTS sagas_due_idx
+    ON sagas (next_attempt_at)
+    WHERE status IN ('running','compensating');
+CREATE INDEX IF NOT EXISTS sagas_created_idx ON sagas (created_at);
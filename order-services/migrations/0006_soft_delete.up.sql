@@ -0,0 +1,14 @@
+-- Soft deletes -----------------------------------------------------------------------
+-- Deleting an order or a coupon keeps its row with deleted_at and deleted_by, following
+-- shared/softdelete, so redemptions, invoices and refunds still point to it and an admin
+-- can restore it. A deleted coupon frees its code: the code is unique among the coupons
+-- that are not deleted only.
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS deleted_by TEXT;
+
+ALTER TABLE coupons
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS deleted_by TEXT;
+
+ALTER TABLE coupons DROP CONSTRAINT IF EXISTS coupons_code_key;
+CREATE UNIQUE INDEX IF NOT EXISTS coupons_code_active_idx ON coupons (code) WHERE deleted_at IS NULL;
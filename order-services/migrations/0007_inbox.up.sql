@@ -0,0 +1,11 @@
+-- Inbox --------------------------------------------------------------------------------
+-- The user.erasure_requested events already handled, see shared/inbox/gormstore; rows
+-- older than INBOX_RETENTION are deleted by the server
+CREATE TABLE IF NOT EXISTS inbox (
+    consumer TEXT NOT NULL,
+    message_id TEXT NOT NULL,
+    locked_until TIMESTAMPTZ NOT NULL,
+    processed_at TIMESTAMPTZ,
+    PRIMARY KEY (consumer, message_id)
+);
+CREATE INDEX IF NOT EXISTS inbox_processed_idx ON inbox (processed_at);
@@ -1,10 +1,10 @@
 package utils
 
 import (
-	"errors"
 	"net/http"
 	"time"
 
+	"github.com/ductan2/microservice-app/shared/apperr"
 	"github.com/gin-gonic/gin"
 	"order-services/internal/dto"
 )
@@ -65,29 +65,33 @@ func ValidationError(c *gin.Context, err error) {
 	ErrorResponse(c, http.StatusBadRequest, dto.ErrCodeValidationFailed, err.Error())
 }
 
-// Error type checking functions
+// Error type checking functions, classifying the *apperr.Error values returned by the
+// services by their canonical code or reason
+
 func IsValidationError(err error) bool {
-	return false // Placeholder - would check for specific validation error types
+	code := apperr.CodeOf(err)
+	return code == apperr.BadRequest || code == apperr.ValidationFailed
 }
 
 func IsNotFoundError(err error) bool {
-	return false // Placeholder - would check for specific not found error types
+	return apperr.CodeOf(err) == apperr.NotFound
 }
 
 func IsUnauthorizedError(err error) bool {
-	return false // Placeholder - would check for specific unauthorized error types
+	code := apperr.CodeOf(err)
+	return code == apperr.Unauthorized || code == apperr.Forbidden
 }
 
 func IsConflictError(err error) bool {
-	return false // Placeholder - would check for specific conflict error types
+	return apperr.CodeOf(err) == apperr.Conflict
 }
 
 func IsExpiredError(err error) bool {
-	return false // Placeholder - would check for specific expired error types
+	return apperr.HasReason(err, dto.ErrCodeOrderExpired, dto.ErrCodeCouponExpired, dto.ErrCodeRefundWindowExpired)
 }
 
 func IsInactiveError(err error) bool {
-	return false // Placeholder - would check for specific inactive error types
+	return apperr.HasReason(err, dto.ErrCodeCouponInactive, dto.ErrCodeCouponNotStarted)
 }
 
 // IsAdmin checks if the current user has admin role
@@ -95,88 +99,3 @@ func IsAdmin(c *gin.Context) bool {
 	role, exists := c.Get("user_role")
 	return exists && role == "admin"
 }
-
-// Custom error types for better error handling
-type (
-	AppValidationError struct {
-		Field   string
-		Message string
-		Value   interface{}
-	}
-
-	NotFoundError struct {
-		Entity string
-		ID     string
-	}
-
-	UnauthorizedError struct {
-		Message string
-	}
-
-	ConflictError struct {
-		Message string
-	}
-
-	ExpiredError struct {
-		Entity string
-	}
-
-	InactiveError struct {
-		Entity string
-	}
-)
-
-func (e AppValidationError) Error() string {
-	return e.Message
-}
-
-func (e NotFoundError) Error() string {
-	return e.Entity + " not found"
-}
-
-func (e UnauthorizedError) Error() string {
-	return e.Message
-}
-
-func (e ConflictError) Error() string {
-	return e.Message
-}
-
-func (e ExpiredError) Error() string {
-	return e.Entity + " has expired"
-}
-
-func (e InactiveError) Error() string {
-	return e.Entity + " is inactive"
-}
-
-// Error checking functions with proper type checking
-func IsValidationErrorType(err error) bool {
-	var validationErr AppValidationError
-	return errors.As(err, &validationErr)
-}
-
-func IsNotFoundErrorType(err error) bool {
-	var notFoundErr NotFoundError
-	return errors.As(err, &notFoundErr)
-}
-
-func IsUnauthorizedErrorType(err error) bool {
-	var unauthorizedErr UnauthorizedError
-	return errors.As(err, &unauthorizedErr)
-}
-
-func IsConflictErrorType(err error) bool {
-	var conflictErr ConflictError
-	return errors.As(err, &conflictErr)
-}
-
-func IsExpiredErrorType(err error) bool {
-	var expiredErr ExpiredError
-	return errors.As(err, &expiredErr)
-}
-
-func IsInactiveErrorType(err error) bool {
-	var inactiveErr InactiveError
-	return errors.As(err, &inactiveErr)
-}
\ No newline at end of file
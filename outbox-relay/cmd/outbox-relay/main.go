@@ -0,0 +1,118 @@
+// Command outbox-relay publishes the outbox tables of the services to RabbitMQ outside
+// of their API processes, so events keep flowing while a service restarts or scales.
+// Replicas claim batches with row locks, so any number of them can share the targets.
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"outbox-relay/internal/config"
+	"outbox-relay/internal/relay"
+
+	"github.com/ductan2/microservice-app/shared/envconfig"
+	"github.com/ductan2/microservice-app/shared/health"
+	"github.com/ductan2/microservice-app/shared/lifecycle"
+	"github.com/ductan2/microservice-app/shared/logging"
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/ductan2/microservice-app/shared/secrets"
+	"github.com/ductan2/microservice-app/shared/telemetry"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// drainDelay is how long readiness fails before the relays stop.
+const drainDelay = 2 * time.Second
+
+func main() {
+	// Settings such as RELAY_ORDER_SERVICES_DATABASE_URL=secret://outbox-relay/orders are
+	// read from the secrets manager named by SECRETS_PROVIDER
+	secretStore, secretsErr := secrets.FromEnv()
+	if secretStore != nil {
+		envconfig.UseSecrets(secretStore)
+	}
+	// "outbox-relay config validate" checks the configuration of a deployment and exits
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(envconfig.Command(os.Args[2:], os.Stdout, func() (any, error) { return config.Load() }))
+	}
+	cfg, err := config.Load()
+	logging.Setup(logging.ConfigFromEnv("outbox-relay"))
+	if secretsErr != nil {
+		logging.Fatal("invalid secrets configuration", "error", secretsErr)
+	}
+	if err != nil {
+		logging.Fatal("invalid configuration", "error", err)
+	}
+	slog.Info("configuration loaded", "config", envconfig.Values(cfg), "targets", cfg.TargetNames)
+
+	// Components are stopped in the reverse order they are added, on SIGINT or SIGTERM
+	app := lifecycle.New(lifecycle.Config{})
+	if secretStore != nil {
+		app.Add(lifecycle.Worker("secrets", secretStore.Run))
+	}
+
+	// Publishes continue the traces of the requests that wrote the events
+	tracer := telemetry.Setup(telemetry.ConfigFromEnv("outbox-relay"))
+	app.Add(lifecycle.Component{Name: "tracer", Stop: tracer.Shutdown})
+	metrics.SetBuildInfo("outbox-relay")
+
+	conn, err := amqp.Dial(cfg.RabbitMQURL)
+	if err != nil {
+		logging.Fatal("failed to connect to RabbitMQ", "error", err)
+	}
+	app.Add(lifecycle.Closer("rabbitmq", conn))
+	// The relay has nothing to do without the broker: exit when the connection is lost,
+	// to be restarted
+	closed := conn.NotifyClose(make(chan *amqp.Error, 1))
+	app.Add(lifecycle.Component{
+		Name: "rabbitmq-watch",
+		Run: func(ctx context.Context) error {
+			select {
+			case err := <-closed:
+				if err != nil {
+					return err
+				}
+				return errors.New("RabbitMQ connection closed")
+			case <-ctx.Done():
+				return nil
+			}
+		},
+	})
+
+	checker := health.New(health.Config{Service: "outbox-relay"})
+	checker.Register("rabbitmq", health.Critical, func(context.Context) error {
+		if conn.IsClosed() {
+			return errors.New("connection closed")
+		}
+		return nil
+	})
+
+	for _, targetCfg := range cfg.Targets {
+		target, err := relay.Open(cfg, targetCfg, conn)
+		if err != nil {
+			logging.Fatal("failed to open outbox target", "error", err)
+		}
+		app.Add(lifecycle.Closer("postgres-"+target.Name, target.DB))
+		metrics.RegisterPool("postgres-"+target.Name, metrics.SQLPool(target.DB))
+		metrics.Default.OnScrape(target.ObserveBacklog)
+		checker.Register("postgres-"+target.Name, health.Critical, target.DB.PingContext)
+		app.Add(lifecycle.Worker("relay-"+target.Name, target.Relay.Start))
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	mux.Handle(health.LivePath, checker.Live())
+	mux.Handle(health.ReadyPath, checker.Ready())
+	mux.Handle(health.HealthPath, checker.Health())
+	server := &http.Server{Addr: ":" + cfg.Port, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	app.Add(lifecycle.HTTPServer("http", server))
+	app.Add(lifecycle.Drain(checker.Shutdown, drainDelay))
+
+	slog.Info("outbox relay starting", "port", cfg.Port, "targets", len(cfg.Targets))
+	if err := app.Run(context.Background()); err != nil {
+		logging.Fatal("relay stopped with errors", "error", err)
+	}
+}
@@ -0,0 +1,208 @@
+// Command outbox-replay publishes again the events a service already published, from its
+// outbox table, to rebuild a read model or to feed a consumer that mishandled them. It
+// reads the targets of outbox-relay from the same environment:
+//
+//	outbox-replay -target order-services -type order.paid -from 2025-03-01T00:00:00Z -queue notifications.orders
+//
+// Events go to the exchanges of their first delivery unless -exchange or -queue is
+// given. Each replay has an ID, sent with its events, so the consumers' inboxes process
+// them again; a replay that fails prints the ID to resume after with -after.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"outbox-relay/internal/config"
+	"outbox-relay/internal/relay"
+
+	"github.com/ductan2/microservice-app/shared/envconfig"
+	"github.com/ductan2/microservice-app/shared/logging"
+	"github.com/ductan2/microservice-app/shared/outbox"
+	"github.com/ductan2/microservice-app/shared/outbox/gormstore"
+	"github.com/ductan2/microservice-app/shared/secrets"
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+type options struct {
+	target    string
+	replay    outbox.ReplayConfig
+	exchange  string
+	queue     string
+	aggregate string
+	from, to  string
+	topics    string
+	types     string
+}
+
+func main() {
+	opts := parseFlags()
+
+	secretStore, secretsErr := secrets.FromEnv()
+	if secretStore != nil {
+		envconfig.UseSecrets(secretStore)
+	}
+	cfg, err := config.Load()
+	logging.Setup(logging.ConfigFromEnv("outbox-replay"))
+	if secretsErr != nil {
+		logging.Fatal("invalid secrets configuration", "error", secretsErr)
+	}
+	if err != nil {
+		logging.Fatal("invalid configuration", "error", err)
+	}
+	if err := opts.resolve(); err != nil {
+		logging.Fatal("invalid flags", "error", err)
+	}
+	target, err := findTarget(cfg, opts.target)
+	if err != nil {
+		logging.Fatal("invalid flags", "error", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	gormDB, sqlDB, err := relay.OpenDB(target)
+	if err != nil {
+		logging.Fatal("failed to open outbox target", "error", err)
+	}
+	defer sqlDB.Close()
+	store := gormstore.New(gormDB, target.Table)
+
+	var publisher outbox.Publisher = dryRunPublisher{}
+	if !opts.replay.DryRun {
+		conn, err := amqp.Dial(cfg.RabbitMQURL)
+		if err != nil {
+			logging.Fatal("failed to connect to RabbitMQ", "error", err)
+		}
+		defer conn.Close()
+		publisher, err = newPublisher(conn, cfg, target, opts)
+		if err != nil {
+			logging.Fatal("failed to start publisher", "error", err)
+		}
+	}
+
+	slog.Info("outbox replay starting", "replay", opts.replay.ID, "target", target.Name, "filter", opts.replay.Filter, "after", opts.replay.After, "dry_run", opts.replay.DryRun)
+	result, err := outbox.Replay(ctx, store, publisher, opts.replay)
+	if err != nil {
+		logging.Fatal("outbox replay failed", "replay", opts.replay.ID, "error", err,
+			"published", result.Published, "resume_with", fmt.Sprintf("-id %s -after %s", opts.replay.ID, result.LastID))
+	}
+	slog.Info("outbox replay finished", "replay", opts.replay.ID, "matched", result.Matched, "published", result.Published, "last_id", result.LastID)
+}
+
+func parseFlags() *options {
+	opts := &options{}
+	flag.StringVar(&opts.target, "target", "", "outbox target to replay, one of RELAY_TARGETS (required)")
+	flag.StringVar(&opts.replay.ID, "id", "", "replay ID sent with the events; reuse it to resume (default replay-<time>)")
+	flag.StringVar(&opts.topics, "topic", "", "comma separated topics to replay")
+	flag.StringVar(&opts.types, "type", "", "comma separated event types to replay")
+	flag.StringVar(&opts.aggregate, "aggregate", "", "aggregate ID to replay the events of")
+	flag.StringVar(&opts.from, "from", "", "replay events created at or after this RFC 3339 time")
+	flag.StringVar(&opts.to, "to", "", "replay events created before this RFC 3339 time")
+	flag.StringVar(&opts.exchange, "exchange", "", "routes to publish with instead of the target's, such as \"*=order.events\"")
+	flag.StringVar(&opts.queue, "queue", "", "queue to publish every event to, for a single consumer")
+	flag.StringVar(&opts.replay.After, "after", "", "resume after the event with this outbox ID")
+	flag.IntVar(&opts.replay.Max, "max", 0, "stop after this many events, 0 for no limit")
+	flag.IntVar(&opts.replay.BatchSize, "batch", outbox.DefaultReplayBatchSize, "events read at a time")
+	flag.DurationVar(&opts.replay.Pause, "pause", 0, "pause between batches")
+	flag.BoolVar(&opts.replay.DryRun, "dry-run", false, "count the matching events without publishing them")
+	flag.Parse()
+	return opts
+}
+
+// resolve checks the flags and builds the filter of the replay.
+func (o *options) resolve() error {
+	var errs []error
+	if o.target == "" {
+		errs = append(errs, errors.New("-target is required"))
+	}
+	if o.exchange != "" && o.queue != "" {
+		errs = append(errs, errors.New("-exchange and -queue are exclusive"))
+	}
+	if o.replay.ID == "" {
+		o.replay.ID = "replay-" + time.Now().UTC().Format("20060102T150405Z")
+	}
+
+	filter := outbox.Filter{Topics: splitList(o.topics), Types: splitList(o.types)}
+	if o.aggregate != "" {
+		id, err := uuid.Parse(o.aggregate)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("-aggregate: %w", err))
+		}
+		filter.AggregateID = id
+	}
+	for _, bound := range []struct {
+		name  string
+		value string
+		dst   *time.Time
+	}{{"-from", o.from, &filter.From}, {"-to", o.to, &filter.To}} {
+		if bound.value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, bound.value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", bound.name, err))
+		}
+		*bound.dst = t
+	}
+	o.replay.Filter = filter
+	return errors.Join(errs...)
+}
+
+func findTarget(cfg *config.Config, name string) (config.Target, error) {
+	for _, target := range cfg.Targets {
+		if target.Name == name {
+			return target, nil
+		}
+	}
+	return config.Target{}, fmt.Errorf("unknown target %q, want one of %s", name, strings.Join(cfg.TargetNames, ", "))
+}
+
+// newPublisher publishes on a channel of conn with the routes of target, or those the
+// flags give.
+func newPublisher(conn *amqp.Connection, cfg *config.Config, target config.Target, opts *options) (outbox.Publisher, error) {
+	channel, err := conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+	route := target.Route
+	switch {
+	case opts.queue != "":
+		// The default exchange drops messages for a missing queue without an error
+		if _, err := channel.QueueDeclarePassive(opts.queue, true, false, false, false, nil); err != nil {
+			return nil, fmt.Errorf("queue %s: %w", opts.queue, err)
+		}
+		route = outbox.ToQueue(opts.queue)
+	case opts.exchange != "":
+		if route, err = outbox.ParseRoutes(opts.exchange); err != nil {
+			return nil, fmt.Errorf("-exchange: %w", err)
+		}
+	}
+	return outbox.NewAMQPPublisher(channel, route, cfg.ConfirmTimeout)
+}
+
+// dryRunPublisher is never called: dry runs only read the outbox.
+type dryRunPublisher struct{}
+
+func (dryRunPublisher) Publish(context.Context, outbox.Message) error {
+	return errors.New("dry run")
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
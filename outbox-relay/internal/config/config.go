@@ -0,0 +1,116 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/envconfig"
+	"github.com/ductan2/microservice-app/shared/outbox"
+)
+
+// Config holds the settings shared by every target of the relay
+type Config struct {
+	// Port serves /metrics and the health endpoints
+	Port string `env:"PORT" envDefault:"8020"`
+
+	RabbitMQURL string `env:"RABBITMQ_URL,required,secret"`
+
+	// TargetNames lists the outbox tables to relay, such as "user-services,order-services";
+	// each one is configured by the RELAY_<NAME>_* variables, see Target
+	TargetNames []string `env:"RELAY_TARGETS,required"`
+
+	PollInterval time.Duration `env:"RELAY_POLL_INTERVAL" envDefault:"1s"`
+	BatchSize    int           `env:"RELAY_BATCH_SIZE" envDefault:"100"`
+	// MaxAttempts is how many times an event is published before it is parked as failed
+	MaxAttempts int `env:"RELAY_MAX_ATTEMPTS" envDefault:"10"`
+	// BackoffBase is the delay after the first failed publish; it doubles with every
+	// further failure up to BackoffMax
+	BackoffBase time.Duration `env:"RELAY_BACKOFF_BASE" envDefault:"5s"`
+	BackoffMax  time.Duration `env:"RELAY_BACKOFF_MAX" envDefault:"10m"`
+	// ConfirmTimeout is how long a publish waits for RabbitMQ to confirm the event
+	ConfirmTimeout time.Duration `env:"RELAY_CONFIRM_TIMEOUT" envDefault:"5s"`
+	// Lease is how long a claimed batch is hidden from the other replicas; it must
+	// exceed the time a batch takes to publish
+	Lease time.Duration `env:"RELAY_LEASE" envDefault:"1m"`
+	// Retention is how long published events are kept; 0 keeps them
+	Retention time.Duration `env:"RELAY_RETENTION"`
+
+	Targets []Target `env:"-"`
+}
+
+// Target is the outbox table of a service, configured by the variables prefixed with
+// RELAY_ and its name in upper case, such as RELAY_ORDER_SERVICES_DATABASE_URL.
+type Target struct {
+	Name string `env:"-"`
+
+	// DatabaseURL is the PostgreSQL connection string of the service's database
+	DatabaseURL string `env:"DATABASE_URL,required,secret"`
+	Table       string `env:"TABLE" envDefault:"outbox"`
+	// Routes maps the topics of the events to exchanges, see outbox.ParseRoutes:
+	// "user.*=user.events" publishes to user.events with the topic as routing key, and
+	// "*=*" to the exchange named after the topic with the event type as routing key
+	Routes string `env:"ROUTES,required"`
+	// DeclareExchanges are declared as durable topic exchanges at startup, for the
+	// exchanges no consumer declares
+	DeclareExchanges []string `env:"DECLARE_EXCHANGES"`
+	// Validate checks the payloads against the event schemas of shared/events
+	Validate bool `env:"VALIDATE" envDefault:"true"`
+
+	Route outbox.Route `env:"-"`
+}
+
+// Endpoints lists RabbitMQ and the database of each target, dialed by
+// "outbox-relay config validate -connect".
+func (c *Config) Endpoints() []envconfig.Endpoint {
+	endpoints := []envconfig.Endpoint{{Name: "rabbitmq", Address: c.RabbitMQURL}}
+	for _, target := range c.Targets {
+		endpoints = append(endpoints, envconfig.Endpoint{Name: target.Name, Address: target.DatabaseURL})
+	}
+	return endpoints
+}
+
+// Prefix returns the prefix of the variables of the target.
+func (t Target) Prefix() string {
+	return "RELAY_" + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(t.Name)) + "_"
+}
+
+// Load reads the configuration from the environment and .env, with the settings of each
+// target, and reports every invalid value at once.
+func Load() (*Config, error) {
+	cfg := &Config{}
+	var errs []error
+	if err := envconfig.Load(cfg); err != nil {
+		errs = append(errs, err)
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range cfg.TargetNames {
+		target := Target{Name: name}
+		if seen[target.Prefix()] {
+			errs = append(errs, fmt.Errorf("target %s is listed twice", name))
+			continue
+		}
+		seen[target.Prefix()] = true
+
+		if err := envconfig.ProcessPrefix(target.Prefix(), &target); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		route, err := outbox.ParseRoutes(target.Routes)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid %sROUTES: %w", target.Prefix(), err))
+			continue
+		}
+		target.Route = route
+		cfg.Targets = append(cfg.Targets, target)
+	}
+	if cfg.BatchSize <= 0 || cfg.MaxAttempts <= 0 {
+		errs = append(errs, errors.New("RELAY_BATCH_SIZE and RELAY_MAX_ATTEMPTS must be positive"))
+	}
+	if cfg.PollInterval <= 0 || cfg.Lease <= 0 {
+		errs = append(errs, errors.New("RELAY_POLL_INTERVAL and RELAY_LEASE must be positive"))
+	}
+	return cfg, errors.Join(errs...)
+}
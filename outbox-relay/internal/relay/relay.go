@@ -0,0 +1,151 @@
+// Package relay publishes the outbox of one service from the standalone relay.
+package relay
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"outbox-relay/internal/config"
+
+	"github.com/ductan2/microservice-app/shared/events"
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/ductan2/microservice-app/shared/outbox"
+	"github.com/ductan2/microservice-app/shared/outbox/gormstore"
+	"github.com/ductan2/microservice-app/shared/telemetry/gormtrace"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var (
+	publishedTotal = promauto.With(metrics.Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "outbox_relay_published_total",
+		Help: "Outbox events published and confirmed by RabbitMQ, by target and topic.",
+	}, []string{"target", "topic"})
+	publishFailuresTotal = promauto.With(metrics.Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "outbox_relay_publish_failures_total",
+		Help: "Failed publishes of outbox events; parked is true for the failure that parks an event.",
+	}, []string{"target", "topic", "parked"})
+	lagSeconds = promauto.With(metrics.Default).NewGaugeVec(prometheus.GaugeOpts{
+		Name: "outbox_relay_lag_seconds",
+		Help: "Age of the oldest pending outbox event of a target, 0 when none is pending.",
+	}, []string{"target"})
+	failedEvents = promauto.With(metrics.Default).NewGaugeVec(prometheus.GaugeOpts{
+		Name: "outbox_relay_failed_events",
+		Help: "Outbox events of a target parked after too many failed publishes.",
+	}, []string{"target"})
+)
+
+// Target is the relay of the outbox table of one service.
+type Target struct {
+	Name  string
+	Relay *outbox.Relay
+	DB    *sql.DB
+	store *gormstore.Store
+}
+
+// Open connects to the database of target and builds its relay, publishing on a channel
+// of its own of conn since the publisher waits for confirms.
+func Open(cfg *config.Config, target config.Target, conn *amqp.Connection) (*Target, error) {
+	gormDB, sqlDB, err := OpenDB(target)
+	if err != nil {
+		return nil, err
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("target %s: failed to open channel: %w", target.Name, err)
+	}
+	publisher, err := outbox.NewAMQPPublisher(channel, target.Route, cfg.ConfirmTimeout)
+	if err == nil {
+		err = publisher.DeclareExchanges(target.DeclareExchanges...)
+	}
+	if err != nil {
+		channel.Close()
+		sqlDB.Close()
+		return nil, fmt.Errorf("target %s: %w", target.Name, err)
+	}
+
+	relayCfg := outbox.Config{
+		PollInterval: cfg.PollInterval,
+		BatchSize:    cfg.BatchSize,
+		MaxAttempts:  cfg.MaxAttempts,
+		BackoffBase:  cfg.BackoffBase,
+		BackoffMax:   cfg.BackoffMax,
+		Retention:    cfg.Retention,
+		Lease:        cfg.Lease,
+		Metrics:      targetMetrics{target: target.Name},
+	}
+	if target.Validate {
+		relayCfg.Validator = events.Schemas
+	}
+	store := gormstore.New(gormDB, target.Table)
+	slog.Info("outbox target ready", "target", target.Name, "table", target.Table, "routes", target.Routes)
+	return &Target{
+		Name:  target.Name,
+		Relay: outbox.NewRelay(store, publisher, relayCfg),
+		DB:    sqlDB,
+		store: store,
+	}, nil
+}
+
+// OpenDB connects to the database of target.
+func OpenDB(target config.Target) (*gorm.DB, *sql.DB, error) {
+	gormDB, err := gorm.Open(postgres.Open(target.DatabaseURL), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+		NowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("target %s: failed to connect to database: %w", target.Name, err)
+	}
+	// Continue the traces of the requests that wrote the events
+	if err := gormDB.Use(gormtrace.Plugin{}); err != nil {
+		return nil, nil, fmt.Errorf("target %s: failed to install tracing: %w", target.Name, err)
+	}
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		return nil, nil, fmt.Errorf("target %s: failed to get sql.DB: %w", target.Name, err)
+	}
+	sqlDB.SetMaxOpenConns(4)
+	sqlDB.SetConnMaxLifetime(time.Hour)
+	if err := sqlDB.Ping(); err != nil {
+		sqlDB.Close()
+		return nil, nil, fmt.Errorf("target %s: failed to ping database: %w", target.Name, err)
+	}
+	return gormDB, sqlDB, nil
+}
+
+// ObserveBacklog records the backlog of the outbox in the metrics.
+func (t *Target) ObserveBacklog(ctx context.Context) {
+	stats, err := t.store.Stats(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to read outbox stats", "target", t.Name, "error", err)
+		return
+	}
+	metrics.QueueDepth.WithLabelValues("outbox:" + t.Name).Set(float64(stats.Pending))
+	lagSeconds.WithLabelValues(t.Name).Set(stats.Lag(time.Now()).Seconds())
+	failedEvents.WithLabelValues(t.Name).Set(float64(stats.Failed))
+}
+
+// targetMetrics counts the publishes of the relay of a target.
+type targetMetrics struct {
+	target string
+}
+
+func (m targetMetrics) Published(msg outbox.Message) {
+	publishedTotal.WithLabelValues(m.target, msg.Topic).Inc()
+}
+
+func (m targetMetrics) Failed(msg outbox.Message, parked bool) {
+	publishFailuresTotal.WithLabelValues(m.target, msg.Topic, strconv.FormatBool(parked)).Inc()
+}
@@ -49,8 +49,14 @@ build_and_push() {
     return 1
   fi
   
+  # Services using the shared Go modules are built from the repository root
+  local context="${service}"
+  if grep -q "=> ../shared/" "${service}/go.mod" 2>/dev/null; then
+    context="."
+  fi
+
   # Build the image
-  docker build -t "${image_name}" -f "${service}/Dockerfile" "${service}"
+  docker build -t "${image_name}" -f "${service}/Dockerfile" "${context}"
   
   if [ $? -ne 0 ]; then
     print_message "$RED" "Failed to build ${service}"
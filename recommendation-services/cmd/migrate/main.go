@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"recommendation-services/internal/db"
+
+	"github.com/ductan2/microservice-app/shared/envconfig"
+	"github.com/ductan2/microservice-app/shared/logging"
+	"github.com/ductan2/microservice-app/shared/migrate"
+	"github.com/ductan2/microservice-app/shared/secrets"
+)
+
+func main() {
+	var dir string
+	flag.StringVar(&dir, "dir", "", "path to a migrations directory to use instead of the embedded migrations (defaults to MIGRATIONS_DIR)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: migrate [-dir path] [command]\n%s\n", migrate.Usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	logging.Setup(logging.ConfigFromEnv("recommendation-services-migrate"))
+
+	// Only the database is needed, DATABASE_URL possibly a secret:// reference
+	secretStore, err := secrets.FromEnv()
+	if err != nil {
+		logging.Fatal("invalid secrets configuration", "error", err)
+	}
+	if secretStore != nil {
+		envconfig.UseSecrets(secretStore)
+	}
+	var cfg struct {
+		DatabaseURL string `env:"DATABASE_URL,required,secret"`
+	}
+	if err := envconfig.Load(&cfg); err != nil {
+		logging.Fatal("invalid configuration", "error", err)
+	}
+
+	gormDB, err := db.Connect(cfg.DatabaseURL, false)
+	if err != nil {
+		logging.Fatal("failed to connect to database", "error", err)
+	}
+	sqlDB, err := gormDB.DB()
+	if err == nil {
+		defer sqlDB.Close()
+	}
+
+	migrator, err := db.Migrator(gormDB, dir)
+	if err != nil {
+		logging.Fatal("failed to load migrations", "error", err)
+	}
+	if err := migrate.Command(context.Background(), migrator, flag.Args(), os.Stdout); err != nil {
+		logging.Fatal("migration failed", "error", err)
+	}
+}
@@ -0,0 +1,169 @@
+// Command server runs recommendation-services: it keeps the feature profiles of the
+// learners from the progress events of lesson-services, and the catalog from the content
+// events of content-services, and serves the recommendations the BFF merges into the
+// dashboard.
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"recommendation-services/internal/config"
+	"recommendation-services/internal/db"
+	"recommendation-services/internal/handler"
+	"recommendation-services/internal/recommend"
+	"recommendation-services/internal/server"
+	"recommendation-services/internal/store"
+
+	"github.com/ductan2/microservice-app/shared/consumer"
+	"github.com/ductan2/microservice-app/shared/envconfig"
+	"github.com/ductan2/microservice-app/shared/health"
+	"github.com/ductan2/microservice-app/shared/inbox/gormstore"
+	"github.com/ductan2/microservice-app/shared/internalauth"
+	"github.com/ductan2/microservice-app/shared/lifecycle"
+	"github.com/ductan2/microservice-app/shared/logging"
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/ductan2/microservice-app/shared/secrets"
+	"github.com/ductan2/microservice-app/shared/telemetry"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// drainDelay is how long readiness fails before the server stops.
+const drainDelay = 2 * time.Second
+
+// inboxCleanupInterval is how often the IDs of progress events older than the retention
+// are deleted.
+const inboxCleanupInterval = time.Hour
+
+func main() {
+	secretStore, secretsErr := secrets.FromEnv()
+	if secretStore != nil {
+		envconfig.UseSecrets(secretStore)
+	}
+	// "recommendation-services config validate" checks the configuration of a deployment and exits
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(envconfig.Command(os.Args[2:], os.Stdout, func() (any, error) { return config.Load() }))
+	}
+	cfg, err := config.Load()
+	logging.Setup(logging.ConfigFromEnv("recommendation-services"))
+	if secretsErr != nil {
+		logging.Fatal("invalid secrets configuration", "error", secretsErr)
+	}
+	if err != nil {
+		logging.Fatal("invalid configuration", "error", err)
+	}
+	slog.Info("configuration loaded", "config", envconfig.Values(cfg))
+
+	// Components are stopped in the reverse order they are added, on SIGINT or SIGTERM
+	app := lifecycle.New(lifecycle.Config{})
+	if secretStore != nil {
+		app.Add(lifecycle.Worker("secrets", secretStore.Run))
+	}
+	tracer := telemetry.Setup(telemetry.ConfigFromEnv("recommendation-services"))
+	app.Add(lifecycle.Component{Name: "tracer", Stop: tracer.Shutdown})
+	metrics.SetBuildInfo("recommendation-services")
+
+	gormDB, err := db.Connect(cfg.DatabaseURL, cfg.Development)
+	if err != nil {
+		logging.Fatal("failed to connect to database", "error", err)
+	}
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		logging.Fatal("failed to get underlying sql.DB", "error", err)
+	}
+	app.Add(lifecycle.Closer("postgres", sqlDB))
+	metrics.RegisterPool("postgres", metrics.SQLPool(sqlDB))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	err = db.RunMigrations(ctx, gormDB, cfg.MigrateOnStart)
+	cancel()
+	if err != nil {
+		logging.Fatal("database migration failed", "error", err)
+	}
+
+	conn, err := amqp.Dial(cfg.RabbitMQURL)
+	if err != nil {
+		logging.Fatal("failed to connect to RabbitMQ", "error", err)
+	}
+	app.Add(lifecycle.Closer("rabbitmq", conn))
+
+	profiles := store.New(gormDB)
+	inboxStore := gormstore.New(gormDB, "")
+	events := handler.New(profiles, inboxStore)
+	// Events wait in their queue while Postgres is down: the consumers retry them
+	content := consumer.New(conn, consumer.Config{
+		Exchange:    "content.events",
+		Queue:       cfg.ContentQueue,
+		Concurrency: cfg.Concurrency,
+		Metrics:     handler.Metrics{},
+	})
+	events.RegisterContent(content)
+	app.Add(lifecycle.Component{Name: "content-events", Run: content.Run})
+	progress := consumer.New(conn, consumer.Config{
+		Exchange:    "progress.events",
+		Queue:       cfg.ProgressQueue,
+		Concurrency: cfg.Concurrency,
+		Metrics:     handler.Metrics{},
+	})
+	events.RegisterProgress(progress)
+	app.Add(lifecycle.Component{Name: "progress-events", Run: progress.Run})
+	app.Add(lifecycle.Worker("inbox-cleanup", func(ctx context.Context) {
+		ticker := time.NewTicker(inboxCleanupInterval)
+		defer ticker.Stop()
+		for {
+			if err := inboxStore.DeleteProcessedBefore(ctx, time.Now().Add(-cfg.InboxRetention)); err != nil && ctx.Err() == nil {
+				slog.WarnContext(ctx, "failed to clean up the inbox", "error", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}))
+
+	// Recommendations need Postgres; profiles fall behind without RabbitMQ but
+	// recommendations are still served
+	checker := health.New(health.Config{Service: "recommendation-services"})
+	checker.Register("postgres", health.Critical, sqlDB.PingContext)
+	checker.Register("rabbitmq", health.Optional, func(context.Context) error {
+		if conn.IsClosed() {
+			return errors.New("connection closed")
+		}
+		return nil
+	})
+
+	// The BFF authenticates with service tokens signed with the shared keys
+	internalAuth, err := internalauth.ConfigFromEnv("recommendation-services")
+	if err != nil {
+		logging.Fatal("invalid internal auth configuration", "error", err)
+	}
+	if internalAuth.Development {
+		slog.Warn("INTERNAL_AUTH_KEYS is not set; service tokens are signed with the development key")
+	}
+	verifier, err := internalauth.NewVerifier(internalAuth)
+	if err != nil {
+		logging.Fatal("failed to create service token verifier", "error", err)
+	}
+
+	r := server.NewRouter(recommend.New(profiles, cfg.Candidates), cfg.MaxLimit, verifier, checker)
+	srv := &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       60 * time.Second,
+	}
+	app.Add(lifecycle.HTTPServer("http", srv))
+	app.Add(lifecycle.Drain(checker.Shutdown, drainDelay))
+
+	slog.Info("recommendation service starting", "port", cfg.Port)
+	if err := app.Run(context.Background()); err != nil {
+		logging.Fatal("server stopped with errors", "error", err)
+	}
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/envconfig"
+)
+
+// Config holds the settings of recommendation-services
+type Config struct {
+	Port string `env:"PORT" envDefault:"8008"`
+
+	DatabaseURL string `env:"DATABASE_URL,required,secret"`
+	// MigrateOnStart applies pending migrations at startup; when unset, migrations are
+	// applied with cmd/migrate and the server refuses to start on a pending one
+	MigrateOnStart bool `env:"MIGRATE_ON_START" envDefault:"true"`
+	// Development logs the queries
+	Development bool `env:"DB_DEBUG" envDefault:"false"`
+
+	RabbitMQURL string `env:"RABBITMQ_URL,required,secret"`
+	// ContentQueue consumes the content.events exchange, ProgressQueue the progress.events
+	// exchange; replicas share them
+	ContentQueue  string `env:"RECOMMENDATION_CONTENT_QUEUE" envDefault:"recommendation-services.content-events"`
+	ProgressQueue string `env:"RECOMMENDATION_PROGRESS_QUEUE" envDefault:"recommendation-services.progress-events"`
+	// Concurrency is how many events of each queue are applied at once
+	Concurrency int `env:"RECOMMENDATION_CONSUMER_CONCURRENCY" envDefault:"4"`
+	// InboxRetention is how long the IDs of applied progress events are kept to skip
+	// their duplicates
+	InboxRetention time.Duration `env:"RECOMMENDATION_INBOX_RETENTION" envDefault:"168h"`
+
+	// MaxLimit bounds the limit of a recommendation request
+	MaxLimit int `env:"RECOMMENDATION_MAX_LIMIT" envDefault:"50"`
+	// Candidates is how many courses or lessons are scored per request, the most
+	// popular of the learner's topics first
+	Candidates int `env:"RECOMMENDATION_CANDIDATES" envDefault:"500"`
+}
+
+// Endpoints lists PostgreSQL and RabbitMQ, dialed by
+// "recommendation-services config validate -connect".
+func (c *Config) Endpoints() []envconfig.Endpoint {
+	return []envconfig.Endpoint{
+		{Name: "postgres", Address: c.DatabaseURL},
+		{Name: "rabbitmq", Address: c.RabbitMQURL},
+	}
+}
+
+// Load reads the configuration from the environment and .env, and reports every invalid
+// value at once.
+func Load() (*Config, error) {
+	cfg := &Config{}
+	var errs []error
+	if err := envconfig.Load(cfg); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Concurrency <= 0 || cfg.MaxLimit <= 0 || cfg.Candidates <= 0 || cfg.InboxRetention <= 0 {
+		errs = append(errs, errors.New("RECOMMENDATION_CONSUMER_CONCURRENCY, RECOMMENDATION_MAX_LIMIT, RECOMMENDATION_CANDIDATES and RECOMMENDATION_INBOX_RETENTION must be positive"))
+	}
+	return cfg, errors.Join(errs...)
+}
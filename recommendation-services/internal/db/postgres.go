@@ -0,0 +1,89 @@
+// Package db connects to the PostgreSQL database of the profiles and applies its
+// migrations.
+package db
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"recommendation-services/migrations"
+
+	"github.com/ductan2/microservice-app/shared/migrate"
+	"github.com/ductan2/microservice-app/shared/telemetry/gormtrace"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Connect opens the database of dsn, logging the queries when debug is set.
+func Connect(dsn string, debug bool) (*gorm.DB, error) {
+	logLevel := logger.Silent
+	if debug {
+		logLevel = logger.Info
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logLevel),
+		NowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	// Trace the queries of each request and event
+	if err := db.Use(gormtrace.Plugin{}); err != nil {
+		return nil, fmt.Errorf("failed to install tracing: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	}
+	sqlDB.SetMaxIdleConns(5)
+	sqlDB.SetMaxOpenConns(10)
+	sqlDB.SetConnMaxLifetime(time.Hour)
+	sqlDB.SetConnMaxIdleTime(30 * time.Minute)
+
+	if err := sqlDB.Ping(); err != nil {
+		_ = sqlDB.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+	return db, nil
+}
+
+// Migrator returns the migrator of the migrations embedded in the binary, or of the
+// directory dir, MIGRATIONS_DIR when empty, to try migrations without rebuilding.
+func Migrator(gormDB *gorm.DB, dir string) (*migrate.Migrator, error) {
+	if dir == "" {
+		dir = os.Getenv("MIGRATIONS_DIR")
+	}
+	var fsys fs.FS = migrations.FS
+	if dir != "" {
+		fsys = os.DirFS(dir)
+	}
+	files, err := migrate.Load(fsys, ".sql")
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(migrate.NewPostgres(sqlDB, migrate.DefaultTable), files), nil
+}
+
+// RunMigrations applies the pending migrations when apply is set, then refuses to go on
+// if the database does not match the migrations of this build.
+func RunMigrations(ctx context.Context, gormDB *gorm.DB, apply bool) error {
+	migrator, err := Migrator(gormDB, "")
+	if err != nil {
+		return err
+	}
+	return migrator.Startup(ctx, apply)
+}
@@ -0,0 +1,233 @@
+// Package handler applies the events the recommendations are computed from: the content
+// events of content-services to the catalog, and the progress events of lesson-services
+// to the profiles of the learners.
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"recommendation-services/internal/store"
+
+	"github.com/ductan2/microservice-app/shared/consumer"
+	"github.com/ductan2/microservice-app/shared/events"
+	"github.com/ductan2/microservice-app/shared/inbox"
+	"github.com/ductan2/microservice-app/shared/inbox/gormstore"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ContentEventTypes are the content events shaping the catalog. Drafts and flashcards
+// are not recommended, and topics are known by ID only.
+var ContentEventTypes = []string{
+	"LessonUpdated", "LessonPublished", "LessonUnpublished", "LessonDeleted",
+	"CourseUpdated", "CoursePublished", "CourseUnpublished", "CourseDeleted",
+	"LevelSaved", "LevelDeleted",
+}
+
+// ProgressEventTypes are the progress events shaping the profiles.
+var ProgressEventTypes = []string{"CourseEnrolled", "CourseCompleted", "LessonCompleted", "QuizSubmitted"}
+
+// InboxConsumer names the progress consumer in the inbox.
+const InboxConsumer = "recommendation-services.progress"
+
+// Handler applies the events to the store. Content events are versioned with their time,
+// so they can be applied in any order and more than once. Progress events add to the
+// profiles, so each one is applied once: its message ID is recorded in the inbox in the
+// transaction of its changes.
+type Handler struct {
+	store *store.Store
+	inbox *gormstore.Store
+}
+
+func New(s *store.Store, inboxStore *gormstore.Store) *Handler {
+	return &Handler{store: s, inbox: inboxStore}
+}
+
+// RegisterContent adds the handlers of the content events to c.
+func (h *Handler) RegisterContent(c *consumer.Consumer) {
+	for _, eventType := range ContentEventTypes {
+		c.Handle(eventType, h.Content)
+	}
+}
+
+// RegisterProgress adds the handlers of the progress events to c, skipping the messages
+// already applied.
+func (h *Handler) RegisterProgress(c *consumer.Consumer) {
+	dedup := inbox.New(h.inbox, inbox.Config{Consumer: InboxConsumer})
+	for _, eventType := range ProgressEventTypes {
+		c.Handle(eventType, dedup.Handler(h.Progress))
+	}
+}
+
+// Content applies a content event routed by its type to the catalog. Payloads that do not
+// decode are dead-lettered; database failures are retried.
+func (h *Handler) Content(ctx context.Context, msg consumer.Message) error {
+	event, err := events.Decode(msg.RoutingKey, msg.Body)
+	if err != nil {
+		return consumer.Permanent(err)
+	}
+
+	var applied bool
+	switch e := event.(type) {
+	case *events.LessonUpdated:
+		applied, err = h.store.PutItem(ctx, lesson(e.Meta, e.LessonID, e.Title, e.IsPublished, e.LessonDetails))
+	case *events.LessonPublished:
+		applied, err = h.store.PutItem(ctx, lesson(e.Meta, e.LessonID, e.Title, true, e.LessonDetails))
+	case *events.LessonUnpublished:
+		applied, err = h.store.HideItem(ctx, store.TypeLesson, e.LessonID, e.OccurredAt)
+	case *events.LessonDeleted:
+		applied, err = h.store.HideItem(ctx, store.TypeLesson, e.LessonID, e.OccurredAt)
+
+	case *events.CourseUpdated:
+		applied, err = h.store.PutItem(ctx, course(e.Meta, e.CourseID, e.Title, e.IsPublished, e.CourseDetails))
+	case *events.CoursePublished:
+		applied, err = h.store.PutItem(ctx, course(e.Meta, e.CourseID, e.Title, true, e.CourseDetails))
+	case *events.CourseUnpublished:
+		applied, err = h.store.HideItem(ctx, store.TypeCourse, e.CourseID, e.OccurredAt)
+	case *events.CourseDeleted:
+		applied, err = h.store.HideItem(ctx, store.TypeCourse, e.CourseID, e.OccurredAt)
+
+	case *events.LevelSaved:
+		applied, err = h.store.PutLevel(ctx, e.LevelID, e.Code, false, e.OccurredAt)
+	case *events.LevelDeleted:
+		applied, err = h.store.PutLevel(ctx, e.LevelID, "", true, e.OccurredAt)
+
+	default:
+		return consumer.Permanent(fmt.Errorf("%s events are not applied", msg.RoutingKey))
+	}
+	if err != nil {
+		return err
+	}
+	if !applied {
+		slog.DebugContext(ctx, "skipped stale event", "event_type", msg.RoutingKey)
+	}
+	return nil
+}
+
+func lesson(meta events.Meta, id uuid.UUID, title string, visible bool, details events.LessonDetails) store.Item {
+	return store.Item{
+		ID:      id,
+		Type:    store.TypeLesson,
+		Title:   title,
+		TopicID: details.TopicID,
+		LevelID: details.LevelID,
+		Visible: visible,
+		Version: meta.OccurredAt,
+	}
+}
+
+func course(meta events.Meta, id uuid.UUID, title string, visible bool, details events.CourseDetails) store.Item {
+	return store.Item{
+		ID:            id,
+		Type:          store.TypeCourse,
+		Title:         title,
+		TopicID:       details.TopicID,
+		LevelID:       details.LevelID,
+		IsFeatured:    details.IsFeatured,
+		AverageRating: details.AverageRating,
+		ReviewCount:   details.ReviewCount,
+		Popularity:    popularity(details.AverageRating, details.ReviewCount, details.IsFeatured),
+		Visible:       visible,
+		Version:       meta.OccurredAt,
+	}
+}
+
+// popularity grows with the rating of a course and, more slowly, with its number of
+// reviews; featured courses get a bonus. Lessons have no reviews and rank by fit alone.
+func popularity(rating float64, reviews int, featured bool) float64 {
+	p := rating / 5 * math.Log2(1+float64(reviews))
+	if featured {
+		p++
+	}
+	return p
+}
+
+// The affinities an event adds to the topic and level of its course or lesson: finishing
+// counts more than starting, and a quiz counts by its score.
+const (
+	enrolledWeight  = 1.0
+	completedWeight = 2.0
+	lessonWeight    = 1.0
+	quizWeight      = 0.5
+)
+
+// Progress applies a progress event routed by its type to the profile of its learner.
+// Payloads that do not decode are dead-lettered; database failures are retried.
+func (h *Handler) Progress(ctx context.Context, msg consumer.Message) error {
+	event, err := events.Decode(msg.RoutingKey, msg.Body)
+	if err != nil {
+		return consumer.Permanent(err)
+	}
+
+	return h.store.Transaction(ctx, func(tx *gorm.DB, s *store.Store) error {
+		p, err := progress(ctx, s, event)
+		if err != nil {
+			return err
+		}
+		if err := s.ApplyProgress(ctx, p); err != nil {
+			return err
+		}
+		if msg.MessageId == "" {
+			return nil
+		}
+		return h.inbox.WithTx(tx).Complete(ctx, InboxConsumer, msg.MessageId)
+	})
+}
+
+// catalog reads the items of the catalog; *store.Store implements it.
+type catalog interface {
+	Item(ctx context.Context, id uuid.UUID) (*store.Item, error)
+}
+
+// progress is the change event makes to a profile. The topic and level of its course or
+// lesson are read from the catalog; content not in the catalog yet adds no affinity.
+func progress(ctx context.Context, s catalog, event events.Event) (store.Progress, error) {
+	var (
+		p      store.Progress
+		itemID uuid.UUID
+		weight float64
+	)
+	switch e := event.(type) {
+	case *events.CourseEnrolled:
+		p = store.Progress{UserID: e.UserID, At: e.EnrolledAt, CoursesEnrolled: 1, ItemID: &e.CourseID, ItemStatus: store.StatusEnrolled}
+		itemID, weight = e.CourseID, enrolledWeight
+	case *events.CourseCompleted:
+		p = store.Progress{UserID: e.UserID, At: e.CompletedAt, CoursesCompleted: 1, ItemID: &e.CourseID, ItemStatus: store.StatusCompleted}
+		itemID, weight = e.CourseID, completedWeight
+	case *events.LessonCompleted:
+		p = store.Progress{UserID: e.UserID, At: e.CompletedAt, LessonsCompleted: 1, ItemID: &e.LessonID, ItemStatus: store.StatusCompleted}
+		itemID, weight = e.LessonID, lessonWeight
+	case *events.QuizSubmitted:
+		p = store.Progress{UserID: e.UserID, At: e.SubmittedAt, QuizzesSubmitted: 1}
+		if e.Passed {
+			p.QuizzesPassed = 1
+		}
+		if e.MaxPoints > 0 {
+			score := min(float64(e.Points)/float64(e.MaxPoints), 1)
+			p.QuizScore = &score
+			if e.LessonID != nil {
+				itemID, weight = *e.LessonID, quizWeight*score
+			}
+		}
+	default:
+		return store.Progress{}, consumer.Permanent(fmt.Errorf("%s events are not applied", event.EventType()))
+	}
+
+	if itemID == uuid.Nil || weight <= 0 {
+		return p, nil
+	}
+	item, err := s.Item(ctx, itemID)
+	if err != nil || item == nil {
+		return p, err
+	}
+	if item.TopicID != nil {
+		p.Affinities = append(p.Affinities, store.Affinity{Kind: store.KindTopic, TermID: *item.TopicID, Weight: weight})
+	}
+	if item.LevelID != nil {
+		p.Affinities = append(p.Affinities, store.Affinity{Kind: store.KindLevel, TermID: *item.LevelID, Weight: weight})
+	}
+	return p, nil
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"recommendation-services/internal/store"
+
+	"github.com/ductan2/microservice-app/shared/events"
+	"github.com/google/uuid"
+)
+
+// fakeCatalog holds the items of the catalog by ID.
+type fakeCatalog map[uuid.UUID]*store.Item
+
+func (c fakeCatalog) Item(_ context.Context, id uuid.UUID) (*store.Item, error) {
+	return c[id], nil
+}
+
+// TestProgress checks the change each progress event makes to the profile of its learner:
+// the counters, the quiz score, the affinities with the topic and level of its course or
+// lesson, and the course or lesson excluded from the recommendations.
+func TestProgress(t *testing.T) {
+	userID := uuid.New()
+	grammar, travel := uuid.New(), uuid.New()
+	b1, a2 := uuid.New(), uuid.New()
+	course := &store.Item{ID: uuid.New(), Type: store.TypeCourse, TopicID: &travel, LevelID: &a2}
+	lesson := &store.Item{ID: uuid.New(), Type: store.TypeLesson, TopicID: &grammar, LevelID: &b1}
+	unknown := uuid.New()
+	catalog := fakeCatalog{course.ID: course, lesson.ID: lesson}
+	at := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	score := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name  string
+		event events.Event
+		want  store.Progress
+	}{
+		{
+			name:  "course enrolled",
+			event: &events.CourseEnrolled{UserID: userID, CourseID: course.ID, EnrolledAt: at},
+			want: store.Progress{
+				UserID: userID, At: at, CoursesEnrolled: 1,
+				Affinities: []store.Affinity{
+					{Kind: store.KindTopic, TermID: travel, Weight: enrolledWeight},
+					{Kind: store.KindLevel, TermID: a2, Weight: enrolledWeight},
+				},
+				ItemID: &course.ID, ItemStatus: store.StatusEnrolled,
+			},
+		},
+		{
+			name:  "course completed counts more",
+			event: &events.CourseCompleted{UserID: userID, CourseID: course.ID, CompletedAt: at},
+			want: store.Progress{
+				UserID: userID, At: at, CoursesCompleted: 1,
+				Affinities: []store.Affinity{
+					{Kind: store.KindTopic, TermID: travel, Weight: completedWeight},
+					{Kind: store.KindLevel, TermID: a2, Weight: completedWeight},
+				},
+				ItemID: &course.ID, ItemStatus: store.StatusCompleted,
+			},
+		},
+		{
+			name:  "lesson completed",
+			event: &events.LessonCompleted{UserID: userID, LessonID: lesson.ID, CompletedAt: at},
+			want: store.Progress{
+				UserID: userID, At: at, LessonsCompleted: 1,
+				Affinities: []store.Affinity{
+					{Kind: store.KindTopic, TermID: grammar, Weight: lessonWeight},
+					{Kind: store.KindLevel, TermID: b1, Weight: lessonWeight},
+				},
+				ItemID: &lesson.ID, ItemStatus: store.StatusCompleted,
+			},
+		},
+		{
+			name:  "lesson not in the catalog yet",
+			event: &events.LessonCompleted{UserID: userID, LessonID: unknown, CompletedAt: at},
+			want:  store.Progress{UserID: userID, At: at, LessonsCompleted: 1, ItemID: &unknown, ItemStatus: store.StatusCompleted},
+		},
+		{
+			name:  "quiz passed weighs by its score",
+			event: &events.QuizSubmitted{UserID: userID, QuizID: uuid.New(), AttemptID: uuid.New(), LessonID: &lesson.ID, Points: 8, MaxPoints: 10, Passed: true, SubmittedAt: at},
+			want: store.Progress{
+				UserID: userID, At: at, QuizzesSubmitted: 1, QuizzesPassed: 1, QuizScore: score(0.8),
+				Affinities: []store.Affinity{
+					{Kind: store.KindTopic, TermID: grammar, Weight: quizWeight * 0.8},
+					{Kind: store.KindLevel, TermID: b1, Weight: quizWeight * 0.8},
+				},
+			},
+		},
+		{
+			name:  "quiz failed with no points adds no affinity",
+			event: &events.QuizSubmitted{UserID: userID, QuizID: uuid.New(), AttemptID: uuid.New(), LessonID: &lesson.ID, Points: 0, MaxPoints: 10, SubmittedAt: at},
+			want:  store.Progress{UserID: userID, At: at, QuizzesSubmitted: 1, QuizScore: score(0)},
+		},
+		{
+			name:  "quiz without points",
+			event: &events.QuizSubmitted{UserID: userID, QuizID: uuid.New(), AttemptID: uuid.New(), SubmittedAt: at},
+			want:  store.Progress{UserID: userID, At: at, QuizzesSubmitted: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := progress(context.Background(), catalog, tt.event)
+			if err != nil {
+				t.Fatalf("progress: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/consumer"
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	eventsHandled = promauto.With(metrics.Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "recommendation_events_handled_total",
+		Help: "Content and progress events applied by recommendation-services, by event type and outcome.",
+	}, []string{"event_type", "outcome"})
+	eventDuration = promauto.With(metrics.Default).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "recommendation_event_duration_seconds",
+		Help:    "Time taken to apply a content or progress event.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"event_type"})
+)
+
+// Metrics exports the events handled by the consumers of the handler.
+type Metrics struct{}
+
+var _ consumer.Metrics = Metrics{}
+
+func (Metrics) Handled(_, routingKey string, outcome consumer.Outcome, duration time.Duration) {
+	eventsHandled.WithLabelValues(routingKey, string(outcome)).Inc()
+	eventDuration.WithLabelValues(routingKey).Observe(duration.Seconds())
+}
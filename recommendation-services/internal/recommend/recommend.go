@@ -0,0 +1,274 @@
+// Package recommend ranks the courses and lessons of the catalog for a learner, from the
+// topics and level of the content they engaged with and their quiz scores.
+package recommend
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"recommendation-services/internal/store"
+
+	"github.com/ductan2/microservice-app/shared/apperr"
+	"github.com/google/uuid"
+)
+
+// defaultLimit is how many recommendations a request returns without a limit.
+const defaultLimit = 10
+
+// Query is a request for the recommendations of a learner.
+type Query struct {
+	UserID uuid.UUID
+	Types  []string
+	Limit  int
+}
+
+// ParseQuery reads a query of the learner userID from the parameters of
+// GET /api/v1/users/:user_id/recommendations: type, repeated or comma-separated, and
+// limit, up to maxLimit.
+func ParseQuery(userID string, params url.Values, maxLimit int) (Query, error) {
+	q := Query{Limit: min(defaultLimit, maxLimit)}
+	var problems []string
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		problems = append(problems, "user_id must be a UUID")
+	}
+	q.UserID = id
+	for _, value := range params["type"] {
+		for _, docType := range strings.Split(value, ",") {
+			if docType = strings.TrimSpace(docType); docType == "" {
+				continue
+			}
+			if !slices.Contains(store.Types, docType) {
+				problems = append(problems, "type must be one of "+strings.Join(store.Types, ", "))
+				break
+			}
+			q.Types = append(q.Types, docType)
+		}
+	}
+	if len(q.Types) == 0 {
+		q.Types = store.Types
+	}
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxLimit {
+			problems = append(problems, fmt.Sprintf("limit must be between 1 and %d", maxLimit))
+		}
+		q.Limit = limit
+	}
+	if len(problems) > 0 {
+		return Query{}, apperr.New(apperr.ValidationFailed, "invalid recommendation parameters").WithDetails(problems)
+	}
+	return q, nil
+}
+
+// Recommendation is a course or lesson recommended to a learner.
+type Recommendation struct {
+	store.Item
+	Score float64 `json:"score"`
+	// Reasons say why the item is recommended: topic, level or popular
+	Reasons []string `json:"reasons"`
+}
+
+// Recommendations are the items recommended to a learner, the best first. Personalized is
+// false for a learner without progress yet, who gets the most popular content.
+type Recommendations struct {
+	Items        []Recommendation `json:"items"`
+	Personalized bool             `json:"personalized"`
+}
+
+// Store reads what the recommendations are computed from; *store.Store implements it.
+type Store interface {
+	Profile(ctx context.Context, userID uuid.UUID) (*store.Profile, []store.Affinity, error)
+	Levels(ctx context.Context) ([]uuid.UUID, error)
+	Candidates(ctx context.Context, userID uuid.UUID, types []string, topics []uuid.UUID, limit int) ([]store.Item, error)
+}
+
+// Recommender ranks the candidates the store finds for a learner.
+type Recommender struct {
+	store Store
+	// candidates is how many items are scored per request
+	candidates int
+}
+
+func New(s Store, candidates int) *Recommender {
+	return &Recommender{store: s, candidates: candidates}
+}
+
+// maxTopics is how many of the learner's topics the candidates are looked for in first.
+const maxTopics = 5
+
+// Recommend returns the recommendations of q: courses and lessons the learner has not
+// enrolled in or completed, in their topics and at their level first.
+func (r *Recommender) Recommend(ctx context.Context, q Query) (*Recommendations, error) {
+	profile, affinities, err := r.store.Profile(ctx, q.UserID)
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.Unavailable, "recommendations are unavailable")
+	}
+	levels, err := r.store.Levels(ctx)
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.Unavailable, "recommendations are unavailable")
+	}
+	features := NewFeatures(profile, affinities, levels)
+
+	candidates, err := r.store.Candidates(ctx, q.UserID, q.Types, features.TopTopics(maxTopics), r.candidates)
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.Unavailable, "recommendations are unavailable")
+	}
+	return &Recommendations{Items: Rank(features, candidates, q.Limit), Personalized: profile != nil}, nil
+}
+
+// Features are what the ranking knows of a learner.
+type Features struct {
+	// topics maps the topics of the learner to their weight, from 0 to 1 for the
+	// topic engaged with most
+	topics map[uuid.UUID]float64
+	// levels orders the levels from the easiest
+	levels map[uuid.UUID]int
+	// target is the position in levels of the level to recommend, -1 when unknown
+	target int
+}
+
+// The quiz scores moving the recommended level one up or down, once the learner submitted
+// enough quizzes for their average to tell.
+const (
+	minQuizzes    = 3
+	stepUpScore   = 0.85
+	stepDownScore = 0.5
+)
+
+// NewFeatures derives the features of a learner from their profile, nil before their
+// first progress event, their affinities and the levels from the easiest. The level
+// recommended is the one they engaged with most, one up when they ace their quizzes and
+// one down when they fail them.
+func NewFeatures(profile *store.Profile, affinities []store.Affinity, levels []uuid.UUID) Features {
+	f := Features{topics: map[uuid.UUID]float64{}, levels: map[uuid.UUID]int{}, target: -1}
+	for i, id := range levels {
+		f.levels[id] = i
+	}
+
+	var maxTopic, maxLevel float64
+	var level uuid.UUID
+	for _, affinity := range affinities {
+		switch affinity.Kind {
+		case store.KindTopic:
+			f.topics[affinity.TermID] = affinity.Weight
+			maxTopic = max(maxTopic, affinity.Weight)
+		case store.KindLevel:
+			if _, known := f.levels[affinity.TermID]; known && affinity.Weight > maxLevel {
+				maxLevel, level = affinity.Weight, affinity.TermID
+			}
+		}
+	}
+	for id, weight := range f.topics {
+		if maxTopic > 0 {
+			f.topics[id] = weight / maxTopic
+		}
+	}
+	if maxLevel == 0 {
+		return f
+	}
+
+	f.target = f.levels[level]
+	if profile != nil && profile.QuizScore != nil && profile.QuizzesSubmitted >= minQuizzes {
+		switch score := *profile.QuizScore; {
+		case score >= stepUpScore:
+			f.target = min(f.target+1, len(levels)-1)
+		case score < stepDownScore:
+			f.target = max(f.target-1, 0)
+		}
+	}
+	return f
+}
+
+// TopTopics returns up to n topics of the learner, the most engaged with first.
+func (f Features) TopTopics(n int) []uuid.UUID {
+	topics := make([]uuid.UUID, 0, len(f.topics))
+	for id := range f.topics {
+		topics = append(topics, id)
+	}
+	slices.SortFunc(topics, func(a, b uuid.UUID) int {
+		if c := cmp.Compare(f.topics[b], f.topics[a]); c != 0 {
+			return c
+		}
+		return strings.Compare(a.String(), b.String())
+	})
+	return topics[:min(n, len(topics))]
+}
+
+// topicFit is how close item is to the topics of the learner, from 0 to 1.
+func (f Features) topicFit(item store.Item) float64 {
+	if item.TopicID == nil {
+		return 0
+	}
+	return f.topics[*item.TopicID]
+}
+
+// levelFit is 1 for an item at the level recommended, 0.5 a level away, and 0 further;
+// 0.5 when either level is unknown.
+func (f Features) levelFit(item store.Item) float64 {
+	if f.target < 0 || item.LevelID == nil {
+		return 0.5
+	}
+	position, known := f.levels[*item.LevelID]
+	if !known {
+		return 0.5
+	}
+	switch distance := position - f.target; {
+	case distance == 0:
+		return 1
+	case distance == 1 || distance == -1:
+		return 0.5
+	}
+	return 0
+}
+
+// The weights of the features in the score of an item, summing to 1.
+const (
+	topicWeight      = 0.5
+	levelWeight      = 0.3
+	popularityWeight = 0.2
+)
+
+// Rank scores the candidates for the learner of f and returns the best limit of them.
+// Popularity is relative to the most popular candidate.
+func Rank(f Features, candidates []store.Item, limit int) []Recommendation {
+	var maxPopularity float64
+	for _, item := range candidates {
+		maxPopularity = max(maxPopularity, item.Popularity)
+	}
+
+	ranked := make([]Recommendation, 0, len(candidates))
+	for _, item := range candidates {
+		topic, level := f.topicFit(item), f.levelFit(item)
+		var popularity float64
+		if maxPopularity > 0 {
+			popularity = item.Popularity / maxPopularity
+		}
+
+		reasons := []string{}
+		if topic >= 0.5 {
+			reasons = append(reasons, "topic")
+		}
+		if level == 1 {
+			reasons = append(reasons, "level")
+		}
+		if popularity >= 0.7 || item.IsFeatured {
+			reasons = append(reasons, "popular")
+		}
+		ranked = append(ranked, Recommendation{
+			Item:    item,
+			Score:   topicWeight*topic + levelWeight*level + popularityWeight*popularity,
+			Reasons: reasons,
+		})
+	}
+
+	slices.SortStableFunc(ranked, func(a, b Recommendation) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+	return ranked[:min(limit, len(ranked))]
+}
@@ -0,0 +1,262 @@
+package recommend
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+
+	"recommendation-services/internal/store"
+
+	"github.com/google/uuid"
+)
+
+// levels are A1 to C1, from the easiest.
+var levels = []uuid.UUID{uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()}
+
+const (
+	a1 = iota
+	a2
+	b1
+	b2
+	c1
+)
+
+func topic(id uuid.UUID, weight float64) store.Affinity {
+	return store.Affinity{Kind: store.KindTopic, TermID: id, Weight: weight}
+}
+
+func level(position int, weight float64) store.Affinity {
+	return store.Affinity{Kind: store.KindLevel, TermID: levels[position], Weight: weight}
+}
+
+func quizzes(submitted int, score float64) *store.Profile {
+	return &store.Profile{QuizzesSubmitted: submitted, QuizScore: &score}
+}
+
+func TestNewFeatures(t *testing.T) {
+	grammar, travel, business := uuid.New(), uuid.New(), uuid.New()
+	tests := []struct {
+		name       string
+		profile    *store.Profile
+		affinities []store.Affinity
+		wantTopics []uuid.UUID
+		wantWeight map[uuid.UUID]float64
+		wantTarget int
+	}{
+		{
+			name:       "new learner",
+			wantTopics: []uuid.UUID{},
+			wantWeight: map[uuid.UUID]float64{},
+			wantTarget: -1,
+		},
+		{
+			name:       "topics relative to the most engaged with",
+			profile:    &store.Profile{},
+			affinities: []store.Affinity{topic(grammar, 4), topic(travel, 1), topic(business, 2), level(b1, 3), level(a2, 1)},
+			wantTopics: []uuid.UUID{grammar, business, travel},
+			wantWeight: map[uuid.UUID]float64{grammar: 1, business: 0.5, travel: 0.25},
+			wantTarget: b1,
+		},
+		{
+			name:       "aced quizzes step up a level",
+			profile:    quizzes(5, 0.9),
+			affinities: []store.Affinity{level(b1, 3)},
+			wantTopics: []uuid.UUID{},
+			wantWeight: map[uuid.UUID]float64{},
+			wantTarget: b2,
+		},
+		{
+			name:       "failed quizzes step down a level",
+			profile:    quizzes(5, 0.4),
+			affinities: []store.Affinity{level(b1, 3)},
+			wantTopics: []uuid.UUID{},
+			wantWeight: map[uuid.UUID]float64{},
+			wantTarget: a2,
+		},
+		{
+			name:       "too few quizzes to tell",
+			profile:    quizzes(2, 0.95),
+			affinities: []store.Affinity{level(b1, 3)},
+			wantTopics: []uuid.UUID{},
+			wantWeight: map[uuid.UUID]float64{},
+			wantTarget: b1,
+		},
+		{
+			name:       "no level above the hardest",
+			profile:    quizzes(5, 0.95),
+			affinities: []store.Affinity{level(c1, 3)},
+			wantTopics: []uuid.UUID{},
+			wantWeight: map[uuid.UUID]float64{},
+			wantTarget: c1,
+		},
+		{
+			name:       "deleted level ignored",
+			profile:    &store.Profile{},
+			affinities: []store.Affinity{{Kind: store.KindLevel, TermID: uuid.New(), Weight: 9}, level(a1, 1)},
+			wantTopics: []uuid.UUID{},
+			wantWeight: map[uuid.UUID]float64{},
+			wantTarget: a1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFeatures(tt.profile, tt.affinities, levels)
+			if got := f.TopTopics(maxTopics); !reflect.DeepEqual(got, tt.wantTopics) {
+				t.Errorf("top topics %v, want %v", got, tt.wantTopics)
+			}
+			if !reflect.DeepEqual(f.topics, tt.wantWeight) {
+				t.Errorf("topic weights %v, want %v", f.topics, tt.wantWeight)
+			}
+			if f.target != tt.wantTarget {
+				t.Errorf("target level %d, want %d", f.target, tt.wantTarget)
+			}
+		})
+	}
+}
+
+// TestRank checks that the candidates are ordered by how well they fit the topics and
+// level of the learner, popularity breaking the ties.
+func TestRank(t *testing.T) {
+	grammar, travel := uuid.New(), uuid.New()
+	item := func(title string, topicID *uuid.UUID, position int, popularity float64) store.Item {
+		it := store.Item{ID: uuid.New(), Type: store.TypeLesson, Title: title, TopicID: topicID, Popularity: popularity}
+		if position >= 0 {
+			it.LevelID = &levels[position]
+		}
+		return it
+	}
+	learner := NewFeatures(&store.Profile{}, []store.Affinity{topic(grammar, 4), topic(travel, 1), level(b1, 2)}, levels)
+
+	tests := []struct {
+		name       string
+		features   Features
+		candidates []store.Item
+		limit      int
+		want       []string
+	}{
+		{
+			name:     "topic first, then level",
+			features: learner,
+			candidates: []store.Item{
+				item("travel at B1", &travel, b1, 0),
+				item("grammar at C1", &grammar, c1, 0),
+				item("grammar at B1", &grammar, b1, 0),
+				item("grammar at B2", &grammar, b2, 0),
+				item("no topic at B1", nil, b1, 0),
+			},
+			limit: 10,
+			want:  []string{"grammar at B1", "grammar at B2", "grammar at C1", "travel at B1", "no topic at B1"},
+		},
+		{
+			name:     "popularity breaks ties",
+			features: learner,
+			candidates: []store.Item{
+				item("less popular", &grammar, b1, 1),
+				item("more popular", &grammar, b1, 4),
+			},
+			limit: 10,
+			want:  []string{"more popular", "less popular"},
+		},
+		{
+			name:     "unknown level ranks below the level and above two levels away",
+			features: learner,
+			candidates: []store.Item{
+				item("two levels up", &grammar, c1, 0),
+				item("unknown level", &grammar, -1, 0),
+				item("at level", &grammar, b1, 0),
+			},
+			limit: 10,
+			want:  []string{"at level", "unknown level", "two levels up"},
+		},
+		{
+			name:     "new learner gets the most popular",
+			features: NewFeatures(nil, nil, levels),
+			candidates: []store.Item{
+				item("quiet", &travel, a1, 1),
+				item("popular", &grammar, c1, 10),
+				item("known", &travel, b1, 5),
+			},
+			limit: 2,
+			want:  []string{"popular", "known"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranked := Rank(tt.features, tt.candidates, tt.limit)
+			got := make([]string, len(ranked))
+			for i, r := range ranked {
+				got[i] = r.Title
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("ranked %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeStore is the catalog and profiles of one learner. Candidates leaves out the items
+// the learner enrolled in or completed, like the query of store.Candidates.
+type fakeStore struct {
+	profile    *store.Profile
+	affinities []store.Affinity
+	items      []store.Item
+	taken      map[uuid.UUID]bool
+	// topics are the topics Candidates was asked for
+	topics []uuid.UUID
+}
+
+func (s *fakeStore) Profile(context.Context, uuid.UUID) (*store.Profile, []store.Affinity, error) {
+	return s.profile, s.affinities, nil
+}
+
+func (s *fakeStore) Levels(context.Context) ([]uuid.UUID, error) {
+	return levels, nil
+}
+
+func (s *fakeStore) Candidates(_ context.Context, _ uuid.UUID, types []string, topics []uuid.UUID, limit int) ([]store.Item, error) {
+	s.topics = topics
+	var items []store.Item
+	for _, item := range s.items {
+		if item.Visible && slices.Contains(types, item.Type) && !s.taken[item.ID] && len(items) < limit {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func TestRecommend(t *testing.T) {
+	grammar := uuid.New()
+	completed := store.Item{ID: uuid.New(), Type: store.TypeLesson, Title: "completed", TopicID: &grammar, LevelID: &levels[b1], Visible: true}
+	next := store.Item{ID: uuid.New(), Type: store.TypeLesson, Title: "next", TopicID: &grammar, LevelID: &levels[b1], Visible: true}
+	other := store.Item{ID: uuid.New(), Type: store.TypeLesson, Title: "other", LevelID: &levels[a1], Visible: true, Popularity: 1}
+	course := store.Item{ID: uuid.New(), Type: store.TypeCourse, Title: "course", TopicID: &grammar, Visible: true}
+	s := &fakeStore{
+		profile:    &store.Profile{LessonsCompleted: 1},
+		affinities: []store.Affinity{topic(grammar, 1), level(b1, 1)},
+		items:      []store.Item{completed, next, other, course},
+		taken:      map[uuid.UUID]bool{completed.ID: true},
+	}
+
+	got, err := New(s, 100).Recommend(context.Background(), Query{UserID: uuid.New(), Types: []string{store.TypeLesson}, Limit: 10})
+	if err != nil {
+		t.Fatalf("Recommend: %v", err)
+	}
+
+	var titles []string
+	for _, r := range got.Items {
+		titles = append(titles, r.Title)
+	}
+	if want := []string{"next", "other"}; !slices.Equal(titles, want) {
+		t.Errorf("recommended %q, want %q", titles, want)
+	}
+	if !got.Personalized {
+		t.Error("recommendations of a learner with progress are not personalized")
+	}
+	if !slices.Equal(s.topics, []uuid.UUID{grammar}) {
+		t.Errorf("candidates looked for in topics %v, want the learner's %v", s.topics, grammar)
+	}
+	if reasons := got.Items[0].Reasons; !slices.Equal(reasons, []string{"topic", "level"}) {
+		t.Errorf("next lesson recommended for %q, want topic and level", reasons)
+	}
+}
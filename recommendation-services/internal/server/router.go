@@ -0,0 +1,113 @@
+// Package server serves the recommendation API to the BFF.
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"recommendation-services/internal/recommend"
+
+	"github.com/ductan2/microservice-app/shared/apperr"
+	"github.com/ductan2/microservice-app/shared/health"
+	"github.com/ductan2/microservice-app/shared/internalauth"
+	"github.com/ductan2/microservice-app/shared/logging"
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// NewRouter returns the routes of the service. The API answers {"data": ...} or
+// {"error": {...}}, and only requests with a service token: learners reach it through
+// the BFF.
+func NewRouter(recommender *recommend.Recommender, maxLimit int, verifier *internalauth.Verifier, checker *health.Checker) *gin.Engine {
+	r := gin.New()
+	r.Use(requestLog())
+	r.Use(tracing())
+	r.Use(observe())
+	r.Use(gin.Recovery())
+
+	r.GET(health.LivePath, gin.WrapH(checker.Live()))
+	r.GET(health.ReadyPath, gin.WrapH(checker.Ready()))
+	r.GET(health.HealthPath, gin.WrapH(checker.Health()))
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	api := r.Group("/api/v1")
+	api.Use(serviceAuth(verifier))
+	{
+		api.GET("/users/:user_id/recommendations", recommendations(recommender, maxLimit)) // GET /api/v1/users/:user_id/recommendations?type=&limit=
+	}
+	return r
+}
+
+// recommendations answers the courses and lessons recommended to a learner.
+func recommendations(recommender *recommend.Recommender, maxLimit int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query, err := recommend.ParseQuery(c.Param("user_id"), c.Request.URL.Query(), maxLimit)
+		if err != nil {
+			failJSON(c, apperr.From(err))
+			return
+		}
+
+		result, err := recommender.Recommend(c.Request.Context(), query)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "recommendation failed", "error", err)
+			failJSON(c, apperr.From(err))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": result})
+	}
+}
+
+// serviceAuth refuses the requests without a valid service token.
+func serviceAuth(verifier *internalauth.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := verifier.Authenticate(c.Request)
+		if err != nil {
+			slog.WarnContext(c.Request.Context(), "rejected service token", "error", err, "path", c.Request.URL.Path)
+		}
+		if err != nil || claims == nil {
+			failJSON(c, apperr.New(apperr.Unauthorized, "internal service credentials are required"))
+			return
+		}
+		c.Set("calling_service", claims.Issuer)
+		c.Next()
+	}
+}
+
+func failJSON(c *gin.Context, err *apperr.Error) {
+	c.AbortWithStatusJSON(err.HTTPStatus(), gin.H{"error": err.Public()})
+}
+
+// tracing starts the server span of each request with otelgin, continuing the trace of the caller's
+// traceparent header.
+func tracing() gin.HandlerFunc {
+	return otelgin.Middleware("recommendation-services")
+}
+
+// observe records the count and latency of each request by matched route.
+func observe() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		metrics.ObserveHTTPServer(c.Request.Method, c.FullPath(), c.Writer.Status(), time.Since(start))
+	}
+}
+
+// requestLog writes an access log record for each request once it has been served.
+func requestLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		logging.LogRequest(c.Request.Context(), logging.HTTPRequest{
+			Method:   c.Request.Method,
+			Route:    c.FullPath(),
+			Path:     c.Request.URL.Path,
+			Status:   c.Writer.Status(),
+			Latency:  time.Since(start),
+			ClientIP: c.ClientIP(),
+		})
+	}
+}
@@ -0,0 +1,266 @@
+// Package store keeps the catalog of recommendable content and the feature profiles of
+// the learners in PostgreSQL.
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// The types of the content recommended.
+const (
+	TypeCourse = "course"
+	TypeLesson = "lesson"
+)
+
+// Types lists the types of the content recommended.
+var Types = []string{TypeCourse, TypeLesson}
+
+// The kinds of the terms a learner has an affinity with.
+const (
+	KindTopic = "topic"
+	KindLevel = "level"
+)
+
+// The status of a course or lesson for a learner.
+const (
+	StatusEnrolled  = "enrolled"
+	StatusCompleted = "completed"
+)
+
+// Item is a course or lesson of the catalog. Hidden items are unpublished or deleted.
+type Item struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	Type          string     `json:"type"`
+	Title         string     `json:"title"`
+	TopicID       *uuid.UUID `gorm:"type:uuid" json:"topic_id,omitempty"`
+	LevelID       *uuid.UUID `gorm:"type:uuid" json:"level_id,omitempty"`
+	IsFeatured    bool       `json:"is_featured,omitempty"`
+	AverageRating float64    `json:"average_rating,omitempty"`
+	ReviewCount   int        `json:"review_count,omitempty"`
+	// Popularity ranks the items learners know nothing about, see the handler
+	Popularity float64   `json:"-"`
+	Visible    bool      `json:"-"`
+	Version    time.Time `json:"-"`
+	UpdatedAt  time.Time `json:"-"`
+}
+
+func (Item) TableName() string { return "catalog_items" }
+
+// Profile holds the features of a learner, from their progress events.
+type Profile struct {
+	UserID           uuid.UUID `gorm:"type:uuid;primaryKey"`
+	CoursesEnrolled  int
+	CoursesCompleted int
+	LessonsCompleted int
+	QuizzesSubmitted int
+	QuizzesPassed    int
+	// QuizScore is the moving average of the share of points scored, nil before the
+	// first quiz
+	QuizScore    *float64
+	LastActiveAt *time.Time
+	UpdatedAt    time.Time
+}
+
+func (Profile) TableName() string { return "user_profiles" }
+
+// Affinity is how much a learner engaged with a topic or level.
+type Affinity struct {
+	UserID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Kind   string    `gorm:"primaryKey"`
+	TermID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Weight float64
+}
+
+func (Affinity) TableName() string { return "user_affinities" }
+
+// Progress is the change a progress event makes to the profile of a learner: counters to
+// add, a quiz score to average in, affinities to add and a course or lesson to exclude.
+type Progress struct {
+	UserID uuid.UUID
+	At     time.Time
+
+	CoursesEnrolled  int
+	CoursesCompleted int
+	LessonsCompleted int
+	QuizzesSubmitted int
+	QuizzesPassed    int
+	QuizScore        *float64
+
+	Affinities []Affinity
+	ItemID     *uuid.UUID
+	ItemStatus string
+}
+
+// quizScoreWeight is the weight of the last quiz in the moving average of the scores.
+const quizScoreWeight = 0.3
+
+type Store struct {
+	db *gorm.DB
+}
+
+func New(db *gorm.DB) *Store {
+	return &Store{db: db}
+}
+
+// Transaction runs fn in a transaction, with a store writing through it.
+func (s *Store) Transaction(ctx context.Context, fn func(tx *gorm.DB, store *Store) error) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(tx, &Store{db: tx})
+	})
+}
+
+// PutItem writes item unless an event newer than its version was applied. It reports
+// whether it did.
+func (s *Store) PutItem(ctx context.Context, item Item) (bool, error) {
+	result := s.db.WithContext(ctx).Exec(`INSERT INTO catalog_items
+    (id, type, title, topic_id, level_id, is_featured, average_rating, review_count, popularity, visible, version, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NOW())
+ON CONFLICT (id) DO UPDATE SET
+    type = EXCLUDED.type, title = EXCLUDED.title, topic_id = EXCLUDED.topic_id, level_id = EXCLUDED.level_id,
+    is_featured = EXCLUDED.is_featured, average_rating = EXCLUDED.average_rating, review_count = EXCLUDED.review_count,
+    popularity = EXCLUDED.popularity, visible = EXCLUDED.visible, version = EXCLUDED.version, updated_at = NOW()
+WHERE catalog_items.version < EXCLUDED.version`,
+		item.ID, item.Type, item.Title, item.TopicID, item.LevelID, item.IsFeatured, item.AverageRating,
+		item.ReviewCount, item.Popularity, item.Visible, item.Version)
+	return result.RowsAffected == 1, result.Error
+}
+
+// HideItem hides the item id unless an event newer than version was applied. An unknown
+// item is kept hidden, so that a late event published before version cannot show it.
+func (s *Store) HideItem(ctx context.Context, itemType string, id uuid.UUID, version time.Time) (bool, error) {
+	result := s.db.WithContext(ctx).Exec(`INSERT INTO catalog_items (id, type, visible, version, updated_at)
+VALUES (?, ?, FALSE, ?, NOW())
+ON CONFLICT (id) DO UPDATE SET visible = FALSE, version = EXCLUDED.version, updated_at = NOW()
+WHERE catalog_items.version < EXCLUDED.version`,
+		id, itemType, version)
+	return result.RowsAffected == 1, result.Error
+}
+
+// PutLevel writes the code of the level id, or marks it deleted, unless an event newer
+// than version was applied.
+func (s *Store) PutLevel(ctx context.Context, id uuid.UUID, code string, deleted bool, version time.Time) (bool, error) {
+	result := s.db.WithContext(ctx).Exec(`INSERT INTO levels (id, code, deleted, version)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (id) DO UPDATE SET
+    code = CASE WHEN EXCLUDED.deleted THEN levels.code ELSE EXCLUDED.code END,
+    deleted = EXCLUDED.deleted, version = EXCLUDED.version
+WHERE levels.version < EXCLUDED.version`,
+		id, code, deleted, version)
+	return result.RowsAffected == 1, result.Error
+}
+
+// Item returns the item id, hidden or not, or nil when no event named it yet.
+func (s *Store) Item(ctx context.Context, id uuid.UUID) (*Item, error) {
+	var item Item
+	err := s.db.WithContext(ctx).Where("id = ?", id).Take(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Levels returns the IDs of the levels, from the easiest to the hardest.
+func (s *Store) Levels(ctx context.Context) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := s.db.WithContext(ctx).Table("levels").
+		Where("NOT deleted").
+		Order("code, id").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// ApplyProgress adds the change of a progress event to the profile of its learner.
+func (s *Store) ApplyProgress(ctx context.Context, p Progress) error {
+	db := s.db.WithContext(ctx)
+	err := db.Exec(`INSERT INTO user_profiles
+    (user_id, courses_enrolled, courses_completed, lessons_completed, quizzes_submitted, quizzes_passed, quiz_score, last_active_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW())
+ON CONFLICT (user_id) DO UPDATE SET
+    courses_enrolled = user_profiles.courses_enrolled + EXCLUDED.courses_enrolled,
+    courses_completed = user_profiles.courses_completed + EXCLUDED.courses_completed,
+    lessons_completed = user_profiles.lessons_completed + EXCLUDED.lessons_completed,
+    quizzes_submitted = user_profiles.quizzes_submitted + EXCLUDED.quizzes_submitted,
+    quizzes_passed = user_profiles.quizzes_passed + EXCLUDED.quizzes_passed,
+    quiz_score = CASE
+        WHEN EXCLUDED.quiz_score IS NULL THEN user_profiles.quiz_score
+        WHEN user_profiles.quiz_score IS NULL THEN EXCLUDED.quiz_score
+        ELSE user_profiles.quiz_score * (1 - ?) + EXCLUDED.quiz_score * ?
+    END,
+    last_active_at = GREATEST(user_profiles.last_active_at, EXCLUDED.last_active_at),
+    updated_at = NOW()`,
+		p.UserID, p.CoursesEnrolled, p.CoursesCompleted, p.LessonsCompleted, p.QuizzesSubmitted, p.QuizzesPassed,
+		p.QuizScore, p.At, quizScoreWeight, quizScoreWeight).Error
+	if err != nil {
+		return err
+	}
+
+	for _, affinity := range p.Affinities {
+		err := db.Exec(`INSERT INTO user_affinities (user_id, kind, term_id, weight, updated_at)
+VALUES (?, ?, ?, ?, NOW())
+ON CONFLICT (user_id, kind, term_id) DO UPDATE SET weight = user_affinities.weight + EXCLUDED.weight, updated_at = NOW()`,
+			p.UserID, affinity.Kind, affinity.TermID, affinity.Weight).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	if p.ItemID != nil {
+		// A completed course or lesson stays completed when an enrollment arrives late
+		err := db.Exec(`INSERT INTO user_items (user_id, item_id, status, updated_at)
+VALUES (?, ?, ?, NOW())
+ON CONFLICT (user_id, item_id) DO UPDATE SET
+    status = CASE WHEN user_items.status = 'completed' THEN user_items.status ELSE EXCLUDED.status END,
+    updated_at = NOW()`,
+			p.UserID, *p.ItemID, p.ItemStatus).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Profile returns the profile of a learner and their affinities, a nil profile for a
+// learner with no progress yet.
+func (s *Store) Profile(ctx context.Context, userID uuid.UUID) (*Profile, []Affinity, error) {
+	db := s.db.WithContext(ctx)
+	var profile Profile
+	err := db.Where("user_id = ?", userID).Take(&profile).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var affinities []Affinity
+	if err := db.Where("user_id = ?", userID).Order("weight DESC").Find(&affinities).Error; err != nil {
+		return nil, nil, err
+	}
+	return &profile, affinities, nil
+}
+
+// Candidates returns up to limit visible items of types the learner has not enrolled in
+// or completed: those of topics first, then the most popular.
+func (s *Store) Candidates(ctx context.Context, userID uuid.UUID, types []string, topics []uuid.UUID, limit int) ([]Item, error) {
+	query := s.db.WithContext(ctx).
+		Where("visible AND type IN ?", types).
+		Where("NOT EXISTS (SELECT 1 FROM user_items u WHERE u.user_id = ? AND u.item_id = catalog_items.id)", userID)
+	if len(topics) > 0 {
+		query = query.Order(clause.OrderBy{Expression: clause.Expr{SQL: "COALESCE(topic_id IN ?, FALSE) DESC, popularity DESC, id", Vars: []any{topics}}})
+	} else {
+		query = query.Order("popularity DESC, id")
+	}
+
+	var items []Item
+	err := query.Limit(limit).Find(&items).Error
+	return items, err
+}
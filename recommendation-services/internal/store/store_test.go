@@ -0,0 +1,102 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRun returns a store whose statements are built but not sent, and the statements it
+// built, their values inlined.
+func dryRun(t *testing.T) (*Store, *[]string) {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var statements []string
+	capture := func(tx *gorm.DB) {
+		statements = append(statements, tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+	}
+	if err := db.Callback().Query().After("gorm:query").Register("test:capture", capture); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Callback().Raw().After("gorm:raw").Register("test:capture", capture); err != nil {
+		t.Fatal(err)
+	}
+	return New(db), &statements
+}
+
+// TestCandidatesExcludeTakenItems checks that the candidates of a learner leave out the
+// courses and lessons they enrolled in or completed, whatever their topics.
+func TestCandidatesExcludeTakenItems(t *testing.T) {
+	userID, topicID := uuid.New(), uuid.New()
+	tests := []struct {
+		name   string
+		topics []uuid.UUID
+		order  string
+	}{
+		{name: "by popularity", order: "ORDER BY popularity DESC, id"},
+		{name: "in the learner's topics first", topics: []uuid.UUID{topicID}, order: "ORDER BY COALESCE(topic_id IN ('" + topicID.String() + "'), FALSE) DESC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, statements := dryRun(t)
+			if _, err := s.Candidates(context.Background(), userID, []string{TypeLesson}, tt.topics, 50); err != nil {
+				t.Fatalf("Candidates: %v", err)
+			}
+			if len(*statements) != 1 {
+				t.Fatalf("ran %d statements, want 1", len(*statements))
+			}
+			sql := (*statements)[0]
+			for _, want := range []string{
+				"visible AND type IN ('lesson')",
+				"NOT EXISTS (SELECT 1 FROM user_items u WHERE u.user_id = '" + userID.String() + "' AND u.item_id = catalog_items.id)",
+				tt.order,
+				"LIMIT 50",
+			} {
+				if !strings.Contains(sql, want) {
+					t.Errorf("candidates query %s\nlacks %s", sql, want)
+				}
+			}
+		})
+	}
+}
+
+// TestApplyProgressMarksItem checks that a completed course or lesson is recorded for the
+// learner, so that it leaves their candidates, and that a late enrollment keeps it completed.
+func TestApplyProgressMarksItem(t *testing.T) {
+	s, statements := dryRun(t)
+	userID, lessonID, topicID := uuid.New(), uuid.New(), uuid.New()
+	err := s.ApplyProgress(context.Background(), Progress{
+		UserID:           userID,
+		LessonsCompleted: 1,
+		Affinities:       []Affinity{{Kind: KindTopic, TermID: topicID, Weight: 1}},
+		ItemID:           &lessonID,
+		ItemStatus:       StatusCompleted,
+	})
+	if err != nil {
+		t.Fatalf("ApplyProgress: %v", err)
+	}
+
+	if len(*statements) != 3 {
+		t.Fatalf("ran %d statements, want the profile, the affinity and the item: %q", len(*statements), *statements)
+	}
+	affinity, item := (*statements)[1], (*statements)[2]
+	if !strings.Contains(affinity, "weight = user_affinities.weight + EXCLUDED.weight") {
+		t.Errorf("affinity is not added to: %s", affinity)
+	}
+	for _, want := range []string{
+		"INSERT INTO user_items (user_id, item_id, status, updated_at)",
+		"VALUES ('" + userID.String() + "', '" + lessonID.String() + "', 'completed', NOW())",
+		"CASE WHEN user_items.status = 'completed' THEN user_items.status ELSE EXCLUDED.status END",
+	} {
+		if !strings.Contains(item, want) {
+			t.Errorf("item statement %s\nlacks %s", item, want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+-- Catalog ----------------------------------------------------------------------
+-- The courses and lessons that can be recommended, from the content.events of
+-- content-services. version is the time of the last event applied: an older event is
+-- skipped, so events can arrive out of order or twice. Unpublished and deleted content
+-- stays as a hidden row, so a late update cannot bring it back.
+CREATE TABLE IF NOT EXISTS catalog_items (
+    id UUID PRIMARY KEY,
+    type TEXT NOT NULL CHECK (type IN ('course','lesson')),
+    title TEXT NOT NULL DEFAULT '',
+    topic_id UUID,
+    level_id UUID,
+    is_featured BOOLEAN NOT NULL DEFAULT FALSE,
+    average_rating DOUBLE PRECISION NOT NULL DEFAULT 0,
+    review_count INT NOT NULL DEFAULT 0,
+    popularity DOUBLE PRECISION NOT NULL DEFAULT 0,
+    visible BOOLEAN NOT NULL DEFAULT FALSE,
+    version TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS catalog_items_candidates_idx
+    ON catalog_items (type, popularity DESC)
+    WHERE visible;
+
+-- Levels are ordered by code, A1 to C2, to recommend the level above the learner's
+CREATE TABLE IF NOT EXISTS levels (
+    id UUID PRIMARY KEY,
+    code TEXT NOT NULL DEFAULT '',
+    deleted BOOLEAN NOT NULL DEFAULT FALSE,
+    version TIMESTAMPTZ NOT NULL
+);
+
+-- Profiles ---------------------------------------------------------------------
+-- The features of each learner, from the progress.events of lesson-services.
+CREATE TABLE IF NOT EXISTS user_profiles (
+    user_id UUID PRIMARY KEY,
+    courses_enrolled INT NOT NULL DEFAULT 0,
+    courses_completed INT NOT NULL DEFAULT 0,
+    lessons_completed INT NOT NULL DEFAULT 0,
+    quizzes_submitted INT NOT NULL DEFAULT 0,
+    quizzes_passed INT NOT NULL DEFAULT 0,
+    -- Moving average of the share of points scored in quizzes, NULL before the first
+    quiz_score DOUBLE PRECISION,
+    last_active_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- How much a learner engaged with each topic and level
+CREATE TABLE IF NOT EXISTS user_affinities (
+    user_id UUID NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('topic','level')),
+    term_id UUID NOT NULL,
+    weight DOUBLE PRECISION NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, kind, term_id)
+);
+
+-- The courses a learner enrolled in and the lessons and courses they completed, never
+-- recommended to them
+CREATE TABLE IF NOT EXISTS user_items (
+    user_id UUID NOT NULL,
+    item_id UUID NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('enrolled','completed')),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, item_id)
+);
+
+-- Inbox ------------------------------------------------------------------------
+-- The progress events already applied, see shared/inbox/gormstore
+CREATE TABLE IF NOT EXISTS inbox (
+    consumer TEXT NOT NULL,
+    message_id TEXT NOT NULL,
+    locked_until TIMESTAMPTZ NOT NULL,
+    processed_at TIMESTAMPTZ,
+    PRIMARY KEY (consumer, message_id)
+);
+CREATE INDEX IF NOT EXISTS inbox_processed_idx ON inbox (processed_at);
@@ -0,0 +1,138 @@
+// Command server runs search-services: it indexes the content events of content-services
+// in OpenSearch and serves the search API the BFF calls.
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"search-services/internal/config"
+	"search-services/internal/index"
+	"search-services/internal/indexer"
+	"search-services/internal/search"
+	"search-services/internal/server"
+	"search-services/internal/synonyms"
+
+	"github.com/ductan2/microservice-app/shared/consumer"
+	"github.com/ductan2/microservice-app/shared/envconfig"
+	"github.com/ductan2/microservice-app/shared/health"
+	"github.com/ductan2/microservice-app/shared/internalauth"
+	"github.com/ductan2/microservice-app/shared/lifecycle"
+	"github.com/ductan2/microservice-app/shared/logging"
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/ductan2/microservice-app/shared/secrets"
+	"github.com/ductan2/microservice-app/shared/telemetry"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// drainDelay is how long readiness fails before the server stops.
+const drainDelay = 2 * time.Second
+
+func main() {
+	secretStore, secretsErr := secrets.FromEnv()
+	if secretStore != nil {
+		envconfig.UseSecrets(secretStore)
+	}
+	// "search-services config validate" checks the configuration of a deployment and exits
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(envconfig.Command(os.Args[2:], os.Stdout, func() (any, error) { return config.Load() }))
+	}
+	cfg, err := config.Load()
+	logging.Setup(logging.ConfigFromEnv("search-services"))
+	if secretsErr != nil {
+		logging.Fatal("invalid secrets configuration", "error", secretsErr)
+	}
+	if err != nil {
+		logging.Fatal("invalid configuration", "error", err)
+	}
+	slog.Info("configuration loaded", "config", envconfig.Values(cfg))
+
+	// Components are stopped in the reverse order they are added, on SIGINT or SIGTERM
+	app := lifecycle.New(lifecycle.Config{})
+	if secretStore != nil {
+		app.Add(lifecycle.Worker("secrets", secretStore.Run))
+	}
+	tracer := telemetry.Setup(telemetry.ConfigFromEnv("search-services"))
+	app.Add(lifecycle.Component{Name: "tracer", Stop: tracer.Shutdown})
+	metrics.SetBuildInfo("search-services")
+
+	client, err := index.NewClient(cfg.OpenSearchURL, cfg.OpenSearchUsername, cfg.OpenSearchPassword, cfg.RequestTimeout)
+	if err != nil {
+		logging.Fatal("invalid OpenSearch configuration", "error", err)
+	}
+	names := index.NewNames(cfg.IndexPrefix)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	err = index.EnsureIndexes(ctx, client, names)
+	cancel()
+	if err != nil {
+		logging.Fatal("failed to create the search indexes", "error", err)
+	}
+
+	conn, err := amqp.Dial(cfg.RabbitMQURL)
+	if err != nil {
+		logging.Fatal("failed to connect to RabbitMQ", "error", err)
+	}
+	app.Add(lifecycle.Closer("rabbitmq", conn))
+	// Events wait in the queue while OpenSearch is down: the consumer retries them
+	events := consumer.New(conn, consumer.Config{
+		Exchange:    "content.events",
+		Queue:       cfg.Queue,
+		Concurrency: cfg.Concurrency,
+		Metrics:     indexer.Metrics{},
+	})
+	indexer.New(client, names).Register(events)
+	app.Add(lifecycle.Component{Name: "content-events", Run: events.Run})
+
+	// Searches need OpenSearch; indexing falls behind without RabbitMQ but search still works
+	checker := health.New(health.Config{Service: "search-services"})
+	checker.Register("opensearch", health.Critical, client.Ping)
+	checker.Register("rabbitmq", health.Optional, func(context.Context) error {
+		if conn.IsClosed() {
+			return errors.New("connection closed")
+		}
+		return nil
+	})
+
+	// The BFF authenticates with service tokens signed with the shared keys
+	internalAuth, err := internalauth.ConfigFromEnv("search-services")
+	if err != nil {
+		logging.Fatal("invalid internal auth configuration", "error", err)
+	}
+	if internalAuth.Development {
+		slog.Warn("INTERNAL_AUTH_KEYS is not set; service tokens are signed with the development key")
+	}
+	verifier, err := internalauth.NewVerifier(internalAuth)
+	if err != nil {
+		logging.Fatal("failed to create service token verifier", "error", err)
+	}
+
+	// Synonym rules admins change reach the other replicas on their next reload
+	dictionary := synonyms.NewStore(client, names.Synonyms(), cfg.SynonymsReload)
+	app.Add(lifecycle.Worker("synonyms", dictionary.Run))
+	fuzziness := search.Fuzziness{
+		Edits:         cfg.Fuzziness,
+		PrefixLength:  cfg.FuzzyPrefixLength,
+		MaxExpansions: cfg.FuzzyMaxExpansions,
+	}
+
+	r := server.NewRouter(search.NewSearcher(client, names, fuzziness, dictionary), dictionary, cfg.MaxPageSize, cfg.SuggestTimeout, verifier, checker)
+	srv := &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       60 * time.Second,
+	}
+	app.Add(lifecycle.HTTPServer("http", srv))
+	app.Add(lifecycle.Drain(checker.Shutdown, drainDelay))
+
+	slog.Info("search service starting", "port", cfg.Port)
+	if err := app.Run(context.Background()); err != nil {
+		logging.Fatal("server stopped with errors", "error", err)
+	}
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/envconfig"
+)
+
+// Config holds the settings of search-services
+type Config struct {
+	Port string `env:"PORT" envDefault:"8007"`
+
+	RabbitMQURL string `env:"RABBITMQ_URL,required,secret"`
+	// Queue consumes the content.events exchange; replicas share it
+	Queue string `env:"SEARCH_QUEUE" envDefault:"search-services.content-events"`
+	// Concurrency is how many events are indexed at once
+	Concurrency int `env:"SEARCH_CONSUMER_CONCURRENCY" envDefault:"4"`
+
+	// OpenSearchURL is the address of the OpenSearch or Elasticsearch cluster
+	OpenSearchURL      string `env:"OPENSEARCH_URL" envDefault:"http://opensearch:9200"`
+	OpenSearchUsername string `env:"OPENSEARCH_USERNAME"`
+	OpenSearchPassword string `env:"OPENSEARCH_PASSWORD,secret"`
+	// IndexPrefix is put before the names of the indexes, such as "english-app-courses",
+	// so that several environments can share a cluster
+	IndexPrefix    string        `env:"SEARCH_INDEX_PREFIX" envDefault:"english-app-"`
+	RequestTimeout time.Duration `env:"OPENSEARCH_TIMEOUT" envDefault:"5s"`
+
+	// MaxPageSize bounds the page_size of a search
+	MaxPageSize int `env:"SEARCH_MAX_PAGE_SIZE" envDefault:"50"`
+	// SuggestTimeout bounds a suggestion request: a late completion is of no use to the
+	// search box, which has moved on to the next keystroke
+	SuggestTimeout time.Duration `env:"SEARCH_SUGGEST_TIMEOUT" envDefault:"100ms"`
+
+	// Fuzziness is how many typos a word may have: AUTO scales it with the length of the
+	// word, 0 turns typo tolerance off
+	Fuzziness string `env:"SEARCH_FUZZINESS" envDefault:"AUTO"`
+	// FuzzyPrefixLength is how many first characters of a word have to be typed right;
+	// learners rarely get the first letter wrong, and each one makes fuzzy matching faster
+	FuzzyPrefixLength  int `env:"SEARCH_FUZZY_PREFIX_LENGTH" envDefault:"1"`
+	FuzzyMaxExpansions int `env:"SEARCH_FUZZY_MAX_EXPANSIONS" envDefault:"50"`
+	// SynonymsReload is how often each replica reloads the synonym rules admins change
+	SynonymsReload time.Duration `env:"SEARCH_SYNONYMS_RELOAD" envDefault:"30s"`
+}
+
+// fuzzinessValues are the values of SEARCH_FUZZINESS.
+var fuzzinessValues = []string{"AUTO", "0", "1", "2"}
+
+// Endpoints lists RabbitMQ and OpenSearch, dialed by "search-services config validate -connect".
+func (c *Config) Endpoints() []envconfig.Endpoint {
+	return []envconfig.Endpoint{
+		{Name: "rabbitmq", Address: c.RabbitMQURL},
+		{Name: "opensearch", Address: c.OpenSearchURL},
+	}
+}
+
+// Load reads the configuration from the environment and .env, and reports every invalid
+// value at once.
+func Load() (*Config, error) {
+	cfg := &Config{}
+	var errs []error
+	if err := envconfig.Load(cfg); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.Concurrency <= 0 || cfg.MaxPageSize <= 0 || cfg.SuggestTimeout <= 0 {
+		errs = append(errs, errors.New("SEARCH_CONSUMER_CONCURRENCY, SEARCH_MAX_PAGE_SIZE and SEARCH_SUGGEST_TIMEOUT must be positive"))
+	}
+	if !slices.Contains(fuzzinessValues, cfg.Fuzziness) {
+		errs = append(errs, errors.New("SEARCH_FUZZINESS must be AUTO, 0, 1 or 2"))
+	}
+	if cfg.FuzzyPrefixLength < 0 || cfg.FuzzyMaxExpansions <= 0 || cfg.SynonymsReload <= 0 {
+		errs = append(errs, errors.New("SEARCH_FUZZY_PREFIX_LENGTH must not be negative, SEARCH_FUZZY_MAX_EXPANSIONS and SEARCH_SYNONYMS_RELOAD must be positive"))
+	}
+	return cfg, errors.Join(errs...)
+}
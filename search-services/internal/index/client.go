@@ -0,0 +1,209 @@
+// Package index talks to OpenSearch, or Elasticsearch, through opensearch-go: it creates
+// the indexes of the searchable content, writes their documents and runs the searches.
+package index
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/opensearch-project/opensearch-go/v4"
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+)
+
+var (
+	// ErrStale is returned by Put for a document older than the one indexed: events
+	// delivered late or twice do not overwrite a newer version.
+	ErrStale = errors.New("a newer version is indexed")
+	// ErrRejected is returned when the cluster refuses a request as invalid, such as a
+	// document that does not match the mapping; sending it again would fail the same way.
+	ErrRejected = errors.New("request rejected by the search cluster")
+	// ErrNotFound is returned by Get and Delete for a document that is not indexed.
+	ErrNotFound = errors.New("document not found")
+)
+
+// Client wraps the OpenSearch client with the few calls the service makes, mapping the
+// responses of the cluster to the errors above.
+type Client struct {
+	os      *opensearch.Client
+	timeout time.Duration
+}
+
+// NewClient returns a client of the cluster at baseURL, with basic authentication when
+// username is set. Each request is cancelled after timeout.
+func NewClient(baseURL, username, password string, timeout time.Duration) (*Client, error) {
+	client, err := opensearch.NewClient(opensearch.Config{
+		Addresses: []string{baseURL},
+		Username:  username,
+		Password:  password,
+		Transport: metrics.NewTransport("opensearch", http.DefaultTransport),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create OpenSearch client: %w", err)
+	}
+	return &Client{os: client, timeout: timeout}, nil
+}
+
+// Ping checks that the cluster answers.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.do(ctx, "ping", opensearchapi.PingReq{}, nil)
+	return err
+}
+
+// EnsureIndex creates the index with body, its settings and mappings, unless it exists.
+// Mappings of an existing index are left as they are: changing one means a new index.
+func (c *Client) EnsureIndex(ctx context.Context, name string, body any) error {
+	status, err := c.do(ctx, "check index "+name, opensearchapi.IndicesExistsReq{Indices: []string{name}}, nil)
+	if err == nil && status == http.StatusOK {
+		return nil
+	}
+	if err != nil && status != http.StatusNotFound {
+		return err
+	}
+	data, err := encode(body)
+	if err != nil {
+		return err
+	}
+	_, err = c.do(ctx, "create index "+name, opensearchapi.IndicesCreateReq{Index: name, Body: data}, nil)
+	var osErr *opensearch.StructError
+	if errors.As(err, &osErr) && osErr.Err.Type == "resource_already_exists_exception" {
+		// Created by another replica meanwhile
+		return nil
+	}
+	return err
+}
+
+// Put writes the document id of index at version, the time of the event it comes from.
+// It returns ErrStale when the index holds the same or a newer version.
+func (c *Client) Put(ctx context.Context, index, id string, version int64, doc any) error {
+	data, err := encode(doc)
+	if err != nil {
+		return err
+	}
+	status, err := c.do(ctx, "put "+index+"/"+id, opensearchapi.IndexReq{
+		Index:      index,
+		DocumentID: url.PathEscape(id),
+		Body:       data,
+		Params: opensearchapi.IndexParams{
+			Version:     opensearch.ToPointer(int(version)),
+			VersionType: "external",
+		},
+	}, nil)
+	if status == http.StatusConflict {
+		return ErrStale
+	}
+	return err
+}
+
+// Search runs body on the indexes and decodes the response into out.
+func (c *Client) Search(ctx context.Context, indexes []string, body, out any) error {
+	data, err := encode(body)
+	if err != nil {
+		return err
+	}
+	_, err = c.do(ctx, "search", opensearchapi.SearchReq{Indices: indexes, Body: data}, out)
+	return err
+}
+
+// DeleteByQuery deletes the documents of index matching query, skipping those changed
+// while it runs.
+func (c *Client) DeleteByQuery(ctx context.Context, index string, query any) error {
+	data, err := encode(map[string]any{"query": query})
+	if err != nil {
+		return err
+	}
+	_, err = c.do(ctx, "delete by query "+index, opensearchapi.DocumentDeleteByQueryReq{
+		Indices: []string{index},
+		Body:    data,
+		Params:  opensearchapi.DocumentDeleteByQueryParams{Conflicts: "proceed"},
+	}, nil)
+	return err
+}
+
+// Get reads the document id of index into out. It returns ErrNotFound when there is none.
+func (c *Client) Get(ctx context.Context, index, id string, out any) error {
+	var resp struct {
+		Source json.RawMessage `json:"_source"`
+	}
+	status, err := c.do(ctx, "get "+index+"/"+id, opensearchapi.DocumentGetReq{Index: index, DocumentID: url.PathEscape(id)}, &resp)
+	if status == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(resp.Source, out)
+}
+
+// Delete removes the document id of index. It returns ErrNotFound when there is none.
+func (c *Client) Delete(ctx context.Context, index, id string) error {
+	status, err := c.do(ctx, "delete "+index+"/"+id, opensearchapi.DocumentDeleteReq{Index: index, DocumentID: url.PathEscape(id)}, nil)
+	if status == http.StatusNotFound {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Refresh makes the writes to index visible to its searches at once, rather than within
+// the refresh interval of the cluster.
+func (c *Client) Refresh(ctx context.Context, index string) error {
+	_, err := c.do(ctx, "refresh "+index, opensearchapi.IndicesRefreshReq{Indices: []string{index}}, nil)
+	return err
+}
+
+// MultiGet reads the documents ids of index and decodes the response into out.
+func (c *Client) MultiGet(ctx context.Context, index string, ids []string, out any) error {
+	data, err := encode(map[string]any{"ids": ids})
+	if err != nil {
+		return err
+	}
+	_, err = c.do(ctx, "multi get "+index, opensearchapi.MGetReq{Index: index, Body: data}, out)
+	return err
+}
+
+// do sends req and decodes a successful response into out. It returns the status of the
+// response with an error for any status above 299; the error wraps ErrRejected for a
+// client error other than 404 and 409.
+func (c *Client) do(ctx context.Context, op string, req opensearch.Request, out any) (int, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	resp, err := c.os.Do(ctx, req, out)
+	if err != nil {
+		if resp != nil {
+			// The response does not decode into out
+			return resp.StatusCode, fmt.Errorf("%s: %w", op, err)
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if !resp.IsError() {
+		return resp.StatusCode, nil
+	}
+
+	err = fmt.Errorf("%s: status %d: %w", op, resp.StatusCode, opensearch.ParseError(resp))
+	if resp.StatusCode < 500 && resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusConflict {
+		err = fmt.Errorf("%w: %w", ErrRejected, err)
+	}
+	return resp.StatusCode, err
+}
+
+// encode is the JSON body of a request, nil when v is.
+func encode(v any) (io.Reader, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encode request body: %w", err)
+	}
+	return bytes.NewReader(data), nil
+}
@@ -0,0 +1,173 @@
+package index
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// clusterError is the body of an OpenSearch error response.
+func clusterError(status int, errType string) string {
+	return fmt.Sprintf(`{"error":{"root_cause":[{"type":%q,"reason":"test"}],"type":%q,"reason":"test"},"status":%d}`, errType, errType, status)
+}
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	client, err := NewClient(srv.URL, "search", "secret", 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// TestPutVersionsDocument checks that a document is written with the version of its event
+// as an external version, so that the cluster refuses older ones.
+func TestPutVersionsDocument(t *testing.T) {
+	var got struct {
+		method, path, version, versionType, user string
+		doc                                      Document
+	}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		got.method, got.path = r.Method, r.URL.EscapedPath()
+		got.version, got.versionType = r.URL.Query().Get("version"), r.URL.Query().Get("version_type")
+		got.user, _, _ = r.BasicAuth()
+		if err := json.NewDecoder(r.Body).Decode(&got.doc); err != nil {
+			t.Errorf("decode document: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"_index":"english-app-lessons","_id":"l1","_version":1700000000000000000,"result":"created"}`))
+	})
+
+	doc := Document{ID: "lesson:1", Type: TypeLesson, Visible: true, Title: "Past tense"}
+	if err := client.Put(context.Background(), "english-app-lessons", doc.ID, 1700000000000000000, doc); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	if got.method != http.MethodPut || got.path != "/english-app-lessons/_doc/lesson:1" {
+		t.Errorf("sent %s %s, want PUT /english-app-lessons/_doc/lesson:1", got.method, got.path)
+	}
+	if got.version != "1700000000000000000" || got.versionType != "external" {
+		t.Errorf("sent version %q of type %q, want 1700000000000000000 external", got.version, got.versionType)
+	}
+	if got.user != "search" {
+		t.Errorf("sent user %q, want search", got.user)
+	}
+	if got.doc.Title != doc.Title || !got.doc.Visible {
+		t.Errorf("sent document %+v, want %+v", got.doc, doc)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		call    func(*Client) error
+		want    error
+		wantNot []error
+	}{
+		{
+			name:   "older version is stale",
+			status: http.StatusConflict,
+			body:   clusterError(http.StatusConflict, "version_conflict_engine_exception"),
+			call: func(c *Client) error {
+				return c.Put(context.Background(), "english-app-lessons", "l1", 1, Document{ID: "l1"})
+			},
+			want:    ErrStale,
+			wantNot: []error{ErrRejected},
+		},
+		{
+			name:   "document not matching the mapping is rejected",
+			status: http.StatusBadRequest,
+			body:   clusterError(http.StatusBadRequest, "strict_dynamic_mapping_exception"),
+			call: func(c *Client) error {
+				return c.Put(context.Background(), "english-app-lessons", "l1", 1, map[string]any{"unknown": true})
+			},
+			want:    ErrRejected,
+			wantNot: []error{ErrStale},
+		},
+		{
+			name:   "cluster failure is neither",
+			status: http.StatusInternalServerError,
+			body:   clusterError(http.StatusInternalServerError, "exception"),
+			call: func(c *Client) error {
+				return c.Put(context.Background(), "english-app-lessons", "l1", 1, Document{ID: "l1"})
+			},
+			wantNot: []error{ErrStale, ErrRejected},
+		},
+		{
+			name:   "missing document is not found",
+			status: http.StatusNotFound,
+			body:   `{"_index":"english-app-synonyms","_id":"r1","found":false}`,
+			call: func(c *Client) error {
+				var doc map[string]any
+				return c.Get(context.Background(), "english-app-synonyms", "r1", &doc)
+			},
+			want:    ErrNotFound,
+			wantNot: []error{ErrRejected},
+		},
+		{
+			name:   "deleted document is not found",
+			status: http.StatusNotFound,
+			body:   `{"_index":"english-app-synonyms","_id":"r1","result":"not_found"}`,
+			call: func(c *Client) error {
+				return c.Delete(context.Background(), "english-app-synonyms", "r1")
+			},
+			want:    ErrNotFound,
+			wantNot: []error{ErrRejected},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			err := tt.call(client)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+			for _, other := range tt.wantNot {
+				if errors.Is(err, other) {
+					t.Errorf("got %v, which should not be %v", err, other)
+				}
+			}
+		})
+	}
+}
+
+// TestEnsureIndexCreatedMeanwhile checks that an index another replica creates between
+// the check and the creation is not an error.
+func TestEnsureIndexCreatedMeanwhile(t *testing.T) {
+	var created bool
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodHead:
+			w.WriteHeader(http.StatusNotFound)
+		case http.MethodPut:
+			created = true
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(clusterError(http.StatusBadRequest, "resource_already_exists_exception")))
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	if err := client.EnsureIndex(context.Background(), "english-app-courses", contentMapping); err != nil {
+		t.Fatalf("EnsureIndex: %v", err)
+	}
+	if !created {
+		t.Fatal("index was not created")
+	}
+}
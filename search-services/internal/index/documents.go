@@ -0,0 +1,188 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// The types of searchable content, the type field of their documents.
+const (
+	TypeCourse       = "course"
+	TypeLesson       = "lesson"
+	TypeFlashcardSet = "flashcard_set"
+)
+
+// Types lists the types of searchable content.
+var Types = []string{TypeCourse, TypeLesson, TypeFlashcardSet}
+
+// The kinds of taxonomy terms, the kind field of their documents.
+const (
+	KindTopic = "topic"
+	KindLevel = "level"
+)
+
+// Document is the indexed version of a course, lesson or flashcard set. Content hidden
+// from learners, unpublished or deleted, stays indexed with Visible false, so that an
+// older event delivered late cannot bring it back.
+type Document struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	Visible     bool   `json:"visible"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Code is the human-readable ID of a lesson
+	Code    string `json:"code,omitempty"`
+	TopicID string `json:"topic_id,omitempty"`
+	LevelID string `json:"level_id,omitempty"`
+
+	// Courses only
+	InstructorID  string  `json:"instructor_id,omitempty"`
+	ThumbnailURL  string  `json:"thumbnail_url,omitempty"`
+	IsFeatured    bool    `json:"is_featured,omitempty"`
+	Price         float64 `json:"price,omitempty"`
+	DurationHours int     `json:"duration_hours,omitempty"`
+	AverageRating float64 `json:"average_rating,omitempty"`
+	ReviewCount   int     `json:"review_count,omitempty"`
+
+	// PublishedAt is when a course or lesson was published, or a flashcard set created
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	IndexedAt   time.Time  `json:"indexed_at"`
+}
+
+// Term is the indexed version of a topic or level, naming the facets of the search.
+// Deleted terms stay indexed with Deleted true, like hidden documents.
+type Term struct {
+	ID      string `json:"id"`
+	Kind    string `json:"kind"`
+	Slug    string `json:"slug,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Deleted bool   `json:"deleted,omitempty"`
+}
+
+// Names are the names of the indexes, the same prefix before each.
+type Names struct {
+	prefix string
+}
+
+func NewNames(prefix string) Names {
+	return Names{prefix: prefix}
+}
+
+// Content is the index of the documents of a type of content, such as "english-app-courses".
+func (n Names) Content(docType string) string {
+	switch docType {
+	case TypeCourse:
+		return n.prefix + "courses"
+	case TypeLesson:
+		return n.prefix + "lessons"
+	default:
+		return n.prefix + "flashcard-sets"
+	}
+}
+
+// AllContent lists the indexes of every type of content, searched together.
+func (n Names) AllContent() []string {
+	names := make([]string, len(Types))
+	for i, docType := range Types {
+		names[i] = n.Content(docType)
+	}
+	return names
+}
+
+// Taxonomy is the index of the topics and levels.
+func (n Names) Taxonomy() string {
+	return n.prefix + "taxonomy"
+}
+
+// Synonyms is the index of the synonym rules admins add.
+func (n Names) Synonyms() string {
+	return n.prefix + "synonyms"
+}
+
+// contentMapping is shared by the indexes of the three types of content, so that one
+// search and one set of facets covers them.
+var contentMapping = map[string]any{
+	"settings": map[string]any{
+		"analysis": map[string]any{
+			"analyzer": map[string]any{
+				// Titles are matched as typed, accents and case aside
+				"folded": map[string]any{
+					"type":      "custom",
+					"tokenizer": "standard",
+					"filter":    []string{"lowercase", "asciifolding"},
+				},
+			},
+		},
+	},
+	"mappings": map[string]any{
+		"dynamic": "strict",
+		"properties": map[string]any{
+			"id":          map[string]any{"type": "keyword"},
+			"type":        map[string]any{"type": "keyword"},
+			"visible":     map[string]any{"type": "boolean"},
+			"title":       map[string]any{"type": "text", "analyzer": "folded", "fields": map[string]any{"sort": map[string]any{"type": "keyword", "ignore_above": 256}}},
+			"description": map[string]any{"type": "text", "analyzer": "folded"},
+			"code":        map[string]any{"type": "keyword"},
+			"topic_id":    map[string]any{"type": "keyword"},
+			"level_id":    map[string]any{"type": "keyword"},
+
+			"instructor_id":  map[string]any{"type": "keyword"},
+			"thumbnail_url":  map[string]any{"type": "keyword", "index": false},
+			"is_featured":    map[string]any{"type": "boolean"},
+			"price":          map[string]any{"type": "scaled_float", "scaling_factor": 100},
+			"duration_hours": map[string]any{"type": "integer"},
+			"average_rating": map[string]any{"type": "float"},
+			"review_count":   map[string]any{"type": "integer"},
+
+			"published_at": map[string]any{"type": "date"},
+			"indexed_at":   map[string]any{"type": "date"},
+		},
+	},
+}
+
+var taxonomyMapping = map[string]any{
+	"mappings": map[string]any{
+		"dynamic": "strict",
+		"properties": map[string]any{
+			"id":      map[string]any{"type": "keyword"},
+			"kind":    map[string]any{"type": "keyword"},
+			"slug":    map[string]any{"type": "keyword"},
+			"code":    map[string]any{"type": "keyword"},
+			"name":    map[string]any{"type": "text", "fields": map[string]any{"raw": map[string]any{"type": "keyword"}}},
+			"deleted": map[string]any{"type": "boolean"},
+		},
+	},
+}
+
+var synonymsMapping = map[string]any{
+	"mappings": map[string]any{
+		"dynamic": "strict",
+		"properties": map[string]any{
+			"id":           map[string]any{"type": "keyword"},
+			"terms":        map[string]any{"type": "keyword"},
+			"misspellings": map[string]any{"type": "keyword"},
+			"updated_at":   map[string]any{"type": "date"},
+		},
+	},
+}
+
+// EnsureIndexes creates the missing indexes of the service.
+func EnsureIndexes(ctx context.Context, client *Client, names Names) error {
+	for _, name := range names.AllContent() {
+		if err := client.EnsureIndex(ctx, name, contentMapping); err != nil {
+			return fmt.Errorf("create index %s: %w", name, err)
+		}
+	}
+	if err := client.EnsureIndex(ctx, names.Taxonomy(), taxonomyMapping); err != nil {
+		return fmt.Errorf("create index %s: %w", names.Taxonomy(), err)
+	}
+	if err := client.EnsureIndex(ctx, names.Suggestions(), suggestionsMapping); err != nil {
+		return fmt.Errorf("create index %s: %w", names.Suggestions(), err)
+	}
+	if err := client.EnsureIndex(ctx, names.Synonyms(), synonymsMapping); err != nil {
+		return fmt.Errorf("create index %s: %w", names.Synonyms(), err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package index
+
+import (
+	"strings"
+	"time"
+)
+
+// TypeGlossary is the type of the suggestions of glossary terms, the fronts of the
+// flashcards. Courses and lessons are suggested under their own types.
+const TypeGlossary = "glossary"
+
+// SuggestionTypes lists the types of suggestions.
+var SuggestionTypes = []string{TypeCourse, TypeLesson, TypeGlossary}
+
+// The locales with a suggester of their own.
+const (
+	LocaleEN = "en"
+	LocaleVI = "vi"
+)
+
+// Locales lists the locales with a suggester.
+var Locales = []string{LocaleEN, LocaleVI}
+
+// SuggestField is the completion field of the suggester of locale.
+func SuggestField(locale string) string {
+	return "suggest_" + locale
+}
+
+// Suggestion is a completion of the search box: the title of a course or lesson, or a
+// glossary term with its meaning. Hidden content keeps its suggestion without inputs,
+// which the suggester never returns, like the hidden documents of the content indexes.
+type Suggestion struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+	// Meaning is the back of the flashcard of a glossary term
+	Meaning string `json:"meaning,omitempty"`
+	// SetID is the flashcard set of a glossary term
+	SetID string `json:"set_id,omitempty"`
+
+	SuggestEN *Completion `json:"suggest_en,omitempty"`
+	SuggestVI *Completion `json:"suggest_vi,omitempty"`
+	IndexedAt time.Time   `json:"indexed_at"`
+}
+
+// Completion is the value of a completion field: the inputs a prefix is matched against,
+// and the weight ranking the suggestion among those matching.
+type Completion struct {
+	Input  []string `json:"input"`
+	Weight int      `json:"weight"`
+}
+
+// SetInputs makes the suggestion complete the words of texts in every locale, ranked by
+// weight.
+func (s *Suggestion) SetInputs(weight int, texts ...string) {
+	inputs := Inputs(texts...)
+	if len(inputs) == 0 {
+		return
+	}
+	s.SuggestEN = &Completion{Input: inputs, Weight: weight}
+	s.SuggestVI = &Completion{Input: inputs, Weight: weight}
+}
+
+// maxInputWords bounds the inputs of a text: a prefix matches from the start of one of
+// its first words only.
+const maxInputWords = 6
+
+// Inputs are the inputs of text, the text from each of its words, so that "perf" also
+// completes "Present Perfect".
+func Inputs(texts ...string) []string {
+	var inputs []string
+	seen := map[string]bool{}
+	for _, text := range texts {
+		words := strings.Fields(text)
+		for i := 0; i < len(words) && i < maxInputWords; i++ {
+			input := strings.Join(words[i:], " ")
+			if !seen[input] {
+				seen[input] = true
+				inputs = append(inputs, input)
+			}
+		}
+	}
+	return inputs
+}
+
+// Suggestions is the index of the suggestions of every type.
+func (n Names) Suggestions() string {
+	return n.prefix + "suggestions"
+}
+
+// suggestionsMapping has a completion field per locale. English folds case and drops
+// possessives; Vietnamese also matches words typed without their diacritics.
+var suggestionsMapping = map[string]any{
+	"settings": map[string]any{
+		"analysis": map[string]any{
+			"filter": map[string]any{
+				"possessive_en": map[string]any{"type": "stemmer", "language": "possessive_english"},
+				"folding_vi":    map[string]any{"type": "asciifolding", "preserve_original": true},
+			},
+			"analyzer": map[string]any{
+				"suggest_en": map[string]any{
+					"type":      "custom",
+					"tokenizer": "standard",
+					"filter":    []string{"lowercase", "possessive_en", "asciifolding"},
+				},
+				"suggest_vi": map[string]any{
+					"type":      "custom",
+					"tokenizer": "standard",
+					"filter":    []string{"lowercase", "folding_vi"},
+				},
+			},
+		},
+	},
+	"mappings": map[string]any{
+		"dynamic": "strict",
+		"properties": map[string]any{
+			"id":         map[string]any{"type": "keyword"},
+			"type":       map[string]any{"type": "keyword"},
+			"text":       map[string]any{"type": "keyword", "index": false},
+			"meaning":    map[string]any{"type": "keyword", "index": false},
+			"set_id":     map[string]any{"type": "keyword"},
+			"suggest_en": completionMapping("suggest_en"),
+			"suggest_vi": completionMapping("suggest_vi"),
+			"indexed_at": map[string]any{"type": "date"},
+		},
+	},
+}
+
+// completionMapping is a completion field analyzed by analyzer, filtered by the type of
+// the suggestion.
+func completionMapping(analyzer string) map[string]any {
+	return map[string]any{
+		"type":             "completion",
+		"analyzer":         analyzer,
+		"max_input_length": 100,
+		"contexts": []any{
+			map[string]any{"name": "type", "type": "category", "path": "type"},
+		},
+	}
+}
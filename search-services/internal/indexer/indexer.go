@@ -0,0 +1,274 @@
+// Package indexer keeps the search indexes up to date with the content events published
+// by content-services.
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"search-services/internal/index"
+
+	"github.com/ductan2/microservice-app/shared/consumer"
+	"github.com/ductan2/microservice-app/shared/events"
+	"github.com/google/uuid"
+)
+
+// EventTypes are the content events the indexer handles. LessonCreated is not among
+// them: drafts are not searchable.
+var EventTypes = []string{
+	"LessonUpdated", "LessonPublished", "LessonUnpublished", "LessonDeleted",
+	"CourseUpdated", "CoursePublished", "CourseUnpublished", "CourseDeleted",
+	"FlashcardSetCreated", "FlashcardSetUpdated", "FlashcardSetDeleted",
+	"FlashcardSaved", "FlashcardDeleted",
+	"TopicSaved", "TopicDeleted", "LevelSaved", "LevelDeleted",
+}
+
+// Indexer writes the documents of each content event it handles: the content itself and
+// its suggestion for the search box. Documents are versioned with the time of their event,
+// so events can be handled in any order and more than once.
+type Indexer struct {
+	client *index.Client
+	names  index.Names
+}
+
+func New(client *index.Client, names index.Names) *Indexer {
+	return &Indexer{client: client, names: names}
+}
+
+// Register adds the handlers of the indexer to c, binding its queue to the events.
+func (ix *Indexer) Register(c *consumer.Consumer) {
+	for _, eventType := range EventTypes {
+		c.Handle(eventType, ix.Handle)
+	}
+}
+
+// Handle indexes the event of a message routed by its type. Payloads that do not decode
+// and documents the cluster rejects are dead-lettered; other failures are retried.
+func (ix *Indexer) Handle(ctx context.Context, msg consumer.Message) error {
+	event, err := events.Decode(msg.RoutingKey, msg.Body)
+	if err != nil {
+		return consumer.Permanent(err)
+	}
+	writes, ok := ix.writes(event)
+	if !ok {
+		return consumer.Permanent(fmt.Errorf("%s events are not indexed", msg.RoutingKey))
+	}
+
+	for _, w := range writes {
+		err = ix.client.Put(ctx, w.index, w.id, w.occurredAt.UnixNano(), w.doc)
+		switch {
+		case errors.Is(err, index.ErrStale):
+			slog.DebugContext(ctx, "skipped stale event", "event_type", msg.RoutingKey, "index", w.index, "id", w.id)
+			continue
+		case errors.Is(err, index.ErrRejected):
+			return consumer.Permanent(err)
+		case err != nil:
+			return err
+		}
+		slog.DebugContext(ctx, "indexed event", "event_type", msg.RoutingKey, "index", w.index, "id", w.id)
+	}
+
+	// The glossary terms of a deleted set are not in the event: they are deleted by set,
+	// after the set is hidden so that a retry still reaches them
+	if e, ok := event.(*events.FlashcardSetDeleted); ok {
+		query := map[string]any{"term": map[string]any{"set_id": e.SetID.String()}}
+		if err := ix.client.DeleteByQuery(ctx, ix.names.Suggestions(), query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// write is the document an event writes, with the time of the event as its version.
+type write struct {
+	index      string
+	id         string
+	doc        any
+	occurredAt time.Time
+}
+
+// writes are the documents an event writes: the content and its suggestion, or either.
+func (ix *Indexer) writes(event events.Event) ([]write, bool) {
+	now := time.Now().UTC()
+	switch e := event.(type) {
+	case *events.LessonUpdated:
+		return ix.content(e.Meta, lesson(e.LessonID, e.Title, e.IsPublished, e.PublishedAt, e.LessonDetails, now)), true
+	case *events.LessonPublished:
+		return ix.content(e.Meta, lesson(e.LessonID, e.Title, true, &e.PublishedAt, e.LessonDetails, now)), true
+	case *events.LessonUnpublished:
+		return ix.content(e.Meta, hidden(index.TypeLesson, e.LessonID, now)), true
+	case *events.LessonDeleted:
+		return ix.content(e.Meta, hidden(index.TypeLesson, e.LessonID, now)), true
+
+	case *events.CourseUpdated:
+		return ix.content(e.Meta, course(e.CourseID, e.Title, e.IsPublished, e.PublishedAt, e.CourseDetails, now)), true
+	case *events.CoursePublished:
+		return ix.content(e.Meta, course(e.CourseID, e.Title, true, &e.PublishedAt, e.CourseDetails, now)), true
+	case *events.CourseUnpublished:
+		return ix.content(e.Meta, hidden(index.TypeCourse, e.CourseID, now)), true
+	case *events.CourseDeleted:
+		return ix.content(e.Meta, hidden(index.TypeCourse, e.CourseID, now)), true
+
+	case *events.FlashcardSetCreated:
+		return ix.content(e.Meta, flashcardSet(e.SetID, e.Title, e.CreatedAt, e.FlashcardSetDetails, now)), true
+	case *events.FlashcardSetUpdated:
+		return ix.content(e.Meta, flashcardSet(e.SetID, e.Title, e.CreatedAt, e.FlashcardSetDetails, now)), true
+	case *events.FlashcardSetDeleted:
+		return ix.content(e.Meta, hidden(index.TypeFlashcardSet, e.SetID, now)), true
+
+	case *events.FlashcardSaved:
+		return []write{ix.suggestion(e.Meta, glossary(e, now))}, true
+	case *events.FlashcardDeleted:
+		return []write{ix.suggestion(e.Meta, &index.Suggestion{ID: e.FlashcardID.String(), Type: index.TypeGlossary, SetID: e.SetID.String(), IndexedAt: now})}, true
+
+	case *events.TopicSaved:
+		return []write{ix.term(e.Meta, index.Term{ID: e.TopicID.String(), Kind: index.KindTopic, Slug: e.Slug, Name: e.Name})}, true
+	case *events.TopicDeleted:
+		return []write{ix.term(e.Meta, index.Term{ID: e.TopicID.String(), Kind: index.KindTopic, Deleted: true})}, true
+	case *events.LevelSaved:
+		return []write{ix.term(e.Meta, index.Term{ID: e.LevelID.String(), Kind: index.KindLevel, Code: e.Code, Name: e.Name})}, true
+	case *events.LevelDeleted:
+		return []write{ix.term(e.Meta, index.Term{ID: e.LevelID.String(), Kind: index.KindLevel, Deleted: true})}, true
+	}
+	return nil, false
+}
+
+// content writes doc, and the suggestion of its title when it is a course or lesson.
+// Flashcard sets are not suggested: their cards are.
+func (ix *Indexer) content(meta events.Meta, doc *index.Document) []write {
+	writes := []write{{index: ix.names.Content(doc.Type), id: doc.ID, doc: doc, occurredAt: meta.OccurredAt}}
+	if doc.Type != index.TypeFlashcardSet {
+		writes = append(writes, ix.suggestion(meta, titleSuggestion(doc)))
+	}
+	return writes
+}
+
+func (ix *Indexer) suggestion(meta events.Meta, s *index.Suggestion) write {
+	return write{index: ix.names.Suggestions(), id: s.ID, doc: s, occurredAt: meta.OccurredAt}
+}
+
+func (ix *Indexer) term(meta events.Meta, term index.Term) write {
+	return write{index: ix.names.Taxonomy(), id: term.ID, doc: term, occurredAt: meta.OccurredAt}
+}
+
+func lesson(id uuid.UUID, title string, published bool, publishedAt *time.Time, details events.LessonDetails, now time.Time) *index.Document {
+	if !published {
+		return hidden(index.TypeLesson, id, now)
+	}
+	return &index.Document{
+		ID:          id.String(),
+		Type:        index.TypeLesson,
+		Visible:     true,
+		Title:       title,
+		Description: details.Description,
+		Code:        details.Code,
+		TopicID:     idString(details.TopicID),
+		LevelID:     idString(details.LevelID),
+		PublishedAt: publishedAt,
+		IndexedAt:   now,
+	}
+}
+
+func course(id uuid.UUID, title string, published bool, publishedAt *time.Time, details events.CourseDetails, now time.Time) *index.Document {
+	if !published {
+		return hidden(index.TypeCourse, id, now)
+	}
+	return &index.Document{
+		ID:            id.String(),
+		Type:          index.TypeCourse,
+		Visible:       true,
+		Title:         title,
+		Description:   details.Description,
+		TopicID:       idString(details.TopicID),
+		LevelID:       idString(details.LevelID),
+		InstructorID:  idString(details.InstructorID),
+		ThumbnailURL:  details.ThumbnailURL,
+		IsFeatured:    details.IsFeatured,
+		Price:         details.Price,
+		DurationHours: details.DurationHours,
+		AverageRating: details.AverageRating,
+		ReviewCount:   details.ReviewCount,
+		PublishedAt:   publishedAt,
+		IndexedAt:     now,
+	}
+}
+
+func flashcardSet(id uuid.UUID, title string, createdAt time.Time, details events.FlashcardSetDetails, now time.Time) *index.Document {
+	return &index.Document{
+		ID:          id.String(),
+		Type:        index.TypeFlashcardSet,
+		Visible:     true,
+		Title:       title,
+		Description: details.Description,
+		TopicID:     idString(details.TopicID),
+		LevelID:     idString(details.LevelID),
+		PublishedAt: &createdAt,
+		IndexedAt:   now,
+	}
+}
+
+// hidden is the document of content learners cannot see, unpublished or deleted.
+func hidden(docType string, id uuid.UUID, now time.Time) *index.Document {
+	return &index.Document{ID: id.String(), Type: docType, IndexedAt: now}
+}
+
+// The weights of the suggestions: courses rank above lessons, and lessons above glossary
+// terms, among those completing the same prefix.
+const (
+	courseWeight   = 100
+	lessonWeight   = 50
+	glossaryWeight = 20
+	// featuredBoost is added to the weight of a featured course
+	featuredBoost = 50
+)
+
+// titleSuggestion suggests the title of a visible course or lesson, and the code of a
+// lesson. A hidden one keeps its suggestion without inputs.
+func titleSuggestion(doc *index.Document) *index.Suggestion {
+	s := &index.Suggestion{ID: doc.ID, Type: doc.Type, IndexedAt: doc.IndexedAt}
+	if !doc.Visible {
+		return s
+	}
+	s.Text = doc.Title
+	weight := lessonWeight
+	if doc.Type == index.TypeCourse {
+		weight = courseWeight + popularity(doc)
+	}
+	s.SetInputs(weight, doc.Title, doc.Code)
+	return s
+}
+
+// popularity boosts a course by its reviews: the more and the better they are, the higher
+// it ranks, with diminishing returns so that a few famous courses do not hide the rest.
+func popularity(doc *index.Document) int {
+	boost := int(10 * math.Log2(1+float64(doc.ReviewCount)) * doc.AverageRating / 5)
+	if doc.IsFeatured {
+		boost += featuredBoost
+	}
+	return boost
+}
+
+// glossary suggests the front of a flashcard, with its back as the meaning shown next to it.
+func glossary(e *events.FlashcardSaved, now time.Time) *index.Suggestion {
+	s := &index.Suggestion{
+		ID:        e.FlashcardID.String(),
+		Type:      index.TypeGlossary,
+		Text:      e.FrontText,
+		Meaning:   e.BackText,
+		SetID:     e.SetID.String(),
+		IndexedAt: now,
+	}
+	s.SetInputs(glossaryWeight, e.FrontText)
+	return s
+}
+
+func idString(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
@@ -0,0 +1,249 @@
+package indexer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"search-services/internal/index"
+
+	"github.com/ductan2/microservice-app/shared/consumer"
+	"github.com/ductan2/microservice-app/shared/events"
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// put is a document written to the fake cluster.
+type put struct {
+	version int64
+	body    json.RawMessage
+}
+
+// cluster is a fake OpenSearch keeping the documents written to it by index and ID, and
+// refusing a version no newer than the one it holds, as the real one does.
+type cluster struct {
+	mu   sync.Mutex
+	docs map[string]put
+	// status, when set, answers every write
+	status int
+}
+
+func newCluster(t *testing.T) (*cluster, *index.Client) {
+	t.Helper()
+	c := &cluster{docs: map[string]put{}}
+	srv := httptest.NewServer(c)
+	t.Cleanup(srv.Close)
+	client, err := index.NewClient(srv.URL, "", "", 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, client
+}
+
+func (c *cluster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.status != 0 {
+		w.WriteHeader(c.status)
+		w.Write([]byte(`{"error":{"type":"exception","reason":"test"},"status":` + strconv.Itoa(c.status) + `}`))
+		return
+	}
+	if r.Method != http.MethodPut || !strings.Contains(r.URL.Path, "/_doc/") {
+		w.Write([]byte(`{}`))
+		return
+	}
+
+	version, _ := strconv.ParseInt(r.URL.Query().Get("version"), 10, 64)
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	if held, ok := c.docs[key]; ok && held.version >= version {
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"error":{"type":"version_conflict_engine_exception","reason":"current version is higher"},"status":409}`))
+		return
+	}
+	var body json.RawMessage
+	json.NewDecoder(r.Body).Decode(&body)
+	c.docs[key] = put{version: version, body: body}
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(`{"result":"created"}`))
+}
+
+// doc decodes the document key, index/_doc/id, into out and returns its version.
+func (c *cluster) doc(t *testing.T, key string, out any) int64 {
+	t.Helper()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	held, ok := c.docs[key]
+	if !ok {
+		t.Fatalf("%s was not indexed", key)
+	}
+	if err := json.Unmarshal(held.body, out); err != nil {
+		t.Fatal(err)
+	}
+	return held.version
+}
+
+func message(t *testing.T, e events.Event) consumer.Message {
+	t.Helper()
+	body, err := events.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return consumer.Message{Delivery: amqp.Delivery{RoutingKey: e.EventType(), Body: body}, Attempt: 1}
+}
+
+var names = index.NewNames("english-app-")
+
+func TestHandleIndexesPublishedLesson(t *testing.T) {
+	c, client := newCluster(t)
+	ix := New(client, names)
+
+	topicID := uuid.New()
+	occurredAt := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	e := &events.LessonPublished{
+		Meta:          events.Meta{OccurredAt: occurredAt},
+		LessonID:      uuid.New(),
+		Title:         "Present Perfect",
+		PublishedAt:   occurredAt,
+		LessonDetails: events.LessonDetails{Code: "GR-12", Description: "Have you ever...", TopicID: &topicID},
+	}
+	if err := ix.Handle(context.Background(), message(t, e)); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+
+	var doc index.Document
+	version := c.doc(t, "english-app-lessons/_doc/"+e.LessonID.String(), &doc)
+	if version != occurredAt.UnixNano() {
+		t.Errorf("lesson indexed at version %d, want the time of its event %d", version, occurredAt.UnixNano())
+	}
+	if !doc.Visible || doc.Type != index.TypeLesson || doc.Title != e.Title || doc.Code != "GR-12" || doc.TopicID != topicID.String() {
+		t.Errorf("lesson indexed as %+v", doc)
+	}
+
+	var suggestion index.Suggestion
+	c.doc(t, "english-app-suggestions/_doc/"+e.LessonID.String(), &suggestion)
+	if suggestion.SuggestEN == nil || suggestion.SuggestEN.Weight != lessonWeight {
+		t.Fatalf("lesson suggested as %+v", suggestion)
+	}
+	for _, input := range []string{"Present Perfect", "Perfect", "GR-12"} {
+		if !slices.Contains(suggestion.SuggestEN.Input, input) {
+			t.Errorf("suggestion inputs %q lack %q", suggestion.SuggestEN.Input, input)
+		}
+	}
+}
+
+func TestHandleHidesUnpublishedCourse(t *testing.T) {
+	c, client := newCluster(t)
+	ix := New(client, names)
+
+	courseID := uuid.New()
+	published := &events.CoursePublished{
+		Meta:        events.Meta{OccurredAt: time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)},
+		CourseID:    courseID,
+		Title:       "Business English",
+		PublishedAt: time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC),
+	}
+	unpublished := &events.CourseUnpublished{
+		Meta:     events.Meta{OccurredAt: time.Date(2025, 3, 2, 9, 0, 0, 0, time.UTC)},
+		CourseID: courseID,
+	}
+	for _, e := range []events.Event{published, unpublished} {
+		if err := ix.Handle(context.Background(), message(t, e)); err != nil {
+			t.Fatalf("Handle %s: %v", e.EventType(), err)
+		}
+	}
+
+	var doc index.Document
+	c.doc(t, "english-app-courses/_doc/"+courseID.String(), &doc)
+	if doc.Visible || doc.Title != "" {
+		t.Errorf("unpublished course indexed as %+v, want hidden", doc)
+	}
+	var suggestion index.Suggestion
+	c.doc(t, "english-app-suggestions/_doc/"+courseID.String(), &suggestion)
+	if suggestion.SuggestEN != nil || suggestion.SuggestVI != nil {
+		t.Errorf("unpublished course still suggested: %+v", suggestion)
+	}
+}
+
+// TestHandleSkipsStaleEvent checks that an event older than the indexed document, such as
+// a publication delivered after the lesson was deleted, changes nothing and is not retried.
+func TestHandleSkipsStaleEvent(t *testing.T) {
+	c, client := newCluster(t)
+	ix := New(client, names)
+
+	lessonID := uuid.New()
+	deleted := &events.LessonDeleted{Meta: events.Meta{OccurredAt: time.Date(2025, 3, 2, 9, 0, 0, 0, time.UTC)}, LessonID: lessonID}
+	late := &events.LessonPublished{
+		Meta:        events.Meta{OccurredAt: time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)},
+		LessonID:    lessonID,
+		Title:       "Present Perfect",
+		PublishedAt: time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC),
+	}
+	for _, e := range []events.Event{deleted, late, deleted} {
+		if err := ix.Handle(context.Background(), message(t, e)); err != nil {
+			t.Fatalf("Handle %s: %v", e.EventType(), err)
+		}
+	}
+
+	var doc index.Document
+	version := c.doc(t, "english-app-lessons/_doc/"+lessonID.String(), &doc)
+	if doc.Visible || version != deleted.OccurredAt.UnixNano() {
+		t.Errorf("stale event overwrote the deleted lesson: %+v at version %d", doc, version)
+	}
+}
+
+func TestHandleFailures(t *testing.T) {
+	lesson := &events.LessonDeleted{LessonID: uuid.New()}
+	tests := []struct {
+		name          string
+		status        int
+		msg           func(t *testing.T) consumer.Message
+		wantPermanent bool
+	}{
+		{
+			name:          "document rejected by the cluster",
+			status:        http.StatusBadRequest,
+			msg:           func(t *testing.T) consumer.Message { return message(t, lesson) },
+			wantPermanent: true,
+		},
+		{
+			name:   "cluster failing",
+			status: http.StatusInternalServerError,
+			msg:    func(t *testing.T) consumer.Message { return message(t, lesson) },
+		},
+		{
+			name: "payload that does not decode",
+			msg: func(t *testing.T) consumer.Message {
+				return consumer.Message{Delivery: amqp.Delivery{RoutingKey: "LessonDeleted", Body: []byte(`{"lesson_id":"not-a-uuid"}`)}}
+			},
+			wantPermanent: true,
+		},
+		{
+			name: "event not indexed",
+			msg: func(t *testing.T) consumer.Message {
+				return message(t, &events.LessonCreated{LessonID: uuid.New(), Title: "Draft"})
+			},
+			wantPermanent: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, client := newCluster(t)
+			c.status = tt.status
+
+			err := New(client, names).Handle(context.Background(), tt.msg(t))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if consumer.IsPermanent(err) != tt.wantPermanent {
+				t.Errorf("got %v, permanent %v, want permanent %v", err, consumer.IsPermanent(err), tt.wantPermanent)
+			}
+		})
+	}
+}
@@ -0,0 +1,474 @@
+// Package search runs the searches of the API: a full-text query over the courses,
+// lessons and flashcard sets learners can see, with filters, facets and pagination, and
+// the completions of the search box.
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"search-services/internal/index"
+	"search-services/internal/synonyms"
+
+	"github.com/ductan2/microservice-app/shared/apperr"
+)
+
+// maxWindow is the deepest result a search can page to, the default
+// index.max_result_window of OpenSearch.
+const maxWindow = 10000
+
+// facetSize is how many values each facet lists, the most frequent first.
+const facetSize = 50
+
+// The orders of the results.
+const (
+	SortRelevance = "relevance"
+	SortNewest    = "newest"
+	SortRating    = "rating"
+	SortTitle     = "title"
+)
+
+var sorts = []string{SortRelevance, SortNewest, SortRating, SortTitle}
+
+// Fuzziness is the typo tolerance of the searches and suggestions.
+type Fuzziness struct {
+	// Edits is how many edits a typed word may be from an indexed one: AUTO, scaled with
+	// the length of the word, or a fixed 0, 1 or 2. 0 turns typo tolerance off
+	Edits string
+	// PrefixLength is how many first characters of a word have to be typed right
+	PrefixLength int
+	// MaxExpansions bounds the indexed words a misspelled one is matched against
+	MaxExpansions int
+}
+
+// On reports whether typos are tolerated.
+func (f Fuzziness) On() bool {
+	return f.Edits != "" && f.Edits != "0"
+}
+
+// Query is a search. Values of the same filter match any of them; different filters all
+// have to match.
+type Query struct {
+	Text     string
+	Types    []string
+	TopicIDs []string
+	LevelIDs []string
+	// Featured keeps the featured courses only
+	Featured bool
+	// Exact turns off typo tolerance and the dictionary for this search
+	Exact    bool
+	Sort     string
+	Page     int
+	PageSize int
+}
+
+// ParseQuery reads a query from the parameters of GET /api/v1/search: q, type, topic_id
+// and level_id, repeated or comma-separated, featured, fuzzy, sort, page and page_size.
+func ParseQuery(params url.Values, maxPageSize int) (Query, error) {
+	q := Query{
+		Text:     strings.TrimSpace(params.Get("q")),
+		Types:    list(params["type"]),
+		TopicIDs: list(params["topic_id"]),
+		LevelIDs: list(params["level_id"]),
+		Sort:     params.Get("sort"),
+		Page:     1,
+		PageSize: min(20, maxPageSize),
+	}
+	var problems []string
+	for _, docType := range q.Types {
+		if !slices.Contains(index.Types, docType) {
+			problems = append(problems, "type must be one of "+strings.Join(index.Types, ", "))
+			break
+		}
+	}
+	if value := params.Get("featured"); value != "" {
+		featured, err := strconv.ParseBool(value)
+		if err != nil {
+			problems = append(problems, "featured must be true or false")
+		}
+		q.Featured = featured
+	}
+	if value := params.Get("fuzzy"); value != "" {
+		fuzzy, err := strconv.ParseBool(value)
+		if err != nil {
+			problems = append(problems, "fuzzy must be true or false")
+		}
+		q.Exact = !fuzzy
+	}
+	if q.Sort == "" {
+		q.Sort = SortRelevance
+		if q.Text == "" {
+			q.Sort = SortNewest
+		}
+	} else if !slices.Contains(sorts, q.Sort) {
+		problems = append(problems, "sort must be one of "+strings.Join(sorts, ", "))
+	}
+	if value := params.Get("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil || page < 1 {
+			problems = append(problems, "page must be a positive integer")
+		}
+		q.Page = page
+	}
+	if value := params.Get("page_size"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 1 || size > maxPageSize {
+			problems = append(problems, fmt.Sprintf("page_size must be between 1 and %d", maxPageSize))
+		}
+		q.PageSize = size
+	}
+	if len(problems) == 0 && q.Page*q.PageSize > maxWindow {
+		problems = append(problems, fmt.Sprintf("results past the first %d cannot be paged to; refine the search", maxWindow))
+	}
+	if len(problems) > 0 {
+		return Query{}, apperr.New(apperr.ValidationFailed, "invalid search parameters").WithDetails(problems)
+	}
+	return q, nil
+}
+
+// list splits the comma-separated values of a parameter given once or more.
+func list(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for item := range strings.SplitSeq(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// Body is the OpenSearch request of the query, its text rewritten with rw. The filters of
+// the facets are post filters, and each facet counts the results of the other facet
+// filters only, so that picking a value of a facet still shows the counts of the other
+// values.
+func (q Query) Body(rw synonyms.Rewrite, fuzz Fuzziness) map[string]any {
+	must := []any{map[string]any{"match_all": map[string]any{}}}
+	if q.Text != "" {
+		must = []any{q.textClause(rw, fuzz)}
+	}
+	filter := []any{map[string]any{"term": map[string]any{"visible": true}}}
+	if q.Featured {
+		filter = append(filter, map[string]any{"term": map[string]any{"is_featured": true}})
+	}
+
+	facetFilters := map[string]any{}
+	for field, values := range map[string][]string{"type": q.Types, "topic_id": q.TopicIDs, "level_id": q.LevelIDs} {
+		if len(values) > 0 {
+			facetFilters[field] = map[string]any{"terms": map[string]any{field: values}}
+		}
+	}
+	// others are the facet filters other than that of field
+	others := func(field string) []any {
+		clauses := []any{}
+		for other, clause := range facetFilters {
+			if other != field {
+				clauses = append(clauses, clause)
+			}
+		}
+		return clauses
+	}
+	aggs := map[string]any{}
+	for _, field := range []string{"type", "topic_id", "level_id"} {
+		aggs[field] = map[string]any{
+			"filter": map[string]any{"bool": map[string]any{"filter": others(field)}},
+			"aggs": map[string]any{
+				"values": map[string]any{"terms": map[string]any{"field": field, "size": facetSize}},
+			},
+		}
+	}
+
+	return map[string]any{
+		"from":             (q.Page - 1) * q.PageSize,
+		"size":             q.PageSize,
+		"track_total_hits": true,
+		"query":            map[string]any{"bool": map[string]any{"must": must, "filter": filter}},
+		"post_filter":      map[string]any{"bool": map[string]any{"filter": others("")}},
+		"aggs":             aggs,
+		"sort":             q.sortClause(),
+		"highlight": map[string]any{
+			"pre_tags":  []string{"<em>"},
+			"post_tags": []string{"</em>"},
+			"fields":    map[string]any{"title": map[string]any{}, "description": map[string]any{}},
+		},
+	}
+}
+
+// textClause matches the text as typed, its corrected version and the synonyms of its
+// terms: any of them is a match, and a document matching several ranks higher. Synonyms
+// weigh less than the words the learner typed.
+func (q Query) textClause(rw synonyms.Rewrite, fuzz Fuzziness) map[string]any {
+	match := func(text string, boost float64, fuzzy bool) map[string]any {
+		clause := map[string]any{
+			"query":  text,
+			"fields": []string{"title^3", "code^3", "description"},
+			"boost":  boost,
+		}
+		if fuzzy && fuzz.On() && !q.Exact {
+			clause["fuzziness"] = fuzz.Edits
+			clause["prefix_length"] = fuzz.PrefixLength
+			clause["max_expansions"] = fuzz.MaxExpansions
+		}
+		return map[string]any{"multi_match": clause}
+	}
+
+	should := []any{match(q.Text, 1, true)}
+	if rw.Corrected != "" {
+		should = append(should, match(rw.Corrected, 1, true))
+	}
+	if len(rw.Synonyms) > 0 {
+		should = append(should, match(strings.Join(rw.Synonyms, " "), 0.5, false))
+	}
+	return map[string]any{"bool": map[string]any{"should": should, "minimum_should_match": 1}}
+}
+
+func (q Query) sortClause() []any {
+	byID := map[string]any{"id": "asc"}
+	switch q.Sort {
+	case SortNewest:
+		return []any{map[string]any{"published_at": map[string]any{"order": "desc", "missing": "_last"}}, byID}
+	case SortRating:
+		return []any{map[string]any{"average_rating": map[string]any{"order": "desc", "missing": "_last"}}, "_score", byID}
+	case SortTitle:
+		return []any{map[string]any{"title.sort": "asc"}, byID}
+	}
+	return []any{"_score", map[string]any{"published_at": map[string]any{"order": "desc", "missing": "_last"}}, byID}
+}
+
+// Term names a topic or level in the results.
+type Term struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	// Code is the code of a level, such as B1
+	Code string `json:"code,omitempty"`
+	// Slug is the slug of a topic
+	Slug string `json:"slug,omitempty"`
+}
+
+// Item is a result of a search.
+type Item struct {
+	ID            string              `json:"id"`
+	Type          string              `json:"type"`
+	Title         string              `json:"title"`
+	Description   string              `json:"description,omitempty"`
+	Code          string              `json:"code,omitempty"`
+	Topic         *Term               `json:"topic,omitempty"`
+	Level         *Term               `json:"level,omitempty"`
+	InstructorID  string              `json:"instructor_id,omitempty"`
+	ThumbnailURL  string              `json:"thumbnail_url,omitempty"`
+	IsFeatured    bool                `json:"is_featured,omitempty"`
+	Price         float64             `json:"price,omitempty"`
+	DurationHours int                 `json:"duration_hours,omitempty"`
+	AverageRating float64             `json:"average_rating,omitempty"`
+	ReviewCount   int                 `json:"review_count,omitempty"`
+	PublishedAt   *time.Time          `json:"published_at,omitempty"`
+	Score         float64             `json:"score,omitempty"`
+	Highlights    map[string][]string `json:"highlights,omitempty"`
+}
+
+// FacetValue is a value of a facet and the number of results with it.
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+	// Term names the topic or level of the value
+	Term *Term `json:"term,omitempty"`
+}
+
+// Facets count the results by type, topic and level.
+type Facets struct {
+	Type  []FacetValue `json:"type"`
+	Topic []FacetValue `json:"topic"`
+	Level []FacetValue `json:"level"`
+}
+
+// Result is a page of results.
+type Result struct {
+	Items  []Item `json:"items"`
+	Facets Facets `json:"facets"`
+	// CorrectedQuery is the text with its misspellings corrected, for a "did you mean"
+	CorrectedQuery string `json:"corrected_query,omitempty"`
+	// Synonyms are the terms the text was expanded with
+	Synonyms   []string `json:"synonyms,omitempty"`
+	Total      int64    `json:"total"`
+	Page       int      `json:"page"`
+	PageSize   int      `json:"page_size"`
+	TotalPages int64    `json:"total_pages"`
+}
+
+type searchResponse struct {
+	Hits struct {
+		Total struct {
+			Value int64 `json:"value"`
+		} `json:"total"`
+		Hits []struct {
+			Score     *float64            `json:"_score"`
+			Source    index.Document      `json:"_source"`
+			Highlight map[string][]string `json:"highlight"`
+		} `json:"hits"`
+	} `json:"hits"`
+	Aggregations map[string]struct {
+		Values struct {
+			Buckets []bucket `json:"buckets"`
+		} `json:"values"`
+	} `json:"aggregations"`
+}
+
+type bucket struct {
+	Key      string `json:"key"`
+	DocCount int64  `json:"doc_count"`
+}
+
+type mgetResponse struct {
+	Docs []struct {
+		Found  bool            `json:"found"`
+		Source json.RawMessage `json:"_source"`
+	} `json:"docs"`
+}
+
+// Searcher runs searches on the content indexes.
+type Searcher struct {
+	client    *index.Client
+	names     index.Names
+	fuzziness Fuzziness
+	synonyms  *synonyms.Store
+}
+
+func NewSearcher(client *index.Client, names index.Names, fuzziness Fuzziness, store *synonyms.Store) *Searcher {
+	return &Searcher{client: client, names: names, fuzziness: fuzziness, synonyms: store}
+}
+
+// Search runs q and names the topics and levels of the results and facets.
+func (s *Searcher) Search(ctx context.Context, q Query) (*Result, error) {
+	var rw synonyms.Rewrite
+	if q.Text != "" && !q.Exact {
+		rw = s.synonyms.Dictionary().Rewrite(q.Text)
+	}
+	var resp searchResponse
+	if err := s.client.Search(ctx, s.names.AllContent(), q.Body(rw, s.fuzziness), &resp); err != nil {
+		return nil, apperr.Wrap(err, apperr.Unavailable, "search is unavailable")
+	}
+
+	result := &Result{
+		Items:          make([]Item, 0, len(resp.Hits.Hits)),
+		CorrectedQuery: rw.Corrected,
+		Synonyms:       rw.Synonyms,
+		Total:          resp.Hits.Total.Value,
+		Page:           q.Page,
+		PageSize:       q.PageSize,
+	}
+	result.TotalPages = (result.Total + int64(q.PageSize) - 1) / int64(q.PageSize)
+	for _, hit := range resp.Hits.Hits {
+		doc := hit.Source
+		item := Item{
+			ID:            doc.ID,
+			Type:          doc.Type,
+			Title:         doc.Title,
+			Description:   doc.Description,
+			Code:          doc.Code,
+			InstructorID:  doc.InstructorID,
+			ThumbnailURL:  doc.ThumbnailURL,
+			IsFeatured:    doc.IsFeatured,
+			Price:         doc.Price,
+			DurationHours: doc.DurationHours,
+			AverageRating: doc.AverageRating,
+			ReviewCount:   doc.ReviewCount,
+			PublishedAt:   doc.PublishedAt,
+			Highlights:    hit.Highlight,
+		}
+		if hit.Score != nil {
+			item.Score = *hit.Score
+		}
+		if doc.TopicID != "" {
+			item.Topic = &Term{ID: doc.TopicID}
+		}
+		if doc.LevelID != "" {
+			item.Level = &Term{ID: doc.LevelID}
+		}
+		result.Items = append(result.Items, item)
+	}
+	result.Facets = Facets{
+		Type:  facet(resp.Aggregations["type"].Values.Buckets),
+		Topic: facet(resp.Aggregations["topic_id"].Values.Buckets),
+		Level: facet(resp.Aggregations["level_id"].Values.Buckets),
+	}
+
+	s.nameTerms(ctx, result)
+	return result, nil
+}
+
+func facet(buckets []bucket) []FacetValue {
+	values := make([]FacetValue, 0, len(buckets))
+	for _, bucket := range buckets {
+		values = append(values, FacetValue{Value: bucket.Key, Count: bucket.DocCount})
+	}
+	return values
+}
+
+// nameTerms fills in the names of the topics and levels of result from the taxonomy
+// index. The search is still useful without them, so a failure leaves the IDs alone.
+func (s *Searcher) nameTerms(ctx context.Context, result *Result) {
+	var ids []string
+	seen := map[string]bool{}
+	add := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, item := range result.Items {
+		if item.Topic != nil {
+			add(item.Topic.ID)
+		}
+		if item.Level != nil {
+			add(item.Level.ID)
+		}
+	}
+	for _, value := range append(result.Facets.Topic, result.Facets.Level...) {
+		add(value.Value)
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	var resp mgetResponse
+	if err := s.client.MultiGet(ctx, s.names.Taxonomy(), ids, &resp); err != nil {
+		slog.WarnContext(ctx, "failed to name the topics and levels of a search", "error", err)
+		return
+	}
+	terms := make(map[string]Term, len(resp.Docs))
+	for _, doc := range resp.Docs {
+		var term index.Term
+		if !doc.Found || json.Unmarshal(doc.Source, &term) != nil || term.Deleted {
+			continue
+		}
+		terms[term.ID] = Term{ID: term.ID, Name: term.Name, Code: term.Code, Slug: term.Slug}
+	}
+
+	for i := range result.Items {
+		if topic := result.Items[i].Topic; topic != nil {
+			if term, ok := terms[topic.ID]; ok {
+				*topic = term
+			}
+		}
+		if level := result.Items[i].Level; level != nil {
+			if term, ok := terms[level.ID]; ok {
+				*level = term
+			}
+		}
+	}
+	for _, values := range [][]FacetValue{result.Facets.Topic, result.Facets.Level} {
+		for i := range values {
+			if term, ok := terms[values[i].Value]; ok {
+				values[i].Term = &term
+			}
+		}
+	}
+}
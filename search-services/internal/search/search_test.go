@@ -0,0 +1,281 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"search-services/internal/index"
+	"search-services/internal/synonyms"
+
+	"github.com/ductan2/microservice-app/shared/apperr"
+)
+
+var fuzzy = Fuzziness{Edits: "AUTO", PrefixLength: 1, MaxExpansions: 50}
+
+// at is the value of body at path, through the JSON of body: object keys, and array
+// indexes as numbers. It fails the test when path does not exist.
+func at(t *testing.T, body any, path ...string) any {
+	t.Helper()
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	for i, key := range path {
+		switch node := v.(type) {
+		case map[string]any:
+			value, ok := node[key]
+			if !ok {
+				t.Fatalf("%v has no %q", path[:i], key)
+			}
+			v = value
+		case []any:
+			n, err := strconv.Atoi(key)
+			if err != nil || n >= len(node) {
+				t.Fatalf("%v has no element %s", path[:i], key)
+			}
+			v = node[n]
+		default:
+			t.Fatalf("%v is not an object or array", path[:i])
+		}
+	}
+	return v
+}
+
+// asJSON is v as the JSON decoder reads it, to compare with the values of at.
+func asJSON(t *testing.T, v any) any {
+	t.Helper()
+	return at(t, v)
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  string
+		want    Query
+		invalid bool
+	}{
+		{
+			name:   "text sorts by relevance",
+			params: "q=+present+perfect+",
+			want:   Query{Text: "present perfect", Sort: SortRelevance, Page: 1, PageSize: 20},
+		},
+		{
+			name:   "no text sorts by newest",
+			params: "",
+			want:   Query{Sort: SortNewest, Page: 1, PageSize: 20},
+		},
+		{
+			name:   "filters repeated or comma-separated",
+			params: "type=course,lesson&topic_id=t1&topic_id=t2&level_id=b1&featured=true&fuzzy=false&sort=rating&page=3&page_size=10",
+			want: Query{
+				Types: []string{"course", "lesson"}, TopicIDs: []string{"t1", "t2"}, LevelIDs: []string{"b1"},
+				Featured: true, Exact: true, Sort: SortRating, Page: 3, PageSize: 10,
+			},
+		},
+		{name: "unknown type", params: "type=video", invalid: true},
+		{name: "unknown sort", params: "sort=price", invalid: true},
+		{name: "page size above the maximum", params: "page_size=51", invalid: true},
+		{name: "page past the window", params: "page=201&page_size=50", invalid: true},
+		{name: "fuzzy not a boolean", params: "fuzzy=maybe", invalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := url.ParseQuery(tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ParseQuery(params, 50)
+			if tt.invalid {
+				if apperr.CodeOf(err) != apperr.ValidationFailed {
+					t.Fatalf("got %v, want a validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseQuery: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryBodyPagesAndFilters(t *testing.T) {
+	q := Query{Text: "tense", Featured: true, Sort: SortRelevance, Page: 3, PageSize: 10}
+	body := q.Body(synonyms.Rewrite{}, fuzzy)
+
+	if from, size := at(t, body, "from"), at(t, body, "size"); from != 20.0 || size != 10.0 {
+		t.Errorf("page 3 of 10 sent from %v size %v, want from 20 size 10", from, size)
+	}
+	want := asJSON(t, []any{
+		map[string]any{"term": map[string]any{"visible": true}},
+		map[string]any{"term": map[string]any{"is_featured": true}},
+	})
+	if got := at(t, body, "query", "bool", "filter"); !reflect.DeepEqual(got, want) {
+		t.Errorf("filter is %v, want visible and featured content only %v", got, want)
+	}
+}
+
+// TestQueryBodyFacets checks that the facet filters only narrow the hits, and each facet
+// counts the results of the filters of the other facets.
+func TestQueryBodyFacets(t *testing.T) {
+	q := Query{Types: []string{"course"}, LevelIDs: []string{"b1"}, Sort: SortNewest, Page: 1, PageSize: 20}
+	body := q.Body(synonyms.Rewrite{}, fuzzy)
+
+	typeFilter := map[string]any{"terms": map[string]any{"type": []string{"course"}}}
+	levelFilter := map[string]any{"terms": map[string]any{"level_id": []string{"b1"}}}
+	if got := at(t, body, "post_filter", "bool", "filter"); len(got.([]any)) != 2 {
+		t.Errorf("post filter is %v, want the type and level filters", got)
+	}
+	tests := []struct {
+		facet string
+		want  []any
+	}{
+		{facet: "type", want: []any{levelFilter}},
+		{facet: "level_id", want: []any{typeFilter}},
+	}
+	for _, tt := range tests {
+		got := at(t, body, "aggs", tt.facet, "filter", "bool", "filter")
+		if want := asJSON(t, tt.want); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s facet counts with %v, want %v", tt.facet, got, want)
+		}
+	}
+	if got := at(t, body, "aggs", "topic_id", "filter", "bool", "filter"); len(got.([]any)) != 2 {
+		t.Errorf("topic facet counts with %v, want the type and level filters", got)
+	}
+	if got := at(t, body, "query", "bool", "must", "0"); !reflect.DeepEqual(got, asJSON(t, map[string]any{"match_all": map[string]any{}})) {
+		t.Errorf("query without text is %v, want match_all", got)
+	}
+}
+
+func TestQueryBodyText(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     Query
+		fuzz      Fuzziness
+		rewrite   synonyms.Rewrite
+		wantTexts []string
+		// wantFuzzy lists whether each clause tolerates typos
+		wantFuzzy []bool
+	}{
+		{
+			name:      "text as typed, with typos",
+			query:     Query{Text: "tense"},
+			fuzz:      fuzzy,
+			wantTexts: []string{"tense"},
+			wantFuzzy: []bool{true},
+		},
+		{
+			name:      "exact search",
+			query:     Query{Text: "tense", Exact: true},
+			fuzz:      fuzzy,
+			wantTexts: []string{"tense"},
+			wantFuzzy: []bool{false},
+		},
+		{
+			name:      "typo tolerance off",
+			query:     Query{Text: "tense"},
+			fuzz:      Fuzziness{Edits: "0"},
+			wantTexts: []string{"tense"},
+			wantFuzzy: []bool{false},
+		},
+		{
+			name:      "corrected and expanded",
+			query:     Query{Text: "past tence"},
+			fuzz:      fuzzy,
+			rewrite:   synonyms.Rewrite{Corrected: "past tense", Synonyms: []string{"tenses"}},
+			wantTexts: []string{"past tence", "past tense", "tenses"},
+			wantFuzzy: []bool{true, true, false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.query.Sort, tt.query.Page, tt.query.PageSize = SortRelevance, 1, 20
+			body := tt.query.Body(tt.rewrite, tt.fuzz)
+
+			should := at(t, body, "query", "bool", "must", "0", "bool", "should").([]any)
+			if len(should) != len(tt.wantTexts) {
+				t.Fatalf("text matched with %d clauses, want %d: %v", len(should), len(tt.wantTexts), should)
+			}
+			for i, clause := range should {
+				match := clause.(map[string]any)["multi_match"].(map[string]any)
+				if match["query"] != tt.wantTexts[i] {
+					t.Errorf("clause %d matches %v, want %q", i, match["query"], tt.wantTexts[i])
+				}
+				if _, fuzzy := match["fuzziness"]; fuzzy != tt.wantFuzzy[i] {
+					t.Errorf("clause %d fuzzy %v, want %v", i, fuzzy, tt.wantFuzzy[i])
+				}
+			}
+			// Synonyms rank below the words the learner typed
+			if len(tt.rewrite.Synonyms) > 0 {
+				if boost := should[len(should)-1].(map[string]any)["multi_match"].(map[string]any)["boost"]; boost != 0.5 {
+					t.Errorf("synonyms boosted %v, want 0.5", boost)
+				}
+			}
+		})
+	}
+}
+
+// TestSearchRewritesText checks that a search is matched with the text as typed, its
+// correction and its synonyms from the dictionary, unless it is exact.
+func TestSearchRewritesText(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         Query
+		wantTexts     []string
+		wantCorrected string
+		wantSynonyms  []string
+	}{
+		{
+			name:          "misspellings corrected",
+			query:         Query{Text: "gramer excercises"},
+			wantTexts:     []string{"gramer excercises", "grammar exercises", "practices"},
+			wantCorrected: "grammar exercises",
+			wantSynonyms:  []string{"practices"},
+		},
+		{
+			name:         "synonyms expanded",
+			query:        Query{Text: "vocab"},
+			wantTexts:    []string{"vocab", "vocabulary words"},
+			wantSynonyms: []string{"vocabulary", "words"},
+		},
+		{
+			name:      "exact search as typed",
+			query:     Query{Text: "gramer excercises", Exact: true},
+			wantTexts: []string{"gramer excercises"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, sent := fakeCluster(t, `{"hits":{"total":{"value":0},"hits":[]}}`)
+			s := NewSearcher(client, index.NewNames("english-app-"), fuzzy, synonyms.NewStore(client, "english-app-synonyms", time.Minute))
+
+			tt.query.Sort, tt.query.Page, tt.query.PageSize = SortRelevance, 1, 20
+			result, err := s.Search(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+
+			var texts []string
+			for _, clause := range at(t, *sent, "query", "bool", "must", "0", "bool", "should").([]any) {
+				texts = append(texts, clause.(map[string]any)["multi_match"].(map[string]any)["query"].(string))
+			}
+			if !reflect.DeepEqual(texts, tt.wantTexts) {
+				t.Errorf("matched %q, want %q", texts, tt.wantTexts)
+			}
+			if result.CorrectedQuery != tt.wantCorrected || !reflect.DeepEqual(result.Synonyms, tt.wantSynonyms) {
+				t.Errorf("result corrected to %q with synonyms %q, want %q and %q", result.CorrectedQuery, result.Synonyms, tt.wantCorrected, tt.wantSynonyms)
+			}
+		})
+	}
+}
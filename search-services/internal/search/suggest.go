@@ -0,0 +1,179 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"search-services/internal/index"
+
+	"github.com/ductan2/microservice-app/shared/apperr"
+)
+
+// The bounds of a suggestion request: a prefix is completed from its first character,
+// and the search box lists a handful of completions.
+const (
+	maxPrefixLength    = 100
+	defaultSuggestSize = 5
+	maxSuggestSize     = 10
+)
+
+// SuggestQuery is a request for the completions of what a learner is typing.
+type SuggestQuery struct {
+	Prefix string
+	Types  []string
+	// Locale picks the suggester, the analyzer of the prefix
+	Locale string
+	Size   int
+	// Exact turns off typo tolerance
+	Exact bool
+}
+
+// ParseSuggestQuery reads a query from the parameters of GET /api/v1/suggest: q, type,
+// repeated or comma-separated, locale, size and fuzzy. Without locale, the language of
+// acceptLanguage is used when it has a suggester, and English otherwise.
+func ParseSuggestQuery(params url.Values, acceptLanguage string) (SuggestQuery, error) {
+	q := SuggestQuery{
+		Prefix: strings.TrimLeft(params.Get("q"), " \t"),
+		Types:  list(params["type"]),
+		Locale: params.Get("locale"),
+		Size:   defaultSuggestSize,
+	}
+	var problems []string
+	if strings.TrimSpace(q.Prefix) == "" {
+		problems = append(problems, "q is required")
+	} else if len([]rune(q.Prefix)) > maxPrefixLength {
+		problems = append(problems, fmt.Sprintf("q must be at most %d characters", maxPrefixLength))
+	}
+	for _, docType := range q.Types {
+		if !slices.Contains(index.SuggestionTypes, docType) {
+			problems = append(problems, "type must be one of "+strings.Join(index.SuggestionTypes, ", "))
+			break
+		}
+	}
+	if q.Locale == "" {
+		q.Locale = localeOf(acceptLanguage)
+	} else if !slices.Contains(index.Locales, q.Locale) {
+		problems = append(problems, "locale must be one of "+strings.Join(index.Locales, ", "))
+	}
+	if value := params.Get("size"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 1 || size > maxSuggestSize {
+			problems = append(problems, fmt.Sprintf("size must be between 1 and %d", maxSuggestSize))
+		}
+		q.Size = size
+	}
+	if value := params.Get("fuzzy"); value != "" {
+		fuzzy, err := strconv.ParseBool(value)
+		if err != nil {
+			problems = append(problems, "fuzzy must be true or false")
+		}
+		q.Exact = !fuzzy
+	}
+	if len(problems) > 0 {
+		return SuggestQuery{}, apperr.New(apperr.ValidationFailed, "invalid suggestion parameters").WithDetails(problems)
+	}
+	return q, nil
+}
+
+// localeOf is the locale with a suggester of the first language of an Accept-Language
+// header, such as vi for "vi-VN,vi;q=0.9", or English.
+func localeOf(acceptLanguage string) string {
+	tag, _, _ := strings.Cut(acceptLanguage, ",")
+	tag, _, _ = strings.Cut(tag, ";")
+	language, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+	if slices.Contains(index.Locales, language) {
+		return language
+	}
+	return index.LocaleEN
+}
+
+// minFuzzyPrefix is the shortest prefix completed with typos: shorter ones would match
+// most of the dictionary.
+const minFuzzyPrefix = 3
+
+// Body is the OpenSearch request of the query: a completion suggester, which answers from
+// memory without scoring documents, ranked by the weights of the suggestions. With fuzz,
+// a prefix with a typo is completed too, below those typed right.
+func (q SuggestQuery) Body(fuzz Fuzziness) map[string]any {
+	completion := map[string]any{
+		"field": index.SuggestField(q.Locale),
+		"size":  q.Size,
+	}
+	if fuzz.On() && !q.Exact {
+		completion["fuzzy"] = map[string]any{
+			"fuzziness":     fuzz.Edits,
+			"prefix_length": fuzz.PrefixLength,
+			"min_length":    minFuzzyPrefix,
+		}
+	}
+	if len(q.Types) > 0 {
+		completion["contexts"] = map[string]any{"type": q.Types}
+	}
+	return map[string]any{
+		"_source": []string{"id", "type", "text", "meaning", "set_id"},
+		"suggest": map[string]any{
+			"completions": map[string]any{
+				"prefix":     q.Prefix,
+				"completion": completion,
+			},
+		},
+	}
+}
+
+// Suggestion is a completion of the search box.
+type Suggestion struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	// Text is the title of a course or lesson, or the glossary term
+	Text string `json:"text"`
+	// Meaning is the meaning of a glossary term
+	Meaning string `json:"meaning,omitempty"`
+	// SetID is the flashcard set of a glossary term
+	SetID string `json:"set_id,omitempty"`
+}
+
+// Suggestions are the completions of a prefix, the best first.
+type Suggestions struct {
+	Items  []Suggestion `json:"items"`
+	Locale string       `json:"locale"`
+}
+
+type suggestResponse struct {
+	Suggest map[string][]struct {
+		Options []struct {
+			Source index.Suggestion `json:"_source"`
+		} `json:"options"`
+	} `json:"suggest"`
+}
+
+// Suggest completes the prefix of q. The search box calls it on every keystroke, so it
+// gives up after timeout rather than answer late.
+func (s *Searcher) Suggest(ctx context.Context, q SuggestQuery, timeout time.Duration) (*Suggestions, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var resp suggestResponse
+	if err := s.client.Search(ctx, []string{s.names.Suggestions()}, q.Body(s.fuzziness), &resp); err != nil {
+		return nil, apperr.Wrap(err, apperr.Unavailable, "suggestions are unavailable")
+	}
+
+	result := &Suggestions{Items: []Suggestion{}, Locale: q.Locale}
+	for _, entry := range resp.Suggest["completions"] {
+		for _, option := range entry.Options {
+			doc := option.Source
+			result.Items = append(result.Items, Suggestion{
+				ID:      doc.ID,
+				Type:    doc.Type,
+				Text:    doc.Text,
+				Meaning: doc.Meaning,
+				SetID:   doc.SetID,
+			})
+		}
+	}
+	return result, nil
+}
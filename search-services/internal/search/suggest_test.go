@@ -0,0 +1,183 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"search-services/internal/index"
+
+	"github.com/ductan2/microservice-app/shared/apperr"
+)
+
+// fakeCluster answers every request with response and keeps the body of the last one.
+func fakeCluster(t *testing.T, response string) (*index.Client, *map[string]any) {
+	t.Helper()
+	var sent map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&sent); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(srv.Close)
+	client, err := index.NewClient(srv.URL, "", "", 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, &sent
+}
+
+func TestParseSuggestQuery(t *testing.T) {
+	tests := []struct {
+		name           string
+		params         string
+		acceptLanguage string
+		want           SuggestQuery
+		invalid        bool
+	}{
+		{
+			name:   "defaults",
+			params: "q=pres",
+			want:   SuggestQuery{Prefix: "pres", Locale: index.LocaleEN, Size: defaultSuggestSize},
+		},
+		{
+			name:           "locale of the browser",
+			params:         "q=hoan",
+			acceptLanguage: "vi-VN,vi;q=0.9,en;q=0.8",
+			want:           SuggestQuery{Prefix: "hoan", Locale: index.LocaleVI, Size: defaultSuggestSize},
+		},
+		{
+			name:           "browser language without a suggester",
+			params:         "q=gram",
+			acceptLanguage: "fr-FR",
+			want:           SuggestQuery{Prefix: "gram", Locale: index.LocaleEN, Size: defaultSuggestSize},
+		},
+		{
+			name:           "locale parameter over the browser",
+			params:         "q=gram&locale=en&type=course,glossary&size=8&fuzzy=false",
+			acceptLanguage: "vi",
+			want:           SuggestQuery{Prefix: "gram", Types: []string{"course", "glossary"}, Locale: index.LocaleEN, Size: 8, Exact: true},
+		},
+		{
+			name:   "trailing space kept",
+			params: "q=+present+",
+			want:   SuggestQuery{Prefix: "present ", Locale: index.LocaleEN, Size: defaultSuggestSize},
+		},
+		{name: "blank prefix", params: "q=+++", invalid: true},
+		{name: "unknown type", params: "q=gram&type=flashcard_set", invalid: true},
+		{name: "unknown locale", params: "q=gram&locale=fr", invalid: true},
+		{name: "size above the maximum", params: "q=gram&size=11", invalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := url.ParseQuery(tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ParseSuggestQuery(params, tt.acceptLanguage)
+			if tt.invalid {
+				if apperr.CodeOf(err) != apperr.ValidationFailed {
+					t.Fatalf("got %v, want a validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSuggestQuery: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggestQueryBody(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        SuggestQuery
+		fuzz         Fuzziness
+		wantField    string
+		wantFuzzy    any
+		wantContexts any
+	}{
+		{
+			name:      "typos tolerated past the first characters",
+			query:     SuggestQuery{Prefix: "gramer", Locale: index.LocaleEN, Size: 5},
+			fuzz:      fuzzy,
+			wantField: "suggest_en",
+			wantFuzzy: map[string]any{"fuzziness": "AUTO", "prefix_length": 1.0, "min_length": 3.0},
+		},
+		{
+			name:      "exact",
+			query:     SuggestQuery{Prefix: "gramer", Locale: index.LocaleEN, Size: 5, Exact: true},
+			fuzz:      fuzzy,
+			wantField: "suggest_en",
+		},
+		{
+			name:      "typo tolerance off",
+			query:     SuggestQuery{Prefix: "gramer", Locale: index.LocaleEN, Size: 5},
+			fuzz:      Fuzziness{Edits: "0"},
+			wantField: "suggest_en",
+		},
+		{
+			name:         "types and locale",
+			query:        SuggestQuery{Prefix: "hoan", Types: []string{"course", "lesson"}, Locale: index.LocaleVI, Size: 5},
+			fuzz:         fuzzy,
+			wantField:    "suggest_vi",
+			wantFuzzy:    map[string]any{"fuzziness": "AUTO", "prefix_length": 1.0, "min_length": 3.0},
+			wantContexts: map[string]any{"type": []any{"course", "lesson"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := tt.query.Body(tt.fuzz)
+
+			if prefix := at(t, body, "suggest", "completions", "prefix"); prefix != tt.query.Prefix {
+				t.Errorf("completes %v, want %q", prefix, tt.query.Prefix)
+			}
+			completion := at(t, body, "suggest", "completions", "completion").(map[string]any)
+			if completion["field"] != tt.wantField {
+				t.Errorf("suggester field %v, want %s", completion["field"], tt.wantField)
+			}
+			if !reflect.DeepEqual(completion["fuzzy"], tt.wantFuzzy) {
+				t.Errorf("fuzzy %v, want %v", completion["fuzzy"], tt.wantFuzzy)
+			}
+			if !reflect.DeepEqual(completion["contexts"], tt.wantContexts) {
+				t.Errorf("contexts %v, want %v", completion["contexts"], tt.wantContexts)
+			}
+		})
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	client, sent := fakeCluster(t, `{"suggest":{"completions":[{"text":"gramer","options":[
+		{"text":"Grammar basics","_source":{"id":"c1","type":"course","text":"Grammar basics"}},
+		{"text":"grammar","_source":{"id":"f1","type":"glossary","text":"grammar","meaning":"ngữ pháp","set_id":"s1"}}
+	]}]}}`)
+	s := NewSearcher(client, index.NewNames("english-app-"), fuzzy, nil)
+
+	got, err := s.Suggest(context.Background(), SuggestQuery{Prefix: "gramer", Locale: index.LocaleEN, Size: 5}, time.Second)
+	if err != nil {
+		t.Fatalf("Suggest: %v", err)
+	}
+
+	if at(t, *sent, "suggest", "completions", "completion", "fuzzy") == nil {
+		t.Error("suggestion request does not tolerate typos")
+	}
+	want := &Suggestions{
+		Locale: index.LocaleEN,
+		Items: []Suggestion{
+			{ID: "c1", Type: "course", Text: "Grammar basics"},
+			{ID: "f1", Type: "glossary", Text: "grammar", Meaning: "ngữ pháp", SetID: "s1"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
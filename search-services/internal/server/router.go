@@ -0,0 +1,143 @@
+// Package server serves the search API to the BFF.
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"search-services/internal/search"
+	"search-services/internal/synonyms"
+
+	"github.com/ductan2/microservice-app/shared/apperr"
+	"github.com/ductan2/microservice-app/shared/health"
+	"github.com/ductan2/microservice-app/shared/internalauth"
+	"github.com/ductan2/microservice-app/shared/logging"
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// NewRouter returns the routes of the service. The API answers {"data": ...} or
+// {"error": {...}}, and only requests with a service token: learners reach it through
+// the BFF.
+func NewRouter(searcher *search.Searcher, dictionary *synonyms.Store, maxPageSize int, suggestTimeout time.Duration, verifier *internalauth.Verifier, checker *health.Checker) *gin.Engine {
+	r := gin.New()
+	r.Use(requestLog())
+	r.Use(tracing())
+	r.Use(observe())
+	r.Use(gin.Recovery())
+
+	r.GET(health.LivePath, gin.WrapH(checker.Live()))
+	r.GET(health.ReadyPath, gin.WrapH(checker.Ready()))
+	r.GET(health.HealthPath, gin.WrapH(checker.Health()))
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	api := r.Group("/api/v1")
+	api.Use(serviceAuth(verifier))
+	{
+		api.GET("/search", searchContent(searcher, maxPageSize))      // GET /api/v1/search?q=&type=&topic_id=&level_id=
+		api.GET("/suggest", suggestContent(searcher, suggestTimeout)) // GET /api/v1/suggest?q=&type=&locale=&size=
+
+		// The BFF only calls these for admins
+		admin := api.Group("/admin/synonyms")
+		admin.GET("", listSynonyms(dictionary))
+		admin.POST("", createSynonym(dictionary))
+		admin.GET("/:id", getSynonym(dictionary))
+		admin.PUT("/:id", updateSynonym(dictionary))
+		admin.DELETE("/:id", deleteSynonym(dictionary))
+	}
+	return r
+}
+
+// searchContent searches the courses, lessons and flashcard sets learners can see.
+func searchContent(searcher *search.Searcher, maxPageSize int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query, err := search.ParseQuery(c.Request.URL.Query(), maxPageSize)
+		if err != nil {
+			failJSON(c, apperr.From(err))
+			return
+		}
+
+		result, err := searcher.Search(c.Request.Context(), query)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "search failed", "error", err)
+			failJSON(c, apperr.From(err))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": result})
+	}
+}
+
+// suggestContent completes the prefix typed in the search box with course and lesson
+// titles and glossary terms.
+func suggestContent(searcher *search.Searcher, timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query, err := search.ParseSuggestQuery(c.Request.URL.Query(), c.GetHeader("Accept-Language"))
+		if err != nil {
+			failJSON(c, apperr.From(err))
+			return
+		}
+
+		result, err := searcher.Suggest(c.Request.Context(), query, timeout)
+		if err != nil {
+			slog.WarnContext(c.Request.Context(), "suggest failed", "error", err)
+			failJSON(c, apperr.From(err))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": result})
+	}
+}
+
+// serviceAuth refuses the requests without a valid service token.
+func serviceAuth(verifier *internalauth.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := verifier.Authenticate(c.Request)
+		if err != nil {
+			slog.WarnContext(c.Request.Context(), "rejected service token", "error", err, "path", c.Request.URL.Path)
+		}
+		if err != nil || claims == nil {
+			failJSON(c, apperr.New(apperr.Unauthorized, "internal service credentials are required"))
+			return
+		}
+		c.Set("calling_service", claims.Issuer)
+		c.Next()
+	}
+}
+
+func failJSON(c *gin.Context, err *apperr.Error) {
+	c.AbortWithStatusJSON(err.HTTPStatus(), gin.H{"error": err.Public()})
+}
+
+// tracing starts the server span of each request with otelgin, continuing the trace of the caller's
+// traceparent header.
+func tracing() gin.HandlerFunc {
+	return otelgin.Middleware("search-services")
+}
+
+// observe records the count and latency of each request by matched route.
+func observe() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		metrics.ObserveHTTPServer(c.Request.Method, c.FullPath(), c.Writer.Status(), time.Since(start))
+	}
+}
+
+// requestLog writes an access log record for each request once it has been served.
+func requestLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		logging.LogRequest(c.Request.Context(), logging.HTTPRequest{
+			Method:   c.Request.Method,
+			Route:    c.FullPath(),
+			Path:     c.Request.URL.Path,
+			Status:   c.Writer.Status(),
+			Latency:  time.Since(start),
+			ClientIP: c.ClientIP(),
+		})
+	}
+}
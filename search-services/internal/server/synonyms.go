@@ -0,0 +1,88 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+
+	"search-services/internal/synonyms"
+
+	"github.com/ductan2/microservice-app/shared/apperr"
+	"github.com/gin-gonic/gin"
+)
+
+// synonymRequest is the body of a new or changed synonym rule.
+type synonymRequest struct {
+	Terms        []string `json:"terms"`
+	Misspellings []string `json:"misspellings"`
+}
+
+// listSynonyms lists the built-in rules, then those admins added.
+func listSynonyms(store *synonyms.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rules, err := store.List(c.Request.Context())
+		if err != nil {
+			failJSON(c, apperr.From(err))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": gin.H{"items": rules}})
+	}
+}
+
+func getSynonym(store *synonyms.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rule, err := store.Get(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			failJSON(c, apperr.From(err))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"data": rule})
+	}
+}
+
+func createSynonym(store *synonyms.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req synonymRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			failJSON(c, apperr.Wrap(err, apperr.BadRequest, "invalid request body"))
+			return
+		}
+
+		rule, err := store.Create(c.Request.Context(), synonyms.Rule{Terms: req.Terms, Misspellings: req.Misspellings})
+		if err != nil {
+			failJSON(c, apperr.From(err))
+			return
+		}
+		slog.InfoContext(c.Request.Context(), "synonym rule created", "rule_id", rule.ID, "calling_service", c.GetString("calling_service"))
+		c.JSON(http.StatusCreated, gin.H{"data": rule})
+	}
+}
+
+func updateSynonym(store *synonyms.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req synonymRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			failJSON(c, apperr.Wrap(err, apperr.BadRequest, "invalid request body"))
+			return
+		}
+
+		rule, err := store.Update(c.Request.Context(), c.Param("id"), synonyms.Rule{Terms: req.Terms, Misspellings: req.Misspellings})
+		if err != nil {
+			failJSON(c, apperr.From(err))
+			return
+		}
+		slog.InfoContext(c.Request.Context(), "synonym rule updated", "rule_id", rule.ID, "calling_service", c.GetString("calling_service"))
+		c.JSON(http.StatusOK, gin.H{"data": rule})
+	}
+}
+
+func deleteSynonym(store *synonyms.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		if err := store.Delete(c.Request.Context(), id); err != nil {
+			failJSON(c, apperr.From(err))
+			return
+		}
+		slog.InfoContext(c.Request.Context(), "synonym rule deleted", "rule_id", id, "calling_service", c.GetString("calling_service"))
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,199 @@
+package synonyms
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"search-services/internal/index"
+
+	"github.com/ductan2/microservice-app/shared/apperr"
+	"github.com/google/uuid"
+)
+
+// maxRules bounds the rules admins can add, all of them loaded in every replica.
+const maxRules = 1000
+
+// Store keeps the rules admins add in an index and the dictionary of every rule in
+// memory. Each replica reloads the dictionary every interval, and at once after a change
+// it makes itself.
+type Store struct {
+	client   *index.Client
+	index    string
+	interval time.Duration
+	dict     atomic.Pointer[Dictionary]
+}
+
+// NewStore returns a store of the rules of the index named name, with the built-in rules
+// only until it is loaded.
+func NewStore(client *index.Client, name string, interval time.Duration) *Store {
+	s := &Store{client: client, index: name, interval: interval}
+	s.dict.Store(NewDictionary(nil))
+	return s
+}
+
+// Dictionary returns the dictionary searches are rewritten with.
+func (s *Store) Dictionary() *Dictionary {
+	return s.dict.Load()
+}
+
+// Run reloads the dictionary every interval until ctx is cancelled. A failed reload keeps
+// the dictionary loaded before.
+func (s *Store) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Reload(ctx); err != nil && ctx.Err() == nil {
+			slog.WarnContext(ctx, "failed to reload the synonyms", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reload reads the rules of the index and rebuilds the dictionary.
+func (s *Store) Reload(ctx context.Context) error {
+	rules, err := s.stored(ctx)
+	if err != nil {
+		return err
+	}
+	s.dict.Store(NewDictionary(rules))
+	return nil
+}
+
+// List returns the built-in rules followed by those admins added.
+func (s *Store) List(ctx context.Context) ([]Rule, error) {
+	rules, err := s.stored(ctx)
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.Unavailable, "synonyms are unavailable")
+	}
+	return append(Builtin(), rules...), nil
+}
+
+// Get returns the rule id, built-in or not.
+func (s *Store) Get(ctx context.Context, id string) (*Rule, error) {
+	for _, rule := range builtin {
+		if rule.ID == id {
+			return &rule, nil
+		}
+	}
+	var rule Rule
+	err := s.client.Get(ctx, s.index, id, &rule)
+	if errors.Is(err, index.ErrNotFound) {
+		return nil, apperr.New(apperr.NotFound, "synonym rule not found")
+	}
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.Unavailable, "synonyms are unavailable")
+	}
+	return &rule, nil
+}
+
+// Create adds rule with a new ID.
+func (s *Store) Create(ctx context.Context, rule Rule) (*Rule, error) {
+	rules, err := s.stored(ctx)
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.Unavailable, "synonyms are unavailable")
+	}
+	if len(rules) >= maxRules {
+		return nil, apperr.Newf(apperr.Conflict, "the dictionary is limited to %d rules; delete one first", maxRules)
+	}
+	rule.ID = uuid.NewString()
+	return s.put(ctx, rule)
+}
+
+// Update replaces the terms and misspellings of the rule id. Built-in rules cannot be
+// changed.
+func (s *Store) Update(ctx context.Context, id string, rule Rule) (*Rule, error) {
+	if IsBuiltin(id) {
+		return nil, apperr.New(apperr.Conflict, "built-in rules cannot be changed; add a rule overriding its misspellings instead")
+	}
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	rule.ID = id
+	return s.put(ctx, rule)
+}
+
+// Delete removes the rule id. Built-in rules cannot be deleted.
+func (s *Store) Delete(ctx context.Context, id string) error {
+	if IsBuiltin(id) {
+		return apperr.New(apperr.Conflict, "built-in rules cannot be deleted")
+	}
+	err := s.client.Delete(ctx, s.index, id)
+	if errors.Is(err, index.ErrNotFound) {
+		return apperr.New(apperr.NotFound, "synonym rule not found")
+	}
+	if err != nil {
+		return apperr.Wrap(err, apperr.Unavailable, "synonyms are unavailable")
+	}
+	s.changed(ctx)
+	return nil
+}
+
+// put validates and writes rule, versioned with the time of the write so that the last of
+// two concurrent changes wins.
+func (s *Store) put(ctx context.Context, rule Rule) (*Rule, error) {
+	rule.Normalize()
+	if problems := rule.Validate(); len(problems) > 0 {
+		return nil, apperr.New(apperr.ValidationFailed, "invalid synonym rule").WithDetails(problems)
+	}
+	now := time.Now().UTC()
+	rule.Builtin = false
+	rule.UpdatedAt = &now
+
+	err := s.client.Put(ctx, s.index, rule.ID, now.UnixNano(), rule)
+	if errors.Is(err, index.ErrStale) {
+		return nil, apperr.New(apperr.Conflict, "the rule was changed meanwhile; try again")
+	}
+	if errors.Is(err, index.ErrRejected) {
+		return nil, apperr.Wrap(err, apperr.ValidationFailed, "invalid synonym rule")
+	}
+	if err != nil {
+		return nil, apperr.Wrap(err, apperr.Unavailable, "synonyms are unavailable")
+	}
+	s.changed(ctx)
+	return &rule, nil
+}
+
+// changed makes a change visible to the searches of this replica at once; the others
+// pick it up on their next reload.
+func (s *Store) changed(ctx context.Context) {
+	err := s.client.Refresh(ctx, s.index)
+	if err == nil {
+		err = s.Reload(ctx)
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "failed to reload the synonyms after a change", "error", err)
+	}
+}
+
+type rulesResponse struct {
+	Hits struct {
+		Hits []struct {
+			Source Rule `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// stored reads the rules admins added, the oldest first.
+func (s *Store) stored(ctx context.Context) ([]Rule, error) {
+	body := map[string]any{
+		"size":  maxRules,
+		"query": map[string]any{"match_all": map[string]any{}},
+		"sort":  []any{map[string]any{"updated_at": "asc"}, map[string]any{"id": "asc"}},
+	}
+	var resp rulesResponse
+	if err := s.client.Search(ctx, []string{s.index}, body, &resp); err != nil {
+		return nil, err
+	}
+	rules := make([]Rule, 0, len(resp.Hits.Hits))
+	for _, hit := range resp.Hits.Hits {
+		rules = append(rules, hit.Source)
+	}
+	return rules, nil
+}
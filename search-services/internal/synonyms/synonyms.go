@@ -0,0 +1,213 @@
+// Package synonyms keeps the dictionary the searches are expanded with: terms that mean
+// the same to a learner, and the misspellings English learners commonly type for them.
+// Built-in rules cover the common cases; admins add their own through the API.
+package synonyms
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// The bounds of a rule. A term is matched as a phrase of up to maxPhraseWords words.
+const (
+	maxPhraseWords = 3
+	maxTermLength  = 50
+	maxTerms       = 20
+)
+
+// Rule is an entry of the dictionary. Its terms match each other: a search for one also
+// finds the others. Its misspellings are corrected to its first term.
+type Rule struct {
+	ID           string   `json:"id"`
+	Terms        []string `json:"terms"`
+	Misspellings []string `json:"misspellings,omitempty"`
+	// Builtin rules come with the service and cannot be changed; a rule with the same
+	// misspelling overrides them
+	Builtin   bool       `json:"builtin,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// builtin are the rules of every dictionary: the misspellings English learners, and
+// Vietnamese learners in particular, most often type in the search box.
+var builtin = []Rule{
+	{Terms: []string{"grammar"}, Misspellings: []string{"gramer", "grammer", "gramar", "gramma"}},
+	{Terms: []string{"exercise", "practice"}, Misspellings: []string{"excercise", "exercice", "exersice", "excersise"}},
+	{Terms: []string{"exercises", "practices"}, Misspellings: []string{"excercises", "exercices", "exersices", "excersises"}},
+	{Terms: []string{"vocabulary", "vocab", "words"}, Misspellings: []string{"vocabulory", "vocabluary", "vocabularry", "vocabulay", "vocabulari"}},
+	{Terms: []string{"pronunciation", "pronounce"}, Misspellings: []string{"pronounciation", "pronunciaton", "pronuncation", "pronouncation"}},
+	{Terms: []string{"listening"}, Misspellings: []string{"listenning", "lisening", "listning"}},
+	{Terms: []string{"writing"}, Misspellings: []string{"writting", "wrting", "writeing"}},
+	{Terms: []string{"speaking", "conversation"}, Misspellings: []string{"speeking", "speacking"}},
+	{Terms: []string{"conversation"}, Misspellings: []string{"conversasion", "convesation", "conversaton"}},
+	{Terms: []string{"sentence"}, Misspellings: []string{"sentense", "setence", "sentance"}},
+	{Terms: []string{"beginner", "elementary"}, Misspellings: []string{"begginer", "beginer", "begginner"}},
+	{Terms: []string{"intermediate"}, Misspellings: []string{"intermidiate", "intermediat", "intermedite"}},
+	{Terms: []string{"dictionary"}, Misspellings: []string{"dictionnary", "dictionery", "dictonary"}},
+	{Terms: []string{"business"}, Misspellings: []string{"bussiness", "buisness", "busines", "bussines"}},
+	{Terms: []string{"english"}, Misspellings: []string{"englsih", "engish", "englis", "inglish"}},
+	{Terms: []string{"tense", "tenses"}, Misspellings: []string{"tence", "tenze"}},
+	{Terms: []string{"difference"}, Misspellings: []string{"diffrence", "diference", "differance"}},
+	{Terms: []string{"phrasal verb"}, Misspellings: []string{"phrasal verd", "frasal verb"}},
+	{Terms: []string{"idiom", "expression"}, Misspellings: []string{"idom", "ideom"}},
+}
+
+func init() {
+	for i := range builtin {
+		builtin[i].ID = "builtin-" + strings.ReplaceAll(builtin[i].Terms[0], " ", "-")
+		builtin[i].Builtin = true
+	}
+}
+
+// Builtin returns the built-in rules.
+func Builtin() []Rule {
+	return append([]Rule(nil), builtin...)
+}
+
+// IsBuiltin reports whether id is the ID of a built-in rule.
+func IsBuiltin(id string) bool {
+	return strings.HasPrefix(id, "builtin-")
+}
+
+// Normalize lowercases the terms and misspellings of the rule and collapses their spaces,
+// the way queries are matched against them.
+func (r *Rule) Normalize() {
+	for i, term := range r.Terms {
+		r.Terms[i] = normalize(term)
+	}
+	for i, misspelling := range r.Misspellings {
+		r.Misspellings[i] = normalize(misspelling)
+	}
+}
+
+// Validate lists what is wrong with a normalized rule.
+func (r *Rule) Validate() []string {
+	var problems []string
+	switch {
+	case len(r.Terms) == 0:
+		problems = append(problems, "terms must have at least one term")
+	case len(r.Terms) == 1 && len(r.Misspellings) == 0:
+		problems = append(problems, "a rule needs two terms, or a term and its misspellings")
+	}
+	if len(r.Terms) > maxTerms || len(r.Misspellings) > maxTerms {
+		problems = append(problems, fmt.Sprintf("terms and misspellings must have at most %d entries each", maxTerms))
+	}
+	seen := map[string]bool{}
+	for _, phrase := range append(append([]string(nil), r.Terms...), r.Misspellings...) {
+		switch {
+		case phrase == "":
+			problems = append(problems, "terms and misspellings must not be empty")
+		case len(phrase) > maxTermLength || len(strings.Fields(phrase)) > maxPhraseWords:
+			problems = append(problems, fmt.Sprintf("%q must be at most %d characters and %d words", phrase, maxTermLength, maxPhraseWords))
+		case seen[phrase]:
+			problems = append(problems, fmt.Sprintf("%q is listed twice", phrase))
+		}
+		seen[phrase] = true
+	}
+	return problems
+}
+
+// Dictionary is the set of rules a search is rewritten with. It is not changed once built.
+type Dictionary struct {
+	// corrections maps a misspelling to its correction
+	corrections map[string]string
+	// synonyms maps a term to the other terms of its rules
+	synonyms map[string][]string
+}
+
+// NewDictionary builds the dictionary of the built-in rules and rules, which override the
+// built-in corrections of the same misspellings.
+func NewDictionary(rules []Rule) *Dictionary {
+	d := &Dictionary{corrections: map[string]string{}, synonyms: map[string][]string{}}
+	for _, rule := range append(Builtin(), rules...) {
+		for _, misspelling := range rule.Misspellings {
+			d.corrections[misspelling] = rule.Terms[0]
+		}
+		for _, term := range rule.Terms {
+			for _, other := range rule.Terms {
+				if other != term && !slices.Contains(d.synonyms[term], other) {
+					d.synonyms[term] = append(d.synonyms[term], other)
+				}
+			}
+		}
+	}
+	return d
+}
+
+// maxSynonyms bounds the terms a search is expanded with.
+const maxSynonyms = 10
+
+// Rewrite is a search text rewritten with the dictionary.
+type Rewrite struct {
+	// Corrected is the text with its misspellings corrected, empty when it has none
+	Corrected string
+	// Synonyms are the terms matching those of the text, other than the text's own
+	Synonyms []string
+}
+
+// Rewrite corrects the misspellings of text and finds the synonyms of its terms. Phrases
+// are matched before the words they are made of, so "phrasal verd" is corrected as one.
+func (d *Dictionary) Rewrite(text string) Rewrite {
+	words := strings.Fields(normalize(text))
+	corrected := make([]string, 0, len(words))
+	changed := false
+	for i := 0; i < len(words); {
+		phrase, n := d.longest(words[i:], func(phrase string) bool { _, ok := d.corrections[phrase]; return ok })
+		if n == 0 {
+			corrected = append(corrected, words[i])
+			i++
+			continue
+		}
+		corrected = append(corrected, d.corrections[phrase])
+		changed = true
+		i += n
+	}
+
+	var rw Rewrite
+	if changed {
+		rw.Corrected = strings.Join(corrected, " ")
+	}
+	// The terms of the text, the corrected text when it has misspellings
+	terms := strings.Fields(strings.Join(corrected, " "))
+	own := map[string]bool{}
+	for _, term := range terms {
+		own[term] = true
+	}
+	for i := 0; i < len(terms); {
+		phrase, n := d.longest(terms[i:], func(phrase string) bool { return len(d.synonyms[phrase]) > 0 })
+		if n == 0 {
+			i++
+			continue
+		}
+		for _, synonym := range d.synonyms[phrase] {
+			if !own[synonym] && !slices.Contains(rw.Synonyms, synonym) && len(rw.Synonyms) < maxSynonyms {
+				rw.Synonyms = append(rw.Synonyms, synonym)
+			}
+		}
+		i += n
+	}
+	return rw
+}
+
+// longest is the longest phrase at the start of words that has an entry, and its number
+// of words; 0 when even the first word has none.
+func (d *Dictionary) longest(words []string, has func(phrase string) bool) (string, int) {
+	for n := min(maxPhraseWords, len(words)); n > 0; n-- {
+		if phrase := strings.Join(words[:n], " "); has(phrase) {
+			return phrase, n
+		}
+	}
+	return "", 0
+}
+
+// normalize lowercases text, drops the punctuation around its words and collapses its
+// spaces.
+func normalize(text string) string {
+	words := strings.Fields(strings.ToLower(text))
+	for i, word := range words {
+		words[i] = strings.TrimFunc(word, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	}
+	return strings.Join(strings.Fields(strings.Join(words, " ")), " ")
+}
@@ -0,0 +1,103 @@
+package synonyms
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestRewrite(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []Rule
+		text  string
+		want  Rewrite
+	}{
+		{
+			name: "misspellings corrected and expanded",
+			text: "gramer excercises",
+			want: Rewrite{Corrected: "grammar exercises", Synonyms: []string{"practices"}},
+		},
+		{
+			name: "case and punctuation ignored",
+			text: "  Grammer,  EXERCISE! ",
+			want: Rewrite{Corrected: "grammar exercise", Synonyms: []string{"practice"}},
+		},
+		{
+			name: "synonyms of a term typed right",
+			text: "vocab",
+			want: Rewrite{Synonyms: []string{"vocabulary", "words"}},
+		},
+		{
+			name: "terms of the text not repeated as synonyms",
+			text: "speaking conversation",
+			want: Rewrite{Synonyms: nil},
+		},
+		{
+			name: "phrase corrected as one",
+			text: "frasal verb list",
+			want: Rewrite{Corrected: "phrasal verb list"},
+		},
+		{
+			name: "text without entries",
+			text: "present perfect",
+			want: Rewrite{},
+		},
+		{
+			name:  "admin rule expands",
+			rules: []Rule{{Terms: []string{"ielts", "exam preparation"}}},
+			text:  "ielts listening",
+			want:  Rewrite{Synonyms: []string{"exam preparation"}},
+		},
+		{
+			name:  "admin rule overrides a built-in correction",
+			rules: []Rule{{Terms: []string{"grammar book"}, Misspellings: []string{"gramer"}}},
+			text:  "gramer",
+			want:  Rewrite{Corrected: "grammar book"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewDictionary(tt.rules).Rewrite(tt.text)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Rewrite(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRewriteBoundsSynonyms(t *testing.T) {
+	var terms []string
+	for i := range maxTerms {
+		terms = append(terms, fmt.Sprintf("term%d", i))
+	}
+	got := NewDictionary([]Rule{{Terms: terms}}).Rewrite("term0")
+	if len(got.Synonyms) != maxSynonyms {
+		t.Fatalf("expanded with %d synonyms, want %d", len(got.Synonyms), maxSynonyms)
+	}
+}
+
+func TestRuleValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  Rule
+		valid bool
+	}{
+		{name: "two terms", rule: Rule{Terms: []string{"Exam", " test "}}, valid: true},
+		{name: "term and misspelling", rule: Rule{Terms: []string{"grammar"}, Misspellings: []string{"gramer"}}, valid: true},
+		{name: "no terms", rule: Rule{Misspellings: []string{"gramer"}}},
+		{name: "a single term", rule: Rule{Terms: []string{"grammar"}}},
+		{name: "phrase too long", rule: Rule{Terms: []string{"one two three four", "five"}}},
+		{name: "term listed twice", rule: Rule{Terms: []string{"Exam", "exam"}}},
+		{name: "empty misspelling", rule: Rule{Terms: []string{"exam"}, Misspellings: []string{"!!"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.Normalize()
+			problems := tt.rule.Validate()
+			if valid := len(problems) == 0; valid != tt.valid {
+				t.Fatalf("problems %q, want valid %v", problems, tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Package apperr is the error model shared by the Go services.
+//
+// An Error has a canonical Code, which sets its HTTP and gRPC status, and optionally a
+// Reason, the service specific code clients switch on (USER_NOT_FOUND, COUPON_EXPIRED):
+//
+//	var ErrOrderNotFound = apperr.New(apperr.NotFound, "order not found").WithReason("ORDER_NOT_FOUND")
+//
+//	return apperr.Wrap(err, apperr.Upstream, "course service unavailable")
+//
+// The With methods return a copy, so predefined errors can be refined per call, and
+// errors.Is matches a copy against the predefined error by its code and reason. From
+// turns any error into an *Error, hiding unexpected ones behind Internal; Body is the
+// error object of a JSON response.
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Error is an error reported to clients.
+type Error struct {
+	// Code is the canonical code of the error
+	Code Code
+	// Reason is the service specific code, "" when Code says it all
+	Reason string
+	// Message is shown to the client
+	Message string
+	// Status overrides the HTTP status of Code when not 0
+	Status int
+	// Details is extra data for the client, such as the fields that failed validation
+	Details any
+	// Cause is the underlying error, logged but never shown to the client
+	Cause error
+}
+
+// New returns an error with the code and message.
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Newf returns an error with the code and formatted message.
+func Newf(code Code, format string, args ...any) *Error {
+	return New(code, fmt.Sprintf(format, args...))
+}
+
+// Wrap returns an error with the code and message, caused by err.
+func Wrap(err error, code Code, message string) *Error {
+	return &Error{Code: code, Message: message, Cause: err}
+}
+
+// Wrapf returns an error with the code and formatted message, caused by err.
+func Wrapf(err error, code Code, format string, args ...any) *Error {
+	return Wrap(err, code, fmt.Sprintf(format, args...))
+}
+
+// Error returns the message, followed by the cause when there is one.
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return e.Message + ": " + e.Cause.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns the cause.
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is reports whether target is an *Error with the same code and reason, so that a copy
+// made by a With method still matches the error it was made from.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Reason != "" && t.Code == e.Code && t.Reason == e.Reason
+}
+
+// WithReason returns a copy of e with the service specific code.
+func (e *Error) WithReason(reason string) *Error {
+	c := *e
+	c.Reason = reason
+	return &c
+}
+
+// WithMessage returns a copy of e with the message.
+func (e *Error) WithMessage(message string) *Error {
+	c := *e
+	c.Message = message
+	return &c
+}
+
+// WithStatus returns a copy of e with the HTTP status.
+func (e *Error) WithStatus(status int) *Error {
+	c := *e
+	c.Status = status
+	return &c
+}
+
+// WithDetails returns a copy of e with the details.
+func (e *Error) WithDetails(details any) *Error {
+	c := *e
+	c.Details = details
+	return &c
+}
+
+// WithCause returns a copy of e caused by err.
+func (e *Error) WithCause(err error) *Error {
+	c := *e
+	c.Cause = err
+	return &c
+}
+
+// ErrorCode is the code reported to clients: the reason, or the canonical code.
+func (e *Error) ErrorCode() string {
+	if e.Reason != "" {
+		return e.Reason
+	}
+	return string(e.Code)
+}
+
+// HTTPStatus is the HTTP status of the error.
+func (e *Error) HTTPStatus() int {
+	if e.Status != 0 {
+		return e.Status
+	}
+	return e.Code.HTTPStatus()
+}
+
+// GRPCCode is the gRPC status code of the error.
+func (e *Error) GRPCCode() GRPCCode {
+	return e.Code.GRPCCode()
+}
+
+// Internal reports whether the error is a failure of the service rather than of the
+// request, one that is logged and whose message and details are not shown to clients.
+func (e *Error) Internal() bool {
+	return e.HTTPStatus() >= http.StatusInternalServerError && e.Code != Unavailable
+}
+
+// As returns the *Error in err's chain.
+func As(err error) (*Error, bool) {
+	var e *Error
+	ok := errors.As(err, &e)
+	return e, ok
+}
+
+// From returns the *Error in err's chain, or an Internal error caused by err when there
+// is none. It returns nil for a nil err.
+func From(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	if e, ok := As(err); ok {
+		return e
+	}
+	return Wrap(err, Internal, "An unexpected error occurred")
+}
+
+// CodeOf is the canonical code of err: Internal when it is not an *Error, "" when nil.
+func CodeOf(err error) Code {
+	if err == nil {
+		return ""
+	}
+	return From(err).Code
+}
+
+// HasReason reports whether err is an *Error with one of the reasons.
+func HasReason(err error, reasons ...string) bool {
+	e, ok := As(err)
+	if !ok {
+		return false
+	}
+	for _, reason := range reasons {
+		if e.Reason == reason {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestCodesRoundTripHTTPStatus(t *testing.T) {
+	for code := range mappings {
+		if code == Unknown {
+			continue
+		}
+		if got := CodeForHTTPStatus(code.HTTPStatus()); got != code {
+			t.Errorf("CodeForHTTPStatus(%d) = %s, want %s", code.HTTPStatus(), got, code)
+		}
+	}
+	if got := CodeForHTTPStatus(http.StatusTeapot); got != Unknown {
+		t.Errorf("CodeForHTTPStatus(418) = %s, want %s", got, Unknown)
+	}
+	if got := CodeForHTTPStatus(http.StatusNotImplemented); got != Internal {
+		t.Errorf("CodeForHTTPStatus(501) = %s, want %s", got, Internal)
+	}
+}
+
+func TestGRPCMapping(t *testing.T) {
+	tests := []struct {
+		code Code
+		grpc GRPCCode
+	}{
+		{BadRequest, GRPCInvalidArgument},
+		{Unauthorized, GRPCUnauthenticated},
+		{Forbidden, GRPCPermissionDenied},
+		{NotFound, GRPCNotFound},
+		{RateLimited, GRPCResourceExhausted},
+		{Internal, GRPCInternal},
+		{Unavailable, GRPCUnavailable},
+		{UpstreamTimeout, GRPCDeadlineExceeded},
+		{Code("TEAPOT"), GRPCUnknown},
+	}
+	for _, tt := range tests {
+		if got := tt.code.GRPCCode(); got != tt.grpc {
+			t.Errorf("%s.GRPCCode() = %d, want %d", tt.code, got, tt.grpc)
+		}
+	}
+	if got := CodeForGRPC(GRPCAlreadyExists); got != Conflict {
+		t.Errorf("CodeForGRPC(AlreadyExists) = %s, want %s", got, Conflict)
+	}
+	if got := CodeForGRPC(GRPCDataLoss); got != Internal {
+		t.Errorf("CodeForGRPC(DataLoss) = %s, want %s", got, Internal)
+	}
+}
+
+func TestWithMethodsCopy(t *testing.T) {
+	errNotFound := New(NotFound, "order not found").WithReason("ORDER_NOT_FOUND")
+	cause := errors.New("no rows")
+
+	err := fmt.Errorf("get order: %w", errNotFound.WithCause(cause).WithDetails("id"))
+
+	if errNotFound.Cause != nil || errNotFound.Details != nil {
+		t.Fatal("With methods modified the predefined error")
+	}
+	if !errors.Is(err, errNotFound) {
+		t.Error("copy does not match the predefined error")
+	}
+	if !errors.Is(err, cause) {
+		t.Error("cause is not in the chain")
+	}
+	if errors.Is(err, New(NotFound, "coupon not found").WithReason("COUPON_NOT_FOUND")) {
+		t.Error("matched an error of another reason")
+	}
+	if errors.Is(New(NotFound, "a"), New(NotFound, "b")) {
+		t.Error("errors without a reason matched by code alone")
+	}
+	if !HasReason(err, "COUPON_NOT_FOUND", "ORDER_NOT_FOUND") {
+		t.Error("HasReason missed ORDER_NOT_FOUND")
+	}
+}
+
+func TestFrom(t *testing.T) {
+	if From(nil) != nil {
+		t.Error("From(nil) is not nil")
+	}
+
+	plain := errors.New("connection refused")
+	e := From(plain)
+	if e.Code != Internal || !errors.Is(e, plain) {
+		t.Errorf("From(plain) = %v", e)
+	}
+
+	wrapped := fmt.Errorf("create order: %w", New(Conflict, "order cannot be cancelled"))
+	if CodeOf(wrapped) != Conflict {
+		t.Errorf("CodeOf(wrapped) = %s", CodeOf(wrapped))
+	}
+}
+
+func TestPublic(t *testing.T) {
+	e := New(BadRequest, "Invalid email").WithReason("INVALID_EMAIL").WithDetails([]string{"email"})
+	body := e.Public()
+	if body.Code != "INVALID_EMAIL" || body.Message != "Invalid email" || body.Details == nil {
+		t.Errorf("Public() = %+v", body)
+	}
+	if e.HTTPStatus() != http.StatusBadRequest {
+		t.Errorf("HTTPStatus() = %d", e.HTTPStatus())
+	}
+
+	internal := Wrap(errors.New("dial tcp: timeout"), Internal, "Database query failed").WithDetails("SELECT ...")
+	body = internal.Public()
+	if body.Code != string(Internal) || body.Message == internal.Message || body.Details != nil {
+		t.Errorf("internal Public() = %+v", body)
+	}
+
+	unavailable := New(Unavailable, "Avatar uploads are not available")
+	if unavailable.Internal() || unavailable.Public().Message != unavailable.Message {
+		t.Error("an unavailable error is hidden like an internal one")
+	}
+
+	locked := New(Unauthorized, "Too many failed login attempts").WithStatus(http.StatusLocked)
+	if locked.HTTPStatus() != http.StatusLocked || locked.GRPCCode() != GRPCUnauthenticated {
+		t.Errorf("locked status = %d, grpc = %d", locked.HTTPStatus(), locked.GRPCCode())
+	}
+}
@@ -0,0 +1,18 @@
+package apperr
+
+// Body is the error object of a JSON error response.
+type Body struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Details   any    `json:"details,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Public is the error as shown to clients: internal errors lose their message and
+// details, which may leak implementation details, for a generic message.
+func (e *Error) Public() Body {
+	if e.Internal() {
+		return Body{Code: e.ErrorCode(), Message: "An internal error occurred"}
+	}
+	return Body{Code: e.ErrorCode(), Message: e.Message, Details: e.Details}
+}
@@ -0,0 +1,157 @@
+package apperr
+
+import "net/http"
+
+// Code is a canonical error code. It is the error_code reported to clients unless the
+// error carries a more specific reason, and sets the HTTP and gRPC status of the error.
+type Code string
+
+// The canonical codes. Their values are the codes clients already receive from the BFF.
+const (
+	BadRequest       Code = "BAD_REQUEST"
+	ValidationFailed Code = "VALIDATION_FAILED"
+	Unauthorized     Code = "UNAUTHORIZED"
+	Forbidden        Code = "FORBIDDEN"
+	NotFound         Code = "NOT_FOUND"
+	MethodNotAllowed Code = "METHOD_NOT_ALLOWED"
+	Conflict         Code = "CONFLICT"
+	Gone             Code = "GONE"
+	PayloadTooLarge  Code = "PAYLOAD_TOO_LARGE"
+	Locked           Code = "LOCKED"
+	RateLimited      Code = "RATE_LIMITED"
+	Internal         Code = "INTERNAL_ERROR"
+	Upstream         Code = "UPSTREAM_ERROR"
+	Unavailable      Code = "SERVICE_UNAVAILABLE"
+	UpstreamTimeout  Code = "UPSTREAM_TIMEOUT"
+	// Unknown is a client error of a status no other code covers
+	Unknown Code = "REQUEST_FAILED"
+)
+
+// GRPCCode is a gRPC status code. The values are those of google.golang.org/grpc/codes,
+// so codes.Code(c) converts one.
+type GRPCCode int
+
+// The gRPC status codes.
+const (
+	GRPCOK                 GRPCCode = 0
+	GRPCCanceled           GRPCCode = 1
+	GRPCUnknown            GRPCCode = 2
+	GRPCInvalidArgument    GRPCCode = 3
+	GRPCDeadlineExceeded   GRPCCode = 4
+	GRPCNotFound           GRPCCode = 5
+	GRPCAlreadyExists      GRPCCode = 6
+	GRPCPermissionDenied   GRPCCode = 7
+	GRPCResourceExhausted  GRPCCode = 8
+	GRPCFailedPrecondition GRPCCode = 9
+	GRPCAborted            GRPCCode = 10
+	GRPCOutOfRange         GRPCCode = 11
+	GRPCUnimplemented      GRPCCode = 12
+	GRPCInternal           GRPCCode = 13
+	GRPCUnavailable        GRPCCode = 14
+	GRPCDataLoss           GRPCCode = 15
+	GRPCUnauthenticated    GRPCCode = 16
+)
+
+type mapping struct {
+	status int
+	grpc   GRPCCode
+}
+
+var mappings = map[Code]mapping{
+	BadRequest:       {http.StatusBadRequest, GRPCInvalidArgument},
+	ValidationFailed: {http.StatusUnprocessableEntity, GRPCInvalidArgument},
+	Unauthorized:     {http.StatusUnauthorized, GRPCUnauthenticated},
+	Forbidden:        {http.StatusForbidden, GRPCPermissionDenied},
+	NotFound:         {http.StatusNotFound, GRPCNotFound},
+	MethodNotAllowed: {http.StatusMethodNotAllowed, GRPCUnimplemented},
+	Conflict:         {http.StatusConflict, GRPCFailedPrecondition},
+	Gone:             {http.StatusGone, GRPCNotFound},
+	PayloadTooLarge:  {http.StatusRequestEntityTooLarge, GRPCResourceExhausted},
+	Locked:           {http.StatusLocked, GRPCFailedPrecondition},
+	RateLimited:      {http.StatusTooManyRequests, GRPCResourceExhausted},
+	Internal:         {http.StatusInternalServerError, GRPCInternal},
+	Upstream:         {http.StatusBadGateway, GRPCUnavailable},
+	Unavailable:      {http.StatusServiceUnavailable, GRPCUnavailable},
+	UpstreamTimeout:  {http.StatusGatewayTimeout, GRPCDeadlineExceeded},
+	Unknown:          {http.StatusBadRequest, GRPCUnknown},
+}
+
+// HTTPStatus is the HTTP status of the code; 500 for a code that is not canonical.
+func (c Code) HTTPStatus() int {
+	if m, ok := mappings[c]; ok {
+		return m.status
+	}
+	return http.StatusInternalServerError
+}
+
+// GRPCCode is the gRPC status code of the code; Unknown for a code that is not canonical.
+func (c Code) GRPCCode() GRPCCode {
+	if m, ok := mappings[c]; ok {
+		return m.grpc
+	}
+	return GRPCUnknown
+}
+
+// CodeForHTTPStatus is the canonical code of an HTTP error status, for errors that come
+// with a status only, such as the responses of other services.
+func CodeForHTTPStatus(status int) Code {
+	switch status {
+	case http.StatusBadRequest:
+		return BadRequest
+	case http.StatusUnauthorized:
+		return Unauthorized
+	case http.StatusForbidden:
+		return Forbidden
+	case http.StatusNotFound:
+		return NotFound
+	case http.StatusMethodNotAllowed:
+		return MethodNotAllowed
+	case http.StatusConflict:
+		return Conflict
+	case http.StatusGone:
+		return Gone
+	case http.StatusRequestEntityTooLarge:
+		return PayloadTooLarge
+	case http.StatusUnprocessableEntity:
+		return ValidationFailed
+	case http.StatusLocked:
+		return Locked
+	case http.StatusTooManyRequests:
+		return RateLimited
+	case http.StatusBadGateway:
+		return Upstream
+	case http.StatusServiceUnavailable:
+		return Unavailable
+	case http.StatusGatewayTimeout:
+		return UpstreamTimeout
+	}
+	if status >= http.StatusInternalServerError {
+		return Internal
+	}
+	return Unknown
+}
+
+// CodeForGRPC is the canonical code of a gRPC status code other than OK.
+func CodeForGRPC(code GRPCCode) Code {
+	switch code {
+	case GRPCInvalidArgument, GRPCOutOfRange:
+		return BadRequest
+	case GRPCUnauthenticated:
+		return Unauthorized
+	case GRPCPermissionDenied:
+		return Forbidden
+	case GRPCNotFound:
+		return NotFound
+	case GRPCAlreadyExists, GRPCFailedPrecondition, GRPCAborted:
+		return Conflict
+	case GRPCResourceExhausted:
+		return RateLimited
+	case GRPCUnimplemented:
+		return MethodNotAllowed
+	case GRPCUnavailable:
+		return Unavailable
+	case GRPCDeadlineExceeded:
+		return UpstreamTimeout
+	}
+	return Internal
+}
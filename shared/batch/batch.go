@@ -0,0 +1,146 @@
+// Package batch is the convention of the internal batch endpoints, through which a
+// service resolves many records of another one in a single call instead of one call per
+// ID: users from user-services, courses from content-services, streaks from
+// lesson-services.
+//
+// Every batch endpoint is a POST taking {"ids": [...]} and answering the records found,
+// in the order they were asked for, with the IDs that matched nothing:
+//
+//	POST /api/v1/internal/users/batch  {"ids": ["a", "b", "c"]}
+//	200 {"data": {"items": [{"id": "a", ...}, {"id": "c", ...}], "missing_ids": ["b"]}}
+//
+// IDs are trimmed and deduplicated, and a batch of more than MaxSize distinct IDs is
+// refused with a 400, so a caller chunks its lookups (see Fetch) rather than passing an
+// unbounded list to a database query. An ID the endpoint cannot parse is reported as
+// missing, like an ID of a record that does not exist.
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MaxSize is the number of distinct IDs a batch endpoint accepts at most.
+const MaxSize = 100
+
+// maxBodyBytes bounds the request bodies Decode reads; MaxSize UUIDs take under 4 KiB.
+const maxBodyBytes = 64 << 10
+
+// ErrEmpty reports a batch without IDs.
+var ErrEmpty = errors.New("batch: ids are required")
+
+// TooLargeError reports a batch of more IDs than the endpoint accepts.
+type TooLargeError struct {
+	Size int
+	Max  int
+}
+
+func (e *TooLargeError) Error() string {
+	return fmt.Sprintf("batch: %d ids requested, at most %d may be requested at once", e.Size, e.Max)
+}
+
+// Request is the body of a batch endpoint.
+type Request struct {
+	IDs []string `json:"ids"`
+}
+
+// Response is the answer of a batch endpoint: the records found, in the order of the
+// request, and the IDs that matched none.
+type Response[T any] struct {
+	Items   []T      `json:"items"`
+	Missing []string `json:"missing_ids"`
+}
+
+// IDs returns ids trimmed, without blanks and duplicates, in their order. It fails with
+// ErrEmpty when none is left and a *TooLargeError when more than max are, MaxSize when
+// max is not positive.
+func IDs(ids []string, max int) ([]string, error) {
+	if max <= 0 {
+		max = MaxSize
+	}
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	switch {
+	case len(unique) == 0:
+		return nil, ErrEmpty
+	case len(unique) > max:
+		return nil, &TooLargeError{Size: len(unique), Max: max}
+	}
+	return unique, nil
+}
+
+// Decode reads a Request from body and returns its IDs, checked by IDs.
+//
+//	ids, err := batch.Decode(c.Request.Body, batch.MaxSize)
+func Decode(body io.Reader, max int) ([]string, error) {
+	var req Request
+	if err := json.NewDecoder(io.LimitReader(body, maxBodyBytes)).Decode(&req); err != nil {
+		return nil, fmt.Errorf("batch: invalid request body: %w", err)
+	}
+	return IDs(req.IDs, max)
+}
+
+// Collect builds the response to ids from the records found, whatever their order; key
+// returns the ID of a record. Records that were not asked for are left out.
+func Collect[T any](ids []string, found []T, key func(T) string) Response[T] {
+	byID := make(map[string]T, len(found))
+	for _, item := range found {
+		byID[key(item)] = item
+	}
+	resp := Response[T]{Items: make([]T, 0, len(found)), Missing: []string{}}
+	for _, id := range ids {
+		if item, ok := byID[id]; ok {
+			resp.Items = append(resp.Items, item)
+			continue
+		}
+		resp.Missing = append(resp.Missing, id)
+	}
+	return resp
+}
+
+// Chunks splits ids into batches of at most size IDs, MaxSize when size is not
+// positive.
+func Chunks(ids []string, size int) [][]string {
+	if size <= 0 {
+		size = MaxSize
+	}
+	chunks := make([][]string, 0, (len(ids)+size-1)/size)
+	for start := 0; start < len(ids); start += size {
+		chunks = append(chunks, ids[start:min(start+size, len(ids))])
+	}
+	return chunks
+}
+
+// Fetch resolves ids through a batch endpoint, calling fetch once per chunk of at most
+// size distinct IDs, and merges the responses. It stops at the first failing call.
+func Fetch[T any](ctx context.Context, ids []string, size int, fetch func(ctx context.Context, ids []string) (Response[T], error)) (Response[T], error) {
+	var merged Response[T]
+	ids, err := IDs(ids, len(ids))
+	if errors.Is(err, ErrEmpty) {
+		return merged, nil
+	}
+	for _, chunk := range Chunks(ids, size) {
+		if err := ctx.Err(); err != nil {
+			return merged, err
+		}
+		resp, err := fetch(ctx, chunk)
+		if err != nil {
+			return merged, err
+		}
+		merged.Items = append(merged.Items, resp.Items...)
+		merged.Missing = append(merged.Missing, resp.Missing...)
+	}
+	return merged, nil
+}
@@ -0,0 +1,92 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestIDs(t *testing.T) {
+	ids, err := IDs([]string{" a", "b", "", "a", "c "}, 3)
+	if err != nil || !slices.Equal(ids, []string{"a", "b", "c"}) {
+		t.Errorf("IDs = %v, %v", ids, err)
+	}
+
+	if _, err := IDs([]string{" ", ""}, 0); !errors.Is(err, ErrEmpty) {
+		t.Errorf("blank ids: err = %v, want ErrEmpty", err)
+	}
+	var tooLarge *TooLargeError
+	if _, err := IDs([]string{"a", "b", "c"}, 2); !errors.As(err, &tooLarge) || tooLarge.Size != 3 || tooLarge.Max != 2 {
+		t.Errorf("3 ids, max 2: err = %v", err)
+	}
+	many := make([]string, MaxSize+1)
+	for i := range many {
+		many[i] = fmt.Sprint(i)
+	}
+	if _, err := IDs(many, 0); !errors.As(err, &tooLarge) {
+		t.Errorf("%d ids: err = %v, want a TooLargeError", len(many), err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	ids, err := Decode(strings.NewReader(`{"ids": ["u1", "u2", "u1"]}`), MaxSize)
+	if err != nil || !slices.Equal(ids, []string{"u1", "u2"}) {
+		t.Errorf("Decode = %v, %v", ids, err)
+	}
+	for _, body := range []string{``, `{"ids": "u1"}`, `{"ids": []}`} {
+		if _, err := Decode(strings.NewReader(body), MaxSize); err == nil {
+			t.Errorf("%q: want an error", body)
+		}
+	}
+}
+
+type record struct{ ID, Name string }
+
+func TestCollect(t *testing.T) {
+	found := []record{{"c", "Carol"}, {"a", "Alice"}, {"z", "not asked for"}}
+	resp := Collect([]string{"a", "b", "c"}, found, func(r record) string { return r.ID })
+	if len(resp.Items) != 2 || resp.Items[0].ID != "a" || resp.Items[1].ID != "c" {
+		t.Errorf("items %v, want a then c", resp.Items)
+	}
+	if !slices.Equal(resp.Missing, []string{"b"}) {
+		t.Errorf("missing %v", resp.Missing)
+	}
+
+	if resp := Collect([]string{"a"}, nil, func(r record) string { return r.ID }); resp.Items == nil || len(resp.Missing) != 1 {
+		t.Errorf("nothing found: %+v, want empty items, not null", resp)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	var calls [][]string
+	fetch := func(_ context.Context, ids []string) (Response[record], error) {
+		calls = append(calls, ids)
+		return Collect(ids, []record{{ID: "1"}, {ID: "4"}}, func(r record) string { return r.ID }), nil
+	}
+
+	resp, err := Fetch(context.Background(), []string{"1", "2", "3", "1", "4", "5"}, 2, fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 3 || !slices.Equal(calls[0], []string{"1", "2"}) || !slices.Equal(calls[2], []string{"5"}) {
+		t.Errorf("calls %v, want chunks of 2 distinct ids", calls)
+	}
+	if len(resp.Items) != 2 || !slices.Equal(resp.Missing, []string{"2", "3", "5"}) {
+		t.Errorf("merged %+v", resp)
+	}
+
+	calls = nil
+	if _, err := Fetch(context.Background(), nil, 2, fetch); err != nil || len(calls) != 0 {
+		t.Errorf("no ids: %d calls, err %v", len(calls), err)
+	}
+
+	failing := func(context.Context, []string) (Response[record], error) {
+		return Response[record]{}, errors.New("unavailable")
+	}
+	if _, err := Fetch(context.Background(), []string{"1"}, 2, failing); err == nil {
+		t.Error("failing call: want an error")
+	}
+}
@@ -0,0 +1,253 @@
+// Package chaos injects faults into the calls of a service outside production, so its
+// timeouts, retries and fallbacks are exercised before an incident does it:
+//
+//   - latency, to trip timeouts
+//   - errors, an HTTP status instead of the downstream response or an error instead of
+//     running a consumer handler
+//   - dropped results, the call is made and its result lost, so the caller retries a
+//     call that already took effect
+//
+// Faults come from CHAOS_RULES for every call, and from the X-Chaos header of a single
+// request when CHAOS_HEADERS is set. The Transport of an HTTP client and Handler of a
+// consumer inject them; a nil Injector injects nothing.
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Header carries the faults of a single request, in the syntax of ParseRules.
+const Header = "X-Chaos"
+
+// InjectedHeader is set on the responses made up by an Error fault.
+const InjectedHeader = "X-Chaos-Injected"
+
+var (
+	// ErrInjected is returned by a handler hit by an Error fault
+	ErrInjected = errors.New("chaos: injected error")
+	// ErrDropped is returned by a call whose result was dropped
+	ErrDropped = errors.New("chaos: result dropped")
+)
+
+var faultsTotal = promauto.With(metrics.Default).NewCounterVec(prometheus.CounterOpts{
+	Name: "chaos_faults_injected_total",
+	Help: "Faults injected by target and kind.",
+}, []string{"target", "kind"})
+
+// Config configures fault injection.
+type Config struct {
+	// Enabled turns fault injection on
+	Enabled bool
+	// Rules are the faults injected into every call
+	Rules Rules
+	// Headers accepts the faults of the X-Chaos request header
+	Headers bool
+}
+
+// ConfigFromEnv reads CHAOS_ENABLED, CHAOS_RULES and CHAOS_HEADERS. Fault injection is
+// refused when ENVIRONMENT is production.
+func ConfigFromEnv() (Config, error) {
+	var cfg Config
+	cfg.Enabled, _ = strconv.ParseBool(os.Getenv("CHAOS_ENABLED"))
+	if !cfg.Enabled {
+		return cfg, nil
+	}
+	if strings.EqualFold(os.Getenv("ENVIRONMENT"), "production") {
+		return Config{}, errors.New("chaos: CHAOS_ENABLED must not be set in production")
+	}
+
+	rules, err := ParseRules(os.Getenv("CHAOS_RULES"))
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.Rules = rules
+	cfg.Headers, _ = strconv.ParseBool(os.Getenv("CHAOS_HEADERS"))
+	return cfg, nil
+}
+
+// Injector injects the faults of a configuration.
+type Injector struct {
+	rules   Rules
+	headers bool
+	random  func() float64
+}
+
+// New returns an injector for cfg, nil when fault injection is disabled.
+func New(cfg Config) *Injector {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &Injector{rules: cfg.Rules, headers: cfg.Headers, random: rand.Float64}
+}
+
+type contextKey struct{}
+
+// WithHeader returns ctx carrying the faults of the X-Chaos header of h, for the calls
+// made while handling the request. It returns ctx unchanged when the header is absent or
+// not accepted, and an error when it does not parse.
+func (i *Injector) WithHeader(ctx context.Context, h http.Header) (context.Context, error) {
+	raw := h.Get(Header)
+	if i == nil || !i.headers || raw == "" {
+		return ctx, nil
+	}
+	rules, err := ParseRules(raw)
+	if err != nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, contextKey{}, rules), nil
+}
+
+// Middleware applies WithHeader to the requests of next, answering 400 to a malformed
+// header.
+func (i *Injector) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, err := i.WithHeader(r.Context(), r.Header)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// pick returns the faults hit by a call to target: the configured ones and those of the
+// request in ctx.
+func (i *Injector) pick(ctx context.Context, target string) []Fault {
+	var hit []Fault
+	roll := func(faults []Fault) {
+		for _, fault := range faults {
+			if fault.Rate >= 1 || i.random() < fault.Rate {
+				hit = append(hit, fault)
+			}
+		}
+	}
+	roll(i.rules.faults(target))
+	if rules, ok := ctx.Value(contextKey{}).(Rules); ok {
+		roll(rules.faults(target))
+	}
+
+	for _, fault := range hit {
+		faultsTotal.WithLabelValues(target, string(fault.Kind)).Inc()
+		slog.WarnContext(ctx, "chaos: injecting fault", "target", target, "fault", fault.String())
+	}
+	return hit
+}
+
+// delay waits for the Latency faults of faults, and returns the first Error and Drop
+// faults.
+func delay(ctx context.Context, faults []Fault) (failed, dropped *Fault, err error) {
+	for _, fault := range faults {
+		switch fault.Kind {
+		case Latency:
+			timer := time.NewTimer(fault.Delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, nil, ctx.Err()
+			}
+		case Error:
+			if failed == nil {
+				failed = &fault
+			}
+		case Drop:
+			if dropped == nil {
+				dropped = &fault
+			}
+		}
+	}
+	return failed, dropped, nil
+}
+
+// Transport injects faults into the outbound requests to a target.
+type Transport struct {
+	injector *Injector
+	target   string
+	base     http.RoundTripper
+}
+
+// Transport wraps base (http.DefaultTransport when nil) for the calls to target, the
+// name of the service called. A nil injector returns base unchanged.
+func (i *Injector) Transport(target string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if i == nil {
+		return base
+	}
+	return &Transport{injector: i, target: target, base: base}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	failed, dropped, err := delay(req.Context(), t.injector.pick(req.Context(), t.target))
+	if err != nil || failed != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		if err != nil {
+			return nil, err
+		}
+		return injectedResponse(req, failed.Status), nil
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || dropped == nil {
+		return resp, err
+	}
+	resp.Body.Close()
+	return nil, fmt.Errorf("%s %s: %w", req.Method, req.URL.Redacted(), ErrDropped)
+}
+
+func injectedResponse(req *http.Request, status int) *http.Response {
+	body := fmt.Sprintf(`{"error":"chaos: injected %d"}`, status)
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set(InjectedHeader, string(Error))
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// Handler injects faults into handler, a message handler such as a consumer.Handler,
+// under the name target. A nil injector returns handler unchanged.
+func Handler[M any](i *Injector, target string, handler func(context.Context, M) error) func(context.Context, M) error {
+	if i == nil {
+		return handler
+	}
+	return func(ctx context.Context, msg M) error {
+		failed, dropped, err := delay(ctx, i.pick(ctx, target))
+		switch {
+		case err != nil:
+			return err
+		case failed != nil:
+			return ErrInjected
+		}
+		if err := handler(ctx, msg); err != nil || dropped == nil {
+			return err
+		}
+		return ErrDropped
+	}
+}
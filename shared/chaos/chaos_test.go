@@ -0,0 +1,198 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules("lesson-services=latency:800ms@0.5,error:502@0.1; *=drop@0.01,error")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Rules{
+		"lesson-services": {
+			{Kind: Latency, Rate: 0.5, Delay: 800 * time.Millisecond},
+			{Kind: Error, Rate: 0.1, Status: 502},
+		},
+		"*": {
+			{Kind: Drop, Rate: 0.01},
+			{Kind: Error, Rate: 1, Status: 503},
+		},
+	}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("got %v, want %v", rules, want)
+	}
+	if got := rules.String(); got != "*=drop@0.01,error:503;lesson-services=latency:800ms@0.5,error:502@0.1" {
+		t.Errorf("String = %q", got)
+	}
+
+	for _, bad := range []string{
+		"lesson-services",
+		"=drop",
+		"a=drop;a=error",
+		"a=latency",
+		"a=latency:-1s",
+		"a=error:200",
+		"a=drop:1",
+		"a=drop@1.5",
+		"a=timeout",
+	} {
+		if _, err := ParseRules(bad); err == nil {
+			t.Errorf("%q: want an error", bad)
+		}
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("CHAOS_ENABLED", "true")
+	t.Setenv("CHAOS_RULES", "order-services=error")
+	t.Setenv("CHAOS_HEADERS", "true")
+	t.Setenv("ENVIRONMENT", "staging")
+	cfg, err := ConfigFromEnv()
+	if err != nil || !cfg.Enabled || !cfg.Headers || len(cfg.Rules["order-services"]) != 1 {
+		t.Errorf("got %+v, %v", cfg, err)
+	}
+
+	t.Setenv("ENVIRONMENT", "production")
+	if _, err := ConfigFromEnv(); err == nil {
+		t.Error("fault injection accepted in production")
+	}
+
+	t.Setenv("CHAOS_ENABLED", "")
+	if cfg, err := ConfigFromEnv(); err != nil || cfg.Enabled || New(cfg) != nil {
+		t.Errorf("disabled: got %+v, %v", cfg, err)
+	}
+}
+
+func newInjector(rules string, headers bool) *Injector {
+	parsed, err := ParseRules(rules)
+	if err != nil {
+		panic(err)
+	}
+	return New(Config{Enabled: true, Rules: parsed, Headers: headers})
+}
+
+func TestTransport(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	get := func(i *Injector, target string) (*http.Response, error) {
+		client := &http.Client{Transport: i.Transport(target, nil)}
+		return client.Get(server.URL)
+	}
+
+	resp, err := get(newInjector("lesson-services=error:502", false), "lesson-services")
+	if err != nil || resp.StatusCode != http.StatusBadGateway || resp.Header.Get(InjectedHeader) != "error" {
+		t.Fatalf("error fault: got %v, %v", resp, err)
+	}
+	resp.Body.Close()
+	if calls != 0 {
+		t.Errorf("an error fault called the server")
+	}
+
+	if _, err := get(newInjector("*=drop", false), "lesson-services"); !errors.Is(err, ErrDropped) {
+		t.Errorf("drop fault: got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("a drop fault should call the server, %d calls", calls)
+	}
+
+	resp, err = get(newInjector("order-services=error", false), "lesson-services")
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("other target: got %v, %v", resp, err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "ok" {
+		t.Errorf("body = %q", body)
+	}
+
+	start := time.Now()
+	resp, err = get(newInjector("*=latency:50ms", false), "lesson-services")
+	if err != nil || time.Since(start) < 50*time.Millisecond {
+		t.Errorf("latency fault: got %v after %v", err, time.Since(start))
+	}
+	resp.Body.Close()
+
+	var nilInjector *Injector
+	if nilInjector.Transport("lesson-services", nil) != http.DefaultTransport {
+		t.Error("a nil injector should not wrap the transport")
+	}
+}
+
+func TestLatencyHonoursTheContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://lesson-services.invalid", nil)
+	start := time.Now()
+	_, err := newInjector("*=latency:1h", false).Transport("lesson-services", nil).RoundTrip(req)
+	if !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > time.Second {
+		t.Errorf("got %v after %v", err, time.Since(start))
+	}
+}
+
+func TestRates(t *testing.T) {
+	i := newInjector("*=error@0.25", false)
+	for r, hit := range map[float64]bool{0.1: true, 0.3: false} {
+		i.random = func() float64 { return r }
+		if got := len(i.pick(context.Background(), "x")) == 1; got != hit {
+			t.Errorf("random %v: hit %v, want %v", r, got, hit)
+		}
+	}
+}
+
+func TestHeader(t *testing.T) {
+	serve := func(i *Injector, header string) *httptest.ResponseRecorder {
+		handler := i.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Faults", strconv.Itoa(len(i.pick(r.Context(), "lesson-services"))))
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/lessons", nil)
+		if header != "" {
+			req.Header.Set(Header, header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve(newInjector("", true), "lesson-services=latency:1ms,error"); rec.Header().Get("Faults") != "2" {
+		t.Errorf("faults of the header: got %q", rec.Header().Get("Faults"))
+	}
+	if rec := serve(newInjector("", false), "lesson-services=error"); rec.Header().Get("Faults") != "0" {
+		t.Errorf("header accepted with CHAOS_HEADERS off: got %q", rec.Header().Get("Faults"))
+	}
+	if rec := serve(newInjector("", true), "lesson-services=explode"); rec.Code != http.StatusBadRequest {
+		t.Errorf("malformed header: got %d", rec.Code)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var handled int
+	handler := func(context.Context, string) error {
+		handled++
+		return nil
+	}
+	ctx := context.Background()
+
+	if err := Handler(newInjector("lesson.completed=error", false), "lesson.completed", handler)(ctx, "msg"); !errors.Is(err, ErrInjected) || handled != 0 {
+		t.Errorf("error fault: got %v, %d handled", err, handled)
+	}
+	if err := Handler(newInjector("*=drop", false), "lesson.completed", handler)(ctx, "msg"); !errors.Is(err, ErrDropped) || handled != 1 {
+		t.Errorf("drop fault: got %v, %d handled", err, handled)
+	}
+	if err := Handler(nil, "lesson.completed", handler)(ctx, "msg"); err != nil || handled != 2 {
+		t.Errorf("nil injector: got %v, %d handled", err, handled)
+	}
+}
@@ -0,0 +1,167 @@
+package chaos
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is the kind of a fault.
+type Kind string
+
+const (
+	// Latency delays the call by Fault.Delay
+	Latency Kind = "latency"
+	// Error fails the call without making it: an HTTP call gets a response with
+	// Fault.Status, a handler ErrInjected
+	Error Kind = "error"
+	// Drop makes the call and then loses its result: an HTTP call returns ErrDropped after
+	// the downstream service answered, a handler returns ErrDropped after it succeeded
+	Drop Kind = "drop"
+)
+
+// Fault is a fault injected into a share of the calls to a target.
+type Fault struct {
+	Kind Kind
+	// Rate is the share of the calls the fault hits, from 0 to 1
+	Rate float64
+	// Delay is the delay of a Latency fault
+	Delay time.Duration
+	// Status is the response status of an Error fault on an HTTP call, 503 by default
+	Status int
+}
+
+// String formats the fault the way ParseRules reads it.
+func (f Fault) String() string {
+	s := string(f.Kind)
+	switch f.Kind {
+	case Latency:
+		s += ":" + f.Delay.String()
+	case Error:
+		s += ":" + strconv.Itoa(f.Status)
+	}
+	if f.Rate < 1 {
+		s += "@" + strconv.FormatFloat(f.Rate, 'f', -1, 64)
+	}
+	return s
+}
+
+// Any is the target whose faults apply to every call.
+const Any = "*"
+
+// Rules are faults by target: the peer name of an HTTP client, such as
+// "lesson-services", or the name given to a handler.
+type Rules map[string][]Fault
+
+// ParseRules parses a semicolon separated list of targets with their comma separated
+// faults:
+//
+//	lesson-services=latency:800ms@0.5,error:503@0.1;*=drop@0.01
+//
+// Each fault is latency:<duration>, error[:<status>] or drop, optionally followed by
+// @<rate>, the share of the calls it hits (1 when omitted). The target * matches every
+// call.
+func ParseRules(s string) (Rules, error) {
+	rules := Rules{}
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		target, spec, ok := strings.Cut(entry, "=")
+		target = strings.TrimSpace(target)
+		if !ok || target == "" {
+			return nil, fmt.Errorf("chaos: %q: want target=faults", entry)
+		}
+		if _, dup := rules[target]; dup {
+			return nil, fmt.Errorf("chaos: target %s is defined twice", target)
+		}
+		for _, raw := range strings.Split(spec, ",") {
+			fault, err := parseFault(strings.TrimSpace(raw))
+			if err != nil {
+				return nil, fmt.Errorf("chaos: %s: %w", target, err)
+			}
+			rules[target] = append(rules[target], fault)
+		}
+	}
+	return rules, nil
+}
+
+func parseFault(s string) (Fault, error) {
+	fault := Fault{Rate: 1}
+	spec, rate, hasRate := strings.Cut(s, "@")
+	if hasRate {
+		var err error
+		if fault.Rate, err = strconv.ParseFloat(rate, 64); err != nil || fault.Rate < 0 || fault.Rate > 1 {
+			return Fault{}, fmt.Errorf("%q: rate must be between 0 and 1", s)
+		}
+	}
+
+	kind, arg, hasArg := strings.Cut(spec, ":")
+	fault.Kind = Kind(kind)
+	switch fault.Kind {
+	case Latency:
+		delay, err := time.ParseDuration(arg)
+		if err != nil || delay <= 0 {
+			return Fault{}, fmt.Errorf("%q: want latency:<duration>", s)
+		}
+		fault.Delay = delay
+	case Error:
+		fault.Status = http.StatusServiceUnavailable
+		if hasArg {
+			status, err := strconv.Atoi(arg)
+			if err != nil || status < 400 || status > 599 {
+				return Fault{}, fmt.Errorf("%q: status must be between 400 and 599", s)
+			}
+			fault.Status = status
+		}
+	case Drop:
+		if hasArg {
+			return Fault{}, fmt.Errorf("%q: drop takes no argument", s)
+		}
+	default:
+		return Fault{}, fmt.Errorf("%q: unknown fault, want latency, error or drop", s)
+	}
+	return fault, nil
+}
+
+// UnmarshalText parses the rules with ParseRules, so a configuration struct can load
+// them with `env:"CHAOS_RULES"`.
+func (r *Rules) UnmarshalText(text []byte) error {
+	parsed, err := ParseRules(string(text))
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// String formats the rules the way ParseRules reads them, sorted by target.
+func (r Rules) String() string {
+	targets := make([]string, 0, len(r))
+	for target := range r {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	entries := make([]string, 0, len(targets))
+	for _, target := range targets {
+		faults := make([]string, 0, len(r[target]))
+		for _, fault := range r[target] {
+			faults = append(faults, fault.String())
+		}
+		entries = append(entries, target+"="+strings.Join(faults, ","))
+	}
+	return strings.Join(entries, ";")
+}
+
+// faults returns the faults of target, followed by those of every target.
+func (r Rules) faults(target string) []Fault {
+	if len(r) == 0 {
+		return nil
+	}
+	return append(r[target][:len(r[target]):len(r[target])], r[Any]...)
+}
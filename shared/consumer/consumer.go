@@ -0,0 +1,250 @@
+// Package consumer runs RabbitMQ consumers for the Go services. A Consumer declares its
+// queue together with retry queues and a dead-letter queue, hands every message to the
+// handler registered for its routing key and then acks it, schedules a retry or
+// dead-letters it, so handlers only return an error.
+package consumer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ErrNoHandler is the error a message is dead-lettered with when no handler is
+// registered for its routing key.
+var ErrNoHandler = errors.New("no handler for routing key")
+
+// Message is a delivery as seen by a handler. A retried message has the routing key and
+// exchange it was first published with, not those of its retry queue.
+type Message struct {
+	amqp.Delivery
+	// Attempt is 1 on the first delivery and counts up with every retry
+	Attempt int
+}
+
+// Handler handles one message. Returning nil acks the message; an error retries it
+// after a backoff, unless it is wrapped with Permanent or the message has no attempts
+// left, in which case it is dead-lettered. A panic counts as an error.
+type Handler func(ctx context.Context, msg Message) error
+
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as one a retry cannot fix, such as a message that does not
+// decode; the message is dead-lettered at once.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent.
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// Config tunes a Consumer. Only Queue is required.
+type Config struct {
+	// Exchange is the topic exchange the queue is bound to with the routing keys of its
+	// handlers; it is declared if missing. Without one the queue is only fed by its
+	// retry queues and by messages published to it directly
+	Exchange string
+	Queue    string
+	// Concurrency is how many messages are handled at once; 1 by default
+	Concurrency int
+	// Prefetch is how many unacked messages the broker sends ahead; Concurrency by default
+	Prefetch int
+	// MaxAttempts is how many times a message is handled before it is dead-lettered; 5
+	// by default
+	MaxAttempts int
+	// BackoffBase is the delay before the first retry; it doubles with every further
+	// failure up to BackoffMax. 1s and 5m by default
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// ReconnectDelay is the wait before a closed channel is reopened; 5s by default
+	ReconnectDelay time.Duration
+	// Metrics is optional
+	Metrics Metrics
+}
+
+func (cfg Config) withDefaults() Config {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.Prefetch <= 0 {
+		cfg.Prefetch = cfg.Concurrency
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.BackoffBase <= 0 {
+		cfg.BackoffBase = time.Second
+	}
+	if cfg.BackoffMax < cfg.BackoffBase {
+		cfg.BackoffMax = max(5*time.Minute, cfg.BackoffBase)
+	}
+	if cfg.ReconnectDelay <= 0 {
+		cfg.ReconnectDelay = 5 * time.Second
+	}
+	if cfg.Metrics == nil {
+		cfg.Metrics = nopMetrics{}
+	}
+	return cfg
+}
+
+type route struct {
+	pattern string
+	handler Handler
+}
+
+// Consumer consumes one queue.
+type Consumer struct {
+	conn   *amqp.Connection
+	cfg    Config
+	routes []route
+}
+
+func New(conn *amqp.Connection, cfg Config) *Consumer {
+	return &Consumer{conn: conn, cfg: cfg.withDefaults()}
+}
+
+// Handle registers handler for the messages whose routing key matches pattern, which may
+// use the topic wildcards * and #, and binds the queue to the exchange with it. An exact
+// routing key wins over patterns; patterns are tried in the order they were registered.
+// Handle must be called before Run.
+func (c *Consumer) Handle(pattern string, handler Handler) {
+	c.routes = append(c.routes, route{pattern: pattern, handler: handler})
+}
+
+func (c *Consumer) handler(routingKey string) Handler {
+	for _, r := range c.routes {
+		if r.pattern == routingKey {
+			return r.handler
+		}
+	}
+	for _, r := range c.routes {
+		if matchTopic(r.pattern, routingKey) {
+			return r.handler
+		}
+	}
+	return nil
+}
+
+// Run consumes until ctx is cancelled, then waits for the messages being handled. A
+// closed channel is reopened after ReconnectDelay; Run returns once the connection
+// itself is closed.
+func (c *Consumer) Run(ctx context.Context) error {
+	slog.Info("consumer started", "queue", c.cfg.Queue, "concurrency", c.cfg.Concurrency)
+	for {
+		err := c.consume(ctx)
+		if ctx.Err() != nil {
+			slog.Info("consumer stopped", "queue", c.cfg.Queue)
+			return nil
+		}
+		if c.conn.IsClosed() {
+			return fmt.Errorf("consumer %s: connection closed: %w", c.cfg.Queue, err)
+		}
+		slog.WarnContext(ctx, "consumer channel closed, reopening", "queue", c.cfg.Queue, "error", err, "delay", c.cfg.ReconnectDelay)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(c.cfg.ReconnectDelay):
+		}
+	}
+}
+
+func (c *Consumer) consume(ctx context.Context) error {
+	ch, err := c.conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer ch.Close()
+
+	if err := c.Declare(ch); err != nil {
+		return err
+	}
+	if err := ch.Qos(c.cfg.Prefetch, 0, false); err != nil {
+		return fmt.Errorf("failed to set prefetch: %w", err)
+	}
+	if err := ch.Confirm(false); err != nil {
+		return fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+	closed := ch.NotifyClose(make(chan *amqp.Error, 1))
+
+	tag := consumerTag(c.cfg.Queue)
+	deliveries, err := ch.Consume(c.cfg.Queue, tag, false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to consume %s: %w", c.cfg.Queue, err)
+	}
+
+	// Messages already received are handled to the end on shutdown
+	handleCtx := context.WithoutCancel(ctx)
+	pub := &channelPublisher{channel: ch}
+	var wg sync.WaitGroup
+	for range c.cfg.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d := range deliveries {
+				c.process(handleCtx, pub, d)
+			}
+		}()
+	}
+
+	var result error
+	select {
+	case <-ctx.Done():
+		if err := ch.Cancel(tag, false); err != nil {
+			slog.Warn("failed to cancel consumer", "queue", c.cfg.Queue, "error", err)
+		}
+	case amqpErr := <-closed:
+		result = amqp.ErrClosed
+		if amqpErr != nil {
+			result = amqpErr
+		}
+	}
+	wg.Wait()
+	return result
+}
+
+func consumerTag(queue string) string {
+	var id [6]byte
+	rand.Read(id[:])
+	return queue + "-" + hex.EncodeToString(id[:])
+}
+
+// matchTopic reports whether routingKey matches a topic exchange binding pattern, where
+// * stands for one word and # for zero or more.
+func matchTopic(pattern, routingKey string) bool {
+	return matchWords(strings.Split(pattern, "."), strings.Split(routingKey, "."))
+}
+
+func matchWords(pattern, words []string) bool {
+	if len(pattern) == 0 {
+		return len(words) == 0
+	}
+	if pattern[0] == "#" {
+		for i := 0; i <= len(words); i++ {
+			if matchWords(pattern[1:], words[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(words) == 0 || (pattern[0] != "*" && pattern[0] != words[0]) {
+		return false
+	}
+	return matchWords(pattern[1:], words[1:])
+}
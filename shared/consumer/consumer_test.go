@@ -0,0 +1,195 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+type acknowledger struct {
+	acked, nacked, requeued bool
+}
+
+func (a *acknowledger) Ack(uint64, bool) error { a.acked = true; return nil }
+func (a *acknowledger) Nack(_ uint64, _ bool, requeue bool) error {
+	a.nacked, a.requeued = true, requeue
+	return nil
+}
+func (a *acknowledger) Reject(_ uint64, requeue bool) error {
+	a.nacked, a.requeued = true, requeue
+	return nil
+}
+
+type published struct {
+	exchange, routingKey string
+	msg                  amqp.Publishing
+}
+
+type fakePublisher struct {
+	published []published
+	err       error
+}
+
+func (p *fakePublisher) Publish(_ context.Context, exchange, routingKey string, msg amqp.Publishing) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.published = append(p.published, published{exchange, routingKey, msg})
+	return nil
+}
+
+type recordingMetrics struct{ outcomes []Outcome }
+
+func (m *recordingMetrics) Handled(_, _ string, outcome Outcome, _ time.Duration) {
+	m.outcomes = append(m.outcomes, outcome)
+}
+
+func newConsumer(handler Handler) (*Consumer, *recordingMetrics) {
+	metrics := &recordingMetrics{}
+	c := New(nil, Config{Exchange: "orders", Queue: "notifications.orders", MaxAttempts: 3, Metrics: metrics})
+	c.Handle("order.paid", handler)
+	return c, metrics
+}
+
+func delivery(ack *acknowledger, routingKey string, headers amqp.Table) amqp.Delivery {
+	return amqp.Delivery{
+		Acknowledger: ack,
+		Exchange:     "orders",
+		RoutingKey:   routingKey,
+		Headers:      headers,
+		MessageId:    "m1",
+		Body:         []byte(`{"order_id":"o1"}`),
+	}
+}
+
+func TestProcessAcksHandledMessage(t *testing.T) {
+	c, metrics := newConsumer(func(context.Context, Message) error { return nil })
+	ack, pub := &acknowledger{}, &fakePublisher{}
+
+	c.process(context.Background(), pub, delivery(ack, "order.paid", nil))
+
+	if !ack.acked || len(pub.published) != 0 || metrics.outcomes[0] != Acked {
+		t.Errorf("acked = %v, published = %v, outcomes = %v", ack.acked, pub.published, metrics.outcomes)
+	}
+}
+
+func TestProcessRetriesFailedMessage(t *testing.T) {
+	var got Message
+	c, _ := newConsumer(func(_ context.Context, msg Message) error {
+		got = msg
+		return errors.New("content-services unavailable")
+	})
+	ack, pub := &acknowledger{}, &fakePublisher{}
+
+	// Second delivery, coming back from the first retry queue
+	c.process(context.Background(), pub, delivery(ack, "notifications.orders", amqp.Table{
+		HeaderAttempts:   int32(1),
+		HeaderRoutingKey: "order.paid",
+		HeaderExchange:   "orders",
+	}))
+
+	if got.Attempt != 2 || got.RoutingKey != "order.paid" {
+		t.Errorf("handler got attempt %d, routing key %q", got.Attempt, got.RoutingKey)
+	}
+	if !ack.acked || len(pub.published) != 1 {
+		t.Fatalf("acked = %v, published = %v", ack.acked, pub.published)
+	}
+	p := pub.published[0]
+	if p.exchange != "" || p.routingKey != "notifications.orders.retry.2s" {
+		t.Errorf("retried to %q %q", p.exchange, p.routingKey)
+	}
+	if p.msg.Headers[HeaderAttempts] != int32(2) || p.msg.Headers[HeaderError] != "content-services unavailable" || string(p.msg.Body) != `{"order_id":"o1"}` {
+		t.Errorf("retried message = %+v", p.msg)
+	}
+}
+
+func TestProcessDeadLetters(t *testing.T) {
+	cases := []struct {
+		name       string
+		routingKey string
+		attempts   int32
+		err        error
+	}{
+		{"attempts exhausted", "order.paid", 2, errors.New("timeout")},
+		{"permanent error", "order.paid", 0, Permanent(errors.New("invalid payload"))},
+		{"no handler", "order.refunded", 0, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, metrics := newConsumer(func(context.Context, Message) error { return tc.err })
+			ack, pub := &acknowledger{}, &fakePublisher{}
+
+			c.process(context.Background(), pub, delivery(ack, tc.routingKey, amqp.Table{HeaderAttempts: tc.attempts}))
+
+			if !ack.acked || len(pub.published) != 1 || metrics.outcomes[0] != DeadLettered {
+				t.Fatalf("acked = %v, published = %v, outcomes = %v", ack.acked, pub.published, metrics.outcomes)
+			}
+			if p := pub.published[0]; p.exchange != "notifications.orders.dlx" || p.routingKey != tc.routingKey {
+				t.Errorf("dead-lettered to %q %q", p.exchange, p.routingKey)
+			}
+		})
+	}
+}
+
+func TestProcessRecoversPanic(t *testing.T) {
+	c, metrics := newConsumer(func(context.Context, Message) error { panic("nil map") })
+	ack, pub := &acknowledger{}, &fakePublisher{}
+
+	c.process(context.Background(), pub, delivery(ack, "order.paid", nil))
+
+	if metrics.outcomes[0] != Retried || pub.published[0].msg.Headers[HeaderError] != "handler panicked: nil map" {
+		t.Errorf("outcomes = %v, published = %v", metrics.outcomes, pub.published)
+	}
+}
+
+func TestProcessRequeuesWhenRetryCannotBePublished(t *testing.T) {
+	c, metrics := newConsumer(func(context.Context, Message) error { return errors.New("timeout") })
+	ack, pub := &acknowledger{}, &fakePublisher{err: amqp.ErrClosed}
+
+	c.process(context.Background(), pub, delivery(ack, "order.paid", nil))
+
+	if ack.acked || !ack.requeued || metrics.outcomes[0] != Requeued {
+		t.Errorf("ack = %+v, outcomes = %v", ack, metrics.outcomes)
+	}
+}
+
+func TestHandlerRouting(t *testing.T) {
+	c := New(nil, Config{Queue: "q"})
+	var called string
+	c.Handle("order.#", func(context.Context, Message) error { called = "order.#"; return nil })
+	c.Handle("order.paid", func(context.Context, Message) error { called = "order.paid"; return nil })
+	c.Handle("*.created", func(context.Context, Message) error { called = "*.created"; return nil })
+
+	for key, want := range map[string]string{
+		"order.paid":          "order.paid",
+		"order.item.removed":  "order.#",
+		"order":               "order.#",
+		"course.created":      "*.created",
+		"course.item.created": "",
+	} {
+		called = ""
+		if h := c.handler(key); h != nil {
+			h(context.Background(), Message{})
+		}
+		if called != want {
+			t.Errorf("%s handled by %q, want %q", key, called, want)
+		}
+	}
+}
+
+func TestRetryDelays(t *testing.T) {
+	c := New(nil, Config{Queue: "q", MaxAttempts: 6, BackoffBase: time.Second, BackoffMax: 5 * time.Second})
+	got := c.retryDelays()
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second}
+	if len(got) != len(want) {
+		t.Fatalf("delays = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("delays = %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package consumer
+
+import "time"
+
+// Outcome is what became of a handled message.
+type Outcome string
+
+const (
+	Acked        Outcome = "acked"
+	Retried      Outcome = "retried"
+	DeadLettered Outcome = "dead_lettered"
+	// Requeued is a message put back on its queue because it could not be moved to a
+	// retry queue
+	Requeued Outcome = "requeued"
+)
+
+// Metrics is notified of every handled message, so each service can export the counters
+// in its own metrics registry.
+type Metrics interface {
+	Handled(queue, routingKey string, outcome Outcome, duration time.Duration)
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) Handled(string, string, Outcome, time.Duration) {}
@@ -0,0 +1,182 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/telemetry"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Headers the consumer sets on the messages it retries or dead-letters.
+const (
+	// HeaderAttempts counts the failed attempts of a message
+	HeaderAttempts = "x-attempts"
+	// HeaderRoutingKey and HeaderExchange keep the original route of a message
+	HeaderRoutingKey = "x-original-routing-key"
+	HeaderExchange   = "x-original-exchange"
+	// HeaderError is the error of the last attempt
+	HeaderError = "x-last-error"
+)
+
+// confirmTimeout bounds the wait for the broker to confirm a retried or dead-lettered
+// message.
+const confirmTimeout = 5 * time.Second
+
+// publisher moves a message to a retry queue or the dead-letter exchange.
+type publisher interface {
+	Publish(ctx context.Context, exchange, routingKey string, msg amqp.Publishing) error
+}
+
+// channelPublisher publishes on the consuming channel, which is in confirm mode.
+type channelPublisher struct {
+	channel *amqp.Channel
+}
+
+func (p *channelPublisher) Publish(ctx context.Context, exchange, routingKey string, msg amqp.Publishing) error {
+	confirmation, err := p.channel.PublishWithDeferredConfirmWithContext(ctx, exchange, routingKey, false, false, msg)
+	if err != nil {
+		return err
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, confirmTimeout)
+	defer cancel()
+	acked, err := confirmation.WaitContext(waitCtx)
+	if err != nil {
+		return err
+	}
+	if !acked {
+		return fmt.Errorf("nacked by the broker")
+	}
+	return nil
+}
+
+// message restores the original route and attempt of a delivery.
+func message(d amqp.Delivery) Message {
+	msg := Message{Delivery: d, Attempt: 1}
+	if key, ok := d.Headers[HeaderRoutingKey].(string); ok {
+		msg.RoutingKey = key
+	}
+	if exchange, ok := d.Headers[HeaderExchange].(string); ok {
+		msg.Exchange = exchange
+	}
+	switch n := d.Headers[HeaderAttempts].(type) {
+	case int32:
+		msg.Attempt += int(n)
+	case int64:
+		msg.Attempt += int(n)
+	case int:
+		msg.Attempt += n
+	}
+	return msg
+}
+
+func (c *Consumer) process(ctx context.Context, pub publisher, d amqp.Delivery) {
+	start := time.Now()
+	msg := message(d)
+
+	ctx = telemetry.ExtractHeaders(ctx, d.Headers)
+	ctx, span := telemetry.Start(ctx, c.cfg.Queue+" process", trace.SpanKindConsumer)
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("messaging.system", "rabbitmq"),
+		attribute.String("messaging.destination.name", c.cfg.Queue),
+		attribute.String("messaging.rabbitmq.destination.routing_key", msg.RoutingKey),
+		attribute.String("messaging.message.id", msg.MessageId),
+		attribute.Int("messaging.attempt", msg.Attempt),
+	)
+
+	var err error
+	if handler := c.handler(msg.RoutingKey); handler != nil {
+		err = call(ctx, handler, msg)
+	} else {
+		err = Permanent(fmt.Errorf("%w %q", ErrNoHandler, msg.RoutingKey))
+	}
+	telemetry.SetError(span, err)
+
+	outcome := c.settle(ctx, pub, msg, err)
+	span.SetAttributes(attribute.String("messaging.outcome", string(outcome)))
+	c.cfg.Metrics.Handled(c.cfg.Queue, msg.RoutingKey, outcome, time.Since(start))
+}
+
+func call(ctx context.Context, handler Handler, msg Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.ErrorContext(ctx, "message handler panicked", "routing_key", msg.RoutingKey, "panic", r, "stack", string(debug.Stack()))
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return handler(ctx, msg)
+}
+
+func (c *Consumer) settle(ctx context.Context, pub publisher, msg Message, handleErr error) Outcome {
+	if handleErr == nil {
+		if err := msg.Ack(false); err != nil {
+			slog.WarnContext(ctx, "failed to ack message", "queue", c.cfg.Queue, "error", err)
+		}
+		return Acked
+	}
+
+	if !IsPermanent(handleErr) && msg.Attempt < c.cfg.MaxAttempts {
+		delay := Backoff(msg.Attempt, c.cfg.BackoffBase, c.cfg.BackoffMax)
+		slog.WarnContext(ctx, "failed to handle message, retrying", "queue", c.cfg.Queue, "routing_key", msg.RoutingKey, "attempt", msg.Attempt, "max_attempts", c.cfg.MaxAttempts, "delay", delay, "error", handleErr)
+		if err := pub.Publish(ctx, "", RetryQueue(c.cfg.Queue, delay), republish(msg, handleErr)); err != nil {
+			// Redelivered at once rather than after the delay, but not lost
+			slog.ErrorContext(ctx, "failed to schedule retry, requeueing message", "queue", c.cfg.Queue, "error", err)
+			if err := msg.Nack(false, true); err != nil {
+				slog.WarnContext(ctx, "failed to nack message", "queue", c.cfg.Queue, "error", err)
+			}
+			return Requeued
+		}
+		if err := msg.Ack(false); err != nil {
+			slog.WarnContext(ctx, "failed to ack message", "queue", c.cfg.Queue, "error", err)
+		}
+		return Retried
+	}
+
+	slog.ErrorContext(ctx, "failed to handle message, dead-lettering", "queue", c.cfg.Queue, "routing_key", msg.RoutingKey, "attempt", msg.Attempt, "permanent", IsPermanent(handleErr), "error", handleErr)
+	if err := pub.Publish(ctx, DeadLetterExchange(c.cfg.Queue), msg.RoutingKey, republish(msg, handleErr)); err != nil {
+		// The queue dead-letters rejected messages itself, without the error headers
+		slog.ErrorContext(ctx, "failed to publish dead letter, rejecting message", "queue", c.cfg.Queue, "error", err)
+		if err := msg.Nack(false, false); err != nil {
+			slog.WarnContext(ctx, "failed to nack message", "queue", c.cfg.Queue, "error", err)
+		}
+		return DeadLettered
+	}
+	if err := msg.Ack(false); err != nil {
+		slog.WarnContext(ctx, "failed to ack message", "queue", c.cfg.Queue, "error", err)
+	}
+	return DeadLettered
+}
+
+// republish copies msg for a retry queue or the dead-letter exchange, recording the
+// failed attempt.
+func republish(msg Message, handleErr error) amqp.Publishing {
+	headers := make(amqp.Table, len(msg.Headers)+4)
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	headers[HeaderAttempts] = int32(msg.Attempt)
+	headers[HeaderRoutingKey] = msg.RoutingKey
+	headers[HeaderExchange] = msg.Exchange
+	headers[HeaderError] = handleErr.Error()
+
+	return amqp.Publishing{
+		Headers:         headers,
+		ContentType:     msg.ContentType,
+		ContentEncoding: msg.ContentEncoding,
+		DeliveryMode:    amqp.Persistent,
+		Priority:        msg.Priority,
+		CorrelationId:   msg.CorrelationId,
+		ReplyTo:         msg.ReplyTo,
+		MessageId:       msg.MessageId,
+		Timestamp:       msg.Timestamp,
+		Type:            msg.Type,
+		AppId:           msg.AppId,
+		Body:            msg.Body,
+	}
+}
@@ -0,0 +1,92 @@
+package consumer
+
+import (
+	"fmt"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// DeadLetterExchange is the fanout exchange the messages of queue are dead-lettered to.
+func DeadLetterExchange(queue string) string { return queue + ".dlx" }
+
+// DeadLetterQueue is the queue bound to DeadLetterExchange, where dead-lettered messages
+// wait to be inspected or moved back.
+func DeadLetterQueue(queue string) string { return queue + ".dlq" }
+
+// RetryQueue is the queue holding the messages of queue retried after delay. Its
+// messages expire after delay and go back to queue.
+func RetryQueue(queue string, delay time.Duration) string {
+	return fmt.Sprintf("%s.retry.%s", queue, delay)
+}
+
+// retryDelays lists the distinct delays of the retries a message can get.
+func (c *Consumer) retryDelays() []time.Duration {
+	var delays []time.Duration
+	for attempt := 1; attempt < c.cfg.MaxAttempts; attempt++ {
+		delay := Backoff(attempt, c.cfg.BackoffBase, c.cfg.BackoffMax)
+		if len(delays) == 0 || delays[len(delays)-1] != delay {
+			delays = append(delays, delay)
+		}
+	}
+	return delays
+}
+
+// Declare declares the queue, its bindings, retry queues and dead-letter queue. Run calls
+// it on every channel it opens; declaring is idempotent.
+//
+// The queue is declared with the dead-letter exchange as an argument, which RabbitMQ
+// refuses for a queue that already exists without it: such a queue has to be deleted
+// (or moved with a policy) first.
+func (c *Consumer) Declare(ch *amqp.Channel) error {
+	queue := c.cfg.Queue
+	dlx := DeadLetterExchange(queue)
+
+	if err := ch.ExchangeDeclare(dlx, "fanout", true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare exchange %s: %w", dlx, err)
+	}
+	if _, err := ch.QueueDeclare(DeadLetterQueue(queue), true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare queue %s: %w", DeadLetterQueue(queue), err)
+	}
+	if err := ch.QueueBind(DeadLetterQueue(queue), "", dlx, false, nil); err != nil {
+		return fmt.Errorf("failed to bind queue %s: %w", DeadLetterQueue(queue), err)
+	}
+
+	if _, err := ch.QueueDeclare(queue, true, false, false, false, amqp.Table{
+		"x-dead-letter-exchange": dlx,
+	}); err != nil {
+		return fmt.Errorf("failed to declare queue %s: %w", queue, err)
+	}
+	if c.cfg.Exchange != "" {
+		if err := ch.ExchangeDeclare(c.cfg.Exchange, "topic", true, false, false, false, nil); err != nil {
+			return fmt.Errorf("failed to declare exchange %s: %w", c.cfg.Exchange, err)
+		}
+		for _, r := range c.routes {
+			if err := ch.QueueBind(queue, r.pattern, c.cfg.Exchange, false, nil); err != nil {
+				return fmt.Errorf("failed to bind queue %s to %s: %w", queue, r.pattern, err)
+			}
+		}
+	}
+
+	for _, delay := range c.retryDelays() {
+		name := RetryQueue(queue, delay)
+		if _, err := ch.QueueDeclare(name, true, false, false, false, amqp.Table{
+			"x-message-ttl":             delay.Milliseconds(),
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": queue,
+		}); err != nil {
+			return fmt.Errorf("failed to declare queue %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Backoff is the delay before the next attempt after the given number of failed ones:
+// base, doubling with every failure, capped at ceiling.
+func Backoff(attempts int, base, ceiling time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < ceiling; i++ {
+		delay *= 2
+	}
+	return min(delay, ceiling)
+}
@@ -0,0 +1,219 @@
+// Package dbreplica sends the heavy read-only queries of a service, such as listings,
+// stats and exports, to PostgreSQL read replicas, while writes and every other read stay
+// on the primary. The pool is a GORM plugin of the primary connection, so repositories
+// opt in per query without another dependency:
+//
+//	pool := dbreplica.New(replicas, dbreplica.Config{MaxLag: 30 * time.Second})
+//	primary.Use(pool)
+//
+//	dbreplica.Read(r.db.WithContext(ctx)).Where("user_id = ?", userID).Find(&orders)
+//
+// Read keeps the query on the primary inside a transaction, for a context marked with
+// Primary, and when no replica is healthy, so a service without replicas behaves as
+// before.
+package dbreplica
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Name is the name of the pool among the GORM plugins.
+const Name = "dbreplica"
+
+// DefaultCheckInterval is how often Run checks the replicas when Config leaves it unset.
+const DefaultCheckInterval = 15 * time.Second
+
+// lagQuery returns how far a replica is behind its primary, in seconds: 0 when it has
+// replayed everything it received, as an idle primary writes no new transactions.
+const lagQuery = `SELECT CASE
+	WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+END`
+
+// Config configures a Pool.
+type Config struct {
+	// MaxLag is how far behind the primary a replica may be and still serve reads; a
+	// lagging replica serves again once it catches up. 0 accepts any lag.
+	MaxLag time.Duration
+	// CheckInterval is how often Run checks the replicas, DefaultCheckInterval when 0.
+	CheckInterval time.Duration
+}
+
+// Replica is a connection to a read replica, opened with the same GORM configuration
+// and plugins as the primary.
+type Replica struct {
+	// Name identifies the replica in logs, such as its host.
+	Name string
+	DB   *gorm.DB
+}
+
+// Pool spreads reads over the healthy replicas in turn.
+type Pool struct {
+	cfg      Config
+	replicas []*replica
+	next     atomic.Uint64
+	// lag measures the lag of a replica; lagQuery outside tests.
+	lag func(ctx context.Context, db *gorm.DB) (time.Duration, error)
+}
+
+type replica struct {
+	Replica
+	healthy atomic.Bool
+	// reason is why the replica is unhealthy, to log changes only; used by Check alone.
+	reason string
+}
+
+// New returns a pool of replicas. They serve reads from the start, until a check finds
+// them down or lagging.
+func New(replicas []Replica, cfg Config) *Pool {
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = DefaultCheckInterval
+	}
+	p := &Pool{cfg: cfg, lag: queryLag}
+	for _, r := range replicas {
+		rep := &replica{Replica: r}
+		rep.healthy.Store(true)
+		p.replicas = append(p.replicas, rep)
+	}
+	return p
+}
+
+// Name implements gorm.Plugin.
+func (p *Pool) Name() string { return Name }
+
+// Initialize implements gorm.Plugin; the pool only needs to be found by Read.
+func (p *Pool) Initialize(*gorm.DB) error { return nil }
+
+// Healthy returns how many replicas serve reads.
+func (p *Pool) Healthy() int {
+	n := 0
+	for _, r := range p.replicas {
+		if r.healthy.Load() {
+			n++
+		}
+	}
+	return n
+}
+
+// Check measures every replica once, taking those down or lagging more than MaxLag out
+// of the rotation and putting back those recovered. Changes are logged.
+func (p *Pool) Check(ctx context.Context) {
+	for _, r := range p.replicas {
+		lag, err := p.lag(ctx, r.DB)
+		reason := ""
+		switch {
+		case err != nil:
+			reason = err.Error()
+		case p.cfg.MaxLag > 0 && lag > p.cfg.MaxLag:
+			reason = "lagging " + lag.Round(time.Second).String()
+		}
+		if reason == r.reason {
+			continue
+		}
+		if reason == "" {
+			slog.Info("read replica serving reads again", "replica", r.Name)
+		} else if r.reason == "" {
+			slog.Warn("read replica out of rotation, reads fall back", "replica", r.Name, "reason", reason)
+		}
+		r.reason = reason
+		r.healthy.Store(reason == "")
+	}
+}
+
+// Run checks the replicas every CheckInterval until ctx is done.
+func (p *Pool) Run(ctx context.Context) {
+	if len(p.replicas) == 0 {
+		<-ctx.Done()
+		return
+	}
+	ticker := time.NewTicker(p.cfg.CheckInterval)
+	defer ticker.Stop()
+	for {
+		p.Check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close closes the connections of the replicas.
+func (p *Pool) Close() error {
+	var first error
+	for _, r := range p.replicas {
+		sqlDB, err := r.DB.DB()
+		if err == nil {
+			err = sqlDB.Close()
+		}
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// pick returns the next healthy replica, or nil when there is none.
+func (p *Pool) pick() *replica {
+	n := uint64(len(p.replicas))
+	if n == 0 {
+		return nil
+	}
+	start := p.next.Add(1)
+	for i := range n {
+		if r := p.replicas[(start+i)%n]; r.healthy.Load() {
+			return r
+		}
+	}
+	return nil
+}
+
+type primaryKey struct{}
+
+// Primary marks ctx so Read keeps its queries on the primary, for a read that must see
+// the writes just made, such as the listing returned after an update.
+func Primary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// Read returns a session on a healthy replica for the read-only query about to be built
+// on db, with the context of db. It returns db itself when the primary has no pool, db
+// is in a transaction, its context is marked with Primary, or no replica is healthy.
+//
+// Call Read before adding conditions: the replica session starts a new statement.
+func Read(db *gorm.DB) *gorm.DB {
+	pool, ok := db.Config.Plugins[Name].(*Pool)
+	if !ok {
+		return db
+	}
+	if _, inTx := db.Statement.ConnPool.(gorm.TxCommitter); inTx {
+		return db
+	}
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if primary, _ := ctx.Value(primaryKey{}).(bool); primary {
+		return db
+	}
+	r := pool.pick()
+	if r == nil {
+		return db
+	}
+	return r.DB.WithContext(ctx)
+}
+
+func queryLag(ctx context.Context, db *gorm.DB) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	var seconds float64
+	if err := db.WithContext(ctx).Raw(lagQuery).Scan(&seconds).Error; err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
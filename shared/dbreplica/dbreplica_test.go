@@ -0,0 +1,136 @@
+package dbreplica
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/migrator"
+	"gorm.io/gorm/schema"
+)
+
+// dryRun is a dialector building statements without a database. Sessions copy the
+// configuration of their connection, so the tests tell connections apart by db.
+type dryRun struct{ db string }
+
+func (dryRun) Name() string { return "dryrun" }
+
+func (dryRun) Initialize(db *gorm.DB) error {
+	callbacks.RegisterDefaultCallbacks(db, &callbacks.Config{})
+	return nil
+}
+
+func (dryRun) Migrator(db *gorm.DB) gorm.Migrator {
+	return migrator.Migrator{Config: migrator.Config{DB: db}}
+}
+
+func (dryRun) DataTypeOf(*schema.Field) string                     { return "" }
+func (dryRun) DefaultValueOf(*schema.Field) clause.Expression      { return clause.Expr{} }
+func (dryRun) BindVarTo(w clause.Writer, _ *gorm.Statement, _ any) { w.WriteByte('?') }
+func (dryRun) QuoteTo(w clause.Writer, s string)                   { w.WriteString(s) }
+func (dryRun) Explain(sql string, _ ...any) string                 { return sql }
+
+// tx stands for the connection of a transaction.
+type tx struct{ gorm.ConnPool }
+
+func (tx) Commit() error   { return nil }
+func (tx) Rollback() error { return nil }
+
+func open(t *testing.T, name string) *gorm.DB {
+	db, err := gorm.Open(dryRun{db: name}, &gorm.Config{DryRun: true, Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// conn returns the name of the connection of a session.
+func conn(db *gorm.DB) string {
+	return db.Dialector.(dryRun).db
+}
+
+// setup returns a primary with a pool of two replicas, whose lag is set by lags.
+func setup(t *testing.T, cfg Config) (primary *gorm.DB, replicas []*gorm.DB, pool *Pool, lags map[*gorm.DB]error) {
+	primary = open(t, "primary")
+	replicas = []*gorm.DB{open(t, "r1"), open(t, "r2")}
+	pool = New([]Replica{{Name: "r1", DB: replicas[0]}, {Name: "r2", DB: replicas[1]}}, cfg)
+	lags = map[*gorm.DB]error{}
+	pool.lag = func(_ context.Context, db *gorm.DB) (time.Duration, error) {
+		if err, ok := lags[db]; ok && err != nil {
+			return 0, err
+		}
+		if _, ok := lags[db]; ok {
+			return time.Minute, nil
+		}
+		return 0, nil
+	}
+	if err := primary.Use(pool); err != nil {
+		t.Fatal(err)
+	}
+	return primary, replicas, pool, lags
+}
+
+func TestRead(t *testing.T) {
+	primary, _, _, _ := setup(t, Config{})
+	ctx := context.WithValue(context.Background(), primaryKey{}, false)
+
+	seen := map[string]int{}
+	for range 4 {
+		db := Read(primary.WithContext(ctx))
+		if db.Statement.Context != ctx {
+			t.Fatal("the replica session lost the context")
+		}
+		seen[conn(db)]++
+	}
+	if seen["r1"] != 2 || seen["r2"] != 2 {
+		t.Errorf("reads were not spread over both replicas: %v", seen)
+	}
+
+	if db := Read(primary.WithContext(Primary(ctx))); conn(db) != "primary" {
+		t.Error("a context marked Primary read from a replica")
+	}
+	inTx := primary.Session(&gorm.Session{})
+	inTx.Statement.ConnPool = tx{}
+	if db := Read(inTx); conn(db) != "primary" {
+		t.Error("a transaction read from a replica")
+	}
+
+	alone := open(t, "alone")
+	if db := Read(alone); db != alone {
+		t.Error("a primary without a pool did not read from itself")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	primary, replicas, pool, lags := setup(t, Config{MaxLag: 30 * time.Second})
+
+	lags[replicas[0]] = errors.New("connection refused")
+	lags[replicas[1]] = nil // lagging a minute
+	pool.Check(context.Background())
+	if n := pool.Healthy(); n != 0 {
+		t.Fatalf("%d healthy replicas, want 0", n)
+	}
+	if db := Read(primary); conn(db) != "primary" {
+		t.Error("no healthy replica: the read did not fall back to the primary")
+	}
+
+	delete(lags, replicas[1])
+	pool.Check(context.Background())
+	for range 3 {
+		if db := Read(primary); conn(db) != "r2" {
+			t.Fatal("the read did not go to the replica that caught up")
+		}
+	}
+
+	pool.cfg.MaxLag = 0
+	lags[replicas[0]] = nil
+	pool.Check(context.Background())
+	if n := pool.Healthy(); n != 2 {
+		t.Errorf("%d healthy replicas without a lag limit, want 2", n)
+	}
+}
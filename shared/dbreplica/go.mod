@@ -0,0 +1,11 @@
+module github.com/ductan2/microservice-app/shared/dbreplica
+
+go 1.24.0
+
+require gorm.io/gorm v1.31.0
+
+require (
+	github.com/jinzhu/inflection v1.0.0 // indirect
+	github.com/jinzhu/now v1.1.5 // indirect
+	golang.org/x/text v0.27.0 // indirect
+)
@@ -0,0 +1,70 @@
+package envconfig
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+
+	"github.com/joho/godotenv"
+)
+
+// dotenv remembers the variables set from .env files, so a reload can change or remove
+// them without touching the ones the process was started with.
+var dotenv = struct {
+	sync.Mutex
+	set  map[string]string
+	file map[string]string // the file each variable was set from
+}{set: make(map[string]string), file: make(map[string]string)}
+
+func loadDotEnv(files []string) error {
+	if len(files) == 0 {
+		var err error
+		if files, err = ProfileFiles(Profile()); err != nil {
+			return err
+		}
+	}
+	values := make(map[string]string)
+	from := make(map[string]string)
+	for _, file := range files {
+		fileValues, err := godotenv.Read(file)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		// The first file setting a variable wins, as with godotenv.Load
+		for key, value := range fileValues {
+			if _, ok := values[key]; !ok {
+				values[key] = value
+				from[key] = file
+			}
+		}
+	}
+
+	dotenv.Lock()
+	defer dotenv.Unlock()
+	for key, value := range values {
+		if current, ok := os.LookupEnv(key); ok {
+			if previous, fromFile := dotenv.set[key]; !fromFile || current != previous {
+				continue
+			}
+		}
+		os.Setenv(key, value)
+		dotenv.set[key] = value
+		dotenv.file[key] = from[key]
+	}
+	for key, previous := range dotenv.set {
+		if _, ok := values[key]; ok {
+			continue
+		}
+		if os.Getenv(key) == previous {
+			os.Unsetenv(key)
+		}
+		delete(dotenv.set, key)
+		delete(dotenv.file, key)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+// Package envconfig loads the configuration of the Go services from the environment
+// into typed structs.
+//
+// Fields are described with struct tags:
+//
+//	Port      string        `env:"PORT" envDefault:"8001"`
+//	DBUser    string        `env:"DB_USER,required"`
+//	JWTSecret string        `env:"JWT_SECRET,secret"`
+//	Timeout   time.Duration `env:"SERVER_READ_TIMEOUT" envDefault:"15s"`
+//
+// An unset or empty variable takes the envDefault value; a field with neither keeps the
+// value it had, so defaults can also be set in code. Nested structs without an env tag
+// are loaded field by field; env:"-" skips a field. Supported types are strings, bools,
+// integers, floats, time.Duration, comma separated []string and encoding.TextUnmarshaler
+// implementations such as slog.Level.
+//
+// A required field left empty or a value that does not parse is an error, and Load
+// reports all of them at once instead of falling back to the default. Secret fields are
+// hidden by Redacted, which is what services log.
+//
+// The value of a secret field may be a reference to a secrets manager, such as
+// secret://order-services/stripe#secret_key, resolved by the source set with UseSecrets.
+package envconfig
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrRequired is wrapped by the errors of required fields left empty.
+var ErrRequired = errors.New("is required")
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// spec is a field described by an env tag.
+type spec struct {
+	name     string
+	def      string
+	hasDef   bool
+	required bool
+	secret   bool
+}
+
+// Load reads the .env files (".env" when none are given; missing files are skipped)
+// into the environment, then fills the struct dst points to. Variables set by the
+// process environment win over the files.
+func Load(dst any, files ...string) error {
+	if err := loadDotEnv(files); err != nil {
+		return err
+	}
+	return Process(dst)
+}
+
+// Process fills the struct dst points to from the environment.
+func Process(dst any) error {
+	return ProcessPrefix("", dst)
+}
+
+// ProcessPrefix fills the struct dst points to from the variables named prefix followed
+// by the names of its tags, for settings repeated per instance: with the prefix
+// "RELAY_ORDERS_", env:"DATABASE_URL" reads RELAY_ORDERS_DATABASE_URL.
+func ProcessPrefix(prefix string, dst any) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.New("envconfig: dst must be a pointer to a struct")
+	}
+
+	var errs []error
+	walk(v.Elem(), func(field reflect.Value, s spec) {
+		s.name = prefix + s.name
+		value := os.Getenv(s.name)
+		if value == "" {
+			value = s.def
+		}
+		if value == "" {
+			if s.required && field.IsZero() {
+				errs = append(errs, fmt.Errorf("%s %w", s.name, ErrRequired))
+			}
+			return
+		}
+		if strings.HasPrefix(value, SecretPrefix) {
+			if !s.secret {
+				errs = append(errs, fmt.Errorf("invalid %s: only secret fields may reference a secret", s.name))
+				return
+			}
+			resolved, err := resolveSecret(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", s.name, err))
+				return
+			}
+			value = resolved
+		}
+		if err := set(field, value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s: %w", s.name, err))
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// walk calls fn for every field of v with an env tag, descending into nested structs.
+func walk(v reflect.Value, fn func(field reflect.Value, s spec)) {
+	t := v.Type()
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag, ok := sf.Tag.Lookup("env")
+		if tag == "-" {
+			continue
+		}
+		field := v.Field(i)
+		if !ok || tag == "" {
+			if field.Kind() == reflect.Struct && !isScalar(field) {
+				walk(field, fn)
+			}
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		s := spec{name: parts[0]}
+		s.def, s.hasDef = sf.Tag.Lookup("envDefault")
+		for _, opt := range parts[1:] {
+			switch opt {
+			case "required":
+				s.required = true
+			case "secret":
+				s.secret = true
+			}
+		}
+		fn(field, s)
+	}
+}
+
+// isScalar reports whether a struct value is set from a single variable.
+func isScalar(v reflect.Value) bool {
+	return v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType)
+}
+
+func set(field reflect.Value, value string) error {
+	if field.CanAddr() {
+		if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(value))
+		}
+	}
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", field.Type())
+		}
+		items := splitList(value)
+		if len(items) == 0 {
+			return nil
+		}
+		list := reflect.MakeSlice(field.Type(), len(items), len(items))
+		for i, item := range items {
+			list.Index(i).SetString(item)
+		}
+		field.Set(list)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// splitList splits a comma separated list, dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,190 @@
+package envconfig
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type database struct {
+	User     string `env:"TEST_DB_USER,required"`
+	Password string `env:"TEST_DB_PASSWORD,secret"`
+	MaxConns int    `env:"TEST_DB_MAX_CONNS" envDefault:"25"`
+}
+
+type testConfig struct {
+	Port     string        `env:"TEST_PORT" envDefault:"8001"`
+	Timeout  time.Duration `env:"TEST_TIMEOUT" envDefault:"15s"`
+	Debug    bool          `env:"TEST_DEBUG"`
+	Ratio    float64       `env:"TEST_RATIO" envDefault:"0.5"`
+	Origins  []string      `env:"TEST_ORIGINS" envDefault:"http://localhost:3000"`
+	Level    slog.Level    `env:"TEST_LEVEL" envDefault:"info"`
+	Database database
+	Derived  string
+	Skipped  string `env:"-"`
+}
+
+func TestProcess(t *testing.T) {
+	t.Setenv("TEST_DB_USER", "user")
+	t.Setenv("TEST_DB_PASSWORD", "hunter2")
+	t.Setenv("TEST_TIMEOUT", "1m")
+	t.Setenv("TEST_ORIGINS", "https://a.example, ,https://b.example")
+	t.Setenv("TEST_LEVEL", "debug")
+	t.Setenv("TEST_PORT", "")
+
+	cfg := testConfig{Debug: true}
+	if err := Process(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	want := testConfig{
+		Port:     "8001",
+		Timeout:  time.Minute,
+		Debug:    true,
+		Ratio:    0.5,
+		Origins:  []string{"https://a.example", "https://b.example"},
+		Level:    slog.LevelDebug,
+		Database: database{User: "user", Password: "hunter2", MaxConns: 25},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("cfg = %+v\nwant  %+v", cfg, want)
+	}
+}
+
+func TestProcessPrefix(t *testing.T) {
+	t.Setenv("ORDERS_TEST_DB_USER", "orders")
+	t.Setenv("TEST_DB_USER", "user")
+
+	var cfg database
+	if err := ProcessPrefix("ORDERS_", &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.User != "orders" || cfg.MaxConns != 25 {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	if err := ProcessPrefix("USERS_", &database{}); err == nil || !strings.Contains(err.Error(), "USERS_TEST_DB_USER") {
+		t.Errorf("missing prefixed variable: got %v", err)
+	}
+}
+
+func TestProcessReportsEveryError(t *testing.T) {
+	t.Setenv("TEST_TIMEOUT", "15")
+	t.Setenv("TEST_DB_MAX_CONNS", "many")
+
+	var cfg testConfig
+	err := Process(&cfg)
+	if !errors.Is(err, ErrRequired) {
+		t.Errorf("err = %v, want ErrRequired", err)
+	}
+	for _, name := range []string{"TEST_DB_USER", "TEST_TIMEOUT", "TEST_DB_MAX_CONNS"} {
+		if err == nil || !strings.Contains(err.Error(), name) {
+			t.Errorf("err = %v, want it to name %s", err, name)
+		}
+	}
+}
+
+func TestValuesRedactsSecrets(t *testing.T) {
+	cfg := testConfig{
+		Port:     "8001",
+		Origins:  []string{"https://a.example", "https://b.example"},
+		Level:    slog.LevelWarn,
+		Database: database{User: "user", Password: "hunter2"},
+	}
+	values := Values(&cfg)
+	if values["TEST_DB_PASSWORD"] != Redacted || values["TEST_DB_USER"] != "user" {
+		t.Errorf("values = %v", values)
+	}
+	if values["TEST_ORIGINS"] != "https://a.example,https://b.example" || values["TEST_LEVEL"] != "WARN" {
+		t.Errorf("values = %v", values)
+	}
+	if _, ok := values["Derived"]; ok {
+		t.Error("untagged field listed")
+	}
+
+	rotated := cfg
+	rotated.Database.Password = "correct horse"
+	if got := Changed(&cfg, &rotated); !reflect.DeepEqual(got, []string{"TEST_DB_PASSWORD"}) {
+		t.Errorf("Changed = %v", got)
+	}
+}
+
+type secretMap map[string]string
+
+func (m secretMap) Secret(ref string) (string, error) {
+	if v, ok := m[ref]; ok {
+		return v, nil
+	}
+	return "", errors.New("secret not found")
+}
+
+func TestProcessResolvesSecretReferences(t *testing.T) {
+	t.Setenv("TEST_DB_USER", "user")
+	t.Setenv("TEST_DB_PASSWORD", SecretPrefix+"db#password")
+
+	var cfg testConfig
+	if err := Process(&cfg); !errors.Is(err, ErrNoSecretSource) {
+		t.Errorf("without a source: %v", err)
+	}
+
+	UseSecrets(secretMap{"db#password": "hunter2"})
+	t.Cleanup(func() { UseSecrets(nil) })
+	if err := Process(&cfg); err != nil || cfg.Database.Password != "hunter2" {
+		t.Errorf("Process = %v, password %q", err, cfg.Database.Password)
+	}
+
+	// References in fields that are not secret would be logged once resolved
+	t.Setenv("TEST_PORT", SecretPrefix+"db#password")
+	if err := Process(&cfg); err == nil || !strings.Contains(err.Error(), "TEST_PORT") {
+		t.Errorf("reference in a plain field: %v", err)
+	}
+}
+
+func TestReloadReadsDotEnvAgain(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, ".env")
+	writeFile(t, file, "TEST_DB_USER=user\nTEST_PORT=9000\n")
+	t.Setenv("TEST_DB_MAX_CONNS", "50")
+	t.Cleanup(func() {
+		os.Unsetenv("TEST_DB_USER")
+		os.Unsetenv("TEST_PORT")
+	})
+
+	reloader, err := NewReloader(func() (*testConfig, error) {
+		var cfg testConfig
+		return &cfg, Load(&cfg, file)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hooked string
+	reloader.OnReload(func(old, cur *testConfig) { hooked = old.Port + "->" + cur.Port })
+
+	// The process environment wins over the file
+	writeFile(t, file, "TEST_DB_USER=user\nTEST_PORT=9100\nTEST_DB_MAX_CONNS=10\n")
+	if err := reloader.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg := reloader.Current(); cfg.Port != "9100" || cfg.Database.MaxConns != 50 || hooked != "9000->9100" {
+		t.Errorf("after reload: %+v, hook %q", cfg, hooked)
+	}
+
+	// A reload that does not validate keeps the current configuration
+	writeFile(t, file, "TEST_PORT=9200\n")
+	if err := reloader.Reload(); !errors.Is(err, ErrRequired) {
+		t.Errorf("Reload = %v, want ErrRequired", err)
+	}
+	if cfg := reloader.Current(); cfg.Port != "9100" {
+		t.Errorf("configuration replaced by an invalid one: %+v", cfg)
+	}
+}
+
+func writeFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,5 @@
+module github.com/ductan2/microservice-app/shared/envconfig
+
+go 1.24.0
+
+require github.com/joho/godotenv v1.5.1
@@ -0,0 +1,52 @@
+package envconfig
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// ProfileVar names the profile of the environment a service is deployed to, such as
+// production. Load reads the .env file of the profile before .env.
+const ProfileVar = "ENVIRONMENT"
+
+var profilePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Profile returns the profile the process was started with. A profile set by a .env file
+// is ignored, since the profile picks the files.
+func Profile() string {
+	dotenv.Lock()
+	defer dotenv.Unlock()
+	profile := os.Getenv(ProfileVar)
+	if fromFile, ok := dotenv.set[ProfileVar]; ok && fromFile == profile {
+		return ""
+	}
+	return profile
+}
+
+// ProfileFiles returns the files Load reads for profile: .env.<profile> then .env, or
+// .env alone without a profile.
+func ProfileFiles(profile string) ([]string, error) {
+	if profile == "" {
+		return []string{".env"}, nil
+	}
+	if !profilePattern.MatchString(profile) {
+		return nil, fmt.Errorf("invalid %s %q", ProfileVar, profile)
+	}
+	return []string{".env." + profile, ".env"}, nil
+}
+
+// Source tells where the value of the variable name comes from: "environment" when the
+// process was started with it, the .env file that set it, or "default".
+func Source(name string) string {
+	dotenv.Lock()
+	defer dotenv.Unlock()
+	value, ok := os.LookupEnv(name)
+	if !ok || value == "" {
+		return "default"
+	}
+	if fromFile, ok := dotenv.set[name]; ok && fromFile == value {
+		return dotenv.file[name]
+	}
+	return "environment"
+}
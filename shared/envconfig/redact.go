@@ -0,0 +1,76 @@
+package envconfig
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Redacted is shown instead of the value of a secret field.
+const Redacted = "[REDACTED]"
+
+// Values returns the value of every tagged field of the struct cfg (or points to) by
+// variable name, with secrets replaced by Redacted, for logging the configuration a
+// service runs with. Empty secrets stay empty, so missing ones show.
+func Values(cfg any) map[string]string {
+	return values(cfg, true)
+}
+
+// Changed lists the variables whose values differ between two configurations of the
+// same type, sorted. Secrets are compared but not shown.
+func Changed(old, cur any) []string {
+	before := values(old, false)
+	var changed []string
+	for name, value := range values(cur, false) {
+		if before[name] != value {
+			changed = append(changed, name)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
+
+func values(cfg any, redact bool) map[string]string {
+	result := make(map[string]string)
+	v := reflect.ValueOf(cfg)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return result
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return result
+	}
+	// A copy, so that fields can be addressed for their TextMarshaler
+	addressable := reflect.New(v.Type()).Elem()
+	addressable.Set(v)
+	walk(addressable, func(field reflect.Value, s spec) {
+		value := format(field)
+		if redact && s.secret && value != "" {
+			value = Redacted
+		}
+		result[s.name] = value
+	})
+	return result
+}
+
+func format(field reflect.Value) string {
+	if field.CanAddr() {
+		if m, ok := field.Addr().Interface().(encoding.TextMarshaler); ok {
+			if text, err := m.MarshalText(); err == nil {
+				return string(text)
+			}
+		}
+	}
+	if field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String {
+		items := make([]string, field.Len())
+		for i := range items {
+			items[i] = field.Index(i).String()
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(field.Interface())
+}
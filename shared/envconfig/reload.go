@@ -0,0 +1,81 @@
+package envconfig
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+)
+
+// Reloader holds the configuration of a service and replaces it when asked to reload,
+// such as after the .env file was edited. Code reading Current sees the new values at
+// once; settings read only at startup, like ports and connection strings, need hooks or
+// a restart.
+type Reloader[T any] struct {
+	mu      sync.Mutex
+	current atomic.Pointer[T]
+	load    func() (*T, error)
+	hooks   []func(old, cur *T)
+}
+
+// NewReloader loads the configuration with load, which Reload calls again. load should
+// call Load, so that the .env files are read again, and validate the result.
+func NewReloader[T any](load func() (*T, error)) (*Reloader[T], error) {
+	cfg, err := load()
+	if err != nil {
+		return nil, err
+	}
+	r := &Reloader[T]{load: load}
+	r.current.Store(cfg)
+	return r, nil
+}
+
+// Current returns the configuration loaded last. It must not be modified.
+func (r *Reloader[T]) Current() *T {
+	return r.current.Load()
+}
+
+// OnReload registers hook to be called after every reload with the previous and the new
+// configuration.
+func (r *Reloader[T]) OnReload(hook func(old, cur *T)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks = append(r.hooks, hook)
+}
+
+// Reload loads the configuration again. When it fails to load or validate, the current
+// configuration is kept and the error returned.
+func (r *Reloader[T]) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cfg, err := r.load()
+	if err != nil {
+		return err
+	}
+	old := r.current.Swap(cfg)
+	slog.Info("configuration reloaded", "changed", Changed(old, cfg))
+	for _, hook := range r.hooks {
+		hook(old, cfg)
+	}
+	return nil
+}
+
+// WatchSignals reloads the configuration on every SIGHUP until ctx is cancelled.
+func (r *Reloader[T]) WatchSignals(ctx context.Context) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			if err := r.Reload(); err != nil {
+				slog.Error("failed to reload configuration, keeping the current one", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package envconfig
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+// SecretPrefix marks the value of a secret field as a reference to a secret kept in a
+// secrets manager, such as secret://order-services/stripe#secret_key, rather than the
+// secret itself.
+const SecretPrefix = "secret://"
+
+// ErrNoSecretSource is returned for a secret reference when no source was set with
+// UseSecrets.
+var ErrNoSecretSource = errors.New("references a secret but no secrets provider is configured")
+
+// SecretSource resolves secret references, given without SecretPrefix.
+type SecretSource interface {
+	Secret(ref string) (string, error)
+}
+
+var secretSource struct {
+	sync.RWMutex
+	src SecretSource
+}
+
+// UseSecrets makes Process resolve the secret references of secret fields with src.
+// Values of other fields are never resolved, so a secret cannot end up in the logs.
+func UseSecrets(src SecretSource) {
+	secretSource.Lock()
+	defer secretSource.Unlock()
+	secretSource.src = src
+}
+
+// resolveSecret returns the secret value references.
+func resolveSecret(value string) (string, error) {
+	secretSource.RLock()
+	src := secretSource.src
+	secretSource.RUnlock()
+	if src == nil {
+		return "", ErrNoSecretSource
+	}
+	return src.Secret(strings.TrimPrefix(value, SecretPrefix))
+}
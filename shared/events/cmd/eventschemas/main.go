@@ -0,0 +1,70 @@
+// Command eventschemas maintains the schema registry of shared/events.
+//
+//	eventschemas write               regenerate the schemas of the current versions
+//	eventschemas check -base DIR     fail on a change incompatible with the schemas in DIR
+//
+// Run it from shared/events. CI runs check against the schemas of the branch a pull
+// request is merged into.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ductan2/microservice-app/shared/events"
+)
+
+func main() {
+	fs := flag.NewFlagSet("eventschemas", flag.ExitOnError)
+	dir := fs.String("dir", "schemas", "directory of the schema registry")
+	base := fs.String("base", "", "schema directory of the base branch (check)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: eventschemas write|check [-dir schemas] [-base dir]\n")
+		fs.PrintDefaults()
+	}
+	if len(os.Args) < 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	command := os.Args[1]
+	_ = fs.Parse(os.Args[2:])
+
+	var err error
+	switch command {
+	case "write":
+		err = events.GeneratedSchemas().WriteDir(*dir)
+	case "check":
+		err = check(*dir, *base)
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func check(dir, base string) error {
+	if base == "" {
+		return errors.New("check needs -base")
+	}
+	current, err := events.LoadSchemas(os.DirFS(dir))
+	if err != nil {
+		return err
+	}
+	if stale := events.GeneratedSchemas().Diff(current); len(stale) > 0 {
+		return fmt.Errorf("%s is out of date with the event structs (%s and more): run go run ./cmd/eventschemas write", dir, stale[0].FileName())
+	}
+	previous, err := events.LoadSchemas(os.DirFS(base))
+	if err != nil {
+		return err
+	}
+	if err := current.CheckCompatible(previous); err != nil {
+		return err
+	}
+	fmt.Printf("%d schemas compatible with %s\n", len(current.Keys()), base)
+	return nil
+}
@@ -0,0 +1,406 @@
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Content events are published by content-services to the content.events exchange with
+// the event type as routing key. The events of lessons, courses and flashcard sets that
+// change what learners can find carry the searchable fields of the content, so that
+// search-services indexes it without calling content-services back.
+
+func init() {
+	register(func() Event { return &LessonCreated{} })
+	register(func() Event { return &LessonUpdated{} })
+	register(func() Event { return &LessonPublished{} })
+	register(func() Event { return &LessonUnpublished{} })
+	register(func() Event { return &LessonDeleted{} })
+	register(func() Event { return &CourseUpdated{} })
+	register(func() Event { return &CoursePublished{} })
+	register(func() Event { return &CourseUnpublished{} })
+	register(func() Event { return &CourseDeleted{} })
+	register(func() Event { return &FlashcardSetCreated{} })
+	register(func() Event { return &FlashcardSetUpdated{} })
+	register(func() Event { return &FlashcardSetDeleted{} })
+	register(func() Event { return &FlashcardSaved{} })
+	register(func() Event { return &FlashcardDeleted{} })
+	register(func() Event { return &TopicSaved{} })
+	register(func() Event { return &TopicDeleted{} })
+	register(func() Event { return &LevelSaved{} })
+	register(func() Event { return &LevelDeleted{} })
+}
+
+const contentTopic = "content.events"
+
+// LessonCreated is published when a lesson draft is created.
+type LessonCreated struct {
+	Meta
+	LessonID uuid.UUID `json:"lesson_id"`
+	Title    string    `json:"title"`
+}
+
+func (*LessonCreated) EventType() string  { return "LessonCreated" }
+func (*LessonCreated) Topic() string      { return contentTopic }
+func (*LessonCreated) SchemaVersion() int { return 1 }
+
+func (e *LessonCreated) Validate() error {
+	var v validator
+	v.id("lesson_id", e.LessonID)
+	v.text("title", e.Title)
+	return v.err(e.EventType())
+}
+
+// LessonDetails are the searchable fields of a lesson besides its title.
+type LessonDetails struct {
+	Code        string     `json:"code,omitempty"`
+	Description string     `json:"description,omitempty"`
+	TopicID     *uuid.UUID `json:"topic_id,omitempty"`
+	LevelID     *uuid.UUID `json:"level_id,omitempty"`
+}
+
+// LessonUpdated is published when the title, description, topic or level of a lesson
+// changes, published or not. PublishedAt is set while the lesson is published.
+type LessonUpdated struct {
+	Meta
+	LessonID    uuid.UUID  `json:"lesson_id"`
+	Title       string     `json:"title"`
+	IsPublished bool       `json:"is_published"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	LessonDetails
+}
+
+func (*LessonUpdated) EventType() string  { return "LessonUpdated" }
+func (*LessonUpdated) Topic() string      { return contentTopic }
+func (*LessonUpdated) SchemaVersion() int { return 1 }
+
+func (e *LessonUpdated) Validate() error {
+	var v validator
+	v.id("lesson_id", e.LessonID)
+	v.text("title", e.Title)
+	return v.err(e.EventType())
+}
+
+// LessonPublished is published when a lesson becomes visible to learners.
+type LessonPublished struct {
+	Meta
+	LessonID    uuid.UUID `json:"lesson_id"`
+	Title       string    `json:"title"`
+	PublishedAt time.Time `json:"published_at"`
+	LessonDetails
+}
+
+func (*LessonPublished) EventType() string  { return "LessonPublished" }
+func (*LessonPublished) Topic() string      { return contentTopic }
+func (*LessonPublished) SchemaVersion() int { return 1 }
+
+func (e *LessonPublished) Validate() error {
+	var v validator
+	v.id("lesson_id", e.LessonID)
+	v.text("title", e.Title)
+	v.time("published_at", e.PublishedAt)
+	return v.err(e.EventType())
+}
+
+// LessonUnpublished is published when a lesson is hidden from learners again.
+type LessonUnpublished struct {
+	Meta
+	LessonID uuid.UUID `json:"lesson_id"`
+}
+
+func (*LessonUnpublished) EventType() string  { return "LessonUnpublished" }
+func (*LessonUnpublished) Topic() string      { return contentTopic }
+func (*LessonUnpublished) SchemaVersion() int { return 1 }
+
+func (e *LessonUnpublished) Validate() error {
+	var v validator
+	v.id("lesson_id", e.LessonID)
+	return v.err(e.EventType())
+}
+
+// LessonDeleted is published when a lesson is deleted.
+type LessonDeleted struct {
+	Meta
+	LessonID uuid.UUID `json:"lesson_id"`
+}
+
+func (*LessonDeleted) EventType() string  { return "LessonDeleted" }
+func (*LessonDeleted) Topic() string      { return contentTopic }
+func (*LessonDeleted) SchemaVersion() int { return 1 }
+
+func (e *LessonDeleted) Validate() error {
+	var v validator
+	v.id("lesson_id", e.LessonID)
+	return v.err(e.EventType())
+}
+
+// CourseDetails are the searchable fields of a course besides its title.
+type CourseDetails struct {
+	Description   string     `json:"description,omitempty"`
+	TopicID       *uuid.UUID `json:"topic_id,omitempty"`
+	LevelID       *uuid.UUID `json:"level_id,omitempty"`
+	InstructorID  *uuid.UUID `json:"instructor_id,omitempty"`
+	ThumbnailURL  string     `json:"thumbnail_url,omitempty"`
+	IsFeatured    bool       `json:"is_featured,omitempty"`
+	Price         float64    `json:"price,omitempty"`
+	DurationHours int        `json:"duration_hours,omitempty"`
+	AverageRating float64    `json:"average_rating,omitempty"`
+	ReviewCount   int        `json:"review_count,omitempty"`
+}
+
+// CourseUpdated is published when a course is edited or restored, published or not.
+// PublishedAt is set while the course is published.
+type CourseUpdated struct {
+	Meta
+	CourseID    uuid.UUID  `json:"course_id"`
+	Title       string     `json:"title"`
+	IsPublished bool       `json:"is_published"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	CourseDetails
+}
+
+func (*CourseUpdated) EventType() string  { return "CourseUpdated" }
+func (*CourseUpdated) Topic() string      { return contentTopic }
+func (*CourseUpdated) SchemaVersion() int { return 1 }
+
+func (e *CourseUpdated) Validate() error {
+	var v validator
+	v.id("course_id", e.CourseID)
+	v.text("title", e.Title)
+	return v.err(e.EventType())
+}
+
+// CoursePublished is published when a course becomes visible to learners.
+type CoursePublished struct {
+	Meta
+	CourseID    uuid.UUID `json:"course_id"`
+	Title       string    `json:"title"`
+	PublishedAt time.Time `json:"published_at"`
+	CourseDetails
+}
+
+func (*CoursePublished) EventType() string  { return "CoursePublished" }
+func (*CoursePublished) Topic() string      { return contentTopic }
+func (*CoursePublished) SchemaVersion() int { return 1 }
+
+func (e *CoursePublished) Validate() error {
+	var v validator
+	v.id("course_id", e.CourseID)
+	v.text("title", e.Title)
+	v.time("published_at", e.PublishedAt)
+	return v.err(e.EventType())
+}
+
+// CourseUnpublished is published when a course is hidden from learners again.
+type CourseUnpublished struct {
+	Meta
+	CourseID uuid.UUID `json:"course_id"`
+}
+
+func (*CourseUnpublished) EventType() string  { return "CourseUnpublished" }
+func (*CourseUnpublished) Topic() string      { return contentTopic }
+func (*CourseUnpublished) SchemaVersion() int { return 1 }
+
+func (e *CourseUnpublished) Validate() error {
+	var v validator
+	v.id("course_id", e.CourseID)
+	return v.err(e.EventType())
+}
+
+// CourseDeleted is published when a course is deleted. A restored course is published
+// again as CourseUpdated.
+type CourseDeleted struct {
+	Meta
+	CourseID uuid.UUID `json:"course_id"`
+}
+
+func (*CourseDeleted) EventType() string  { return "CourseDeleted" }
+func (*CourseDeleted) Topic() string      { return contentTopic }
+func (*CourseDeleted) SchemaVersion() int { return 1 }
+
+func (e *CourseDeleted) Validate() error {
+	var v validator
+	v.id("course_id", e.CourseID)
+	return v.err(e.EventType())
+}
+
+// FlashcardSetDetails are the searchable fields of a flashcard set besides its title.
+type FlashcardSetDetails struct {
+	Description string     `json:"description,omitempty"`
+	TopicID     *uuid.UUID `json:"topic_id,omitempty"`
+	LevelID     *uuid.UUID `json:"level_id,omitempty"`
+}
+
+// FlashcardSetCreated is published when a flashcard set is created. Flashcard sets are
+// visible to learners as soon as they exist.
+type FlashcardSetCreated struct {
+	Meta
+	SetID     uuid.UUID `json:"set_id"`
+	Title     string    `json:"title"`
+	CreatedAt time.Time `json:"created_at"`
+	FlashcardSetDetails
+}
+
+func (*FlashcardSetCreated) EventType() string  { return "FlashcardSetCreated" }
+func (*FlashcardSetCreated) Topic() string      { return contentTopic }
+func (*FlashcardSetCreated) SchemaVersion() int { return 1 }
+
+func (e *FlashcardSetCreated) Validate() error {
+	var v validator
+	v.id("set_id", e.SetID)
+	v.text("title", e.Title)
+	v.time("created_at", e.CreatedAt)
+	return v.err(e.EventType())
+}
+
+// FlashcardSetUpdated is published when a flashcard set is edited.
+type FlashcardSetUpdated struct {
+	Meta
+	SetID     uuid.UUID `json:"set_id"`
+	Title     string    `json:"title"`
+	CreatedAt time.Time `json:"created_at"`
+	FlashcardSetDetails
+}
+
+func (*FlashcardSetUpdated) EventType() string  { return "FlashcardSetUpdated" }
+func (*FlashcardSetUpdated) Topic() string      { return contentTopic }
+func (*FlashcardSetUpdated) SchemaVersion() int { return 1 }
+
+func (e *FlashcardSetUpdated) Validate() error {
+	var v validator
+	v.id("set_id", e.SetID)
+	v.text("title", e.Title)
+	v.time("created_at", e.CreatedAt)
+	return v.err(e.EventType())
+}
+
+// FlashcardSetDeleted is published when a flashcard set is deleted.
+type FlashcardSetDeleted struct {
+	Meta
+	SetID uuid.UUID `json:"set_id"`
+}
+
+func (*FlashcardSetDeleted) EventType() string  { return "FlashcardSetDeleted" }
+func (*FlashcardSetDeleted) Topic() string      { return contentTopic }
+func (*FlashcardSetDeleted) SchemaVersion() int { return 1 }
+
+func (e *FlashcardSetDeleted) Validate() error {
+	var v validator
+	v.id("set_id", e.SetID)
+	return v.err(e.EventType())
+}
+
+// FlashcardSaved is published when a flashcard is added to a set or edited. The front
+// of a card is the term it teaches and the back its meaning, the glossary learners search.
+type FlashcardSaved struct {
+	Meta
+	FlashcardID uuid.UUID `json:"flashcard_id"`
+	SetID       uuid.UUID `json:"set_id"`
+	FrontText   string    `json:"front_text"`
+	BackText    string    `json:"back_text"`
+}
+
+func (*FlashcardSaved) EventType() string  { return "FlashcardSaved" }
+func (*FlashcardSaved) Topic() string      { return contentTopic }
+func (*FlashcardSaved) SchemaVersion() int { return 1 }
+
+func (e *FlashcardSaved) Validate() error {
+	var v validator
+	v.id("flashcard_id", e.FlashcardID)
+	v.id("set_id", e.SetID)
+	v.text("front_text", e.FrontText)
+	v.text("back_text", e.BackText)
+	return v.err(e.EventType())
+}
+
+// FlashcardDeleted is published when a flashcard is removed from its set. The cards of a
+// deleted set go with FlashcardSetDeleted.
+type FlashcardDeleted struct {
+	Meta
+	FlashcardID uuid.UUID `json:"flashcard_id"`
+	SetID       uuid.UUID `json:"set_id"`
+}
+
+func (*FlashcardDeleted) EventType() string  { return "FlashcardDeleted" }
+func (*FlashcardDeleted) Topic() string      { return contentTopic }
+func (*FlashcardDeleted) SchemaVersion() int { return 1 }
+
+func (e *FlashcardDeleted) Validate() error {
+	var v validator
+	v.id("flashcard_id", e.FlashcardID)
+	v.id("set_id", e.SetID)
+	return v.err(e.EventType())
+}
+
+// TopicSaved is published when a topic is created or renamed.
+type TopicSaved struct {
+	Meta
+	TopicID uuid.UUID `json:"topic_id"`
+	Slug    string    `json:"slug"`
+	Name    string    `json:"name"`
+}
+
+func (*TopicSaved) EventType() string  { return "TopicSaved" }
+func (*TopicSaved) Topic() string      { return contentTopic }
+func (*TopicSaved) SchemaVersion() int { return 1 }
+
+func (e *TopicSaved) Validate() error {
+	var v validator
+	v.id("topic_id", e.TopicID)
+	v.text("slug", e.Slug)
+	v.text("name", e.Name)
+	return v.err(e.EventType())
+}
+
+// TopicDeleted is published when a topic is deleted.
+type TopicDeleted struct {
+	Meta
+	TopicID uuid.UUID `json:"topic_id"`
+}
+
+func (*TopicDeleted) EventType() string  { return "TopicDeleted" }
+func (*TopicDeleted) Topic() string      { return contentTopic }
+func (*TopicDeleted) SchemaVersion() int { return 1 }
+
+func (e *TopicDeleted) Validate() error {
+	var v validator
+	v.id("topic_id", e.TopicID)
+	return v.err(e.EventType())
+}
+
+// LevelSaved is published when a level is created or renamed.
+type LevelSaved struct {
+	Meta
+	LevelID uuid.UUID `json:"level_id"`
+	Code    string    `json:"code"`
+	Name    string    `json:"name"`
+}
+
+func (*LevelSaved) EventType() string  { return "LevelSaved" }
+func (*LevelSaved) Topic() string      { return contentTopic }
+func (*LevelSaved) SchemaVersion() int { return 1 }
+
+func (e *LevelSaved) Validate() error {
+	var v validator
+	v.id("level_id", e.LevelID)
+	v.text("code", e.Code)
+	v.text("name", e.Name)
+	return v.err(e.EventType())
+}
+
+// LevelDeleted is published when a level is deleted.
+type LevelDeleted struct {
+	Meta
+	LevelID uuid.UUID `json:"level_id"`
+}
+
+func (*LevelDeleted) EventType() string  { return "LevelDeleted" }
+func (*LevelDeleted) Topic() string      { return contentTopic }
+func (*LevelDeleted) SchemaVersion() int { return 1 }
+
+func (e *LevelDeleted) Validate() error {
+	var v validator
+	v.id("level_id", e.LevelID)
+	return v.err(e.EventType())
+}
@@ -0,0 +1,177 @@
+// Package events defines the contract of the events the Go services publish to RabbitMQ:
+// one struct per event type, carrying the schema version of its payload.
+//
+// Producers encode events with Marshal, which stamps the metadata and refuses an event
+// that does not validate, so a broken payload never reaches the outbox. Consumers decode
+// with Unmarshal or Decode, which reject payloads of another type, of a schema version
+// newer than they know, or that do not validate.
+//
+// Payloads are flat JSON objects: the metadata fields (event_id, event_type,
+// schema_version, occurred_at) sit next to the event fields, so consumers written against
+// the payloads published before this package keep working. Adding an optional field is
+// compatible and keeps the schema version. Renaming, removing or retyping a field, or
+// making it required, is not: it bumps the version of the event, and consumers must be
+// updated to the new version before producers publish it.
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrUnknownType is returned by Decode for an event type with no contract.
+	ErrUnknownType = errors.New("unknown event type")
+	// ErrTypeMismatch is returned by Unmarshal when the payload is of another type.
+	ErrTypeMismatch = errors.New("event type mismatch")
+	// ErrUnsupportedVersion is returned when decoding a payload of a schema version newer
+	// than the contract.
+	ErrUnsupportedVersion = errors.New("unsupported event schema version")
+)
+
+// Event is an event with a published contract. It is implemented by the event types of
+// this package only.
+type Event interface {
+	// EventType is the type of the event, the Type of its outbox message.
+	EventType() string
+	// Topic is the Topic of the outbox message the event is published with.
+	Topic() string
+	// SchemaVersion is the version of the payload schema.
+	SchemaVersion() int
+	// Validate reports the missing or invalid fields of the event.
+	Validate() error
+
+	metadata() *Meta
+}
+
+// Meta is embedded in every event. Marshal fills it in. The EventType and SchemaVersion
+// methods of an event shadow the fields: read the decoded values through e.Meta.
+type Meta struct {
+	EventID       string    `json:"event_id"`
+	EventType     string    `json:"event_type"`
+	SchemaVersion int       `json:"schema_version"`
+	OccurredAt    time.Time `json:"occurred_at"`
+}
+
+func (m *Meta) metadata() *Meta { return m }
+
+// ValidationError lists the problems found by Validate.
+type ValidationError struct {
+	EventType string
+	Problems  []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s event: %s", e.EventType, strings.Join(e.Problems, "; "))
+}
+
+// Marshal validates the event and encodes it. It stamps the metadata of the event: its
+// type and schema version, and an ID and the current time unless already set.
+func Marshal(e Event) ([]byte, error) {
+	meta := e.metadata()
+	meta.EventType = e.EventType()
+	meta.SchemaVersion = e.SchemaVersion()
+	if meta.EventID == "" {
+		meta.EventID = uuid.NewString()
+	}
+	if meta.OccurredAt.IsZero() {
+		meta.OccurredAt = time.Now().UTC()
+	}
+
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(e)
+}
+
+// Unmarshal decodes data into e and validates it. Payloads without metadata, published
+// before the contract existed, are read as version 1 of the type of e.
+func Unmarshal(data []byte, e Event) error {
+	if err := json.Unmarshal(data, e); err != nil {
+		return fmt.Errorf("decode %s event: %w", e.EventType(), err)
+	}
+
+	meta := e.metadata()
+	if meta.EventType == "" {
+		meta.EventType = e.EventType()
+	}
+	if meta.SchemaVersion == 0 {
+		meta.SchemaVersion = 1
+	}
+	if meta.EventType != e.EventType() {
+		return fmt.Errorf("%w: got %s, want %s", ErrTypeMismatch, meta.EventType, e.EventType())
+	}
+	if meta.SchemaVersion > e.SchemaVersion() {
+		return fmt.Errorf("%w: %s version %d, supported up to %d", ErrUnsupportedVersion, meta.EventType, meta.SchemaVersion, e.SchemaVersion())
+	}
+	return e.Validate()
+}
+
+// Decode decodes a payload of the given event type, the Type of the outbox message or
+// the event_type header of the published message.
+func Decode(eventType string, data []byte) (Event, error) {
+	newEvent, ok := registry[eventType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, eventType)
+	}
+	e := newEvent()
+	if err := Unmarshal(data, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Types lists the event types with a contract.
+func Types() []string {
+	types := make([]string, 0, len(registry))
+	for eventType := range registry {
+		types = append(types, eventType)
+	}
+	return types
+}
+
+var registry = map[string]func() Event{}
+
+// register adds the constructor of an event type to the registry used by Decode.
+func register(newEvent func() Event) {
+	registry[newEvent().EventType()] = newEvent
+}
+
+// validator collects the problems of an event for Validate.
+type validator struct {
+	problems []string
+}
+
+func (v *validator) require(ok bool, field, problem string) {
+	if !ok {
+		v.problems = append(v.problems, field+" "+problem)
+	}
+}
+
+func (v *validator) id(field string, id uuid.UUID) {
+	v.require(id != uuid.Nil, field, "is required")
+}
+
+func (v *validator) text(field, value string) {
+	v.require(strings.TrimSpace(value) != "", field, "is required")
+}
+
+func (v *validator) time(field string, t time.Time) {
+	v.require(!t.IsZero(), field, "is required")
+}
+
+func (v *validator) nonNegative(field string, n int64) {
+	v.require(n >= 0, field, "must not be negative")
+}
+
+func (v *validator) err(eventType string) error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{EventType: eventType, Problems: v.problems}
+}
@@ -0,0 +1,149 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestMarshalStampsMetadata(t *testing.T) {
+	e := &LessonDeleted{LessonID: uuid.New()}
+	data, err := Marshal(e)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatalf("payload is not JSON: %v", err)
+	}
+	if payload["event_type"] != "LessonDeleted" || payload["schema_version"] != float64(1) {
+		t.Fatalf("metadata not stamped: %v", payload)
+	}
+	if payload["event_id"] == "" || payload["occurred_at"] == nil {
+		t.Fatalf("event id and time not set: %v", payload)
+	}
+	if payload["lesson_id"] != e.LessonID.String() {
+		t.Fatalf("fields are not flattened next to the metadata: %v", payload)
+	}
+}
+
+func TestMarshalRejectsInvalidEvent(t *testing.T) {
+	_, err := Marshal(&PasswordResetRequested{Email: "ann@example.com"})
+
+	var invalid *ValidationError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+	if len(invalid.Problems) != 3 {
+		t.Fatalf("expected reset_link, expires_in_minutes and requested_at problems, got %v", invalid.Problems)
+	}
+}
+
+func TestDecodeRoundTrip(t *testing.T) {
+	sent := &OrderPaid{
+		OrderID:         uuid.New(),
+		UserID:          uuid.New(),
+		PaymentID:       uuid.New(),
+		TotalAmount:     4900,
+		Currency:        "USD",
+		PaymentIntentID: "pi_123",
+		Items:           []OrderItem{{ID: uuid.New(), CourseID: uuid.New(), PriceSnapshot: 4900, Quantity: 1}},
+		PaidAt:          time.Now().UTC(),
+	}
+	data, err := Marshal(sent)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	decoded, err := Decode("order.paid", data)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	received, ok := decoded.(*OrderPaid)
+	if !ok {
+		t.Fatalf("decoded a %T", decoded)
+	}
+	if received.EventID != sent.EventID || received.PaymentIntentID != "pi_123" || len(received.Items) != 1 {
+		t.Fatalf("decoded %+v, sent %+v", received, sent)
+	}
+}
+
+func TestContentDetailsAreFlattened(t *testing.T) {
+	topicID := uuid.New()
+	sent := &CoursePublished{
+		CourseID:      uuid.New(),
+		Title:         "Business English",
+		PublishedAt:   time.Now().UTC(),
+		CourseDetails: CourseDetails{TopicID: &topicID, Price: 19.5},
+	}
+	data, err := Marshal(sent)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatalf("payload is not JSON: %v", err)
+	}
+	if payload["topic_id"] != topicID.String() || payload["price"] != 19.5 {
+		t.Fatalf("details not flattened: %v", payload)
+	}
+	if _, ok := payload["level_id"]; ok {
+		t.Fatalf("unset details are sent: %v", payload)
+	}
+
+	decoded, err := Decode("CoursePublished", data)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if received := decoded.(*CoursePublished); received.TopicID == nil || *received.TopicID != topicID {
+		t.Fatalf("decoded %+v", received)
+	}
+}
+
+func TestUnmarshalChecksTypeAndVersion(t *testing.T) {
+	id := uuid.New()
+
+	err := Unmarshal([]byte(`{"event_type":"LessonCreated","schema_version":1,"lesson_id":"`+id.String()+`"}`), &LessonDeleted{})
+	if !errors.Is(err, ErrTypeMismatch) {
+		t.Fatalf("expected ErrTypeMismatch, got %v", err)
+	}
+
+	err = Unmarshal([]byte(`{"event_type":"LessonDeleted","schema_version":2,"lesson_id":"`+id.String()+`"}`), &LessonDeleted{})
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("expected ErrUnsupportedVersion, got %v", err)
+	}
+
+	var legacy LessonDeleted
+	if err := Unmarshal([]byte(`{"lesson_id":"`+id.String()+`"}`), &legacy); err != nil {
+		t.Fatalf("payload without metadata: %v", err)
+	}
+	if legacy.Meta.SchemaVersion != 1 || legacy.LessonID != id {
+		t.Fatalf("decoded %+v", legacy)
+	}
+}
+
+func TestDecodeUnknownType(t *testing.T) {
+	if _, err := Decode("LessonArchived", []byte(`{}`)); !errors.Is(err, ErrUnknownType) {
+		t.Fatalf("expected ErrUnknownType, got %v", err)
+	}
+}
+
+func TestRegisteredTypesValidateZeroValue(t *testing.T) {
+	for _, eventType := range Types() {
+		e := registry[eventType]()
+		if e.EventType() != eventType {
+			t.Errorf("%s registered as %s", e.EventType(), eventType)
+		}
+		if e.Topic() == "" || e.SchemaVersion() < 1 {
+			t.Errorf("%s has no topic or schema version", eventType)
+		}
+		if err := e.Validate(); err == nil {
+			t.Errorf("%s accepts an empty payload", eventType)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Order events are published by order-services to the order.events exchange with the
+// event type as routing key. Amounts are in cents of Currency.
+
+func init() {
+	register(func() Event { return &OrderCreated{} })
+	register(func() Event { return &OrderCancelled{} })
+	register(func() Event { return &OrderStatusChanged{} })
+	register(func() Event { return &OrderPaid{} })
+	register(func() Event { return &OrderFailed{} })
+	register(func() Event { return &OrderRefunded{} })
+	register(func() Event { return &OrderRefundFailed{} })
+	register(func() Event { return &OrderRefundRejected{} })
+	register(func() Event { return &OrderRefundCompleted{} })
+}
+
+const orderTopic = "order.events"
+
+// OrderItem is a line of an order, with its price when the order was placed.
+type OrderItem struct {
+	ID            uuid.UUID `json:"id"`
+	CourseID      uuid.UUID `json:"course_id"`
+	CourseTitle   string    `json:"course_title"`
+	InstructorID  uuid.UUID `json:"instructor_id,omitempty"`
+	PriceSnapshot int64     `json:"price_snapshot"`
+	OriginalPrice int64     `json:"original_price"`
+	Quantity      int       `json:"quantity"`
+	ItemType      string    `json:"item_type"`
+}
+
+// CouponRef names the coupon applied to an order.
+type CouponRef struct {
+	ID   uuid.UUID `json:"id"`
+	Code string    `json:"code"`
+}
+
+func validateItems(v *validator, items []OrderItem) {
+	v.require(len(items) > 0, "items", "must not be empty")
+	for _, item := range items {
+		v.id("items.course_id", item.CourseID)
+		v.nonNegative("items.price_snapshot", item.PriceSnapshot)
+		v.require(item.Quantity > 0, "items.quantity", "must be positive")
+	}
+}
+
+// OrderCreated is published when an order is placed, before it is paid.
+type OrderCreated struct {
+	Meta
+	OrderID     uuid.UUID   `json:"order_id"`
+	UserID      uuid.UUID   `json:"user_id"`
+	TotalAmount int64       `json:"total_amount"`
+	Currency    string      `json:"currency"`
+	Status      string      `json:"status"`
+	Items       []OrderItem `json:"items"`
+	Coupon      *CouponRef  `json:"coupon,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+	ExpiresAt   *time.Time  `json:"expires_at,omitempty"`
+}
+
+func (*OrderCreated) EventType() string  { return "order.created" }
+func (*OrderCreated) Topic() string      { return orderTopic }
+func (*OrderCreated) SchemaVersion() int { return 1 }
+
+func (e *OrderCreated) Validate() error {
+	var v validator
+	v.id("order_id", e.OrderID)
+	v.id("user_id", e.UserID)
+	v.nonNegative("total_amount", e.TotalAmount)
+	v.text("currency", e.Currency)
+	v.text("status", e.Status)
+	validateItems(&v, e.Items)
+	v.time("created_at", e.CreatedAt)
+	return v.err(e.EventType())
+}
+
+// OrderCancelled is published when a user cancels an unpaid order or it expires.
+type OrderCancelled struct {
+	Meta
+	OrderID     uuid.UUID `json:"order_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	Reason      string    `json:"reason,omitempty"`
+	CancelledAt time.Time `json:"cancelled_at"`
+}
+
+func (*OrderCancelled) EventType() string  { return "order.cancelled" }
+func (*OrderCancelled) Topic() string      { return orderTopic }
+func (*OrderCancelled) SchemaVersion() int { return 1 }
+
+func (e *OrderCancelled) Validate() error {
+	var v validator
+	v.id("order_id", e.OrderID)
+	v.id("user_id", e.UserID)
+	v.time("cancelled_at", e.CancelledAt)
+	return v.err(e.EventType())
+}
+
+// OrderStatusChanged is published when an admin or a system job moves an order to
+// another status. Paying, failing and cancelling an order through its own flow publish
+// OrderPaid, OrderFailed and OrderCancelled instead.
+type OrderStatusChanged struct {
+	Meta
+	OrderID        uuid.UUID `json:"order_id"`
+	UserID         uuid.UUID `json:"user_id"`
+	PreviousStatus string    `json:"previous_status"`
+	NewStatus      string    `json:"new_status"`
+	Reason         string    `json:"reason,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (*OrderStatusChanged) EventType() string  { return "order.status_changed" }
+func (*OrderStatusChanged) Topic() string      { return orderTopic }
+func (*OrderStatusChanged) SchemaVersion() int { return 1 }
+
+func (e *OrderStatusChanged) Validate() error {
+	var v validator
+	v.id("order_id", e.OrderID)
+	v.id("user_id", e.UserID)
+	v.text("previous_status", e.PreviousStatus)
+	v.text("new_status", e.NewStatus)
+	v.time("updated_at", e.UpdatedAt)
+	return v.err(e.EventType())
+}
+
+// OrderPaid is published once the payment of an order succeeded, to grant the items.
+type OrderPaid struct {
+	Meta
+	OrderID         uuid.UUID   `json:"order_id"`
+	UserID          uuid.UUID   `json:"user_id"`
+	PaymentID       uuid.UUID   `json:"payment_id"`
+	TotalAmount     int64       `json:"total_amount"`
+	Currency        string      `json:"currency"`
+	PaymentIntentID string      `json:"payment_intent_id"`
+	Items           []OrderItem `json:"items"`
+	PaidAt          time.Time   `json:"paid_at"`
+}
+
+func (*OrderPaid) EventType() string  { return "order.paid" }
+func (*OrderPaid) Topic() string      { return orderTopic }
+func (*OrderPaid) SchemaVersion() int { return 1 }
+
+func (e *OrderPaid) Validate() error {
+	var v validator
+	v.id("order_id", e.OrderID)
+	v.id("user_id", e.UserID)
+	v.id("payment_id", e.PaymentID)
+	v.nonNegative("total_amount", e.TotalAmount)
+	v.text("currency", e.Currency)
+	v.text("payment_intent_id", e.PaymentIntentID)
+	validateItems(&v, e.Items)
+	v.time("paid_at", e.PaidAt)
+	return v.err(e.EventType())
+}
+
+// OrderFailed is published when the payment of an order failed.
+type OrderFailed struct {
+	Meta
+	OrderID       uuid.UUID `json:"order_id"`
+	UserID        uuid.UUID `json:"user_id"`
+	TotalAmount   int64     `json:"total_amount"`
+	Currency      string    `json:"currency"`
+	FailureReason string    `json:"failure_reason"`
+	FailedAt      time.Time `json:"failed_at"`
+}
+
+func (*OrderFailed) EventType() string  { return "order.failed" }
+func (*OrderFailed) Topic() string      { return orderTopic }
+func (*OrderFailed) SchemaVersion() int { return 1 }
+
+func (e *OrderFailed) Validate() error {
+	var v validator
+	v.id("order_id", e.OrderID)
+	v.id("user_id", e.UserID)
+	v.nonNegative("total_amount", e.TotalAmount)
+	v.text("currency", e.Currency)
+	v.time("failed_at", e.FailedAt)
+	return v.err(e.EventType())
+}
+
+// Refund describes a refund request of an order in the refund events. Status is the
+// status of the request after the change the event reports.
+type Refund struct {
+	RefundID    uuid.UUID `json:"refund_id"`
+	OrderID     uuid.UUID `json:"order_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	Amount      int64     `json:"amount"`
+	Status      string    `json:"status"`
+	AdminReason string    `json:"admin_reason,omitempty"`
+	ProcessedAt time.Time `json:"processed_at"`
+}
+
+func (r *Refund) validate(v *validator) {
+	v.id("refund_id", r.RefundID)
+	v.id("order_id", r.OrderID)
+	v.id("user_id", r.UserID)
+	v.require(r.Amount > 0, "amount", "must be positive")
+	v.text("status", r.Status)
+	v.time("processed_at", r.ProcessedAt)
+}
+
+// OrderRefunded is published when an admin approves a refund and Stripe accepts it.
+type OrderRefunded struct {
+	Meta
+	Refund
+	StripeRefundID string `json:"stripe_refund_id"`
+}
+
+func (*OrderRefunded) EventType() string  { return "order.refunded" }
+func (*OrderRefunded) Topic() string      { return orderTopic }
+func (*OrderRefunded) SchemaVersion() int { return 1 }
+
+func (e *OrderRefunded) Validate() error {
+	var v validator
+	e.Refund.validate(&v)
+	v.text("stripe_refund_id", e.StripeRefundID)
+	return v.err(e.EventType())
+}
+
+// OrderRefundFailed is published when Stripe refuses an approved refund.
+type OrderRefundFailed struct {
+	Meta
+	Refund
+	Error string `json:"error"`
+}
+
+func (*OrderRefundFailed) EventType() string  { return "order.refund_failed" }
+func (*OrderRefundFailed) Topic() string      { return orderTopic }
+func (*OrderRefundFailed) SchemaVersion() int { return 1 }
+
+func (e *OrderRefundFailed) Validate() error {
+	var v validator
+	e.Refund.validate(&v)
+	v.text("error", e.Error)
+	return v.err(e.EventType())
+}
+
+// OrderRefundRejected is published when an admin rejects a refund request.
+type OrderRefundRejected struct {
+	Meta
+	Refund
+}
+
+func (*OrderRefundRejected) EventType() string  { return "order.refund_rejected" }
+func (*OrderRefundRejected) Topic() string      { return orderTopic }
+func (*OrderRefundRejected) SchemaVersion() int { return 1 }
+
+func (e *OrderRefundRejected) Validate() error {
+	var v validator
+	e.Refund.validate(&v)
+	return v.err(e.EventType())
+}
+
+// OrderRefundCompleted is published when Stripe reports the refund as paid out.
+type OrderRefundCompleted struct {
+	Meta
+	Refund
+	StripeRefundID string `json:"stripe_refund_id"`
+}
+
+func (*OrderRefundCompleted) EventType() string  { return "order.refund_completed" }
+func (*OrderRefundCompleted) Topic() string      { return orderTopic }
+func (*OrderRefundCompleted) SchemaVersion() int { return 1 }
+
+func (e *OrderRefundCompleted) Validate() error {
+	var v validator
+	e.Refund.validate(&v)
+	v.text("stripe_refund_id", e.StripeRefundID)
+	return v.err(e.EventType())
+}
@@ -0,0 +1,91 @@
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Payment events are published by order-services to the payment.events exchange with
+// the event type as routing key. Amounts are in cents of Currency.
+
+func init() {
+	register(func() Event { return &PaymentCreated{} })
+	register(func() Event { return &PaymentSucceeded{} })
+	register(func() Event { return &PaymentFailed{} })
+}
+
+const paymentTopic = "payment.events"
+
+// Payment describes the Stripe payment of an order in the payment events. Status is the
+// Stripe status of its payment intent.
+type Payment struct {
+	PaymentID       uuid.UUID `json:"payment_id"`
+	OrderID         uuid.UUID `json:"order_id"`
+	StripePaymentID string    `json:"stripe_payment_id"`
+	Amount          int64     `json:"amount"`
+	Currency        string    `json:"currency"`
+	Status          string    `json:"status"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+func (p *Payment) validate(v *validator) {
+	v.id("payment_id", p.PaymentID)
+	v.id("order_id", p.OrderID)
+	v.text("stripe_payment_id", p.StripePaymentID)
+	v.nonNegative("amount", p.Amount)
+	v.text("currency", p.Currency)
+	v.text("status", p.Status)
+	v.time("created_at", p.CreatedAt)
+}
+
+// PaymentCreated is published when a Stripe payment intent is created for an order.
+type PaymentCreated struct {
+	Meta
+	Payment
+}
+
+func (*PaymentCreated) EventType() string  { return "payment.created" }
+func (*PaymentCreated) Topic() string      { return paymentTopic }
+func (*PaymentCreated) SchemaVersion() int { return 1 }
+
+func (e *PaymentCreated) Validate() error {
+	var v validator
+	e.Payment.validate(&v)
+	return v.err(e.EventType())
+}
+
+// PaymentSucceeded is published when Stripe confirms a payment.
+type PaymentSucceeded struct {
+	Meta
+	Payment
+}
+
+func (*PaymentSucceeded) EventType() string  { return "payment.succeeded" }
+func (*PaymentSucceeded) Topic() string      { return paymentTopic }
+func (*PaymentSucceeded) SchemaVersion() int { return 1 }
+
+func (e *PaymentSucceeded) Validate() error {
+	var v validator
+	e.Payment.validate(&v)
+	return v.err(e.EventType())
+}
+
+// PaymentFailed is published when Stripe reports a payment as failed.
+type PaymentFailed struct {
+	Meta
+	Payment
+	FailureReason string    `json:"failure_reason"`
+	FailedAt      time.Time `json:"failed_at"`
+}
+
+func (*PaymentFailed) EventType() string  { return "payment.failed" }
+func (*PaymentFailed) Topic() string      { return paymentTopic }
+func (*PaymentFailed) SchemaVersion() int { return 1 }
+
+func (e *PaymentFailed) Validate() error {
+	var v validator
+	e.Payment.validate(&v)
+	v.time("failed_at", e.FailedAt)
+	return v.err(e.EventType())
+}
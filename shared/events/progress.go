@@ -0,0 +1,114 @@
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Progress events are published by lesson-services to the progress.events exchange with
+// the event type as routing key, when a learner enrolls in or completes a course,
+// completes a lesson or submits a quiz. lesson-services is written in Python: it writes
+// the payloads of these structs to its outbox, and the relay checks them against their
+// schemas before publishing them.
+
+func init() {
+	register(func() Event { return &CourseEnrolled{} })
+	register(func() Event { return &CourseCompleted{} })
+	register(func() Event { return &LessonCompleted{} })
+	register(func() Event { return &QuizSubmitted{} })
+}
+
+const progressTopic = "progress.events"
+
+// CourseEnrolled is published when a learner enrolls in a course.
+type CourseEnrolled struct {
+	Meta
+	UserID     uuid.UUID `json:"user_id"`
+	CourseID   uuid.UUID `json:"course_id"`
+	EnrolledAt time.Time `json:"enrolled_at"`
+}
+
+func (*CourseEnrolled) EventType() string  { return "CourseEnrolled" }
+func (*CourseEnrolled) Topic() string      { return progressTopic }
+func (*CourseEnrolled) SchemaVersion() int { return 1 }
+
+func (e *CourseEnrolled) Validate() error {
+	var v validator
+	v.id("user_id", e.UserID)
+	v.id("course_id", e.CourseID)
+	v.time("enrolled_at", e.EnrolledAt)
+	return v.err(e.EventType())
+}
+
+// CourseCompleted is published when the enrollment of a learner in a course is completed.
+type CourseCompleted struct {
+	Meta
+	UserID      uuid.UUID `json:"user_id"`
+	CourseID    uuid.UUID `json:"course_id"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+func (*CourseCompleted) EventType() string  { return "CourseCompleted" }
+func (*CourseCompleted) Topic() string      { return progressTopic }
+func (*CourseCompleted) SchemaVersion() int { return 1 }
+
+func (e *CourseCompleted) Validate() error {
+	var v validator
+	v.id("user_id", e.UserID)
+	v.id("course_id", e.CourseID)
+	v.time("completed_at", e.CompletedAt)
+	return v.err(e.EventType())
+}
+
+// LessonCompleted is published when a learner completes a lesson, with the score of the
+// lesson.
+type LessonCompleted struct {
+	Meta
+	UserID      uuid.UUID `json:"user_id"`
+	LessonID    uuid.UUID `json:"lesson_id"`
+	Score       int64     `json:"score"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+func (*LessonCompleted) EventType() string  { return "LessonCompleted" }
+func (*LessonCompleted) Topic() string      { return progressTopic }
+func (*LessonCompleted) SchemaVersion() int { return 1 }
+
+func (e *LessonCompleted) Validate() error {
+	var v validator
+	v.id("user_id", e.UserID)
+	v.id("lesson_id", e.LessonID)
+	v.nonNegative("score", e.Score)
+	v.time("completed_at", e.CompletedAt)
+	return v.err(e.EventType())
+}
+
+// QuizSubmitted is published when a learner submits a quiz attempt. LessonID is set for
+// the quizzes of a lesson.
+type QuizSubmitted struct {
+	Meta
+	UserID      uuid.UUID  `json:"user_id"`
+	QuizID      uuid.UUID  `json:"quiz_id"`
+	AttemptID   uuid.UUID  `json:"attempt_id"`
+	LessonID    *uuid.UUID `json:"lesson_id,omitempty"`
+	Points      int64      `json:"points"`
+	MaxPoints   int64      `json:"max_points"`
+	Passed      bool       `json:"passed"`
+	SubmittedAt time.Time  `json:"submitted_at"`
+}
+
+func (*QuizSubmitted) EventType() string  { return "QuizSubmitted" }
+func (*QuizSubmitted) Topic() string      { return progressTopic }
+func (*QuizSubmitted) SchemaVersion() int { return 1 }
+
+func (e *QuizSubmitted) Validate() error {
+	var v validator
+	v.id("user_id", e.UserID)
+	v.id("quiz_id", e.QuizID)
+	v.id("attempt_id", e.AttemptID)
+	v.nonNegative("points", e.Points)
+	v.nonNegative("max_points", e.MaxPoints)
+	v.time("submitted_at", e.SubmittedAt)
+	return v.err(e.EventType())
+}
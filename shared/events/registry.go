@@ -0,0 +1,211 @@
+package events
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SchemaKey names the schema of one version of an event type.
+type SchemaKey struct {
+	EventType string
+	Version   int
+}
+
+// FileName is the name of the schema file in a registry directory.
+func (k SchemaKey) FileName() string {
+	return fmt.Sprintf("%s.v%d.json", k.EventType, k.Version)
+}
+
+var schemaFileName = regexp.MustCompile(`^(.+)\.v([1-9][0-9]*)\.json$`)
+
+// ErrIncompatibleSchema is returned by CheckCompatible.
+var ErrIncompatibleSchema = errors.New("incompatible event schema change")
+
+// SchemaRegistry holds the JSON Schemas of the versions of the event types.
+type SchemaRegistry struct {
+	schemas map[SchemaKey]*Schema
+}
+
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// Schemas is the registry of the schemas in the schemas directory of this package: the
+// current version of every event type and the versions before it.
+var Schemas = mustLoadSchemas()
+
+func mustLoadSchemas() *SchemaRegistry {
+	sub, err := fs.Sub(schemaFiles, "schemas")
+	if err != nil {
+		panic(err)
+	}
+	r, err := LoadSchemas(sub)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// LoadSchemas reads the schema files at the root of fsys, named like
+// order.created.v1.json.
+func LoadSchemas(fsys fs.FS) (*SchemaRegistry, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	r := &SchemaRegistry{schemas: make(map[SchemaKey]*Schema)}
+	for _, entry := range entries {
+		match := schemaFileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(match[2])
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		var s Schema
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, fmt.Errorf("schema %s: %w", entry.Name(), err)
+		}
+		r.schemas[SchemaKey{EventType: match[1], Version: version}] = &s
+	}
+	return r, nil
+}
+
+// GeneratedSchemas returns a registry of the schemas of the current versions of the
+// event types, generated from their structs.
+func GeneratedSchemas() *SchemaRegistry {
+	r := &SchemaRegistry{schemas: make(map[SchemaKey]*Schema)}
+	for _, newEvent := range registry {
+		e := newEvent()
+		r.schemas[SchemaKey{EventType: e.EventType(), Version: e.SchemaVersion()}] = GenerateSchema(e)
+	}
+	return r
+}
+
+// Keys lists the schemas of the registry by event type and version.
+func (r *SchemaRegistry) Keys() []SchemaKey {
+	keys := make([]SchemaKey, 0, len(r.schemas))
+	for key := range r.schemas {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].EventType != keys[j].EventType {
+			return keys[i].EventType < keys[j].EventType
+		}
+		return keys[i].Version < keys[j].Version
+	})
+	return keys
+}
+
+// Schema returns the schema of a version of an event type, nil when there is none.
+func (r *SchemaRegistry) Schema(eventType string, version int) *Schema {
+	return r.schemas[SchemaKey{EventType: eventType, Version: version}]
+}
+
+// Validate checks a payload against the schema of its event type and schema_version,
+// version 1 when it has none. Types without a schema in the registry are not under
+// contract and pass. It implements the Validator of shared/outbox.
+func (r *SchemaRegistry) Validate(eventType string, payload []byte) error {
+	if !r.has(eventType) {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return fmt.Errorf("decode %s event: %w", eventType, err)
+	}
+
+	version := 1
+	if object, ok := value.(map[string]any); ok {
+		if n, ok := object["schema_version"].(json.Number); ok {
+			v, err := strconv.Atoi(n.String())
+			if err != nil {
+				return &ValidationError{EventType: eventType, Problems: []string{"schema_version must be an integer"}}
+			}
+			version = v
+		}
+	}
+	s := r.Schema(eventType, version)
+	if s == nil {
+		return fmt.Errorf("%w: %s version %d has no schema", ErrUnsupportedVersion, eventType, version)
+	}
+
+	var v validator
+	s.validate(&v, "", value)
+	return v.err(eventType)
+}
+
+func (r *SchemaRegistry) has(eventType string) bool {
+	for key := range r.schemas {
+		if key.EventType == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckCompatible compares the registry with base, the registry of the branch it is
+// merged into. Every schema of base must still be there, changed at most by new optional
+// properties; a breaking change needs a new version of the event type instead.
+func (r *SchemaRegistry) CheckCompatible(base *SchemaRegistry) error {
+	var problems []string
+	for _, key := range base.Keys() {
+		next, ok := r.schemas[key]
+		if !ok {
+			problems = append(problems, key.FileName()+": schema removed")
+			continue
+		}
+		for _, change := range base.schemas[key].breakingChanges("", next) {
+			problems = append(problems, key.FileName()+": "+change)
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w:\n  %s", ErrIncompatibleSchema, strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// Diff lists the schemas of r that differ from or are missing in other.
+func (r *SchemaRegistry) Diff(other *SchemaRegistry) []SchemaKey {
+	var differ []SchemaKey
+	for _, key := range r.Keys() {
+		mine, _ := r.schemas[key].MarshalIndent()
+		var theirs []byte
+		if s, ok := other.schemas[key]; ok {
+			theirs, _ = s.MarshalIndent()
+		}
+		if !bytes.Equal(mine, theirs) {
+			differ = append(differ, key)
+		}
+	}
+	return differ
+}
+
+// WriteDir writes the schemas of r to dir, named by FileName. Schemas of dir that are not
+// in r are left alone.
+func (r *SchemaRegistry) WriteDir(dir string) error {
+	for _, key := range r.Keys() {
+		data, err := r.schemas[key].MarshalIndent()
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, key.FileName()), data, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package events
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The schemas directory is generated from the structs; a struct changed without it fails
+// here, and CI then compares the regenerated schemas with those of the base branch.
+func TestSchemasMatchStructs(t *testing.T) {
+	for _, key := range GeneratedSchemas().Diff(Schemas) {
+		t.Errorf("schemas/%s is out of date: run go run ./cmd/eventschemas write", key.FileName())
+	}
+}
+
+func TestMarshalledEventsValidate(t *testing.T) {
+	expires := time.Now().UTC().Add(time.Hour)
+	sent := []Event{
+		&OrderCreated{
+			OrderID:     uuid.New(),
+			UserID:      uuid.New(),
+			TotalAmount: 4900,
+			Currency:    "USD",
+			Status:      "created",
+			Items:       []OrderItem{{ID: uuid.New(), CourseID: uuid.New(), PriceSnapshot: 4900, Quantity: 1}},
+			Coupon:      &CouponRef{ID: uuid.New(), Code: "SPRING"},
+			ExpiresAt:   &expires,
+			CreatedAt:   time.Now().UTC(),
+		},
+		&LessonDeleted{LessonID: uuid.New()},
+		&OrderRefunded{
+			Refund:         Refund{RefundID: uuid.New(), OrderID: uuid.New(), UserID: uuid.New(), Amount: 100, Status: "processed", ProcessedAt: time.Now()},
+			StripeRefundID: "re_123",
+		},
+	}
+	for _, e := range sent {
+		data, err := Marshal(e)
+		if err != nil {
+			t.Fatalf("Marshal %s: %v", e.EventType(), err)
+		}
+		if err := Schemas.Validate(e.EventType(), data); err != nil {
+			t.Errorf("%s: %v", e.EventType(), err)
+		}
+	}
+}
+
+func TestValidateRejectsPayloads(t *testing.T) {
+	id := uuid.NewString()
+	cases := []struct {
+		name    string
+		payload string
+		problem string
+	}{
+		{"missing field", `{"event_id":"e","event_type":"LessonDeleted","schema_version":1,"occurred_at":"2025-01-01T00:00:00Z"}`, "lesson_id is required"},
+		{"wrong type", `{"event_id":"e","event_type":"LessonDeleted","schema_version":1,"occurred_at":"2025-01-01T00:00:00Z","lesson_id":7}`, "lesson_id must be of type string"},
+		{"bad format", `{"event_id":"e","event_type":"LessonDeleted","schema_version":1,"occurred_at":"yesterday","lesson_id":"` + id + `"}`, "occurred_at must be an RFC 3339 date-time"},
+		{"other type", `{"event_id":"e","event_type":"LessonCreated","schema_version":1,"occurred_at":"2025-01-01T00:00:00Z","lesson_id":"` + id + `"}`, "event_type must be LessonDeleted"},
+	}
+	for _, tc := range cases {
+		err := Schemas.Validate("LessonDeleted", []byte(tc.payload))
+		var invalid *ValidationError
+		if !errors.As(err, &invalid) || !strings.Contains(err.Error(), tc.problem) {
+			t.Errorf("%s: got %v, want %q", tc.name, err, tc.problem)
+		}
+	}
+
+	if err := Schemas.Validate("LessonDeleted", []byte(`{"schema_version":2}`)); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("unknown version: got %v", err)
+	}
+	if err := Schemas.Validate("user.security.login_failed", []byte(`{}`)); err != nil {
+		t.Errorf("a type without a schema is not under contract: %v", err)
+	}
+}
+
+func TestCheckCompatible(t *testing.T) {
+	base := loadTestSchemas(t, `{"type":"object","properties":{"id":{"type":"string","format":"uuid"},"note":{"type":"string"},"n":{"type":"integer"}},"required":["id","n"]}`)
+
+	cases := []struct {
+		name   string
+		schema string
+		change string // empty when compatible
+	}{
+		{"unchanged", `{"type":"object","properties":{"id":{"type":"string","format":"uuid"},"note":{"type":"string"},"n":{"type":"integer"}},"required":["id","n"]}`, ""},
+		{"optional property added", `{"type":"object","properties":{"id":{"type":"string","format":"uuid"},"note":{"type":"string"},"n":{"type":"integer"},"tag":{"type":"string"}},"required":["id","n"]}`, ""},
+		{"property removed", `{"type":"object","properties":{"id":{"type":"string","format":"uuid"},"n":{"type":"integer"}},"required":["id","n"]}`, "property note removed"},
+		{"retyped", `{"type":"object","properties":{"id":{"type":"string","format":"uuid"},"note":{"type":"string"},"n":{"type":"number"}},"required":["id","n"]}`, "payload.n: type changed"},
+		{"made required", `{"type":"object","properties":{"id":{"type":"string","format":"uuid"},"note":{"type":"string"},"n":{"type":"integer"}},"required":["id","n","note"]}`, "property note became required"},
+		{"required property added", `{"type":"object","properties":{"id":{"type":"string","format":"uuid"},"note":{"type":"string"},"n":{"type":"integer"},"tag":{"type":"string"}},"required":["id","n","tag"]}`, "required property tag added"},
+		{"made optional", `{"type":"object","properties":{"id":{"type":"string","format":"uuid"},"note":{"type":"string"},"n":{"type":"integer"}},"required":["id"]}`, "property n became optional"},
+	}
+	for _, tc := range cases {
+		err := loadTestSchemas(t, tc.schema).CheckCompatible(base)
+		switch {
+		case tc.change == "" && err != nil:
+			t.Errorf("%s: %v", tc.name, err)
+		case tc.change != "" && (!errors.Is(err, ErrIncompatibleSchema) || !strings.Contains(err.Error(), tc.change)):
+			t.Errorf("%s: got %v, want %q", tc.name, err, tc.change)
+		}
+	}
+
+	removed, err := LoadSchemas(fstest.MapFS{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := removed.CheckCompatible(base); err == nil || !strings.Contains(err.Error(), "test.event.v1.json: schema removed") {
+		t.Errorf("removed schema: %v", err)
+	}
+}
+
+func loadTestSchemas(t *testing.T, schema string) *SchemaRegistry {
+	t.Helper()
+	r, err := LoadSchemas(fstest.MapFS{"test.event.v1.json": {Data: []byte(schema)}, "README.md": {}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
@@ -0,0 +1,278 @@
+package events
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SchemaDialect is the JSON Schema dialect of the event schemas.
+const SchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is the subset of JSON Schema the event payloads are described with: types,
+// formats, constants, object properties and array items.
+type Schema struct {
+	Dialect    string             `json:"$schema,omitempty"`
+	Title      string             `json:"title,omitempty"`
+	Type       TypeSet            `json:"type,omitempty"`
+	Format     string             `json:"format,omitempty"`
+	Const      any                `json:"const,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+}
+
+// TypeSet is the type keyword of a schema: one JSON type, or several when a value may
+// also be null. Empty allows any value.
+type TypeSet []string
+
+func (t TypeSet) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t *TypeSet) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*t = TypeSet{one}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	uuidType          = reflect.TypeFor[uuid.UUID]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// GenerateSchema describes the payload Marshal produces for e. A field is required unless
+// encoding/json may omit it; event_type and schema_version are constants of e.
+func GenerateSchema(e Event) *Schema {
+	s := schemaOf(reflect.TypeOf(e).Elem())
+	s.Dialect = SchemaDialect
+	s.Title = e.EventType()
+	s.Properties["event_type"].Const = e.EventType()
+	s.Properties["schema_version"].Const = e.SchemaVersion()
+	return s
+}
+
+func schemaOf(t reflect.Type) *Schema {
+	switch {
+	case t.Kind() == reflect.Pointer:
+		s := schemaOf(t.Elem())
+		s.Type = append(s.Type, "null")
+		return s
+	case t == timeType:
+		return &Schema{Type: TypeSet{"string"}, Format: "date-time"}
+	case t == uuidType:
+		return &Schema{Type: TypeSet{"string"}, Format: "uuid"}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return &Schema{Type: TypeSet{"string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: TypeSet{"string"}}
+	case reflect.Bool:
+		return &Schema{Type: TypeSet{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: TypeSet{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: TypeSet{"number"}}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: TypeSet{"array"}, Items: schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: TypeSet{"object"}}
+	case reflect.Struct:
+		s := &Schema{Type: TypeSet{"object"}, Properties: map[string]*Schema{}}
+		addFields(s, t)
+		sort.Strings(s.Required)
+		return s
+	}
+	// Interfaces hold any value
+	return &Schema{}
+}
+
+// addFields adds the fields encoding/json encodes for t to s, flattening embedded structs.
+func addFields(s *Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			addFields(s, f.Type)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		s.Properties[name] = schemaOf(f.Type)
+		if !slices.Contains(strings.Split(opts, ","), "omitempty") || !omittable(f.Type) {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// omittable reports whether omitempty can leave out a value of t. It never omits structs
+// and arrays, such as time.Time and uuid.UUID.
+func omittable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Array:
+		return false
+	}
+	return true
+}
+
+// validate appends the problems of value, decoded with json.Decoder.UseNumber, to v.
+func (s *Schema) validate(v *validator, path string, value any) {
+	if s.Const != nil && fmt.Sprint(value) != fmt.Sprint(s.Const) {
+		v.require(false, path, fmt.Sprintf("must be %v", s.Const))
+		return
+	}
+	if len(s.Type) > 0 && !slices.ContainsFunc(s.Type, func(t string) bool { return isType(value, t) }) {
+		v.require(false, path, "must be of type "+strings.Join(s.Type, " or "))
+		return
+	}
+
+	switch value := value.(type) {
+	case string:
+		switch s.Format {
+		case "uuid":
+			_, err := uuid.Parse(value)
+			v.require(err == nil, path, "must be a UUID")
+		case "date-time":
+			_, err := time.Parse(time.RFC3339Nano, value)
+			v.require(err == nil, path, "must be an RFC 3339 date-time")
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			_, ok := value[name]
+			v.require(ok, join(path, name), "is required")
+		}
+		for name, property := range s.Properties {
+			if field, ok := value[name]; ok {
+				property.validate(v, join(path, name), field)
+			}
+		}
+	case []any:
+		if s.Items != nil {
+			for i, item := range value {
+				s.Items.validate(v, fmt.Sprintf("%s[%d]", path, i), item)
+			}
+		}
+	}
+}
+
+func isType(value any, t string) bool {
+	switch value := value.(type) {
+	case nil:
+		return t == "null"
+	case string:
+		return t == "string"
+	case bool:
+		return t == "boolean"
+	case json.Number:
+		_, err := value.Int64()
+		return t == "number" || t == "integer" && err == nil
+	case map[string]any:
+		return t == "object"
+	case []any:
+		return t == "array"
+	}
+	return false
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// breakingChanges lists how next breaks the payloads of s for their consumers, or their
+// consumers for the payloads of s. Adding an optional property is the only compatible
+// change.
+func (s *Schema) breakingChanges(path string, next *Schema) []string {
+	if path == "" {
+		path = "payload"
+	}
+	var changes []string
+	if !slices.Equal(s.Type, next.Type) {
+		changes = append(changes, fmt.Sprintf("%s: type changed from %v to %v", path, s.Type, next.Type))
+	}
+	if s.Format != next.Format {
+		changes = append(changes, fmt.Sprintf("%s: format changed from %q to %q", path, s.Format, next.Format))
+	}
+	if fmt.Sprint(s.Const) != fmt.Sprint(next.Const) {
+		changes = append(changes, fmt.Sprintf("%s: constant changed from %v to %v", path, s.Const, next.Const))
+	}
+
+	for _, name := range sortedKeys(s.Properties) {
+		property, ok := next.Properties[name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("%s: property %s removed", path, name))
+			continue
+		}
+		changes = append(changes, s.Properties[name].breakingChanges(path+"."+name, property)...)
+	}
+	for _, name := range next.Required {
+		switch {
+		case s.Properties[name] == nil:
+			changes = append(changes, fmt.Sprintf("%s: required property %s added", path, name))
+		case !slices.Contains(s.Required, name):
+			changes = append(changes, fmt.Sprintf("%s: property %s became required", path, name))
+		}
+	}
+	for _, name := range s.Required {
+		if _, ok := next.Properties[name]; ok && !slices.Contains(next.Required, name) {
+			changes = append(changes, fmt.Sprintf("%s: property %s became optional", path, name))
+		}
+	}
+
+	switch {
+	case s.Items != nil && next.Items != nil:
+		changes = append(changes, s.Items.breakingChanges(path+"[]", next.Items)...)
+	case s.Items != nil || next.Items != nil:
+		changes = append(changes, fmt.Sprintf("%s: items changed", path))
+	}
+	return changes
+}
+
+func sortedKeys(m map[string]*Schema) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// MarshalIndent encodes s as it is stored in the registry.
+func (s *Schema) MarshalIndent() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
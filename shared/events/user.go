@@ -0,0 +1,203 @@
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// User events are published by user-services to its exchange with the topic as routing
+// key. notification-services sends an email for each of them, except
+// UserErasureRequested, which the services holding personal data consume to scrub it.
+
+func init() {
+	register(func() Event { return &UserCreated{} })
+	register(func() Event { return &EmailVerificationRequested{} })
+	register(func() Event { return &PasswordResetRequested{} })
+	register(func() Event { return &PasswordResetCompleted{} })
+	register(func() Event { return &AccountUnlockRequested{} })
+	register(func() Event { return &PasswordlessLoginRequested{} })
+	register(func() Event { return &UserErasureRequested{} })
+}
+
+// UserCreated is published when an account is activated, for the welcome email.
+type UserCreated struct {
+	Meta
+	UserID uuid.UUID `json:"user_id"`
+	Email  string    `json:"email"`
+	Name   string    `json:"name,omitempty"`
+}
+
+func (*UserCreated) EventType() string  { return "UserCreated" }
+func (*UserCreated) Topic() string      { return "user.created" }
+func (*UserCreated) SchemaVersion() int { return 1 }
+
+func (e *UserCreated) Validate() error {
+	var v validator
+	v.id("user_id", e.UserID)
+	v.text("email", e.Email)
+	return v.err(e.EventType())
+}
+
+// EmailVerificationRequested carries the link confirming the email address of a new
+// account.
+type EmailVerificationRequested struct {
+	Meta
+	UserID           uuid.UUID `json:"user_id"`
+	Email            string    `json:"email"`
+	Name             string    `json:"name,omitempty"`
+	VerificationLink string    `json:"verification_link"`
+}
+
+func (*EmailVerificationRequested) EventType() string  { return "EmailVerificationRequested" }
+func (*EmailVerificationRequested) Topic() string      { return "user.email_verification" }
+func (*EmailVerificationRequested) SchemaVersion() int { return 1 }
+
+func (e *EmailVerificationRequested) Validate() error {
+	var v validator
+	v.id("user_id", e.UserID)
+	v.text("email", e.Email)
+	v.text("verification_link", e.VerificationLink)
+	return v.err(e.EventType())
+}
+
+// RequestOrigin describes where a request was made from: the IP address, the device
+// ("Chrome on macOS") and the time.
+type RequestOrigin struct {
+	IPAddr      string    `json:"ip_addr,omitempty"`
+	Device      string    `json:"device,omitempty"`
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// PasswordResetRequested carries a password reset link. SameDeviceRequired tells the
+// link only works in the browser that requested it.
+type PasswordResetRequested struct {
+	Meta
+	RequestOrigin
+	Email              string `json:"email"`
+	Name               string `json:"name,omitempty"`
+	ResetLink          string `json:"reset_link"`
+	ExpiresInMinutes   int    `json:"expires_in_minutes"`
+	SameDeviceRequired bool   `json:"same_device_required"`
+}
+
+func (*PasswordResetRequested) EventType() string  { return "PasswordResetRequested" }
+func (*PasswordResetRequested) Topic() string      { return "user.password_reset" }
+func (*PasswordResetRequested) SchemaVersion() int { return 1 }
+
+func (e *PasswordResetRequested) Validate() error {
+	var v validator
+	v.text("email", e.Email)
+	v.text("reset_link", e.ResetLink)
+	v.require(e.ExpiresInMinutes > 0, "expires_in_minutes", "must be positive")
+	v.time("requested_at", e.RequestedAt)
+	return v.err(e.EventType())
+}
+
+// PasswordResetCompleted is published once a password was reset, for the confirmation
+// email. SessionsRevoked tells the other sessions of the user were signed out.
+type PasswordResetCompleted struct {
+	Meta
+	RequestOrigin
+	Email           string `json:"email"`
+	Name            string `json:"name,omitempty"`
+	SessionsRevoked bool   `json:"sessions_revoked"`
+}
+
+func (*PasswordResetCompleted) EventType() string  { return "PasswordResetCompleted" }
+func (*PasswordResetCompleted) Topic() string      { return "user.password_reset_completed" }
+func (*PasswordResetCompleted) SchemaVersion() int { return 1 }
+
+func (e *PasswordResetCompleted) Validate() error {
+	var v validator
+	v.text("email", e.Email)
+	v.time("requested_at", e.RequestedAt)
+	return v.err(e.EventType())
+}
+
+// AccountUnlockRequested carries the link unlocking an account locked after failed
+// logins.
+type AccountUnlockRequested struct {
+	Meta
+	Email            string `json:"email"`
+	Name             string `json:"name,omitempty"`
+	UnlockLink       string `json:"unlock_link"`
+	ExpiresInMinutes int    `json:"expires_in_minutes"`
+}
+
+func (*AccountUnlockRequested) EventType() string  { return "AccountUnlockRequested" }
+func (*AccountUnlockRequested) Topic() string      { return "user.account_unlock" }
+func (*AccountUnlockRequested) SchemaVersion() int { return 1 }
+
+func (e *AccountUnlockRequested) Validate() error {
+	var v validator
+	v.text("email", e.Email)
+	v.text("unlock_link", e.UnlockLink)
+	v.require(e.ExpiresInMinutes > 0, "expires_in_minutes", "must be positive")
+	return v.err(e.EventType())
+}
+
+// PasswordlessLoginRequested carries a login link or a login code, depending on Method.
+type PasswordlessLoginRequested struct {
+	Meta
+	Email            string `json:"email"`
+	Name             string `json:"name,omitempty"`
+	Method           string `json:"method"`
+	LoginLink        string `json:"login_link,omitempty"`
+	LoginCode        string `json:"login_code,omitempty"`
+	ExpiresInMinutes int    `json:"expires_in_minutes"`
+}
+
+func (*PasswordlessLoginRequested) EventType() string  { return "PasswordlessLoginRequested" }
+func (*PasswordlessLoginRequested) Topic() string      { return "user.passwordless_login" }
+func (*PasswordlessLoginRequested) SchemaVersion() int { return 1 }
+
+func (e *PasswordlessLoginRequested) Validate() error {
+	var v validator
+	v.text("email", e.Email)
+	v.text("method", e.Method)
+	v.require(e.LoginLink != "" || e.LoginCode != "", "login_link", "or login_code is required")
+	v.require(e.ExpiresInMinutes > 0, "expires_in_minutes", "must be positive")
+	return v.err(e.EventType())
+}
+
+// UserErasureRequested is published once user-services anonymized an erased account. Each
+// service in Services scrubs or pseudonymizes its copies of the user's data and confirms
+// with a POST to CallbackPath on user-services before Deadline. The email is only sent
+// as EmailSHA256, the hex SHA-256 of the lower-cased address, for copies keyed by
+// address; it is empty when the account no longer existed.
+type UserErasureRequested struct {
+	Meta
+	ErasureID    uuid.UUID `json:"erasure_id"`
+	UserID       uuid.UUID `json:"user_id"`
+	EmailSHA256  string    `json:"email_sha256,omitempty"`
+	RequestedAt  time.Time `json:"requested_at"`
+	Services     []string  `json:"services"`
+	CallbackPath string    `json:"callback_path"`
+	Deadline     time.Time `json:"deadline"`
+}
+
+func (*UserErasureRequested) EventType() string  { return "UserErasureRequested" }
+func (*UserErasureRequested) Topic() string      { return "user.erasure_requested" }
+func (*UserErasureRequested) SchemaVersion() int { return 1 }
+
+func (e *UserErasureRequested) Validate() error {
+	var v validator
+	v.id("erasure_id", e.ErasureID)
+	v.id("user_id", e.UserID)
+	v.time("requested_at", e.RequestedAt)
+	v.require(len(e.Services) > 0, "services", "is required")
+	v.text("callback_path", e.CallbackPath)
+	v.time("deadline", e.Deadline)
+	return v.err(e.EventType())
+}
+
+// Requests reports whether the erasure waits for the confirmation of service.
+func (e *UserErasureRequested) Requests(service string) bool {
+	for _, s := range e.Services {
+		if s == service {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package flags
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DefaultRefresh is how often a Client reloads the definitions. Rollout changes take
+// effect within one refresh.
+const DefaultRefresh = 30 * time.Second
+
+// Config configures a Client.
+type Config struct {
+	// Refresh is how long a snapshot is served before it is reloaded, DefaultRefresh when 0
+	Refresh time.Duration
+}
+
+// Client evaluates flags against an in-memory snapshot of a Source.
+type Client struct {
+	source  Source
+	refresh time.Duration
+
+	mu       sync.RWMutex
+	flags    map[string]Flag
+	loadedAt time.Time
+}
+
+// New returns a client reading source. With a nil source every flag is off, so a service
+// started without its store runs the features it ships by default.
+func New(source Source, cfg Config) *Client {
+	if cfg.Refresh <= 0 {
+		cfg.Refresh = DefaultRefresh
+	}
+	return &Client{source: source, refresh: cfg.Refresh}
+}
+
+// Flags returns all flag definitions. When the source is unavailable the last snapshot
+// is served so a Redis blip does not flip features off.
+func (c *Client) Flags(ctx context.Context) map[string]Flag {
+	c.mu.RLock()
+	flags, fresh := c.flags, time.Since(c.loadedAt) < c.refresh
+	c.mu.RUnlock()
+	if fresh || c.source == nil {
+		return flags
+	}
+
+	loaded, err := c.source.List(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to load feature flags", "error", err)
+		return flags
+	}
+
+	c.mu.Lock()
+	c.flags = loaded
+	c.loadedAt = time.Now()
+	c.mu.Unlock()
+
+	return loaded
+}
+
+// Invalidate drops the snapshot, so the next evaluation reloads the definitions. The
+// admin API calls it after a change so the service serving it applies the change at once.
+func (c *Client) Invalidate() {
+	c.mu.Lock()
+	c.loadedAt = time.Time{}
+	c.mu.Unlock()
+}
+
+// IsEnabled evaluates a single flag for subject. Unknown flags are off.
+func (c *Client) IsEnabled(ctx context.Context, name string, subject Subject) bool {
+	flag, ok := c.Flags(ctx)[name]
+	return ok && flag.EnabledFor(subject)
+}
+
+// Evaluate returns the state of every known flag for subject.
+func (c *Client) Evaluate(ctx context.Context, subject Subject) map[string]bool {
+	flags := c.Flags(ctx)
+	result := make(map[string]bool, len(flags))
+	for name, flag := range flags {
+		result[name] = flag.EnabledFor(subject)
+	}
+	return result
+}
@@ -0,0 +1,117 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestEnabledFor(t *testing.T) {
+	flag := Flag{Name: "new_checkout_flow", Enabled: true, Users: []string{"u-1"}, Roles: []string{"admin"}}
+
+	cases := []struct {
+		name    string
+		flag    Flag
+		subject Subject
+		want    bool
+	}{
+		{"listed user", flag, Subject{UserID: "u-1"}, true},
+		{"listed role", flag, Subject{UserID: "u-2", Role: "admin"}, true},
+		{"other user at 0%", flag, Subject{UserID: "u-2"}, false},
+		{"anonymous at 100%", Flag{Name: "f", Enabled: true, RolloutPercent: 100}, Subject{}, true},
+		{"anonymous at 50%", Flag{Name: "f", Enabled: true, RolloutPercent: 50}, Subject{}, false},
+		{"disabled", Flag{Name: "f", RolloutPercent: 100, Users: []string{"u-1"}}, Subject{UserID: "u-1"}, false},
+	}
+	for _, tc := range cases {
+		if got := tc.flag.EnabledFor(tc.subject); got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRolloutIsStableAsItGrows(t *testing.T) {
+	on := func(percent int) map[string]bool {
+		flag := Flag{Name: "new_checkout_flow", Enabled: true, RolloutPercent: percent}
+		users := make(map[string]bool)
+		for i := range 1000 {
+			id := fmt.Sprintf("user-%d", i)
+			if flag.EnabledFor(Subject{UserID: id}) {
+				users[id] = true
+			}
+		}
+		return users
+	}
+
+	at10, at50 := on(10), on(50)
+	if len(at10) < 50 || len(at10) > 150 {
+		t.Fatalf("10%% rollout reached %d of 1000 users", len(at10))
+	}
+	for id := range at10 {
+		if !at50[id] {
+			t.Fatalf("%s lost the feature when the rollout grew", id)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := (Flag{Name: "new_checkout_flow", RolloutPercent: 20}).Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for _, flag := range []Flag{{Name: ""}, {Name: "New Checkout"}, {Name: "f", RolloutPercent: 101}} {
+		if flag.Validate() == nil {
+			t.Errorf("%+v validated", flag)
+		}
+	}
+}
+
+type failingSource struct{ err error }
+
+func (s *failingSource) List(context.Context) (map[string]Flag, error) { return nil, s.err }
+
+// switchingSource lets a test replace the source behind a client.
+type switchingSource struct{ Source }
+
+func TestClientServesLastSnapshotWhenSourceFails(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(Flag{Name: "f", Enabled: true, RolloutPercent: 100})
+	source := &switchingSource{Source: store}
+	client := New(source, Config{Refresh: time.Nanosecond})
+
+	if !client.IsEnabled(ctx, "f", Subject{}) {
+		t.Fatal("flag off")
+	}
+	source.Source = &failingSource{err: errors.New("connection refused")}
+	time.Sleep(time.Millisecond)
+	if !client.IsEnabled(ctx, "f", Subject{}) {
+		t.Fatal("flag flipped off while the source was down")
+	}
+}
+
+func TestClientInvalidate(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	client := New(store, Config{Refresh: time.Hour})
+
+	if client.IsEnabled(ctx, "f", Subject{}) {
+		t.Fatal("unknown flag on")
+	}
+	if err := store.Put(ctx, Flag{Name: "f", Enabled: true, RolloutPercent: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if client.IsEnabled(ctx, "f", Subject{}) {
+		t.Fatal("snapshot reloaded before the refresh")
+	}
+	client.Invalidate()
+	if !client.IsEnabled(ctx, "f", Subject{}) {
+		t.Fatal("flag off after Invalidate")
+	}
+}
+
+func TestClientWithoutSource(t *testing.T) {
+	client := New(nil, Config{})
+	if flags := client.Evaluate(context.Background(), Subject{UserID: "u-1"}); len(flags) != 0 {
+		t.Fatalf("unexpected flags %v", flags)
+	}
+}
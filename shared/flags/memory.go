@@ -0,0 +1,53 @@
+package flags
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryStore keeps flags in memory, for tests and local runs without Redis.
+type MemoryStore struct {
+	mu    sync.RWMutex
+	flags map[string]Flag
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore returns a store holding flags.
+func NewMemoryStore(flags ...Flag) *MemoryStore {
+	s := &MemoryStore{flags: make(map[string]Flag, len(flags))}
+	for _, flag := range flags {
+		s.flags[flag.Name] = flag
+	}
+	return s
+}
+
+func (s *MemoryStore) List(context.Context) (map[string]Flag, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	flags := make(map[string]Flag, len(s.flags))
+	for name, flag := range s.flags {
+		flags[name] = flag
+	}
+	return flags, nil
+}
+
+func (s *MemoryStore) Put(_ context.Context, flag Flag) error {
+	if err := flag.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flags[flag.Name] = flag
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.flags[name]; !ok {
+		return ErrNotFound
+	}
+	delete(s.flags, name)
+	return nil
+}
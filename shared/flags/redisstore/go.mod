@@ -0,0 +1,15 @@
+module github.com/ductan2/microservice-app/shared/flags/redisstore
+
+go 1.24.0
+
+require (
+	github.com/ductan2/microservice-app/shared/flags v0.0.0
+	github.com/redis/go-redis/v9 v9.14.0
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+)
+
+replace github.com/ductan2/microservice-app/shared/flags => ../
@@ -0,0 +1,80 @@
+// Package redisstore keeps feature flags in a Redis hash, one JSON-encoded flag per
+// field, shared by every service reading the same Redis:
+//
+//	HSET feature_flags new_checkout_flow '{"enabled":true,"rollout_percent":20}'
+//	HSET feature_flags maintenance_banner true
+//
+// A plain boolean is a kill switch, on or off for everyone. It is a separate module so
+// services without Redis do not pull in the Redis client.
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+
+	"github.com/ductan2/microservice-app/shared/flags"
+	"github.com/redis/go-redis/v9"
+)
+
+// Key is the hash holding the flag definitions
+const Key = "feature_flags"
+
+// Store implements flags.Store.
+type Store struct {
+	client redis.UniversalClient
+}
+
+var _ flags.Store = (*Store)(nil)
+
+// New returns a Store reading and writing the hash Key through client.
+func New(client redis.UniversalClient) *Store {
+	return &Store{client: client}
+}
+
+func (s *Store) List(ctx context.Context) (map[string]flags.Flag, error) {
+	raw, err := s.client.HGetAll(ctx, Key).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := make(map[string]flags.Flag, len(raw))
+	for name, value := range raw {
+		var flag flags.Flag
+		if err := json.Unmarshal([]byte(value), &flag); err != nil {
+			// Allow plain booleans for simple kill switches
+			enabled, parseErr := strconv.ParseBool(value)
+			if parseErr != nil {
+				slog.WarnContext(ctx, "invalid feature flag definition", "flag", name, "error", err)
+				continue
+			}
+			flag = flags.Flag{Enabled: enabled, RolloutPercent: 100}
+		}
+		flag.Name = name
+		loaded[name] = flag
+	}
+	return loaded, nil
+}
+
+func (s *Store) Put(ctx context.Context, flag flags.Flag) error {
+	if err := flag.Validate(); err != nil {
+		return err
+	}
+	value, err := json.Marshal(flag)
+	if err != nil {
+		return err
+	}
+	return s.client.HSet(ctx, Key, flag.Name, value).Err()
+}
+
+func (s *Store) Delete(ctx context.Context, name string) error {
+	removed, err := s.client.HDel(ctx, Key, name).Result()
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return flags.ErrNotFound
+	}
+	return nil
+}
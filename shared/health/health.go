@@ -0,0 +1,242 @@
+// Package health implements the liveness, readiness and health endpoints shared by the
+// Go services, so orchestrators and Traefik probe every service the same way:
+//
+//   - /livez answers 200 while the process serves HTTP, without checking anything, so an
+//     outage of a dependency never gets the service restarted
+//   - /readyz answers 503 while a critical dependency is down or the service is shutting
+//     down, so it is taken out of the load balancer until it can serve requests
+//   - /healthz reports every dependency with its latency and error, and the status of
+//     the service: up, degraded when only optional dependencies are down, or down
+//
+// A service registers a probe per dependency on a Checker. Probes run concurrently,
+// each bounded by a timeout, and their results are cached for a few seconds so
+// frequent probing does not load the dependencies.
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults of Config.
+const (
+	DefaultTimeout  = 2 * time.Second
+	DefaultCacheTTL = 5 * time.Second
+)
+
+// Level tells how a dependency being down affects the service.
+type Level int
+
+const (
+	// Critical dependencies are needed to serve requests: the service is not ready
+	// while one is down.
+	Critical Level = iota
+	// Optional dependencies only degrade the service, such as a downstream service
+	// whose features are turned off when it is unavailable.
+	Optional
+)
+
+// String returns critical or optional.
+func (l Level) String() string {
+	if l == Optional {
+		return "optional"
+	}
+	return "critical"
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (l *Level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "critical":
+		*l = Critical
+	case "optional":
+		*l = Optional
+	default:
+		return fmt.Errorf("health: unknown level %q", text)
+	}
+	return nil
+}
+
+// Status is the state of a dependency or of the whole service.
+type Status string
+
+const (
+	StatusUp       Status = "up"
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+)
+
+// Probe checks one dependency, returning an error when it is unavailable. It must
+// return once ctx is done.
+type Probe func(ctx context.Context) error
+
+// Result is the outcome of the last run of a probe.
+type Result struct {
+	Name      string        `json:"name"`
+	Level     Level         `json:"level"`
+	Status    Status        `json:"status"`
+	Latency   time.Duration `json:"-"`
+	LatencyMs float64       `json:"latency_ms"`
+	Error     string        `json:"error,omitempty"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+// Report is the health of the service and of each of its dependencies.
+type Report struct {
+	Service string   `json:"service,omitempty"`
+	Status  Status   `json:"status"`
+	Ready   bool     `json:"ready"`
+	Checks  []Result `json:"checks"`
+}
+
+// Config configures a Checker.
+type Config struct {
+	// Service names the service in the reports.
+	Service string
+	// Timeout bounds each probe, DefaultTimeout when 0.
+	Timeout time.Duration
+	// CacheTTL is how long the results of the probes are reused, DefaultCacheTTL when
+	// 0. A negative value runs the probes on every check.
+	CacheTTL time.Duration
+	// Observe, when set, is called with the result of every probe run, such as to
+	// record it in the metrics of the service.
+	Observe func(Result)
+}
+
+type check struct {
+	name  string
+	level Level
+	probe Probe
+}
+
+// Checker runs the probes registered on it and serves the health endpoints.
+type Checker struct {
+	cfg Config
+
+	draining atomic.Bool
+
+	mu      sync.Mutex
+	checks  []check
+	results []Result
+	checked time.Time
+}
+
+// New returns a checker without probes: it reports the service up until some are
+// registered.
+func New(cfg Config) *Checker {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.CacheTTL == 0 {
+		cfg.CacheTTL = DefaultCacheTTL
+	}
+	return &Checker{cfg: cfg}
+}
+
+// Register adds a probe checking the dependency name. Registering the same name again
+// replaces the probe.
+func (c *Checker) Register(name string, level Level, probe Probe) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checked = time.Time{}
+	for i := range c.checks {
+		if c.checks[i].name == name {
+			c.checks[i] = check{name: name, level: level, probe: probe}
+			return
+		}
+	}
+	c.checks = append(c.checks, check{name: name, level: level, probe: probe})
+}
+
+// Shutdown marks the service as shutting down: readiness fails from then on, so the
+// load balancer stops sending requests while the server drains the ones in flight.
+func (c *Checker) Shutdown() {
+	c.draining.Store(true)
+}
+
+// ShuttingDown reports whether Shutdown was called.
+func (c *Checker) ShuttingDown() bool {
+	return c.draining.Load()
+}
+
+// Check returns the health of the service, running the probes again when their cached
+// results are older than the cache TTL. Concurrent calls share one run.
+func (c *Checker) Check(ctx context.Context) Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cfg.CacheTTL < 0 || time.Since(c.checked) >= c.cfg.CacheTTL {
+		c.results = c.run(ctx, c.checks)
+		c.checked = time.Now()
+	}
+	return c.report()
+}
+
+// run runs checks concurrently and returns their results in the same order.
+func (c *Checker) run(ctx context.Context, checks []check) []Result {
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, ch := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.probe(ctx, ch)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func (c *Checker) probe(ctx context.Context, ch check) (result Result) {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	result = Result{Name: ch.name, Level: ch.level, Status: StatusUp, CheckedAt: start}
+	defer func() {
+		// A panicking probe reports its dependency down instead of crashing the service
+		if v := recover(); v != nil {
+			result.Status = StatusDown
+			result.Error = "probe panicked"
+		}
+		result.Latency = time.Since(start)
+		result.LatencyMs = float64(result.Latency.Microseconds()) / 1000
+		if c.cfg.Observe != nil {
+			c.cfg.Observe(result)
+		}
+	}()
+	if err := ch.probe(ctx); err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// report summarizes the cached results. c.mu must be held.
+func (c *Checker) report() Report {
+	report := Report{
+		Service: c.cfg.Service,
+		Status:  StatusUp,
+		Ready:   !c.draining.Load(),
+		Checks:  append([]Result{}, c.results...),
+	}
+	for _, result := range report.Checks {
+		if result.Status == StatusUp {
+			continue
+		}
+		if result.Level == Critical {
+			report.Status = StatusDown
+			report.Ready = false
+		} else if report.Status == StatusUp {
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
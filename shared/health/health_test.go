@@ -0,0 +1,142 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func up(context.Context) error   { return nil }
+func down(context.Context) error { return errors.New("connection refused") }
+
+func TestCheckLevels(t *testing.T) {
+	tests := []struct {
+		name      string
+		critical  Probe
+		optional  Probe
+		status    Status
+		ready     bool
+		httpReady int
+	}{
+		{"all up", up, up, StatusUp, true, http.StatusOK},
+		{"optional down", up, down, StatusDegraded, true, http.StatusOK},
+		{"critical down", down, up, StatusDown, false, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(Config{Service: "test"})
+			c.Register("postgres", Critical, tt.critical)
+			c.Register("user-services", Optional, tt.optional)
+
+			report := c.Check(context.Background())
+			if report.Status != tt.status || report.Ready != tt.ready {
+				t.Fatalf("got status %s ready %v, want %s %v", report.Status, report.Ready, tt.status, tt.ready)
+			}
+			if len(report.Checks) != 2 || report.Checks[0].Name != "postgres" || report.Checks[1].Name != "user-services" {
+				t.Fatalf("unexpected checks %+v", report.Checks)
+			}
+
+			rec := httptest.NewRecorder()
+			c.Ready().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, ReadyPath, nil))
+			if rec.Code != tt.httpReady {
+				t.Fatalf("readyz answered %d, want %d", rec.Code, tt.httpReady)
+			}
+			rec = httptest.NewRecorder()
+			c.Live().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, LivePath, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("livez answered %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestCheckCachesResults(t *testing.T) {
+	var calls atomic.Int32
+	c := New(Config{CacheTTL: time.Hour})
+	c.Register("redis", Critical, func(context.Context) error {
+		calls.Add(1)
+		return nil
+	})
+	for range 3 {
+		c.Check(context.Background())
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("probe ran %d times, want 1", n)
+	}
+
+	// Registering a probe invalidates the cached results
+	c.Register("rabbitmq", Critical, up)
+	if report := c.Check(context.Background()); len(report.Checks) != 2 || calls.Load() != 2 {
+		t.Fatalf("got %d checks after %d runs", len(report.Checks), calls.Load())
+	}
+}
+
+func TestProbeTimeoutAndPanic(t *testing.T) {
+	var observed []Result
+	c := New(Config{Timeout: 10 * time.Millisecond, CacheTTL: -1, Observe: func(r Result) {
+		observed = append(observed, r)
+	}})
+	c.Register("slow", Critical, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	report := c.Check(context.Background())
+	if report.Checks[0].Status != StatusDown || report.Checks[0].Error != context.DeadlineExceeded.Error() {
+		t.Fatalf("unexpected result %+v", report.Checks[0])
+	}
+
+	c.Register("slow", Optional, func(context.Context) error { panic("boom") })
+	report = c.Check(context.Background())
+	if report.Status != StatusDegraded || report.Checks[0].Error != "probe panicked" {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if len(observed) != 2 {
+		t.Fatalf("observed %d results, want 2", len(observed))
+	}
+}
+
+func TestShutdownFailsReadiness(t *testing.T) {
+	c := New(Config{})
+	c.Register("postgres", Critical, up)
+	c.Shutdown()
+
+	rec := httptest.NewRecorder()
+	c.Ready().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, ReadyPath, nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("readyz answered %d while shutting down", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	c.Health().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, HealthPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("healthz answered %d while shutting down", rec.Code)
+	}
+	var report Report
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Ready || report.Status != StatusUp {
+		t.Fatalf("unexpected report %+v", report)
+	}
+}
+
+func TestHTTPGet(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	probe := HTTPGet(srv.Client(), srv.URL+LivePath)
+	if err := probe(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	status = http.StatusBadGateway
+	if err := probe(context.Background()); err == nil {
+		t.Fatal("expected an error for a 502")
+	}
+}
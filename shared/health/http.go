@@ -0,0 +1,88 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Paths the health endpoints are served on.
+const (
+	LivePath   = "/livez"
+	ReadyPath  = "/readyz"
+	HealthPath = "/healthz"
+)
+
+// Live answers 200 while the process serves HTTP.
+func (c *Checker) Live() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": string(StatusUp)})
+	})
+}
+
+// Ready answers 200 with the health report while the service can serve requests, and
+// 503 while a critical dependency is down or the service is shutting down.
+func (c *Checker) Ready() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := c.Check(r.Context())
+		status := http.StatusOK
+		if !report.Ready {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, report)
+	})
+}
+
+// Health answers with the health report: 200 when the service is up or degraded, 503
+// when it is down.
+func (c *Checker) Health() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := c.Check(r.Context())
+		status := http.StatusOK
+		if report.Status == StatusDown {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, report)
+	})
+}
+
+// Handle registers the health endpoints on mux.
+func (c *Checker) Handle(mux *http.ServeMux) {
+	mux.Handle("GET "+LivePath, c.Live())
+	mux.Handle("GET "+ReadyPath, c.Ready())
+	mux.Handle("GET "+HealthPath, c.Health())
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// HTTPGet returns a probe requesting url with client, http.DefaultClient when nil, that
+// fails unless the response status is 2xx. Pointed at the /livez of another service, it
+// checks that service without cascading its dependencies into this one.
+func HTTPGet(client *http.Client, url string) Probe {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
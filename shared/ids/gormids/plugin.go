@@ -0,0 +1,67 @@
+// Package gormids gives the records created through a gorm.DB a UUIDv7 primary key.
+package gormids
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"github.com/ductan2/microservice-app/shared/ids"
+)
+
+var uuidType = reflect.TypeOf(uuid.UUID{})
+
+// Plugin sets the primary key of a record being created to ids.New() when it is a
+// uuid.UUID left zero. IDs set by the caller are kept, and a column default such as
+// uuid_generate_v4() only applies to rows inserted outside GORM. Install it with
+// db.Use(gormids.Plugin{}).
+type Plugin struct{}
+
+// Name implements gorm.Plugin.
+func (Plugin) Name() string {
+	return "ids"
+}
+
+// Initialize implements gorm.Plugin.
+func (Plugin) Initialize(db *gorm.DB) error {
+	return db.Callback().Create().Before("gorm:create").Register("ids:assign", assign)
+}
+
+func assign(tx *gorm.DB) {
+	stmt := tx.Statement
+	if stmt.Schema == nil {
+		return
+	}
+	field := stmt.Schema.PrioritizedPrimaryField
+	if field == nil || field.FieldType != uuidType {
+		return
+	}
+
+	ctx := stmt.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	switch value := stmt.ReflectValue; value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := range value.Len() {
+			setID(ctx, tx, field, reflect.Indirect(value.Index(i)))
+		}
+	case reflect.Struct:
+		setID(ctx, tx, field, value)
+	}
+}
+
+func setID(ctx context.Context, tx *gorm.DB, field *schema.Field, record reflect.Value) {
+	if record.Kind() != reflect.Struct {
+		return
+	}
+	if _, zero := field.ValueOf(ctx, record); !zero {
+		return
+	}
+	if err := field.Set(ctx, record, ids.New()); err != nil {
+		_ = tx.AddError(err)
+	}
+}
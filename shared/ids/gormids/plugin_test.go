@@ -0,0 +1,63 @@
+package gormids
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type order struct {
+	ID    uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Total int64
+}
+
+type counter struct {
+	ID   int64 `gorm:"primaryKey"`
+	Name string
+}
+
+func statement(t *testing.T, model, dest any) *gorm.DB {
+	t.Helper()
+	s, err := schema.Parse(model, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := &gorm.DB{Config: &gorm.Config{}}
+	tx.Statement = &gorm.Statement{DB: tx, Context: context.Background(), Schema: s, ReflectValue: reflect.Indirect(reflect.ValueOf(dest))}
+	return tx
+}
+
+func TestAssign(t *testing.T) {
+	var single order
+	assign(statement(t, &order{}, &single))
+	if single.ID.Version() != 7 {
+		t.Errorf("single record: got %s", single.ID)
+	}
+
+	kept := uuid.New()
+	batch := []*order{{}, {ID: kept}, {}}
+	assign(statement(t, &order{}, &batch))
+	if batch[0].ID.Version() != 7 || batch[2].ID.Version() != 7 || batch[0].ID == batch[2].ID {
+		t.Errorf("batch: got %s and %s", batch[0].ID, batch[2].ID)
+	}
+	if batch[1].ID != kept {
+		t.Errorf("an ID set by the caller was replaced: %s", batch[1].ID)
+	}
+
+	values := []order{{}, {}}
+	assign(statement(t, &order{}, &values))
+	if values[0].ID.Version() != 7 || values[1].ID.Version() != 7 {
+		t.Errorf("slice of values: got %s and %s", values[0].ID, values[1].ID)
+	}
+
+	c := counter{Name: "x"}
+	assign(statement(t, &counter{}, &c))
+	if c.ID != 0 {
+		t.Errorf("integer key changed to %d", c.ID)
+	}
+}
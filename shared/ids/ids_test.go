@@ -0,0 +1,49 @@
+package ids
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNewIsTimeSortable(t *testing.T) {
+	before := time.Now().Truncate(time.Millisecond)
+	prev := New()
+	for range 10000 {
+		id := New()
+		if id.Version() != 7 || id.Variant() != uuid.RFC4122 {
+			t.Fatalf("%s: version %d, variant %s", id, id.Version(), id.Variant())
+		}
+		if bytes.Compare(prev[:], id[:]) >= 0 {
+			t.Fatalf("%s is not after %s", id, prev)
+		}
+		prev = id
+	}
+
+	created, ok := Time(prev)
+	if !ok || created.Before(before) || created.After(time.Now()) {
+		t.Errorf("Time = %v, %v; want between %v and now", created, ok, before)
+	}
+	if _, ok := Time(uuid.New()); ok {
+		t.Error("Time of a version 4 ID: want false")
+	}
+}
+
+func TestMinAt(t *testing.T) {
+	at := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+	lowest := MinAt(at)
+	if lowest.Version() != 7 {
+		t.Fatalf("version %d", lowest.Version())
+	}
+	if created, ok := Time(lowest); !ok || !created.Equal(at) {
+		t.Errorf("Time(MinAt) = %v, %v", created, ok)
+	}
+	if now := New(); bytes.Compare(lowest[:], now[:]) >= 0 {
+		t.Errorf("MinAt(%v) = %s is not below a later ID %s", at, lowest, now)
+	}
+	if later := MinAt(at.Add(time.Millisecond)); bytes.Compare(lowest[:], later[:]) >= 0 {
+		t.Errorf("MinAt is not increasing: %s, %s", lowest, later)
+	}
+}
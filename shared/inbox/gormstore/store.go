@@ -0,0 +1,96 @@
+// Package gormstore keeps the inbox in a Postgres table through GORM. The table needs
+// these columns:
+//
+//	consumer     TEXT NOT NULL
+//	message_id   TEXT NOT NULL
+//	locked_until TIMESTAMPTZ NOT NULL
+//	processed_at TIMESTAMPTZ
+//	PRIMARY KEY (consumer, message_id)
+//
+// with an index on processed_at for DeleteProcessedBefore.
+package gormstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/inbox"
+	"gorm.io/gorm"
+)
+
+// DefaultTable is the table the services keep their inbox in.
+const DefaultTable = "inbox"
+
+// Store implements inbox.Store.
+type Store struct {
+	db    *gorm.DB
+	table string
+}
+
+var _ inbox.Store = (*Store)(nil)
+
+// New returns a Store on the given table, DefaultTable when empty.
+func New(db *gorm.DB, table string) *Store {
+	if table == "" {
+		table = DefaultTable
+	}
+	return &Store{db: db, table: table}
+}
+
+// WithTx returns a Store writing through tx. Completing a message with it commits the
+// record with the changes the message caused, or rolls both back.
+func (s *Store) WithTx(tx *gorm.DB) *Store {
+	return &Store{db: tx, table: s.table}
+}
+
+func (s *Store) Claim(ctx context.Context, consumerName, messageID string, lease time.Duration) (inbox.Status, error) {
+	now := time.Now()
+	// A new row, or an existing one whose claim has ended without the message being processed
+	result := s.db.WithContext(ctx).Exec(fmt.Sprintf(`INSERT INTO %[1]s (consumer, message_id, locked_until) VALUES (?, ?, ?)
+ON CONFLICT (consumer, message_id) DO UPDATE SET locked_until = EXCLUDED.locked_until
+WHERE %[1]s.processed_at IS NULL AND %[1]s.locked_until < ?`, s.table),
+		consumerName, messageID, now.Add(lease), now)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return inbox.Claimed, nil
+	}
+
+	var processedAt sql.NullTime
+	err := s.db.WithContext(ctx).Table(s.table).
+		Select("processed_at").
+		Where("consumer = ? AND message_id = ?", consumerName, messageID).
+		Row().Scan(&processedAt)
+	if err != nil {
+		return 0, err
+	}
+	if processedAt.Valid {
+		return inbox.Processed, nil
+	}
+	return inbox.InProgress, nil
+}
+
+func (s *Store) Complete(ctx context.Context, consumerName, messageID string) error {
+	now := time.Now()
+	return s.db.WithContext(ctx).Exec(fmt.Sprintf(`INSERT INTO %s (consumer, message_id, locked_until, processed_at) VALUES (?, ?, ?, ?)
+ON CONFLICT (consumer, message_id) DO UPDATE SET processed_at = EXCLUDED.processed_at`, s.table),
+		consumerName, messageID, now, now).Error
+}
+
+func (s *Store) Release(ctx context.Context, consumerName, messageID string) error {
+	return s.db.WithContext(ctx).Table(s.table).
+		Where("consumer = ? AND message_id = ? AND processed_at IS NULL", consumerName, messageID).
+		Delete(nil).Error
+}
+
+// DeleteProcessedBefore deletes the records of messages processed before the given
+// time. A message published again after that is processed again, so the retention
+// should exceed the time a duplicate can take to arrive.
+func (s *Store) DeleteProcessedBefore(ctx context.Context, before time.Time) error {
+	return s.db.WithContext(ctx).Table(s.table).
+		Where("processed_at < ?", before).
+		Delete(nil).Error
+}
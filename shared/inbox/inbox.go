@@ -0,0 +1,122 @@
+// Package inbox makes RabbitMQ consumers idempotent. Delivery is at least once: the
+// outbox relay may publish an event twice and a consumer that fails before its ack
+// gets the message again. An Inbox records the message IDs each consumer has processed
+// in a Store and skips the ones it has already seen.
+package inbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/consumer"
+)
+
+// DefaultLease is how long a claim on a message lasts when Config.Lease is not set.
+const DefaultLease = time.Minute
+
+// ErrInProgress is returned for a message another worker is processing. The consumer
+// retries it, and it is processed again if that worker died before completing it.
+var ErrInProgress = errors.New("message is being processed")
+
+// Status is the result of a claim.
+type Status int
+
+const (
+	// Claimed messages are processed by the caller
+	Claimed Status = iota
+	// Processed messages are duplicates
+	Processed
+	// InProgress messages are held by the lease of another claim
+	InProgress
+)
+
+// Store records the messages processed per consumer. Implementations must make Claim
+// atomic: of concurrent claims on one message, only one succeeds.
+type Store interface {
+	// Claim reserves messageID for consumerName until the lease ends
+	Claim(ctx context.Context, consumerName, messageID string, lease time.Duration) (Status, error)
+	// Complete records messageID as processed
+	Complete(ctx context.Context, consumerName, messageID string) error
+	// Release drops the claim on a message that was not processed, so it can be retried
+	Release(ctx context.Context, consumerName, messageID string) error
+}
+
+// Config tunes an Inbox.
+type Config struct {
+	// Consumer names the consumer in the store; two consumers of the same message, such
+	// as two services, keep separate records
+	Consumer string
+	// Lease bounds the time a message can take to process. When a worker dies while
+	// processing a message, the message is retried once its lease ends, so the retries
+	// of the consumer should span more than the lease
+	Lease time.Duration
+}
+
+// Inbox deduplicates the messages of one consumer.
+type Inbox struct {
+	store Store
+	cfg   Config
+}
+
+func New(store Store, cfg Config) *Inbox {
+	if cfg.Lease <= 0 {
+		cfg.Lease = DefaultLease
+	}
+	return &Inbox{store: store, cfg: cfg}
+}
+
+// Handler wraps handler so it runs once per message ID. A duplicate is acked without
+// calling handler; a failed message is released, so its retries run it again.
+// Messages without a MessageId cannot be told apart and always run.
+//
+// A handler writing to the database of the store can record the message in the same
+// transaction as its changes (gormstore.Store.WithTx), so that a crash between the two
+// cannot process the message twice.
+func (i *Inbox) Handler(handler consumer.Handler) consumer.Handler {
+	return func(ctx context.Context, msg consumer.Message) (err error) {
+		id := msg.MessageId
+		if id == "" {
+			return handler(ctx, msg)
+		}
+
+		status, err := i.store.Claim(ctx, i.cfg.Consumer, id, i.cfg.Lease)
+		if err != nil {
+			return fmt.Errorf("failed to claim message %s: %w", id, err)
+		}
+		switch status {
+		case Processed:
+			slog.InfoContext(ctx, "skipping duplicate message", "consumer", i.cfg.Consumer, "message_id", id, "routing_key", msg.RoutingKey)
+			return nil
+		case InProgress:
+			return ErrInProgress
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				i.release(ctx, id)
+				panic(r)
+			}
+		}()
+		if err := handler(ctx, msg); err != nil {
+			i.release(ctx, id)
+			return err
+		}
+
+		if err := i.store.Complete(ctx, i.cfg.Consumer, id); err != nil {
+			// Acked anyway: retrying would run the handler a second time. Duplicates
+			// are still told apart until the lease ends
+			slog.WarnContext(ctx, "failed to record processed message", "consumer", i.cfg.Consumer, "message_id", id, "error", err)
+		}
+		return nil
+	}
+}
+
+func (i *Inbox) release(ctx context.Context, id string) {
+	if err := i.store.Release(ctx, i.cfg.Consumer, id); err != nil {
+		// The retries get ErrInProgress until the lease ends
+		slog.WarnContext(ctx, "failed to release message", "consumer", i.cfg.Consumer, "message_id", id, "error", err)
+	}
+}
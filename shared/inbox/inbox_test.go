@@ -0,0 +1,153 @@
+package inbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/consumer"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+type record struct {
+	lockedUntil time.Time
+	processed   bool
+}
+
+type memoryStore struct {
+	mu      sync.Mutex
+	records map[string]*record
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{records: make(map[string]*record)}
+}
+
+func (s *memoryStore) Claim(_ context.Context, consumerName, messageID string, lease time.Duration) (Status, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.records[consumerName+"/"+messageID]
+	switch {
+	case ok && r.processed:
+		return Processed, nil
+	case ok && time.Now().Before(r.lockedUntil):
+		return InProgress, nil
+	}
+	s.records[consumerName+"/"+messageID] = &record{lockedUntil: time.Now().Add(lease)}
+	return Claimed, nil
+}
+
+func (s *memoryStore) Complete(_ context.Context, consumerName, messageID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[consumerName+"/"+messageID] = &record{processed: true}
+	return nil
+}
+
+func (s *memoryStore) Release(_ context.Context, consumerName, messageID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.records[consumerName+"/"+messageID]; ok && !r.processed {
+		delete(s.records, consumerName+"/"+messageID)
+	}
+	return nil
+}
+
+func message(id string) consumer.Message {
+	return consumer.Message{Delivery: amqp.Delivery{MessageId: id, RoutingKey: "order.paid"}, Attempt: 1}
+}
+
+func TestHandlerSkipsDuplicates(t *testing.T) {
+	store := newMemoryStore()
+	calls := 0
+	handler := New(store, Config{Consumer: "content-services"}).Handler(func(context.Context, consumer.Message) error {
+		calls++
+		return nil
+	})
+
+	for range 3 {
+		if err := handler(context.Background(), message("42")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times", calls)
+	}
+
+	// Another consumer of the same message keeps its own record
+	other := New(store, Config{Consumer: "notification-services"}).Handler(func(context.Context, consumer.Message) error {
+		calls++
+		return nil
+	})
+	if err := other(context.Background(), message("42")); err != nil || calls != 2 {
+		t.Errorf("other consumer: err = %v, calls = %d", err, calls)
+	}
+}
+
+func TestHandlerReleasesFailedMessage(t *testing.T) {
+	store := newMemoryStore()
+	failing := true
+	calls := 0
+	handler := New(store, Config{Consumer: "content-services"}).Handler(func(context.Context, consumer.Message) error {
+		calls++
+		if failing {
+			return errors.New("database unavailable")
+		}
+		return nil
+	})
+
+	if err := handler(context.Background(), message("42")); err == nil {
+		t.Fatal("failure not returned")
+	}
+	failing = false
+	if err := handler(context.Background(), message("42")); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("handler ran %d times, want the retry to run it", calls)
+	}
+}
+
+func TestHandlerReleasesOnPanic(t *testing.T) {
+	store := newMemoryStore()
+	handler := New(store, Config{Consumer: "content-services"}).Handler(func(context.Context, consumer.Message) error {
+		panic("nil map")
+	})
+
+	func() {
+		defer func() { recover() }()
+		handler(context.Background(), message("42"))
+	}()
+
+	if status, _ := store.Claim(context.Background(), "content-services", "42", time.Minute); status != Claimed {
+		t.Errorf("message still claimed after panic: %v", status)
+	}
+}
+
+func TestHandlerRetriesMessageInProgress(t *testing.T) {
+	store := newMemoryStore()
+	store.Claim(context.Background(), "content-services", "42", time.Minute)
+
+	handler := New(store, Config{Consumer: "content-services"}).Handler(func(context.Context, consumer.Message) error {
+		t.Error("handler ran while another worker holds the message")
+		return nil
+	})
+	if err := handler(context.Background(), message("42")); !errors.Is(err, ErrInProgress) {
+		t.Errorf("err = %v, want ErrInProgress", err)
+	}
+}
+
+func TestHandlerRunsMessagesWithoutID(t *testing.T) {
+	calls := 0
+	handler := New(newMemoryStore(), Config{Consumer: "content-services"}).Handler(func(context.Context, consumer.Message) error {
+		calls++
+		return nil
+	})
+	handler(context.Background(), message(""))
+	handler(context.Background(), message(""))
+	if calls != 2 {
+		t.Errorf("handler ran %d times", calls)
+	}
+}
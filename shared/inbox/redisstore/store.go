@@ -0,0 +1,80 @@
+// Package redisstore keeps the inbox in Redis, for consumers without a Postgres
+// database. Each message is a key holding "processing" while claimed, with the lease as
+// expiry, and "processed" for the retention once completed. It is a separate module so
+// Postgres services do not pull in the Redis client.
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/inbox"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// KeyPrefix starts the keys of the inbox, followed by the consumer and message ID
+	KeyPrefix = "inbox:"
+	// DefaultRetention is how long processed messages are remembered
+	DefaultRetention = 7 * 24 * time.Hour
+
+	processing = "processing"
+	processed  = "processed"
+)
+
+// release deletes a key only while it is claimed, never the record of a processed message.
+var release = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`)
+
+// Store implements inbox.Store.
+type Store struct {
+	client    redis.UniversalClient
+	retention time.Duration
+}
+
+var _ inbox.Store = (*Store)(nil)
+
+// New returns a Store keeping processed messages for retention, DefaultRetention when 0.
+// A message published again after that is processed again.
+func New(client redis.UniversalClient, retention time.Duration) *Store {
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	return &Store{client: client, retention: retention}
+}
+
+func (s *Store) key(consumerName, messageID string) string {
+	return KeyPrefix + consumerName + ":" + messageID
+}
+
+func (s *Store) Claim(ctx context.Context, consumerName, messageID string, lease time.Duration) (inbox.Status, error) {
+	key := s.key(consumerName, messageID)
+	claimed, err := s.client.SetNX(ctx, key, processing, lease).Result()
+	if err != nil {
+		return 0, err
+	}
+	if claimed {
+		return inbox.Claimed, nil
+	}
+
+	value, err := s.client.Get(ctx, key).Result()
+	switch {
+	case errors.Is(err, redis.Nil):
+		// The lease ended between the two calls
+		return s.Claim(ctx, consumerName, messageID, lease)
+	case err != nil:
+		return 0, err
+	case value == processed:
+		return inbox.Processed, nil
+	default:
+		return inbox.InProgress, nil
+	}
+}
+
+func (s *Store) Complete(ctx context.Context, consumerName, messageID string) error {
+	return s.client.Set(ctx, s.key(consumerName, messageID), processed, s.retention).Err()
+}
+
+func (s *Store) Release(ctx context.Context, consumerName, messageID string) error {
+	return release.Run(ctx, s.client, []string{s.key(consumerName, messageID)}, processing).Err()
+}
@@ -0,0 +1,85 @@
+package internalauth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultTTL is the lifetime of a service token.
+const DefaultTTL = time.Minute
+
+// minKeyLength is the shortest key accepted, the size of an HMAC-SHA256 block.
+const minKeyLength = 32
+
+// developmentKeyID and developmentKey sign the tokens of local environments that set no
+// key. Every service falls back to the same key so they trust each other out of the box;
+// ConfigFromEnv refuses it in production.
+const (
+	developmentKeyID = "development"
+	developmentKey   = "internal-auth-development-key-do-not-use-in-production"
+)
+
+// Config configures the signing and verification of service tokens.
+type Config struct {
+	// Service is the name of this service: the issuer of the tokens it signs and the
+	// audience of the tokens it accepts
+	Service string
+	// Keys are the keys accepted, by key ID
+	Keys map[string][]byte
+	// KeyID is the key tokens are signed with
+	KeyID string
+	// TTL is the lifetime of the tokens signed, DefaultTTL when 0
+	TTL time.Duration
+	// Development is set when the development key is used
+	Development bool
+}
+
+// ConfigFromEnv reads the keys from INTERNAL_AUTH_KEYS, a comma separated list of
+// id:secret pairs, and signs with the key named by INTERNAL_AUTH_KEY_ID, the first listed
+// by default. Rotating a key means listing the new one in every service, then switching
+// INTERNAL_AUTH_KEY_ID, then removing the old one. INTERNAL_AUTH_TTL overrides the token
+// lifetime.
+//
+// Without INTERNAL_AUTH_KEYS the development key is used, unless ENVIRONMENT is
+// production.
+func ConfigFromEnv(service string) (Config, error) {
+	cfg := Config{Service: service, Keys: make(map[string][]byte), TTL: DefaultTTL}
+	if ttl, err := time.ParseDuration(os.Getenv("INTERNAL_AUTH_TTL")); err == nil && ttl > 0 {
+		cfg.TTL = ttl
+	}
+
+	raw := strings.TrimSpace(os.Getenv("INTERNAL_AUTH_KEYS"))
+	if raw == "" {
+		if strings.EqualFold(os.Getenv("ENVIRONMENT"), "production") {
+			return Config{}, errors.New("internalauth: INTERNAL_AUTH_KEYS must be set in production")
+		}
+		cfg.Keys[developmentKeyID] = []byte(developmentKey)
+		cfg.KeyID = developmentKeyID
+		cfg.Development = true
+		return cfg, nil
+	}
+
+	for _, pair := range strings.Split(raw, ",") {
+		id, secret, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || id == "" {
+			return Config{}, fmt.Errorf("internalauth: INTERNAL_AUTH_KEYS entry %q is not id:secret", pair)
+		}
+		if len(secret) < minKeyLength {
+			return Config{}, fmt.Errorf("internalauth: key %q is shorter than %d bytes", id, minKeyLength)
+		}
+		cfg.Keys[id] = []byte(secret)
+		if cfg.KeyID == "" {
+			cfg.KeyID = id
+		}
+	}
+	if id := os.Getenv("INTERNAL_AUTH_KEY_ID"); id != "" {
+		if _, ok := cfg.Keys[id]; !ok {
+			return Config{}, fmt.Errorf("internalauth: INTERNAL_AUTH_KEY_ID %q is not in INTERNAL_AUTH_KEYS", id)
+		}
+		cfg.KeyID = id
+	}
+	return cfg, nil
+}
@@ -0,0 +1,5 @@
+module github.com/ductan2/microservice-app/shared/internalauth
+
+go 1.24.0
+
+require github.com/golang-jwt/jwt/v5 v5.3.0
@@ -0,0 +1,182 @@
+package internalauth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const testKey = "0123456789abcdef0123456789abcdef"
+
+func testConfig(service string) Config {
+	return Config{Service: service, Keys: map[string][]byte{"k1": []byte(testKey)}, KeyID: "k1"}
+}
+
+func newPair(t *testing.T, from, to string) (*Signer, *Verifier) {
+	t.Helper()
+	signer, err := NewSigner(testConfig(from))
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := NewVerifier(testConfig(to))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, verifier
+}
+
+func identity() http.Header {
+	header := http.Header{}
+	header.Set("X-User-ID", "8a3c1d6e-1111-4222-8333-944455556666")
+	header.Set("X-User-Email", "ada@example.com")
+	header.Set("X-Session-ID", "0f0e0d0c-1111-4222-8333-944455556666")
+	return header
+}
+
+func TestVerifyAcceptsSignedIdentity(t *testing.T) {
+	signer, verifier := newPair(t, "bff-services", "user-services")
+	token, err := signer.Sign("user-services", identity())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := verifier.Verify(token, identity())
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if claims.Issuer != "bff-services" || claims.UserID != identity().Get("X-User-ID") || claims.Role != "" {
+		t.Errorf("claims = %+v", claims)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	signer, verifier := newPair(t, "bff-services", "user-services")
+	token, _ := signer.Sign("user-services", identity())
+
+	forged := identity()
+	forged.Set("X-User-ID", "00000000-0000-4000-8000-000000000000")
+	elevated := identity()
+	elevated.Set("X-User-Role", "admin")
+	otherAudience, _ := signer.Sign("order-services", identity())
+
+	other, err := NewSigner(Config{Service: "bff-services", Keys: map[string][]byte{"k1": []byte("another key of thirty two bytes!")}, KeyID: "k1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrongKey, _ := other.Sign("user-services", identity())
+
+	expiredSigner, _ := NewSigner(testConfig("bff-services"))
+	expiredSigner.now = func() time.Time { return time.Now().Add(-time.Hour) }
+	expired, _ := expiredSigner.Sign("user-services", identity())
+
+	cases := []struct {
+		name   string
+		token  string
+		header http.Header
+		want   error
+	}{
+		{"forged user", token, forged, ErrIdentityMissing},
+		{"added role", token, elevated, ErrIdentityMissing},
+		{"other audience", otherAudience, identity(), ErrAudience},
+		{"wrong key", wrongKey, identity(), ErrSignature},
+		{"expired", expired, identity(), ErrExpired},
+		{"static token", "internal-service-token", identity(), ErrMalformed},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := verifier.Verify(tc.token, tc.header); !errors.Is(err, tc.want) {
+				t.Errorf("err = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	signer, err := NewSigner(Config{Service: "order-services", Keys: map[string][]byte{"k2": []byte(testKey + "-2")}, KeyID: "k2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _ := signer.Sign("content-services", nil)
+
+	old, _ := NewVerifier(testConfig("content-services"))
+	if _, err := old.Verify(token, http.Header{}); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("verifier without the new key: err = %v", err)
+	}
+
+	rotated := testConfig("content-services")
+	rotated.Keys["k2"] = []byte(testKey + "-2")
+	verifier, _ := NewVerifier(rotated)
+	if _, err := verifier.Verify(token, http.Header{}); err != nil {
+		t.Errorf("verifier with both keys: %v", err)
+	}
+}
+
+func TestAuthenticateStripsUnsignedIdentity(t *testing.T) {
+	_, verifier := newPair(t, "bff-services", "content-services")
+	r := httptest.NewRequest(http.MethodGet, "/graphql", nil)
+	r.Header = identity()
+
+	claims, err := verifier.Authenticate(r)
+	if claims != nil || err != nil {
+		t.Fatalf("Authenticate = %v, %v", claims, err)
+	}
+	if got := r.Header.Get("X-User-ID"); got != "" {
+		t.Errorf("X-User-ID kept: %q", got)
+	}
+}
+
+func TestTransportSignsRequests(t *testing.T) {
+	signer, verifier := newPair(t, "bff-services", "order-services")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, err := verifier.Authenticate(r)
+		if err != nil || claims == nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(claims.Issuer + " " + r.Header.Get("X-User-ID")))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(signer, "order-services", nil)}
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("X-User-ID", "u1")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	if req.Header.Get(Header) != "" {
+		t.Error("Transport modified the caller's request")
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("INTERNAL_AUTH_KEYS", "")
+	t.Setenv("ENVIRONMENT", "production")
+	if _, err := ConfigFromEnv("user-services"); err == nil {
+		t.Error("production without keys accepted")
+	}
+
+	t.Setenv("ENVIRONMENT", "development")
+	cfg, err := ConfigFromEnv("user-services")
+	if err != nil || !cfg.Development {
+		t.Errorf("development fallback: %+v, %v", cfg, err)
+	}
+
+	t.Setenv("INTERNAL_AUTH_KEYS", "old:"+testKey+", new:"+testKey+"-new")
+	t.Setenv("INTERNAL_AUTH_KEY_ID", "new")
+	cfg, err = ConfigFromEnv("user-services")
+	if err != nil || cfg.KeyID != "new" || len(cfg.Keys) != 2 || cfg.Development {
+		t.Errorf("keys: %+v, %v", cfg, err)
+	}
+
+	t.Setenv("INTERNAL_AUTH_KEYS", "short:secret")
+	if _, err := ConfigFromEnv("user-services"); err == nil {
+		t.Error("short key accepted")
+	}
+}
@@ -0,0 +1,124 @@
+package internalauth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// clockSkew is the difference tolerated between the clocks of two services.
+const clockSkew = 30 * time.Second
+
+// Signer signs the service tokens of one service.
+type Signer struct {
+	service string
+	keyID   string
+	key     []byte
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+// NewSigner returns a signer for the service and key of cfg.
+func NewSigner(cfg Config) (*Signer, error) {
+	key, ok := cfg.Keys[cfg.KeyID]
+	if !ok || cfg.Service == "" {
+		return nil, ErrUnknownKey
+	}
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Signer{service: cfg.Service, keyID: cfg.KeyID, key: key, ttl: ttl, now: time.Now}, nil
+}
+
+// Sign returns a token for a call to audience carrying the identity headers of header.
+func (s *Signer) Sign(audience string, header http.Header) (string, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", err
+	}
+	now := s.now()
+	claims := &Claims{
+		Issuer:    s.service,
+		Audience:  audience,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(s.ttl)),
+		ID:        hex.EncodeToString(id[:]),
+	}
+	claims.bindIdentity(header)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = s.keyID
+	return token.SignedString(s.key)
+}
+
+// Verifier checks the service tokens presented to one service.
+type Verifier struct {
+	service string
+	keys    map[string][]byte
+	now     func() time.Time
+}
+
+// NewVerifier returns a verifier accepting the keys of cfg for calls to its service.
+func NewVerifier(cfg Config) (*Verifier, error) {
+	if len(cfg.Keys) == 0 || cfg.Service == "" {
+		return nil, ErrUnknownKey
+	}
+	return &Verifier{service: cfg.Service, keys: cfg.Keys, now: time.Now}, nil
+}
+
+// Verify checks token for a request with the identity headers of header and returns
+// its claims.
+func (v *Verifier) Verify(token string, header http.Header) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, v.key,
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(v.service),
+		jwt.WithLeeway(clockSkew),
+		jwt.WithIssuedAt(),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(v.now),
+	)
+	switch {
+	case errors.Is(err, ErrUnknownKey):
+		return nil, ErrUnknownKey
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid):
+		return nil, ErrSignature
+	case errors.Is(err, jwt.ErrTokenExpired), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return nil, ErrExpired
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return nil, ErrAudience
+	case err != nil, claims.Issuer == "":
+		return nil, ErrMalformed
+	}
+	if !claims.matchesIdentity(header) {
+		return nil, ErrIdentityMissing
+	}
+	return claims, nil
+}
+
+// key returns the key named by the kid header of token.
+func (v *Verifier) key(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := v.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+// Authenticate checks the service token of r and returns its claims. A request without
+// one gets nil claims and its identity headers removed, so that handlers reading them
+// only ever see identities vouched for by a service token.
+func (v *Verifier) Authenticate(r *http.Request) (*Claims, error) {
+	token := r.Header.Get(Header)
+	if token == "" {
+		stripIdentity(r.Header)
+		return nil, nil
+	}
+	return v.Verify(token, r.Header)
+}
@@ -0,0 +1,34 @@
+package internalauth
+
+import "net/http"
+
+// Transport signs outgoing requests to one service with a service token carrying their
+// identity headers.
+type Transport struct {
+	signer   *Signer
+	audience string
+	base     http.RoundTripper
+}
+
+// NewTransport wraps base (http.DefaultTransport when nil) for the calls to audience, the
+// name of the service called.
+func NewTransport(signer *Signer, audience string, base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{signer: signer, audience: audience, base: base}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.signer.Sign(t.audience, req.Header)
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set(Header, token)
+	return t.base.RoundTrip(req)
+}
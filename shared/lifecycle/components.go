@@ -0,0 +1,73 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPServer serves srv and stops it with http.Server.Shutdown, which stops accepting
+// connections and waits for the requests in flight.
+func HTTPServer(name string, srv *http.Server) Component {
+	return Component{
+		Name: name,
+		Run: func(context.Context) error {
+			if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+		Stop: srv.Shutdown,
+	}
+}
+
+// Worker runs run, a background loop such as an outbox relay or a consumer, until its
+// context is cancelled.
+func Worker(name string, run func(ctx context.Context)) Component {
+	return Component{
+		Name: name,
+		Run: func(ctx context.Context) error {
+			run(ctx)
+			return nil
+		},
+	}
+}
+
+// Closer closes c, such as a connection pool or a message broker connection.
+func Closer(name string, c io.Closer) Component {
+	return Component{
+		Name: name,
+		Stop: func(context.Context) error { return c.Close() },
+	}
+}
+
+// Hook calls fn on shutdown.
+func Hook(name string, fn func()) Component {
+	return Component{
+		Name: name,
+		Stop: func(context.Context) error {
+			fn()
+			return nil
+		},
+	}
+}
+
+// Drain calls notReady, which fails the readiness probe of the service, then waits for
+// delay so the load balancer notices before the servers stop accepting connections.
+// Added last, it is the first component stopped.
+func Drain(notReady func(), delay time.Duration) Component {
+	return Component{
+		Name: "readiness",
+		Stop: func(ctx context.Context) error {
+			notReady()
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+			}
+			return nil
+		},
+		Timeout: delay + time.Second,
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/ductan2/microservice-app/shared/lifecycle
+
+go 1.24.0
@@ -0,0 +1,186 @@
+// Package lifecycle runs the components of a Go service and shuts them down in order.
+//
+// Components are added in the order they depend on each other: connection pools first,
+// then the workers and consumers using them, then the HTTP servers, and last the
+// readiness switch. Run starts them, waits for SIGINT or SIGTERM, and stops them in the
+// reverse order, each within a timeout of its own, so the load balancer stops routing
+// before the servers drain, the servers drain before the workers stop, and the
+// workers stop before the pools they use are closed.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DefaultTimeout bounds the stop of a component without a timeout of its own.
+const DefaultTimeout = 10 * time.Second
+
+// Component is a part of the service with a lifetime, such as an HTTP server, a
+// background worker or a connection pool.
+type Component struct {
+	// Name identifies the component in the logs.
+	Name string
+	// Run, when set, runs the component until its context is cancelled. Returning an
+	// error before the shutdown starts shuts the service down.
+	Run func(ctx context.Context) error
+	// Stop, when set, stops the component. It is called before the context of Run is
+	// cancelled, so a server can drain the requests in flight.
+	Stop func(ctx context.Context) error
+	// Timeout bounds Stop and the return of Run, DefaultTimeout when 0.
+	Timeout time.Duration
+}
+
+// WithTimeout returns c stopping within timeout.
+func (c Component) WithTimeout(timeout time.Duration) Component {
+	c.Timeout = timeout
+	return c
+}
+
+// Config configures a Manager.
+type Config struct {
+	// Signals start the shutdown, SIGINT and SIGTERM when empty. A second one exits at
+	// once.
+	Signals []os.Signal
+}
+
+// Manager runs components and stops them in the reverse order they were added.
+type Manager struct {
+	cfg Config
+
+	mu         sync.Mutex
+	components []*running
+}
+
+type running struct {
+	Component
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New returns a manager without components.
+func New(cfg Config) *Manager {
+	if len(cfg.Signals) == 0 {
+		cfg.Signals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	}
+	return &Manager{cfg: cfg}
+}
+
+// Add adds c, to be stopped before the components added earlier. Components must be
+// added before Run.
+func (m *Manager) Add(c Component) {
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.components = append(m.components, &running{Component: c})
+}
+
+// Run starts the components and blocks until a signal arrives, ctx is cancelled or
+// the Run of a component fails, then stops every component. It returns the error of
+// the failed component joined with those of the stops.
+func (m *Manager) Run(ctx context.Context) error {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, m.cfg.Signals...)
+	defer signal.Stop(signals)
+
+	failed := make(chan error, 1)
+	m.mu.Lock()
+	for _, c := range m.components {
+		m.start(c, failed)
+	}
+	m.mu.Unlock()
+
+	var runErr error
+	select {
+	case sig := <-signals:
+		slog.Info("shutting down", "signal", sig.String())
+	case <-ctx.Done():
+		slog.Info("shutting down", "reason", ctx.Err())
+	case runErr = <-failed:
+		slog.Error("shutting down after a component failed", "error", runErr)
+	}
+
+	stopped := make(chan struct{})
+	defer close(stopped)
+	go func() {
+		select {
+		case sig := <-signals:
+			slog.Error("forcing exit", "signal", sig.String())
+			os.Exit(1)
+		case <-stopped:
+		}
+	}()
+	return errors.Join(runErr, m.Shutdown())
+}
+
+func (m *Manager) start(c *running, failed chan<- error) {
+	if c.Run == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	go func() {
+		defer close(c.done)
+		err := c.Run(ctx)
+		if err != nil && ctx.Err() == nil {
+			select {
+			case failed <- fmt.Errorf("%s: %w", c.Name, err):
+			default:
+			}
+		}
+	}()
+}
+
+// Shutdown stops the components in the reverse order they were added and returns
+// their errors joined. Run calls it; a service stopping for another reason, such as a
+// failed startup, calls it directly.
+func (m *Manager) Shutdown() error {
+	m.mu.Lock()
+	components := m.components
+	m.components = nil
+	m.mu.Unlock()
+
+	var errs []error
+	for i := len(components) - 1; i >= 0; i-- {
+		if err := components[i].stop(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	slog.Info("shutdown complete")
+	return errors.Join(errs...)
+}
+
+func (c *running) stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+	start := time.Now()
+
+	var err error
+	if c.Stop != nil {
+		err = c.Stop(ctx)
+	}
+	if c.cancel != nil {
+		c.cancel()
+		select {
+		case <-c.done:
+		case <-ctx.Done():
+			err = errors.Join(err, context.DeadlineExceeded)
+		}
+	}
+	if err != nil {
+		slog.Warn("component did not stop cleanly", "component", c.Name, "elapsed", time.Since(start), "error", err)
+		return fmt.Errorf("%s: %w", c.Name, err)
+	}
+	slog.Info("component stopped", "component", c.Name, "elapsed", time.Since(start))
+	return nil
+}
@@ -0,0 +1,131 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder records the order components stop in.
+type recorder struct {
+	mu      sync.Mutex
+	stopped []string
+}
+
+func (r *recorder) add(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stopped = append(r.stopped, name)
+}
+
+func TestRunStopsInReverseOrder(t *testing.T) {
+	var rec recorder
+	m := New(Config{})
+	m.Add(Hook("postgres", func() { rec.add("postgres") }))
+	m.Add(Worker("relay", func(ctx context.Context) {
+		<-ctx.Done()
+		rec.add("relay")
+	}))
+	m.Add(Component{Name: "http", Stop: func(context.Context) error {
+		rec.add("http")
+		return nil
+	}})
+	m.Add(Drain(func() { rec.add("readiness") }, time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := m.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"readiness", "http", "relay", "postgres"}
+	if !slices.Equal(rec.stopped, want) {
+		t.Fatalf("stopped %v, want %v", rec.stopped, want)
+	}
+}
+
+func TestRunShutsDownWhenAComponentFails(t *testing.T) {
+	var rec recorder
+	m := New(Config{})
+	m.Add(Hook("postgres", func() { rec.add("postgres") }))
+	m.Add(Component{Name: "http", Run: func(context.Context) error {
+		return errors.New("address already in use")
+	}})
+
+	err := m.Run(context.Background())
+	if err == nil || err.Error() != "http: address already in use" {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !slices.Equal(rec.stopped, []string{"postgres"}) {
+		t.Fatalf("stopped %v", rec.stopped)
+	}
+}
+
+func TestStopTimeout(t *testing.T) {
+	m := New(Config{})
+	m.Add(Component{Name: "stuck", Run: func(context.Context) error {
+		select {}
+	}}.WithTimeout(10 * time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	err := m.Run(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("shutdown took %v", elapsed)
+	}
+}
+
+func TestHTTPServerDrains(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	srv := &http.Server{Addr: addr, Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusNoContent)
+	})}
+	m := New(Config{})
+	m.Add(HTTPServer("http", srv))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.Run(ctx) }()
+
+	status := make(chan int, 1)
+	go func() {
+		for {
+			resp, err := http.Get("http://" + addr)
+			if err == nil {
+				resp.Body.Close()
+				status <- resp.StatusCode
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+	<-started
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	if code := <-status; code != http.StatusNoContent {
+		t.Fatalf("request in flight answered %d", code)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,92 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// handler adds the request-scoped fields of the context to each record and samples the
+// debug and info ones.
+type handler struct {
+	next    slog.Handler
+	sampler *sampler
+}
+
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	if h.sampler != nil && r.Level < slog.LevelWarn && !h.sampler.allow(r.Level, r.Message) {
+		return nil
+	}
+	if ctx != nil {
+		if requestID := RequestIDFromContext(ctx); requestID != "" {
+			r.AddAttrs(slog.String("request_id", requestID))
+		}
+		if userID := UserIDFromContext(ctx); userID != "" {
+			r.AddAttrs(slog.String("user_id", userID))
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+		}
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &handler{next: h.next.WithAttrs(attrs), sampler: h.sampler}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{next: h.next.WithGroup(name), sampler: h.sampler}
+}
+
+// maxSampledKeys bounds the messages counted in one tick; records beyond it are kept.
+const maxSampledKeys = 4096
+
+type sampleKey struct {
+	level   slog.Level
+	message string
+}
+
+type sampler struct {
+	cfg Sampling
+	now func() time.Time
+
+	mu          sync.Mutex
+	windowStart time.Time
+	counts      map[sampleKey]int
+}
+
+func newSampler(cfg Sampling) *sampler {
+	if cfg.Tick <= 0 {
+		cfg.Tick = time.Second
+	}
+	return &sampler{cfg: cfg, now: time.Now, counts: make(map[sampleKey]int)}
+}
+
+func (s *sampler) allow(level slog.Level, message string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now := s.now(); now.Sub(s.windowStart) >= s.cfg.Tick {
+		s.windowStart = now
+		clear(s.counts)
+	}
+	key := sampleKey{level: level, message: message}
+	n, counted := s.counts[key]
+	if !counted && len(s.counts) >= maxSampledKeys {
+		return true
+	}
+	n++
+	s.counts[key] = n
+	if n <= s.cfg.Initial {
+		return true
+	}
+	return s.cfg.Thereafter > 0 && (n-s.cfg.Initial)%s.cfg.Thereafter == 0
+}
@@ -0,0 +1,139 @@
+// Package logging is the structured logger of the Go services, built on log/slog.
+//
+// Setup installs a JSON logger as the slog default, which also routes the standard log
+// package through it. Records logged with a context (slog.InfoContext, ErrorContext, ...)
+// carry the request ID and user ID stored on it with WithRequestID and WithUserID, and
+// the trace and span IDs of its span (shared/telemetry), so the log lines of one request
+// can be found across services. Debug and info records can be sampled per service to
+// keep noisy paths from flooding the log pipeline; warnings and errors never are.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config configures a logger.
+type Config struct {
+	// Service is the service attribute of every record
+	Service string
+	// Level is the lowest level logged
+	Level slog.Level
+	// Format is "json" (the default) or "text"
+	Format string
+	// Sampling limits debug and info records, disabled when Initial is 0
+	Sampling Sampling
+}
+
+// Sampling keeps the first Initial records with the same level and message in each Tick,
+// then every Thereafter-th one (none when Thereafter is 0).
+type Sampling struct {
+	Initial    int
+	Thereafter int
+	Tick       time.Duration
+}
+
+// ConfigFromEnv reads LOG_LEVEL (debug, info, warn or error; default info), LOG_FORMAT
+// (json or text; default json) and the sampling of the service from
+// LOG_SAMPLING_INITIAL, LOG_SAMPLING_THEREAFTER and LOG_SAMPLING_TICK (default 1s).
+func ConfigFromEnv(service string) Config {
+	cfg := Config{
+		Service: service,
+		Level:   slog.LevelInfo,
+		Format:  "json",
+		Sampling: Sampling{
+			Initial:    envInt("LOG_SAMPLING_INITIAL"),
+			Thereafter: envInt("LOG_SAMPLING_THEREAFTER"),
+			Tick:       time.Second,
+		},
+	}
+	if level := os.Getenv("LOG_LEVEL"); level != "" {
+		_ = cfg.Level.UnmarshalText([]byte(level))
+	}
+	if strings.EqualFold(os.Getenv("LOG_FORMAT"), "text") {
+		cfg.Format = "text"
+	}
+	if tick, err := time.ParseDuration(os.Getenv("LOG_SAMPLING_TICK")); err == nil && tick > 0 {
+		cfg.Sampling.Tick = tick
+	}
+	return cfg
+}
+
+func envInt(key string) int {
+	n, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// New returns a logger writing to w.
+func New(cfg Config, w io.Writer) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: cfg.Level}
+	var base slog.Handler
+	if cfg.Format == "text" {
+		base = slog.NewTextHandler(w, opts)
+	} else {
+		base = slog.NewJSONHandler(w, opts)
+	}
+	if cfg.Service != "" {
+		base = base.WithAttrs([]slog.Attr{slog.String("service", cfg.Service)})
+	}
+
+	h := &handler{next: base}
+	if cfg.Sampling.Initial > 0 {
+		h.sampler = newSampler(cfg.Sampling)
+	}
+	return slog.New(h)
+}
+
+// Setup makes a logger writing to stdout the slog default and returns it. Output of the
+// standard log package goes through it at info level.
+func Setup(cfg Config) *slog.Logger {
+	logger := New(cfg, os.Stdout)
+	slog.SetDefault(logger)
+	return logger
+}
+
+type requestIDKey struct{}
+
+type userIDKey struct{}
+
+// WithRequestID stores the request ID on ctx for the records logged with it.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored on ctx, or "" when none is set.
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// WithUserID stores the authenticated user on ctx for the records logged with it.
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// UserIDFromContext returns the user ID stored on ctx, or "" when none is set.
+func UserIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	userID, _ := ctx.Value(userIDKey{}).(string)
+	return userID
+}
+
+// Fatal logs msg at error level with the default logger and exits with status 1.
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
@@ -0,0 +1,151 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/telemetry"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("log line is not JSON: %q", line)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestContextFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(Config{Service: "order-services", Level: slog.LevelInfo}, &buf)
+
+	ctx, span := telemetry.Start(context.Background(), "checkout", trace.SpanKindServer)
+	ctx = WithUserID(WithRequestID(ctx, "req-1"), "user-1")
+	logger.InfoContext(ctx, "order created", "order_id", "o-1")
+
+	records := decodeLines(t, &buf)
+	if len(records) != 1 {
+		t.Fatalf("logged %d records, want 1", len(records))
+	}
+	want := map[string]any{
+		"service":    "order-services",
+		"level":      "INFO",
+		"msg":        "order created",
+		"order_id":   "o-1",
+		"request_id": "req-1",
+		"user_id":    "user-1",
+		"trace_id":   span.SpanContext().TraceID().String(),
+		"span_id":    span.SpanContext().SpanID().String(),
+	}
+	for key, value := range want {
+		if records[0][key] != value {
+			t.Errorf("%s = %v, want %v", key, records[0][key], value)
+		}
+	}
+}
+
+func TestLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(Config{Level: slog.LevelWarn}, &buf)
+	logger.Info("dropped")
+	logger.Warn("kept")
+
+	records := decodeLines(t, &buf)
+	if len(records) != 1 || records[0]["msg"] != "kept" {
+		t.Fatalf("unexpected records %v", records)
+	}
+}
+
+func TestSampling(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(Config{Level: slog.LevelDebug, Sampling: Sampling{Initial: 2, Thereafter: 3, Tick: time.Hour}}, &buf)
+	for range 8 {
+		logger.Info("cache miss")
+	}
+	for range 3 {
+		logger.Error("payment failed")
+	}
+
+	var infos, errors int
+	for _, record := range decodeLines(t, &buf) {
+		switch record["msg"] {
+		case "cache miss":
+			infos++
+		case "payment failed":
+			errors++
+		}
+	}
+	// records 1 and 2, then every third: 5 and 8
+	if infos != 4 {
+		t.Errorf("kept %d of 8 info records, want 4", infos)
+	}
+	if errors != 3 {
+		t.Errorf("kept %d of 3 error records, want all", errors)
+	}
+}
+
+func TestSamplingWindowResets(t *testing.T) {
+	now := time.Unix(0, 0)
+	s := newSampler(Sampling{Initial: 1, Tick: time.Second})
+	s.now = func() time.Time { return now }
+
+	if !s.allow(slog.LevelInfo, "tick") || s.allow(slog.LevelInfo, "tick") {
+		t.Fatalf("expected only the first record of the window")
+	}
+	now = now.Add(time.Second)
+	if !s.allow(slog.LevelInfo, "tick") {
+		t.Fatalf("expected the window to reset")
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("LOG_FORMAT", "text")
+	t.Setenv("LOG_SAMPLING_INITIAL", "100")
+	t.Setenv("LOG_SAMPLING_THEREAFTER", "10")
+
+	cfg := ConfigFromEnv("user-services")
+	if cfg.Service != "user-services" || cfg.Level != slog.LevelDebug || cfg.Format != "text" {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+	if cfg.Sampling != (Sampling{Initial: 100, Thereafter: 10, Tick: time.Second}) {
+		t.Fatalf("unexpected sampling %+v", cfg.Sampling)
+	}
+}
+
+func TestLogRequestLevels(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(New(Config{}, &buf))
+	defer slog.SetDefault(previous)
+
+	ctx := WithRequestID(context.Background(), "req-2")
+	LogRequest(ctx, HTTPRequest{Method: "GET", Route: "/orders/:id", Path: "/orders/1", Status: 200})
+	LogRequest(ctx, HTTPRequest{Method: "GET", Path: "/missing", Status: 404})
+	LogRequest(ctx, HTTPRequest{Method: "POST", Route: "/orders", Path: "/orders", Status: 502})
+
+	records := decodeLines(t, &buf)
+	levels := []string{"INFO", "WARN", "ERROR"}
+	for i, record := range records {
+		if record["level"] != levels[i] || record["request_id"] != "req-2" {
+			t.Errorf("record %d = %v", i, record)
+		}
+	}
+	if _, ok := records[1]["route"]; ok {
+		t.Errorf("unmatched request logged a route: %v", records[1])
+	}
+}
@@ -0,0 +1,41 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// HTTPRequest describes a served HTTP request for its access log record.
+type HTTPRequest struct {
+	Method string
+	// Route is the matched route template, "" when no route matched
+	Route    string
+	Path     string
+	Status   int
+	Latency  time.Duration
+	ClientIP string
+}
+
+// LogRequest writes the access log record of a request with the default logger: info
+// for a success, warn for a client error and error for a server error.
+func LogRequest(ctx context.Context, req HTTPRequest) {
+	level := slog.LevelInfo
+	switch {
+	case req.Status >= 500:
+		level = slog.LevelError
+	case req.Status >= 400:
+		level = slog.LevelWarn
+	}
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("path", req.Path),
+		slog.Int("status", req.Status),
+		slog.Duration("latency", req.Latency),
+		slog.String("client_ip", req.ClientIP),
+	}
+	if req.Route != "" {
+		attrs = append(attrs, slog.String("route", req.Route))
+	}
+	slog.LogAttrs(ctx, level, "request served", attrs...)
+}
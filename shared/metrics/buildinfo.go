@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"runtime"
+	"runtime/debug"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Version and Revision describe the running build. The Dockerfiles set them with
+//
+//	-ldflags "-X github.com/ductan2/microservice-app/shared/metrics.Version=... -X github.com/ductan2/microservice-app/shared/metrics.Revision=..."
+//
+// When unset, Revision falls back to the VCS revision stamped by the Go toolchain.
+var (
+	Version  string
+	Revision string
+)
+
+var buildInfo = promauto.With(Default).NewGaugeVec(prometheus.GaugeOpts{
+	Name: "build_info",
+	Help: "Always 1, labeled with the service, version and revision of the running build.",
+}, []string{"service", "version", "revision", "go_version"})
+
+// SetBuildInfo exports build_info for service.
+func SetBuildInfo(service string) {
+	version, revision := Version, Revision
+	if info, ok := debug.ReadBuildInfo(); ok && revision == "" {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				revision = setting.Value
+			}
+		}
+	}
+	if version == "" {
+		version = "unknown"
+	}
+	if revision == "" {
+		revision = "unknown"
+	}
+	buildInfo.WithLabelValues(service, version, revision, runtime.Version()).Set(1)
+}
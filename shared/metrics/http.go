@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Transport records the count and latency of the outbound requests to a service.
+type Transport struct {
+	peer string
+	base http.RoundTripper
+}
+
+// NewTransport wraps base (http.DefaultTransport when nil) for the calls to peer, the
+// name of the service called.
+func NewTransport(peer string, base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{peer: peer, base: base}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	HTTPClientRequestDuration.WithLabelValues(t.peer, req.Method).Observe(time.Since(start).Seconds())
+
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	HTTPClientRequestsTotal.WithLabelValues(t.peer, req.Method, status).Inc()
+
+	return resp, err
+}
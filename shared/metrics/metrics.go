@@ -0,0 +1,92 @@
+// Package metrics is the Prometheus instrumentation shared by the Go services.
+//
+// Every service exposes the same families under the same names, so one dashboard covers
+// them all, told apart by the job label Prometheus adds to the scraped series:
+//
+//   - http_server_requests_total and http_server_request_duration_seconds, recorded by
+//     the request middleware of each service with ObserveHTTPServer
+//   - http_client_requests_total and http_client_request_duration_seconds, recorded by
+//     Transport for the calls to other services
+//   - db_pool_* gauges of the database and cache connection pools (RegisterPool)
+//   - queue_depth, the messages waiting in a queue such as the outbox
+//   - build_info, the version and revision of the running binary (SetBuildInfo)
+//
+// Service specific families are registered on Default next to them, prefixed with the
+// service name. Handler serves Default with promhttp, along with the go_* and process_*
+// families of the Go runtime.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Default is the registry exposed on /metrics.
+var Default = NewRegistry()
+
+var (
+	// HTTPServerRequestsTotal counts inbound requests by matched route and status code.
+	HTTPServerRequestsTotal = promauto.With(Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "http_server_requests_total",
+		Help: "Total HTTP requests handled.",
+	}, []string{"method", "route", "status"})
+	// HTTPServerRequestDuration observes inbound request latency by matched route.
+	HTTPServerRequestDuration = promauto.With(Default).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_server_request_duration_seconds",
+		Help:    "Latency of HTTP requests handled.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+	// HTTPClientRequestsTotal counts outbound requests by the service called; status is
+	// "error" on transport failures.
+	HTTPClientRequestsTotal = promauto.With(Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "http_client_requests_total",
+		Help: "Total HTTP requests made to other services.",
+	}, []string{"peer", "method", "status"})
+	// HTTPClientRequestDuration observes outbound request latency by the service called.
+	HTTPClientRequestDuration = promauto.With(Default).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_client_request_duration_seconds",
+		Help:    "Latency of HTTP requests made to other services.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"peer", "method"})
+	// GRPCClientRequestsTotal counts outbound gRPC calls by the service called, the full
+	// method name and the status code, such as "OK" or "Unavailable".
+	GRPCClientRequestsTotal = promauto.With(Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_client_requests_total",
+		Help: "Total gRPC calls made to other services.",
+	}, []string{"peer", "grpc_method", "grpc_code"})
+	// GRPCClientRequestDuration observes outbound gRPC call latency by the service called.
+	GRPCClientRequestDuration = promauto.With(Default).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_client_request_duration_seconds",
+		Help:    "Latency of gRPC calls made to other services.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"peer", "grpc_method"})
+	// QueueDepth is the number of messages waiting in a queue. Services refresh it on
+	// scrape, see Registry.OnScrape.
+	QueueDepth = promauto.With(Default).NewGaugeVec(prometheus.GaugeOpts{
+		Name: "queue_depth",
+		Help: "Messages waiting to be processed in a queue.",
+	}, []string{"queue"})
+)
+
+// unmatchedRoute labels the requests that matched no route, to keep the cardinality of
+// the route label bounded.
+const unmatchedRoute = "unmatched"
+
+// ObserveHTTPServer records a request handled by the service. route is the matched
+// route template, "" when none matched.
+func ObserveHTTPServer(method, route string, status int, elapsed time.Duration) {
+	if route == "" {
+		route = unmatchedRoute
+	}
+	HTTPServerRequestDuration.WithLabelValues(method, route).Observe(elapsed.Seconds())
+	HTTPServerRequestsTotal.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+}
+
+// Handler serves Default, see Registry.Handler.
+func Handler() http.Handler {
+	return Default.Handler()
+}
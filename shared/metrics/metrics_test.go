@@ -0,0 +1,143 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// render scrapes r in the Prometheus text exposition format.
+func render(r *Registry) string {
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	return rec.Body.String()
+}
+
+func assertContains(t *testing.T, out string, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("missing %q in:\n%s", line, out)
+		}
+	}
+}
+
+func TestRegistryRendersFamilies(t *testing.T) {
+	r := NewRegistry()
+	counter := promauto.With(r).NewCounterVec(prometheus.CounterOpts{Name: "jobs_total", Help: "Jobs."}, []string{"kind"})
+	gauge := promauto.With(r).NewGauge(prometheus.GaugeOpts{Name: "temperature", Help: "Temperature."})
+	histogram := promauto.With(r).NewHistogramVec(prometheus.HistogramOpts{Name: "latency_seconds", Help: "Latency.", Buckets: []float64{0.1, 1}}, []string{"route"})
+
+	counter.WithLabelValues(`say "hi"`).Inc()
+	counter.WithLabelValues(`say "hi"`).Inc()
+	counter.WithLabelValues("batch").Add(3)
+	gauge.Set(21.5)
+	histogram.WithLabelValues("/a").Observe(0.05)
+	histogram.WithLabelValues("/a").Observe(0.5)
+
+	assertContains(t, render(r),
+		"# TYPE jobs_total counter",
+		`jobs_total{kind="say \"hi\""} 2`,
+		`jobs_total{kind="batch"} 3`,
+		"# TYPE temperature gauge",
+		"temperature 21.5",
+		"# TYPE latency_seconds histogram",
+		`latency_seconds_bucket{route="/a",le="0.1"} 1`,
+		`latency_seconds_bucket{route="/a",le="1"} 2`,
+		`latency_seconds_bucket{route="/a",le="+Inf"} 2`,
+		`latency_seconds_sum{route="/a"} 0.55`,
+		`latency_seconds_count{route="/a"} 2`,
+		"# TYPE go_goroutines gauge",
+	)
+}
+
+func TestHandlerRunsScrapeHooks(t *testing.T) {
+	r := NewRegistry()
+	depth := promauto.With(r).NewGaugeVec(prometheus.GaugeOpts{Name: "queue_depth", Help: "Depth."}, []string{"queue"})
+	r.OnScrape(func(ctx context.Context) {
+		if ctx == nil {
+			t.Error("hook got no context")
+		}
+		depth.WithLabelValues("outbox").Set(7)
+	})
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", got)
+	}
+	assertContains(t, rec.Body.String(), `queue_depth{queue="outbox"} 7`)
+}
+
+func TestRegisterPool(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterPool("postgres", func() PoolStats {
+		return PoolStats{Open: 4, InUse: 1, Idle: 3, MaxOpen: 10, WaitCount: 2, WaitDuration: 1500 * time.Millisecond}
+	})
+	r.RegisterPool("redis", func() PoolStats { return PoolStats{Open: 1, Idle: 1} })
+
+	assertContains(t, render(r),
+		"# TYPE db_pool_open_connections gauge",
+		`db_pool_open_connections{pool="postgres"} 4`,
+		`db_pool_open_connections{pool="redis"} 1`,
+		`db_pool_in_use_connections{pool="postgres"} 1`,
+		`db_pool_max_open_connections{pool="postgres"} 10`,
+		"# TYPE db_pool_wait_count_total counter",
+		`db_pool_wait_count_total{pool="postgres"} 2`,
+		`db_pool_wait_duration_seconds_total{pool="postgres"} 1.5`,
+	)
+}
+
+func TestObserveHTTPServerGroupsUnmatchedRoutes(t *testing.T) {
+	ObserveHTTPServer(http.MethodGet, "", http.StatusNotFound, 10*time.Millisecond)
+	ObserveHTTPServer(http.MethodGet, "/users/:id", http.StatusOK, 10*time.Millisecond)
+
+	assertContains(t, render(Default),
+		`http_server_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`http_server_requests_total{method="GET",route="/users/:id",status="200"} 1`,
+		`http_server_request_duration_seconds_count{method="GET",route="/users/:id"} 1`,
+	)
+}
+
+func TestTransportRecordsCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport("content-services", nil)}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	unreachable := &http.Client{Transport: NewTransport("geoip", nil)}
+	if _, err := unreachable.Get("http://127.0.0.1:1"); err == nil {
+		t.Fatal("call to a closed port succeeded")
+	}
+
+	assertContains(t, render(Default),
+		`http_client_requests_total{method="GET",peer="content-services",status="418"} 1`,
+		`http_client_requests_total{method="GET",peer="geoip",status="error"} 1`,
+	)
+}
+
+func TestSetBuildInfo(t *testing.T) {
+	Version, Revision = "1.4.0", "abc123"
+	defer func() { Version, Revision = "", "" }()
+
+	SetBuildInfo("order-services")
+
+	out := render(Default)
+	if !strings.Contains(out, `build_info{go_version="go`) || !strings.Contains(out, `",revision="abc123",service="order-services",version="1.4.0"} 1`) {
+		t.Errorf("missing build_info in:\n%s", out)
+	}
+}
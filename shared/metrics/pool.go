@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"database/sql"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoolStats is a snapshot of a connection pool.
+type PoolStats struct {
+	// Open is the number of established connections, in use or idle
+	Open  int
+	InUse int
+	Idle  int
+	// MaxOpen is the size limit of the pool, 0 when unlimited
+	MaxOpen int
+	// WaitCount and WaitDuration are the total number of waits for a free connection and
+	// the time spent waiting, since the pool was created
+	WaitCount    int64
+	WaitDuration time.Duration
+}
+
+// SQLPool reads the stats of a database/sql pool.
+func SQLPool(db *sql.DB) func() PoolStats {
+	return func() PoolStats {
+		s := db.Stats()
+		return PoolStats{
+			Open:         s.OpenConnections,
+			InUse:        s.InUse,
+			Idle:         s.Idle,
+			MaxOpen:      s.MaxOpenConnections,
+			WaitCount:    s.WaitCount,
+			WaitDuration: s.WaitDuration,
+		}
+	}
+}
+
+// RegisterPool exports the db_pool_* families of the named pool on Default, reading
+// stats on every scrape.
+func RegisterPool(name string, stats func() PoolStats) {
+	Default.RegisterPool(name, stats)
+}
+
+// RegisterPool exports the db_pool_* families of the named pool, reading stats on every
+// scrape. Registering a name again replaces its stats function.
+func (r *Registry) RegisterPool(name string, stats func() PoolStats) {
+	r.pools.mu.Lock()
+	defer r.pools.mu.Unlock()
+	r.pools.pools[name] = stats
+}
+
+// poolFamily is one of the db_pool_* families, labeled with the pool name.
+type poolFamily struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	value     func(PoolStats) float64
+}
+
+// poolCollector reads the stats of the registered pools on every scrape.
+type poolCollector struct {
+	families []poolFamily
+
+	mu    sync.Mutex
+	pools map[string]func() PoolStats
+}
+
+func newPoolCollector() *poolCollector {
+	family := func(name, help string, valueType prometheus.ValueType, value func(PoolStats) float64) poolFamily {
+		return poolFamily{desc: prometheus.NewDesc(name, help, []string{"pool"}, nil), valueType: valueType, value: value}
+	}
+	return &poolCollector{
+		families: []poolFamily{
+			family("db_pool_open_connections", "Established connections of the pool, in use or idle.",
+				prometheus.GaugeValue, func(s PoolStats) float64 { return float64(s.Open) }),
+			family("db_pool_in_use_connections", "Connections of the pool currently in use.",
+				prometheus.GaugeValue, func(s PoolStats) float64 { return float64(s.InUse) }),
+			family("db_pool_idle_connections", "Idle connections of the pool.",
+				prometheus.GaugeValue, func(s PoolStats) float64 { return float64(s.Idle) }),
+			family("db_pool_max_open_connections", "Size limit of the pool, 0 when unlimited.",
+				prometheus.GaugeValue, func(s PoolStats) float64 { return float64(s.MaxOpen) }),
+			family("db_pool_wait_count_total", "Total waits for a free connection of the pool.",
+				prometheus.CounterValue, func(s PoolStats) float64 { return float64(s.WaitCount) }),
+			family("db_pool_wait_duration_seconds_total", "Total time spent waiting for a free connection of the pool.",
+				prometheus.CounterValue, func(s PoolStats) float64 { return s.WaitDuration.Seconds() }),
+		},
+		pools: make(map[string]func() PoolStats),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (p *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, f := range p.families {
+		ch <- f.desc
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (p *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	p.mu.Lock()
+	pools := maps.Clone(p.pools)
+	p.mu.Unlock()
+
+	for name, stats := range pools {
+		s := stats()
+		for _, f := range p.families {
+			ch <- prometheus.MustNewConstMetric(f.desc, f.valueType, f.value(s), name)
+		}
+	}
+}
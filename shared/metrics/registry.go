@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry is a Prometheus registry that also runs hooks before each scrape and exports
+// the connection pools registered with RegisterPool. Register families on it with
+// promauto.With(registry).
+type Registry struct {
+	*prometheus.Registry
+
+	mu    sync.RWMutex
+	hooks []func(ctx context.Context)
+	pools *poolCollector
+}
+
+// NewRegistry constructs a Registry exporting the Go runtime and process families.
+func NewRegistry() *Registry {
+	r := &Registry{Registry: prometheus.NewRegistry(), pools: newPoolCollector()}
+	r.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		r.pools,
+	)
+	return r
+}
+
+// OnScrape registers fn to run before each scrape served by Handler, to refresh gauges
+// that are expensive to keep up to date, such as a queue depth read from the database.
+func (r *Registry) OnScrape(fn func(ctx context.Context)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks = append(r.hooks, fn)
+}
+
+// Handler runs the scrape hooks with the request context, then serves the registry with
+// promhttp.
+func (r *Registry) Handler() http.Handler {
+	serve := promhttp.HandlerFor(r.Registry, promhttp.HandlerOpts{})
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.mu.RLock()
+		hooks := slices.Clone(r.hooks)
+		r.mu.RUnlock()
+		for _, hook := range hooks {
+			hook(req.Context())
+		}
+
+		serve.ServeHTTP(w, req)
+	})
+}
@@ -0,0 +1,67 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// Usage describes the commands of Command.
+const Usage = `commands:
+  up          apply the pending migrations (default)
+  down [n]    revert the last n migrations, 1 by default
+  status      list the migrations and their state
+  check       exit with an error on drift between the migrations and the database`
+
+// Command runs the migrate command of args, the arguments left after the flags of the
+// cmd/migrate binary of a service, writing its report to out.
+func Command(ctx context.Context, m *Migrator, args []string, out io.Writer) error {
+	command := "up"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+	switch command {
+	case "up":
+		count, err := m.Up(ctx)
+		fmt.Fprintf(out, "applied %d migrations\n", count)
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 0 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 {
+				return fmt.Errorf("migrate: invalid number of migrations %q", args[0])
+			}
+			steps = n
+		}
+		count, err := m.Down(ctx, steps)
+		fmt.Fprintf(out, "reverted %d migrations\n", count)
+		return err
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tSTATE\tAPPLIED AT\tNAME")
+		for _, status := range statuses {
+			appliedAt := "-"
+			if !status.AppliedAt.IsZero() {
+				appliedAt = status.AppliedAt.UTC().Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", status.Version, status.State, appliedAt, status.Name)
+		}
+		return w.Flush()
+	case "check":
+		if err := m.Check(ctx); err != nil {
+			return err
+		}
+		fmt.Fprintln(out, "no drift")
+		return nil
+	default:
+		return fmt.Errorf("migrate: unknown command %q\n%s", command, Usage)
+	}
+}
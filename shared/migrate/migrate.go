@@ -0,0 +1,129 @@
+// Package migrate applies the schema migrations of the Go services and detects drift
+// between the migrations a service was built with and those applied to its database.
+//
+// Migrations are files embedded in the service binary, named
+// <version>_<description>.up.<ext> with an optional .down.<ext> to revert them, such as
+// 0003_phone_mfa.up.sql. A Driver runs them on one kind of datastore: Postgres runs SQL
+// files here, and the mongodriver module runs JSON command files on MongoDB. Each
+// applied migration is recorded with the checksum of its file, so a migration edited
+// after it was applied is reported instead of silently diverging.
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrDrift is wrapped by the errors of Check.
+	ErrDrift = errors.New("migrate: schema drift")
+	// ErrNoDown is returned when reverting a migration that has no down file.
+	ErrNoDown = errors.New("migrate: no down migration")
+)
+
+// Migration is a schema change, read from its up and down files.
+type Migration struct {
+	// Version is the prefix of the file name, such as 0003
+	Version string
+	// Name is the name of the up file
+	Name string
+	Up   string
+	Down string
+	// Checksum is the SHA-256 of the up file
+	Checksum string
+
+	order int64
+}
+
+// Record is an applied migration as recorded by a driver.
+type Record struct {
+	Version string
+	// Checksum is empty for migrations applied before checksums were recorded
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// Driver applies migrations to a datastore and records them.
+type Driver interface {
+	// Lock keeps other instances from migrating until unlock is called.
+	Lock(ctx context.Context) (unlock func(), err error)
+	// Prepare creates or upgrades the record of applied migrations.
+	Prepare(ctx context.Context) error
+	// Applied returns the migrations recorded, none before Prepare ran.
+	Applied(ctx context.Context) ([]Record, error)
+	// Apply runs the up file of m and records it.
+	Apply(ctx context.Context, m Migration) error
+	// Revert runs the down file of m and removes its record.
+	Revert(ctx context.Context, m Migration) error
+	// SetChecksum records the checksum of a migration recorded without one.
+	SetChecksum(ctx context.Context, version, checksum string) error
+}
+
+// Load reads the migrations of fsys with the extension ext, such as ".sql", sorted by
+// version.
+func Load(fsys fs.FS, ext string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("migrate: reading migrations: %w", err)
+	}
+	byVersion := make(map[string]*Migration)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.EqualFold(path.Ext(name), ext) {
+			continue
+		}
+		base := strings.ToLower(strings.TrimSuffix(name, path.Ext(name)))
+		down := strings.HasSuffix(base, ".down")
+		if !down && !strings.HasSuffix(base, ".up") {
+			continue
+		}
+		version, _, _ := strings.Cut(name, "_")
+		order, err := strconv.ParseInt(version, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: %s does not start with a numeric version", name)
+		}
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: reading %s: %w", name, err)
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, order: order}
+			byVersion[version] = m
+		}
+		if down {
+			m.Down = string(content)
+			continue
+		}
+		if m.Name != "" {
+			return nil, fmt.Errorf("migrate: duplicate migration version %s: %s and %s", version, m.Name, name)
+		}
+		sum := sha256.Sum256(content)
+		m.Name, m.Up, m.Checksum = name, string(content), hex.EncodeToString(sum[:])
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Name == "" {
+			return nil, fmt.Errorf("migrate: down migration %s has no up migration", m.Version)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		if migrations[i].order == migrations[j].order {
+			return migrations[i].Version < migrations[j].Version
+		}
+		return migrations[i].order < migrations[j].order
+	})
+	return migrations, nil
+}
@@ -0,0 +1,151 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// memoryDriver records migrations in memory and logs the scripts it runs.
+type memoryDriver struct {
+	records map[string]Record
+	ran     []string
+}
+
+func newMemoryDriver() *memoryDriver {
+	return &memoryDriver{records: make(map[string]Record)}
+}
+
+func (d *memoryDriver) Lock(context.Context) (func(), error) { return func() {}, nil }
+func (d *memoryDriver) Prepare(context.Context) error        { return nil }
+
+func (d *memoryDriver) Applied(context.Context) ([]Record, error) {
+	var records []Record
+	for _, record := range d.records {
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func (d *memoryDriver) Apply(_ context.Context, m Migration) error {
+	d.ran = append(d.ran, m.Up)
+	d.records[m.Version] = Record{Version: m.Version, Checksum: m.Checksum, AppliedAt: time.Now()}
+	return nil
+}
+
+func (d *memoryDriver) Revert(_ context.Context, m Migration) error {
+	d.ran = append(d.ran, m.Down)
+	delete(d.records, m.Version)
+	return nil
+}
+
+func (d *memoryDriver) SetChecksum(_ context.Context, version, checksum string) error {
+	record := d.records[version]
+	record.Checksum = checksum
+	d.records[version] = record
+	return nil
+}
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"0002_orders.up.sql":   {Data: []byte("CREATE TABLE orders ();")},
+		"0001_users.up.sql":    {Data: []byte("CREATE TABLE users ();")},
+		"0001_users.down.sql":  {Data: []byte("DROP TABLE users;")},
+		"0010_coupons.up.sql":  {Data: []byte("CREATE TABLE coupons ();")},
+		"README.md":            {Data: []byte("not a migration")},
+		"0003_indexes.up.json": {Data: []byte("[]")},
+	}
+}
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load(testFS(), ".sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, m := range migrations {
+		names = append(names, m.Name)
+	}
+	if got := strings.Join(names, ","); got != "0001_users.up.sql,0002_orders.up.sql,0010_coupons.up.sql" {
+		t.Errorf("migrations = %s", got)
+	}
+	if migrations[0].Down != "DROP TABLE users;" || migrations[0].Checksum == "" {
+		t.Errorf("first migration = %+v", migrations[0])
+	}
+
+	duplicate := testFS()
+	duplicate["0002_payments.up.sql"] = &fstest.MapFile{Data: []byte("")}
+	if _, err := Load(duplicate, ".sql"); err == nil {
+		t.Error("duplicate version accepted")
+	}
+}
+
+func TestUpDownAndDrift(t *testing.T) {
+	ctx := context.Background()
+	migrations, _ := Load(testFS(), ".sql")
+	driver := newMemoryDriver()
+	m := New(driver, migrations)
+
+	if err := m.Check(ctx); !errors.Is(err, ErrDrift) {
+		t.Errorf("Check before Up = %v", err)
+	}
+	if err := m.Startup(ctx, true); err != nil {
+		t.Fatalf("Startup: %v", err)
+	}
+	if count, _ := m.Up(ctx); count != 0 || len(driver.ran) != 3 {
+		t.Errorf("second Up applied %d, ran %v", count, driver.ran)
+	}
+
+	// Records of the earlier runners have no checksum until Up adopts them
+	driver.records["0002"] = Record{Version: "0002"}
+	if err := m.Check(ctx); err != nil {
+		t.Errorf("Check with a record without checksum: %v", err)
+	}
+	m.Up(ctx)
+	if driver.records["0002"].Checksum != migrations[1].Checksum {
+		t.Error("checksum not adopted")
+	}
+
+	edited := testFS()
+	edited["0002_orders.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE orders (id int);")}
+	editedMigrations, _ := Load(edited, ".sql")
+	driver.records["0042"] = Record{Version: "0042"}
+	err := New(driver, editedMigrations).Check(ctx)
+	if !errors.Is(err, ErrDrift) || !strings.Contains(err.Error(), "0002 modified") || !strings.Contains(err.Error(), "0042 unknown") {
+		t.Errorf("Check with drift = %v", err)
+	}
+	delete(driver.records, "0042")
+
+	if _, err := m.Down(ctx, 1); !errors.Is(err, ErrNoDown) {
+		t.Errorf("Down without a down file = %v", err)
+	}
+	delete(driver.records, "0010")
+	delete(driver.records, "0002")
+	if count, err := m.Down(ctx, 5); err != nil || count != 1 || len(driver.records) != 0 {
+		t.Errorf("Down = %d, %v, records %v", count, err, driver.records)
+	}
+}
+
+func TestCommandStatus(t *testing.T) {
+	ctx := context.Background()
+	migrations, _ := Load(testFS(), ".sql")
+	driver := newMemoryDriver()
+	m := New(driver, migrations)
+	driver.Apply(ctx, migrations[0])
+
+	var out bytes.Buffer
+	if err := Command(ctx, m, []string{"status"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 || !strings.Contains(lines[1], "applied") || !strings.Contains(lines[2], "pending") {
+		t.Errorf("status:\n%s", out.String())
+	}
+	if err := Command(ctx, m, []string{"sideways"}, &out); err == nil {
+		t.Error("unknown command accepted")
+	}
+}
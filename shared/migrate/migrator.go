@@ -0,0 +1,200 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// State is the state of a migration in Status.
+type State string
+
+const (
+	// StateApplied is a migration applied with the file the service was built with
+	StateApplied State = "applied"
+	// StatePending is a migration not applied yet
+	StatePending State = "pending"
+	// StateModified is an applied migration whose file changed since
+	StateModified State = "modified"
+	// StateUnknown is an applied migration the service has no file for, such as one
+	// applied by a newer release
+	StateUnknown State = "unknown"
+)
+
+// Status describes a migration known to the service or to the datastore.
+type Status struct {
+	Version   string
+	Name      string
+	State     State
+	AppliedAt time.Time
+}
+
+// Migrator applies the migrations of a service with a driver.
+type Migrator struct {
+	driver     Driver
+	migrations []Migration
+}
+
+// New returns a migrator applying migrations, as returned by Load, with driver.
+func New(driver Driver, migrations []Migration) *Migrator {
+	return &Migrator{driver: driver, migrations: migrations}
+}
+
+// Up applies the pending migrations in version order and returns how many were applied.
+// Migrations recorded before checksums were kept are given the checksum of their file.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, migration := range m.migrations {
+		record, ok := applied[migration.Version]
+		if ok {
+			if record.Checksum == "" {
+				if err := m.driver.SetChecksum(ctx, migration.Version, migration.Checksum); err != nil {
+					return count, err
+				}
+			}
+			continue
+		}
+		if err := m.driver.Apply(ctx, migration); err != nil {
+			return count, fmt.Errorf("migrate: applying %s: %w", migration.Name, err)
+		}
+		slog.InfoContext(ctx, "applied migration", "migration", migration.Name)
+		count++
+	}
+	return count, nil
+}
+
+// Down reverts the last steps applied migrations, newest first, and returns how many
+// were reverted.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		if migration.Down == "" {
+			return count, fmt.Errorf("%w for %s", ErrNoDown, migration.Name)
+		}
+		if err := m.driver.Revert(ctx, migration); err != nil {
+			return count, fmt.Errorf("migrate: reverting %s: %w", migration.Name, err)
+		}
+		slog.InfoContext(ctx, "reverted migration", "migration", migration.Name)
+		count++
+	}
+	return count, nil
+}
+
+// Status returns the state of every migration of the service, followed by those only
+// the datastore knows.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	records, err := m.driver.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	applied := byVersion(records)
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name, State: StatePending}
+		if record, ok := applied[migration.Version]; ok {
+			status.AppliedAt = record.AppliedAt
+			status.State = StateApplied
+			if record.Checksum != "" && record.Checksum != migration.Checksum {
+				status.State = StateModified
+			}
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, record := range records {
+		if _, ok := applied[record.Version]; ok {
+			statuses = append(statuses, Status{Version: record.Version, State: StateUnknown, AppliedAt: record.AppliedAt})
+		}
+	}
+	return statuses, nil
+}
+
+// Check returns an error wrapping ErrDrift unless every migration of the service is
+// applied unchanged and the datastore has none the service does not know. Services run
+// it at startup and refuse to serve on a mismatch.
+func (m *Migrator) Check(ctx context.Context) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	var problems []string
+	for _, status := range statuses {
+		if status.State != StateApplied {
+			problems = append(problems, fmt.Sprintf("%s %s", status.Version, status.State))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrDrift, strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+func (m *Migrator) lock(ctx context.Context) (func(), error) {
+	unlock, err := m.driver.Lock(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("migrate: locking: %w", err)
+	}
+	if err := m.driver.Prepare(ctx); err != nil {
+		unlock()
+		return nil, err
+	}
+	return unlock, nil
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[string]Record, error) {
+	records, err := m.driver.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return byVersion(records), nil
+}
+
+func byVersion(records []Record) map[string]Record {
+	applied := make(map[string]Record, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied
+}
+
+// Startup readies the datastore of a service before it serves: it applies the pending
+// migrations when apply is set, then runs Check.
+func (m *Migrator) Startup(ctx context.Context, apply bool) error {
+	if apply {
+		count, err := m.Up(ctx)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			slog.InfoContext(ctx, "completed migrations", "count", count)
+		}
+	}
+	return m.Check(ctx)
+}
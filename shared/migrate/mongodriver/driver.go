@@ -0,0 +1,150 @@
+// Package mongodriver runs the migrations of shared/migrate on MongoDB. It is a module of
+// its own so services on Postgres do not depend on the MongoDB driver.
+//
+// A migration is a .json file holding an array of database commands in extended JSON,
+// run in order with RunCommand, such as
+//
+//	[{"createIndexes": "topics", "indexes": [{"key": {"slug": 1}, "name": "slug_1", "unique": true}]}]
+//
+// MongoDB cannot run index and collection changes in a transaction: a migration failing
+// halfway is not recorded, so its commands should be safe to run again, as createIndexes
+// with unchanged indexes is.
+package mongodriver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Ext is the extension of MongoDB migrations, for migrate.Load.
+const Ext = ".json"
+
+// DefaultCollection is the collection applied migrations are recorded in.
+const DefaultCollection = "schema_migrations"
+
+const (
+	lockID = "lock"
+	// lockLease bounds how long a crashed instance keeps others from migrating
+	lockLease = 10 * time.Minute
+)
+
+type record struct {
+	Version   string    `bson:"_id"`
+	Checksum  string    `bson:"checksum"`
+	AppliedAt time.Time `bson:"applied_at"`
+}
+
+// Driver implements migrate.Driver.
+type Driver struct {
+	db      *mongo.Database
+	records *mongo.Collection
+	locks   *mongo.Collection
+}
+
+var _ migrate.Driver = (*Driver)(nil)
+
+// New returns a driver running migrations on db and recording them in collection,
+// DefaultCollection when empty.
+func New(db *mongo.Database, collection string) *Driver {
+	if collection == "" {
+		collection = DefaultCollection
+	}
+	return &Driver{db: db, records: db.Collection(collection), locks: db.Collection(collection + "_lock")}
+}
+
+// Lock implements migrate.Driver. The lock is a document other instances wait on until
+// it is removed or its lease ends.
+func (d *Driver) Lock(ctx context.Context) (func(), error) {
+	for {
+		now := time.Now()
+		_, err := d.locks.UpdateOne(ctx,
+			bson.M{"_id": lockID, "locked_until": bson.M{"$lt": now}},
+			bson.M{"$set": bson.M{"locked_until": now.Add(lockLease)}},
+			options.Update().SetUpsert(true))
+		if err == nil {
+			return func() {
+				d.locks.DeleteOne(context.Background(), bson.M{"_id": lockID})
+			}, nil
+		}
+		// The upsert of a held lock collides with it
+		if !mongo.IsDuplicateKeyError(err) {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// Prepare implements migrate.Driver. The collections are created on first write.
+func (d *Driver) Prepare(context.Context) error {
+	return nil
+}
+
+// Applied implements migrate.Driver.
+func (d *Driver) Applied(ctx context.Context) ([]migrate.Record, error) {
+	cursor, err := d.records.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var docs []record
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	records := make([]migrate.Record, len(docs))
+	for i, doc := range docs {
+		records[i] = migrate.Record{Version: doc.Version, Checksum: doc.Checksum, AppliedAt: doc.AppliedAt}
+	}
+	return records, nil
+}
+
+// Apply implements migrate.Driver.
+func (d *Driver) Apply(ctx context.Context, m migrate.Migration) error {
+	if err := d.run(ctx, m.Up); err != nil {
+		return err
+	}
+	_, err := d.records.InsertOne(ctx, record{Version: m.Version, Checksum: m.Checksum, AppliedAt: time.Now().UTC()})
+	return err
+}
+
+// Revert implements migrate.Driver.
+func (d *Driver) Revert(ctx context.Context, m migrate.Migration) error {
+	if err := d.run(ctx, m.Down); err != nil {
+		return err
+	}
+	_, err := d.records.DeleteOne(ctx, bson.M{"_id": m.Version})
+	return err
+}
+
+// SetChecksum implements migrate.Driver.
+func (d *Driver) SetChecksum(ctx context.Context, version, checksum string) error {
+	_, err := d.records.UpdateOne(ctx, bson.M{"_id": version}, bson.M{"$set": bson.M{"checksum": checksum}})
+	return err
+}
+
+// run runs the commands of a migration file in order.
+func (d *Driver) run(ctx context.Context, script string) error {
+	var commands []json.RawMessage
+	if err := json.Unmarshal([]byte(script), &commands); err != nil {
+		return fmt.Errorf("migration is not a JSON array of commands: %w", err)
+	}
+	for i, raw := range commands {
+		var command bson.D
+		if err := bson.UnmarshalExtJSON(raw, false, &command); err != nil {
+			return fmt.Errorf("command %d: %w", i+1, err)
+		}
+		if err := d.db.RunCommand(ctx, command).Err(); err != nil {
+			return fmt.Errorf("command %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+module github.com/ductan2/microservice-app/shared/migrate/mongodriver
+
+go 1.24.0
+
+require (
+	github.com/ductan2/microservice-app/shared/migrate v0.0.0
+	go.mongodb.org/mongo-driver v1.17.3
+)
+
+replace github.com/ductan2/microservice-app/shared/migrate => ../
+
+require (
+	github.com/golang/snappy v0.0.4 // indirect
+	github.com/klauspost/compress v1.16.7 // indirect
+	github.com/montanaflynn/stats v0.7.1 // indirect
+	github.com/xdg-go/pbkdf2 v1.0.0 // indirect
+	github.com/xdg-go/scram v1.1.2 // indirect
+	github.com/xdg-go/stringprep v1.0.4 // indirect
+	github.com/youmark/pkcs8 v0.0.0-20240726163527-a2c0da244d78 // indirect
+	golang.org/x/crypto v0.42.0 // indirect
+	golang.org/x/sync v0.17.0 // indirect
+	golang.org/x/text v0.29.0 // indirect
+)
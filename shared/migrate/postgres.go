@@ -0,0 +1,128 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// DefaultTable is the table Postgres records applied migrations in.
+const DefaultTable = "schema_migrations"
+
+// Postgres runs SQL migrations on PostgreSQL. Each migration runs in a transaction with
+// its record, and a session advisory lock keeps instances starting together from
+// migrating at once.
+type Postgres struct {
+	db      *sql.DB
+	table   string
+	lockKey int64
+}
+
+var _ Driver = (*Postgres)(nil)
+
+// NewPostgres returns a driver recording migrations in table of db, DefaultTable when
+// empty. The table of the earlier migration runners of the services is upgraded in place.
+func NewPostgres(db *sql.DB, table string) *Postgres {
+	if table == "" {
+		table = DefaultTable
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte("migrate:" + table))
+	return &Postgres{db: db, table: table, lockKey: int64(hash.Sum64())}
+}
+
+// Lock implements Driver.
+func (p *Postgres) Lock(ctx context.Context) (func(), error) {
+	conn, err := p.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", p.lockKey); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return func() {
+		conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", p.lockKey)
+		conn.Close()
+	}, nil
+}
+
+// Prepare implements Driver.
+func (p *Postgres) Prepare(ctx context.Context) error {
+	_, err := p.db.ExecContext(ctx, fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %[1]s (
+			version TEXT PRIMARY KEY,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS checksum TEXT;`, p.table))
+	if err != nil {
+		return fmt.Errorf("migrate: preparing %s: %w", p.table, err)
+	}
+	return nil
+}
+
+// Applied implements Driver. It only reads, so that a service checking for drift needs
+// no DDL privilege.
+func (p *Postgres) Applied(ctx context.Context) ([]Record, error) {
+	var exists bool
+	if err := p.db.QueryRowContext(ctx, "SELECT to_regclass($1) IS NOT NULL", p.table).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("migrate: reading %s: %w", p.table, err)
+	}
+	if !exists {
+		return nil, nil
+	}
+	// to_jsonb reads checksum from tables not upgraded by Prepare yet as well
+	rows, err := p.db.QueryContext(ctx, fmt.Sprintf(
+		"SELECT version, COALESCE(to_jsonb(m)->>'checksum', ''), applied_at FROM %s m ORDER BY version", p.table))
+	if err != nil {
+		return nil, fmt.Errorf("migrate: reading %s: %w", p.table, err)
+	}
+	defer rows.Close()
+	var records []Record
+	for rows.Next() {
+		var record Record
+		if err := rows.Scan(&record.Version, &record.Checksum, &record.AppliedAt); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// Apply implements Driver.
+func (p *Postgres) Apply(ctx context.Context, m Migration) error {
+	return p.inTx(ctx, m.Up, fmt.Sprintf("INSERT INTO %s (version, checksum) VALUES ($1, $2)", p.table), m.Version, m.Checksum)
+}
+
+// Revert implements Driver.
+func (p *Postgres) Revert(ctx context.Context, m Migration) error {
+	return p.inTx(ctx, m.Down, fmt.Sprintf("DELETE FROM %s WHERE version = $1", p.table), m.Version)
+}
+
+// SetChecksum implements Driver.
+func (p *Postgres) SetChecksum(ctx context.Context, version, checksum string) error {
+	_, err := p.db.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET checksum = $2 WHERE version = $1", p.table), version, checksum)
+	return err
+}
+
+// inTx runs script, then record with args, in a transaction.
+func (p *Postgres) inTx(ctx context.Context, script, record string, args ...any) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	// Without arguments the script runs on the simple protocol, which allows several
+	// statements
+	if strings.TrimSpace(script) != "" {
+		if _, err := tx.ExecContext(ctx, script); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,65 @@
+package money
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency code, such as "USD".
+type Currency string
+
+// The currencies the services sell in.
+const (
+	USD Currency = "USD"
+	EUR Currency = "EUR"
+	GBP Currency = "GBP"
+	VND Currency = "VND"
+	JPY Currency = "JPY"
+	KRW Currency = "KRW"
+	AUD Currency = "AUD"
+	CAD Currency = "CAD"
+	SGD Currency = "SGD"
+)
+
+type currencyInfo struct {
+	// exponent is the number of digits of the minor unit: 2 for cents, 0 when there is
+	// no minor unit
+	exponent int
+	symbol   string
+}
+
+var currencies = map[Currency]currencyInfo{
+	USD: {exponent: 2, symbol: "$"},
+	EUR: {exponent: 2, symbol: "€"},
+	GBP: {exponent: 2, symbol: "£"},
+	VND: {exponent: 0, symbol: "₫"},
+	JPY: {exponent: 0, symbol: "¥"},
+	KRW: {exponent: 0, symbol: "₩"},
+	AUD: {exponent: 2, symbol: "A$"},
+	CAD: {exponent: 2, symbol: "CA$"},
+	SGD: {exponent: 2, symbol: "S$"},
+}
+
+// ParseCurrency returns the currency of code, in any case. Only the currencies this
+// package knows the minor unit of are accepted.
+func ParseCurrency(code string) (Currency, error) {
+	currency := Currency(strings.ToUpper(strings.TrimSpace(code)))
+	if _, ok := currencies[currency]; !ok {
+		return "", fmt.Errorf("money: unsupported currency %q", code)
+	}
+	return currency, nil
+}
+
+// Exponent returns the number of digits of the minor unit of c, 2 for an unknown
+// currency.
+func (c Currency) Exponent() int {
+	if info, ok := currencies[c]; ok {
+		return info.exponent
+	}
+	return 2
+}
+
+// Symbol returns the symbol of c, "" for an unknown currency.
+func (c Currency) Symbol() string {
+	return currencies[c].symbol
+}
@@ -0,0 +1,277 @@
+// Package money represents amounts as an integer number of minor units, such as cents,
+// with their currency. Arithmetic refuses to mix currencies and reports overflows
+// instead of wrapping, splitting an amount never loses or creates a minor unit, and
+// formatting follows the minor unit of the currency, so no amount goes through a float.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrCurrencyMismatch is returned when an operation mixes two currencies
+	ErrCurrencyMismatch = errors.New("money: currency mismatch")
+	// ErrOverflow is returned when a result does not fit in an int64 of minor units
+	ErrOverflow = errors.New("money: amount overflows")
+)
+
+// Money is an amount in the minor unit of its currency. The zero value is zero in no
+// currency.
+type Money struct {
+	amount   int64
+	currency Currency
+}
+
+// New returns amount minor units of currency, such as New(1999, USD) for $19.99.
+func New(amount int64, currency Currency) Money {
+	return Money{amount: amount, currency: currency}
+}
+
+// Zero returns zero in currency.
+func Zero(currency Currency) Money {
+	return Money{currency: currency}
+}
+
+// Amount returns the amount in minor units.
+func (m Money) Amount() int64 { return m.amount }
+
+// Currency returns the currency of m.
+func (m Money) Currency() Currency { return m.currency }
+
+// IsZero reports whether m is zero.
+func (m Money) IsZero() bool { return m.amount == 0 }
+
+// IsNegative reports whether m is below zero.
+func (m Money) IsNegative() bool { return m.amount < 0 }
+
+func (m Money) same(o Money) error {
+	if m.currency != o.currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, o.currency)
+	}
+	return nil
+}
+
+// Add returns m + o.
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.same(o); err != nil {
+		return Money{}, err
+	}
+	sum := m.amount + o.amount
+	if (o.amount > 0 && sum < m.amount) || (o.amount < 0 && sum > m.amount) {
+		return Money{}, ErrOverflow
+	}
+	return Money{amount: sum, currency: m.currency}, nil
+}
+
+// Sub returns m - o.
+func (m Money) Sub(o Money) (Money, error) {
+	if o.amount == math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+	return m.Add(Money{amount: -o.amount, currency: o.currency})
+}
+
+// Multiply returns m times n.
+func (m Money) Multiply(n int64) (Money, error) {
+	product, ok := mul(m.amount, n)
+	if !ok {
+		return Money{}, ErrOverflow
+	}
+	return Money{amount: product, currency: m.currency}, nil
+}
+
+// Percent returns percent % of m, rounded toward zero so a percentage discount never
+// exceeds its rate.
+func (m Money) Percent(percent int64) (Money, error) {
+	product, ok := mul(m.amount, percent)
+	if !ok {
+		return Money{}, ErrOverflow
+	}
+	return Money{amount: product / 100, currency: m.currency}, nil
+}
+
+func mul(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	product := a * b
+	if product/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	return product, true
+}
+
+// Compare returns -1, 0 or 1 as m is less than, equal to or greater than o.
+func (m Money) Compare(o Money) (int, error) {
+	if err := m.same(o); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.amount < o.amount:
+		return -1, nil
+	case m.amount > o.amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Min returns the smaller of m and o.
+func (m Money) Min(o Money) (Money, error) {
+	cmp, err := m.Compare(o)
+	if err != nil {
+		return Money{}, err
+	}
+	if cmp > 0 {
+		return o, nil
+	}
+	return m, nil
+}
+
+// Allocate splits m in parts proportional to ratios. The minor units left over by the
+// division go one each to the first parts, so the parts always add up to m.
+func (m Money) Allocate(ratios ...int64) ([]Money, error) {
+	var total int64
+	for _, ratio := range ratios {
+		if ratio < 0 {
+			return nil, errors.New("money: negative allocation ratio")
+		}
+		total += ratio
+		if total < 0 {
+			return nil, ErrOverflow
+		}
+	}
+	if total == 0 {
+		return nil, errors.New("money: allocation ratios sum to zero")
+	}
+
+	parts := make([]Money, len(ratios))
+	remainder := m.amount
+	for i, ratio := range ratios {
+		share, ok := mul(m.amount, ratio)
+		if !ok {
+			return nil, ErrOverflow
+		}
+		parts[i] = Money{amount: share / total, currency: m.currency}
+		remainder -= parts[i].amount
+	}
+
+	unit := int64(1)
+	if remainder < 0 {
+		unit = -1
+	}
+	for i := 0; remainder != 0; i = (i + 1) % len(parts) {
+		if ratios[i] == 0 {
+			continue
+		}
+		parts[i].amount += unit
+		remainder -= unit
+	}
+	return parts, nil
+}
+
+// Split splits m in n parts differing by at most one minor unit.
+func (m Money) Split(n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, errors.New("money: split in fewer than one part")
+	}
+	ratios := make([]int64, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return m.Allocate(ratios...)
+}
+
+// Decimal returns the amount in major units, such as "1234.50" for 123450 cents.
+func (m Money) Decimal() string {
+	return m.format(false)
+}
+
+// String formats m for people, with the currency symbol and grouped thousands, such as
+// "$1,234.50" or "-₫120,000". A currency without a symbol follows the amount, as in
+// "1,234.50 CHF".
+func (m Money) String() string {
+	symbol := m.currency.Symbol()
+	number := m.format(true)
+	sign := ""
+	if m.amount < 0 {
+		sign, number = "-", number[1:]
+	}
+	if symbol == "" {
+		return strings.TrimSpace(sign + number + " " + string(m.currency))
+	}
+	return sign + symbol + number
+}
+
+func (m Money) format(group bool) string {
+	exponent := m.currency.Exponent()
+	digits := strconv.FormatUint(abs(m.amount), 10)
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	whole, fraction := digits[:len(digits)-exponent], digits[len(digits)-exponent:]
+	if group {
+		whole = groupThousands(whole)
+	}
+
+	s := whole
+	if exponent > 0 {
+		s += "." + fraction
+	}
+	if m.amount < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+func abs(n int64) uint64 {
+	if n < 0 {
+		return uint64(-(n + 1)) + 1
+	}
+	return uint64(n)
+}
+
+func groupThousands(digits string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+	var b strings.Builder
+	first := len(digits) % 3
+	if first > 0 {
+		b.WriteString(digits[:first])
+	}
+	for i := first; i < len(digits); i += 3 {
+		if b.Len() > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
+
+// Parse reads an amount in major units, such as "19.99" or "-5", in currency. It
+// refuses more decimals than the minor unit of the currency has.
+func Parse(s string, currency Currency) (Money, error) {
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	whole, fraction, _ := strings.Cut(s, ".")
+	exponent := currency.Exponent()
+	if whole == "" || len(fraction) > exponent || strings.ContainsAny(whole+fraction, "+-") {
+		return Money{}, fmt.Errorf("money: invalid %s amount %q", currency, s)
+	}
+	fraction += strings.Repeat("0", exponent-len(fraction))
+
+	amount, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("money: invalid %s amount %q", currency, s)
+	}
+	if negative {
+		amount = -amount
+	}
+	return Money{amount: amount, currency: currency}, nil
+}
@@ -0,0 +1,137 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestArithmetic(t *testing.T) {
+	price := New(1999, USD)
+
+	if sum, err := price.Add(New(1, USD)); err != nil || sum != New(2000, USD) {
+		t.Errorf("Add: got %v, %v", sum, err)
+	}
+	if diff, err := price.Sub(New(2999, USD)); err != nil || diff != New(-1000, USD) {
+		t.Errorf("Sub: got %v, %v", diff, err)
+	}
+	if _, err := price.Add(New(1, EUR)); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Add of another currency: got %v", err)
+	}
+	if total, err := price.Multiply(3); err != nil || total != New(5997, USD) {
+		t.Errorf("Multiply: got %v, %v", total, err)
+	}
+	if discount, err := price.Percent(15); err != nil || discount != New(299, USD) {
+		t.Errorf("Percent should round toward zero: got %v, %v", discount, err)
+	}
+	if lower, err := price.Min(New(500, USD)); err != nil || lower != New(500, USD) {
+		t.Errorf("Min: got %v, %v", lower, err)
+	}
+
+	for name, op := range map[string]func() (Money, error){
+		"Add":      func() (Money, error) { return New(math.MaxInt64, USD).Add(New(1, USD)) },
+		"Sub":      func() (Money, error) { return New(math.MinInt64, USD).Sub(New(1, USD)) },
+		"Multiply": func() (Money, error) { return New(math.MaxInt64/2+1, USD).Multiply(2) },
+		"Percent":  func() (Money, error) { return New(math.MaxInt64, USD).Percent(50) },
+	} {
+		if _, err := op(); !errors.Is(err, ErrOverflow) {
+			t.Errorf("%s: got %v, want ErrOverflow", name, err)
+		}
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	for _, tc := range []struct {
+		amount int64
+		ratios []int64
+		want   []int64
+	}{
+		{100, []int64{1, 1, 1}, []int64{34, 33, 33}},
+		{5, []int64{3, 7}, []int64{2, 3}},
+		{-100, []int64{1, 1, 1}, []int64{-34, -33, -33}},
+		{10, []int64{0, 1, 1}, []int64{0, 5, 5}},
+		{7, []int64{0, 1, 1}, []int64{0, 4, 3}},
+	} {
+		parts, err := New(tc.amount, USD).Allocate(tc.ratios...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sum int64
+		for i, part := range parts {
+			if part.Amount() != tc.want[i] || part.Currency() != USD {
+				t.Errorf("%d by %v: got %v, want %v", tc.amount, tc.ratios, parts, tc.want)
+				break
+			}
+			sum += part.Amount()
+		}
+		if sum != tc.amount {
+			t.Errorf("%d by %v: parts add up to %d", tc.amount, tc.ratios, sum)
+		}
+	}
+
+	if _, err := New(100, USD).Allocate(0, 0); err == nil {
+		t.Error("Allocate with zero ratios: want an error")
+	}
+	if _, err := New(100, USD).Allocate(1, -1); err == nil {
+		t.Error("Allocate with a negative ratio: want an error")
+	}
+	if parts, err := New(1000, VND).Split(3); err != nil || len(parts) != 3 || parts[0].Amount() != 334 {
+		t.Errorf("Split: got %v, %v", parts, err)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	for _, tc := range []struct {
+		money   Money
+		str     string
+		decimal string
+	}{
+		{New(123450, USD), "$1,234.50", "1234.50"},
+		{New(5, USD), "$0.05", "0.05"},
+		{New(-1999, EUR), "-€19.99", "-19.99"},
+		{New(120000, VND), "₫120,000", "120000"},
+		{New(123456789, GBP), "£1,234,567.89", "1234567.89"},
+		{New(1050, "CHF"), "10.50 CHF", "10.50"},
+		{New(math.MinInt64, USD), "-$92,233,720,368,547,758.08", "-92233720368547758.08"},
+	} {
+		if got := tc.money.String(); got != tc.str {
+			t.Errorf("String of %d %s: got %q, want %q", tc.money.Amount(), tc.money.Currency(), got, tc.str)
+		}
+		if got := tc.money.Decimal(); got != tc.decimal {
+			t.Errorf("Decimal of %d %s: got %q, want %q", tc.money.Amount(), tc.money.Currency(), got, tc.decimal)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	for input, want := range map[string]Money{
+		"19.99": New(1999, USD),
+		"19.9":  New(1990, USD),
+		"20":    New(2000, USD),
+		"-0.05": New(-5, USD),
+	} {
+		if got, err := Parse(input, USD); err != nil || got != want {
+			t.Errorf("Parse(%q): got %v, %v", input, got, err)
+		}
+	}
+	if got, err := Parse("120000", VND); err != nil || got != New(120000, VND) {
+		t.Errorf("Parse of VND: got %v, %v", got, err)
+	}
+	for _, bad := range []string{"", "1.999", "abc", "1.-5", "--1", "1e3"} {
+		if _, err := Parse(bad, USD); err == nil {
+			t.Errorf("Parse(%q): want an error", bad)
+		}
+	}
+	if _, err := Parse("1.5", VND); err == nil {
+		t.Error("Parse of decimals in a currency without minor unit: want an error")
+	}
+}
+
+func TestParseCurrency(t *testing.T) {
+	if c, err := ParseCurrency(" usd "); err != nil || c != USD || c.Exponent() != 2 {
+		t.Errorf("got %v, %v", c, err)
+	}
+	if _, err := ParseCurrency("XYZ"); err == nil {
+		t.Error("unknown currency: want an error")
+	}
+}
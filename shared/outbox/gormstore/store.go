@@ -0,0 +1,278 @@
+// Package gormstore keeps outbox messages in a Postgres table through GORM. The table
+// needs these columns:
+//
+//	id              BIGSERIAL PRIMARY KEY
+//	aggregate_id    UUID NOT NULL
+//	topic           TEXT NOT NULL
+//	type            TEXT NOT NULL
+//	payload         JSONB
+//	created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+//	published_at    TIMESTAMPTZ
+//	attempts        INT NOT NULL DEFAULT 0
+//	last_error      TEXT
+//	next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+//	failed_at       TIMESTAMPTZ
+//	trace_parent    TEXT NOT NULL DEFAULT ''
+//
+// with an index on (next_attempt_at, created_at) WHERE published_at IS NULL AND
+// failed_at IS NULL for the relay to find due messages.
+//
+// Store is an outbox.Claimer: Claim locks the due rows with FOR UPDATE SKIP LOCKED and
+// moves their next attempt past the lease in one statement, so relays sharing the table
+// never claim the same row. It is also an outbox.History: Published pages through the
+// published rows by id for replays.
+package gormstore
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/outbox"
+	"github.com/ductan2/microservice-app/shared/telemetry"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultTable is the table the services keep their outbox in.
+const DefaultTable = "outbox"
+
+type row struct {
+	ID            int64     `gorm:"primaryKey;autoIncrement"`
+	AggregateID   uuid.UUID `gorm:"type:uuid"`
+	Topic         string
+	Type          string
+	Payload       []byte `gorm:"type:jsonb"`
+	CreatedAt     time.Time
+	PublishedAt   sql.NullTime
+	Attempts      int
+	LastError     sql.NullString
+	NextAttemptAt time.Time
+	FailedAt      sql.NullTime
+	TraceParent   string
+}
+
+// Store implements outbox.Store.
+type Store struct {
+	db    *gorm.DB
+	table string
+}
+
+var (
+	_ outbox.Store   = (*Store)(nil)
+	_ outbox.Claimer = (*Store)(nil)
+	_ outbox.History = (*Store)(nil)
+)
+
+// New returns a Store on the given table, DefaultTable when empty.
+func New(db *gorm.DB, table string) *Store {
+	if table == "" {
+		table = DefaultTable
+	}
+	return &Store{db: db, table: table}
+}
+
+// WithTx returns a Store writing through tx, so messages are committed or rolled back
+// with the rest of the transaction.
+func (s *Store) WithTx(tx *gorm.DB) *Store {
+	return &Store{db: tx, table: s.table}
+}
+
+func (s *Store) query(ctx context.Context) *gorm.DB {
+	return s.db.WithContext(ctx).Table(s.table)
+}
+
+func (s *Store) Add(ctx context.Context, msgs ...*outbox.Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	now := time.Now()
+	rows := make([]row, len(msgs))
+	for i, msg := range msgs {
+		if msg.CreatedAt.IsZero() {
+			msg.CreatedAt = now
+		}
+		if msg.TraceParent == "" {
+			msg.TraceParent = telemetry.Traceparent(ctx)
+		}
+		rows[i] = row{
+			AggregateID:   msg.AggregateID,
+			Topic:         msg.Topic,
+			Type:          msg.Type,
+			Payload:       msg.Payload,
+			CreatedAt:     msg.CreatedAt,
+			NextAttemptAt: msg.CreatedAt,
+			TraceParent:   msg.TraceParent,
+		}
+	}
+	if err := s.query(ctx).Create(&rows).Error; err != nil {
+		return err
+	}
+	for i, msg := range msgs {
+		msg.ID = strconv.FormatInt(rows[i].ID, 10)
+	}
+	return nil
+}
+
+func (s *Store) Due(ctx context.Context, now time.Time, limit int) ([]outbox.Message, error) {
+	var rows []row
+	err := s.query(ctx).
+		Where("published_at IS NULL AND failed_at IS NULL AND next_attempt_at <= ?", now).
+		Order("next_attempt_at ASC, created_at ASC").
+		Limit(limit).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return messages(rows), nil
+}
+
+func (s *Store) Claim(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]outbox.Message, error) {
+	var rows []row
+	err := s.db.WithContext(ctx).Raw(`UPDATE ? SET next_attempt_at = ?
+		WHERE id IN (
+			SELECT id FROM ?
+			WHERE published_at IS NULL AND failed_at IS NULL AND next_attempt_at <= ?
+			ORDER BY next_attempt_at ASC, created_at ASC
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		clause.Table{Name: s.table}, now.Add(lease),
+		clause.Table{Name: s.table}, now, limit,
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	// RETURNING does not keep the order of the subquery
+	slices.SortFunc(rows, func(a, b row) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+	return messages(rows), nil
+}
+
+func (s *Store) Published(ctx context.Context, filter outbox.Filter, afterID string, limit int) ([]outbox.Message, error) {
+	query := s.query(ctx).Where("published_at IS NOT NULL")
+	if afterID != "" {
+		rowID, err := parseID(afterID)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("id > ?", rowID)
+	}
+	if len(filter.Topics) > 0 {
+		query = query.Where("topic IN ?", filter.Topics)
+	}
+	if len(filter.Types) > 0 {
+		query = query.Where("type IN ?", filter.Types)
+	}
+	if filter.AggregateID != uuid.Nil {
+		query = query.Where("aggregate_id = ?", filter.AggregateID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+
+	var rows []row
+	if err := query.Order("id ASC").Limit(limit).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return messages(rows), nil
+}
+
+func messages(rows []row) []outbox.Message {
+	msgs := make([]outbox.Message, len(rows))
+	for i, r := range rows {
+		msgs[i] = outbox.Message{
+			ID:          strconv.FormatInt(r.ID, 10),
+			AggregateID: r.AggregateID,
+			Topic:       r.Topic,
+			Type:        r.Type,
+			Payload:     r.Payload,
+			CreatedAt:   r.CreatedAt,
+			Attempts:    r.Attempts,
+			TraceParent: r.TraceParent,
+		}
+	}
+	return msgs
+}
+
+func (s *Store) MarkPublished(ctx context.Context, id string, at time.Time) error {
+	rowID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+	return s.query(ctx).
+		Where("id = ?", rowID).
+		Update("published_at", at).Error
+}
+
+func (s *Store) RecordFailure(ctx context.Context, id string, reason string, nextAttemptAt time.Time, park bool) error {
+	rowID, err := parseID(id)
+	if err != nil {
+		return err
+	}
+	updates := map[string]any{
+		"attempts":        gorm.Expr("attempts + 1"),
+		"last_error":      reason,
+		"next_attempt_at": nextAttemptAt,
+	}
+	if park {
+		updates["failed_at"] = time.Now()
+	}
+	return s.query(ctx).
+		Where("id = ? AND published_at IS NULL", rowID).
+		Updates(updates).Error
+}
+
+func (s *Store) DeletePublished(ctx context.Context, before time.Time) error {
+	return s.query(ctx).
+		Where("published_at IS NOT NULL AND published_at < ?", before).
+		Delete(&row{}).Error
+}
+
+func (s *Store) Stats(ctx context.Context) (outbox.Stats, error) {
+	var result struct {
+		Pending         int64
+		Retrying        int64
+		Failed          int64
+		OldestPendingAt sql.NullTime
+	}
+	err := s.query(ctx).
+		Select(`COUNT(*) FILTER (WHERE failed_at IS NULL) AS pending,
+			COUNT(*) FILTER (WHERE failed_at IS NULL AND attempts > 0) AS retrying,
+			COUNT(*) FILTER (WHERE failed_at IS NOT NULL) AS failed,
+			MIN(created_at) FILTER (WHERE failed_at IS NULL) AS oldest_pending_at`).
+		Where("published_at IS NULL").
+		Scan(&result).Error
+	if err != nil {
+		return outbox.Stats{}, err
+	}
+
+	stats := outbox.Stats{
+		Pending:  result.Pending,
+		Retrying: result.Retrying,
+		Failed:   result.Failed,
+	}
+	if result.OldestPendingAt.Valid {
+		stats.OldestPendingAt = &result.OldestPendingAt.Time
+	}
+	return stats, nil
+}
+
+func parseID(id string) (int64, error) {
+	rowID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid outbox message id %q: %w", id, err)
+	}
+	return rowID, nil
+}
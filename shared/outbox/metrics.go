@@ -0,0 +1,15 @@
+package outbox
+
+// Metrics is notified by the Relay of every publish, so each service can export the
+// counters in its own metrics registry. The backlog itself is read from Store.Stats.
+type Metrics interface {
+	Published(msg Message)
+	// Failed is called after a failed publish; parked is set when the message will not
+	// be retried.
+	Failed(msg Message, parked bool)
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) Published(Message)    {}
+func (nopMetrics) Failed(Message, bool) {}
@@ -0,0 +1,207 @@
+// Package mongostore keeps outbox messages in a MongoDB collection. It is a module of
+// its own so services on Postgres do not depend on the MongoDB driver.
+//
+// Add joins the transaction of the session carried by ctx, so a message written with the
+// mongo.SessionContext of a transaction is committed or aborted with it. Transactions
+// need a replica set; on a standalone server the message is written on its own.
+package mongostore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/outbox"
+	"github.com/ductan2/microservice-app/shared/telemetry"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DefaultCollection is the collection the services keep their outbox in.
+const DefaultCollection = "outbox"
+
+type document struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	AggregateID   string             `bson:"aggregate_id"`
+	Topic         string             `bson:"topic"`
+	Type          string             `bson:"type"`
+	Payload       []byte             `bson:"payload"`
+	CreatedAt     time.Time          `bson:"created_at"`
+	PublishedAt   *time.Time         `bson:"published_at"`
+	Attempts      int                `bson:"attempts"`
+	LastError     string             `bson:"last_error,omitempty"`
+	NextAttemptAt time.Time          `bson:"next_attempt_at"`
+	FailedAt      *time.Time         `bson:"failed_at"`
+	TraceParent   string             `bson:"trace_parent,omitempty"`
+}
+
+// Store implements outbox.Store.
+type Store struct {
+	collection *mongo.Collection
+}
+
+var _ outbox.Store = (*Store)(nil)
+
+// New returns a Store on the given collection of db, DefaultCollection when empty.
+func New(db *mongo.Database, collection string) *Store {
+	if collection == "" {
+		collection = DefaultCollection
+	}
+	return &Store{collection: db.Collection(collection)}
+}
+
+// EnsureIndexes creates the indexes the relay queries by.
+func (s *Store) EnsureIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "published_at", Value: 1}, {Key: "failed_at", Value: 1}, {Key: "next_attempt_at", Value: 1}, {Key: "created_at", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "published_at", Value: 1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create outbox indexes: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) Add(ctx context.Context, msgs ...*outbox.Message) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	now := time.Now()
+	docs := make([]any, len(msgs))
+	for i, msg := range msgs {
+		if msg.CreatedAt.IsZero() {
+			msg.CreatedAt = now
+		}
+		if msg.TraceParent == "" {
+			msg.TraceParent = telemetry.Traceparent(ctx)
+		}
+		docs[i] = document{
+			ID:            primitive.NewObjectID(),
+			AggregateID:   msg.AggregateID.String(),
+			Topic:         msg.Topic,
+			Type:          msg.Type,
+			Payload:       msg.Payload,
+			CreatedAt:     msg.CreatedAt,
+			NextAttemptAt: msg.CreatedAt,
+			TraceParent:   msg.TraceParent,
+		}
+	}
+	if _, err := s.collection.InsertMany(ctx, docs); err != nil {
+		return err
+	}
+	for i, msg := range msgs {
+		msg.ID = docs[i].(document).ID.Hex()
+	}
+	return nil
+}
+
+func (s *Store) Due(ctx context.Context, now time.Time, limit int) ([]outbox.Message, error) {
+	filter := bson.M{
+		"published_at":    nil,
+		"failed_at":       nil,
+		"next_attempt_at": bson.M{"$lte": now},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}, {Key: "created_at", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []document
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	msgs := make([]outbox.Message, 0, len(docs))
+	for _, doc := range docs {
+		aggregateID, err := uuid.Parse(doc.AggregateID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid aggregate id on outbox message %s: %w", doc.ID.Hex(), err)
+		}
+		msgs = append(msgs, outbox.Message{
+			ID:          doc.ID.Hex(),
+			AggregateID: aggregateID,
+			Topic:       doc.Topic,
+			Type:        doc.Type,
+			Payload:     doc.Payload,
+			CreatedAt:   doc.CreatedAt,
+			Attempts:    doc.Attempts,
+			TraceParent: doc.TraceParent,
+		})
+	}
+	return msgs, nil
+}
+
+func (s *Store) MarkPublished(ctx context.Context, id string, at time.Time) error {
+	docID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid outbox message id %q: %w", id, err)
+	}
+	_, err = s.collection.UpdateByID(ctx, docID, bson.M{"$set": bson.M{"published_at": at}})
+	return err
+}
+
+func (s *Store) RecordFailure(ctx context.Context, id string, reason string, nextAttemptAt time.Time, park bool) error {
+	docID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid outbox message id %q: %w", id, err)
+	}
+	set := bson.M{
+		"last_error":      reason,
+		"next_attempt_at": nextAttemptAt,
+	}
+	if park {
+		set["failed_at"] = time.Now()
+	}
+	_, err = s.collection.UpdateOne(ctx,
+		bson.M{"_id": docID, "published_at": nil},
+		bson.M{"$set": set, "$inc": bson.M{"attempts": 1}},
+	)
+	return err
+}
+
+func (s *Store) DeletePublished(ctx context.Context, before time.Time) error {
+	_, err := s.collection.DeleteMany(ctx, bson.M{"published_at": bson.M{"$ne": nil, "$lt": before}})
+	return err
+}
+
+func (s *Store) Stats(ctx context.Context) (outbox.Stats, error) {
+	var stats outbox.Stats
+	var err error
+
+	pending := bson.M{"published_at": nil, "failed_at": nil}
+	if stats.Pending, err = s.collection.CountDocuments(ctx, pending); err != nil {
+		return outbox.Stats{}, err
+	}
+	retrying := bson.M{"published_at": nil, "failed_at": nil, "attempts": bson.M{"$gt": 0}}
+	if stats.Retrying, err = s.collection.CountDocuments(ctx, retrying); err != nil {
+		return outbox.Stats{}, err
+	}
+	failed := bson.M{"published_at": nil, "failed_at": bson.M{"$ne": nil}}
+	if stats.Failed, err = s.collection.CountDocuments(ctx, failed); err != nil {
+		return outbox.Stats{}, err
+	}
+
+	if stats.Pending > 0 {
+		var oldest document
+		opts := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: 1}})
+		if err := s.collection.FindOne(ctx, pending, opts).Decode(&oldest); err != nil && err != mongo.ErrNoDocuments {
+			return outbox.Stats{}, err
+		}
+		if !oldest.CreatedAt.IsZero() {
+			stats.OldestPendingAt = &oldest.CreatedAt
+		}
+	}
+	return stats, nil
+}
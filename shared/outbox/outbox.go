@@ -0,0 +1,122 @@
+// Package outbox implements the transactional outbox shared by the Go services.
+//
+// A service records the events it wants to publish in the same transaction as the
+// change they describe, through a Writer backed by the Store of its database. A Relay
+// then polls the Store and publishes the due events to RabbitMQ, waiting for the broker
+// to confirm each one before marking it published. A failed publish is retried with
+// exponential backoff and the event is parked once it has failed MaxAttempts times, so
+// delivery is at least once: consumers must tolerate duplicates.
+//
+// The gormstore package stores events in a Postgres table and the mongostore module in
+// a MongoDB collection.
+//
+// Messages keep the trace of the request that wrote them: the publisher continues it
+// with a producer span and passes it on in the traceparent header of the message.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Message is an event waiting in the outbox. ID is assigned by the Store when the
+// message is added. Topic and Type are mapped to an exchange and routing key by the
+// Route of the publisher.
+type Message struct {
+	ID          string
+	AggregateID uuid.UUID
+	Topic       string
+	Type        string
+	// Payload is the JSON body of the published message
+	Payload   []byte
+	CreatedAt time.Time
+	// Attempts counts the failed publishes so far
+	Attempts int
+	// TraceParent is the traceparent of the span that wrote the message, set by the Store
+	// from ctx when empty. The publisher carries it on to the consumers.
+	TraceParent string
+	// Replay is the ID of the replay publishing the message again, empty for the first
+	// delivery. The publisher adds it to the message ID, so the consumers' inboxes do not
+	// skip the message as a duplicate, and sends it in the replay_id header.
+	Replay string
+}
+
+// Stats describes the outbox backlog. Pending includes the messages backing off after a
+// failed publish (Retrying) but not the parked ones (Failed). OldestPendingAt is nil
+// when nothing is pending.
+type Stats struct {
+	Pending         int64
+	Retrying        int64
+	Failed          int64
+	OldestPendingAt *time.Time
+}
+
+// Lag is the age of the oldest pending message at now, 0 when none is pending.
+func (s Stats) Lag(now time.Time) time.Duration {
+	if s.OldestPendingAt == nil {
+		return 0
+	}
+	return max(now.Sub(*s.OldestPendingAt), 0)
+}
+
+// Store persists outbox messages. Add must write through the caller's transaction when
+// there is one, which is how the stores are scoped: see gormstore.Store.WithTx and the
+// session handling of mongostore.
+type Store interface {
+	// Add inserts the messages and sets their ID and CreatedAt.
+	Add(ctx context.Context, msgs ...*Message) error
+	// Due returns the unpublished messages whose next attempt is due at now, oldest
+	// first; parked messages are skipped.
+	Due(ctx context.Context, now time.Time, limit int) ([]Message, error)
+	MarkPublished(ctx context.Context, id string, at time.Time) error
+	// RecordFailure counts a failed publish and schedules the next attempt at
+	// nextAttemptAt, or parks the message when park is set.
+	RecordFailure(ctx context.Context, id string, reason string, nextAttemptAt time.Time, park bool) error
+	// DeletePublished removes the messages published before the given time.
+	DeletePublished(ctx context.Context, before time.Time) error
+	Stats(ctx context.Context) (Stats, error)
+}
+
+// Claimer is implemented by the stores that let several relays share an outbox. Claim
+// returns the due messages like Due and hides them from the other relays for lease, so
+// each message is published by one relay. A message whose relay stopped before marking
+// it is due again once the lease ends.
+type Claimer interface {
+	Claim(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]Message, error)
+}
+
+// Writer records events in a Store.
+type Writer struct {
+	store Store
+}
+
+func NewWriter(store Store) *Writer {
+	return &Writer{store: store}
+}
+
+// Write marshals payload to JSON and adds it to the outbox.
+func (w *Writer) Write(ctx context.Context, aggregateID uuid.UUID, topic, eventType string, payload any) error {
+	msg, err := NewMessage(aggregateID, topic, eventType, payload)
+	if err != nil {
+		return err
+	}
+	return w.store.Add(ctx, msg)
+}
+
+// NewMessage builds a message with payload marshalled to JSON.
+func NewMessage(aggregateID uuid.UUID, topic, eventType string, payload any) (*Message, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s payload: %w", eventType, err)
+	}
+	return &Message{
+		AggregateID: aggregateID,
+		Topic:       topic,
+		Type:        eventType,
+		Payload:     body,
+	}, nil
+}
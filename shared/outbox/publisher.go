@@ -0,0 +1,202 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/telemetry"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrNotConfirmed is returned when the broker nacks a message or does not confirm it in
+// time; the message is retried.
+var ErrNotConfirmed = errors.New("message not confirmed by the broker")
+
+// Publisher delivers a message to the broker. It returns once the broker has taken
+// responsibility for the message.
+type Publisher interface {
+	Publish(ctx context.Context, msg Message) error
+}
+
+// Route maps a message to the exchange and routing key it is published with.
+type Route func(msg Message) (exchange, routingKey string)
+
+// ToExchange publishes every message to one exchange with its topic as routing key.
+func ToExchange(exchange string) Route {
+	return func(msg Message) (string, string) {
+		return exchange, msg.Topic
+	}
+}
+
+// ExchangePerTopic publishes every message to the exchange named after its topic, with
+// its type as routing key.
+func ExchangePerTopic(msg Message) (string, string) {
+	return msg.Topic, msg.Type
+}
+
+// ToQueue publishes every message to queue through the default exchange, so it reaches
+// one consumer only; replays use it to feed a consumer again.
+func ToQueue(queue string) Route {
+	return func(Message) (string, string) {
+		return "", queue
+	}
+}
+
+// ParseRoutes reads a route table such as "user.*=user.events,order.events=*". Each
+// entry maps a topic pattern, an exact topic, a prefix ending in "*" or "*" alone, to an
+// exchange; the first entry matching the topic of a message wins. Messages go to a named
+// exchange with their topic as routing key, like ToExchange, and with the exchange "*"
+// to the exchange named after their topic with their type as routing key, like
+// ExchangePerTopic. A message no entry matches cannot be published and is parked after
+// MaxAttempts.
+func ParseRoutes(s string) (Route, error) {
+	type entry struct {
+		pattern  string
+		exchange string
+	}
+	var entries []entry
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		pattern, exchange, ok := strings.Cut(item, "=")
+		pattern, exchange = strings.TrimSpace(pattern), strings.TrimSpace(exchange)
+		if !ok || pattern == "" || exchange == "" {
+			return nil, fmt.Errorf("invalid route %q: want pattern=exchange", item)
+		}
+		if strings.Contains(strings.TrimSuffix(pattern, "*"), "*") {
+			return nil, fmt.Errorf("invalid route %q: \"*\" may only end a pattern", item)
+		}
+		entries = append(entries, entry{pattern: pattern, exchange: exchange})
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("no routes")
+	}
+
+	return func(msg Message) (string, string) {
+		for _, e := range entries {
+			prefix, wildcard := strings.CutSuffix(e.pattern, "*")
+			if msg.Topic != e.pattern && !(wildcard && strings.HasPrefix(msg.Topic, prefix)) {
+				continue
+			}
+			if e.exchange == "*" {
+				return ExchangePerTopic(msg)
+			}
+			return e.exchange, msg.Topic
+		}
+		return "", ""
+	}, nil
+}
+
+// AMQPPublisher publishes to RabbitMQ on a channel in confirm mode. The channel must not
+// be shared with other publishers, since confirmations are counted per channel.
+type AMQPPublisher struct {
+	mu             sync.Mutex
+	channel        *amqp.Channel
+	route          Route
+	confirmTimeout time.Duration
+}
+
+// NewAMQPPublisher puts channel into confirm mode. Each publish waits up to
+// confirmTimeout for the broker to confirm the message.
+func NewAMQPPublisher(channel *amqp.Channel, route Route, confirmTimeout time.Duration) (*AMQPPublisher, error) {
+	if err := channel.Confirm(false); err != nil {
+		return nil, fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+	return &AMQPPublisher{
+		channel:        channel,
+		route:          route,
+		confirmTimeout: confirmTimeout,
+	}, nil
+}
+
+// DeclareExchanges declares durable topic exchanges, for routes that publish to
+// exchanges no other service declares.
+func (p *AMQPPublisher) DeclareExchanges(names ...string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, name := range names {
+		if err := p.channel.ExchangeDeclare(name, "topic", true, false, false, false, nil); err != nil {
+			return fmt.Errorf("failed to declare exchange %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (p *AMQPPublisher) Publish(ctx context.Context, msg Message) error {
+	if len(msg.Payload) == 0 {
+		return fmt.Errorf("message payload is empty")
+	}
+	exchange, routingKey := p.route(msg)
+	if exchange == "" && routingKey == "" {
+		return fmt.Errorf("no exchange routes topic %q", msg.Topic)
+	}
+	messageID := msg.ID
+	// The default exchange delivers to the queue named by the routing key
+	destination := exchange
+	if destination == "" {
+		destination = routingKey
+	}
+
+	ctx, span := telemetry.StartFromTraceparent(ctx, msg.TraceParent, destination+" publish", trace.SpanKindProducer)
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("messaging.system", "rabbitmq"),
+		attribute.String("messaging.destination.name", destination),
+		attribute.String("messaging.rabbitmq.destination.routing_key", routingKey),
+	)
+
+	headers := amqp.Table{
+		"aggregate_id": msg.AggregateID.String(),
+		"event_type":   msg.Type,
+		"topic":        msg.Topic,
+	}
+	if msg.Replay != "" {
+		messageID = msg.ID + ":replay:" + msg.Replay
+		headers["replay_id"] = msg.Replay
+	}
+	span.SetAttributes(attribute.String("messaging.message.id", messageID))
+	telemetry.InjectHeaders(ctx, headers)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	confirmation, err := p.channel.PublishWithDeferredConfirmWithContext(
+		ctx,
+		exchange,
+		routingKey,
+		false, // mandatory
+		false, // immediate
+		amqp.Publishing{
+			ContentType:  "application/json",
+			Body:         msg.Payload,
+			DeliveryMode: amqp.Persistent,
+			MessageId:    messageID,
+			Timestamp:    time.Now(),
+			Type:         msg.Type,
+			Headers:      headers,
+		},
+	)
+	if err != nil {
+		telemetry.SetError(span, err)
+		return fmt.Errorf("failed to publish to RabbitMQ: %w", err)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, p.confirmTimeout)
+	defer cancel()
+	acked, err := confirmation.WaitContext(waitCtx)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrNotConfirmed, err)
+	} else if !acked {
+		err = fmt.Errorf("%w: nacked by %s", ErrNotConfirmed, destination)
+	}
+	telemetry.SetError(span, err)
+	return err
+}
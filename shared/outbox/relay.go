@@ -0,0 +1,177 @@
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// cleanupInterval is how often the relay deletes messages older than the retention.
+const cleanupInterval = time.Hour
+
+// Config tunes a Relay.
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// MaxAttempts is how many times a message is published before it is parked
+	MaxAttempts int
+	// BackoffBase is the delay after the first failed publish; it doubles with every
+	// further failure up to BackoffMax
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// Retention is how long published messages are kept; 0 keeps them forever
+	Retention time.Duration
+	// Lease is how long a batch is hidden from the other relays of the same store, so
+	// several replicas can relay one outbox. It must exceed the time a batch takes to
+	// publish. 0, or a store that is not a Claimer, reads the due messages without
+	// claiming them, for a single relay per store.
+	Lease time.Duration
+	// Metrics is optional
+	Metrics Metrics
+	// Validator is optional. A message it rejects is parked without being published,
+	// since publishing it again would not make it valid
+	Validator Validator
+}
+
+// Validator checks the payload of a message against the schema of its type before it is
+// published. events.Schemas of shared/events implements it.
+type Validator interface {
+	Validate(msgType string, payload []byte) error
+}
+
+// Relay publishes the due messages of a Store every PollInterval.
+type Relay struct {
+	store       Store
+	publisher   Publisher
+	cfg         Config
+	metrics     Metrics
+	lastCleanup time.Time
+	stopChan    chan struct{}
+	stopOnce    sync.Once
+}
+
+func NewRelay(store Store, publisher Publisher, cfg Config) *Relay {
+	metrics := cfg.Metrics
+	if metrics == nil {
+		metrics = nopMetrics{}
+	}
+	if _, ok := store.(Claimer); cfg.Lease > 0 && !ok {
+		slog.Warn("outbox store cannot claim messages; run a single relay for it", "lease", cfg.Lease)
+	}
+	return &Relay{
+		store:     store,
+		publisher: publisher,
+		cfg:       cfg,
+		metrics:   metrics,
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// Start publishes until ctx is cancelled or Stop is called.
+func (r *Relay) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	slog.Info("outbox relay started", "interval", r.cfg.PollInterval, "batch_size", r.cfg.BatchSize)
+
+	r.tick(ctx)
+	for {
+		select {
+		case <-ticker.C:
+			r.tick(ctx)
+		case <-r.stopChan:
+			slog.Info("outbox relay stopped")
+			return
+		case <-ctx.Done():
+			slog.Info("outbox relay context cancelled")
+			return
+		}
+	}
+}
+
+// Stop ends Start; it is safe to call more than once.
+func (r *Relay) Stop() {
+	r.stopOnce.Do(func() { close(r.stopChan) })
+}
+
+func (r *Relay) tick(ctx context.Context) {
+	if err := r.ProcessDue(ctx); err != nil {
+		slog.ErrorContext(ctx, "outbox processing failed", "error", err)
+	}
+	if r.cfg.Retention > 0 && time.Since(r.lastCleanup) >= cleanupInterval {
+		r.lastCleanup = time.Now()
+		if err := r.store.DeletePublished(ctx, r.lastCleanup.Add(-r.cfg.Retention)); err != nil {
+			slog.WarnContext(ctx, "failed to delete published outbox messages", "error", err)
+		}
+	}
+}
+
+// ProcessDue publishes one batch of due messages. A message that fails is rescheduled
+// and does not hold back the rest of the batch.
+func (r *Relay) ProcessDue(ctx context.Context) error {
+	now := time.Now()
+	msgs, err := r.due(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range msgs {
+		if r.cfg.Validator != nil {
+			if err := r.cfg.Validator.Validate(msg.Type, msg.Payload); err != nil {
+				r.recordFailure(ctx, msg, err, now, true)
+				continue
+			}
+		}
+		if err := r.publisher.Publish(ctx, msg); err != nil {
+			r.recordFailure(ctx, msg, err, now, false)
+			continue
+		}
+		r.metrics.Published(msg)
+
+		if err := r.store.MarkPublished(ctx, msg.ID, time.Now()); err != nil {
+			// The message will be published again, which at-least-once delivery allows
+			slog.WarnContext(ctx, "failed to mark outbox message as published", "message_id", msg.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// due returns the next batch, claimed when the relay shares its store.
+func (r *Relay) due(ctx context.Context, now time.Time) ([]Message, error) {
+	if claimer, ok := r.store.(Claimer); ok && r.cfg.Lease > 0 {
+		return claimer.Claim(ctx, now, r.cfg.BatchSize, r.cfg.Lease)
+	}
+	return r.store.Due(ctx, now, r.cfg.BatchSize)
+}
+
+// recordFailure reschedules a message that failed, or parks it after MaxAttempts or when
+// invalid.
+func (r *Relay) recordFailure(ctx context.Context, msg Message, publishErr error, now time.Time, invalid bool) {
+	attempts := msg.Attempts + 1
+	park := invalid || attempts >= r.cfg.MaxAttempts
+	switch {
+	case invalid:
+		slog.ErrorContext(ctx, "outbox message does not match its schema, giving up", "message_id", msg.ID, "type", msg.Type, "error", publishErr)
+	case park:
+		slog.ErrorContext(ctx, "failed to publish outbox message, giving up", "message_id", msg.ID, "type", msg.Type, "attempts", attempts, "error", publishErr)
+	default:
+		slog.WarnContext(ctx, "failed to publish outbox message", "message_id", msg.ID, "attempt", attempts, "max_attempts", r.cfg.MaxAttempts, "error", publishErr)
+	}
+	r.metrics.Failed(msg, park)
+
+	nextAttemptAt := now.Add(Backoff(attempts, r.cfg.BackoffBase, r.cfg.BackoffMax))
+	if err := r.store.RecordFailure(ctx, msg.ID, publishErr.Error(), nextAttemptAt, park); err != nil {
+		slog.WarnContext(ctx, "failed to record failed publish of outbox message", "message_id", msg.ID, "error", err)
+	}
+}
+
+// Backoff is the delay before the next attempt after the given number of failed ones:
+// base, doubling with every failure, capped at ceiling.
+func Backoff(attempts int, base, ceiling time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < ceiling; i++ {
+		delay *= 2
+	}
+	return min(delay, ceiling)
+}
@@ -0,0 +1,278 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type memoryStore struct {
+	msgs      []*Message
+	published map[string]bool
+	parked    map[string]bool
+	nextAt    map[string]time.Time
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		published: make(map[string]bool),
+		parked:    make(map[string]bool),
+		nextAt:    make(map[string]time.Time),
+	}
+}
+
+func (s *memoryStore) Add(_ context.Context, msgs ...*Message) error {
+	for _, msg := range msgs {
+		msg.ID = strconv.Itoa(len(s.msgs) + 1)
+		msg.CreatedAt = time.Now()
+		s.msgs = append(s.msgs, msg)
+	}
+	return nil
+}
+
+func (s *memoryStore) Due(_ context.Context, now time.Time, limit int) ([]Message, error) {
+	var due []Message
+	for _, msg := range s.msgs {
+		if s.published[msg.ID] || s.parked[msg.ID] || s.nextAt[msg.ID].After(now) {
+			continue
+		}
+		if len(due) == limit {
+			break
+		}
+		due = append(due, *msg)
+	}
+	return due, nil
+}
+
+func (s *memoryStore) Claim(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]Message, error) {
+	due, _ := s.Due(ctx, now, limit)
+	for _, msg := range due {
+		s.nextAt[msg.ID] = now.Add(lease)
+	}
+	return due, nil
+}
+
+func (s *memoryStore) MarkPublished(_ context.Context, id string, _ time.Time) error {
+	s.published[id] = true
+	return nil
+}
+
+func (s *memoryStore) RecordFailure(_ context.Context, id string, _ string, nextAttemptAt time.Time, park bool) error {
+	for _, msg := range s.msgs {
+		if msg.ID == id {
+			msg.Attempts++
+		}
+	}
+	s.nextAt[id] = nextAttemptAt
+	s.parked[id] = park
+	return nil
+}
+
+func (s *memoryStore) DeletePublished(context.Context, time.Time) error { return nil }
+
+func (s *memoryStore) Stats(context.Context) (Stats, error) { return Stats{}, nil }
+
+type fakePublisher struct {
+	fail map[string]bool
+	sent []string
+}
+
+func (p *fakePublisher) Publish(_ context.Context, msg Message) error {
+	if p.fail[msg.Type] {
+		return errors.New("broker unavailable")
+	}
+	p.sent = append(p.sent, msg.Type)
+	return nil
+}
+
+// publishFunc is a Publisher calling a function.
+type publishFunc func(ctx context.Context, msg Message) error
+
+func (f publishFunc) Publish(ctx context.Context, msg Message) error { return f(ctx, msg) }
+
+type countingMetrics struct {
+	published, failed, parked int
+}
+
+func (m *countingMetrics) Published(Message) { m.published++ }
+
+func (m *countingMetrics) Failed(_ Message, parked bool) {
+	m.failed++
+	if parked {
+		m.parked++
+	}
+}
+
+func TestRelayPublishesDueMessages(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	writer := NewWriter(store)
+	for _, eventType := range []string{"user.created", "user.updated"} {
+		if err := writer.Write(ctx, uuid.New(), "user.events", eventType, map[string]string{"type": eventType}); err != nil {
+			t.Fatalf("write %s: %v", eventType, err)
+		}
+	}
+
+	publisher := &fakePublisher{}
+	metrics := &countingMetrics{}
+	relay := NewRelay(store, publisher, Config{BatchSize: 10, MaxAttempts: 3, BackoffBase: time.Second, BackoffMax: time.Minute, Metrics: metrics})
+	if err := relay.ProcessDue(ctx); err != nil {
+		t.Fatalf("process: %v", err)
+	}
+
+	if len(publisher.sent) != 2 || publisher.sent[0] != "user.created" {
+		t.Fatalf("expected both messages in order, got %v", publisher.sent)
+	}
+	if !store.published["1"] || !store.published["2"] || metrics.published != 2 {
+		t.Fatalf("expected both messages marked published")
+	}
+
+	if err := relay.ProcessDue(ctx); err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	if len(publisher.sent) != 2 {
+		t.Fatalf("published messages must not be sent again, got %v", publisher.sent)
+	}
+}
+
+func TestRelayBacksOffAndParksFailingMessages(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	if err := NewWriter(store).Write(ctx, uuid.New(), "user.events", "user.deleted", map[string]string{}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	metrics := &countingMetrics{}
+	relay := NewRelay(store, &fakePublisher{fail: map[string]bool{"user.deleted": true}}, Config{BatchSize: 10, MaxAttempts: 2, BackoffBase: time.Second, BackoffMax: time.Minute, Metrics: metrics})
+
+	if err := relay.ProcessDue(ctx); err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	if store.parked["1"] || !store.nextAt["1"].After(time.Now()) {
+		t.Fatalf("expected the first failure to be retried later")
+	}
+
+	// Skip the backoff
+	store.nextAt["1"] = time.Time{}
+	if err := relay.ProcessDue(ctx); err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	if !store.parked["1"] || metrics.failed != 2 || metrics.parked != 1 {
+		t.Fatalf("expected the message to be parked after MaxAttempts, got %+v", metrics)
+	}
+}
+
+func TestRelaysShareAClaimingStore(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	writer := NewWriter(store)
+	for _, eventType := range []string{"order.created", "order.paid", "order.refunded"} {
+		if err := writer.Write(ctx, uuid.New(), "order.events", eventType, map[string]string{}); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	cfg := Config{BatchSize: 2, MaxAttempts: 3, BackoffBase: time.Second, BackoffMax: time.Minute, Lease: time.Minute}
+	second := &fakePublisher{}
+	secondRelay := NewRelay(store, second, cfg)
+	var first []string
+	firstRelay := NewRelay(store, publishFunc(func(ctx context.Context, msg Message) error {
+		// The second relay polls while the first one is publishing its batch
+		if len(first) == 0 {
+			if err := secondRelay.ProcessDue(ctx); err != nil {
+				t.Fatalf("second relay: %v", err)
+			}
+		}
+		first = append(first, msg.Type)
+		return nil
+	}), cfg)
+	if err := firstRelay.ProcessDue(ctx); err != nil {
+		t.Fatalf("first relay: %v", err)
+	}
+
+	if len(first) != 2 || len(second.sent) != 1 || second.sent[0] != "order.refunded" {
+		t.Fatalf("expected the relays to split the messages, got %v and %v", first, second.sent)
+	}
+}
+
+func TestParseRoutes(t *testing.T) {
+	route, err := ParseRoutes("user.security=security.events, user.*=user.events, order.events=*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		topic, msgType     string
+		exchange, routeKey string
+	}{
+		{"user.security", "user.locked", "security.events", "user.security"},
+		{"user.profile", "user.updated", "user.events", "user.profile"},
+		{"order.events", "order.paid", "order.events", "order.paid"},
+		{"content.events", "lesson.published", "", ""},
+	} {
+		exchange, key := route(Message{Topic: tc.topic, Type: tc.msgType})
+		if exchange != tc.exchange || key != tc.routeKey {
+			t.Errorf("%s: got %s/%s, want %s/%s", tc.topic, exchange, key, tc.exchange, tc.routeKey)
+		}
+	}
+
+	for _, bad := range []string{"", "user.events", "=user.events", "user.events=", "*.events=x"} {
+		if _, err := ParseRoutes(bad); err == nil {
+			t.Errorf("%q: want an error", bad)
+		}
+	}
+}
+
+type rejectingValidator struct{ msgType string }
+
+func (v rejectingValidator) Validate(msgType string, _ []byte) error {
+	if msgType == v.msgType {
+		return errors.New("order_id is required")
+	}
+	return nil
+}
+
+func TestRelayParksInvalidMessages(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	writer := NewWriter(store)
+	for _, msgType := range []string{"order.created", "order.paid"} {
+		if err := writer.Write(ctx, uuid.New(), "order.events", msgType, map[string]string{}); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	publisher := &fakePublisher{}
+	metrics := &countingMetrics{}
+	relay := NewRelay(store, publisher, Config{BatchSize: 10, MaxAttempts: 5, BackoffBase: time.Second, BackoffMax: time.Minute, Metrics: metrics, Validator: rejectingValidator{"order.created"}})
+	if err := relay.ProcessDue(ctx); err != nil {
+		t.Fatalf("process: %v", err)
+	}
+
+	if len(publisher.sent) != 1 || publisher.sent[0] != "order.paid" {
+		t.Fatalf("expected only the valid message to be published, got %v", publisher.sent)
+	}
+	if !store.parked["1"] || metrics.parked != 1 {
+		t.Fatalf("expected the invalid message to be parked at once, got %+v", metrics)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	cases := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 5 * time.Second},
+		{2, 10 * time.Second},
+		{4, 40 * time.Second},
+		{20, 10 * time.Minute},
+	}
+	for _, tc := range cases {
+		if got := Backoff(tc.attempts, 5*time.Second, 10*time.Minute); got != tc.want {
+			t.Errorf("Backoff(%d) = %s, want %s", tc.attempts, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultReplayBatchSize is the number of messages a replay reads at a time.
+const DefaultReplayBatchSize = 100
+
+// Filter selects published messages to replay. Zero fields match every message.
+type Filter struct {
+	// Topics and Types match any of their values
+	Topics      []string
+	Types       []string
+	AggregateID uuid.UUID
+	// From and To bound CreatedAt: From included, To excluded
+	From time.Time
+	To   time.Time
+}
+
+// Match reports whether msg passes the filter.
+func (f Filter) Match(msg Message) bool {
+	return (len(f.Topics) == 0 || slices.Contains(f.Topics, msg.Topic)) &&
+		(len(f.Types) == 0 || slices.Contains(f.Types, msg.Type)) &&
+		(f.AggregateID == uuid.Nil || f.AggregateID == msg.AggregateID) &&
+		(f.From.IsZero() || !msg.CreatedAt.Before(f.From)) &&
+		(f.To.IsZero() || msg.CreatedAt.Before(f.To))
+}
+
+// History is implemented by the stores that keep published messages, for replays.
+type History interface {
+	// Published returns up to limit published messages matching filter whose ID comes
+	// after afterID, in the order they were added; afterID is empty to start from the
+	// first one.
+	Published(ctx context.Context, filter Filter, afterID string, limit int) ([]Message, error)
+}
+
+// ReplayConfig describes a replay.
+type ReplayConfig struct {
+	// ID names the replay. It is sent with each message, which the consumers' inboxes
+	// then tell apart from the first delivery; running a replay again with the same ID
+	// is deduplicated like a redelivery.
+	ID     string
+	Filter Filter
+	// After resumes a replay after the message with this ID, such as the LastID of a
+	// replay that stopped
+	After string
+	// Max stops the replay after this many messages, 0 for no limit
+	Max int
+	// BatchSize is the number of messages read at a time, DefaultReplayBatchSize when 0
+	BatchSize int
+	// Pause is waited between batches, to spare the consumers
+	Pause time.Duration
+	// DryRun counts the matching messages without publishing them
+	DryRun bool
+}
+
+// ReplayResult reports the progress of a replay.
+type ReplayResult struct {
+	// Matched counts the messages read, Published those the broker confirmed
+	Matched   int
+	Published int
+	// LastID is the ID of the last message published, or read in a dry run; pass it as
+	// After to resume a replay that failed
+	LastID string
+}
+
+// Replay publishes again the messages of history matching cfg.Filter, in the order they
+// were added, through publisher: the same Route as the relay sends them to the exchanges
+// of the first delivery, ToQueue to the queue of one consumer. It stops at the first
+// failed publish, whose message is retried by resuming after the returned LastID.
+// Payloads are not validated again: they were valid when first published.
+func Replay(ctx context.Context, history History, publisher Publisher, cfg ReplayConfig) (ReplayResult, error) {
+	if cfg.ID == "" {
+		return ReplayResult{}, errors.New("replay id is required")
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultReplayBatchSize
+	}
+
+	result := ReplayResult{LastID: cfg.After}
+	for cfg.Max == 0 || result.Matched < cfg.Max {
+		limit := cfg.BatchSize
+		if cfg.Max > 0 {
+			limit = min(limit, cfg.Max-result.Matched)
+		}
+		msgs, err := history.Published(ctx, cfg.Filter, result.LastID, limit)
+		if err != nil {
+			return result, fmt.Errorf("failed to read published messages: %w", err)
+		}
+		for _, msg := range msgs {
+			result.Matched++
+			if !cfg.DryRun {
+				msg.Replay = cfg.ID
+				if err := publisher.Publish(ctx, msg); err != nil {
+					return result, fmt.Errorf("failed to replay message %s: %w", msg.ID, err)
+				}
+				result.Published++
+			}
+			result.LastID = msg.ID
+		}
+		slog.InfoContext(ctx, "outbox replay progress", "replay", cfg.ID, "matched", result.Matched, "published", result.Published, "last_id", result.LastID)
+		if len(msgs) < limit {
+			break
+		}
+
+		if cfg.Pause > 0 && !cfg.DryRun {
+			select {
+			case <-ctx.Done():
+				return result, ctx.Err()
+			case <-time.After(cfg.Pause):
+			}
+		}
+	}
+	return result, nil
+}
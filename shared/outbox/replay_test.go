@@ -0,0 +1,134 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (s *memoryStore) Published(_ context.Context, filter Filter, afterID string, limit int) ([]Message, error) {
+	after, _ := strconv.Atoi(afterID)
+	var msgs []Message
+	for _, msg := range s.msgs {
+		id, _ := strconv.Atoi(msg.ID)
+		if id <= after || !s.published[msg.ID] || !filter.Match(*msg) {
+			continue
+		}
+		if len(msgs) == limit {
+			break
+		}
+		msgs = append(msgs, *msg)
+	}
+	return msgs, nil
+}
+
+// publishedStore holds n published order events and one user event.
+func publishedStore(t *testing.T, n int) *memoryStore {
+	store := newMemoryStore()
+	for range n {
+		msg, err := NewMessage(uuid.New(), "order.events", "order.created", map[string]string{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		store.Add(context.Background(), msg)
+	}
+	user, _ := NewMessage(uuid.New(), "user.events", "user.registered", map[string]string{})
+	store.Add(context.Background(), user)
+	for _, msg := range store.msgs {
+		store.published[msg.ID] = true
+	}
+	return store
+}
+
+func TestReplay(t *testing.T) {
+	store := publishedStore(t, 5)
+	// Not published yet: left to the relay
+	pending, _ := NewMessage(uuid.New(), "order.events", "order.created", map[string]string{})
+	store.Add(context.Background(), pending)
+
+	var sent []Message
+	publisher := publishFunc(func(_ context.Context, msg Message) error {
+		sent = append(sent, msg)
+		return nil
+	})
+	result, err := Replay(context.Background(), store, publisher, ReplayConfig{
+		ID:        "rebuild-orders",
+		Filter:    Filter{Topics: []string{"order.events"}},
+		BatchSize: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Matched != 5 || result.Published != 5 || result.LastID != "5" || len(sent) != 5 {
+		t.Fatalf("result %+v, sent %d", result, len(sent))
+	}
+	for i, msg := range sent {
+		if msg.ID != strconv.Itoa(i+1) || msg.Replay != "rebuild-orders" {
+			t.Errorf("sent[%d] = %s, replay %q", i, msg.ID, msg.Replay)
+		}
+	}
+
+	result, _ = Replay(context.Background(), store, publisher, ReplayConfig{ID: "dry", DryRun: true, Max: 3})
+	if result.Matched != 3 || result.Published != 0 || result.LastID != "3" {
+		t.Errorf("dry run: %+v", result)
+	}
+	if _, err := Replay(context.Background(), store, publisher, ReplayConfig{}); err == nil {
+		t.Error("replay without an ID: want an error")
+	}
+}
+
+func TestReplayResumesAfterAFailure(t *testing.T) {
+	store := publishedStore(t, 4)
+	var sent []string
+	fail := true
+	publisher := publishFunc(func(_ context.Context, msg Message) error {
+		if msg.ID == "3" && fail {
+			return errors.New("broker unavailable")
+		}
+		sent = append(sent, msg.ID)
+		return nil
+	})
+
+	cfg := ReplayConfig{ID: "r1", Filter: Filter{Types: []string{"order.created"}}}
+	result, err := Replay(context.Background(), store, publisher, cfg)
+	if err == nil || result.LastID != "2" || result.Published != 2 {
+		t.Fatalf("result %+v, err %v", result, err)
+	}
+
+	fail = false
+	cfg.After = result.LastID
+	result, err = Replay(context.Background(), store, publisher, cfg)
+	if err != nil || result.Published != 2 || result.LastID != "4" {
+		t.Fatalf("resumed: result %+v, err %v", result, err)
+	}
+	if len(sent) != 4 || sent[2] != "3" {
+		t.Errorf("sent %v", sent)
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	aggregate := uuid.New()
+	now := time.Now()
+	msg := Message{AggregateID: aggregate, Topic: "order.events", Type: "order.paid", CreatedAt: now}
+	for _, tc := range []struct {
+		filter Filter
+		want   bool
+	}{
+		{Filter{}, true},
+		{Filter{Topics: []string{"user.events", "order.events"}}, true},
+		{Filter{Types: []string{"order.created"}}, false},
+		{Filter{AggregateID: aggregate}, true},
+		{Filter{AggregateID: uuid.New()}, false},
+		{Filter{From: now, To: now.Add(time.Second)}, true},
+		{Filter{To: now}, false},
+		{Filter{From: now.Add(time.Second)}, false},
+	} {
+		if got := tc.filter.Match(msg); got != tc.want {
+			t.Errorf("%+v: Match = %v, want %v", tc.filter, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package queryparams
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PageOptions are the bounds of an endpoint.
+type PageOptions struct {
+	// DefaultLimit is the limit when the client sends none. 0 leaves it to the downstream
+	// service.
+	DefaultLimit int
+	// MaxLimit caps the limit; larger limits are clamped rather than rejected
+	MaxLimit int
+}
+
+// Page is the requested page, as a limit and an offset. It can be translated to
+// page/page_size for the downstreams paginating by page.
+type Page struct {
+	Limit  int
+	Offset int
+}
+
+// Number returns the 1-based page number.
+func (p Page) Number() int {
+	if p.Limit <= 0 {
+		return 1
+	}
+	return p.Offset/p.Limit + 1
+}
+
+// ParsePage reads `limit` (or `page_size`) and `cursor`. When no cursor is sent, `offset`
+// or the 1-based `page` select the page.
+func ParsePage(values url.Values, opts PageOptions) (Page, error) {
+	page := Page{Limit: opts.DefaultLimit}
+
+	limitParam := "limit"
+	if values.Get(limitParam) == "" && values.Get("page_size") != "" {
+		limitParam = "page_size"
+	}
+	limit, err := Int(values, limitParam, opts.DefaultLimit, 1, opts.MaxLimit)
+	if err != nil {
+		return page, err
+	}
+	page.Limit = limit
+
+	if cursor := values.Get("cursor"); cursor != "" {
+		if page.Offset, err = DecodeCursor(cursor); err != nil {
+			return page, err
+		}
+		return page, nil
+	}
+
+	if values.Get("offset") != "" {
+		page.Offset, err = Int(values, "offset", 0, 0, 0)
+		return page, err
+	}
+	number, err := Int(values, "page", 1, 1, 0)
+	if err != nil {
+		return page, err
+	}
+	page.Offset = (number - 1) * page.Limit
+	return page, nil
+}
+
+const cursorPrefix = "o:"
+
+// EncodeCursor returns the opaque cursor of the page starting at offset, for the
+// next_cursor of a response.
+func EncodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+// DecodeCursor returns the offset of a cursor made by EncodeCursor.
+func DecodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), cursorPrefix) {
+		return 0, invalid("cursor", "malformed cursor")
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(raw), cursorPrefix))
+	if err != nil || offset < 0 {
+		return 0, invalid("cursor", "malformed cursor")
+	}
+	return offset, nil
+}
@@ -0,0 +1,112 @@
+// Package queryparams parses the query parameters of list endpoints: the page (limit,
+// offset, page, cursor), the sort order and typed filters. Limits are clamped to the
+// maximum of the endpoint rather than rejected; anything that cannot be parsed is
+// reported as an *Error naming the parameter, so every endpoint answers a bad parameter
+// with the same 400.
+//
+// It reads url.Values, so it works with any router:
+//
+//	page, err := queryparams.ParsePage(c.Request.URL.Query(), queryparams.PageOptions{DefaultLimit: 20, MaxLimit: 100})
+package queryparams
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Error reports a query parameter that could not be parsed.
+type Error struct {
+	// Param is the name of the parameter
+	Param string
+	// Reason tells the client what the parameter accepts, such as "must be a positive integer"
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return "invalid " + e.Param + " parameter: " + e.Reason
+}
+
+// Message is the message shown to the client, such as "Invalid limit parameter".
+func (e *Error) Message() string {
+	return "Invalid " + e.Param + " parameter"
+}
+
+// AsError returns err as an *Error, and false when err is not one.
+func AsError(err error) (*Error, bool) {
+	var e *Error
+	ok := errors.As(err, &e)
+	return e, ok
+}
+
+func invalid(param, reason string) *Error {
+	return &Error{Param: param, Reason: reason}
+}
+
+// Int parses the integer parameter name, def when it is absent. Values below min are
+// rejected; values above max are clamped to max when max is positive.
+func Int(values url.Values, name string, def, min, max int) (int, error) {
+	raw := values.Get(name)
+	if raw == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < min {
+		switch min {
+		case 0:
+			return 0, invalid(name, "must be a non-negative integer")
+		case 1:
+			return 0, invalid(name, "must be a positive integer")
+		default:
+			return 0, invalid(name, "must be an integer of at least "+strconv.Itoa(min))
+		}
+	}
+	if max > 0 && n > max {
+		n = max
+	}
+	return n, nil
+}
+
+// Bool parses the boolean parameter name, nil when it is absent.
+func Bool(values url.Values, name string) (*bool, error) {
+	raw := values.Get(name)
+	if raw == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, invalid(name, "must be true or false")
+	}
+	return &b, nil
+}
+
+// Time parses the timestamp parameter name, an RFC 3339 timestamp or a date, nil when it
+// is absent. A date is midnight UTC.
+func Time(values url.Values, name string) (*time.Time, error) {
+	raw := values.Get(name)
+	if raw == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, invalid(name, "must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+}
+
+// OneOf returns the parameter name when it is one of allowed, "" when it is absent.
+func OneOf(values url.Values, name string, allowed ...string) (string, error) {
+	raw := values.Get(name)
+	if raw == "" {
+		return "", nil
+	}
+	for _, a := range allowed {
+		if raw == a {
+			return raw, nil
+		}
+	}
+	return "", invalid(name, "must be one of "+strings.Join(allowed, ", "))
+}
@@ -0,0 +1,149 @@
+package queryparams
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func query(t *testing.T, raw string) url.Values {
+	t.Helper()
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return values
+}
+
+func TestParsePage(t *testing.T) {
+	opts := PageOptions{DefaultLimit: 20, MaxLimit: 100}
+
+	for _, tc := range []struct {
+		query string
+		want  Page
+	}{
+		{"", Page{Limit: 20}},
+		{"limit=50&offset=10", Page{Limit: 50, Offset: 10}},
+		{"limit=1000", Page{Limit: 100}},
+		{"page_size=10&page=3", Page{Limit: 10, Offset: 20}},
+		{"limit=10&page_size=30", Page{Limit: 10}},
+		{"limit=10&offset=5&page=3", Page{Limit: 10, Offset: 5}},
+		{"limit=10&offset=5&cursor=" + EncodeCursor(40), Page{Limit: 10, Offset: 40}},
+	} {
+		page, err := ParsePage(query(t, tc.query), opts)
+		if err != nil || page != tc.want {
+			t.Errorf("%q: got %+v, %v, want %+v", tc.query, page, err, tc.want)
+		}
+	}
+
+	for raw, param := range map[string]string{
+		"limit=0":       "limit",
+		"limit=abc":     "limit",
+		"page_size=-1":  "page_size",
+		"offset=-1":     "offset",
+		"page=0":        "page",
+		"cursor=%21%21": "cursor",
+		"cursor=bzp4":   "cursor",
+	} {
+		_, err := ParsePage(query(t, raw), opts)
+		qerr, ok := AsError(err)
+		if !ok || qerr.Param != param {
+			t.Errorf("%q: got %v, want an error on %s", raw, err, param)
+		}
+	}
+
+	if page, _ := ParsePage(url.Values{}, PageOptions{MaxLimit: 500}); page.Limit != 0 || page.Number() != 1 {
+		t.Errorf("no default limit: got %+v", page)
+	}
+}
+
+func TestPageNumber(t *testing.T) {
+	if n := (Page{Limit: 10, Offset: 20}).Number(); n != 3 {
+		t.Errorf("Number = %d, want 3", n)
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	opts := SortOptions{
+		Fields:  []string{"created_at", "total_amount"},
+		Default: Sort{Field: "created_at", Desc: true},
+	}
+
+	for _, tc := range []struct {
+		query string
+		want  Sort
+	}{
+		{"", Sort{Field: "created_at", Desc: true}},
+		{"sort=total_amount", Sort{Field: "total_amount"}},
+		{"sort=-total_amount", Sort{Field: "total_amount", Desc: true}},
+		{"sort_by=total_amount&sort_order=desc", Sort{Field: "total_amount", Desc: true}},
+		{"sort_order=asc", Sort{Field: "created_at"}},
+	} {
+		sort, err := ParseSort(query(t, tc.query), opts)
+		if err != nil || sort != tc.want {
+			t.Errorf("%q: got %+v, %v, want %+v", tc.query, sort, err, tc.want)
+		}
+	}
+
+	for _, bad := range []string{"sort=password", "sort_by=id", "sort_order=up"} {
+		if _, err := ParseSort(query(t, bad), opts); err == nil {
+			t.Errorf("%q: want an error", bad)
+		}
+	}
+
+	if got := (Sort{Field: "created_at", Desc: true}).OrderBy(); got != "created_at DESC" {
+		t.Errorf("OrderBy = %q", got)
+	}
+}
+
+func TestFilters(t *testing.T) {
+	values := query(t, "passed=true&since=2026-03-01&until=2026-03-02T10:00:00Z&status=paid&n=7&bad=x")
+
+	if passed, err := Bool(values, "passed"); err != nil || passed == nil || !*passed {
+		t.Errorf("Bool: got %v, %v", passed, err)
+	}
+	if missing, err := Bool(values, "missing"); err != nil || missing != nil {
+		t.Errorf("Bool of a missing parameter: got %v, %v", missing, err)
+	}
+	if _, err := Bool(values, "bad"); err == nil {
+		t.Error("Bool: want an error")
+	}
+
+	if since, err := Time(values, "since"); err != nil || !since.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Time of a date: got %v, %v", since, err)
+	}
+	if until, err := Time(values, "until"); err != nil || until.Hour() != 10 {
+		t.Errorf("Time of a timestamp: got %v, %v", until, err)
+	}
+	if _, err := Time(values, "bad"); err == nil {
+		t.Error("Time: want an error")
+	}
+
+	if status, err := OneOf(values, "status", "pending", "paid"); err != nil || status != "paid" {
+		t.Errorf("OneOf: got %q, %v", status, err)
+	}
+	if _, err := OneOf(values, "status", "pending"); err == nil {
+		t.Error("OneOf: want an error")
+	}
+
+	if n, err := Int(values, "n", 1, 1, 5); err != nil || n != 5 {
+		t.Errorf("Int should clamp to max: got %d, %v", n, err)
+	}
+	if n, err := Int(values, "missing", 3, 1, 5); err != nil || n != 3 {
+		t.Errorf("Int of a missing parameter: got %d, %v", n, err)
+	}
+}
+
+func TestErrorMessages(t *testing.T) {
+	_, err := ParsePage(query(t, "limit=-5"), PageOptions{DefaultLimit: 20, MaxLimit: 100})
+	qerr, ok := AsError(err)
+	if !ok {
+		t.Fatalf("got %v", err)
+	}
+	if qerr.Message() != "Invalid limit parameter" || qerr.Reason != "must be a positive integer" {
+		t.Errorf("got %q, %q", qerr.Message(), qerr.Reason)
+	}
+	if err.Error() != "invalid limit parameter: must be a positive integer" {
+		t.Errorf("Error = %q", err.Error())
+	}
+}
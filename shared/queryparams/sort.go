@@ -0,0 +1,74 @@
+package queryparams
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Sort is a sort order on a single field.
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// Direction returns "asc" or "desc".
+func (s Sort) Direction() string {
+	if s.Desc {
+		return "desc"
+	}
+	return "asc"
+}
+
+// String returns the sort in the `sort` parameter syntax, such as "-created_at".
+func (s Sort) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// OrderBy returns the sort as an SQL ORDER BY clause, such as "created_at DESC". It is
+// only safe because ParseSort accepts the allowed fields only.
+func (s Sort) OrderBy() string {
+	return s.Field + " " + strings.ToUpper(s.Direction())
+}
+
+// SortOptions are the sorts an endpoint supports.
+type SortOptions struct {
+	// Fields are the fields clients may sort by, as they appear in the parameter
+	Fields []string
+	// Default is the sort when the client sends none
+	Default Sort
+}
+
+// ParseSort reads `sort`, a field prefixed with "-" for descending order, or the
+// `sort_by` and `sort_order` (asc or desc) pair. The field must be one of opts.Fields;
+// `sort_order` alone changes the direction of the default sort.
+func ParseSort(values url.Values, opts SortOptions) (Sort, error) {
+	sort := opts.Default
+
+	param := "sort"
+	field := values.Get(param)
+	if field != "" {
+		sort.Desc = strings.HasPrefix(field, "-")
+		field = strings.TrimPrefix(field, "-")
+	} else if field = values.Get("sort_by"); field != "" {
+		param = "sort_by"
+	}
+	if field != "" {
+		if !slices.Contains(opts.Fields, field) {
+			return sort, invalid(param, "must be one of "+strings.Join(opts.Fields, ", "))
+		}
+		sort.Field = field
+	}
+
+	order, err := OneOf(values, "sort_order", "asc", "desc")
+	if err != nil {
+		return sort, err
+	}
+	if order != "" {
+		sort.Desc = order == "desc"
+	}
+	return sort, nil
+}
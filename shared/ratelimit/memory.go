@@ -0,0 +1,117 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps the counters in memory, for tests and single-instance local runs
+// without Redis. It follows the same rules as redisstore.
+type MemoryStore struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	windows map[string][]time.Time
+	buckets map[string]bucket
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore returns an empty store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		now:     time.Now,
+		windows: make(map[string][]time.Time),
+		buckets: make(map[string]bucket),
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, policy Policy) (Result, error) {
+	if err := policy.Validate(); err != nil {
+		return Result{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if policy.Algorithm == TokenBucket {
+		return s.takeToken(key, policy), nil
+	}
+	return s.takeSlot(key, policy), nil
+}
+
+func (s *MemoryStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.windows, key)
+	delete(s.buckets, key)
+	return nil
+}
+
+// takeSlot admits a request when fewer than Limit requests were admitted in the last
+// Window. Rejected requests are not counted, so a client hammering a limit gets back in
+// as soon as its oldest request leaves the window.
+func (s *MemoryStore) takeSlot(key string, policy Policy) Result {
+	now := s.now()
+	cutoff := now.Add(-policy.Window)
+
+	requests := s.windows[key]
+	kept := requests[:0]
+	for _, at := range requests {
+		if at.After(cutoff) {
+			kept = append(kept, at)
+		}
+	}
+
+	result := Result{Limit: policy.Limit}
+	if len(kept) < policy.Limit {
+		kept = append(kept, now)
+		result.Allowed = true
+	}
+	s.windows[key] = kept
+
+	result.Remaining = policy.Limit - len(kept)
+	result.ResetAt = kept[0].Add(policy.Window)
+	if !result.Allowed {
+		result.RetryAfter = result.ResetAt.Sub(now)
+	}
+	return result
+}
+
+// takeToken refills the bucket for the time elapsed since the last request, then spends
+// one token if there is one.
+func (s *MemoryStore) takeToken(key string, policy Policy) Result {
+	now := s.now()
+	capacity := float64(policy.Capacity())
+	perSecond := float64(policy.Limit) / policy.Window.Seconds()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = bucket{tokens: capacity, updatedAt: now}
+	}
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updatedAt).Seconds()*perSecond)
+	b.updatedAt = now
+
+	result := Result{Limit: policy.Capacity()}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / perSecond)
+	}
+	s.buckets[key] = b
+
+	result.Remaining = int(b.tokens)
+	result.ResetAt = now.Add(seconds((capacity - b.tokens) / perSecond))
+	return result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
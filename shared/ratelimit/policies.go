@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvVar is the variable every service reads its policy overrides from, so a limit is
+// tuned the same way wherever it is enforced.
+const EnvVar = "RATE_LIMITS"
+
+// Policies are policies by name.
+type Policies map[string]Policy
+
+// ParsePolicies parses a comma separated list of policies:
+//
+//	auth.login=10/1m,payments.intent=5/1m:token_bucket:10
+//
+// Each entry is name=limit/window, optionally followed by the algorithm (sliding_window
+// when omitted) and the burst of a token bucket.
+func ParsePolicies(s string) (Policies, error) {
+	policies := Policies{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		policy, err := parsePolicy(entry)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := policies[policy.Name]; dup {
+			return nil, fmt.Errorf("ratelimit: policy %s is defined twice", policy.Name)
+		}
+		policies[policy.Name] = policy
+	}
+	return policies, nil
+}
+
+func parsePolicy(entry string) (Policy, error) {
+	name, spec, ok := strings.Cut(entry, "=")
+	if !ok {
+		return Policy{}, fmt.Errorf("ratelimit: %q: want name=limit/window", entry)
+	}
+	policy := Policy{Name: strings.TrimSpace(name), Algorithm: SlidingWindow}
+
+	parts := strings.Split(spec, ":")
+	limit, window, ok := strings.Cut(parts[0], "/")
+	if !ok {
+		return Policy{}, fmt.Errorf("ratelimit: %q: want name=limit/window", entry)
+	}
+	var err error
+	if policy.Limit, err = strconv.Atoi(strings.TrimSpace(limit)); err != nil {
+		return Policy{}, fmt.Errorf("ratelimit: %q: invalid limit: %w", entry, err)
+	}
+	if policy.Window, err = time.ParseDuration(strings.TrimSpace(window)); err != nil {
+		return Policy{}, fmt.Errorf("ratelimit: %q: invalid window: %w", entry, err)
+	}
+	if len(parts) > 1 {
+		policy.Algorithm = Algorithm(strings.TrimSpace(parts[1]))
+	}
+	if len(parts) > 2 {
+		if policy.Burst, err = strconv.Atoi(strings.TrimSpace(parts[2])); err != nil {
+			return Policy{}, fmt.Errorf("ratelimit: %q: invalid burst: %w", entry, err)
+		}
+	}
+	if len(parts) > 3 {
+		return Policy{}, fmt.Errorf("ratelimit: %q: too many fields", entry)
+	}
+
+	return policy, policy.Validate()
+}
+
+// UnmarshalText parses the policies with ParsePolicies, so a configuration struct can
+// load them with `env:"RATE_LIMITS"`.
+func (p *Policies) UnmarshalText(text []byte) error {
+	parsed, err := ParsePolicies(string(text))
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
+
+// String formats the policies the way ParsePolicies reads them, sorted by name.
+func (p Policies) String() string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	entries := make([]string, 0, len(names))
+	for _, name := range names {
+		policy := p[name]
+		entry := fmt.Sprintf("%s=%d/%s", name, policy.Limit, policy.Window)
+		if policy.Algorithm != SlidingWindow {
+			entry += ":" + string(policy.Algorithm)
+			if policy.Burst > 0 {
+				entry += ":" + strconv.Itoa(policy.Burst)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return strings.Join(entries, ",")
+}
@@ -0,0 +1,176 @@
+// Package ratelimit limits requests per key with policies shared by the Go services, so
+// a limit behaves the same in the BFF, user-services and order-services and is tuned in
+// one place. The algorithms run in a Store; redisstore runs them as atomic Lua scripts so
+// every replica of a service counts against the same budget.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Algorithm selects how a policy counts requests.
+type Algorithm string
+
+const (
+	// SlidingWindow allows Limit requests in any Window-long period. It suits login and
+	// signup endpoints, where a burst at the edge of a fixed window must not double the
+	// budget.
+	SlidingWindow Algorithm = "sliding_window"
+	// TokenBucket refills Limit tokens per Window up to Burst and spends one per request.
+	// It suits API traffic that is steady with occasional bursts.
+	TokenBucket Algorithm = "token_bucket"
+)
+
+// Policy is a named limit. The name identifies the policy in overrides and metrics; the
+// key passed to Allow identifies who is limited, such as a client IP or a user ID.
+type Policy struct {
+	Name      string
+	Algorithm Algorithm
+	Limit     int
+	Window    time.Duration
+	// Burst is the capacity of a token bucket, Limit when 0. Sliding windows ignore it.
+	Burst int
+}
+
+// Validate reports whether the policy can be enforced.
+func (p Policy) Validate() error {
+	if p.Name == "" {
+		return errors.New("ratelimit: policy name is required")
+	}
+	if p.Algorithm != SlidingWindow && p.Algorithm != TokenBucket {
+		return fmt.Errorf("ratelimit: policy %s: unknown algorithm %q", p.Name, p.Algorithm)
+	}
+	if p.Limit <= 0 || p.Window <= 0 {
+		return fmt.Errorf("ratelimit: policy %s: limit and window must be positive", p.Name)
+	}
+	if p.Burst < 0 {
+		return fmt.Errorf("ratelimit: policy %s: burst must not be negative", p.Name)
+	}
+	return nil
+}
+
+// Capacity is the number of requests the policy allows at once.
+func (p Policy) Capacity() int {
+	if p.Algorithm == TokenBucket && p.Burst > 0 {
+		return p.Burst
+	}
+	return p.Limit
+}
+
+// Result is the outcome of a request against a policy.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long a rejected caller should wait, 0 when allowed
+	RetryAfter time.Duration
+	// ResetAt is when the full budget is available again
+	ResetAt time.Time
+}
+
+// SetHeaders writes the X-RateLimit-* headers, and Retry-After when the request was
+// rejected, so clients see the same headers from every service.
+func (r Result) SetHeaders(h http.Header) {
+	h.Set("X-RateLimit-Limit", strconv.Itoa(r.Limit))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(r.Remaining))
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(r.ResetAt.Unix(), 10))
+	if !r.Allowed {
+		// Round up so a client retrying after the header is not rejected again
+		h.Set("Retry-After", strconv.FormatInt(int64((r.RetryAfter+time.Second-1)/time.Second), 10))
+	}
+}
+
+// Store counts a request for key against a policy. Implementations must be atomic, so
+// concurrent requests cannot both spend the last unit of a budget.
+type Store interface {
+	Take(ctx context.Context, key string, policy Policy) (Result, error)
+	// Reset gives key its full budget back
+	Reset(ctx context.Context, key string) error
+}
+
+// DefaultPrefix starts the store keys of a Limiter.
+const DefaultPrefix = "ratelimit"
+
+// Config configures a Limiter.
+type Config struct {
+	// Prefix starts every store key, DefaultPrefix when empty
+	Prefix string
+	// Overrides replace the policies of the same name, see ParsePolicies
+	Overrides Policies
+	// FailOpen allows requests when the store is unreachable. Errors are returned either
+	// way so callers can log them.
+	FailOpen bool
+}
+
+var requestsTotal = promauto.With(metrics.Default).NewCounterVec(prometheus.CounterOpts{
+	Name: "rate_limit_requests_total",
+	Help: "Requests checked against a rate limit policy, by outcome (allowed, limited or error).",
+}, []string{"policy", "outcome"})
+
+// Limiter enforces policies on a Store and counts the outcomes per policy.
+type Limiter struct {
+	store Store
+	cfg   Config
+}
+
+// New returns a limiter on store.
+func New(store Store, cfg Config) *Limiter {
+	if cfg.Prefix == "" {
+		cfg.Prefix = DefaultPrefix
+	}
+	return &Limiter{store: store, cfg: cfg}
+}
+
+// Policy returns policy with the override of the same name applied, if any.
+func (l *Limiter) Policy(policy Policy) Policy {
+	if override, ok := l.cfg.Overrides[policy.Name]; ok {
+		return override
+	}
+	return policy
+}
+
+// Allow counts a request for key against policy. A policy that cannot be enforced, or a
+// store error, returns an error along with a result allowing the request when the
+// limiter fails open.
+func (l *Limiter) Allow(ctx context.Context, policy Policy, key string) (Result, error) {
+	policy = l.Policy(policy)
+	if err := policy.Validate(); err != nil {
+		requestsTotal.WithLabelValues(policy.Name, "error").Inc()
+		return l.failed(policy), err
+	}
+
+	result, err := l.store.Take(ctx, l.key(policy, key), policy)
+	if err != nil {
+		requestsTotal.WithLabelValues(policy.Name, "error").Inc()
+		return l.failed(policy), fmt.Errorf("ratelimit: policy %s: %w", policy.Name, err)
+	}
+
+	if result.Allowed {
+		requestsTotal.WithLabelValues(policy.Name, "allowed").Inc()
+	} else {
+		requestsTotal.WithLabelValues(policy.Name, "limited").Inc()
+	}
+	return result, nil
+}
+
+// Reset gives key its full budget of policy back, such as after a successful login.
+func (l *Limiter) Reset(ctx context.Context, policy Policy, key string) error {
+	return l.store.Reset(ctx, l.key(policy, key))
+}
+
+func (l *Limiter) key(policy Policy, key string) string {
+	return l.cfg.Prefix + ":" + policy.Name + ":" + key
+}
+
+func (l *Limiter) failed(policy Policy) Result {
+	return Result{Allowed: l.cfg.FailOpen, Limit: policy.Capacity(), Remaining: policy.Capacity(), ResetAt: time.Now()}
+}
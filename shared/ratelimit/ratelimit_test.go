@@ -0,0 +1,195 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type clock struct{ now time.Time }
+
+func (c *clock) advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestStore() (*MemoryStore, *clock) {
+	c := &clock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	store.now = func() time.Time { return c.now }
+	return store, c
+}
+
+func take(t *testing.T, l *Limiter, policy Policy, key string) Result {
+	t.Helper()
+	result, err := l.Allow(context.Background(), policy, key)
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	return result
+}
+
+func TestSlidingWindow(t *testing.T) {
+	store, c := newTestStore()
+	l := New(store, Config{})
+	policy := Policy{Name: "auth.login", Algorithm: SlidingWindow, Limit: 3, Window: time.Minute}
+
+	for i := range 3 {
+		if r := take(t, l, policy, "1.2.3.4"); !r.Allowed || r.Remaining != 2-i {
+			t.Fatalf("request %d: got %+v", i, r)
+		}
+		c.advance(10 * time.Second)
+	}
+
+	r := take(t, l, policy, "1.2.3.4")
+	if r.Allowed || r.Remaining != 0 {
+		t.Fatalf("fourth request: got %+v", r)
+	}
+	// The first request leaves the window 60s after it was made, 30s from now
+	if r.RetryAfter != 30*time.Second {
+		t.Errorf("RetryAfter = %v, want 30s", r.RetryAfter)
+	}
+	if r := take(t, l, policy, "5.6.7.8"); !r.Allowed {
+		t.Errorf("other key limited: %+v", r)
+	}
+
+	c.advance(30 * time.Second)
+	if r := take(t, l, policy, "1.2.3.4"); !r.Allowed || r.Remaining != 0 {
+		t.Errorf("after the oldest request left the window: got %+v", r)
+	}
+
+	if err := l.Reset(context.Background(), policy, "1.2.3.4"); err != nil {
+		t.Fatal(err)
+	}
+	if r := take(t, l, policy, "1.2.3.4"); !r.Allowed || r.Remaining != 2 {
+		t.Errorf("after Reset: got %+v", r)
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	store, c := newTestStore()
+	l := New(store, Config{})
+	// 6 per minute is one token every 10s, with up to 2 spent at once
+	policy := Policy{Name: "payments.intent", Algorithm: TokenBucket, Limit: 6, Window: time.Minute, Burst: 2}
+
+	for i := range 2 {
+		if r := take(t, l, policy, "user-1"); !r.Allowed || r.Limit != 2 {
+			t.Fatalf("burst request %d: got %+v", i, r)
+		}
+	}
+	r := take(t, l, policy, "user-1")
+	if r.Allowed || r.RetryAfter != 10*time.Second {
+		t.Fatalf("empty bucket: got %+v", r)
+	}
+
+	c.advance(10 * time.Second)
+	if r := take(t, l, policy, "user-1"); !r.Allowed || r.Remaining != 0 {
+		t.Fatalf("after one refill: got %+v", r)
+	}
+
+	c.advance(time.Hour)
+	if r := take(t, l, policy, "user-1"); !r.Allowed || r.Remaining != 1 {
+		t.Errorf("bucket should refill up to its burst only: got %+v", r)
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	overrides, err := ParsePolicies("auth.login=1/1h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, _ := newTestStore()
+	l := New(store, Config{Overrides: overrides})
+	policy := Policy{Name: "auth.login", Algorithm: SlidingWindow, Limit: 10, Window: time.Minute}
+
+	take(t, l, policy, "1.2.3.4")
+	if r := take(t, l, policy, "1.2.3.4"); r.Allowed || r.Limit != 1 {
+		t.Errorf("override not applied: got %+v", r)
+	}
+	if other := (Policy{Name: "auth.refresh", Algorithm: SlidingWindow, Limit: 5, Window: time.Minute}); l.Policy(other) != other {
+		t.Errorf("policy without override changed: %+v", l.Policy(other))
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, Policy) (Result, error) {
+	return Result{}, errors.New("connection refused")
+}
+
+func (failingStore) Reset(context.Context, string) error {
+	return errors.New("connection refused")
+}
+
+func TestStoreErrors(t *testing.T) {
+	policy := Policy{Name: "auth.login", Algorithm: SlidingWindow, Limit: 10, Window: time.Minute}
+
+	for _, failOpen := range []bool{true, false} {
+		l := New(failingStore{}, Config{FailOpen: failOpen})
+		r, err := l.Allow(context.Background(), policy, "1.2.3.4")
+		if err == nil {
+			t.Errorf("FailOpen=%v: want the store error", failOpen)
+		}
+		if r.Allowed != failOpen {
+			t.Errorf("FailOpen=%v: Allowed = %v", failOpen, r.Allowed)
+		}
+	}
+
+	l := New(NewMemoryStore(), Config{})
+	if _, err := l.Allow(context.Background(), Policy{Name: "broken", Algorithm: SlidingWindow}, "k"); err == nil {
+		t.Error("want an error for a policy without limit")
+	}
+}
+
+func TestParsePolicies(t *testing.T) {
+	policies, err := ParsePolicies(" auth.login=10/1m, payments.intent=5/1m:token_bucket:10,api_key.default=100/1m:token_bucket")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Policies{
+		"auth.login":      {Name: "auth.login", Algorithm: SlidingWindow, Limit: 10, Window: time.Minute},
+		"payments.intent": {Name: "payments.intent", Algorithm: TokenBucket, Limit: 5, Window: time.Minute, Burst: 10},
+		"api_key.default": {Name: "api_key.default", Algorithm: TokenBucket, Limit: 100, Window: time.Minute},
+	}
+	if len(policies) != len(want) {
+		t.Fatalf("got %v", policies)
+	}
+	for name, policy := range want {
+		if policies[name] != policy {
+			t.Errorf("%s: got %+v, want %+v", name, policies[name], policy)
+		}
+	}
+
+	roundTrip, err := ParsePolicies(policies.String())
+	if err != nil || roundTrip.String() != policies.String() {
+		t.Errorf("String does not round trip: %q, %v", policies.String(), err)
+	}
+
+	for _, bad := range []string{"auth.login", "auth.login=10", "auth.login=x/1m", "auth.login=10/x", "auth.login=10/1m:leaky", "a=1/1m,a=2/1m", "a=1/1m:token_bucket:2:3", "a=0/1m"} {
+		if _, err := ParsePolicies(bad); err == nil {
+			t.Errorf("%q: want an error", bad)
+		}
+	}
+}
+
+func TestSetHeaders(t *testing.T) {
+	h := http.Header{}
+	reset := time.Unix(1_700_000_000, 0)
+	Result{Allowed: false, Limit: 10, RetryAfter: 1500 * time.Millisecond, ResetAt: reset}.SetHeaders(h)
+
+	for name, want := range map[string]string{
+		"X-RateLimit-Limit":     "10",
+		"X-RateLimit-Remaining": "0",
+		"X-RateLimit-Reset":     "1700000000",
+		"Retry-After":           "2",
+	} {
+		if got := h.Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	h = http.Header{}
+	Result{Allowed: true, Limit: 10, Remaining: 9, ResetAt: reset}.SetHeaders(h)
+	if h.Get("Retry-After") != "" {
+		t.Error("Retry-After set on an allowed request")
+	}
+}
@@ -0,0 +1,146 @@
+// Package redisstore runs the rate limit algorithms in Redis as Lua scripts, so the
+// read, the decision and the write of a request happen atomically and every replica of
+// a service counts against the same budget. Time is read from the Redis server, so the
+// clocks of the services do not skew the windows.
+//
+// A sliding window is a sorted set of the admitted requests scored by time; a token
+// bucket is a hash with the tokens left and the time they were counted. Both expire once
+// they would be back to a full budget. It is a separate module so services without Redis
+// do not pull in the Redis client.
+package redisstore
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/ratelimit"
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindow admits a request when fewer than limit requests were admitted in the
+// last window. KEYS[1] is the sorted set; ARGV are the window in microseconds, the limit
+// and a unique member for the request. Returns allowed, remaining, retry after and reset
+// after, in microseconds.
+var slidingWindow = redis.NewScript(`
+local key = KEYS[1]
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[3])
+	count = count + 1
+	allowed = 1
+end
+
+local reset = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+redis.call('PEXPIRE', key, math.ceil(reset / 1000))
+
+local retry = 0
+if allowed == 0 then
+	retry = reset
+end
+return {allowed, limit - count, retry, reset}
+`)
+
+// tokenBucket refills the bucket for the time elapsed since the last request and spends
+// one token. KEYS[1] is the hash; ARGV are the capacity and the refill rate in tokens
+// per microsecond. Returns allowed, remaining, retry after and reset after, in
+// microseconds.
+var tokenBucket = redis.NewScript(`
+local key = KEYS[1]
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local state = redis.call('HMGET', key, 'tokens', 'ts')
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate)
+end
+
+local reset = math.ceil((capacity - tokens) / rate)
+redis.call('HSET', key, 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', key, math.max(1, math.ceil(reset / 1000)))
+return {allowed, math.floor(tokens), retry, reset}
+`)
+
+// Store implements ratelimit.Store.
+type Store struct {
+	client redis.UniversalClient
+}
+
+var _ ratelimit.Store = (*Store)(nil)
+
+// New returns a Store keeping the counters in client.
+func New(client redis.UniversalClient) *Store {
+	return &Store{client: client}
+}
+
+func (s *Store) Take(ctx context.Context, key string, policy ratelimit.Policy) (ratelimit.Result, error) {
+	if err := policy.Validate(); err != nil {
+		return ratelimit.Result{}, err
+	}
+
+	window := policy.Window.Microseconds()
+	var (
+		values []int64
+		err    error
+	)
+	switch policy.Algorithm {
+	case ratelimit.TokenBucket:
+		rate := float64(policy.Limit) / float64(window)
+		values, err = tokenBucket.Run(ctx, s.client, []string{key}, policy.Capacity(), rate).Int64Slice()
+	default:
+		values, err = slidingWindow.Run(ctx, s.client, []string{key}, window, policy.Limit, member()).Int64Slice()
+	}
+	if err != nil {
+		return ratelimit.Result{}, err
+	}
+	if len(values) != 4 {
+		return ratelimit.Result{}, fmt.Errorf("unexpected rate limit script result %v", values)
+	}
+
+	return ratelimit.Result{
+		Allowed:    values[0] == 1,
+		Limit:      policy.Capacity(),
+		Remaining:  int(max(0, values[1])),
+		RetryAfter: time.Duration(values[2]) * time.Microsecond,
+		ResetAt:    time.Now().Add(time.Duration(values[3]) * time.Microsecond),
+	}, nil
+}
+
+func (s *Store) Reset(ctx context.Context, key string) error {
+	return s.client.Del(ctx, key).Err()
+}
+
+// member identifies a request in a sliding window, so two requests admitted in the same
+// microsecond are both counted.
+func member() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,331 @@
+// Package rediscache caches JSON values in Redis, with the same behaviour and metrics in
+// every service:
+//
+//   - typed helpers: Get, Set and Replace (de)serialize the value as JSON, so the keys
+//     stay readable by other services and redis-cli
+//   - TTL jitter: each TTL is randomized by up to Options.Jitter, so keys written
+//     together, such as after a deploy, do not expire together
+//   - GetOrLoad runs the loader once per key and process however many requests miss at
+//     the same time, and refreshes a hot key in the background shortly before it expires
+//     (probabilistic early expiration, XFetch), so a popular key never expires under load
+//   - negative caching: a loader returning ErrNotFound is remembered for
+//     Options.NegativeTTL, so lookups of missing entries do not reach the loader each time
+//   - cache_lookups_total, cache_loads_total and cache_load_duration_seconds, labelled
+//     with the name of the cache
+package rediscache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrNotFound is returned by a loader when the entry does not exist, and by GetOrLoad
+// when the entry is missing, including from the negative cache.
+var ErrNotFound = errors.New("rediscache: not found")
+
+// missing is the value of a negative cache entry. It is not valid JSON, so it cannot be
+// mistaken for a value.
+const missing = ""
+
+var (
+	lookupsTotal = promauto.With(metrics.Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_lookups_total",
+		Help: "Cache lookups by outcome (hit, miss, negative_hit or error).",
+	}, []string{"cache", "outcome"})
+	loadsTotal = promauto.With(metrics.Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_loads_total",
+		Help: "Values loaded into a cache, by trigger (miss or early_refresh) and outcome (ok, not_found or error).",
+	}, []string{"cache", "trigger", "outcome"})
+	loadDuration = promauto.With(metrics.Default).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "cache_load_duration_seconds",
+		Help:    "Time taken by the loaders of a cache.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"cache"})
+)
+
+// Options configures a Cache.
+type Options struct {
+	// Name labels the metrics of the cache, such as "session"
+	Name string
+	// Jitter randomizes each TTL by up to this fraction of it, 0.1 for ±10%. 0 keeps TTLs
+	// exact, for entries that must expire at a given time such as sessions.
+	Jitter float64
+	// NegativeTTL is how long GetOrLoad remembers a loader returning ErrNotFound, 0 to
+	// not remember it
+	NegativeTTL time.Duration
+	// Beta weighs the early refresh of GetOrLoad: a key is refreshed when the time left
+	// is within Beta times the loading time, scaled by a random factor. 1 suits most
+	// caches, larger values refresh earlier and 0 disables early refresh.
+	Beta float64
+}
+
+// Cache is a namespace of cached values sharing options and metrics.
+type Cache struct {
+	client redis.UniversalClient
+	opts   Options
+	random func() float64
+
+	// loadNanos is a moving average of the loading time, for early refresh
+	loadNanos atomic.Int64
+
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// flight is a load in progress, shared by the callers missing the same key.
+type flight struct {
+	done chan struct{}
+	val  []byte
+	err  error
+}
+
+// New returns a cache storing its values in client.
+func New(client redis.UniversalClient, opts Options) *Cache {
+	if opts.Name == "" {
+		opts.Name = "default"
+	}
+	return &Cache{
+		client:  client,
+		opts:    opts,
+		random:  rand.Float64,
+		flights: make(map[string]*flight),
+	}
+}
+
+// Get returns the value of key, and false when it is not cached.
+func Get[T any](ctx context.Context, c *Cache, key string) (T, bool, error) {
+	var value T
+	raw, err := c.client.Get(ctx, key).Bytes()
+	switch {
+	case errors.Is(err, redis.Nil):
+		c.lookup("miss")
+		return value, false, nil
+	case err != nil:
+		c.lookup("error")
+		return value, false, err
+	case string(raw) == missing:
+		c.lookup("negative_hit")
+		return value, false, nil
+	}
+
+	if err := json.Unmarshal(raw, &value); err != nil {
+		c.lookup("error")
+		return value, false, fmt.Errorf("rediscache: decode %s: %w", key, err)
+	}
+	c.lookup("hit")
+	return value, true, nil
+}
+
+// Set caches value under key for ttl, with jitter.
+func Set[T any](ctx context.Context, c *Cache, key string, value T, ttl time.Duration) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("rediscache: encode %s: %w", key, err)
+	}
+	return c.client.Set(ctx, key, raw, c.ttl(ttl)).Err()
+}
+
+// Replace is Set for a key that is already cached. It returns false without writing
+// when key is not cached, so an entry deleted concurrently is not resurrected.
+func Replace[T any](ctx context.Context, c *Cache, key string, value T, ttl time.Duration) (bool, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return false, fmt.Errorf("rediscache: encode %s: %w", key, err)
+	}
+	ok, err := c.client.SetXX(ctx, key, raw, c.ttl(ttl)).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	return ok, err
+}
+
+// Exists reports whether key is cached, without reading its value.
+func (c *Cache) Exists(ctx context.Context, key string) (bool, error) {
+	n, err := c.client.Exists(ctx, key).Result()
+	switch {
+	case err != nil:
+		c.lookup("error")
+		return false, err
+	case n > 0:
+		c.lookup("hit")
+	default:
+		c.lookup("miss")
+	}
+	return n > 0, nil
+}
+
+// Delete removes keys from the cache.
+func (c *Cache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.client.Del(ctx, keys...).Err()
+}
+
+// GetOrLoad returns the value of key, loading and caching it for ttl on a miss. A hit
+// close to its expiry is returned as is and refreshed in the background. When load
+// returns ErrNotFound, GetOrLoad returns ErrNotFound and remembers it for
+// Options.NegativeTTL. A cache that cannot be read or written is bypassed: the value is
+// loaded for every request until Redis is back.
+func GetOrLoad[T any](ctx context.Context, c *Cache, key string, ttl time.Duration, load func(context.Context) (T, error)) (T, error) {
+	var value T
+	loader := func(ctx context.Context) ([]byte, error) {
+		v, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(v)
+	}
+
+	raw, err := c.getOrLoad(ctx, key, ttl, loader)
+	if err != nil {
+		return value, err
+	}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return value, fmt.Errorf("rediscache: decode %s: %w", key, err)
+	}
+	return value, nil
+}
+
+func (c *Cache) getOrLoad(ctx context.Context, key string, ttl time.Duration, load func(context.Context) ([]byte, error)) ([]byte, error) {
+	// The errors are those of the commands, read below
+	var get *redis.StringCmd
+	var pttl *redis.DurationCmd
+	_, _ = c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pttl = pipe.PTTL(ctx, key)
+		return nil
+	})
+
+	raw, err := get.Bytes()
+	switch {
+	case errors.Is(err, redis.Nil):
+		c.lookup("miss")
+	case err != nil:
+		c.lookup("error")
+	case string(raw) == missing:
+		c.lookup("negative_hit")
+		return nil, ErrNotFound
+	default:
+		c.lookup("hit")
+		if c.expiresSoon(pttl.Val()) {
+			c.refresh(context.WithoutCancel(ctx), key, ttl, load)
+		}
+		return raw, nil
+	}
+
+	return c.load(ctx, key, ttl, load, "miss")
+}
+
+// load runs load for key, or waits for the load of another caller in progress, and
+// caches the result.
+func (c *Cache) load(ctx context.Context, key string, ttl time.Duration, load func(context.Context) ([]byte, error), trigger string) ([]byte, error) {
+	c.mu.Lock()
+	if f, ok := c.flights[key]; ok {
+		c.mu.Unlock()
+		select {
+		case <-f.done:
+			return f.val, f.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	f := &flight{done: make(chan struct{})}
+	c.flights[key] = f
+	c.mu.Unlock()
+
+	f.val, f.err = c.run(ctx, key, ttl, load, trigger)
+
+	c.mu.Lock()
+	delete(c.flights, key)
+	c.mu.Unlock()
+	close(f.done)
+	return f.val, f.err
+}
+
+// refresh reloads key in the background unless a load is already in progress.
+func (c *Cache) refresh(ctx context.Context, key string, ttl time.Duration, load func(context.Context) ([]byte, error)) {
+	c.mu.Lock()
+	_, loading := c.flights[key]
+	c.mu.Unlock()
+	if !loading {
+		go func() { _, _ = c.load(ctx, key, ttl, load, "early_refresh") }()
+	}
+}
+
+func (c *Cache) run(ctx context.Context, key string, ttl time.Duration, load func(context.Context) ([]byte, error), trigger string) ([]byte, error) {
+	start := time.Now()
+	raw, err := load(ctx)
+	elapsed := time.Since(start)
+	loadDuration.WithLabelValues(c.opts.Name).Observe(elapsed.Seconds())
+	c.observeLoad(elapsed)
+
+	switch {
+	case errors.Is(err, ErrNotFound):
+		loadsTotal.WithLabelValues(c.opts.Name, trigger, "not_found").Inc()
+		if c.opts.NegativeTTL > 0 {
+			// Best effort: the loader answered, so a cache error is not the caller's
+			_ = c.client.Set(ctx, key, missing, c.ttl(c.opts.NegativeTTL)).Err()
+		}
+		return nil, ErrNotFound
+	case err != nil:
+		loadsTotal.WithLabelValues(c.opts.Name, trigger, "error").Inc()
+		return nil, err
+	}
+
+	loadsTotal.WithLabelValues(c.opts.Name, trigger, "ok").Inc()
+	_ = c.client.Set(ctx, key, raw, c.ttl(ttl)).Err()
+	return raw, nil
+}
+
+// expiresSoon implements XFetch: a key is refreshed early with a probability growing
+// as its expiry gets within the time a load takes, so one request refreshes a hot key
+// shortly before it expires instead of all of them after.
+func (c *Cache) expiresSoon(remaining time.Duration) bool {
+	delta := time.Duration(c.loadNanos.Load())
+	if c.opts.Beta <= 0 || delta <= 0 || remaining <= 0 {
+		return false
+	}
+	gap := float64(delta) * c.opts.Beta * -math.Log(1-c.random())
+	return gap >= float64(remaining)
+}
+
+// observeLoad folds elapsed into the moving average of the loading time.
+func (c *Cache) observeLoad(elapsed time.Duration) {
+	for {
+		old := c.loadNanos.Load()
+		next := int64(elapsed)
+		if old > 0 {
+			next = old + (int64(elapsed)-old)/8
+		}
+		if c.loadNanos.CompareAndSwap(old, next) {
+			return
+		}
+	}
+}
+
+// ttl applies the jitter of the cache to ttl.
+func (c *Cache) ttl(ttl time.Duration) time.Duration {
+	if c.opts.Jitter <= 0 || ttl <= 0 {
+		return ttl
+	}
+	spread := float64(ttl) * c.opts.Jitter
+	jittered := time.Duration(float64(ttl) + spread*(2*c.random()-1))
+	return max(jittered, time.Millisecond)
+}
+
+func (c *Cache) lookup(outcome string) {
+	lookupsTotal.WithLabelValues(c.opts.Name, outcome).Inc()
+}
@@ -0,0 +1,335 @@
+package rediscache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// fakeRedis answers the commands used by the cache from memory, as a client hook, so
+// the tests need no server.
+type fakeRedis struct {
+	mu     sync.Mutex
+	values map[string]string
+	ttls   map[string]time.Duration
+	down   bool
+}
+
+func newFake(t *testing.T) (*fakeRedis, redis.UniversalClient) {
+	f := &fakeRedis{values: map[string]string{}, ttls: map[string]time.Duration{}}
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0"})
+	client.AddHook(f)
+	t.Cleanup(func() { _ = client.Close() })
+	return f, client
+}
+
+func (f *fakeRedis) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, errors.New("fake redis does not dial")
+	}
+}
+
+func (f *fakeRedis) ProcessHook(redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		f.process(cmd)
+		return cmd.Err()
+	}
+}
+
+func (f *fakeRedis) ProcessPipelineHook(redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			f.process(cmd)
+		}
+		return nil
+	}
+}
+
+func (f *fakeRedis) process(cmd redis.Cmder) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.down {
+		cmd.SetErr(errors.New("connection refused"))
+		return
+	}
+
+	args := cmd.Args()
+	key := fmt.Sprint(args[1])
+	switch strings.ToLower(cmd.Name()) {
+	case "get":
+		c := cmd.(*redis.StringCmd)
+		if v, ok := f.values[key]; ok {
+			c.SetVal(v)
+		} else {
+			c.SetErr(redis.Nil)
+		}
+	case "pttl":
+		c := cmd.(*redis.DurationCmd)
+		if ttl, ok := f.ttls[key]; ok {
+			c.SetVal(ttl)
+		} else {
+			c.SetVal(-2)
+		}
+	case "set":
+		_, exists := f.values[key]
+		xx := strings.EqualFold(fmt.Sprint(args[len(args)-1]), "xx")
+		if xx && !exists {
+			cmd.(*redis.BoolCmd).SetVal(false)
+			return
+		}
+		f.values[key] = fmt.Sprintf("%s", args[2])
+		f.ttls[key] = 0
+		if len(args) > 4 {
+			n := args[4].(int64)
+			if strings.EqualFold(fmt.Sprint(args[3]), "ex") {
+				f.ttls[key] = time.Duration(n) * time.Second
+			} else {
+				f.ttls[key] = time.Duration(n) * time.Millisecond
+			}
+		}
+		if xx {
+			cmd.(*redis.BoolCmd).SetVal(true)
+		} else {
+			cmd.(*redis.StatusCmd).SetVal("OK")
+		}
+	case "exists", "del":
+		var n int64
+		for _, arg := range args[1:] {
+			k := fmt.Sprint(arg)
+			if _, ok := f.values[k]; ok {
+				n++
+				if cmd.Name() == "del" {
+					delete(f.values, k)
+					delete(f.ttls, k)
+				}
+			}
+		}
+		cmd.(*redis.IntCmd).SetVal(n)
+	default:
+		cmd.SetErr(fmt.Errorf("fake redis: unsupported command %s", cmd.Name()))
+	}
+}
+
+func (f *fakeRedis) setTTL(key string, ttl time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ttls[key] = ttl
+}
+
+type profile struct {
+	Name string `json:"name"`
+}
+
+func TestGetSet(t *testing.T) {
+	f, client := newFake(t)
+	c := New(client, Options{Name: "test"})
+	ctx := context.Background()
+
+	if _, ok, err := Get[profile](ctx, c, "profile:1"); ok || err != nil {
+		t.Fatalf("empty cache: got %v, %v", ok, err)
+	}
+	if err := Set(ctx, c, "profile:1", profile{Name: "Ada"}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if f.values["profile:1"] != `{"name":"Ada"}` {
+		t.Errorf("stored %q, want plain JSON", f.values["profile:1"])
+	}
+	if got, ok, err := Get[profile](ctx, c, "profile:1"); !ok || err != nil || got.Name != "Ada" {
+		t.Errorf("Get: got %+v, %v, %v", got, ok, err)
+	}
+
+	if ok, err := Replace(ctx, c, "profile:2", profile{Name: "Bob"}, time.Minute); ok || err != nil {
+		t.Errorf("Replace of a missing key: got %v, %v", ok, err)
+	}
+	if _, exists := f.values["profile:2"]; exists {
+		t.Error("Replace created a missing key")
+	}
+	if ok, err := Replace(ctx, c, "profile:1", profile{Name: "Ada L."}, time.Minute); !ok || err != nil {
+		t.Errorf("Replace: got %v, %v", ok, err)
+	}
+
+	if err := c.Delete(ctx, "profile:1"); err != nil {
+		t.Fatal(err)
+	}
+	if exists, err := c.Exists(ctx, "profile:1"); exists || err != nil {
+		t.Errorf("after Delete: got %v, %v", exists, err)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	f, client := newFake(t)
+	c := New(client, Options{Name: "test", Jitter: 0.1})
+	ctx := context.Background()
+
+	for i, r := range []float64{0, 0.5, 0.999} {
+		c.random = func() float64 { return r }
+		key := fmt.Sprint("k", i)
+		if err := Set(ctx, c, key, 1, 100*time.Second); err != nil {
+			t.Fatal(err)
+		}
+		if ttl := f.ttls[key]; ttl < 90*time.Second || ttl > 110*time.Second {
+			t.Errorf("random %v: TTL %v outside ±10%%", r, ttl)
+		}
+	}
+
+	c = New(client, Options{Name: "test"})
+	if err := Set(ctx, c, "exact", 1, 100*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if f.ttls["exact"] != 100*time.Second {
+		t.Errorf("TTL without jitter: %v", f.ttls["exact"])
+	}
+}
+
+func TestGetOrLoadSharesLoads(t *testing.T) {
+	_, client := newFake(t)
+	c := New(client, Options{Name: "test"})
+	ctx := context.Background()
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(context.Context) (profile, error) {
+		loads.Add(1)
+		<-release
+		return profile{Name: "Ada"}, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make(chan profile, 10)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p, err := GetOrLoad(ctx, c, "profile:1", time.Minute, load)
+			if err != nil {
+				t.Error(err)
+			}
+			results <- p
+		}()
+	}
+	// Let the callers pile up on the first load
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	if n := loads.Load(); n != 1 {
+		t.Errorf("loaded %d times, want 1", n)
+	}
+	for p := range results {
+		if p.Name != "Ada" {
+			t.Errorf("got %+v", p)
+		}
+	}
+
+	p, err := GetOrLoad(ctx, c, "profile:1", time.Minute, func(context.Context) (profile, error) {
+		t.Error("loaded a cached key")
+		return profile{}, nil
+	})
+	if err != nil || p.Name != "Ada" {
+		t.Errorf("cached: got %+v, %v", p, err)
+	}
+}
+
+func TestGetOrLoadNegativeCaching(t *testing.T) {
+	_, client := newFake(t)
+	c := New(client, Options{Name: "test", NegativeTTL: time.Minute})
+	ctx := context.Background()
+
+	var loads int
+	load := func(context.Context) (profile, error) {
+		loads++
+		return profile{}, ErrNotFound
+	}
+	for range 3 {
+		if _, err := GetOrLoad(ctx, c, "profile:404", time.Minute, load); !errors.Is(err, ErrNotFound) {
+			t.Errorf("got %v, want ErrNotFound", err)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("loaded %d times, want 1", loads)
+	}
+	if _, ok, err := Get[profile](ctx, c, "profile:404"); ok || err != nil {
+		t.Errorf("Get of a negative entry: got %v, %v", ok, err)
+	}
+
+	failing := errors.New("user service unavailable")
+	if _, err := GetOrLoad(ctx, c, "profile:500", time.Minute, func(context.Context) (profile, error) {
+		return profile{}, failing
+	}); !errors.Is(err, failing) {
+		t.Errorf("got %v, want the loader error", err)
+	}
+	if exists, _ := c.Exists(ctx, "profile:500"); exists {
+		t.Error("a loader error was cached")
+	}
+}
+
+func TestGetOrLoadRefreshesEarly(t *testing.T) {
+	f, client := newFake(t)
+	c := New(client, Options{Name: "test", Beta: 1})
+	ctx := context.Background()
+
+	version := 0
+	refreshed := make(chan struct{}, 1)
+	load := func(context.Context) (int, error) {
+		version++
+		if version > 1 {
+			refreshed <- struct{}{}
+		}
+		return version, nil
+	}
+	if v, err := GetOrLoad(ctx, c, "counter", time.Minute, load); err != nil || v != 1 {
+		t.Fatalf("first load: got %v, %v", v, err)
+	}
+	c.loadNanos.Store(int64(time.Second))
+
+	// Far from expiry: no refresh, even with a large random factor
+	c.random = func() float64 { return 0.99 }
+	if v, _ := GetOrLoad(ctx, c, "counter", time.Minute, load); v != 1 {
+		t.Fatalf("got %v", v)
+	}
+
+	// Within the loading time of expiry: the cached value is served and refreshed
+	f.setTTL("counter", 500*time.Millisecond)
+	if v, _ := GetOrLoad(ctx, c, "counter", time.Minute, load); v != 1 {
+		t.Errorf("early refresh should serve the cached value, got %v", v)
+	}
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("no background refresh")
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		v, ok, _ := Get[int](ctx, c, "counter")
+		if ok && v == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("refreshed value not cached, got %v", v)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestGetOrLoadBypassesAnUnavailableCache(t *testing.T) {
+	f, client := newFake(t)
+	c := New(client, Options{Name: "test"})
+	f.down = true
+
+	v, err := GetOrLoad(context.Background(), c, "k", time.Minute, func(context.Context) (string, error) {
+		return "loaded", nil
+	})
+	if err != nil || v != "loaded" {
+		t.Errorf("got %q, %v", v, err)
+	}
+}
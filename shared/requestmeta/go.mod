@@ -0,0 +1,17 @@
+module github.com/ductan2/microservice-app/shared/requestmeta
+
+go 1.24.0
+
+require github.com/ductan2/microservice-app/shared/logging v0.0.0
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/ductan2/microservice-app/shared/telemetry v0.0.0 // indirect
+	go.opentelemetry.io/otel v1.39.0 // indirect
+	go.opentelemetry.io/otel/trace v1.39.0 // indirect
+)
+
+replace (
+	github.com/ductan2/microservice-app/shared/logging => ../logging
+	github.com/ductan2/microservice-app/shared/telemetry => ../telemetry
+)
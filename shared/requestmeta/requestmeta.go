@@ -0,0 +1,197 @@
+// Package requestmeta resolves the request ID and the client IP of the requests served
+// by the Go services, the same way in each of them, so their logs and rate limits agree.
+//
+// The request ID comes from the X-Request-ID header when it is safe to log and forward,
+// and is generated otherwise. The client IP is read from X-Forwarded-For only past the
+// proxies the service trusts, Traefik and the BFF, so a client cannot pick the IP its
+// rate limits are counted against. Proxies also configures gin, so Context.ClientIP
+// resolves the same address.
+package requestmeta
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"regexp"
+	"strings"
+
+	"github.com/ductan2/microservice-app/shared/logging"
+)
+
+// Header carries the request ID between the services and back to the caller.
+const Header = "X-Request-ID"
+
+// idPattern bounds caller supplied request IDs so they are safe to log and forward.
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID returns the X-Request-ID of r, or a new ID when it is missing or not safe to
+// log and forward.
+func RequestID(r *http.Request) string {
+	if id := r.Header.Get(Header); idPattern.MatchString(id) {
+		return id
+	}
+	return NewRequestID()
+}
+
+// NewRequestID returns a random UUID.
+func NewRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+type clientIPKey struct{}
+
+// WithClientIP stores the client IP on ctx.
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIPFromContext returns the client IP stored on ctx, or "" when none is set.
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
+
+// Attach echoes the request ID of r on w and returns r with the request ID (see
+// logging.WithRequestID) and the client IP stored on its context. The gin middlewares of
+// the services call it first thing.
+func Attach(w http.ResponseWriter, r *http.Request, proxies Proxies) *http.Request {
+	requestID := RequestID(r)
+	w.Header().Set(Header, requestID)
+	ctx := logging.WithRequestID(r.Context(), requestID)
+	ctx = WithClientIP(ctx, proxies.ClientIP(r))
+	return r.WithContext(ctx)
+}
+
+// Handler is Attach as a net/http middleware.
+func Handler(proxies Proxies, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, Attach(w, r, proxies))
+	})
+}
+
+// DefaultTrustedProxies are the loopback and private networks, where Traefik and the
+// services reach each other in Docker Compose and Kubernetes.
+const DefaultTrustedProxies = "127.0.0.0/8,::1/128,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16,fc00::/7"
+
+// Proxies are the networks whose X-Forwarded-For is trusted. The zero value trusts none,
+// so the client IP is the peer address. It is read from TRUSTED_PROXIES by envconfig.
+type Proxies struct {
+	prefixes []netip.Prefix
+}
+
+// ParseProxies reads a comma separated list of IP addresses and CIDR networks.
+func ParseProxies(s string) (Proxies, error) {
+	var p Proxies
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if !strings.Contains(item, "/") {
+			addr, err := netip.ParseAddr(item)
+			if err != nil {
+				return Proxies{}, fmt.Errorf("invalid trusted proxy %q: %w", item, err)
+			}
+			addr = addr.Unmap()
+			p.prefixes = append(p.prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(item)
+		if err != nil {
+			return Proxies{}, fmt.Errorf("invalid trusted proxy %q: %w", item, err)
+		}
+		p.prefixes = append(p.prefixes, prefix.Masked())
+	}
+	return p, nil
+}
+
+// MustParseProxies is ParseProxies panicking on an error, for constants.
+func MustParseProxies(s string) Proxies {
+	p, err := ParseProxies(s)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func (p *Proxies) UnmarshalText(text []byte) error {
+	parsed, err := ParseProxies(string(text))
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
+
+func (p Proxies) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p Proxies) String() string {
+	return strings.Join(p.Strings(), ",")
+}
+
+// Strings returns the trusted networks in CIDR notation.
+func (p Proxies) Strings() []string {
+	items := make([]string, len(p.prefixes))
+	for i, prefix := range p.prefixes {
+		items[i] = prefix.String()
+	}
+	return items
+}
+
+// Trusted reports whether ip is the address of a trusted proxy.
+func (p Proxies) Trusted(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	for _, prefix := range p.prefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the address of the client of r. When the peer is a trusted proxy,
+// X-Forwarded-For is read from the right, each hop appending the address it received
+// the request from, and the first address that is not a trusted proxy is the client.
+// The entries left of it were written by the client and are ignored.
+func (p Proxies) ClientIP(r *http.Request) string {
+	peer, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil {
+		// Not an address and port, such as in tests; kept as is
+		return r.RemoteAddr
+	}
+	client := peer.Addr().Unmap()
+	if !p.Trusted(client) {
+		return client.String()
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			// The proxies write valid addresses: stop at the last one they vouch for
+			break
+		}
+		client = hop.Unmap()
+		if !p.Trusted(client) {
+			break
+		}
+	}
+	return client.String()
+}
+
+// Configure makes gin.Engine resolve Context.ClientIP like ClientIP, past the same
+// proxies. It takes the engine through an interface so this package does not import gin.
+func (p Proxies) Configure(engine interface{ SetTrustedProxies([]string) error }) error {
+	return engine.SetTrustedProxies(p.Strings())
+}
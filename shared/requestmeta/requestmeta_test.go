@@ -0,0 +1,122 @@
+package requestmeta
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/ductan2/microservice-app/shared/logging"
+)
+
+func TestRequestID(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(Header, "req-123:abc")
+	if got := RequestID(r); got != "req-123:abc" {
+		t.Errorf("RequestID = %q, want the header", got)
+	}
+
+	for _, bad := range []string{"", "has space", "new\nline", strings.Repeat("a", 129)} {
+		r.Header.Set(Header, bad)
+		if got := RequestID(r); got == bad || len(got) != 36 {
+			t.Errorf("%q: RequestID = %q, want a new ID", bad, got)
+		}
+	}
+	if NewRequestID() == NewRequestID() {
+		t.Error("NewRequestID returned the same ID twice")
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	proxies := MustParseProxies("10.0.0.0/8, 192.168.1.10")
+	for _, tc := range []struct {
+		name   string
+		remote string
+		xff    []string
+		want   string
+	}{
+		{"direct client", "203.0.113.7:5123", nil, "203.0.113.7"},
+		{"untrusted peer", "203.0.113.7:5123", []string{"198.51.100.1"}, "203.0.113.7"},
+		{"through traefik", "10.0.0.5:80", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"spoofed by the client", "10.0.0.5:80", []string{"1.2.3.4, 198.51.100.1"}, "198.51.100.1"},
+		{"traefik then bff", "192.168.1.10:80", []string{"198.51.100.1, 10.0.0.5"}, "198.51.100.1"},
+		{"several headers", "10.0.0.5:80", []string{"1.2.3.4", "198.51.100.1"}, "198.51.100.1"},
+		{"only proxies", "10.0.0.5:80", []string{"10.0.0.9"}, "10.0.0.9"},
+		{"no header", "10.0.0.5:80", nil, "10.0.0.5"},
+		{"invalid hop", "10.0.0.5:80", []string{"198.51.100.1, unknown"}, "10.0.0.5"},
+		{"ipv6", "[2001:db8::1]:443", nil, "2001:db8::1"},
+		{"mapped ipv4", "[::ffff:10.0.0.5]:80", []string{"198.51.100.1"}, "198.51.100.1"},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = tc.remote
+		for _, value := range tc.xff {
+			r.Header.Add("X-Forwarded-For", value)
+		}
+		if got := proxies.ClientIP(r); got != tc.want {
+			t.Errorf("%s: ClientIP = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+
+	// The zero value trusts no proxy
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.5:80"
+	r.Header.Set("X-Forwarded-For", "198.51.100.1")
+	if got := (Proxies{}).ClientIP(r); got != "10.0.0.5" {
+		t.Errorf("zero Proxies: ClientIP = %q", got)
+	}
+}
+
+func TestParseProxies(t *testing.T) {
+	p, err := ParseProxies(DefaultTrustedProxies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Trusted(netip.MustParseAddr("172.18.0.3")) || p.Trusted(netip.MustParseAddr("8.8.8.8")) {
+		t.Error("default proxies: wrong networks")
+	}
+
+	var text Proxies
+	if err := text.UnmarshalText([]byte("10.1.2.3/8,::1")); err != nil {
+		t.Fatal(err)
+	}
+	if got := text.String(); got != "10.0.0.0/8,::1/128" {
+		t.Errorf("String = %q", got)
+	}
+	for _, bad := range []string{"10.0.0.0/33", "traefik", "10.0.0"} {
+		if _, err := ParseProxies(bad); err == nil {
+			t.Errorf("%q: want an error", bad)
+		}
+	}
+}
+
+type fakeEngine struct{ trusted []string }
+
+func (e *fakeEngine) SetTrustedProxies(trusted []string) error {
+	e.trusted = trusted
+	return nil
+}
+
+func TestAttach(t *testing.T) {
+	engine := &fakeEngine{}
+	proxies := MustParseProxies("10.0.0.0/8")
+	if err := proxies.Configure(engine); err != nil || len(engine.trusted) != 1 || engine.trusted[0] != "10.0.0.0/8" {
+		t.Errorf("Configure: %v, %v", engine.trusted, err)
+	}
+
+	var requestID, clientIP string
+	handler := Handler(proxies, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID = logging.RequestIDFromContext(r.Context())
+		clientIP = ClientIPFromContext(r.Context())
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.5:80"
+	r.Header.Set("X-Forwarded-For", "198.51.100.1")
+	r.Header.Set(Header, "abc")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if requestID != "abc" || w.Header().Get(Header) != "abc" || clientIP != "198.51.100.1" {
+		t.Errorf("request ID %q, echoed %q, client IP %q", requestID, w.Header().Get(Header), clientIP)
+	}
+}
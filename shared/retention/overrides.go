@@ -0,0 +1,99 @@
+package retention
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Overrides are the max ages replacing those of the rules of the same name; a zero age
+// turns the rule off.
+type Overrides map[string]time.Duration
+
+// ParseOverrides parses a comma separated list of overrides:
+//
+//	audit_logs=8760h,webhook_events=off
+//
+// Each entry is name=age, the age a duration or "off".
+func ParseOverrides(s string) (Overrides, error) {
+	overrides := Overrides{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, age, ok := strings.Cut(entry, "=")
+		name, age = strings.TrimSpace(name), strings.TrimSpace(age)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("retention: %q: want name=age", entry)
+		}
+		if _, dup := overrides[name]; dup {
+			return nil, fmt.Errorf("retention: rule %s is overridden twice", name)
+		}
+		if age == "off" {
+			overrides[name] = 0
+			continue
+		}
+		d, err := time.ParseDuration(age)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("retention: %q: want a positive duration or off", entry)
+		}
+		overrides[name] = d
+	}
+	return overrides, nil
+}
+
+// Apply returns rules with the overrides applied, leaving out those turned off. An
+// override naming no rule is an error, so a typo does not keep rows longer than meant.
+func (o Overrides) Apply(rules []Rule) ([]Rule, error) {
+	applied := make([]Rule, 0, len(rules))
+	seen := make(map[string]bool, len(o))
+	for _, rule := range rules {
+		age, ok := o[rule.Name]
+		if ok {
+			seen[rule.Name] = true
+			if age == 0 {
+				continue
+			}
+			rule.MaxAge = age
+		}
+		applied = append(applied, rule)
+	}
+	for name := range o {
+		if !seen[name] {
+			return nil, fmt.Errorf("retention: override of unknown rule %s", name)
+		}
+	}
+	return applied, nil
+}
+
+// UnmarshalText parses the overrides with ParseOverrides, so a configuration struct can
+// load them with `env:"RETENTION_OVERRIDES"`.
+func (o *Overrides) UnmarshalText(text []byte) error {
+	parsed, err := ParseOverrides(string(text))
+	if err != nil {
+		return err
+	}
+	*o = parsed
+	return nil
+}
+
+// String formats the overrides the way ParseOverrides reads them, sorted by name.
+func (o Overrides) String() string {
+	names := make([]string, 0, len(o))
+	for name := range o {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	entries := make([]string, 0, len(names))
+	for _, name := range names {
+		age := "off"
+		if o[name] > 0 {
+			age = o[name].String()
+		}
+		entries = append(entries, name+"="+age)
+	}
+	return strings.Join(entries, ",")
+}
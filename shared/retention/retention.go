@@ -0,0 +1,276 @@
+// Package retention enforces how long the Go services keep their rows. Each service
+// declares its rules, such as webhook events for 90 days or audit logs for two years,
+// and runs a Purger as a scheduled job (see shared/scheduler): past its age, a row is
+// deleted, or has its personal columns cleared when the rest of it is still needed.
+// Rules are enforced in batches, so a first purge of a large table does not hold long
+// locks, and a dry run reports what would go without touching anything.
+//
+// The statements are written for PostgreSQL and run through database/sql; the services
+// pass the *sql.DB of their GORM connection.
+package retention
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	// EnvVar is the variable the services read Overrides from
+	EnvVar = "RETENTION_OVERRIDES"
+	// DefaultBatchSize is the number of rows a statement purges
+	DefaultBatchSize = 1000
+)
+
+var (
+	purgedTotal = promauto.With(metrics.Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "retention_purged_rows_total",
+		Help: "Rows deleted or anonymized by retention rules, by rule and action.",
+	}, []string{"rule", "action"})
+	expiredRows = promauto.With(metrics.Default).NewGaugeVec(prometheus.GaugeOpts{
+		Name: "retention_expired_rows",
+		Help: "Rows past the age of a retention rule at the last dry run.",
+	}, []string{"rule"})
+	ruleFailures = promauto.With(metrics.Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "retention_failures_total",
+		Help: "Retention rules that failed to run.",
+	}, []string{"rule"})
+)
+
+// identifier matches the table and column names a rule may use, optionally schema
+// qualified.
+var identifier = regexp.MustCompile(`^[a-z_][a-z0-9_]*(\.[a-z_][a-z0-9_]*)?$`)
+
+// Rule is how long the rows of a table are kept.
+type Rule struct {
+	// Name identifies the rule in overrides, logs and metrics, such as "audit_logs"
+	Name  string
+	Table string
+	// Column is the timestamp compared with the age, such as created_at
+	Column string
+	MaxAge time.Duration
+	// Where restricts the rule to some rows, such as "processed"; it is SQL written by
+	// the service, never by a user
+	Where string
+	// Anonymize lists the columns set to NULL instead of deleting the rows
+	Anonymize []string
+	// Blank lists the NOT NULL text columns set to '' instead of deleting the rows
+	Blank []string
+}
+
+// Action is "anonymize" for rules clearing columns and "delete" otherwise.
+func (r Rule) Action() string {
+	if r.anonymizes() {
+		return "anonymize"
+	}
+	return "delete"
+}
+
+// Validate reports whether the rule can run.
+func (r Rule) Validate() error {
+	if r.Name == "" {
+		return errors.New("retention: rule name is required")
+	}
+	if !identifier.MatchString(r.Table) || !identifier.MatchString(r.Column) {
+		return fmt.Errorf("retention: rule %s: invalid table %q or column %q", r.Name, r.Table, r.Column)
+	}
+	for _, column := range slices.Concat(r.Anonymize, r.Blank) {
+		if !identifier.MatchString(column) {
+			return fmt.Errorf("retention: rule %s: invalid column %q", r.Name, column)
+		}
+	}
+	if r.MaxAge <= 0 {
+		return fmt.Errorf("retention: rule %s: max age must be positive", r.Name)
+	}
+	return nil
+}
+
+// condition selects the rows past the age, those with something left to clear when the
+// rule anonymizes.
+func (r Rule) condition() string {
+	cond := r.Column + " < $1"
+	if r.Where != "" {
+		cond += " AND (" + r.Where + ")"
+	}
+	if r.anonymizes() {
+		var left []string
+		for _, column := range r.Anonymize {
+			left = append(left, column+" IS NOT NULL")
+		}
+		for _, column := range r.Blank {
+			left = append(left, column+" <> ''")
+		}
+		cond += " AND (" + strings.Join(left, " OR ") + ")"
+	}
+	return cond
+}
+
+func (r Rule) anonymizes() bool {
+	return len(r.Anonymize) > 0 || len(r.Blank) > 0
+}
+
+// batchStatement purges up to $2 rows past the cutoff $1.
+func (r Rule) batchStatement() string {
+	batch := "SELECT ctid FROM " + r.Table + " WHERE " + r.condition() + " LIMIT $2"
+	if !r.anonymizes() {
+		return "DELETE FROM " + r.Table + " WHERE ctid IN (" + batch + ")"
+	}
+	var sets []string
+	for _, column := range r.Anonymize {
+		sets = append(sets, column+" = NULL")
+	}
+	for _, column := range r.Blank {
+		sets = append(sets, column+" = ''")
+	}
+	return "UPDATE " + r.Table + " SET " + strings.Join(sets, ", ") + " WHERE ctid IN (" + batch + ")"
+}
+
+func (r Rule) countStatement() string {
+	return "SELECT COUNT(*) FROM " + r.Table + " WHERE " + r.condition()
+}
+
+// Config configures a Purger.
+type Config struct {
+	// BatchSize is the number of rows a statement purges, DefaultBatchSize when 0
+	BatchSize int
+	// DryRun makes Run report the rows past their age instead of purging them
+	DryRun bool
+	// Overrides replace the max age of rules, or turn them off
+	Overrides Overrides
+}
+
+// Result reports a rule run by a Purger.
+type Result struct {
+	Rule   string
+	Action string
+	// Cutoff is the time before which rows were purged
+	Cutoff time.Time
+	// Rows counts the rows purged, or those past the age in a dry run
+	Rows     int64
+	DryRun   bool
+	Duration time.Duration
+	Err      error
+}
+
+// Purger enforces the rules of a service on its database.
+type Purger struct {
+	db    *sql.DB
+	rules []Rule
+	cfg   Config
+	now   func() time.Time
+}
+
+// New returns a purger of rules on db, with the overrides of cfg applied. Rules turned
+// off by an override are left out.
+func New(db *sql.DB, cfg Config, rules ...Rule) (*Purger, error) {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultBatchSize
+	}
+	rules, err := cfg.Overrides.Apply(rules)
+	if err != nil {
+		return nil, err
+	}
+	var errs []error
+	for i, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+		if slices.ContainsFunc(rules[:i], func(other Rule) bool { return other.Name == rule.Name }) {
+			errs = append(errs, fmt.Errorf("retention: rule %s is declared twice", rule.Name))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return &Purger{db: db, rules: rules, cfg: cfg, now: time.Now}, nil
+}
+
+// Rules returns the rules the purger enforces, overrides applied.
+func (p *Purger) Rules() []Rule {
+	return slices.Clone(p.rules)
+}
+
+// Run purges every rule, or reports them when Config.DryRun is set, and logs the
+// results; it is the scheduled job of the services. A failed rule does not stop the
+// others; their errors are joined.
+func (p *Purger) Run(ctx context.Context) error {
+	var errs []error
+	for _, result := range p.Purge(ctx, p.cfg.DryRun) {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Report counts the rows past the age of each rule without purging them.
+func (p *Purger) Report(ctx context.Context) []Result {
+	return p.Purge(ctx, true)
+}
+
+// Purge runs every rule, deleting or anonymizing the rows past its age in batches until
+// none is left or ctx is done; with dryRun it only counts them.
+func (p *Purger) Purge(ctx context.Context, dryRun bool) []Result {
+	results := make([]Result, 0, len(p.rules))
+	for _, rule := range p.rules {
+		result := p.run(ctx, rule, dryRun)
+		attrs := []any{"rule", rule.Name, "action", result.Action, "cutoff", result.Cutoff, "rows", result.Rows, "duration", result.Duration}
+		switch {
+		case result.Err != nil:
+			ruleFailures.WithLabelValues(rule.Name).Inc()
+			slog.ErrorContext(ctx, "retention rule failed", append(attrs, "error", result.Err)...)
+		case dryRun:
+			slog.InfoContext(ctx, "retention dry run", attrs...)
+		case result.Rows > 0:
+			slog.InfoContext(ctx, "retention rule applied", attrs...)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func (p *Purger) run(ctx context.Context, rule Rule, dryRun bool) (result Result) {
+	start := p.now()
+	result = Result{Rule: rule.Name, Action: rule.Action(), Cutoff: start.Add(-rule.MaxAge), DryRun: dryRun}
+	defer func() { result.Duration = p.now().Sub(start) }()
+
+	if dryRun {
+		if err := p.db.QueryRowContext(ctx, rule.countStatement(), result.Cutoff).Scan(&result.Rows); err != nil {
+			result.Err = fmt.Errorf("retention: rule %s: %w", rule.Name, err)
+			return result
+		}
+		expiredRows.WithLabelValues(rule.Name).Set(float64(result.Rows))
+		return result
+	}
+
+	statement := rule.batchStatement()
+	for {
+		res, err := p.db.ExecContext(ctx, statement, result.Cutoff, p.cfg.BatchSize)
+		if err == nil {
+			var n int64
+			n, err = res.RowsAffected()
+			result.Rows += n
+			purgedTotal.WithLabelValues(rule.Name, result.Action).Add(float64(n))
+			if err == nil && n < int64(p.cfg.BatchSize) {
+				return result
+			}
+		}
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			result.Err = fmt.Errorf("retention: rule %s: %w", rule.Name, err)
+			return result
+		}
+	}
+}
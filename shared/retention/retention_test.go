@@ -0,0 +1,209 @@
+package retention
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDB is a database/sql driver recording statements; each Exec affects the next
+// count of affected, each Query returns count.
+type fakeDB struct {
+	mu         sync.Mutex
+	statements []string
+	args       [][]driver.NamedValue
+	affected   []int64
+	count      int64
+	err        error
+}
+
+func (d *fakeDB) Open(string) (driver.Conn, error) { return fakeConn{d}, nil }
+
+func (d *fakeDB) record(query string, args []driver.NamedValue) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.statements = append(d.statements, query)
+	d.args = append(d.args, args)
+	return d.err
+}
+
+type fakeConn struct{ db *fakeDB }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c fakeConn) Close() error                        { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := c.db.record(query, args); err != nil {
+		return nil, err
+	}
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	var n int64
+	if len(c.db.affected) > 0 {
+		n, c.db.affected = c.db.affected[0], c.db.affected[1:]
+	}
+	return driver.RowsAffected(n), nil
+}
+
+func (c fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := c.db.record(query, args); err != nil {
+		return nil, err
+	}
+	return &countRows{n: c.db.count}, nil
+}
+
+type countRows struct {
+	n    int64
+	done bool
+}
+
+func (r *countRows) Columns() []string { return []string{"count"} }
+func (r *countRows) Close() error      { return nil }
+
+func (r *countRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.n
+	return nil
+}
+
+func openFake(t *testing.T, fake *fakeDB) *sql.DB {
+	db := sql.OpenDB(fakeConnector{fake})
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+type fakeConnector struct{ db *fakeDB }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn{c.db}, nil }
+func (c fakeConnector) Driver() driver.Driver                        { return c.db }
+
+var webhookEvents = Rule{Name: "webhook_events", Table: "webhook_events", Column: "created_at", MaxAge: 90 * 24 * time.Hour, Where: "processed"}
+
+func TestPurgeDeletesInBatches(t *testing.T) {
+	fake := &fakeDB{affected: []int64{2, 2, 1}}
+	purger, err := New(openFake(t, fake), Config{BatchSize: 2}, webhookEvents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	purger.now = func() time.Time { return now }
+
+	results := purger.Purge(context.Background(), false)
+	if len(results) != 1 || results[0].Err != nil || results[0].Rows != 5 || results[0].Action != "delete" {
+		t.Fatalf("results %+v", results)
+	}
+	if want := now.Add(-webhookEvents.MaxAge); !results[0].Cutoff.Equal(want) {
+		t.Errorf("cutoff %v, want %v", results[0].Cutoff, want)
+	}
+	if len(fake.statements) != 3 {
+		t.Fatalf("%d statements, want 3", len(fake.statements))
+	}
+	want := "DELETE FROM webhook_events WHERE ctid IN (SELECT ctid FROM webhook_events WHERE created_at < $1 AND (processed) LIMIT $2)"
+	if fake.statements[0] != want {
+		t.Errorf("statement %q", fake.statements[0])
+	}
+	if cutoff, _ := fake.args[0][0].Value.(time.Time); !cutoff.Equal(results[0].Cutoff) || fake.args[0][1].Value != int64(2) {
+		t.Errorf("args %v", fake.args[0])
+	}
+}
+
+func TestPurgeAnonymizes(t *testing.T) {
+	fake := &fakeDB{}
+	sessions := Rule{Name: "sessions", Table: "sessions", Column: "ended_at", MaxAge: time.Hour, Anonymize: []string{"ip_addr"}, Blank: []string{"user_agent"}}
+	purger, err := New(openFake(t, fake), Config{}, sessions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := purger.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := "UPDATE sessions SET ip_addr = NULL, user_agent = '' WHERE ctid IN (SELECT ctid FROM sessions WHERE ended_at < $1 AND (ip_addr IS NOT NULL OR user_agent <> '') LIMIT $2)"
+	if len(fake.statements) != 1 || fake.statements[0] != want {
+		t.Errorf("statements %q", fake.statements)
+	}
+}
+
+func TestDryRunCounts(t *testing.T) {
+	fake := &fakeDB{count: 42}
+	purger, err := New(openFake(t, fake), Config{DryRun: true}, webhookEvents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := purger.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	results := purger.Report(context.Background())
+	if len(results) != 1 || results[0].Rows != 42 || !results[0].DryRun {
+		t.Fatalf("results %+v", results)
+	}
+	for _, statement := range fake.statements {
+		if !strings.HasPrefix(statement, "SELECT COUNT(*) FROM webhook_events WHERE") {
+			t.Errorf("dry run ran %q", statement)
+		}
+	}
+}
+
+func TestRunJoinsFailures(t *testing.T) {
+	fake := &fakeDB{err: errors.New("connection reset")}
+	audit := Rule{Name: "audit_logs", Table: "audit_logs", Column: "created_at", MaxAge: time.Hour}
+	purger, err := New(openFake(t, fake), Config{}, webhookEvents, audit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = purger.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "webhook_events") || !strings.Contains(err.Error(), "audit_logs") {
+		t.Errorf("Run = %v, want both rules failing", err)
+	}
+}
+
+func TestNewValidatesRules(t *testing.T) {
+	for _, rules := range [][]Rule{
+		{{Name: "bad", Table: "users; DROP TABLE users", Column: "created_at", MaxAge: time.Hour}},
+		{{Name: "bad", Table: "users", Column: "created_at", MaxAge: time.Hour, Blank: []string{"Email"}}},
+		{{Name: "bad", Table: "users", Column: "created_at"}},
+		{webhookEvents, webhookEvents},
+	} {
+		if _, err := New(nil, Config{}, rules...); err == nil {
+			t.Errorf("%+v: want an error", rules)
+		}
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	overrides, err := ParseOverrides(" webhook_events=720h, audit_logs=off")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := overrides.String(); got != "audit_logs=off,webhook_events=720h0m0s" {
+		t.Errorf("String = %q", got)
+	}
+
+	audit := Rule{Name: "audit_logs", Table: "audit_logs", Column: "created_at", MaxAge: time.Hour}
+	purger, err := New(nil, Config{Overrides: overrides}, webhookEvents, audit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := purger.Rules()
+	if len(rules) != 1 || rules[0].Name != "webhook_events" || rules[0].MaxAge != 720*time.Hour {
+		t.Errorf("rules %+v", rules)
+	}
+
+	if _, err := New(nil, Config{Overrides: Overrides{"webhooks": time.Hour}}, webhookEvents); err == nil {
+		t.Error("override of an unknown rule: want an error")
+	}
+	for _, bad := range []string{"audit_logs", "audit_logs=2y", "audit_logs=-1h", "a=1h,a=2h"} {
+		if _, err := ParseOverrides(bad); err == nil {
+			t.Errorf("%q: want an error", bad)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+// Package gormstore keeps sagas in a Postgres table through GORM. The table needs these
+// columns:
+//
+//	id              UUID PRIMARY KEY
+//	type            TEXT NOT NULL
+//	aggregate_id    UUID NOT NULL
+//	status          TEXT NOT NULL
+//	step            INT NOT NULL DEFAULT 0
+//	steps           JSONB NOT NULL
+//	attempts        INT NOT NULL DEFAULT 0
+//	next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+//	last_error      TEXT
+//	locked_until    TIMESTAMPTZ
+//	created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+//	updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+//	finished_at     TIMESTAMPTZ
+//
+// with a unique index on (type, aggregate_id) and an index on (next_attempt_at) WHERE
+// status IN ('running', 'compensating') for the orchestrator to find due sagas.
+package gormstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/saga"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultTable is the table the services keep their sagas in.
+const DefaultTable = "sagas"
+
+type row struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Type          string
+	AggregateID   uuid.UUID `gorm:"type:uuid"`
+	Status        string
+	Step          int
+	Steps         []byte `gorm:"type:jsonb"`
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     sql.NullString
+	LockedUntil   sql.NullTime
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	FinishedAt    sql.NullTime
+}
+
+// Store implements saga.Store.
+type Store struct {
+	db    *gorm.DB
+	table string
+}
+
+var _ saga.Store = (*Store)(nil)
+
+// New returns a Store on the given table, DefaultTable when empty.
+func New(db *gorm.DB, table string) *Store {
+	if table == "" {
+		table = DefaultTable
+	}
+	return &Store{db: db, table: table}
+}
+
+// WithTx returns a Store writing through tx, so a saga is started only if the rest of the
+// transaction commits.
+func (s *Store) WithTx(tx *gorm.DB) *Store {
+	return &Store{db: tx, table: s.table}
+}
+
+func (s *Store) query(ctx context.Context) *gorm.DB {
+	return s.db.WithContext(ctx).Table(s.table)
+}
+
+func (s *Store) Create(ctx context.Context, inst *saga.Instance) error {
+	r, err := toRow(inst)
+	if err != nil {
+		return err
+	}
+	// ON CONFLICT keeps the caller's transaction usable when the saga exists
+	result := s.query(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "type"}, {Name: "aggregate_id"}}, DoNothing: true}).
+		Create(&r)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return saga.ErrExists
+	}
+	return nil
+}
+
+func (s *Store) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*saga.Instance, error) {
+	var rows []row
+	err := s.db.WithContext(ctx).Raw(`UPDATE `+s.table+` SET locked_until = ?
+		WHERE id IN (
+			SELECT id FROM `+s.table+`
+			WHERE status IN ? AND next_attempt_at <= ? AND (locked_until IS NULL OR locked_until < ?)
+			ORDER BY next_attempt_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED)
+		RETURNING *`,
+		now.Add(lease), []string{string(saga.StatusRunning), string(saga.StatusCompensating)}, now, now, limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return fromRows(rows)
+}
+
+func (s *Store) Save(ctx context.Context, inst *saga.Instance) error {
+	r, err := toRow(inst)
+	if err != nil {
+		return err
+	}
+	return s.query(ctx).
+		Where("id = ?", r.ID).
+		Select("*").Omit("id", "type", "aggregate_id", "created_at").
+		Updates(&r).Error
+}
+
+func (s *Store) Get(ctx context.Context, id uuid.UUID) (*saga.Instance, error) {
+	var r row
+	err := s.query(ctx).Where("id = ?", id).Take(&r).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, saga.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fromRow(r)
+}
+
+func (s *Store) List(ctx context.Context, filter saga.Filter) ([]*saga.Instance, int64, error) {
+	var total int64
+	if err := s.filtered(ctx, filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	q := s.filtered(ctx, filter).Order("created_at DESC").Offset(filter.Offset)
+	if filter.Limit > 0 {
+		q = q.Limit(filter.Limit)
+	}
+	var rows []row
+	if err := q.Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	instances, err := fromRows(rows)
+	return instances, total, err
+}
+
+func (s *Store) filtered(ctx context.Context, filter saga.Filter) *gorm.DB {
+	q := s.query(ctx)
+	if filter.Type != "" {
+		q = q.Where("type = ?", filter.Type)
+	}
+	if filter.AggregateID != uuid.Nil {
+		q = q.Where("aggregate_id = ?", filter.AggregateID)
+	}
+	if filter.Status != "" {
+		q = q.Where("status = ?", filter.Status)
+	}
+	if filter.Stuck {
+		q = q.Where("(status IN ? OR (status IN ? AND created_at < ?))",
+			[]string{string(saga.StatusFailed), string(saga.StatusCompensationFailed)},
+			[]string{string(saga.StatusRunning), string(saga.StatusCompensating)},
+			filter.StuckBefore)
+	}
+	return q
+}
+
+func toRow(inst *saga.Instance) (row, error) {
+	steps, err := json.Marshal(inst.Steps)
+	if err != nil {
+		return row{}, fmt.Errorf("failed to encode steps of saga %s: %w", inst.ID, err)
+	}
+	r := row{
+		ID:            inst.ID,
+		Type:          inst.Type,
+		AggregateID:   inst.AggregateID,
+		Status:        string(inst.Status),
+		Step:          inst.Step,
+		Steps:         steps,
+		Attempts:      inst.Attempts,
+		NextAttemptAt: inst.NextAttemptAt,
+		LastError:     sql.NullString{String: inst.LastError, Valid: inst.LastError != ""},
+		CreatedAt:     inst.CreatedAt,
+		UpdatedAt:     inst.UpdatedAt,
+	}
+	if inst.LockedUntil != nil {
+		r.LockedUntil = sql.NullTime{Time: *inst.LockedUntil, Valid: true}
+	}
+	if inst.FinishedAt != nil {
+		r.FinishedAt = sql.NullTime{Time: *inst.FinishedAt, Valid: true}
+	}
+	return r, nil
+}
+
+func fromRow(r row) (*saga.Instance, error) {
+	inst := &saga.Instance{
+		ID:            r.ID,
+		Type:          r.Type,
+		AggregateID:   r.AggregateID,
+		Status:        saga.Status(r.Status),
+		Step:          r.Step,
+		Attempts:      r.Attempts,
+		NextAttemptAt: r.NextAttemptAt,
+		LastError:     r.LastError.String,
+		CreatedAt:     r.CreatedAt,
+		UpdatedAt:     r.UpdatedAt,
+	}
+	if err := json.Unmarshal(r.Steps, &inst.Steps); err != nil {
+		return nil, fmt.Errorf("failed to decode steps of saga %s: %w", r.ID, err)
+	}
+	if r.LockedUntil.Valid {
+		inst.LockedUntil = &r.LockedUntil.Time
+	}
+	if r.FinishedAt.Valid {
+		inst.FinishedAt = &r.FinishedAt.Time
+	}
+	return inst, nil
+}
+
+func fromRows(rows []row) ([]*saga.Instance, error) {
+	instances := make([]*saga.Instance, len(rows))
+	for i, r := range rows {
+		inst, err := fromRow(r)
+		if err != nil {
+			return nil, err
+		}
+		instances[i] = inst
+	}
+	return instances, nil
+}
@@ -0,0 +1,346 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Config tunes an Orchestrator. Zero fields take the defaults below.
+type Config struct {
+	PollInterval time.Duration // default 5s
+	BatchSize    int           // sagas claimed per poll, default 20
+	// StepTimeout bounds a run of a step without a Timeout of its own, default 30s
+	StepTimeout time.Duration
+	// MaxAttempts is how many times a step without MaxAttempts of its own runs before
+	// the saga is parked, default 5
+	MaxAttempts int
+	// BackoffBase is the delay after the first failure of a step; it doubles with every
+	// further failure up to BackoffMax. Defaults 10s and 10m
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// Lease is how long a claimed saga is reserved for this Orchestrator. It is renewed
+	// before every step and must exceed the longest step timeout; default twice the
+	// longest timeout of the definitions
+	Lease time.Duration
+}
+
+// Orchestrator runs the sagas of a Store every PollInterval.
+type Orchestrator struct {
+	store       Store
+	cfg         Config
+	definitions map[string]Definition
+	stopChan    chan struct{}
+	stopOnce    sync.Once
+}
+
+// New returns an Orchestrator of the sagas of the given definitions.
+func New(store Store, cfg Config, definitions ...Definition) *Orchestrator {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 5 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 20
+	}
+	if cfg.StepTimeout <= 0 {
+		cfg.StepTimeout = 30 * time.Second
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.BackoffBase <= 0 {
+		cfg.BackoffBase = 10 * time.Second
+	}
+	if cfg.BackoffMax <= 0 {
+		cfg.BackoffMax = 10 * time.Minute
+	}
+
+	o := &Orchestrator{
+		store:       store,
+		cfg:         cfg,
+		definitions: make(map[string]Definition, len(definitions)),
+		stopChan:    make(chan struct{}),
+	}
+	longest := cfg.StepTimeout
+	for _, d := range definitions {
+		o.definitions[d.Type] = d
+		for _, step := range d.Steps {
+			longest = max(longest, step.Timeout)
+		}
+	}
+	if o.cfg.Lease <= 0 {
+		o.cfg.Lease = 2 * longest
+	}
+	return o
+}
+
+// Start runs sagas until ctx is cancelled or Stop is called.
+func (o *Orchestrator) Start(ctx context.Context) {
+	ticker := time.NewTicker(o.cfg.PollInterval)
+	defer ticker.Stop()
+
+	slog.Info("saga orchestrator started", "interval", o.cfg.PollInterval, "batch_size", o.cfg.BatchSize)
+
+	o.tick(ctx)
+	for {
+		select {
+		case <-ticker.C:
+			o.tick(ctx)
+		case <-o.stopChan:
+			slog.Info("saga orchestrator stopped")
+			return
+		case <-ctx.Done():
+			slog.Info("saga orchestrator context cancelled")
+			return
+		}
+	}
+}
+
+// Stop ends Start; it is safe to call more than once.
+func (o *Orchestrator) Stop() {
+	o.stopOnce.Do(func() { close(o.stopChan) })
+}
+
+func (o *Orchestrator) tick(ctx context.Context) {
+	if err := o.ProcessDue(ctx); err != nil {
+		slog.ErrorContext(ctx, "saga processing failed", "error", err)
+	}
+}
+
+// ProcessDue claims one batch of due sagas and runs each as far as it goes: to its end,
+// or to a step that fails.
+func (o *Orchestrator) ProcessDue(ctx context.Context) error {
+	instances, err := o.store.Claim(ctx, time.Now(), o.cfg.Lease, o.cfg.BatchSize)
+	if err != nil {
+		return err
+	}
+	for _, inst := range instances {
+		o.run(ctx, inst)
+	}
+	return nil
+}
+
+func (o *Orchestrator) run(ctx context.Context, inst *Instance) {
+	def, ok := o.definitions[inst.Type]
+	if !ok || len(def.Steps) != len(inst.Steps) {
+		// Another version of the service may know it; leave it to its lease expiring
+		slog.WarnContext(ctx, "saga has no matching definition", "saga_id", inst.ID, "type", inst.Type)
+		return
+	}
+
+	for inst.Status.Active() {
+		var err error
+		switch inst.Status {
+		case StatusRunning:
+			err = o.forward(ctx, def, inst)
+		case StatusCompensating:
+			err = o.backward(ctx, def, inst)
+		}
+		if err != nil {
+			// The lease lapses and another poll picks the saga up again
+			slog.WarnContext(ctx, "failed to save saga", "saga_id", inst.ID, "type", inst.Type, "error", err)
+			return
+		}
+		if !inst.NextAttemptAt.IsZero() && inst.NextAttemptAt.After(time.Now()) {
+			return
+		}
+	}
+}
+
+// forward runs the current step, or completes the saga when there is none left.
+func (o *Orchestrator) forward(ctx context.Context, def Definition, inst *Instance) error {
+	if inst.Step >= len(def.Steps) {
+		o.finish(inst, StatusCompleted)
+		return o.save(ctx, inst)
+	}
+
+	step := def.Steps[inst.Step]
+	if err := o.renew(ctx, inst); err != nil {
+		return err
+	}
+	if err := o.call(ctx, inst, step.Do, step.Timeout); err != nil {
+		o.recordFailure(ctx, inst, step, err, StepFailed, StatusFailed)
+		return o.save(ctx, inst)
+	}
+	o.recordProgress(inst, StepCompleted)
+	inst.Step++
+	return o.save(ctx, inst)
+}
+
+// backward compensates the current step and moves to the one before it, or ends the
+// compensation when there is none left.
+func (o *Orchestrator) backward(ctx context.Context, def Definition, inst *Instance) error {
+	if inst.Step < 0 {
+		inst.Step = 0
+		o.finish(inst, StatusCompensated)
+		return o.save(ctx, inst)
+	}
+
+	step := def.Steps[inst.Step]
+	if inst.Steps[inst.Step].Status == StepCompleted && step.Compensate != nil {
+		if err := o.renew(ctx, inst); err != nil {
+			return err
+		}
+		if err := o.call(ctx, inst, step.Compensate, step.Timeout); err != nil {
+			o.recordFailure(ctx, inst, step, err, StepCompleted, StatusCompensationFailed)
+			return o.save(ctx, inst)
+		}
+		o.recordProgress(inst, StepCompensated)
+	}
+	inst.Step--
+	return o.save(ctx, inst)
+}
+
+// renew extends the lease of the saga before one of its steps runs.
+func (o *Orchestrator) renew(ctx context.Context, inst *Instance) error {
+	lockedUntil := time.Now().Add(o.cfg.Lease)
+	inst.LockedUntil = &lockedUntil
+	return o.save(ctx, inst)
+}
+
+// call runs fn under the timeout of the step. A step that ignores its context is
+// abandoned at the timeout, and may still complete after its next attempt started.
+func (o *Orchestrator) call(ctx context.Context, inst *Instance, fn func(context.Context, uuid.UUID) error, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = o.cfg.StepTimeout
+	}
+
+	stepCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- Permanent(fmt.Errorf("step panicked: %v", p))
+			}
+		}()
+		done <- fn(stepCtx, inst.AggregateID)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil && errors.Is(stepCtx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("step timed out after %s: %w", timeout, err)
+		}
+		return err
+	case <-stepCtx.Done():
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("step timed out after %s", timeout)
+	}
+}
+
+func (o *Orchestrator) recordProgress(inst *Instance, status StepStatus) {
+	now := time.Now()
+	state := &inst.Steps[inst.Step]
+	state.Status = status
+	state.LastError = ""
+	state.UpdatedAt = &now
+	inst.Attempts = 0
+	inst.LastError = ""
+	inst.NextAttemptAt = now
+	slog.Debug("saga step "+string(status), "saga_id", inst.ID, "type", inst.Type, "step", state.Name)
+}
+
+// recordFailure schedules the next attempt of a step, or parks the saga after
+// MaxAttempts or a Permanent error.
+func (o *Orchestrator) recordFailure(ctx context.Context, inst *Instance, step Step, stepErr error, stepStatus StepStatus, parked Status) {
+	now := time.Now()
+	maxAttempts := step.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = o.cfg.MaxAttempts
+	}
+
+	inst.Attempts++
+	inst.LastError = stepErr.Error()
+	state := &inst.Steps[inst.Step]
+	state.Attempts++
+	state.LastError = stepErr.Error()
+	state.UpdatedAt = &now
+
+	if IsPermanent(stepErr) || inst.Attempts >= maxAttempts {
+		state.Status = stepStatus
+		inst.Status = parked
+		inst.LockedUntil = nil
+		slog.ErrorContext(ctx, "saga step failed, parking the saga", "saga_id", inst.ID, "type", inst.Type, "step", step.Name, "status", parked, "attempts", inst.Attempts, "error", stepErr)
+		return
+	}
+	inst.NextAttemptAt = now.Add(Backoff(inst.Attempts, o.cfg.BackoffBase, o.cfg.BackoffMax))
+	inst.LockedUntil = nil
+	slog.WarnContext(ctx, "saga step failed", "saga_id", inst.ID, "type", inst.Type, "step", step.Name, "attempt", inst.Attempts, "max_attempts", maxAttempts, "error", stepErr)
+}
+
+func (o *Orchestrator) finish(inst *Instance, status Status) {
+	now := time.Now()
+	inst.Status = status
+	inst.FinishedAt = &now
+	inst.LockedUntil = nil
+	slog.Info("saga "+string(status), "saga_id", inst.ID, "type", inst.Type, "aggregate_id", inst.AggregateID)
+}
+
+func (o *Orchestrator) save(ctx context.Context, inst *Instance) error {
+	inst.UpdatedAt = time.Now()
+	// The saga is saved even when ctx is cancelled by a shutdown mid-step
+	return o.store.Save(context.WithoutCancel(ctx), inst)
+}
+
+// Retry resumes a parked saga where it stopped: a failed saga runs its failed step again,
+// one whose compensation failed compensates again.
+func (o *Orchestrator) Retry(ctx context.Context, id uuid.UUID) (*Instance, error) {
+	inst, err := o.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	switch inst.Status {
+	case StatusFailed:
+		inst.Status = StatusRunning
+		inst.Steps[inst.Step].Status = StepPending
+	case StatusCompensationFailed:
+		inst.Status = StatusCompensating
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidState, inst.Status)
+	}
+	o.resume(inst)
+	return inst, o.save(ctx, inst)
+}
+
+// Compensate undoes the completed steps of a failed or completed saga, last first.
+func (o *Orchestrator) Compensate(ctx context.Context, id uuid.UUID) (*Instance, error) {
+	inst, err := o.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if inst.Status != StatusFailed && inst.Status != StatusCompleted {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidState, inst.Status)
+	}
+	// The step that failed did not complete; compensation starts with the one before it
+	if inst.Step >= len(inst.Steps) || inst.Steps[inst.Step].Status != StepCompleted {
+		inst.Step--
+	}
+	inst.Status = StatusCompensating
+	inst.FinishedAt = nil
+	o.resume(inst)
+	return inst, o.save(ctx, inst)
+}
+
+func (o *Orchestrator) resume(inst *Instance) {
+	inst.Attempts = 0
+	inst.LastError = ""
+	inst.NextAttemptAt = time.Now()
+}
+
+// Backoff is the delay before the next attempt after the given number of failed ones:
+// base, doubling with every failure, capped at ceiling.
+func Backoff(attempts int, base, ceiling time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < ceiling; i++ {
+		delay *= 2
+	}
+	return min(delay, ceiling)
+}
@@ -0,0 +1,337 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type memoryStore struct {
+	mu    sync.Mutex
+	sagas map[uuid.UUID]Instance
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{sagas: make(map[uuid.UUID]Instance)}
+}
+
+func (s *memoryStore) Create(_ context.Context, inst *Instance) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.sagas {
+		if existing.Type == inst.Type && existing.AggregateID == inst.AggregateID {
+			return ErrExists
+		}
+	}
+	s.sagas[inst.ID] = clone(inst)
+	return nil
+}
+
+func (s *memoryStore) Claim(_ context.Context, now time.Time, lease time.Duration, limit int) ([]*Instance, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var claimed []*Instance
+	for id, inst := range s.sagas {
+		if len(claimed) == limit || !inst.Status.Active() || inst.NextAttemptAt.After(now) ||
+			inst.LockedUntil != nil && inst.LockedUntil.After(now) {
+			continue
+		}
+		lockedUntil := now.Add(lease)
+		inst.LockedUntil = &lockedUntil
+		s.sagas[id] = inst
+		c := clone(&inst)
+		claimed = append(claimed, &c)
+	}
+	return claimed, nil
+}
+
+func (s *memoryStore) Save(_ context.Context, inst *Instance) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sagas[inst.ID] = clone(inst)
+	return nil
+}
+
+func (s *memoryStore) Get(_ context.Context, id uuid.UUID) (*Instance, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inst, ok := s.sagas[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	c := clone(&inst)
+	return &c, nil
+}
+
+func (s *memoryStore) List(_ context.Context, filter Filter) ([]*Instance, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var list []*Instance
+	for _, inst := range s.sagas {
+		if filter.Stuck && !inst.Status.Parked() && !(inst.Status.Active() && inst.CreatedAt.Before(filter.StuckBefore)) {
+			continue
+		}
+		c := clone(&inst)
+		list = append(list, &c)
+	}
+	return list, int64(len(list)), nil
+}
+
+func clone(inst *Instance) Instance {
+	c := *inst
+	c.Steps = slices.Clone(inst.Steps)
+	return c
+}
+
+// recorder builds steps that log their runs and fail as told.
+type recorder struct {
+	mu    sync.Mutex
+	calls []string
+	fail  map[string]error
+}
+
+func (r *recorder) step(name string, compensate bool) Step {
+	run := func(call string) func(context.Context, uuid.UUID) error {
+		return func(context.Context, uuid.UUID) error {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.calls = append(r.calls, call)
+			return r.fail[call]
+		}
+	}
+	step := Step{Name: name, Do: run(name)}
+	if compensate {
+		step.Compensate = run("undo " + name)
+	}
+	return step
+}
+
+func (r *recorder) took() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	calls := strings.Join(r.calls, ", ")
+	r.calls = nil
+	return calls
+}
+
+func startSaga(t *testing.T, store Store, def Definition) *Instance {
+	t.Helper()
+	inst := def.New(uuid.New())
+	if err := store.Create(context.Background(), inst); err != nil {
+		t.Fatal(err)
+	}
+	return inst
+}
+
+func load(t *testing.T, store Store, id uuid.UUID) *Instance {
+	t.Helper()
+	inst, err := store.Get(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return inst
+}
+
+func TestOrchestratorRunsStepsInOrder(t *testing.T) {
+	store := newMemoryStore()
+	rec := &recorder{}
+	def := Definition{Type: "purchase", Steps: []Step{rec.step("enroll", true), rec.step("notify", false), rec.step("invoice", true)}}
+	o := New(store, Config{}, def)
+	inst := startSaga(t, store, def)
+
+	if err := store.Create(context.Background(), def.New(inst.AggregateID)); !errors.Is(err, ErrExists) {
+		t.Fatalf("second saga for the aggregate: %v", err)
+	}
+	if err := o.ProcessDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := rec.took(); got != "enroll, notify, invoice" {
+		t.Errorf("ran %q", got)
+	}
+	inst = load(t, store, inst.ID)
+	if inst.Status != StatusCompleted || inst.FinishedAt == nil || inst.LockedUntil != nil {
+		t.Errorf("saga is %s, finished %v, locked until %v", inst.Status, inst.FinishedAt, inst.LockedUntil)
+	}
+	for _, step := range inst.Steps {
+		if step.Status != StepCompleted {
+			t.Errorf("step %s is %s", step.Name, step.Status)
+		}
+	}
+}
+
+func TestOrchestratorRetriesThenParks(t *testing.T) {
+	store := newMemoryStore()
+	rec := &recorder{fail: map[string]error{"notify": errors.New("notification service unavailable")}}
+	def := Definition{Type: "purchase", Steps: []Step{rec.step("enroll", true), rec.step("notify", false), rec.step("invoice", true)}}
+	o := New(store, Config{MaxAttempts: 3, BackoffBase: time.Nanosecond, BackoffMax: time.Nanosecond}, def)
+	inst := startSaga(t, store, def)
+
+	for range 3 {
+		time.Sleep(time.Millisecond)
+		if err := o.ProcessDue(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := rec.took(); got != "enroll, notify, notify, notify" {
+		t.Errorf("ran %q", got)
+	}
+	inst = load(t, store, inst.ID)
+	if inst.Status != StatusFailed || inst.Step != 1 || inst.Steps[1].Status != StepFailed || inst.Steps[1].Attempts != 3 {
+		t.Fatalf("saga is %s at step %d: %+v", inst.Status, inst.Step, inst.Steps[1])
+	}
+	if !strings.Contains(inst.LastError, "unavailable") {
+		t.Errorf("last error %q", inst.LastError)
+	}
+
+	// A parked saga is left alone until an operator resumes it
+	if err := o.ProcessDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.took(); got != "" {
+		t.Errorf("parked saga ran %q", got)
+	}
+	stuck, _, _ := store.List(context.Background(), Filter{Stuck: true, StuckBefore: time.Now().Add(-time.Hour)})
+	if len(stuck) != 1 || stuck[0].ID != inst.ID {
+		t.Errorf("stuck sagas: %v", stuck)
+	}
+
+	delete(rec.fail, "notify")
+	if _, err := o.Retry(context.Background(), inst.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.ProcessDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.took(); got != "notify, invoice" {
+		t.Errorf("retry ran %q", got)
+	}
+	if inst = load(t, store, inst.ID); inst.Status != StatusCompleted {
+		t.Errorf("saga is %s after the retry", inst.Status)
+	}
+	if _, err := o.Retry(context.Background(), inst.ID); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("retry of a completed saga: %v", err)
+	}
+}
+
+func TestOrchestratorParksOnPermanentError(t *testing.T) {
+	store := newMemoryStore()
+	rec := &recorder{fail: map[string]error{"enroll": Permanent(errors.New("order was refunded"))}}
+	def := Definition{Type: "purchase", Steps: []Step{rec.step("enroll", true)}}
+	o := New(store, Config{}, def)
+	inst := startSaga(t, store, def)
+
+	if err := o.ProcessDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.took(); got != "enroll" {
+		t.Errorf("ran %q", got)
+	}
+	if inst = load(t, store, inst.ID); inst.Status != StatusFailed || inst.Attempts != 1 {
+		t.Errorf("saga is %s after %d attempts", inst.Status, inst.Attempts)
+	}
+}
+
+func TestOrchestratorCompensatesCompletedSteps(t *testing.T) {
+	store := newMemoryStore()
+	rec := &recorder{fail: map[string]error{"invoice": Permanent(errors.New("no billing address"))}}
+	def := Definition{Type: "purchase", Steps: []Step{rec.step("enroll", true), rec.step("notify", false), rec.step("invoice", true)}}
+	o := New(store, Config{}, def)
+	inst := startSaga(t, store, def)
+
+	if _, err := o.Compensate(context.Background(), inst.ID); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("compensating a running saga: %v", err)
+	}
+	if err := o.ProcessDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	rec.took()
+
+	// The failed invoice step did not complete, so only the enrollment is undone
+	if _, err := o.Compensate(context.Background(), inst.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.ProcessDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.took(); got != "undo enroll" {
+		t.Errorf("compensation ran %q", got)
+	}
+	inst = load(t, store, inst.ID)
+	if inst.Status != StatusCompensated || inst.Steps[0].Status != StepCompensated || inst.Steps[1].Status != StepCompleted {
+		t.Errorf("saga is %s with steps %+v", inst.Status, inst.Steps)
+	}
+}
+
+func TestOrchestratorCompensatesCompletedSaga(t *testing.T) {
+	store := newMemoryStore()
+	rec := &recorder{fail: map[string]error{"undo invoice": Permanent(errors.New("invoice already sent"))}}
+	def := Definition{Type: "purchase", Steps: []Step{rec.step("enroll", true), rec.step("invoice", true)}}
+	o := New(store, Config{}, def)
+	inst := startSaga(t, store, def)
+	if err := o.ProcessDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	rec.took()
+
+	if _, err := o.Compensate(context.Background(), inst.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.ProcessDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if inst = load(t, store, inst.ID); inst.Status != StatusCompensationFailed || inst.Step != 1 {
+		t.Fatalf("saga is %s at step %d", inst.Status, inst.Step)
+	}
+
+	delete(rec.fail, "undo invoice")
+	if _, err := o.Retry(context.Background(), inst.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.ProcessDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.took(); got != "undo invoice, undo invoice, undo enroll" {
+		t.Errorf("compensation ran %q", got)
+	}
+	if inst = load(t, store, inst.ID); inst.Status != StatusCompensated {
+		t.Errorf("saga is %s", inst.Status)
+	}
+}
+
+func TestOrchestratorTimesOutSteps(t *testing.T) {
+	store := newMemoryStore()
+	block := make(chan struct{})
+	defer close(block)
+	def := Definition{Type: "purchase", Steps: []Step{{
+		Name:        "enroll",
+		Timeout:     10 * time.Millisecond,
+		MaxAttempts: 1,
+		// Ignores its context, like a client without a deadline
+		Do: func(context.Context, uuid.UUID) error { <-block; return nil },
+	}}}
+	o := New(store, Config{}, def)
+	inst := startSaga(t, store, def)
+
+	if err := o.ProcessDue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	inst = load(t, store, inst.ID)
+	if inst.Status != StatusFailed || !strings.Contains(inst.LastError, "timed out after 10ms") {
+		t.Errorf("saga is %s: %s", inst.Status, inst.LastError)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempts, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 10: 5 * time.Second} {
+		if got := Backoff(attempts, time.Second, 5*time.Second); got != want {
+			t.Errorf("Backoff(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+// Package saga runs sagas: workflows spanning several services, written as explicit
+// steps that an Orchestrator drives one after the other.
+//
+// A service starts a saga by adding an Instance to its Store, in the same transaction as
+// the change that starts it. The Orchestrator polls the Store, leases the due sagas so
+// that other replicas skip them, and runs their steps. Each step runs under a timeout and
+// is retried with exponential backoff; a step that fails MaxAttempts times, or returns a
+// Permanent error, parks the saga as failed until an operator retries it or compensates
+// it. Compensating runs the Compensate function of the completed steps in reverse order.
+//
+// Steps may run more than once, after a timeout or a crash between a step and saving its
+// result, so they must be idempotent.
+//
+// The gormstore package keeps sagas in a Postgres table.
+package saga
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status is the state of a saga.
+type Status string
+
+const (
+	// StatusRunning sagas run their steps forward.
+	StatusRunning Status = "running"
+	// StatusCompleted sagas ran all their steps.
+	StatusCompleted Status = "completed"
+	// StatusFailed sagas have a step that failed for good; they wait for an operator.
+	StatusFailed Status = "failed"
+	// StatusCompensating sagas undo their completed steps.
+	StatusCompensating Status = "compensating"
+	// StatusCompensated sagas undid their completed steps.
+	StatusCompensated Status = "compensated"
+	// StatusCompensationFailed sagas have a compensation that failed for good; they wait
+	// for an operator.
+	StatusCompensationFailed Status = "compensation_failed"
+)
+
+// Active reports whether the Orchestrator works on sagas of status s.
+func (s Status) Active() bool {
+	return s == StatusRunning || s == StatusCompensating
+}
+
+// Parked reports whether sagas of status s wait for an operator.
+func (s Status) Parked() bool {
+	return s == StatusFailed || s == StatusCompensationFailed
+}
+
+// StepStatus is the state of a step of a saga.
+type StepStatus string
+
+const (
+	StepPending     StepStatus = "pending"
+	StepCompleted   StepStatus = "completed"
+	StepFailed      StepStatus = "failed"
+	StepCompensated StepStatus = "compensated"
+)
+
+// StepState records the progress of a step. Attempts counts the failed runs of the step
+// and of its compensation.
+type StepState struct {
+	Name      string     `json:"name"`
+	Status    StepStatus `json:"status"`
+	Attempts  int        `json:"attempts"`
+	LastError string     `json:"last_error,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// Instance is a saga run for an aggregate, such as an order. A saga type runs at most
+// once per aggregate.
+type Instance struct {
+	ID          uuid.UUID `json:"id"`
+	Type        string    `json:"type"`
+	AggregateID uuid.UUID `json:"aggregate_id"`
+	Status      Status    `json:"status"`
+	// Step is the index of the step being run, or compensated while compensating; it is
+	// len(Steps) once all steps completed
+	Step  int         `json:"step"`
+	Steps []StepState `json:"steps"`
+	// Attempts counts the failures of the current step, or of its compensation, since the
+	// saga last made progress
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     string     `json:"last_error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`
+	// LockedUntil is the end of the lease of the Orchestrator running the saga
+	LockedUntil *time.Time `json:"-"`
+}
+
+// Step is one step of a saga. Do and Compensate receive the aggregate of the saga.
+type Step struct {
+	Name string
+	Do   func(ctx context.Context, aggregateID uuid.UUID) error
+	// Compensate undoes Do; it is optional for steps with nothing to undo
+	Compensate func(ctx context.Context, aggregateID uuid.UUID) error
+	// Timeout bounds each run of Do and Compensate; 0 uses Config.StepTimeout
+	Timeout time.Duration
+	// MaxAttempts is how many times the step runs before the saga is parked; 0 uses
+	// Config.MaxAttempts
+	MaxAttempts int
+}
+
+// Definition is a type of saga and its steps, in the order they run.
+type Definition struct {
+	Type  string
+	Steps []Step
+}
+
+// New returns an instance of the saga for an aggregate, due at once, to be added to a
+// Store.
+func (d Definition) New(aggregateID uuid.UUID) *Instance {
+	now := time.Now()
+	steps := make([]StepState, len(d.Steps))
+	for i, step := range d.Steps {
+		steps[i] = StepState{Name: step.Name, Status: StepPending}
+	}
+	return &Instance{
+		ID:            uuid.New(),
+		Type:          d.Type,
+		AggregateID:   aggregateID,
+		Status:        StatusRunning,
+		Steps:         steps,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+var (
+	// ErrExists is returned by Store.Create for a saga type already started for the
+	// aggregate.
+	ErrExists = errors.New("saga already started")
+	// ErrNotFound is returned for an unknown saga.
+	ErrNotFound = errors.New("saga not found")
+	// ErrInvalidState is returned by Retry and Compensate for a saga in another state.
+	ErrInvalidState = errors.New("saga cannot be changed in its current state")
+)
+
+// Filter selects sagas to list. Zero fields match every saga.
+type Filter struct {
+	Type        string
+	AggregateID uuid.UUID
+	Status      Status
+	// Stuck selects the parked sagas and the active ones created before StuckBefore
+	Stuck       bool
+	StuckBefore time.Time
+	Limit       int
+	Offset      int
+}
+
+// Store persists sagas. Create must write through the caller's transaction when there is
+// one: see gormstore.Store.WithTx.
+type Store interface {
+	// Create adds a saga, or returns ErrExists when its type already ran for the
+	// aggregate. It must not abort the caller's transaction in that case.
+	Create(ctx context.Context, inst *Instance) error
+	// Claim leases up to limit active sagas due at now until now+lease, oldest due first,
+	// skipping those leased by another Orchestrator.
+	Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*Instance, error)
+	// Save writes the state of inst, including its lease.
+	Save(ctx context.Context, inst *Instance) error
+	Get(ctx context.Context, id uuid.UUID) (*Instance, error)
+	// List returns the sagas matching filter, newest first, and how many match in all.
+	List(ctx context.Context, filter Filter) ([]*Instance, int64, error)
+}
+
+// permanentError marks a step error that retrying cannot fix.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the step is not retried and the saga is parked at once.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was wrapped by Permanent.
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
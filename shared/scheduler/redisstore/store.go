@@ -0,0 +1,91 @@
+// Package redisstore keeps the scheduler locks and run history in Redis, so every
+// instance of a service sees the same locks. A lock is a key holding the token of its
+// owner with a TTL; it is extended and released by Lua scripts that check the token, so
+// an instance never frees a lock another one took over. The history of a job is a list
+// of JSON runs, most recent first, trimmed on every write. It is a separate module so
+// services without Redis do not pull in the Redis client.
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/scheduler"
+	"github.com/redis/go-redis/v9"
+)
+
+// extend sets the TTL of KEYS[1] to ARGV[2] milliseconds when it holds the token
+// ARGV[1]. Returns 1 when extended, 0 when the lock is held by another token or expired.
+var extend = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// release deletes KEYS[1] when it holds the token ARGV[1].
+var release = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// Store implements scheduler.Store.
+type Store struct {
+	client redis.UniversalClient
+}
+
+var _ scheduler.Store = (*Store)(nil)
+
+// New returns a Store keeping the locks and the history in client.
+func New(client redis.UniversalClient) *Store {
+	return &Store{client: client}
+}
+
+func (s *Store) Acquire(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, key, token, ttl).Result()
+}
+
+func (s *Store) Extend(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	extended, err := extend.Run(ctx, s.client, []string{key}, token, max(1, ttl.Milliseconds())).Int()
+	return extended == 1, err
+}
+
+func (s *Store) Release(ctx context.Context, key, token string) error {
+	return release.Run(ctx, s.client, []string{key}, token).Err()
+}
+
+func (s *Store) Record(ctx context.Context, key string, run scheduler.Run, keep int) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LPush(ctx, key, data)
+		pipe.LTrim(ctx, key, 0, int64(keep-1))
+		return nil
+	})
+	return err
+}
+
+func (s *Store) Runs(ctx context.Context, key string, limit int) ([]scheduler.Run, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	items, err := s.client.LRange(ctx, key, 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]scheduler.Run, 0, len(items))
+	for _, item := range items {
+		var run scheduler.Run
+		if err := json.Unmarshal([]byte(item), &run); err != nil {
+			return nil, fmt.Errorf("invalid run in %s: %w", key, err)
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
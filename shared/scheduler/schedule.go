@@ -0,0 +1,221 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule computes when a job runs.
+type Schedule interface {
+	// Next returns the first time after t the job runs, the zero time when it never
+	// runs again
+	Next(t time.Time) time.Time
+	String() string
+}
+
+// Every runs a job every d, at multiples of d since the zero time, so every instance
+// picks the same times: Every(time.Hour) runs on the hour and Every(24*time.Hour) at
+// midnight UTC. It panics when d is not positive, like time.NewTicker.
+func Every(d time.Duration) Schedule {
+	if d <= 0 {
+		panic("scheduler: non-positive interval for Every")
+	}
+	return every(d)
+}
+
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Truncate(time.Duration(e)).Add(time.Duration(e))
+}
+
+func (e every) String() string {
+	return "@every " + time.Duration(e).String()
+}
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse reads a schedule: a cron expression of five fields (minute, hour, day of month,
+// month, day of week) such as "*/5 * * * *" or "30 2 * * 1-5", a descriptor such as
+// "@daily", or "@every <duration>" such as "@every 90s". A field is "*", a value, a
+// range "a-b" or a comma separated list of them, each optionally stepped with "/n".
+// Sunday is 0 or 7. As in cron, a day matching either the day of month or the day of
+// week runs the job when both are restricted.
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("scheduler: invalid interval in %q", spec)
+		}
+		return every(d), nil
+	}
+	expr := spec
+	if strings.HasPrefix(spec, "@") {
+		var ok bool
+		if expr, ok = descriptors[spec]; !ok {
+			return nil, fmt.Errorf("scheduler: unknown descriptor %q", spec)
+		}
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("scheduler: %q: want 5 fields, got %d", spec, len(fields))
+	}
+	c := &cron{spec: spec}
+	var err error
+	if c.minute, err = parseField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("scheduler: %q: minute: %w", spec, err)
+	}
+	if c.hour, err = parseField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("scheduler: %q: hour: %w", spec, err)
+	}
+	if c.dom, err = parseField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("scheduler: %q: day of month: %w", spec, err)
+	}
+	if c.month, err = parseField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("scheduler: %q: month: %w", spec, err)
+	}
+	if c.dow, err = parseField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("scheduler: %q: day of week: %w", spec, err)
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	c.anyDay = strings.HasPrefix(fields[2], "*") || strings.HasPrefix(fields[4], "*")
+	return c, nil
+}
+
+// MustParse is Parse for schedules known to be valid, such as constants. It panics on
+// an invalid spec.
+func MustParse(spec string) Schedule {
+	s, err := Parse(spec)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// parseField returns the values of field as a bit set.
+func parseField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepText, stepped := strings.Cut(part, "/")
+		step := 1
+		if stepped {
+			var err error
+			if step, err = strconv.Atoi(stepText); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepText)
+			}
+		}
+
+		lo, hi := min, max
+		if rng != "*" {
+			loText, hiText, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = strconv.Atoi(loText); err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(hiText); err != nil {
+					return 0, fmt.Errorf("invalid value %q", part)
+				}
+			} else if stepped {
+				// "5/15" is every 15 from 5
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q is outside %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// cron is a parsed cron expression; each field is a bit set of its values.
+type cron struct {
+	spec                          string
+	minute, hour, dom, month, dow uint64
+	// anyDay is set when the day of month or the day of week is "*", so a day must match
+	// both instead of either
+	anyDay bool
+}
+
+// maxSearch bounds the search of Next, so an expression that never matches, such as
+// February 30th, returns the zero time.
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+func (c *cron) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxSearch)
+	for t.Before(limit) {
+		year, month, day := t.Date()
+		switch {
+		case c.month&(1<<uint(month)) == 0:
+			t = time.Date(year, month+1, 1, 0, 0, 0, 0, loc)
+		case !c.dayMatches(t):
+			t = time.Date(year, month, day+1, 0, 0, 0, 0, loc)
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(year, month, day, t.Hour()+1, 0, 0, 0, loc)
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (c *cron) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.anyDay {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+func (c *cron) String() string {
+	return c.spec
+}
+
+// Spec is a schedule read from the configuration, so an invalid schedule fails the
+// configuration load:
+//
+//	ExpiredOrders scheduler.Spec `env:"EXPIRED_ORDERS_SCHEDULE" envDefault:"*/5 * * * *"`
+type Spec struct {
+	Schedule
+}
+
+// UnmarshalText parses the schedule with Parse.
+func (s *Spec) UnmarshalText(text []byte) error {
+	schedule, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	s.Schedule = schedule
+	return nil
+}
+
+// MarshalText returns the spec the schedule was parsed from.
+func (s Spec) MarshalText() ([]byte, error) {
+	if s.Schedule == nil {
+		return nil, nil
+	}
+	return []byte(s.String()), nil
+}
@@ -0,0 +1,96 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	from := time.Date(2025, time.March, 14, 10, 7, 30, 0, time.UTC) // a Friday
+	for _, tc := range []struct {
+		spec string
+		want time.Time
+	}{
+		{"*/5 * * * *", time.Date(2025, time.March, 14, 10, 10, 0, 0, time.UTC)},
+		{"7 * * * *", time.Date(2025, time.March, 14, 11, 7, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2025, time.March, 15, 2, 30, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2025, time.March, 17, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, time.March, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2025, time.March, 15, 0, 0, 0, 0, time.UTC)},
+		// Both days restricted: the 1st or a Monday, whichever comes first
+		{"0 0 1 * 1", time.Date(2025, time.March, 17, 0, 0, 0, 0, time.UTC)},
+		{"15/20 10 * * *", time.Date(2025, time.March, 14, 10, 15, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2025, time.March, 14, 11, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 10m", time.Date(2025, time.March, 14, 10, 10, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	} {
+		schedule, err := Parse(tc.spec)
+		if err != nil {
+			t.Errorf("%q: %v", tc.spec, err)
+			continue
+		}
+		if got := schedule.Next(from); !got.Equal(tc.want) {
+			t.Errorf("%q: next after %v = %v, want %v", tc.spec, from, got, tc.want)
+		}
+		if schedule.String() != tc.spec && tc.spec != "@every 10m" {
+			t.Errorf("%q: String = %q", tc.spec, schedule.String())
+		}
+	}
+
+	for _, bad := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"@sometimes",
+		"@every -1m",
+		"@every soon",
+	} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("%q: want an error", bad)
+		}
+	}
+}
+
+func TestNextInLocation(t *testing.T) {
+	hcm, err := time.LoadLocation("Asia/Ho_Chi_Minh")
+	if err != nil {
+		t.Skip(err)
+	}
+	from := time.Date(2025, time.March, 14, 23, 0, 0, 0, time.UTC) // 06:00 on the 15th in Ho Chi Minh City
+	got := MustParse("30 6 * * *").Next(from.In(hcm))
+	if want := time.Date(2025, time.March, 15, 6, 30, 0, 0, hcm); !got.Equal(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestEvery(t *testing.T) {
+	at := time.Date(2025, time.March, 14, 10, 7, 30, 0, time.UTC)
+	if got := Every(time.Hour).Next(at); !got.Equal(time.Date(2025, time.March, 14, 11, 0, 0, 0, time.UTC)) {
+		t.Errorf("Every(time.Hour) = %v", got)
+	}
+	if got := Every(time.Hour).Next(at.Truncate(time.Hour)); !got.Equal(at.Truncate(time.Hour).Add(time.Hour)) {
+		t.Errorf("Every(time.Hour) on the hour = %v, want the next hour", got)
+	}
+}
+
+func TestSpec(t *testing.T) {
+	var spec Spec
+	if err := spec.UnmarshalText([]byte("*/5 * * * *")); err != nil {
+		t.Fatal(err)
+	}
+	if text, _ := spec.MarshalText(); string(text) != "*/5 * * * *" {
+		t.Errorf("MarshalText = %q", text)
+	}
+	if err := spec.UnmarshalText([]byte("every five minutes")); err == nil {
+		t.Error("invalid spec: want an error")
+	}
+}
@@ -0,0 +1,313 @@
+// Package scheduler runs periodic jobs, such as cancelling expired orders or purging old
+// records, on a cron-style schedule. Every instance of a service runs the scheduler, and
+// a lock in a shared Store makes one instance run each occurrence of a job: the first
+// to take the lock runs it, the others skip it. The lock is renewed while the job runs,
+// so a slow job is not started twice, and expires on its own, so the job moves to
+// another instance when the one holding it dies. Runs are kept in a history and counted
+// in the scheduler_* metrics.
+//
+// The Redis store is the separate module scheduler/redisstore, so services without
+// Redis do not pull in the Redis client.
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// DefaultPrefix starts the store keys of a Scheduler.
+const DefaultPrefix = "scheduler"
+
+const (
+	// DefaultLockTTL is how long a lock outlives an instance that stopped renewing it
+	DefaultLockTTL = 30 * time.Second
+	// DefaultHistory is the number of runs kept per job
+	DefaultHistory = 50
+)
+
+// Job is a periodic task.
+type Job struct {
+	// Name identifies the job in the store keys, logs and metrics, such as
+	// "orders.expire"
+	Name     string
+	Schedule Schedule
+	Run      func(ctx context.Context) error
+	// Timeout cancels the context of a run after this long, 0 for no limit
+	Timeout time.Duration
+}
+
+// Validate reports whether the job can be scheduled.
+func (j Job) Validate() error {
+	if j.Name == "" {
+		return errors.New("scheduler: job name is required")
+	}
+	if j.Schedule == nil || j.Run == nil {
+		return fmt.Errorf("scheduler: job %s: schedule and run are required", j.Name)
+	}
+	if j.Timeout < 0 {
+		return fmt.Errorf("scheduler: job %s: timeout must not be negative", j.Name)
+	}
+	return nil
+}
+
+// Config configures a Scheduler.
+type Config struct {
+	// Prefix starts every store key, DefaultPrefix when empty. Services sharing a Redis
+	// need their own prefix, or distinct job names.
+	Prefix string
+	// Instance names this instance in the history, the hostname when empty
+	Instance string
+	// Location is the time zone of the cron schedules, UTC when nil
+	Location *time.Location
+	// LockTTL is how long a lock outlives an instance that stopped renewing it,
+	// DefaultLockTTL when 0
+	LockTTL time.Duration
+	// History is the number of runs kept per job, DefaultHistory when 0
+	History int
+}
+
+var (
+	runsTotal = promauto.With(metrics.Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "scheduler_job_runs_total",
+		Help: "Occurrences of scheduled jobs, by outcome (succeeded, failed, skipped when another instance ran it, or error when the lock store failed).",
+	}, []string{"job", "outcome"})
+	runDuration = promauto.With(metrics.Default).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "scheduler_job_duration_seconds",
+		Help:    "Time taken by the runs of scheduled jobs.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"job"})
+	lastSuccess = promauto.With(metrics.Default).NewGaugeVec(prometheus.GaugeOpts{
+		Name: "scheduler_job_last_success_timestamp_seconds",
+		Help: "Unix time of the last successful run of a scheduled job by this instance.",
+	}, []string{"job"})
+)
+
+// Scheduler runs registered jobs on their schedule.
+type Scheduler struct {
+	store Store
+	cfg   Config
+	now   func() time.Time
+
+	mu      sync.Mutex
+	jobs    []Job
+	running bool
+}
+
+// New returns a scheduler locking jobs in store.
+func New(store Store, cfg Config) *Scheduler {
+	if cfg.Prefix == "" {
+		cfg.Prefix = DefaultPrefix
+	}
+	if cfg.Instance == "" {
+		cfg.Instance, _ = os.Hostname()
+	}
+	if cfg.Location == nil {
+		cfg.Location = time.UTC
+	}
+	if cfg.LockTTL <= 0 {
+		cfg.LockTTL = DefaultLockTTL
+	}
+	if cfg.History <= 0 {
+		cfg.History = DefaultHistory
+	}
+	return &Scheduler{store: store, cfg: cfg, now: time.Now}
+}
+
+// Register adds job. Jobs are registered before Run; names must be unique.
+func (s *Scheduler) Register(job Job) error {
+	if err := job.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return fmt.Errorf("scheduler: job %s registered after Run", job.Name)
+	}
+	for _, registered := range s.jobs {
+		if registered.Name == job.Name {
+			return fmt.Errorf("scheduler: job %s is already registered", job.Name)
+		}
+	}
+	s.jobs = append(s.jobs, job)
+	return nil
+}
+
+// Jobs returns the registered jobs.
+func (s *Scheduler) Jobs() []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Job(nil), s.jobs...)
+}
+
+// Runs returns up to limit runs of the job name, by any instance, most recent first.
+func (s *Scheduler) Runs(ctx context.Context, name string, limit int) ([]Run, error) {
+	return s.store.Runs(ctx, s.key("runs", name), limit)
+}
+
+// Run runs the jobs until ctx is cancelled, which also cancels the runs in flight. An
+// occurrence due while the previous run of the job is still going is skipped.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	s.running = true
+	jobs := append([]Job(nil), s.jobs...)
+	s.mu.Unlock()
+
+	slog.Info("scheduler started", "jobs", len(jobs), "instance", s.cfg.Instance)
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.loop(ctx, job)
+		}()
+	}
+	wg.Wait()
+	slog.Info("scheduler stopped")
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	for {
+		now := s.now()
+		next := job.Schedule.Next(now.In(s.cfg.Location))
+		if next.IsZero() {
+			slog.Warn("scheduled job never runs again", "job", job.Name, "schedule", job.Schedule.String())
+			return
+		}
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.runOnce(ctx, job, next)
+	}
+}
+
+// runOnce runs the occurrence of job due at scheduledAt, when this instance gets the
+// lock of the job.
+func (s *Scheduler) runOnce(ctx context.Context, job Job, scheduledAt time.Time) {
+	key := s.key("lock", job.Name)
+	token := s.cfg.Instance + ":" + randomToken()
+	// The bookkeeping outlives a cancelled ctx, so the history and the lock are left
+	// consistent on shutdown
+	bookkeeping := context.WithoutCancel(ctx)
+
+	acquired, err := s.store.Acquire(ctx, key, token, s.cfg.LockTTL)
+	if err != nil {
+		runsTotal.WithLabelValues(job.Name, "error").Inc()
+		slog.ErrorContext(ctx, "failed to lock scheduled job", "job", job.Name, "error", err)
+		return
+	}
+	if !acquired {
+		runsTotal.WithLabelValues(job.Name, "skipped").Inc()
+		slog.DebugContext(ctx, "scheduled job run by another instance", "job", job.Name, "scheduled_at", scheduledAt)
+		return
+	}
+
+	var runCtx context.Context
+	var cancel context.CancelFunc
+	if job.Timeout > 0 {
+		runCtx, cancel = context.WithTimeout(ctx, job.Timeout)
+	} else {
+		runCtx, cancel = context.WithCancel(ctx)
+	}
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		s.renew(runCtx, cancel, job, key, token)
+	}()
+
+	start := s.now()
+	err = call(runCtx, job)
+	elapsed := s.now().Sub(start)
+	cancel()
+	<-renewed
+
+	run := Run{Job: job.Name, Instance: s.cfg.Instance, ScheduledAt: scheduledAt, StartedAt: start, Duration: elapsed}
+	runDuration.WithLabelValues(job.Name).Observe(elapsed.Seconds())
+	if err != nil {
+		run.Error = err.Error()
+		runsTotal.WithLabelValues(job.Name, "failed").Inc()
+		slog.ErrorContext(ctx, "scheduled job failed", "job", job.Name, "duration", elapsed, "error", err)
+	} else {
+		runsTotal.WithLabelValues(job.Name, "succeeded").Inc()
+		lastSuccess.WithLabelValues(job.Name).Set(float64(s.now().Unix()))
+		slog.InfoContext(ctx, "scheduled job finished", "job", job.Name, "duration", elapsed)
+	}
+	if err := s.store.Record(bookkeeping, s.key("runs", job.Name), run, s.cfg.History); err != nil {
+		slog.WarnContext(ctx, "failed to record scheduled job run", "job", job.Name, "error", err)
+	}
+
+	// The lock is kept until halfway to the next occurrence, so an instance whose clock
+	// is a little behind does not run this occurrence again once it is done
+	hold := scheduledAt.Add(job.Schedule.Next(scheduledAt).Sub(scheduledAt) / 2).Sub(s.now())
+	if hold > 0 {
+		_, err = s.store.Extend(bookkeeping, key, token, hold)
+	} else {
+		err = s.store.Release(bookkeeping, key, token)
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "failed to release scheduled job lock", "job", job.Name, "error", err)
+	}
+}
+
+// renew extends the lock of a run until ctx is done. A run whose lock is lost is
+// cancelled, as another instance may take the job over.
+func (s *Scheduler) renew(ctx context.Context, cancel context.CancelFunc, job Job, key, token string) {
+	ticker := time.NewTicker(s.cfg.LockTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			held, err := s.store.Extend(ctx, key, token, s.cfg.LockTTL)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				// Kept running: the lock still has until its TTL to be renewed
+				slog.WarnContext(ctx, "failed to renew scheduled job lock", "job", job.Name, "error", err)
+				continue
+			}
+			if !held {
+				slog.ErrorContext(ctx, "scheduled job lost its lock; cancelling the run", "job", job.Name)
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// call runs job, turning a panic into an error so it cannot stop the scheduler.
+func call(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+func (s *Scheduler) key(kind, name string) string {
+	return s.cfg.Prefix + ":" + kind + ":" + name
+}
+
+func randomToken() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,159 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRegister(t *testing.T) {
+	s := New(NewMemoryStore(), Config{})
+	run := func(context.Context) error { return nil }
+	if err := s.Register(Job{Name: "orders.expire", Schedule: Every(time.Minute), Run: run}); err != nil {
+		t.Fatal(err)
+	}
+	for _, bad := range []Job{
+		{Name: "orders.expire", Schedule: Every(time.Minute), Run: run},
+		{Schedule: Every(time.Minute), Run: run},
+		{Name: "orders.purge", Run: run},
+		{Name: "orders.purge", Schedule: Every(time.Minute)},
+		{Name: "orders.purge", Schedule: Every(time.Minute), Run: run, Timeout: -time.Second},
+	} {
+		if err := s.Register(bad); err == nil {
+			t.Errorf("%+v: want an error", bad)
+		}
+	}
+	if jobs := s.Jobs(); len(jobs) != 1 || jobs[0].Name != "orders.expire" {
+		t.Errorf("Jobs = %v", jobs)
+	}
+}
+
+// TestOneInstancePerOccurrence runs the same job on three schedulers sharing a store:
+// each occurrence runs once.
+func TestOneInstancePerOccurrence(t *testing.T) {
+	store := NewMemoryStore()
+	var runs atomic.Int32
+	ctx, cancel := context.WithTimeout(context.Background(), 350*time.Millisecond)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, instance := range []string{"a", "b", "c"} {
+		s := New(store, Config{Instance: instance})
+		err := s.Register(Job{Name: "rollup", Schedule: Every(100 * time.Millisecond), Run: func(context.Context) error {
+			runs.Add(1)
+			return nil
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Run(ctx)
+		}()
+	}
+	wg.Wait()
+
+	history, err := New(store, Config{}).Runs(context.Background(), "rollup", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) < 2 || len(history) > 4 || int(runs.Load()) != len(history) {
+		t.Fatalf("%d runs, history %v", runs.Load(), history)
+	}
+	seen := make(map[time.Time]string)
+	for _, run := range history {
+		if other, ok := seen[run.ScheduledAt]; ok {
+			t.Errorf("occurrence %v ran on %s and %s", run.ScheduledAt, other, run.Instance)
+		}
+		seen[run.ScheduledAt] = run.Instance
+		if !run.Succeeded() {
+			t.Errorf("run failed: %s", run.Error)
+		}
+	}
+}
+
+func TestFailures(t *testing.T) {
+	store := NewMemoryStore()
+	s := New(store, Config{Instance: "a"})
+	s.Register(Job{Name: "fails", Schedule: Every(time.Hour), Run: func(context.Context) error {
+		return errors.New("database unavailable")
+	}})
+	s.Register(Job{Name: "panics", Schedule: Every(time.Hour), Run: func(context.Context) error {
+		panic("nil map")
+	}})
+	s.Register(Job{Name: "slow", Schedule: Every(time.Hour), Timeout: 10 * time.Millisecond, Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+
+	for _, job := range s.Jobs() {
+		s.runOnce(context.Background(), job, time.Now())
+		runs, err := s.Runs(context.Background(), job.Name, 10)
+		if err != nil || len(runs) != 1 || runs[0].Succeeded() {
+			t.Errorf("%s: got %v, %v", job.Name, runs, err)
+		}
+	}
+	if runs, _ := s.Runs(context.Background(), "panics", 1); len(runs) == 1 && runs[0].Error != "panic: nil map" {
+		t.Errorf("panic recorded as %q", runs[0].Error)
+	}
+}
+
+func TestLostLockCancelsTheRun(t *testing.T) {
+	store := NewMemoryStore()
+	s := New(store, Config{Instance: "a", LockTTL: 30 * time.Millisecond})
+	s.Register(Job{Name: "purge", Schedule: Every(time.Hour), Run: func(ctx context.Context) error {
+		// Another instance takes the lock over, as if this one had stalled past its TTL
+		store.mu.Lock()
+		store.locks["scheduler:lock:purge"] = lock{token: "b", expiresAt: time.Now().Add(time.Hour)}
+		store.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+			return nil
+		}
+	}})
+
+	s.runOnce(context.Background(), s.Jobs()[0], time.Now())
+	runs, _ := s.Runs(context.Background(), "purge", 1)
+	if len(runs) != 1 || runs[0].Error != context.Canceled.Error() {
+		t.Errorf("got %v", runs)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	if ok, _ := store.Acquire(ctx, "k", "a", time.Second); !ok {
+		t.Fatal("a: lock not acquired")
+	}
+	if ok, _ := store.Acquire(ctx, "k", "b", time.Second); ok {
+		t.Error("b: acquired a held lock")
+	}
+	if ok, _ := store.Extend(ctx, "k", "b", time.Second); ok {
+		t.Error("b: extended a lock it does not hold")
+	}
+	store.Release(ctx, "k", "b")
+	now = now.Add(2 * time.Second)
+	if ok, _ := store.Extend(ctx, "k", "a", time.Second); ok {
+		t.Error("a: extended an expired lock")
+	}
+	if ok, _ := store.Acquire(ctx, "k", "b", time.Second); !ok {
+		t.Error("b: expired lock not acquired")
+	}
+
+	for i := range 5 {
+		store.Record(ctx, "runs", Run{Job: "rollup", Duration: time.Duration(i)}, 3)
+	}
+	runs, _ := store.Runs(ctx, "runs", 10)
+	if len(runs) != 3 || runs[0].Duration != 4 || runs[2].Duration != 2 {
+		t.Errorf("runs = %v", runs)
+	}
+}
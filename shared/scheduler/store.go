@@ -0,0 +1,123 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Run is a run of a job, kept in its history.
+type Run struct {
+	Job      string `json:"job"`
+	Instance string `json:"instance"`
+	// ScheduledAt is the time the run was due, StartedAt the time it started
+	ScheduledAt time.Time     `json:"scheduled_at"`
+	StartedAt   time.Time     `json:"started_at"`
+	Duration    time.Duration `json:"duration"`
+	// Error is the error the job returned or its panic, empty when it succeeded
+	Error string `json:"error,omitempty"`
+}
+
+// Succeeded reports whether the job returned no error.
+func (r Run) Succeeded() bool {
+	return r.Error == ""
+}
+
+// Store holds the locks that keep the instances of a service from running a job at the
+// same time, and the history of the runs. Locks expire on their own, so a lock of an
+// instance that died is taken over once its TTL passes.
+type Store interface {
+	// Acquire takes the lock key for ttl with token, and returns false when another
+	// token holds it
+	Acquire(ctx context.Context, key, token string, ttl time.Duration) (bool, error)
+	// Extend makes the lock key held with token expire ttl from now, and returns false
+	// when token no longer holds it
+	Extend(ctx context.Context, key, token string, ttl time.Duration) (bool, error)
+	// Release frees the lock key when token holds it
+	Release(ctx context.Context, key, token string) error
+	// Record adds run to the history key, keeping the keep most recent runs
+	Record(ctx context.Context, key string, run Run, keep int) error
+	// Runs returns up to limit runs of the history key, most recent first
+	Runs(ctx context.Context, key string, limit int) ([]Run, error)
+}
+
+// MemoryStore keeps the locks and the history in memory, for tests and services running
+// a single instance. It follows the same rules as redisstore.
+type MemoryStore struct {
+	mu    sync.Mutex
+	now   func() time.Time
+	locks map[string]lock
+	runs  map[string][]Run
+}
+
+type lock struct {
+	token     string
+	expiresAt time.Time
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore returns an empty store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		now:   time.Now,
+		locks: make(map[string]lock),
+		runs:  make(map[string][]Run),
+	}
+}
+
+func (s *MemoryStore) Acquire(_ context.Context, key, token string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if l, ok := s.locks[key]; ok && now.Before(l.expiresAt) {
+		return false, nil
+	}
+	s.locks[key] = lock{token: token, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+func (s *MemoryStore) Extend(_ context.Context, key, token string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	l, ok := s.locks[key]
+	if !ok || l.token != token || !now.Before(l.expiresAt) {
+		return false, nil
+	}
+	s.locks[key] = lock{token: token, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+func (s *MemoryStore) Release(_ context.Context, key, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if l, ok := s.locks[key]; ok && l.token == token {
+		delete(s.locks, key)
+	}
+	return nil
+}
+
+func (s *MemoryStore) Record(_ context.Context, key string, run Run, keep int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	runs := append([]Run{run}, s.runs[key]...)
+	if len(runs) > keep {
+		runs = runs[:keep]
+	}
+	s.runs[key] = runs
+	return nil
+}
+
+func (s *MemoryStore) Runs(_ context.Context, key string, limit int) ([]Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if limit <= 0 {
+		return nil, nil
+	}
+	runs := s.runs[key]
+	if limit < len(runs) {
+		runs = runs[:limit]
+	}
+	return append([]Run(nil), runs...), nil
+}
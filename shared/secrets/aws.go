@@ -0,0 +1,132 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	smtypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// AWSConfig holds the region and endpoint of the AWS providers. Credentials come from
+// the SDK's default chain: the AWS_* variables, the shared config files, the web
+// identity token of an EKS service account, the ECS task role or the EC2 instance
+// profile. Static keys set here take precedence over the chain.
+type AWSConfig struct {
+	// Region is read by the SDK from AWS_REGION or the shared config files when empty
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Endpoint overrides the endpoint of the service, such as for LocalStack
+	Endpoint string
+}
+
+// load resolves the SDK configuration of cfg, sending requests with client when it is
+// not nil.
+func (cfg AWSConfig) load(ctx context.Context, client *http.Client) (aws.Config, error) {
+	var opts []func(*awsconfig.LoadOptions) error
+	if cfg.Region != "" {
+		opts = append(opts, awsconfig.WithRegion(cfg.Region))
+	}
+	if cfg.AccessKeyID != "" {
+		opts = append(opts, awsconfig.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(cfg.AccessKeyID, cfg.SecretAccessKey, cfg.SessionToken)))
+	}
+	if client != nil {
+		opts = append(opts, awsconfig.WithHTTPClient(client))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("secrets: load AWS configuration: %w", err)
+	}
+	if awsCfg.Region == "" {
+		return aws.Config{}, errors.New("secrets: AWS_REGION is required by the AWS providers")
+	}
+	return awsCfg, nil
+}
+
+// endpoint returns the client option overriding the endpoint, if any.
+func (cfg AWSConfig) endpoint() *string {
+	if cfg.Endpoint == "" {
+		return nil
+	}
+	return aws.String(cfg.Endpoint)
+}
+
+// SecretsManager reads secrets from AWS Secrets Manager. A secret stored as key/value
+// pairs is a JSON object; a reference selects one, as in order-services/stripe#secret_key.
+type SecretsManager struct {
+	client *secretsmanager.Client
+}
+
+// NewSecretsManager returns a provider reading Secrets Manager with client, the SDK's
+// default HTTP client when nil.
+func NewSecretsManager(ctx context.Context, cfg AWSConfig, client *http.Client) (*SecretsManager, error) {
+	awsCfg, err := cfg.load(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretsManager{client: secretsmanager.NewFromConfig(awsCfg, func(o *secretsmanager.Options) {
+		o.BaseEndpoint = cfg.endpoint()
+	})}, nil
+}
+
+// GetSecret implements Provider. name is the name or ARN of the secret.
+func (s *SecretsManager) GetSecret(ctx context.Context, name string) (string, error) {
+	out, err := s.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(name)})
+	if err != nil {
+		var notFound *smtypes.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return "", fmt.Errorf("secrets: secretsmanager: %w", err)
+	}
+	if out.SecretString != nil {
+		return *out.SecretString, nil
+	}
+	return base64.StdEncoding.EncodeToString(out.SecretBinary), nil
+}
+
+// ParameterStore reads the parameters of AWS Systems Manager Parameter Store,
+// decrypting SecureString parameters.
+type ParameterStore struct {
+	client *ssm.Client
+}
+
+// NewParameterStore returns a provider reading Parameter Store with client, the SDK's
+// default HTTP client when nil.
+func NewParameterStore(ctx context.Context, cfg AWSConfig, client *http.Client) (*ParameterStore, error) {
+	awsCfg, err := cfg.load(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	return &ParameterStore{client: ssm.NewFromConfig(awsCfg, func(o *ssm.Options) {
+		o.BaseEndpoint = cfg.endpoint()
+	})}, nil
+}
+
+// GetSecret implements Provider. name is the name of the parameter, such as
+// /order-services/stripe/secret-key.
+func (p *ParameterStore) GetSecret(ctx context.Context, name string) (string, error) {
+	out, err := p.client.GetParameter(ctx, &ssm.GetParameterInput{Name: aws.String(name), WithDecryption: aws.Bool(true)})
+	if err != nil {
+		var notFound *ssmtypes.ParameterNotFound
+		if errors.As(err, &notFound) {
+			return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return "", fmt.Errorf("secrets: ssm: %w", err)
+	}
+	if out.Parameter == nil {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return aws.ToString(out.Parameter.Value), nil
+}
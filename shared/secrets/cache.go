@@ -0,0 +1,130 @@
+package secrets
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DefaultTTL is how often a Cache fetches its secrets again.
+const DefaultTTL = 5 * time.Minute
+
+// lookupTimeout bounds the fetches made to resolve configuration references.
+const lookupTimeout = 10 * time.Second
+
+// Cache keeps the secrets fetched from a provider and refreshes them in the background.
+type Cache struct {
+	provider Provider
+	ttl      time.Duration
+
+	mu     sync.Mutex
+	values map[string]string
+	hooks  []func(name string)
+}
+
+// NewCache returns a cache in front of provider refreshing its secrets every ttl,
+// DefaultTTL when 0.
+func NewCache(provider Provider, ttl time.Duration) *Cache {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Cache{provider: provider, ttl: ttl, values: make(map[string]string)}
+}
+
+// Get returns the secret ref refers to: a secret name, optionally followed by #field to
+// select a field of a secret holding a JSON object. A secret is fetched the first time
+// it is asked for; Refresh and Run fetch it again.
+func (c *Cache) Get(ctx context.Context, ref string) (string, error) {
+	name, field := splitRef(ref)
+	c.mu.Lock()
+	value, ok := c.values[name]
+	c.mu.Unlock()
+	if !ok {
+		var err error
+		if value, err = c.fetch(ctx, name); err != nil {
+			return "", err
+		}
+	}
+	return selectField(value, field)
+}
+
+// Secret resolves the secret:// references of shared/envconfig.
+func (c *Cache) Secret(ref string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+	defer cancel()
+	return c.Get(ctx, ref)
+}
+
+// OnRotate registers hook to be called with the name of every secret whose value
+// changed on a refresh, such as to reload the configuration.
+func (c *Cache) OnRotate(hook func(name string)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hooks = append(c.hooks, hook)
+}
+
+// Refresh fetches every secret held again and calls the rotation hooks for those that
+// changed. Secrets that fail to fetch keep their value, so a provider outage only
+// matters for secrets not fetched yet.
+func (c *Cache) Refresh(ctx context.Context) error {
+	c.mu.Lock()
+	held := make(map[string]string, len(c.values))
+	for name, value := range c.values {
+		held[name] = value
+	}
+	c.mu.Unlock()
+
+	var firstErr error
+	var rotated []string
+	for name, old := range held {
+		value, err := c.fetch(ctx, name)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if value != old {
+			rotated = append(rotated, name)
+		}
+	}
+
+	c.mu.Lock()
+	hooks := c.hooks
+	c.mu.Unlock()
+	for _, name := range rotated {
+		slog.InfoContext(ctx, "secret rotated", "secret", name)
+		for _, hook := range hooks {
+			hook(name)
+		}
+	}
+	return firstErr
+}
+
+// Run refreshes the secrets every TTL until ctx is cancelled.
+func (c *Cache) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.ttl)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Refresh(ctx); err != nil {
+				slog.WarnContext(ctx, "failed to refresh secrets", "error", err)
+			}
+		}
+	}
+}
+
+func (c *Cache) fetch(ctx context.Context, name string) (string, error) {
+	value, err := c.provider.GetSecret(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	c.values[name] = value
+	c.mu.Unlock()
+	return value, nil
+}
@@ -0,0 +1,108 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Providers named by SECRETS_PROVIDER.
+const (
+	ProviderVault          = "vault"
+	ProviderSecretsManager = "aws-secretsmanager"
+	ProviderParameterStore = "aws-ssm"
+	ProviderDir            = "file"
+)
+
+// Config selects and configures the provider of a service.
+type Config struct {
+	// Provider is one of the Provider* names, or empty when secrets are kept in the
+	// environment
+	Provider string
+	// TTL is how often secrets are fetched again, DefaultTTL when 0
+	TTL   time.Duration
+	Vault VaultConfig
+	AWS   AWSConfig
+	// Dir is the directory of the file provider
+	Dir string
+}
+
+// ConfigFromEnv reads the provider from SECRETS_PROVIDER and how often secrets are
+// refreshed from SECRETS_REFRESH_INTERVAL. The providers read the usual variables of
+// their platform: VAULT_ADDR, VAULT_TOKEN and VAULT_NAMESPACE, with VAULT_KV_MOUNT for
+// the KV engine; AWS_REGION and the SDK's default credential chain, with
+// SECRETS_ENDPOINT to override the AWS endpoint; SECRETS_DIR, /run/secrets by default,
+// for files.
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		Provider: os.Getenv("SECRETS_PROVIDER"),
+		TTL:      DefaultTTL,
+		Vault: VaultConfig{
+			Addr:      os.Getenv("VAULT_ADDR"),
+			Token:     os.Getenv("VAULT_TOKEN"),
+			Mount:     os.Getenv("VAULT_KV_MOUNT"),
+			Namespace: os.Getenv("VAULT_NAMESPACE"),
+		},
+		AWS: AWSConfig{
+			Region:   os.Getenv("AWS_REGION"),
+			Endpoint: os.Getenv("SECRETS_ENDPOINT"),
+		},
+		Dir: os.Getenv("SECRETS_DIR"),
+	}
+	if cfg.AWS.Region == "" {
+		cfg.AWS.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if cfg.Dir == "" {
+		cfg.Dir = "/run/secrets"
+	}
+	if v := os.Getenv("SECRETS_REFRESH_INTERVAL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil || ttl <= 0 {
+			return Config{}, fmt.Errorf("secrets: invalid SECRETS_REFRESH_INTERVAL %q", v)
+		}
+		cfg.TTL = ttl
+	}
+	return cfg, nil
+}
+
+// New returns a cache in front of the provider of cfg, or nil when cfg names none.
+func New(cfg Config) (*Cache, error) {
+	var provider Provider
+	switch cfg.Provider {
+	case "":
+		return nil, nil
+	case ProviderVault:
+		if cfg.Vault.Addr == "" || cfg.Vault.Token == "" {
+			return nil, fmt.Errorf("secrets: VAULT_ADDR and VAULT_TOKEN are required by the %s provider", cfg.Provider)
+		}
+		provider = NewVault(cfg.Vault, nil)
+	case ProviderSecretsManager:
+		sm, err := NewSecretsManager(context.Background(), cfg.AWS, nil)
+		if err != nil {
+			return nil, err
+		}
+		provider = sm
+	case ProviderParameterStore:
+		ps, err := NewParameterStore(context.Background(), cfg.AWS, nil)
+		if err != nil {
+			return nil, err
+		}
+		provider = ps
+	case ProviderDir:
+		provider = Dir(cfg.Dir)
+	default:
+		return nil, fmt.Errorf("secrets: unknown SECRETS_PROVIDER %q", cfg.Provider)
+	}
+	return NewCache(provider, cfg.TTL), nil
+}
+
+// FromEnv returns the cache configured by ConfigFromEnv, or nil when SECRETS_PROVIDER is
+// not set.
+func FromEnv() (*Cache, error) {
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return New(cfg)
+}
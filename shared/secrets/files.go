@@ -0,0 +1,31 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Dir reads secrets from the files of a directory, such as the /run/secrets of Docker
+// and Kubernetes, or the files rendered by Vault Agent. The name of a secret is the path
+// of its file in the directory.
+type Dir string
+
+// GetSecret implements Provider.
+func (d Dir) GetSecret(_ context.Context, name string) (string, error) {
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("secrets: invalid secret name %q", name)
+	}
+	data, err := os.ReadFile(filepath.Join(string(d), name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
@@ -0,0 +1,65 @@
+// Package secrets reads the secrets of the Go services, such as Stripe keys, JWT keys and
+// database passwords, from a secrets manager instead of .env files.
+//
+// A Provider fetches a secret by name from HashiCorp Vault, AWS Secrets Manager, AWS
+// Systems Manager Parameter Store or a directory of mounted files. A Cache in front of
+// it keeps the values for a while, refreshes them in the background and tells the
+// service when one was rotated. The Cache resolves the secret:// references of
+// shared/envconfig, so a setting like
+//
+//	STRIPE_SECRET_KEY=secret://order-services/stripe#secret_key
+//
+// is read from the provider when the configuration is loaded.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotFound is returned for a secret or field the provider does not have.
+var ErrNotFound = errors.New("secrets: not found")
+
+// Provider fetches the current value of a secret.
+type Provider interface {
+	GetSecret(ctx context.Context, name string) (string, error)
+}
+
+// ProviderFunc adapts a function to Provider.
+type ProviderFunc func(ctx context.Context, name string) (string, error)
+
+// GetSecret implements Provider.
+func (f ProviderFunc) GetSecret(ctx context.Context, name string) (string, error) {
+	return f(ctx, name)
+}
+
+// splitRef splits a reference into the name of a secret and the field selected from it,
+// such as "order-services/stripe" and "secret_key" for order-services/stripe#secret_key.
+func splitRef(ref string) (name, field string) {
+	name, field, _ = strings.Cut(ref, "#")
+	return name, field
+}
+
+// selectField returns field of a secret holding a JSON object, or the whole secret when
+// field is empty.
+func selectField(value, field string) (string, error) {
+	if field == "" {
+		return value, nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", fmt.Errorf("secrets: field %q of a secret that is not a JSON object", field)
+	}
+	v, ok := fields[field]
+	if !ok {
+		return "", fmt.Errorf("%w: field %q", ErrNotFound, field)
+	}
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(v)
+	return string(data), err
+}
@@ -0,0 +1,197 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCacheRotation(t *testing.T) {
+	values := map[string]string{"stripe": `{"secret_key":"sk_1","webhook_secret":"whsec_1"}`}
+	var fail bool
+	cache := NewCache(ProviderFunc(func(_ context.Context, name string) (string, error) {
+		if fail {
+			return "", errors.New("provider down")
+		}
+		v, ok := values[name]
+		if !ok {
+			return "", ErrNotFound
+		}
+		return v, nil
+	}), time.Minute)
+	var rotated []string
+	cache.OnRotate(func(name string) { rotated = append(rotated, name) })
+
+	ctx := context.Background()
+	if v, err := cache.Get(ctx, "stripe#secret_key"); err != nil || v != "sk_1" {
+		t.Fatalf("Get = %q, %v", v, err)
+	}
+	if _, err := cache.Get(ctx, "stripe#missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing field: %v", err)
+	}
+	if _, err := cache.Get(ctx, "jwt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing secret: %v", err)
+	}
+
+	values["stripe"] = `{"secret_key":"sk_2","webhook_secret":"whsec_1"}`
+	if v, _ := cache.Get(ctx, "stripe#secret_key"); v != "sk_1" {
+		t.Errorf("value changed before a refresh: %q", v)
+	}
+	if err := cache.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := cache.Get(ctx, "stripe#secret_key"); v != "sk_2" || len(rotated) != 1 || rotated[0] != "stripe" {
+		t.Errorf("after refresh: %q, rotated %v", v, rotated)
+	}
+
+	// An outage keeps the values held
+	fail = true
+	if err := cache.Refresh(ctx); err == nil {
+		t.Error("Refresh hid the provider error")
+	}
+	if v, err := cache.Secret("stripe#secret_key"); err != nil || v != "sk_2" {
+		t.Errorf("during an outage: %q, %v", v, err)
+	}
+}
+
+func TestVault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/kv/data/order-services/stripe" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"secret_key":"sk_test"},"metadata":{"version":3}}}`))
+	}))
+	defer server.Close()
+
+	cache := NewCache(NewVault(VaultConfig{Addr: server.URL, Token: "root", Mount: "kv"}, server.Client()), 0)
+	if v, err := cache.Get(context.Background(), "order-services/stripe#secret_key"); err != nil || v != "sk_test" {
+		t.Errorf("Get = %q, %v", v, err)
+	}
+	if _, err := cache.Get(context.Background(), "order-services/jwt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing secret: %v", err)
+	}
+}
+
+// awsTestServer answers the JSON API calls of target the way AWS does, after checking
+// that they are signed with the credentials of AKID in eu-west-1. found maps the names
+// it knows to their response; other names get an error of type notFound. The AWS
+// variables and files of the machine running the tests are hidden from the SDK.
+func awsTestServer(t *testing.T, target, notFound string, found map[string]string) *httptest.Server {
+	t.Helper()
+	for _, name := range []string{"AWS_CA_BUNDLE", "AWS_PROFILE", "AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_REGION", "AWS_DEFAULT_REGION"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "/eu-west-1/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.Header.Get("X-Amz-Target") != target {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var in struct{ SecretId, Name string }
+		json.NewDecoder(r.Body).Decode(&in)
+		body, ok := found[in.SecretId+in.Name]
+		if !ok {
+			w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type":"` + notFound + `","message":"not found"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSecretsManager(t *testing.T) {
+	server := awsTestServer(t, "secretsmanager.GetSecretValue", "ResourceNotFoundException", map[string]string{
+		"user-services/jwt": `{"Name":"user-services/jwt","SecretString":"s3cret"}`,
+	})
+
+	cfg := AWSConfig{Region: "eu-west-1", AccessKeyID: "AKID", SecretAccessKey: "secret", Endpoint: server.URL}
+	provider, err := NewSecretsManager(context.Background(), cfg, server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := provider.GetSecret(context.Background(), "user-services/jwt"); err != nil || v != "s3cret" {
+		t.Errorf("GetSecret = %q, %v", v, err)
+	}
+	if _, err := provider.GetSecret(context.Background(), "other"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing secret: %v", err)
+	}
+}
+
+func TestParameterStore(t *testing.T) {
+	server := awsTestServer(t, "AmazonSSM.GetParameter", "ParameterNotFound", map[string]string{
+		"/order-services/stripe/secret-key": `{"Parameter":{"Name":"/order-services/stripe/secret-key","Type":"SecureString","Value":"sk_test"}}`,
+	})
+
+	cfg := AWSConfig{Region: "eu-west-1", AccessKeyID: "AKID", SecretAccessKey: "secret", Endpoint: server.URL}
+	provider, err := NewParameterStore(context.Background(), cfg, server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := provider.GetSecret(context.Background(), "/order-services/stripe/secret-key"); err != nil || v != "sk_test" {
+		t.Errorf("GetSecret = %q, %v", v, err)
+	}
+	if _, err := provider.GetSecret(context.Background(), "/other"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing parameter: %v", err)
+	}
+}
+
+// TestAWSDefaultCredentials checks that without static keys the providers take their
+// credentials and region from the SDK's default chain.
+func TestAWSDefaultCredentials(t *testing.T) {
+	server := awsTestServer(t, "secretsmanager.GetSecretValue", "ResourceNotFoundException", map[string]string{
+		"user-services/jwt": `{"SecretString":"s3cret"}`,
+	})
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_REGION", "eu-west-1")
+
+	provider, err := NewSecretsManager(context.Background(), AWSConfig{Endpoint: server.URL}, server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := provider.GetSecret(context.Background(), "user-services/jwt"); err != nil || v != "s3cret" {
+		t.Errorf("GetSecret = %q, %v", v, err)
+	}
+
+	t.Setenv("AWS_REGION", "")
+	if _, err := NewSecretsManager(context.Background(), AWSConfig{}, nil); err == nil {
+		t.Error("NewSecretsManager accepted a configuration without a region")
+	}
+}
+
+func TestDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "smtp_password"), []byte("p4ss\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := Dir(dir).GetSecret(context.Background(), "smtp_password"); err != nil || v != "p4ss" {
+		t.Errorf("GetSecret = %q, %v", v, err)
+	}
+	if _, err := Dir(dir).GetSecret(context.Background(), "../etc/passwd"); err == nil {
+		t.Error("read outside the directory")
+	}
+}
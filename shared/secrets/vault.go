@@ -0,0 +1,85 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Vault reads secrets from a KV version 2 engine of HashiCorp Vault. A secret is the
+// JSON object of its fields; a reference selects one, as in order-services/stripe#secret_key.
+type Vault struct {
+	addr      string
+	token     string
+	mount     string
+	namespace string
+	client    *http.Client
+}
+
+// VaultConfig locates a Vault KV engine.
+type VaultConfig struct {
+	// Addr is the address of Vault, such as https://vault.internal:8200
+	Addr string
+	// Token authenticates the requests
+	Token string
+	// Mount is the path the KV engine is mounted at, "secret" when empty
+	Mount string
+	// Namespace is the Vault Enterprise namespace, if any
+	Namespace string
+}
+
+// NewVault returns a provider reading the KV engine of cfg with client,
+// http.DefaultClient when nil.
+func NewVault(cfg VaultConfig, client *http.Client) *Vault {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	mount := strings.Trim(cfg.Mount, "/")
+	if mount == "" {
+		mount = "secret"
+	}
+	return &Vault{
+		addr:      strings.TrimRight(cfg.Addr, "/"),
+		token:     cfg.Token,
+		mount:     mount,
+		namespace: cfg.Namespace,
+		client:    client,
+	}
+}
+
+// GetSecret implements Provider.
+func (v *Vault) GetSecret(ctx context.Context, name string) (string, error) {
+	path := (&url.URL{Path: strings.Trim(name, "/")}).EscapedPath()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.addr+"/v1/"+v.mount+"/data/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+	if v.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.namespace)
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("secrets: vault: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("secrets: vault: reading %s: %s", name, resp.Status)
+	}
+	var body struct {
+		Data struct {
+			Data json.RawMessage `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("secrets: vault: decoding %s: %w", name, err)
+	}
+	return string(body.Data.Data), nil
+}
@@ -0,0 +1,132 @@
+package seed
+
+import (
+	"fmt"
+	"time"
+)
+
+// Topic and Level are the taxonomy the courses are filed under. content-services keys
+// them by slug and code, so the seed reuses the ones that exist.
+type Topic struct {
+	Slug string
+	Name string
+}
+
+type Level struct {
+	Code string
+	Name string
+}
+
+// Course is a published course with its lessons.
+type Course struct {
+	ID           string
+	Title        string
+	Description  string
+	Topic        Topic
+	Level        Level
+	InstructorID string // empty when there are no teachers
+	PriceCents   int64
+	Featured     bool
+	PublishedAt  time.Time
+	Lessons      []Lesson
+}
+
+// Lesson is a published lesson of a course with a text section and a quiz.
+type Lesson struct {
+	ID    string
+	Code  string
+	Title string
+	Body  string
+	Quiz  Quiz
+}
+
+type Quiz struct {
+	ID        string
+	Title     string
+	Questions []Question
+}
+
+// Question is a multiple choice question with one correct option.
+type Question struct {
+	ID      string
+	Prompt  string
+	Options []Option
+}
+
+type Option struct {
+	ID      string
+	Label   string
+	Correct bool
+}
+
+// Topics returns the topics of the courses.
+func (p *Plan) Topics() []Topic { return topics }
+
+// Levels returns the CEFR levels of the courses.
+func (p *Plan) Levels() []Level { return levels }
+
+// Course returns the i-th course.
+func (p *Plan) Course(i int) Course {
+	r := p.rand("course", i)
+	topic, level := pick(r, topics), pick(r, levels)
+	c := Course{
+		ID:          p.ID("course", i),
+		Title:       fmt.Sprintf("%s %s (%s)", pick(r, courseAdjectives), topic.Name, level.Code),
+		Description: fmt.Sprintf("%s Built for %s learners, with a quiz after every lesson.", pick(r, courseBlurbs), level.Name),
+		Topic:       topic,
+		Level:       level,
+		PriceCents:  pick(r, pricesCents),
+		Featured:    i%7 == 0,
+		// Published in the first half of the history, so there is time to buy them
+		PublishedAt: between(r, p.from, p.from.Add(p.cfg.Now.Sub(p.from)/2)),
+	}
+	if teachers := p.teachers(); len(teachers) > 0 {
+		c.InstructorID = p.ID("user", teachers[i%len(teachers)])
+	}
+
+	for l := range p.cfg.Lessons {
+		lesson := Lesson{
+			ID:    p.ID("lesson", i, l),
+			Code:  fmt.Sprintf("SEED-%d-%d-%d", p.cfg.Seed, i, l),
+			Title: fmt.Sprintf("Lesson %d: %s", l+1, pick(r, lessonSubjects)),
+			Body:  pick(r, lessonBodies),
+		}
+		lesson.Quiz = Quiz{ID: p.ID("quiz", i, l), Title: lesson.Title + " quiz"}
+		for q, word := range r.Perm(len(vocabulary))[:3] {
+			question := Question{
+				ID:     p.ID("question", i, l, q),
+				Prompt: fmt.Sprintf("Which word means %q?", vocabulary[word].meaning),
+			}
+			// The correct word and three others, in random order
+			choices := []int{word}
+			for _, other := range r.Perm(len(vocabulary)) {
+				if len(choices) == 4 {
+					break
+				}
+				if other != word {
+					choices = append(choices, other)
+				}
+			}
+			r.Shuffle(len(choices), func(a, b int) { choices[a], choices[b] = choices[b], choices[a] })
+			for o, choice := range choices {
+				question.Options = append(question.Options, Option{
+					ID:      p.ID("option", i, l, q, o),
+					Label:   vocabulary[choice].word,
+					Correct: choice == word,
+				})
+			}
+			lesson.Quiz.Questions = append(lesson.Quiz.Questions, question)
+		}
+		c.Lessons = append(c.Lessons, lesson)
+	}
+	return c
+}
+
+// Courses returns every course.
+func (p *Plan) Courses() []Course {
+	courses := make([]Course, 0, max(p.cfg.Courses, 0))
+	for i := range p.cfg.Courses {
+		courses = append(courses, p.Course(i))
+	}
+	return courses
+}
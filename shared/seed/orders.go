@@ -0,0 +1,80 @@
+package seed
+
+import (
+	"fmt"
+	"time"
+)
+
+// Currency is the currency of the seeded orders.
+const Currency = "USD"
+
+// Order is a paid order of one course by a student, with its payment.
+type Order struct {
+	ID        string
+	UserID    string
+	Email     string
+	Name      string
+	Course    Course
+	CreatedAt time.Time
+	PaidAt    time.Time
+	// PaymentID is the ID of the payment; PaymentIntentID and ChargeID stand in for Stripe's
+	PaymentID       string
+	PaymentIntentID string
+	ChargeID        string
+	// Review is the buyer's review of the course, nil for most orders
+	Review *Review
+}
+
+type Review struct {
+	Rating  int
+	Comment string
+}
+
+// Order returns the i-th order, or false when there are no users or courses to link.
+func (p *Plan) Order(i int) (Order, bool) {
+	if p.cfg.Users <= 0 || p.cfg.Courses <= 0 {
+		return Order{}, false
+	}
+	r := p.rand("order", i)
+
+	// Students buy courses; a few draws find one unless there are almost only staff
+	buyer := p.User(r.IntN(p.cfg.Users))
+	for try := 0; buyer.Role != RoleStudent && try < 10; try++ {
+		buyer = p.User(r.IntN(p.cfg.Users))
+	}
+	course := p.Course(r.IntN(p.cfg.Courses))
+
+	start := buyer.CreatedAt
+	if course.PublishedAt.After(start) {
+		start = course.PublishedAt
+	}
+	createdAt := between(r, start, p.cfg.Now)
+	o := Order{
+		ID:              p.ID("order", i),
+		UserID:          buyer.ID,
+		Email:           buyer.Email,
+		Name:            buyer.Name,
+		Course:          course,
+		CreatedAt:       createdAt,
+		PaidAt:          createdAt.Add(time.Duration(30+r.IntN(270)) * time.Second),
+		PaymentID:       p.ID("payment", i),
+		PaymentIntentID: fmt.Sprintf("pi_seed_%d_%d", p.cfg.Seed, i),
+		ChargeID:        fmt.Sprintf("ch_seed_%d_%d", p.cfg.Seed, i),
+	}
+	if r.IntN(3) == 0 {
+		rating := 3 + r.IntN(3)
+		o.Review = &Review{Rating: rating, Comment: reviewComments[rating-3]}
+	}
+	return o, true
+}
+
+// Orders returns every order.
+func (p *Plan) Orders() []Order {
+	var orders []Order
+	for i := range p.cfg.Orders {
+		if o, ok := p.Order(i); ok {
+			orders = append(orders, o)
+		}
+	}
+	return orders
+}
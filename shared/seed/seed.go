@@ -0,0 +1,113 @@
+// Package seed plans synthetic data for local development, demos and load tests. A Plan
+// is derived from a Config alone, so the seed commands of user-services, content-services
+// and order-services, each writing its own entities to its own database, agree on the IDs
+// that link them: the users who teach the courses, buy them and review them.
+package seed
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"time"
+)
+
+// Defaults of the scale flags.
+const (
+	DefaultUsers   = 100
+	DefaultCourses = 20
+	DefaultLessons = 5
+	DefaultOrders  = 200
+	DefaultDays    = 90
+)
+
+// Password is the password of every seeded account.
+const Password = "Seed-password-1"
+
+// EmailDomain is the domain of the seeded accounts, reserved for examples so no mail
+// sent to them is delivered.
+const EmailDomain = "example.com"
+
+// Config sizes a Plan. Entities are generated from the seed and their index only, so
+// raising a count adds entities without changing the ones already seeded.
+type Config struct {
+	Seed int64
+	// Users is the number of accounts. The first is an admin and every tenth a teacher
+	Users int
+	// Courses is the number of published courses, each with Lessons lessons and a quiz per lesson
+	Courses int
+	Lessons int
+	// Orders is the number of paid orders, one course each
+	Orders int
+	// Days is how far back the history goes
+	Days int
+	// Now ends the history, today at midnight UTC when zero
+	Now time.Time
+}
+
+// RegisterFlags registers the scale flags on fs, so every seed command takes the same ones.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.Int64Var(&c.Seed, "seed", 1, "seed of the generated data; the same seed generates the same IDs in every service")
+	fs.IntVar(&c.Users, "users", DefaultUsers, "number of users")
+	fs.IntVar(&c.Courses, "courses", DefaultCourses, "number of published courses")
+	fs.IntVar(&c.Lessons, "lessons", DefaultLessons, "number of lessons, each with a quiz, per course")
+	fs.IntVar(&c.Orders, "orders", DefaultOrders, "number of paid orders")
+	fs.IntVar(&c.Days, "days", DefaultDays, "number of days of history")
+}
+
+// Plan is the data a Config describes.
+type Plan struct {
+	cfg Config
+	// from is the start of the history
+	from time.Time
+}
+
+// New returns the plan of cfg.
+func New(cfg Config) *Plan {
+	if cfg.Now.IsZero() {
+		cfg.Now = time.Now().UTC().Truncate(24 * time.Hour)
+	}
+	if cfg.Days <= 0 {
+		cfg.Days = DefaultDays
+	}
+	return &Plan{cfg: cfg, from: cfg.Now.AddDate(0, 0, -cfg.Days)}
+}
+
+// ID returns the UUID of the index-th entity of kind. It is a name-based UUID of the
+// seed, kind and index, so every service derives the same one.
+func (p *Plan) ID(kind string, index ...int) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "seed/%d/%s", p.cfg.Seed, kind)
+	for _, i := range index {
+		fmt.Fprintf(h, "/%d", i)
+	}
+	var u [16]byte
+	copy(u[:], h.Sum(nil))
+	u[6] = u[6]&0x0f | 0x50 // version 5
+	u[8] = u[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
+
+// rand returns the random source of one entity, independent of every other entity.
+func (p *Plan) rand(kind string, index ...int) *rand.Rand {
+	h := fnv.New64a()
+	h.Write([]byte(kind))
+	for _, i := range index {
+		h.Write(binary.BigEndian.AppendUint64(nil, uint64(i)))
+	}
+	return rand.New(rand.NewPCG(uint64(p.cfg.Seed), h.Sum64()))
+}
+
+// between returns a random time in [from, to), from when the range is empty.
+func between(r *rand.Rand, from, to time.Time) time.Time {
+	if !to.After(from) {
+		return from
+	}
+	return from.Add(time.Duration(r.Int64N(int64(to.Sub(from)))))
+}
+
+func pick[T any](r *rand.Rand, values []T) T {
+	return values[r.IntN(len(values))]
+}
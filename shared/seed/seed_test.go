@@ -0,0 +1,117 @@
+package seed
+
+import (
+	"flag"
+	"regexp"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestIDsAreStableUUIDs(t *testing.T) {
+	uuidPattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-5[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	p := New(Config{Seed: 1, Now: now})
+
+	id := p.ID("user", 7)
+	if !uuidPattern.MatchString(id) {
+		t.Fatalf("%s is not a version 5 UUID", id)
+	}
+	if again := New(Config{Seed: 1, Now: now}).ID("user", 7); again != id {
+		t.Errorf("same seed: %s != %s", again, id)
+	}
+	if other := New(Config{Seed: 2, Now: now}).ID("user", 7); other == id {
+		t.Error("another seed generated the same ID")
+	}
+	if p.ID("course", 7) == id || p.ID("user", 7, 0) == id {
+		t.Error("kinds and indexes share IDs")
+	}
+}
+
+// Raising the scale adds entities without changing the seeded ones, so seeding again at a
+// larger scale tops a database up.
+func TestScalingKeepsEntities(t *testing.T) {
+	small := New(Config{Seed: 1, Users: 20, Courses: 3, Lessons: 2, Orders: 10, Now: now})
+	large := New(Config{Seed: 1, Users: 20, Courses: 3, Lessons: 2, Orders: 50, Now: now})
+
+	smallOrders, largeOrders := small.Orders(), large.Orders()
+	if len(smallOrders) != 10 || len(largeOrders) != 50 {
+		t.Fatalf("got %d and %d orders", len(smallOrders), len(largeOrders))
+	}
+	for i, o := range smallOrders {
+		if largeOrders[i].ID != o.ID || largeOrders[i].UserID != o.UserID || largeOrders[i].Course.ID != o.Course.ID {
+			t.Fatalf("order %d changed with the scale", i)
+		}
+	}
+}
+
+func TestOrdersLinkEntities(t *testing.T) {
+	p := New(Config{Seed: 1, Users: 30, Courses: 4, Lessons: 3, Orders: 40, Days: 30, Now: now})
+
+	users := make(map[string]User)
+	for _, u := range p.Users() {
+		users[u.ID] = u
+	}
+	courses := make(map[string]Course)
+	for _, c := range p.Courses() {
+		courses[c.ID] = c
+		if teacher, ok := users[c.InstructorID]; !ok || teacher.Role != RoleTeacher {
+			t.Errorf("course %s is taught by %q, not a teacher", c.ID, c.InstructorID)
+		}
+		if len(c.Lessons) != 3 || len(c.Lessons[0].Quiz.Questions) != 3 {
+			t.Fatalf("course %s has %d lessons", c.ID, len(c.Lessons))
+		}
+	}
+
+	for _, o := range p.Orders() {
+		buyer, ok := users[o.UserID]
+		if !ok || buyer.Role != RoleStudent || buyer.Email != o.Email {
+			t.Errorf("order %s was placed by %+v", o.ID, buyer)
+		}
+		if _, ok := courses[o.Course.ID]; !ok {
+			t.Errorf("order %s is for unknown course %s", o.ID, o.Course.ID)
+		}
+		if o.CreatedAt.Before(buyer.CreatedAt) || o.CreatedAt.Before(o.Course.PublishedAt) || !o.PaidAt.After(o.CreatedAt) {
+			t.Errorf("order %s at %s, paid %s: user created %s, course published %s", o.ID, o.CreatedAt, o.PaidAt, buyer.CreatedAt, o.Course.PublishedAt)
+		}
+		if o.PaidAt.After(now.Add(5 * time.Minute)) {
+			t.Errorf("order %s is paid in the future", o.ID)
+		}
+	}
+}
+
+func TestQuestionsHaveOneCorrectOption(t *testing.T) {
+	c := New(Config{Seed: 3, Lessons: 4, Now: now}).Course(0)
+	for _, lesson := range c.Lessons {
+		for _, q := range lesson.Quiz.Questions {
+			correct, labels := 0, make(map[string]bool)
+			for _, o := range q.Options {
+				labels[o.Label] = true
+				if o.Correct {
+					correct++
+				}
+			}
+			if correct != 1 || len(labels) != 4 {
+				t.Errorf("question %q: %d correct of %d distinct options", q.Prompt, correct, len(labels))
+			}
+		}
+	}
+}
+
+func TestNoOrdersWithoutUsersOrCourses(t *testing.T) {
+	if orders := New(Config{Seed: 1, Courses: 2, Orders: 5}).Orders(); len(orders) != 0 {
+		t.Errorf("got %d orders without users", len(orders))
+	}
+}
+
+func TestRegisterFlags(t *testing.T) {
+	var cfg Config
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	cfg.RegisterFlags(fs)
+	if err := fs.Parse([]string{"-users", "1000", "-seed", "7"}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Users != 1000 || cfg.Seed != 7 || cfg.Courses != DefaultCourses || cfg.Orders != DefaultOrders {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+}
@@ -0,0 +1,87 @@
+package seed
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Roles of the seeded accounts, as user-services names them.
+const (
+	RoleStudent = "student"
+	RoleTeacher = "teacher"
+	RoleAdmin   = "admin"
+)
+
+// User is a seeded account with its sign-in history.
+type User struct {
+	ID        string
+	Email     string
+	Name      string
+	Role      string
+	Locale    string
+	TimeZone  string
+	CreatedAt time.Time
+	Sessions  []Session
+}
+
+// Session is a sign-in of a user and the activity it recorded.
+type Session struct {
+	ID        string
+	UserAgent string
+	IPAddr    string
+	StartedAt time.Time
+	Duration  time.Duration
+}
+
+// User returns the i-th user.
+func (p *Plan) User(i int) User {
+	r := p.rand("user", i)
+	first, last := pick(r, firstNames), pick(r, lastNames)
+	place := pick(r, places)
+	u := User{
+		ID:       p.ID("user", i),
+		Email:    fmt.Sprintf("%s.%s.%d@%s", strings.ToLower(first), strings.ToLower(last), i, EmailDomain),
+		Name:     first + " " + last,
+		Role:     RoleStudent,
+		Locale:   place.locale,
+		TimeZone: place.timeZone,
+		// Accounts are created in the first half of the history, so they all have some
+		CreatedAt: between(r, p.from, p.from.Add(p.cfg.Now.Sub(p.from)/2)),
+	}
+	switch {
+	case i == 0:
+		u.Role = RoleAdmin
+	case i%10 == 1:
+		u.Role = RoleTeacher
+	}
+
+	for s := range 1 + r.IntN(8) {
+		u.Sessions = append(u.Sessions, Session{
+			ID:        p.ID("session", i, s),
+			UserAgent: pick(r, userAgents),
+			IPAddr:    fmt.Sprintf("%s.%d", pick(r, ipPrefixes), 1+r.IntN(254)),
+			StartedAt: between(r, u.CreatedAt, p.cfg.Now),
+			Duration:  time.Duration(2+r.IntN(88)) * time.Minute,
+		})
+	}
+	return u
+}
+
+// Users returns every user.
+func (p *Plan) Users() []User {
+	users := make([]User, 0, max(p.cfg.Users, 0))
+	for i := range p.cfg.Users {
+		users = append(users, p.User(i))
+	}
+	return users
+}
+
+// teachers returns the indexes of the teachers, who instruct the courses.
+func (p *Plan) teachers() []int {
+	var teachers []int
+	for i := 1; i < p.cfg.Users; i += 10 {
+		teachers = append(teachers, i)
+	}
+	return teachers
+}
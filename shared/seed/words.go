@@ -0,0 +1,108 @@
+package seed
+
+var firstNames = []string{
+	"Ana", "Bao", "Carlos", "Chloe", "Daniel", "Elena", "Farah", "Gabriel", "Hana", "Ivan",
+	"Jin", "Julia", "Kenji", "Laura", "Linh", "Lucas", "Maya", "Minh", "Noah", "Olivia",
+	"Omar", "Priya", "Quang", "Rosa", "Sara", "Tuan", "Umar", "Vy", "William", "Yuki",
+}
+
+var lastNames = []string{
+	"Anderson", "Bui", "Castro", "Dang", "Evans", "Fischer", "Garcia", "Ho", "Ito", "Johnson",
+	"Kim", "Le", "Martin", "Nguyen", "Novak", "Park", "Pham", "Rossi", "Silva", "Tran",
+	"Vo", "Wang", "Weber", "Yilmaz",
+}
+
+var places = []struct {
+	locale   string
+	timeZone string
+}{
+	{"en", "UTC"},
+	{"en", "America/New_York"},
+	{"en", "Europe/London"},
+	{"vi", "Asia/Ho_Chi_Minh"},
+	{"ja", "Asia/Tokyo"},
+	{"es", "Europe/Madrid"},
+	{"pt", "America/Sao_Paulo"},
+}
+
+var userAgents = []string{
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/141.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.5 Safari/605.1.15",
+	"Mozilla/5.0 (X11; Linux x86_64; rv:143.0) Gecko/20100101 Firefox/143.0",
+	"Mozilla/5.0 (iPhone; CPU iPhone OS 18_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.5 Mobile/15E148 Safari/604.1",
+	"Mozilla/5.0 (Linux; Android 15; Pixel 9) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/141.0.0.0 Mobile Safari/537.36",
+}
+
+// ipPrefixes are documentation ranges (RFC 5737), which no real client uses.
+var ipPrefixes = []string{"192.0.2", "198.51.100", "203.0.113"}
+
+var topics = []Topic{
+	{"business-english", "Business English"},
+	{"travel", "Travel English"},
+	{"grammar", "Grammar"},
+	{"vocabulary", "Vocabulary"},
+	{"pronunciation", "Pronunciation"},
+	{"conversation", "Conversation"},
+	{"exam-prep", "Exam Preparation"},
+}
+
+var levels = []Level{
+	{"A1", "Beginner"},
+	{"A2", "Elementary"},
+	{"B1", "Intermediate"},
+	{"B2", "Upper Intermediate"},
+	{"C1", "Advanced"},
+	{"C2", "Proficient"},
+}
+
+var courseAdjectives = []string{"Everyday", "Practical", "Essential", "Complete", "Confident", "Fast-Track", "Hands-On"}
+
+var courseBlurbs = []string{
+	"Short lessons you can finish on a commute.",
+	"Real conversations, recorded by native speakers.",
+	"Step-by-step explanations with plenty of practice.",
+	"Focused on the situations you meet at work and abroad.",
+}
+
+var pricesCents = []int64{1999, 2999, 4999, 7999}
+
+var lessonSubjects = []string{
+	"Introductions", "At the Airport", "Ordering Food", "Making Appointments", "Small Talk",
+	"Writing Emails", "Giving Directions", "Talking About the Past", "Making Plans",
+	"Describing People", "Shopping", "Job Interviews", "Phone Calls", "Presentations",
+}
+
+var lessonBodies = []string{
+	"Read the dialogue aloud, then underline the phrases you would use yourself.",
+	"Study the examples and notice where the verb goes in each sentence.",
+	"Listen for the key words first, then for the details.",
+	"Practise each phrase in a sentence of your own before the quiz.",
+}
+
+var vocabulary = []struct {
+	word    string
+	meaning string
+}{
+	{"reliable", "can be trusted"},
+	{"schedule", "a plan of times"},
+	{"borrow", "take for a short time"},
+	{"delay", "make late"},
+	{"receipt", "proof of payment"},
+	{"journey", "a long trip"},
+	{"improve", "make better"},
+	{"explain", "make clear"},
+	{"arrive", "reach a place"},
+	{"cheap", "low in price"},
+	{"crowded", "full of people"},
+	{"decide", "make a choice"},
+	{"fluent", "speaking easily"},
+	{"polite", "having good manners"},
+	{"refund", "money given back"},
+	{"brief", "short in time"},
+}
+
+var reviewComments = []string{
+	"Useful, though some lessons felt short.",
+	"Clear explanations and good quizzes.",
+	"Excellent course, I use these phrases every day.",
+}
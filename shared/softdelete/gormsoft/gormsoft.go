@@ -0,0 +1,103 @@
+// Package gormsoft applies the soft-delete convention of the softdelete package to GORM
+// models, which embed Fields and read through Scope:
+//
+//	type Coupon struct {
+//		ID uuid.UUID `gorm:"type:uuid;primaryKey"`
+//		gormsoft.Fields
+//	}
+//
+//	db.Scopes(gormsoft.Active).First(&coupon, "id = ?", id)
+//	gormsoft.Delete(db.Model(&Coupon{}).Where("id = ?", id), softdelete.Now(adminID))
+//
+// Fields is not gorm.DeletedAt on purpose: the services choose which reads see deleted
+// rows, such as an admin restoring an account, instead of every query filtering them.
+package gormsoft
+
+import (
+	"database/sql"
+
+	"github.com/ductan2/microservice-app/shared/softdelete"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Fields are the soft-delete columns of a model.
+type Fields struct {
+	DeletedAt sql.NullTime `gorm:"type:timestamptz" json:"deleted_at,omitempty"`
+	DeletedBy *string      `gorm:"type:text" json:"deleted_by,omitempty"`
+}
+
+// IsDeleted reports whether the record is deleted.
+func (f Fields) IsDeleted() bool {
+	return f.DeletedAt.Valid
+}
+
+// Mark sets the fields of a deletion on a loaded record, for the stores saving whole
+// records. A deleted record keeps its first mark.
+func (f *Fields) Mark(mark softdelete.Mark) {
+	if f.DeletedAt.Valid {
+		return
+	}
+	f.DeletedAt = sql.NullTime{Time: mark.At, Valid: true}
+	f.DeletedBy = &mark.By
+}
+
+// Clear clears the fields of a loaded record being restored.
+func (f *Fields) Clear() {
+	f.DeletedAt = sql.NullTime{}
+	f.DeletedBy = nil
+}
+
+// Scope restricts a query to the rows of scope. The column is qualified with the table
+// of the model, so the condition holds in queries joining other tables.
+func Scope(scope softdelete.Scope) func(*gorm.DB) *gorm.DB {
+	column := clause.Column{Table: clause.CurrentTable, Name: softdelete.Column}
+	return func(db *gorm.DB) *gorm.DB {
+		switch scope {
+		case softdelete.Active:
+			return db.Where(clause.Eq{Column: column, Value: nil})
+		case softdelete.Deleted:
+			return db.Where(clause.Neq{Column: column, Value: nil})
+		}
+		return db
+	}
+}
+
+// Active restricts a query to the rows that are not deleted.
+func Active(db *gorm.DB) *gorm.DB {
+	return Scope(softdelete.Active)(db)
+}
+
+// Delete marks the rows of db, a query with a model and Where conditions, that are not
+// deleted yet. It returns the number of rows marked: 0 when they were already deleted or
+// do not exist. Without conditions it fails with gorm.ErrMissingWhereClause rather than
+// deleting the whole table.
+func Delete(db *gorm.DB, mark softdelete.Mark) (int64, error) {
+	if !conditioned(db) {
+		return 0, gorm.ErrMissingWhereClause
+	}
+	result := db.Scopes(Active).Updates(map[string]any{
+		softdelete.Column:   mark.At,
+		softdelete.ByColumn: mark.By,
+	})
+	return result.RowsAffected, result.Error
+}
+
+// Restore clears the deletion of the deleted rows of db, a query with a model and Where
+// conditions, and returns how many it restored.
+func Restore(db *gorm.DB) (int64, error) {
+	if !conditioned(db) {
+		return 0, gorm.ErrMissingWhereClause
+	}
+	result := db.Scopes(Scope(softdelete.Deleted)).Updates(map[string]any{
+		softdelete.Column:   nil,
+		softdelete.ByColumn: nil,
+	})
+	return result.RowsAffected, result.Error
+}
+
+// conditioned reports whether db has Where conditions, before the scope adds its own.
+func conditioned(db *gorm.DB) bool {
+	_, ok := db.Statement.Clauses["WHERE"]
+	return ok
+}
@@ -0,0 +1,107 @@
+package gormsoft
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/softdelete"
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/migrator"
+	"gorm.io/gorm/schema"
+)
+
+// dryRun is a dialector building PostgreSQL-like statements without a database.
+type dryRun struct{}
+
+func (dryRun) Name() string { return "dryrun" }
+
+func (dryRun) Initialize(db *gorm.DB) error {
+	callbacks.RegisterDefaultCallbacks(db, &callbacks.Config{})
+	return nil
+}
+
+func (dryRun) Migrator(db *gorm.DB) gorm.Migrator {
+	return migrator.Migrator{Config: migrator.Config{DB: db}}
+}
+
+func (dryRun) DataTypeOf(*schema.Field) string                     { return "" }
+func (dryRun) DefaultValueOf(*schema.Field) clause.Expression      { return clause.Expr{} }
+func (dryRun) BindVarTo(w clause.Writer, _ *gorm.Statement, _ any) { w.WriteByte('?') }
+func (dryRun) QuoteTo(w clause.Writer, s string)                   { w.WriteString(s) }
+func (dryRun) Explain(sql string, _ ...any) string                 { return sql }
+
+type coupon struct {
+	ID   string
+	Code string
+	Fields
+}
+
+func open(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(dryRun{}, &gorm.Config{DryRun: true, Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestScope(t *testing.T) {
+	db := open(t)
+	for scope, want := range map[softdelete.Scope]string{
+		softdelete.Active:  "SELECT * FROM coupons WHERE id = ? AND coupons.deleted_at IS NULL",
+		softdelete.Deleted: "SELECT * FROM coupons WHERE id = ? AND coupons.deleted_at IS NOT NULL",
+		softdelete.All:     "SELECT * FROM coupons WHERE id = ?",
+	} {
+		var found []coupon
+		stmt := db.Where("id = ?", "c1").Scopes(Scope(scope)).Find(&found).Statement
+		if got := stmt.SQL.String(); got != want {
+			t.Errorf("scope %q: %s, want %s", scope, got, want)
+		}
+	}
+}
+
+func TestDeleteAndRestore(t *testing.T) {
+	db := open(t)
+	mark := softdelete.Mark{At: time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), By: "admin-1"}
+
+	tx := db.Model(&coupon{}).Where("id = ?", "c1")
+	if _, err := Delete(tx, mark); err != nil {
+		t.Fatal(err)
+	}
+	want := "UPDATE coupons SET deleted_at=?,deleted_by=? WHERE id = ? AND coupons.deleted_at IS NULL"
+	if got := tx.Statement.SQL.String(); got != want {
+		t.Errorf("delete: %s, want %s", got, want)
+	}
+	if vars := tx.Statement.Vars; len(vars) != 3 || vars[1] != "admin-1" {
+		t.Errorf("delete vars %v", vars)
+	}
+
+	tx = db.Model(&coupon{}).Where("id = ?", "c1")
+	if _, err := Restore(tx); err != nil {
+		t.Fatal(err)
+	}
+	if got := tx.Statement.SQL.String(); !strings.HasSuffix(got, "WHERE id = ? AND coupons.deleted_at IS NOT NULL") || !strings.Contains(got, "deleted_by=?") {
+		t.Errorf("restore: %s", got)
+	}
+
+	if _, err := Delete(db.Model(&coupon{}), mark); err == nil {
+		t.Error("delete without conditions: want an error")
+	}
+}
+
+func TestFields(t *testing.T) {
+	var c coupon
+	first := softdelete.Mark{At: time.Now(), By: "admin-1"}
+	c.Mark(first)
+	c.Mark(softdelete.Mark{At: time.Now().Add(time.Hour), By: "admin-2"})
+	if !c.IsDeleted() || !c.DeletedAt.Time.Equal(first.At) || *c.DeletedBy != "admin-1" {
+		t.Errorf("marked twice: %+v, want the first mark", c.Fields)
+	}
+	c.Clear()
+	if c.IsDeleted() || c.DeletedBy != nil {
+		t.Errorf("cleared: %+v", c.Fields)
+	}
+}
@@ -0,0 +1,101 @@
+// Package softdelete is the convention for deleting records across the services. A
+// deleted record keeps its row or document with two more fields, deleted_at and
+// deleted_by, so it can be restored and its history still points somewhere:
+//
+//   - Deleting marks a record that is not deleted yet; deleting it again changes nothing
+//     and keeps the first mark.
+//   - Restoring clears both fields; restoring a record that is not deleted changes
+//     nothing.
+//   - Reads see the records that are not deleted unless they ask for another Scope, so a
+//     deleted record is "not found" to everyone but the admin endpoints listing or
+//     restoring it.
+//
+// The package holds what the stores share, including the filters and updates of MongoDB
+// documents as plain maps (bson.M converts to and from them); gormsoft applies it to
+// GORM models.
+package softdelete
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// Column is the time a record was deleted, NULL or missing while it is not
+	Column = "deleted_at"
+	// ByColumn is who deleted the record: a user ID, or System
+	ByColumn = "deleted_by"
+	// System is the deleter of the records no user deleted, such as merged accounts
+	System = "system"
+	// QueryParam is the query parameter list endpoints read a Scope from
+	QueryParam = "deleted"
+)
+
+// Scope selects records by deletion.
+type Scope string
+
+const (
+	// Active is the default scope: records that are not deleted
+	Active Scope = ""
+	// Deleted selects the deleted records only, ?deleted=only
+	Deleted Scope = "only"
+	// All selects records whether deleted or not, ?deleted=include
+	All Scope = "include"
+)
+
+// ParseScope parses the value of QueryParam: "" or "exclude", "only" and "include".
+func ParseScope(value string) (Scope, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "exclude":
+		return Active, nil
+	case "only":
+		return Deleted, nil
+	case "include":
+		return All, nil
+	}
+	return Active, fmt.Errorf("softdelete: invalid scope %q, want exclude, only or include", value)
+}
+
+// Mark is a deletion: when it happened and who made it.
+type Mark struct {
+	At time.Time
+	By string
+}
+
+// Now is the mark of a deletion by the user by happening now, System when by is empty.
+func Now(by string) Mark {
+	if by == "" {
+		by = System
+	}
+	return Mark{At: time.Now().UTC(), By: by}
+}
+
+// MongoScope adds the condition of scope to a MongoDB filter and returns it; a nil
+// filter is allocated. A missing deleted_at counts as not deleted, so documents written
+// before the convention need no migration.
+//
+//	filter := softdelete.MongoScope(bson.M{"_id": id}, softdelete.Active)
+func MongoScope(filter map[string]any, scope Scope) map[string]any {
+	if filter == nil {
+		filter = map[string]any{}
+	}
+	switch scope {
+	case Active:
+		filter[Column] = nil
+	case Deleted:
+		filter[Column] = map[string]any{"$ne": nil}
+	}
+	return filter
+}
+
+// MongoUpdate is the update of a MongoDB document marking it deleted. Match the
+// document with MongoScope(filter, Active) so a second delete keeps the first mark.
+func (m Mark) MongoUpdate() map[string]any {
+	return map[string]any{"$set": map[string]any{Column: m.At, ByColumn: m.By}}
+}
+
+// MongoRestore is the update of a MongoDB document clearing its deletion.
+func MongoRestore() map[string]any {
+	return map[string]any{"$unset": map[string]any{Column: "", ByColumn: ""}}
+}
@@ -0,0 +1,51 @@
+package softdelete
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseScope(t *testing.T) {
+	for value, want := range map[string]Scope{"": Active, "exclude": Active, "only": Deleted, " Include ": All} {
+		if got, err := ParseScope(value); err != nil || got != want {
+			t.Errorf("ParseScope(%q) = %q, %v, want %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseScope("true"); err == nil {
+		t.Error(`ParseScope("true"): want an error`)
+	}
+}
+
+func TestNow(t *testing.T) {
+	if mark := Now(""); mark.By != System || mark.At.IsZero() || mark.At.Location() != time.UTC {
+		t.Errorf("Now(\"\") = %+v", mark)
+	}
+	if mark := Now("admin-1"); mark.By != "admin-1" {
+		t.Errorf("Now(admin-1).By = %q", mark.By)
+	}
+}
+
+func TestMongo(t *testing.T) {
+	for scope, want := range map[Scope]map[string]any{
+		Active:  {"_id": "c1", "deleted_at": nil},
+		Deleted: {"_id": "c1", "deleted_at": map[string]any{"$ne": nil}},
+		All:     {"_id": "c1"},
+	} {
+		if got := MongoScope(map[string]any{"_id": "c1"}, scope); !reflect.DeepEqual(got, want) {
+			t.Errorf("scope %q: filter %v, want %v", scope, got, want)
+		}
+	}
+	if got := MongoScope(nil, Active); len(got) != 1 {
+		t.Errorf("nil filter: %v", got)
+	}
+
+	at := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	want := map[string]any{"$set": map[string]any{"deleted_at": at, "deleted_by": "admin-1"}}
+	if got := (Mark{At: at, By: "admin-1"}).MongoUpdate(); !reflect.DeepEqual(got, want) {
+		t.Errorf("MongoUpdate = %v", got)
+	}
+	if got := MongoRestore(); !reflect.DeepEqual(got, map[string]any{"$unset": map[string]any{"deleted_at": "", "deleted_by": ""}}) {
+		t.Errorf("MongoRestore = %v", got)
+	}
+}
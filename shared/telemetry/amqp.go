@@ -0,0 +1,40 @@
+package telemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+)
+
+// headerCarrier adapts the headers of a RabbitMQ message (an amqp.Table) to the
+// propagators. Only string values are read.
+type headerCarrier map[string]any
+
+func (c headerCarrier) Get(key string) string {
+	value, _ := c[key].(string)
+	return value
+}
+
+func (c headerCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// InjectHeaders writes the trace context of ctx into the headers of a RabbitMQ message
+// (an amqp.Table), so the consumer continues the trace.
+func InjectHeaders(ctx context.Context, headers map[string]any) {
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier(headers))
+}
+
+// ExtractHeaders returns ctx carrying the trace context of the headers of a RabbitMQ
+// message, unchanged when they carry none. Start the consumer span from the result.
+func ExtractHeaders(ctx context.Context, headers map[string]any) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, headerCarrier(headers))
+}
@@ -0,0 +1,80 @@
+// Package gormtrace traces the statements of a gorm.DB: each one becomes a client span,
+// a child of the span on the context of the statement (set with db.WithContext).
+package gormtrace
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"github.com/ductan2/microservice-app/shared/telemetry"
+)
+
+// spanKey stores the span of a statement on its gorm.DB instance
+const spanKey = "telemetry:span"
+
+// Plugin registers the tracing callbacks. Install it with db.Use(gormtrace.Plugin{}).
+type Plugin struct{}
+
+// Name implements gorm.Plugin.
+func (Plugin) Name() string {
+	return "telemetry"
+}
+
+// Initialize implements gorm.Plugin.
+func (Plugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("telemetry:before_create", before("INSERT")),
+		cb.Create().After("gorm:create").Register("telemetry:after_create", after),
+		cb.Query().Before("gorm:query").Register("telemetry:before_query", before("SELECT")),
+		cb.Query().After("gorm:query").Register("telemetry:after_query", after),
+		cb.Update().Before("gorm:update").Register("telemetry:before_update", before("UPDATE")),
+		cb.Update().After("gorm:update").Register("telemetry:after_update", after),
+		cb.Delete().Before("gorm:delete").Register("telemetry:before_delete", before("DELETE")),
+		cb.Delete().After("gorm:delete").Register("telemetry:after_delete", after),
+		cb.Row().Before("gorm:row").Register("telemetry:before_row", before("ROW")),
+		cb.Row().After("gorm:row").Register("telemetry:after_row", after),
+		cb.Raw().Before("gorm:raw").Register("telemetry:before_raw", before("RAW")),
+		cb.Raw().After("gorm:raw").Register("telemetry:after_raw", after),
+	)
+}
+
+func before(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		if tx.Statement.Context == nil {
+			return
+		}
+		name := operation
+		if tx.Statement.Table != "" {
+			name += " " + tx.Statement.Table
+		}
+		_, span := telemetry.Start(tx.Statement.Context, name, trace.SpanKindClient)
+		span.SetAttributes(
+			attribute.String("db.system", tx.Dialector.Name()),
+			attribute.String("db.operation.name", operation),
+		)
+		if tx.Statement.Table != "" {
+			span.SetAttributes(attribute.String("db.collection.name", tx.Statement.Table))
+		}
+		tx.InstanceSet(spanKey, span)
+	}
+}
+
+func after(tx *gorm.DB) {
+	value, ok := tx.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+	if sql := tx.Statement.SQL.String(); sql != "" {
+		span.SetAttributes(attribute.String("db.query.text", sql))
+	}
+	span.SetAttributes(attribute.Int64("db.response.rows_affected", tx.Statement.RowsAffected))
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		telemetry.SetError(span, tx.Error)
+	}
+	span.End()
+}
@@ -0,0 +1,14 @@
+package telemetry
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// NewTransport wraps base (http.DefaultTransport when nil) to trace outgoing requests:
+// each one gets a client span, a child of the span on the request context, propagated
+// to the server in the traceparent header.
+func NewTransport(base http.RoundTripper) http.RoundTripper {
+	return otelhttp.NewTransport(base)
+}
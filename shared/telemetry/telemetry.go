@@ -0,0 +1,74 @@
+// Package telemetry sets up distributed tracing for the Go services on the OpenTelemetry
+// SDK: W3C Trace Context propagation and spans exported to a collector over OTLP/HTTP.
+//
+// A request entering a service continues the trace of its traceparent header, through
+// otelgin in the Gin services, or starts a new one. Every span started from the
+// resulting context is a child in the same trace: outgoing HTTP calls through
+// NewTransport, database statements through the gormtrace plugin, and messages
+// published to RabbitMQ, whose headers carry the trace on to the consumers
+// (InjectHeaders, ExtractHeaders). The services install the global TracerProvider with
+// Setup from the standard OTEL_* environment variables; without an exporter endpoint
+// spans are propagated but not exported.
+package telemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceparentHeader is the W3C Trace Context propagation header.
+const TraceparentHeader = "traceparent"
+
+// scopeName names this package as the instrumentation scope of the spans it starts.
+const scopeName = "github.com/ductan2/microservice-app/shared/telemetry"
+
+// Start starts a span with the global TracerProvider, a child of the span on ctx or the
+// root of a new trace. The returned context carries the new span.
+func Start(ctx context.Context, name string, kind trace.SpanKind) (context.Context, trace.Span) {
+	return otel.Tracer(scopeName).Start(ctx, name, trace.WithSpanKind(kind))
+}
+
+// StartFromTraceparent starts a span continuing the trace of a traceparent header value
+// received from another service. An invalid or empty value starts a new trace.
+func StartFromTraceparent(ctx context.Context, traceparent, name string, kind trace.SpanKind) (context.Context, trace.Span) {
+	return Start(ContextWithTraceparent(ctx, traceparent), name, kind)
+}
+
+// SetError marks span as failed with err. A nil err is ignored.
+func SetError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// ContextWithTraceparent returns ctx carrying the remote span of a traceparent header
+// value, unchanged when the value is invalid or empty.
+func ContextWithTraceparent(ctx context.Context, traceparent string) context.Context {
+	if traceparent == "" {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier{TraceparentHeader: traceparent})
+}
+
+// Traceparent returns the traceparent header value of the span on ctx, or "" when ctx
+// carries none.
+func Traceparent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	return carrier[TraceparentHeader]
+}
+
+// TraceID returns the trace ID of the span on ctx, or "" when ctx carries none.
+func TraceID(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.HasTraceID() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
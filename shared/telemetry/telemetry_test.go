@@ -0,0 +1,212 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+const remoteTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// useTracer makes a Tracer of cfg the global one for the test, recording the spans
+// that end.
+func useTracer(t *testing.T, cfg Config) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	tracer := NewTracer(cfg, sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(tracer.provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = tracer.Shutdown(context.Background())
+	})
+	return recorder
+}
+
+func TestStartContinuesTrace(t *testing.T) {
+	recorder := useTracer(t, Config{SampleRatio: 1})
+
+	ctx, root := Start(context.Background(), "root", trace.SpanKindServer)
+	_, child := Start(ctx, "child", trace.SpanKindInternal)
+	child.End()
+	root.End()
+
+	ended := recorder.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("recorded %d spans, want 2", len(ended))
+	}
+	if got, want := ended[0].Parent().SpanID(), root.SpanContext().SpanID(); got != want {
+		t.Fatalf("child has parent %s, want %s", got, want)
+	}
+	if ended[0].SpanContext().TraceID() != root.SpanContext().TraceID() {
+		t.Fatalf("child is in trace %s, want %s", ended[0].SpanContext().TraceID(), root.SpanContext().TraceID())
+	}
+	if TraceID(ctx) != root.SpanContext().TraceID().String() {
+		t.Fatalf("context does not carry the root span")
+	}
+}
+
+func TestStartFromTraceparent(t *testing.T) {
+	useTracer(t, Config{SampleRatio: 1})
+
+	_, span := StartFromTraceparent(context.Background(), remoteTraceparent, "GET /orders", trace.SpanKindServer)
+	if got := span.SpanContext().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("span is in trace %s, want the caller's", got)
+	}
+
+	for _, invalid := range []string{
+		"",
+		"00-00000000000000000000000000000000-0000000000000001-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	} {
+		_, span := StartFromTraceparent(context.Background(), invalid, "GET /orders", trace.SpanKindServer)
+		if !span.SpanContext().IsValid() || span.SpanContext().TraceID().String() == "4bf92f3577b34da6a3ce929d0e0e4736" {
+			t.Errorf("traceparent %q: span %v, want a new trace", invalid, span.SpanContext())
+		}
+	}
+}
+
+func TestTracerExportsSampledSpans(t *testing.T) {
+	received := make(chan *coltracepb.ExportTraceServiceRequest, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			t.Errorf("exported to %s", r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		req := &coltracepb.ExportTraceServiceRequest{}
+		if err := proto.Unmarshal(body, req); err != nil {
+			t.Errorf("export request is not OTLP: %v", err)
+		}
+		received <- req
+	}))
+	defer collector.Close()
+
+	tracer := NewTracer(Config{ServiceName: "test-service", Endpoint: collector.URL, SampleRatio: 1, FlushInterval: time.Hour})
+	_, span := tracer.provider.Tracer(scopeName).Start(context.Background(), "GET /health", trace.WithSpanKind(trace.SpanKindServer))
+	span.SetAttributes(attribute.Int("http.response.status_code", 503))
+	SetError(span, context.DeadlineExceeded)
+	span.End()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := tracer.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	req := <-received
+	resource := req.ResourceSpans[0]
+	var serviceName string
+	for _, attr := range resource.Resource.Attributes {
+		if attr.Key == "service.name" {
+			serviceName = attr.Value.GetStringValue()
+		}
+	}
+	if serviceName != "test-service" {
+		t.Fatalf("service.name is %q", serviceName)
+	}
+	exported := resource.ScopeSpans[0].Spans
+	if len(exported) != 1 {
+		t.Fatalf("exported %d spans, want 1", len(exported))
+	}
+	got := exported[0]
+	traceID := span.SpanContext().TraceID()
+	if string(got.TraceId) != string(traceID[:]) || got.Name != "GET /health" || got.Kind.String() != "SPAN_KIND_SERVER" {
+		t.Fatalf("unexpected span %v", got)
+	}
+	if got.Status.GetCode().String() != "STATUS_CODE_ERROR" || got.Attributes[0].Value.GetIntValue() != 503 {
+		t.Fatalf("status and attributes not exported: %v", got)
+	}
+}
+
+// TestSampling checks that new traces follow the sample ratio, continued traces the
+// decision of the caller, and that the decision propagates.
+func TestSampling(t *testing.T) {
+	recorder := useTracer(t, Config{SampleRatio: 0})
+
+	ctx, span := Start(context.Background(), "root", trace.SpanKindServer)
+	span.End()
+	if len(recorder.Ended()) != 0 {
+		t.Fatalf("recorded an unsampled span")
+	}
+	if !strings.HasSuffix(Traceparent(ctx), "-00") {
+		t.Fatalf("traceparent %q does not propagate the sampling decision", Traceparent(ctx))
+	}
+
+	_, span = StartFromTraceparent(context.Background(), remoteTraceparent, "continued", trace.SpanKindServer)
+	span.End()
+	if len(recorder.Ended()) != 1 {
+		t.Fatalf("dropped a span of a trace sampled by the caller")
+	}
+}
+
+func TestSetError(t *testing.T) {
+	recorder := useTracer(t, Config{SampleRatio: 1})
+
+	_, span := Start(context.Background(), "charge", trace.SpanKindClient)
+	SetError(span, nil)
+	SetError(span, errors.New("card declined"))
+	span.End()
+
+	if status := recorder.Ended()[0].Status(); status.Code != codes.Error || status.Description != "card declined" {
+		t.Fatalf("status = %+v, want the error", status)
+	}
+}
+
+func TestAMQPHeadersCarryTrace(t *testing.T) {
+	useTracer(t, Config{SampleRatio: 1})
+
+	ctx, span := Start(context.Background(), "publish", trace.SpanKindProducer)
+	headers := map[string]any{}
+	InjectHeaders(ctx, headers)
+
+	consumed := trace.SpanContextFromContext(ExtractHeaders(context.Background(), headers))
+	if consumed.TraceID() != span.SpanContext().TraceID() || consumed.SpanID() != span.SpanContext().SpanID() {
+		t.Fatalf("headers %v do not carry span %v", headers, span.SpanContext())
+	}
+
+	if trace.SpanContextFromContext(ExtractHeaders(context.Background(), map[string]any{})).IsValid() {
+		t.Fatalf("extracted a trace from empty headers")
+	}
+}
+
+func TestTransportPropagatesTraceparent(t *testing.T) {
+	useTracer(t, Config{SampleRatio: 1})
+
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(TraceparentHeader)
+	}))
+	defer server.Close()
+
+	ctx, span := Start(context.Background(), "checkout", trace.SpanKindServer)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	client := &http.Client{Transport: NewTransport(nil)}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	resp.Body.Close()
+
+	remote := trace.SpanContextFromContext(ContextWithTraceparent(context.Background(), got))
+	if !remote.IsValid() || remote.TraceID() != span.SpanContext().TraceID() {
+		t.Fatalf("server received traceparent %q, want trace %s", got, span.SpanContext().TraceID())
+	}
+	if remote.SpanID() == span.SpanContext().SpanID() {
+		t.Fatalf("traceparent names the caller span instead of the client span")
+	}
+}
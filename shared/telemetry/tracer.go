@@ -0,0 +1,126 @@
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+const (
+	defaultBatchSize     = 256
+	defaultFlushInterval = 5 * time.Second
+	queueSize            = 4096
+)
+
+// Config configures the TracerProvider of a service.
+type Config struct {
+	// ServiceName is the service.name resource attribute of the exported spans
+	ServiceName string
+	// Endpoint is the base URL of the OTLP/HTTP collector, e.g. http://otel-collector:4318.
+	// Spans are not exported when it is empty.
+	Endpoint string
+	// SampleRatio is the share of new traces that are sampled, between 0 and 1. Traces
+	// continued from another service keep the decision of their caller.
+	SampleRatio float64
+	// BatchSize is the most spans sent in one export request
+	BatchSize int
+	// FlushInterval is how long a span may wait for its batch to fill up
+	FlushInterval time.Duration
+}
+
+// ConfigFromEnv reads the standard OpenTelemetry variables: OTEL_SERVICE_NAME (default
+// serviceName), OTEL_EXPORTER_OTLP_TRACES_ENDPOINT or OTEL_EXPORTER_OTLP_ENDPOINT, and
+// OTEL_TRACES_SAMPLER_ARG (default 1). OTEL_SDK_DISABLED=true disables export.
+func ConfigFromEnv(serviceName string) Config {
+	cfg := Config{
+		ServiceName:   serviceName,
+		SampleRatio:   1,
+		BatchSize:     defaultBatchSize,
+		FlushInterval: defaultFlushInterval,
+	}
+	if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+		cfg.ServiceName = name
+	}
+	if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"); endpoint != "" {
+		cfg.Endpoint = strings.TrimSuffix(strings.TrimSuffix(endpoint, "/"), "/v1/traces")
+	} else {
+		cfg.Endpoint = strings.TrimSuffix(os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"), "/")
+	}
+	if ratio, err := strconv.ParseFloat(os.Getenv("OTEL_TRACES_SAMPLER_ARG"), 64); err == nil && ratio >= 0 && ratio <= 1 {
+		cfg.SampleRatio = ratio
+	}
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") {
+		cfg.Endpoint = ""
+	}
+	return cfg
+}
+
+// Tracer is the TracerProvider of a service.
+type Tracer struct {
+	provider *sdktrace.TracerProvider
+}
+
+// NewTracer returns the TracerProvider of cfg, sampling new traces at cfg.SampleRatio
+// and, when cfg has an endpoint, exporting the sampled spans in batches. Stop it with
+// Shutdown. opts are added to those of cfg.
+func NewTracer(cfg Config, opts ...sdktrace.TracerProviderOption) *Tracer {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultFlushInterval
+	}
+
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	}
+	if cfg.ServiceName != "" {
+		options = append(options, sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(cfg.ServiceName))))
+	}
+	if cfg.Endpoint != "" {
+		exporter, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(cfg.Endpoint+"/v1/traces"))
+		if err != nil {
+			slog.Warn("traces are not exported", "endpoint", cfg.Endpoint, "error", err)
+		} else {
+			options = append(options, sdktrace.WithBatcher(exporter,
+				sdktrace.WithMaxExportBatchSize(cfg.BatchSize),
+				sdktrace.WithBatchTimeout(cfg.FlushInterval),
+				sdktrace.WithMaxQueueSize(queueSize),
+			))
+		}
+	}
+	return &Tracer{provider: sdktrace.NewTracerProvider(append(options, opts...)...)}
+}
+
+func init() {
+	// Spans get IDs and propagate before Setup, as in tests, but are not exported
+	otel.SetTracerProvider(NewTracer(Config{SampleRatio: 1}).provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// Setup creates the TracerProvider of the service and makes it the global one, used by
+// Start and the otelgin and otelhttp instrumentation.
+func Setup(cfg Config) *Tracer {
+	t := NewTracer(cfg)
+	otel.SetTracerProvider(t.provider)
+	if cfg.Endpoint != "" {
+		slog.Info("exporting traces", "service", cfg.ServiceName, "endpoint", cfg.Endpoint)
+	}
+	return t
+}
+
+// Shutdown exports the spans already ended and stops the Tracer. Spans ending afterwards
+// are dropped.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	return t.provider.Shutdown(ctx)
+}
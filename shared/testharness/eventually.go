@@ -0,0 +1,20 @@
+package testharness
+
+import (
+	"testing"
+	"time"
+)
+
+// Eventually polls cond until it returns true, failing t when it has not within timeout.
+// Cross-service tests use it for effects that happen asynchronously, such as an event
+// relayed from the outbox and consumed by another service.
+func Eventually(t testing.TB, timeout time.Duration, cond func() bool, format string, args ...any) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf(format, args...)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
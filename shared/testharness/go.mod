@@ -0,0 +1,41 @@
+module github.com/ductan2/microservice-app/shared/testharness
+
+go 1.24.0
+
+require (
+	github.com/aws/aws-sdk-go-v2 v1.39.6
+	github.com/aws/aws-sdk-go-v2/credentials v1.18.16
+	github.com/aws/aws-sdk-go-v2/service/s3 v1.90.0
+	github.com/ductan2/microservice-app/shared/flags v0.0.0
+	github.com/ductan2/microservice-app/shared/flags/redisstore v0.0.0
+	github.com/ductan2/microservice-app/shared/inbox v0.0.0
+	github.com/ductan2/microservice-app/shared/inbox/redisstore v0.0.0
+	github.com/ductan2/microservice-app/shared/migrate v0.0.0
+	github.com/ductan2/microservice-app/shared/migrate/mongodriver v0.0.0
+	github.com/ductan2/microservice-app/shared/outbox v0.0.0
+	github.com/google/uuid v1.6.0
+	github.com/jackc/pgx/v5 v5.7.6
+	github.com/rabbitmq/amqp091-go v1.10.0
+	github.com/redis/go-redis/v9 v9.14.0
+	github.com/testcontainers/testcontainers-go v0.38.0
+	github.com/testcontainers/testcontainers-go/modules/minio v0.38.0
+	github.com/testcontainers/testcontainers-go/modules/mongodb v0.38.0
+	github.com/testcontainers/testcontainers-go/modules/postgres v0.38.0
+	github.com/testcontainers/testcontainers-go/modules/rabbitmq v0.38.0
+	github.com/testcontainers/testcontainers-go/modules/redis v0.38.0
+	go.mongodb.org/mongo-driver v1.17.3
+	gorm.io/driver/postgres v1.6.0
+	gorm.io/gorm v1.31.0
+)
+
+replace (
+	github.com/ductan2/microservice-app/shared/consumer => ../consumer
+	github.com/ductan2/microservice-app/shared/flags => ../flags
+	github.com/ductan2/microservice-app/shared/flags/redisstore => ../flags/redisstore
+	github.com/ductan2/microservice-app/shared/inbox => ../inbox
+	github.com/ductan2/microservice-app/shared/inbox/redisstore => ../inbox/redisstore
+	github.com/ductan2/microservice-app/shared/migrate => ../migrate
+	github.com/ductan2/microservice-app/shared/migrate/mongodriver => ../migrate/mongodriver
+	github.com/ductan2/microservice-app/shared/outbox => ../outbox
+	github.com/ductan2/microservice-app/shared/telemetry => ../telemetry
+)
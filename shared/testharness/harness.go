@@ -0,0 +1,209 @@
+// Package testharness runs the backing services of the Go services in containers for
+// integration tests: Postgres, MongoDB, Redis, RabbitMQ and MinIO, started with
+// testcontainers-go.
+//
+// The containers are started once per test binary by Run, from TestMain, and shared by
+// its tests. Each test takes a resource of its own on them, such as a Postgres database
+// with the service's migrations applied, a Redis logical database or a RabbitMQ virtual
+// host, so tests run in parallel without seeing each other's data. The resources are
+// dropped when the test ends.
+//
+// Integration tests carry the integration build tag, so go test ./... runs without
+// Docker:
+//
+//	//go:build integration
+package testharness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+)
+
+// Service is a backing service Run can start.
+type Service string
+
+const (
+	Postgres Service = "postgres"
+	Mongo    Service = "mongodb"
+	Redis    Service = "redis"
+	RabbitMQ Service = "rabbitmq"
+	MinIO    Service = "minio"
+)
+
+// Images of the containers, the versions infrastructure/docker-compose.yml runs.
+const (
+	PostgresImage = "postgres:17"
+	MongoImage    = "mongo:8"
+	RedisImage    = "redis:8.2"
+	RabbitMQImage = "rabbitmq:4.1-management"
+	MinIOImage    = "minio/minio:RELEASE.2024-01-16T16-07-38Z"
+)
+
+// StartTimeout bounds the start of all the containers, image pulls included.
+const StartTimeout = 5 * time.Minute
+
+// env holds the containers started by Run.
+type env struct {
+	postgres *postgresEnv
+	mongo    *mongoEnv
+	redis    *redisEnv
+	rabbitmq *rabbitmqEnv
+	minio    *minioEnv
+
+	mu         sync.Mutex
+	terminates []func(context.Context) error
+}
+
+var current *env
+
+var starters = map[Service]func(ctx context.Context, e *env) error{
+	Postgres: startPostgres,
+	Mongo:    startMongo,
+	Redis:    startRedis,
+	RabbitMQ: startRabbitMQ,
+	MinIO:    startMinIO,
+}
+
+// Run starts services, runs the tests of m and terminates the containers. It returns
+// the exit code of the tests, or 1 when Docker is unavailable or a container does not
+// start:
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(testharness.Run(m, testharness.Postgres, testharness.RabbitMQ))
+//	}
+func Run(m *testing.M, services ...Service) int {
+	e := &env{}
+	defer e.terminate()
+
+	ctx, cancel := context.WithTimeout(context.Background(), StartTimeout)
+	err := e.start(ctx, services)
+	cancel()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "testharness:", err)
+		return 1
+	}
+
+	current = e
+	defer func() { current = nil }()
+	return m.Run()
+}
+
+func (e *env) start(ctx context.Context, services []Service) error {
+	provider, err := testcontainers.NewDockerProvider()
+	if err != nil {
+		return fmt.Errorf("docker is not available: %w", err)
+	}
+	defer provider.Close()
+	if err := provider.Health(ctx); err != nil {
+		return fmt.Errorf("docker is not available: %w", err)
+	}
+
+	// The containers start concurrently; the slowest one bounds the start
+	var wg sync.WaitGroup
+	errs := make([]error, len(services))
+	for i, service := range services {
+		start, ok := starters[service]
+		if !ok {
+			return fmt.Errorf("unknown service %q", service)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := start(ctx, e); err != nil {
+				errs[i] = fmt.Errorf("failed to start %s: %w", service, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// onTerminate registers fn to run when the tests end, in the reverse order of
+// registration.
+func (e *env) onTerminate(fn func(context.Context) error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.terminates = append(e.terminates, fn)
+}
+
+// terminateContainer stops and removes ctr when the tests end.
+func (e *env) terminateContainer(ctr testcontainers.Container) {
+	e.onTerminate(func(context.Context) error {
+		return testcontainers.TerminateContainer(ctr)
+	})
+}
+
+func (e *env) terminate() {
+	e.mu.Lock()
+	terminates := e.terminates
+	e.terminates = nil
+	e.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	for i := len(terminates) - 1; i >= 0; i-- {
+		if err := terminates[i](ctx); err != nil {
+			fmt.Fprintln(os.Stderr, "testharness: failed to clean up:", err)
+		}
+	}
+}
+
+// started returns the environment of Run, failing t when service was not started.
+func started(t testing.TB, service Service) *env {
+	t.Helper()
+	if current == nil || !current.has(service) {
+		t.Fatalf("testharness: %s is not running: pass it to testharness.Run in TestMain", service)
+	}
+	return current
+}
+
+func (e *env) has(service Service) bool {
+	switch service {
+	case Postgres:
+		return e.postgres != nil
+	case Mongo:
+		return e.mongo != nil
+	case Redis:
+		return e.redis != nil
+	case RabbitMQ:
+		return e.rabbitmq != nil
+	case MinIO:
+		return e.minio != nil
+	}
+	return false
+}
+
+var names atomic.Int64
+
+// uniqueName returns a name for a resource of t, unique in the test binary and made of
+// lowercase letters, digits and underscores so every service accepts it.
+func uniqueName(t testing.TB) string {
+	base := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		default:
+			return '_'
+		}
+	}, t.Name())
+	if len(base) > 40 {
+		base = base[:40]
+	}
+	return fmt.Sprintf("t%d_%s", names.Add(1), base)
+}
+
+// setupContext returns a context for setting up and cleaning up the resources of a test.
+func setupContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), time.Minute)
+}
@@ -0,0 +1,182 @@
+//go:build integration
+
+package testharness
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/ductan2/microservice-app/shared/flags"
+	flagstore "github.com/ductan2/microservice-app/shared/flags/redisstore"
+	"github.com/ductan2/microservice-app/shared/inbox"
+	inboxstore "github.com/ductan2/microservice-app/shared/inbox/redisstore"
+	"github.com/ductan2/microservice-app/shared/migrate"
+	"github.com/ductan2/microservice-app/shared/migrate/mongodriver"
+	"github.com/ductan2/microservice-app/shared/outbox"
+	"github.com/ductan2/microservice-app/shared/outbox/gormstore"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(Run(m, Postgres, Mongo, Redis, RabbitMQ, MinIO))
+}
+
+// The migrations of every service apply to an empty database and leave no drift.
+func TestServiceMigrations(t *testing.T) {
+	for _, service := range []string{"user-services", "order-services"} {
+		t.Run(service, func(t *testing.T) {
+			t.Parallel()
+			files := os.DirFS("../../" + service + "/migrations")
+			db := NewPostgres(t, files)
+
+			loaded, err := migrate.Load(files, ".sql")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := migrate.New(migrate.NewPostgres(db.DB, migrate.DefaultTable), loaded).Check(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	t.Run("content-services", func(t *testing.T) {
+		t.Parallel()
+		files := os.DirFS("../../content-services/migrations")
+		db := NewMongo(t, files)
+
+		loaded, err := migrate.Load(files, mongodriver.Ext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := migrate.New(mongodriver.New(db.Database, ""), loaded).Check(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// An event written to the outbox of order-services reaches a consumer bound to the
+// exchange, as the other services consume it.
+func TestOutboxRelaysToRabbitMQ(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := NewPostgres(t, os.DirFS("../../order-services/migrations"))
+	gormDB, err := gorm.Open(postgres.Open(db.DSN), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := gormstore.New(gormDB, "")
+
+	broker := NewRabbitMQ(t)
+	deliveries := broker.Consume(t, "harness.events", "order.#")
+	channel, err := broker.Dial(t).Channel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	publisher, err := outbox.NewAMQPPublisher(channel, outbox.ToExchange("harness.events"), 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orderID := uuid.New()
+	if err := outbox.NewWriter(store).Write(ctx, orderID, "order.created", "order.created", map[string]string{"order_id": orderID.String()}); err != nil {
+		t.Fatal(err)
+	}
+	relay := outbox.NewRelay(store, publisher, outbox.Config{BatchSize: 10, MaxAttempts: 3, BackoffBase: time.Second, BackoffMax: time.Second})
+	if err := relay.ProcessDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case delivery := <-deliveries:
+		if delivery.RoutingKey != "order.created" || !bytes.Contains(delivery.Body, []byte(orderID.String())) {
+			t.Fatalf("unexpected delivery %s: %s", delivery.RoutingKey, delivery.Body)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("the event was not delivered")
+	}
+
+	if stats, err := store.Stats(ctx); err != nil || stats.Pending != 0 {
+		t.Fatalf("outbox after the relay: %+v, %v", stats, err)
+	}
+}
+
+func TestInboxRedisStore(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	store := inboxstore.New(NewRedis(t), 0)
+
+	status, err := store.Claim(ctx, "content-services", "message-1", time.Minute)
+	if err != nil || status != inbox.Claimed {
+		t.Fatalf("first claim: %v, %v", status, err)
+	}
+	if status, _ := store.Claim(ctx, "content-services", "message-1", time.Minute); status != inbox.InProgress {
+		t.Fatalf("claim while processing: %v", status)
+	}
+	if err := store.Complete(ctx, "content-services", "message-1"); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := store.Claim(ctx, "content-services", "message-1", time.Minute); status != inbox.Processed {
+		t.Fatalf("claim of a processed message: %v", status)
+	}
+}
+
+func TestFlagsRedisStore(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	client := NewRedis(t)
+	store := flagstore.New(client)
+
+	if err := store.Put(ctx, flags.Flag{Name: flags.NewCheckoutFlow, Enabled: true, RolloutPercent: 20}); err != nil {
+		t.Fatal(err)
+	}
+	// Kill switches written by hand are plain booleans
+	if err := client.HSet(ctx, flagstore.Key, "maintenance_banner", "true").Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	listed, err := store.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if listed[flags.NewCheckoutFlow].RolloutPercent != 20 || !listed["maintenance_banner"].EnabledFor(flags.Subject{}) {
+		t.Fatalf("unexpected flags %+v", listed)
+	}
+	if err := store.Delete(ctx, flags.NewCheckoutFlow); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(ctx, flags.NewCheckoutFlow); !errors.Is(err, flags.ErrNotFound) {
+		t.Fatalf("second delete: %v", err)
+	}
+}
+
+func TestBucket(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	bucket := NewBucket(t)
+
+	_, err := bucket.Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(bucket.Name),
+		Key:    aws.String("media/cover.png"),
+		Body:   bytes.NewReader([]byte("image")),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	object, err := bucket.Client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(bucket.Name), Key: aws.String("media/cover.png")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer object.Body.Close()
+	if body, _ := io.ReadAll(object.Body); string(body) != "image" {
+		t.Fatalf("read %q", body)
+	}
+}
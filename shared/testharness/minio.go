@@ -0,0 +1,111 @@
+package testharness
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/testcontainers/testcontainers-go/modules/minio"
+)
+
+// MinIORegion is the region the S3 clients of the harness sign requests for.
+const MinIORegion = "us-east-1"
+
+type minioEnv struct {
+	endpoint  string
+	accessKey string
+	secretKey string
+}
+
+func startMinIO(ctx context.Context, e *env) error {
+	ctr, err := minio.Run(ctx, MinIOImage)
+	if ctr != nil {
+		e.terminateContainer(ctr)
+	}
+	if err != nil {
+		return err
+	}
+
+	address, err := ctr.ConnectionString(ctx)
+	if err != nil {
+		return err
+	}
+	e.minio = &minioEnv{endpoint: "http://" + address, accessKey: ctr.Username, secretKey: ctr.Password}
+	return nil
+}
+
+// Bucket is an S3 bucket of one test on the shared MinIO container.
+type Bucket struct {
+	// Client is an S3 client for the container, using path-style addressing
+	Client    *s3.Client
+	Name      string
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+}
+
+// NewBucket creates a bucket for t and deletes it, with its objects, when t ends.
+func NewBucket(t testing.TB) *Bucket {
+	t.Helper()
+	mn := started(t, MinIO).minio
+	ctx, cancel := setupContext()
+	defer cancel()
+
+	client := s3.New(s3.Options{
+		Region:       MinIORegion,
+		BaseEndpoint: aws.String(mn.endpoint),
+		UsePathStyle: true,
+		Credentials:  credentials.NewStaticCredentialsProvider(mn.accessKey, mn.secretKey, ""),
+	})
+	// Bucket names take hyphens, not underscores
+	name := strings.TrimRight(strings.ReplaceAll(uniqueName(t), "_", "-"), "-")
+	if _, err := client.CreateBucket(ctx, &s3.CreateBucketInput{Bucket: aws.String(name)}); err != nil {
+		t.Fatalf("testharness: failed to create bucket %s: %v", name, err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := setupContext()
+		defer cancel()
+		if err := deleteBucket(ctx, client, name); err != nil {
+			t.Errorf("testharness: failed to delete bucket %s: %v", name, err)
+		}
+	})
+
+	return &Bucket{
+		Client:    client,
+		Name:      name,
+		Endpoint:  mn.endpoint,
+		AccessKey: mn.accessKey,
+		SecretKey: mn.secretKey,
+	}
+}
+
+func deleteBucket(ctx context.Context, client *s3.Client, name string) error {
+	objects := s3.NewListObjectsV2Paginator(client, &s3.ListObjectsV2Input{Bucket: aws.String(name)})
+	for objects.HasMorePages() {
+		page, err := objects.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		for _, object := range page.Contents {
+			if _, err := client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(name), Key: object.Key}); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := client.DeleteBucket(ctx, &s3.DeleteBucketInput{Bucket: aws.String(name)})
+	return err
+}
+
+// Setenv points the S3_* settings of the services at the bucket for the rest of t. Like
+// testing.T.Setenv, it cannot be used in parallel tests.
+func (b *Bucket) Setenv(t testing.TB) {
+	t.Setenv("S3_ENDPOINT", b.Endpoint)
+	t.Setenv("S3_REGION", MinIORegion)
+	t.Setenv("S3_BUCKET", b.Name)
+	t.Setenv("S3_ACCESS_KEY_ID", b.AccessKey)
+	t.Setenv("S3_SECRET_ACCESS_KEY", b.SecretKey)
+	t.Setenv("S3_USE_PATH_STYLE", "true")
+}
@@ -0,0 +1,85 @@
+package testharness
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+
+	"github.com/ductan2/microservice-app/shared/migrate"
+	"github.com/ductan2/microservice-app/shared/migrate/mongodriver"
+	"github.com/testcontainers/testcontainers-go/modules/mongodb"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoEnv struct {
+	client *mongo.Client
+	uri    string
+}
+
+func startMongo(ctx context.Context, e *env) error {
+	ctr, err := mongodb.Run(ctx, MongoImage)
+	if ctr != nil {
+		e.terminateContainer(ctr)
+	}
+	if err != nil {
+		return err
+	}
+
+	uri, err := ctr.ConnectionString(ctx)
+	if err != nil {
+		return err
+	}
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		return err
+	}
+	e.onTerminate(client.Disconnect)
+
+	e.mongo = &mongoEnv{client: client, uri: uri}
+	return nil
+}
+
+// MongoDB is a database of one test on the shared MongoDB container.
+type MongoDB struct {
+	*mongo.Database
+	// URI connects to the container; the database is Name
+	URI string
+}
+
+// NewMongo creates a database for t, applies migrations to it when not nil, and drops it
+// when t ends. migrations holds the *.up.json files of a service.
+func NewMongo(t testing.TB, migrations fs.FS) *MongoDB {
+	t.Helper()
+	mg := started(t, Mongo).mongo
+	ctx, cancel := setupContext()
+	defer cancel()
+
+	db := mg.client.Database(uniqueName(t))
+	t.Cleanup(func() {
+		ctx, cancel := setupContext()
+		defer cancel()
+		if err := db.Drop(ctx); err != nil {
+			t.Errorf("testharness: failed to drop database %s: %v", db.Name(), err)
+		}
+	})
+
+	if migrations != nil {
+		files, err := migrate.Load(migrations, mongodriver.Ext)
+		if err != nil {
+			t.Fatalf("testharness: failed to load migrations: %v", err)
+		}
+		if _, err := migrate.New(mongodriver.New(db, ""), files).Up(ctx); err != nil {
+			t.Fatalf("testharness: failed to apply migrations: %v", err)
+		}
+	}
+
+	return &MongoDB{Database: db, URI: mg.uri}
+}
+
+// Setenv points the MONGO_URI and MONGO_DB settings of the services at the database for
+// the rest of t. Like testing.T.Setenv, it cannot be used in parallel tests.
+func (m *MongoDB) Setenv(t testing.TB) {
+	t.Setenv("MONGO_URI", m.URI)
+	t.Setenv("MONGO_DB", m.Name())
+}
@@ -0,0 +1,154 @@
+package testharness
+
+import (
+	"context"
+	"database/sql"
+	"io/fs"
+	"net/url"
+	"testing"
+
+	"github.com/ductan2/microservice-app/shared/migrate"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+)
+
+type postgresEnv struct {
+	// admin is connected to the maintenance database, to create and drop the databases
+	// of the tests
+	admin *sql.DB
+	dsn   *url.URL
+}
+
+func startPostgres(ctx context.Context, e *env) error {
+	ctr, err := postgres.Run(ctx, PostgresImage,
+		postgres.WithDatabase("harness"),
+		postgres.WithUsername("harness"),
+		postgres.WithPassword("harness"),
+		postgres.BasicWaitStrategies(),
+	)
+	if ctr != nil {
+		e.terminateContainer(ctr)
+	}
+	if err != nil {
+		return err
+	}
+
+	raw, err := ctr.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		return err
+	}
+	dsn, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	admin, err := sql.Open("pgx", raw)
+	if err != nil {
+		return err
+	}
+	e.onTerminate(func(context.Context) error { return admin.Close() })
+
+	e.postgres = &postgresEnv{admin: admin, dsn: dsn}
+	return nil
+}
+
+// PostgresDB is a database of one test on the shared Postgres container.
+type PostgresDB struct {
+	*sql.DB
+	// DSN is a postgres:// URL of the database, for drivers such as GORM
+	DSN      string
+	Host     string
+	Port     string
+	User     string
+	Password string
+	Name     string
+}
+
+// NewPostgres creates a database for t, applies migrations to it when not nil, and drops
+// it when t ends. migrations holds the *.up.sql files of a service, such as the
+// migrations.FS it embeds:
+//
+//	db := testharness.NewPostgres(t, migrations.FS)
+func NewPostgres(t testing.TB, migrations fs.FS) *PostgresDB {
+	t.Helper()
+	pg := started(t, Postgres).postgres
+	ctx, cancel := setupContext()
+	defer cancel()
+
+	name := uniqueName(t)
+	if _, err := pg.admin.ExecContext(ctx, "CREATE DATABASE "+name); err != nil {
+		t.Fatalf("testharness: failed to create database %s: %v", name, err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := setupContext()
+		defer cancel()
+		if _, err := pg.admin.ExecContext(ctx, "DROP DATABASE IF EXISTS "+name+" WITH (FORCE)"); err != nil {
+			t.Errorf("testharness: failed to drop database %s: %v", name, err)
+		}
+	})
+
+	dsn := *pg.dsn
+	dsn.Path = "/" + name
+	password, _ := dsn.User.Password()
+	db, err := sql.Open("pgx", dsn.String())
+	if err != nil {
+		t.Fatalf("testharness: failed to open database %s: %v", name, err)
+	}
+	// Registered after the drop, so it runs before it
+	t.Cleanup(func() { _ = db.Close() })
+
+	if migrations != nil {
+		files, err := migrate.Load(migrations, ".sql")
+		if err != nil {
+			t.Fatalf("testharness: failed to load migrations: %v", err)
+		}
+		if _, err := migrate.New(migrate.NewPostgres(db, migrate.DefaultTable), files).Up(ctx); err != nil {
+			t.Fatalf("testharness: failed to apply migrations: %v", err)
+		}
+	}
+
+	return &PostgresDB{
+		DB:       db,
+		DSN:      dsn.String(),
+		Host:     dsn.Hostname(),
+		Port:     dsn.Port(),
+		User:     dsn.User.Username(),
+		Password: password,
+		Name:     name,
+	}
+}
+
+// Seed runs the SQL files of fsys matching pattern, in lexical order, such as fixtures
+// a test builds on.
+func (p *PostgresDB) Seed(t testing.TB, fsys fs.FS, pattern string) {
+	t.Helper()
+	files, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		t.Fatalf("testharness: invalid seed pattern %q: %v", pattern, err)
+	}
+	if len(files) == 0 {
+		t.Fatalf("testharness: no seed file matches %q", pattern)
+	}
+
+	ctx, cancel := setupContext()
+	defer cancel()
+	for _, file := range files {
+		script, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			t.Fatalf("testharness: failed to read %s: %v", file, err)
+		}
+		if _, err := p.ExecContext(ctx, string(script)); err != nil {
+			t.Fatalf("testharness: failed to run %s: %v", file, err)
+		}
+	}
+}
+
+// Setenv points the DB_* settings of the services at the database for the rest of t,
+// so a service's config.Load connects to it. Like testing.T.Setenv, it cannot be used in
+// parallel tests.
+func (p *PostgresDB) Setenv(t testing.TB) {
+	t.Setenv("DB_HOST", p.Host)
+	t.Setenv("DB_PORT", p.Port)
+	t.Setenv("DB_USER", p.User)
+	t.Setenv("DB_PASSWORD", p.Password)
+	t.Setenv("DB_NAME", p.Name)
+}
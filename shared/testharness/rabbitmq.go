@@ -0,0 +1,166 @@
+package testharness
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/testcontainers/testcontainers-go/modules/rabbitmq"
+)
+
+type rabbitmqEnv struct {
+	amqpURL *url.URL
+	// api is the management API, which creates and deletes the virtual hosts of the tests
+	api      string
+	user     string
+	password string
+}
+
+func startRabbitMQ(ctx context.Context, e *env) error {
+	ctr, err := rabbitmq.Run(ctx, RabbitMQImage)
+	if ctr != nil {
+		e.terminateContainer(ctr)
+	}
+	if err != nil {
+		return err
+	}
+
+	raw, err := ctr.AmqpURL(ctx)
+	if err != nil {
+		return err
+	}
+	amqpURL, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	api, err := ctr.HttpURL(ctx)
+	if err != nil {
+		return err
+	}
+
+	e.rabbitmq = &rabbitmqEnv{amqpURL: amqpURL, api: api, user: ctr.AdminUsername, password: ctr.AdminPassword}
+	return nil
+}
+
+func (r *rabbitmqEnv) manage(ctx context.Context, method, path, body string) error {
+	req, err := http.NewRequestWithContext(ctx, method, r.api+path, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(r.user, r.password)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, detail)
+	}
+	return nil
+}
+
+// RabbitMQ is a virtual host of one test on the shared RabbitMQ container.
+type RabbitMQ struct {
+	// URL is an amqp:// URL of the virtual host
+	URL      string
+	Host     string
+	Port     string
+	User     string
+	Password string
+	VHost    string
+}
+
+// NewRabbitMQ creates a virtual host for t and deletes it, with its exchanges and
+// queues, when t ends.
+func NewRabbitMQ(t testing.TB) *RabbitMQ {
+	t.Helper()
+	rb := started(t, RabbitMQ).rabbitmq
+	ctx, cancel := setupContext()
+	defer cancel()
+
+	vhost := uniqueName(t)
+	path := "/api/vhosts/" + url.PathEscape(vhost)
+	if err := rb.manage(ctx, http.MethodPut, path, "{}"); err != nil {
+		t.Fatalf("testharness: failed to create virtual host %s: %v", vhost, err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := setupContext()
+		defer cancel()
+		if err := rb.manage(ctx, http.MethodDelete, path, ""); err != nil {
+			t.Errorf("testharness: failed to delete virtual host %s: %v", vhost, err)
+		}
+	})
+	permissions := "/api/permissions/" + url.PathEscape(vhost) + "/" + url.PathEscape(rb.user)
+	if err := rb.manage(ctx, http.MethodPut, permissions, `{"configure":".*","write":".*","read":".*"}`); err != nil {
+		t.Fatalf("testharness: failed to grant access to virtual host %s: %v", vhost, err)
+	}
+
+	amqpURL := *rb.amqpURL
+	amqpURL.Path = "/" + vhost
+	host, port, _ := net.SplitHostPort(amqpURL.Host)
+	return &RabbitMQ{
+		URL:      amqpURL.String(),
+		Host:     host,
+		Port:     port,
+		User:     rb.user,
+		Password: rb.password,
+		VHost:    vhost,
+	}
+}
+
+// Dial connects to the virtual host, closing the connection when t ends.
+func (r *RabbitMQ) Dial(t testing.TB) *amqp.Connection {
+	t.Helper()
+	conn, err := amqp.Dial(r.URL)
+	if err != nil {
+		t.Fatalf("testharness: failed to connect to RabbitMQ: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// Consume declares the topic exchange, binds a queue of its own to it with routingKey
+// and returns its deliveries, acked automatically. It lets a test see the events a
+// service publishes as another service would.
+func (r *RabbitMQ) Consume(t testing.TB, exchange, routingKey string) <-chan amqp.Delivery {
+	t.Helper()
+	channel, err := r.Dial(t).Channel()
+	if err != nil {
+		t.Fatalf("testharness: failed to open a channel: %v", err)
+	}
+	if err := channel.ExchangeDeclare(exchange, "topic", true, false, false, false, nil); err != nil {
+		t.Fatalf("testharness: failed to declare exchange %s: %v", exchange, err)
+	}
+	queue, err := channel.QueueDeclare("", false, true, true, false, nil)
+	if err != nil {
+		t.Fatalf("testharness: failed to declare a queue: %v", err)
+	}
+	if err := channel.QueueBind(queue.Name, routingKey, exchange, false, nil); err != nil {
+		t.Fatalf("testharness: failed to bind to %s: %v", exchange, err)
+	}
+	deliveries, err := channel.Consume(queue.Name, "", true, true, false, false, nil)
+	if err != nil {
+		t.Fatalf("testharness: failed to consume %s: %v", queue.Name, err)
+	}
+	return deliveries
+}
+
+// Setenv points the RABBITMQ_* settings of the services at the virtual host for the rest
+// of t. Like testing.T.Setenv, it cannot be used in parallel tests.
+func (r *RabbitMQ) Setenv(t testing.TB) {
+	t.Setenv("RABBITMQ_URL", r.URL)
+	t.Setenv("RABBITMQ_HOST", r.Host)
+	t.Setenv("RABBITMQ_PORT", r.Port)
+	t.Setenv("RABBITMQ_USER", r.User)
+	t.Setenv("RABBITMQ_PASSWORD", r.Password)
+	t.Setenv("RABBITMQ_VHOST", r.VHost)
+}
@@ -0,0 +1,80 @@
+package testharness
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	tcredis "github.com/testcontainers/testcontainers-go/modules/redis"
+)
+
+// redisDatabases is the number of logical databases of a Redis server.
+const redisDatabases = 16
+
+type redisEnv struct {
+	options *redis.Options
+	// free holds the logical databases no test is using
+	free chan int
+}
+
+func startRedis(ctx context.Context, e *env) error {
+	ctr, err := tcredis.Run(ctx, RedisImage)
+	if ctr != nil {
+		e.terminateContainer(ctr)
+	}
+	if err != nil {
+		return err
+	}
+
+	uri, err := ctr.ConnectionString(ctx)
+	if err != nil {
+		return err
+	}
+	options, err := redis.ParseURL(uri)
+	if err != nil {
+		return err
+	}
+
+	free := make(chan int, redisDatabases)
+	for db := range redisDatabases {
+		free <- db
+	}
+	e.redis = &redisEnv{options: options, free: free}
+	return nil
+}
+
+// NewRedis returns a client on a logical database of its own for t, flushed and
+// released when t ends. With more than 16 tests in parallel, the others wait for a
+// database to be released.
+func NewRedis(t testing.TB) *redis.Client {
+	t.Helper()
+	rd := started(t, Redis).redis
+
+	db := <-rd.free
+	options := *rd.options
+	options.DB = db
+	client := redis.NewClient(&options)
+	t.Cleanup(func() {
+		ctx, cancel := setupContext()
+		defer cancel()
+		if err := client.FlushDB(ctx).Err(); err != nil {
+			t.Errorf("testharness: failed to flush redis database %d: %v", db, err)
+		}
+		_ = client.Close()
+		rd.free <- db
+	})
+	return client
+}
+
+// SetenvRedis points the REDIS_* settings of the services at the database of client for
+// the rest of t. Like testing.T.Setenv, it cannot be used in parallel tests.
+func SetenvRedis(t testing.TB, client *redis.Client) {
+	options := client.Options()
+	host, port, _ := net.SplitHostPort(options.Addr)
+	t.Setenv("REDIS_HOST", host)
+	t.Setenv("REDIS_PORT", port)
+	t.Setenv("REDIS_PASSWORD", options.Password)
+	t.Setenv("REDIS_DB", strconv.Itoa(options.DB))
+}
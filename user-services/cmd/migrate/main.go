@@ -1,27 +1,47 @@
 package main
 
 import (
+	"context"
 	"flag"
-	"log"
+	"fmt"
+	"os"
 
 	"user-services/internal/db"
+
+	"github.com/ductan2/microservice-app/shared/logging"
+	"github.com/ductan2/microservice-app/shared/migrate"
 )
 
 func main() {
 	var dir string
-	flag.StringVar(&dir, "dir", "", "path to the migrations directory (defaults to MIGRATIONS_DIR or ./migrations)")
+	flag.StringVar(&dir, "dir", "", "path to a migrations directory to use instead of the embedded migrations (defaults to MIGRATIONS_DIR)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: migrate [-dir path] [command]\n%s\n", migrate.Usage)
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
+	logging.Setup(logging.ConfigFromEnv("user-services-migrate"))
+
 	gormDB, err := db.ConnectPostgres()
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		logging.Fatal("failed to connect to database", "error", err)
 	}
 	sqlDB, err := gormDB.DB()
 	if err == nil {
 		defer sqlDB.Close()
 	}
 
-	if err := db.RunMigrations(gormDB, dir); err != nil {
-		log.Fatalf("Migration failed: %v", err)
+	if command := flag.Arg(0); command == "" || command == "up" {
+		if err := db.EnsureUUIDExtension(gormDB); err != nil {
+			logging.Fatal("migration failed", "error", err)
+		}
+	}
+	migrator, err := db.Migrator(gormDB, dir)
+	if err != nil {
+		logging.Fatal("failed to load migrations", "error", err)
+	}
+	if err := migrate.Command(context.Background(), migrator, flag.Args(), os.Stdout); err != nil {
+		logging.Fatal("migration failed", "error", err)
 	}
 }
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"user-services/internal/db"
+	"user-services/internal/models"
+	"user-services/internal/utils"
+
+	"github.com/ductan2/microservice-app/shared/logging"
+	"github.com/ductan2/microservice-app/shared/seed"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// batchSize is how many users are written per transaction.
+const batchSize = 500
+
+func main() {
+	var cfg seed.Config
+	cfg.RegisterFlags(flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: seed [flags]\nWrites the users, sessions and activity of the seed plan; run the same flags against every service.\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	logging.Setup(logging.ConfigFromEnv("user-services-seed"))
+
+	gormDB, err := db.ConnectPostgres()
+	if err != nil {
+		logging.Fatal("failed to connect to database", "error", err)
+	}
+	sqlDB, err := gormDB.DB()
+	if err == nil {
+		defer sqlDB.Close()
+	}
+
+	// Hashing once keeps large plans fast; every account signs in with seed.Password
+	passwordHash, err := utils.HashPassword(seed.Password)
+	if err != nil {
+		logging.Fatal("failed to hash the seed password", "error", err)
+	}
+
+	users := seed.New(cfg).Users()
+	sessions := 0
+	for batch := range slices.Chunk(users, batchSize) {
+		n, err := writeUsers(gormDB, batch, passwordHash)
+		if err != nil {
+			logging.Fatal("failed to seed users", "error", err)
+		}
+		sessions += n
+	}
+	slog.Info("seeded users", "users", len(users), "sessions", sessions, "password", seed.Password)
+}
+
+// writeUsers saves a batch of users with their profiles, sessions and activity sessions
+// and returns the number of sessions. Rows seeded before are left as they are, so the
+// command can be run again, at the same or a larger scale.
+func writeUsers(gormDB *gorm.DB, batch []seed.User, passwordHash string) (int, error) {
+	var (
+		users      []models.User
+		profiles   []models.UserProfile
+		sessions   []models.Session
+		activities []models.UserActivitySession
+	)
+	for _, u := range batch {
+		userID := uuid.MustParse(u.ID)
+		user := models.User{
+			ID:              userID,
+			Email:           u.Email,
+			EmailNormalized: u.Email,
+			PasswordHash:    passwordHash,
+			EmailVerified:   true,
+			Status:          models.StatusActive,
+			Role:            u.Role,
+			CreatedAt:       u.CreatedAt,
+			UpdatedAt:       u.CreatedAt,
+		}
+		profiles = append(profiles, models.UserProfile{
+			UserID:      userID,
+			DisplayName: u.Name,
+			Locale:      u.Locale,
+			TimeZone:    u.TimeZone,
+			UpdatedAt:   u.CreatedAt,
+		})
+
+		for _, s := range u.Sessions {
+			sessionID := uuid.MustParse(s.ID)
+			ip := s.IPAddr
+			endedAt := s.StartedAt.Add(s.Duration)
+			device := utils.ParseUserAgent(s.UserAgent)
+			sessions = append(sessions, models.Session{
+				ID:         sessionID,
+				UserID:     userID,
+				UserAgent:  s.UserAgent,
+				IPAddr:     &ip,
+				DeviceType: device.DeviceType,
+				Browser:    device.Browser,
+				OS:         device.OS,
+				CreatedAt:  s.StartedAt,
+				ExpiresAt:  s.StartedAt.Add(7 * 24 * time.Hour),
+			})
+			// One activity session per session, under the same ID
+			activities = append(activities, models.UserActivitySession{
+				ID:         sessionID,
+				UserID:     userID,
+				SessionID:  sessionID,
+				StartedAt:  s.StartedAt,
+				EndedAt:    sql.NullTime{Time: endedAt, Valid: true},
+				DurationMs: s.Duration.Milliseconds(),
+				IPAddr:     &ip,
+				UserAgent:  s.UserAgent,
+				CreatedAt:  s.StartedAt,
+				UpdatedAt:  endedAt,
+			})
+			if !user.LastLoginAt.Valid || s.StartedAt.After(user.LastLoginAt.Time) {
+				user.LastLoginAt = sql.NullTime{Time: s.StartedAt, Valid: true}
+				user.LastLoginIP = &ip
+			}
+		}
+		users = append(users, user)
+	}
+
+	err := gormDB.Transaction(func(tx *gorm.DB) error {
+		tx = tx.Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Session(&gorm.Session{})
+		for _, rows := range []any{&users, &profiles, &sessions, &activities} {
+			if err := tx.CreateInBatches(rows, batchSize).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return len(sessions), err
+}
@@ -2,11 +2,9 @@ package main
 
 import (
 	"context"
-	"database/sql"
-	"log"
+	"log/slog"
+	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"user-services/internal/api/repositories"
@@ -15,80 +13,144 @@ import (
 	"user-services/internal/config"
 	"user-services/internal/db"
 	"user-services/internal/errors"
+	"user-services/internal/metrics"
 	"user-services/internal/queue"
 	"user-services/internal/server"
+	"user-services/internal/storage"
 	"user-services/internal/worker"
 
-	"github.com/gin-gonic/gin"
+	"github.com/ductan2/microservice-app/shared/envconfig"
+	"github.com/ductan2/microservice-app/shared/events"
+	"github.com/ductan2/microservice-app/shared/health"
+	"github.com/ductan2/microservice-app/shared/internalauth"
+	"github.com/ductan2/microservice-app/shared/lifecycle"
+	"github.com/ductan2/microservice-app/shared/logging"
+	sharedmetrics "github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/ductan2/microservice-app/shared/outbox"
+	"github.com/ductan2/microservice-app/shared/outbox/gormstore"
+	"github.com/ductan2/microservice-app/shared/retention"
+	"github.com/ductan2/microservice-app/shared/scheduler"
+	schedulerredis "github.com/ductan2/microservice-app/shared/scheduler/redisstore"
+	"github.com/ductan2/microservice-app/shared/secrets"
+	"github.com/ductan2/microservice-app/shared/telemetry"
 	"github.com/rabbitmq/amqp091-go"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
 
+// drainDelay is how long readiness fails before the servers stop accepting connections,
+// so the load balancer stops routing requests here first.
+const drainDelay = 2 * time.Second
+
 func main() {
-	// Load configuration
-	cfg, err := config.Load()
+	logging.Setup(logging.ConfigFromEnv("user-services"))
+
+	// Settings such as JWT_SECRET=secret://user-services/jwt are read from the secrets
+	// manager named by SECRETS_PROVIDER
+	secretStore, err := secrets.FromEnv()
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		logging.Fatal("invalid secrets configuration", "error", err)
+	}
+	if secretStore != nil {
+		envconfig.UseSecrets(secretStore)
 	}
 
-	if err := cfg.Validate(); err != nil {
-		log.Fatalf("Configuration validation failed: %v", err)
+	// "user-services config validate" checks the configuration of a deployment and exits
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(envconfig.Command(os.Args[2:], os.Stdout, func() (any, error) {
+			cfg, err := config.Load()
+			if err != nil {
+				return cfg, err
+			}
+			return cfg, cfg.Validate()
+		}))
 	}
 
-	// Setup graceful shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	// Load configuration; SIGHUP loads it again from the environment and .env
+	configs, err := envconfig.NewReloader(func() (*config.Config, error) {
+		cfg, err := config.Load()
+		if err != nil {
+			return nil, err
+		}
+		if err := cfg.Validate(); err != nil {
+			return nil, err
+		}
+		return cfg, nil
+	})
+	if err != nil {
+		logging.Fatal("invalid configuration", "error", err)
+	}
+	cfg := configs.Current()
 
-	// Create main context with cancellation
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// Set up again with the LOG_* settings of .env. They also apply on reload; the other
+	// settings need a restart
+	logging.Setup(logging.ConfigFromEnv("user-services"))
+	configs.OnReload(func(_, _ *config.Config) {
+		logging.Setup(logging.ConfigFromEnv("user-services"))
+	})
+	slog.Info("configuration loaded", "config", envconfig.Values(cfg))
+
+	// Components are stopped in the reverse order they are added, on SIGINT or SIGTERM:
+	// readiness, the servers, the background workers, the connections and the tracer
+	app := lifecycle.New(lifecycle.Config{})
+	app.Add(lifecycle.Worker("config-reload", configs.WatchSignals))
+	if secretStore != nil {
+		// A rotated secret is picked up by reloading the configuration
+		secretStore.OnRotate(func(string) {
+			if err := configs.Reload(); err != nil {
+				slog.Error("failed to reload configuration after a secret rotation", "error", err)
+			}
+		})
+		app.Add(lifecycle.Worker("secrets", secretStore.Run))
+	}
 
-	log.Printf("Starting User Services in %s mode", cfg.Environment)
-	log.Printf("Server will listen on port %s", cfg.Server.Port)
+	// Export traces to the OpenTelemetry collector
+	tracer := telemetry.Setup(telemetry.ConfigFromEnv("user-services"))
+	app.Add(lifecycle.Component{Name: "tracer", Stop: tracer.Shutdown})
+	sharedmetrics.SetBuildInfo("user-services")
+
+	slog.Info("starting user services", "environment", cfg.Environment, "port", cfg.Server.Port)
 
 	// Initialize dependencies
-	deps, err := initializeDependencies(ctx, cfg)
+	deps, err := initializeDependencies(context.Background(), cfg, app)
 	if err != nil {
-		log.Fatalf("Failed to initialize dependencies: %v", err)
+		logging.Fatal("failed to initialize dependencies", "error", err)
 	}
 
-	// Setup graceful shutdown cleanup
-	defer cleanupDependencies(deps)
-
 	// Start background workers
-	if err := startBackgroundWorkers(ctx, cfg, deps); err != nil {
-		log.Fatalf("Failed to start background workers: %v", err)
+	if err := startBackgroundWorkers(cfg, deps, app); err != nil {
+		logging.Fatal("failed to start background workers", "error", err)
 	}
 
-	// Initialize and start server
-	if err := startServer(ctx, cfg, deps); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	// Initialize the servers
+	if err := startServer(cfg, deps, app); err != nil {
+		logging.Fatal("failed to start server", "error", err)
 	}
+	app.Add(lifecycle.Drain(deps.Health.Shutdown, drainDelay))
 
-	// Wait for interrupt signal
-	<-quit
-	log.Println("Shutting down server gracefully...")
-
-	// Cancel context to signal shutdown
-	cancel()
-
-	// Give time for graceful shutdown
-	time.Sleep(2 * time.Second)
-	log.Println("Shutdown complete")
+	if err := app.Run(context.Background()); err != nil {
+		logging.Fatal("server stopped with errors", "error", err)
+	}
 }
 
 // Dependencies holds all application dependencies
 type Dependencies struct {
-	DB              interface{}
-	RedisClient     interface{}
-	RabbitConn      interface{}
-	RabbitCh        interface{}
-	OutboxProcessor interface{}
+	DB                  interface{}
+	RedisClient         interface{}
+	RabbitConn          interface{}
+	RabbitCh            interface{}
+	OutboxRelay         interface{}
+	DataExportProcessor interface{}
+	ErasureProcessor    interface{}
+	AvatarProcessor     interface{}
+	ActivityProcessor   interface{}
+	Health              *health.Checker
 }
 
 // initializeDependencies sets up all external connections and services
-func initializeDependencies(ctx context.Context, cfg *config.Config) (*Dependencies, error) {
+// initializeDependencies sets up all external connections and services. The connections
+// are closed by app once the workers using them stopped.
+func initializeDependencies(ctx context.Context, cfg *config.Config, app *lifecycle.Manager) (*Dependencies, error) {
 	deps := &Dependencies{}
 
 	// Connect to PostgreSQL with GORM
@@ -108,18 +170,32 @@ func initializeDependencies(ctx context.Context, cfg *config.Config) (*Dependenc
 	sqlDB.SetMaxOpenConns(cfg.Database.MaxOpenConns)
 	sqlDB.SetMaxIdleConns(cfg.Database.MaxIdleConns)
 	sqlDB.SetConnMaxIdleTime(cfg.Database.MaxIdleTime)
+	sharedmetrics.RegisterPool("postgres", sharedmetrics.SQLPool(sqlDB))
+	app.Add(lifecycle.Closer("postgres", sqlDB))
 
 	// Run database migrations
-	if err := db.RunMigrations(gormDB, ""); err != nil {
+	if err := db.RunMigrations(ctx, gormDB, cfg.Database.MigrateOnStart); err != nil {
 		return nil, errors.NewInternalError("Failed to run database migrations").WithCause(err)
 	}
 
+	// Route the listings and exports to the read replicas, if any
+	replicas, err := db.ConnectReplicas(gormDB)
+	if err != nil {
+		return nil, errors.ErrDatabaseConnection.WithCause(err)
+	}
+	if replicas != nil {
+		app.Add(lifecycle.Worker("read-replicas", replicas.Run))
+		app.Add(lifecycle.Closer("postgres-replicas", replicas))
+	}
+
 	// Connect to Redis
 	redisClient, err := cache.NewRedisClient(ctx)
 	if err != nil {
 		return nil, errors.ErrCacheConnection.WithCause(err)
 	}
 	deps.RedisClient = redisClient
+	sharedmetrics.RegisterPool("redis", redisPoolStats(redisClient))
+	app.Add(lifecycle.Closer("redis", redisClient))
 
 	// Connect to RabbitMQ
 	rabbitConn, rabbitCh, err := queue.NewRabbitMQ(ctx)
@@ -128,6 +204,8 @@ func initializeDependencies(ctx context.Context, cfg *config.Config) (*Dependenc
 	}
 	deps.RabbitConn = rabbitConn
 	deps.RabbitCh = rabbitCh
+	app.Add(lifecycle.Closer("rabbitmq", rabbitConn))
+	deps.Health = services.NewHealthChecker(gormDB, redisClient, rabbitConn)
 
 	// Declare exchange
 	err = rabbitCh.ExchangeDeclare(
@@ -143,91 +221,189 @@ func initializeDependencies(ctx context.Context, cfg *config.Config) (*Dependenc
 		return nil, errors.NewExternalServiceError("RabbitMQ", "Failed to declare exchange").WithCause(err)
 	}
 
-	log.Printf("Successfully connected to all external services")
+	// Keep security events until the SIEM shipper consumes them
+	if securityQueue := cfg.RabbitMQ.SecurityQueue; securityQueue != "" {
+		if _, err := rabbitCh.QueueDeclare(securityQueue, true, false, false, false, nil); err != nil {
+			return nil, errors.NewExternalServiceError("RabbitMQ", "Failed to declare security events queue").WithCause(err)
+		}
+		if err := rabbitCh.QueueBind(securityQueue, services.SecurityEventTopic, cfg.RabbitMQ.ExchangeName, false, nil); err != nil {
+			return nil, errors.NewExternalServiceError("RabbitMQ", "Failed to bind security events queue").WithCause(err)
+		}
+	}
+
+	slog.Info("connected to all external services")
 	return deps, nil
 }
 
-// cleanupDependencies handles graceful cleanup of resources
-func cleanupDependencies(deps *Dependencies) {
-	log.Println("Cleaning up dependencies...")
+// startBackgroundWorkers initializes background workers, run by app until the servers
+// stopped
+func startBackgroundWorkers(cfg *config.Config, deps *Dependencies, app *lifecycle.Manager) error {
+	gormDB := deps.DB
 
-	// Close RabbitMQ connection
-	if rabbitCh, ok := deps.RabbitCh.(interface{ Close() error }); ok {
-		if err := rabbitCh.Close(); err != nil {
-			log.Printf("Error closing RabbitMQ channel: %v", err)
+	// Start the outbox relay on a channel of its own, since it waits for publisher confirms.
+	// Without it the events wait in the outbox for the standalone outbox-relay
+	if cfg.Outbox.RelayEnabled {
+		relayCh, err := deps.RabbitConn.(*amqp091.Connection).Channel()
+		if err != nil {
+			return errors.NewExternalServiceError("RabbitMQ", "Failed to open outbox channel").WithCause(err)
 		}
-	}
-
-	if rabbitConn, ok := deps.RabbitConn.(interface{ Close() error }); ok {
-		if err := rabbitConn.Close(); err != nil {
-			log.Printf("Error closing RabbitMQ connection: %v", err)
+		publisher, err := outbox.NewAMQPPublisher(relayCh, outbox.ToExchange(cfg.RabbitMQ.ExchangeName), cfg.Outbox.ConfirmTimeout)
+		if err != nil {
+			return errors.NewExternalServiceError("RabbitMQ", "Failed to start outbox publisher").WithCause(err)
 		}
+		outboxRelay := outbox.NewRelay(gormstore.New(gormDB.(*gorm.DB), gormstore.DefaultTable), publisher, outbox.Config{
+			PollInterval: cfg.Outbox.PollInterval,
+			BatchSize:    cfg.Outbox.BatchSize,
+			MaxAttempts:  cfg.Outbox.MaxAttempts,
+			BackoffBase:  cfg.Outbox.BackoffBase,
+			BackoffMax:   cfg.Outbox.BackoffMax,
+			Retention:    cfg.Outbox.Retention,
+			Lease:        cfg.Outbox.Lease,
+			Metrics:      metrics.OutboxRelay{},
+			Validator:    events.Schemas,
+		})
+		app.Add(lifecycle.Worker("outbox-relay", outboxRelay.Start))
+		deps.OutboxRelay = outboxRelay
+	} else {
+		slog.Info("outbox relay disabled; events are published by outbox-relay")
 	}
 
-	// Close Redis connection
-	if redisClient, ok := deps.RedisClient.(interface{ Close() error }); ok {
-		if err := redisClient.Close(); err != nil {
-			log.Printf("Error closing Redis connection: %v", err)
-		}
-	}
-
-	// Close database connection
-	if gormDB, ok := deps.DB.(interface{ DB() (*sql.DB, error) }); ok {
-		if sqlDB, err := gormDB.DB(); err == nil {
-			if err := sqlDB.Close(); err != nil {
-				log.Printf("Error closing database connection: %v", err)
-			}
-		}
-	}
-}
+	outboxRepo := repositories.NewOutboxRepository(gormDB.(*gorm.DB))
 
-// startBackgroundWorkers initializes background workers
-func startBackgroundWorkers(ctx context.Context, cfg *config.Config, deps *Dependencies) error {
-	gormDB := deps.DB
-	rabbitCh := deps.RabbitCh
+	// Start Data Export Processor (builds takeout archives and deletes expired ones)
+	dataExportRepo := repositories.NewDataExportRepository(gormDB.(*gorm.DB))
+	userRepo := repositories.NewUserRepository(gormDB.(*gorm.DB))
+	sessionRepo := repositories.NewSessionRepository(gormDB.(*gorm.DB))
+	auditLogRepo := repositories.NewAuditLogRepository(gormDB.(*gorm.DB))
+	dataExportService := services.NewDataExportService(
+		dataExportRepo,
+		userRepo,
+		sessionRepo,
+		repositories.NewActivitySessionRepository(gormDB.(*gorm.DB)),
+		repositories.NewMFARepository(gormDB.(*gorm.DB)),
+		repositories.NewConsentRepository(gormDB.(*gorm.DB)),
+		auditLogRepo,
+		outboxRepo,
+		cfg.DataExport,
+	)
+	dataExportProcessor := worker.NewDataExportProcessor(dataExportService, cfg.DataExport.PollInterval)
+	app.Add(lifecycle.Worker("data-export-processor", dataExportProcessor.Start))
+	deps.DataExportProcessor = dataExportProcessor
+
+	// Start Erasure Processor (anonymizes accounts once their retention window ends)
+	erasureService := services.NewErasureService(
+		repositories.NewErasureRepository(gormDB.(*gorm.DB)),
+		userRepo,
+		dataExportRepo,
+		auditLogRepo,
+		outboxRepo,
+		services.NewSessionService(sessionRepo, cache.NewSessionCache(deps.RedisClient.(*redis.Client)), nil),
+		cfg.Erasure,
+	)
+	erasureProcessor := worker.NewErasureProcessor(erasureService, cfg.Erasure.PollInterval)
+	app.Add(lifecycle.Worker("erasure-processor", erasureProcessor.Start))
+	deps.ErasureProcessor = erasureProcessor
 
-	// Initialize Outbox Service
-	outboxRepo := repositories.NewOutboxRepository(gormDB.(*gorm.DB))
-	outboxService := services.NewOutboxService(outboxRepo, rabbitCh.(*amqp091.Channel), cfg.RabbitMQ.ExchangeName)
+	// Start Avatar Cleanup Processor (deletes replaced and abandoned avatar images)
+	avatarStore, err := storage.NewAvatarStore(cfg.Avatar)
+	if err != nil {
+		slog.Warn("avatar cleanup processor not started", "error", err)
+	} else if avatarStore != nil {
+		avatarService := services.NewAvatarService(
+			repositories.NewAvatarRepository(gormDB.(*gorm.DB)),
+			repositories.NewUserProfileRepository(gormDB.(*gorm.DB)),
+			auditLogRepo,
+			avatarStore,
+			cfg.Avatar,
+		)
+		avatarProcessor := worker.NewAvatarCleanupProcessor(avatarService, cfg.Avatar.CleanupInterval)
+		app.Add(lifecycle.Worker("avatar-cleanup-processor", avatarProcessor.Start))
+		deps.AvatarProcessor = avatarProcessor
+	}
 
-	// Start Outbox Processor
-	outboxProcessor := worker.NewOutboxProcessor(
-		outboxService,
-		5*time.Second, // TODO: Make configurable
-		10,            // TODO: Make configurable
+	// Start Activity Rollup Processor (daily and weekly study time per user)
+	activityRollupService := services.NewActivityRollupService(
+		repositories.NewActivityRollupRepository(gormDB.(*gorm.DB)),
+		userRepo,
+		cfg.Activity,
 	)
+	activityProcessor := worker.NewActivityRollupProcessor(activityRollupService, cfg.Activity.PollInterval)
+	app.Add(lifecycle.Worker("activity-rollup-processor", activityProcessor.Start))
+	deps.ActivityProcessor = activityProcessor
 
-	// Run outbox processor in background
-	go outboxProcessor.Start(ctx)
-	deps.OutboxProcessor = outboxProcessor
+	// Periodic jobs, see shared/scheduler: a lock in Redis makes one replica run each
+	// occurrence
+	sqlDB, err := gormDB.(*gorm.DB).DB()
+	if err != nil {
+		return errors.ErrDatabaseConnection.WithCause(err)
+	}
+	purger, err := retention.New(sqlDB, retention.Config{DryRun: cfg.Retention.DryRun, Overrides: cfg.Retention.Overrides}, repositories.RetentionRules()...)
+	if err != nil {
+		return err
+	}
+	jobs := scheduler.New(schedulerredis.New(deps.RedisClient.(*redis.Client)), scheduler.Config{Prefix: "scheduler:user-services"})
+	if err := jobs.Register(scheduler.Job{
+		Name:     "retention.purge",
+		Schedule: cfg.Retention.Schedule,
+		Run:      purger.Run,
+		Timeout:  30 * time.Minute,
+	}); err != nil {
+		return err
+	}
+	app.Add(lifecycle.Worker("scheduler", jobs.Run))
 
-	log.Println("Background workers started")
+	slog.Info("background workers started")
 	return nil
 }
 
-// startServer initializes and starts the HTTP server
-func startServer(ctx context.Context, cfg *config.Config, deps *Dependencies) error {
+// startServer initializes the HTTP server and the gRPC identity API, served by app
+func startServer(cfg *config.Config, deps *Dependencies, app *lifecycle.Manager) error {
+	// Other services authenticate with service tokens signed with the shared keys
+	serviceVerifier, err := internalauth.NewVerifier(cfg.Security.InternalAuth)
+	if err != nil {
+		return err
+	}
+	if cfg.Security.InternalAuth.Development {
+		slog.Warn("INTERNAL_AUTH_KEYS is not set; service tokens are signed with the development key")
+	}
+
 	// Initialize router with dependencies
 	r := server.NewRouter(server.Deps{
-		DB:          deps.DB.(*gorm.DB),
-		RedisClient: deps.RedisClient.(*redis.Client),
+		DB:              deps.DB.(*gorm.DB),
+		RedisClient:     deps.RedisClient.(*redis.Client),
+		RabbitConn:      deps.RabbitConn.(*amqp091.Connection),
+		ServiceVerifier: serviceVerifier,
+		Health:          deps.Health,
 	})
 
-	// Configure server with timeouts from configuration
-	r.Use(func(c *gin.Context) {
-		c.Request = c.Request.WithContext(ctx)
-		c.Next()
-	})
+	// Requests in flight get lifecycle.DefaultTimeout to finish on shutdown
+	srv := &http.Server{Addr: ":" + cfg.Server.Port, Handler: r}
+	slog.Info("server starting", "addr", srv.Addr)
+	app.Add(lifecycle.HTTPServer("http", srv))
+
+	// Start the internal gRPC identity API
+	if cfg.Server.GRPCPort != "" {
+		grpcServer := server.NewGRPCServer(server.Deps{DB: deps.DB.(*gorm.DB), ServiceVerifier: serviceVerifier})
+		addr := ":" + cfg.Server.GRPCPort
+		slog.Info("gRPC server starting", "addr", addr)
+		app.Add(grpcServer.Component("grpc", addr))
+	}
 
-	// Start server in goroutine
-	go func() {
-		addr := ":" + cfg.Server.Port
-		log.Printf("Server starting on %s", addr)
+	return nil
+}
 
-		if err := r.Run(addr); err != nil {
-			log.Printf("Server error: %v", err)
+// redisPoolStats reads the stats of the connection pool of client for the db_pool_*
+// metrics.
+func redisPoolStats(client *redis.Client) func() sharedmetrics.PoolStats {
+	return func() sharedmetrics.PoolStats {
+		s := client.PoolStats()
+		return sharedmetrics.PoolStats{
+			Open:         int(s.TotalConns),
+			InUse:        max(int(s.TotalConns)-int(s.IdleConns), 0),
+			Idle:         int(s.IdleConns),
+			MaxOpen:      client.Options().PoolSize,
+			WaitCount:    int64(s.WaitCount),
+			WaitDuration: time.Duration(s.WaitDurationNs),
 		}
-	}()
-
-	return nil
+	}
 }
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"net/http"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/middleware"
+	"user-services/internal/api/services"
+	"user-services/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AccessTokenController struct {
+	tokenService services.AccessTokenService
+}
+
+func NewAccessTokenController(tokenService services.AccessTokenService) *AccessTokenController {
+	return &AccessTokenController{
+		tokenService: tokenService,
+	}
+}
+
+// CreateToken godoc
+// @Summary Create a personal access token
+// @Description The token is returned only in this response.
+// @Tags access-tokens
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateAccessTokenRequest true "Token"
+// @Success 201 {object} dto.CreatedAccessTokenResponse
+// @Failure 409 {object} map[string]interface{}
+// @Router /users/me/access-tokens [post]
+func (c *AccessTokenController) CreateToken(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	var req dto.CreateAccessTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.tokenService.CreateToken(ctx.Request.Context(), userID.(uuid.UUID), req)
+	if err != nil {
+		failWithAppError(ctx, "Failed to create access token", err)
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	utils.Created(ctx, result)
+}
+
+// ListTokens godoc
+// @Summary List the caller's personal access tokens
+// @Tags access-tokens
+// @Produce json
+// @Success 200 {array} dto.AccessTokenResponse
+// @Router /users/me/access-tokens [get]
+func (c *AccessTokenController) ListTokens(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	result, err := c.tokenService.ListTokens(ctx.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		failWithAppError(ctx, "Failed to retrieve access tokens", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// RevokeToken godoc
+// @Summary Revoke a personal access token
+// @Tags access-tokens
+// @Produce json
+// @Param id path string true "Token ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /users/me/access-tokens/{id} [delete]
+func (c *AccessTokenController) RevokeToken(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+	tokenID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid token ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := c.tokenService.RevokeToken(ctx.Request.Context(), userID.(uuid.UUID), tokenID); err != nil {
+		failWithAppError(ctx, "Failed to revoke access token", err)
+		return
+	}
+
+	utils.Success(ctx, gin.H{"message": "Access token revoked"})
+}
+
+// Introspect godoc
+// @Summary Resolve a personal access token to its user (internal services only)
+// @Tags access-tokens
+// @Accept json
+// @Produce json
+// @Param request body dto.IntrospectAccessTokenRequest true "Token"
+// @Success 200 {object} dto.IntrospectAccessTokenResponse
+// @Failure 401 {object} map[string]interface{}
+// @Router /internal/access-tokens/introspect [post]
+func (c *AccessTokenController) Introspect(ctx *gin.Context) {
+	var req dto.IntrospectAccessTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.tokenService.Introspect(ctx.Request.Context(), req)
+	if err != nil {
+		failWithAppError(ctx, "Failed to verify access token", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"net/http"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/middleware"
+	"user-services/internal/api/services"
+	"user-services/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AccountMergeController struct {
+	mergeService services.AccountMergeService
+}
+
+func NewAccountMergeController(mergeService services.AccountMergeService) *AccountMergeController {
+	return &AccountMergeController{
+		mergeService: mergeService,
+	}
+}
+
+// RequestMerge godoc
+// @Summary Start merging another account into the caller's
+// @Description Emails a verification code to both accounts.
+// @Tags account-merges
+// @Accept json
+// @Produce json
+// @Param request body dto.RequestAccountMergeRequest true "Email of the account to merge"
+// @Success 201 {object} dto.AccountMergeResponse
+// @Failure 404 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
+// @Router /users/me/merges [post]
+func (c *AccountMergeController) RequestMerge(ctx *gin.Context) {
+	userID, ok := mergeCaller(ctx)
+	if !ok {
+		return
+	}
+
+	var req dto.RequestAccountMergeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.mergeService.RequestMerge(ctx.Request.Context(), userID, req)
+	if err != nil {
+		failWithAppError(ctx, "Failed to request account merge", err)
+		return
+	}
+
+	utils.Created(ctx, result)
+}
+
+// GetMerge godoc
+// @Summary Get one of the caller's account merges
+// @Tags account-merges
+// @Produce json
+// @Param id path string true "Merge ID"
+// @Success 200 {object} dto.AccountMergeResponse
+// @Failure 404 {object} map[string]interface{}
+// @Router /users/me/merges/{id} [get]
+func (c *AccountMergeController) GetMerge(ctx *gin.Context) {
+	userID, ok := mergeCaller(ctx)
+	if !ok {
+		return
+	}
+	mergeID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid merge ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.mergeService.GetMerge(ctx.Request.Context(), userID, mergeID)
+	if err != nil {
+		failWithAppError(ctx, "Failed to retrieve account merge", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// ConfirmMerge godoc
+// @Summary Enter the codes sent to both accounts and merge them
+// @Description The other account's sessions end and it is deleted; its data moves to the caller's account.
+// @Tags account-merges
+// @Accept json
+// @Produce json
+// @Param id path string true "Merge ID"
+// @Param request body dto.ConfirmAccountMergeRequest true "Codes"
+// @Success 200 {object} dto.AccountMergeResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /users/me/merges/{id}/confirm [post]
+func (c *AccountMergeController) ConfirmMerge(ctx *gin.Context) {
+	userID, ok := mergeCaller(ctx)
+	if !ok {
+		return
+	}
+	mergeID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid merge ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req dto.ConfirmAccountMergeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.mergeService.ConfirmMerge(ctx.Request.Context(), userID, mergeID, req)
+	if err != nil {
+		failWithAppError(ctx, "Failed to merge accounts", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// CancelMerge godoc
+// @Summary Cancel a pending account merge
+// @Tags account-merges
+// @Produce json
+// @Param id path string true "Merge ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /users/me/merges/{id} [delete]
+func (c *AccountMergeController) CancelMerge(ctx *gin.Context) {
+	userID, ok := mergeCaller(ctx)
+	if !ok {
+		return
+	}
+	mergeID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid merge ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := c.mergeService.CancelMerge(ctx.Request.Context(), userID, mergeID); err != nil {
+		failWithAppError(ctx, "Failed to cancel account merge", err)
+		return
+	}
+
+	utils.Success(ctx, gin.H{"message": "Account merge cancelled"})
+}
+
+func mergeCaller(ctx *gin.Context) (uuid.UUID, bool) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return uuid.Nil, false
+	}
+	return userID.(uuid.UUID), true
+}
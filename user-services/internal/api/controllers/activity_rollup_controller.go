@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"net/http"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/services"
+	"user-services/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ActivityRollupController struct {
+	rollupService services.ActivityRollupService
+}
+
+func NewActivityRollupController(rollupService services.ActivityRollupService) *ActivityRollupController {
+	return &ActivityRollupController{
+		rollupService: rollupService,
+	}
+}
+
+// GetUserRollups godoc
+// @Summary Get a user's daily or weekly study time (service-to-service)
+// @Description Lists the periods with any study time between from and to, both inclusive UTC dates, with their totals. Weeks start on Monday.
+// @Tags activity
+// @Produce json
+// @Param id path string true "User ID"
+// @Param period query string false "day (default) or week"
+// @Param from query string false "First date, YYYY-MM-DD"
+// @Param to query string false "Last date, YYYY-MM-DD, today when omitted"
+// @Success 200 {object} dto.ActivityRollupsResponse
+// @Router /internal/activity/users/{id}/rollups [get]
+func (c *ActivityRollupController) GetUserRollups(ctx *gin.Context) {
+	userID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid user ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var query dto.ActivityRollupQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		utils.Fail(ctx, "Invalid query parameters", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.rollupService.GetRollups(ctx.Request.Context(), userID, query)
+	if err != nil {
+		failWithAppError(ctx, "Failed to retrieve activity rollups", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
@@ -1,9 +1,15 @@
 package controllers
 
 import (
+	"net/http"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/middleware"
 	"user-services/internal/api/services"
+	"user-services/internal/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type AuditController struct {
@@ -16,17 +22,133 @@ func NewAuditController(auditService services.AuditService) *AuditController {
 	}
 }
 
+// GetMyAuditLogs godoc
+// @Summary Get the security events on the caller's own account
+// @Tags audit
+// @Produce json
+// @Param action query string false "Action, or a category such as mfa.*"
+// @Param from query string false "RFC 3339 start time (inclusive)"
+// @Param to query string false "RFC 3339 end time (exclusive)"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} dto.PaginatedResponse
+// @Router /audit/me [get]
+func (c *AuditController) GetMyAuditLogs(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	var query dto.AuditLogQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		utils.Fail(ctx, "Invalid query parameters", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.auditService.GetUserAuditLogs(ctx.Request.Context(), userID.(uuid.UUID), query)
+	if err != nil {
+		failWithAppError(ctx, "Failed to retrieve audit logs", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// GetMyLoginHistory godoc
+// @Summary Get the successful and failed sign-ins on the caller's account
+// @Tags audit
+// @Produce json
+// @Param result query string false "success or failure"
+// @Param from query string false "RFC 3339 start time (inclusive)"
+// @Param to query string false "RFC 3339 end time (exclusive)"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} dto.PaginatedResponse
+// @Router /users/me/login-history [get]
+func (c *AuditController) GetMyLoginHistory(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	var query dto.LoginHistoryQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		utils.Fail(ctx, "Invalid query parameters", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.auditService.GetLoginHistory(ctx.Request.Context(), userID.(uuid.UUID), query)
+	if err != nil {
+		failWithAppError(ctx, "Failed to retrieve login history", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
 // GetUserAuditLogs godoc
 // @Summary Get audit logs for a user (admin only)
 // @Tags audit
 // @Produce json
 // @Param id path string true "User ID"
+// @Param action query string false "Action, or a category such as mfa.*"
+// @Param from query string false "RFC 3339 start time (inclusive)"
+// @Param to query string false "RFC 3339 end time (exclusive)"
 // @Param page query int false "Page number"
 // @Param page_size query int false "Page size"
 // @Success 200 {object} dto.PaginatedResponse
 // @Router /audit/users/{id} [get]
 func (c *AuditController) GetUserAuditLogs(ctx *gin.Context) {
-	// TODO: implement
+	targetID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid user ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var query dto.AuditLogQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		utils.Fail(ctx, "Invalid query parameters", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.auditService.GetUserAuditLogs(ctx.Request.Context(), targetID, query)
+	if err != nil {
+		failWithAppError(ctx, "Failed to retrieve audit logs", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// ListAuditLogs godoc
+// @Summary Search audit logs across all users (admin only)
+// @Tags audit
+// @Produce json
+// @Param action query string false "Action, or a category such as mfa.*"
+// @Param user_id query string false "Affected user ID"
+// @Param actor_id query string false "Acting user ID"
+// @Param from query string false "RFC 3339 start time (inclusive)"
+// @Param to query string false "RFC 3339 end time (exclusive)"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} dto.PaginatedResponse
+// @Router /audit/logs [get]
+func (c *AuditController) ListAuditLogs(ctx *gin.Context) {
+	var query dto.AuditLogQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		utils.Fail(ctx, "Invalid query parameters", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.auditService.ListAuditLogs(ctx.Request.Context(), query)
+	if err != nil {
+		failWithAppError(ctx, "Failed to retrieve audit logs", err)
+		return
+	}
+
+	utils.Success(ctx, result)
 }
 
 // GetActionAuditLogs godoc
@@ -39,5 +161,9 @@ func (c *AuditController) GetUserAuditLogs(ctx *gin.Context) {
 // @Success 200 {object} dto.PaginatedResponse
 // @Router /audit/actions [get]
 func (c *AuditController) GetActionAuditLogs(ctx *gin.Context) {
-	// TODO: implement
+	if ctx.Query("action") == "" {
+		utils.Fail(ctx, "Action is required", http.StatusBadRequest, "missing action")
+		return
+	}
+	c.ListAuditLogs(ctx)
 }
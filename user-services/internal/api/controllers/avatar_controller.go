@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"net/http"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/middleware"
+	"user-services/internal/api/services"
+	"user-services/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AvatarController struct {
+	avatarService services.AvatarService
+}
+
+func NewAvatarController(avatarService services.AvatarService) *AvatarController {
+	return &AvatarController{
+		avatarService: avatarService,
+	}
+}
+
+// CreateUpload godoc
+// @Summary Start an avatar upload
+// @Description Returns a presigned URL the client uploads the image to, then completes the upload.
+// @Tags avatar
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateAvatarUploadRequest true "Image to upload"
+// @Success 201 {object} dto.AvatarUploadResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /users/me/avatar/uploads [post]
+func (c *AvatarController) CreateUpload(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	var req dto.CreateAvatarUploadRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.avatarService.CreateUpload(ctx.Request.Context(), userID.(uuid.UUID), req)
+	if err != nil {
+		failWithAppError(ctx, "Failed to start avatar upload", err)
+		return
+	}
+
+	utils.Created(ctx, result)
+}
+
+// CompleteUpload godoc
+// @Summary Use an uploaded image as the caller's avatar
+// @Description The image is checked, cropped to a square and scaled down before it is stored.
+// @Tags avatar
+// @Produce json
+// @Param id path string true "Upload ID"
+// @Success 200 {object} dto.AvatarResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /users/me/avatar/uploads/{id}/complete [post]
+func (c *AvatarController) CompleteUpload(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	uploadID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid upload ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.avatarService.CompleteUpload(ctx.Request.Context(), userID.(uuid.UUID), uploadID)
+	if err != nil {
+		failWithAppError(ctx, "Failed to complete avatar upload", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// RemoveAvatar godoc
+// @Summary Remove the caller's avatar
+// @Tags avatar
+// @Success 204
+// @Router /users/me/avatar [delete]
+func (c *AvatarController) RemoveAvatar(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	if err := c.avatarService.RemoveAvatar(ctx.Request.Context(), userID.(uuid.UUID)); err != nil {
+		failWithAppError(ctx, "Failed to remove avatar", err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"net/http"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/middleware"
+	"user-services/internal/api/services"
+	"user-services/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ConsentController struct {
+	consentService services.ConsentService
+}
+
+func NewConsentController(consentService services.ConsentService) *ConsentController {
+	return &ConsentController{
+		consentService: consentService,
+	}
+}
+
+// ListPolicies godoc
+// @Summary List the current version of the terms of service and privacy policy
+// @Tags consents
+// @Produce json
+// @Success 200 {array} dto.PolicyVersionResponse
+// @Router /policies [get]
+func (c *ConsentController) ListPolicies(ctx *gin.Context) {
+	result, err := c.consentService.ListPolicies(ctx.Request.Context())
+	if err != nil {
+		failWithAppError(ctx, "Failed to retrieve policies", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// ListPolicyVersions godoc
+// @Summary List every published version of a policy document
+// @Description Admin only; the BFF enforces the role.
+// @Tags consents
+// @Produce json
+// @Param document path string true "terms or privacy"
+// @Success 200 {array} dto.PolicyVersionResponse
+// @Router /policies/{document}/versions [get]
+func (c *ConsentController) ListPolicyVersions(ctx *gin.Context) {
+	var params dto.PolicyDocumentParam
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		utils.Fail(ctx, "Invalid policy document", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.consentService.ListPolicyVersions(ctx.Request.Context(), params.Document)
+	if err != nil {
+		failWithAppError(ctx, "Failed to retrieve policy versions", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// PublishPolicy godoc
+// @Summary Publish a new version of the terms of service or privacy policy
+// @Description Admin only; the BFF enforces the role. Unless requires_consent is false, every user is asked to accept the new version.
+// @Tags consents
+// @Accept json
+// @Produce json
+// @Param request body dto.PublishPolicyRequest true "Policy version"
+// @Success 201 {object} dto.PolicyVersionResponse
+// @Failure 409 {object} map[string]interface{}
+// @Router /policies [post]
+func (c *ConsentController) PublishPolicy(ctx *gin.Context) {
+	var req dto.PublishPolicyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.consentService.PublishPolicy(ctx.Request.Context(), req)
+	if err != nil {
+		failWithAppError(ctx, "Failed to publish policy", err)
+		return
+	}
+
+	utils.Created(ctx, result)
+}
+
+// GetConsents godoc
+// @Summary Show which policy versions the caller accepted and which still need consent
+// @Tags consents
+// @Produce json
+// @Success 200 {object} dto.ConsentStatusResponse
+// @Router /users/me/consents [get]
+func (c *ConsentController) GetConsents(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	result, err := c.consentService.GetConsents(ctx.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		failWithAppError(ctx, "Failed to retrieve consents", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// RecordConsent godoc
+// @Summary Accept the current version of policy documents
+// @Tags consents
+// @Accept json
+// @Produce json
+// @Param request body dto.RecordConsentRequest true "Accepted versions"
+// @Success 200 {object} dto.ConsentStatusResponse
+// @Failure 409 {object} map[string]interface{}
+// @Router /users/me/consents [post]
+func (c *ConsentController) RecordConsent(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	var req dto.RecordConsentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.consentService.RecordConsent(ctx.Request.Context(), userID.(uuid.UUID), req)
+	if err != nil {
+		failWithAppError(ctx, "Failed to record consent", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
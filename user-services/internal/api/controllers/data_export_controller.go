@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"net/http"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/middleware"
+	"user-services/internal/api/services"
+	"user-services/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type DataExportController struct {
+	dataExportService services.DataExportService
+	maxPartBytes      int64
+}
+
+func NewDataExportController(dataExportService services.DataExportService, maxPartBytes int64) *DataExportController {
+	return &DataExportController{
+		dataExportService: dataExportService,
+		maxPartBytes:      maxPartBytes,
+	}
+}
+
+// RequestExport godoc
+// @Summary Start an export of the caller's data
+// @Description Returns 201 with a new job, or 200 with the export already in progress.
+// @Tags data-exports
+// @Produce json
+// @Success 201 {object} dto.DataExportResponse
+// @Success 200 {object} dto.DataExportResponse
+// @Failure 429 {object} map[string]interface{}
+// @Router /data-exports [post]
+func (c *DataExportController) RequestExport(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	result, created, err := c.dataExportService.RequestExport(ctx.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		failWithAppError(ctx, "Failed to request data export", err)
+		return
+	}
+
+	if created {
+		utils.Created(ctx, result)
+		return
+	}
+	utils.Success(ctx, result)
+}
+
+// ListExports godoc
+// @Summary List the caller's recent data exports
+// @Tags data-exports
+// @Produce json
+// @Success 200 {array} dto.DataExportResponse
+// @Router /data-exports [get]
+func (c *DataExportController) ListExports(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	result, err := c.dataExportService.ListExports(ctx.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		failWithAppError(ctx, "Failed to retrieve data exports", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// GetExport godoc
+// @Summary Poll the status of a data export
+// @Tags data-exports
+// @Produce json
+// @Param id path string true "Export ID"
+// @Success 200 {object} dto.DataExportResponse
+// @Router /data-exports/{id} [get]
+func (c *DataExportController) GetExport(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	exportID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid export ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.dataExportService.GetExport(ctx.Request.Context(), userID.(uuid.UUID), exportID)
+	if err != nil {
+		failWithAppError(ctx, "Failed to retrieve data export", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// DownloadExport godoc
+// @Summary Download a finished data export as a zip archive
+// @Tags data-exports
+// @Produce application/zip
+// @Param id path string true "Export ID"
+// @Success 200 {file} file
+// @Failure 409 {object} map[string]interface{}
+// @Failure 410 {object} map[string]interface{}
+// @Router /data-exports/{id}/download [get]
+func (c *DataExportController) DownloadExport(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	exportID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid export ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	path, filename, err := c.dataExportService.OpenArchive(ctx.Request.Context(), userID.(uuid.UUID), exportID)
+	if err != nil {
+		failWithAppError(ctx, "Failed to download data export", err)
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Content-Type", "application/zip")
+	ctx.FileAttachment(path, filename)
+}
+
+// ReceivePart godoc
+// @Summary Deliver a service's share of a data export (service-to-service)
+// @Tags data-exports
+// @Accept json
+// @Produce json
+// @Param id path string true "Export ID"
+// @Param request body dto.DataExportPartRequest true "Part"
+// @Success 200 {object} map[string]interface{}
+// @Router /internal/data-exports/{id}/parts [post]
+func (c *DataExportController) ReceivePart(ctx *gin.Context) {
+	exportID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid export ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, c.maxPartBytes)
+	var req dto.DataExportPartRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := c.dataExportService.ReceivePart(ctx.Request.Context(), exportID, req.Source, req.Data); err != nil {
+		failWithAppError(ctx, "Failed to store data export part", err)
+		return
+	}
+
+	utils.Success(ctx, gin.H{"message": "Part received"})
+}
@@ -0,0 +1,244 @@
+package controllers
+
+import (
+	"net/http"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/middleware"
+	"user-services/internal/api/services"
+	"user-services/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ErasureController struct {
+	erasureService services.ErasureService
+}
+
+func NewErasureController(erasureService services.ErasureService) *ErasureController {
+	return &ErasureController{
+		erasureService: erasureService,
+	}
+}
+
+// RequestMyErasure godoc
+// @Summary Delete the caller's account and erase their data after the retention window
+// @Description Returns 201 with a new request, or 200 with the erasure already scheduled.
+// @Tags erasure
+// @Accept json
+// @Produce json
+// @Param request body dto.ErasureSelfRequest true "Password confirmation"
+// @Success 201 {object} dto.ErasureResponse
+// @Success 200 {object} dto.ErasureResponse
+// @Failure 401 {object} map[string]interface{}
+// @Router /erasure/me [post]
+func (c *ErasureController) RequestMyErasure(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	var req dto.ErasureSelfRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, created, err := c.erasureService.RequestErasure(ctx.Request.Context(), userID.(uuid.UUID), req.Password)
+	if err != nil {
+		failWithAppError(ctx, "Failed to request account erasure", err)
+		return
+	}
+
+	if created {
+		utils.Created(ctx, result)
+		return
+	}
+	utils.Success(ctx, result)
+}
+
+// DeactivateMyAccount godoc
+// @Summary Deactivate the caller's account, erasing it unless they sign in again within the cooling-off period
+// @Description Returns 201 with a new deactivation, or 200 with the deactivation or erasure already scheduled.
+// @Tags erasure
+// @Accept json
+// @Produce json
+// @Param request body dto.DeactivateAccountRequest true "Password confirmation and optional reason"
+// @Success 201 {object} dto.ErasureResponse
+// @Success 200 {object} dto.ErasureResponse
+// @Failure 401 {object} map[string]interface{}
+// @Router /erasure/me/deactivate [post]
+func (c *ErasureController) DeactivateMyAccount(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	var req dto.DeactivateAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, created, err := c.erasureService.DeactivateAccount(ctx.Request.Context(), userID.(uuid.UUID), req.Password, req.Reason)
+	if err != nil {
+		failWithAppError(ctx, "Failed to deactivate account", err)
+		return
+	}
+
+	if created {
+		utils.Created(ctx, result)
+		return
+	}
+	utils.Success(ctx, result)
+}
+
+// ScheduleUserErasure godoc
+// @Summary Delete a user's account and erase their data after the retention window (admin only)
+// @Tags erasure
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body dto.ErasureAdminRequest false "Reason"
+// @Success 201 {object} dto.ErasureResponse
+// @Success 200 {object} dto.ErasureResponse
+// @Failure 409 {object} map[string]interface{}
+// @Router /erasure/users/{id} [post]
+func (c *ErasureController) ScheduleUserErasure(ctx *gin.Context) {
+	targetID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid user ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req dto.ErasureAdminRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	result, created, err := c.erasureService.ScheduleErasure(ctx.Request.Context(), targetID, req.Reason)
+	if err != nil {
+		failWithAppError(ctx, "Failed to schedule account erasure", err)
+		return
+	}
+
+	if created {
+		utils.Created(ctx, result)
+		return
+	}
+	utils.Success(ctx, result)
+}
+
+// RecoverAccount godoc
+// @Summary Restore an account the user deleted before its retention window ends
+// @Description Cancels the scheduled erasure. Accounts deleted by an admin cannot be recovered this way.
+// @Tags erasure
+// @Accept json
+// @Produce json
+// @Param request body dto.ErasureRecoverRequest true "Credentials"
+// @Success 200 {object} dto.PublicUser
+// @Failure 401 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /erasure/recover [post]
+func (c *ErasureController) RecoverAccount(ctx *gin.Context) {
+	var req dto.ErasureRecoverRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.erasureService.RecoverAccount(ctx.Request.Context(), req.Email, req.Password)
+	if err != nil {
+		failWithAppError(ctx, "Failed to recover account", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// ListErasures godoc
+// @Summary List erasure requests (admin only)
+// @Tags erasure
+// @Produce json
+// @Param kind query string false "erasure or deactivation"
+// @Param status query string false "scheduled, cancelled, anonymized, completed or incomplete"
+// @Param user_id query string false "User ID"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} dto.PaginatedResponse
+// @Router /erasure/requests [get]
+func (c *ErasureController) ListErasures(ctx *gin.Context) {
+	var query dto.ErasureQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		utils.Fail(ctx, "Invalid query parameters", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.erasureService.ListErasures(ctx.Request.Context(), query)
+	if err != nil {
+		failWithAppError(ctx, "Failed to retrieve erasure requests", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// GetErasure godoc
+// @Summary Get the completion report of an erasure request (admin only)
+// @Tags erasure
+// @Produce json
+// @Param id path string true "Erasure request ID"
+// @Success 200 {object} dto.ErasureResponse
+// @Router /erasure/requests/{id} [get]
+func (c *ErasureController) GetErasure(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid erasure request ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.erasureService.GetErasure(ctx.Request.Context(), id)
+	if err != nil {
+		failWithAppError(ctx, "Failed to retrieve erasure request", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// Acknowledge godoc
+// @Summary Confirm a service has scrubbed an erased user's data (service-to-service)
+// @Tags erasure
+// @Accept json
+// @Produce json
+// @Param id path string true "Erasure request ID"
+// @Param request body dto.ErasureAckRequest true "Acknowledgement"
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /internal/erasures/{id}/acks [post]
+func (c *ErasureController) Acknowledge(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid erasure request ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req dto.ErasureAckRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := c.erasureService.Acknowledge(ctx.Request.Context(), id, req); err != nil {
+		failWithAppError(ctx, "Failed to record erasure acknowledgement", err)
+		return
+	}
+
+	utils.Success(ctx, gin.H{"message": "Acknowledgement recorded"})
+}
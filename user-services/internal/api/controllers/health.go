@@ -2,10 +2,12 @@ package controllers
 
 import (
 	"net/http"
+
 	"github.com/gin-gonic/gin"
 )
 
-// Health returns a simple health check response.
+// Health returns a simple health check response. It is kept for the clients of the
+// service predating /livez, /readyz and /healthz.
 func Health(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"status": "ok",
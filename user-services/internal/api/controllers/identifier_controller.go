@@ -0,0 +1,237 @@
+package controllers
+
+import (
+	"net/http"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/middleware"
+	"user-services/internal/api/services"
+	"user-services/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type IdentifierController struct {
+	identifierService services.IdentifierService
+}
+
+func NewIdentifierController(identifierService services.IdentifierService) *IdentifierController {
+	return &IdentifierController{
+		identifierService: identifierService,
+	}
+}
+
+// CheckUsername godoc
+// @Summary Check whether a username can be claimed
+// @Tags identifiers
+// @Produce json
+// @Param username query string true "Username"
+// @Success 200 {object} dto.IdentifierAvailabilityResponse
+// @Router /users/identifiers/username/availability [get]
+func (c *IdentifierController) CheckUsername(ctx *gin.Context) {
+	var query dto.UsernameAvailabilityQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		utils.Fail(ctx, "Invalid query parameters", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.identifierService.CheckUsername(ctx.Request.Context(), nil, query.Username)
+	if err != nil {
+		failWithAppError(ctx, "Failed to check username", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// GetIdentifiers godoc
+// @Summary List the caller's email, username and phone number
+// @Tags identifiers
+// @Produce json
+// @Success 200 {object} dto.LoginIdentifiersResponse
+// @Router /users/me/identifiers [get]
+func (c *IdentifierController) GetIdentifiers(ctx *gin.Context) {
+	userID, ok := identifierUserID(ctx)
+	if !ok {
+		return
+	}
+
+	result, err := c.identifierService.GetIdentifiers(ctx.Request.Context(), userID)
+	if err != nil {
+		failWithAppError(ctx, "Failed to retrieve identifiers", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// SetUsername godoc
+// @Summary Claim or change the caller's username
+// @Tags identifiers
+// @Accept json
+// @Produce json
+// @Param request body dto.SetUsernameRequest true "Username"
+// @Success 200 {object} dto.LoginIdentifiersResponse
+// @Failure 409 {object} map[string]interface{}
+// @Router /users/me/identifiers/username [put]
+func (c *IdentifierController) SetUsername(ctx *gin.Context) {
+	userID, ok := identifierUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var req dto.SetUsernameRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.identifierService.SetUsername(ctx.Request.Context(), userID, req.Username)
+	if err != nil {
+		failWithAppError(ctx, "Failed to set username", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// RemoveUsername godoc
+// @Summary Remove the caller's username
+// @Tags identifiers
+// @Produce json
+// @Success 200 {object} dto.LoginIdentifiersResponse
+// @Router /users/me/identifiers/username [delete]
+func (c *IdentifierController) RemoveUsername(ctx *gin.Context) {
+	userID, ok := identifierUserID(ctx)
+	if !ok {
+		return
+	}
+
+	result, err := c.identifierService.RemoveUsername(ctx.Request.Context(), userID)
+	if err != nil {
+		failWithAppError(ctx, "Failed to remove username", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// CheckPhone godoc
+// @Summary Check whether a phone number can be added for signing in
+// @Tags identifiers
+// @Produce json
+// @Param phone_number query string true "Phone number with country code"
+// @Success 200 {object} dto.IdentifierAvailabilityResponse
+// @Router /users/me/identifiers/phone/availability [get]
+func (c *IdentifierController) CheckPhone(ctx *gin.Context) {
+	userID, ok := identifierUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var query dto.PhoneAvailabilityQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		utils.Fail(ctx, "Invalid query parameters", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.identifierService.CheckPhone(ctx.Request.Context(), userID, query.PhoneNumber)
+	if err != nil {
+		failWithAppError(ctx, "Failed to check phone number", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// StartPhoneVerification godoc
+// @Summary Send a code to a phone number the caller wants to sign in with
+// @Tags identifiers
+// @Accept json
+// @Produce json
+// @Param request body dto.PhoneVerificationRequest true "Phone number and channel"
+// @Success 200 {object} dto.PhoneVerificationResponse
+// @Failure 409 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
+// @Router /users/me/identifiers/phone [post]
+func (c *IdentifierController) StartPhoneVerification(ctx *gin.Context) {
+	userID, ok := identifierUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var req dto.PhoneVerificationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.identifierService.StartPhoneVerification(ctx.Request.Context(), userID, req)
+	if err != nil {
+		failWithAppError(ctx, "Failed to send verification code", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// VerifyPhone godoc
+// @Summary Confirm the caller's phone number with the code sent to it
+// @Tags identifiers
+// @Accept json
+// @Produce json
+// @Param request body dto.VerifyPhoneRequest true "Phone number and code"
+// @Success 200 {object} dto.LoginIdentifiersResponse
+// @Failure 409 {object} map[string]interface{}
+// @Router /users/me/identifiers/phone/verify [post]
+func (c *IdentifierController) VerifyPhone(ctx *gin.Context) {
+	userID, ok := identifierUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var req dto.VerifyPhoneRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.identifierService.VerifyPhone(ctx.Request.Context(), userID, req)
+	if err != nil {
+		failWithAppError(ctx, "Failed to verify phone number", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// RemovePhone godoc
+// @Summary Stop signing in with the caller's phone number
+// @Tags identifiers
+// @Produce json
+// @Success 200 {object} dto.LoginIdentifiersResponse
+// @Router /users/me/identifiers/phone [delete]
+func (c *IdentifierController) RemovePhone(ctx *gin.Context) {
+	userID, ok := identifierUserID(ctx)
+	if !ok {
+		return
+	}
+
+	result, err := c.identifierService.RemovePhone(ctx.Request.Context(), userID)
+	if err != nil {
+		failWithAppError(ctx, "Failed to remove phone number", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+func identifierUserID(ctx *gin.Context) (uuid.UUID, bool) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return uuid.Nil, false
+	}
+	return userID.(uuid.UUID), true
+}
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"net/http"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/middleware"
+	"user-services/internal/api/services"
+	"user-services/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ImpersonationController struct {
+	impersonationService services.ImpersonationService
+}
+
+func NewImpersonationController(impersonationService services.ImpersonationService) *ImpersonationController {
+	return &ImpersonationController{
+		impersonationService: impersonationService,
+	}
+}
+
+// StartImpersonation godoc
+// @Summary Open a time-boxed session as a user (support only)
+// @Description Requires one of the roles in IMPERSONATION_ALLOWED_ROLES. Administrators cannot be impersonated.
+// @Tags impersonation
+// @Accept json
+// @Produce json
+// @Param request body dto.StartImpersonationRequest true "User, reason and duration"
+// @Success 201 {object} dto.ImpersonationResponse
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /impersonations [post]
+func (c *ImpersonationController) StartImpersonation(ctx *gin.Context) {
+	userID, ok := impersonationCaller(ctx)
+	if !ok {
+		return
+	}
+
+	var req dto.StartImpersonationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.impersonationService.StartImpersonation(ctx.Request.Context(), userID, ctx.GetHeader("User-Agent"), ctx.ClientIP(), req)
+	if err != nil {
+		failWithAppError(ctx, "Failed to start impersonation", err)
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	utils.Created(ctx, result)
+}
+
+// EndImpersonation godoc
+// @Summary End an impersonation session before it expires
+// @Tags impersonation
+// @Produce json
+// @Param id path string true "Impersonation session ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /impersonations/{id} [delete]
+func (c *ImpersonationController) EndImpersonation(ctx *gin.Context) {
+	userID, ok := impersonationCaller(ctx)
+	if !ok {
+		return
+	}
+	sessionID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid session ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := c.impersonationService.EndImpersonation(ctx.Request.Context(), userID, sessionID); err != nil {
+		failWithAppError(ctx, "Failed to end impersonation", err)
+		return
+	}
+
+	utils.Success(ctx, gin.H{"message": "Impersonation ended"})
+}
+
+func impersonationCaller(ctx *gin.Context) (uuid.UUID, bool) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return uuid.Nil, false
+	}
+	return userID.(uuid.UUID), true
+}
@@ -0,0 +1,203 @@
+package controllers
+
+import (
+	"net/http"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/middleware"
+	"user-services/internal/api/services"
+	"user-services/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type InvitationController struct {
+	invitationService services.InvitationService
+}
+
+func NewInvitationController(invitationService services.InvitationService) *InvitationController {
+	return &InvitationController{
+		invitationService: invitationService,
+	}
+}
+
+// CreateInvitation godoc
+// @Summary Invite someone to register
+// @Description Admins may invite with any role; organization owners and managers only students into their organization.
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateInvitationRequest true "Invitation"
+// @Success 201 {object} dto.InvitationResponse
+// @Failure 409 {object} map[string]interface{}
+// @Router /invitations [post]
+func (c *InvitationController) CreateInvitation(ctx *gin.Context) {
+	callerID, ok := invitationCaller(ctx)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateInvitationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.invitationService.CreateInvitation(ctx.Request.Context(), callerID, req)
+	if err != nil {
+		failWithAppError(ctx, "Failed to create invitation", err)
+		return
+	}
+
+	utils.Created(ctx, result)
+}
+
+// ListInvitations godoc
+// @Summary List invitations
+// @Description Admins see every invitation; owners and managers must filter by their organization.
+// @Tags invitations
+// @Produce json
+// @Param organization_id query string false "Organization ID"
+// @Param status query string false "pending, accepted, revoked or expired"
+// @Param email query string false "Invitee email"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} dto.PaginatedResponse
+// @Router /invitations [get]
+func (c *InvitationController) ListInvitations(ctx *gin.Context) {
+	callerID, ok := invitationCaller(ctx)
+	if !ok {
+		return
+	}
+
+	var query dto.InvitationQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		utils.Fail(ctx, "Invalid query parameters", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.invitationService.ListInvitations(ctx.Request.Context(), callerID, query)
+	if err != nil {
+		failWithAppError(ctx, "Failed to retrieve invitations", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// ResendInvitation godoc
+// @Summary Email a pending invitation again with a new link
+// @Description The previous link stops working and the expiry starts over.
+// @Tags invitations
+// @Produce json
+// @Param id path string true "Invitation ID"
+// @Success 200 {object} dto.InvitationResponse
+// @Failure 429 {object} map[string]interface{}
+// @Router /invitations/{id}/resend [post]
+func (c *InvitationController) ResendInvitation(ctx *gin.Context) {
+	callerID, ok := invitationCaller(ctx)
+	if !ok {
+		return
+	}
+	invitationID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid invitation ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.invitationService.ResendInvitation(ctx.Request.Context(), callerID, invitationID)
+	if err != nil {
+		failWithAppError(ctx, "Failed to resend invitation", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// RevokeInvitation godoc
+// @Summary Revoke a pending invitation
+// @Tags invitations
+// @Produce json
+// @Param id path string true "Invitation ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /invitations/{id} [delete]
+func (c *InvitationController) RevokeInvitation(ctx *gin.Context) {
+	callerID, ok := invitationCaller(ctx)
+	if !ok {
+		return
+	}
+	invitationID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid invitation ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := c.invitationService.RevokeInvitation(ctx.Request.Context(), callerID, invitationID); err != nil {
+		failWithAppError(ctx, "Failed to revoke invitation", err)
+		return
+	}
+
+	utils.Success(ctx, gin.H{"message": "Invitation revoked"})
+}
+
+// PreviewInvitation godoc
+// @Summary Describe the invitation behind a link
+// @Tags invitations
+// @Produce json
+// @Param token query string true "Invitation token"
+// @Success 200 {object} dto.InvitationPreviewResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /invitations/preview [get]
+func (c *InvitationController) PreviewInvitation(ctx *gin.Context) {
+	token := ctx.Query("token")
+	if token == "" {
+		utils.Fail(ctx, "Invitation token is required", http.StatusBadRequest, "token_required")
+		return
+	}
+
+	result, err := c.invitationService.PreviewInvitation(ctx.Request.Context(), token)
+	if err != nil {
+		failWithAppError(ctx, "Failed to retrieve invitation", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// AcceptInvitation godoc
+// @Summary Accept an invitation and complete registration
+// @Description Sets the password of a new active account with the invited role and organization.
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param request body dto.AcceptInvitationRequest true "Token and password"
+// @Success 201 {object} dto.PublicUser
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /invitations/accept [post]
+func (c *InvitationController) AcceptInvitation(ctx *gin.Context) {
+	var req dto.AcceptInvitationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.invitationService.AcceptInvitation(ctx.Request.Context(), req)
+	if err != nil {
+		failWithAppError(ctx, "Failed to accept invitation", err)
+		return
+	}
+
+	utils.Created(ctx, result)
+}
+
+func invitationCaller(ctx *gin.Context) (uuid.UUID, bool) {
+	callerID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return uuid.Nil, false
+	}
+	return callerID.(uuid.UUID), true
+}
@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"log/slog"
+
+	"user-services/internal/api/services"
+	"user-services/internal/metrics"
+
+	"github.com/ductan2/microservice-app/shared/health"
+	"github.com/gin-gonic/gin"
+)
+
+type MetricsController struct {
+	outboxService services.OutboxService
+	health        *health.Checker
+}
+
+func NewMetricsController(outboxService services.OutboxService, health *health.Checker) *MetricsController {
+	return &MetricsController{
+		outboxService: outboxService,
+		health:        health,
+	}
+}
+
+// Metrics serves the metrics registry in the Prometheus text exposition format. The
+// dependency checks, unless cached by a recent probe, and the outbox backlog are
+// refreshed on every scrape; when the backlog cannot be read the previous values are
+// kept and the database shows as down.
+// GET /metrics
+func (c *MetricsController) Metrics(ctx *gin.Context) {
+	c.health.Check(ctx.Request.Context())
+
+	stats, err := c.outboxService.Stats(ctx.Request.Context())
+	if err != nil {
+		slog.WarnContext(ctx.Request.Context(), "failed to collect outbox metrics", "error", err)
+	} else {
+		metrics.OutboxPendingEvents.Set(float64(stats.Pending))
+		metrics.OutboxRetryingEvents.Set(float64(stats.Retrying))
+		metrics.OutboxFailedEvents.Set(float64(stats.Failed))
+		metrics.OutboxLag.Set(stats.LagSeconds)
+		metrics.QueueDepth.WithLabelValues("outbox").Set(float64(stats.Pending))
+	}
+
+	metrics.Handler()(ctx)
+}
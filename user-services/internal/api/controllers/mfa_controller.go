@@ -1,23 +1,32 @@
 package controllers
 
 import (
+	"errors"
+	"io"
+	"log/slog"
 	"net/http"
 	"user-services/internal/api/dto"
 	"user-services/internal/api/middleware"
 	"user-services/internal/api/services"
+	customerrors "user-services/internal/errors"
 	"user-services/internal/utils"
 
+	"github.com/ductan2/microservice-app/shared/apperr"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type MFAController struct {
-	mfaService services.MFAService
+	mfaService      services.MFAService
+	webAuthnService services.WebAuthnService
+	otpService      services.OTPService
 }
 
-func NewMFAController(mfaService services.MFAService) *MFAController {
+func NewMFAController(mfaService services.MFAService, webAuthnService services.WebAuthnService, otpService services.OTPService) *MFAController {
 	return &MFAController{
-		mfaService: mfaService,
+		mfaService:      mfaService,
+		webAuthnService: webAuthnService,
+		otpService:      otpService,
 	}
 }
 
@@ -130,3 +139,340 @@ func (c *MFAController) GetMFAMethods(ctx *gin.Context) {
 
 	utils.Success(ctx, result)
 }
+
+// BeginPasskeyRegistration godoc
+// @Summary Start registering a passkey (WebAuthn credential)
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body dto.WebAuthnRegistrationBeginRequest true "Device nickname"
+// @Success 200 {object} dto.WebAuthnRegistrationBeginResponse
+// @Router /mfa/webauthn/register/begin [post]
+func (c *MFAController) BeginPasskeyRegistration(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	var req dto.WebAuthnRegistrationBeginRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.webAuthnService.BeginRegistration(ctx.Request.Context(), userID.(uuid.UUID), req.Label)
+	if err != nil {
+		failWithAppError(ctx, "Failed to start passkey registration", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// FinishPasskeyRegistration godoc
+// @Summary Verify and store a new passkey
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body dto.WebAuthnRegistrationFinishRequest true "Result of navigator.credentials.create()"
+// @Success 201 {object} dto.WebAuthnCredentialResponse
+// @Router /mfa/webauthn/register/finish [post]
+func (c *MFAController) FinishPasskeyRegistration(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	var req dto.WebAuthnRegistrationFinishRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.webAuthnService.FinishRegistration(ctx.Request.Context(), userID.(uuid.UUID), req)
+	if err != nil {
+		failWithAppError(ctx, "Failed to register passkey", err)
+		return
+	}
+
+	utils.Created(ctx, result)
+}
+
+// ListPasskeys godoc
+// @Summary Get user's passkeys
+// @Tags mfa
+// @Produce json
+// @Success 200 {array} dto.WebAuthnCredentialResponse
+// @Router /mfa/webauthn/credentials [get]
+func (c *MFAController) ListPasskeys(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	result, err := c.webAuthnService.ListCredentials(ctx.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		utils.Fail(ctx, "Failed to get passkeys", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// RenamePasskey godoc
+// @Summary Change a passkey's device nickname
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param id path string true "Passkey ID"
+// @Param request body dto.WebAuthnCredentialUpdateRequest true "New nickname"
+// @Success 200 {object} dto.WebAuthnCredentialResponse
+// @Router /mfa/webauthn/credentials/{id} [patch]
+func (c *MFAController) RenamePasskey(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	methodID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid passkey ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req dto.WebAuthnCredentialUpdateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.webAuthnService.RenameCredential(ctx.Request.Context(), userID.(uuid.UUID), methodID, req.Label)
+	if err != nil {
+		failWithAppError(ctx, "Failed to rename passkey", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// RevokePasskey godoc
+// @Summary Revoke a passkey (requires password)
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param id path string true "Passkey ID"
+// @Param request body dto.WebAuthnCredentialRevokeRequest true "Password confirmation"
+// @Success 200
+// @Router /mfa/webauthn/credentials/{id}/revoke [post]
+func (c *MFAController) RevokePasskey(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	methodID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid passkey ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req dto.WebAuthnCredentialRevokeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := c.webAuthnService.RevokeCredential(ctx.Request.Context(), userID.(uuid.UUID), methodID, req.Password); err != nil {
+		failWithAppError(ctx, "Failed to revoke passkey", err)
+		return
+	}
+
+	utils.Success(ctx, gin.H{"message": "Passkey revoked"})
+}
+
+// ReorderMFAMethods godoc
+// @Summary Set the fallback order of MFA methods (primary first)
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body dto.MFAOrderRequest true "Every method ID, primary first"
+// @Success 200 {array} dto.MFASetupResponse
+// @Router /mfa/methods/order [put]
+func (c *MFAController) ReorderMFAMethods(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	var req dto.MFAOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.mfaService.ReorderMFAMethods(ctx.Request.Context(), userID.(uuid.UUID), req.MethodIDs)
+	if err != nil {
+		failWithAppError(ctx, "Failed to reorder MFA methods", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// SetupPhoneMFA godoc
+// @Summary Add a phone number for SMS or voice codes and send it a confirmation code
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param request body dto.PhoneMFASetupRequest true "Phone number (E.164) and channel"
+// @Success 201 {object} dto.PhoneMFASetupResponse
+// @Router /mfa/phone [post]
+func (c *MFAController) SetupPhoneMFA(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	var req dto.PhoneMFASetupRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.otpService.SetupPhone(ctx.Request.Context(), userID.(uuid.UUID), req)
+	if err != nil {
+		failWithAppError(ctx, "Failed to set up phone MFA", err)
+		return
+	}
+
+	utils.Created(ctx, result)
+}
+
+// ConfirmPhoneMFA godoc
+// @Summary Confirm a phone number with the code sent to it
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param id path string true "Phone method ID"
+// @Param request body dto.PhoneMFAConfirmRequest true "Code"
+// @Success 200 {object} dto.PhoneMFAResponse
+// @Router /mfa/phone/{id}/verify [post]
+func (c *MFAController) ConfirmPhoneMFA(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	methodID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid method ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req dto.PhoneMFAConfirmRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.otpService.ConfirmPhone(ctx.Request.Context(), userID.(uuid.UUID), methodID, req.Code)
+	if err != nil {
+		failWithAppError(ctx, "Failed to verify phone number", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// ResendPhoneCode godoc
+// @Summary Send a new code to a phone number, optionally on another channel
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param id path string true "Phone method ID"
+// @Param request body dto.OTPSendRequest false "Channel override"
+// @Success 200 {object} dto.OTPSendResponse
+// @Router /mfa/phone/{id}/send [post]
+func (c *MFAController) ResendPhoneCode(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	methodID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid method ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req dto.OTPSendRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.otpService.ResendCode(ctx.Request.Context(), userID.(uuid.UUID), methodID, req.Channel)
+	if err != nil {
+		failWithAppError(ctx, "Failed to send verification code", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// RevokePhoneMFA godoc
+// @Summary Remove a phone number from MFA (requires password)
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Param id path string true "Phone method ID"
+// @Param request body dto.PhoneMFARevokeRequest true "Password confirmation"
+// @Success 200
+// @Router /mfa/phone/{id}/revoke [post]
+func (c *MFAController) RevokePhoneMFA(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	methodID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid method ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req dto.PhoneMFARevokeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := c.otpService.RevokePhone(ctx.Request.Context(), userID.(uuid.UUID), methodID, req.Password); err != nil {
+		failWithAppError(ctx, "Failed to remove phone number", err)
+		return
+	}
+
+	utils.Success(ctx, gin.H{"message": "Phone number removed"})
+}
+
+// failWithAppError reports AppErrors with their own status and code, anything else as
+// an internal error, logged rather than shown to the client.
+func failWithAppError(ctx *gin.Context, message string, err error) {
+	var appErr *customerrors.AppError
+	if errors.As(err, &appErr) {
+		failWithAppErrorDetails(ctx, appErr)
+		return
+	}
+	slog.ErrorContext(ctx.Request.Context(), message, "error", err)
+	utils.Fail(ctx, message, http.StatusInternalServerError, string(apperr.Internal))
+}
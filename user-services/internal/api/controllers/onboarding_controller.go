@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"net/http"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/middleware"
+	"user-services/internal/api/services"
+	"user-services/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type OnboardingController struct {
+	onboardingService services.OnboardingService
+}
+
+func NewOnboardingController(onboardingService services.OnboardingService) *OnboardingController {
+	return &OnboardingController{
+		onboardingService: onboardingService,
+	}
+}
+
+// GetOnboarding godoc
+// @Summary Get the caller's onboarding progress
+// @Tags onboarding
+// @Produce json
+// @Success 200 {object} dto.OnboardingResponse
+// @Router /users/me/onboarding [get]
+func (c *OnboardingController) GetOnboarding(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	result, err := c.onboardingService.GetOnboarding(ctx.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		failWithAppError(ctx, "Failed to retrieve onboarding progress", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// AdvanceOnboarding godoc
+// @Summary Complete or skip one of the caller's onboarding steps
+// @Description email_verified and profile_completed are only completed once the account satisfies them; only level_test_taken can be skipped.
+// @Tags onboarding
+// @Accept json
+// @Produce json
+// @Param step path string true "email_verified, profile_completed, level_test_taken or first_lesson_started"
+// @Param request body dto.AdvanceOnboardingRequest false "Status, completed when omitted"
+// @Success 200 {object} dto.OnboardingResponse
+// @Failure 409 {object} map[string]interface{}
+// @Router /users/me/onboarding/steps/{step} [post]
+func (c *OnboardingController) AdvanceOnboarding(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	req, ok := bindAdvanceOnboarding(ctx)
+	if !ok {
+		return
+	}
+
+	result, err := c.onboardingService.AdvanceStep(ctx.Request.Context(), userID.(uuid.UUID), ctx.Param("step"), req.Status, services.OnboardingSourceUser)
+	if err != nil {
+		failWithAppError(ctx, "Failed to advance onboarding", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// ReportOnboardingStep godoc
+// @Summary Complete or skip a user's onboarding step on a service's report (service-to-service)
+// @Tags onboarding
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param step path string true "level_test_taken or first_lesson_started"
+// @Param request body dto.AdvanceOnboardingRequest false "Status, completed when omitted"
+// @Success 200 {object} dto.OnboardingResponse
+// @Router /internal/onboarding/users/{id}/steps/{step} [post]
+func (c *OnboardingController) ReportOnboardingStep(ctx *gin.Context) {
+	userID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid user ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req, ok := bindAdvanceOnboarding(ctx)
+	if !ok {
+		return
+	}
+
+	result, err := c.onboardingService.AdvanceStep(ctx.Request.Context(), userID, ctx.Param("step"), req.Status, ctx.GetString(middleware.ContextServiceNameKey()))
+	if err != nil {
+		failWithAppError(ctx, "Failed to advance onboarding", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// bindAdvanceOnboarding reads the optional request body.
+func bindAdvanceOnboarding(ctx *gin.Context) (dto.AdvanceOnboardingRequest, bool) {
+	var req dto.AdvanceOnboardingRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+			return req, false
+		}
+	}
+	return req, true
+}
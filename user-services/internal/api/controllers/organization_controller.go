@@ -0,0 +1,295 @@
+package controllers
+
+import (
+	"net/http"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/middleware"
+	"user-services/internal/api/services"
+	"user-services/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type OrganizationController struct {
+	orgService services.OrganizationService
+}
+
+func NewOrganizationController(orgService services.OrganizationService) *OrganizationController {
+	return &OrganizationController{
+		orgService: orgService,
+	}
+}
+
+// CreateOrganization godoc
+// @Summary Create an organization (admin only)
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateOrganizationRequest true "Organization"
+// @Success 201 {object} dto.OrganizationResponse
+// @Failure 409 {object} map[string]interface{}
+// @Router /organizations [post]
+func (c *OrganizationController) CreateOrganization(ctx *gin.Context) {
+	var req dto.CreateOrganizationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.orgService.CreateOrganization(ctx.Request.Context(), req)
+	if err != nil {
+		failWithAppError(ctx, "Failed to create organization", err)
+		return
+	}
+
+	utils.Created(ctx, result)
+}
+
+// ListOrganizations godoc
+// @Summary List organizations (admin only)
+// @Tags organizations
+// @Produce json
+// @Param search query string false "Part of the name"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} dto.PaginatedResponse
+// @Router /organizations [get]
+func (c *OrganizationController) ListOrganizations(ctx *gin.Context) {
+	var query dto.OrganizationQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		utils.Fail(ctx, "Invalid query parameters", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.orgService.ListOrganizations(ctx.Request.Context(), query)
+	if err != nil {
+		failWithAppError(ctx, "Failed to retrieve organizations", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// UpdateOrganization godoc
+// @Summary Rename an organization or change its seat limit (admin only)
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body dto.UpdateOrganizationRequest true "Changes"
+// @Success 200 {object} dto.OrganizationResponse
+// @Failure 409 {object} map[string]interface{}
+// @Router /organizations/{id} [patch]
+func (c *OrganizationController) UpdateOrganization(ctx *gin.Context) {
+	orgID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid organization ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req dto.UpdateOrganizationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.orgService.UpdateOrganization(ctx.Request.Context(), orgID, req)
+	if err != nil {
+		failWithAppError(ctx, "Failed to update organization", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// GetOrganization godoc
+// @Summary Get an organization with its seat usage (members and admins)
+// @Tags organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} dto.OrganizationResponse
+// @Failure 404 {object} map[string]interface{}
+// @Router /organizations/{id} [get]
+func (c *OrganizationController) GetOrganization(ctx *gin.Context) {
+	callerID, orgID, ok := organizationParams(ctx)
+	if !ok {
+		return
+	}
+
+	result, err := c.orgService.GetOrganization(ctx.Request.Context(), callerID, orgID)
+	if err != nil {
+		failWithAppError(ctx, "Failed to retrieve organization", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// ListMembers godoc
+// @Summary List an organization's members (owners, managers and admins)
+// @Tags organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param role query string false "owner, manager or learner"
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Success 200 {object} dto.PaginatedResponse
+// @Failure 403 {object} map[string]interface{}
+// @Router /organizations/{id}/members [get]
+func (c *OrganizationController) ListMembers(ctx *gin.Context) {
+	callerID, orgID, ok := organizationParams(ctx)
+	if !ok {
+		return
+	}
+
+	var query dto.OrganizationMemberQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		utils.Fail(ctx, "Invalid query parameters", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.orgService.ListMembers(ctx.Request.Context(), callerID, orgID, query)
+	if err != nil {
+		failWithAppError(ctx, "Failed to retrieve organization members", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// AddMember godoc
+// @Summary Add an existing account to an organization
+// @Description Owners may add any role, managers only learners. Learners take up a seat.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body dto.AddOrganizationMemberRequest true "Member"
+// @Success 201 {object} dto.OrganizationMemberResponse
+// @Failure 409 {object} map[string]interface{}
+// @Router /organizations/{id}/members [post]
+func (c *OrganizationController) AddMember(ctx *gin.Context) {
+	callerID, orgID, ok := organizationParams(ctx)
+	if !ok {
+		return
+	}
+
+	var req dto.AddOrganizationMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.orgService.AddMember(ctx.Request.Context(), callerID, orgID, req)
+	if err != nil {
+		failWithAppError(ctx, "Failed to add organization member", err)
+		return
+	}
+
+	utils.Created(ctx, result)
+}
+
+// UpdateMemberRole godoc
+// @Summary Change a member's role
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param user_id path string true "User ID"
+// @Param request body dto.UpdateOrganizationMemberRequest true "Role"
+// @Success 200 {object} dto.OrganizationMemberResponse
+// @Failure 409 {object} map[string]interface{}
+// @Router /organizations/{id}/members/{user_id} [patch]
+func (c *OrganizationController) UpdateMemberRole(ctx *gin.Context) {
+	callerID, orgID, ok := organizationParams(ctx)
+	if !ok {
+		return
+	}
+	userID, err := uuid.Parse(ctx.Param("user_id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid user ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req dto.UpdateOrganizationMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.orgService.UpdateMemberRole(ctx.Request.Context(), callerID, orgID, userID, req.Role)
+	if err != nil {
+		failWithAppError(ctx, "Failed to update organization member", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// RemoveMember godoc
+// @Summary Remove a member from an organization, or leave it
+// @Tags organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param user_id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /organizations/{id}/members/{user_id} [delete]
+func (c *OrganizationController) RemoveMember(ctx *gin.Context) {
+	callerID, orgID, ok := organizationParams(ctx)
+	if !ok {
+		return
+	}
+	userID, err := uuid.Parse(ctx.Param("user_id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid user ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := c.orgService.RemoveMember(ctx.Request.Context(), callerID, orgID, userID); err != nil {
+		failWithAppError(ctx, "Failed to remove organization member", err)
+		return
+	}
+
+	utils.Success(ctx, gin.H{"message": "Member removed"})
+}
+
+// ListMyOrganizations godoc
+// @Summary List the organizations the caller belongs to
+// @Tags organizations
+// @Produce json
+// @Success 200 {array} dto.OrganizationMembershipResponse
+// @Router /users/me/organizations [get]
+func (c *OrganizationController) ListMyOrganizations(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	result, err := c.orgService.ListMyOrganizations(ctx.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		failWithAppError(ctx, "Failed to retrieve organizations", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// organizationParams reads the caller and the organization ID of an org-scoped request,
+// writing the error response when either is missing.
+func organizationParams(ctx *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	callerID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return uuid.Nil, uuid.Nil, false
+	}
+	orgID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid organization ID", http.StatusBadRequest, err.Error())
+		return uuid.Nil, uuid.Nil, false
+	}
+	return callerID.(uuid.UUID), orgID, true
+}
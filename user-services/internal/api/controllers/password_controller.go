@@ -4,7 +4,9 @@ import (
 	"net/http"
 	"user-services/internal/api/dto"
 	"user-services/internal/api/services"
+	customerrors "user-services/internal/errors"
 
+	"github.com/ductan2/microservice-app/shared/apperr"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -46,10 +48,13 @@ func (c *PasswordController) RequestPasswordReset(ctx *gin.Context) {
 
 // ConfirmPasswordReset godoc
 // @Summary Confirm password reset
+// @Description Signs out every session of the user. The link must be used from the browser and network that requested it.
 // @Tags password
 // @Accept json
 // @Param request body dto.PasswordResetConfirmDTO true "Password Reset Confirm"
 // @Success 200
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
 // @Router /password/reset/confirm [post]
 func (c *PasswordController) ConfirmPasswordReset(ctx *gin.Context) {
 	var req dto.PasswordResetConfirmDTO
@@ -59,6 +64,13 @@ func (c *PasswordController) ConfirmPasswordReset(ctx *gin.Context) {
 	}
 
 	if err := c.passwordService.CompletePasswordReset(ctx.Request.Context(), req.Token, req.NewPassword); err != nil {
+		if respondWithPasswordPolicyError(ctx, err) {
+			return
+		}
+		if appErr, ok := err.(*customerrors.AppError); ok {
+			ctx.JSON(appErr.HTTPStatus(), gin.H{"error": appErr.Message, "code": appErr.ErrorCode()})
+			return
+		}
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -96,6 +108,9 @@ func (c *PasswordController) ChangePassword(ctx *gin.Context) {
 	}
 
 	if err := c.passwordService.ChangePassword(ctx.Request.Context(), userID, req.OldPassword, req.NewPassword); err != nil {
+		if respondWithPasswordPolicyError(ctx, err) {
+			return
+		}
 		if err.Error() == "invalid old password" {
 			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
@@ -108,3 +123,18 @@ func (c *PasswordController) ChangePassword(ctx *gin.Context) {
 		"message": "Password changed successfully",
 	})
 }
+
+// respondWithPasswordPolicyError writes validation errors from the password policy, such
+// as WEAK_PASSWORD with its list of violations, and reports whether it did.
+func respondWithPasswordPolicyError(ctx *gin.Context, err error) bool {
+	appErr, ok := err.(*customerrors.AppError)
+	if !ok || appErr.Code != apperr.BadRequest {
+		return false
+	}
+	ctx.JSON(appErr.HTTPStatus(), gin.H{
+		"error":   appErr.Message,
+		"code":    appErr.ErrorCode(),
+		"details": appErr.Details,
+	})
+	return true
+}
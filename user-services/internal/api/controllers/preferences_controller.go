@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/middleware"
+	"user-services/internal/api/services"
+	"user-services/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+)
+
+type PreferencesController struct {
+	preferencesService services.PreferencesService
+}
+
+func NewPreferencesController(preferencesService services.PreferencesService) *PreferencesController {
+	return &PreferencesController{
+		preferencesService: preferencesService,
+	}
+}
+
+// GetPreferences godoc
+// @Summary Get the caller's preferences
+// @Tags preferences
+// @Produce json
+// @Success 200 {object} dto.PreferencesResponse
+// @Router /users/me/preferences [get]
+func (c *PreferencesController) GetPreferences(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	result, err := c.preferencesService.GetPreferences(ctx.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		failWithAppError(ctx, "Failed to retrieve preferences", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// UpdatePreferences godoc
+// @Summary Change some of the caller's preferences
+// @Description Omitted fields keep their value. Returns the full preferences.
+// @Tags preferences
+// @Accept json
+// @Produce json
+// @Param request body dto.UpdatePreferencesRequest true "Changes"
+// @Success 200 {object} dto.PreferencesResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /users/me/preferences [patch]
+func (c *PreferencesController) UpdatePreferences(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	// Unknown fields are rejected rather than ignored so a misspelt preference is not
+	// silently lost
+	var req dto.UpdatePreferencesRequest
+	decoder := json.NewDecoder(ctx.Request.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.preferencesService.UpdatePreferences(ctx.Request.Context(), userID.(uuid.UUID), req)
+	if err != nil {
+		failWithAppError(ctx, "Failed to update preferences", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
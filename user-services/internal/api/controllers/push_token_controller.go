@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"net/http"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/middleware"
+	"user-services/internal/api/services"
+	"user-services/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PushTokenController struct {
+	pushTokenService services.PushTokenService
+}
+
+func NewPushTokenController(pushTokenService services.PushTokenService) *PushTokenController {
+	return &PushTokenController{
+		pushTokenService: pushTokenService,
+	}
+}
+
+// RegisterToken godoc
+// @Summary Register a device token for push notifications
+// @Description Returns 201 for a new token, or 200 when an already registered token was refreshed.
+// @Tags push-tokens
+// @Accept json
+// @Produce json
+// @Param request body dto.RegisterPushTokenRequest true "Token and device details"
+// @Success 201 {object} dto.PushTokenResponse
+// @Success 200 {object} dto.PushTokenResponse
+// @Router /users/me/push-tokens [post]
+func (c *PushTokenController) RegisterToken(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	var req dto.RegisterPushTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, created, err := c.pushTokenService.RegisterToken(ctx.Request.Context(), userID.(uuid.UUID), req)
+	if err != nil {
+		failWithAppError(ctx, "Failed to register push token", err)
+		return
+	}
+
+	if created {
+		utils.Created(ctx, result)
+		return
+	}
+	utils.Success(ctx, result)
+}
+
+// ListTokens godoc
+// @Summary List the devices registered for the caller's push notifications
+// @Tags push-tokens
+// @Produce json
+// @Success 200 {array} dto.PushTokenResponse
+// @Router /users/me/push-tokens [get]
+func (c *PushTokenController) ListTokens(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	result, err := c.pushTokenService.ListTokens(ctx.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		failWithAppError(ctx, "Failed to retrieve push tokens", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// UnregisterToken godoc
+// @Summary Stop push notifications to one of the caller's devices
+// @Tags push-tokens
+// @Produce json
+// @Param id path string true "Push token ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /users/me/push-tokens/{id} [delete]
+func (c *PushTokenController) UnregisterToken(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+	tokenID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid push token ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := c.pushTokenService.UnregisterToken(ctx.Request.Context(), userID.(uuid.UUID), tokenID); err != nil {
+		failWithAppError(ctx, "Failed to unregister push token", err)
+		return
+	}
+
+	utils.Success(ctx, gin.H{"message": "Push token unregistered"})
+}
+
+// UnregisterByValue godoc
+// @Summary Stop push notifications to the device holding a token, e.g. when signing out
+// @Tags push-tokens
+// @Accept json
+// @Produce json
+// @Param request body dto.UnregisterPushTokenRequest true "Token"
+// @Success 200 {object} map[string]interface{}
+// @Router /users/me/push-tokens/unregister [post]
+func (c *PushTokenController) UnregisterByValue(ctx *gin.Context) {
+	userID, ok := ctx.Get(middleware.ContextUserIDKey())
+	if !ok {
+		utils.Fail(ctx, "User not found", http.StatusUnauthorized, "user_not_found")
+		return
+	}
+
+	var req dto.UnregisterPushTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := c.pushTokenService.UnregisterByValue(ctx.Request.Context(), userID.(uuid.UUID), req); err != nil {
+		failWithAppError(ctx, "Failed to unregister push token", err)
+		return
+	}
+
+	utils.Success(ctx, gin.H{"message": "Push token unregistered"})
+}
+
+// ListTargets godoc
+// @Summary List the device tokens to push a user's notifications to (service-to-service)
+// @Tags push-tokens
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} dto.PushTargetsResponse
+// @Failure 404 {object} map[string]interface{}
+// @Router /internal/push-tokens/users/{id} [get]
+func (c *PushTokenController) ListTargets(ctx *gin.Context) {
+	userID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		utils.Fail(ctx, "Invalid user ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.pushTokenService.ListTargets(ctx.Request.Context(), userID)
+	if err != nil {
+		failWithAppError(ctx, "Failed to retrieve push tokens", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// ReportInvalid godoc
+// @Summary Delete device tokens a push provider rejected (service-to-service)
+// @Tags push-tokens
+// @Accept json
+// @Produce json
+// @Param request body dto.InvalidPushTokensRequest true "Provider and rejected tokens"
+// @Success 200 {object} dto.InvalidPushTokensResponse
+// @Router /internal/push-tokens/invalid [post]
+func (c *PushTokenController) ReportInvalid(ctx *gin.Context) {
+	var req dto.InvalidPushTokensRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.pushTokenService.ReportInvalid(ctx.Request.Context(), req)
+	if err != nil {
+		failWithAppError(ctx, "Failed to prune push tokens", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
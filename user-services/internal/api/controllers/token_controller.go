@@ -1,12 +1,14 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
 	"user-services/internal/api/dto"
 	"user-services/internal/api/middleware"
 	"user-services/internal/api/services"
+	customerrors "user-services/internal/errors"
 	"user-services/internal/utils"
 
 	"github.com/gin-gonic/gin"
@@ -43,12 +45,17 @@ func (c *TokenController) RefreshToken(ctx *gin.Context) {
 	result, err := c.tokenService.RefreshAccessToken(ctx.Request.Context(), req.RefreshToken)
 	if err != nil {
 		// Record failed attempt if it's an invalid token (could be brute force)
-		if c.rateLimiter != nil && strings.Contains(err.Error(), "invalid") {
+		if c.rateLimiter != nil && (errors.Is(err, customerrors.ErrRefreshTokenInvalid) || errors.Is(err, customerrors.ErrRefreshTokenReused)) {
 			// Use a generic identifier for refresh token attempts since we don't have user context
 			c.rateLimiter.RecordFailedAttempt(ctx.Request.Context(), "refresh_token:"+ctx.ClientIP())
 		}
 
-		utils.Fail(ctx, "Invalid or expired refresh token", http.StatusUnauthorized, err.Error())
+		var appErr *customerrors.AppError
+		if errors.As(err, &appErr) {
+			utils.Fail(ctx, appErr.Message, appErr.HTTPStatus(), appErr.ErrorCode())
+			return
+		}
+		utils.Fail(ctx, "Failed to refresh token", http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -92,7 +99,7 @@ func (c *TokenController) RevokeToken(ctx *gin.Context) {
 		return
 	}
 
-	err := c.tokenService.RevokeRefreshToken(ctx.Request.Context(), req.RefreshToken)
+	err := c.tokenService.RevokeRefreshToken(ctx.Request.Context(), userID, req.RefreshToken)
 	if err != nil {
 		utils.Fail(ctx, "Failed to revoke token", http.StatusBadRequest, err.Error())
 		return
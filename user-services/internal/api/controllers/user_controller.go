@@ -3,14 +3,19 @@ package controllers
 import (
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"user-services/internal/api/dto"
 	"user-services/internal/api/helpers"
 	"user-services/internal/api/middleware"
 	"user-services/internal/api/services"
+	customerrors "user-services/internal/errors"
 	"user-services/internal/utils"
 
+	"github.com/ductan2/microservice-app/shared/apperr"
+	"github.com/ductan2/microservice-app/shared/batch"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
@@ -22,6 +27,8 @@ type UserController struct {
 	currentUserService services.CurrentUserService
 	userService        services.UserService
 	sessionService     services.SessionService
+	lockoutService     services.LockoutService
+	webAuthnService    services.WebAuthnService
 	rateLimiter        middleware.RateLimiter
 	redisClient        *redis.Client
 }
@@ -32,6 +39,8 @@ func NewUserController(
 	currentUserService services.CurrentUserService,
 	userService services.UserService,
 	sessionService services.SessionService,
+	lockoutService services.LockoutService,
+	webAuthnService services.WebAuthnService,
 	rateLimiter middleware.RateLimiter,
 	redisClient *redis.Client,
 ) *UserController {
@@ -41,6 +50,8 @@ func NewUserController(
 		currentUserService: currentUserService,
 		userService:        userService,
 		sessionService:     sessionService,
+		lockoutService:     lockoutService,
+		webAuthnService:    webAuthnService,
 		rateLimiter:        rateLimiter,
 		redisClient:        redisClient,
 	}
@@ -59,6 +70,11 @@ func (c *UserController) RegisterUser(ctx *gin.Context) {
 
 	result, err := c.authService.Register(ctx.Request.Context(), email, req.Password, req.Name)
 	if err != nil {
+		// Validation errors such as WEAK_PASSWORD carry the violated rules in their details
+		if appErr, ok := err.(*customerrors.AppError); ok && appErr.Code == apperr.BadRequest {
+			failWithAppErrorDetails(ctx, appErr)
+			return
+		}
 		utils.Fail(ctx, "Failed to register", http.StatusBadRequest, err.Error())
 		return
 	}
@@ -79,15 +95,14 @@ func (c *UserController) LoginUser(ctx *gin.Context) {
 		return
 	}
 
-	email := strings.ToLower(strings.TrimSpace(req.Email))
+	principal := loginPrincipal(req.Email, req.Identifier)
 	userAgent := ctx.GetHeader("User-Agent")
 	ipAddr := ctx.ClientIP()
 
-	result, err := c.authService.Login(ctx.Request.Context(), email, req.Password, req.MFACode, userAgent, ipAddr)
+	result, err := c.authService.Login(ctx.Request.Context(), principal, req.Password, req.MFACode, req.WebAuthn, userAgent, ipAddr)
 	if err != nil {
-		// Record failed attempt for rate limiting
-		if c.rateLimiter != nil {
-			c.rateLimiter.RecordFailedAttempt(ctx.Request.Context(), email)
+		if respondWithLoginError(ctx, err) {
+			return
 		}
 
 		// Handle specific error types
@@ -104,9 +119,170 @@ func (c *UserController) LoginUser(ctx *gin.Context) {
 		return
 	}
 
-	// Reset failed attempts on successful login
-	if c.rateLimiter != nil {
-		c.rateLimiter.ResetFailedAttempts(ctx.Request.Context(), email)
+	response := dto.AuthResponse{
+		AccessToken:  result.Token,
+		RefreshToken: result.RefreshToken,
+		ExpiresAt:    result.ExpiresAt,
+		User:         helpers.ToPublicUser(result.User),
+	}
+	utils.Success(ctx, response)
+}
+
+// BeginPasskeyLogin issues a passkey challenge, for passwordless login or as the second
+// factor of a password login
+// POST /users/login/webauthn/begin
+func (c *UserController) BeginPasskeyLogin(ctx *gin.Context) {
+	var req dto.WebAuthnLoginBeginRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	result, err := c.webAuthnService.BeginLogin(ctx.Request.Context(), email)
+	if err != nil {
+		utils.Fail(ctx, "Failed to start passkey login", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// FinishPasskeyLogin signs the user in with a passkey assertion alone
+// POST /users/login/webauthn/finish
+func (c *UserController) FinishPasskeyLogin(ctx *gin.Context) {
+	var req dto.WebAuthnAssertion
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.authService.LoginWithPasskey(ctx.Request.Context(), req, ctx.GetHeader("User-Agent"), ctx.ClientIP())
+	if err != nil {
+		if respondWithLoginError(ctx, err) {
+			return
+		}
+		utils.Fail(ctx, "Internal server error", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.Success(ctx, dto.AuthResponse{
+		AccessToken:  result.Token,
+		RefreshToken: result.RefreshToken,
+		ExpiresAt:    result.ExpiresAt,
+		User:         helpers.ToPublicUser(result.User),
+	})
+}
+
+// respondWithLoginError writes login failures reported as AppErrors, passing details such
+// as the MFA methods to use or when a lockout ends. It returns false for other errors.
+func respondWithLoginError(ctx *gin.Context, err error) bool {
+	var appErr *customerrors.AppError
+	if !errors.As(err, &appErr) {
+		return false
+	}
+
+	// Lockouts tell the client when it may try again
+	if appErr.Code == apperr.Locked {
+		if details, ok := appErr.Details.(map[string]any); ok {
+			if unlockAt, ok := details["unlock_at"].(time.Time); ok {
+				ctx.Header("Retry-After", strconv.Itoa(int(time.Until(unlockAt).Seconds())+1))
+			}
+		}
+	}
+
+	failWithAppErrorDetails(ctx, appErr)
+	return true
+}
+
+// failWithAppErrorDetails writes an AppError with its details, or its code when it has
+// none; internal errors are written without either (apperr.Error.Public). Throttled code
+// sends also get a Retry-After header.
+func failWithAppErrorDetails(ctx *gin.Context, appErr *customerrors.AppError) {
+	if details, ok := appErr.Details.(map[string]any); ok {
+		if retryAfter, ok := details["retry_after"].(int); ok {
+			ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+		}
+	}
+
+	body := appErr.Public()
+	var details any = body.Code
+	if body.Details != nil {
+		details = body.Details
+	}
+	utils.Fail(ctx, body.Message, appErr.HTTPStatus(), details)
+}
+
+// loginPrincipal returns the identifier a login was sent with, preferring the newer
+// identifier field over email
+func loginPrincipal(email, identifier string) string {
+	if identifier = strings.TrimSpace(identifier); identifier != "" {
+		return identifier
+	}
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// SendLoginOTP texts or calls a login code to the user's verified phone number
+// POST /users/login/otp/send
+func (c *UserController) SendLoginOTP(ctx *gin.Context) {
+	var req dto.LoginOTPSendRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	principal := loginPrincipal(req.Email, req.Identifier)
+	result, err := c.authService.SendLoginOTP(ctx.Request.Context(), principal, req.Password, req.Channel, ctx.ClientIP())
+	if err != nil {
+		if respondWithLoginError(ctx, err) {
+			return
+		}
+		utils.Fail(ctx, "Failed to send verification code", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// RequestPasswordlessLogin emails a single-use login link or code
+// POST /users/login/passwordless
+func (c *UserController) RequestPasswordlessLogin(ctx *gin.Context) {
+	var req dto.PasswordlessLoginRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	result, err := c.authService.RequestPasswordlessLogin(ctx.Request.Context(), email, req.Method, req.BindDevice, ctx.ClientIP())
+	if err != nil {
+		if respondWithLoginError(ctx, err) {
+			return
+		}
+		utils.Fail(ctx, "Failed to send login email", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
+// VerifyPasswordlessLogin signs the user in with an emailed login link or code
+// POST /users/login/passwordless/verify
+func (c *UserController) VerifyPasswordlessLogin(ctx *gin.Context) {
+	var req dto.PasswordlessVerifyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+	result, err := c.authService.LoginWithPasswordless(ctx.Request.Context(), req, ctx.GetHeader("User-Agent"), ctx.ClientIP())
+	if err != nil {
+		if respondWithLoginError(ctx, err) {
+			return
+		}
+		utils.Fail(ctx, "Internal server error", http.StatusInternalServerError, err.Error())
+		return
 	}
 
 	response := dto.AuthResponse{
@@ -118,9 +294,20 @@ func (c *UserController) LoginUser(ctx *gin.Context) {
 	utils.Success(ctx, response)
 }
 
-// LogoutUser handles user logout
+// LogoutUser ends the caller's session, revoking its refresh tokens and access tokens
 // POST /users/logout
 func (c *UserController) LogoutUser(ctx *gin.Context) {
+	sessionID, ok := ctx.Value(middleware.ContextSessionIDKey()).(uuid.UUID)
+	if !ok {
+		utils.Fail(ctx, "Unauthorized", http.StatusUnauthorized, "invalid session context")
+		return
+	}
+
+	if err := c.sessionService.RevokeSession(ctx.Request.Context(), sessionID); err != nil && !errors.Is(err, services.ErrSessionNotFound) {
+		utils.Fail(ctx, "Failed to logout", http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	utils.Success(ctx, gin.H{"message": "Logged out successfully"})
 }
 
@@ -143,6 +330,48 @@ func (c *UserController) VerifyUserEmail(ctx *gin.Context) {
 	})
 }
 
+// RequestAccountUnlock emails an unlock link to the owner of a locked-out account
+// POST /users/unlock/request
+func (c *UserController) RequestAccountUnlock(ctx *gin.Context) {
+	var req dto.AccountUnlockRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := c.lockoutService.RequestUnlock(ctx.Request.Context(), req.Email); err != nil {
+		utils.Fail(ctx, "Failed to request account unlock", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Same response whether or not the account exists or is locked
+	utils.Success(ctx, gin.H{
+		"message": "If the account is locked, an unlock link has been sent to its email address.",
+	})
+}
+
+// ConfirmAccountUnlock lifts a lockout using the token from the unlock email
+// POST /users/unlock/confirm
+func (c *UserController) ConfirmAccountUnlock(ctx *gin.Context) {
+	var req dto.AccountUnlockConfirmRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := c.lockoutService.ConfirmUnlock(ctx.Request.Context(), req.Token); err != nil {
+		var appErr *customerrors.AppError
+		if errors.As(err, &appErr) {
+			utils.Fail(ctx, appErr.Message, appErr.HTTPStatus(), appErr.ErrorCode())
+			return
+		}
+		utils.Fail(ctx, "Failed to unlock account", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.Success(ctx, gin.H{"message": "Account unlocked. You can now log in."})
+}
+
 // GetUserProfile gets the current user's profile (combines auth + profile data)
 // GET /users/profile
 func (c *UserController) GetUserProfile(ctx *gin.Context) {
@@ -212,6 +441,11 @@ func (c *UserController) UpdateUserProfile(ctx *gin.Context) {
 			utils.Fail(ctx, "Profile not found", http.StatusNotFound, nil)
 			return
 		}
+		var appErr *customerrors.AppError
+		if errors.As(err, &appErr) {
+			utils.Fail(ctx, appErr.Message, appErr.HTTPStatus(), appErr.ErrorCode())
+			return
+		}
 
 		utils.Fail(ctx, "Failed to update profile", http.StatusInternalServerError, err.Error())
 		return
@@ -245,7 +479,7 @@ func (c *UserController) UpdateUserProfile(ctx *gin.Context) {
 	utils.Success(ctx, profile)
 }
 
-// ListAllUsers lists all users with pagination (admin function)
+// ListAllUsers lists users with filters, sorting and keyset pagination (admin function)
 // GET /users
 func (c *UserController) ListAllUsers(ctx *gin.Context) {
 	userIDValue, exists := ctx.Get(middleware.ContextUserIDKey())
@@ -281,7 +515,7 @@ func (c *UserController) ListAllUsers(ctx *gin.Context) {
 
 	result, err := c.userService.ListUsers(ctx.Request.Context(), req)
 	if err != nil {
-		utils.Fail(ctx, "Failed to retrieve users", http.StatusInternalServerError, err.Error())
+		failWithAppError(ctx, "Failed to retrieve users", err)
 		return
 	}
 
@@ -306,6 +540,31 @@ func (c *UserController) GetUserByID(ctx *gin.Context) {
 	utils.Success(ctx, user)
 }
 
+// BatchGetUsers godoc
+// @Summary Resolve up to 100 users in one call (service-to-service)
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body batch.Request true "User IDs"
+// @Success 200 {object} map[string]interface{} "items and missing_ids"
+// @Failure 400 {object} map[string]interface{}
+// @Router /internal/users/batch [post]
+func (c *UserController) BatchGetUsers(ctx *gin.Context) {
+	ids, err := batch.Decode(ctx.Request.Body, batch.MaxSize)
+	if err != nil {
+		utils.Fail(ctx, "Invalid batch request", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.currentUserService.BatchGetPublicUsers(ctx.Request.Context(), ids)
+	if err != nil {
+		utils.Fail(ctx, "Failed to retrieve users", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.Success(ctx, result)
+}
+
 // UpdateUserRole updates a user's role (admin only; internal auth)
 // PUT /users/:id/role
 func (c *UserController) UpdateUserRole(ctx *gin.Context) {
@@ -412,6 +671,14 @@ func (c *UserController) RestoreAccount(ctx *gin.Context) {
 
 	updated, err := c.userService.RestoreAccount(ctx.Request.Context(), targetID, reason)
 	if err != nil {
+		if errors.Is(err, services.ErrUserErased) {
+			utils.Fail(ctx, "Account has been erased and cannot be restored", http.StatusConflict, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrUserMerged) {
+			utils.Fail(ctx, "Account has been merged into another account and cannot be restored", http.StatusConflict, err.Error())
+			return
+		}
 		utils.Fail(ctx, "Failed to restore account", http.StatusBadRequest, err.Error())
 		return
 	}
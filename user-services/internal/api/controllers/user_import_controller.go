@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"mime"
+	"net/http"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/services"
+	"user-services/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportBodyBytes bounds the JSON or CSV body of a bulk import.
+const maxImportBodyBytes = 10 << 20
+
+type UserImportController struct {
+	importService services.UserImportService
+	maxRows       int
+}
+
+func NewUserImportController(importService services.UserImportService, maxRows int) *UserImportController {
+	return &UserImportController{
+		importService: importService,
+		maxRows:       maxRows,
+	}
+}
+
+// ImportUsers godoc
+// @Summary Create invited accounts in bulk (admin only)
+// @Description Send a JSON body, or a text/csv body with an email header column and optional name, role and organization columns; the defaults then go in the query string. Returns a per-row report.
+// @Tags users
+// @Accept json,text/csv
+// @Produce json
+// @Param request body dto.UserImportRequest true "Users to import"
+// @Param organization query string false "Default organization for CSV imports"
+// @Param role query string false "Default role for CSV imports"
+// @Param send_invites query bool false "Email invitations for CSV imports (default true)"
+// @Success 200 {object} dto.UserImportResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /users/import [post]
+func (c *UserImportController) ImportUsers(ctx *gin.Context) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportBodyBytes)
+
+	var req dto.UserImportRequest
+	mediaType, _, _ := mime.ParseMediaType(ctx.GetHeader("Content-Type"))
+	if mediaType == "text/csv" {
+		if err := ctx.ShouldBindQuery(&req); err != nil {
+			utils.Fail(ctx, "Invalid query parameters", http.StatusBadRequest, err.Error())
+			return
+		}
+		rows, err := services.ParseUserImportCSV(ctx.Request.Body, c.maxRows)
+		if err != nil {
+			failWithAppError(ctx, "Invalid CSV file", err)
+			return
+		}
+		req.Users = rows
+	} else if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.Fail(ctx, "Invalid request data", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.importService.Import(ctx.Request.Context(), req)
+	if err != nil {
+		failWithAppError(ctx, "Failed to import users", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
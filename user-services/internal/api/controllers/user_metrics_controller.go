@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"net/http"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/services"
+	"user-services/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UserMetricsController struct {
+	metricsService services.UserMetricsService
+}
+
+func NewUserMetricsController(metricsService services.UserMetricsService) *UserMetricsController {
+	return &UserMetricsController{
+		metricsService: metricsService,
+	}
+}
+
+// GetSummary godoc
+// @Summary Get the headline user metrics of the admin dashboard
+// @Description Admin only; the BFF enforces the role. Daily signups and active users over the last days (UTC), email verification and MFA adoption rates, and locked accounts.
+// @Tags dashboard
+// @Produce json
+// @Param days query int false "Days covered by the daily series, 1-90 (default 30)"
+// @Success 200 {object} dto.UserMetricsSummary
+// @Router /dashboard/users [get]
+func (c *UserMetricsController) GetSummary(ctx *gin.Context) {
+	var query dto.UserMetricsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		utils.Fail(ctx, "Invalid query parameters", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := c.metricsService.GetSummary(ctx.Request.Context(), query)
+	if err != nil {
+		failWithAppError(ctx, "Failed to retrieve user metrics", err)
+		return
+	}
+
+	utils.Success(ctx, result)
+}
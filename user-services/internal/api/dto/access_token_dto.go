@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateAccessTokenRequest creates a personal access token. Without ExpiresInDays the
+// token lives as long as ACCESS_TOKEN_MAX_LIFETIME allows.
+type CreateAccessTokenRequest struct {
+	Name          string   `json:"name" binding:"required,min=1,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,dive,oneof=read write"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1,max=3650"`
+}
+
+// AccessTokenResponse describes a personal access token without its secret. Status is
+// active, expired or revoked.
+type AccessTokenResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	Status     string     `json:"status"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP string     `json:"last_used_ip,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreatedAccessTokenResponse is a newly created token with its secret, which is shown
+// only this once.
+type CreatedAccessTokenResponse struct {
+	AccessTokenResponse
+	Token string `json:"token"`
+}
+
+// IntrospectAccessTokenRequest asks who a personal access token belongs to. IP is the
+// address of the client presenting it, recorded as its last use.
+type IntrospectAccessTokenRequest struct {
+	Token string `json:"token" binding:"required"`
+	IP    string `json:"ip" binding:"omitempty,ip"`
+}
+
+// IntrospectAccessTokenResponse is the identity a valid personal access token acts as.
+type IntrospectAccessTokenResponse struct {
+	TokenID          uuid.UUID  `json:"token_id"`
+	UserID           uuid.UUID  `json:"user_id"`
+	Email            string     `json:"email"`
+	Role             string     `json:"role"`
+	Scopes           []string   `json:"scopes"`
+	OrganizationID   *uuid.UUID `json:"organization_id,omitempty"`
+	OrganizationRole string     `json:"organization_role,omitempty"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+}
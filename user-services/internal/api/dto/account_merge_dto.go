@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RequestAccountMergeRequest starts merging the account registered with Email into the
+// caller's account.
+type RequestAccountMergeRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ConfirmAccountMergeRequest carries the codes emailed to both accounts: TargetCode to
+// the caller's own address and SourceCode to the address of the account being merged.
+type ConfirmAccountMergeRequest struct {
+	TargetCode string `json:"target_code" binding:"required"`
+	SourceCode string `json:"source_code" binding:"required"`
+}
+
+// AccountMergeResponse describes an account merge. Status is pending, completed,
+// cancelled or expired; Report counts the rows moved per step once completed.
+type AccountMergeResponse struct {
+	ID           uuid.UUID        `json:"id"`
+	SourceEmail  string           `json:"source_email"`
+	SourceUserID uuid.UUID        `json:"source_user_id"`
+	Status       string           `json:"status"`
+	Attempts     int              `json:"attempts"`
+	ExpiresAt    time.Time        `json:"expires_at"`
+	Report       map[string]int64 `json:"report,omitempty"`
+	CompletedAt  *time.Time       `json:"completed_at,omitempty"`
+	CancelledAt  *time.Time       `json:"cancelled_at,omitempty"`
+	CreatedAt    time.Time        `json:"created_at"`
+}
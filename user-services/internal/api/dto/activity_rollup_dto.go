@@ -0,0 +1,44 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ActivityRollupQuery selects a user's day or week rollups (day when omitted) between two
+// UTC dates, both inclusive. Without dates it covers the last 30 days or 12 weeks.
+type ActivityRollupQuery struct {
+	Period string `form:"period" binding:"omitempty,oneof=day week"`
+	From   string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To     string `form:"to" binding:"omitempty,datetime=2006-01-02"`
+}
+
+// ActivityTotals is study time summed over one or more periods. ActiveDays counts the
+// days with any study time.
+type ActivityTotals struct {
+	SessionCount     int   `json:"session_count"`
+	TotalMinutes     int64 `json:"total_minutes"`
+	TotalDurationMs  int64 `json:"total_duration_ms"`
+	AverageSessionMs int64 `json:"average_session_ms"`
+	ActiveDays       int   `json:"active_days"`
+}
+
+// ActivityRollupResponse is a user's study time over one day, or one week starting on
+// the Monday PeriodStart.
+type ActivityRollupResponse struct {
+	PeriodStart string `json:"period_start"`
+	ActivityTotals
+}
+
+// ActivityRollupsResponse lists the periods of the range with any study time, oldest
+// first, and totals them. RefreshedAt is when the newest rollup was computed.
+type ActivityRollupsResponse struct {
+	UserID      uuid.UUID                `json:"user_id"`
+	Period      string                   `json:"period"`
+	From        string                   `json:"from"`
+	To          string                   `json:"to"`
+	Rollups     []ActivityRollupResponse `json:"rollups"`
+	Totals      ActivityTotals           `json:"totals"`
+	RefreshedAt *time.Time               `json:"refreshed_at,omitempty"`
+}
@@ -13,11 +13,42 @@ type RegisterRequest struct {
 	Password string `json:"password" binding:"required,min=8"`
 }
 
-// LoginRequest represents login attempt
+// LoginRequest represents login attempt. Identifier is the user's email, username or
+// verified phone number, and may be sent instead of Email.
 type LoginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
-	MFACode  string `json:"mfa_code,omitempty"`
+	Email      string `json:"email" binding:"required_without=Identifier,omitempty,email"`
+	Identifier string `json:"identifier" binding:"required_without=Email"`
+	Password   string `json:"password" binding:"required"`
+	MFACode    string `json:"mfa_code,omitempty"`
+	// WebAuthn answers a passkey challenge from /users/login/webauthn/begin instead of MFACode
+	WebAuthn *WebAuthnAssertion `json:"webauthn,omitempty"`
+}
+
+// PasswordlessLoginRequest asks for a login link or a 6-digit login code by email.
+// BindDevice makes the login usable only with the device token returned to this client.
+type PasswordlessLoginRequest struct {
+	Email      string `json:"email" binding:"required,email"`
+	Method     string `json:"method" binding:"omitempty,oneof=link code"`
+	BindDevice bool   `json:"bind_device"`
+}
+
+// PasswordlessLoginResponse is the same whether or not the email belongs to an account
+// that can sign in
+type PasswordlessLoginResponse struct {
+	Method      string    `json:"method"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	DeviceToken string    `json:"device_token,omitempty"`
+}
+
+// PasswordlessVerifyRequest completes a passwordless login with the token of the emailed
+// link, or with the email and the emailed code
+type PasswordlessVerifyRequest struct {
+	Token       string             `json:"token" binding:"required_without=Email"`
+	Email       string             `json:"email" binding:"required_without=Token,omitempty,email"`
+	Code        string             `json:"code" binding:"required_with=Email,omitempty,len=6,numeric"`
+	DeviceToken string             `json:"device_token,omitempty"`
+	MFACode     string             `json:"mfa_code,omitempty"`
+	WebAuthn    *WebAuthnAssertion `json:"webauthn,omitempty"`
 }
 
 // AuthResponse after successful authentication
@@ -41,6 +72,16 @@ type RefreshTokenResponse struct {
 	ExpiresAt    time.Time `json:"expires_at"`
 }
 
+// AccountUnlockRequest asks for an unlock link for a locked-out account
+type AccountUnlockRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// AccountUnlockConfirmRequest lifts a lockout with the emailed token
+type AccountUnlockConfirmRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
 // LogoutRequest to revoke session
 type LogoutRequest struct {
 	SessionID string `json:"session_id,omitempty"`
@@ -68,12 +109,30 @@ type VerifyEmailRequest struct {
 	Token string `json:"token" binding:"required"`
 }
 
-// SessionResponse represents active session
+// SessionResponse represents active session. Impersonated marks sessions support staff
+// opened as the user. Label reads like "Chrome on Windows, Hanoi"; DeviceType is
+// desktop, mobile, tablet or unknown, and Browser and OS are "Unknown" when not
+// recognized.
 type SessionResponse struct {
-	ID        uuid.UUID `json:"id"`
-	UserAgent string    `json:"user_agent,omitempty"`
-	IPAddr    *string   `json:"ip_addr,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
-	ExpiresAt time.Time `json:"expires_at"`
-	IsCurrent bool      `json:"is_current"`
+	ID           uuid.UUID        `json:"id"`
+	UserAgent    string           `json:"user_agent,omitempty"`
+	IPAddr       *string          `json:"ip_addr,omitempty"`
+	Label        string           `json:"label"`
+	DeviceType   string           `json:"device_type"`
+	Browser      string           `json:"browser"`
+	OS           string           `json:"os"`
+	Location     *SessionLocation `json:"location,omitempty"`
+	CreatedAt    time.Time        `json:"created_at"`
+	ExpiresAt    time.Time        `json:"expires_at"`
+	IsCurrent    bool             `json:"is_current"`
+	Impersonated bool             `json:"impersonated,omitempty"`
+}
+
+// SessionLocation is the approximate location of the IP address a session was opened
+// from. Country is "Local network" for private addresses.
+type SessionLocation struct {
+	City        string `json:"city,omitempty"`
+	Region      string `json:"region,omitempty"`
+	Country     string `json:"country,omitempty"`
+	CountryCode string `json:"country_code,omitempty"`
 }
@@ -0,0 +1,29 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateAvatarUploadRequest describes the image the client is about to upload. The
+// upload URL only accepts a file of exactly this type and size.
+type CreateAvatarUploadRequest struct {
+	ContentType string `json:"content_type" binding:"required,oneof=image/jpeg image/png image/gif"`
+	SizeBytes   int64  `json:"size_bytes" binding:"required,min=1"`
+}
+
+// AvatarUploadResponse tells the client where to upload the image: a Method request to
+// UploadURL with Headers, before ExpiresAt. The upload is then completed with UploadID.
+type AvatarUploadResponse struct {
+	UploadID  uuid.UUID         `json:"upload_id"`
+	UploadURL string            `json:"upload_url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// AvatarResponse is the user's avatar after an upload completed.
+type AvatarResponse struct {
+	AvatarURL string `json:"avatar_url"`
+}
@@ -0,0 +1,68 @@
+package dto
+
+import "time"
+
+// PublishPolicyRequest publishes a new version of the terms of service or privacy policy.
+// RequiresConsent defaults to true; editorial fixes are published with it set to false so
+// users are not asked to accept them.
+type PublishPolicyRequest struct {
+	Document        string `json:"document" binding:"required,oneof=terms privacy"`
+	Version         string `json:"version" binding:"required,max=64"`
+	URL             string `json:"url" binding:"required,url,max=2048"`
+	Summary         string `json:"summary" binding:"omitempty,max=2000"`
+	RequiresConsent *bool  `json:"requires_consent"`
+}
+
+// PolicyDocumentParam names a policy document in the path
+type PolicyDocumentParam struct {
+	Document string `uri:"document" binding:"required,oneof=terms privacy"`
+}
+
+// PolicyVersionResponse describes a published version of a policy document
+type PolicyVersionResponse struct {
+	Document        string    `json:"document"`
+	Version         string    `json:"version"`
+	URL             string    `json:"url"`
+	Summary         string    `json:"summary,omitempty"`
+	RequiresConsent bool      `json:"requires_consent"`
+	PublishedAt     time.Time `json:"published_at"`
+}
+
+// ConsentItem accepts a version of a policy document, which must be the current one
+type ConsentItem struct {
+	Document string `json:"document" binding:"required,oneof=terms privacy"`
+	Version  string `json:"version" binding:"required,max=64"`
+}
+
+// RecordConsentRequest records the user accepting one or more policy documents
+type RecordConsentRequest struct {
+	Consents []ConsentItem `json:"consents" binding:"required,min=1,max=2,dive"`
+}
+
+// DocumentConsentResponse tells which version of a policy document is current and which
+// one the user accepted. ConsentRequired is set when the user must accept the current
+// version before they can keep using the app.
+type DocumentConsentResponse struct {
+	Document        string     `json:"document"`
+	CurrentVersion  string     `json:"current_version"`
+	URL             string     `json:"url"`
+	Summary         string     `json:"summary,omitempty"`
+	AcceptedVersion *string    `json:"accepted_version"`
+	AcceptedAt      *time.Time `json:"accepted_at"`
+	ConsentRequired bool       `json:"consent_required"`
+}
+
+// ConsentRecordResponse is one entry of the user's consent history
+type ConsentRecordResponse struct {
+	Document   string    `json:"document"`
+	Version    string    `json:"version"`
+	AcceptedAt time.Time `json:"accepted_at"`
+}
+
+// ConsentStatusResponse is the user's standing with every published policy document and
+// their consent history, most recent first
+type ConsentStatusResponse struct {
+	ConsentRequired bool                      `json:"consent_required"`
+	Documents       []DocumentConsentResponse `json:"documents"`
+	History         []ConsentRecordResponse   `json:"history"`
+}
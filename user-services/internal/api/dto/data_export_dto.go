@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DataExportResponse reports the state of a data export job. Poll it until Status is
+// "ready", then fetch the archive from the download endpoint before ExpiresAt.
+type DataExportResponse struct {
+	ID          uuid.UUID                  `json:"id"`
+	Status      string                     `json:"status"`
+	RequestedAt time.Time                  `json:"requested_at"`
+	CompletedAt *time.Time                 `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time                 `json:"expires_at,omitempty"`
+	SizeBytes   int64                      `json:"size_bytes,omitempty"`
+	Error       string                     `json:"error,omitempty"`
+	Sources     []DataExportSourceResponse `json:"sources"`
+}
+
+// DataExportSourceResponse tells whether another service has sent its share of the
+// export yet. Sources still missing when the job is built are listed in the archive's
+// manifest.
+type DataExportSourceResponse struct {
+	Source   string `json:"source"`
+	Received bool   `json:"received"`
+}
+
+// DataExportPartRequest is posted by another service with its share of a user's data.
+// Data is copied into the archive as-is under <source>.json.
+type DataExportPartRequest struct {
+	Source string          `json:"source" binding:"required,max=64"`
+	Data   json.RawMessage `json:"data" binding:"required"`
+}
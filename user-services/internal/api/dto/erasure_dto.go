@@ -0,0 +1,80 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErasureSelfRequest asks for the caller's own account to be erased. The password is
+// required again because the request cannot be undone once the retention window ends.
+type ErasureSelfRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// DeactivateAccountRequest deactivates the caller's account. The password is required
+// again because the account is erased unless the user signs in before the cooling-off
+// period ends.
+type DeactivateAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+	Reason   string `json:"reason" binding:"omitempty,max=500"`
+}
+
+// ErasureRecoverRequest restores an account its owner deleted, before its retention
+// window ends. The account is signed out, so the credentials are checked here.
+type ErasureRecoverRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// ErasureAdminRequest schedules the erasure of another user's account.
+type ErasureAdminRequest struct {
+	Reason string `json:"reason" binding:"omitempty,max=500"`
+}
+
+// ErasureQuery filters and paginates erasure request listings.
+type ErasureQuery struct {
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PageSize int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+	Kind     string `form:"kind" binding:"omitempty,oneof=erasure deactivation"`
+	Status   string `form:"status" binding:"omitempty,oneof=scheduled cancelled anonymized completed incomplete"`
+	UserID   string `form:"user_id" binding:"omitempty,uuid"`
+}
+
+// ErasureAckRequest is posted by another service once it has scrubbed or pseudonymized
+// its copies of an erased user's data. A failed ack may be followed by a completed one.
+type ErasureAckRequest struct {
+	Service         string `json:"service" binding:"required,max=64"`
+	Status          string `json:"status" binding:"required,oneof=completed failed"`
+	RecordsAffected int64  `json:"records_affected" binding:"min=0"`
+	Detail          string `json:"detail" binding:"omitempty,max=1000"`
+}
+
+// ErasureResponse is the completion report of an erasure request: what was anonymized
+// here, and what each other service confirmed.
+type ErasureResponse struct {
+	ID           uuid.UUID                `json:"id"`
+	UserID       uuid.UUID                `json:"user_id"`
+	Kind         string                   `json:"kind"`
+	Status       string                   `json:"status"`
+	Reason       string                   `json:"reason,omitempty"`
+	RequestedBy  *uuid.UUID               `json:"requested_by,omitempty"`
+	RequestedAt  time.Time                `json:"requested_at"`
+	ScheduledFor time.Time                `json:"scheduled_for"`
+	AnonymizedAt *time.Time               `json:"anonymized_at,omitempty"`
+	AckDeadline  *time.Time               `json:"ack_deadline,omitempty"`
+	CompletedAt  *time.Time               `json:"completed_at,omitempty"`
+	CancelledAt  *time.Time               `json:"cancelled_at,omitempty"`
+	LocalReport  map[string]any           `json:"local_report"`
+	Services     []ErasureServiceResponse `json:"services"`
+}
+
+// ErasureServiceResponse reports one service's confirmation; Status is "pending" until
+// the service acknowledges.
+type ErasureServiceResponse struct {
+	Service         string     `json:"service"`
+	Status          string     `json:"status"`
+	RecordsAffected int64      `json:"records_affected"`
+	Detail          string     `json:"detail,omitempty"`
+	AcknowledgedAt  *time.Time `json:"acknowledged_at,omitempty"`
+}
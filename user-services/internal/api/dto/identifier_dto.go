@@ -0,0 +1,56 @@
+package dto
+
+import "time"
+
+// UsernameAvailabilityQuery asks whether a username can be claimed
+type UsernameAvailabilityQuery struct {
+	Username string `form:"username" binding:"required"`
+}
+
+// PhoneAvailabilityQuery asks whether a phone number can be added for signing in
+type PhoneAvailabilityQuery struct {
+	PhoneNumber string `form:"phone_number" binding:"required"`
+}
+
+// IdentifierAvailabilityResponse tells whether a username or phone number is free.
+// Value is the normalized identifier; Reason is invalid, reserved or taken when it is
+// not available.
+type IdentifierAvailabilityResponse struct {
+	Value     string `json:"value"`
+	Available bool   `json:"available"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// SetUsernameRequest claims or changes the user's username
+type SetUsernameRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+
+// PhoneVerificationRequest sends a code proving the user owns a phone number they want
+// to sign in with
+type PhoneVerificationRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required"`
+	Channel     string `json:"channel" binding:"omitempty,oneof=sms voice"`
+}
+
+// PhoneVerificationResponse tells where a phone verification code went and until when
+// it is valid
+type PhoneVerificationResponse struct {
+	PhoneNumber string    `json:"phone_number"` // masked
+	Channel     string    `json:"channel"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// VerifyPhoneRequest confirms a phone number with the code sent to it
+type VerifyPhoneRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required"`
+	Code        string `json:"code" binding:"required,numeric"`
+}
+
+// LoginIdentifiersResponse lists what the user can sign in with besides passkeys
+type LoginIdentifiersResponse struct {
+	Email           string     `json:"email"`
+	Username        *string    `json:"username"`
+	PhoneNumber     *string    `json:"phone_number"` // masked
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at,omitempty"`
+}
@@ -0,0 +1,29 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StartImpersonationRequest opens a session as UserID for DurationMinutes, or the
+// configured default when omitted. Reason is recorded in the audit log.
+type StartImpersonationRequest struct {
+	UserID          uuid.UUID `json:"user_id" binding:"required"`
+	Reason          string    `json:"reason" binding:"required,min=5,max=500"`
+	DurationMinutes int       `json:"duration_minutes,omitempty" binding:"omitempty,min=1"`
+}
+
+// ImpersonationResponse carries the tokens of an impersonation session. ExpiresAt is
+// when the access token expires and SessionExpiresAt when the session itself ends; it
+// cannot be extended past that.
+type ImpersonationResponse struct {
+	SessionID        uuid.UUID  `json:"session_id"`
+	ImpersonatorID   uuid.UUID  `json:"impersonator_id"`
+	Reason           string     `json:"reason"`
+	AccessToken      string     `json:"access_token"`
+	RefreshToken     string     `json:"refresh_token"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	SessionExpiresAt time.Time  `json:"session_expires_at"`
+	User             PublicUser `json:"user"`
+}
@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateInvitationRequest invites someone without an account. OrganizationID and
+// OrgRole go together and make the invitee a member on acceptance; Role defaults to
+// student.
+type CreateInvitationRequest struct {
+	Email          string `json:"email" binding:"required,email"`
+	Role           string `json:"role" binding:"omitempty,oneof=student teacher"`
+	OrganizationID string `json:"organization_id" binding:"required_with=OrgRole,omitempty,uuid"`
+	OrgRole        string `json:"org_role" binding:"omitempty,oneof=owner manager learner"`
+}
+
+// InvitationQuery filters and paginates invitation listings. Expired matches pending
+// invitations past their expiry.
+type InvitationQuery struct {
+	Page           int    `form:"page" binding:"omitempty,min=1"`
+	PageSize       int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+	OrganizationID string `form:"organization_id" binding:"omitempty,uuid"`
+	Status         string `form:"status" binding:"omitempty,oneof=pending accepted revoked expired"`
+	Email          string `form:"email" binding:"omitempty,max=320"`
+}
+
+// AcceptInvitationRequest completes the registration of an invitee.
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Name     string `json:"name" binding:"omitempty,max=200"`
+}
+
+// InvitationResponse is an invitation as seen by the people managing it. Status is
+// "expired" for pending invitations past their expiry.
+type InvitationResponse struct {
+	ID               uuid.UUID  `json:"id"`
+	Email            string     `json:"email"`
+	Role             string     `json:"role"`
+	OrganizationID   *uuid.UUID `json:"organization_id,omitempty"`
+	OrganizationName string     `json:"organization_name,omitempty"`
+	OrgRole          *string    `json:"org_role,omitempty"`
+	Status           string     `json:"status"`
+	InvitedBy        *uuid.UUID `json:"invited_by,omitempty"`
+	SentCount        int        `json:"sent_count"`
+	LastSentAt       time.Time  `json:"last_sent_at"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	AcceptedUserID   *uuid.UUID `json:"accepted_user_id,omitempty"`
+	AcceptedAt       *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// InvitationPreviewResponse is what the holder of an invitation link may see before
+// accepting it.
+type InvitationPreviewResponse struct {
+	Email            string    `json:"email"`
+	Role             string    `json:"role"`
+	OrganizationName string    `json:"organization_name,omitempty"`
+	OrgRole          *string   `json:"org_role,omitempty"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
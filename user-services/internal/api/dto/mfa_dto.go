@@ -1,6 +1,10 @@
 package dto
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // MFASetupRequest represents the request to setup MFA
 type MFASetupRequest struct {
@@ -15,6 +19,8 @@ type MFASetupResponse struct {
 	Label     string    `json:"label,omitempty"`
 	Secret    string    `json:"secret,omitempty"`     // TOTP secret (base32)
 	QRCodeURL string    `json:"qr_code_url,omitempty"` // TOTP QR code data URL
+	PhoneNumber string  `json:"phone_number,omitempty"` // masked, phone methods only
+	Priority  int       `json:"priority"`
 	AddedAt   string    `json:"added_at,omitempty"`
 }
 
@@ -33,4 +39,211 @@ type MFADisableRequest struct {
 // MFALoginRequest represents MFA verification during login
 type MFALoginRequest struct {
 	Code string `json:"code" binding:"required,len=6"`
-}
\ No newline at end of file
+}
+
+// WebAuthnRegistrationBeginRequest starts registering a passkey
+type WebAuthnRegistrationBeginRequest struct {
+	Label string `json:"label" binding:"omitempty,max=64"` // device nickname, e.g. "Work laptop"
+}
+
+// WebAuthnLoginBeginRequest starts a passkey login. Without an email the browser offers
+// every discoverable passkey for this site.
+type WebAuthnLoginBeginRequest struct {
+	Email string `json:"email" binding:"omitempty,email"`
+}
+
+// WebAuthnRelyingParty identifies this service to the authenticator
+type WebAuthnRelyingParty struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// WebAuthnUserEntity describes the account a passkey is created for. ID is the base64url
+// user handle.
+type WebAuthnUserEntity struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// WebAuthnCredentialParameter is an acceptable credential type and COSE algorithm
+type WebAuthnCredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+// WebAuthnCredentialDescriptor references an existing credential by its base64url ID
+type WebAuthnCredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+// WebAuthnAuthenticatorSelection states the authenticator requirements
+type WebAuthnAuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+// WebAuthnCreationOptions mirrors PublicKeyCredentialCreationOptions in its JSON form,
+// as accepted by PublicKeyCredential.parseCreationOptionsFromJSON()
+type WebAuthnCreationOptions struct {
+	Challenge              string                         `json:"challenge"`
+	RP                     WebAuthnRelyingParty           `json:"rp"`
+	User                   WebAuthnUserEntity             `json:"user"`
+	PubKeyCredParams       []WebAuthnCredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                          `json:"timeout"`
+	ExcludeCredentials     []WebAuthnCredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection WebAuthnAuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                         `json:"attestation"`
+}
+
+// WebAuthnRequestOptions mirrors PublicKeyCredentialRequestOptions in its JSON form,
+// as accepted by PublicKeyCredential.parseRequestOptionsFromJSON()
+type WebAuthnRequestOptions struct {
+	Challenge        string                         `json:"challenge"`
+	RPID             string                         `json:"rpId"`
+	Timeout          int64                          `json:"timeout"`
+	AllowCredentials []WebAuthnCredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                         `json:"userVerification"`
+}
+
+// WebAuthnRegistrationBeginResponse carries the options for navigator.credentials.create().
+// ChallengeID must be sent back with the result.
+type WebAuthnRegistrationBeginResponse struct {
+	ChallengeID uuid.UUID               `json:"challenge_id"`
+	PublicKey   WebAuthnCreationOptions `json:"public_key"`
+}
+
+// WebAuthnLoginBeginResponse carries the options for navigator.credentials.get().
+// ChallengeID must be sent back with the assertion.
+type WebAuthnLoginBeginResponse struct {
+	ChallengeID uuid.UUID              `json:"challenge_id"`
+	PublicKey   WebAuthnRequestOptions `json:"public_key"`
+}
+
+// WebAuthnAttestationResponse is the response of a created credential, base64url encoded
+// as by PublicKeyCredential.toJSON()
+type WebAuthnAttestationResponse struct {
+	ClientDataJSON    string   `json:"clientDataJSON" binding:"required"`
+	AttestationObject string   `json:"attestationObject" binding:"required"`
+	Transports        []string `json:"transports,omitempty"`
+}
+
+// WebAuthnRegistrationCredential is the result of navigator.credentials.create()
+type WebAuthnRegistrationCredential struct {
+	ID       string                      `json:"id" binding:"required"`
+	Type     string                      `json:"type" binding:"required,eq=public-key"`
+	Response WebAuthnAttestationResponse `json:"response"`
+}
+
+// WebAuthnRegistrationFinishRequest completes passkey registration
+type WebAuthnRegistrationFinishRequest struct {
+	ChallengeID uuid.UUID                      `json:"challenge_id" binding:"required"`
+	Credential  WebAuthnRegistrationCredential `json:"credential"`
+}
+
+// WebAuthnAssertionResponse is the response of an assertion, base64url encoded as by
+// PublicKeyCredential.toJSON()
+type WebAuthnAssertionResponse struct {
+	ClientDataJSON    string `json:"clientDataJSON" binding:"required"`
+	AuthenticatorData string `json:"authenticatorData" binding:"required"`
+	Signature         string `json:"signature" binding:"required"`
+	UserHandle        string `json:"userHandle,omitempty"`
+}
+
+// WebAuthnAssertionCredential is the result of navigator.credentials.get()
+type WebAuthnAssertionCredential struct {
+	ID       string                    `json:"id" binding:"required"`
+	Type     string                    `json:"type" binding:"required,eq=public-key"`
+	Response WebAuthnAssertionResponse `json:"response"`
+}
+
+// WebAuthnAssertion answers a login challenge, either as the second factor of a
+// password login or on its own for passwordless login
+type WebAuthnAssertion struct {
+	ChallengeID uuid.UUID                   `json:"challenge_id" binding:"required"`
+	Credential  WebAuthnAssertionCredential `json:"credential"`
+}
+
+// WebAuthnCredentialResponse describes a registered passkey
+type WebAuthnCredentialResponse struct {
+	ID         uuid.UUID `json:"id"`
+	Label      string    `json:"label,omitempty"`
+	Transports []string  `json:"transports,omitempty"`
+	AddedAt    string    `json:"added_at"`
+	LastUsedAt string    `json:"last_used_at,omitempty"`
+}
+
+// WebAuthnCredentialUpdateRequest renames a passkey
+type WebAuthnCredentialUpdateRequest struct {
+	Label string `json:"label" binding:"required,max=64"`
+}
+
+// WebAuthnCredentialRevokeRequest revokes a passkey
+type WebAuthnCredentialRevokeRequest struct {
+	Password string `json:"password" binding:"required"` // require password for security
+}
+
+// MFAOrderRequest sets the fallback order of the user's MFA methods, primary first
+type MFAOrderRequest struct {
+	MethodIDs []uuid.UUID `json:"method_ids" binding:"required,min=1,dive,required"`
+}
+
+// PhoneMFASetupRequest registers a phone number for SMS or voice codes
+type PhoneMFASetupRequest struct {
+	PhoneNumber string `json:"phone_number" binding:"required,e164"`
+	Channel     string `json:"channel" binding:"omitempty,oneof=sms voice"` // defaults to sms
+	Label       string `json:"label" binding:"omitempty,max=64"`
+}
+
+// PhoneMFAConfirmRequest confirms a phone number with the code sent to it
+type PhoneMFAConfirmRequest struct {
+	Code string `json:"code" binding:"required,numeric,min=4,max=10"`
+}
+
+// PhoneMFARevokeRequest removes a phone number from MFA
+type PhoneMFARevokeRequest struct {
+	Password string `json:"password" binding:"required"` // require password for security
+}
+
+// OTPSendRequest asks for a new code. Channel overrides the method's default channel.
+type OTPSendRequest struct {
+	Channel string `json:"channel" binding:"omitempty,oneof=sms voice"`
+}
+
+// LoginOTPSendRequest asks for a login code after the password step reported
+// MFA_REQUIRED. The password is checked again so codes are only sent to its owner.
+// Identifier is the email, username or phone number the login used.
+type LoginOTPSendRequest struct {
+	Email      string `json:"email" binding:"required_without=Identifier,omitempty,email"`
+	Identifier string `json:"identifier" binding:"required_without=Email"`
+	Password   string `json:"password" binding:"required"`
+	Channel    string `json:"channel" binding:"omitempty,oneof=sms voice"`
+}
+
+// OTPSendResponse tells the client where a code went and until when it is valid
+type OTPSendResponse struct {
+	MethodID    uuid.UUID `json:"method_id"`
+	Channel     string    `json:"channel"`
+	PhoneNumber string    `json:"phone_number"` // masked
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// PhoneMFAResponse describes a phone MFA method
+type PhoneMFAResponse struct {
+	ID          uuid.UUID `json:"id"`
+	Label       string    `json:"label,omitempty"`
+	PhoneNumber string    `json:"phone_number"` // masked
+	Channel     string    `json:"channel"`
+	Verified    bool      `json:"verified"`
+	Priority    int       `json:"priority"`
+	AddedAt     string    `json:"added_at"`
+}
+
+// PhoneMFASetupResponse is returned when a phone number is added; the number must then
+// be confirmed with the code that was sent
+type PhoneMFASetupResponse struct {
+	Method   PhoneMFAResponse `json:"method"`
+	Delivery OTPSendResponse  `json:"delivery"`
+}
@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+// AdvanceOnboardingRequest moves an onboarding step to Status, completed when omitted.
+// Only the level test can be skipped.
+type AdvanceOnboardingRequest struct {
+	Status string `json:"status" binding:"omitempty,oneof=completed skipped"`
+}
+
+// OnboardingStepResponse is one onboarding step. Status is pending, completed or
+// skipped; Source says who reported it: user, system or the name of a service.
+type OnboardingStepResponse struct {
+	Step        string     `json:"step"`
+	Status      string     `json:"status"`
+	Source      string     `json:"source,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// OnboardingResponse is the user's onboarding progress. Status is in_progress until no
+// step is pending any more, then completed. CurrentStep is the first pending step, and
+// CompletedSteps counts the completed and skipped ones.
+type OnboardingResponse struct {
+	Status         string                   `json:"status"`
+	CurrentStep    string                   `json:"current_step,omitempty"`
+	CompletedSteps int                      `json:"completed_steps"`
+	TotalSteps     int                      `json:"total_steps"`
+	Steps          []OnboardingStepResponse `json:"steps"`
+	CompletedAt    *time.Time               `json:"completed_at,omitempty"`
+}
@@ -0,0 +1,91 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreateOrganizationRequest creates an organization. OwnerID makes an existing account
+// its first owner; without one only platform admins can manage it until an owner is
+// added.
+type CreateOrganizationRequest struct {
+	Name      string `json:"name" binding:"required,min=1,max=200"`
+	SeatLimit *int   `json:"seat_limit" binding:"omitempty,min=0"`
+	OwnerID   string `json:"owner_id" binding:"omitempty,uuid"`
+}
+
+// UpdateOrganizationRequest changes an organization's name or seat limit. Set
+// ClearSeatLimit to remove the limit.
+type UpdateOrganizationRequest struct {
+	Name           *string `json:"name" binding:"omitempty,min=1,max=200"`
+	SeatLimit      *int    `json:"seat_limit" binding:"omitempty,min=0"`
+	ClearSeatLimit bool    `json:"clear_seat_limit"`
+}
+
+// OrganizationQuery filters and paginates organization listings.
+type OrganizationQuery struct {
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PageSize int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+	Search   string `form:"search" binding:"omitempty,max=200"`
+}
+
+// OrganizationMemberQuery filters and paginates member listings.
+type OrganizationMemberQuery struct {
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PageSize int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+	Role     string `form:"role" binding:"omitempty,oneof=owner manager learner"`
+}
+
+// AddOrganizationMemberRequest adds an existing account, identified by ID or email, to
+// an organization.
+type AddOrganizationMemberRequest struct {
+	UserID string `json:"user_id" binding:"required_without=Email,omitempty,uuid"`
+	Email  string `json:"email" binding:"required_without=UserID,omitempty,email"`
+	Role   string `json:"role" binding:"required,oneof=owner manager learner"`
+}
+
+// UpdateOrganizationMemberRequest changes a member's role.
+type UpdateOrganizationMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=owner manager learner"`
+}
+
+// SeatUsage reports how many seats an organization's learners take. Limit and Available
+// are omitted when seats are unlimited.
+type SeatUsage struct {
+	Limit     *int  `json:"limit,omitempty"`
+	Used      int64 `json:"used"`
+	Available *int  `json:"available,omitempty"`
+}
+
+// OrganizationResponse is an organization with its member counts per role and seat
+// usage.
+type OrganizationResponse struct {
+	ID        uuid.UUID        `json:"id"`
+	Name      string           `json:"name"`
+	Members   map[string]int64 `json:"members"`
+	Seats     SeatUsage        `json:"seats"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// OrganizationMemberResponse is one member of an organization.
+type OrganizationMemberResponse struct {
+	UserID      uuid.UUID `json:"user_id"`
+	Email       string    `json:"email"`
+	DisplayName string    `json:"display_name,omitempty"`
+	Status      string    `json:"status"`
+	Role        string    `json:"role"`
+	Primary     bool      `json:"primary"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
+
+// OrganizationMembershipResponse is one organization the caller belongs to. Primary
+// marks the one whose role is carried in their access tokens.
+type OrganizationMembershipResponse struct {
+	OrganizationID uuid.UUID `json:"organization_id"`
+	Name           string    `json:"name"`
+	Role           string    `json:"role"`
+	Primary        bool      `json:"primary"`
+	JoinedAt       time.Time `json:"joined_at"`
+}
@@ -0,0 +1,14 @@
+package dto
+
+import "time"
+
+// OutboxStats describes the backlog of events waiting to be published. Pending includes
+// the events backing off after a failed publish (Retrying) but not the parked ones
+// (Failed). LagSeconds is the age of the oldest pending event.
+type OutboxStats struct {
+	Pending         int64      `json:"pending"`
+	Retrying        int64      `json:"retrying"`
+	Failed          int64      `json:"failed"`
+	OldestPendingAt *time.Time `json:"oldest_pending_at,omitempty"`
+	LagSeconds      float64    `json:"lag_seconds"`
+}
@@ -0,0 +1,59 @@
+package dto
+
+import "time"
+
+// PreferencesResponse is a user's full set of preferences. Locale and TimeZone are the
+// same values as on the profile.
+type PreferencesResponse struct {
+	Locale        string                  `json:"locale"`
+	TimeZone      string                  `json:"time_zone"`
+	Theme         string                  `json:"theme"`
+	Notifications NotificationPreferences `json:"notifications"`
+	LearningGoals LearningGoals           `json:"learning_goals"`
+	Version       int64                   `json:"version"`
+	UpdatedAt     *time.Time              `json:"updated_at,omitempty"`
+}
+
+// NotificationPreferences tells which channels a user may be notified on. Security
+// notices are sent by email regardless.
+type NotificationPreferences struct {
+	Email bool `json:"email"`
+	Push  bool `json:"push"`
+	SMS   bool `json:"sms"`
+	InApp bool `json:"in_app"`
+}
+
+// LearningGoals are the user's study targets and lesson reminder schedule. ReminderTime
+// is "HH:MM" in the user's time zone, or empty when reminders are off.
+type LearningGoals struct {
+	DailyMinutes  int      `json:"daily_minutes"`
+	WeeklyLessons int      `json:"weekly_lessons"`
+	ReminderTime  string   `json:"reminder_time"`
+	ReminderDays  []string `json:"reminder_days"`
+}
+
+// UpdatePreferencesRequest changes some preferences; omitted fields keep their value.
+type UpdatePreferencesRequest struct {
+	Locale        *string                       `json:"locale" binding:"omitempty,len=2,alpha,lowercase"`
+	TimeZone      *string                       `json:"time_zone" binding:"omitempty,max=64"`
+	Theme         *string                       `json:"theme" binding:"omitempty,oneof=system light dark"`
+	Notifications *NotificationPreferencesPatch `json:"notifications"`
+	LearningGoals *LearningGoalsPatch           `json:"learning_goals"`
+}
+
+// NotificationPreferencesPatch changes some notification channels.
+type NotificationPreferencesPatch struct {
+	Email *bool `json:"email"`
+	Push  *bool `json:"push"`
+	SMS   *bool `json:"sms"`
+	InApp *bool `json:"in_app"`
+}
+
+// LearningGoalsPatch changes some learning goals. An empty ReminderTime turns reminders
+// off; ReminderDays replaces the whole list.
+type LearningGoalsPatch struct {
+	DailyMinutes  *int      `json:"daily_minutes" binding:"omitempty,min=0,max=1440"`
+	WeeklyLessons *int      `json:"weekly_lessons" binding:"omitempty,min=0,max=100"`
+	ReminderTime  *string   `json:"reminder_time"`
+	ReminderDays  *[]string `json:"reminder_days"`
+}
@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RegisterPushTokenRequest registers the device token of an app install for push
+// notifications. APNs tokens are only valid on iOS.
+type RegisterPushTokenRequest struct {
+	Provider    string `json:"provider" binding:"required,oneof=fcm apns"`
+	Token       string `json:"token" binding:"required,max=4096"`
+	Platform    string `json:"platform" binding:"required,oneof=ios android web"`
+	AppID       string `json:"app_id" binding:"omitempty,max=255"`
+	AppVersion  string `json:"app_version" binding:"omitempty,max=64"`
+	OSVersion   string `json:"os_version" binding:"omitempty,max=64"`
+	DeviceModel string `json:"device_model" binding:"omitempty,max=128"`
+}
+
+// UnregisterPushTokenRequest removes a device token by its value, as an app does when
+// the user signs out.
+type UnregisterPushTokenRequest struct {
+	Provider string `json:"provider" binding:"required,oneof=fcm apns"`
+	Token    string `json:"token" binding:"required,max=4096"`
+}
+
+// PushTokenResponse describes a registered device. Only the end of the token is shown.
+type PushTokenResponse struct {
+	ID           uuid.UUID `json:"id"`
+	Provider     string    `json:"provider"`
+	TokenSuffix  string    `json:"token_suffix"`
+	Platform     string    `json:"platform"`
+	AppID        string    `json:"app_id,omitempty"`
+	AppVersion   string    `json:"app_version,omitempty"`
+	OSVersion    string    `json:"os_version,omitempty"`
+	DeviceModel  string    `json:"device_model,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	RegisteredAt time.Time `json:"registered_at"`
+}
+
+// PushTargetsResponse lists the tokens the notification service pushes to for a user.
+// It is empty for users who cannot sign in.
+type PushTargetsResponse struct {
+	UserID uuid.UUID    `json:"user_id"`
+	Tokens []PushTarget `json:"tokens"`
+}
+
+// PushTarget is one device token, in full, for the notification service.
+type PushTarget struct {
+	ID       uuid.UUID `json:"id"`
+	Provider string    `json:"provider"`
+	Token    string    `json:"token"`
+	Platform string    `json:"platform"`
+	AppID    string    `json:"app_id,omitempty"`
+}
+
+// InvalidPushTokensRequest is posted by the notification service with the tokens a push
+// provider rejected as unregistered or invalid, so they are not pushed to again.
+type InvalidPushTokensRequest struct {
+	Provider string   `json:"provider" binding:"required,oneof=fcm apns"`
+	Tokens   []string `json:"tokens" binding:"required,min=1,max=500,dive,required,max=4096"`
+	Reason   string   `json:"reason" binding:"omitempty,max=255"`
+}
+
+// InvalidPushTokensResponse tells how many of the reported tokens were deleted. Tokens
+// that were already gone are not counted.
+type InvalidPushTokensResponse struct {
+	Deleted int `json:"deleted"`
+}
@@ -10,6 +10,7 @@ import (
 type PublicUser struct {
 	ID            uuid.UUID         `json:"id"`
 	Email         string            `json:"email"`
+	Username      string            `json:"username,omitempty"`
 	EmailVerified bool              `json:"email_verified"`
 	Status        string            `json:"status"`
 	Role          string            `json:"role"`
@@ -53,25 +54,85 @@ type AuditLogResponse struct {
 	ActorID   *uuid.UUID     `json:"actor_id,omitempty"`
 	Action    string         `json:"action"`
 	IPAddr    string         `json:"ip_addr,omitempty"`
+	UserAgent string         `json:"user_agent,omitempty"`
 	Metadata  map[string]any `json:"metadata"`
 	CreatedAt time.Time      `json:"created_at"`
 }
 
-// ListUsersRequest for pagination and filtering
+// AuditLogQuery filters and paginates audit log listings. Action matches exactly, or a
+// whole category when it ends in ".*" (for example "mfa.*"). UserID and ActorID are only
+// honoured on the admin listing.
+type AuditLogQuery struct {
+	Page     int       `form:"page" binding:"omitempty,min=1"`
+	PageSize int       `form:"page_size" binding:"omitempty,min=1,max=100"`
+	Action   string    `form:"action" binding:"omitempty,max=100"`
+	UserID   string    `form:"user_id" binding:"omitempty,uuid"`
+	ActorID  string    `form:"actor_id" binding:"omitempty,uuid"`
+	From     time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// LoginHistoryQuery filters and paginates the caller's sign-in history. Result is
+// success or failure.
+type LoginHistoryQuery struct {
+	Page     int       `form:"page" binding:"omitempty,min=1"`
+	PageSize int       `form:"page_size" binding:"omitempty,min=1,max=100"`
+	Result   string    `form:"result" binding:"omitempty,oneof=success failure"`
+	From     time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To       time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// LoginHistoryEntry is one sign-in attempt on the user's account. Reason tells how a
+// sign-in succeeded (success, success_passkey, success_passwordless) or why it failed,
+// e.g. invalid_credentials, mfa_required (the password was right and a second factor
+// was asked for), mfa_invalid or locked_out.
+type LoginHistoryEntry struct {
+	ID         int64     `json:"id"`
+	Result     string    `json:"result"`
+	Reason     string    `json:"reason"`
+	IPAddr     string    `json:"ip_addr,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	Label      string    `json:"label"`
+	DeviceType string    `json:"device_type"`
+	Browser    string    `json:"browser"`
+	OS         string    `json:"os"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// ListUsersRequest filters, sorts and paginates the admin user listing. Time ranges are
+// RFC 3339, From inclusive and To exclusive. Sort defaults to created_at; Order defaults
+// to desc for the time columns and asc otherwise. Cursor is the next_cursor of the
+// previous page and must be sent with the same filters and sort; Page is ignored with it.
+// Deleted users are left out unless Deleted is "only" or "include", or Status is deleted.
 type ListUsersRequest struct {
-	Page     int    `form:"page" binding:"omitempty,min=1"`
-	PageSize int    `form:"page_size" binding:"omitempty,min=1,max=100"`
-	Status   string `form:"status" binding:"omitempty,oneof=active locked disabled deleted"`
-	Search   string `form:"search" binding:"omitempty"`
+	Page          int       `form:"page" binding:"omitempty,min=1"`
+	PageSize      int       `form:"page_size" binding:"omitempty,min=1,max=100"`
+	Status        string    `form:"status" binding:"omitempty,oneof=active invited locked disabled deleted"`
+	Role          string    `form:"role" binding:"omitempty,oneof=student teacher admin super-admin"`
+	Search        string    `form:"search" binding:"omitempty,max=254"`
+	EmailVerified *bool     `form:"email_verified"`
+	MFAEnabled    *bool     `form:"mfa_enabled"`
+	Locked        *bool     `form:"locked"`
+	CreatedFrom   time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo     time.Time `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	LastLoginFrom time.Time `form:"last_login_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	LastLoginTo   time.Time `form:"last_login_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Sort          string    `form:"sort" binding:"omitempty,oneof=created_at last_login_at email role status"`
+	Order         string    `form:"order" binding:"omitempty,oneof=asc desc"`
+	Cursor        string    `form:"cursor" binding:"omitempty,max=512"`
+	Deleted       string    `form:"deleted" binding:"omitempty,oneof=exclude only include"`
 }
 
-// PaginatedResponse generic pagination wrapper
+// PaginatedResponse generic pagination wrapper. NextCursor is only set by listings with
+// keyset pagination, and only when there are more results; Page is omitted on pages
+// read with a cursor.
 type PaginatedResponse struct {
-	Data       any `json:"data"`
-	Page       int `json:"page"`
-	PageSize   int `json:"page_size"`
-	Total      int `json:"total"`
-	TotalPages int `json:"total_pages"`
+	Data       any    `json:"data"`
+	Page       int    `json:"page,omitempty"`
+	PageSize   int    `json:"page_size"`
+	Total      int    `json:"total"`
+	TotalPages int    `json:"total_pages"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // UpdateUserRoleRequest updates a user's role (admin only)
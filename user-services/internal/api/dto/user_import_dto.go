@@ -0,0 +1,45 @@
+package dto
+
+import "github.com/google/uuid"
+
+// UserImportRequest provisions accounts in bulk, from a JSON body or a CSV upload with
+// the defaults in the query string. Organization (a name, or the ID of an existing
+// organization) and Role apply to rows that leave theirs empty. An invitation is
+// emailed to every created user unless SendInvites is false.
+type UserImportRequest struct {
+	Organization string          `json:"organization" form:"organization" binding:"omitempty,max=200"`
+	Role         string          `json:"role" form:"role" binding:"omitempty,oneof=student teacher"`
+	SendInvites  *bool           `json:"send_invites" form:"send_invites"`
+	Users        []UserImportRow `json:"users" form:"-"`
+}
+
+// UserImportRow is one account to create. Rows are validated one by one, so a bad row
+// is reported without failing the others.
+type UserImportRow struct {
+	Email        string `json:"email"`
+	Name         string `json:"name"`
+	Role         string `json:"role"`
+	Organization string `json:"organization"`
+}
+
+// UserImportResponse reports the outcome of every row, in the order they were sent.
+type UserImportResponse struct {
+	Total   int                   `json:"total"`
+	Created int                   `json:"created"`
+	Skipped int                   `json:"skipped"`
+	Failed  int                   `json:"failed"`
+	Rows    []UserImportRowResult `json:"rows"`
+}
+
+// UserImportRowResult is the outcome of one row. Row is its 1-based position, not
+// counting a CSV header. Status is "created", "skipped" for an email that already has an
+// account or appears earlier in the import, or "failed"; Code and Error say why.
+type UserImportRowResult struct {
+	Row            int        `json:"row"`
+	Email          string     `json:"email"`
+	Status         string     `json:"status"`
+	UserID         *uuid.UUID `json:"user_id,omitempty"`
+	OrganizationID *uuid.UUID `json:"organization_id,omitempty"`
+	Code           string     `json:"code,omitempty"`
+	Error          string     `json:"error,omitempty"`
+}
@@ -0,0 +1,35 @@
+package dto
+
+import "time"
+
+// UserMetricsQuery selects how many days, up to and including today (UTC), the daily
+// series of the user metrics cover; 30 when omitted.
+type UserMetricsQuery struct {
+	Days int `form:"days" binding:"omitempty,min=1,max=90"`
+}
+
+// DailyMetric is a count for one UTC day.
+type DailyMetric struct {
+	Date  string `json:"date"`
+	Count int64  `json:"count"`
+}
+
+// UserMetricsSummary holds the headline user metrics of the admin dashboard. Totals and
+// rates cover the accounts that are neither deleted nor waiting for their invitation to
+// be accepted; rates are fractions between 0 and 1. Signups and ActiveUsers cover the
+// range of the daily series, in which every day is listed, oldest first.
+type UserMetricsSummary struct {
+	From             string        `json:"from"`
+	To               string        `json:"to"`
+	TotalUsers       int64         `json:"total_users"`
+	VerifiedUsers    int64         `json:"verified_users"`
+	VerificationRate float64       `json:"verification_rate"`
+	MFAEnabledUsers  int64         `json:"mfa_enabled_users"`
+	MFAAdoptionRate  float64       `json:"mfa_adoption_rate"`
+	LockedUsers      int64         `json:"locked_users"`
+	Signups          int64         `json:"signups"`
+	ActiveUsers      int64         `json:"active_users"`
+	DailySignups     []DailyMetric `json:"daily_signups"`
+	DailyActiveUsers []DailyMetric `json:"daily_active_users"`
+	GeneratedAt      time.Time     `json:"generated_at"`
+}
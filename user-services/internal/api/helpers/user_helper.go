@@ -21,6 +21,10 @@ func ToPublicUser(user models.User) dto.PublicUser {
 		UpdatedAt:     user.UpdatedAt,
 	}
 
+	if user.Username != nil {
+		publicUser.Username = *user.Username
+	}
+
 	if user.Profile.UserID != (user.ID) || user.Profile.DisplayName != "" || user.Profile.AvatarURL != "" {
 		publicUser.Profile = &dto.UserProfile{
 			DisplayName: user.Profile.DisplayName,
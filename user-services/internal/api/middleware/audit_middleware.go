@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"user-services/internal/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxAuditUserAgentLength bounds the user agent stored with each audit entry.
+const maxAuditUserAgentLength = 512
+
+// AuditContext stores the client IP and user agent on the request context so audit log
+// entries written while handling the request record where it came from. The auth
+// middlewares add the authenticated actor.
+func AuditContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userAgent := c.Request.UserAgent()
+		if len(userAgent) > maxAuditUserAgentLength {
+			userAgent = userAgent[:maxAuditUserAgentLength]
+		}
+
+		c.Request = c.Request.WithContext(audit.WithRequest(c.Request.Context(), audit.Request{
+			IPAddr:    c.ClientIP(),
+			UserAgent: userAgent,
+		}))
+
+		c.Next()
+	}
+}
@@ -1,23 +1,27 @@
 package middleware
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
 
+	"user-services/internal/audit"
 	"user-services/internal/cache"
 	"user-services/internal/config"
 	"user-services/internal/response"
 	"user-services/internal/utils"
 
+	"github.com/ductan2/microservice-app/shared/internalauth"
+	"github.com/ductan2/microservice-app/shared/logging"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 const (
-	contextUserIDKey    = "userID"
-	contextUserEmailKey = "userEmail"
-	contextSessionIDKey = "sessionID"
+	contextUserIDKey      = "userID"
+	contextUserEmailKey   = "userEmail"
+	contextSessionIDKey   = "sessionID"
+	contextServiceNameKey = "serviceName"
 )
 
 // AuthRequired ensures requests include a valid Bearer access token and validates session in Redis.
@@ -51,6 +55,13 @@ func AuthRequired(sessionCache *cache.SessionCache) gin.HandlerFunc {
 			return
 		}
 
+		// Reject tokens of sessions on the revocation list
+		if revoked, err := sessionCache.IsSessionRevoked(c.Request.Context(), claims.SessionID); err != nil || revoked {
+			response.Unauthorized(c, "Session has been revoked")
+			c.Abort()
+			return
+		}
+
 		// Check if session exists in Redis
 		sessionData, err := sessionCache.GetSession(c.Request.Context(), claims.SessionID)
 		if err != nil {
@@ -74,6 +85,11 @@ func AuthRequired(sessionCache *cache.SessionCache) gin.HandlerFunc {
 		c.Set(contextUserIDKey, claims.UserID)
 		c.Set(contextUserEmailKey, claims.Email)
 		c.Set(contextSessionIDKey, claims.SessionID)
+		c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), claims.UserID))
+		c.Request = c.Request.WithContext(logging.WithUserID(c.Request.Context(), claims.UserID.String()))
+		if sessionData.ImpersonatorID != nil {
+			c.Request = c.Request.WithContext(audit.WithImpersonator(c.Request.Context(), *sessionData.ImpersonatorID))
+		}
 
 		// Add security headers
 		c.Header("X-Content-Type-Options", "nosniff")
@@ -106,6 +122,11 @@ func OptionalAuth(sessionCache *cache.SessionCache) gin.HandlerFunc {
 			return
 		}
 
+		if revoked, err := sessionCache.IsSessionRevoked(c.Request.Context(), claims.SessionID); err != nil || revoked {
+			c.Next()
+			return
+		}
+
 		// Check if session exists in Redis
 		sessionData, err := sessionCache.GetSession(c.Request.Context(), claims.SessionID)
 		if err != nil {
@@ -123,6 +144,8 @@ func OptionalAuth(sessionCache *cache.SessionCache) gin.HandlerFunc {
 		c.Set(contextUserIDKey, claims.UserID)
 		c.Set(contextUserEmailKey, claims.Email)
 		c.Set(contextSessionIDKey, claims.SessionID)
+		c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), claims.UserID))
+		c.Request = c.Request.WithContext(logging.WithUserID(c.Request.Context(), claims.UserID.String()))
 
 		c.Next()
 	}
@@ -152,18 +175,42 @@ func ContextSessionIDKey() string {
 	return contextSessionIDKey
 }
 
+// ServiceAuth checks the service token of each request (shared/internalauth) and stores
+// the calling service's name under ContextServiceNameKey. Requests without one reach the
+// public routes with their user identity headers removed; requests with an invalid one,
+// or with identity headers the token does not carry, are refused.
+func ServiceAuth(verifier *internalauth.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := verifier.Authenticate(c.Request)
+		if err != nil {
+			slog.WarnContext(c.Request.Context(), "rejected service token", "error", err, "path", c.Request.URL.Path)
+			utils.Fail(c, "Unauthorized", http.StatusUnauthorized, "invalid internal service credentials")
+			c.Abort()
+			return
+		}
+		if claims != nil {
+			c.Set(contextServiceNameKey, claims.Issuer)
+		}
+		c.Next()
+	}
+}
+
 // InternalAuthRequired validates internal requests from BFF service.
-// It extracts userID, email, and sessionID from headers set by BFF.
+// It extracts userID, email, and sessionID from headers set by BFF, which ServiceAuth
+// has checked against the service token of the request.
 // This middleware is for internal microservice communication only.
 func InternalAuthRequired() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if _, ok := c.Get(contextServiceNameKey); !ok {
+			utils.Fail(c, "Unauthorized", http.StatusUnauthorized, "missing internal service credentials")
+			c.Abort()
+			return
+		}
+
 		userID := c.GetHeader("X-User-ID")
 		email := c.GetHeader("X-User-Email")
 		sessionID := c.GetHeader("X-Session-ID")
 
-		log.Println("userID", userID)
-		log.Println("email", email)
-		log.Println("sessionID", sessionID)
 		if userID == "" || email == "" || sessionID == "" {
 			utils.Fail(c, "Unauthorized", http.StatusUnauthorized, "missing internal auth headers")
 			c.Abort()
@@ -189,7 +236,39 @@ func InternalAuthRequired() gin.HandlerFunc {
 		c.Set(contextUserIDKey, parsedUserID)
 		c.Set(contextUserEmailKey, email)
 		c.Set(contextSessionIDKey, parsedSessionID)
+		c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), parsedUserID))
+		c.Request = c.Request.WithContext(logging.WithUserID(c.Request.Context(), parsedUserID.String()))
+
+		// The BFF names the administrator behind an impersonation session so that audit
+		// entries are attributed to them
+		if impersonator := c.GetHeader("X-Impersonator-ID"); impersonator != "" {
+			impersonatorID, err := uuid.Parse(impersonator)
+			if err != nil {
+				utils.Fail(c, "Unauthorized", http.StatusUnauthorized, "invalid impersonator ID format")
+				c.Abort()
+				return
+			}
+			c.Request = c.Request.WithContext(audit.WithImpersonator(c.Request.Context(), impersonatorID))
+		}
+
+		c.Next()
+	}
+}
 
+// ServiceAuthRequired restricts a route to other backend services, authenticated by
+// ServiceAuth.
+func ServiceAuthRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get(contextServiceNameKey); !ok {
+			utils.Fail(c, "Unauthorized", http.StatusUnauthorized, "invalid internal service credentials")
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
+
+// ContextServiceNameKey exposes the context key used to store the calling service's name.
+func ContextServiceNameKey() string {
+	return contextServiceNameKey
+}
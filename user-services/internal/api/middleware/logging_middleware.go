@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/logging"
+	"github.com/ductan2/microservice-app/shared/requestmeta"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestMeta stores the X-Request-ID forwarded by the BFF, or a new one, and the client
+// IP resolved past proxies on the request context, so every log record written while
+// handling the request carries them.
+func RequestMeta(proxies requestmeta.Proxies) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = requestmeta.Attach(c.Writer, c.Request, proxies)
+
+		c.Next()
+	}
+}
+
+// RequestLog writes an access log record for each request once it has been served.
+func RequestLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		logging.LogRequest(c.Request.Context(), logging.HTTPRequest{
+			Method:   c.Request.Method,
+			Route:    c.FullPath(),
+			Path:     c.Request.URL.Path,
+			Status:   c.Writer.Status(),
+			Latency:  time.Since(start),
+			ClientIP: c.ClientIP(),
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics records the count and latency of each request by matched route.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		metrics.ObserveHTTPServer(c.Request.Method, c.FullPath(), c.Writer.Status(), time.Since(start))
+	}
+}
@@ -1,20 +1,33 @@
 package middleware
 
 import (
+	"log/slog"
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
-	"strconv"
+	"io"
+	"strings"
 	"time"
 
+	"user-services/internal/api/repositories"
 	"user-services/internal/config"
+	"user-services/internal/models"
 	"user-services/internal/response"
+	"user-services/internal/utils"
 
+	"github.com/ductan2/microservice-app/shared/ratelimit"
+	"github.com/ductan2/microservice-app/shared/ratelimit/redisstore"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 )
 
 // RateLimitConfig holds configuration for rate limiting
 type RateLimitConfig struct {
+	// Name names the policy in RATE_LIMITS and the rate_limit_requests_total metric; the
+	// per-account limit is the policy Name + ".account"
+	Name               string
 	Requests           int           // Number of requests allowed
 	Window             time.Duration // Time window for rate limiting
 	AccountRequests    int           // Number of requests allowed per account
@@ -24,85 +37,102 @@ type RateLimitConfig struct {
 
 // RateLimitResult represents the result of a rate limit check
 type RateLimitResult struct {
-	Allowed   bool
-	Remaining int
-	ResetTime time.Time
-	Reason    string
+	ratelimit.Result
+	Reason string
 }
 
 // RateLimiter interface for rate limiting implementations
 type RateLimiter interface {
-	CheckRateLimit(ctx context.Context, key string, limit int, window time.Duration) (*RateLimitResult, error)
+	CheckRateLimit(ctx context.Context, policy ratelimit.Policy, key string) (*RateLimitResult, error)
 	CheckAccountRateLimit(ctx context.Context, email string, failedAttempts int) (*RateLimitResult, error)
 	RecordFailedAttempt(ctx context.Context, email string) error
 	ResetFailedAttempts(ctx context.Context, email string) error
+	RecordViolation(ctx context.Context, violation RateLimitViolation)
 }
 
+// RateLimitViolation describes a request rejected by a rate limit, for security logging
+type RateLimitViolation struct {
+	Scope    string // "ip" or "account"
+	Endpoint string
+	IPAddr   string
+	Account  string
+	UserID   *uuid.UUID
+	Limit    int
+	Window   time.Duration
+}
+
+// accountBackoffPolicy limits the attempts on an account with failed logins. Its limit
+// tightens with the failures, see getProgressiveRateLimit.
+const accountBackoffPolicy = "auth.account_backoff"
+
 // RedisRateLimiter implements rate limiting using Redis
 type RedisRateLimiter struct {
-	client *redis.Client
-	config *config.Config
+	client       *redis.Client
+	limiter      *ratelimit.Limiter
+	config       *config.Config
+	auditLogRepo repositories.AuditLogRepository
 }
 
-// NewRedisRateLimiter creates a new Redis-based rate limiter
-func NewRedisRateLimiter(client *redis.Client, cfg *config.Config) *RedisRateLimiter {
+// NewRedisRateLimiter creates a new Redis-based rate limiter on the shared sliding
+// window, with the policy overrides of RATE_LIMITS. Violations are written to the audit
+// log as security events.
+func NewRedisRateLimiter(client *redis.Client, cfg *config.Config, auditLogRepo repositories.AuditLogRepository) *RedisRateLimiter {
 	return &RedisRateLimiter{
 		client: client,
-		config: cfg,
+		limiter: ratelimit.New(redisstore.New(client), ratelimit.Config{
+			Overrides: cfg.RateLimit.Policies,
+			FailOpen:  cfg.IsProduction(),
+		}),
+		config:       cfg,
+		auditLogRepo: auditLogRepo,
 	}
 }
 
-// CheckRateLimit checks if a request is allowed based on IP rate limiting
-func (r *RedisRateLimiter) CheckRateLimit(ctx context.Context, key string, limit int, window time.Duration) (*RateLimitResult, error) {
-	now := time.Now()
-	windowStart := now.Add(-window)
-
-	// Use sliding window algorithm with Redis sorted set
-	pipe := r.client.Pipeline()
-
-	// Remove old entries outside the window
-	removeCmd := pipe.ZRemRangeByScore(ctx, key, "0", strconv.FormatInt(windowStart.UnixNano(), 10))
-
-	// Count current requests in window
-	countCmd := pipe.ZCard(ctx, key)
-
-	// Add current request
-	pipe.ZAdd(ctx, key, redis.Z{
-		Score:  float64(now.UnixNano()),
-		Member: now.UnixNano(),
-	})
-
-	// Set expiration on the key
-	pipe.Expire(ctx, key, window)
-
-	_, err := pipe.Exec(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute rate limit pipeline: %w", err)
+// RecordViolation logs a rate limit violation as a security event. Only the first
+// violation per limit and window is recorded so an attack cannot flood the audit log.
+func (r *RedisRateLimiter) RecordViolation(ctx context.Context, v RateLimitViolation) {
+	subject := v.IPAddr
+	if v.Scope == "account" {
+		subject = v.Account
+	}
+	dedupeKey := fmt.Sprintf("rate_limit_violation:%s:%s:%s", v.Scope, subject, v.Endpoint)
+	first, err := r.client.SetNX(ctx, dedupeKey, "1", v.Window).Result()
+	if err != nil || !first {
+		return
 	}
 
-	// Check results
-	currentCount := countCmd.Val()
-	remaining := limit - int(currentCount)
+	slog.WarnContext(ctx, "rate limit exceeded",
+		"scope", v.Scope, "endpoint", v.Endpoint, "ip", v.IPAddr, "account", v.Account, "limit", v.Limit, "window", v.Window)
 
-	// If we're at or over the limit, remove the current request we just added
-	if currentCount > int64(limit) {
-		r.client.ZRem(ctx, key, now.UnixNano())
-		remaining = 0
+	if r.auditLogRepo == nil {
+		return
 	}
-
-	result := &RateLimitResult{
-		Allowed:   currentCount <= int64(limit),
-		Remaining: max(0, remaining-1), // Subtract 1 for current request
-		ResetTime: now.Add(window),
-		Reason:    "ip_rate_limit",
+	var ipAddr *string
+	if sanitized := utils.SanitizeIPAddress(v.IPAddr); sanitized != "" {
+		ipAddr = &sanitized
 	}
+	_ = r.auditLogRepo.Create(ctx, &models.AuditLog{
+		UserID: v.UserID,
+		Action: "security.rate_limit_exceeded",
+		IPAddr: ipAddr,
+		Metadata: models.JSONBMap{
+			"scope":          v.Scope,
+			"endpoint":       v.Endpoint,
+			"account":        v.Account,
+			"limit":          v.Limit,
+			"window_seconds": int(v.Window.Seconds()),
+		},
+		CreatedAt: time.Now(),
+	})
+}
 
-	// Clean up expired entries periodically
-	if removeCmd.Val() > 0 {
-		r.client.Expire(ctx, key, window)
+// CheckRateLimit counts a request for key against policy
+func (r *RedisRateLimiter) CheckRateLimit(ctx context.Context, policy ratelimit.Policy, key string) (*RateLimitResult, error) {
+	result, err := r.limiter.Allow(ctx, policy, key)
+	if err != nil {
+		return nil, err
 	}
-
-	return result, nil
+	return &RateLimitResult{Result: result, Reason: "ip_rate_limit"}, nil
 }
 
 // CheckAccountRateLimit checks account-specific rate limiting with progressive backoff
@@ -119,10 +149,8 @@ func (r *RedisRateLimiter) CheckAccountRateLimit(ctx context.Context, email stri
 	if blocked > 0 {
 		ttl, _ := r.client.TTL(ctx, blockKey).Result()
 		return &RateLimitResult{
-			Allowed:   false,
-			Remaining: 0,
-			ResetTime: time.Now().Add(ttl),
-			Reason:    "account_blocked",
+			Result: ratelimit.Result{RetryAfter: ttl, ResetAt: time.Now().Add(ttl)},
+			Reason: "account_blocked",
 		}, nil
 	}
 
@@ -139,11 +167,13 @@ func (r *RedisRateLimiter) CheckAccountRateLimit(ctx context.Context, email stri
 	// Calculate progressive rate limits
 	maxRequests, window := r.getProgressiveRateLimit(attempts)
 
-	// Create rate limit key for this account
-	rateLimitKey := fmt.Sprintf("account_rate_limit:%s", email)
-
 	// Check rate limit for this account
-	result, err := r.CheckRateLimit(ctx, rateLimitKey, maxRequests, window)
+	result, err := r.CheckRateLimit(ctx, ratelimit.Policy{
+		Name:      accountBackoffPolicy,
+		Algorithm: ratelimit.SlidingWindow,
+		Limit:     maxRequests,
+		Window:    window,
+	}, email)
 	if err != nil {
 		return nil, err
 	}
@@ -156,10 +186,8 @@ func (r *RedisRateLimiter) CheckAccountRateLimit(ctx context.Context, email stri
 		r.client.SetEx(ctx, blockKey, "1", blockDuration)
 
 		return &RateLimitResult{
-			Allowed:   false,
-			Remaining: 0,
-			ResetTime: time.Now().Add(blockDuration),
-			Reason:    "account_blocked_too_many_failures",
+			Result: ratelimit.Result{RetryAfter: blockDuration, ResetAt: time.Now().Add(blockDuration)},
+			Reason: "account_blocked_too_many_failures",
 		}, nil
 	}
 
@@ -183,10 +211,12 @@ func (r *RedisRateLimiter) ResetFailedAttempts(ctx context.Context, email string
 	keys := []string{
 		fmt.Sprintf("failed_attempts:%s", email),
 		fmt.Sprintf("account_block:%s", email),
-		fmt.Sprintf("account_rate_limit:%s", email),
+	}
+	if err := r.client.Del(ctx, keys...).Err(); err != nil {
+		return err
 	}
 
-	return r.client.Del(ctx, keys...).Err()
+	return r.limiter.Reset(ctx, ratelimit.Policy{Name: accountBackoffPolicy}, email)
 }
 
 // getProgressiveRateLimit returns rate limits based on failed attempts
@@ -219,9 +249,9 @@ func (r *RedisRateLimiter) getBlockDuration(failedAttempts int) time.Duration {
 func RateLimitMiddleware(limiter RateLimiter, config RateLimitConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		clientIP := c.ClientIP()
-		key := fmt.Sprintf("rate_limit:%s:%s", clientIP, c.Request.URL.Path)
+		key := clientIP + ":" + c.Request.URL.Path
 
-		result, err := limiter.CheckRateLimit(c.Request.Context(), key, config.Requests, config.Window)
+		result, err := limiter.CheckRateLimit(c.Request.Context(), config.ipPolicy(), key)
 		if err != nil {
 			// Log error but don't block requests on Redis failures
 			if !limiter.(*RedisRateLimiter).config.IsProduction() {
@@ -235,13 +265,9 @@ func RateLimitMiddleware(limiter RateLimiter, config RateLimitConfig) gin.Handle
 		}
 
 		// Set rate limit headers
-		c.Header("X-RateLimit-Limit", strconv.Itoa(config.Requests))
-		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
-		c.Header("X-RateLimit-Reset", strconv.FormatInt(result.ResetTime.Unix(), 10))
+		result.SetHeaders(c.Writer.Header())
 
 		if !result.Allowed {
-			c.Header("Retry-After", strconv.FormatInt(int64(result.ResetTime.Sub(time.Now()).Seconds()), 10))
-
 			response.TooManyRequests(c, "Too many requests. Please try again later.")
 			c.Abort()
 			return
@@ -303,7 +329,7 @@ func AccountRateLimitMiddleware(limiter RateLimiter) gin.HandlerFunc {
 				message = "Too many attempts for this account. Please try again later."
 			}
 
-			c.Header("Retry-After", strconv.FormatInt(int64(result.ResetTime.Sub(time.Now()).Seconds()), 10))
+			result.SetHeaders(c.Writer.Header())
 			response.TooManyRequests(c, message)
 			c.Abort()
 			return
@@ -313,18 +339,19 @@ func AccountRateLimitMiddleware(limiter RateLimiter) gin.HandlerFunc {
 	}
 }
 
-// AuthRateLimitMiddleware combines IP and account rate limiting for auth endpoints
-func AuthRateLimitMiddleware(limiter RateLimiter, ipConfig RateLimitConfig) gin.HandlerFunc {
+// AuthRateLimitMiddleware limits an authentication endpoint per client IP and, when the
+// request identifies an account (an email in the JSON body or an authenticated user),
+// per account using AccountRequests/AccountWindow. Violations are recorded as security
+// events.
+func AuthRateLimitMiddleware(limiter RateLimiter, config RateLimitConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Apply IP-based rate limiting
+		ctx := c.Request.Context()
+		endpoint := c.FullPath()
 		clientIP := c.ClientIP()
-		ipKey := fmt.Sprintf("auth_rate_limit:%s:%s", clientIP, c.Request.URL.Path)
 
-		ipResult, err := limiter.CheckRateLimit(c.Request.Context(), ipKey, ipConfig.Requests, ipConfig.Window)
+		ipResult, err := limiter.CheckRateLimit(ctx, config.ipPolicy(), clientIP+":"+endpoint)
 		if err != nil {
-			if !limiter.(*RedisRateLimiter).config.IsProduction() {
-				response.InternalServerError(c, "Rate limiting service unavailable")
-				c.Abort()
+			if rateLimitUnavailable(c, limiter) {
 				return
 			}
 			c.Next()
@@ -332,79 +359,120 @@ func AuthRateLimitMiddleware(limiter RateLimiter, ipConfig RateLimitConfig) gin.
 		}
 
 		// Set rate limit headers for IP-based limiting
-		c.Header("X-RateLimit-Limit", strconv.Itoa(ipConfig.Requests))
-		c.Header("X-RateLimit-Remaining", strconv.Itoa(ipResult.Remaining))
-		c.Header("X-RateLimit-Reset", strconv.FormatInt(ipResult.ResetTime.Unix(), 10))
+		ipResult.SetHeaders(c.Writer.Header())
 
+		account, userID := requestAccount(c)
 		if !ipResult.Allowed {
-			c.Header("Retry-After", strconv.FormatInt(int64(ipResult.ResetTime.Sub(time.Now()).Seconds()), 10))
+			limiter.RecordViolation(ctx, RateLimitViolation{
+				Scope:    "ip",
+				Endpoint: endpoint,
+				IPAddr:   clientIP,
+				Account:  account,
+				UserID:   userID,
+				Limit:    ipResult.Limit,
+				Window:   config.Window,
+			})
 			response.TooManyRequests(c, "Too many authentication attempts. Please try again later.")
 			c.Abort()
 			return
 		}
 
-		// Apply account-based rate limiting if we can extract email
-		var email string
-		if c.Request.Method == "POST" {
-			if c.Request.URL.Path == "/api/v1/users/login" || c.Request.URL.Path == "/api/v1/users/register" {
-				type authRequest struct {
-					Email string `json:"email"`
-				}
-				var req authRequest
-				if err := c.ShouldBindJSON(&req); err == nil {
-					email = req.Email
-				}
-			} else if c.Request.URL.Path == "/api/v1/password/reset/request" {
-				type resetRequest struct {
-					Email string `json:"email"`
-				}
-				var req resetRequest
-				if err := c.ShouldBindJSON(&req); err == nil {
-					email = req.Email
-				}
-			}
+		if account == "" || config.AccountRequests <= 0 {
+			c.Next()
+			return
 		}
 
-		if email != "" {
-			failedKey := fmt.Sprintf("failed_attempts:%s", email)
-			attempts, _ := limiter.(*RedisRateLimiter).client.Get(c.Request.Context(), failedKey).Int()
-
-			accountResult, err := limiter.CheckAccountRateLimit(c.Request.Context(), email, attempts)
-			if err != nil {
-				if !limiter.(*RedisRateLimiter).config.IsProduction() {
-					response.InternalServerError(c, "Rate limiting service unavailable")
-					c.Abort()
-					return
-				}
-				c.Next()
+		accountResult, err := limiter.CheckRateLimit(ctx, config.accountPolicy(), account+":"+endpoint)
+		if err != nil {
+			if rateLimitUnavailable(c, limiter) {
 				return
 			}
+			c.Next()
+			return
+		}
 
-			if !accountResult.Allowed {
-				var message string
-				switch accountResult.Reason {
-				case "account_blocked":
-					message = "Account temporarily locked due to too many failed attempts. Please try again later."
-				case "account_blocked_too_many_failures":
-					message = "Account locked due to suspicious activity. Please contact support or try again later."
-				default:
-					message = "Too many attempts for this account. Please try again later."
-				}
-
-				c.Header("Retry-After", strconv.FormatInt(int64(accountResult.ResetTime.Sub(time.Now()).Seconds()), 10))
-				response.TooManyRequests(c, message)
-				c.Abort()
-				return
-			}
+		if !accountResult.Allowed {
+			limiter.RecordViolation(ctx, RateLimitViolation{
+				Scope:    "account",
+				Endpoint: endpoint,
+				IPAddr:   clientIP,
+				Account:  account,
+				UserID:   userID,
+				Limit:    accountResult.Limit,
+				Window:   config.AccountWindow,
+			})
+			accountResult.SetHeaders(c.Writer.Header())
+			response.TooManyRequests(c, "Too many attempts for this account. Please try again later.")
+			c.Abort()
+			return
 		}
 
 		c.Next()
 	}
 }
 
-func max(a, b int) int {
-	if a > b {
-		return a
+// ipPolicy is the per-IP sliding window of the config
+func (config RateLimitConfig) ipPolicy() ratelimit.Policy {
+	return ratelimit.Policy{
+		Name:      config.Name,
+		Algorithm: ratelimit.SlidingWindow,
+		Limit:     config.Requests,
+		Window:    config.Window,
 	}
-	return b
-}
\ No newline at end of file
+}
+
+// accountPolicy is the per-account sliding window of the config
+func (config RateLimitConfig) accountPolicy() ratelimit.Policy {
+	return ratelimit.Policy{
+		Name:      config.Name + ".account",
+		Algorithm: ratelimit.SlidingWindow,
+		Limit:     config.AccountRequests,
+		Window:    config.AccountWindow,
+	}
+}
+
+// requestAccount identifies the account an authentication request targets: the
+// authenticated user, or else the email, username or phone number in the JSON body. The body is restored so
+// handlers can still bind it.
+func requestAccount(c *gin.Context) (string, *uuid.UUID) {
+	if userID, ok := c.Value(contextUserIDKey).(uuid.UUID); ok {
+		return "user:" + userID.String(), &userID
+	}
+
+	if c.Request.Body == nil || c.ContentType() != gin.MIMEJSON {
+		return "", nil
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return "", nil
+	}
+
+	var req struct {
+		Email      string `json:"email"`
+		Identifier string `json:"identifier"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return "", nil
+	}
+	principal := strings.TrimSpace(req.Identifier)
+	if principal == "" {
+		principal = strings.TrimSpace(req.Email)
+	}
+	if principal == "" {
+		return "", nil
+	}
+	kind, normalized := utils.ParseLoginPrincipal(principal)
+	return kind + ":" + normalized, nil
+}
+
+// rateLimitUnavailable rejects the request when the limiter backend fails outside
+// production and reports whether it did. Production fails open.
+func rateLimitUnavailable(c *gin.Context, limiter RateLimiter) bool {
+	if redisLimiter, ok := limiter.(*RedisRateLimiter); ok && redisLimiter.config.IsProduction() {
+		return false
+	}
+	response.InternalServerError(c, "Rate limiting service unavailable")
+	c.Abort()
+	return true
+}
@@ -0,0 +1,13 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// Tracing starts the server span of each request with otelgin, continuing the trace of
+// the caller's traceparent header. Queries and events of the request join the trace
+// through the request context.
+func Tracing() gin.HandlerFunc {
+	return otelgin.Middleware("user-services")
+}
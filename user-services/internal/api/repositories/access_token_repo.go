@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"user-services/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrAccessTokenLimit is returned when a user already holds the most active tokens
+// allowed.
+var ErrAccessTokenLimit = errors.New("access token limit reached")
+
+// AccessTokenRepository stores personal access tokens.
+type AccessTokenRepository interface {
+	// Create saves token unless its user already holds maxActive active tokens, in which
+	// case it fails with ErrAccessTokenLimit.
+	Create(ctx context.Context, token *models.PersonalAccessToken, maxActive int) error
+	// ListByUser returns the user's tokens, newest first, revoked ones included.
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]models.PersonalAccessToken, error)
+	GetByHash(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error)
+	// Revoke revokes one of the user's tokens. It reports false when the user has no
+	// such unrevoked token.
+	Revoke(ctx context.Context, userID, tokenID uuid.UUID) (bool, error)
+	// RecordUse stores when and from where the token was last used.
+	RecordUse(ctx context.Context, tokenID uuid.UUID, usedAt time.Time, ip *string) error
+}
+
+type accessTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewAccessTokenRepository(db *gorm.DB) AccessTokenRepository {
+	return &accessTokenRepository{db: db}
+}
+
+func (r *accessTokenRepository) Create(ctx context.Context, token *models.PersonalAccessToken, maxActive int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the user so concurrent creations cannot both pass the limit
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			Where("id = ?", token.UserID).
+			First(&user).Error; err != nil {
+			return err
+		}
+
+		var active int64
+		if err := tx.Model(&models.PersonalAccessToken{}).
+			Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", token.UserID, time.Now()).
+			Count(&active).Error; err != nil {
+			return err
+		}
+		if active >= int64(maxActive) {
+			return ErrAccessTokenLimit
+		}
+		return tx.Create(token).Error
+	})
+}
+
+func (r *accessTokenRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.PersonalAccessToken, error) {
+	var tokens []models.PersonalAccessToken
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (r *accessTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error) {
+	var token models.PersonalAccessToken
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *accessTokenRepository) Revoke(ctx context.Context, userID, tokenID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.PersonalAccessToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", tokenID, userID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *accessTokenRepository) RecordUse(ctx context.Context, tokenID uuid.UUID, usedAt time.Time, ip *string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.PersonalAccessToken{}).
+		Where("id = ?", tokenID).
+		Updates(map[string]any{
+			"last_used_at": usedAt,
+			"last_used_ip": ip,
+		}).Error
+}
@@ -0,0 +1,267 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"user-services/internal/models"
+
+	"github.com/ductan2/microservice-app/shared/softdelete"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrMergeAccountUnavailable is returned when one of the accounts of a merge was deleted
+// or merged elsewhere after the merge was requested.
+var ErrMergeAccountUnavailable = errors.New("account is no longer available for merging")
+
+// AccountMergeRepository stores account merges and moves a merged account's data to the
+// account it is merged into.
+type AccountMergeRepository interface {
+	// Create saves merge and the events emailing its codes, cancelling the target's
+	// earlier pending merge, all in one transaction.
+	Create(ctx context.Context, merge *models.AccountMerge, events []*models.Outbox) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.AccountMerge, error)
+	GetLatestByTarget(ctx context.Context, targetUserID uuid.UUID) (*models.AccountMerge, error)
+	// RecordFailedAttempt counts a wrong code and cancels the merge once maxAttempts is
+	// reached. It reports whether the merge was cancelled.
+	RecordFailedAttempt(ctx context.Context, id uuid.UUID, maxAttempts int) (bool, error)
+	// Cancel cancels a pending merge and reports whether there was one.
+	Cancel(ctx context.Context, id uuid.UUID) (bool, error)
+	// Complete moves the source account's sessions, activity sessions, MFA methods,
+	// preferences and organization memberships to the target, revokes the source's
+	// access tokens, tombstones the source and writes event to the outbox, all in one
+	// transaction. It reports false, changing nothing, when the merge is no longer
+	// pending or has expired, and fails with ErrMergeAccountUnavailable when either
+	// account was deleted or merged meanwhile. The returned report counts the rows
+	// changed per step.
+	Complete(ctx context.Context, merge *models.AccountMerge, event *models.Outbox) (map[string]int64, bool, error)
+}
+
+type accountMergeRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountMergeRepository(db *gorm.DB) AccountMergeRepository {
+	return &accountMergeRepository{db: db}
+}
+
+func (r *accountMergeRepository) Create(ctx context.Context, merge *models.AccountMerge, events []*models.Outbox) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&models.AccountMerge{}).
+			Where("target_user_id = ? AND status = ?", merge.TargetUserID, models.AccountMergeStatusPending).
+			Updates(map[string]any{
+				"status":       models.AccountMergeStatusCancelled,
+				"cancelled_at": now,
+				"updated_at":   now,
+			}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(merge).Error; err != nil {
+			return err
+		}
+		for _, event := range events {
+			event.AggregateID = merge.ID
+			if err := tx.Create(event).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *accountMergeRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.AccountMerge, error) {
+	var merge models.AccountMerge
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&merge).Error; err != nil {
+		return nil, err
+	}
+	return &merge, nil
+}
+
+func (r *accountMergeRepository) GetLatestByTarget(ctx context.Context, targetUserID uuid.UUID) (*models.AccountMerge, error) {
+	var merge models.AccountMerge
+	if err := r.db.WithContext(ctx).
+		Where("target_user_id = ?", targetUserID).
+		Order("created_at DESC").
+		First(&merge).Error; err != nil {
+		return nil, err
+	}
+	return &merge, nil
+}
+
+func (r *accountMergeRepository) RecordFailedAttempt(ctx context.Context, id uuid.UUID, maxAttempts int) (bool, error) {
+	now := time.Now()
+	result := r.db.WithContext(ctx).
+		Model(&models.AccountMerge{}).
+		Where("id = ? AND status = ?", id, models.AccountMergeStatusPending).
+		Updates(map[string]any{
+			"attempts":     gorm.Expr("attempts + 1"),
+			"status":       gorm.Expr("CASE WHEN attempts + 1 >= ? THEN ? ELSE status END", maxAttempts, models.AccountMergeStatusCancelled),
+			"cancelled_at": gorm.Expr("CASE WHEN attempts + 1 >= ? THEN ?::timestamptz ELSE cancelled_at END", maxAttempts, now),
+			"updated_at":   now,
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+
+	var status string
+	if err := r.db.WithContext(ctx).
+		Model(&models.AccountMerge{}).
+		Where("id = ?", id).
+		Pluck("status", &status).Error; err != nil {
+		return false, err
+	}
+	return status == models.AccountMergeStatusCancelled, nil
+}
+
+func (r *accountMergeRepository) Cancel(ctx context.Context, id uuid.UUID) (bool, error) {
+	now := time.Now()
+	result := r.db.WithContext(ctx).
+		Model(&models.AccountMerge{}).
+		Where("id = ? AND status = ?", id, models.AccountMergeStatusPending).
+		Updates(map[string]any{
+			"status":       models.AccountMergeStatusCancelled,
+			"cancelled_at": now,
+			"updated_at":   now,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *accountMergeRepository) Complete(ctx context.Context, merge *models.AccountMerge, event *models.Outbox) (map[string]int64, bool, error) {
+	report := map[string]int64{}
+	claimed := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// Claiming the merge first takes its row lock, so a concurrent confirmation
+		// blocks here and then finds the merge no longer pending
+		claim := tx.Model(&models.AccountMerge{}).
+			Where("id = ? AND status = ? AND expires_at > ?", merge.ID, models.AccountMergeStatusPending, now).
+			Updates(map[string]any{
+				"status":       models.AccountMergeStatusCompleted,
+				"completed_at": now,
+				"updated_at":   now,
+			})
+		if claim.Error != nil || claim.RowsAffected == 0 {
+			return claim.Error
+		}
+		claimed = true
+
+		// Lock both accounts, in a fixed order so that two merges between the same
+		// accounts cannot deadlock
+		var users []models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "status", "deleted_at", "merged_into_id").
+			Where("id IN ?", []uuid.UUID{merge.TargetUserID, merge.SourceUserID}).
+			Order("id").
+			Find(&users).Error; err != nil {
+			return err
+		}
+		if len(users) != 2 {
+			return ErrMergeAccountUnavailable
+		}
+		for _, user := range users {
+			if user.Status == models.StatusDeleted || user.DeletedAt.Valid || user.MergedIntoID != nil {
+				return ErrMergeAccountUnavailable
+			}
+		}
+
+		target, source := merge.TargetUserID, merge.SourceUserID
+		placeholder := "merged-" + source.String() + "@merged.invalid"
+		steps := []struct {
+			name string
+			sql  string
+			args []any
+		}{
+			// The source's sessions were revoked before the merge; moving them keeps the
+			// target's session history and the study time of their activity sessions
+			{"sessions", `UPDATE sessions SET user_id = ? WHERE user_id = ?`,
+				[]any{target, source}},
+			{"user_activity_sessions", `UPDATE user_activity_sessions SET user_id = ?,
+				updated_at = ? WHERE user_id = ?`,
+				[]any{target, now, source}},
+			// Passkeys all move; a TOTP app or phone number only when the target has
+			// none, since an account holds at most one of each
+			{"mfa_methods", `UPDATE mfa_methods SET user_id = ?,
+				priority = priority + (SELECT COALESCE(MAX(priority) + 1, 0) FROM mfa_methods WHERE user_id = ?)
+				WHERE user_id = ? AND (type = 'webauthn' OR NOT EXISTS
+					(SELECT 1 FROM mfa_methods m WHERE m.user_id = ? AND m.type = mfa_methods.type))`,
+				[]any{target, target, source, target}},
+			{"mfa_methods_discarded", `DELETE FROM mfa_methods WHERE user_id = ?`,
+				[]any{source}},
+			// The target's preferences win; the source's are kept only if the target
+			// never changed any
+			{"user_preferences", `UPDATE user_preferences SET user_id = ?, updated_at = ?
+				WHERE user_id = ? AND NOT EXISTS (SELECT 1 FROM user_preferences WHERE user_id = ?)`,
+				[]any{target, now, source, target}},
+			{"user_preferences_discarded", `DELETE FROM user_preferences WHERE user_id = ?`,
+				[]any{source}},
+			// Where both accounts are members of an organization, the target keeps the
+			// stronger of the two roles, so an organization never loses its owner
+			{"organization_roles", `UPDATE organization_members t SET role = s.role, updated_at = ?
+				FROM organization_members s
+				WHERE t.user_id = ? AND s.user_id = ? AND s.organization_id = t.organization_id
+				AND (CASE s.role WHEN 'owner' THEN 0 WHEN 'manager' THEN 1 ELSE 2 END)
+					< (CASE t.role WHEN 'owner' THEN 0 WHEN 'manager' THEN 1 ELSE 2 END)`,
+				[]any{now, target, source}},
+			{"organization_members", `UPDATE organization_members SET user_id = ?, updated_at = ?
+				WHERE user_id = ? AND organization_id NOT IN
+					(SELECT organization_id FROM organization_members WHERE user_id = ?)`,
+				[]any{target, now, source, target}},
+			{"organization_members_discarded", `DELETE FROM organization_members WHERE user_id = ?`,
+				[]any{source}},
+			{"primary_organization", `UPDATE users SET organization_id = (SELECT organization_id FROM users WHERE id = ?),
+				updated_at = ? WHERE id = ? AND organization_id IS NULL`,
+				[]any{source, now, target}},
+			// The devices signed in to the source now receive the target's notifications
+			{"push_tokens", `UPDATE push_tokens SET user_id = ?, updated_at = ? WHERE user_id = ?`,
+				[]any{target, now, source}},
+			{"user_consents", `UPDATE user_consents SET user_id = ? WHERE user_id = ?`,
+				[]any{target, source}},
+			// Access tokens act as the source account and are not carried over
+			{"personal_access_tokens", `UPDATE personal_access_tokens SET revoked_at = ?
+				WHERE user_id = ? AND revoked_at IS NULL`,
+				[]any{now, source}},
+			{"password_resets", `DELETE FROM password_resets WHERE user_id = ? AND consumed_at IS NULL`,
+				[]any{source}},
+			{"account_merges", `UPDATE account_merges SET status = 'cancelled', cancelled_at = ?, updated_at = ?
+				WHERE status = 'pending' AND id <> ? AND (source_user_id = ? OR target_user_id = ?)`,
+				[]any{now, now, merge.ID, source, source}},
+			// The tombstone frees the email address, username and phone number and can no
+			// longer sign in
+			{"users", `UPDATE users SET email = ?, email_normalized = ?, password_hash = '!',
+				email_verification_token = '', email_verification_expiry = NULL,
+				username = NULL, phone_number = NULL, phone_verified_at = NULL,
+				status = 'deleted', deleted_at = ?, deleted_by = ?, organization_id = NULL,
+				merged_into_id = ?, updated_at = ? WHERE id = ?`,
+				[]any{placeholder, placeholder, now, softdelete.System, target, now, source}},
+		}
+		for _, step := range steps {
+			result := tx.Exec(step.sql, step.args...)
+			if result.Error != nil {
+				return result.Error
+			}
+			report[step.name] = result.RowsAffected
+		}
+
+		stored := models.JSONBMap{}
+		for name, count := range report {
+			stored[name] = count
+		}
+		if err := tx.Model(&models.AccountMerge{}).
+			Where("id = ?", merge.ID).
+			Update("report", stored).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(event).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return report, claimed, nil
+}
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"user-services/internal/models"
+
+	"github.com/ductan2/microservice-app/shared/dbreplica"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// touchedActivityDays selects the users and UTC days with a session changed since the
+// first parameter.
+const touchedActivityDays = `
+	WITH touched AS (
+		SELECT DISTINCT user_id, (started_at AT TIME ZONE 'UTC')::date AS day
+		FROM user_activity_sessions
+		WHERE updated_at >= ?
+	)`
+
+// ActivityRollupRepository stores the daily and weekly study time of users.
+type ActivityRollupRepository interface {
+	// Refresh recomputes the day rollups of every user and day with a session changed
+	// since the given time, then the week rollups containing them, and returns the
+	// number of day rollups written.
+	Refresh(ctx context.Context, since time.Time) (int64, error)
+	// LastRefreshed returns when rollups were last written, or the zero time when none
+	// exist yet.
+	LastRefreshed(ctx context.Context) (time.Time, error)
+	// ListByUser returns the user's rollups of one period starting from from up to and
+	// including to, oldest first.
+	ListByUser(ctx context.Context, userID uuid.UUID, period string, from, to time.Time) ([]models.ActivityRollup, error)
+}
+
+type activityRollupRepository struct {
+	db *gorm.DB
+}
+
+func NewActivityRollupRepository(db *gorm.DB) ActivityRollupRepository {
+	return &activityRollupRepository{db: db}
+}
+
+func (r *activityRollupRepository) Refresh(ctx context.Context, since time.Time) (int64, error) {
+	var days int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Rollups outlive the sessions they were computed from, so deleting old
+		// sessions does not erase a user's study history
+		result := tx.Exec(touchedActivityDays+`
+			INSERT INTO activity_rollups (user_id, period, period_start, session_count, total_duration_ms, active_days, updated_at)
+			SELECT s.user_id, 'day', t.day, COUNT(*), SUM(s.duration_ms), 1, now()
+			FROM touched t
+			JOIN user_activity_sessions s
+				ON s.user_id = t.user_id AND (s.started_at AT TIME ZONE 'UTC')::date = t.day
+			WHERE s.duration_ms > 0
+			GROUP BY s.user_id, t.day
+			ON CONFLICT (user_id, period, period_start) DO UPDATE SET
+				session_count     = EXCLUDED.session_count,
+				total_duration_ms = EXCLUDED.total_duration_ms,
+				active_days       = EXCLUDED.active_days,
+				updated_at        = EXCLUDED.updated_at
+		`, since)
+		if result.Error != nil {
+			return result.Error
+		}
+		days = result.RowsAffected
+
+		return tx.Exec(touchedActivityDays+`
+			, weeks AS (
+				SELECT DISTINCT user_id, date_trunc('week', day)::date AS week FROM touched
+			)
+			INSERT INTO activity_rollups (user_id, period, period_start, session_count, total_duration_ms, active_days, updated_at)
+			SELECT d.user_id, 'week', w.week, SUM(d.session_count), SUM(d.total_duration_ms), COUNT(*), now()
+			FROM weeks w
+			JOIN activity_rollups d
+				ON d.user_id = w.user_id AND d.period = 'day'
+				AND d.period_start >= w.week AND d.period_start < w.week + 7
+			GROUP BY d.user_id, w.week
+			ON CONFLICT (user_id, period, period_start) DO UPDATE SET
+				session_count     = EXCLUDED.session_count,
+				total_duration_ms = EXCLUDED.total_duration_ms,
+				active_days       = EXCLUDED.active_days,
+				updated_at        = EXCLUDED.updated_at
+		`, since).Error
+	})
+	return days, err
+}
+
+func (r *activityRollupRepository) LastRefreshed(ctx context.Context) (time.Time, error) {
+	var last sql.NullTime
+	if err := r.db.WithContext(ctx).Model(&models.ActivityRollup{}).Select("MAX(updated_at)").Scan(&last).Error; err != nil {
+		return time.Time{}, err
+	}
+	return last.Time, nil
+}
+
+// ListByUser reads from a replica when the service has some.
+func (r *activityRollupRepository) ListByUser(ctx context.Context, userID uuid.UUID, period string, from, to time.Time) ([]models.ActivityRollup, error) {
+	var rollups []models.ActivityRollup
+	err := dbreplica.Read(r.db.WithContext(ctx)).
+		Where("user_id = ? AND period = ? AND period_start BETWEEN ? AND ?", userID, period, from.Format(time.DateOnly), to.Format(time.DateOnly)).
+		Order("period_start").
+		Find(&rollups).Error
+	return rollups, err
+}
@@ -8,6 +8,7 @@ import (
 
 	"gorm.io/gorm"
 
+	"github.com/ductan2/microservice-app/shared/dbreplica"
 	"github.com/google/uuid"
 	"user-services/internal/models"
 )
@@ -94,14 +95,16 @@ func (r *activitySessionRepository) GetByUserID(ctx context.Context, userID uuid
 	var sessions []models.UserActivitySession
 	var total int64
 
-	err := r.db.WithContext(ctx).Model(&models.UserActivitySession{}).
+	// The history pages and data exports read from a replica when the service has some
+	db := dbreplica.Read(r.db.WithContext(ctx))
+	err := db.Model(&models.UserActivitySession{}).
 		Where("user_id = ?", userID).
 		Count(&total).Error
 	if err != nil {
 		return nil, 0, err
 	}
 
-	err = r.db.WithContext(ctx).
+	err = db.
 		Where("user_id = ?", userID).
 		Order("created_at DESC").
 		Limit(limit).
@@ -2,17 +2,40 @@ package repositories
 
 import (
 	"context"
+	"time"
+
+	"user-services/internal/audit"
 	"user-services/internal/models"
+	"user-services/internal/utils"
 
+	"github.com/ductan2/microservice-app/shared/dbreplica"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// AuditLogFilter narrows an audit log query. Zero values are ignored.
+type AuditLogFilter struct {
+	UserID  *uuid.UUID
+	ActorID *uuid.UUID
+	// Action matches exactly, or every action under a prefix when it ends in ".*"
+	// (for example "mfa.*")
+	Action string
+	// Actions, when set, restricts the listing to these exact actions
+	Actions []string
+	From    time.Time
+	To      time.Time
+	Limit   int
+	Offset  int
+}
+
+// AuditLogRepository stores the append-only audit trail. Entries can only be created
+// and read; there is deliberately no update or delete.
 type AuditLogRepository interface {
 	Create(ctx context.Context, log *models.AuditLog) error
 	GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]models.AuditLog, error)
 	GetByAction(ctx context.Context, action string, limit, offset int) ([]models.AuditLog, error)
 	CountByUserID(ctx context.Context, userID uuid.UUID) (int64, error)
+	List(ctx context.Context, filter AuditLogFilter) ([]models.AuditLog, int64, error)
 }
 
 type auditLogRepository struct {
@@ -23,21 +46,118 @@ func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
 	return &auditLogRepository{db: db}
 }
 
+// Create appends an entry. The actor, IP and user agent of the current request are
+// filled in from ctx when the caller did not set them. Entries written during an
+// impersonation session are flagged as impersonated in their metadata.
 func (r *auditLogRepository) Create(ctx context.Context, log *models.AuditLog) error {
+	req, ok := audit.FromContext(ctx)
+	if ok {
+		if log.ActorID == nil && req.ActorID != nil {
+			actorID := *req.ActorID
+			log.ActorID = &actorID
+		}
+		if log.IPAddr == nil && req.IPAddr != "" {
+			log.IPAddr = &req.IPAddr
+		}
+		if log.UserAgent == nil && req.UserAgent != "" {
+			log.UserAgent = &req.UserAgent
+		}
+	}
+
+	// ip_addr is an inet column, so anything unparsable would fail the insert
+	if log.IPAddr != nil {
+		if sanitized := utils.SanitizeIPAddress(*log.IPAddr); sanitized != "" {
+			log.IPAddr = &sanitized
+		} else {
+			log.IPAddr = nil
+		}
+	}
+	if log.Metadata == nil {
+		log.Metadata = models.JSONBMap{}
+	}
+	if req.Impersonating {
+		log.Metadata["impersonated"] = true
+	}
+	if log.CreatedAt.IsZero() {
+		log.CreatedAt = time.Now()
+	}
+
 	return r.db.WithContext(ctx).Create(log).Error
 }
 
 func (r *auditLogRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]models.AuditLog, error) {
-	// TODO: implement
-	return nil, nil
+	logs, _, err := r.List(ctx, AuditLogFilter{UserID: &userID, Limit: limit, Offset: offset})
+	return logs, err
 }
 
 func (r *auditLogRepository) GetByAction(ctx context.Context, action string, limit, offset int) ([]models.AuditLog, error) {
-	// TODO: implement
-	return nil, nil
+	logs, _, err := r.List(ctx, AuditLogFilter{Action: action, Limit: limit, Offset: offset})
+	return logs, err
 }
 
 func (r *auditLogRepository) CountByUserID(ctx context.Context, userID uuid.UUID) (int64, error) {
-	// TODO: implement
-	return 0, nil
+	var total int64
+	err := r.db.WithContext(ctx).Model(&models.AuditLog{}).Where("user_id = ?", userID).Count(&total).Error
+	return total, err
+}
+
+// List returns the entries matching filter, newest first, with the total match count,
+// read from a replica when the service has some.
+func (r *auditLogRepository) List(ctx context.Context, filter AuditLogFilter) ([]models.AuditLog, int64, error) {
+	query := dbreplica.Read(r.db.WithContext(ctx)).Model(&models.AuditLog{})
+
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		if prefix, ok := actionPrefix(filter.Action); ok {
+			query = query.Where("action LIKE ?", prefix+"%")
+		} else {
+			query = query.Where("action = ?", filter.Action)
+		}
+	}
+	if len(filter.Actions) > 0 {
+		query = query.Where("action IN ?", filter.Actions)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []models.AuditLog
+	if err := query.
+		Order("created_at DESC, id DESC").
+		Offset(filter.Offset).
+		Limit(filter.Limit).
+		Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return logs, total, nil
+}
+
+// actionPrefix turns "mfa.*" into the LIKE prefix "mfa.", escaping LIKE wildcards.
+func actionPrefix(action string) (string, bool) {
+	if len(action) < 2 || action[len(action)-2:] != ".*" {
+		return "", false
+	}
+	prefix := action[:len(action)-1]
+	escaped := make([]byte, 0, len(prefix))
+	for i := 0; i < len(prefix); i++ {
+		if prefix[i] == '%' || prefix[i] == '_' || prefix[i] == '\\' {
+			escaped = append(escaped, '\\')
+		}
+		escaped = append(escaped, prefix[i])
+	}
+	return string(escaped), true
 }
@@ -0,0 +1,205 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"user-services/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AvatarRepository stores avatar uploads, the avatar on the profile and the queue of
+// object store keys to delete. Whenever a key stops being referenced it is queued in the
+// same transaction, so no object is left behind when a step fails.
+type AvatarRepository interface {
+	CreateUpload(ctx context.Context, upload *models.AvatarUpload) error
+	GetUpload(ctx context.Context, id uuid.UUID) (*models.AvatarUpload, error)
+	CountPendingUploads(ctx context.Context, userID uuid.UUID, now time.Time) (int64, error)
+	// FailUpload marks a pending upload failed and queues its object for deletion.
+	FailUpload(ctx context.Context, id uuid.UUID, reason string) error
+	// CompleteUpload marks a pending upload completed and makes key, served at url, the
+	// user's avatar. The uploaded object and the replaced avatar are queued for deletion.
+	// It reports false when the upload is no longer pending.
+	CompleteUpload(ctx context.Context, upload *models.AvatarUpload, key, url string) (bool, error)
+	// SetAvatarURL points the avatar at a URL this service does not store, or removes
+	// it when url is empty, and queues the replaced avatar for deletion.
+	SetAvatarURL(ctx context.Context, userID uuid.UUID, url string) error
+	// ExpireUploads marks pending uploads past their expiry expired and queues their
+	// objects for deletion.
+	ExpireUploads(ctx context.Context, now time.Time) (int64, error)
+	// ListDueDeletions returns queued keys whose deletion may be attempted at now.
+	ListDueDeletions(ctx context.Context, now time.Time, limit int) ([]models.AvatarDeletion, error)
+	DeleteDeletion(ctx context.Context, key string) error
+	// RetryDeletion records a failed deletion attempt and postpones the next one.
+	RetryDeletion(ctx context.Context, key, reason string, notBefore time.Time) error
+}
+
+type avatarRepository struct {
+	db *gorm.DB
+}
+
+func NewAvatarRepository(db *gorm.DB) AvatarRepository {
+	return &avatarRepository{db: db}
+}
+
+func (r *avatarRepository) CreateUpload(ctx context.Context, upload *models.AvatarUpload) error {
+	return r.db.WithContext(ctx).Create(upload).Error
+}
+
+func (r *avatarRepository) GetUpload(ctx context.Context, id uuid.UUID) (*models.AvatarUpload, error) {
+	var upload models.AvatarUpload
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&upload).Error; err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+func (r *avatarRepository) CountPendingUploads(ctx context.Context, userID uuid.UUID, now time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.AvatarUpload{}).
+		Where("user_id = ? AND status = ? AND expires_at > ?", userID, models.AvatarUploadStatusPending, now).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *avatarRepository) FailUpload(ctx context.Context, id uuid.UUID, reason string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var upload models.AvatarUpload
+		if err := tx.Clauses(clause.Returning{}).
+			Model(&upload).
+			Where("id = ? AND status = ?", id, models.AvatarUploadStatusPending).
+			Updates(map[string]any{
+				"status": models.AvatarUploadStatusFailed,
+				"error":  reason,
+			}).Error; err != nil {
+			return err
+		}
+		if upload.ObjectKey == "" {
+			return nil
+		}
+		return queueAvatarDeletions(tx, "rejected", upload.ObjectKey)
+	})
+}
+
+func (r *avatarRepository) CompleteUpload(ctx context.Context, upload *models.AvatarUpload, key, url string) (bool, error) {
+	completed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		claim := tx.Model(&models.AvatarUpload{}).
+			Where("id = ? AND status = ?", upload.ID, models.AvatarUploadStatusPending).
+			Updates(map[string]any{
+				"status":       models.AvatarUploadStatusCompleted,
+				"completed_at": now,
+			})
+		if claim.Error != nil || claim.RowsAffected == 0 {
+			return claim.Error
+		}
+		completed = true
+
+		if err := replaceAvatar(tx, upload.UserID, url, key, now); err != nil {
+			return err
+		}
+		return queueAvatarDeletions(tx, "processed", upload.ObjectKey)
+	})
+	return completed, err
+}
+
+func (r *avatarRepository) SetAvatarURL(ctx context.Context, userID uuid.UUID, url string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return replaceAvatar(tx, userID, url, "", time.Now())
+	})
+}
+
+// replaceAvatar sets the profile's avatar and queues the object of the one it replaces.
+func replaceAvatar(tx *gorm.DB, userID uuid.UUID, url, key string, now time.Time) error {
+	var profile models.UserProfile
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ?", userID).
+		First(&profile).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Model(&models.UserProfile{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]any{
+			"avatar_url": url,
+			"avatar_key": key,
+			"updated_at": now,
+		}).Error; err != nil {
+		return err
+	}
+
+	if profile.AvatarKey == "" || profile.AvatarKey == key {
+		return nil
+	}
+	return queueAvatarDeletions(tx, "replaced", profile.AvatarKey)
+}
+
+func (r *avatarRepository) ExpireUploads(ctx context.Context, now time.Time) (int64, error) {
+	var expired []models.AvatarUpload
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Returning{}).
+			Model(&expired).
+			Where("status = ? AND expires_at <= ?", models.AvatarUploadStatusPending, now).
+			Update("status", models.AvatarUploadStatusExpired).Error; err != nil {
+			return err
+		}
+		if len(expired) == 0 {
+			return nil
+		}
+		keys := make([]string, len(expired))
+		for i, upload := range expired {
+			keys[i] = upload.ObjectKey
+		}
+		return queueAvatarDeletions(tx, "expired", keys...)
+	})
+	return int64(len(expired)), err
+}
+
+func (r *avatarRepository) ListDueDeletions(ctx context.Context, now time.Time, limit int) ([]models.AvatarDeletion, error) {
+	var deletions []models.AvatarDeletion
+	err := r.db.WithContext(ctx).
+		Where("not_before <= ?", now).
+		Order("not_before").
+		Limit(limit).
+		Find(&deletions).Error
+	return deletions, err
+}
+
+func (r *avatarRepository) DeleteDeletion(ctx context.Context, key string) error {
+	return r.db.WithContext(ctx).Where("object_key = ?", key).Delete(&models.AvatarDeletion{}).Error
+}
+
+func (r *avatarRepository) RetryDeletion(ctx context.Context, key, reason string, notBefore time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.AvatarDeletion{}).
+		Where("object_key = ?", key).
+		Updates(map[string]any{
+			"attempts":   gorm.Expr("attempts + 1"),
+			"last_error": reason,
+			"not_before": notBefore,
+		}).Error
+}
+
+// queueAvatarDeletions queues keys for the cleanup worker; a key already queued keeps
+// its place.
+func queueAvatarDeletions(tx *gorm.DB, reason string, keys ...string) error {
+	now := time.Now()
+	deletions := make([]models.AvatarDeletion, 0, len(keys))
+	for _, key := range keys {
+		deletions = append(deletions, models.AvatarDeletion{
+			ObjectKey: key,
+			Reason:    reason,
+			NotBefore: now,
+			CreatedAt: now,
+		})
+	}
+	if len(deletions) == 0 {
+		return nil
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&deletions).Error
+}
@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"context"
+
+	"user-services/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ConsentRepository stores the published versions of the terms of service and privacy
+// policy, and the versions each user accepted.
+type ConsentRepository interface {
+	// PublishPolicy saves a new version of a document. It reports false when the document
+	// already has a version with that name.
+	PublishPolicy(ctx context.Context, policy *models.PolicyVersion) (bool, error)
+	// ListCurrentPolicies returns the most recently published version of each document.
+	ListCurrentPolicies(ctx context.Context) ([]models.PolicyVersion, error)
+	// ListPolicyVersions returns every version of a document, most recent first.
+	ListPolicyVersions(ctx context.Context, document string) ([]models.PolicyVersion, error)
+	// ListRequiredPolicies returns, per document, the most recently published version
+	// users had to accept again.
+	ListRequiredPolicies(ctx context.Context) ([]models.PolicyVersion, error)
+	// RecordConsents appends the user's acceptances to their history.
+	RecordConsents(ctx context.Context, consents []models.UserConsent) error
+	// ListLatestConsents returns the user's most recent acceptance of each document.
+	ListLatestConsents(ctx context.Context, userID uuid.UUID) ([]models.UserConsent, error)
+	// ListConsentHistory returns every acceptance of the user, most recent first.
+	ListConsentHistory(ctx context.Context, userID uuid.UUID) ([]models.UserConsent, error)
+}
+
+type consentRepository struct {
+	db *gorm.DB
+}
+
+func NewConsentRepository(db *gorm.DB) ConsentRepository {
+	return &consentRepository{db: db}
+}
+
+func (r *consentRepository) PublishPolicy(ctx context.Context, policy *models.PolicyVersion) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(policy)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *consentRepository) ListCurrentPolicies(ctx context.Context) ([]models.PolicyVersion, error) {
+	var policies []models.PolicyVersion
+	if err := r.db.WithContext(ctx).
+		Raw(`SELECT DISTINCT ON (document) * FROM policy_versions
+			ORDER BY document, published_at DESC`).
+		Scan(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+func (r *consentRepository) ListPolicyVersions(ctx context.Context, document string) ([]models.PolicyVersion, error) {
+	var policies []models.PolicyVersion
+	if err := r.db.WithContext(ctx).
+		Where("document = ?", document).
+		Order("published_at DESC").
+		Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+func (r *consentRepository) ListRequiredPolicies(ctx context.Context) ([]models.PolicyVersion, error) {
+	var policies []models.PolicyVersion
+	if err := r.db.WithContext(ctx).
+		Raw(`SELECT DISTINCT ON (document) * FROM policy_versions WHERE requires_consent
+			ORDER BY document, published_at DESC`).
+		Scan(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+func (r *consentRepository) RecordConsents(ctx context.Context, consents []models.UserConsent) error {
+	if len(consents) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(&consents).Error
+}
+
+func (r *consentRepository) ListLatestConsents(ctx context.Context, userID uuid.UUID) ([]models.UserConsent, error) {
+	var consents []models.UserConsent
+	if err := r.db.WithContext(ctx).
+		Raw(`SELECT DISTINCT ON (document) * FROM user_consents WHERE user_id = ?
+			ORDER BY document, accepted_at DESC`, userID).
+		Scan(&consents).Error; err != nil {
+		return nil, err
+	}
+	return consents, nil
+}
+
+func (r *consentRepository) ListConsentHistory(ctx context.Context, userID uuid.UUID) ([]models.UserConsent, error) {
+	var consents []models.UserConsent
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("accepted_at DESC").
+		Find(&consents).Error; err != nil {
+		return nil, err
+	}
+	return consents, nil
+}
@@ -0,0 +1,187 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"user-services/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DataExportRepository stores data export jobs and the parts other services send back.
+type DataExportRepository interface {
+	// Create inserts the job together with an empty part for each source.
+	Create(ctx context.Context, export *models.DataExport, sources []string) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.DataExport, error)
+	GetLatestByUserID(ctx context.Context, userID uuid.UUID) (*models.DataExport, error)
+	ListByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]models.DataExport, error)
+	GetParts(ctx context.Context, exportID uuid.UUID) ([]models.DataExportPart, error)
+	// SavePart stores a source's payload. It reports false when the job has no such
+	// source or is no longer collecting.
+	SavePart(ctx context.Context, exportID uuid.UUID, source string, payload []byte) (bool, error)
+	// ListDue returns the jobs ready to build: collecting jobs with every part received or
+	// past their deadline, and building jobs whose builder's lease ran out.
+	ListDue(ctx context.Context, now time.Time, limit int) ([]models.DataExport, error)
+	// ListExpired returns ready jobs whose archive expires before now.
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]models.DataExport, error)
+	// Claim moves a due job to building with a lease, so the job is picked up again if
+	// the build dies. It reports false when another caller claimed the job first.
+	Claim(ctx context.Context, id uuid.UUID, now time.Time, lease time.Duration) (bool, error)
+	MarkReady(ctx context.Context, id uuid.UUID, filePath string, sizeBytes int64, expiresAt time.Time) error
+	MarkFailed(ctx context.Context, id uuid.UUID, reason string) error
+	MarkExpired(ctx context.Context, id uuid.UUID) error
+}
+
+// dueCondition matches jobs ready to build; see ListDue.
+const dueCondition = `(status = ? AND (collect_deadline <= ? OR NOT EXISTS (
+		SELECT 1 FROM data_export_parts p
+		WHERE p.export_id = data_exports.id AND p.received_at IS NULL)))
+	OR (status = ? AND collect_deadline <= ?)`
+
+type dataExportRepository struct {
+	db *gorm.DB
+}
+
+func NewDataExportRepository(db *gorm.DB) DataExportRepository {
+	return &dataExportRepository{db: db}
+}
+
+func (r *dataExportRepository) Create(ctx context.Context, export *models.DataExport, sources []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(export).Error; err != nil {
+			return err
+		}
+		if len(sources) == 0 {
+			return nil
+		}
+		parts := make([]models.DataExportPart, len(sources))
+		for i, source := range sources {
+			parts[i] = models.DataExportPart{ExportID: export.ID, Source: source}
+		}
+		return tx.Create(&parts).Error
+	})
+}
+
+func (r *dataExportRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DataExport, error) {
+	var export models.DataExport
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&export).Error; err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *dataExportRepository) GetLatestByUserID(ctx context.Context, userID uuid.UUID) (*models.DataExport, error) {
+	var export models.DataExport
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("requested_at DESC").
+		First(&export).Error; err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *dataExportRepository) ListByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("requested_at DESC").
+		Limit(limit).
+		Find(&exports).Error
+	return exports, err
+}
+
+func (r *dataExportRepository) GetParts(ctx context.Context, exportID uuid.UUID) ([]models.DataExportPart, error) {
+	var parts []models.DataExportPart
+	err := r.db.WithContext(ctx).
+		Where("export_id = ?", exportID).
+		Order("source ASC").
+		Find(&parts).Error
+	return parts, err
+}
+
+func (r *dataExportRepository) SavePart(ctx context.Context, exportID uuid.UUID, source string, payload []byte) (bool, error) {
+	result := r.db.WithContext(ctx).Exec(`
+		UPDATE data_export_parts p
+		SET payload = ?, received_at = now()
+		FROM data_exports e
+		WHERE p.export_id = e.id
+		  AND p.export_id = ? AND p.source = ?
+		  AND e.status = ?`,
+		payload, exportID, source, models.DataExportStatusCollecting)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *dataExportRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	err := r.db.WithContext(ctx).
+		Where(dueCondition, models.DataExportStatusCollecting, now, models.DataExportStatusBuilding, now).
+		Order("collect_deadline ASC").
+		Limit(limit).
+		Find(&exports).Error
+	return exports, err
+}
+
+func (r *dataExportRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND expires_at <= ?", models.DataExportStatusReady, now).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&exports).Error
+	return exports, err
+}
+
+func (r *dataExportRepository) Claim(ctx context.Context, id uuid.UUID, now time.Time, lease time.Duration) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.DataExport{}).
+		Where("id = ?", id).
+		Where(dueCondition, models.DataExportStatusCollecting, now, models.DataExportStatusBuilding, now).
+		Updates(map[string]any{
+			"status":           models.DataExportStatusBuilding,
+			"collect_deadline": now.Add(lease),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *dataExportRepository) MarkReady(ctx context.Context, id uuid.UUID, filePath string, sizeBytes int64, expiresAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&models.DataExport{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":       models.DataExportStatusReady,
+			"file_path":    filePath,
+			"size_bytes":   sizeBytes,
+			"completed_at": time.Now(),
+			"expires_at":   expiresAt,
+		}).Error
+}
+
+func (r *dataExportRepository) MarkFailed(ctx context.Context, id uuid.UUID, reason string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.DataExport{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":       models.DataExportStatusFailed,
+			"error":        reason,
+			"completed_at": time.Now(),
+		}).Error
+}
+
+func (r *dataExportRepository) MarkExpired(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&models.DataExport{}).
+		Where("id = ? AND status = ?", id, models.DataExportStatusReady).
+		Updates(map[string]any{
+			"status":    models.DataExportStatusExpired,
+			"file_path": "",
+		}).Error
+}
@@ -0,0 +1,324 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"user-services/internal/models"
+
+	"github.com/ductan2/microservice-app/shared/softdelete"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErasureFilter narrows an erasure request listing. Zero values are ignored.
+type ErasureFilter struct {
+	UserID *uuid.UUID
+	Kind   string
+	Status string
+	Limit  int
+	Offset int
+}
+
+// ErasureRepository stores right-to-erasure requests and performs the anonymization of
+// an account's personal data in this service's tables.
+type ErasureRepository interface {
+	Create(ctx context.Context, request *models.ErasureRequest) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.ErasureRequest, error)
+	GetLatestByUserID(ctx context.Context, userID uuid.UUID) (*models.ErasureRequest, error)
+	List(ctx context.Context, filter ErasureFilter) ([]models.ErasureRequest, int64, error)
+	GetAcks(ctx context.Context, requestID uuid.UUID) ([]models.ErasureAck, error)
+	// CancelScheduled cancels the user's erasure if it is still waiting out the retention
+	// window, and reports whether there was one.
+	CancelScheduled(ctx context.Context, userID uuid.UUID) (bool, error)
+	// ListDue returns scheduled requests whose retention window ended before now.
+	ListDue(ctx context.Context, now time.Time, limit int) ([]models.ErasureRequest, error)
+	// ListExpiredDeletions returns accounts deleted before deletedBefore that have no
+	// erasure request other than cancelled ones. Merged accounts are left out.
+	ListExpiredDeletions(ctx context.Context, deletedBefore time.Time, limit int) ([]models.User, error)
+	// Anonymize overwrites the user's personal data, creates an empty ack for each
+	// service and writes event to the outbox, all in one transaction. The request moves
+	// to status. It reports false, changing nothing, when the request is no longer
+	// scheduled. The returned report counts the rows changed per table.
+	Anonymize(ctx context.Context, request *models.ErasureRequest, services []string, status string, ackDeadline time.Time, event *models.Outbox) (map[string]int64, bool, error)
+	// SaveAck records a service's confirmation. It reports false when the request does
+	// not wait for that service or has not been anonymized yet.
+	SaveAck(ctx context.Context, requestID uuid.UUID, ack *models.ErasureAck) (bool, error)
+	// CompleteIfAcknowledged marks an anonymized or incomplete request completed once
+	// every service has confirmed successfully.
+	CompleteIfAcknowledged(ctx context.Context, requestID uuid.UUID) (bool, error)
+	// MarkOverdueIncomplete marks anonymized requests past their ack deadline incomplete.
+	MarkOverdueIncomplete(ctx context.Context, now time.Time) (int64, error)
+}
+
+type erasureRepository struct {
+	db *gorm.DB
+}
+
+func NewErasureRepository(db *gorm.DB) ErasureRepository {
+	return &erasureRepository{db: db}
+}
+
+func (r *erasureRepository) Create(ctx context.Context, request *models.ErasureRequest) error {
+	return r.db.WithContext(ctx).Create(request).Error
+}
+
+func (r *erasureRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ErasureRequest, error) {
+	var request models.ErasureRequest
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&request).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (r *erasureRepository) GetLatestByUserID(ctx context.Context, userID uuid.UUID) (*models.ErasureRequest, error) {
+	var request models.ErasureRequest
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("requested_at DESC").
+		First(&request).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (r *erasureRepository) List(ctx context.Context, filter ErasureFilter) ([]models.ErasureRequest, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.ErasureRequest{})
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var requests []models.ErasureRequest
+	if err := query.
+		Order("requested_at DESC").
+		Offset(filter.Offset).
+		Limit(filter.Limit).
+		Find(&requests).Error; err != nil {
+		return nil, 0, err
+	}
+	return requests, total, nil
+}
+
+func (r *erasureRepository) GetAcks(ctx context.Context, requestID uuid.UUID) ([]models.ErasureAck, error) {
+	var acks []models.ErasureAck
+	err := r.db.WithContext(ctx).
+		Where("request_id = ?", requestID).
+		Order("service ASC").
+		Find(&acks).Error
+	return acks, err
+}
+
+func (r *erasureRepository) CancelScheduled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.ErasureRequest{}).
+		Where("user_id = ? AND status = ?", userID, models.ErasureStatusScheduled).
+		Updates(map[string]any{
+			"status":       models.ErasureStatusCancelled,
+			"cancelled_at": time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *erasureRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]models.ErasureRequest, error) {
+	var requests []models.ErasureRequest
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND scheduled_for <= ?", models.ErasureStatusScheduled, now).
+		Order("scheduled_for ASC").
+		Limit(limit).
+		Find(&requests).Error
+	return requests, err
+}
+
+func (r *erasureRepository) ListExpiredDeletions(ctx context.Context, deletedBefore time.Time, limit int) ([]models.User, error) {
+	var users []models.User
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND deleted_at < ? AND merged_into_id IS NULL", models.StatusDeleted, deletedBefore).
+		Where("NOT EXISTS (SELECT 1 FROM erasure_requests er WHERE er.user_id = users.id AND er.status <> ?)", models.ErasureStatusCancelled).
+		Order("deleted_at ASC").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+func (r *erasureRepository) Anonymize(ctx context.Context, request *models.ErasureRequest, services []string, status string, ackDeadline time.Time, event *models.Outbox) (map[string]int64, bool, error) {
+	report := map[string]int64{}
+	claimed := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// Claiming the request first takes its row lock, so a concurrent run blocks here
+		// and then finds the request no longer scheduled.
+		updates := map[string]any{
+			"status":        status,
+			"anonymized_at": now,
+			"ack_deadline":  ackDeadline,
+		}
+		if status == models.ErasureStatusCompleted {
+			updates["completed_at"] = now
+		}
+		claim := tx.Model(&models.ErasureRequest{}).
+			Where("id = ? AND status = ?", request.ID, models.ErasureStatusScheduled).
+			Updates(updates)
+		if claim.Error != nil || claim.RowsAffected == 0 {
+			return claim.Error
+		}
+		claimed = true
+
+		var email string
+		if err := tx.Model(&models.User{}).Where("id = ?", request.UserID).Pluck("email", &email).Error; err != nil {
+			return err
+		}
+
+		placeholder := "erased-" + request.UserID.String() + "@erased.invalid"
+		steps := []struct {
+			table string
+			sql   string
+			args  []any
+		}{
+			{"users", `UPDATE users SET email = ?, email_normalized = ?, password_hash = '!',
+				email_verification_token = '', email_verification_expiry = NULL,
+				last_login_ip = NULL, username = NULL, phone_number = NULL, phone_verified_at = NULL,
+				status = 'deleted', deleted_at = COALESCE(deleted_at, ?),
+				deleted_by = CASE WHEN deleted_at IS NULL THEN ? ELSE deleted_by END, updated_at = ? WHERE id = ?`,
+				[]any{placeholder, placeholder, now, softdelete.System, now, request.UserID}},
+			// The stored avatar and any upload still in progress are queued for the
+			// avatar cleanup worker to delete from the object store
+			{"avatar_deletions", `INSERT INTO avatar_deletions (object_key, reason)
+				SELECT avatar_key, 'erased' FROM user_profiles WHERE user_id = ? AND avatar_key <> ''
+				UNION SELECT object_key, 'erased' FROM avatar_uploads WHERE user_id = ? AND status = 'pending'
+				ON CONFLICT (object_key) DO NOTHING`,
+				[]any{request.UserID, request.UserID}},
+			{"avatar_uploads", `DELETE FROM avatar_uploads WHERE user_id = ?`,
+				[]any{request.UserID}},
+			{"user_profiles", `UPDATE user_profiles SET display_name = '', avatar_url = '',
+				avatar_key = '', updated_at = ? WHERE user_id = ?`,
+				[]any{now, request.UserID}},
+			{"refresh_tokens", `UPDATE refresh_tokens SET revoked_at = ? WHERE revoked_at IS NULL
+				AND session_id IN (SELECT id FROM sessions WHERE user_id = ?)`,
+				[]any{now, request.UserID}},
+			// Sessions are kept, without the device details, because activity sessions
+			// (study time) reference them
+			{"sessions", `UPDATE sessions SET ip_addr = NULL, user_agent = '', device_type = '',
+				browser = '', os = '', geo_city = '', geo_region = '', geo_country = '',
+				geo_country_code = '', revoked_at = COALESCE(revoked_at, ?) WHERE user_id = ?`,
+				[]any{now, request.UserID}},
+			{"user_activity_sessions", `UPDATE user_activity_sessions SET ip_addr = NULL,
+				user_agent = '' WHERE user_id = ?`,
+				[]any{request.UserID}},
+			{"mfa_methods", `DELETE FROM mfa_methods WHERE user_id = ?`,
+				[]any{request.UserID}},
+			{"push_tokens", `DELETE FROM push_tokens WHERE user_id = ?`,
+				[]any{request.UserID}},
+			// The consent history is kept as proof of what was agreed to, without where
+			// it was given from
+			{"user_consents", `UPDATE user_consents SET ip_addr = NULL, user_agent = ''
+				WHERE user_id = ?`,
+				[]any{request.UserID}},
+			{"password_resets", `DELETE FROM password_resets WHERE user_id = ?`,
+				[]any{request.UserID}},
+			{"login_attempts", `UPDATE login_attempts SET email = '', ip_addr = NULL
+				WHERE user_id = ? OR (email <> '' AND lower(email) = lower(?))`,
+				[]any{request.UserID, email}},
+			{"audit_logs", `UPDATE audit_logs SET ip_addr = NULL, user_agent = NULL
+				WHERE user_id = ? AND (ip_addr IS NOT NULL OR user_agent IS NOT NULL)`,
+				[]any{request.UserID}},
+			{"data_exports", `DELETE FROM data_exports WHERE user_id = ?`,
+				[]any{request.UserID}},
+		}
+		for _, step := range steps {
+			result := tx.Exec(step.sql, step.args...)
+			if result.Error != nil {
+				return result.Error
+			}
+			report[step.table] = result.RowsAffected
+		}
+
+		localReport := models.JSONBMap{}
+		for table, rows := range report {
+			localReport[table] = rows
+		}
+		if err := tx.Model(&models.ErasureRequest{}).
+			Where("id = ?", request.ID).
+			Update("local_report", localReport).Error; err != nil {
+			return err
+		}
+
+		if len(services) > 0 {
+			acks := make([]models.ErasureAck, len(services))
+			for i, service := range services {
+				acks[i] = models.ErasureAck{RequestID: request.ID, Service: service}
+			}
+			if err := tx.Create(&acks).Error; err != nil {
+				return err
+			}
+		}
+
+		if event != nil {
+			return tx.Create(event).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return report, claimed, nil
+}
+
+func (r *erasureRepository) SaveAck(ctx context.Context, requestID uuid.UUID, ack *models.ErasureAck) (bool, error) {
+	result := r.db.WithContext(ctx).Exec(`
+		UPDATE erasure_acks a
+		SET status = ?, records_affected = ?, detail = ?, acknowledged_at = now()
+		FROM erasure_requests e
+		WHERE a.request_id = e.id
+		  AND a.request_id = ? AND a.service = ?
+		  AND e.status IN (?, ?)`,
+		ack.Status, ack.RecordsAffected, ack.Detail, requestID, ack.Service,
+		models.ErasureStatusAnonymized, models.ErasureStatusIncomplete)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *erasureRepository) CompleteIfAcknowledged(ctx context.Context, requestID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Exec(`
+		UPDATE erasure_requests e
+		SET status = ?, completed_at = now()
+		WHERE e.id = ? AND e.status IN (?, ?)
+		  AND NOT EXISTS (
+		      SELECT 1 FROM erasure_acks a
+		      WHERE a.request_id = e.id AND a.status IS DISTINCT FROM ?)`,
+		models.ErasureStatusCompleted, requestID,
+		models.ErasureStatusAnonymized, models.ErasureStatusIncomplete,
+		models.ErasureAckCompleted)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *erasureRepository) MarkOverdueIncomplete(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.ErasureRequest{}).
+		Where("status = ? AND ack_deadline <= ?", models.ErasureStatusAnonymized, now).
+		Updates(map[string]any{
+			"status":       models.ErasureStatusIncomplete,
+			"completed_at": now,
+		})
+	return result.RowsAffected, result.Error
+}
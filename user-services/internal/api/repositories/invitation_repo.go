@@ -0,0 +1,206 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"user-services/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Invitation acceptances the repository refuses.
+var (
+	ErrInvitationNotPending = errors.New("invitation is no longer pending")
+	ErrEmailTaken           = errors.New("email already belongs to an account")
+)
+
+// InvitationFilter narrows an invitation listing. Zero values are ignored. Status may
+// be "expired", matching pending invitations past their expiry, in which case "pending"
+// only matches those still valid.
+type InvitationFilter struct {
+	OrganizationID *uuid.UUID
+	Status         string
+	Email          string
+	Limit          int
+	Offset         int
+}
+
+// InvitationRepository stores invitations and registers the accounts that accept them.
+type InvitationRepository interface {
+	// Create saves a pending invitation and queues event in one transaction. It reports
+	// false, saving nothing, when one is already pending for the email and organization.
+	Create(ctx context.Context, invitation *models.Invitation, event *models.Outbox) (bool, error)
+	// GetByID returns the invitation with its organization.
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Invitation, error)
+	List(ctx context.Context, filter InvitationFilter) ([]models.Invitation, int64, error)
+	// Resend replaces the nonce and expiry of a pending invitation and queues event. It
+	// reports false, changing nothing, when the invitation is no longer pending.
+	Resend(ctx context.Context, id uuid.UUID, nonceHash string, expiresAt time.Time, event *models.Outbox) (bool, error)
+	// Revoke cancels a pending invitation. It reports false when it is no longer pending.
+	Revoke(ctx context.Context, id uuid.UUID) (bool, error)
+	// Accept registers user, with their profile and the invitation's organization
+	// membership, and marks the invitation accepted, in one transaction. It fails with
+	// ErrInvitationNotPending when the invitation was accepted, revoked or resent since
+	// nonceHash was read, ErrEmailTaken when the email has an account, or
+	// ErrOrganizationFull when a learner has no seat left.
+	Accept(ctx context.Context, invitation *models.Invitation, nonceHash string, user *models.User, profile *models.UserProfile) error
+}
+
+type invitationRepository struct {
+	db *gorm.DB
+}
+
+func NewInvitationRepository(db *gorm.DB) InvitationRepository {
+	return &invitationRepository{db: db}
+}
+
+func (r *invitationRepository) Create(ctx context.Context, invitation *models.Invitation, event *models.Outbox) (bool, error) {
+	created := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Create(invitation)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	return created && err == nil, err
+}
+
+func (r *invitationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Invitation, error) {
+	var invitation models.Invitation
+	if err := r.db.WithContext(ctx).Preload("Organization").Where("id = ?", id).First(&invitation).Error; err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *invitationRepository) List(ctx context.Context, filter InvitationFilter) ([]models.Invitation, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Invitation{})
+	if filter.OrganizationID != nil {
+		query = query.Where("organization_id = ?", *filter.OrganizationID)
+	}
+	if filter.Email != "" {
+		query = query.Where("lower(email) = ?", strings.ToLower(filter.Email))
+	}
+	switch filter.Status {
+	case "":
+	case models.InvitationStatusExpired:
+		query = query.Where("status = ? AND expires_at < ?", models.InvitationStatusPending, time.Now())
+	case models.InvitationStatusPending:
+		query = query.Where("status = ? AND expires_at >= ?", models.InvitationStatusPending, time.Now())
+	default:
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var invitations []models.Invitation
+	if err := query.
+		Preload("Organization").
+		Order("created_at DESC").
+		Offset(filter.Offset).
+		Limit(filter.Limit).
+		Find(&invitations).Error; err != nil {
+		return nil, 0, err
+	}
+	return invitations, total, nil
+}
+
+func (r *invitationRepository) Resend(ctx context.Context, id uuid.UUID, nonceHash string, expiresAt time.Time, event *models.Outbox) (bool, error) {
+	resent := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.Invitation{}).
+			Where("id = ? AND status = ?", id, models.InvitationStatusPending).
+			Updates(map[string]any{
+				"nonce_hash":   nonceHash,
+				"expires_at":   expiresAt,
+				"sent_count":   gorm.Expr("sent_count + 1"),
+				"last_sent_at": now,
+				"updated_at":   now,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+		resent = true
+		return nil
+	})
+	return resent && err == nil, err
+}
+
+func (r *invitationRepository) Revoke(ctx context.Context, id uuid.UUID) (bool, error) {
+	now := time.Now()
+	result := r.db.WithContext(ctx).
+		Model(&models.Invitation{}).
+		Where("id = ? AND status = ?", id, models.InvitationStatusPending).
+		Updates(map[string]any{
+			"status":     models.InvitationStatusRevoked,
+			"revoked_at": now,
+			"updated_at": now,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *invitationRepository) Accept(ctx context.Context, invitation *models.Invitation, nonceHash string, user *models.User, profile *models.UserProfile) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Claiming the invitation first takes its row lock, so a concurrent acceptance
+		// blocks here and then finds it accepted
+		now := time.Now()
+		result := tx.Model(&models.Invitation{}).
+			Where("id = ? AND status = ? AND nonce_hash = ?", invitation.ID, models.InvitationStatusPending, nonceHash).
+			Updates(map[string]any{
+				"status":           models.InvitationStatusAccepted,
+				"accepted_user_id": user.ID,
+				"accepted_at":      now,
+				"updated_at":       now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvitationNotPending
+		}
+
+		var taken int64
+		if err := tx.Model(&models.User{}).Where("lower(email) = ?", strings.ToLower(user.Email)).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			return ErrEmailTaken
+		}
+		result = tx.Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Create(user)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrEmailTaken
+		}
+		if err := tx.Create(profile).Error; err != nil {
+			return err
+		}
+
+		if invitation.OrganizationID != nil && invitation.OrgRole != nil {
+			return addMember(tx, &models.OrganizationMember{
+				OrganizationID: *invitation.OrganizationID,
+				UserID:         user.ID,
+				Role:           *invitation.OrgRole,
+			})
+		}
+		return nil
+	})
+}
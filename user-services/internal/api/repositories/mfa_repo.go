@@ -13,7 +13,14 @@ type MFARepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*models.MFAMethod, error)
 	GetByUserID(ctx context.Context, userID uuid.UUID) ([]models.MFAMethod, error)
 	GetTOTPByUserID(ctx context.Context, userID uuid.UUID) (*models.MFAMethod, error)
+	GetWebAuthnByUserID(ctx context.Context, userID uuid.UUID) ([]models.MFAMethod, error)
+	GetByCredentialID(ctx context.Context, credentialID string) (*models.MFAMethod, error)
+	GetPhoneByUserID(ctx context.Context, userID uuid.UUID) (*models.MFAMethod, error)
 	UpdateLastUsed(ctx context.Context, id uuid.UUID) error
+	UpdateSignCount(ctx context.Context, id uuid.UUID, signCount int64) error
+	UpdateLabel(ctx context.Context, id uuid.UUID, label string) error
+	MarkVerified(ctx context.Context, id uuid.UUID) error
+	UpdatePriorities(ctx context.Context, userID uuid.UUID, orderedIDs []uuid.UUID) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
@@ -37,9 +44,10 @@ func (r *mfaRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.MFAM
 	return &m, nil
 }
 
+// GetByUserID returns the user's methods in fallback order
 func (r *mfaRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]models.MFAMethod, error) {
 	var methods []models.MFAMethod
-	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&methods).Error
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("priority, added_at").Find(&methods).Error
 	return methods, err
 }
 
@@ -51,10 +59,64 @@ func (r *mfaRepository) GetTOTPByUserID(ctx context.Context, userID uuid.UUID) (
 	return &m, nil
 }
 
+func (r *mfaRepository) GetWebAuthnByUserID(ctx context.Context, userID uuid.UUID) ([]models.MFAMethod, error) {
+	var methods []models.MFAMethod
+	err := r.db.WithContext(ctx).Where("user_id = ? AND type = ?", userID, models.MFATypeWebAuthn).Order("added_at").Find(&methods).Error
+	return methods, err
+}
+
+func (r *mfaRepository) GetByCredentialID(ctx context.Context, credentialID string) (*models.MFAMethod, error) {
+	var m models.MFAMethod
+	if err := r.db.WithContext(ctx).Where("credential_id = ? AND type = ?", credentialID, models.MFATypeWebAuthn).First(&m).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *mfaRepository) GetPhoneByUserID(ctx context.Context, userID uuid.UUID) (*models.MFAMethod, error) {
+	var m models.MFAMethod
+	if err := r.db.WithContext(ctx).Where("user_id = ? AND type = ?", userID, models.MFATypePhone).First(&m).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
 func (r *mfaRepository) UpdateLastUsed(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Model(&models.MFAMethod{}).Where("id = ?", id).Update("last_used_at", gorm.Expr("now()")).Error
 }
 
+// UpdateSignCount stores the authenticator's signature counter after a successful
+// assertion and marks the credential as used.
+func (r *mfaRepository) UpdateSignCount(ctx context.Context, id uuid.UUID, signCount int64) error {
+	return r.db.WithContext(ctx).Model(&models.MFAMethod{}).Where("id = ?", id).Updates(map[string]any{
+		"sign_count":   signCount,
+		"last_used_at": gorm.Expr("now()"),
+	}).Error
+}
+
+func (r *mfaRepository) UpdateLabel(ctx context.Context, id uuid.UUID, label string) error {
+	return r.db.WithContext(ctx).Model(&models.MFAMethod{}).Where("id = ?", id).Update("label", label).Error
+}
+
+func (r *mfaRepository) MarkVerified(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&models.MFAMethod{}).Where("id = ?", id).Updates(map[string]any{
+		"verified_at":  gorm.Expr("now()"),
+		"last_used_at": gorm.Expr("now()"),
+	}).Error
+}
+
+// UpdatePriorities sets each method's priority to its position in orderedIDs
+func (r *mfaRepository) UpdatePriorities(ctx context.Context, userID uuid.UUID, orderedIDs []uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, id := range orderedIDs {
+			if err := tx.Model(&models.MFAMethod{}).Where("id = ? AND user_id = ?", id, userID).Update("priority", i).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (r *mfaRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.MFAMethod{}).Error
 }
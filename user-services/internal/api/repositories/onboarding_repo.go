@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+	stderrors "errors"
+
+	"user-services/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OnboardingEventsFunc returns the events announcing a step's transition out of the
+// previous status, given every step recorded for the user once it has been applied.
+type OnboardingEventsFunc func(previous string, steps []models.OnboardingStep) ([]*models.Outbox, error)
+
+// OnboardingRepository stores the onboarding steps users have completed or skipped.
+type OnboardingRepository interface {
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]models.OnboardingStep, error)
+	// Advance records step unless the user already completed it, or already skipped it
+	// and step skips it again. The user row is locked so transitions of the same user are
+	// serialized, and the events returned by events are written to the outbox in the
+	// same transaction. It reports whether the step changed and returns every step
+	// recorded for the user.
+	Advance(ctx context.Context, step *models.OnboardingStep, events OnboardingEventsFunc) (bool, []models.OnboardingStep, error)
+}
+
+type onboardingRepository struct {
+	db *gorm.DB
+}
+
+func NewOnboardingRepository(db *gorm.DB) OnboardingRepository {
+	return &onboardingRepository{db: db}
+}
+
+func (r *onboardingRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]models.OnboardingStep, error) {
+	var steps []models.OnboardingStep
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&steps).Error
+	return steps, err
+}
+
+func (r *onboardingRepository) Advance(ctx context.Context, step *models.OnboardingStep, events OnboardingEventsFunc) (bool, []models.OnboardingStep, error) {
+	changed := false
+	var steps []models.OnboardingStep
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			Where("id = ?", step.UserID).
+			First(&user).Error; err != nil {
+			return err
+		}
+
+		previous := models.OnboardingStatusPending
+		var current models.OnboardingStep
+		err := tx.Where("user_id = ? AND step = ?", step.UserID, step.Step).First(&current).Error
+		switch {
+		case stderrors.Is(err, gorm.ErrRecordNotFound):
+			if err := tx.Create(step).Error; err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		case current.Status == models.OnboardingStatusCompleted || current.Status == step.Status:
+			*step = current
+			return tx.Where("user_id = ?", step.UserID).Find(&steps).Error
+		default:
+			previous = current.Status
+			if err := tx.Save(step).Error; err != nil {
+				return err
+			}
+		}
+		changed = true
+
+		if err := tx.Where("user_id = ?", step.UserID).Find(&steps).Error; err != nil {
+			return err
+		}
+		outbox, err := events(previous, steps)
+		if err != nil {
+			return err
+		}
+		for _, event := range outbox {
+			if err := tx.Create(event).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return false, nil, err
+	}
+	return changed, steps, nil
+}
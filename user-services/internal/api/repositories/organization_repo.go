@@ -0,0 +1,395 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"user-services/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Membership changes the repository refuses.
+var (
+	ErrOrganizationFull      = errors.New("organization has no seats left")
+	ErrSeatLimitBelowUsage   = errors.New("seat limit is lower than the seats taken")
+	ErrAlreadyMember         = errors.New("user is already a member of the organization")
+	ErrLastOrganizationOwner = errors.New("organization must keep at least one owner")
+)
+
+// OrganizationFilter narrows an organization listing. Zero values are ignored.
+type OrganizationFilter struct {
+	Search string
+	Limit  int
+	Offset int
+}
+
+// OrganizationMemberFilter narrows a member listing. Zero values are ignored.
+type OrganizationMemberFilter struct {
+	Role   string
+	Limit  int
+	Offset int
+}
+
+// OrganizationRepository stores the organizations accounts are assigned to and their
+// members. Membership changes lock the organization's row, so seat and owner counts
+// cannot be raced past their limits.
+type OrganizationRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Organization, error)
+	// GetByName returns the organization with the given name, ignoring case.
+	GetByName(ctx context.Context, name string) (*models.Organization, error)
+	// FindOrCreate returns the organization with the given name, ignoring case, creating
+	// it when there is none.
+	FindOrCreate(ctx context.Context, name string) (*models.Organization, error)
+	// Create saves a new organization, with owner as its first owner when set. It
+	// reports false, saving nothing, when the name is taken.
+	Create(ctx context.Context, org *models.Organization, owner *models.OrganizationMember) (bool, error)
+	List(ctx context.Context, filter OrganizationFilter) ([]models.Organization, int64, error)
+	// Update saves the organization's name and seat limit. It fails with
+	// ErrSeatLimitBelowUsage when the limit is lower than the learners it has.
+	Update(ctx context.Context, org *models.Organization) error
+	// CountMembers returns the number of members of the organization per role.
+	CountMembers(ctx context.Context, orgID uuid.UUID) (map[string]int64, error)
+	GetMember(ctx context.Context, orgID, userID uuid.UUID) (*models.OrganizationMember, error)
+	// ListMembers returns the organization's members with their user and profile.
+	ListMembers(ctx context.Context, orgID uuid.UUID, filter OrganizationMemberFilter) ([]models.OrganizationMember, int64, error)
+	// ListUserMemberships returns every membership of the user with its organization.
+	ListUserMemberships(ctx context.Context, userID uuid.UUID) ([]models.OrganizationMember, error)
+	// AddMember saves a membership, making the organization the user's primary one if
+	// they have none. It fails with ErrAlreadyMember, or ErrOrganizationFull when a
+	// learner would exceed the seat limit.
+	AddMember(ctx context.Context, member *models.OrganizationMember) error
+	// UpdateMemberRole changes a member's role. It fails with ErrLastOrganizationOwner
+	// when the only owner would be demoted, or ErrOrganizationFull when a member would
+	// become a learner without a seat left.
+	UpdateMemberRole(ctx context.Context, orgID, userID uuid.UUID, role string) (*models.OrganizationMember, error)
+	// RemoveMember deletes a membership. A user whose primary organization it was falls
+	// back to their oldest remaining membership, or none. It fails with
+	// ErrLastOrganizationOwner when the only owner would leave.
+	RemoveMember(ctx context.Context, orgID, userID uuid.UUID) error
+}
+
+type organizationRepository struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository(db *gorm.DB) OrganizationRepository {
+	return &organizationRepository{db: db}
+}
+
+func (r *organizationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Organization, error) {
+	var org models.Organization
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&org).Error; err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *organizationRepository) GetByName(ctx context.Context, name string) (*models.Organization, error) {
+	var org models.Organization
+	if err := r.db.WithContext(ctx).Where("lower(name) = lower(?)", strings.TrimSpace(name)).First(&org).Error; err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *organizationRepository) FindOrCreate(ctx context.Context, name string) (*models.Organization, error) {
+	name = strings.TrimSpace(name)
+
+	// A concurrent import may create the same organization; the unique index on
+	// lower(name) turns the loser's insert into a no-op and both read the winner's row
+	org := models.Organization{Name: name}
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&org).Error; err != nil {
+		return nil, err
+	}
+
+	return r.GetByName(ctx, name)
+}
+
+func (r *organizationRepository) Create(ctx context.Context, org *models.Organization, owner *models.OrganizationMember) (bool, error) {
+	created := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(org)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		if owner != nil {
+			owner.OrganizationID = org.ID
+			if err := addMember(tx, owner); err != nil {
+				return err
+			}
+		}
+		created = true
+		return nil
+	})
+	return created && err == nil, err
+}
+
+func (r *organizationRepository) List(ctx context.Context, filter OrganizationFilter) ([]models.Organization, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Organization{})
+	if filter.Search != "" {
+		query = query.Where("name ILIKE ?", "%"+filter.Search+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var orgs []models.Organization
+	if err := query.
+		Order("lower(name) ASC").
+		Offset(filter.Offset).
+		Limit(filter.Limit).
+		Find(&orgs).Error; err != nil {
+		return nil, 0, err
+	}
+	return orgs, total, nil
+}
+
+func (r *organizationRepository) Update(ctx context.Context, org *models.Organization) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockOrganization(tx, org.ID); err != nil {
+			return err
+		}
+		if org.SeatLimit != nil {
+			learners, err := countRole(tx, org.ID, models.OrgRoleLearner)
+			if err != nil {
+				return err
+			}
+			if learners > int64(*org.SeatLimit) {
+				return ErrSeatLimitBelowUsage
+			}
+		}
+
+		org.UpdatedAt = time.Now()
+		return tx.Model(&models.Organization{}).
+			Where("id = ?", org.ID).
+			Updates(map[string]any{
+				"name":       org.Name,
+				"seat_limit": org.SeatLimit,
+				"updated_at": org.UpdatedAt,
+			}).Error
+	})
+}
+
+func (r *organizationRepository) CountMembers(ctx context.Context, orgID uuid.UUID) (map[string]int64, error) {
+	var rows []struct {
+		Role  string
+		Count int64
+	}
+	if err := r.db.WithContext(ctx).
+		Model(&models.OrganizationMember{}).
+		Select("role, COUNT(*) AS count").
+		Where("organization_id = ?", orgID).
+		Group("role").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int64{
+		models.OrgRoleOwner:   0,
+		models.OrgRoleManager: 0,
+		models.OrgRoleLearner: 0,
+	}
+	for _, row := range rows {
+		counts[row.Role] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *organizationRepository) GetMember(ctx context.Context, orgID, userID uuid.UUID) (*models.OrganizationMember, error) {
+	var member models.OrganizationMember
+	if err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND user_id = ?", orgID, userID).
+		First(&member).Error; err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *organizationRepository) ListMembers(ctx context.Context, orgID uuid.UUID, filter OrganizationMemberFilter) ([]models.OrganizationMember, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.OrganizationMember{}).Where("organization_id = ?", orgID)
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var members []models.OrganizationMember
+	if err := query.
+		Preload("User.Profile").
+		Order("created_at ASC, user_id ASC").
+		Offset(filter.Offset).
+		Limit(filter.Limit).
+		Find(&members).Error; err != nil {
+		return nil, 0, err
+	}
+	return members, total, nil
+}
+
+func (r *organizationRepository) ListUserMemberships(ctx context.Context, userID uuid.UUID) ([]models.OrganizationMember, error) {
+	var members []models.OrganizationMember
+	err := r.db.WithContext(ctx).
+		Preload("Organization").
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&members).Error
+	return members, err
+}
+
+func (r *organizationRepository) AddMember(ctx context.Context, member *models.OrganizationMember) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return addMember(tx, member)
+	})
+}
+
+func (r *organizationRepository) UpdateMemberRole(ctx context.Context, orgID, userID uuid.UUID, role string) (*models.OrganizationMember, error) {
+	var member models.OrganizationMember
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		org, err := lockOrganization(tx, orgID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("organization_id = ? AND user_id = ?", orgID, userID).First(&member).Error; err != nil {
+			return err
+		}
+		if member.Role == role {
+			return nil
+		}
+
+		if member.Role == models.OrgRoleOwner {
+			if err := ensureAnotherOwner(tx, orgID); err != nil {
+				return err
+			}
+		}
+		if role == models.OrgRoleLearner {
+			if err := ensureSeat(tx, org); err != nil {
+				return err
+			}
+		}
+
+		member.Role = role
+		member.UpdatedAt = time.Now()
+		return tx.Model(&models.OrganizationMember{}).
+			Where("organization_id = ? AND user_id = ?", orgID, userID).
+			Updates(map[string]any{"role": member.Role, "updated_at": member.UpdatedAt}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *organizationRepository) RemoveMember(ctx context.Context, orgID, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := lockOrganization(tx, orgID); err != nil {
+			return err
+		}
+		var member models.OrganizationMember
+		if err := tx.Where("organization_id = ? AND user_id = ?", orgID, userID).First(&member).Error; err != nil {
+			return err
+		}
+		if member.Role == models.OrgRoleOwner {
+			if err := ensureAnotherOwner(tx, orgID); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("organization_id = ? AND user_id = ?", orgID, userID).
+			Delete(&models.OrganizationMember{}).Error; err != nil {
+			return err
+		}
+
+		// Fall back to the oldest remaining membership as the primary organization
+		return tx.Exec(`
+			UPDATE users SET organization_id = (
+				SELECT organization_id FROM organization_members
+				WHERE user_id = ? ORDER BY created_at ASC LIMIT 1
+			)
+			WHERE id = ? AND organization_id = ?`, userID, userID, orgID).Error
+	})
+}
+
+// addMember saves a membership within tx. Callers must not hold rows of other
+// organizations locked, as it locks the member's organization.
+func addMember(tx *gorm.DB, member *models.OrganizationMember) error {
+	org, err := lockOrganization(tx, member.OrganizationID)
+	if err != nil {
+		return err
+	}
+	if member.Role == models.OrgRoleLearner {
+		if err := ensureSeat(tx, org); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	member.CreatedAt, member.UpdatedAt = now, now
+	result := tx.Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Create(member)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAlreadyMember
+	}
+
+	return tx.Model(&models.User{}).
+		Where("id = ? AND organization_id IS NULL", member.UserID).
+		Update("organization_id", member.OrganizationID).Error
+}
+
+// lockOrganization reads the organization, holding its row lock until tx ends.
+func lockOrganization(tx *gorm.DB, orgID uuid.UUID) (*models.Organization, error) {
+	var org models.Organization
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", orgID).
+		First(&org).Error; err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+// ensureSeat fails with ErrOrganizationFull when one more learner would exceed the
+// organization's seat limit.
+func ensureSeat(tx *gorm.DB, org *models.Organization) error {
+	if org.SeatLimit == nil {
+		return nil
+	}
+	learners, err := countRole(tx, org.ID, models.OrgRoleLearner)
+	if err != nil {
+		return err
+	}
+	if learners >= int64(*org.SeatLimit) {
+		return ErrOrganizationFull
+	}
+	return nil
+}
+
+// ensureAnotherOwner fails with ErrLastOrganizationOwner unless the organization has
+// more than one owner.
+func ensureAnotherOwner(tx *gorm.DB, orgID uuid.UUID) error {
+	owners, err := countRole(tx, orgID, models.OrgRoleOwner)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastOrganizationOwner
+	}
+	return nil
+}
+
+func countRole(tx *gorm.DB, orgID uuid.UUID, role string) (int64, error) {
+	var count int64
+	err := tx.Model(&models.OrganizationMember{}).
+		Where("organization_id = ? AND role = ?", orgID, role).
+		Count(&count).Error
+	return count, err
+}
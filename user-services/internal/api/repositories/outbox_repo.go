@@ -7,11 +7,11 @@ import (
 	"gorm.io/gorm"
 )
 
+// OutboxRepository adds events to the outbox table, which the shared outbox relay
+// publishes from (see shared/outbox/gormstore). Repositories writing events in their own
+// transactions create models.Outbox rows directly.
 type OutboxRepository interface {
 	Create(ctx context.Context, event *models.Outbox) error
-	GetUnpublished(ctx context.Context, limit int) ([]models.Outbox, error)
-	MarkAsPublished(ctx context.Context, id int64) error
-	DeletePublished(ctx context.Context, olderThanID int64) error
 }
 
 type outboxRepository struct {
@@ -25,26 +25,3 @@ func NewOutboxRepository(db *gorm.DB) OutboxRepository {
 func (r *outboxRepository) Create(ctx context.Context, event *models.Outbox) error {
 	return r.db.WithContext(ctx).Create(event).Error
 }
-
-func (r *outboxRepository) GetUnpublished(ctx context.Context, limit int) ([]models.Outbox, error) {
-	var events []models.Outbox
-	err := r.db.WithContext(ctx).
-		Where("published_at IS NULL").
-		Order("created_at ASC").
-		Limit(limit).
-		Find(&events).Error
-	return events, err
-}
-
-func (r *outboxRepository) MarkAsPublished(ctx context.Context, id int64) error {
-	return r.db.WithContext(ctx).
-		Model(&models.Outbox{}).
-		Where("id = ?", id).
-		Update("published_at", "NOW()").Error
-}
-
-func (r *outboxRepository) DeletePublished(ctx context.Context, olderThanID int64) error {
-	return r.db.WithContext(ctx).
-		Where("id <= ? AND published_at IS NOT NULL", olderThanID).
-		Delete(&models.Outbox{}).Error
-}
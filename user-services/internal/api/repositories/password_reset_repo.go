@@ -12,7 +12,8 @@ import (
 type PasswordResetRepository interface {
 	Create(ctx context.Context, reset *models.PasswordReset) error
 	GetByTokenHash(ctx context.Context, tokenHash string) (*models.PasswordReset, error)
-	Consume(ctx context.Context, id uuid.UUID) error
+	Consume(ctx context.Context, id uuid.UUID) (bool, error)
+	RecordFailedAttempt(ctx context.Context, id uuid.UUID, maxAttempts int) (bool, error)
 	DeleteExpired(ctx context.Context) error
 	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
 }
@@ -43,11 +44,33 @@ func (r *passwordResetRepository) GetByTokenHash(ctx context.Context, tokenHash
 	return &reset, nil
 }
 
-func (r *passwordResetRepository) Consume(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).
+// Consume marks a reset token as used. It reports false when the token was already used
+// up or has expired, so concurrent confirmations of the same link cannot both succeed.
+func (r *passwordResetRepository) Consume(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).
 		Model(&models.PasswordReset{}).
-		Where("id = ?", id).
-		Update("consumed_at", time.Now()).Error
+		Where("id = ? AND consumed_at IS NULL AND expires_at > ?", id, time.Now()).
+		Update("consumed_at", gorm.Expr("now()"))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// RecordFailedAttempt counts a confirmation of the token from another browser or network
+// and uses the token up once maxAttempts is reached, which it reports.
+func (r *passwordResetRepository) RecordFailedAttempt(ctx context.Context, id uuid.UUID, maxAttempts int) (bool, error) {
+	var reset models.PasswordReset
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE password_resets
+		SET failed_attempts = failed_attempts + 1,
+		    consumed_at = CASE WHEN failed_attempts + 1 >= ? THEN now() ELSE consumed_at END
+		WHERE id = ? AND consumed_at IS NULL
+		RETURNING *`, maxAttempts, id).Scan(&reset).Error
+	if err != nil {
+		return false, err
+	}
+	return reset.ConsumedAt.Valid, nil
 }
 
 func (r *passwordResetRepository) DeleteExpired(ctx context.Context) error {
@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"user-services/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PreferencesApplyFunc changes a user's preferences and profile in place and returns the
+// event announcing the change, or nil when nothing changed.
+type PreferencesApplyFunc func(prefs *models.UserPreferences, profile *models.UserProfile) (*models.Outbox, error)
+
+// PreferencesRepository stores user preferences.
+type PreferencesRepository interface {
+	// GetByUserID returns the user's preferences, or the defaults when they never
+	// changed any.
+	GetByUserID(ctx context.Context, userID uuid.UUID) (*models.UserPreferences, error)
+	// Update locks the user's profile and preferences, lets apply change them, and saves
+	// both with the version increased and the returned event in the outbox, all in one
+	// transaction. Nothing is written when apply returns no event.
+	Update(ctx context.Context, userID uuid.UUID, apply PreferencesApplyFunc) (*models.UserPreferences, *models.UserProfile, error)
+}
+
+type preferencesRepository struct {
+	db *gorm.DB
+}
+
+func NewPreferencesRepository(db *gorm.DB) PreferencesRepository {
+	return &preferencesRepository{db: db}
+}
+
+func (r *preferencesRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*models.UserPreferences, error) {
+	var prefs models.UserPreferences
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&prefs).Error
+	if stderrors.Is(err, gorm.ErrRecordNotFound) {
+		prefs = models.DefaultUserPreferences(userID)
+		return &prefs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &prefs, nil
+}
+
+func (r *preferencesRepository) Update(ctx context.Context, userID uuid.UUID, apply PreferencesApplyFunc) (*models.UserPreferences, *models.UserProfile, error) {
+	var prefs models.UserPreferences
+	var profile models.UserProfile
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The profile row always exists, so locking it serializes concurrent updates even
+		// before the user's first preferences row is written
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).
+			First(&profile).Error; err != nil {
+			return err
+		}
+
+		err := tx.Where("user_id = ?", userID).First(&prefs).Error
+		switch {
+		case stderrors.Is(err, gorm.ErrRecordNotFound):
+			prefs = models.DefaultUserPreferences(userID)
+		case err != nil:
+			return err
+		}
+
+		prefs.Version++
+		event, err := apply(&prefs, &profile)
+		if err != nil || event == nil {
+			prefs.Version--
+			return err
+		}
+
+		now := time.Now()
+		prefs.UpdatedAt = now
+		if err := tx.Save(&prefs).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.UserProfile{}).
+			Where("user_id = ?", userID).
+			Updates(map[string]any{
+				"locale":     profile.Locale,
+				"time_zone":  profile.TimeZone,
+				"updated_at": now,
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Create(event).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &prefs, &profile, nil
+}
@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"user-services/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PushTokenRepository stores the device tokens users register for push notifications.
+type PushTokenRepository interface {
+	// Register saves token for its user, or moves an already registered token with the
+	// same provider and value to the user and refreshes its metadata. The user's least
+	// recently registered tokens beyond maxPerUser are deleted. It reports whether the
+	// token was new.
+	Register(ctx context.Context, token *models.PushToken, maxPerUser int) (bool, error)
+	// ListByUser returns the user's tokens, most recently registered first.
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]models.PushToken, error)
+	// Delete removes one of the user's tokens. It reports false when the user has no such
+	// token.
+	Delete(ctx context.Context, userID, tokenID uuid.UUID) (bool, error)
+	// DeleteByValue removes the user's token with the given provider and value.
+	DeleteByValue(ctx context.Context, userID uuid.UUID, provider, token string) (bool, error)
+	// DeleteInvalid removes the tokens a push provider rejected, whoever they belong to,
+	// and returns them.
+	DeleteInvalid(ctx context.Context, provider string, tokens []string) ([]models.PushToken, error)
+}
+
+type pushTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewPushTokenRepository(db *gorm.DB) PushTokenRepository {
+	return &pushTokenRepository{db: db}
+}
+
+func (r *pushTokenRepository) Register(ctx context.Context, token *models.PushToken, maxPerUser int) (bool, error) {
+	created := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the user so concurrent registrations cannot both escape the pruning
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			Where("id = ?", token.UserID).
+			First(&user).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		var existing models.PushToken
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("provider = ? AND token = ?", token.Provider, token.Token).
+			First(&existing).Error
+		switch {
+		case stderrors.Is(err, gorm.ErrRecordNotFound):
+			token.CreatedAt, token.UpdatedAt = now, now
+			if err := tx.Create(token).Error; err != nil {
+				return err
+			}
+			created = true
+		case err != nil:
+			return err
+		default:
+			token.ID, token.CreatedAt, token.UpdatedAt = existing.ID, existing.CreatedAt, now
+			if err := tx.Model(&existing).Updates(map[string]any{
+				"user_id":      token.UserID,
+				"platform":     token.Platform,
+				"app_id":       token.AppID,
+				"app_version":  token.AppVersion,
+				"os_version":   token.OSVersion,
+				"device_model": token.DeviceModel,
+				"updated_at":   now,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Exec(`DELETE FROM push_tokens WHERE user_id = ? AND id NOT IN
+			(SELECT id FROM push_tokens WHERE user_id = ? ORDER BY updated_at DESC, id LIMIT ?)`,
+			token.UserID, token.UserID, maxPerUser).Error
+	})
+	return created, err
+}
+
+func (r *pushTokenRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.PushToken, error) {
+	var tokens []models.PushToken
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("updated_at DESC").
+		Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (r *pushTokenRepository) Delete(ctx context.Context, userID, tokenID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", tokenID, userID).
+		Delete(&models.PushToken{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *pushTokenRepository) DeleteByValue(ctx context.Context, userID uuid.UUID, provider, token string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("user_id = ? AND provider = ? AND token = ?", userID, provider, token).
+		Delete(&models.PushToken{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *pushTokenRepository) DeleteInvalid(ctx context.Context, provider string, tokens []string) ([]models.PushToken, error) {
+	var deleted []models.PushToken
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.Returning{}).
+		Where("provider = ? AND token IN ?", provider, tokens).
+		Delete(&deleted).Error; err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
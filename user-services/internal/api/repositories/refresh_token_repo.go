@@ -11,7 +11,7 @@ import (
 type RefreshTokenRepository interface {
 	Create(ctx context.Context, token *models.RefreshToken) error
 	GetByTokenHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
-	Consume(ctx context.Context, id uuid.UUID) error
+	Consume(ctx context.Context, id uuid.UUID) (bool, error)
 	Revoke(ctx context.Context, id uuid.UUID) error
 	RevokeBySessionID(ctx context.Context, sessionID uuid.UUID) error
 	DeleteExpired(ctx context.Context) error
@@ -37,8 +37,14 @@ func (r *refreshTokenRepository) GetByTokenHash(ctx context.Context, tokenHash s
 	return &t, nil
 }
 
-func (r *refreshTokenRepository) Consume(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Model(&models.RefreshToken{}).Where("id = ?", id).Update("consumed_at", gorm.Expr("now()")).Error
+// Consume marks a token as used. It reports false when the token was already consumed,
+// so concurrent rotations of the same token cannot both succeed.
+func (r *refreshTokenRepository) Consume(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.RefreshToken{}).Where("id = ? AND consumed_at IS NULL", id).Update("consumed_at", gorm.Expr("now()"))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
 }
 
 func (r *refreshTokenRepository) Revoke(ctx context.Context, id uuid.UUID) error {
@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/retention"
+)
+
+// RetentionRules are how long the service keeps its rows, enforced by the
+// retention.purge job. RETENTION_OVERRIDES changes their ages or turns them off.
+func RetentionRules() []retention.Rule {
+	return []retention.Rule{
+		{Name: "audit_logs", Table: "audit_logs", Column: "created_at", MaxAge: 2 * 365 * 24 * time.Hour},
+		// Sessions are kept, like on erasure, because activity sessions reference them;
+		// 30 days after they expired they lose the device details
+		{Name: "sessions", Table: "sessions", Column: "expires_at", MaxAge: 30 * 24 * time.Hour,
+			Anonymize: []string{"ip_addr"},
+			Blank: []string{"user_agent", "device_type", "browser", "os",
+				"geo_city", "geo_region", "geo_country", "geo_country_code"}},
+		{Name: "activity_sessions", Table: "user_activity_sessions", Column: "updated_at", MaxAge: 30 * 24 * time.Hour,
+			Anonymize: []string{"ip_addr"}, Blank: []string{"user_agent"}},
+	}
+}
@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+
+	"user-services/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserImportRepository creates the accounts of a bulk user import.
+type UserImportRepository interface {
+	// ExistingEmails returns which of the lower-cased emails already belong to an
+	// account, compared ignoring case.
+	ExistingEmails(ctx context.Context, emails []string) (map[string]bool, error)
+	// CreateInvited saves an invited user with their profile, invitation token and
+	// invitation event in one transaction. A user assigned to an organization becomes
+	// one of its learners, failing with ErrOrganizationFull when it has no seat left.
+	// created is false, and nothing is written, when the email was taken in the meantime.
+	CreateInvited(ctx context.Context, user *models.User, profile *models.UserProfile, invite *models.PasswordReset, event *models.Outbox) (bool, error)
+}
+
+type userImportRepository struct {
+	db *gorm.DB
+}
+
+func NewUserImportRepository(db *gorm.DB) UserImportRepository {
+	return &userImportRepository{db: db}
+}
+
+func (r *userImportRepository) ExistingEmails(ctx context.Context, emails []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	if len(emails) == 0 {
+		return existing, nil
+	}
+
+	var found []string
+	if err := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("lower(email) IN ?", emails).
+		Pluck("email", &found).Error; err != nil {
+		return nil, err
+	}
+	for _, email := range found {
+		existing[strings.ToLower(email)] = true
+	}
+	return existing, nil
+}
+
+func (r *userImportRepository) CreateInvited(ctx context.Context, user *models.User, profile *models.UserProfile, invite *models.PasswordReset, event *models.Outbox) (bool, error) {
+	created := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Create(user)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		if err := tx.Create(profile).Error; err != nil {
+			return err
+		}
+		if user.OrganizationID != nil {
+			if err := addMember(tx, &models.OrganizationMember{
+				OrganizationID: *user.OrganizationID,
+				UserID:         user.ID,
+				Role:           models.OrgRoleLearner,
+			}); err != nil {
+				return err
+			}
+		}
+		if invite != nil {
+			if err := tx.Create(invite).Error; err != nil {
+				return err
+			}
+		}
+		if event != nil {
+			if err := tx.Create(event).Error; err != nil {
+				return err
+			}
+		}
+		created = true
+		return nil
+	})
+	return created && err == nil, err
+}
@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"user-services/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// UserTotals counts the accounts that are neither deleted nor still waiting for their
+// invitation to be accepted.
+type UserTotals struct {
+	TotalUsers      int64
+	VerifiedUsers   int64
+	MFAEnabledUsers int64
+	LockedUsers     int64
+}
+
+// DailyCount is a count for one UTC day.
+type DailyCount struct {
+	Day   time.Time
+	Count int64
+}
+
+// UserMetricsRepository computes the headline user metrics of the admin dashboard with
+// aggregate queries.
+type UserMetricsRepository interface {
+	Totals(ctx context.Context, now time.Time) (UserTotals, error)
+	// DailySignups counts the accounts created on each UTC day from from up to to,
+	// exclusive, leaving out days without any. Imported accounts count once their
+	// invitation is accepted.
+	DailySignups(ctx context.Context, from, to time.Time) ([]DailyCount, error)
+	// DailyActiveUsers counts the users who started an activity session on each UTC day
+	// from from up to to, exclusive, leaving out days without any.
+	DailyActiveUsers(ctx context.Context, from, to time.Time) ([]DailyCount, error)
+	// ActiveUsers counts the users who started an activity session from from up to to,
+	// exclusive.
+	ActiveUsers(ctx context.Context, from, to time.Time) (int64, error)
+}
+
+type userMetricsRepository struct {
+	db *gorm.DB
+}
+
+func NewUserMetricsRepository(db *gorm.DB) UserMetricsRepository {
+	return &userMetricsRepository{db: db}
+}
+
+func (r *userMetricsRepository) Totals(ctx context.Context, now time.Time) (UserTotals, error) {
+	var totals UserTotals
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT
+			COUNT(*) AS total_users,
+			COUNT(*) FILTER (WHERE u.email_verified) AS verified_users,
+			COUNT(*) FILTER (WHERE EXISTS (
+				SELECT 1 FROM mfa_methods m WHERE m.user_id = u.id AND m.verified_at IS NOT NULL
+			)) AS mfa_enabled_users,
+			COUNT(*) FILTER (WHERE u.status = ? OR COALESCE(u.lockout_until > ?, false)) AS locked_users
+		FROM users u
+		WHERE u.status NOT IN (?, ?)`,
+		models.StatusLocked, now, models.StatusDeleted, models.StatusInvited).
+		Scan(&totals).Error
+	return totals, err
+}
+
+func (r *userMetricsRepository) DailySignups(ctx context.Context, from, to time.Time) ([]DailyCount, error) {
+	var counts []DailyCount
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT (created_at AT TIME ZONE 'UTC')::date AS day, COUNT(*) AS count
+		FROM users
+		WHERE created_at >= ? AND created_at < ? AND status <> ?
+		GROUP BY 1
+		ORDER BY 1`,
+		from, to, models.StatusInvited).
+		Scan(&counts).Error
+	return counts, err
+}
+
+func (r *userMetricsRepository) DailyActiveUsers(ctx context.Context, from, to time.Time) ([]DailyCount, error) {
+	var counts []DailyCount
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT (started_at AT TIME ZONE 'UTC')::date AS day, COUNT(DISTINCT user_id) AS count
+		FROM user_activity_sessions
+		WHERE started_at >= ? AND started_at < ?
+		GROUP BY 1
+		ORDER BY 1`,
+		from, to).
+		Scan(&counts).Error
+	return counts, err
+}
+
+func (r *userMetricsRepository) ActiveUsers(ctx context.Context, from, to time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT COUNT(DISTINCT user_id)
+		FROM user_activity_sessions
+		WHERE started_at >= ? AND started_at < ?`,
+		from, to).
+		Scan(&count).Error
+	return count, err
+}
@@ -11,6 +11,8 @@ import (
 type UserProfileRepository interface {
 	Create(ctx context.Context, profile *models.UserProfile) error
 	GetByUserID(ctx context.Context, userID uuid.UUID) (*models.UserProfile, error)
+	// Update saves the profile but not its avatar, which is changed through the
+	// AvatarRepository so replaced avatars are cleaned up.
 	Update(ctx context.Context, profile *models.UserProfile) error
 	Delete(ctx context.Context, userID uuid.UUID) error
 }
@@ -36,7 +38,7 @@ func (r *userProfileRepository) GetByUserID(ctx context.Context, userID uuid.UUI
 }
 
 func (r *userProfileRepository) Update(ctx context.Context, profile *models.UserProfile) error {
-	return r.db.WithContext(ctx).Save(profile).Error
+	return r.db.WithContext(ctx).Omit("avatar_url", "avatar_key").Save(profile).Error
 }
 
 func (r *userProfileRepository) Delete(ctx context.Context, userID uuid.UUID) error {
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"time"
@@ -9,6 +10,9 @@ import (
 	"user-services/internal/models"
 	"user-services/internal/utils"
 
+	"github.com/ductan2/microservice-app/shared/dbreplica"
+	"github.com/ductan2/microservice-app/shared/softdelete"
+	"github.com/ductan2/microservice-app/shared/softdelete/gormsoft"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -18,14 +22,69 @@ type UserRepository interface {
 	CheckEmailExists(ctx context.Context, email string) (bool, error)
 	GetUserByEmail(ctx context.Context, email string) (models.User, error)
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	// GetByUsername and GetByPhoneNumber find a user by an alternative login: a lowercase
+	// username or an E.164 phone number.
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+	GetByPhoneNumber(ctx context.Context, phone string) (*models.User, error)
+	// SetUsername sets or, with nil, clears the user's username.
+	SetUsername(ctx context.Context, userID uuid.UUID, username *string) error
+	// SetPhoneNumber sets the user's verified phone number, or clears it with nil.
+	SetPhoneNumber(ctx context.Context, userID uuid.UUID, phone *string, verifiedAt time.Time) error
 	GetByID(ctx context.Context, userID uuid.UUID) (*models.User, error)
+	GetByIDs(ctx context.Context, userIDs []uuid.UUID) ([]models.User, error)
 	GetUserByID(ctx context.Context, userID string) (models.User, error)
 	UpdateUser(ctx context.Context, user *models.User) error
 	UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error
+	// ActivateInvited activates an invited account and marks its email verified. It
+	// reports false when the account was not invited.
+	ActivateInvited(ctx context.Context, userID uuid.UUID) (bool, error)
 	UpdateLastLogin(ctx context.Context, userID uuid.UUID, at time.Time, ip string) error
 	GetByVerificationToken(ctx context.Context, tokenHash string) (*models.User, error)
-	DeleteUser(ctx context.Context, userID string) error
-	ListUsers(ctx context.Context, page, pageSize int, status, search string) ([]models.User, int64, error)
+	DeleteUser(ctx context.Context, userID string, mark softdelete.Mark) error
+	ListUsers(ctx context.Context, filter UserListFilter) ([]models.User, int64, error)
+}
+
+// Columns a user listing can be sorted by. Ties are broken by ID.
+const (
+	UserSortCreatedAt   = "created_at"
+	UserSortLastLoginAt = "last_login_at"
+	UserSortEmail       = "email"
+	UserSortRole        = "role"
+	UserSortStatus      = "status"
+)
+
+// UserListFilter narrows and orders a user listing. Zero values are ignored.
+type UserListFilter struct {
+	Status        string
+	Role          string
+	Search        string
+	EmailVerified *bool
+	// MFAEnabled matches users with at least one verified MFA method
+	MFAEnabled *bool
+	// Locked matches users locked by an admin or temporarily locked out after failed
+	// logins
+	Locked        *bool
+	CreatedFrom   time.Time
+	CreatedTo     time.Time
+	LastLoginFrom time.Time
+	LastLoginTo   time.Time
+	// Deleted selects the users by deletion; the zero value excludes deleted users
+	Deleted softdelete.Scope
+	// Sort is one of the UserSort columns; users who never logged in sort as the
+	// oldest last_login_at
+	Sort string
+	Desc bool
+	// After continues the listing after the given user; Offset is ignored when set
+	After  *UserCursor
+	Limit  int
+	Offset int
+}
+
+// UserCursor is the position of the last user on a page: the value of the sort column
+// (a time.Time or string, nil for a user who never logged in) and the user's ID.
+type UserCursor struct {
+	Value any
+	ID    uuid.UUID
 }
 
 type userRepository struct {
@@ -87,6 +146,46 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.
 	return &user, nil
 }
 
+// GetByUsername retrieves a user by username
+func (r *userRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	var user models.User
+	err := r.DB.WithContext(ctx).Preload("Profile").Where("username = ?", username).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetByPhoneNumber retrieves a user by verified phone number
+func (r *userRepository) GetByPhoneNumber(ctx context.Context, phone string) (*models.User, error) {
+	var user models.User
+	err := r.DB.WithContext(ctx).Preload("Profile").Where("phone_number = ?", phone).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// SetUsername updates the user's username
+func (r *userRepository) SetUsername(ctx context.Context, userID uuid.UUID, username *string) error {
+	return r.DB.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ?", userID).
+		Update("username", username).Error
+}
+
+// SetPhoneNumber updates the user's phone number and when it was verified
+func (r *userRepository) SetPhoneNumber(ctx context.Context, userID uuid.UUID, phone *string, verifiedAt time.Time) error {
+	verified := sql.NullTime{Time: verifiedAt, Valid: phone != nil}
+	return r.DB.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]any{
+			"phone_number":      phone,
+			"phone_verified_at": verified,
+		}).Error
+}
+
 // GetByID retrieves a user by UUID
 func (r *userRepository) GetByID(ctx context.Context, userID uuid.UUID) (*models.User, error) {
 	var user models.User
@@ -97,6 +196,17 @@ func (r *userRepository) GetByID(ctx context.Context, userID uuid.UUID) (*models
 	return &user, nil
 }
 
+// GetByIDs retrieves the users among userIDs that exist, in no particular order. Deleted
+// users are included: callers render them by their status.
+func (r *userRepository) GetByIDs(ctx context.Context, userIDs []uuid.UUID) ([]models.User, error) {
+	var users []models.User
+	if len(userIDs) == 0 {
+		return users, nil
+	}
+	err := r.DB.WithContext(ctx).Preload("Profile").Where("id IN ?", userIDs).Find(&users).Error
+	return users, err
+}
+
 // GetUserByID retrieves a user by their ID (string version)
 func (r *userRepository) GetUserByID(ctx context.Context, userID string) (models.User, error) {
 	var user models.User
@@ -123,6 +233,18 @@ func (r *userRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, p
 		Update("password_hash", passwordHash).Error
 }
 
+// ActivateInvited activates an invited account once the user has set a password
+func (r *userRepository) ActivateInvited(ctx context.Context, userID uuid.UUID) (bool, error) {
+	result := r.DB.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ? AND status = ?", userID, models.StatusInvited).
+		Updates(map[string]any{
+			"status":         models.StatusActive,
+			"email_verified": true,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
 // UpdateLastLogin updates last login timestamp and ip
 func (r *userRepository) UpdateLastLogin(ctx context.Context, userID uuid.UUID, at time.Time, ip string) error {
 	updates := map[string]any{
@@ -136,7 +258,6 @@ func (r *userRepository) UpdateLastLogin(ctx context.Context, userID uuid.UUID,
 	} else {
 		updates["last_login_ip"] = nil
 	}
-	fmt.Println("updates", updates)
 	return r.DB.WithContext(ctx).
 		Model(&models.User{}).
 		Where("id = ?", userID).
@@ -156,41 +277,113 @@ func (r *userRepository) GetByVerificationToken(ctx context.Context, tokenHash s
 	return &user, nil
 }
 
-// DeleteUser soft deletes a user
-func (r *userRepository) DeleteUser(ctx context.Context, userID string) error {
-	return r.DB.WithContext(ctx).Where("id = ?", userID).Delete(&models.User{}).Error
+// DeleteUser soft deletes a user that is not deleted yet
+func (r *userRepository) DeleteUser(ctx context.Context, userID string, mark softdelete.Mark) error {
+	return r.DB.WithContext(ctx).Model(&models.User{}).Scopes(gormsoft.Active).
+		Where("id = ?", userID).
+		Updates(map[string]any{
+			"status":            models.StatusDeleted,
+			softdelete.Column:   mark.At,
+			softdelete.ByColumn: mark.By,
+		}).Error
 }
 
-// ListUsers retrieves a paginated list of users with optional filtering
-func (r *userRepository) ListUsers(ctx context.Context, page, pageSize int, status, search string) ([]models.User, int64, error) {
-	var users []models.User
-	var total int64
+// ListUsers returns a page of the users matching filter with the total match count,
+// read from a replica when the service has some.
+func (r *userRepository) ListUsers(ctx context.Context, filter UserListFilter) ([]models.User, int64, error) {
+	now := time.Now()
+	db := dbreplica.Read(r.DB.WithContext(ctx))
+	filtered := func() *gorm.DB {
+		return applyUserListFilter(db.Model(&models.User{}), filter, now)
+	}
 
-	query := r.DB.WithContext(ctx).Model(&models.User{})
+	var total int64
+	if err := filtered().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
 
-	// Apply filters
-	if status != "" {
-		query = query.Where("status = ?", status)
+	column := filter.Sort
+	if column == "" {
+		column = UserSortCreatedAt
 	}
-	if search != "" {
-		query = query.Where("email ILIKE ?", "%"+search+"%")
+	op, direction, nulls := ">", "ASC", "NULLS FIRST"
+	if filter.Desc {
+		op, direction, nulls = "<", "DESC", "NULLS LAST"
 	}
 
-	// Count total records
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
+	query := filtered()
+	if after := filter.After; after != nil {
+		// NULL last_login_at sorts first ascending and last descending, matching the
+		// (last_login_at NULLS FIRST, id) index in either direction
+		switch {
+		case column != UserSortLastLoginAt:
+			query = query.Where(fmt.Sprintf("(users.%s, users.id) %s (?, ?)", column, op), after.Value, after.ID)
+		case after.Value == nil && filter.Desc:
+			query = query.Where("users.last_login_at IS NULL AND users.id < ?", after.ID)
+		case after.Value == nil:
+			query = query.Where("((users.last_login_at IS NULL AND users.id > ?) OR users.last_login_at IS NOT NULL)", after.ID)
+		case filter.Desc:
+			query = query.Where("((users.last_login_at, users.id) < (?, ?) OR users.last_login_at IS NULL)", after.Value, after.ID)
+		default:
+			query = query.Where("(users.last_login_at, users.id) > (?, ?)", after.Value, after.ID)
+		}
+	} else if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
 	}
 
-	// Apply pagination
-	offset := (page - 1) * pageSize
+	var users []models.User
 	if err := query.
 		Preload("Profile").
-		Order("created_at DESC").
-		Offset(offset).
-		Limit(pageSize).
+		Order(fmt.Sprintf("users.%s %s %s, users.id %s", column, direction, nulls, direction)).
+		Limit(filter.Limit).
 		Find(&users).Error; err != nil {
 		return nil, 0, err
 	}
 
 	return users, total, nil
 }
+
+func applyUserListFilter(query *gorm.DB, filter UserListFilter, now time.Time) *gorm.DB {
+	query = query.Scopes(gormsoft.Scope(filter.Deleted))
+	if filter.Status != "" {
+		query = query.Where("users.status = ?", filter.Status)
+	}
+	if filter.Role != "" {
+		query = query.Where("users.role = ?", filter.Role)
+	}
+	if filter.Search != "" {
+		query = query.Where("users.email ILIKE ?", "%"+filter.Search+"%")
+	}
+	if filter.EmailVerified != nil {
+		query = query.Where("users.email_verified = ?", *filter.EmailVerified)
+	}
+	if filter.MFAEnabled != nil {
+		const hasMFA = "EXISTS (SELECT 1 FROM mfa_methods m WHERE m.user_id = users.id AND m.verified_at IS NOT NULL)"
+		if *filter.MFAEnabled {
+			query = query.Where(hasMFA)
+		} else {
+			query = query.Where("NOT " + hasMFA)
+		}
+	}
+	if filter.Locked != nil {
+		const locked = "(users.status = ? OR COALESCE(users.lockout_until > ?, false))"
+		if *filter.Locked {
+			query = query.Where(locked, models.StatusLocked, now)
+		} else {
+			query = query.Where("NOT "+locked, models.StatusLocked, now)
+		}
+	}
+	if !filter.CreatedFrom.IsZero() {
+		query = query.Where("users.created_at >= ?", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		query = query.Where("users.created_at < ?", filter.CreatedTo)
+	}
+	if !filter.LastLoginFrom.IsZero() {
+		query = query.Where("users.last_login_at >= ?", filter.LastLoginFrom)
+	}
+	if !filter.LastLoginTo.IsZero() {
+		query = query.Where("users.last_login_at < ?", filter.LastLoginTo)
+	}
+	return query
+}
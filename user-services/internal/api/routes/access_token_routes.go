@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"user-services/internal/api/controllers"
+	"user-services/internal/api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterAccessTokenRoutes exposes personal access token management to the BFF, and the
+// introspection endpoint it calls to authenticate requests bearing one.
+func RegisterAccessTokenRoutes(router *gin.RouterGroup, controller *controllers.AccessTokenController) {
+	tokens := router.Group("/users/me/access-tokens")
+	tokens.Use(middleware.InternalAuthRequired())
+	{
+		tokens.POST("", controller.CreateToken)       // POST /users/me/access-tokens
+		tokens.GET("", controller.ListTokens)         // GET /users/me/access-tokens
+		tokens.DELETE("/:id", controller.RevokeToken) // DELETE /users/me/access-tokens/:id
+	}
+
+	internal := router.Group("/internal/access-tokens")
+	internal.Use(middleware.ServiceAuthRequired())
+	{
+		internal.POST("/introspect", controller.Introspect) // POST /internal/access-tokens/introspect
+	}
+}
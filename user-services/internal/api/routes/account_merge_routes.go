@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"user-services/internal/api/controllers"
+	"user-services/internal/api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterAccountMergeRoutes exposes merging a duplicate account into the caller's.
+func RegisterAccountMergeRoutes(router *gin.RouterGroup, controller *controllers.AccountMergeController) {
+	merges := router.Group("/users/me/merges")
+	merges.Use(middleware.InternalAuthRequired())
+	{
+		merges.POST("", controller.RequestMerge)             // POST /users/me/merges
+		merges.GET("/:id", controller.GetMerge)              // GET /users/me/merges/:id
+		merges.POST("/:id/confirm", controller.ConfirmMerge) // POST /users/me/merges/:id/confirm
+		merges.DELETE("/:id", controller.CancelMerge)        // DELETE /users/me/merges/:id
+	}
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"user-services/internal/api/controllers"
+	"user-services/internal/api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterActivityRollupRoutes lets other services, such as streaks and reports, read the
+// study time rolled up per user without scanning activity sessions.
+func RegisterActivityRollupRoutes(router *gin.RouterGroup, controller *controllers.ActivityRollupController) {
+	internal := router.Group("/internal/activity")
+	internal.Use(middleware.ServiceAuthRequired())
+	{
+		internal.GET("/users/:id/rollups", controller.GetUserRollups) // GET /internal/activity/users/:id/rollups
+	}
+}
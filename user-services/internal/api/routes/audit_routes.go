@@ -2,14 +2,22 @@ package routes
 
 import (
 	"user-services/internal/api/controllers"
+	"user-services/internal/api/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
+// RegisterAuditRoutes exposes the audit trail to the BFF, which restricts the user and
+// listing endpoints to admins, and the caller's sign-in history drawn from it.
 func RegisterAuditRoutes(router *gin.RouterGroup, controller *controllers.AuditController) {
 	audit := router.Group("/audit")
+	audit.Use(middleware.InternalAuthRequired())
 	{
+		audit.GET("/me", controller.GetMyAuditLogs)          // GET /audit/me
 		audit.GET("/users/:id", controller.GetUserAuditLogs) // GET /audit/users/:id
+		audit.GET("/logs", controller.ListAuditLogs)         // GET /audit/logs
 		audit.GET("/actions", controller.GetActionAuditLogs) // GET /audit/actions
 	}
+
+	router.GET("/users/me/login-history", middleware.InternalAuthRequired(), controller.GetMyLoginHistory) // GET /users/me/login-history
 }
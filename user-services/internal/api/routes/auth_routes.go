@@ -14,6 +14,7 @@ func RegisterAuthRoutes(router *gin.RouterGroup, controller *controllers.TokenCo
 	{
 		// Refresh token endpoint with rate limiting
 		refreshConfig := middleware.RateLimitConfig{
+			Name:     "auth.refresh",
 			Requests: cfg.RateLimit.AuthRequestsPerMinute * 2, // Allow more refresh attempts
 			Window:   cfg.RateLimit.AuthWindow,
 		}
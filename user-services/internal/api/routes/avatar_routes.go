@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"user-services/internal/api/controllers"
+	"user-services/internal/api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterAvatarRoutes exposes avatar uploads for the caller to the BFF.
+func RegisterAvatarRoutes(router *gin.RouterGroup, controller *controllers.AvatarController) {
+	avatar := router.Group("/users/me/avatar")
+	avatar.Use(middleware.InternalAuthRequired())
+	{
+		avatar.POST("/uploads", controller.CreateUpload)                // POST /users/me/avatar/uploads
+		avatar.POST("/uploads/:id/complete", controller.CompleteUpload) // POST /users/me/avatar/uploads/:id/complete
+		avatar.DELETE("", controller.RemoveAvatar)                      // DELETE /users/me/avatar
+	}
+}
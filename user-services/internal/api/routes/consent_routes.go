@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"user-services/internal/api/controllers"
+	"user-services/internal/api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterConsentRoutes exposes the current terms of service and privacy policy to
+// everyone, the caller's consents, and publishing new versions, which the BFF restricts
+// to admins.
+func RegisterConsentRoutes(router *gin.RouterGroup, controller *controllers.ConsentController) {
+	router.GET("/policies", controller.ListPolicies) // GET /policies
+
+	policies := router.Group("/policies")
+	policies.Use(middleware.InternalAuthRequired())
+	{
+		policies.POST("", controller.PublishPolicy)                        // POST /policies
+		policies.GET("/:document/versions", controller.ListPolicyVersions) // GET /policies/:document/versions
+	}
+
+	consents := router.Group("/users/me/consents")
+	consents.Use(middleware.InternalAuthRequired())
+	{
+		consents.GET("", controller.GetConsents)    // GET /users/me/consents
+		consents.POST("", controller.RecordConsent) // POST /users/me/consents
+	}
+}
@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"user-services/internal/api/controllers"
+	"user-services/internal/api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterDataExportRoutes exposes self-service data exports to the BFF, and the
+// callback other services use to deliver their share of an export.
+func RegisterDataExportRoutes(router *gin.RouterGroup, controller *controllers.DataExportController) {
+	exports := router.Group("/data-exports")
+	exports.Use(middleware.InternalAuthRequired())
+	{
+		exports.POST("", controller.RequestExport)              // POST /data-exports
+		exports.GET("", controller.ListExports)                 // GET /data-exports
+		exports.GET("/:id", controller.GetExport)               // GET /data-exports/:id
+		exports.GET("/:id/download", controller.DownloadExport) // GET /data-exports/:id/download
+	}
+
+	internal := router.Group("/internal/data-exports")
+	internal.Use(middleware.ServiceAuthRequired())
+	{
+		internal.POST("/:id/parts", controller.ReceivePart) // POST /internal/data-exports/:id/parts
+	}
+}
@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"user-services/internal/api/controllers"
+	"user-services/internal/api/middleware"
+	"user-services/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterErasureRoutes exposes right-to-erasure requests to the BFF, which restricts the
+// user and listing endpoints to admins, the public endpoint a signed-out user recovers
+// their deleted account with, and the callback other services use to confirm they
+// scrubbed an erased user's data.
+func RegisterErasureRoutes(router *gin.RouterGroup, controller *controllers.ErasureController, rateLimiter middleware.RateLimiter, cfg *config.Config) {
+	// Recovering checks a password, so it is limited like a login
+	publicConfig := middleware.RateLimitConfig{
+		Name:     "auth.erasure_recover",
+		Requests: cfg.RateLimit.AuthRequestsPerMinute,
+		Window:   cfg.RateLimit.AuthWindow,
+	}
+
+	public := router.Group("/erasure")
+	public.Use(middleware.RateLimitMiddleware(rateLimiter, publicConfig))
+	{
+		public.POST("/recover", controller.RecoverAccount) // POST /erasure/recover
+	}
+
+	erasure := router.Group("/erasure")
+	erasure.Use(middleware.InternalAuthRequired())
+	{
+		erasure.POST("/me", controller.RequestMyErasure)               // POST /erasure/me
+		erasure.POST("/me/deactivate", controller.DeactivateMyAccount) // POST /erasure/me/deactivate
+		erasure.POST("/users/:id", controller.ScheduleUserErasure)     // POST /erasure/users/:id
+		erasure.GET("/requests", controller.ListErasures)              // GET /erasure/requests
+		erasure.GET("/requests/:id", controller.GetErasure)            // GET /erasure/requests/:id
+	}
+
+	internal := router.Group("/internal/erasures")
+	internal.Use(middleware.ServiceAuthRequired())
+	{
+		internal.POST("/:id/acks", controller.Acknowledge) // POST /internal/erasures/:id/acks
+	}
+}
@@ -0,0 +1,50 @@
+package routes
+
+import (
+	"user-services/internal/api/controllers"
+	"user-services/internal/api/middleware"
+	"user-services/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterIdentifierRoutes exposes the usernames and phone numbers users sign in with:
+// a public username availability check for sign-up forms, and the caller's own
+// identifiers. Phone availability needs a signed-in caller so it cannot be used to find
+// out who has an account.
+func RegisterIdentifierRoutes(router *gin.RouterGroup, controller *controllers.IdentifierController, rateLimiter middleware.RateLimiter, cfg *config.Config) {
+	availabilityConfig := middleware.RateLimitConfig{
+		Name:     "auth.identifier_check",
+		Requests: cfg.RateLimit.AuthRequestsPerMinute,
+		Window:   cfg.RateLimit.AuthWindow,
+	}
+	otpSendConfig := middleware.RateLimitConfig{
+		Name:            "auth.otp_send",
+		Requests:        cfg.RateLimit.OTPSendRequests,
+		Window:          cfg.RateLimit.OTPSendWindow,
+		AccountRequests: cfg.RateLimit.OTPSendRequests,
+		AccountWindow:   cfg.RateLimit.OTPSendWindow,
+	}
+
+	public := router.Group("/users/identifiers")
+	public.Use(middleware.RateLimitMiddleware(rateLimiter, availabilityConfig))
+	{
+		public.GET("/username/availability", controller.CheckUsername) // GET /users/identifiers/username/availability
+	}
+
+	identifiers := router.Group("/users/me/identifiers")
+	identifiers.Use(middleware.InternalAuthRequired())
+	{
+		identifiers.GET("", controller.GetIdentifiers)             // GET /users/me/identifiers
+		identifiers.PUT("/username", controller.SetUsername)       // PUT /users/me/identifiers/username
+		identifiers.DELETE("/username", controller.RemoveUsername) // DELETE /users/me/identifiers/username
+		identifiers.GET("/phone/availability",
+			middleware.RateLimitMiddleware(rateLimiter, availabilityConfig),
+			controller.CheckPhone) // GET /users/me/identifiers/phone/availability
+		identifiers.POST("/phone",
+			middleware.AuthRateLimitMiddleware(rateLimiter, otpSendConfig),
+			controller.StartPhoneVerification) // POST /users/me/identifiers/phone
+		identifiers.POST("/phone/verify", controller.VerifyPhone) // POST /users/me/identifiers/phone/verify
+		identifiers.DELETE("/phone", controller.RemovePhone)      // DELETE /users/me/identifiers/phone
+	}
+}
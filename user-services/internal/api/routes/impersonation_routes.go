@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"user-services/internal/api/controllers"
+	"user-services/internal/api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterImpersonationRoutes exposes support impersonation to the BFF, which restricts
+// it to admins; the service further requires one of IMPERSONATION_ALLOWED_ROLES.
+func RegisterImpersonationRoutes(router *gin.RouterGroup, controller *controllers.ImpersonationController) {
+	impersonations := router.Group("/impersonations")
+	impersonations.Use(middleware.InternalAuthRequired())
+	{
+		impersonations.POST("", controller.StartImpersonation)     // POST /impersonations
+		impersonations.DELETE("/:id", controller.EndImpersonation) // DELETE /impersonations/:id
+	}
+}
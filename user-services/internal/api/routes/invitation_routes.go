@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"user-services/internal/api/controllers"
+	"user-services/internal/api/middleware"
+	"user-services/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterInvitationRoutes exposes invitation management to the BFF, authorized here
+// against the caller's role and memberships, and the public preview and accept
+// endpoints the emailed link leads to.
+func RegisterInvitationRoutes(router *gin.RouterGroup, controller *controllers.InvitationController, rateLimiter middleware.RateLimiter, cfg *config.Config) {
+	// Accepting requires a valid signed token, like a password reset confirmation
+	publicConfig := middleware.RateLimitConfig{
+		Name:     "auth.invitation",
+		Requests: cfg.RateLimit.AuthRequestsPerMinute * 2,
+		Window:   cfg.RateLimit.AuthWindow,
+	}
+
+	public := router.Group("/invitations")
+	public.Use(middleware.RateLimitMiddleware(rateLimiter, publicConfig))
+	{
+		public.GET("/preview", controller.PreviewInvitation) // GET /invitations/preview?token=
+		public.POST("/accept", controller.AcceptInvitation)  // POST /invitations/accept
+	}
+
+	invitations := router.Group("/invitations")
+	invitations.Use(middleware.InternalAuthRequired())
+	{
+		invitations.POST("", controller.CreateInvitation)            // POST /invitations
+		invitations.GET("", controller.ListInvitations)              // GET /invitations
+		invitations.POST("/:id/resend", controller.ResendInvitation) // POST /invitations/:id/resend
+		invitations.DELETE("/:id", controller.RevokeInvitation)      // DELETE /invitations/:id
+	}
+}
@@ -4,18 +4,66 @@ import (
 	"user-services/internal/api/controllers"
 	"user-services/internal/api/middleware"
 	"user-services/internal/cache"
+	"user-services/internal/config"
 
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterMFARoutes(router *gin.RouterGroup, controller *controllers.MFAController, sessionCache *cache.SessionCache) {
+func RegisterMFARoutes(router *gin.RouterGroup, controller *controllers.MFAController, sessionCache *cache.SessionCache, rateLimiter middleware.RateLimiter, cfg *config.Config) {
+	// Codes are short, so verification is limited per IP and per user
+	mfaVerifyConfig := middleware.RateLimitConfig{
+		Name:            "auth.mfa_verify",
+		Requests:        cfg.RateLimit.MFAVerifyRequests,
+		Window:          cfg.RateLimit.MFAVerifyWindow,
+		AccountRequests: cfg.RateLimit.MFAVerifyRequests,
+		AccountWindow:   cfg.RateLimit.MFAVerifyWindow,
+	}
+	// Every SMS or call costs money, so code sends get their own budget
+	otpSendConfig := middleware.RateLimitConfig{
+		Name:            "auth.otp_send",
+		Requests:        cfg.RateLimit.OTPSendRequests,
+		Window:          cfg.RateLimit.OTPSendWindow,
+		AccountRequests: cfg.RateLimit.OTPSendRequests,
+		AccountWindow:   cfg.RateLimit.OTPSendWindow,
+	}
 
 	mfa := router.Group("/mfa")
 	mfa.Use(middleware.AuthRequired(sessionCache))
 	{
-		mfa.POST("/setup", controller.SetupMFA)        // POST /mfa/setup
-		mfa.POST("/verify", controller.VerifyMFASetup) // POST /mfa/verify
-		mfa.POST("/disable", controller.DisableMFA)    // POST /mfa/disable
-		mfa.GET("/methods", controller.GetMFAMethods)  // GET /mfa/methods
+		mfa.POST("/setup", controller.SetupMFA) // POST /mfa/setup
+		mfa.POST("/verify",
+			middleware.AuthRateLimitMiddleware(rateLimiter, mfaVerifyConfig),
+			controller.VerifyMFASetup) // POST /mfa/verify
+		mfa.POST("/disable", controller.DisableMFA)   // POST /mfa/disable
+		mfa.GET("/methods", controller.GetMFAMethods) // GET /mfa/methods
+	}
+
+	// Passkey management is reached through the BFF, which forwards the caller's identity
+	webauthn := router.Group("/mfa/webauthn")
+	webauthn.Use(middleware.InternalAuthRequired())
+	{
+		webauthn.POST("/register/begin", controller.BeginPasskeyRegistration)
+		webauthn.POST("/register/finish", controller.FinishPasskeyRegistration)
+		webauthn.GET("/credentials", controller.ListPasskeys)
+		webauthn.PATCH("/credentials/:id", controller.RenamePasskey)
+		webauthn.POST("/credentials/:id/revoke", controller.RevokePasskey)
 	}
+
+	// Phone numbers and the fallback order are managed through the BFF as well
+	phone := router.Group("/mfa/phone")
+	phone.Use(middleware.InternalAuthRequired())
+	{
+		phone.POST("",
+			middleware.AuthRateLimitMiddleware(rateLimiter, otpSendConfig),
+			controller.SetupPhoneMFA)
+		phone.POST("/:id/send",
+			middleware.AuthRateLimitMiddleware(rateLimiter, otpSendConfig),
+			controller.ResendPhoneCode)
+		phone.POST("/:id/verify",
+			middleware.AuthRateLimitMiddleware(rateLimiter, mfaVerifyConfig),
+			controller.ConfirmPhoneMFA)
+		phone.POST("/:id/revoke", controller.RevokePhoneMFA)
+	}
+
+	router.PUT("/mfa/methods/order", middleware.InternalAuthRequired(), controller.ReorderMFAMethods)
 }
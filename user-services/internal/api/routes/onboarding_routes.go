@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"user-services/internal/api/controllers"
+	"user-services/internal/api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterOnboardingRoutes exposes the caller's onboarding progress to the BFF, and the
+// callback other services use to report the steps that happen on their side.
+func RegisterOnboardingRoutes(router *gin.RouterGroup, controller *controllers.OnboardingController) {
+	onboarding := router.Group("/users/me/onboarding")
+	onboarding.Use(middleware.InternalAuthRequired())
+	{
+		onboarding.GET("", controller.GetOnboarding)                  // GET /users/me/onboarding
+		onboarding.POST("/steps/:step", controller.AdvanceOnboarding) // POST /users/me/onboarding/steps/:step
+	}
+
+	internal := router.Group("/internal/onboarding")
+	internal.Use(middleware.ServiceAuthRequired())
+	{
+		internal.POST("/users/:id/steps/:step", controller.ReportOnboardingStep) // POST /internal/onboarding/users/:id/steps/:step
+	}
+}
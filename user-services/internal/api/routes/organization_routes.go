@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"user-services/internal/api/controllers"
+	"user-services/internal/api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterOrganizationRoutes exposes organizations and their members to the BFF, which
+// restricts creating, listing and updating organizations to admins. Org-scoped
+// endpoints authorize the caller against their membership.
+func RegisterOrganizationRoutes(router *gin.RouterGroup, controller *controllers.OrganizationController) {
+	orgs := router.Group("/organizations")
+	orgs.Use(middleware.InternalAuthRequired())
+	{
+		orgs.POST("", controller.CreateOrganization)                     // POST /organizations
+		orgs.GET("", controller.ListOrganizations)                       // GET /organizations
+		orgs.GET("/:id", controller.GetOrganization)                     // GET /organizations/:id
+		orgs.PATCH("/:id", controller.UpdateOrganization)                // PATCH /organizations/:id
+		orgs.GET("/:id/members", controller.ListMembers)                 // GET /organizations/:id/members
+		orgs.POST("/:id/members", controller.AddMember)                  // POST /organizations/:id/members
+		orgs.PATCH("/:id/members/:user_id", controller.UpdateMemberRole) // PATCH /organizations/:id/members/:user_id
+		orgs.DELETE("/:id/members/:user_id", controller.RemoveMember)    // DELETE /organizations/:id/members/:user_id
+	}
+
+	me := router.Group("/users/me/organizations")
+	me.Use(middleware.InternalAuthRequired())
+	{
+		me.GET("", controller.ListMyOrganizations) // GET /users/me/organizations
+	}
+}
@@ -14,8 +14,11 @@ func RegisterPasswordRoutes(router *gin.RouterGroup, controller *controllers.Pas
 	{
 		// Password reset request with stricter rate limiting
 		passwordResetConfig := middleware.RateLimitConfig{
-			Requests: cfg.RateLimit.PasswordResetPerHour,
-			Window:   cfg.RateLimit.PasswordResetWindow,
+			Name:            "auth.password_reset",
+			Requests:        cfg.RateLimit.PasswordResetPerHour,
+			Window:          cfg.RateLimit.PasswordResetWindow,
+			AccountRequests: cfg.RateLimit.PasswordResetPerHour,
+			AccountWindow:   cfg.RateLimit.PasswordResetWindow,
 		}
 
 		password.POST("/reset/request",
@@ -24,6 +27,7 @@ func RegisterPasswordRoutes(router *gin.RouterGroup, controller *controllers.Pas
 
 		// Password reset confirmation (less restrictive since it requires valid token)
 		passwordConfirmConfig := middleware.RateLimitConfig{
+			Name:     "auth.password_confirm",
 			Requests: cfg.RateLimit.AuthRequestsPerMinute * 2, // Allow more attempts for confirmation
 			Window:   cfg.RateLimit.AuthWindow,
 		}
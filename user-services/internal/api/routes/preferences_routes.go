@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"user-services/internal/api/controllers"
+	"user-services/internal/api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterPreferencesRoutes exposes the caller's preferences to the BFF.
+func RegisterPreferencesRoutes(router *gin.RouterGroup, controller *controllers.PreferencesController) {
+	preferences := router.Group("/users/me/preferences")
+	preferences.Use(middleware.InternalAuthRequired())
+	{
+		preferences.GET("", controller.GetPreferences)      // GET /users/me/preferences
+		preferences.PATCH("", controller.UpdatePreferences) // PATCH /users/me/preferences
+	}
+}
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"user-services/internal/api/controllers"
+	"user-services/internal/api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterPushTokenRoutes exposes the caller's push notification devices to the BFF, and
+// the endpoints the notification service reads tokens from and reports the ones a push
+// provider rejected to.
+func RegisterPushTokenRoutes(router *gin.RouterGroup, controller *controllers.PushTokenController) {
+	tokens := router.Group("/users/me/push-tokens")
+	tokens.Use(middleware.InternalAuthRequired())
+	{
+		tokens.POST("", controller.RegisterToken)                // POST /users/me/push-tokens
+		tokens.GET("", controller.ListTokens)                    // GET /users/me/push-tokens
+		tokens.POST("/unregister", controller.UnregisterByValue) // POST /users/me/push-tokens/unregister
+		tokens.DELETE("/:id", controller.UnregisterToken)        // DELETE /users/me/push-tokens/:id
+	}
+
+	internal := router.Group("/internal/push-tokens")
+	internal.Use(middleware.ServiceAuthRequired())
+	{
+		internal.GET("/users/:id", controller.ListTargets)  // GET /internal/push-tokens/users/:id
+		internal.POST("/invalid", controller.ReportInvalid) // POST /internal/push-tokens/invalid
+	}
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"user-services/internal/api/controllers"
+	"user-services/internal/api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterUserImportRoutes exposes bulk user provisioning to the BFF, which restricts it
+// to admins.
+func RegisterUserImportRoutes(router *gin.RouterGroup, controller *controllers.UserImportController) {
+	users := router.Group("/users")
+	users.Use(middleware.InternalAuthRequired())
+	{
+		users.POST("/import", controller.ImportUsers) // POST /users/import
+	}
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"user-services/internal/api/controllers"
+	"user-services/internal/api/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterUserMetricsRoutes exposes the user metrics of the back-office overview page to
+// the BFF, which restricts them to admins.
+func RegisterUserMetricsRoutes(router *gin.RouterGroup, controller *controllers.UserMetricsController) {
+	dashboard := router.Group("/dashboard")
+	dashboard.Use(middleware.InternalAuthRequired())
+	{
+		dashboard.GET("/users", controller.GetSummary) // GET /dashboard/users
+	}
+}
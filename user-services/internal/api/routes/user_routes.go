@@ -12,20 +12,67 @@ import (
 func RegisterUserRoutes(router *gin.RouterGroup, controller *controllers.UserController, rateLimiter middleware.RateLimiter, cfg *config.Config) {
 	users := router.Group("/users")
 	{
-		// Authentication routes (public) with rate limiting
+		// Authentication routes (public) with per-IP and per-account rate limiting
 		authConfig := middleware.RateLimitConfig{
-			Requests: cfg.RateLimit.AuthRequestsPerMinute,
-			Window:   cfg.RateLimit.AuthWindow,
+			Name:            "auth.login",
+			Requests:        cfg.RateLimit.AuthRequestsPerMinute,
+			Window:          cfg.RateLimit.AuthWindow,
+			AccountRequests: cfg.RateLimit.AuthAccountRequests,
+			AccountWindow:   cfg.RateLimit.AuthAccountWindow,
+		}
+		registerConfig := middleware.RateLimitConfig{
+			Name:            "auth.register",
+			Requests:        cfg.RateLimit.RegisterPerHour,
+			Window:          cfg.RateLimit.RegisterWindow,
+			AccountRequests: cfg.RateLimit.RegisterPerHour,
+			AccountWindow:   cfg.RateLimit.RegisterWindow,
+		}
+		// Unlock links are emailed, so they share the password reset budget
+		unlockConfig := middleware.RateLimitConfig{
+			Name:            "auth.email_link",
+			Requests:        cfg.RateLimit.PasswordResetPerHour,
+			Window:          cfg.RateLimit.PasswordResetWindow,
+			AccountRequests: cfg.RateLimit.PasswordResetPerHour,
+			AccountWindow:   cfg.RateLimit.PasswordResetWindow,
+		}
+		otpSendConfig := middleware.RateLimitConfig{
+			Name:            "auth.otp_send",
+			Requests:        cfg.RateLimit.OTPSendRequests,
+			Window:          cfg.RateLimit.OTPSendWindow,
+			AccountRequests: cfg.RateLimit.OTPSendRequests,
+			AccountWindow:   cfg.RateLimit.OTPSendWindow,
 		}
 
 		users.POST("/register",
-			middleware.AuthRateLimitMiddleware(rateLimiter, authConfig),
+			middleware.AuthRateLimitMiddleware(rateLimiter, registerConfig),
 			controller.RegisterUser)
 		users.POST("/login",
 			middleware.AuthRateLimitMiddleware(rateLimiter, authConfig),
 			controller.LoginUser)
-		users.POST("/logout", controller.LogoutUser)
+		users.POST("/login/otp/send",
+			middleware.AuthRateLimitMiddleware(rateLimiter, otpSendConfig),
+			controller.SendLoginOTP)
+		users.POST("/login/webauthn/begin",
+			middleware.AuthRateLimitMiddleware(rateLimiter, authConfig),
+			controller.BeginPasskeyLogin)
+		users.POST("/login/webauthn/finish",
+			middleware.AuthRateLimitMiddleware(rateLimiter, authConfig),
+			controller.FinishPasskeyLogin)
+		// Login links and codes are emailed, so requests share the password reset budget
+		users.POST("/login/passwordless",
+			middleware.AuthRateLimitMiddleware(rateLimiter, unlockConfig),
+			controller.RequestPasswordlessLogin)
+		users.POST("/login/passwordless/verify",
+			middleware.AuthRateLimitMiddleware(rateLimiter, authConfig),
+			controller.VerifyPasswordlessLogin)
+		users.POST("/logout", middleware.InternalAuthRequired(), controller.LogoutUser)
 		users.GET("/verify-email", controller.VerifyUserEmail)
+		users.POST("/unlock/request",
+			middleware.AuthRateLimitMiddleware(rateLimiter, unlockConfig),
+			controller.RequestAccountUnlock)
+		users.POST("/unlock/confirm",
+			middleware.AuthRateLimitMiddleware(rateLimiter, authConfig),
+			controller.ConfirmAccountUnlock)
 
 		// Profile routes (authenticated)
 		profile := users.Group("/profile")
@@ -48,4 +95,11 @@ func RegisterUserRoutes(router *gin.RouterGroup, controller *controllers.UserCon
 			users.POST("/:id/restore", controller.RestoreAccount)
 		}
 	}
+
+	// Batch lookups of other services, such as the BFF enriching leaderboards
+	internal := router.Group("/internal/users")
+	internal.Use(middleware.ServiceAuthRequired())
+	{
+		internal.POST("/batch", controller.BatchGetUsers) // POST /internal/users/batch
+	}
 }
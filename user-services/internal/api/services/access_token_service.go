@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/repositories"
+	"user-services/internal/config"
+	"user-services/internal/errors"
+	"user-services/internal/models"
+	"user-services/internal/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Statuses reported for personal access tokens.
+const (
+	AccessTokenStatusActive  = "active"
+	AccessTokenStatusExpired = "expired"
+	AccessTokenStatusRevoked = "revoked"
+)
+
+// AccessTokenService manages personal access tokens: long-lived API tokens users create
+// for scripts and integrations. A token acts as its user with the scopes it was granted,
+// read for safe requests and write for the rest, and is shown only when created; only
+// its hash is stored. The BFF introspects tokens presented to it instead of validating a
+// session JWT.
+type AccessTokenService interface {
+	CreateToken(ctx context.Context, userID uuid.UUID, req dto.CreateAccessTokenRequest) (*dto.CreatedAccessTokenResponse, error)
+	ListTokens(ctx context.Context, userID uuid.UUID) ([]dto.AccessTokenResponse, error)
+	RevokeToken(ctx context.Context, userID, tokenID uuid.UUID) error
+	// Introspect resolves a presented token to the identity it acts as, failing with
+	// ErrInvalidAccessToken for unknown, revoked or expired tokens and for users who can
+	// no longer sign in.
+	Introspect(ctx context.Context, req dto.IntrospectAccessTokenRequest) (*dto.IntrospectAccessTokenResponse, error)
+}
+
+type accessTokenService struct {
+	tokenRepo    repositories.AccessTokenRepository
+	userRepo     repositories.UserRepository
+	orgRepo      repositories.OrganizationRepository
+	auditLogRepo repositories.AuditLogRepository
+	cfg          config.AccessTokenConfig
+}
+
+func NewAccessTokenService(
+	tokenRepo repositories.AccessTokenRepository,
+	userRepo repositories.UserRepository,
+	orgRepo repositories.OrganizationRepository,
+	auditLogRepo repositories.AuditLogRepository,
+	cfg config.AccessTokenConfig,
+) AccessTokenService {
+	return &accessTokenService{
+		tokenRepo:    tokenRepo,
+		userRepo:     userRepo,
+		orgRepo:      orgRepo,
+		auditLogRepo: auditLogRepo,
+		cfg:          cfg,
+	}
+}
+
+func (s *accessTokenService) CreateToken(ctx context.Context, userID uuid.UUID, req dto.CreateAccessTokenRequest) (*dto.CreatedAccessTokenResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.NewValidationError("name must not be blank")
+	}
+	scopes := normalizeAccessTokenScopes(req.Scopes)
+
+	now := time.Now()
+	lifetime := time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	if s.cfg.MaxLifetime > 0 {
+		if lifetime > s.cfg.MaxLifetime {
+			return nil, errors.NewValidationError(fmt.Sprintf("expires_in_days must be at most %d", int(s.cfg.MaxLifetime.Hours()/24)))
+		}
+		if lifetime == 0 {
+			lifetime = s.cfg.MaxLifetime
+		}
+	}
+
+	plaintext, prefix, err := utils.GenerateAccessToken()
+	if err != nil {
+		return nil, err
+	}
+	token := &models.PersonalAccessToken{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    prefix,
+		TokenHash: utils.HashToken(plaintext),
+		Scopes:    strings.Join(scopes, " "),
+		CreatedAt: now,
+	}
+	if lifetime > 0 {
+		token.ExpiresAt = sql.NullTime{Time: now.Add(lifetime), Valid: true}
+	}
+
+	if err := s.tokenRepo.Create(ctx, token, s.cfg.MaxPerUser); err != nil {
+		switch {
+		case stderrors.Is(err, repositories.ErrAccessTokenLimit):
+			return nil, errors.NewAccessTokenLimitError(s.cfg.MaxPerUser)
+		case stderrors.Is(err, gorm.ErrRecordNotFound):
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to create access token: %w", err)
+	}
+
+	resp := toAccessTokenResponse(*token, now)
+	s.audit(ctx, userID, "access_token.created", map[string]any{
+		"token_id":   token.ID,
+		"name":       token.Name,
+		"prefix":     token.Prefix,
+		"scopes":     scopes,
+		"expires_at": resp.ExpiresAt,
+	})
+
+	return &dto.CreatedAccessTokenResponse{
+		AccessTokenResponse: resp,
+		Token:               plaintext,
+	}, nil
+}
+
+func (s *accessTokenService) ListTokens(ctx context.Context, userID uuid.UUID) ([]dto.AccessTokenResponse, error) {
+	tokens, err := s.tokenRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	responses := make([]dto.AccessTokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		responses = append(responses, toAccessTokenResponse(token, now))
+	}
+	return responses, nil
+}
+
+func (s *accessTokenService) RevokeToken(ctx context.Context, userID, tokenID uuid.UUID) error {
+	revoked, err := s.tokenRepo.Revoke(ctx, userID, tokenID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+	if !revoked {
+		return errors.ErrAccessTokenNotFound
+	}
+
+	s.audit(ctx, userID, "access_token.revoked", map[string]any{
+		"token_id": tokenID,
+	})
+	return nil
+}
+
+func (s *accessTokenService) Introspect(ctx context.Context, req dto.IntrospectAccessTokenRequest) (*dto.IntrospectAccessTokenResponse, error) {
+	if !utils.IsAccessToken(req.Token) {
+		return nil, errors.ErrInvalidAccessToken
+	}
+	token, err := s.tokenRepo.GetByHash(ctx, utils.HashToken(req.Token))
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrInvalidAccessToken
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if !token.Active(now) {
+		return nil, errors.ErrInvalidAccessToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, token.UserID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrInvalidAccessToken
+		}
+		return nil, err
+	}
+	if user.Status != models.StatusActive || user.DeletedAt.Valid {
+		return nil, errors.ErrInvalidAccessToken
+	}
+
+	// Recording every use would write on each API call; a coarse timestamp is enough
+	if !token.LastUsedAt.Valid || now.Sub(token.LastUsedAt.Time) >= s.cfg.LastUsedInterval {
+		var ip *string
+		if req.IP != "" {
+			ip = &req.IP
+		}
+		if err := s.tokenRepo.RecordUse(ctx, token.ID, now, ip); err != nil {
+			slog.WarnContext(ctx, "failed to record use of access token", "access_token_id", token.ID, "error", err)
+		}
+	}
+
+	resp := &dto.IntrospectAccessTokenResponse{
+		TokenID: token.ID,
+		UserID:  user.ID,
+		Email:   user.Email,
+		Role:    user.Role,
+		Scopes:  token.ScopeList(),
+	}
+	if token.ExpiresAt.Valid {
+		resp.ExpiresAt = &token.ExpiresAt.Time
+	}
+	if user.OrganizationID != nil {
+		member, err := s.orgRepo.GetMember(ctx, *user.OrganizationID, user.ID)
+		switch {
+		case err == nil:
+			resp.OrganizationID = &member.OrganizationID
+			resp.OrganizationRole = member.Role
+		case !stderrors.Is(err, gorm.ErrRecordNotFound):
+			slog.WarnContext(ctx, "failed to load organization membership", "user_id", user.ID, "error", err)
+		}
+	}
+	return resp, nil
+}
+
+func (s *accessTokenService) audit(ctx context.Context, userID uuid.UUID, action string, metadata map[string]any) {
+	entry := &models.AuditLog{
+		UserID:    &userID,
+		Action:    action,
+		Metadata:  metadata,
+		CreatedAt: time.Now(),
+	}
+	if err := s.auditLogRepo.Create(ctx, entry); err != nil {
+		slog.WarnContext(ctx, "failed to write audit log", "action", action, "error", err)
+	}
+}
+
+// normalizeAccessTokenScopes deduplicates scopes and adds read to write, which implies
+// it.
+func normalizeAccessTokenScopes(scopes []string) []string {
+	normalized := []string{models.AccessTokenScopeRead}
+	if slices.Contains(scopes, models.AccessTokenScopeWrite) {
+		normalized = append(normalized, models.AccessTokenScopeWrite)
+	}
+	return normalized
+}
+
+func toAccessTokenResponse(token models.PersonalAccessToken, now time.Time) dto.AccessTokenResponse {
+	resp := dto.AccessTokenResponse{
+		ID:         token.ID,
+		Name:       token.Name,
+		Prefix:     token.Prefix,
+		Scopes:     token.ScopeList(),
+		Status:     AccessTokenStatusActive,
+		LastUsedIP: getStringValue(token.LastUsedIP),
+		CreatedAt:  token.CreatedAt,
+	}
+	if token.ExpiresAt.Valid {
+		resp.ExpiresAt = &token.ExpiresAt.Time
+		if !now.Before(token.ExpiresAt.Time) {
+			resp.Status = AccessTokenStatusExpired
+		}
+	}
+	if token.LastUsedAt.Valid {
+		resp.LastUsedAt = &token.LastUsedAt.Time
+	}
+	if token.RevokedAt.Valid {
+		resp.RevokedAt = &token.RevokedAt.Time
+		resp.Status = AccessTokenStatusRevoked
+	}
+	return resp
+}
@@ -0,0 +1,390 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/repositories"
+	"user-services/internal/config"
+	"user-services/internal/errors"
+	"user-services/internal/models"
+
+	"github.com/ductan2/microservice-app/shared/ids"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Which account of a merge an emailed code was sent to.
+const (
+	mergeAccountTarget = "target"
+	mergeAccountSource = "source"
+)
+
+// AccountMergeService merges a user's duplicate account (the source) into the account
+// they are signed in to (the target). Requesting a merge emails a code to both
+// addresses; entering both proves the user owns both accounts. The source's sessions
+// are then revoked and moved to the target along with its activity sessions, MFA
+// methods, preferences and organization memberships, and the source is tombstoned:
+// deleted, its email address freed and merged_into_id pointing at the target. A
+// user.merged event tells other services to re-point the source's user ID.
+type AccountMergeService interface {
+	RequestMerge(ctx context.Context, targetID uuid.UUID, req dto.RequestAccountMergeRequest) (*dto.AccountMergeResponse, error)
+	GetMerge(ctx context.Context, targetID, mergeID uuid.UUID) (*dto.AccountMergeResponse, error)
+	// ConfirmMerge checks both codes and runs the merge. Wrong codes count towards
+	// ACCOUNT_MERGE_MAX_ATTEMPTS, after which the merge is cancelled.
+	ConfirmMerge(ctx context.Context, targetID, mergeID uuid.UUID, req dto.ConfirmAccountMergeRequest) (*dto.AccountMergeResponse, error)
+	CancelMerge(ctx context.Context, targetID, mergeID uuid.UUID) error
+}
+
+type accountMergeService struct {
+	mergeRepo      repositories.AccountMergeRepository
+	userRepo       repositories.UserRepository
+	auditLogRepo   repositories.AuditLogRepository
+	sessionService SessionService
+	cfg            config.AccountMergeConfig
+}
+
+func NewAccountMergeService(
+	mergeRepo repositories.AccountMergeRepository,
+	userRepo repositories.UserRepository,
+	auditLogRepo repositories.AuditLogRepository,
+	sessionService SessionService,
+	cfg config.AccountMergeConfig,
+) AccountMergeService {
+	return &accountMergeService{
+		mergeRepo:      mergeRepo,
+		userRepo:       userRepo,
+		auditLogRepo:   auditLogRepo,
+		sessionService: sessionService,
+		cfg:            cfg,
+	}
+}
+
+func (s *accountMergeService) RequestMerge(ctx context.Context, targetID uuid.UUID, req dto.RequestAccountMergeRequest) (*dto.AccountMergeResponse, error) {
+	target, err := s.userRepo.GetByID(ctx, targetID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if strings.EqualFold(email, target.Email) {
+		return nil, errors.ErrAccountMergeSelf
+	}
+
+	// Every request emails both accounts, so requests are spaced out
+	now := time.Now()
+	latest, err := s.mergeRepo.GetLatestByTarget(ctx, targetID)
+	switch {
+	case err == nil:
+		if wait := s.cfg.RequestCooldown - now.Sub(latest.CreatedAt); wait > 0 {
+			return nil, errors.NewAccountMergeTooSoonError(wait)
+		}
+	case !stderrors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+
+	source, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, err
+	}
+	if source.Status == models.StatusDeleted || source.DeletedAt.Valid || source.MergedIntoID != nil {
+		return nil, errors.ErrUserNotFound
+	}
+	if source.ID == target.ID {
+		return nil, errors.ErrAccountMergeSelf
+	}
+	if source.Role == models.RoleAdmin || source.Role == models.RoleSuperAdmin {
+		return nil, errors.ErrAccountMergeForbidden
+	}
+
+	merge := &models.AccountMerge{
+		ID:           ids.New(),
+		TargetUserID: target.ID,
+		SourceUserID: source.ID,
+		SourceEmail:  source.Email,
+		Status:       models.AccountMergeStatusPending,
+		ExpiresAt:    now.Add(s.cfg.CodeTTL),
+		Report:       models.JSONBMap{},
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	events := make([]*models.Outbox, 0, 2)
+	for _, recipient := range []struct {
+		account string
+		email   string
+		hash    *string
+	}{
+		{mergeAccountTarget, target.Email, &merge.TargetCodeHash},
+		{mergeAccountSource, source.Email, &merge.SourceCodeHash},
+	} {
+		code, err := generateOTPCode(config.GetConfig().OTP.CodeLength)
+		if err != nil {
+			return nil, err
+		}
+		*recipient.hash = hashMergeCode(merge.ID, recipient.account, code)
+
+		event, err := s.codeEvent(merge, target, source, recipient.account, recipient.email, code)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	if err := s.mergeRepo.Create(ctx, merge, events); err != nil {
+		return nil, fmt.Errorf("failed to create account merge: %w", err)
+	}
+
+	s.audit(ctx, target.ID, "user.merge_requested", map[string]any{
+		"merge_id":       merge.ID,
+		"source_user_id": source.ID,
+	})
+
+	resp := toAccountMergeResponse(*merge)
+	return &resp, nil
+}
+
+func (s *accountMergeService) GetMerge(ctx context.Context, targetID, mergeID uuid.UUID) (*dto.AccountMergeResponse, error) {
+	merge, err := s.getOwned(ctx, targetID, mergeID)
+	if err != nil {
+		return nil, err
+	}
+	resp := toAccountMergeResponse(*merge)
+	return &resp, nil
+}
+
+func (s *accountMergeService) ConfirmMerge(ctx context.Context, targetID, mergeID uuid.UUID, req dto.ConfirmAccountMergeRequest) (*dto.AccountMergeResponse, error) {
+	merge, err := s.getOwned(ctx, targetID, mergeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMergePending(merge); err != nil {
+		return nil, err
+	}
+
+	targetOK := hmac.Equal([]byte(hashMergeCode(merge.ID, mergeAccountTarget, strings.TrimSpace(req.TargetCode))), []byte(merge.TargetCodeHash))
+	sourceOK := hmac.Equal([]byte(hashMergeCode(merge.ID, mergeAccountSource, strings.TrimSpace(req.SourceCode))), []byte(merge.SourceCodeHash))
+	if !targetOK || !sourceOK {
+		cancelled, err := s.mergeRepo.RecordFailedAttempt(ctx, merge.ID, s.cfg.MaxAttempts)
+		if err != nil {
+			return nil, err
+		}
+		if cancelled {
+			s.audit(ctx, targetID, "user.merge_cancelled", map[string]any{
+				"merge_id": merge.ID,
+				"reason":   "too many attempts",
+			})
+		}
+		return nil, errors.ErrInvalidAccountMergeCode
+	}
+
+	target, err := s.userRepo.GetByID(ctx, merge.TargetUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tokens issued to the source's sessions name the source account, so the sessions
+	// are ended before they move to the target
+	if err := s.sessionService.RevokeAllUserSessions(ctx, merge.SourceUserID); err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions of merged account: %w", err)
+	}
+
+	now := time.Now()
+	payload, err := json.Marshal(map[string]any{
+		"merge_id":       merge.ID,
+		"source_user_id": merge.SourceUserID,
+		"target_user_id": merge.TargetUserID,
+		"source_email":   merge.SourceEmail,
+		"target_email":   target.Email,
+		"merged_at":      now.UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	event := &models.Outbox{
+		AggregateID: merge.SourceUserID,
+		Topic:       "user.merged",
+		Type:        "UserMerged",
+		Payload:     payload,
+		CreatedAt:   now,
+	}
+
+	report, claimed, err := s.mergeRepo.Complete(ctx, merge, event)
+	if err != nil {
+		if stderrors.Is(err, repositories.ErrMergeAccountUnavailable) {
+			if _, cancelErr := s.mergeRepo.Cancel(ctx, merge.ID); cancelErr != nil {
+				slog.WarnContext(ctx, "failed to cancel account merge", "merge_id", merge.ID, "error", cancelErr)
+			}
+			return nil, errors.ErrAccountMergeUnavailable
+		}
+		return nil, fmt.Errorf("failed to merge accounts: %w", err)
+	}
+	if !claimed {
+		current, err := s.mergeRepo.GetByID(ctx, merge.ID)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkMergePending(current); err != nil {
+			return nil, err
+		}
+		return nil, errors.ErrAccountMergeNotPending
+	}
+
+	s.audit(ctx, merge.TargetUserID, "user.merged", map[string]any{
+		"merge_id":       merge.ID,
+		"source_user_id": merge.SourceUserID,
+		"report":         report,
+	})
+	s.audit(ctx, merge.SourceUserID, "user.merged", map[string]any{
+		"merge_id":       merge.ID,
+		"merged_into_id": merge.TargetUserID,
+	})
+
+	completed, err := s.mergeRepo.GetByID(ctx, merge.ID)
+	if err != nil {
+		return nil, err
+	}
+	resp := toAccountMergeResponse(*completed)
+	return &resp, nil
+}
+
+func (s *accountMergeService) CancelMerge(ctx context.Context, targetID, mergeID uuid.UUID) error {
+	merge, err := s.getOwned(ctx, targetID, mergeID)
+	if err != nil {
+		return err
+	}
+	cancelled, err := s.mergeRepo.Cancel(ctx, merge.ID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel account merge: %w", err)
+	}
+	if !cancelled {
+		return errors.ErrAccountMergeNotPending
+	}
+
+	s.audit(ctx, targetID, "user.merge_cancelled", map[string]any{
+		"merge_id": merge.ID,
+		"reason":   "cancelled by user",
+	})
+	return nil
+}
+
+// getOwned returns one of the target's merges; merges of other users are reported as
+// not found.
+func (s *accountMergeService) getOwned(ctx context.Context, targetID, mergeID uuid.UUID) (*models.AccountMerge, error) {
+	merge, err := s.mergeRepo.GetByID(ctx, mergeID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrAccountMergeNotFound
+		}
+		return nil, err
+	}
+	if merge.TargetUserID != targetID {
+		return nil, errors.ErrAccountMergeNotFound
+	}
+	return merge, nil
+}
+
+// codeEvent returns the event that emails one of the merge's codes. Each address is
+// told which account the other one is, so an unexpected email reveals the attempt.
+func (s *accountMergeService) codeEvent(merge *models.AccountMerge, target, source *models.User, account, email, code string) (*models.Outbox, error) {
+	otherEmail := source.Email
+	if account == mergeAccountSource {
+		otherEmail = target.Email
+	}
+	payloadBytes, err := json.Marshal(map[string]any{
+		"merge_id":    merge.ID,
+		"email":       email,
+		"account":     account,
+		"other_email": otherEmail,
+		"code":        code,
+		"expires_at":  merge.ExpiresAt.UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.Outbox{
+		AggregateID: merge.ID,
+		Topic:       "user.merge_verification_requested",
+		Type:        "AccountMergeVerification",
+		Payload:     payloadBytes,
+		CreatedAt:   merge.CreatedAt,
+	}, nil
+}
+
+func (s *accountMergeService) audit(ctx context.Context, userID uuid.UUID, action string, metadata map[string]any) {
+	entry := &models.AuditLog{
+		UserID:    &userID,
+		Action:    action,
+		Metadata:  metadata,
+		CreatedAt: time.Now(),
+	}
+	if err := s.auditLogRepo.Create(ctx, entry); err != nil {
+		slog.WarnContext(ctx, "failed to write audit log", "action", action, "error", err)
+	}
+}
+
+// checkMergePending fails unless the merge can still be confirmed.
+func checkMergePending(merge *models.AccountMerge) error {
+	if merge.Status != models.AccountMergeStatusPending {
+		return errors.ErrAccountMergeNotPending
+	}
+	if !time.Now().Before(merge.ExpiresAt) {
+		return errors.ErrAccountMergeExpired
+	}
+	return nil
+}
+
+// hashMergeCode keys the code hash with the server secret, the merge and the account
+// it was sent to, so the two codes cannot be swapped or replayed against another merge.
+func hashMergeCode(mergeID uuid.UUID, account, code string) string {
+	mac := hmac.New(sha256.New, []byte(config.GetConfig().JWT.Secret))
+	mac.Write([]byte(mergeID.String() + ":" + account + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func toAccountMergeResponse(merge models.AccountMerge) dto.AccountMergeResponse {
+	resp := dto.AccountMergeResponse{
+		ID:           merge.ID,
+		SourceEmail:  merge.SourceEmail,
+		SourceUserID: merge.SourceUserID,
+		Status:       merge.Status,
+		Attempts:     merge.Attempts,
+		ExpiresAt:    merge.ExpiresAt,
+		CreatedAt:    merge.CreatedAt,
+	}
+	if resp.Status == models.AccountMergeStatusPending && !time.Now().Before(merge.ExpiresAt) {
+		resp.Status = models.AccountMergeStatusExpired
+	}
+	if len(merge.Report) > 0 {
+		resp.Report = make(map[string]int64, len(merge.Report))
+		for step, count := range merge.Report {
+			switch n := count.(type) {
+			case float64:
+				resp.Report[step] = int64(n)
+			case int64:
+				resp.Report[step] = n
+			}
+		}
+	}
+	if merge.CompletedAt.Valid {
+		resp.CompletedAt = &merge.CompletedAt.Time
+	}
+	if merge.CancelledAt.Valid {
+		resp.CancelledAt = &merge.CancelledAt.Time
+	}
+	return resp
+}
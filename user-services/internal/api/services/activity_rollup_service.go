@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/repositories"
+	"user-services/internal/config"
+	"user-services/internal/errors"
+	"user-services/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Default ranges of rollup queries without dates
+const (
+	defaultActivityRollupDays  = 30
+	defaultActivityRollupWeeks = 12
+)
+
+// ActivityRollupService aggregates activity sessions into daily and weekly study time
+// per user, so services such as streaks and reports can read totals without scanning
+// sessions.
+type ActivityRollupService interface {
+	// ProcessRollups rolls up the activity sessions changed since the last run, or every
+	// session when nothing was rolled up yet.
+	ProcessRollups(ctx context.Context) error
+	GetRollups(ctx context.Context, userID uuid.UUID, query dto.ActivityRollupQuery) (*dto.ActivityRollupsResponse, error)
+}
+
+type activityRollupService struct {
+	rollupRepo repositories.ActivityRollupRepository
+	userRepo   repositories.UserRepository
+	cfg        config.ActivityRollupConfig
+}
+
+func NewActivityRollupService(rollupRepo repositories.ActivityRollupRepository, userRepo repositories.UserRepository, cfg config.ActivityRollupConfig) ActivityRollupService {
+	return &activityRollupService{
+		rollupRepo: rollupRepo,
+		userRepo:   userRepo,
+		cfg:        cfg,
+	}
+}
+
+func (s *activityRollupService) ProcessRollups(ctx context.Context) error {
+	last, err := s.rollupRepo.LastRefreshed(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read last activity rollup: %w", err)
+	}
+	since := last
+	if !last.IsZero() {
+		since = last.Add(-s.cfg.Overlap)
+	}
+
+	if _, err := s.rollupRepo.Refresh(ctx, since); err != nil {
+		return fmt.Errorf("failed to roll up activity sessions: %w", err)
+	}
+	return nil
+}
+
+func (s *activityRollupService) GetRollups(ctx context.Context, userID uuid.UUID, query dto.ActivityRollupQuery) (*dto.ActivityRollupsResponse, error) {
+	period := query.Period
+	if period == "" {
+		period = models.ActivityRollupDay
+	}
+	from, to, err := s.rollupRange(period, query.From, query.To)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	rollups, err := s.rollupRepo.ListByUser(ctx, userID, period, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list activity rollups: %w", err)
+	}
+
+	resp := &dto.ActivityRollupsResponse{
+		UserID:  userID,
+		Period:  period,
+		From:    from.Format(time.DateOnly),
+		To:      to.Format(time.DateOnly),
+		Rollups: make([]dto.ActivityRollupResponse, 0, len(rollups)),
+	}
+	for _, rollup := range rollups {
+		resp.Rollups = append(resp.Rollups, dto.ActivityRollupResponse{
+			PeriodStart:    rollup.PeriodStart.Format(time.DateOnly),
+			ActivityTotals: activityTotals(rollup.SessionCount, rollup.TotalDurationMs, rollup.ActiveDays),
+		})
+		resp.Totals.SessionCount += rollup.SessionCount
+		resp.Totals.TotalDurationMs += rollup.TotalDurationMs
+		resp.Totals.ActiveDays += rollup.ActiveDays
+		if resp.RefreshedAt == nil || rollup.UpdatedAt.After(*resp.RefreshedAt) {
+			updatedAt := rollup.UpdatedAt
+			resp.RefreshedAt = &updatedAt
+		}
+	}
+	resp.Totals = activityTotals(resp.Totals.SessionCount, resp.Totals.TotalDurationMs, resp.Totals.ActiveDays)
+	return resp, nil
+}
+
+// rollupRange resolves the dates of a rollup query. Week ranges start on the Monday of
+// the week from falls in.
+func (s *activityRollupService) rollupRange(period, fromParam, toParam string) (time.Time, time.Time, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	to := today
+	if toParam != "" {
+		parsed, err := time.Parse(time.DateOnly, toParam)
+		if err != nil {
+			return time.Time{}, time.Time{}, invalidActivityRange("to must be a date such as 2024-01-31")
+		}
+		to = parsed
+	}
+
+	var from time.Time
+	if fromParam != "" {
+		parsed, err := time.Parse(time.DateOnly, fromParam)
+		if err != nil {
+			return time.Time{}, time.Time{}, invalidActivityRange("from must be a date such as 2024-01-01")
+		}
+		from = parsed
+	} else if period == models.ActivityRollupWeek {
+		from = to.AddDate(0, 0, -7*(defaultActivityRollupWeeks-1))
+	} else {
+		from = to.AddDate(0, 0, -(defaultActivityRollupDays - 1))
+	}
+	if period == models.ActivityRollupWeek {
+		// time.Weekday counts from Sunday; ISO weeks start on Monday
+		from = from.AddDate(0, 0, -((int(from.Weekday()) + 6) % 7))
+	}
+
+	if from.After(to) {
+		return time.Time{}, time.Time{}, invalidActivityRange("from must not be after to")
+	}
+	if to.Sub(from) > s.cfg.MaxRange {
+		return time.Time{}, time.Time{}, invalidActivityRange(fmt.Sprintf("The range may cover at most %d days", int(s.cfg.MaxRange.Hours()/24)))
+	}
+	return from, to, nil
+}
+
+func invalidActivityRange(message string) error {
+	return errors.NewValidationError(message).WithReason("INVALID_ACTIVITY_RANGE")
+}
+
+func activityTotals(sessions int, durationMs int64, activeDays int) dto.ActivityTotals {
+	totals := dto.ActivityTotals{
+		SessionCount:    sessions,
+		TotalMinutes:    durationMs / int64(time.Minute/time.Millisecond),
+		TotalDurationMs: durationMs,
+		ActiveDays:      activeDays,
+	}
+	if sessions > 0 {
+		totals.AverageSessionMs = durationMs / int64(sessions)
+	}
+	return totals
+}
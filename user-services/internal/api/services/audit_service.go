@@ -2,18 +2,35 @@ package services
 
 import (
 	"context"
+	"math"
+	"time"
+
 	"user-services/internal/api/dto"
 	"user-services/internal/api/repositories"
+	"user-services/internal/errors"
+	"user-services/internal/models"
+	"user-services/internal/utils"
 
 	"github.com/google/uuid"
 )
 
+// AuditService records security-relevant events and serves paginated views of the audit
+// trail: a user's own history, and filtered listings for admins.
 type AuditService interface {
 	LogAction(ctx context.Context, userID, actorID *uuid.UUID, action, ipAddr string, metadata map[string]any) error
-	GetUserAuditLogs(ctx context.Context, userID uuid.UUID, page, pageSize int) (*dto.PaginatedResponse, error)
-	GetActionAuditLogs(ctx context.Context, action string, page, pageSize int) (*dto.PaginatedResponse, error)
+	GetUserAuditLogs(ctx context.Context, userID uuid.UUID, query dto.AuditLogQuery) (*dto.PaginatedResponse, error)
+	ListAuditLogs(ctx context.Context, query dto.AuditLogQuery) (*dto.PaginatedResponse, error)
+	// GetLoginHistory lists the successful and failed sign-ins on one account, most
+	// recent first, so users can spot sign-ins that were not theirs.
+	GetLoginHistory(ctx context.Context, userID uuid.UUID, query dto.LoginHistoryQuery) (*dto.PaginatedResponse, error)
 }
 
+// Audit actions of sign-in attempts on a known account
+const (
+	auditActionLoginSucceeded = "auth.login_succeeded"
+	auditActionLoginFailed    = "auth.login_failed"
+)
+
 type auditService struct {
 	auditLogRepo repositories.AuditLogRepository
 }
@@ -24,17 +41,175 @@ func NewAuditService(auditLogRepo repositories.AuditLogRepository) AuditService
 	}
 }
 
+// LogAction appends an entry. An empty ipAddr falls back to the request's client IP.
 func (s *auditService) LogAction(ctx context.Context, userID, actorID *uuid.UUID, action, ipAddr string, metadata map[string]any) error {
-	// TODO: implement
-	return nil
+	entry := &models.AuditLog{
+		UserID:    userID,
+		ActorID:   actorID,
+		Action:    action,
+		Metadata:  metadata,
+		CreatedAt: time.Now(),
+	}
+	if ipAddr != "" {
+		entry.IPAddr = &ipAddr
+	}
+	return s.auditLogRepo.Create(ctx, entry)
 }
 
-func (s *auditService) GetUserAuditLogs(ctx context.Context, userID uuid.UUID, page, pageSize int) (*dto.PaginatedResponse, error) {
-	// TODO: implement
-	return nil, nil
+// GetUserAuditLogs lists the events on one account. The query's user and actor filters
+// are ignored so a user cannot widen the view beyond their own account.
+func (s *auditService) GetUserAuditLogs(ctx context.Context, userID uuid.UUID, query dto.AuditLogQuery) (*dto.PaginatedResponse, error) {
+	query.UserID, query.ActorID = "", ""
+	filter, page, pageSize, err := auditLogFilter(query)
+	if err != nil {
+		return nil, err
+	}
+	filter.UserID = &userID
+	return s.list(ctx, filter, page, pageSize)
 }
 
-func (s *auditService) GetActionAuditLogs(ctx context.Context, action string, page, pageSize int) (*dto.PaginatedResponse, error) {
-	// TODO: implement
-	return nil, nil
+// ListAuditLogs lists events across all accounts for admins.
+func (s *auditService) ListAuditLogs(ctx context.Context, query dto.AuditLogQuery) (*dto.PaginatedResponse, error) {
+	filter, page, pageSize, err := auditLogFilter(query)
+	if err != nil {
+		return nil, err
+	}
+	return s.list(ctx, filter, page, pageSize)
+}
+
+func (s *auditService) GetLoginHistory(ctx context.Context, userID uuid.UUID, query dto.LoginHistoryQuery) (*dto.PaginatedResponse, error) {
+	filter, page, pageSize, err := auditLogFilter(dto.AuditLogQuery{
+		Page:     query.Page,
+		PageSize: query.PageSize,
+		From:     query.From,
+		To:       query.To,
+	})
+	if err != nil {
+		return nil, err
+	}
+	filter.UserID = &userID
+	switch query.Result {
+	case "success":
+		filter.Action = auditActionLoginSucceeded
+	case "failure":
+		filter.Action = auditActionLoginFailed
+	default:
+		filter.Actions = []string{auditActionLoginSucceeded, auditActionLoginFailed}
+	}
+
+	logs, total, err := s.auditLogRepo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]dto.LoginHistoryEntry, len(logs))
+	for i, log := range logs {
+		entries[i] = toLoginHistoryEntry(log)
+	}
+
+	return &dto.PaginatedResponse{
+		Data:       entries,
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      int(total),
+		TotalPages: int(math.Ceil(float64(total) / float64(pageSize))),
+	}, nil
+}
+
+func (s *auditService) list(ctx context.Context, filter repositories.AuditLogFilter, page, pageSize int) (*dto.PaginatedResponse, error) {
+	logs, total, err := s.auditLogRepo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]dto.AuditLogResponse, len(logs))
+	for i, log := range logs {
+		entries[i] = toAuditLogResponse(log)
+	}
+
+	return &dto.PaginatedResponse{
+		Data:       entries,
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      int(total),
+		TotalPages: int(math.Ceil(float64(total) / float64(pageSize))),
+	}, nil
+}
+
+func auditLogFilter(query dto.AuditLogQuery) (repositories.AuditLogFilter, int, int, error) {
+	page := query.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := query.PageSize
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		return repositories.AuditLogFilter{}, 0, 0,
+			errors.NewValidationError("from must be before to").WithReason("INVALID_TIME_RANGE")
+	}
+
+	filter := repositories.AuditLogFilter{
+		Action: query.Action,
+		From:   query.From,
+		To:     query.To,
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+	}
+	if query.UserID != "" {
+		id, err := uuid.Parse(query.UserID)
+		if err != nil {
+			return repositories.AuditLogFilter{}, 0, 0, errors.NewValidationError("user_id must be a UUID")
+		}
+		filter.UserID = &id
+	}
+	if query.ActorID != "" {
+		id, err := uuid.Parse(query.ActorID)
+		if err != nil {
+			return repositories.AuditLogFilter{}, 0, 0, errors.NewValidationError("actor_id must be a UUID")
+		}
+		filter.ActorID = &id
+	}
+	return filter, page, pageSize, nil
+}
+
+func toAuditLogResponse(log models.AuditLog) dto.AuditLogResponse {
+	return dto.AuditLogResponse{
+		ID:        log.ID,
+		UserID:    log.UserID,
+		ActorID:   log.ActorID,
+		Action:    log.Action,
+		IPAddr:    getStringValue(log.IPAddr),
+		UserAgent: getStringValue(log.UserAgent),
+		Metadata:  log.Metadata,
+		CreatedAt: log.CreatedAt,
+	}
+}
+
+func toLoginHistoryEntry(log models.AuditLog) dto.LoginHistoryEntry {
+	userAgent := getStringValue(log.UserAgent)
+	device := utils.ParseUserAgent(userAgent)
+	entry := dto.LoginHistoryEntry{
+		ID:         log.ID,
+		Result:     "failure",
+		IPAddr:     getStringValue(log.IPAddr),
+		UserAgent:  userAgent,
+		Label:      deviceLabel(device.Browser, device.OS),
+		DeviceType: device.DeviceType,
+		Browser:    device.Browser,
+		OS:         device.OS,
+		OccurredAt: log.CreatedAt,
+	}
+	if log.Action == auditActionLoginSucceeded {
+		entry.Result = "success"
+	}
+	if reason, ok := log.Metadata["reason"].(string); ok {
+		entry.Reason = reason
+	}
+	return entry
 }
@@ -2,18 +2,26 @@ package services
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"database/sql"
-	"encoding/json"
+	"encoding/hex"
 	"fmt"
+	"log/slog"
+	"slices"
 	"time"
 
+	"user-services/internal/api/dto"
 	"user-services/internal/api/repositories"
 	"user-services/internal/cache"
 	"user-services/internal/config"
 	"user-services/internal/errors"
+	"user-services/internal/metrics"
 	"user-services/internal/models"
+	"user-services/internal/passwordpolicy"
 	"user-services/internal/utils"
 
+	"github.com/ductan2/microservice-app/shared/events"
 	"github.com/google/uuid"
 )
 
@@ -27,6 +35,16 @@ type AuthService struct {
 	MFARepo          repositories.MFARepository
 	LoginAttemptRepo repositories.LoginAttemptRepository
 	SessionCache     *cache.SessionCache
+	Lockout          LockoutService
+	WebAuthn         WebAuthnService
+	OTP              OTPService
+	PasswordPolicy   *passwordpolicy.Engine
+	OrganizationRepo repositories.OrganizationRepository
+	Passwordless     *cache.PasswordlessCache
+	SessionDescriber *SessionDescriber
+	SecurityEvents   SecurityEventService
+	Erasure          ErasureService
+	Consents         ConsentService
 }
 
 // NewAuthService creates a new auth service instance
@@ -40,6 +58,16 @@ func NewAuthService(
 	mfaRepo repositories.MFARepository,
 	loginAttemptRepo repositories.LoginAttemptRepository,
 	sessionCache *cache.SessionCache,
+	lockout LockoutService,
+	webAuthn WebAuthnService,
+	otpService OTPService,
+	passwordPolicy *passwordpolicy.Engine,
+	organizationRepo repositories.OrganizationRepository,
+	passwordlessCache *cache.PasswordlessCache,
+	sessionDescriber *SessionDescriber,
+	securityEvents SecurityEventService,
+	erasure ErasureService,
+	consents ConsentService,
 ) *AuthService {
 	return &AuthService{
 		UserRepo:         userRepo,
@@ -51,6 +79,16 @@ func NewAuthService(
 		MFARepo:          mfaRepo,
 		LoginAttemptRepo: loginAttemptRepo,
 		SessionCache:     sessionCache,
+		Lockout:          lockout,
+		WebAuthn:         webAuthn,
+		OTP:              otpService,
+		PasswordPolicy:   passwordPolicy,
+		OrganizationRepo: organizationRepo,
+		Passwordless:     passwordlessCache,
+		SessionDescriber: sessionDescriber,
+		SecurityEvents:   securityEvents,
+		Erasure:          erasure,
+		Consents:         consents,
 	}
 }
 
@@ -69,7 +107,7 @@ func (s *AuthService) Register(ctx context.Context, email, password, name string
 		return AuthResult{}, err
 	}
 
-	if err := utils.ValidatePassword(password); err != nil {
+	if err := s.PasswordPolicy.Validate(ctx, password, email, name); err != nil {
 		return AuthResult{}, err
 	}
 
@@ -150,30 +188,17 @@ func (s *AuthService) Register(ctx context.Context, email, password, name string
 	verificationLink := fmt.Sprintf("%s/verify-email?token=%s", cfg.Email.FrontendURL, verificationToken)
 
 	// 9. Create outbox event for email verification
-	payloadData := map[string]any{
-		"user_id":           user.ID,
-		"email":             user.Email,
-		"name":              name,
-		"verification_link": verificationLink,
-		"verificationLink":  verificationLink, // alternative key
-	}
-	payloadBytes, err := json.Marshal(payloadData)
+	outboxEvent, err := newOutboxEvent(user.ID, &events.EmailVerificationRequested{
+		UserID:           user.ID,
+		Email:            user.Email,
+		Name:             name,
+		VerificationLink: verificationLink,
+	})
 	if err != nil {
 		// Log error but don't fail registration
-		// In production, you might want to use a proper logger
-	} else {
-		outboxEvent := &models.Outbox{
-			AggregateID: user.ID,
-			Topic:       "user.email_verification",
-			Type:        "EmailVerificationRequested",
-			Payload:     payloadBytes,
-			CreatedAt:   time.Now(),
-		}
-
-		if err := s.OutboxRepo.Create(ctx, outboxEvent); err != nil {
-			// Log error but don't fail registration
-			// In production, you might want to use a proper logger
-		}
+		slog.WarnContext(ctx, "failed to build email verification event", "user_id", user.ID, "error", err)
+	} else if err := s.OutboxRepo.Create(ctx, outboxEvent); err != nil {
+		slog.WarnContext(ctx, "failed to queue email verification", "user_id", user.ID, "error", err)
 	}
 
 	// 10. Return result WITHOUT token (user needs to verify email first)
@@ -183,37 +208,91 @@ func (s *AuthService) Register(ctx context.Context, email, password, name string
 	}, nil
 }
 
-// Login authenticates a user and returns auth result
-func (s *AuthService) Login(ctx context.Context, email, password, mfaCode, userAgent, ipAddr string) (AuthResult, error) {
+// Login authenticates a user and returns auth result. The principal is the user's
+// email, username or verified phone number. The second factor, when the user has one, is
+// either a TOTP code or a passkey assertion.
+func (s *AuthService) Login(ctx context.Context, principal, password, mfaCode string, webAuthn *dto.WebAuthnAssertion, userAgent, ipAddr string) (AuthResult, error) {
+	account, email := s.resolveLoginPrincipal(ctx, principal)
+
+	// Step 0: Reject accounts and IPs locked out after repeated failures
+	if err := s.Lockout.Check(ctx, email, ipAddr); err != nil {
+		_ = s.logLoginAttempt(ctx, nil, email, ipAddr, false, "locked_out")
+		return AuthResult{}, err
+	}
+
 	// Step 1: Authenticate user credentials
-	user, err := s.authenticateUser(ctx, email, password, ipAddr)
+	user, err := s.authenticateUser(ctx, account, email, password, ipAddr)
 	if err != nil {
-		return AuthResult{}, err
+		return AuthResult{}, s.recordLoginFailure(ctx, err, email, ipAddr)
 	}
 
 	// Step 2: Verify MFA if required
-	if err := s.verifyMFA(ctx, user, mfaCode, email, ipAddr); err != nil {
+	if err := s.verifyMFA(ctx, user, mfaCode, webAuthn, email, ipAddr); err != nil {
+		return AuthResult{}, s.recordLoginFailure(ctx, err, email, ipAddr)
+	}
+
+	// Step 3: Signing in during the cooling-off period reactivates a deactivated account
+	if err := s.reactivate(ctx, user); err != nil {
 		return AuthResult{}, err
 	}
 
-	// Step 3: Create session and tokens
+	// Step 4: Create session and tokens
 	authResult, err := s.createSessionAndTokens(ctx, user, userAgent, ipAddr)
 	if err != nil {
 		_ = s.logLoginAttempt(ctx, &user.ID, email, ipAddr, false, "session_creation_failed")
 		return AuthResult{}, err
 	}
 
-	// Step 4: Log successful login
+	// Step 5: Log successful login
 	_ = s.logLoginAttempt(ctx, &user.ID, email, ipAddr, true, "success")
 	_ = s.UserRepo.UpdateLastLogin(ctx, user.ID, time.Now(), ipAddr)
+	_ = s.Lockout.RecordSuccess(ctx, email)
 
 	return authResult, nil
 }
 
-// authenticateUser validates user credentials
-func (s *AuthService) authenticateUser(ctx context.Context, email, password, ipAddr string) (*models.User, error) {
-	user, err := s.UserRepo.GetUserByEmail(ctx, email)
-	if err != nil {
+// resolveLoginPrincipal finds the account an email, username or phone number belongs to.
+// It also returns the email the login is recorded and locked out under: the account's
+// email when there is one, so every identifier of an account shares its lockout, and the
+// normalized principal otherwise.
+func (s *AuthService) resolveLoginPrincipal(ctx context.Context, principal string) (*models.User, string) {
+	kind, normalized := utils.ParseLoginPrincipal(principal)
+
+	var user *models.User
+	var err error
+	switch kind {
+	case utils.PrincipalEmail:
+		var found models.User
+		if found, err = s.UserRepo.GetUserByEmail(ctx, normalized); err == nil {
+			user = &found
+		}
+	case utils.PrincipalPhone:
+		user, err = s.UserRepo.GetByPhoneNumber(ctx, normalized)
+	default:
+		user, err = s.UserRepo.GetByUsername(ctx, normalized)
+	}
+	if err != nil || user == nil {
+		return nil, normalized
+	}
+	return user, user.Email
+}
+
+// recordLoginFailure counts wrong passwords and MFA codes towards a lockout. When the
+// failure trips a lockout, the lockout error is returned instead of err.
+func (s *AuthService) recordLoginFailure(ctx context.Context, err error, email, ipAddr string) error {
+	if err != errors.ErrInvalidCredentials && err != errors.ErrInvalidMFACode && err != errors.ErrWebAuthnVerificationFailed {
+		return err
+	}
+	if lockErr := s.Lockout.RecordFailure(ctx, email, ipAddr); lockErr != nil {
+		return lockErr
+	}
+	return err
+}
+
+// authenticateUser validates user credentials against the account resolved from the
+// login principal, nil when none was found
+func (s *AuthService) authenticateUser(ctx context.Context, user *models.User, email, password, ipAddr string) (*models.User, error) {
+	if user == nil {
 		_ = s.logLoginAttempt(ctx, nil, email, ipAddr, false, "invalid_credentials")
 		return nil, errors.ErrInvalidCredentials
 	}
@@ -229,7 +308,9 @@ func (s *AuthService) authenticateUser(ctx context.Context, email, password, ipA
 	case "disabled":
 		return nil, errors.ErrAccountDisabled
 	case "deleted":
-		return nil, errors.ErrUserNotFound
+		if !s.deactivationPending(ctx, user) {
+			return nil, errors.ErrUserNotFound
+		}
 	}
 
 	if err := utils.CheckPassword(user.PasswordHash, password); err != nil {
@@ -237,30 +318,377 @@ func (s *AuthService) authenticateUser(ctx context.Context, email, password, ipA
 		return nil, errors.ErrInvalidCredentials
 	}
 
-	return &user, nil
+	return user, nil
+}
+
+// deactivationPending reports whether a deleted account was deactivated by its owner and
+// can still be reactivated by signing in.
+func (s *AuthService) deactivationPending(ctx context.Context, user *models.User) bool {
+	pending, err := s.Erasure.DeactivationPending(ctx, user)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to look up user deactivation", "user_id", user.ID, "error", err)
+		return false
+	}
+	return pending
+}
+
+// reactivate restores a deactivated account once the user has fully authenticated. It
+// does nothing for accounts that are not deleted.
+func (s *AuthService) reactivate(ctx context.Context, user *models.User) error {
+	if user.Status != models.StatusDeleted {
+		return nil
+	}
+	if err := s.Erasure.Reactivate(ctx, user); err != nil {
+		if err == errors.ErrAccountNotRecoverable {
+			return errors.ErrUserNotFound
+		}
+		return err
+	}
+	return nil
 }
 
-// verifyMFA checks MFA if required for the user
-func (s *AuthService) verifyMFA(ctx context.Context, user *models.User, mfaCode, email, ipAddr string) error {
-	totp, err := s.MFARepo.GetTOTPByUserID(ctx, user.ID)
-	if err != nil || totp == nil {
+// verifyMFA checks the second factor if the user has set one up. A passkey assertion is
+// preferred over a code when both are sent. A code is checked against the user's TOTP
+// app and then against the last code sent to their phone, in the user's fallback order.
+func (s *AuthService) verifyMFA(ctx context.Context, user *models.User, mfaCode string, webAuthn *dto.WebAuthnAssertion, email, ipAddr string) error {
+	methods, err := s.MFARepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		methods = nil
+	}
+
+	var codeMethods []*models.MFAMethod
+	var phone *models.MFAMethod
+	hasPasskey := false
+	available := make([]string, 0, 3)
+	for i := range methods {
+		m := &methods[i]
+		switch m.Type {
+		case models.MFATypeTOTP:
+			codeMethods = append(codeMethods, m)
+		case models.MFATypeWebAuthn:
+			hasPasskey = true
+		case models.MFATypePhone:
+			if !m.VerifiedAt.Valid {
+				continue
+			}
+			codeMethods = append(codeMethods, m)
+			if phone == nil {
+				phone = m
+			}
+		default:
+			continue
+		}
+		if !slices.Contains(available, m.Type) {
+			available = append(available, m.Type)
+		}
+	}
+	if len(available) == 0 {
 		// No MFA setup, skip verification
 		return nil
 	}
 
-	if mfaCode == "" {
+	if webAuthn != nil && hasPasskey {
+		if _, err := s.WebAuthn.VerifyAssertion(ctx, *webAuthn, &user.ID, false); err != nil {
+			_ = s.logLoginAttempt(ctx, &user.ID, email, ipAddr, false, "mfa_invalid")
+			return err
+		}
+		return nil
+	}
+
+	if mfaCode == "" || len(codeMethods) == 0 {
 		_ = s.logLoginAttempt(ctx, &user.ID, email, ipAddr, false, "mfa_required")
-		return errors.NewAuthenticationError("MFA code required").WithCode("MFA_REQUIRED")
+		details := map[string]any{"code": "mfa_required", "methods": available}
+		if phone != nil {
+			details["phone_number"] = maskPhoneNumber(phone.PhoneNumber)
+		}
+		return errors.NewAuthenticationError("MFA code required").WithReason("MFA_REQUIRED").WithDetails(details)
+	}
+
+	for _, m := range codeMethods {
+		switch m.Type {
+		case models.MFATypeTOTP:
+			if utils.VerifyTOTP(m.Secret, mfaCode, time.Now()) {
+				// Update last used timestamp
+				_ = s.MFARepo.UpdateLastUsed(ctx, m.ID)
+				return nil
+			}
+		case models.MFATypePhone:
+			ok, err := s.OTP.VerifyCode(ctx, m, mfaCode)
+			if err != nil {
+				return err
+			}
+			if ok {
+				return nil
+			}
+		}
 	}
 
-	if !utils.VerifyTOTP(totp.Secret, mfaCode, time.Now()) {
-		_ = s.logLoginAttempt(ctx, &user.ID, email, ipAddr, false, "mfa_invalid")
-		return errors.ErrInvalidMFACode
+	_ = s.logLoginAttempt(ctx, &user.ID, email, ipAddr, false, "mfa_invalid")
+	return errors.ErrInvalidMFACode
+}
+
+// SendLoginOTP texts or calls a login code to the user's verified phone number. It is
+// used after Login reported MFA_REQUIRED, and checks the password again (counting
+// failures towards the lockout) so codes are only sent on behalf of the account owner.
+// The principal is the same email, username or phone number the login used.
+func (s *AuthService) SendLoginOTP(ctx context.Context, principal, password, channel, ipAddr string) (*dto.OTPSendResponse, error) {
+	account, email := s.resolveLoginPrincipal(ctx, principal)
+	if err := s.Lockout.Check(ctx, email, ipAddr); err != nil {
+		_ = s.logLoginAttempt(ctx, nil, email, ipAddr, false, "locked_out")
+		return nil, err
 	}
 
-	// Update last used timestamp
-	_ = s.MFARepo.UpdateLastUsed(ctx, totp.ID)
-	return nil
+	user, err := s.authenticateUser(ctx, account, email, password, ipAddr)
+	if err != nil {
+		return nil, s.recordLoginFailure(ctx, err, email, ipAddr)
+	}
+
+	phone, err := s.MFARepo.GetPhoneByUserID(ctx, user.ID)
+	if err != nil || !phone.VerifiedAt.Valid {
+		return nil, errors.ErrMFANotSetup
+	}
+	return s.OTP.SendLoginCode(ctx, phone, channel)
+}
+
+// LoginWithPasskey signs a user in with a passkey alone. The authenticator must have
+// verified the user (PIN or biometrics), so the passkey counts as both factors.
+// Passkey logins skip the password lockout: an assertion cannot be guessed, and
+// honouring the lockout would let anyone who knows the email lock the owner out.
+func (s *AuthService) LoginWithPasskey(ctx context.Context, assertion dto.WebAuthnAssertion, userAgent, ipAddr string) (AuthResult, error) {
+	passkey, err := s.WebAuthn.VerifyAssertion(ctx, assertion, nil, true)
+	if err != nil {
+		_ = s.logLoginAttempt(ctx, nil, "", ipAddr, false, "passkey_invalid")
+		return AuthResult{}, err
+	}
+
+	user, err := s.UserRepo.GetByID(ctx, passkey.UserID)
+	if err != nil {
+		return AuthResult{}, errors.ErrWebAuthnVerificationFailed
+	}
+	if !user.EmailVerified {
+		return AuthResult{}, errors.ErrEmailNotVerified
+	}
+	switch user.Status {
+	case "locked":
+		return AuthResult{}, errors.ErrAccountLocked
+	case "disabled":
+		return AuthResult{}, errors.ErrAccountDisabled
+	case "deleted":
+		if !s.deactivationPending(ctx, user) {
+			return AuthResult{}, errors.ErrUserNotFound
+		}
+	}
+	if err := s.reactivate(ctx, user); err != nil {
+		return AuthResult{}, err
+	}
+
+	authResult, err := s.createSessionAndTokens(ctx, user, userAgent, ipAddr)
+	if err != nil {
+		_ = s.logLoginAttempt(ctx, &user.ID, user.Email, ipAddr, false, "session_creation_failed")
+		return AuthResult{}, err
+	}
+
+	_ = s.logLoginAttempt(ctx, &user.ID, user.Email, ipAddr, true, "success_passkey")
+	_ = s.UserRepo.UpdateLastLogin(ctx, user.ID, time.Now(), ipAddr)
+	_ = s.Lockout.RecordSuccess(ctx, user.Email)
+
+	return authResult, nil
+}
+
+// RequestPasswordlessLogin emails a single-use login link or 6-digit login code. The
+// response is the same whether or not the email belongs to an active, verified account,
+// so it cannot be used to find accounts, and the resend cooldown applies to every
+// address. When the client binds the login to its device, or the configuration requires
+// it, the response carries a device token that must be presented to complete the login.
+func (s *AuthService) RequestPasswordlessLogin(ctx context.Context, email, method string, bindDevice bool, ipAddr string) (*dto.PasswordlessLoginResponse, error) {
+	cfg := config.GetConfig()
+	if method == "" {
+		method = cache.PasswordlessMethodLink
+	}
+	ttl := passwordlessTTL(cfg.MagicLogin, method)
+
+	if err := s.Lockout.Check(ctx, email, ipAddr); err != nil {
+		_ = s.logLoginAttempt(ctx, nil, email, ipAddr, false, "locked_out")
+		return nil, err
+	}
+	retryAfter, err := s.Passwordless.ReserveSend(ctx, email, cfg.MagicLogin.ResendCooldown)
+	if err != nil {
+		return nil, err
+	}
+	if retryAfter > 0 {
+		return nil, errors.NewPasswordlessThrottledError(retryAfter)
+	}
+
+	resp := &dto.PasswordlessLoginResponse{
+		Method:    method,
+		ExpiresAt: time.Unix(time.Now().Add(ttl).Unix(), 0).UTC(),
+	}
+	challenge := cache.PasswordlessChallenge{Method: method, ExpiresAt: resp.ExpiresAt}
+	if bindDevice || cfg.MagicLogin.RequireDeviceBinding {
+		deviceToken, err := utils.GenerateSecureToken(32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate device token: %w", err)
+		}
+		resp.DeviceToken = deviceToken
+		challenge.DeviceHash = utils.HashToken(deviceToken)
+	}
+
+	user, err := s.UserRepo.GetUserByEmail(ctx, email)
+	if err != nil || !user.EmailVerified || user.Status != models.StatusActive {
+		return resp, nil
+	}
+
+	event := &events.PasswordlessLoginRequested{
+		Email:            user.Email,
+		Method:           method,
+		ExpiresInMinutes: int(ttl.Minutes()),
+	}
+	if profile, err := s.UserProfileRepo.GetByUserID(ctx, user.ID); err == nil && profile != nil {
+		event.Name = profile.DisplayName
+	}
+	switch method {
+	case cache.PasswordlessMethodCode:
+		code, err := generateOTPCode(6)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate login code: %w", err)
+		}
+		challenge.SecretHash = hashLoginCode(user.ID, code)
+		event.LoginCode = code
+	default:
+		token, signed, err := utils.NewLoginLinkToken(cfg.MagicLogin.SigningSecret, user.ID, resp.ExpiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate login link: %w", err)
+		}
+		challenge.SecretHash = utils.HashToken(token.Nonce)
+		event.LoginLink = fmt.Sprintf("%s/login/magic-link?token=%s", cfg.Email.FrontendURL, signed)
+	}
+
+	if err := s.Passwordless.StoreChallenge(ctx, user.ID, challenge, ttl); err != nil {
+		return nil, err
+	}
+
+	outboxEvent, err := newOutboxEvent(user.ID, event)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.OutboxRepo.Create(ctx, outboxEvent); err != nil {
+		return nil, fmt.Errorf("failed to create outbox event: %w", err)
+	}
+
+	s.logAuditEvent(ctx, &user.ID, "auth.passwordless_requested", map[string]any{
+		"method":       method,
+		"device_bound": challenge.DeviceHash != "",
+		"ip_addr":      utils.SanitizeIPAddress(ipAddr),
+	})
+	return resp, nil
+}
+
+// LoginWithPasswordless completes a passwordless login with the token of an emailed
+// link, or with the email and an emailed code, and establishes the same session as
+// Login. The second factor is still required when the user has one; the link or code is
+// only used up once everything else checked out, so a missing MFA code can be supplied
+// on a retry. Each check counts towards the attempts the pending login allows.
+func (s *AuthService) LoginWithPasswordless(ctx context.Context, req dto.PasswordlessVerifyRequest, userAgent, ipAddr string) (AuthResult, error) {
+	cfg := config.GetConfig().MagicLogin
+
+	method := cache.PasswordlessMethodLink
+	invalid := errors.ErrInvalidLoginLink
+	var userID uuid.UUID
+	var secretHash string
+	if req.Token != "" {
+		token, err := utils.ParseLoginLinkToken(cfg.SigningSecret, req.Token)
+		if err != nil {
+			_ = s.logLoginAttempt(ctx, nil, "", ipAddr, false, "passwordless_invalid")
+			return AuthResult{}, invalid
+		}
+		userID = token.UserID
+		secretHash = utils.HashToken(token.Nonce)
+	} else {
+		method = cache.PasswordlessMethodCode
+		invalid = errors.ErrInvalidLoginCode
+		user, err := s.UserRepo.GetUserByEmail(ctx, req.Email)
+		if err != nil {
+			_ = s.logLoginAttempt(ctx, nil, req.Email, ipAddr, false, "passwordless_invalid")
+			return AuthResult{}, invalid
+		}
+		userID = user.ID
+		secretHash = hashLoginCode(user.ID, req.Code)
+	}
+
+	user, err := s.UserRepo.GetByID(ctx, userID)
+	if err != nil {
+		return AuthResult{}, invalid
+	}
+	if err := s.Lockout.Check(ctx, user.Email, ipAddr); err != nil {
+		_ = s.logLoginAttempt(ctx, &user.ID, user.Email, ipAddr, false, "locked_out")
+		return AuthResult{}, err
+	}
+
+	challenge, err := s.Passwordless.GetChallenge(ctx, user.ID, cfg.MaxAttempts, passwordlessTTL(cfg, method))
+	if err != nil {
+		return AuthResult{}, err
+	}
+	if challenge == nil || challenge.Method != method || !hmac.Equal([]byte(challenge.SecretHash), []byte(secretHash)) {
+		_ = s.logLoginAttempt(ctx, &user.ID, user.Email, ipAddr, false, "passwordless_invalid")
+		return AuthResult{}, invalid
+	}
+	if challenge.DeviceHash != "" && !hmac.Equal([]byte(challenge.DeviceHash), []byte(utils.HashToken(req.DeviceToken))) {
+		_ = s.logLoginAttempt(ctx, &user.ID, user.Email, ipAddr, false, "passwordless_device_mismatch")
+		return AuthResult{}, errors.ErrLoginDeviceMismatch
+	}
+
+	if !user.EmailVerified {
+		return AuthResult{}, errors.ErrEmailNotVerified
+	}
+	switch user.Status {
+	case "locked":
+		return AuthResult{}, errors.ErrAccountLocked
+	case "disabled":
+		return AuthResult{}, errors.ErrAccountDisabled
+	case "deleted":
+		return AuthResult{}, errors.ErrUserNotFound
+	}
+
+	if err := s.verifyMFA(ctx, user, req.MFACode, req.WebAuthn, user.Email, ipAddr); err != nil {
+		return AuthResult{}, s.recordLoginFailure(ctx, err, user.Email, ipAddr)
+	}
+
+	// Losing this race means a concurrent request already signed in with the same login
+	consumed, err := s.Passwordless.ConsumeChallenge(ctx, user.ID, secretHash)
+	if err != nil {
+		return AuthResult{}, err
+	}
+	if !consumed {
+		return AuthResult{}, invalid
+	}
+
+	authResult, err := s.createSessionAndTokens(ctx, user, userAgent, ipAddr)
+	if err != nil {
+		_ = s.logLoginAttempt(ctx, &user.ID, user.Email, ipAddr, false, "session_creation_failed")
+		return AuthResult{}, err
+	}
+
+	_ = s.logLoginAttempt(ctx, &user.ID, user.Email, ipAddr, true, "success_passwordless")
+	_ = s.UserRepo.UpdateLastLogin(ctx, user.ID, time.Now(), ipAddr)
+	_ = s.Lockout.RecordSuccess(ctx, user.Email)
+
+	return authResult, nil
+}
+
+// passwordlessTTL is how long a login sent with method can be used.
+func passwordlessTTL(cfg config.PasswordlessConfig, method string) time.Duration {
+	if method == cache.PasswordlessMethodCode {
+		return cfg.CodeTTL
+	}
+	return cfg.LinkTTL
+}
+
+// hashLoginCode keys the code hash with the signing secret and the user, so stored hashes
+// cannot be checked offline or replayed against another account.
+func hashLoginCode(userID uuid.UUID, code string) string {
+	mac := hmac.New(sha256.New, []byte(config.GetConfig().MagicLogin.SigningSecret))
+	mac.Write([]byte("passwordless:" + userID.String() + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
 }
 
 // createSessionAndTokens creates a session and generates JWT tokens
@@ -281,6 +709,7 @@ func (s *AuthService) createSessionAndTokens(ctx context.Context, user *models.U
 		CreatedAt: time.Now(),
 		ExpiresAt: time.Now().Add(cfg.Session.Expiry),
 	}
+	s.SessionDescriber.Describe(ctx, session)
 
 	if err := s.SessionRepo.Create(ctx, session); err != nil {
 		return AuthResult{}, err
@@ -289,17 +718,16 @@ func (s *AuthService) createSessionAndTokens(ctx context.Context, user *models.U
 	// Store session in Redis
 	if err := s.storeSessionInCache(ctx, session, user, userAgent, ipAddr); err != nil {
 		// Log error but don't fail login
-		fmt.Printf("Warning: failed to store session in Redis: %v\n", err)
+		slog.WarnContext(ctx, "failed to store session in Redis", "error", err)
 	}
 
-	// Generate JWT token
-	accessToken, err := utils.GenerateJWT(user.ID, user.Email, session.ID)
+	// Generate a short-lived access token and the first refresh token of the session's family
+	accessToken, expiresAt, err := issueAccessToken(ctx, s.OrganizationRepo, user, session)
 	if err != nil {
 		return AuthResult{}, err
 	}
 
-	// Generate refresh token
-	refreshToken, err := s.createRefreshToken(ctx, session.ID)
+	refreshToken, err := issueRefreshToken(ctx, s.RefreshTokenRepo, session)
 	if err != nil {
 		return AuthResult{}, err
 	}
@@ -308,53 +736,37 @@ func (s *AuthService) createSessionAndTokens(ctx context.Context, user *models.U
 		User:         *user,
 		Token:        accessToken,
 		RefreshToken: refreshToken,
-		ExpiresAt:    time.Now().Add(cfg.JWT.ExpiresIn),
+		ExpiresAt:    expiresAt,
 	}, nil
 }
 
 // storeSessionInCache stores session data in Redis cache
 func (s *AuthService) storeSessionInCache(ctx context.Context, session *models.Session, user *models.User, userAgent, ipAddr string) error {
-	cfg := config.GetConfig()
-
 	sessionData := cache.SessionData{
 		UserID:    user.ID,
 		Email:     user.Email,
+		Role:      user.Role,
 		UserAgent: userAgent,
 		IPAddr:    utils.SanitizeIPAddress(ipAddr),
 		CreatedAt: session.CreatedAt,
 	}
 
-	return s.SessionCache.StoreSession(ctx, session.ID, sessionData, cfg.JWT.ExpiresIn)
-}
-
-// createRefreshToken creates and stores a refresh token
-func (s *AuthService) createRefreshToken(ctx context.Context, sessionID uuid.UUID) (string, error) {
-	cfg := config.GetConfig()
-
-	rawRefresh := uuid.NewString()
-	refreshHash, err := utils.HashPassword(rawRefresh)
+	// A failed lookup leaves the session unchecked rather than failing the login
+	consents, err := s.Consents.AcceptedAt(ctx, user.ID)
 	if err != nil {
-		return "", err
-	}
-
-	refresh := &models.RefreshToken{
-		SessionID: sessionID,
-		TokenHash: refreshHash,
-		IssuedAt:  time.Now(),
-		ExpiresAt: time.Now().Add(cfg.JWT.RefreshExpiresIn),
-	}
-
-	if err := s.RefreshTokenRepo.Create(ctx, refresh); err != nil {
-		return "", err
+		slog.WarnContext(ctx, "failed to load user consents", "user_id", user.ID, "error", err)
+	} else {
+		sessionData.Consents = consents
 	}
 
-	return rawRefresh, nil
+	// The session outlives individual access tokens; refreshes keep using it until it ends.
+	return s.SessionCache.StoreSession(ctx, session.ID, sessionData, time.Until(session.ExpiresAt))
 }
 
 // VerifyEmail verifies user's email with the provided token
 func (s *AuthService) VerifyEmail(ctx context.Context, token string) error {
 	if token == "" {
-		return errors.NewValidationError("Verification token is required").WithCode("TOKEN_REQUIRED")
+		return errors.NewValidationError("Verification token is required").WithReason("TOKEN_REQUIRED")
 	}
 
 	// 1. Hash the token
@@ -411,18 +823,14 @@ func (s *AuthService) logAuditEvent(ctx context.Context, userID *uuid.UUID, acti
 
 // sendWelcomeEmail creates an outbox event for welcome email
 func (s *AuthService) sendWelcomeEmail(ctx context.Context, user *models.User) {
-	payloadData := map[string]any{
-		"user_id": user.ID,
-		"email":   user.Email,
-		"name":    user.Profile.DisplayName,
-	}
-	payloadBytes, _ := json.Marshal(payloadData)
-	outboxEvent := &models.Outbox{
-		AggregateID: user.ID,
-		Topic:       "user.created",
-		Type:        "UserCreated",
-		Payload:     payloadBytes,
-		CreatedAt:   time.Now(),
+	outboxEvent, err := newOutboxEvent(user.ID, &events.UserCreated{
+		UserID: user.ID,
+		Email:  user.Email,
+		Name:   user.Profile.DisplayName,
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "failed to build welcome email event", "user_id", user.ID, "error", err)
+		return
 	}
 	_ = s.OutboxRepo.Create(ctx, outboxEvent)
 }
@@ -440,5 +848,49 @@ func (s *AuthService) logLoginAttempt(ctx context.Context, userID *uuid.UUID, em
 		Success: success,
 		Reason:  reason,
 	}
+
+	result := "failure"
+	if success {
+		result = "success"
+	}
+	metrics.AuthAttemptsTotal.WithLabelValues(result, reason).Inc()
+
+	// Attempts against a known account also go to its audit trail
+	if userID != nil {
+		action := "auth.login_failed"
+		if success {
+			action = "auth.login_succeeded"
+		}
+		s.logAuditEvent(ctx, userID, action, map[string]any{"reason": reason})
+	}
+	if !success {
+		s.emitLoginFailure(ctx, userID, email, ip, reason)
+	}
+
 	return s.LoginAttemptRepo.Create(ctx, attempt)
 }
+
+// emitLoginFailure reports a failed login to the security event stream. Being asked for
+// the second factor is part of a normal login, and a failure to create the session is
+// not the client's doing, so neither is reported.
+func (s *AuthService) emitLoginFailure(ctx context.Context, userID *uuid.UUID, email, ip, reason string) {
+	event := SecurityEvent{
+		Type:     SecurityEventLoginFailed,
+		Severity: SecuritySeverityLow,
+		Outcome:  "failure",
+		UserID:   userID,
+		Email:    email,
+		IPAddr:   ip,
+		Reason:   reason,
+	}
+	switch reason {
+	case "mfa_required", "session_creation_failed":
+		return
+	case "mfa_invalid":
+		event.Type = SecurityEventMFAFailed
+		event.Severity = SecuritySeverityMedium
+	case "locked_out":
+		event.Severity = SecuritySeverityMedium
+	}
+	s.SecurityEvents.Emit(ctx, event)
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/repositories"
+	"user-services/internal/config"
+	"user-services/internal/errors"
+	"user-services/internal/imaging"
+	"user-services/internal/models"
+	"user-services/internal/storage"
+
+	"github.com/ductan2/microservice-app/shared/ids"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxPendingAvatarUploads caps the uploads a user may have in progress at once.
+	maxPendingAvatarUploads = 5
+	// avatarDeletionBatchSize is how many queued objects one cleanup run deletes.
+	avatarDeletionBatchSize = 100
+	// avatarCacheControl lets browsers and CDNs keep an avatar forever; every avatar is
+	// stored under a new key, so a cached one is never stale.
+	avatarCacheControl = "public, max-age=31536000, immutable"
+)
+
+// AvatarService handles avatar uploads. The client asks for an upload, sends the image
+// straight to the object store with the presigned URL it gets back, and then completes
+// the upload: the image is checked, cropped and scaled to a square JPEG, stored under
+// avatars/ and set as the profile's avatar. The original upload and any replaced avatar
+// are deleted by ProcessCleanup.
+type AvatarService interface {
+	CreateUpload(ctx context.Context, userID uuid.UUID, req dto.CreateAvatarUploadRequest) (*dto.AvatarUploadResponse, error)
+	CompleteUpload(ctx context.Context, userID, uploadID uuid.UUID) (*dto.AvatarResponse, error)
+	RemoveAvatar(ctx context.Context, userID uuid.UUID) error
+	// ProcessCleanup expires abandoned uploads and deletes the queued objects.
+	ProcessCleanup(ctx context.Context) error
+}
+
+type avatarService struct {
+	avatarRepo   repositories.AvatarRepository
+	profileRepo  repositories.UserProfileRepository
+	auditLogRepo repositories.AuditLogRepository
+	// store is nil when avatar storage is not configured
+	store *storage.S3Client
+	cfg   config.AvatarConfig
+}
+
+func NewAvatarService(
+	avatarRepo repositories.AvatarRepository,
+	profileRepo repositories.UserProfileRepository,
+	auditLogRepo repositories.AuditLogRepository,
+	store *storage.S3Client,
+	cfg config.AvatarConfig,
+) AvatarService {
+	return &avatarService{
+		avatarRepo:   avatarRepo,
+		profileRepo:  profileRepo,
+		auditLogRepo: auditLogRepo,
+		store:        store,
+		cfg:          cfg,
+	}
+}
+
+func (s *avatarService) CreateUpload(ctx context.Context, userID uuid.UUID, req dto.CreateAvatarUploadRequest) (*dto.AvatarUploadResponse, error) {
+	if s.store == nil {
+		return nil, errors.ErrAvatarStorageDisabled
+	}
+	if req.SizeBytes > s.cfg.MaxBytes {
+		return nil, errors.NewAvatarTooLargeError(s.cfg.MaxBytes)
+	}
+	if _, err := s.profileRepo.GetByUserID(ctx, userID); err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	pending, err := s.avatarRepo.CountPendingUploads(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if pending >= maxPendingAvatarUploads {
+		return nil, errors.ErrAvatarUploadsThrottled
+	}
+
+	// The upload may be completed for a while after the URL expires, so an upload that
+	// finishes just before the deadline can still be completed
+	uploadID := ids.New()
+	upload := &models.AvatarUpload{
+		ID:          uploadID,
+		UserID:      userID,
+		ObjectKey:   fmt.Sprintf("uploads/avatars/%s/%s", userID, uploadID),
+		ContentType: req.ContentType,
+		SizeBytes:   req.SizeBytes,
+		Status:      models.AvatarUploadStatusPending,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(2 * s.cfg.UploadTTL),
+	}
+	if err := s.avatarRepo.CreateUpload(ctx, upload); err != nil {
+		return nil, err
+	}
+
+	uploadURL, headers, err := s.store.PresignPut(ctx, upload.ObjectKey, req.ContentType, req.SizeBytes, s.cfg.UploadTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.AvatarUploadResponse{
+		UploadID:  uploadID,
+		UploadURL: uploadURL,
+		Method:    http.MethodPut,
+		Headers:   headers,
+		ExpiresAt: now.Add(s.cfg.UploadTTL),
+	}, nil
+}
+
+func (s *avatarService) CompleteUpload(ctx context.Context, userID, uploadID uuid.UUID) (*dto.AvatarResponse, error) {
+	if s.store == nil {
+		return nil, errors.ErrAvatarStorageDisabled
+	}
+
+	upload, err := s.avatarRepo.GetUpload(ctx, uploadID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrAvatarUploadNotFound
+		}
+		return nil, err
+	}
+	if upload.UserID != userID {
+		return nil, errors.ErrAvatarUploadNotFound
+	}
+	if upload.Status != models.AvatarUploadStatusPending || time.Now().After(upload.ExpiresAt) {
+		return nil, errors.ErrAvatarUploadClosed
+	}
+
+	data, err := s.store.Get(ctx, upload.ObjectKey, s.cfg.MaxBytes)
+	switch {
+	case stderrors.Is(err, storage.ErrObjectNotFound):
+		// The upload stays pending so the client can retry once the image is uploaded
+		return nil, errors.ErrAvatarNotUploaded
+	case stderrors.Is(err, storage.ErrObjectTooLarge):
+		return nil, s.reject(ctx, upload, errors.NewAvatarTooLargeError(s.cfg.MaxBytes))
+	case err != nil:
+		slog.WarnContext(ctx, "failed to download avatar upload", "upload_id", upload.ID, "error", err)
+		return nil, errors.ErrAvatarStorageFailed
+	}
+
+	avatar, err := imaging.SquareAvatar(data, s.cfg.Size, imaging.Limits{
+		MinDimension: s.cfg.MinDimension,
+		MaxDimension: s.cfg.MaxDimension,
+	})
+	if err != nil {
+		return nil, s.reject(ctx, upload, errors.NewInvalidAvatarError(avatarRejectionReason(err, s.cfg)))
+	}
+
+	// The key is derived from the upload, so two concurrent completions of the same
+	// upload write the same object and only one of them is applied below
+	key := fmt.Sprintf("avatars/%s/%s.jpg", userID, upload.ID)
+	if err := s.store.Put(ctx, key, avatar, "image/jpeg", avatarCacheControl); err != nil {
+		slog.WarnContext(ctx, "failed to store avatar", "key", key, "error", err)
+		return nil, errors.ErrAvatarStorageFailed
+	}
+
+	url := s.publicURL(key)
+	completed, err := s.avatarRepo.CompleteUpload(ctx, upload, key, url)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, err
+	}
+	if !completed {
+		return nil, errors.ErrAvatarUploadClosed
+	}
+
+	if err := s.auditLogRepo.Create(ctx, &models.AuditLog{
+		UserID:    &userID,
+		Action:    "profile.avatar_updated",
+		Metadata:  map[string]any{"upload_id": upload.ID, "size_bytes": len(avatar)},
+		CreatedAt: time.Now(),
+	}); err != nil {
+		slog.WarnContext(ctx, "failed to write profile.avatar_updated audit log", "error", err)
+	}
+
+	return &dto.AvatarResponse{AvatarURL: url}, nil
+}
+
+// reject marks the upload failed, which queues the uploaded object for deletion, and
+// returns appErr for the client.
+func (s *avatarService) reject(ctx context.Context, upload *models.AvatarUpload, appErr *errors.AppError) error {
+	if err := s.avatarRepo.FailUpload(ctx, upload.ID, appErr.ErrorCode()); err != nil {
+		return err
+	}
+	return appErr
+}
+
+func (s *avatarService) RemoveAvatar(ctx context.Context, userID uuid.UUID) error {
+	if err := s.avatarRepo.SetAvatarURL(ctx, userID, ""); err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.ErrUserNotFound
+		}
+		return err
+	}
+
+	if err := s.auditLogRepo.Create(ctx, &models.AuditLog{
+		UserID:    &userID,
+		Action:    "profile.avatar_removed",
+		CreatedAt: time.Now(),
+	}); err != nil {
+		slog.WarnContext(ctx, "failed to write profile.avatar_removed audit log", "error", err)
+	}
+	return nil
+}
+
+func (s *avatarService) ProcessCleanup(ctx context.Context) error {
+	now := time.Now()
+
+	if _, err := s.avatarRepo.ExpireUploads(ctx, now); err != nil {
+		return fmt.Errorf("failed to expire avatar uploads: %w", err)
+	}
+	if s.store == nil {
+		return nil
+	}
+
+	deletions, err := s.avatarRepo.ListDueDeletions(ctx, now, avatarDeletionBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to list avatar deletions: %w", err)
+	}
+	for _, deletion := range deletions {
+		if err := s.store.Delete(ctx, deletion.ObjectKey); err != nil {
+			// Back off exponentially, up to about an hour between attempts
+			retryAt := now.Add(time.Minute << min(deletion.Attempts, 6))
+			if err := s.avatarRepo.RetryDeletion(ctx, deletion.ObjectKey, err.Error(), retryAt); err != nil {
+				return fmt.Errorf("failed to reschedule avatar deletion %s: %w", deletion.ObjectKey, err)
+			}
+			continue
+		}
+		if err := s.avatarRepo.DeleteDeletion(ctx, deletion.ObjectKey); err != nil {
+			return fmt.Errorf("failed to dequeue avatar deletion %s: %w", deletion.ObjectKey, err)
+		}
+	}
+	return nil
+}
+
+// publicURL is where key is served from: under AVATAR_PUBLIC_BASE_URL when set, otherwise
+// straight from the bucket.
+func (s *avatarService) publicURL(key string) string {
+	if s.cfg.PublicBaseURL != "" {
+		return strings.TrimRight(s.cfg.PublicBaseURL, "/") + "/" + key
+	}
+	return s.store.ObjectURL(key)
+}
+
+func avatarRejectionReason(err error, cfg config.AvatarConfig) string {
+	switch {
+	case stderrors.Is(err, imaging.ErrTooSmall):
+		return fmt.Sprintf("image must be at least %dx%d pixels", cfg.MinDimension, cfg.MinDimension)
+	case stderrors.Is(err, imaging.ErrTooLarge):
+		return fmt.Sprintf("image must be at most %dx%d pixels", cfg.MaxDimension, cfg.MaxDimension)
+	case stderrors.Is(err, imaging.ErrUnsupportedFormat):
+		return err.Error()
+	default:
+		return "image could not be processed"
+	}
+}
@@ -0,0 +1,333 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/repositories"
+	"user-services/internal/audit"
+	"user-services/internal/cache"
+	"user-services/internal/errors"
+	"user-services/internal/models"
+	"user-services/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// ConsentService tracks which versions of the terms of service and privacy policy each
+// user accepted. Publishing a version that requires consent writes the documents users
+// must have accepted to Redis, where the BFF compares them with the acceptances recorded
+// in each session and asks users for consent again before they can keep using the app.
+type ConsentService interface {
+	// ListPolicies returns the current version of every published document.
+	ListPolicies(ctx context.Context) ([]dto.PolicyVersionResponse, error)
+	ListPolicyVersions(ctx context.Context, document string) ([]dto.PolicyVersionResponse, error)
+	PublishPolicy(ctx context.Context, req dto.PublishPolicyRequest) (*dto.PolicyVersionResponse, error)
+	GetConsents(ctx context.Context, userID uuid.UUID) (*dto.ConsentStatusResponse, error)
+	// RecordConsent records the user accepting the current version of the documents and
+	// updates the user's sessions so they are let through right away.
+	RecordConsent(ctx context.Context, userID uuid.UUID, req dto.RecordConsentRequest) (*dto.ConsentStatusResponse, error)
+	// AcceptedAt returns when the user last accepted each document, for new sessions.
+	AcceptedAt(ctx context.Context, userID uuid.UUID) (map[string]time.Time, error)
+	// SyncRequiredPolicies writes the documents users must have accepted to Redis.
+	SyncRequiredPolicies(ctx context.Context) error
+}
+
+type consentService struct {
+	consentRepo  repositories.ConsentRepository
+	sessionRepo  repositories.SessionRepository
+	auditLogRepo repositories.AuditLogRepository
+	sessionCache *cache.SessionCache
+}
+
+func NewConsentService(
+	consentRepo repositories.ConsentRepository,
+	sessionRepo repositories.SessionRepository,
+	auditLogRepo repositories.AuditLogRepository,
+	sessionCache *cache.SessionCache,
+) ConsentService {
+	return &consentService{
+		consentRepo:  consentRepo,
+		sessionRepo:  sessionRepo,
+		auditLogRepo: auditLogRepo,
+		sessionCache: sessionCache,
+	}
+}
+
+func (s *consentService) ListPolicies(ctx context.Context) ([]dto.PolicyVersionResponse, error) {
+	policies, err := s.consentRepo.ListCurrentPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return toPolicyVersionResponses(policies), nil
+}
+
+func (s *consentService) ListPolicyVersions(ctx context.Context, document string) ([]dto.PolicyVersionResponse, error) {
+	policies, err := s.consentRepo.ListPolicyVersions(ctx, document)
+	if err != nil {
+		return nil, err
+	}
+	return toPolicyVersionResponses(policies), nil
+}
+
+func (s *consentService) PublishPolicy(ctx context.Context, req dto.PublishPolicyRequest) (*dto.PolicyVersionResponse, error) {
+	policy := &models.PolicyVersion{
+		Document:        req.Document,
+		Version:         strings.TrimSpace(req.Version),
+		URL:             req.URL,
+		Summary:         strings.TrimSpace(req.Summary),
+		RequiresConsent: req.RequiresConsent == nil || *req.RequiresConsent,
+		PublishedAt:     time.Now(),
+	}
+	if policy.Version == "" {
+		return nil, errors.NewValidationError("Version must not be blank")
+	}
+	if info, ok := audit.FromContext(ctx); ok {
+		policy.PublishedBy = info.ActorID
+	}
+
+	created, err := s.consentRepo.PublishPolicy(ctx, policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish policy: %w", err)
+	}
+	if !created {
+		return nil, errors.ErrPolicyVersionExists
+	}
+
+	if err := s.SyncRequiredPolicies(ctx); err != nil {
+		slog.WarnContext(ctx, "failed to sync required policies", "error", err)
+	}
+
+	s.audit(ctx, nil, "policy.published", map[string]any{
+		"policy_id":        policy.ID,
+		"document":         policy.Document,
+		"version":          policy.Version,
+		"requires_consent": policy.RequiresConsent,
+	})
+
+	resp := toPolicyVersionResponse(*policy)
+	return &resp, nil
+}
+
+func (s *consentService) GetConsents(ctx context.Context, userID uuid.UUID) (*dto.ConsentStatusResponse, error) {
+	current, err := s.consentRepo.ListCurrentPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	required, err := s.requiredSince(ctx)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := s.consentRepo.ListLatestConsents(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	history, err := s.consentRepo.ListConsentHistory(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	accepted := make(map[string]models.UserConsent, len(latest))
+	for _, consent := range latest {
+		accepted[consent.Document] = consent
+	}
+
+	resp := &dto.ConsentStatusResponse{
+		Documents: make([]dto.DocumentConsentResponse, 0, len(current)),
+		History:   make([]dto.ConsentRecordResponse, 0, len(history)),
+	}
+	for _, policy := range current {
+		doc := dto.DocumentConsentResponse{
+			Document:       policy.Document,
+			CurrentVersion: policy.Version,
+			URL:            policy.URL,
+			Summary:        policy.Summary,
+		}
+		consent, ok := accepted[policy.Document]
+		if ok {
+			version, acceptedAt := consent.Version, consent.AcceptedAt
+			doc.AcceptedVersion, doc.AcceptedAt = &version, &acceptedAt
+		}
+		if since, ok := required[policy.Document]; ok && (doc.AcceptedAt == nil || doc.AcceptedAt.Before(since)) {
+			doc.ConsentRequired = true
+			resp.ConsentRequired = true
+		}
+		resp.Documents = append(resp.Documents, doc)
+	}
+	for _, consent := range history {
+		resp.History = append(resp.History, dto.ConsentRecordResponse{
+			Document:   consent.Document,
+			Version:    consent.Version,
+			AcceptedAt: consent.AcceptedAt,
+		})
+	}
+	return resp, nil
+}
+
+// RecordConsent only accepts the current version of a document, so a user cannot agree to
+// terms that were replaced while they were reading them.
+func (s *consentService) RecordConsent(ctx context.Context, userID uuid.UUID, req dto.RecordConsentRequest) (*dto.ConsentStatusResponse, error) {
+	current, err := s.consentRepo.ListCurrentPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	currentVersions := make(map[string]string, len(current))
+	for _, policy := range current {
+		currentVersions[policy.Document] = policy.Version
+	}
+
+	info, _ := audit.FromContext(ctx)
+	var ipAddr *string
+	if sanitized := utils.SanitizeIPAddress(info.IPAddr); sanitized != "" {
+		ipAddr = &sanitized
+	}
+
+	now := time.Now()
+	consents := make([]models.UserConsent, 0, len(req.Consents))
+	seen := make(map[string]bool, len(req.Consents))
+	for _, item := range req.Consents {
+		if seen[item.Document] {
+			return nil, errors.NewValidationError("Each document can only be accepted once per request")
+		}
+		seen[item.Document] = true
+
+		version, ok := currentVersions[item.Document]
+		if !ok {
+			return nil, errors.ErrPolicyNotFound
+		}
+		if strings.TrimSpace(item.Version) != version {
+			return nil, errors.ErrPolicyVersionOutdated
+		}
+		consents = append(consents, models.UserConsent{
+			UserID:     userID,
+			Document:   item.Document,
+			Version:    version,
+			AcceptedAt: now,
+			IPAddr:     ipAddr,
+			UserAgent:  info.UserAgent,
+		})
+	}
+
+	if err := s.consentRepo.RecordConsents(ctx, consents); err != nil {
+		return nil, fmt.Errorf("failed to record consent: %w", err)
+	}
+	for _, consent := range consents {
+		s.audit(ctx, &userID, "user.consent_recorded", map[string]any{
+			"document": consent.Document,
+			"version":  consent.Version,
+		})
+	}
+
+	if err := s.refreshSessions(ctx, userID); err != nil {
+		slog.WarnContext(ctx, "failed to update consents in user sessions", "user_id", userID, "error", err)
+	}
+
+	return s.GetConsents(ctx, userID)
+}
+
+func (s *consentService) AcceptedAt(ctx context.Context, userID uuid.UUID) (map[string]time.Time, error) {
+	latest, err := s.consentRepo.ListLatestConsents(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	accepted := make(map[string]time.Time, len(latest))
+	for _, consent := range latest {
+		accepted[consent.Document] = consent.AcceptedAt
+	}
+	return accepted, nil
+}
+
+func (s *consentService) SyncRequiredPolicies(ctx context.Context) error {
+	current, err := s.consentRepo.ListCurrentPolicies(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load current policies: %w", err)
+	}
+	required, err := s.requiredSince(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load required policies: %w", err)
+	}
+
+	policies := make(map[string]cache.RequiredPolicy, len(required))
+	for _, policy := range current {
+		since, ok := required[policy.Document]
+		if !ok {
+			continue
+		}
+		policies[policy.Document] = cache.RequiredPolicy{
+			Version:       policy.Version,
+			URL:           policy.URL,
+			RequiredSince: since,
+		}
+	}
+	return s.sessionCache.SetRequiredPolicies(ctx, policies)
+}
+
+// requiredSince returns, per document, when the latest version users had to accept was
+// published. Acceptances from before then no longer count.
+func (s *consentService) requiredSince(ctx context.Context) (map[string]time.Time, error) {
+	policies, err := s.consentRepo.ListRequiredPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	since := make(map[string]time.Time, len(policies))
+	for _, policy := range policies {
+		since[policy.Document] = policy.PublishedAt
+	}
+	return since, nil
+}
+
+// refreshSessions copies the user's acceptances into their active sessions.
+func (s *consentService) refreshSessions(ctx context.Context, userID uuid.UUID) error {
+	accepted, err := s.AcceptedAt(ctx, userID)
+	if err != nil {
+		return err
+	}
+	sessions, err := s.sessionRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	sessionIDs := make([]uuid.UUID, 0, len(sessions))
+	for _, session := range sessions {
+		if !session.RevokedAt.Valid && session.ExpiresAt.After(now) {
+			sessionIDs = append(sessionIDs, session.ID)
+		}
+	}
+	return s.sessionCache.SetSessionConsents(ctx, sessionIDs, accepted)
+}
+
+func (s *consentService) audit(ctx context.Context, userID *uuid.UUID, action string, metadata map[string]any) {
+	entry := &models.AuditLog{
+		UserID:    userID,
+		Action:    action,
+		Metadata:  metadata,
+		CreatedAt: time.Now(),
+	}
+	if err := s.auditLogRepo.Create(ctx, entry); err != nil {
+		slog.WarnContext(ctx, "failed to write audit log", "action", action, "error", err)
+	}
+}
+
+func toPolicyVersionResponses(policies []models.PolicyVersion) []dto.PolicyVersionResponse {
+	responses := make([]dto.PolicyVersionResponse, 0, len(policies))
+	for _, policy := range policies {
+		responses = append(responses, toPolicyVersionResponse(policy))
+	}
+	return responses
+}
+
+func toPolicyVersionResponse(policy models.PolicyVersion) dto.PolicyVersionResponse {
+	return dto.PolicyVersionResponse{
+		Document:        policy.Document,
+		Version:         policy.Version,
+		URL:             policy.URL,
+		Summary:         policy.Summary,
+		RequiresConsent: policy.RequiresConsent,
+		PublishedAt:     policy.PublishedAt,
+	}
+}
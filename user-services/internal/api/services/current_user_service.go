@@ -6,11 +6,17 @@ import (
 	"user-services/internal/api/dto"
 	"user-services/internal/api/repositories"
 	"user-services/internal/models"
+
+	"github.com/ductan2/microservice-app/shared/batch"
+	"github.com/google/uuid"
 )
 
 type CurrentUserService interface {
 	GetPublicUserByID(ctx context.Context, id string) (dto.PublicUser, error)
 	IsAdmin(ctx context.Context, id string) (bool, error)
+	// BatchGetPublicUsers resolves the users of ids, checked by batch.IDs, in one query.
+	// IDs that are not UUIDs are reported missing.
+	BatchGetPublicUsers(ctx context.Context, ids []string) (batch.Response[dto.PublicUser], error)
 }
 
 type currentUserService struct {
@@ -36,3 +42,24 @@ func (s *currentUserService) IsAdmin(ctx context.Context, id string) (bool, erro
 	}
 	return user.Role == models.RoleAdmin || user.Role == models.RoleSuperAdmin, nil
 }
+
+func (s *currentUserService) BatchGetPublicUsers(ctx context.Context, ids []string) (batch.Response[dto.PublicUser], error) {
+	// requested maps each user ID to the way the caller spelled it
+	requested := make(map[uuid.UUID]string, len(ids))
+	userIDs := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if userID, err := uuid.Parse(id); err == nil {
+			requested[userID] = id
+			userIDs = append(userIDs, userID)
+		}
+	}
+	users, err := s.userRepo.GetByIDs(ctx, userIDs)
+	if err != nil {
+		return batch.Response[dto.PublicUser]{}, err
+	}
+	found := make([]dto.PublicUser, len(users))
+	for i := range users {
+		found[i] = toPublicUser(users[i])
+	}
+	return batch.Collect(ids, found, func(user dto.PublicUser) string { return requested[user.ID] }), nil
+}
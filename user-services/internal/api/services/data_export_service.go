@@ -0,0 +1,587 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/repositories"
+	"user-services/internal/config"
+	"user-services/internal/errors"
+	"user-services/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// dataExportBuildLease bounds how long one build may hold a job before another
+	// processor run picks it up again.
+	dataExportBuildLease = 15 * time.Minute
+	// dataExportBatchSize is the page size used to read long histories and due jobs.
+	dataExportBatchSize = 500
+	// dataExportListLimit caps how many past exports a user sees.
+	dataExportListLimit = 10
+)
+
+// DataExportService runs self-service data exports (takeout). A request records a job and
+// publishes a user.data_export_requested event asking every configured source service
+// for its share of the user's data; each posts it back with ReceivePart. ProcessDue then
+// builds a zip archive of the user's account, profile, sessions, activity sessions, MFA
+// methods, policy consents and audit log plus every part received, once all parts are in or the collect
+// timeout passes. Archives are deleted after DATA_EXPORT_TTL.
+type DataExportService interface {
+	RequestExport(ctx context.Context, userID uuid.UUID) (*dto.DataExportResponse, bool, error)
+	ListExports(ctx context.Context, userID uuid.UUID) ([]dto.DataExportResponse, error)
+	GetExport(ctx context.Context, userID, exportID uuid.UUID) (*dto.DataExportResponse, error)
+	// OpenArchive returns the path of a ready archive and the file name to download it as.
+	OpenArchive(ctx context.Context, userID, exportID uuid.UUID) (string, string, error)
+	ReceivePart(ctx context.Context, exportID uuid.UUID, source string, data json.RawMessage) error
+	// ProcessDue builds the jobs that are ready and deletes expired archives.
+	ProcessDue(ctx context.Context) error
+}
+
+type dataExportService struct {
+	exportRepo          repositories.DataExportRepository
+	userRepo            repositories.UserRepository
+	sessionRepo         repositories.SessionRepository
+	activitySessionRepo repositories.ActivitySessionRepository
+	mfaRepo             repositories.MFARepository
+	consentRepo         repositories.ConsentRepository
+	auditLogRepo        repositories.AuditLogRepository
+	outboxRepo          repositories.OutboxRepository
+	cfg                 config.DataExportConfig
+}
+
+func NewDataExportService(
+	exportRepo repositories.DataExportRepository,
+	userRepo repositories.UserRepository,
+	sessionRepo repositories.SessionRepository,
+	activitySessionRepo repositories.ActivitySessionRepository,
+	mfaRepo repositories.MFARepository,
+	consentRepo repositories.ConsentRepository,
+	auditLogRepo repositories.AuditLogRepository,
+	outboxRepo repositories.OutboxRepository,
+	cfg config.DataExportConfig,
+) DataExportService {
+	return &dataExportService{
+		exportRepo:          exportRepo,
+		userRepo:            userRepo,
+		sessionRepo:         sessionRepo,
+		activitySessionRepo: activitySessionRepo,
+		mfaRepo:             mfaRepo,
+		consentRepo:         consentRepo,
+		auditLogRepo:        auditLogRepo,
+		outboxRepo:          outboxRepo,
+		cfg:                 cfg,
+	}
+}
+
+// RequestExport starts an export for the user. While a previous export is still being
+// collected or built it is returned instead and created is false; a new export within
+// DATA_EXPORT_COOLDOWN of the last successful one is refused.
+func (s *dataExportService) RequestExport(ctx context.Context, userID uuid.UUID) (*dto.DataExportResponse, bool, error) {
+	latest, err := s.exportRepo.GetLatestByUserID(ctx, userID)
+	if err != nil && !stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+	if latest != nil {
+		switch latest.Status {
+		case models.DataExportStatusCollecting, models.DataExportStatusBuilding:
+			resp, err := s.toResponse(ctx, *latest)
+			return resp, false, err
+		case models.DataExportStatusReady, models.DataExportStatusExpired:
+			if wait := time.Until(latest.RequestedAt.Add(s.cfg.Cooldown)); wait > 0 {
+				return nil, false, errors.NewDataExportTooSoonError(wait)
+			}
+		}
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, errors.ErrUserNotFound
+		}
+		return nil, false, err
+	}
+
+	now := time.Now()
+	export := &models.DataExport{
+		UserID:          userID,
+		Status:          models.DataExportStatusCollecting,
+		RequestedAt:     now,
+		CollectDeadline: now.Add(s.cfg.CollectTimeout),
+	}
+	if err := s.exportRepo.Create(ctx, export, s.cfg.Sources); err != nil {
+		return nil, false, fmt.Errorf("failed to create data export: %w", err)
+	}
+
+	if len(s.cfg.Sources) > 0 {
+		if err := s.publishRequested(ctx, export, user.Email); err != nil {
+			if markErr := s.exportRepo.MarkFailed(ctx, export.ID, "could not notify source services"); markErr != nil {
+				slog.WarnContext(ctx, "failed to mark data export failed", "export_id", export.ID, "error", markErr)
+			}
+			return nil, false, err
+		}
+	}
+
+	s.audit(ctx, userID, "data_export.requested", map[string]any{"export_id": export.ID})
+
+	resp, err := s.toResponse(ctx, *export)
+	return resp, true, err
+}
+
+func (s *dataExportService) publishRequested(ctx context.Context, export *models.DataExport, email string) error {
+	payload, err := json.Marshal(map[string]any{
+		"export_id":     export.ID,
+		"user_id":       export.UserID,
+		"email":         email,
+		"sources":       s.cfg.Sources,
+		"callback_path": fmt.Sprintf("/api/v1/internal/data-exports/%s/parts", export.ID),
+		"deadline":      export.CollectDeadline.UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	event := &models.Outbox{
+		AggregateID: export.UserID,
+		Topic:       "user.data_export_requested",
+		Type:        "DataExportRequested",
+		Payload:     payload,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.outboxRepo.Create(ctx, event); err != nil {
+		return fmt.Errorf("failed to create outbox event: %w", err)
+	}
+	return nil
+}
+
+func (s *dataExportService) ListExports(ctx context.Context, userID uuid.UUID) ([]dto.DataExportResponse, error) {
+	exports, err := s.exportRepo.ListByUserID(ctx, userID, dataExportListLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.DataExportResponse, 0, len(exports))
+	for _, export := range exports {
+		resp, err := s.toResponse(ctx, export)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *resp)
+	}
+	return responses, nil
+}
+
+func (s *dataExportService) GetExport(ctx context.Context, userID, exportID uuid.UUID) (*dto.DataExportResponse, error) {
+	export, err := s.getOwned(ctx, userID, exportID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponse(ctx, *export)
+}
+
+func (s *dataExportService) OpenArchive(ctx context.Context, userID, exportID uuid.UUID) (string, string, error) {
+	export, err := s.getOwned(ctx, userID, exportID)
+	if err != nil {
+		return "", "", err
+	}
+
+	switch {
+	case export.Status == models.DataExportStatusExpired,
+		export.Status == models.DataExportStatusReady && export.ExpiresAt.Valid && time.Now().After(export.ExpiresAt.Time):
+		return "", "", errors.ErrDataExportExpired
+	case export.Status != models.DataExportStatusReady:
+		return "", "", errors.ErrDataExportNotReady
+	}
+
+	if _, err := os.Stat(export.FilePath); err != nil {
+		return "", "", fmt.Errorf("data export archive unavailable: %w", err)
+	}
+
+	s.audit(ctx, userID, "data_export.downloaded", map[string]any{"export_id": export.ID})
+
+	return export.FilePath, fmt.Sprintf("data-export-%s.zip", export.RequestedAt.UTC().Format("20060102")), nil
+}
+
+// ReceivePart stores a source service's share of an export. Parts for unknown sources or
+// for jobs no longer collecting are rejected; a source may resend its part until then.
+func (s *dataExportService) ReceivePart(ctx context.Context, exportID uuid.UUID, source string, data json.RawMessage) error {
+	if !json.Valid(data) {
+		return errors.NewValidationError("data must be valid JSON")
+	}
+
+	saved, err := s.exportRepo.SavePart(ctx, exportID, source, data)
+	if err != nil {
+		return err
+	}
+	if !saved {
+		if _, err := s.exportRepo.GetByID(ctx, exportID); err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.ErrDataExportNotFound
+			}
+			return err
+		}
+		return errors.NewConflictError("Data export is not waiting for this source").WithReason("DATA_EXPORT_PART_REJECTED")
+	}
+	return nil
+}
+
+func (s *dataExportService) ProcessDue(ctx context.Context) error {
+	now := time.Now()
+
+	due, err := s.exportRepo.ListDue(ctx, now, dataExportBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to list due data exports: %w", err)
+	}
+	for _, export := range due {
+		claimed, err := s.exportRepo.Claim(ctx, export.ID, now, dataExportBuildLease)
+		if err != nil {
+			return fmt.Errorf("failed to claim data export %s: %w", export.ID, err)
+		}
+		if !claimed {
+			continue
+		}
+		if err := s.build(ctx, export); err != nil {
+			slog.WarnContext(ctx, "failed to build data export", "export_id", export.ID, "error", err)
+			if err := s.exportRepo.MarkFailed(ctx, export.ID, "archive could not be built"); err != nil {
+				return fmt.Errorf("failed to mark data export %s failed: %w", export.ID, err)
+			}
+		}
+	}
+
+	expired, err := s.exportRepo.ListExpired(ctx, now, dataExportBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to list expired data exports: %w", err)
+	}
+	for _, export := range expired {
+		if export.FilePath != "" {
+			if err := os.Remove(export.FilePath); err != nil && !os.IsNotExist(err) {
+				slog.WarnContext(ctx, "failed to delete data export archive", "path", export.FilePath, "error", err)
+				continue
+			}
+		}
+		if err := s.exportRepo.MarkExpired(ctx, export.ID); err != nil {
+			return fmt.Errorf("failed to mark data export %s expired: %w", export.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// build writes the archive to a temporary file and renames it into place, so a ready job
+// never points at a partial archive.
+func (s *dataExportService) build(ctx context.Context, export models.DataExport) error {
+	files, missing, err := s.collect(ctx, export)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.cfg.Dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+	path := filepath.Join(s.cfg.Dir, export.ID.String()+".zip")
+	tmp := path + ".tmp"
+
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer os.Remove(tmp)
+
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, f.name)
+	}
+	manifest := map[string]any{
+		"export_id":       export.ID,
+		"user_id":         export.UserID,
+		"requested_at":    export.RequestedAt.UTC(),
+		"generated_at":    time.Now().UTC(),
+		"files":           names,
+		"missing_sources": missing,
+	}
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	archive := zip.NewWriter(file)
+	for _, f := range append([]exportFile{{name: "manifest.json", data: manifestJSON}}, files...) {
+		w, err := archive.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			file.Close()
+			return err
+		}
+		if _, err := w.Write(f.data); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(tmp)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to move archive into place: %w", err)
+	}
+
+	return s.exportRepo.MarkReady(ctx, export.ID, path, info.Size(), time.Now().Add(s.cfg.TTL))
+}
+
+type exportFile struct {
+	name string
+	data []byte
+}
+
+// collect gathers the archive's files and lists the sources that never sent their part.
+func (s *dataExportService) collect(ctx context.Context, export models.DataExport) ([]exportFile, []string, error) {
+	user, err := s.userRepo.GetByID(ctx, export.UserID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load user: %w", err)
+	}
+
+	sessions, err := s.sessionRepo.GetByUserID(ctx, export.UserID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load sessions: %w", err)
+	}
+
+	var activitySessions []models.UserActivitySession
+	for offset := 0; ; offset += dataExportBatchSize {
+		batch, _, err := s.activitySessionRepo.GetByUserID(ctx, export.UserID, dataExportBatchSize, offset)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load activity sessions: %w", err)
+		}
+		activitySessions = append(activitySessions, batch...)
+		if len(batch) < dataExportBatchSize {
+			break
+		}
+	}
+
+	methods, err := s.mfaRepo.GetByUserID(ctx, export.UserID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load MFA methods: %w", err)
+	}
+
+	consents, err := s.consentRepo.ListConsentHistory(ctx, export.UserID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load consents: %w", err)
+	}
+
+	var auditLogs []dto.AuditLogResponse
+	for offset := 0; ; offset += dataExportBatchSize {
+		batch, _, err := s.auditLogRepo.List(ctx, repositories.AuditLogFilter{
+			UserID: &export.UserID,
+			Limit:  dataExportBatchSize,
+			Offset: offset,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load audit log: %w", err)
+		}
+		for _, log := range batch {
+			entry := toAuditLogResponse(log)
+			// Where the user was not the one acting (an admin, say), the IP address and
+			// browser belong to someone else and are left out.
+			if log.ActorID == nil || *log.ActorID != export.UserID {
+				entry.IPAddr, entry.UserAgent = "", ""
+			}
+			auditLogs = append(auditLogs, entry)
+		}
+		if len(batch) < dataExportBatchSize {
+			break
+		}
+	}
+
+	sections := []struct {
+		name string
+		data any
+	}{
+		{"account.json", exportAccount(user)},
+		{"profile.json", user.Profile},
+		{"sessions.json", exportSessions(sessions)},
+		{"activity_sessions.json", exportActivitySessions(activitySessions)},
+		{"mfa_methods.json", exportMFAMethods(methods)},
+		{"consents.json", exportConsents(consents)},
+		{"audit_log.json", auditLogs},
+	}
+
+	files := make([]exportFile, 0, len(sections)+len(s.cfg.Sources))
+	for _, section := range sections {
+		data, err := json.MarshalIndent(section.data, "", "  ")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode %s: %w", section.name, err)
+		}
+		files = append(files, exportFile{name: section.name, data: data})
+	}
+
+	parts, err := s.exportRepo.GetParts(ctx, export.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load parts: %w", err)
+	}
+	missing := []string{}
+	for _, part := range parts {
+		if !part.ReceivedAt.Valid {
+			missing = append(missing, part.Source)
+			continue
+		}
+		files = append(files, exportFile{name: part.Source + ".json", data: part.Payload})
+	}
+
+	return files, missing, nil
+}
+
+func (s *dataExportService) getOwned(ctx context.Context, userID, exportID uuid.UUID) (*models.DataExport, error) {
+	export, err := s.exportRepo.GetByID(ctx, exportID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrDataExportNotFound
+		}
+		return nil, err
+	}
+	// Someone else's export is reported as missing rather than forbidden so IDs cannot
+	// be probed.
+	if export.UserID != userID {
+		return nil, errors.ErrDataExportNotFound
+	}
+	return export, nil
+}
+
+func (s *dataExportService) toResponse(ctx context.Context, export models.DataExport) (*dto.DataExportResponse, error) {
+	parts, err := s.exportRepo.GetParts(ctx, export.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.DataExportResponse{
+		ID:          export.ID,
+		Status:      export.Status,
+		RequestedAt: export.RequestedAt,
+		CompletedAt: nullTimePtr(export.CompletedAt),
+		ExpiresAt:   nullTimePtr(export.ExpiresAt),
+		SizeBytes:   export.SizeBytes,
+		Error:       export.Error,
+		Sources:     make([]dto.DataExportSourceResponse, len(parts)),
+	}
+	for i, part := range parts {
+		resp.Sources[i] = dto.DataExportSourceResponse{Source: part.Source, Received: part.ReceivedAt.Valid}
+	}
+	return resp, nil
+}
+
+func (s *dataExportService) audit(ctx context.Context, userID uuid.UUID, action string, metadata map[string]any) {
+	entry := &models.AuditLog{
+		UserID:    &userID,
+		Action:    action,
+		Metadata:  metadata,
+		CreatedAt: time.Now(),
+	}
+	if err := s.auditLogRepo.Create(ctx, entry); err != nil {
+		slog.WarnContext(ctx, "failed to write audit log", "action", action, "error", err)
+	}
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func exportAccount(user *models.User) map[string]any {
+	return map[string]any{
+		"id":             user.ID,
+		"email":          user.Email,
+		"email_verified": user.EmailVerified,
+		"status":         user.Status,
+		"role":           user.Role,
+		"created_at":     user.CreatedAt,
+		"updated_at":     user.UpdatedAt,
+		"last_login_at":  nullTimePtr(user.LastLoginAt),
+		"last_login_ip":  getStringValue(user.LastLoginIP),
+	}
+}
+
+func exportSessions(sessions []models.Session) []map[string]any {
+	out := make([]map[string]any, len(sessions))
+	for i, session := range sessions {
+		out[i] = map[string]any{
+			"id":         session.ID,
+			"user_agent": session.UserAgent,
+			"ip_addr":    getStringValue(session.IPAddr),
+			"created_at": session.CreatedAt,
+			"expires_at": session.ExpiresAt,
+			"revoked_at": nullTimePtr(session.RevokedAt),
+		}
+	}
+	return out
+}
+
+func exportActivitySessions(sessions []models.UserActivitySession) []map[string]any {
+	out := make([]map[string]any, len(sessions))
+	for i, session := range sessions {
+		out[i] = map[string]any{
+			"id":          session.ID,
+			"session_id":  session.SessionID,
+			"started_at":  session.StartedAt,
+			"ended_at":    nullTimePtr(session.EndedAt),
+			"duration_ms": session.DurationMs,
+			"ip_addr":     getStringValue(session.IPAddr),
+			"user_agent":  session.UserAgent,
+		}
+	}
+	return out
+}
+
+// exportMFAMethods describes the user's second factors without any secret material.
+func exportMFAMethods(methods []models.MFAMethod) []map[string]any {
+	out := make([]map[string]any, len(methods))
+	for i, method := range methods {
+		entry := map[string]any{
+			"id":           method.ID,
+			"type":         method.Type,
+			"label":        method.Label,
+			"priority":     method.Priority,
+			"added_at":     method.AddedAt,
+			"verified_at":  nullTimePtr(method.VerifiedAt),
+			"last_used_at": nullTimePtr(method.LastUsedAt),
+		}
+		if method.Type == models.MFATypePhone {
+			entry["phone_number"] = method.PhoneNumber
+			entry["channel"] = method.OTPChannel
+		}
+		out[i] = entry
+	}
+	return out
+}
+
+func exportConsents(consents []models.UserConsent) []map[string]any {
+	out := make([]map[string]any, len(consents))
+	for i, consent := range consents {
+		out[i] = map[string]any{
+			"document":    consent.Document,
+			"version":     consent.Version,
+			"accepted_at": consent.AcceptedAt,
+			"ip_addr":     getStringValue(consent.IPAddr),
+			"user_agent":  consent.UserAgent,
+		}
+	}
+	return out
+}
@@ -0,0 +1,606 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/repositories"
+	"user-services/internal/audit"
+	"user-services/internal/config"
+	"user-services/internal/errors"
+	"user-services/internal/models"
+	"user-services/internal/utils"
+
+	"github.com/ductan2/microservice-app/shared/events"
+	"github.com/ductan2/microservice-app/shared/softdelete"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// erasureBatchSize bounds how many due requests one run anonymizes, and how many data
+// exports are looked up for deletion per user.
+const erasureBatchSize = 100
+
+// ErasureService runs right-to-erasure requests. Scheduling one deletes the account and
+// signs the user out everywhere; until ERASURE_RETENTION_WINDOW has passed, restoring the
+// account cancels it. ProcessDue then anonymizes the user's personal data here, deletes
+// their data export archives and publishes a user.erasure_requested event asking every
+// service in ERASURE_SERVICES to scrub or pseudonymize its copies. Each confirms through
+// Acknowledge; the request is completed once all have, or reported incomplete when
+// ERASURE_ACK_TIMEOUT passes first. Accounts deleted without an erasure request, such as
+// by an admin, are erased the same way once ERASURE_SOFT_DELETE_RETENTION has passed.
+//
+// A deactivation is an erasure the user can still call off by signing in: the account is
+// disabled right away and erased once ACCOUNT_DEACTIVATION_WINDOW has passed, unless a
+// password or passkey login reactivates it first. Deactivations and reactivations are
+// published as user.deactivated and user.reactivated events.
+type ErasureService interface {
+	// RequestErasure schedules the erasure of the caller's own account after checking
+	// their password. created is false when one was already scheduled.
+	RequestErasure(ctx context.Context, userID uuid.UUID, password string) (*dto.ErasureResponse, bool, error)
+	// ScheduleErasure schedules the erasure of a user's account on an admin's behalf.
+	ScheduleErasure(ctx context.Context, userID uuid.UUID, reason string) (*dto.ErasureResponse, bool, error)
+	// DeactivateAccount deactivates the caller's own account after checking their
+	// password. created is false when an erasure or deactivation was already scheduled.
+	DeactivateAccount(ctx context.Context, userID uuid.UUID, password, reason string) (*dto.ErasureResponse, bool, error)
+	// DeactivationPending reports whether the user deactivated their account and its
+	// cooling-off period has not ended, so signing in may reactivate it.
+	DeactivationPending(ctx context.Context, user *models.User) (bool, error)
+	// Reactivate cancels the user's pending deactivation and restores the account. The
+	// caller must have authenticated the user.
+	Reactivate(ctx context.Context, user *models.User) error
+	GetErasure(ctx context.Context, id uuid.UUID) (*dto.ErasureResponse, error)
+	ListErasures(ctx context.Context, query dto.ErasureQuery) (*dto.PaginatedResponse, error)
+	Acknowledge(ctx context.Context, id uuid.UUID, req dto.ErasureAckRequest) error
+	// RecoverAccount restores an account the user deleted themselves, cancelling its
+	// erasure, as long as the retention window has not ended. Accounts deleted by an
+	// admin can only be restored by an admin.
+	RecoverAccount(ctx context.Context, email, password string) (*dto.PublicUser, error)
+	// ProcessDue schedules the erasure of accounts deleted longer than the soft-delete
+	// retention ago, anonymizes accounts whose retention window has ended and reports
+	// requests still waiting on acknowledgements past their deadline as incomplete.
+	ProcessDue(ctx context.Context) error
+}
+
+type erasureService struct {
+	erasureRepo    repositories.ErasureRepository
+	userRepo       repositories.UserRepository
+	exportRepo     repositories.DataExportRepository
+	auditLogRepo   repositories.AuditLogRepository
+	outboxRepo     repositories.OutboxRepository
+	sessionService SessionService
+	cfg            config.ErasureConfig
+}
+
+func NewErasureService(
+	erasureRepo repositories.ErasureRepository,
+	userRepo repositories.UserRepository,
+	exportRepo repositories.DataExportRepository,
+	auditLogRepo repositories.AuditLogRepository,
+	outboxRepo repositories.OutboxRepository,
+	sessionService SessionService,
+	cfg config.ErasureConfig,
+) ErasureService {
+	return &erasureService{
+		erasureRepo:    erasureRepo,
+		userRepo:       userRepo,
+		exportRepo:     exportRepo,
+		auditLogRepo:   auditLogRepo,
+		outboxRepo:     outboxRepo,
+		sessionService: sessionService,
+		cfg:            cfg,
+	}
+}
+
+func (s *erasureService) RequestErasure(ctx context.Context, userID uuid.UUID, password string) (*dto.ErasureResponse, bool, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := utils.CheckPassword(user.PasswordHash, password); err != nil {
+		return nil, false, errors.ErrInvalidCredentials
+	}
+	return s.schedule(ctx, user, models.ErasureKindErasure, "requested by user", &userID, s.cfg.RetentionWindow)
+}
+
+func (s *erasureService) ScheduleErasure(ctx context.Context, userID uuid.UUID, reason string) (*dto.ErasureResponse, bool, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	var requestedBy *uuid.UUID
+	if req, ok := audit.FromContext(ctx); ok {
+		requestedBy = req.ActorID
+	}
+	return s.schedule(ctx, user, models.ErasureKindErasure, reason, requestedBy, s.cfg.RetentionWindow)
+}
+
+func (s *erasureService) DeactivateAccount(ctx context.Context, userID uuid.UUID, password, reason string) (*dto.ErasureResponse, bool, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	if user.Status == models.StatusDeleted {
+		return nil, false, errors.ErrUserNotFound
+	}
+	if err := utils.CheckPassword(user.PasswordHash, password); err != nil {
+		return nil, false, errors.ErrInvalidCredentials
+	}
+	if reason == "" {
+		reason = "deactivated by user"
+	}
+	return s.schedule(ctx, user, models.ErasureKindDeactivation, reason, &userID, s.cfg.DeactivationWindow)
+}
+
+// schedule deletes the account and records the erasure request of the given kind, due
+// after retention. An erasure or deactivation already waiting out its retention window
+// is returned as-is.
+func (s *erasureService) schedule(ctx context.Context, user *models.User, kind, reason string, requestedBy *uuid.UUID, retention time.Duration) (*dto.ErasureResponse, bool, error) {
+	latest, err := s.erasureRepo.GetLatestByUserID(ctx, user.ID)
+	if err != nil && !stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+	if latest != nil {
+		switch latest.Status {
+		case models.ErasureStatusScheduled:
+			resp, err := s.toResponse(ctx, *latest)
+			return resp, false, err
+		case models.ErasureStatusAnonymized, models.ErasureStatusCompleted, models.ErasureStatusIncomplete:
+			return nil, false, errors.ErrAccountErased
+		}
+	}
+
+	now := time.Now()
+	request := &models.ErasureRequest{
+		UserID:       user.ID,
+		Kind:         kind,
+		Status:       models.ErasureStatusScheduled,
+		Reason:       reason,
+		RequestedBy:  requestedBy,
+		RequestedAt:  now,
+		ScheduledFor: now.Add(retention),
+		LocalReport:  models.JSONBMap{},
+	}
+	if err := s.erasureRepo.Create(ctx, request); err != nil {
+		return nil, false, fmt.Errorf("failed to create erasure request: %w", err)
+	}
+
+	if user.Status != models.StatusDeleted || !user.DeletedAt.Valid {
+		user.Status = models.StatusDeleted
+		deletedBy := softdelete.System
+		if requestedBy != nil {
+			deletedBy = requestedBy.String()
+		}
+		user.Mark(softdelete.Mark{At: now.UTC(), By: deletedBy})
+		if err := s.userRepo.UpdateUser(ctx, user); err != nil {
+			return nil, false, fmt.Errorf("failed to delete account: %w", err)
+		}
+	}
+
+	if err := s.sessionService.RevokeAllUserSessions(ctx, user.ID); err != nil {
+		slog.WarnContext(ctx, "failed to revoke user sessions", "user_id", user.ID, "error", err)
+	}
+
+	action := "user.erasure_scheduled"
+	if kind == models.ErasureKindDeactivation {
+		action = "user.deactivated"
+		s.publishLifecycleEvent(ctx, user.ID, "user.deactivated", "UserDeactivated", map[string]any{
+			"deactivation_id": request.ID,
+			"deactivated_at":  now.UTC(),
+			"erase_after":     request.ScheduledFor.UTC(),
+		})
+	}
+	s.audit(ctx, user.ID, action, map[string]any{
+		"erasure_id":    request.ID,
+		"scheduled_for": request.ScheduledFor.UTC(),
+		"reason":        reason,
+	})
+
+	resp, err := s.toResponse(ctx, *request)
+	return resp, true, err
+}
+
+func (s *erasureService) GetErasure(ctx context.Context, id uuid.UUID) (*dto.ErasureResponse, error) {
+	request, err := s.erasureRepo.GetByID(ctx, id)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrErasureNotFound
+		}
+		return nil, err
+	}
+	return s.toResponse(ctx, *request)
+}
+
+func (s *erasureService) ListErasures(ctx context.Context, query dto.ErasureQuery) (*dto.PaginatedResponse, error) {
+	page := query.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := query.PageSize
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	filter := repositories.ErasureFilter{
+		Kind:   query.Kind,
+		Status: query.Status,
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+	}
+	if query.UserID != "" {
+		id, err := uuid.Parse(query.UserID)
+		if err != nil {
+			return nil, errors.NewValidationError("user_id must be a UUID")
+		}
+		filter.UserID = &id
+	}
+
+	requests, total, err := s.erasureRepo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.ErasureResponse, 0, len(requests))
+	for _, request := range requests {
+		resp, err := s.toResponse(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *resp)
+	}
+
+	return &dto.PaginatedResponse{
+		Data:       responses,
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      int(total),
+		TotalPages: int(math.Ceil(float64(total) / float64(pageSize))),
+	}, nil
+}
+
+// Acknowledge records a service's confirmation and completes the request once every
+// service has confirmed successfully. Acks keep being accepted after the deadline, so a
+// late service can still turn an incomplete request into a completed one.
+func (s *erasureService) Acknowledge(ctx context.Context, id uuid.UUID, req dto.ErasureAckRequest) error {
+	saved, err := s.erasureRepo.SaveAck(ctx, id, &models.ErasureAck{
+		Service:         req.Service,
+		Status:          req.Status,
+		RecordsAffected: req.RecordsAffected,
+		Detail:          req.Detail,
+	})
+	if err != nil {
+		return err
+	}
+	if !saved {
+		if _, err := s.erasureRepo.GetByID(ctx, id); err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.ErrErasureNotFound
+			}
+			return err
+		}
+		return errors.NewConflictError("Erasure request is not waiting for this service").WithReason("ERASURE_ACK_REJECTED")
+	}
+
+	completed, err := s.erasureRepo.CompleteIfAcknowledged(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to complete erasure request: %w", err)
+	}
+	if completed {
+		request, err := s.erasureRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		s.audit(ctx, request.UserID, "user.erasure_completed", map[string]any{"erasure_id": id})
+	}
+	return nil
+}
+
+func (s *erasureService) RecoverAccount(ctx context.Context, email, password string) (*dto.PublicUser, error) {
+	user, err := s.userRepo.GetByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrInvalidCredentials
+		}
+		return nil, err
+	}
+	if err := utils.CheckPassword(user.PasswordHash, password); err != nil {
+		return nil, errors.ErrInvalidCredentials
+	}
+	if user.Status != models.StatusDeleted || user.MergedIntoID != nil {
+		return nil, errors.ErrAccountNotRecoverable
+	}
+
+	latest, err := s.erasureRepo.GetLatestByUserID(ctx, user.ID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrAccountNotRecoverable
+		}
+		return nil, err
+	}
+	if !restorableByOwner(user, latest) {
+		return nil, errors.ErrAccountNotRecoverable
+	}
+	if err := s.restore(ctx, user, latest, "recovered by user"); err != nil {
+		return nil, err
+	}
+
+	resp := toPublicUser(*user)
+	return &resp, nil
+}
+
+func (s *erasureService) DeactivationPending(ctx context.Context, user *models.User) (bool, error) {
+	if user.Status != models.StatusDeleted || user.MergedIntoID != nil {
+		return false, nil
+	}
+	latest, err := s.erasureRepo.GetLatestByUserID(ctx, user.ID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return latest.Kind == models.ErasureKindDeactivation && restorableByOwner(user, latest), nil
+}
+
+func (s *erasureService) Reactivate(ctx context.Context, user *models.User) error {
+	latest, err := s.erasureRepo.GetLatestByUserID(ctx, user.ID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.ErrAccountNotRecoverable
+		}
+		return err
+	}
+	if latest.Kind != models.ErasureKindDeactivation || !restorableByOwner(user, latest) {
+		return errors.ErrAccountNotRecoverable
+	}
+	return s.restore(ctx, user, latest, "reactivated by signing in")
+}
+
+// restorableByOwner reports whether the user deleted or deactivated the account
+// themselves and its retention window has not ended yet.
+func restorableByOwner(user *models.User, latest *models.ErasureRequest) bool {
+	selfRequested := latest.RequestedBy != nil && *latest.RequestedBy == user.ID
+	return latest.Status == models.ErasureStatusScheduled && selfRequested && time.Now().Before(latest.ScheduledFor)
+}
+
+// restore cancels the scheduled erasure and makes the account active again. Cancelling a
+// deactivation publishes user.reactivated.
+func (s *erasureService) restore(ctx context.Context, user *models.User, latest *models.ErasureRequest, reason string) error {
+	// The anonymization may have claimed the request in the meantime
+	cancelled, err := s.erasureRepo.CancelScheduled(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel erasure request: %w", err)
+	}
+	if !cancelled {
+		return errors.ErrAccountNotRecoverable
+	}
+
+	user.Status = models.StatusActive
+	user.Clear()
+	user.LockoutUntil = sql.NullTime{}
+	if err := s.userRepo.UpdateUser(ctx, user); err != nil {
+		return fmt.Errorf("failed to restore account: %w", err)
+	}
+
+	if latest.Kind == models.ErasureKindDeactivation {
+		s.publishLifecycleEvent(ctx, user.ID, "user.reactivated", "UserReactivated", map[string]any{
+			"deactivation_id": latest.ID,
+			"reactivated_at":  time.Now().UTC(),
+		})
+	}
+	s.audit(ctx, user.ID, "user.restored", map[string]any{
+		"reason":            reason,
+		"erasure_cancelled": latest.ID,
+	})
+	return nil
+}
+
+func (s *erasureService) ProcessDue(ctx context.Context) error {
+	if s.cfg.SoftDeleteRetention > 0 {
+		if err := s.scheduleExpiredDeletions(ctx); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+
+	due, err := s.erasureRepo.ListDue(ctx, now, erasureBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to list due erasure requests: %w", err)
+	}
+	for _, request := range due {
+		if err := s.anonymize(ctx, request); err != nil {
+			return fmt.Errorf("failed to anonymize user %s: %w", request.UserID, err)
+		}
+	}
+
+	if _, err := s.erasureRepo.MarkOverdueIncomplete(ctx, now); err != nil {
+		return fmt.Errorf("failed to mark overdue erasure requests incomplete: %w", err)
+	}
+	return nil
+}
+
+// scheduleExpiredDeletions schedules the immediate erasure of accounts deleted longer
+// than the soft-delete retention ago, signing them out everywhere.
+func (s *erasureService) scheduleExpiredDeletions(ctx context.Context) error {
+	users, err := s.erasureRepo.ListExpiredDeletions(ctx, time.Now().Add(-s.cfg.SoftDeleteRetention), erasureBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to list expired deletions: %w", err)
+	}
+	for i := range users {
+		if _, _, err := s.schedule(ctx, &users[i], models.ErasureKindErasure, "soft-delete retention ended", nil, 0); err != nil {
+			return fmt.Errorf("failed to schedule erasure of user %s: %w", users[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// anonymize erases the user's data here and publishes the event for the other services in
+// the same transaction, then deletes the user's data export archives from disk.
+func (s *erasureService) anonymize(ctx context.Context, request models.ErasureRequest) error {
+	// The archive paths are read first because the anonymization deletes the export rows
+	exports, err := s.exportRepo.ListByUserID(ctx, request.UserID, erasureBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to list data exports: %w", err)
+	}
+
+	var emailHash string
+	user, err := s.userRepo.GetByID(ctx, request.UserID)
+	switch {
+	case err == nil:
+		emailHash = utils.HashToken(strings.ToLower(strings.TrimSpace(user.Email)))
+	case !stderrors.Is(err, gorm.ErrRecordNotFound):
+		return err
+	}
+
+	status := models.ErasureStatusAnonymized
+	if len(s.cfg.Services) == 0 {
+		status = models.ErasureStatusCompleted
+	}
+	ackDeadline := time.Now().Add(s.cfg.AckTimeout)
+
+	var event *models.Outbox
+	if len(s.cfg.Services) > 0 {
+		// Services match the user by ID; the email is only sent hashed, for copies keyed
+		// by address, so the event itself carries no personal data.
+		requested := &events.UserErasureRequested{
+			ErasureID:    request.ID,
+			UserID:       request.UserID,
+			EmailSHA256:  emailHash,
+			RequestedAt:  request.RequestedAt.UTC(),
+			Services:     s.cfg.Services,
+			CallbackPath: fmt.Sprintf("/api/v1/internal/erasures/%s/acks", request.ID),
+			Deadline:     ackDeadline.UTC(),
+		}
+		payload, err := events.Marshal(requested)
+		if err != nil {
+			return fmt.Errorf("failed to marshal payload: %w", err)
+		}
+		event = &models.Outbox{
+			AggregateID: request.UserID,
+			Topic:       requested.Topic(),
+			Type:        requested.EventType(),
+			Payload:     payload,
+			CreatedAt:   time.Now(),
+		}
+	}
+
+	report, claimed, err := s.erasureRepo.Anonymize(ctx, &request, s.cfg.Services, status, ackDeadline, event)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+
+	for _, export := range exports {
+		if export.FilePath == "" {
+			continue
+		}
+		if err := os.Remove(export.FilePath); err != nil && !os.IsNotExist(err) {
+			slog.WarnContext(ctx, "failed to delete data export archive", "path", export.FilePath, "error", err)
+		}
+	}
+
+	s.audit(ctx, request.UserID, "user.erased", map[string]any{
+		"erasure_id": request.ID,
+		"report":     report,
+	})
+	return nil
+}
+
+func (s *erasureService) getUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *erasureService) toResponse(ctx context.Context, request models.ErasureRequest) (*dto.ErasureResponse, error) {
+	acks, err := s.erasureRepo.GetAcks(ctx, request.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.ErasureResponse{
+		ID:           request.ID,
+		UserID:       request.UserID,
+		Kind:         request.Kind,
+		Status:       request.Status,
+		Reason:       request.Reason,
+		RequestedBy:  request.RequestedBy,
+		RequestedAt:  request.RequestedAt,
+		ScheduledFor: request.ScheduledFor,
+		AnonymizedAt: nullTimePtr(request.AnonymizedAt),
+		AckDeadline:  nullTimePtr(request.AckDeadline),
+		CompletedAt:  nullTimePtr(request.CompletedAt),
+		CancelledAt:  nullTimePtr(request.CancelledAt),
+		LocalReport:  request.LocalReport,
+		Services:     make([]dto.ErasureServiceResponse, len(acks)),
+	}
+	if resp.LocalReport == nil {
+		resp.LocalReport = map[string]any{}
+	}
+	for i, ack := range acks {
+		status := ack.Status
+		if status == "" {
+			status = "pending"
+		}
+		resp.Services[i] = dto.ErasureServiceResponse{
+			Service:         ack.Service,
+			Status:          status,
+			RecordsAffected: ack.RecordsAffected,
+			Detail:          ack.Detail,
+			AcknowledgedAt:  nullTimePtr(ack.AcknowledgedAt),
+		}
+	}
+	return resp, nil
+}
+
+func (s *erasureService) audit(ctx context.Context, userID uuid.UUID, action string, metadata map[string]any) {
+	entry := &models.AuditLog{
+		UserID:    &userID,
+		Action:    action,
+		Metadata:  metadata,
+		CreatedAt: time.Now(),
+	}
+	if err := s.auditLogRepo.Create(ctx, entry); err != nil {
+		slog.WarnContext(ctx, "failed to write audit log", "action", action, "error", err)
+	}
+}
+
+// publishLifecycleEvent tells the other services about a change to the account's
+// lifecycle. Failing to queue the event does not fail the change.
+func (s *erasureService) publishLifecycleEvent(ctx context.Context, userID uuid.UUID, topic, eventType string, payload map[string]any) {
+	payload["user_id"] = userID
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to marshal event", "topic", topic, "error", err)
+		return
+	}
+	if err := s.outboxRepo.Create(ctx, &models.Outbox{
+		AggregateID: userID,
+		Topic:       topic,
+		Type:        eventType,
+		Payload:     payloadBytes,
+		CreatedAt:   time.Now(),
+	}); err != nil {
+		slog.WarnContext(ctx, "failed to queue event", "topic", topic, "error", err)
+	}
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"user-services/internal/metrics"
+
+	"github.com/ductan2/microservice-app/shared/health"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Dependencies the service needs to serve requests
+const (
+	DependencyPostgres = "postgres"
+	DependencyRedis    = "redis"
+	DependencyRabbitMQ = "rabbitmq"
+)
+
+// NewHealthChecker returns the checker behind /livez, /readyz and /healthz. PostgreSQL,
+// Redis and RabbitMQ are all critical; every check is recorded in the dependency
+// metrics. A nil RabbitMQ connection is reported as down.
+func NewHealthChecker(db *gorm.DB, redisClient *redis.Client, rabbitConn *amqp.Connection) *health.Checker {
+	checker := health.New(health.Config{
+		Service: "user-services",
+		Observe: func(result health.Result) {
+			up := 0.0
+			if result.Status == health.StatusUp {
+				up = 1
+			}
+			metrics.DependencyUp.WithLabelValues(result.Name).Set(up)
+			metrics.DependencyCheckDuration.WithLabelValues(result.Name).Set(result.Latency.Seconds())
+		},
+	})
+	checker.Register(DependencyPostgres, health.Critical, func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+	checker.Register(DependencyRedis, health.Critical, func(ctx context.Context) error {
+		return redisClient.Ping(ctx).Err()
+	})
+	checker.Register(DependencyRabbitMQ, health.Critical, func(context.Context) error {
+		// The connection heartbeats every 10s, so a lost broker closes it
+		if rabbitConn == nil || rabbitConn.IsClosed() {
+			return fmt.Errorf("connection closed")
+		}
+		return nil
+	})
+	return checker
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/repositories"
+	"user-services/internal/errors"
+	"user-services/internal/models"
+	"user-services/internal/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Reasons an identifier is not available
+const (
+	IdentifierUnavailableInvalid  = "invalid"
+	IdentifierUnavailableReserved = "reserved"
+	IdentifierUnavailableTaken    = "taken"
+)
+
+// IdentifierService manages the usernames and phone numbers users can sign in with
+// besides their email. Usernames are case-insensitive; a phone number only becomes a
+// login once the user entered the code sent to it, and each belongs to one account.
+type IdentifierService interface {
+	GetIdentifiers(ctx context.Context, userID uuid.UUID) (*dto.LoginIdentifiersResponse, error)
+	// CheckUsername and CheckPhone report whether an identifier can be claimed. The
+	// caller's own identifiers count as available.
+	CheckUsername(ctx context.Context, userID *uuid.UUID, username string) (*dto.IdentifierAvailabilityResponse, error)
+	CheckPhone(ctx context.Context, userID uuid.UUID, phone string) (*dto.IdentifierAvailabilityResponse, error)
+	SetUsername(ctx context.Context, userID uuid.UUID, username string) (*dto.LoginIdentifiersResponse, error)
+	RemoveUsername(ctx context.Context, userID uuid.UUID) (*dto.LoginIdentifiersResponse, error)
+	StartPhoneVerification(ctx context.Context, userID uuid.UUID, req dto.PhoneVerificationRequest) (*dto.PhoneVerificationResponse, error)
+	VerifyPhone(ctx context.Context, userID uuid.UUID, req dto.VerifyPhoneRequest) (*dto.LoginIdentifiersResponse, error)
+	RemovePhone(ctx context.Context, userID uuid.UUID) (*dto.LoginIdentifiersResponse, error)
+}
+
+type identifierService struct {
+	userRepo     repositories.UserRepository
+	auditLogRepo repositories.AuditLogRepository
+	otp          OTPService
+}
+
+func NewIdentifierService(userRepo repositories.UserRepository, auditLogRepo repositories.AuditLogRepository, otpService OTPService) IdentifierService {
+	return &identifierService{
+		userRepo:     userRepo,
+		auditLogRepo: auditLogRepo,
+		otp:          otpService,
+	}
+}
+
+func (s *identifierService) GetIdentifiers(ctx context.Context, userID uuid.UUID) (*dto.LoginIdentifiersResponse, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toLoginIdentifiersResponse(user), nil
+}
+
+func (s *identifierService) CheckUsername(ctx context.Context, userID *uuid.UUID, username string) (*dto.IdentifierAvailabilityResponse, error) {
+	username = utils.NormalizeUsername(username)
+	resp := &dto.IdentifierAvailabilityResponse{Value: username}
+	switch {
+	case !utils.ValidUsername(username):
+		resp.Reason = IdentifierUnavailableInvalid
+	case utils.ReservedUsername(username):
+		resp.Reason = IdentifierUnavailableReserved
+	default:
+		owner, err := s.userRepo.GetByUsername(ctx, username)
+		switch {
+		case err == nil && (userID == nil || owner.ID != *userID):
+			resp.Reason = IdentifierUnavailableTaken
+		case err != nil && !stderrors.Is(err, gorm.ErrRecordNotFound):
+			return nil, err
+		}
+	}
+	resp.Available = resp.Reason == ""
+	return resp, nil
+}
+
+func (s *identifierService) CheckPhone(ctx context.Context, userID uuid.UUID, phone string) (*dto.IdentifierAvailabilityResponse, error) {
+	normalized := utils.NormalizePhoneNumber(phone)
+	resp := &dto.IdentifierAvailabilityResponse{Value: normalized}
+	if normalized == "" {
+		resp.Value = phone
+		resp.Reason = IdentifierUnavailableInvalid
+	} else {
+		owner, err := s.userRepo.GetByPhoneNumber(ctx, normalized)
+		switch {
+		case err == nil && owner.ID != userID:
+			resp.Reason = IdentifierUnavailableTaken
+		case err != nil && !stderrors.Is(err, gorm.ErrRecordNotFound):
+			return nil, err
+		}
+	}
+	resp.Available = resp.Reason == ""
+	return resp, nil
+}
+
+func (s *identifierService) SetUsername(ctx context.Context, userID uuid.UUID, username string) (*dto.LoginIdentifiersResponse, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	availability, err := s.CheckUsername(ctx, &userID, username)
+	if err != nil {
+		return nil, err
+	}
+	switch availability.Reason {
+	case IdentifierUnavailableInvalid:
+		return nil, errors.NewValidationError("Usernames are 3 to 30 letters, digits, dots or underscores, start with a letter and end with a letter or digit").WithReason("INVALID_USERNAME")
+	case IdentifierUnavailableReserved:
+		return nil, errors.NewValidationError("This username is reserved").WithReason("USERNAME_RESERVED")
+	case IdentifierUnavailableTaken:
+		return nil, errors.ErrUsernameTaken
+	}
+
+	previous := user.Username
+	if previous != nil && *previous == availability.Value {
+		return toLoginIdentifiersResponse(user), nil
+	}
+	if err := s.userRepo.SetUsername(ctx, userID, &availability.Value); err != nil {
+		return nil, err
+	}
+	user.Username = &availability.Value
+
+	s.audit(ctx, userID, "user.username_changed", map[string]any{
+		"from": previous,
+		"to":   availability.Value,
+	})
+	return toLoginIdentifiersResponse(user), nil
+}
+
+func (s *identifierService) RemoveUsername(ctx context.Context, userID uuid.UUID) (*dto.LoginIdentifiersResponse, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Username == nil {
+		return toLoginIdentifiersResponse(user), nil
+	}
+
+	if err := s.userRepo.SetUsername(ctx, userID, nil); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, userID, "user.username_removed", map[string]any{"username": *user.Username})
+	user.Username = nil
+	return toLoginIdentifiersResponse(user), nil
+}
+
+// StartPhoneVerification sends a code to a phone number the user wants to sign in with.
+// The number is only saved once VerifyPhone confirms the code.
+func (s *identifierService) StartPhoneVerification(ctx context.Context, userID uuid.UUID, req dto.PhoneVerificationRequest) (*dto.PhoneVerificationResponse, error) {
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	phone, err := s.availablePhone(ctx, userID, req.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery, err := s.otp.SendVerificationCode(ctx, userID, phone, req.Channel)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.PhoneVerificationResponse{
+		PhoneNumber: delivery.PhoneNumber,
+		Channel:     delivery.Channel,
+		ExpiresAt:   delivery.ExpiresAt,
+	}, nil
+}
+
+// VerifyPhone saves the phone number as a login once the user entered the code sent to
+// it, replacing any earlier one.
+func (s *identifierService) VerifyPhone(ctx context.Context, userID uuid.UUID, req dto.VerifyPhoneRequest) (*dto.LoginIdentifiersResponse, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	phone, err := s.availablePhone(ctx, userID, req.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.otp.CheckVerificationCode(ctx, userID, phone, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.ErrInvalidPhoneCode
+	}
+
+	now := time.Now()
+	if err := s.userRepo.SetPhoneNumber(ctx, userID, &phone, now); err != nil {
+		return nil, err
+	}
+	user.PhoneNumber = &phone
+	user.PhoneVerifiedAt.Time, user.PhoneVerifiedAt.Valid = now, true
+
+	s.audit(ctx, userID, "user.phone_verified", map[string]any{"phone": maskPhoneNumber(phone)})
+	return toLoginIdentifiersResponse(user), nil
+}
+
+func (s *identifierService) RemovePhone(ctx context.Context, userID uuid.UUID) (*dto.LoginIdentifiersResponse, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.PhoneNumber == nil {
+		return toLoginIdentifiersResponse(user), nil
+	}
+
+	if err := s.userRepo.SetPhoneNumber(ctx, userID, nil, time.Time{}); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, userID, "user.phone_removed", map[string]any{"phone": maskPhoneNumber(*user.PhoneNumber)})
+	user.PhoneNumber = nil
+	user.PhoneVerifiedAt.Valid = false
+	return toLoginIdentifiersResponse(user), nil
+}
+
+// availablePhone normalizes a phone number and checks no other account signs in with it.
+func (s *identifierService) availablePhone(ctx context.Context, userID uuid.UUID, phone string) (string, error) {
+	availability, err := s.CheckPhone(ctx, userID, phone)
+	if err != nil {
+		return "", err
+	}
+	switch availability.Reason {
+	case IdentifierUnavailableInvalid:
+		return "", errors.NewValidationError("Phone numbers must include the country code, e.g. +84901234567").WithReason("INVALID_PHONE_NUMBER")
+	case IdentifierUnavailableTaken:
+		return "", errors.ErrPhoneNumberTaken
+	}
+	return availability.Value, nil
+}
+
+func (s *identifierService) getUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, err
+	}
+	if user.Status == models.StatusDeleted || user.MergedIntoID != nil {
+		return nil, errors.ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (s *identifierService) audit(ctx context.Context, userID uuid.UUID, action string, metadata map[string]any) {
+	_ = s.auditLogRepo.Create(ctx, &models.AuditLog{
+		UserID:    &userID,
+		Action:    action,
+		Metadata:  metadata,
+		CreatedAt: time.Now(),
+	})
+}
+
+func toLoginIdentifiersResponse(user *models.User) *dto.LoginIdentifiersResponse {
+	resp := &dto.LoginIdentifiersResponse{
+		Email:    user.Email,
+		Username: user.Username,
+	}
+	if user.PhoneNumber != nil {
+		masked := maskPhoneNumber(*user.PhoneNumber)
+		resp.PhoneNumber = &masked
+		if user.PhoneVerifiedAt.Valid {
+			verifiedAt := user.PhoneVerifiedAt.Time
+			resp.PhoneVerifiedAt = &verifiedAt
+		}
+	}
+	return resp
+}
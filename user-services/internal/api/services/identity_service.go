@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"user-services/internal/api/repositories"
+	"user-services/internal/errors"
+	"user-services/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Session states reported by ValidateSession
+const (
+	SessionStateActive       = "active"
+	SessionStateNotFound     = "not_found"
+	SessionStateRevoked      = "revoked"
+	SessionStateExpired      = "expired"
+	SessionStateUserMismatch = "user_mismatch"
+	SessionStateUserInactive = "user_inactive"
+)
+
+// SessionValidation is the outcome of a session check. User and ExpiresAt are only set
+// for an active session.
+type SessionValidation struct {
+	State     string
+	User      *models.User
+	ExpiresAt time.Time
+}
+
+// IdentityService answers the user and session lookups other services make over the
+// internal gRPC API.
+type IdentityService interface {
+	GetUser(ctx context.Context, userID uuid.UUID) (*models.User, error)
+	// GetUsers returns the users among userIDs that exist, in no particular order.
+	GetUsers(ctx context.Context, userIDs []uuid.UUID) ([]models.User, error)
+	// ValidateSession checks that the session is neither revoked nor expired, that its
+	// user is active and, when userID is set, that it belongs to that user.
+	ValidateSession(ctx context.Context, sessionID uuid.UUID, userID *uuid.UUID) (*SessionValidation, error)
+}
+
+type identityService struct {
+	userRepo    repositories.UserRepository
+	sessionRepo repositories.SessionRepository
+}
+
+func NewIdentityService(userRepo repositories.UserRepository, sessionRepo repositories.SessionRepository) IdentityService {
+	return &identityService{
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+	}
+}
+
+func (s *identityService) GetUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *identityService) GetUsers(ctx context.Context, userIDs []uuid.UUID) ([]models.User, error) {
+	return s.userRepo.GetByIDs(ctx, userIDs)
+}
+
+func (s *identityService) ValidateSession(ctx context.Context, sessionID uuid.UUID, userID *uuid.UUID) (*SessionValidation, error) {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return &SessionValidation{State: SessionStateNotFound}, nil
+		}
+		return nil, err
+	}
+
+	switch {
+	case session.RevokedAt.Valid:
+		return &SessionValidation{State: SessionStateRevoked}, nil
+	case !session.ExpiresAt.After(time.Now()):
+		return &SessionValidation{State: SessionStateExpired}, nil
+	case userID != nil && *userID != session.UserID:
+		return &SessionValidation{State: SessionStateUserMismatch}, nil
+	}
+
+	user, err := s.userRepo.GetByID(ctx, session.UserID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return &SessionValidation{State: SessionStateNotFound}, nil
+		}
+		return nil, err
+	}
+	if user.Status != models.StatusActive || user.DeletedAt.Valid {
+		return &SessionValidation{State: SessionStateUserInactive}, nil
+	}
+
+	return &SessionValidation{
+		State:     SessionStateActive,
+		User:      user,
+		ExpiresAt: session.ExpiresAt,
+	}, nil
+}
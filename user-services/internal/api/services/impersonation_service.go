@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/repositories"
+	"user-services/internal/cache"
+	"user-services/internal/config"
+	"user-services/internal/errors"
+	"user-services/internal/models"
+	"user-services/internal/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ImpersonationService lets administrators holding one of IMPERSONATION_ALLOWED_ROLES open
+// a session as a user to reproduce their problem. The session records who opened it and
+// why, its access tokens carry the administrator's ID, and it ends at a fixed time that
+// activity does not extend. Audit entries written while it is used name the
+// administrator as the actor and are flagged as impersonated.
+type ImpersonationService interface {
+	StartImpersonation(ctx context.Context, impersonatorID uuid.UUID, userAgent, ipAddr string, req dto.StartImpersonationRequest) (*dto.ImpersonationResponse, error)
+	// EndImpersonation ends an impersonation session early. The administrator who opened
+	// it, or any other administrator allowed to impersonate, may end it.
+	EndImpersonation(ctx context.Context, callerID, sessionID uuid.UUID) error
+}
+
+type impersonationService struct {
+	userRepo         repositories.UserRepository
+	sessionRepo      repositories.SessionRepository
+	refreshTokenRepo repositories.RefreshTokenRepository
+	orgRepo          repositories.OrganizationRepository
+	auditLogRepo     repositories.AuditLogRepository
+	sessionCache     *cache.SessionCache
+	tokenService     TokenService
+	describer        *SessionDescriber
+	securityEvents   SecurityEventService
+	cfg              config.ImpersonationConfig
+}
+
+func NewImpersonationService(
+	userRepo repositories.UserRepository,
+	sessionRepo repositories.SessionRepository,
+	refreshTokenRepo repositories.RefreshTokenRepository,
+	orgRepo repositories.OrganizationRepository,
+	auditLogRepo repositories.AuditLogRepository,
+	sessionCache *cache.SessionCache,
+	tokenService TokenService,
+	describer *SessionDescriber,
+	securityEvents SecurityEventService,
+	cfg config.ImpersonationConfig,
+) ImpersonationService {
+	return &impersonationService{
+		userRepo:         userRepo,
+		sessionRepo:      sessionRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		orgRepo:          orgRepo,
+		auditLogRepo:     auditLogRepo,
+		sessionCache:     sessionCache,
+		tokenService:     tokenService,
+		describer:        describer,
+		securityEvents:   securityEvents,
+		cfg:              cfg,
+	}
+}
+
+func (s *impersonationService) StartImpersonation(ctx context.Context, impersonatorID uuid.UUID, userAgent, ipAddr string, req dto.StartImpersonationRequest) (*dto.ImpersonationResponse, error) {
+	allowed, err := s.mayImpersonate(ctx, impersonatorID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		s.securityEvents.Emit(ctx, SecurityEvent{
+			Type:     SecurityEventImpersonationDenied,
+			Severity: SecuritySeverityMedium,
+			Outcome:  "failure",
+			UserID:   &req.UserID,
+			IPAddr:   ipAddr,
+			Reason:   "role_not_allowed",
+			Details:  map[string]any{"impersonator_id": impersonatorID},
+		})
+		return nil, errors.ErrImpersonationForbidden
+	}
+	if req.UserID == impersonatorID {
+		return nil, errors.ErrImpersonationTargetForbidden
+	}
+
+	target, err := s.userRepo.GetByID(ctx, req.UserID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, err
+	}
+	if target.Status == models.StatusDeleted || target.DeletedAt.Valid || target.MergedIntoID != nil {
+		return nil, errors.ErrUserNotFound
+	}
+	// Acting as another administrator would hand over their privileges
+	if isPlatformAdmin(target) {
+		return nil, errors.ErrImpersonationTargetForbidden
+	}
+	if target.Status != models.StatusActive {
+		return nil, errors.ErrImpersonationTargetInactive
+	}
+
+	duration := s.cfg.DefaultDuration
+	if req.DurationMinutes > 0 {
+		duration = time.Duration(req.DurationMinutes) * time.Minute
+	}
+	if duration > s.cfg.MaxDuration {
+		return nil, errors.NewValidationError(fmt.Sprintf("Impersonation sessions last at most %d minutes", int(s.cfg.MaxDuration.Minutes()))).
+			WithReason("IMPERSONATION_TOO_LONG")
+	}
+
+	now := time.Now()
+	sanitizedIP := utils.SanitizeIPAddress(ipAddr)
+	var ipAddrPtr *string
+	if sanitizedIP != "" {
+		ipAddrPtr = &sanitizedIP
+	}
+	reason := strings.TrimSpace(req.Reason)
+	session := &models.Session{
+		UserID:              target.ID,
+		UserAgent:           userAgent,
+		IPAddr:              ipAddrPtr,
+		ImpersonatorID:      &impersonatorID,
+		ImpersonationReason: reason,
+		CreatedAt:           now,
+		ExpiresAt:           now.Add(duration),
+	}
+	s.describer.Describe(ctx, session)
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to create impersonation session: %w", err)
+	}
+
+	// Unlike a login, a session missing from Redis could not be used at all
+	sessionData := cache.SessionData{
+		UserID:         target.ID,
+		Email:          target.Email,
+		Role:           target.Role,
+		UserAgent:      userAgent,
+		IPAddr:         sanitizedIP,
+		CreatedAt:      session.CreatedAt,
+		ImpersonatorID: &impersonatorID,
+		ExpiresAt:      session.ExpiresAt,
+	}
+	if err := s.sessionCache.StoreSession(ctx, session.ID, sessionData, duration); err != nil {
+		s.revoke(ctx, session.ID)
+		return nil, fmt.Errorf("failed to store impersonation session: %w", err)
+	}
+
+	accessToken, expiresAt, err := issueAccessToken(ctx, s.orgRepo, target, session)
+	if err != nil {
+		s.revoke(ctx, session.ID)
+		return nil, err
+	}
+	refreshToken, err := issueRefreshToken(ctx, s.refreshTokenRepo, session)
+	if err != nil {
+		s.revoke(ctx, session.ID)
+		return nil, err
+	}
+
+	s.audit(ctx, target.ID, "user.impersonation_started", map[string]any{
+		"session_id":      session.ID,
+		"impersonator_id": impersonatorID,
+		"reason":          reason,
+		"expires_at":      session.ExpiresAt.UTC(),
+	})
+	s.securityEvents.Emit(ctx, SecurityEvent{
+		Type:     SecurityEventImpersonationStarted,
+		Severity: SecuritySeverityHigh,
+		Outcome:  "success",
+		UserID:   &target.ID,
+		Email:    target.Email,
+		IPAddr:   ipAddr,
+		Reason:   reason,
+		Details: map[string]any{
+			"session_id":      session.ID,
+			"impersonator_id": impersonatorID,
+			"expires_at":      session.ExpiresAt.UTC(),
+		},
+	})
+
+	return &dto.ImpersonationResponse{
+		SessionID:        session.ID,
+		ImpersonatorID:   impersonatorID,
+		Reason:           reason,
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		ExpiresAt:        expiresAt,
+		SessionExpiresAt: session.ExpiresAt,
+		User:             toPublicUser(*target),
+	}, nil
+}
+
+func (s *impersonationService) EndImpersonation(ctx context.Context, callerID, sessionID uuid.UUID) error {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.ErrImpersonationNotFound
+		}
+		return err
+	}
+	if session.ImpersonatorID == nil {
+		return errors.ErrImpersonationNotFound
+	}
+	if *session.ImpersonatorID != callerID {
+		allowed, err := s.mayImpersonate(ctx, callerID)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return errors.ErrImpersonationForbidden
+		}
+	}
+
+	// Ending a session that already ended is a no-op
+	if session.RevokedAt.Valid || !time.Now().Before(session.ExpiresAt) {
+		return nil
+	}
+	if err := s.tokenService.RevokeSession(ctx, session.ID); err != nil {
+		return fmt.Errorf("failed to end impersonation session: %w", err)
+	}
+
+	s.audit(ctx, session.UserID, "user.impersonation_ended", map[string]any{
+		"session_id":      session.ID,
+		"impersonator_id": *session.ImpersonatorID,
+	})
+	s.securityEvents.Emit(ctx, SecurityEvent{
+		Type:     SecurityEventImpersonationEnded,
+		Severity: SecuritySeverityMedium,
+		Outcome:  "success",
+		UserID:   &session.UserID,
+		Details: map[string]any{
+			"session_id":      session.ID,
+			"impersonator_id": *session.ImpersonatorID,
+			"ended_by":        callerID,
+		},
+	})
+	return nil
+}
+
+// mayImpersonate reports whether the user holds one of the roles allowed to impersonate.
+func (s *impersonationService) mayImpersonate(ctx context.Context, userID uuid.UUID) (bool, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return false, errors.ErrUserNotFound
+		}
+		return false, err
+	}
+	return user.Status == models.StatusActive && slices.Contains(s.cfg.AllowedRoles, user.Role), nil
+}
+
+// revoke ends a session whose setup failed halfway.
+func (s *impersonationService) revoke(ctx context.Context, sessionID uuid.UUID) {
+	if err := s.tokenService.RevokeSession(ctx, sessionID); err != nil {
+		slog.WarnContext(ctx, "failed to revoke impersonation session", "session_id", sessionID, "error", err)
+	}
+}
+
+func (s *impersonationService) audit(ctx context.Context, userID uuid.UUID, action string, metadata map[string]any) {
+	entry := &models.AuditLog{
+		UserID:    &userID,
+		Action:    action,
+		Metadata:  metadata,
+		CreatedAt: time.Now(),
+	}
+	if err := s.auditLogRepo.Create(ctx, entry); err != nil {
+		slog.WarnContext(ctx, "failed to write audit log", "action", action, "error", err)
+	}
+}
@@ -0,0 +1,502 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/repositories"
+	"user-services/internal/config"
+	"user-services/internal/errors"
+	"user-services/internal/models"
+	"user-services/internal/passwordpolicy"
+	"user-services/internal/utils"
+
+	"github.com/ductan2/microservice-app/shared/ids"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// InvitationService invites people without an account to register with a role, and
+// optionally an organization membership, chosen by the inviter. Platform admins may
+// invite anyone; organization owners and managers may invite students into their
+// organization, managers only as learners. The emailed link carries a signed token that
+// expires; resending replaces it, so only the latest link works. Accepting sets the
+// password and creates an active account with a verified email, since the link proved
+// the invitee owns it. Seats are only taken on acceptance.
+type InvitationService interface {
+	CreateInvitation(ctx context.Context, callerID uuid.UUID, req dto.CreateInvitationRequest) (*dto.InvitationResponse, error)
+	// ListInvitations lists every invitation for platform admins; others must name an
+	// organization they manage.
+	ListInvitations(ctx context.Context, callerID uuid.UUID, query dto.InvitationQuery) (*dto.PaginatedResponse, error)
+	ResendInvitation(ctx context.Context, callerID, invitationID uuid.UUID) (*dto.InvitationResponse, error)
+	RevokeInvitation(ctx context.Context, callerID, invitationID uuid.UUID) error
+	// PreviewInvitation describes the invitation behind a link without accepting it.
+	PreviewInvitation(ctx context.Context, token string) (*dto.InvitationPreviewResponse, error)
+	AcceptInvitation(ctx context.Context, req dto.AcceptInvitationRequest) (*dto.PublicUser, error)
+}
+
+type invitationService struct {
+	invitationRepo repositories.InvitationRepository
+	orgRepo        repositories.OrganizationRepository
+	userRepo       repositories.UserRepository
+	auditLogRepo   repositories.AuditLogRepository
+	passwordPolicy *passwordpolicy.Engine
+	cfg            config.InvitationConfig
+}
+
+func NewInvitationService(
+	invitationRepo repositories.InvitationRepository,
+	orgRepo repositories.OrganizationRepository,
+	userRepo repositories.UserRepository,
+	auditLogRepo repositories.AuditLogRepository,
+	passwordPolicy *passwordpolicy.Engine,
+	cfg config.InvitationConfig,
+) InvitationService {
+	return &invitationService{
+		invitationRepo: invitationRepo,
+		orgRepo:        orgRepo,
+		userRepo:       userRepo,
+		auditLogRepo:   auditLogRepo,
+		passwordPolicy: passwordPolicy,
+		cfg:            cfg,
+	}
+}
+
+func (s *invitationService) CreateInvitation(ctx context.Context, callerID uuid.UUID, req dto.CreateInvitationRequest) (*dto.InvitationResponse, error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if err := utils.ValidateEmail(email); err != nil {
+		return nil, err
+	}
+	invitation := &models.Invitation{
+		ID:    ids.New(),
+		Email: email,
+		Role:  req.Role,
+	}
+	if invitation.Role == "" {
+		invitation.Role = models.RoleStudent
+	}
+
+	caller, err := s.userRepo.GetByID(ctx, callerID)
+	if err != nil {
+		return nil, errors.ErrUserNotFound
+	}
+	admin := isPlatformAdmin(caller)
+
+	var org *models.Organization
+	if req.OrganizationID != "" {
+		orgID, err := uuid.Parse(req.OrganizationID)
+		if err != nil {
+			return nil, errors.NewValidationError("organization_id must be a UUID")
+		}
+		org, err = s.orgRepo.GetByID(ctx, orgID)
+		if err != nil {
+			if stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.ErrOrganizationNotFound
+			}
+			return nil, err
+		}
+		orgRole := req.OrgRole
+		if orgRole == "" {
+			orgRole = models.OrgRoleLearner
+		}
+		access, err := resolveOrgAccess(ctx, s.userRepo, s.orgRepo, callerID, orgID)
+		if err != nil {
+			return nil, err
+		}
+		if !access.canManage(orgRole) {
+			return nil, errors.ErrOrganizationForbidden
+		}
+		invitation.OrganizationID = &org.ID
+		invitation.OrgRole = &orgRole
+	}
+	if !admin && (org == nil || invitation.Role != models.RoleStudent) {
+		return nil, errors.ErrInvitationForbidden
+	}
+
+	exists, err := s.userRepo.CheckEmailExists(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.ErrEmailExists
+	}
+
+	now := time.Now()
+	invitation.Status = models.InvitationStatusPending
+	invitation.InvitedBy = &callerID
+	invitation.SentCount = 1
+	invitation.LastSentAt = now
+	invitation.ExpiresAt = now.Add(s.cfg.Expiry)
+	invitation.Organization = org
+
+	event, err := s.issueToken(invitation)
+	if err != nil {
+		return nil, err
+	}
+	created, err := s.invitationRepo.Create(ctx, invitation, event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invitation: %w", err)
+	}
+	if !created {
+		return nil, errors.ErrInvitationPending
+	}
+
+	metadata := map[string]any{
+		"invitation_id": invitation.ID,
+		"email":         invitation.Email,
+		"role":          invitation.Role,
+	}
+	if org != nil {
+		metadata["organization_id"] = org.ID
+		metadata["org_role"] = *invitation.OrgRole
+	}
+	s.audit(ctx, nil, "invitation.created", metadata)
+
+	resp := toInvitationResponse(*invitation)
+	return &resp, nil
+}
+
+func (s *invitationService) ListInvitations(ctx context.Context, callerID uuid.UUID, query dto.InvitationQuery) (*dto.PaginatedResponse, error) {
+	filter := repositories.InvitationFilter{
+		Status: query.Status,
+		Email:  strings.TrimSpace(query.Email),
+	}
+	if query.OrganizationID != "" {
+		orgID, err := uuid.Parse(query.OrganizationID)
+		if err != nil {
+			return nil, errors.NewValidationError("organization_id must be a UUID")
+		}
+		filter.OrganizationID = &orgID
+	}
+
+	caller, err := s.userRepo.GetByID(ctx, callerID)
+	if err != nil {
+		return nil, errors.ErrUserNotFound
+	}
+	if !isPlatformAdmin(caller) {
+		if filter.OrganizationID == nil {
+			return nil, errors.ErrInvitationForbidden
+		}
+		access, err := resolveOrgAccess(ctx, s.userRepo, s.orgRepo, callerID, *filter.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		if !access.canManage(models.OrgRoleLearner) {
+			return nil, errors.ErrOrganizationForbidden
+		}
+	}
+
+	page, pageSize := pageBounds(query.Page, query.PageSize)
+	filter.Limit = pageSize
+	filter.Offset = (page - 1) * pageSize
+	invitations, total, err := s.invitationRepo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.InvitationResponse, 0, len(invitations))
+	for _, invitation := range invitations {
+		responses = append(responses, toInvitationResponse(invitation))
+	}
+	return &dto.PaginatedResponse{
+		Data:       responses,
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      int(total),
+		TotalPages: int(math.Ceil(float64(total) / float64(pageSize))),
+	}, nil
+}
+
+func (s *invitationService) ResendInvitation(ctx context.Context, callerID, invitationID uuid.UUID) (*dto.InvitationResponse, error) {
+	invitation, err := s.getManaged(ctx, callerID, invitationID)
+	if err != nil {
+		return nil, err
+	}
+	if invitation.Status != models.InvitationStatusPending {
+		return nil, errors.ErrInvitationNotPending
+	}
+	if wait := s.cfg.ResendCooldown - time.Since(invitation.LastSentAt); wait > 0 {
+		return nil, errors.NewInvitationResendTooSoonError(wait)
+	}
+
+	now := time.Now()
+	invitation.ExpiresAt = now.Add(s.cfg.Expiry)
+	event, err := s.issueToken(invitation)
+	if err != nil {
+		return nil, err
+	}
+	resent, err := s.invitationRepo.Resend(ctx, invitation.ID, invitation.NonceHash, invitation.ExpiresAt, event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resend invitation: %w", err)
+	}
+	if !resent {
+		return nil, errors.ErrInvitationNotPending
+	}
+	invitation.SentCount++
+	invitation.LastSentAt = now
+
+	s.audit(ctx, nil, "invitation.resent", map[string]any{
+		"invitation_id": invitation.ID,
+		"email":         invitation.Email,
+		"sent_count":    invitation.SentCount,
+	})
+
+	resp := toInvitationResponse(*invitation)
+	return &resp, nil
+}
+
+func (s *invitationService) RevokeInvitation(ctx context.Context, callerID, invitationID uuid.UUID) error {
+	invitation, err := s.getManaged(ctx, callerID, invitationID)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := s.invitationRepo.Revoke(ctx, invitation.ID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke invitation: %w", err)
+	}
+	if !revoked {
+		return errors.ErrInvitationNotPending
+	}
+
+	s.audit(ctx, nil, "invitation.revoked", map[string]any{
+		"invitation_id": invitation.ID,
+		"email":         invitation.Email,
+	})
+	return nil
+}
+
+func (s *invitationService) PreviewInvitation(ctx context.Context, token string) (*dto.InvitationPreviewResponse, error) {
+	invitation, _, err := s.resolveToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &dto.InvitationPreviewResponse{
+		Email:     invitation.Email,
+		Role:      invitation.Role,
+		OrgRole:   invitation.OrgRole,
+		ExpiresAt: invitation.ExpiresAt,
+	}
+	if invitation.Organization != nil {
+		preview.OrganizationName = invitation.Organization.Name
+	}
+	return preview, nil
+}
+
+func (s *invitationService) AcceptInvitation(ctx context.Context, req dto.AcceptInvitationRequest) (*dto.PublicUser, error) {
+	invitation, nonceHash, err := s.resolveToken(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if err := s.passwordPolicy.Validate(ctx, req.Password, invitation.Email, name); err != nil {
+		return nil, err
+	}
+	hash, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user := &models.User{
+		ID:              ids.New(),
+		Email:           invitation.Email,
+		EmailNormalized: strings.ToLower(invitation.Email),
+		PasswordHash:    hash,
+		EmailVerified:   true,
+		Status:          models.StatusActive,
+		Role:            invitation.Role,
+		OrganizationID:  invitation.OrganizationID,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	profile := &models.UserProfile{
+		UserID:      user.ID,
+		DisplayName: name,
+		Locale:      "en",
+		TimeZone:    "UTC",
+		UpdatedAt:   now,
+	}
+
+	if err := s.invitationRepo.Accept(ctx, invitation, nonceHash, user, profile); err != nil {
+		switch {
+		case stderrors.Is(err, repositories.ErrInvitationNotPending):
+			return nil, errors.ErrInvitationNotPending
+		case stderrors.Is(err, repositories.ErrEmailTaken):
+			return nil, errors.ErrEmailExists
+		case stderrors.Is(err, repositories.ErrOrganizationFull):
+			return nil, errors.ErrOrganizationFull
+		}
+		return nil, fmt.Errorf("failed to accept invitation: %w", err)
+	}
+
+	metadata := map[string]any{
+		"invitation_id": invitation.ID,
+		"email":         user.Email,
+		"role":          user.Role,
+		"invited_by":    invitation.InvitedBy,
+	}
+	if invitation.OrganizationID != nil {
+		metadata["organization_id"] = *invitation.OrganizationID
+		metadata["org_role"] = *invitation.OrgRole
+	}
+	s.audit(ctx, &user.ID, "invitation.accepted", metadata)
+
+	user.Profile = *profile
+	publicUser := toPublicUser(*user)
+	return &publicUser, nil
+}
+
+// getManaged loads an invitation the caller may resend or revoke: any for platform
+// admins, otherwise one into an organization where they can manage its role.
+func (s *invitationService) getManaged(ctx context.Context, callerID, invitationID uuid.UUID) (*models.Invitation, error) {
+	invitation, err := s.invitationRepo.GetByID(ctx, invitationID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrInvitationNotFound
+		}
+		return nil, err
+	}
+
+	caller, err := s.userRepo.GetByID(ctx, callerID)
+	if err != nil {
+		return nil, errors.ErrUserNotFound
+	}
+	if isPlatformAdmin(caller) {
+		return invitation, nil
+	}
+	if invitation.OrganizationID == nil {
+		return nil, errors.ErrInvitationNotFound
+	}
+	access, err := resolveOrgAccess(ctx, s.userRepo, s.orgRepo, callerID, *invitation.OrganizationID)
+	if err != nil {
+		if stderrors.Is(err, errors.ErrOrganizationNotFound) {
+			return nil, errors.ErrInvitationNotFound
+		}
+		return nil, err
+	}
+	if !access.canManage(*invitation.OrgRole) {
+		return nil, errors.ErrInvitationForbidden
+	}
+	return invitation, nil
+}
+
+// resolveToken returns the pending invitation a signed token was issued for, with the
+// hash of its nonce. Tokens replaced by a resend are invalid.
+func (s *invitationService) resolveToken(ctx context.Context, token string) (*models.Invitation, string, error) {
+	parsed, err := utils.ParseInvitationToken(s.cfg.SigningSecret, strings.TrimSpace(token))
+	if err != nil {
+		if stderrors.Is(err, utils.ErrInvitationTokenExpired) {
+			return nil, "", errors.ErrInvitationExpired
+		}
+		return nil, "", errors.ErrInvalidInvitation
+	}
+
+	invitation, err := s.invitationRepo.GetByID(ctx, parsed.InvitationID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", errors.ErrInvalidInvitation
+		}
+		return nil, "", err
+	}
+	nonceHash := utils.HashToken(parsed.Nonce)
+	if subtle.ConstantTimeCompare([]byte(nonceHash), []byte(invitation.NonceHash)) != 1 {
+		return nil, "", errors.ErrInvalidInvitation
+	}
+	if invitation.Status != models.InvitationStatusPending {
+		return nil, "", errors.ErrInvitationNotPending
+	}
+	if time.Now().After(invitation.ExpiresAt) {
+		return nil, "", errors.ErrInvitationExpired
+	}
+	return invitation, nonceHash, nil
+}
+
+// issueToken gives the invitation a fresh signed token, storing its nonce hash, and
+// returns the event that emails the link.
+func (s *invitationService) issueToken(invitation *models.Invitation) (*models.Outbox, error) {
+	token, signed, err := utils.NewInvitationToken(s.cfg.SigningSecret, invitation.ID, invitation.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	invitation.NonceHash = utils.HashToken(token.Nonce)
+	invitation.ExpiresAt = token.ExpiresAt
+
+	inviteLink := fmt.Sprintf("%s/invitations/accept?token=%s", config.GetConfig().Email.FrontendURL, signed)
+	payload := map[string]any{
+		"invitation_id": invitation.ID,
+		"email":         invitation.Email,
+		"role":          invitation.Role,
+		"invite_link":   inviteLink,
+		"inviteLink":    inviteLink, // alternative key
+		"expires_at":    invitation.ExpiresAt.UTC(),
+	}
+	if invitation.Organization != nil {
+		payload["organization_id"] = invitation.Organization.ID
+		payload["organization"] = invitation.Organization.Name
+		payload["org_role"] = invitation.OrgRole
+	}
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Outbox{
+		AggregateID: invitation.ID,
+		Topic:       "user.invitation_sent",
+		Type:        "InvitationSent",
+		Payload:     payloadBytes,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+func (s *invitationService) audit(ctx context.Context, userID *uuid.UUID, action string, metadata map[string]any) {
+	entry := &models.AuditLog{
+		UserID:    userID,
+		Action:    action,
+		Metadata:  metadata,
+		CreatedAt: time.Now(),
+	}
+	if err := s.auditLogRepo.Create(ctx, entry); err != nil {
+		slog.WarnContext(ctx, "failed to write audit log", "action", action, "error", err)
+	}
+}
+
+func toInvitationResponse(invitation models.Invitation) dto.InvitationResponse {
+	resp := dto.InvitationResponse{
+		ID:             invitation.ID,
+		Email:          invitation.Email,
+		Role:           invitation.Role,
+		OrganizationID: invitation.OrganizationID,
+		OrgRole:        invitation.OrgRole,
+		Status:         invitation.Status,
+		InvitedBy:      invitation.InvitedBy,
+		SentCount:      invitation.SentCount,
+		LastSentAt:     invitation.LastSentAt,
+		ExpiresAt:      invitation.ExpiresAt,
+		AcceptedUserID: invitation.AcceptedUserID,
+		CreatedAt:      invitation.CreatedAt,
+	}
+	if invitation.Organization != nil {
+		resp.OrganizationName = invitation.Organization.Name
+	}
+	if resp.Status == models.InvitationStatusPending && time.Now().After(invitation.ExpiresAt) {
+		resp.Status = models.InvitationStatusExpired
+	}
+	if invitation.AcceptedAt.Valid {
+		resp.AcceptedAt = &invitation.AcceptedAt.Time
+	}
+	if invitation.RevokedAt.Valid {
+		resp.RevokedAt = &invitation.RevokedAt.Time
+	}
+	return resp
+}
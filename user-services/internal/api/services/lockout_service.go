@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"user-services/internal/api/repositories"
+	"user-services/internal/cache"
+	"user-services/internal/config"
+	"user-services/internal/errors"
+	"user-services/internal/models"
+	"user-services/internal/utils"
+
+	"github.com/ductan2/microservice-app/shared/events"
+	"github.com/google/uuid"
+)
+
+// LockoutService locks accounts and client IPs out of login after repeated failures.
+// Each further lockout doubles the previous window, and a locked account can be
+// unlocked by an admin or by its owner through a link sent to the verified email.
+type LockoutService interface {
+	Check(ctx context.Context, email, ipAddr string) error
+	RecordFailure(ctx context.Context, email, ipAddr string) error
+	RecordSuccess(ctx context.Context, email string) error
+	Unlock(ctx context.Context, email string) error
+	RequestUnlock(ctx context.Context, email string) error
+	ConfirmUnlock(ctx context.Context, token string) error
+}
+
+type lockoutService struct {
+	lockoutCache    *cache.LockoutCache
+	userRepo        repositories.UserRepository
+	userProfileRepo repositories.UserProfileRepository
+	outboxRepo      repositories.OutboxRepository
+	auditLogRepo    repositories.AuditLogRepository
+	securityEvents  SecurityEventService
+}
+
+func NewLockoutService(
+	lockoutCache *cache.LockoutCache,
+	userRepo repositories.UserRepository,
+	userProfileRepo repositories.UserProfileRepository,
+	outboxRepo repositories.OutboxRepository,
+	auditLogRepo repositories.AuditLogRepository,
+	securityEvents SecurityEventService,
+) LockoutService {
+	return &lockoutService{
+		lockoutCache:    lockoutCache,
+		userRepo:        userRepo,
+		userProfileRepo: userProfileRepo,
+		outboxRepo:      outboxRepo,
+		auditLogRepo:    auditLogRepo,
+		securityEvents:  securityEvents,
+	}
+}
+
+func accountLockoutPolicy(cfg *config.Config) cache.LockoutPolicy {
+	return cache.LockoutPolicy{
+		MaxAttempts:  cfg.Security.MaxLoginAttempts,
+		Window:       cfg.Security.LoginAttemptWindow,
+		BaseDuration: cfg.Security.LockoutDuration,
+		MaxDuration:  cfg.Security.MaxLockoutDuration,
+		StrikeMemory: 2 * cfg.Security.MaxLockoutDuration,
+	}
+}
+
+func ipLockoutPolicy(cfg *config.Config) cache.LockoutPolicy {
+	policy := accountLockoutPolicy(cfg)
+	policy.MaxAttempts = cfg.Security.MaxIPLoginAttempts
+	return policy
+}
+
+func normalizeLockoutEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Check rejects logins for an account or IP that is currently locked out. Lockout state
+// is best effort: if Redis is unavailable the login proceeds.
+func (s *lockoutService) Check(ctx context.Context, email, ipAddr string) error {
+	now := time.Now()
+
+	until, err := s.lockoutCache.LockedUntil(ctx, cache.LockoutScopeAccount, normalizeLockoutEmail(email))
+	if err != nil {
+		slog.WarnContext(ctx, "failed to check account lockout", "error", err)
+	} else if until.After(now) {
+		return errors.NewAccountLockedError("account_locked", until)
+	}
+
+	if ipAddr == "" {
+		return nil
+	}
+	until, err = s.lockoutCache.LockedUntil(ctx, cache.LockoutScopeIP, ipAddr)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to check IP lockout", "error", err)
+	} else if until.After(now) {
+		return errors.NewAccountLockedError("ip_locked", until)
+	}
+
+	return nil
+}
+
+// RecordFailure counts a failed login against both the account and the IP. It returns a
+// lockout error when this failure tripped a lockout, nil otherwise.
+func (s *lockoutService) RecordFailure(ctx context.Context, email, ipAddr string) error {
+	cfg := config.GetConfig()
+	email = normalizeLockoutEmail(email)
+
+	accountUntil, err := s.lockoutCache.RecordFailure(ctx, cache.LockoutScopeAccount, email, accountLockoutPolicy(cfg))
+	if err != nil {
+		slog.WarnContext(ctx, "failed to record account login failure", "error", err)
+	}
+
+	var ipUntil time.Time
+	if ipAddr != "" {
+		ipUntil, err = s.lockoutCache.RecordFailure(ctx, cache.LockoutScopeIP, ipAddr, ipLockoutPolicy(cfg))
+		if err != nil {
+			slog.WarnContext(ctx, "failed to record IP login failure", "error", err)
+		}
+	}
+
+	switch {
+	case !accountUntil.IsZero():
+		s.audit(ctx, email, "account.locked_out", map[string]any{"email": email, "ip_addr": ipAddr, "unlock_at": accountUntil})
+		s.emitLockout(ctx, SecurityEventAccountLockedOut, email, ipAddr, accountUntil)
+		return errors.NewAccountLockedError("account_locked", accountUntil)
+	case !ipUntil.IsZero():
+		s.audit(ctx, email, "account.ip_locked_out", map[string]any{"email": email, "ip_addr": ipAddr, "unlock_at": ipUntil})
+		s.emitLockout(ctx, SecurityEventIPLockedOut, email, ipAddr, ipUntil)
+		return errors.NewAccountLockedError("ip_locked", ipUntil)
+	}
+	return nil
+}
+
+func (s *lockoutService) emitLockout(ctx context.Context, eventType, email, ipAddr string, until time.Time) {
+	s.securityEvents.Emit(ctx, SecurityEvent{
+		Type:     eventType,
+		Severity: SecuritySeverityHigh,
+		Outcome:  "failure",
+		UserID:   s.userIDByEmail(ctx, email),
+		Email:    email,
+		IPAddr:   ipAddr,
+		Reason:   "too_many_failed_logins",
+		Details:  map[string]any{"unlock_at": until.UTC()},
+	})
+}
+
+// RecordSuccess forgets the account's failures after a successful login. IP counters are
+// kept so one valid account cannot be used to reset an IP that is guessing others.
+func (s *lockoutService) RecordSuccess(ctx context.Context, email string) error {
+	return s.lockoutCache.ResetFailures(ctx, cache.LockoutScopeAccount, normalizeLockoutEmail(email))
+}
+
+// Unlock lifts an account lockout and clears its lockout history.
+func (s *lockoutService) Unlock(ctx context.Context, email string) error {
+	return s.lockoutCache.Unlock(ctx, cache.LockoutScopeAccount, normalizeLockoutEmail(email))
+}
+
+// RequestUnlock emails an unlock link to the owner of a locked account. It reports
+// success for unknown, unverified or unlocked accounts so it cannot be used to probe
+// which emails are registered.
+func (s *lockoutService) RequestUnlock(ctx context.Context, email string) error {
+	cfg := config.GetConfig()
+	email = normalizeLockoutEmail(email)
+
+	until, err := s.lockoutCache.LockedUntil(ctx, cache.LockoutScopeAccount, email)
+	if err != nil {
+		return err
+	}
+	if !until.After(time.Now()) {
+		return nil
+	}
+
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if err != nil || !user.EmailVerified || user.Status != models.StatusActive {
+		return nil
+	}
+
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return fmt.Errorf("failed to generate unlock token: %w", err)
+	}
+	if err := s.lockoutCache.StoreUnlockToken(ctx, utils.HashToken(token), email, cfg.Email.AccountUnlockExpiry); err != nil {
+		return err
+	}
+
+	var displayName string
+	if profile, err := s.userProfileRepo.GetByUserID(ctx, user.ID); err == nil && profile != nil {
+		displayName = profile.DisplayName
+	}
+
+	unlockLink := fmt.Sprintf("%s/unlock-account?token=%s", cfg.Email.FrontendURL, token)
+	outboxEvent, err := newOutboxEvent(user.ID, &events.AccountUnlockRequested{
+		Email:            user.Email,
+		Name:             displayName,
+		UnlockLink:       unlockLink,
+		ExpiresInMinutes: int(cfg.Email.AccountUnlockExpiry.Minutes()),
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.outboxRepo.Create(ctx, outboxEvent); err != nil {
+		return fmt.Errorf("failed to create outbox event: %w", err)
+	}
+
+	s.audit(ctx, email, "account.unlock_requested", map[string]any{"email": email})
+	return nil
+}
+
+// ConfirmUnlock lifts the lockout of the account an emailed unlock token was issued for.
+func (s *lockoutService) ConfirmUnlock(ctx context.Context, token string) error {
+	email, err := s.lockoutCache.ConsumeUnlockToken(ctx, utils.HashToken(token))
+	if err != nil {
+		return err
+	}
+	if email == "" {
+		return errors.NewValidationError("Invalid or expired unlock token").WithReason("INVALID_UNLOCK_TOKEN")
+	}
+
+	if err := s.Unlock(ctx, email); err != nil {
+		return err
+	}
+
+	s.audit(ctx, email, "account.unlocked", map[string]any{"email": email, "method": "email"})
+	return nil
+}
+
+func (s *lockoutService) audit(ctx context.Context, email, action string, metadata map[string]any) {
+	_ = s.auditLogRepo.Create(ctx, &models.AuditLog{
+		UserID:    s.userIDByEmail(ctx, email),
+		Action:    action,
+		Metadata:  metadata,
+		CreatedAt: time.Now(),
+	})
+}
+
+// userIDByEmail returns the ID of the account with the email, nil when there is none.
+func (s *lockoutService) userIDByEmail(ctx context.Context, email string) *uuid.UUID {
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil
+	}
+	return &user.ID
+}
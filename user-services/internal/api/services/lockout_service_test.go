@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"testing"
+
+	"user-services/internal/api/repositories"
+	"user-services/internal/cache"
+	"user-services/internal/models"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ductan2/microservice-app/shared/apperr"
+	"github.com/ductan2/microservice-app/shared/events"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+func (r *memoryUsers) GetUserByEmail(ctx context.Context, email string) (models.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return *user, nil
+		}
+	}
+	return models.User{}, gorm.ErrRecordNotFound
+}
+
+// noProfiles has no profile for any user.
+type noProfiles struct {
+	repositories.UserProfileRepository
+}
+
+func (noProfiles) GetByUserID(ctx context.Context, userID uuid.UUID) (*models.UserProfile, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+// recordedOutbox keeps the events written to the outbox.
+type recordedOutbox struct {
+	mu     sync.Mutex
+	events []*models.Outbox
+}
+
+func (r *recordedOutbox) Create(ctx context.Context, event *models.Outbox) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	return nil
+}
+
+// recordedAuditLog keeps the actions written to the audit log.
+type recordedAuditLog struct {
+	repositories.AuditLogRepository
+	actions []string
+}
+
+func (r *recordedAuditLog) Create(ctx context.Context, log *models.AuditLog) error {
+	r.actions = append(r.actions, log.Action)
+	return nil
+}
+
+// recordedSecurityEvents keeps the emitted security events.
+type recordedSecurityEvents struct {
+	events []SecurityEvent
+}
+
+func (r *recordedSecurityEvents) Emit(ctx context.Context, event SecurityEvent) {
+	r.events = append(r.events, event)
+}
+
+// lockoutCode returns the code of a lockout error, "account_locked" or "ip_locked", or
+// "" for other errors.
+func lockoutCode(err error) string {
+	e, ok := apperr.As(err)
+	if !ok || e.Code != apperr.Locked {
+		return ""
+	}
+	details, _ := e.Details.(map[string]any)
+	code, _ := details["code"].(string)
+	return code
+}
+
+type lockoutFixture struct {
+	service        LockoutService
+	user           *models.User
+	outbox         *recordedOutbox
+	audit          *recordedAuditLog
+	securityEvents *recordedSecurityEvents
+}
+
+func newLockoutFixture(t *testing.T) *lockoutFixture {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	user := &models.User{ID: uuid.New(), Email: "ada@example.com", EmailVerified: true, Status: models.StatusActive}
+	f := &lockoutFixture{
+		user:           user,
+		outbox:         &recordedOutbox{},
+		audit:          &recordedAuditLog{},
+		securityEvents: &recordedSecurityEvents{},
+	}
+	f.service = NewLockoutService(
+		cache.NewLockoutCache(client),
+		&memoryUsers{users: map[uuid.UUID]*models.User{user.ID: user}},
+		noProfiles{},
+		f.outbox,
+		f.audit,
+		f.securityEvents,
+	)
+	return f
+}
+
+// lockAccount fails the login of the account until it locks, which takes the
+// configured five attempts.
+func (f *lockoutFixture) lockAccount(t *testing.T, email string) {
+	t.Helper()
+	ctx := context.Background()
+
+	for i := 1; i <= 5; i++ {
+		err := f.service.RecordFailure(ctx, email, "")
+		if i < 5 && err != nil {
+			t.Fatalf("failure %d: %v", i, err)
+		}
+		if i == 5 && lockoutCode(err) != "account_locked" {
+			t.Fatalf("failure %d: error = %v, want a lockout", i, err)
+		}
+	}
+}
+
+func TestLockoutServiceLocksAccount(t *testing.T) {
+	ctx := context.Background()
+	f := newLockoutFixture(t)
+
+	// Emails are compared normalized, so case and spacing do not reset the count.
+	f.lockAccount(t, " Ada@Example.com ")
+
+	if err := f.service.Check(ctx, "ada@example.com", "198.51.100.20"); lockoutCode(err) != "account_locked" {
+		t.Fatalf("Check = %v, want an account lockout", err)
+	}
+	if err := f.service.Check(ctx, "grace@example.com", "198.51.100.20"); err != nil {
+		t.Fatalf("Check of another account = %v, want nil", err)
+	}
+
+	if len(f.securityEvents.events) != 1 || f.securityEvents.events[0].Type != SecurityEventAccountLockedOut {
+		t.Fatalf("security events = %+v, want one %s", f.securityEvents.events, SecurityEventAccountLockedOut)
+	}
+	if event := f.securityEvents.events[0]; event.UserID == nil || *event.UserID != f.user.ID {
+		t.Fatalf("security event user = %v, want %s", event.UserID, f.user.ID)
+	}
+	if len(f.audit.actions) != 1 || f.audit.actions[0] != "account.locked_out" {
+		t.Fatalf("audit actions = %v, want [account.locked_out]", f.audit.actions)
+	}
+}
+
+func TestLockoutServiceLocksIP(t *testing.T) {
+	ctx := context.Background()
+	f := newLockoutFixture(t)
+
+	// Spread over accounts so only the IP reaches its threshold of 20.
+	var err error
+	for i := 0; i < 20; i++ {
+		err = f.service.RecordFailure(ctx, uuid.NewString()+"@example.com", "203.0.113.7")
+	}
+	if lockoutCode(err) != "ip_locked" {
+		t.Fatalf("20th failure: error = %v, want a lockout", err)
+	}
+
+	if err := f.service.Check(ctx, "ada@example.com", "203.0.113.7"); lockoutCode(err) != "ip_locked" {
+		t.Fatalf("Check from the locked IP = %v, want a lockout", err)
+	}
+	if err := f.service.Check(ctx, "ada@example.com", "198.51.100.20"); err != nil {
+		t.Fatalf("Check from another IP = %v, want nil", err)
+	}
+}
+
+// TestLockoutServiceRecordSuccess checks that a successful login resets the account's
+// failure count.
+func TestLockoutServiceRecordSuccess(t *testing.T) {
+	ctx := context.Background()
+	f := newLockoutFixture(t)
+
+	for i := 0; i < 4; i++ {
+		if err := f.service.RecordFailure(ctx, "ada@example.com", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.service.RecordSuccess(ctx, "ada@example.com"); err != nil {
+		t.Fatalf("RecordSuccess: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		if err := f.service.RecordFailure(ctx, "ada@example.com", ""); err != nil {
+			t.Fatalf("failure %d after success: %v", i+1, err)
+		}
+	}
+}
+
+// TestLockoutServiceSelfUnlock walks the emailed unlock: the link's token lifts the
+// lockout once.
+func TestLockoutServiceSelfUnlock(t *testing.T) {
+	ctx := context.Background()
+	f := newLockoutFixture(t)
+
+	f.lockAccount(t, "ada@example.com")
+	if err := f.service.RequestUnlock(ctx, "ada@example.com"); err != nil {
+		t.Fatalf("RequestUnlock: %v", err)
+	}
+	if len(f.outbox.events) != 1 {
+		t.Fatalf("outbox events = %d, want 1", len(f.outbox.events))
+	}
+
+	var requested events.AccountUnlockRequested
+	if err := events.Unmarshal(f.outbox.events[0].Payload, &requested); err != nil {
+		t.Fatalf("decode unlock event: %v", err)
+	}
+	link, err := url.Parse(requested.UnlockLink)
+	if err != nil {
+		t.Fatalf("unlock link %q: %v", requested.UnlockLink, err)
+	}
+	token := link.Query().Get("token")
+
+	if err := f.service.ConfirmUnlock(ctx, token); err != nil {
+		t.Fatalf("ConfirmUnlock: %v", err)
+	}
+	if err := f.service.Check(ctx, "ada@example.com", ""); err != nil {
+		t.Fatalf("Check after unlock = %v, want nil", err)
+	}
+	if err := f.service.ConfirmUnlock(ctx, token); !apperr.HasReason(err, "INVALID_UNLOCK_TOKEN") {
+		t.Fatalf("reused unlock token: error = %v, want INVALID_UNLOCK_TOKEN", err)
+	}
+}
+
+// TestLockoutServiceRequestUnlockIsSilent checks that unlock requests for accounts that
+// are not locked, unknown or unverified succeed without sending anything.
+func TestLockoutServiceRequestUnlockIsSilent(t *testing.T) {
+	ctx := context.Background()
+	f := newLockoutFixture(t)
+
+	if err := f.service.RequestUnlock(ctx, "ada@example.com"); err != nil {
+		t.Fatalf("RequestUnlock of an unlocked account: %v", err)
+	}
+
+	f.lockAccount(t, "grace@example.com")
+	if err := f.service.RequestUnlock(ctx, "grace@example.com"); err != nil {
+		t.Fatalf("RequestUnlock of an unknown account: %v", err)
+	}
+
+	f.user.EmailVerified = false
+	f.lockAccount(t, "ada@example.com")
+	if err := f.service.RequestUnlock(ctx, "ada@example.com"); err != nil {
+		t.Fatalf("RequestUnlock of an unverified account: %v", err)
+	}
+
+	if len(f.outbox.events) != 0 {
+		t.Fatalf("outbox events = %d, want none", len(f.outbox.events))
+	}
+}
@@ -7,9 +7,11 @@ import (
 	"time"
 	"user-services/internal/api/dto"
 	"user-services/internal/api/repositories"
+	customerrors "user-services/internal/errors"
 	"user-services/internal/models"
 	"user-services/internal/utils"
 
+	"github.com/ductan2/microservice-app/shared/ids"
 	"github.com/google/uuid"
 	"github.com/pquerna/otp"
 	"github.com/pquerna/otp/totp"
@@ -21,20 +23,24 @@ type MFAService interface {
 	VerifyMFALogin(ctx context.Context, userID uuid.UUID, code, secret string) error
 	DisableMFA(ctx context.Context, userID, methodID uuid.UUID, password string) error
 	GetUserMFAMethods(ctx context.Context, userID uuid.UUID) ([]dto.MFASetupResponse, error)
+	ReorderMFAMethods(ctx context.Context, userID uuid.UUID, methodIDs []uuid.UUID) ([]dto.MFASetupResponse, error)
 }
 
 type mfaService struct {
-	mfaRepo  repositories.MFARepository
-	userRepo repositories.UserRepository
+	mfaRepo      repositories.MFARepository
+	userRepo     repositories.UserRepository
+	auditLogRepo repositories.AuditLogRepository
 }
 
 func NewMFAService(
 	mfaRepo repositories.MFARepository,
 	userRepo repositories.UserRepository,
+	auditLogRepo repositories.AuditLogRepository,
 ) MFAService {
 	return &mfaService{
-		mfaRepo:  mfaRepo,
-		userRepo: userRepo,
+		mfaRepo:      mfaRepo,
+		userRepo:     userRepo,
+		auditLogRepo: auditLogRepo,
 	}
 }
 
@@ -51,12 +57,18 @@ func (s *mfaService) SetupMFA(ctx context.Context, userID uuid.UUID, mfaType str
 			return nil, err
 		}
 
+		existing, err := s.mfaRepo.GetByUserID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+
 		m := &models.MFAMethod{
-			ID:     uuid.New(),
+			ID:     ids.New(),
 			UserID: userID,
 			Type:   "totp",
 			Label:  label,
 			Secret: key.Secret(),
+			Priority: len(existing),
 			LastUsedAt: sql.NullTime{},
 		}
 
@@ -84,10 +96,14 @@ func (s *mfaService) VerifyMFASetup(ctx context.Context, userID, methodID uuid.U
 		if !totp.Validate(code, m.Secret) {
 			return errors.New("invalid code")
 		}
+		firstUse := !m.LastUsedAt.Valid
 		m.LastUsedAt = sql.NullTime{Time: time.Now(), Valid: true}
 		if err := s.mfaRepo.UpdateLastUsed(ctx, m.ID); err != nil {
 			return err
 		}
+		if firstUse {
+			s.audit(ctx, userID, "mfa.totp.enabled", map[string]any{"method_id": m.ID, "label": m.Label})
+		}
 		return nil
 	}
 	return errors.New("unsupported type")
@@ -108,7 +124,15 @@ func (s *mfaService) DisableMFA(ctx context.Context, userID, methodID uuid.UUID,
 	if err := utils.CheckPassword(user.PasswordHash, password); err != nil {
 		return errors.New("invalid password")
 	}
-	return s.mfaRepo.Delete(ctx, methodID)
+	m, err := s.mfaRepo.GetByID(ctx, methodID)
+	if err != nil || m.UserID != userID {
+		return errors.New("method not found")
+	}
+	if err := s.mfaRepo.Delete(ctx, methodID); err != nil {
+		return err
+	}
+	s.audit(ctx, userID, "mfa.disabled", map[string]any{"method_id": m.ID, "type": m.Type})
+	return nil
 }
 
 // Get List MFA of a user
@@ -120,12 +144,56 @@ func (s *mfaService) GetUserMFAMethods(ctx context.Context, userID uuid.UUID) ([
 
 	resp := make([]dto.MFASetupResponse, 0)
 	for _, m := range methods {
-		resp = append(resp, dto.MFASetupResponse{
+		item := dto.MFASetupResponse{
 			ID:     m.ID,
 			Type:   m.Type,
 			Label:  m.Label,
+			Priority: m.Priority,
 			AddedAt: m.AddedAt.Format(time.RFC3339),
-		})
+		}
+		if m.Type == models.MFATypePhone {
+			item.PhoneNumber = maskPhoneNumber(m.PhoneNumber)
+		}
+		resp = append(resp, item)
 	}
 	return resp, nil
+}
+
+// ReorderMFAMethods sets the order in which a user's methods are offered at login: the
+// first is the primary method and the rest are fallbacks. methodIDs must list every
+// method the user has, each exactly once.
+func (s *mfaService) ReorderMFAMethods(ctx context.Context, userID uuid.UUID, methodIDs []uuid.UUID) ([]dto.MFASetupResponse, error) {
+	methods, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	owned := make(map[uuid.UUID]bool, len(methods))
+	for _, m := range methods {
+		owned[m.ID] = true
+	}
+	if len(methodIDs) != len(methods) {
+		return nil, customerrors.NewValidationError("method_ids must list every MFA method exactly once").WithReason("INVALID_MFA_ORDER")
+	}
+	for _, id := range methodIDs {
+		if !owned[id] {
+			return nil, customerrors.NewValidationError("method_ids must list every MFA method exactly once").WithReason("INVALID_MFA_ORDER")
+		}
+		delete(owned, id)
+	}
+
+	if err := s.mfaRepo.UpdatePriorities(ctx, userID, methodIDs); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, userID, "mfa.methods.reordered", map[string]any{"method_ids": methodIDs})
+	return s.GetUserMFAMethods(ctx, userID)
+}
+
+func (s *mfaService) audit(ctx context.Context, userID uuid.UUID, action string, metadata map[string]any) {
+	_ = s.auditLogRepo.Create(ctx, &models.AuditLog{
+		UserID:    &userID,
+		Action:    action,
+		Metadata:  metadata,
+		CreatedAt: time.Now(),
+	})
 }
\ No newline at end of file
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/repositories"
+	"user-services/internal/errors"
+	"user-services/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OnboardingSourceUser and OnboardingSourceSystem name who moved an onboarding step:
+// the app on the user's behalf, or this service from the account's own data. Steps
+// reported by other services carry the service's name.
+const (
+	OnboardingSourceUser   = "user"
+	OnboardingSourceSystem = "system"
+)
+
+// OnboardingService tracks the first-run steps of a new user so every client walks them
+// through the same experience. email_verified and profile_completed are derived from the
+// account and recorded as soon as they hold; the app or the lesson service reports
+// level_test_taken and first_lesson_started. Every transition is published as a
+// user.onboarding_step_advanced event, and the one finishing the last pending step as
+// user.onboarding_completed.
+type OnboardingService interface {
+	GetOnboarding(ctx context.Context, userID uuid.UUID) (*dto.OnboardingResponse, error)
+	// AdvanceStep completes or skips a step. A derived step can only be completed once
+	// the account satisfies it, and only the level test can be skipped. Advancing a step
+	// that is already completed changes nothing.
+	AdvanceStep(ctx context.Context, userID uuid.UUID, step, status, source string) (*dto.OnboardingResponse, error)
+}
+
+type onboardingService struct {
+	onboardingRepo repositories.OnboardingRepository
+	userRepo       repositories.UserRepository
+}
+
+func NewOnboardingService(onboardingRepo repositories.OnboardingRepository, userRepo repositories.UserRepository) OnboardingService {
+	return &onboardingService{
+		onboardingRepo: onboardingRepo,
+		userRepo:       userRepo,
+	}
+}
+
+func (s *onboardingService) GetOnboarding(ctx context.Context, userID uuid.UUID) (*dto.OnboardingResponse, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	steps, err := s.syncDerived(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return toOnboardingResponse(steps), nil
+}
+
+func (s *onboardingService) AdvanceStep(ctx context.Context, userID uuid.UUID, step, status, source string) (*dto.OnboardingResponse, error) {
+	if !slices.Contains(models.OnboardingSteps, step) {
+		return nil, errors.NewValidationError("Unknown onboarding step").WithReason("UNKNOWN_ONBOARDING_STEP")
+	}
+	if status == "" {
+		status = models.OnboardingStatusCompleted
+	}
+	if status == models.OnboardingStatusSkipped && step != models.OnboardingStepLevelTestTaken {
+		return nil, errors.NewValidationError("Only the level test can be skipped").WithReason("ONBOARDING_STEP_NOT_SKIPPABLE")
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	steps, err := s.syncDerived(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if met, derived := derivedStepMet(user, step); derived {
+		// syncDerived has already recorded it if the account satisfies it
+		if !met {
+			return nil, errors.ErrOnboardingStepNotMet
+		}
+		return toOnboardingResponse(steps), nil
+	}
+
+	steps, err = s.advance(ctx, userID, step, status, source)
+	if err != nil {
+		return nil, err
+	}
+	return toOnboardingResponse(steps), nil
+}
+
+// syncDerived records the derived steps the account satisfies but that were not recorded
+// yet, and returns every recorded step.
+func (s *onboardingService) syncDerived(ctx context.Context, user *models.User) ([]models.OnboardingStep, error) {
+	steps, err := s.onboardingRepo.ListByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, step := range []string{models.OnboardingStepEmailVerified, models.OnboardingStepProfileCompleted} {
+		if met, _ := derivedStepMet(user, step); !met || findOnboardingStep(steps, step) != nil {
+			continue
+		}
+		if steps, err = s.advance(ctx, user.ID, step, models.OnboardingStatusCompleted, OnboardingSourceSystem); err != nil {
+			return nil, err
+		}
+	}
+	return steps, nil
+}
+
+// advance records the step and publishes the transition.
+func (s *onboardingService) advance(ctx context.Context, userID uuid.UUID, step, status, source string) ([]models.OnboardingStep, error) {
+	now := time.Now()
+	record := &models.OnboardingStep{
+		UserID:      userID,
+		Step:        step,
+		Status:      status,
+		Source:      source,
+		CompletedAt: now,
+	}
+
+	_, steps, err := s.onboardingRepo.Advance(ctx, record, func(previous string, steps []models.OnboardingStep) ([]*models.Outbox, error) {
+		progress := toOnboardingResponse(steps)
+		payload, err := json.Marshal(map[string]any{
+			"user_id":         userID,
+			"step":            step,
+			"from":            previous,
+			"to":              status,
+			"source":          source,
+			"occurred_at":     now.UTC(),
+			"current_step":    progress.CurrentStep,
+			"completed_steps": progress.CompletedSteps,
+			"total_steps":     progress.TotalSteps,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal payload: %w", err)
+		}
+		events := []*models.Outbox{{
+			AggregateID: userID,
+			Topic:       "user.onboarding_step_advanced",
+			Type:        "UserOnboardingStepAdvanced",
+			Payload:     payload,
+			CreatedAt:   now,
+		}}
+
+		// Completing a skipped step does not finish onboarding a second time
+		if progress.Status == models.OnboardingStatusCompleted && previous == models.OnboardingStatusPending {
+			var skipped []string
+			for _, item := range progress.Steps {
+				if item.Status == models.OnboardingStatusSkipped {
+					skipped = append(skipped, item.Step)
+				}
+			}
+			payload, err := json.Marshal(map[string]any{
+				"user_id":       userID,
+				"completed_at":  now.UTC(),
+				"skipped_steps": skipped,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal payload: %w", err)
+			}
+			events = append(events, &models.Outbox{
+				AggregateID: userID,
+				Topic:       "user.onboarding_completed",
+				Type:        "UserOnboardingCompleted",
+				Payload:     payload,
+				CreatedAt:   now,
+			})
+		}
+		return events, nil
+	})
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to advance onboarding step: %w", err)
+	}
+	return steps, nil
+}
+
+func (s *onboardingService) getUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, err
+	}
+	if user.Status == models.StatusDeleted || user.MergedIntoID != nil {
+		return nil, errors.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// derivedStepMet reports whether the account satisfies a step derived from it, and
+// whether the step is derived at all.
+func derivedStepMet(user *models.User, step string) (met bool, derived bool) {
+	switch step {
+	case models.OnboardingStepEmailVerified:
+		return user.EmailVerified, true
+	case models.OnboardingStepProfileCompleted:
+		return strings.TrimSpace(user.Profile.DisplayName) != "", true
+	}
+	return false, false
+}
+
+func findOnboardingStep(steps []models.OnboardingStep, step string) *models.OnboardingStep {
+	for i := range steps {
+		if steps[i].Step == step {
+			return &steps[i]
+		}
+	}
+	return nil
+}
+
+// toOnboardingResponse lists every step in order, pending ones included.
+func toOnboardingResponse(steps []models.OnboardingStep) *dto.OnboardingResponse {
+	resp := &dto.OnboardingResponse{
+		Status:     models.OnboardingStatusInProgress,
+		TotalSteps: len(models.OnboardingSteps),
+		Steps:      make([]dto.OnboardingStepResponse, 0, len(models.OnboardingSteps)),
+	}
+
+	var lastAt time.Time
+	for _, name := range models.OnboardingSteps {
+		step := findOnboardingStep(steps, name)
+		if step == nil {
+			if resp.CurrentStep == "" {
+				resp.CurrentStep = name
+			}
+			resp.Steps = append(resp.Steps, dto.OnboardingStepResponse{Step: name, Status: models.OnboardingStatusPending})
+			continue
+		}
+
+		completedAt := step.CompletedAt
+		resp.Steps = append(resp.Steps, dto.OnboardingStepResponse{
+			Step:        name,
+			Status:      step.Status,
+			Source:      step.Source,
+			CompletedAt: &completedAt,
+		})
+		resp.CompletedSteps++
+		if completedAt.After(lastAt) {
+			lastAt = completedAt
+		}
+	}
+
+	if resp.CompletedSteps == resp.TotalSteps {
+		resp.Status = models.OnboardingStatusCompleted
+		resp.CompletedAt = &lastAt
+	}
+	return resp
+}
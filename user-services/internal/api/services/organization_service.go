@@ -0,0 +1,492 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/repositories"
+	"user-services/internal/errors"
+	"user-services/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OrganizationService manages organizations and their members. Creating, listing and
+// updating organizations is left to platform admins, which the BFF enforces. Everything
+// scoped to one organization is authorized here against the caller's membership:
+// platform admins and owners manage every member, managers manage learners only, and
+// any member may view the organization or leave it. Membership changes reach access
+// tokens on their next refresh.
+type OrganizationService interface {
+	CreateOrganization(ctx context.Context, req dto.CreateOrganizationRequest) (*dto.OrganizationResponse, error)
+	ListOrganizations(ctx context.Context, query dto.OrganizationQuery) (*dto.PaginatedResponse, error)
+	UpdateOrganization(ctx context.Context, orgID uuid.UUID, req dto.UpdateOrganizationRequest) (*dto.OrganizationResponse, error)
+	GetOrganization(ctx context.Context, callerID, orgID uuid.UUID) (*dto.OrganizationResponse, error)
+	ListMembers(ctx context.Context, callerID, orgID uuid.UUID, query dto.OrganizationMemberQuery) (*dto.PaginatedResponse, error)
+	AddMember(ctx context.Context, callerID, orgID uuid.UUID, req dto.AddOrganizationMemberRequest) (*dto.OrganizationMemberResponse, error)
+	UpdateMemberRole(ctx context.Context, callerID, orgID, userID uuid.UUID, role string) (*dto.OrganizationMemberResponse, error)
+	RemoveMember(ctx context.Context, callerID, orgID, userID uuid.UUID) error
+	// ListMyOrganizations returns the organizations the caller belongs to.
+	ListMyOrganizations(ctx context.Context, userID uuid.UUID) ([]dto.OrganizationMembershipResponse, error)
+}
+
+type organizationService struct {
+	orgRepo      repositories.OrganizationRepository
+	userRepo     repositories.UserRepository
+	auditLogRepo repositories.AuditLogRepository
+}
+
+func NewOrganizationService(
+	orgRepo repositories.OrganizationRepository,
+	userRepo repositories.UserRepository,
+	auditLogRepo repositories.AuditLogRepository,
+) OrganizationService {
+	return &organizationService{
+		orgRepo:      orgRepo,
+		userRepo:     userRepo,
+		auditLogRepo: auditLogRepo,
+	}
+}
+
+// orgAccess is what the caller may do in one organization.
+type orgAccess struct {
+	admin bool   // platform admin, whether or not they are a member
+	role  string // membership role, empty for non-members
+}
+
+// canManage reports whether the caller may add, change or remove a member with role.
+func (a orgAccess) canManage(role string) bool {
+	return a.admin || a.role == models.OrgRoleOwner || (a.role == models.OrgRoleManager && role == models.OrgRoleLearner)
+}
+
+func (s *organizationService) CreateOrganization(ctx context.Context, req dto.CreateOrganizationRequest) (*dto.OrganizationResponse, error) {
+	org := &models.Organization{
+		Name:      strings.TrimSpace(req.Name),
+		SeatLimit: req.SeatLimit,
+	}
+	if org.Name == "" {
+		return nil, errors.NewValidationError("name must not be blank")
+	}
+
+	var owner *models.OrganizationMember
+	if req.OwnerID != "" {
+		ownerID, err := uuid.Parse(req.OwnerID)
+		if err != nil {
+			return nil, errors.NewValidationError("owner_id must be a UUID")
+		}
+		if _, err := s.userRepo.GetByID(ctx, ownerID); err != nil {
+			return nil, errors.ErrUserNotFound
+		}
+		owner = &models.OrganizationMember{UserID: ownerID, Role: models.OrgRoleOwner}
+	}
+
+	created, err := s.orgRepo.Create(ctx, org, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+	if !created {
+		return nil, errors.ErrOrganizationExists
+	}
+
+	metadata := map[string]any{
+		"organization_id": org.ID,
+		"name":            org.Name,
+		"seat_limit":      org.SeatLimit,
+	}
+	if owner != nil {
+		metadata["owner_id"] = owner.UserID
+	}
+	s.audit(ctx, nil, "organization.created", metadata)
+
+	return s.toResponse(ctx, org)
+}
+
+func (s *organizationService) ListOrganizations(ctx context.Context, query dto.OrganizationQuery) (*dto.PaginatedResponse, error) {
+	page, pageSize := pageBounds(query.Page, query.PageSize)
+	orgs, total, err := s.orgRepo.List(ctx, repositories.OrganizationFilter{
+		Search: strings.TrimSpace(query.Search),
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.OrganizationResponse, 0, len(orgs))
+	for idx := range orgs {
+		resp, err := s.toResponse(ctx, &orgs[idx])
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *resp)
+	}
+
+	return &dto.PaginatedResponse{
+		Data:       responses,
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      int(total),
+		TotalPages: int(math.Ceil(float64(total) / float64(pageSize))),
+	}, nil
+}
+
+func (s *organizationService) UpdateOrganization(ctx context.Context, orgID uuid.UUID, req dto.UpdateOrganizationRequest) (*dto.OrganizationResponse, error) {
+	org, err := s.getOrganization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]any{}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, errors.NewValidationError("name must not be blank")
+		}
+		if !strings.EqualFold(name, org.Name) {
+			existing, err := s.orgRepo.GetByName(ctx, name)
+			if err != nil && !stderrors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, err
+			}
+			if existing != nil {
+				return nil, errors.ErrOrganizationExists
+			}
+		}
+		if name != org.Name {
+			changes["name"] = map[string]any{"from": org.Name, "to": name}
+			org.Name = name
+		}
+	}
+	switch {
+	case req.ClearSeatLimit:
+		if org.SeatLimit != nil {
+			changes["seat_limit"] = map[string]any{"from": *org.SeatLimit, "to": nil}
+		}
+		org.SeatLimit = nil
+	case req.SeatLimit != nil:
+		if org.SeatLimit == nil || *org.SeatLimit != *req.SeatLimit {
+			changes["seat_limit"] = map[string]any{"from": org.SeatLimit, "to": *req.SeatLimit}
+		}
+		org.SeatLimit = req.SeatLimit
+	}
+
+	if len(changes) > 0 {
+		if err := s.orgRepo.Update(ctx, org); err != nil {
+			if stderrors.Is(err, repositories.ErrSeatLimitBelowUsage) {
+				return nil, errors.ErrSeatLimitBelowUsage
+			}
+			return nil, fmt.Errorf("failed to update organization: %w", err)
+		}
+		changes["organization_id"] = org.ID
+		s.audit(ctx, nil, "organization.updated", changes)
+	}
+
+	return s.toResponse(ctx, org)
+}
+
+func (s *organizationService) GetOrganization(ctx context.Context, callerID, orgID uuid.UUID) (*dto.OrganizationResponse, error) {
+	org, err := s.getOrganization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.access(ctx, callerID, orgID); err != nil {
+		return nil, err
+	}
+	return s.toResponse(ctx, org)
+}
+
+func (s *organizationService) ListMembers(ctx context.Context, callerID, orgID uuid.UUID, query dto.OrganizationMemberQuery) (*dto.PaginatedResponse, error) {
+	org, err := s.getOrganization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	access, err := s.access(ctx, callerID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !access.canManage(models.OrgRoleLearner) {
+		return nil, errors.ErrOrganizationForbidden
+	}
+
+	page, pageSize := pageBounds(query.Page, query.PageSize)
+	members, total, err := s.orgRepo.ListMembers(ctx, org.ID, repositories.OrganizationMemberFilter{
+		Role:   query.Role,
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.OrganizationMemberResponse, 0, len(members))
+	for _, member := range members {
+		responses = append(responses, toMemberResponse(member, &member.User))
+	}
+
+	return &dto.PaginatedResponse{
+		Data:       responses,
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      int(total),
+		TotalPages: int(math.Ceil(float64(total) / float64(pageSize))),
+	}, nil
+}
+
+func (s *organizationService) AddMember(ctx context.Context, callerID, orgID uuid.UUID, req dto.AddOrganizationMemberRequest) (*dto.OrganizationMemberResponse, error) {
+	org, err := s.getOrganization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	access, err := s.access(ctx, callerID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !access.canManage(req.Role) {
+		return nil, errors.ErrOrganizationForbidden
+	}
+
+	var user *models.User
+	if req.UserID != "" {
+		userID, parseErr := uuid.Parse(req.UserID)
+		if parseErr != nil {
+			return nil, errors.NewValidationError("user_id must be a UUID")
+		}
+		user, err = s.userRepo.GetByID(ctx, userID)
+	} else {
+		user, err = s.userRepo.GetByEmail(ctx, strings.ToLower(strings.TrimSpace(req.Email)))
+	}
+	if err != nil || user.Status == models.StatusDeleted {
+		return nil, errors.ErrUserNotFound
+	}
+
+	member := &models.OrganizationMember{
+		OrganizationID: org.ID,
+		UserID:         user.ID,
+		Role:           req.Role,
+	}
+	if err := s.orgRepo.AddMember(ctx, member); err != nil {
+		return nil, memberError(err)
+	}
+	if user.OrganizationID == nil {
+		user.OrganizationID = &org.ID
+	}
+
+	s.audit(ctx, &user.ID, "organization.member_added", map[string]any{
+		"organization_id": org.ID,
+		"role":            member.Role,
+	})
+
+	resp := toMemberResponse(*member, user)
+	return &resp, nil
+}
+
+func (s *organizationService) UpdateMemberRole(ctx context.Context, callerID, orgID, userID uuid.UUID, role string) (*dto.OrganizationMemberResponse, error) {
+	member, access, err := s.getMember(ctx, callerID, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !access.canManage(member.Role) || !access.canManage(role) {
+		return nil, errors.ErrOrganizationForbidden
+	}
+
+	previous := member.Role
+	member, err = s.orgRepo.UpdateMemberRole(ctx, orgID, userID, role)
+	if err != nil {
+		return nil, memberError(err)
+	}
+	if previous != member.Role {
+		s.audit(ctx, &userID, "organization.member_role_changed", map[string]any{
+			"organization_id": orgID,
+			"from":            previous,
+			"to":              member.Role,
+		})
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.ErrUserNotFound
+	}
+	resp := toMemberResponse(*member, user)
+	return &resp, nil
+}
+
+func (s *organizationService) RemoveMember(ctx context.Context, callerID, orgID, userID uuid.UUID) error {
+	member, access, err := s.getMember(ctx, callerID, orgID, userID)
+	if err != nil {
+		return err
+	}
+	if callerID != userID && !access.canManage(member.Role) {
+		return errors.ErrOrganizationForbidden
+	}
+
+	if err := s.orgRepo.RemoveMember(ctx, orgID, userID); err != nil {
+		return memberError(err)
+	}
+
+	s.audit(ctx, &userID, "organization.member_removed", map[string]any{
+		"organization_id": orgID,
+		"role":            member.Role,
+		"left":            callerID == userID,
+	})
+	return nil
+}
+
+func (s *organizationService) ListMyOrganizations(ctx context.Context, userID uuid.UUID) ([]dto.OrganizationMembershipResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.ErrUserNotFound
+	}
+	members, err := s.orgRepo.ListUserMemberships(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.OrganizationMembershipResponse, 0, len(members))
+	for _, member := range members {
+		responses = append(responses, dto.OrganizationMembershipResponse{
+			OrganizationID: member.OrganizationID,
+			Name:           member.Organization.Name,
+			Role:           member.Role,
+			Primary:        user.OrganizationID != nil && *user.OrganizationID == member.OrganizationID,
+			JoinedAt:       member.CreatedAt,
+		})
+	}
+	return responses, nil
+}
+
+func (s *organizationService) getOrganization(ctx context.Context, orgID uuid.UUID) (*models.Organization, error) {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+	return org, nil
+}
+
+func (s *organizationService) access(ctx context.Context, callerID, orgID uuid.UUID) (orgAccess, error) {
+	return resolveOrgAccess(ctx, s.userRepo, s.orgRepo, callerID, orgID)
+}
+
+// resolveOrgAccess resolves the caller's rights in the organization. Callers that are
+// neither members nor platform admins are told it does not exist.
+func resolveOrgAccess(ctx context.Context, userRepo repositories.UserRepository, orgRepo repositories.OrganizationRepository, callerID, orgID uuid.UUID) (orgAccess, error) {
+	caller, err := userRepo.GetByID(ctx, callerID)
+	if err != nil {
+		return orgAccess{}, errors.ErrUserNotFound
+	}
+	access := orgAccess{admin: isPlatformAdmin(caller)}
+
+	member, err := orgRepo.GetMember(ctx, orgID, callerID)
+	switch {
+	case err == nil:
+		access.role = member.Role
+	case !stderrors.Is(err, gorm.ErrRecordNotFound):
+		return orgAccess{}, err
+	case !access.admin:
+		return orgAccess{}, errors.ErrOrganizationNotFound
+	}
+	return access, nil
+}
+
+func isPlatformAdmin(user *models.User) bool {
+	return user.Role == models.RoleAdmin || user.Role == models.RoleSuperAdmin
+}
+
+// getMember resolves the caller's rights and the membership they act on.
+func (s *organizationService) getMember(ctx context.Context, callerID, orgID, userID uuid.UUID) (*models.OrganizationMember, orgAccess, error) {
+	if _, err := s.getOrganization(ctx, orgID); err != nil {
+		return nil, orgAccess{}, err
+	}
+	access, err := s.access(ctx, callerID, orgID)
+	if err != nil {
+		return nil, orgAccess{}, err
+	}
+
+	member, err := s.orgRepo.GetMember(ctx, orgID, userID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, orgAccess{}, errors.ErrOrganizationMemberNotFound
+		}
+		return nil, orgAccess{}, err
+	}
+	return member, access, nil
+}
+
+func (s *organizationService) toResponse(ctx context.Context, org *models.Organization) (*dto.OrganizationResponse, error) {
+	counts, err := s.orgRepo.CountMembers(ctx, org.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	seats := dto.SeatUsage{Limit: org.SeatLimit, Used: counts[models.OrgRoleLearner]}
+	if org.SeatLimit != nil {
+		available := max(*org.SeatLimit-int(seats.Used), 0)
+		seats.Available = &available
+	}
+	return &dto.OrganizationResponse{
+		ID:        org.ID,
+		Name:      org.Name,
+		Members:   counts,
+		Seats:     seats,
+		CreatedAt: org.CreatedAt,
+		UpdatedAt: org.UpdatedAt,
+	}, nil
+}
+
+func (s *organizationService) audit(ctx context.Context, userID *uuid.UUID, action string, metadata map[string]any) {
+	entry := &models.AuditLog{
+		UserID:    userID,
+		Action:    action,
+		Metadata:  metadata,
+		CreatedAt: time.Now(),
+	}
+	if err := s.auditLogRepo.Create(ctx, entry); err != nil {
+		slog.WarnContext(ctx, "failed to write audit log", "action", action, "error", err)
+	}
+}
+
+func toMemberResponse(member models.OrganizationMember, user *models.User) dto.OrganizationMemberResponse {
+	return dto.OrganizationMemberResponse{
+		UserID:      member.UserID,
+		Email:       user.Email,
+		DisplayName: user.Profile.DisplayName,
+		Status:      user.Status,
+		Role:        member.Role,
+		Primary:     user.OrganizationID != nil && *user.OrganizationID == member.OrganizationID,
+		JoinedAt:    member.CreatedAt,
+	}
+}
+
+// memberError maps the repository's refusals to application errors.
+func memberError(err error) error {
+	switch {
+	case stderrors.Is(err, gorm.ErrRecordNotFound):
+		return errors.ErrOrganizationMemberNotFound
+	case stderrors.Is(err, repositories.ErrAlreadyMember):
+		return errors.ErrOrganizationMemberExists
+	case stderrors.Is(err, repositories.ErrOrganizationFull):
+		return errors.ErrOrganizationFull
+	case stderrors.Is(err, repositories.ErrLastOrganizationOwner):
+		return errors.ErrLastOrganizationOwner
+	}
+	return err
+}
+
+// pageBounds applies the default and maximum page size to a page request.
+func pageBounds(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	return page, min(pageSize, 100)
+}
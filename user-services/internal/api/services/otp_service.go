@@ -0,0 +1,357 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"time"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/repositories"
+	"user-services/internal/cache"
+	"user-services/internal/config"
+	"user-services/internal/errors"
+	"user-services/internal/models"
+	"user-services/internal/otp"
+	"user-services/internal/utils"
+
+	"github.com/ductan2/microservice-app/shared/ids"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OTPService manages phone MFA: it registers and confirms phone numbers and sends and
+// checks one-time codes by SMS or voice call. Codes are stored only as HMACs, expire
+// after OTP_CODE_TTL and allow OTP_MAX_ATTEMPTS guesses; sends are throttled per number.
+type OTPService interface {
+	SetupPhone(ctx context.Context, userID uuid.UUID, req dto.PhoneMFASetupRequest) (*dto.PhoneMFASetupResponse, error)
+	ConfirmPhone(ctx context.Context, userID, methodID uuid.UUID, code string) (*dto.PhoneMFAResponse, error)
+	ResendCode(ctx context.Context, userID, methodID uuid.UUID, channel string) (*dto.OTPSendResponse, error)
+	RevokePhone(ctx context.Context, userID, methodID uuid.UUID, password string) error
+	SendLoginCode(ctx context.Context, method *models.MFAMethod, channel string) (*dto.OTPSendResponse, error)
+	VerifyCode(ctx context.Context, method *models.MFAMethod, code string) (bool, error)
+	// SendVerificationCode and CheckVerificationCode prove that a user controls a phone
+	// number outside of MFA, for example before it can be used to sign in. A code only
+	// verifies for the user and number it was sent for.
+	SendVerificationCode(ctx context.Context, userID uuid.UUID, phone, channel string) (*dto.OTPSendResponse, error)
+	CheckVerificationCode(ctx context.Context, userID uuid.UUID, phone, code string) (bool, error)
+}
+
+type otpService struct {
+	mfaRepo      repositories.MFARepository
+	userRepo     repositories.UserRepository
+	auditLogRepo repositories.AuditLogRepository
+	otpCache     *cache.OTPCache
+	provider     otp.Provider
+}
+
+func NewOTPService(
+	mfaRepo repositories.MFARepository,
+	userRepo repositories.UserRepository,
+	auditLogRepo repositories.AuditLogRepository,
+	otpCache *cache.OTPCache,
+	provider otp.Provider,
+) OTPService {
+	return &otpService{
+		mfaRepo:      mfaRepo,
+		userRepo:     userRepo,
+		auditLogRepo: auditLogRepo,
+		otpCache:     otpCache,
+		provider:     provider,
+	}
+}
+
+// SetupPhone adds a phone number as an unconfirmed MFA method and sends it a code. An
+// earlier number that was never confirmed is replaced; a confirmed one must be revoked
+// first.
+func (s *otpService) SetupPhone(ctx context.Context, userID uuid.UUID, req dto.PhoneMFASetupRequest) (*dto.PhoneMFASetupResponse, error) {
+	existing, err := s.mfaRepo.GetPhoneByUserID(ctx, userID)
+	switch {
+	case err == nil && existing.VerifiedAt.Valid:
+		return nil, errors.ErrPhoneMFAExists
+	case err == nil:
+		if err := s.mfaRepo.Delete(ctx, existing.ID); err != nil {
+			return nil, err
+		}
+	case !stderrors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+
+	methods, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	channel := req.Channel
+	if channel == "" {
+		channel = otp.ChannelSMS
+	}
+	label := strings.TrimSpace(req.Label)
+	if label == "" {
+		label = "Phone"
+	}
+
+	method := &models.MFAMethod{
+		ID:          ids.New(),
+		UserID:      userID,
+		Type:        models.MFATypePhone,
+		Label:       label,
+		PhoneNumber: req.PhoneNumber,
+		OTPChannel:  channel,
+		Priority:    len(methods),
+		AddedAt:     time.Now(),
+	}
+	if err := s.mfaRepo.Create(ctx, method); err != nil {
+		return nil, err
+	}
+
+	delivery, err := s.send(ctx, method, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PhoneMFASetupResponse{
+		Method:   toPhoneMFAResponse(*method),
+		Delivery: *delivery,
+	}, nil
+}
+
+// ConfirmPhone marks a phone number as verified once the user enters the code sent to it.
+func (s *otpService) ConfirmPhone(ctx context.Context, userID, methodID uuid.UUID, code string) (*dto.PhoneMFAResponse, error) {
+	method, err := s.ownedPhone(ctx, userID, methodID)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.VerifyCode(ctx, method, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.ErrInvalidMFACode
+	}
+
+	if !method.VerifiedAt.Valid {
+		if err := s.mfaRepo.MarkVerified(ctx, method.ID); err != nil {
+			return nil, err
+		}
+		method.VerifiedAt.Time, method.VerifiedAt.Valid = time.Now(), true
+		s.audit(ctx, userID, "mfa.phone.verified", map[string]any{
+			"method_id": method.ID,
+			"phone":     maskPhoneNumber(method.PhoneNumber),
+		})
+	}
+
+	resp := toPhoneMFAResponse(*method)
+	return &resp, nil
+}
+
+// ResendCode sends a fresh code to one of the user's phone numbers, for example when
+// the first SMS did not arrive and the user asks for a call instead.
+func (s *otpService) ResendCode(ctx context.Context, userID, methodID uuid.UUID, channel string) (*dto.OTPSendResponse, error) {
+	method, err := s.ownedPhone(ctx, userID, methodID)
+	if err != nil {
+		return nil, err
+	}
+	return s.send(ctx, method, channel)
+}
+
+// RevokePhone removes a phone number from MFA after re-checking the password.
+func (s *otpService) RevokePhone(ctx context.Context, userID, methodID uuid.UUID, password string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return errors.ErrUserNotFound
+	}
+	if err := utils.CheckPassword(user.PasswordHash, password); err != nil {
+		return errors.ErrInvalidCredentials
+	}
+
+	method, err := s.ownedPhone(ctx, userID, methodID)
+	if err != nil {
+		return err
+	}
+	if err := s.mfaRepo.Delete(ctx, method.ID); err != nil {
+		return err
+	}
+
+	s.audit(ctx, userID, "mfa.phone.revoked", map[string]any{
+		"method_id": method.ID,
+		"phone":     maskPhoneNumber(method.PhoneNumber),
+	})
+	return nil
+}
+
+// SendLoginCode sends a login code to a verified phone method. The caller must already
+// have checked the user's password.
+func (s *otpService) SendLoginCode(ctx context.Context, method *models.MFAMethod, channel string) (*dto.OTPSendResponse, error) {
+	if method.Type != models.MFATypePhone || !method.VerifiedAt.Valid {
+		return nil, errors.ErrMFANotSetup
+	}
+	return s.send(ctx, method, channel)
+}
+
+// VerifyCode checks a code sent to a phone method. Wrong guesses count towards
+// OTP_MAX_ATTEMPTS, after which the code is discarded and a new one must be sent.
+func (s *otpService) VerifyCode(ctx context.Context, method *models.MFAMethod, code string) (bool, error) {
+	cfg := config.GetConfig()
+
+	ok, err := s.otpCache.VerifyCode(ctx, method.ID, hashOTPCode(method.ID, code), cfg.OTP.MaxAttempts, cfg.OTP.CodeTTL)
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		_ = s.mfaRepo.UpdateLastUsed(ctx, method.ID)
+	}
+	return ok, nil
+}
+
+func (s *otpService) SendVerificationCode(ctx context.Context, userID uuid.UUID, phone, channel string) (*dto.OTPSendResponse, error) {
+	method := &models.MFAMethod{
+		ID:          phoneVerificationID(userID, phone),
+		UserID:      userID,
+		Type:        models.MFATypePhone,
+		PhoneNumber: phone,
+		OTPChannel:  otp.ChannelSMS,
+	}
+	return s.send(ctx, method, channel)
+}
+
+func (s *otpService) CheckVerificationCode(ctx context.Context, userID uuid.UUID, phone, code string) (bool, error) {
+	cfg := config.GetConfig()
+
+	id := phoneVerificationID(userID, phone)
+	return s.otpCache.VerifyCode(ctx, id, hashOTPCode(id, code), cfg.OTP.MaxAttempts, cfg.OTP.CodeTTL)
+}
+
+// send delivers a new code on channel, or on the method's own channel when empty. If
+// the provider cannot use the channel the other one is tried, so an SMS-only gateway
+// still serves users who prefer calls.
+func (s *otpService) send(ctx context.Context, method *models.MFAMethod, channel string) (*dto.OTPSendResponse, error) {
+	cfg := config.GetConfig()
+
+	if channel == "" {
+		channel = method.OTPChannel
+	}
+	channel = s.deliverableChannel(channel)
+	if channel == "" {
+		return nil, errors.ErrOTPChannelUnsupported
+	}
+
+	retryAfter, err := s.otpCache.ReserveSend(ctx, method.PhoneNumber, cfg.OTP.ResendCooldown, cfg.OTP.MaxSendsPerHour)
+	if err != nil {
+		return nil, err
+	}
+	if retryAfter > 0 {
+		return nil, errors.NewOTPThrottledError(retryAfter)
+	}
+
+	code, err := generateOTPCode(cfg.OTP.CodeLength)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.otpCache.StoreCode(ctx, method.ID, hashOTPCode(method.ID, code), cfg.OTP.CodeTTL); err != nil {
+		return nil, err
+	}
+
+	msg := otp.Message{
+		To:      method.PhoneNumber,
+		Channel: channel,
+		Code:    code,
+		Text: fmt.Sprintf("Your %s verification code is %s. It expires in %d minutes.",
+			cfg.OTP.AppName, code, int(cfg.OTP.CodeTTL.Minutes())),
+	}
+	if err := s.provider.Send(ctx, msg); err != nil {
+		slog.WarnContext(ctx, "failed to send MFA code", "channel", channel, "provider", s.provider.Name(), "mfa_method_id", method.ID, "error", err)
+		return nil, errors.ErrOTPDeliveryFailed
+	}
+
+	return &dto.OTPSendResponse{
+		MethodID:    method.ID,
+		Channel:     channel,
+		PhoneNumber: maskPhoneNumber(method.PhoneNumber),
+		ExpiresAt:   time.Now().Add(cfg.OTP.CodeTTL).UTC(),
+	}, nil
+}
+
+func (s *otpService) deliverableChannel(preferred string) string {
+	for _, channel := range []string{preferred, otp.ChannelSMS, otp.ChannelVoice} {
+		if channel != "" && s.provider.Supports(channel) {
+			return channel
+		}
+	}
+	return ""
+}
+
+func (s *otpService) ownedPhone(ctx context.Context, userID, methodID uuid.UUID) (*models.MFAMethod, error) {
+	method, err := s.mfaRepo.GetByID(ctx, methodID)
+	if err != nil || method.UserID != userID || method.Type != models.MFATypePhone {
+		return nil, errors.ErrMFAMethodNotFound
+	}
+	return method, nil
+}
+
+func (s *otpService) audit(ctx context.Context, userID uuid.UUID, action string, metadata map[string]any) {
+	_ = s.auditLogRepo.Create(ctx, &models.AuditLog{
+		UserID:    &userID,
+		Action:    action,
+		Metadata:  metadata,
+		CreatedAt: time.Now(),
+	})
+}
+
+// generateOTPCode returns a uniformly random numeric code of the given length.
+func generateOTPCode(length int) (string, error) {
+	if length < 4 {
+		length = 6
+	}
+	digits := make([]byte, length)
+	for i := range digits {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		digits[i] = byte('0' + n.Int64())
+	}
+	return string(digits), nil
+}
+
+// hashOTPCode keys the code hash with the server secret and the method, so stored hashes
+// cannot be checked offline or replayed against another method.
+func hashOTPCode(methodID uuid.UUID, code string) string {
+	mac := hmac.New(sha256.New, []byte(config.GetConfig().JWT.Secret))
+	mac.Write([]byte(methodID.String() + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// phoneVerificationID stands in for the MFA method ID when a code verifies a phone number
+// that is not an MFA method, so its codes are stored per user and number.
+func phoneVerificationID(userID uuid.UUID, phone string) uuid.UUID {
+	return uuid.NewSHA1(userID, []byte("phone_verification:"+phone))
+}
+
+// maskPhoneNumber keeps the country prefix and the last two digits: +84*******89.
+func maskPhoneNumber(phone string) string {
+	if len(phone) <= 5 {
+		return phone
+	}
+	return phone[:3] + strings.Repeat("*", len(phone)-5) + phone[len(phone)-2:]
+}
+
+func toPhoneMFAResponse(m models.MFAMethod) dto.PhoneMFAResponse {
+	return dto.PhoneMFAResponse{
+		ID:          m.ID,
+		Label:       m.Label,
+		PhoneNumber: maskPhoneNumber(m.PhoneNumber),
+		Channel:     m.OTPChannel,
+		Verified:    m.VerifiedAt.Valid,
+		Priority:    m.Priority,
+		AddedAt:     m.AddedAt.Format(time.RFC3339),
+	}
+}
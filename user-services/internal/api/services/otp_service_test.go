@@ -0,0 +1,324 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+	"strings"
+	"testing"
+	"time"
+
+	"user-services/internal/api/repositories"
+	"user-services/internal/cache"
+	"user-services/internal/errors"
+	"user-services/internal/models"
+	"user-services/internal/otp"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ductan2/microservice-app/shared/apperr"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// capturingProvider keeps the messages it is asked to send.
+type capturingProvider struct {
+	channels []string
+	fail     error
+	sent     []otp.Message
+}
+
+func (p *capturingProvider) Name() string { return "test" }
+
+func (p *capturingProvider) Supports(channel string) bool {
+	for _, c := range p.channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *capturingProvider) Send(ctx context.Context, msg otp.Message) error {
+	if p.fail != nil {
+		return p.fail
+	}
+	p.sent = append(p.sent, msg)
+	return nil
+}
+
+func (p *capturingProvider) lastCode(t *testing.T) string {
+	t.Helper()
+	if len(p.sent) == 0 {
+		t.Fatal("no code was sent")
+	}
+	return p.sent[len(p.sent)-1].Code
+}
+
+// usedMFAMethods records which MFA methods were used.
+type usedMFAMethods struct {
+	repositories.MFARepository
+	used []uuid.UUID
+}
+
+func (r *usedMFAMethods) UpdateLastUsed(ctx context.Context, id uuid.UUID) error {
+	r.used = append(r.used, id)
+	return nil
+}
+
+type otpFixture struct {
+	service  OTPService
+	provider *capturingProvider
+	mfaRepo  *usedMFAMethods
+	redis    *miniredis.Miniredis
+}
+
+func newOTPFixture(t *testing.T, channels ...string) *otpFixture {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	if len(channels) == 0 {
+		channels = []string{otp.ChannelSMS, otp.ChannelVoice}
+	}
+	f := &otpFixture{
+		provider: &capturingProvider{channels: channels},
+		mfaRepo:  &usedMFAMethods{},
+		redis:    mr,
+	}
+	f.service = NewOTPService(f.mfaRepo, nil, &recordedAuditLog{}, cache.NewOTPCache(client), f.provider)
+	return f
+}
+
+func TestOTPCodeIsStoredAsHMAC(t *testing.T) {
+	ctx := context.Background()
+	f := newOTPFixture(t)
+	userID := uuid.New()
+
+	resp, err := f.service.SendVerificationCode(ctx, userID, "+84901234589", "")
+	if err != nil {
+		t.Fatalf("SendVerificationCode: %v", err)
+	}
+	code := f.provider.lastCode(t)
+	if len(code) != 6 || strings.Trim(code, "0123456789") != "" {
+		t.Fatalf("code = %q, want 6 digits", code)
+	}
+	if resp.PhoneNumber != "+84*******89" {
+		t.Fatalf("PhoneNumber = %q, want it masked", resp.PhoneNumber)
+	}
+
+	id := phoneVerificationID(userID, "+84901234589")
+	stored, err := f.redis.Get("otp_code:" + id.String())
+	if err != nil {
+		t.Fatalf("stored code: %v", err)
+	}
+	if stored != hashOTPCode(id, code) || strings.Contains(stored, code) {
+		t.Fatalf("stored %q, want the HMAC of the code", stored)
+	}
+	if hashOTPCode(uuid.New(), code) == stored {
+		t.Fatal("the hash of the code does not depend on the method")
+	}
+	if ttl := f.redis.TTL("otp_code:" + id.String()); ttl != 5*time.Minute {
+		t.Fatalf("code TTL = %v, want OTP_CODE_TTL", ttl)
+	}
+}
+
+func TestOTPCodeVerifiesOnce(t *testing.T) {
+	ctx := context.Background()
+	f := newOTPFixture(t)
+	userID := uuid.New()
+
+	if _, err := f.service.SendVerificationCode(ctx, userID, "+84901234589", ""); err != nil {
+		t.Fatal(err)
+	}
+	code := f.provider.lastCode(t)
+
+	for i, want := range []bool{true, false} {
+		ok, err := f.service.CheckVerificationCode(ctx, userID, "+84901234589", code)
+		if err != nil || ok != want {
+			t.Fatalf("check #%d = %v, %v, want %v", i+1, ok, err, want)
+		}
+	}
+}
+
+func TestOTPCodeExpires(t *testing.T) {
+	ctx := context.Background()
+	f := newOTPFixture(t)
+	userID := uuid.New()
+
+	if _, err := f.service.SendVerificationCode(ctx, userID, "+84901234589", ""); err != nil {
+		t.Fatal(err)
+	}
+	code := f.provider.lastCode(t)
+	f.redis.FastForward(5*time.Minute + time.Second)
+
+	ok, err := f.service.CheckVerificationCode(ctx, userID, "+84901234589", code)
+	if err != nil || ok {
+		t.Fatalf("expired code: %v, %v, want false", ok, err)
+	}
+}
+
+// TestOTPCodeAttempts checks that a code still verifies on the last allowed attempt and
+// is discarded once OTP_MAX_ATTEMPTS guesses are used up.
+func TestOTPCodeAttempts(t *testing.T) {
+	cases := []struct {
+		name         string
+		wrongGuesses int
+		want         bool
+	}{
+		{name: "LastAttempt", wrongGuesses: 4, want: true},
+		{name: "AttemptsUsedUp", wrongGuesses: 5, want: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newOTPFixture(t)
+			userID := uuid.New()
+
+			if _, err := f.service.SendVerificationCode(ctx, userID, "+84901234589", ""); err != nil {
+				t.Fatal(err)
+			}
+			code := f.provider.lastCode(t)
+			wrong := "000000"
+			if code == wrong {
+				wrong = "111111"
+			}
+
+			for i := 0; i < tc.wrongGuesses; i++ {
+				if ok, err := f.service.CheckVerificationCode(ctx, userID, "+84901234589", wrong); err != nil || ok {
+					t.Fatalf("wrong guess %d = %v, %v, want false", i+1, ok, err)
+				}
+			}
+			ok, err := f.service.CheckVerificationCode(ctx, userID, "+84901234589", code)
+			if err != nil || ok != tc.want {
+				t.Fatalf("right code after %d wrong guesses = %v, %v, want %v", tc.wrongGuesses, ok, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestOTPCodeIsBoundToUserAndNumber(t *testing.T) {
+	ctx := context.Background()
+	f := newOTPFixture(t)
+	userID := uuid.New()
+
+	if _, err := f.service.SendVerificationCode(ctx, userID, "+84901234589", ""); err != nil {
+		t.Fatal(err)
+	}
+	code := f.provider.lastCode(t)
+
+	if ok, _ := f.service.CheckVerificationCode(ctx, uuid.New(), "+84901234589", code); ok {
+		t.Fatal("code verified for another user")
+	}
+	if ok, _ := f.service.CheckVerificationCode(ctx, userID, "+84901234500", code); ok {
+		t.Fatal("code verified for another number")
+	}
+}
+
+// TestOTPSendThrottle checks the resend cooldown and the hourly allowance per number.
+func TestOTPSendThrottle(t *testing.T) {
+	ctx := context.Background()
+	f := newOTPFixture(t)
+	userID := uuid.New()
+
+	send := func() error {
+		_, err := f.service.SendVerificationCode(ctx, userID, "+84901234589", "")
+		return err
+	}
+
+	if err := send(); err != nil {
+		t.Fatalf("first send: %v", err)
+	}
+	if err := send(); !apperr.HasReason(err, "OTP_SEND_THROTTLED") {
+		t.Fatalf("send within the cooldown: error = %v, want OTP_SEND_THROTTLED", err)
+	}
+
+	for i := 2; i <= 5; i++ {
+		f.redis.FastForward(time.Minute)
+		if err := send(); err != nil {
+			t.Fatalf("send %d: %v", i, err)
+		}
+	}
+	f.redis.FastForward(time.Minute)
+	if err := send(); !apperr.HasReason(err, "OTP_SEND_THROTTLED") {
+		t.Fatalf("sixth send in the hour: error = %v, want OTP_SEND_THROTTLED", err)
+	}
+	if len(f.provider.sent) != 5 {
+		t.Fatalf("sent %d codes, want 5", len(f.provider.sent))
+	}
+
+	// Another number has its own allowance.
+	if _, err := f.service.SendVerificationCode(ctx, userID, "+84901234500", ""); err != nil {
+		t.Fatalf("send to another number: %v", err)
+	}
+}
+
+func TestOTPSendChannel(t *testing.T) {
+	cases := []struct {
+		name      string
+		supported []string
+		requested string
+		want      string
+		wantErr   error
+	}{
+		{name: "Requested", supported: []string{otp.ChannelSMS, otp.ChannelVoice}, requested: otp.ChannelVoice, want: otp.ChannelVoice},
+		{name: "MethodDefault", supported: []string{otp.ChannelSMS, otp.ChannelVoice}, want: otp.ChannelSMS},
+		{name: "FallsBackToSMS", supported: []string{otp.ChannelSMS}, requested: otp.ChannelVoice, want: otp.ChannelSMS},
+		{name: "FallsBackToVoice", supported: []string{otp.ChannelVoice}, requested: otp.ChannelSMS, want: otp.ChannelVoice},
+		{name: "NoChannel", supported: []string{"fax"}, requested: otp.ChannelSMS, wantErr: errors.ErrOTPChannelUnsupported},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newOTPFixture(t, tc.supported...)
+
+			resp, err := f.service.SendVerificationCode(context.Background(), uuid.New(), "+84901234589", tc.requested)
+			if tc.wantErr != nil {
+				if !stderrors.Is(err, tc.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SendVerificationCode: %v", err)
+			}
+			if resp.Channel != tc.want || f.provider.sent[0].Channel != tc.want {
+				t.Fatalf("channel = %q, sent on %q, want %q", resp.Channel, f.provider.sent[0].Channel, tc.want)
+			}
+		})
+	}
+}
+
+func TestOTPDeliveryFailure(t *testing.T) {
+	f := newOTPFixture(t)
+	f.provider.fail = otp.ErrDeliveryFailed
+
+	_, err := f.service.SendVerificationCode(context.Background(), uuid.New(), "+84901234589", "")
+	if !stderrors.Is(err, errors.ErrOTPDeliveryFailed) {
+		t.Fatalf("error = %v, want %v", err, errors.ErrOTPDeliveryFailed)
+	}
+}
+
+func TestOTPLoginCode(t *testing.T) {
+	ctx := context.Background()
+	f := newOTPFixture(t)
+
+	method := &models.MFAMethod{ID: uuid.New(), UserID: uuid.New(), Type: models.MFATypePhone, PhoneNumber: "+84901234589", OTPChannel: otp.ChannelSMS}
+	if _, err := f.service.SendLoginCode(ctx, method, ""); !stderrors.Is(err, errors.ErrMFANotSetup) {
+		t.Fatalf("unverified method: error = %v, want %v", err, errors.ErrMFANotSetup)
+	}
+
+	method.VerifiedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	if _, err := f.service.SendLoginCode(ctx, method, ""); err != nil {
+		t.Fatalf("SendLoginCode: %v", err)
+	}
+
+	ok, err := f.service.VerifyCode(ctx, method, f.provider.lastCode(t))
+	if err != nil || !ok {
+		t.Fatalf("VerifyCode = %v, %v, want true", ok, err)
+	}
+	if len(f.mfaRepo.used) != 1 || f.mfaRepo.used[0] != method.ID {
+		t.Fatalf("used methods = %v, want [%s]", f.mfaRepo.used, method.ID)
+	}
+}
@@ -2,139 +2,69 @@ package services
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"log"
 	"time"
-	"user-services/internal/api/repositories"
-	"user-services/internal/models"
 
-	amqp "github.com/rabbitmq/amqp091-go"
+	"user-services/internal/api/dto"
+	"user-services/internal/models"
 
+	"github.com/ductan2/microservice-app/shared/events"
+	"github.com/ductan2/microservice-app/shared/outbox"
 	"github.com/google/uuid"
 )
 
+// OutboxService writes user events to the outbox and reports its backlog. Publishing is
+// done by the outbox relay started in cmd/server.
 type OutboxService interface {
 	PublishUserEvent(ctx context.Context, aggregateID uuid.UUID, eventType string, payload map[string]any) error
-	ProcessUnpublishedEvents(ctx context.Context, limit int) error
-	CleanupPublishedEvents(ctx context.Context) error
+	// Stats reports the backlog of events waiting to be published.
+	Stats(ctx context.Context) (*dto.OutboxStats, error)
 }
 
 type outboxService struct {
-	outboxRepo repositories.OutboxRepository
-	channel    *amqp.Channel
-	exchange   string
+	store  outbox.Store
+	writer *outbox.Writer
 }
 
-func NewOutboxService(outboxRepo repositories.OutboxRepository, channel *amqp.Channel, exchange string) OutboxService {
+func NewOutboxService(store outbox.Store) OutboxService {
 	return &outboxService{
-		outboxRepo: outboxRepo,
-		channel:    channel,
-		exchange:   exchange,
+		store:  store,
+		writer: outbox.NewWriter(store),
 	}
 }
 
 func (s *outboxService) PublishUserEvent(ctx context.Context, aggregateID uuid.UUID, eventType string, payload map[string]any) error {
-	// Marshal payload to JSON bytes for storage
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %w", err)
-	}
-
-	event := &models.Outbox{
-		AggregateID: aggregateID,
-		Topic:       "user.events",
-		Type:        eventType,
-		Payload:     payloadBytes,
-		CreatedAt:   time.Now(),
-	}
-	return s.outboxRepo.Create(ctx, event)
+	return s.writer.Write(ctx, aggregateID, "user.events", eventType, payload)
 }
 
-func (s *outboxService) ProcessUnpublishedEvents(ctx context.Context, limit int) error {
-	// 1. Fetch unpublished events
-	events, err := s.outboxRepo.GetUnpublished(ctx, limit)
+func (s *outboxService) Stats(ctx context.Context) (*dto.OutboxStats, error) {
+	stats, err := s.store.Stats(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get unpublished events: %w", err)
+		return nil, fmt.Errorf("failed to get outbox stats: %w", err)
 	}
 
-	if len(events) == 0 {
-		return nil
-	}
-
-	log.Printf("Processing %d unpublished events", len(events))
-
-	// 2. Publish each event to RabbitMQ
-	for _, event := range events {
-		if err := s.publishToRabbitMQ(ctx, event); err != nil {
-			log.Printf("Failed to publish event %d: %v", event.ID, err)
-			// Continue with other events instead of failing all
-			continue
-		}
-
-		// 3. Mark as published
-		if err := s.outboxRepo.MarkAsPublished(ctx, event.ID); err != nil {
-			log.Printf("Failed to mark event %d as published: %v", event.ID, err)
-			// Event was published but not marked - it will be retried
-			// This is acceptable for at-least-once delivery
-		}
-	}
-
-	return nil
+	return &dto.OutboxStats{
+		Pending:         stats.Pending,
+		Retrying:        stats.Retrying,
+		Failed:          stats.Failed,
+		OldestPendingAt: stats.OldestPendingAt,
+		LagSeconds:      stats.Lag(time.Now()).Seconds(),
+	}, nil
 }
 
-func (s *outboxService) publishToRabbitMQ(ctx context.Context, event models.Outbox) error {
-	// Create routing key from topic
-	routingKey := event.Topic // e.g., "user.created", "user.events"
-
-	// Payload is already JSON bytes from database
-	body := event.Payload
-	if len(body) == 0 {
-		return fmt.Errorf("event payload is empty")
-	}
-
-	// Publish to RabbitMQ
-	err := s.channel.PublishWithContext(
-		ctx,
-		s.exchange, // exchange
-		routingKey, // routing key
-		false,      // mandatory
-		false,      // immediate
-		amqp.Publishing{
-			ContentType:  "application/json",
-			Body:         body,
-			DeliveryMode: amqp.Persistent,
-			Timestamp:    time.Now(),
-			Type:         event.Type,
-			Headers: amqp.Table{
-				"aggregate_id": event.AggregateID.String(),
-				"event_type":   event.Type,
-			},
-		},
-	)
-
-	if err != nil {
-		return fmt.Errorf("failed to publish to RabbitMQ: %w", err)
-	}
-
-	log.Printf("Published event %d (type=%s) to exchange=%s with routing_key=%s",
-		event.ID, event.Type, s.exchange, routingKey)
-
-	return nil
-}
-
-func (s *outboxService) CleanupPublishedEvents(ctx context.Context) error {
-	// Delete published events older than 7 days
-	// Get the last event ID before cutoff
-	events, err := s.outboxRepo.GetUnpublished(ctx, 1)
+// newOutboxEvent encodes an event of the shared contract (see shared/events) into an
+// outbox row. It fails when the event does not validate, so a payload consumers cannot
+// read is never queued.
+func newOutboxEvent(aggregateID uuid.UUID, event events.Event) (*models.Outbox, error) {
+	payload, err := events.Marshal(event)
 	if err != nil {
-		return err
-	}
-
-	if len(events) > 0 {
-		// Delete all published events with ID less than this
-		return s.outboxRepo.DeletePublished(ctx, events[0].ID)
+		return nil, err
 	}
-
-	return nil
+	return &models.Outbox{
+		AggregateID: aggregateID,
+		Topic:       event.Topic(),
+		Type:        event.EventType(),
+		Payload:     payload,
+		CreatedAt:   time.Now(),
+	}, nil
 }
@@ -2,17 +2,22 @@ package services
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/hex"
-	"encoding/json"
-	"errors"
+	"crypto/hmac"
+	stderrors "errors"
 	"fmt"
+	"log/slog"
+	"net/netip"
+	"strings"
 	"time"
 	"user-services/internal/api/repositories"
+	"user-services/internal/audit"
 	"user-services/internal/config"
+	"user-services/internal/errors"
 	"user-services/internal/models"
+	"user-services/internal/passwordpolicy"
 	"user-services/internal/utils"
 
+	"github.com/ductan2/microservice-app/shared/events"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -31,6 +36,8 @@ type passwordService struct {
 	auditLogRepo      repositories.AuditLogRepository
 	outboxRepo        repositories.OutboxRepository
 	userProfileRepo   repositories.UserProfileRepository
+	sessionService    SessionService
+	passwordPolicy    *passwordpolicy.Engine
 }
 
 func NewPasswordService(
@@ -39,6 +46,8 @@ func NewPasswordService(
 	auditLogRepo repositories.AuditLogRepository,
 	outboxRepo repositories.OutboxRepository,
 	userProfileRepo repositories.UserProfileRepository,
+	sessionService SessionService,
+	passwordPolicy *passwordpolicy.Engine,
 ) PasswordService {
 	return &passwordService{
 		userRepo:          userRepo,
@@ -46,6 +55,8 @@ func NewPasswordService(
 		auditLogRepo:      auditLogRepo,
 		outboxRepo:        outboxRepo,
 		userProfileRepo:   userProfileRepo,
+		sessionService:    sessionService,
+		passwordPolicy:    passwordPolicy,
 	}
 }
 
@@ -53,7 +64,7 @@ func (s *passwordService) InitiatePasswordReset(ctx context.Context, email strin
 	// 1. Find user by email
 	user, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
 			// Don't reveal if email exists - just return success
 			return nil
 		}
@@ -64,69 +75,51 @@ func (s *passwordService) InitiatePasswordReset(ctx context.Context, email strin
 	_ = s.passwordResetRepo.DeleteByUserID(ctx, user.ID)
 
 	// 3. Generate secure random token (32 bytes = 64 hex chars)
-	tokenBytes := make([]byte, 32)
-	if _, err := rand.Read(tokenBytes); err != nil {
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
 		return fmt.Errorf("failed to generate token: %w", err)
 	}
-	token := hex.EncodeToString(tokenBytes)
-
-	// 4. Hash the token for storage (use simple SHA256, not bcrypt)
-	tokenHash := utils.HashToken(token)
 
-	// 5. Create password reset record (expires in 1 hour)
+	// 4. Create password reset record bound to the requesting browser and network; only
+	// the hash of the token is stored
+	cfg := config.GetConfig()
+	info, _ := audit.FromContext(ctx)
 	passwordReset := &models.PasswordReset{
 		UserID:    user.ID,
-		TokenHash: tokenHash,
-		ExpiresAt: time.Now().Add(1 * time.Hour),
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(cfg.Email.PasswordResetExpiry),
+	}
+	if fingerprint := resetFingerprint(cfg.Reset, info.UserAgent, info.IPAddr); fingerprint != "" {
+		passwordReset.FingerprintHash = &fingerprint
 	}
 
 	if err := s.passwordResetRepo.Create(ctx, passwordReset); err != nil {
 		return fmt.Errorf("failed to create password reset: %w", err)
 	}
 
-	// 6. Get user profile for name
-	profile, err := s.userProfileRepo.GetByUserID(ctx, user.ID)
-	var displayName string
-	if err == nil && profile != nil {
-		displayName = profile.DisplayName
-	}
-
-	// 7. Build reset link
-	cfg := config.GetConfig()
+	// 5. Build reset link
 	resetLink := fmt.Sprintf("%s/reset-password?token=%s", cfg.Email.FrontendURL, token)
 
-	// 8. Create outbox event for password reset email
-	payloadData := map[string]any{
-		"email":              user.Email,
-		"name":               displayName,
-		"reset_link":         resetLink,
-		"resetLink":          resetLink, // alternative key
-		"expires_in_minutes": 60,
-		"expiresInMinutes":   60, // alternative key
-	}
-	payloadBytes, err := json.Marshal(payloadData)
-	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %w", err)
-	}
-
-	outboxEvent := &models.Outbox{
-		AggregateID: user.ID,
-		Topic:       "user.password_reset",
-		Type:        "PasswordResetRequested",
-		Payload:     payloadBytes,
-		CreatedAt:   time.Now(),
-	}
-
-	if err := s.outboxRepo.Create(ctx, outboxEvent); err != nil {
-		return fmt.Errorf("failed to create outbox event: %w", err)
+	// 6. Create outbox event for password reset email, which tells the user where the
+	// reset was requested from in case it was not them
+	if err := s.queueEmail(ctx, user.ID, &events.PasswordResetRequested{
+		RequestOrigin:      requestOrigin(info),
+		Email:              user.Email,
+		Name:               s.displayName(ctx, user.ID),
+		ResetLink:          resetLink,
+		ExpiresInMinutes:   int(cfg.Email.PasswordResetExpiry.Minutes()),
+		SameDeviceRequired: passwordReset.FingerprintHash != nil,
+	}); err != nil {
+		return err
 	}
 
-	// 9. Log audit event
+	// 7. Log audit event
 	auditLog := &models.AuditLog{
 		UserID: &user.ID,
 		Action: "password.reset_requested",
 		Metadata: map[string]any{
 			"email": user.Email,
+			"bound": passwordReset.FingerprintHash != nil,
 		},
 		CreatedAt: time.Now(),
 	}
@@ -142,53 +135,131 @@ func (s *passwordService) VerifyResetToken(ctx context.Context, token string) (*
 	// Find valid reset record
 	reset, err := s.passwordResetRepo.GetByTokenHash(ctx, tokenHash)
 	if err != nil {
-		return nil, errors.New("invalid or expired token")
+		return nil, errors.ErrInvalidResetToken
 	}
 
 	return &reset.UserID, nil
 }
 
+// CompletePasswordReset sets a new password with a reset link. A link bound to a browser
+// and network only works from there; each attempt from elsewhere counts towards the
+// attempts it allows. On success every other reset link of the user is discarded and
+// all their sessions are signed out.
 func (s *passwordService) CompletePasswordReset(ctx context.Context, token, newPassword string) error {
-	// 1. Validate new password
-	if err := utils.ValidatePassword(newPassword); err != nil {
-		return err
-	}
-
-	// 2. Hash the token to find the reset record
+	// 1. Hash the token to find the reset record
 	tokenHash := utils.HashToken(token)
 
 	reset, err := s.passwordResetRepo.GetByTokenHash(ctx, tokenHash)
 	if err != nil {
-		return errors.New("invalid or expired token")
+		return errors.ErrInvalidResetToken
+	}
+
+	// 2. Check the link is used from where it was requested
+	cfg := config.GetConfig()
+	info, _ := audit.FromContext(ctx)
+	if reset.FingerprintHash != nil {
+		fingerprint := resetFingerprint(cfg.Reset, info.UserAgent, info.IPAddr)
+		if !hmac.Equal([]byte(*reset.FingerprintHash), []byte(fingerprint)) {
+			exhausted, err := s.passwordResetRepo.RecordFailedAttempt(ctx, reset.ID, cfg.Reset.MaxAttempts)
+			if err != nil {
+				return fmt.Errorf("failed to record reset attempt: %w", err)
+			}
+			_ = s.auditLogRepo.Create(ctx, &models.AuditLog{
+				UserID: &reset.UserID,
+				Action: "password.reset_rejected",
+				Metadata: map[string]any{
+					"reset_id":  reset.ID,
+					"reason":    "fingerprint_mismatch",
+					"exhausted": exhausted,
+				},
+				CreatedAt: time.Now(),
+			})
+			return errors.ErrResetDeviceMismatch
+		}
+	}
+
+	// 3. Validate new password against the policy and the account's own details
+	if err := s.passwordPolicy.Validate(ctx, newPassword, s.userInputs(ctx, reset.UserID)...); err != nil {
+		return err
 	}
 
-	// 3. Hash new password
+	// 4. Hash new password
 	newPasswordHash, err := utils.HashPassword(newPassword)
 	if err != nil {
 		return fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	// 4. Update user's password
+	// 5. Consume the reset token; losing this race means a concurrent request already
+	// used the link
+	consumed, err := s.passwordResetRepo.Consume(ctx, reset.ID)
+	if err != nil {
+		return fmt.Errorf("failed to consume token: %w", err)
+	}
+	if !consumed {
+		return errors.ErrInvalidResetToken
+	}
+
+	// 6. Update user's password
 	if err := s.userRepo.UpdatePassword(ctx, reset.UserID, newPasswordHash); err != nil {
 		return fmt.Errorf("failed to update password: %w", err)
 	}
 
-	// 5. Consume the reset token
-	if err := s.passwordResetRepo.Consume(ctx, reset.ID); err != nil {
-		return fmt.Errorf("failed to consume token: %w", err)
+	// 7. Discard the user's other reset links and sign out every session, which may
+	// belong to whoever knew the old password
+	if err := s.passwordResetRepo.DeleteByUserID(ctx, reset.UserID); err != nil {
+		slog.WarnContext(ctx, "failed to discard password reset tokens", "user_id", reset.UserID, "error", err)
+	}
+	sessionsRevoked := true
+	if err := s.sessionService.RevokeAllUserSessions(ctx, reset.UserID); err != nil {
+		sessionsRevoked = false
+		slog.WarnContext(ctx, "failed to revoke sessions after password reset", "user_id", reset.UserID, "error", err)
 	}
 
-	// 6. Log audit event
+	// An invited user sets their first password through the invitation link, which also
+	// proves they own the email
+	activated, err := s.userRepo.ActivateInvited(ctx, reset.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to activate invited user: %w", err)
+	}
+	if activated {
+		_ = s.auditLogRepo.Create(ctx, &models.AuditLog{
+			UserID:    &reset.UserID,
+			Action:    "user.invitation_accepted",
+			Metadata:  map[string]any{"reset_id": reset.ID},
+			CreatedAt: time.Now(),
+		})
+	}
+
+	// 8. Log audit event
 	auditLog := &models.AuditLog{
 		UserID: &reset.UserID,
 		Action: "password.reset_completed",
 		Metadata: map[string]any{
-			"reset_id": reset.ID,
+			"reset_id":         reset.ID,
+			"sessions_revoked": sessionsRevoked,
 		},
 		CreatedAt: time.Now(),
 	}
 	_ = s.auditLogRepo.Create(ctx, auditLog)
 
+	// 9. Tell the user their password was changed; an invitation being accepted is not
+	// news to them
+	if !activated {
+		user, err := s.userRepo.GetByID(ctx, reset.UserID)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to load user for password reset notification", "user_id", reset.UserID, "error", err)
+			return nil
+		}
+		if err := s.queueEmail(ctx, user.ID, &events.PasswordResetCompleted{
+			RequestOrigin:   requestOrigin(info),
+			Email:           user.Email,
+			Name:            s.displayName(ctx, user.ID),
+			SessionsRevoked: sessionsRevoked,
+		}); err != nil {
+			slog.WarnContext(ctx, "failed to queue password reset notification", "error", err)
+		}
+	}
+
 	return nil
 }
 
@@ -201,11 +272,11 @@ func (s *passwordService) ChangePassword(ctx context.Context, userID uuid.UUID,
 
 	// 2. Verify old password
 	if err := utils.ComparePassword(user.PasswordHash, oldPassword); err != nil {
-		return errors.New("invalid old password")
+		return stderrors.New("invalid old password")
 	}
 
 	// 3. Validate new password
-	if err := utils.ValidatePassword(newPassword); err != nil {
+	if err := s.passwordPolicy.Validate(ctx, newPassword, s.userInputs(ctx, userID)...); err != nil {
 		return err
 	}
 
@@ -234,6 +305,75 @@ func (s *passwordService) ChangePassword(ctx context.Context, userID uuid.UUID,
 	return nil
 }
 
+// userInputs returns the account details a new password must not contain.
+func (s *passwordService) userInputs(ctx context.Context, userID uuid.UUID) []string {
+	var inputs []string
+	if user, err := s.userRepo.GetByID(ctx, userID); err == nil {
+		inputs = append(inputs, user.Email)
+	}
+	if profile, err := s.userProfileRepo.GetByUserID(ctx, userID); err == nil {
+		inputs = append(inputs, profile.DisplayName)
+	}
+	return inputs
+}
+
 func (s *passwordService) CleanupExpiredResets(ctx context.Context) error {
 	return s.passwordResetRepo.DeleteExpired(ctx)
 }
+
+// requestOrigin describes where a password reset was requested or completed from, for
+// the emails telling the user in case it was not them.
+func requestOrigin(info audit.Request) events.RequestOrigin {
+	device := utils.ParseUserAgent(info.UserAgent)
+	return events.RequestOrigin{
+		IPAddr:      utils.SanitizeIPAddress(info.IPAddr),
+		Device:      deviceLabel(device.Browser, device.OS),
+		RequestedAt: time.Now().UTC(),
+	}
+}
+
+func (s *passwordService) displayName(ctx context.Context, userID uuid.UUID) string {
+	if profile, err := s.userProfileRepo.GetByUserID(ctx, userID); err == nil && profile != nil {
+		return profile.DisplayName
+	}
+	return ""
+}
+
+func (s *passwordService) queueEmail(ctx context.Context, userID uuid.UUID, event events.Event) error {
+	outboxEvent, err := newOutboxEvent(userID, event)
+	if err != nil {
+		return err
+	}
+	if err := s.outboxRepo.Create(ctx, outboxEvent); err != nil {
+		return fmt.Errorf("failed to create outbox event: %w", err)
+	}
+	return nil
+}
+
+// resetFingerprint hashes what a reset link is bound to: the user agent of the requesting
+// browser and the network of its IP address, so the link keeps working when the address
+// changes within the network. It is empty when the configuration binds neither.
+func resetFingerprint(cfg config.PasswordResetConfig, userAgent, ipAddr string) string {
+	var parts []string
+	if cfg.BindUserAgent {
+		parts = append(parts, "ua:"+strings.TrimSpace(userAgent))
+	}
+	if cfg.BindNetwork {
+		network := strings.TrimSpace(ipAddr)
+		if addr, err := netip.ParseAddr(network); err == nil {
+			addr = addr.Unmap()
+			bits := 64
+			if addr.Is4() {
+				bits = 24
+			}
+			if prefix, err := addr.Prefix(bits); err == nil {
+				network = prefix.String()
+			}
+		}
+		parts = append(parts, "net:"+network)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return utils.HashToken(strings.Join(parts, "\n"))
+}
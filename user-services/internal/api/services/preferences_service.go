@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/repositories"
+	"user-services/internal/errors"
+	"user-services/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// weekdays lists the valid reminder days in order.
+var weekdays = []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+
+// PreferencesService reads and changes user preferences. Every change is published as a
+// user.preferences_updated event carrying the full preferences and their version, so
+// the notification and lesson services can keep their copies in sync.
+type PreferencesService interface {
+	GetPreferences(ctx context.Context, userID uuid.UUID) (*dto.PreferencesResponse, error)
+	UpdatePreferences(ctx context.Context, userID uuid.UUID, req dto.UpdatePreferencesRequest) (*dto.PreferencesResponse, error)
+}
+
+type preferencesService struct {
+	preferencesRepo repositories.PreferencesRepository
+	profileRepo     repositories.UserProfileRepository
+	auditLogRepo    repositories.AuditLogRepository
+}
+
+func NewPreferencesService(
+	preferencesRepo repositories.PreferencesRepository,
+	profileRepo repositories.UserProfileRepository,
+	auditLogRepo repositories.AuditLogRepository,
+) PreferencesService {
+	return &preferencesService{
+		preferencesRepo: preferencesRepo,
+		profileRepo:     profileRepo,
+		auditLogRepo:    auditLogRepo,
+	}
+}
+
+func (s *preferencesService) GetPreferences(ctx context.Context, userID uuid.UUID) (*dto.PreferencesResponse, error) {
+	profile, err := s.profileRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	prefs, err := s.preferencesRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toPreferencesResponse(*prefs, *profile), nil
+}
+
+// UpdatePreferences applies the fields set in req. A request that changes nothing
+// returns the current preferences without a new version or event.
+func (s *preferencesService) UpdatePreferences(ctx context.Context, userID uuid.UUID, req dto.UpdatePreferencesRequest) (*dto.PreferencesResponse, error) {
+	if err := validatePreferences(req); err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	prefs, profile, err := s.preferencesRepo.Update(ctx, userID, func(prefs *models.UserPreferences, profile *models.UserProfile) (*models.Outbox, error) {
+		changed = applyPreferences(prefs, profile, req)
+		if len(changed) == 0 {
+			return nil, nil
+		}
+
+		payload, err := json.Marshal(map[string]any{
+			"user_id":     userID,
+			"version":     prefs.Version,
+			"changed":     changed,
+			"preferences": toPreferencesResponse(*prefs, *profile),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal payload: %w", err)
+		}
+		return &models.Outbox{
+			AggregateID: userID,
+			Topic:       "user.preferences_updated",
+			Type:        "UserPreferencesUpdated",
+			Payload:     payload,
+			CreatedAt:   time.Now(),
+		}, nil
+	})
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if len(changed) > 0 {
+		// Only the names of changed fields are audited, not their values
+		if err := s.auditLogRepo.Create(ctx, &models.AuditLog{
+			UserID:    &userID,
+			Action:    "preferences.updated",
+			Metadata:  map[string]any{"fields": changed, "version": prefs.Version},
+			CreatedAt: time.Now(),
+		}); err != nil {
+			slog.WarnContext(ctx, "failed to write preferences.updated audit log", "error", err)
+		}
+	}
+
+	return toPreferencesResponse(*prefs, *profile), nil
+}
+
+// validatePreferences checks what the request binding cannot: that the time zone is
+// known, the reminder time is a clock time and the reminder days are weekdays.
+func validatePreferences(req dto.UpdatePreferencesRequest) error {
+	if req.TimeZone != nil {
+		if _, err := time.LoadLocation(*req.TimeZone); err != nil || *req.TimeZone == "" || *req.TimeZone == "Local" {
+			return errors.NewValidationError("time_zone must be an IANA time zone such as Asia/Ho_Chi_Minh").WithReason("INVALID_TIME_ZONE")
+		}
+	}
+	if goals := req.LearningGoals; goals != nil {
+		if goals.ReminderTime != nil && *goals.ReminderTime != "" {
+			if _, err := time.Parse("15:04", *goals.ReminderTime); err != nil {
+				return errors.NewValidationError("reminder_time must be HH:MM or empty").WithReason("INVALID_REMINDER_TIME")
+			}
+		}
+		if goals.ReminderDays != nil {
+			for _, day := range *goals.ReminderDays {
+				if !containsString(weekdays, day) {
+					return errors.NewValidationError("reminder_days must only contain mon, tue, wed, thu, fri, sat and sun").WithReason("INVALID_REMINDER_DAYS")
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// applyPreferences copies the fields set in req and returns the names of those that
+// changed.
+func applyPreferences(prefs *models.UserPreferences, profile *models.UserProfile, req dto.UpdatePreferencesRequest) []string {
+	var changed []string
+	setString := func(name string, dst *string, src *string) {
+		if src != nil && *src != *dst {
+			*dst = *src
+			changed = append(changed, name)
+		}
+	}
+	setBool := func(name string, dst *bool, src *bool) {
+		if src != nil && *src != *dst {
+			*dst = *src
+			changed = append(changed, name)
+		}
+	}
+	setInt := func(name string, dst *int, src *int) {
+		if src != nil && *src != *dst {
+			*dst = *src
+			changed = append(changed, name)
+		}
+	}
+
+	setString("locale", &profile.Locale, req.Locale)
+	setString("time_zone", &profile.TimeZone, req.TimeZone)
+	setString("theme", &prefs.Theme, req.Theme)
+	if n := req.Notifications; n != nil {
+		setBool("notifications.email", &prefs.NotifyEmail, n.Email)
+		setBool("notifications.push", &prefs.NotifyPush, n.Push)
+		setBool("notifications.sms", &prefs.NotifySMS, n.SMS)
+		setBool("notifications.in_app", &prefs.NotifyInApp, n.InApp)
+	}
+	if g := req.LearningGoals; g != nil {
+		setInt("learning_goals.daily_minutes", &prefs.DailyGoalMinutes, g.DailyMinutes)
+		setInt("learning_goals.weekly_lessons", &prefs.WeeklyGoalLessons, g.WeeklyLessons)
+		setString("learning_goals.reminder_time", &prefs.ReminderTime, g.ReminderTime)
+		if g.ReminderDays != nil {
+			days := joinWeekdays(*g.ReminderDays)
+			setString("learning_goals.reminder_days", &prefs.ReminderDays, &days)
+		}
+	}
+	return changed
+}
+
+// joinWeekdays stores days in week order without duplicates.
+func joinWeekdays(days []string) string {
+	ordered := make([]string, 0, len(weekdays))
+	for _, day := range weekdays {
+		if containsString(days, day) {
+			ordered = append(ordered, day)
+		}
+	}
+	return strings.Join(ordered, ",")
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func toPreferencesResponse(prefs models.UserPreferences, profile models.UserProfile) *dto.PreferencesResponse {
+	resp := &dto.PreferencesResponse{
+		Locale:   profile.Locale,
+		TimeZone: profile.TimeZone,
+		Theme:    prefs.Theme,
+		Notifications: dto.NotificationPreferences{
+			Email: prefs.NotifyEmail,
+			Push:  prefs.NotifyPush,
+			SMS:   prefs.NotifySMS,
+			InApp: prefs.NotifyInApp,
+		},
+		LearningGoals: dto.LearningGoals{
+			DailyMinutes:  prefs.DailyGoalMinutes,
+			WeeklyLessons: prefs.WeeklyGoalLessons,
+			ReminderTime:  prefs.ReminderTime,
+			ReminderDays:  []string{},
+		},
+		Version: prefs.Version,
+	}
+	if prefs.ReminderDays != "" {
+		resp.LearningGoals.ReminderDays = strings.Split(prefs.ReminderDays, ",")
+	}
+	if !prefs.UpdatedAt.IsZero() {
+		resp.UpdatedAt = &prefs.UpdatedAt
+	}
+	return resp
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/repositories"
+	"user-services/internal/config"
+	"user-services/internal/errors"
+	"user-services/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// apnsTokenRegex matches an APNs device token once lowercased: hex-encoded, 32 bytes
+// today but allowed to grow
+var apnsTokenRegex = regexp.MustCompile(`^[0-9a-f]{64,200}$`)
+
+// PushTokenService keeps the registry of device tokens the notification service pushes
+// to. A token identifies one app install and belongs to one account at a time, so
+// registering it again moves it to whoever signed in on that device. The notification
+// service reads a user's tokens through ListTargets and reports the ones FCM or APNs
+// rejected through ReportInvalid, which deletes them.
+type PushTokenService interface {
+	// RegisterToken registers or refreshes a token. created is false when it was already
+	// registered, to this or another account.
+	RegisterToken(ctx context.Context, userID uuid.UUID, req dto.RegisterPushTokenRequest) (*dto.PushTokenResponse, bool, error)
+	ListTokens(ctx context.Context, userID uuid.UUID) ([]dto.PushTokenResponse, error)
+	UnregisterToken(ctx context.Context, userID, tokenID uuid.UUID) error
+	// UnregisterByValue removes the user's token with the given value. Removing a token
+	// that is not registered succeeds, so apps can always call it when signing out.
+	UnregisterByValue(ctx context.Context, userID uuid.UUID, req dto.UnregisterPushTokenRequest) error
+	ListTargets(ctx context.Context, userID uuid.UUID) (*dto.PushTargetsResponse, error)
+	ReportInvalid(ctx context.Context, req dto.InvalidPushTokensRequest) (*dto.InvalidPushTokensResponse, error)
+}
+
+type pushTokenService struct {
+	tokenRepo    repositories.PushTokenRepository
+	userRepo     repositories.UserRepository
+	auditLogRepo repositories.AuditLogRepository
+	cfg          config.PushTokenConfig
+}
+
+func NewPushTokenService(
+	tokenRepo repositories.PushTokenRepository,
+	userRepo repositories.UserRepository,
+	auditLogRepo repositories.AuditLogRepository,
+	cfg config.PushTokenConfig,
+) PushTokenService {
+	return &pushTokenService{
+		tokenRepo:    tokenRepo,
+		userRepo:     userRepo,
+		auditLogRepo: auditLogRepo,
+		cfg:          cfg,
+	}
+}
+
+func (s *pushTokenService) RegisterToken(ctx context.Context, userID uuid.UUID, req dto.RegisterPushTokenRequest) (*dto.PushTokenResponse, bool, error) {
+	token, err := normalizePushToken(req.Provider, req.Token)
+	if err != nil {
+		return nil, false, err
+	}
+	if req.Provider == models.PushProviderAPNs && req.Platform != "ios" {
+		return nil, false, errors.NewValidationError("APNs tokens can only be registered for the ios platform")
+	}
+
+	pushToken := &models.PushToken{
+		UserID:      userID,
+		Provider:    req.Provider,
+		Token:       token,
+		Platform:    req.Platform,
+		AppID:       strings.TrimSpace(req.AppID),
+		AppVersion:  strings.TrimSpace(req.AppVersion),
+		OSVersion:   strings.TrimSpace(req.OSVersion),
+		DeviceModel: strings.TrimSpace(req.DeviceModel),
+	}
+	created, err := s.tokenRepo.Register(ctx, pushToken, s.cfg.MaxPerUser)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, errors.ErrUserNotFound
+		}
+		return nil, false, fmt.Errorf("failed to register push token: %w", err)
+	}
+
+	if created {
+		s.audit(ctx, userID, "push_token.registered", map[string]any{
+			"token_id": pushToken.ID,
+			"provider": pushToken.Provider,
+			"platform": pushToken.Platform,
+		})
+	}
+
+	resp := toPushTokenResponse(*pushToken)
+	return &resp, created, nil
+}
+
+func (s *pushTokenService) ListTokens(ctx context.Context, userID uuid.UUID) ([]dto.PushTokenResponse, error) {
+	tokens, err := s.tokenRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.PushTokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		responses = append(responses, toPushTokenResponse(token))
+	}
+	return responses, nil
+}
+
+func (s *pushTokenService) UnregisterToken(ctx context.Context, userID, tokenID uuid.UUID) error {
+	deleted, err := s.tokenRepo.Delete(ctx, userID, tokenID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.ErrPushTokenNotFound
+	}
+
+	s.audit(ctx, userID, "push_token.unregistered", map[string]any{"token_id": tokenID})
+	return nil
+}
+
+func (s *pushTokenService) UnregisterByValue(ctx context.Context, userID uuid.UUID, req dto.UnregisterPushTokenRequest) error {
+	token, err := normalizePushToken(req.Provider, req.Token)
+	if err != nil {
+		return err
+	}
+	deleted, err := s.tokenRepo.DeleteByValue(ctx, userID, req.Provider, token)
+	if err != nil {
+		return err
+	}
+
+	if deleted {
+		s.audit(ctx, userID, "push_token.unregistered", map[string]any{"provider": req.Provider})
+	}
+	return nil
+}
+
+func (s *pushTokenService) ListTargets(ctx context.Context, userID uuid.UUID) (*dto.PushTargetsResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	resp := &dto.PushTargetsResponse{UserID: userID, Tokens: []dto.PushTarget{}}
+	// Deleted, deactivated and merged accounts are not pushed to, though their tokens are
+	// kept until the account is restored or erased
+	if user.Status == models.StatusDeleted || user.MergedIntoID != nil {
+		return resp, nil
+	}
+
+	tokens, err := s.tokenRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, token := range tokens {
+		resp.Tokens = append(resp.Tokens, dto.PushTarget{
+			ID:       token.ID,
+			Provider: token.Provider,
+			Token:    token.Token,
+			Platform: token.Platform,
+			AppID:    token.AppID,
+		})
+	}
+	return resp, nil
+}
+
+func (s *pushTokenService) ReportInvalid(ctx context.Context, req dto.InvalidPushTokensRequest) (*dto.InvalidPushTokensResponse, error) {
+	tokens := make([]string, 0, len(req.Tokens))
+	for _, token := range req.Tokens {
+		// A token that could never have been registered cannot be pruned either
+		if normalized, err := normalizePushToken(req.Provider, token); err == nil {
+			tokens = append(tokens, normalized)
+		}
+	}
+	if len(tokens) == 0 {
+		return &dto.InvalidPushTokensResponse{}, nil
+	}
+
+	deleted, err := s.tokenRepo.DeleteInvalid(ctx, req.Provider, tokens)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prune push tokens: %w", err)
+	}
+
+	for _, token := range deleted {
+		s.audit(ctx, token.UserID, "push_token.pruned", map[string]any{
+			"token_id": token.ID,
+			"provider": token.Provider,
+			"platform": token.Platform,
+			"reason":   req.Reason,
+		})
+	}
+	return &dto.InvalidPushTokensResponse{Deleted: len(deleted)}, nil
+}
+
+// normalizePushToken trims a token, and lowercases and checks an APNs token, which is
+// hex-encoded and sent in either case.
+func normalizePushToken(provider, token string) (string, error) {
+	token = strings.TrimSpace(token)
+	if provider == models.PushProviderAPNs {
+		token = strings.ToLower(token)
+		if !apnsTokenRegex.MatchString(token) {
+			return "", errors.NewValidationError("APNs tokens must be hex-encoded").WithReason("INVALID_PUSH_TOKEN")
+		}
+	}
+	if token == "" {
+		return "", errors.NewValidationError("token must not be blank").WithReason("INVALID_PUSH_TOKEN")
+	}
+	return token, nil
+}
+
+func (s *pushTokenService) audit(ctx context.Context, userID uuid.UUID, action string, metadata map[string]any) {
+	entry := &models.AuditLog{
+		UserID:    &userID,
+		Action:    action,
+		Metadata:  metadata,
+		CreatedAt: time.Now(),
+	}
+	if err := s.auditLogRepo.Create(ctx, entry); err != nil {
+		slog.WarnContext(ctx, "failed to write audit log", "action", action, "error", err)
+	}
+}
+
+func toPushTokenResponse(token models.PushToken) dto.PushTokenResponse {
+	suffix := token.Token
+	if len(suffix) > 8 {
+		suffix = suffix[len(suffix)-8:]
+	}
+	return dto.PushTokenResponse{
+		ID:           token.ID,
+		Provider:     token.Provider,
+		TokenSuffix:  suffix,
+		Platform:     token.Platform,
+		AppID:        token.AppID,
+		AppVersion:   token.AppVersion,
+		OSVersion:    token.OSVersion,
+		DeviceModel:  token.DeviceModel,
+		CreatedAt:    token.CreatedAt,
+		RegisteredAt: token.UpdatedAt,
+	}
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"time"
+
+	"user-services/internal/api/repositories"
+	"user-services/internal/audit"
+	"user-services/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// SecurityEventTopic is the routing key every security event is published under, so a
+// SIEM shipper binds a single queue to receive them all.
+const SecurityEventTopic = "security.events"
+
+// SecurityEventSchemaVersion is bumped on breaking changes to the event payload.
+const SecurityEventSchemaVersion = 1
+
+// Security event types
+const (
+	SecurityEventLoginFailed          = "auth.login_failed"
+	SecurityEventMFAFailed            = "auth.mfa_failed"
+	SecurityEventAccountLockedOut     = "auth.account_locked_out"
+	SecurityEventIPLockedOut          = "auth.ip_locked_out"
+	SecurityEventRoleChanged          = "user.role_changed"
+	SecurityEventImpersonationStarted = "impersonation.started"
+	SecurityEventImpersonationEnded   = "impersonation.ended"
+	SecurityEventImpersonationDenied  = "impersonation.denied"
+)
+
+// Security event severities
+const (
+	SecuritySeverityLow    = "low"
+	SecuritySeverityMedium = "medium"
+	SecuritySeverityHigh   = "high"
+)
+
+// SecurityEvent is one security-relevant occurrence. UserID and Email name the account
+// it happened to, when known; the actor, IP and user agent come from the request.
+type SecurityEvent struct {
+	Type     string
+	Severity string
+	// Outcome is success or failure
+	Outcome string
+	UserID  *uuid.UUID
+	Email   string
+	// IPAddr overrides the client IP of the request, for callers that are handed it
+	IPAddr  string
+	Reason  string
+	Details map[string]any
+}
+
+// SecurityEventService publishes security events to RabbitMQ through the outbox for
+// the SIEM. Emitting never fails the operation it reports on.
+type SecurityEventService interface {
+	Emit(ctx context.Context, event SecurityEvent)
+}
+
+type securityEventService struct {
+	outboxRepo repositories.OutboxRepository
+}
+
+func NewSecurityEventService(outboxRepo repositories.OutboxRepository) SecurityEventService {
+	return &securityEventService{outboxRepo: outboxRepo}
+}
+
+func (s *securityEventService) Emit(ctx context.Context, event SecurityEvent) {
+	now := time.Now()
+	eventID := uuid.New()
+	req, _ := audit.FromContext(ctx)
+
+	category, _, _ := strings.Cut(event.Type, ".")
+	ipAddr := event.IPAddr
+	if ipAddr == "" {
+		ipAddr = req.IPAddr
+	}
+	details := event.Details
+	if details == nil {
+		details = map[string]any{}
+	}
+
+	payload, err := json.Marshal(map[string]any{
+		"schema_version": SecurityEventSchemaVersion,
+		"event_id":       eventID,
+		"event_type":     event.Type,
+		"category":       category,
+		"severity":       event.Severity,
+		"outcome":        event.Outcome,
+		"occurred_at":    now.UTC(),
+		"service":        "user-services",
+		"subject": map[string]any{
+			"user_id": event.UserID,
+			"email":   event.Email,
+		},
+		"actor": map[string]any{
+			"user_id":       req.ActorID,
+			"impersonating": req.Impersonating,
+		},
+		"source": map[string]any{
+			"ip":         ipAddr,
+			"user_agent": req.UserAgent,
+		},
+		"reason":  event.Reason,
+		"details": details,
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "failed to marshal security event", "event_type", event.Type, "error", err)
+		return
+	}
+
+	// Events about no known account are keyed by their own ID
+	aggregateID := eventID
+	if event.UserID != nil {
+		aggregateID = *event.UserID
+	}
+	if err := s.outboxRepo.Create(ctx, &models.Outbox{
+		AggregateID: aggregateID,
+		Topic:       SecurityEventTopic,
+		Type:        "SecurityEvent",
+		Payload:     payload,
+		CreatedAt:   now,
+	}); err != nil {
+		slog.WarnContext(ctx, "failed to queue security event", "event_type", event.Type, "error", err)
+	}
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/geoip"
+	"user-services/internal/models"
+	"user-services/internal/utils"
+)
+
+// SessionDescriber records the device and the approximate location a session is opened
+// from, so users can recognize their sessions without reading user agents and IPs.
+type SessionDescriber struct {
+	geo geoip.Resolver
+}
+
+// NewSessionDescriber creates a describer. A nil resolver leaves sessions without a
+// location.
+func NewSessionDescriber(geo geoip.Resolver) *SessionDescriber {
+	return &SessionDescriber{geo: geo}
+}
+
+// Describe fills in the device and location of a session about to be created. A failed
+// location lookup leaves the location empty rather than failing the sign-in. A nil
+// describer only parses the device.
+func (d *SessionDescriber) Describe(ctx context.Context, session *models.Session) {
+	device := utils.ParseUserAgent(session.UserAgent)
+	session.DeviceType = device.DeviceType
+	session.Browser = device.Browser
+	session.OS = device.OS
+
+	if d == nil || d.geo == nil || session.IPAddr == nil {
+		return
+	}
+	location, err := d.geo.Lookup(ctx, *session.IPAddr)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to locate session IP", "error", err)
+		return
+	}
+	if location == nil {
+		return
+	}
+	session.GeoCity = location.City
+	session.GeoRegion = location.Region
+	session.GeoCountry = location.Country
+	session.GeoCountryCode = location.CountryCode
+}
+
+// sessionLabel describes a session as "Chrome on Windows, Hanoi", leaving out what is
+// unknown.
+func sessionLabel(resp dto.SessionResponse) string {
+	label := deviceLabel(resp.Browser, resp.OS)
+	if resp.Location != nil {
+		if resp.Location.City != "" {
+			label += ", " + resp.Location.City
+		} else if resp.Location.Country != "" {
+			label += ", " + resp.Location.Country
+		}
+	}
+	return label
+}
+
+// deviceLabel describes a device as "Chrome on Windows", leaving out what is unknown.
+func deviceLabel(browser, os string) string {
+	switch {
+	case browser != "Unknown" && os != "Unknown":
+		return browser + " on " + os
+	case browser != "Unknown":
+		return browser
+	case os != "Unknown":
+		return os + " device"
+	}
+	return "Unknown device"
+}
@@ -8,6 +8,7 @@ import (
 	"user-services/internal/api/dto"
 	"user-services/internal/api/repositories"
 	"user-services/internal/cache"
+	"user-services/internal/config"
 	"user-services/internal/models"
 	"user-services/internal/utils"
 
@@ -31,12 +32,16 @@ type SessionService interface {
 type sessionService struct {
 	sessionRepo  repositories.SessionRepository
 	sessionCache *cache.SessionCache
+	describer    *SessionDescriber
 }
 
-func NewSessionService(sessionRepo repositories.SessionRepository, sessionCache *cache.SessionCache) SessionService {
+// NewSessionService creates a session service. describer may be nil where no session is
+// created; sessions then only get their device.
+func NewSessionService(sessionRepo repositories.SessionRepository, sessionCache *cache.SessionCache, describer *SessionDescriber) SessionService {
 	return &sessionService{
 		sessionRepo:  sessionRepo,
 		sessionCache: sessionCache,
+		describer:    describer,
 	}
 }
 
@@ -54,6 +59,7 @@ func (s *sessionService) CreateSession(ctx context.Context, userID uuid.UUID, us
 		CreatedAt: now,
 		ExpiresAt: now.Add(defaultSessionExpiry),
 	}
+	s.describer.Describe(ctx, session)
 
 	if err := s.sessionRepo.Create(ctx, session); err != nil {
 		return nil, err
@@ -96,6 +102,11 @@ func (s *sessionService) RevokeSession(ctx context.Context, sessionID uuid.UUID)
 		return err
 	}
 
+	// Reject access tokens already issued for the session until they expire
+	if err := s.sessionCache.RevokeSession(ctx, sessionID, config.GetConfig().JWT.ExpiresIn); err != nil {
+		return err
+	}
+
 	// Then, delete from Redis
 	if err := s.sessionCache.DeleteSession(ctx, sessionID); err != nil {
 		// Log error but don't fail the operation - DB is source of truth
@@ -125,6 +136,14 @@ func (s *sessionService) RevokeAllUserSessions(ctx context.Context, userID uuid.
 		return err
 	}
 
+	// Reject access tokens already issued for those sessions until they expire
+	accessTTL := config.GetConfig().JWT.ExpiresIn
+	for _, sessionID := range sessionIDs {
+		if err := s.sessionCache.RevokeSession(ctx, sessionID, accessTTL); err != nil {
+			return err
+		}
+	}
+
 	// Delete from Redis
 	if len(sessionIDs) > 0 {
 		if err := s.sessionCache.DeleteAllUserSessions(ctx, sessionIDs); err != nil {
@@ -141,12 +160,31 @@ func (s *sessionService) CleanupExpiredSessions(ctx context.Context) error {
 }
 
 func modelToSessionDTO(session models.Session) dto.SessionResponse {
-	return dto.SessionResponse{
-		ID:        session.ID,
-		UserAgent: session.UserAgent,
-		IPAddr:    session.IPAddr,
-		CreatedAt: session.CreatedAt,
-		ExpiresAt: session.ExpiresAt,
-		IsCurrent: false,
+	resp := dto.SessionResponse{
+		ID:           session.ID,
+		UserAgent:    session.UserAgent,
+		IPAddr:       session.IPAddr,
+		DeviceType:   session.DeviceType,
+		Browser:      session.Browser,
+		OS:           session.OS,
+		CreatedAt:    session.CreatedAt,
+		ExpiresAt:    session.ExpiresAt,
+		IsCurrent:    false,
+		Impersonated: session.ImpersonatorID != nil,
+	}
+	// Sessions created before devices were recorded
+	if session.DeviceType == "" {
+		device := utils.ParseUserAgent(session.UserAgent)
+		resp.DeviceType, resp.Browser, resp.OS = device.DeviceType, device.Browser, device.OS
+	}
+	if session.GeoCountry != "" {
+		resp.Location = &dto.SessionLocation{
+			City:        session.GeoCity,
+			Region:      session.GeoRegion,
+			Country:     session.GeoCountry,
+			CountryCode: session.GeoCountryCode,
+		}
 	}
+	resp.Label = sessionLabel(resp)
+	return resp
 }
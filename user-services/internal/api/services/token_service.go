@@ -2,56 +2,301 @@ package services
 
 import (
 	"context"
+	stderrors "errors"
+	"log/slog"
+	"time"
+
 	"user-services/internal/api/dto"
+	"user-services/internal/api/helpers"
 	"user-services/internal/api/repositories"
+	"user-services/internal/cache"
+	"user-services/internal/config"
+	"user-services/internal/errors"
+	"user-services/internal/models"
+	"user-services/internal/utils"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+// TokenService issues and rotates access/refresh token pairs. All refresh tokens issued
+// for one session form a family: presenting a token that was already rotated revokes
+// the whole family, since either the client or an attacker holds a stolen copy.
 type TokenService interface {
 	GenerateTokenPair(ctx context.Context, userID, sessionID uuid.UUID) (*dto.AuthResponse, error)
 	RefreshAccessToken(ctx context.Context, refreshToken string) (*dto.RefreshTokenResponse, error)
 	ValidateAccessToken(ctx context.Context, token string) (*uuid.UUID, error)
-	RevokeRefreshToken(ctx context.Context, tokenHash string) error
+	RevokeRefreshToken(ctx context.Context, userID uuid.UUID, refreshToken string) error
+	RevokeSession(ctx context.Context, sessionID uuid.UUID) error
 	CleanupExpiredTokens(ctx context.Context) error
 }
 
 type tokenService struct {
 	refreshTokenRepo repositories.RefreshTokenRepository
 	sessionRepo      repositories.SessionRepository
+	userRepo         repositories.UserRepository
+	orgRepo          repositories.OrganizationRepository
+	sessionCache     *cache.SessionCache
 }
 
 func NewTokenService(
 	refreshTokenRepo repositories.RefreshTokenRepository,
 	sessionRepo repositories.SessionRepository,
+	userRepo repositories.UserRepository,
+	orgRepo repositories.OrganizationRepository,
+	sessionCache *cache.SessionCache,
 ) TokenService {
 	return &tokenService{
 		refreshTokenRepo: refreshTokenRepo,
 		sessionRepo:      sessionRepo,
+		userRepo:         userRepo,
+		orgRepo:          orgRepo,
+		sessionCache:     sessionCache,
 	}
 }
 
 func (s *tokenService) GenerateTokenPair(ctx context.Context, userID, sessionID uuid.UUID) (*dto.AuthResponse, error) {
-	// TODO: implement JWT generation and refresh token creation
-	return nil, nil
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.ErrUserNotFound
+	}
+
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil || session.UserID != userID {
+		return nil, errors.ErrSessionNotFound
+	}
+
+	accessToken, expiresAt, err := issueAccessToken(ctx, s.orgRepo, user, session)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := issueRefreshToken(ctx, s.refreshTokenRepo, session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.AuthResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    expiresAt,
+		User:         helpers.ToPublicUser(*user),
+	}, nil
 }
 
+// RefreshAccessToken exchanges a refresh token for a new token pair. The presented token
+// is consumed, so every refresh token can be used exactly once.
 func (s *tokenService) RefreshAccessToken(ctx context.Context, refreshToken string) (*dto.RefreshTokenResponse, error) {
-	// TODO: implement token rotation
-	return nil, nil
+	stored, err := s.refreshTokenRepo.GetByTokenHash(ctx, utils.HashToken(refreshToken))
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrRefreshTokenInvalid
+		}
+		return nil, err
+	}
+
+	if stored.RevokedAt.Valid || time.Now().After(stored.ExpiresAt) {
+		return nil, errors.ErrRefreshTokenInvalid
+	}
+	if stored.ConsumedAt.Valid {
+		s.revokeFamilyAfterReuse(ctx, stored.SessionID)
+		return nil, errors.ErrRefreshTokenReused
+	}
+
+	// Losing the race to consume the token means another request rotated it first.
+	consumed, err := s.refreshTokenRepo.Consume(ctx, stored.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		s.revokeFamilyAfterReuse(ctx, stored.SessionID)
+		return nil, errors.ErrRefreshTokenReused
+	}
+
+	session, err := s.activeSession(ctx, stored.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, session.UserID)
+	if err != nil {
+		return nil, errors.ErrRefreshTokenInvalid
+	}
+	switch user.Status {
+	case "locked":
+		_ = s.RevokeSession(ctx, session.ID)
+		return nil, errors.ErrAccountLocked
+	case "disabled", "deleted":
+		_ = s.RevokeSession(ctx, session.ID)
+		return nil, errors.ErrAccountDisabled
+	}
+
+	accessToken, expiresAt, err := issueAccessToken(ctx, s.orgRepo, user, session)
+	if err != nil {
+		return nil, err
+	}
+
+	newRefreshToken, err := issueRefreshToken(ctx, s.refreshTokenRepo, session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.RefreshTokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: newRefreshToken,
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+// activeSession loads the session a refresh token belongs to and rejects it when the
+// session has ended, either in the database, on the revocation list, or in Redis (the
+// BFF drops idle sessions there).
+func (s *tokenService) activeSession(ctx context.Context, sessionID uuid.UUID) (*models.Session, error) {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, errors.ErrRefreshTokenInvalid
+	}
+	if session.RevokedAt.Valid || time.Now().After(session.ExpiresAt) {
+		return nil, errors.ErrRefreshTokenInvalid
+	}
+
+	revoked, err := s.sessionCache.IsSessionRevoked(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, errors.ErrRefreshTokenInvalid
+	}
+
+	exists, err := s.sessionCache.SessionExists(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		_ = s.refreshTokenRepo.RevokeBySessionID(ctx, sessionID)
+		return nil, errors.ErrSessionExpired
+	}
+
+	return session, nil
+}
+
+func (s *tokenService) revokeFamilyAfterReuse(ctx context.Context, sessionID uuid.UUID) {
+	if err := s.RevokeSession(ctx, sessionID); err != nil {
+		slog.WarnContext(ctx, "failed to revoke session after refresh token reuse", "session_id", sessionID, "error", err)
+	}
 }
 
 func (s *tokenService) ValidateAccessToken(ctx context.Context, token string) (*uuid.UUID, error) {
-	// TODO: implement JWT validation
-	return nil, nil
+	claims, err := utils.ValidateJWT(token)
+	if err != nil {
+		return nil, errors.ErrTokenInvalid
+	}
+
+	revoked, err := s.sessionCache.IsSessionRevoked(ctx, claims.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, errors.ErrTokenInvalid
+	}
+
+	return &claims.UserID, nil
 }
 
-func (s *tokenService) RevokeRefreshToken(ctx context.Context, tokenHash string) error {
-	// TODO: implement
-	return nil
+// RevokeRefreshToken revokes a single refresh token owned by userID. Access tokens
+// already issued stay valid until they expire.
+func (s *tokenService) RevokeRefreshToken(ctx context.Context, userID uuid.UUID, refreshToken string) error {
+	stored, err := s.refreshTokenRepo.GetByTokenHash(ctx, utils.HashToken(refreshToken))
+	if err != nil {
+		return errors.ErrRefreshTokenInvalid
+	}
+
+	session, err := s.sessionRepo.GetByID(ctx, stored.SessionID)
+	if err != nil || session.UserID != userID {
+		return errors.ErrRefreshTokenInvalid
+	}
+
+	return s.refreshTokenRepo.Revoke(ctx, stored.ID)
+}
+
+// RevokeSession ends a session and its whole token family: refresh tokens are revoked,
+// the session is marked revoked and removed from Redis, and it is put on the revocation
+// list so access tokens issued for it are rejected until they expire.
+func (s *tokenService) RevokeSession(ctx context.Context, sessionID uuid.UUID) error {
+	cfg := config.GetConfig()
+
+	var errs []error
+	if err := s.refreshTokenRepo.RevokeBySessionID(ctx, sessionID); err != nil {
+		errs = append(errs, err)
+	}
+	if err := s.sessionRepo.Revoke(ctx, sessionID); err != nil && !stderrors.Is(err, gorm.ErrRecordNotFound) {
+		errs = append(errs, err)
+	}
+	if err := s.sessionCache.RevokeSession(ctx, sessionID, cfg.JWT.ExpiresIn); err != nil {
+		errs = append(errs, err)
+	}
+	if err := s.sessionCache.DeleteSession(ctx, sessionID); err != nil {
+		errs = append(errs, err)
+	}
+	return stderrors.Join(errs...)
 }
 
 func (s *tokenService) CleanupExpiredTokens(ctx context.Context) error {
-	// TODO: implement
-	return nil
+	return s.refreshTokenRepo.DeleteExpired(ctx)
+}
+
+// issueAccessToken signs a short-lived access JWT for the session. It carries the user's
+// primary organization and their role in it, read fresh on every refresh; a failed
+// lookup leaves them out rather than failing the sign-in. Tokens of impersonation
+// sessions also name the impersonating administrator.
+func issueAccessToken(ctx context.Context, orgRepo repositories.OrganizationRepository, user *models.User, session *models.Session) (string, time.Time, error) {
+	cfg := config.GetConfig()
+
+	var org *utils.OrgClaims
+	if user.OrganizationID != nil {
+		member, err := orgRepo.GetMember(ctx, *user.OrganizationID, user.ID)
+		switch {
+		case err == nil:
+			org = &utils.OrgClaims{ID: member.OrganizationID, Role: member.Role}
+		case !stderrors.Is(err, gorm.ErrRecordNotFound):
+			slog.WarnContext(ctx, "failed to load organization membership", "user_id", user.ID, "error", err)
+		}
+	}
+
+	expiresAt := time.Now().Add(cfg.JWT.ExpiresIn)
+	accessToken, err := utils.GenerateJWT(user.ID, user.Email, session.ID, org, session.ImpersonatorID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return accessToken, expiresAt, nil
+}
+
+// issueRefreshToken stores a new refresh token for the session and returns the raw
+// value. Only its SHA-256 hash is persisted so it can be looked up on use. The token
+// never outlives its session.
+func issueRefreshToken(ctx context.Context, repo repositories.RefreshTokenRepository, session *models.Session) (string, error) {
+	cfg := config.GetConfig()
+
+	rawRefresh, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(cfg.JWT.RefreshExpiresIn)
+	if session.ExpiresAt.Before(expiresAt) {
+		expiresAt = session.ExpiresAt
+	}
+
+	refresh := &models.RefreshToken{
+		SessionID: session.ID,
+		TokenHash: utils.HashToken(rawRefresh),
+		IssuedAt:  now,
+		ExpiresAt: expiresAt,
+	}
+	if err := repo.Create(ctx, refresh); err != nil {
+		return "", err
+	}
+
+	return rawRefresh, nil
 }
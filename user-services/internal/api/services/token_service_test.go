@@ -0,0 +1,445 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+	"sync"
+	"testing"
+	"time"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/repositories"
+	"user-services/internal/cache"
+	"user-services/internal/errors"
+	"user-services/internal/models"
+	"user-services/internal/utils"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// memoryRefreshTokens keeps refresh tokens in memory with the semantics of the
+// PostgreSQL repository: Consume succeeds once per token.
+type memoryRefreshTokens struct {
+	mu     sync.Mutex
+	tokens map[uuid.UUID]*models.RefreshToken
+}
+
+func (r *memoryRefreshTokens) Create(ctx context.Context, token *models.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token.ID = uuid.New()
+	stored := *token
+	r.tokens[token.ID] = &stored
+	return nil
+}
+
+func (r *memoryRefreshTokens) GetByTokenHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			found := *token
+			return &found, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryRefreshTokens) Consume(ctx context.Context, id uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token := r.tokens[id]
+	if token == nil || token.ConsumedAt.Valid {
+		return false, nil
+	}
+	token.ConsumedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return true, nil
+}
+
+func (r *memoryRefreshTokens) Revoke(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if token := r.tokens[id]; token != nil {
+		token.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+	return nil
+}
+
+func (r *memoryRefreshTokens) RevokeBySessionID(ctx context.Context, sessionID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range r.tokens {
+		if token.SessionID == sessionID && !token.RevokedAt.Valid {
+			token.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		}
+	}
+	return nil
+}
+
+func (r *memoryRefreshTokens) DeleteExpired(ctx context.Context) error {
+	return nil
+}
+
+// memorySessions implements the session lookups of the token service.
+type memorySessions struct {
+	repositories.SessionRepository
+	mu       sync.Mutex
+	sessions map[uuid.UUID]*models.Session
+}
+
+func (r *memorySessions) GetByID(ctx context.Context, id uuid.UUID) (*models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session := r.sessions[id]
+	if session == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *session
+	return &found, nil
+}
+
+func (r *memorySessions) Revoke(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session := r.sessions[id]
+	if session == nil {
+		return gorm.ErrRecordNotFound
+	}
+	session.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return nil
+}
+
+// memoryUsers implements the user lookup of the token service.
+type memoryUsers struct {
+	repositories.UserRepository
+	users map[uuid.UUID]*models.User
+}
+
+func (r *memoryUsers) GetByID(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	user := r.users[userID]
+	if user == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *user
+	return &found, nil
+}
+
+type tokenFixture struct {
+	service      TokenService
+	tokens       *memoryRefreshTokens
+	sessions     *memorySessions
+	sessionCache *cache.SessionCache
+	redis        *miniredis.Miniredis
+	user         *models.User
+	session      *models.Session
+}
+
+// newTokenFixture sets up a token service with one active user and session, the session
+// stored in Redis as after a login.
+func newTokenFixture(t *testing.T) *tokenFixture {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	lastLoginIP := "203.0.113.7"
+	user := &models.User{ID: uuid.New(), Email: "ada@example.com", Status: "active", LastLoginIP: &lastLoginIP}
+	session := &models.Session{ID: uuid.New(), UserID: user.ID, CreatedAt: time.Now(), ExpiresAt: time.Now().Add(24 * time.Hour)}
+
+	f := &tokenFixture{
+		tokens:       &memoryRefreshTokens{tokens: map[uuid.UUID]*models.RefreshToken{}},
+		sessions:     &memorySessions{sessions: map[uuid.UUID]*models.Session{session.ID: session}},
+		sessionCache: cache.NewSessionCache(client),
+		redis:        mr,
+		user:         user,
+		session:      session,
+	}
+	f.service = NewTokenService(f.tokens, f.sessions, &memoryUsers{users: map[uuid.UUID]*models.User{user.ID: user}}, nil, f.sessionCache)
+
+	if err := f.sessionCache.StoreSession(context.Background(), session.ID, cache.SessionData{UserID: user.ID, Email: user.Email}, time.Hour); err != nil {
+		t.Fatalf("store session: %v", err)
+	}
+	return f
+}
+
+// assertFamilyRevoked checks that every refresh token of the session is revoked and the
+// session is ended in the database, in Redis and on the revocation list.
+func (f *tokenFixture) assertFamilyRevoked(t *testing.T) {
+	t.Helper()
+	for _, token := range f.tokens.tokens {
+		if token.SessionID == f.session.ID && !token.RevokedAt.Valid {
+			t.Errorf("refresh token %s is not revoked", token.ID)
+		}
+	}
+	f.assertSessionRevoked(t)
+}
+
+func (f *tokenFixture) assertSessionRevoked(t *testing.T) {
+	t.Helper()
+	ctx := context.Background()
+
+	if !f.sessions.sessions[f.session.ID].RevokedAt.Valid {
+		t.Error("session is not revoked in the database")
+	}
+	if exists, _ := f.sessionCache.SessionExists(ctx, f.session.ID); exists {
+		t.Error("session is still stored in Redis")
+	}
+	if revoked, _ := f.sessionCache.IsSessionRevoked(ctx, f.session.ID); !revoked {
+		t.Error("session is not on the revocation list")
+	}
+}
+
+func TestRefreshAccessTokenRotates(t *testing.T) {
+	ctx := context.Background()
+	f := newTokenFixture(t)
+
+	pair, err := f.service.GenerateTokenPair(ctx, f.user.ID, f.session.ID)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair: %v", err)
+	}
+
+	refreshed, err := f.service.RefreshAccessToken(ctx, pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshAccessToken: %v", err)
+	}
+	if refreshed.RefreshToken == pair.RefreshToken {
+		t.Fatal("refresh token was not rotated")
+	}
+	if _, err := f.service.ValidateAccessToken(ctx, refreshed.AccessToken); err != nil {
+		t.Fatalf("rotated access token is invalid: %v", err)
+	}
+
+	old, err := f.tokens.GetByTokenHash(ctx, utils.HashToken(pair.RefreshToken))
+	if err != nil || !old.ConsumedAt.Valid {
+		t.Fatalf("presented token was not consumed: %+v, %v", old, err)
+	}
+	if _, err := f.tokens.GetByTokenHash(ctx, utils.HashToken(refreshed.RefreshToken)); err != nil {
+		t.Fatalf("rotated token is not stored by hash: %v", err)
+	}
+
+	// The rotated token is good for one more rotation.
+	if _, err := f.service.RefreshAccessToken(ctx, refreshed.RefreshToken); err != nil {
+		t.Fatalf("second rotation: %v", err)
+	}
+}
+
+// TestRefreshAccessTokenReuseRevokesFamily checks that presenting a rotated token
+// again ends the whole session, including the tokens issued after it.
+func TestRefreshAccessTokenReuseRevokesFamily(t *testing.T) {
+	ctx := context.Background()
+	f := newTokenFixture(t)
+
+	pair, err := f.service.GenerateTokenPair(ctx, f.user.ID, f.session.ID)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair: %v", err)
+	}
+	rotated, err := f.service.RefreshAccessToken(ctx, pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshAccessToken: %v", err)
+	}
+
+	if _, err := f.service.RefreshAccessToken(ctx, pair.RefreshToken); !stderrors.Is(err, errors.ErrRefreshTokenReused) {
+		t.Fatalf("reused token: error = %v, want %v", err, errors.ErrRefreshTokenReused)
+	}
+	f.assertFamilyRevoked(t)
+
+	if _, err := f.service.RefreshAccessToken(ctx, rotated.RefreshToken); !stderrors.Is(err, errors.ErrRefreshTokenInvalid) {
+		t.Fatalf("token rotated before the reuse: error = %v, want %v", err, errors.ErrRefreshTokenInvalid)
+	}
+	if _, err := f.service.ValidateAccessToken(ctx, rotated.AccessToken); !stderrors.Is(err, errors.ErrTokenInvalid) {
+		t.Fatalf("access token of the revoked session: error = %v, want %v", err, errors.ErrTokenInvalid)
+	}
+}
+
+// TestRefreshAccessTokenConcurrentRotation checks that of two requests racing to rotate
+// the same token, only one can win and the other is treated as reuse. The winner may
+// still be turned away if the loser has revoked the session by then, and a token it got
+// before that is dead with the session.
+func TestRefreshAccessTokenConcurrentRotation(t *testing.T) {
+	ctx := context.Background()
+	f := newTokenFixture(t)
+
+	pair, err := f.service.GenerateTokenPair(ctx, f.user.ID, f.session.ID)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair: %v", err)
+	}
+
+	responses := make([]*dto.RefreshTokenResponse, 2)
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i], errs[i] = f.service.RefreshAccessToken(ctx, pair.RefreshToken)
+		}(i)
+	}
+	wg.Wait()
+
+	var reused int
+	for _, err := range errs {
+		switch {
+		case err == nil, stderrors.Is(err, errors.ErrRefreshTokenInvalid):
+		case stderrors.Is(err, errors.ErrRefreshTokenReused):
+			reused++
+		default:
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if reused != 1 {
+		t.Fatalf("reused = %d, want 1: %v", reused, errs)
+	}
+	f.assertSessionRevoked(t)
+
+	for _, resp := range responses {
+		if resp == nil {
+			continue
+		}
+		if _, err := f.service.RefreshAccessToken(ctx, resp.RefreshToken); !stderrors.Is(err, errors.ErrRefreshTokenInvalid) {
+			t.Fatalf("token of the winner: error = %v, want %v", err, errors.ErrRefreshTokenInvalid)
+		}
+	}
+}
+
+func TestRefreshAccessTokenRejects(t *testing.T) {
+	cases := []struct {
+		name    string
+		unknown bool
+		prepare func(t *testing.T, f *tokenFixture, token string)
+		want    error
+	}{
+		{
+			name:    "UnknownToken",
+			unknown: true,
+			prepare: func(t *testing.T, f *tokenFixture, token string) {},
+			want:    errors.ErrRefreshTokenInvalid,
+		},
+		{
+			name: "ExpiredToken",
+			prepare: func(t *testing.T, f *tokenFixture, token string) {
+				for _, stored := range f.tokens.tokens {
+					stored.ExpiresAt = time.Now().Add(-time.Minute)
+				}
+			},
+			want: errors.ErrRefreshTokenInvalid,
+		},
+		{
+			name: "RevokedToken",
+			prepare: func(t *testing.T, f *tokenFixture, token string) {
+				stored, _ := f.tokens.GetByTokenHash(context.Background(), utils.HashToken(token))
+				_ = f.tokens.Revoke(context.Background(), stored.ID)
+			},
+			want: errors.ErrRefreshTokenInvalid,
+		},
+		{
+			name: "SessionOnRevocationList",
+			prepare: func(t *testing.T, f *tokenFixture, token string) {
+				if err := f.sessionCache.RevokeSession(context.Background(), f.session.ID, time.Hour); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: errors.ErrRefreshTokenInvalid,
+		},
+		{
+			name: "SessionRevokedInDatabase",
+			prepare: func(t *testing.T, f *tokenFixture, token string) {
+				_ = f.sessions.Revoke(context.Background(), f.session.ID)
+			},
+			want: errors.ErrRefreshTokenInvalid,
+		},
+		{
+			name: "SessionDroppedFromRedis",
+			prepare: func(t *testing.T, f *tokenFixture, token string) {
+				f.redis.FastForward(2 * time.Hour)
+			},
+			want: errors.ErrSessionExpired,
+		},
+		{
+			name: "AccountLocked",
+			prepare: func(t *testing.T, f *tokenFixture, token string) {
+				f.user.Status = "locked"
+			},
+			want: errors.ErrAccountLocked,
+		},
+		{
+			name: "AccountDisabled",
+			prepare: func(t *testing.T, f *tokenFixture, token string) {
+				f.user.Status = "disabled"
+			},
+			want: errors.ErrAccountDisabled,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newTokenFixture(t)
+
+			pair, err := f.service.GenerateTokenPair(ctx, f.user.ID, f.session.ID)
+			if err != nil {
+				t.Fatalf("GenerateTokenPair: %v", err)
+			}
+			token := pair.RefreshToken
+			if tc.unknown {
+				token = "not-a-refresh-token"
+			}
+			tc.prepare(t, f, token)
+
+			if _, err := f.service.RefreshAccessToken(ctx, token); !stderrors.Is(err, tc.want) {
+				t.Fatalf("RefreshAccessToken error = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+// TestRevokeSessionRejectsAccessTokens checks the revocation list: access tokens of a
+// revoked session are rejected until the entry expires with the longest access token.
+func TestRevokeSessionRejectsAccessTokens(t *testing.T) {
+	ctx := context.Background()
+	f := newTokenFixture(t)
+
+	pair, err := f.service.GenerateTokenPair(ctx, f.user.ID, f.session.ID)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair: %v", err)
+	}
+	if _, err := f.service.ValidateAccessToken(ctx, pair.AccessToken); err != nil {
+		t.Fatalf("ValidateAccessToken before revocation: %v", err)
+	}
+
+	if err := f.service.RevokeSession(ctx, f.session.ID); err != nil {
+		t.Fatalf("RevokeSession: %v", err)
+	}
+	f.assertFamilyRevoked(t)
+	if _, err := f.service.ValidateAccessToken(ctx, pair.AccessToken); !stderrors.Is(err, errors.ErrTokenInvalid) {
+		t.Fatalf("ValidateAccessToken after revocation: error = %v, want %v", err, errors.ErrTokenInvalid)
+	}
+
+	key := "revoked_session:" + f.session.ID.String()
+	if ttl := f.redis.TTL(key); ttl <= 0 || ttl > 15*time.Minute {
+		t.Fatalf("revocation list entry TTL = %v, want the access token lifetime", ttl)
+	}
+}
+
+func TestRevokeRefreshTokenChecksOwner(t *testing.T) {
+	ctx := context.Background()
+	f := newTokenFixture(t)
+
+	pair, err := f.service.GenerateTokenPair(ctx, f.user.ID, f.session.ID)
+	if err != nil {
+		t.Fatalf("GenerateTokenPair: %v", err)
+	}
+
+	if err := f.service.RevokeRefreshToken(ctx, uuid.New(), pair.RefreshToken); !stderrors.Is(err, errors.ErrRefreshTokenInvalid) {
+		t.Fatalf("revoke by another user: error = %v, want %v", err, errors.ErrRefreshTokenInvalid)
+	}
+	if err := f.service.RevokeRefreshToken(ctx, f.user.ID, pair.RefreshToken); err != nil {
+		t.Fatalf("RevokeRefreshToken: %v", err)
+	}
+	if _, err := f.service.RefreshAccessToken(ctx, pair.RefreshToken); !stderrors.Is(err, errors.ErrRefreshTokenInvalid) {
+		t.Fatalf("revoked token: error = %v, want %v", err, errors.ErrRefreshTokenInvalid)
+	}
+}
@@ -0,0 +1,393 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/repositories"
+	"user-services/internal/config"
+	"user-services/internal/errors"
+	"user-services/internal/models"
+	"user-services/internal/utils"
+
+	"github.com/ductan2/microservice-app/shared/ids"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Outcomes of an imported row.
+const (
+	ImportRowCreated = "created"
+	ImportRowSkipped = "skipped"
+	ImportRowFailed  = "failed"
+)
+
+// maxImportNameLength bounds the display name and organization name of an imported row.
+const maxImportNameLength = 200
+
+// importableRoles are the roles an import may assign. Admin roles are only granted one
+// user at a time through the role endpoint.
+var importableRoles = map[string]bool{
+	models.RoleStudent: true,
+	models.RoleTeacher: true,
+}
+
+// UserImportService provisions accounts in bulk for B2B customers. Every account is
+// created invited, without a password, and assigned a role and optionally an
+// organization. Emails are compared ignoring case: one that already has an account, or
+// appears earlier in the same import, is skipped. Each created user is emailed an
+// invitation link through the outbox; following it sets their password, which verifies
+// their email and activates the account.
+type UserImportService interface {
+	Import(ctx context.Context, req dto.UserImportRequest) (*dto.UserImportResponse, error)
+}
+
+type userImportService struct {
+	importRepo   repositories.UserImportRepository
+	orgRepo      repositories.OrganizationRepository
+	auditLogRepo repositories.AuditLogRepository
+	cfg          config.ImportConfig
+}
+
+func NewUserImportService(
+	importRepo repositories.UserImportRepository,
+	orgRepo repositories.OrganizationRepository,
+	auditLogRepo repositories.AuditLogRepository,
+	cfg config.ImportConfig,
+) UserImportService {
+	return &userImportService{
+		importRepo:   importRepo,
+		orgRepo:      orgRepo,
+		auditLogRepo: auditLogRepo,
+		cfg:          cfg,
+	}
+}
+
+// ParseUserImportCSV reads the rows of a bulk import CSV. The first line is a header
+// naming the columns, in any order and case: email is required, name, role and
+// organization are optional, and unknown columns are ignored.
+func ParseUserImportCSV(r io.Reader, maxRows int) ([]dto.UserImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if stderrors.Is(err, io.EOF) {
+		return nil, errors.ErrImportEmpty
+	}
+	if err != nil {
+		return nil, csvError(err)
+	}
+
+	columns := map[string]int{"email": -1, "name": -1, "role": -1, "organization": -1}
+	for idx, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if col, ok := columns[name]; ok && col == -1 {
+			columns[name] = idx
+		}
+	}
+	if columns["email"] == -1 {
+		return nil, errors.NewInvalidImportCSVError(1, "the header has no email column")
+	}
+
+	var rows []dto.UserImportRow
+	for {
+		record, err := reader.Read()
+		if stderrors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, csvError(err)
+		}
+		if len(rows) == maxRows {
+			return nil, errors.NewImportTooLargeError(maxRows)
+		}
+
+		field := func(name string) string {
+			if idx := columns[name]; idx >= 0 && idx < len(record) {
+				return record[idx]
+			}
+			return ""
+		}
+		rows = append(rows, dto.UserImportRow{
+			Email:        field("email"),
+			Name:         field("name"),
+			Role:         field("role"),
+			Organization: field("organization"),
+		})
+	}
+	return rows, nil
+}
+
+func csvError(err error) error {
+	var parseErr *csv.ParseError
+	if stderrors.As(err, &parseErr) {
+		return errors.NewInvalidImportCSVError(parseErr.Line, parseErr.Err.Error())
+	}
+	return errors.NewInvalidImportCSVError(0, err.Error())
+}
+
+func (s *userImportService) Import(ctx context.Context, req dto.UserImportRequest) (*dto.UserImportResponse, error) {
+	if len(req.Users) == 0 {
+		return nil, errors.ErrImportEmpty
+	}
+	if len(req.Users) > s.cfg.MaxRows {
+		return nil, errors.NewImportTooLargeError(s.cfg.MaxRows)
+	}
+	sendInvites := req.SendInvites == nil || *req.SendInvites
+
+	resp := &dto.UserImportResponse{
+		Total: len(req.Users),
+		Rows:  make([]dto.UserImportRowResult, len(req.Users)),
+	}
+
+	// Validate every row and drop duplicates within the import first, so the existing
+	// accounts can be looked up in one query
+	rows := make([]dto.UserImportRow, len(req.Users))
+	seen := make(map[string]int, len(req.Users))
+	var emails []string
+	for idx, row := range req.Users {
+		row = normalizeImportRow(row, req)
+		rows[idx] = row
+		result := &resp.Rows[idx]
+		result.Row = idx + 1
+		result.Email = row.Email
+
+		if code, message := validateImportRow(row); code != "" {
+			result.Status, result.Code, result.Error = ImportRowFailed, code, message
+			continue
+		}
+		if first, ok := seen[row.Email]; ok {
+			result.Status, result.Code = ImportRowSkipped, "DUPLICATE_EMAIL"
+			result.Error = fmt.Sprintf("The email already appears in row %d", first)
+			continue
+		}
+		seen[row.Email] = idx + 1
+		emails = append(emails, row.Email)
+	}
+
+	existing, err := s.importRepo.ExistingEmails(ctx, emails)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up existing accounts: %w", err)
+	}
+
+	orgs := make(map[string]*models.Organization)
+	for idx, row := range rows {
+		result := &resp.Rows[idx]
+		if result.Status != "" {
+			continue
+		}
+		if existing[row.Email] {
+			result.Status, result.Code, result.Error = ImportRowSkipped, "EMAIL_EXISTS", "An account with this email already exists"
+			continue
+		}
+
+		var org *models.Organization
+		if row.Organization != "" {
+			org, err = s.resolveOrganization(ctx, orgs, row.Organization)
+			if err != nil {
+				result.Status, result.Code, result.Error = ImportRowFailed, "ORGANIZATION_NOT_FOUND", "No organization has this ID"
+				if !stderrors.Is(err, gorm.ErrRecordNotFound) {
+					result.Code, result.Error = "IMPORT_FAILED", "The organization could not be created"
+					slog.WarnContext(ctx, "failed to resolve organization for import", "organization", row.Organization, "error", err)
+				}
+				continue
+			}
+			result.OrganizationID = &org.ID
+		}
+
+		userID, created, err := s.createInvitedUser(ctx, row, org, sendInvites)
+		switch {
+		case stderrors.Is(err, repositories.ErrOrganizationFull):
+			result.Status, result.Code, result.Error = ImportRowFailed, "ORGANIZATION_FULL", "The organization has no seats left"
+		case err != nil:
+			result.Status, result.Code, result.Error = ImportRowFailed, "IMPORT_FAILED", "The account could not be created"
+			slog.WarnContext(ctx, "failed to import user row", "row", idx+1, "error", err)
+		case !created:
+			result.Status, result.Code, result.Error = ImportRowSkipped, "EMAIL_EXISTS", "An account with this email already exists"
+		default:
+			result.Status = ImportRowCreated
+			result.UserID = &userID
+		}
+	}
+
+	for _, result := range resp.Rows {
+		switch result.Status {
+		case ImportRowCreated:
+			resp.Created++
+		case ImportRowSkipped:
+			resp.Skipped++
+		default:
+			resp.Failed++
+		}
+	}
+
+	_ = s.auditLogRepo.Create(ctx, &models.AuditLog{
+		Action: "user.bulk_imported",
+		Metadata: map[string]any{
+			"total":        resp.Total,
+			"created":      resp.Created,
+			"skipped":      resp.Skipped,
+			"failed":       resp.Failed,
+			"organization": req.Organization,
+			"send_invites": sendInvites,
+		},
+		CreatedAt: time.Now(),
+	})
+
+	return resp, nil
+}
+
+// normalizeImportRow trims the row, lower-cases its email and role, and fills in the
+// import's defaults.
+func normalizeImportRow(row dto.UserImportRow, req dto.UserImportRequest) dto.UserImportRow {
+	row.Email = strings.ToLower(strings.TrimSpace(row.Email))
+	row.Name = strings.TrimSpace(row.Name)
+	row.Role = strings.ToLower(strings.TrimSpace(row.Role))
+	row.Organization = strings.TrimSpace(row.Organization)
+	if row.Role == "" {
+		row.Role = req.Role
+	}
+	if row.Role == "" {
+		row.Role = models.RoleStudent
+	}
+	if row.Organization == "" {
+		row.Organization = strings.TrimSpace(req.Organization)
+	}
+	return row
+}
+
+// validateImportRow returns the error code and message of an invalid row, or an empty
+// code.
+func validateImportRow(row dto.UserImportRow) (string, string) {
+	if err := utils.ValidateEmail(row.Email); err != nil {
+		return "INVALID_EMAIL", "The email address is missing or invalid"
+	}
+	if !importableRoles[row.Role] {
+		return "INVALID_ROLE", "The role must be student or teacher"
+	}
+	if len(row.Name) > maxImportNameLength {
+		return "INVALID_NAME", fmt.Sprintf("The name must be at most %d characters", maxImportNameLength)
+	}
+	if len(row.Organization) > maxImportNameLength {
+		return "INVALID_ORGANIZATION", fmt.Sprintf("The organization must be at most %d characters", maxImportNameLength)
+	}
+	return "", ""
+}
+
+// resolveOrganization finds the organization a row names: an existing one by ID, or one
+// by name, created if needed. Results are cached for the rest of the import.
+func (s *userImportService) resolveOrganization(ctx context.Context, cache map[string]*models.Organization, value string) (*models.Organization, error) {
+	key := strings.ToLower(value)
+	if org, ok := cache[key]; ok {
+		return org, nil
+	}
+
+	var org *models.Organization
+	var err error
+	if id, parseErr := uuid.Parse(value); parseErr == nil {
+		org, err = s.orgRepo.GetByID(ctx, id)
+	} else {
+		org, err = s.orgRepo.FindOrCreate(ctx, value)
+	}
+	if err != nil {
+		return nil, err
+	}
+	cache[key] = org
+	return org, nil
+}
+
+// createInvitedUser saves one invited account, with its invitation when sendInvite is
+// set. created is false when the email was taken concurrently.
+func (s *userImportService) createInvitedUser(ctx context.Context, row dto.UserImportRow, org *models.Organization, sendInvite bool) (uuid.UUID, bool, error) {
+	now := time.Now()
+	user := &models.User{
+		ID:              ids.New(),
+		Email:           row.Email,
+		EmailNormalized: row.Email,
+		// No password until the invitation is accepted; no hash ever matches an empty one
+		PasswordHash: "",
+		Status:       models.StatusInvited,
+		Role:         row.Role,
+	}
+	if org != nil {
+		user.OrganizationID = &org.ID
+	}
+	profile := &models.UserProfile{
+		UserID:      user.ID,
+		DisplayName: row.Name,
+		Locale:      "en",
+		TimeZone:    "UTC",
+		UpdatedAt:   now,
+	}
+
+	var invite *models.PasswordReset
+	var event *models.Outbox
+	if sendInvite {
+		token, err := utils.GenerateSecureToken(32)
+		if err != nil {
+			return uuid.Nil, false, err
+		}
+		invite = &models.PasswordReset{
+			UserID:    user.ID,
+			TokenHash: utils.HashToken(token),
+			ExpiresAt: now.Add(s.cfg.InviteExpiry),
+		}
+
+		inviteLink := fmt.Sprintf("%s/accept-invite?token=%s", config.GetConfig().Email.FrontendURL, token)
+		payload := map[string]any{
+			"user_id":     user.ID,
+			"email":       user.Email,
+			"name":        row.Name,
+			"role":        row.Role,
+			"invite_link": inviteLink,
+			"inviteLink":  inviteLink, // alternative key
+			"expires_at":  invite.ExpiresAt.UTC(),
+		}
+		if org != nil {
+			payload["organization_id"] = org.ID
+			payload["organization"] = org.Name
+		}
+		payloadBytes, err := json.Marshal(payload)
+		if err != nil {
+			return uuid.Nil, false, err
+		}
+		event = &models.Outbox{
+			AggregateID: user.ID,
+			Topic:       "user.invited",
+			Type:        "UserInvited",
+			Payload:     payloadBytes,
+			CreatedAt:   now,
+		}
+	}
+
+	created, err := s.importRepo.CreateInvited(ctx, user, profile, invite, event)
+	if err != nil || !created {
+		return uuid.Nil, created, err
+	}
+
+	metadata := map[string]any{
+		"email":       user.Email,
+		"role":        user.Role,
+		"invite_sent": sendInvite,
+	}
+	if org != nil {
+		metadata["organization_id"] = org.ID
+	}
+	_ = s.auditLogRepo.Create(ctx, &models.AuditLog{
+		UserID:    &user.ID,
+		Action:    "user.imported",
+		Metadata:  metadata,
+		CreatedAt: now,
+	})
+
+	return user.ID, true, nil
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/repositories"
+)
+
+// defaultUserMetricsDays is how many days the daily series cover without a days parameter
+const defaultUserMetricsDays = 30
+
+// UserMetricsService computes the headline user metrics of the admin dashboard: signups
+// and active users per day, email verification and MFA adoption rates, and locked
+// accounts.
+type UserMetricsService interface {
+	GetSummary(ctx context.Context, query dto.UserMetricsQuery) (*dto.UserMetricsSummary, error)
+}
+
+type userMetricsService struct {
+	metricsRepo repositories.UserMetricsRepository
+}
+
+func NewUserMetricsService(metricsRepo repositories.UserMetricsRepository) UserMetricsService {
+	return &userMetricsService{metricsRepo: metricsRepo}
+}
+
+func (s *userMetricsService) GetSummary(ctx context.Context, query dto.UserMetricsQuery) (*dto.UserMetricsSummary, error) {
+	days := query.Days
+	if days <= 0 {
+		days = defaultUserMetricsDays
+	}
+	now := time.Now().UTC()
+	end := now.Truncate(24*time.Hour).AddDate(0, 0, 1)
+	start := end.AddDate(0, 0, -days)
+
+	totals, err := s.metricsRepo.Totals(ctx, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count users: %w", err)
+	}
+	signups, err := s.metricsRepo.DailySignups(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count signups: %w", err)
+	}
+	dailyActive, err := s.metricsRepo.DailyActiveUsers(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count daily active users: %w", err)
+	}
+	active, err := s.metricsRepo.ActiveUsers(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count active users: %w", err)
+	}
+
+	resp := &dto.UserMetricsSummary{
+		From:             start.Format(time.DateOnly),
+		To:               end.AddDate(0, 0, -1).Format(time.DateOnly),
+		TotalUsers:       totals.TotalUsers,
+		VerifiedUsers:    totals.VerifiedUsers,
+		VerificationRate: metricRate(totals.VerifiedUsers, totals.TotalUsers),
+		MFAEnabledUsers:  totals.MFAEnabledUsers,
+		MFAAdoptionRate:  metricRate(totals.MFAEnabledUsers, totals.TotalUsers),
+		LockedUsers:      totals.LockedUsers,
+		ActiveUsers:      active,
+		DailySignups:     dailySeries(signups, start, days),
+		DailyActiveUsers: dailySeries(dailyActive, start, days),
+		GeneratedAt:      now,
+	}
+	for _, day := range signups {
+		resp.Signups += day.Count
+	}
+	return resp, nil
+}
+
+// dailySeries lists every day of the range, with zero for days the query returned
+// nothing for.
+func dailySeries(counts []repositories.DailyCount, start time.Time, days int) []dto.DailyMetric {
+	byDay := make(map[string]int64, len(counts))
+	for _, count := range counts {
+		byDay[count.Day.Format(time.DateOnly)] = count.Count
+	}
+	series := make([]dto.DailyMetric, days)
+	for i := range series {
+		date := start.AddDate(0, 0, i).Format(time.DateOnly)
+		series[i] = dto.DailyMetric{Date: date, Count: byDay[date]}
+	}
+	return series
+}
+
+// metricRate returns part/total rounded to four decimals, or 0 without a total.
+func metricRate(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*10000) / 10000
+}
@@ -7,6 +7,7 @@ import (
 
 	"user-services/internal/api/dto"
 	"user-services/internal/api/repositories"
+	"user-services/internal/models"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -22,12 +23,18 @@ type UserProfileService interface {
 }
 
 type userProfileService struct {
-	profileRepo repositories.UserProfileRepository
+	profileRepo  repositories.UserProfileRepository
+	auditLogRepo repositories.AuditLogRepository
+	preferences  PreferencesService
+	avatarRepo   repositories.AvatarRepository
 }
 
-func NewUserProfileService(profileRepo repositories.UserProfileRepository) UserProfileService {
+func NewUserProfileService(profileRepo repositories.UserProfileRepository, auditLogRepo repositories.AuditLogRepository, preferences PreferencesService, avatarRepo repositories.AvatarRepository) UserProfileService {
 	return &userProfileService{
-		profileRepo: profileRepo,
+		profileRepo:  profileRepo,
+		auditLogRepo: auditLogRepo,
+		preferences:  preferences,
+		avatarRepo:   avatarRepo,
 	}
 }
 
@@ -62,22 +69,53 @@ func (s *userProfileService) UpdateProfile(ctx context.Context, userID uuid.UUID
 		return err
 	}
 
-	if req.DisplayName != "" {
-		profile.DisplayName = req.DisplayName
-	}
-	if req.AvatarURL != "" {
-		profile.AvatarURL = req.AvatarURL
-	}
-	if req.Locale != "" {
-		profile.Locale = req.Locale
+	// Locale and time zone are preferences as well; they are changed through the
+	// preferences service so other services hear about it
+	if req.Locale != "" || req.TimeZone != "" {
+		var patch dto.UpdatePreferencesRequest
+		if req.Locale != "" {
+			patch.Locale = &req.Locale
+		}
+		if req.TimeZone != "" {
+			patch.TimeZone = &req.TimeZone
+		}
+		prefs, err := s.preferences.UpdatePreferences(ctx, userID, patch)
+		if err != nil {
+			return err
+		}
+		profile.Locale, profile.TimeZone = prefs.Locale, prefs.TimeZone
 	}
-	if req.TimeZone != "" {
-		profile.TimeZone = req.TimeZone
+
+	// Only the names of changed fields are audited, not their values
+	changed := make([]string, 0, 2)
+	if req.DisplayName != "" && req.DisplayName != profile.DisplayName {
+		profile.DisplayName = req.DisplayName
+		changed = append(changed, "display_name")
 	}
 
 	profile.UpdatedAt = time.Now()
 
-	return s.profileRepo.Update(ctx, profile)
+	if err := s.profileRepo.Update(ctx, profile); err != nil {
+		return err
+	}
+
+	// An avatar URL set by hand replaces an uploaded avatar, whose object is then deleted
+	if req.AvatarURL != "" && req.AvatarURL != profile.AvatarURL {
+		if err := s.avatarRepo.SetAvatarURL(ctx, userID, req.AvatarURL); err != nil {
+			return err
+		}
+		changed = append(changed, "avatar_url")
+	}
+
+	if len(changed) > 0 {
+		_ = s.auditLogRepo.Create(ctx, &models.AuditLog{
+			UserID:    &userID,
+			Action:    "profile.updated",
+			Metadata:  map[string]any{"fields": changed},
+			CreatedAt: time.Now(),
+		})
+	}
+	return nil
 }
 
 func (s *userProfileService) DeleteProfile(ctx context.Context, userID uuid.UUID) error {
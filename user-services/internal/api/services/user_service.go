@@ -3,13 +3,21 @@ package services
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"math"
 	"time"
 
 	"user-services/internal/api/dto"
 	"user-services/internal/api/repositories"
+	"user-services/internal/audit"
+	customerrors "user-services/internal/errors"
 	"user-services/internal/models"
+
+	"github.com/ductan2/microservice-app/shared/softdelete"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type UserService interface {
@@ -23,16 +31,34 @@ type UserService interface {
 
 var ErrUserDeleted = errors.New("user is deleted")
 
+// ErrUserErased is returned when restoring an account whose personal data has already
+// been anonymized.
+var ErrUserErased = errors.New("user has been erased")
+
+// ErrUserMerged is returned when restoring an account that was merged into another one.
+var ErrUserMerged = errors.New("user has been merged into another account")
+
 type userService struct {
-	userRepo repositories.UserRepository
+	userRepo       repositories.UserRepository
+	lockout        LockoutService
+	auditLogRepo   repositories.AuditLogRepository
+	erasureRepo    repositories.ErasureRepository
+	securityEvents SecurityEventService
 }
 
-func NewUserService(userRepo repositories.UserRepository) UserService {
+func NewUserService(userRepo repositories.UserRepository, lockout LockoutService, auditLogRepo repositories.AuditLogRepository, erasureRepo repositories.ErasureRepository, securityEvents SecurityEventService) UserService {
 	return &userService{
-		userRepo: userRepo,
+		userRepo:       userRepo,
+		lockout:        lockout,
+		auditLogRepo:   auditLogRepo,
+		erasureRepo:    erasureRepo,
+		securityEvents: securityEvents,
 	}
 }
 
+// ListUsers returns a page of users, read from the cursor when one is given and by
+// page number otherwise. Every page carries a cursor for the next one while more
+// users match.
 func (s *userService) ListUsers(ctx context.Context, req dto.ListUsersRequest) (*dto.PaginatedResponse, error) {
 	// Set defaults
 	page := req.Page
@@ -47,11 +73,34 @@ func (s *userService) ListUsers(ctx context.Context, req dto.ListUsersRequest) (
 		pageSize = 100
 	}
 
-	users, total, err := s.userRepo.ListUsers(ctx, page, pageSize, req.Status, req.Search)
+	filter, err := userListFilter(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.Cursor != "" {
+		after, err := decodeUserCursor(req.Cursor, filter.Sort, filter.Desc)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+		page = 0
+	} else {
+		filter.Offset = (page - 1) * pageSize
+	}
+	// One extra user tells whether there is a next page
+	filter.Limit = pageSize + 1
+
+	users, total, err := s.userRepo.ListUsers(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 
+	var nextCursor string
+	if len(users) > pageSize {
+		users = users[:pageSize]
+		nextCursor = encodeUserCursor(users[pageSize-1], filter.Sort, filter.Desc)
+	}
+
 	// Convert to PublicUser DTOs
 	publicUsers := make([]dto.PublicUser, len(users))
 	for i, user := range users {
@@ -66,13 +115,130 @@ func (s *userService) ListUsers(ctx context.Context, req dto.ListUsersRequest) (
 		PageSize:   pageSize,
 		Total:      int(total),
 		TotalPages: totalPages,
+		NextCursor: nextCursor,
 	}, nil
 }
 
+func userListFilter(req dto.ListUsersRequest) (repositories.UserListFilter, error) {
+	if !req.CreatedFrom.IsZero() && !req.CreatedTo.IsZero() && !req.CreatedFrom.Before(req.CreatedTo) {
+		return repositories.UserListFilter{},
+			customerrors.NewValidationError("created_from must be before created_to").WithReason("INVALID_TIME_RANGE")
+	}
+	if !req.LastLoginFrom.IsZero() && !req.LastLoginTo.IsZero() && !req.LastLoginFrom.Before(req.LastLoginTo) {
+		return repositories.UserListFilter{},
+			customerrors.NewValidationError("last_login_from must be before last_login_to").WithReason("INVALID_TIME_RANGE")
+	}
+
+	deleted, err := softdelete.ParseScope(req.Deleted)
+	if err != nil {
+		return repositories.UserListFilter{}, customerrors.NewValidationError(err.Error())
+	}
+	// status=deleted predates ?deleted= and keeps listing the deleted accounts
+	if req.Status == models.StatusDeleted && req.Deleted == "" {
+		deleted = softdelete.All
+	}
+
+	filter := repositories.UserListFilter{
+		Status:        req.Status,
+		Role:          req.Role,
+		Search:        req.Search,
+		EmailVerified: req.EmailVerified,
+		MFAEnabled:    req.MFAEnabled,
+		Locked:        req.Locked,
+		CreatedFrom:   req.CreatedFrom,
+		CreatedTo:     req.CreatedTo,
+		LastLoginFrom: req.LastLoginFrom,
+		LastLoginTo:   req.LastLoginTo,
+		Deleted:       deleted,
+		Sort:          req.Sort,
+	}
+	if filter.Sort == "" {
+		filter.Sort = repositories.UserSortCreatedAt
+	}
+	switch req.Order {
+	case "asc":
+		filter.Desc = false
+	case "desc":
+		filter.Desc = true
+	default:
+		filter.Desc = isTimeSort(filter.Sort)
+	}
+	return filter, nil
+}
+
+func isTimeSort(sort string) bool {
+	return sort == repositories.UserSortCreatedAt || sort == repositories.UserSortLastLoginAt
+}
+
+// userCursor is the decoded form of a user listing cursor. The sort it was issued for
+// is kept so a cursor cannot be replayed against a different order.
+type userCursor struct {
+	Sort  string    `json:"s"`
+	Desc  bool      `json:"d"`
+	Value *string   `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+func encodeUserCursor(user models.User, sort string, desc bool) string {
+	cursor := userCursor{Sort: sort, Desc: desc, ID: user.ID}
+	var value string
+	switch sort {
+	case repositories.UserSortCreatedAt:
+		value = user.CreatedAt.UTC().Format(time.RFC3339Nano)
+	case repositories.UserSortLastLoginAt:
+		if user.LastLoginAt.Valid {
+			value = user.LastLoginAt.Time.UTC().Format(time.RFC3339Nano)
+		}
+	case repositories.UserSortEmail:
+		value = user.Email
+	case repositories.UserSortRole:
+		value = user.Role
+	case repositories.UserSortStatus:
+		value = user.Status
+	}
+	if value != "" || sort != repositories.UserSortLastLoginAt {
+		cursor.Value = &value
+	}
+
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeUserCursor(encoded, sort string, desc bool) (*repositories.UserCursor, error) {
+	invalid := customerrors.NewValidationError("cursor is invalid or was issued for a different sort order").WithReason("INVALID_CURSOR")
+
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, invalid
+	}
+	var cursor userCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.Sort != sort || cursor.Desc != desc || cursor.ID == uuid.Nil {
+		return nil, invalid
+	}
+
+	after := &repositories.UserCursor{ID: cursor.ID}
+	switch {
+	case cursor.Value == nil:
+		if sort != repositories.UserSortLastLoginAt {
+			return nil, invalid
+		}
+	case isTimeSort(sort):
+		at, err := time.Parse(time.RFC3339Nano, *cursor.Value)
+		if err != nil {
+			return nil, invalid
+		}
+		after.Value = at
+	default:
+		after.Value = *cursor.Value
+	}
+	return after, nil
+}
+
 func toPublicUser(user models.User) dto.PublicUser {
 	publicUser := dto.PublicUser{
 		ID:            user.ID,
 		Email:         user.Email,
+		Username:      getStringValue(user.Username),
 		EmailVerified: user.EmailVerified,
 		Status:        user.Status,
 		Role:          user.Role,
@@ -113,19 +279,28 @@ func (s *userService) UpdateUserRole(ctx context.Context, userID string, role st
 		return dto.PublicUser{}, err
 	}
 
+	oldRole := user.Role
 	user.Role = role
 	if err := s.userRepo.UpdateUser(ctx, &user); err != nil {
 		return dto.PublicUser{}, err
 	}
 
+	if oldRole != role {
+		s.audit(ctx, user, "user.role_changed", map[string]any{"old_role": oldRole, "new_role": role})
+		s.securityEvents.Emit(ctx, SecurityEvent{
+			Type:     SecurityEventRoleChanged,
+			Severity: SecuritySeverityHigh,
+			Outcome:  "success",
+			UserID:   &user.ID,
+			Email:    user.Email,
+			Details:  map[string]any{"old_role": oldRole, "new_role": role},
+		})
+	}
+
 	return toPublicUser(user), nil
 }
 
 func (s *userService) LockAccount(ctx context.Context, userID string, reason string) (dto.PublicUser, error) {
-	if reason != "" {
-		// TODO: integrate reason with audit logging
-	}
-
 	user, err := s.userRepo.GetUserByID(ctx, userID)
 	if err != nil {
 		return dto.PublicUser{}, err
@@ -142,16 +317,13 @@ func (s *userService) LockAccount(ctx context.Context, userID string, reason str
 		if err := s.userRepo.UpdateUser(ctx, &user); err != nil {
 			return dto.PublicUser{}, err
 		}
+		s.audit(ctx, user, "user.locked", map[string]any{"reason": reason})
 	}
 
 	return toPublicUser(user), nil
 }
 
 func (s *userService) UnlockAccount(ctx context.Context, userID string, reason string) (dto.PublicUser, error) {
-	if reason != "" {
-		// TODO: integrate reason with audit logging
-	}
-
 	user, err := s.userRepo.GetUserByID(ctx, userID)
 	if err != nil {
 		return dto.PublicUser{}, err
@@ -161,6 +333,7 @@ func (s *userService) UnlockAccount(ctx context.Context, userID string, reason s
 		return dto.PublicUser{}, ErrUserDeleted
 	}
 
+	previousStatus := user.Status
 	if user.Status != models.StatusActive {
 		user.Status = models.StatusActive
 		user.LockoutUntil = sql.NullTime{}
@@ -170,14 +343,17 @@ func (s *userService) UnlockAccount(ctx context.Context, userID string, reason s
 		}
 	}
 
+	// Also lift a temporary lockout from failed logins
+	if err := s.lockout.Unlock(ctx, user.Email); err != nil {
+		return dto.PublicUser{}, err
+	}
+
+	s.audit(ctx, user, "user.unlocked", map[string]any{"reason": reason, "previous_status": previousStatus})
+
 	return toPublicUser(user), nil
 }
 
 func (s *userService) SoftDeleteAccount(ctx context.Context, userID string, reason string) (dto.PublicUser, error) {
-	if reason != "" {
-		// TODO: integrate reason with audit logging
-	}
-
 	user, err := s.userRepo.GetUserByID(ctx, userID)
 	if err != nil {
 		return dto.PublicUser{}, err
@@ -188,23 +364,18 @@ func (s *userService) SoftDeleteAccount(ctx context.Context, userID string, reas
 	}
 
 	user.Status = models.StatusDeleted
-	user.DeletedAt = sql.NullTime{
-		Time:  time.Now().UTC(),
-		Valid: true,
-	}
+	user.Mark(softdelete.Now(audit.Actor(ctx)))
 
 	if err := s.userRepo.UpdateUser(ctx, &user); err != nil {
 		return dto.PublicUser{}, err
 	}
 
+	s.audit(ctx, user, "user.deleted", map[string]any{"reason": reason})
+
 	return toPublicUser(user), nil
 }
 
 func (s *userService) RestoreAccount(ctx context.Context, userID string, reason string) (dto.PublicUser, error) {
-	if reason != "" {
-		// TODO: integrate reason with audit logging
-	}
-
 	user, err := s.userRepo.GetUserByID(ctx, userID)
 	if err != nil {
 		return dto.PublicUser{}, err
@@ -213,14 +384,53 @@ func (s *userService) RestoreAccount(ctx context.Context, userID string, reason
 	if !user.DeletedAt.Valid && user.Status != models.StatusDeleted {
 		return toPublicUser(user), nil
 	}
+	if user.MergedIntoID != nil {
+		return dto.PublicUser{}, ErrUserMerged
+	}
+
+	// A scheduled erasure is cancelled by restoring the account within the retention
+	// window; once the data has been anonymized there is nothing left to restore.
+	metadata := map[string]any{"reason": reason}
+	latest, err := s.erasureRepo.GetLatestByUserID(ctx, user.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return dto.PublicUser{}, err
+	}
+	if latest != nil {
+		switch latest.Status {
+		case models.ErasureStatusScheduled:
+			cancelled, err := s.erasureRepo.CancelScheduled(ctx, user.ID)
+			if err != nil {
+				return dto.PublicUser{}, err
+			}
+			if !cancelled {
+				return dto.PublicUser{}, ErrUserErased
+			}
+			metadata["erasure_cancelled"] = latest.ID
+		case models.ErasureStatusAnonymized, models.ErasureStatusCompleted, models.ErasureStatusIncomplete:
+			return dto.PublicUser{}, ErrUserErased
+		}
+	}
 
 	user.Status = models.StatusActive
-	user.DeletedAt = sql.NullTime{}
+	user.Clear()
 	user.LockoutUntil = sql.NullTime{}
 
 	if err := s.userRepo.UpdateUser(ctx, &user); err != nil {
 		return dto.PublicUser{}, err
 	}
 
+	s.audit(ctx, user, "user.restored", metadata)
+
 	return toPublicUser(user), nil
 }
+
+// audit records an administrative action on user. The acting admin is taken from the
+// request context.
+func (s *userService) audit(ctx context.Context, user models.User, action string, metadata map[string]any) {
+	_ = s.auditLogRepo.Create(ctx, &models.AuditLog{
+		UserID:    &user.ID,
+		Action:    action,
+		Metadata:  metadata,
+		CreatedAt: time.Now(),
+	})
+}
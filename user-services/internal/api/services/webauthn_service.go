@@ -0,0 +1,545 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	stderrors "errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/api/repositories"
+	"user-services/internal/cache"
+	"user-services/internal/config"
+	"user-services/internal/errors"
+	"user-services/internal/models"
+	"user-services/internal/utils"
+
+	"github.com/ductan2/microservice-app/shared/ids"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const webAuthnChallengeSize = 32
+
+// WebAuthnService registers passkeys and verifies passkey assertions. A passkey is an
+// MFA method of type "webauthn": it can stand in for the TOTP code at password login,
+// or sign in on its own when the authenticator verified the user (PIN or biometrics).
+//
+// Ceremonies are verified with github.com/go-webauthn/webauthn. Registrations are
+// requested with attestation "none": the service trusts the authenticator on first use.
+type WebAuthnService interface {
+	BeginRegistration(ctx context.Context, userID uuid.UUID, label string) (*dto.WebAuthnRegistrationBeginResponse, error)
+	FinishRegistration(ctx context.Context, userID uuid.UUID, req dto.WebAuthnRegistrationFinishRequest) (*dto.WebAuthnCredentialResponse, error)
+	BeginLogin(ctx context.Context, email string) (*dto.WebAuthnLoginBeginResponse, error)
+	VerifyAssertion(ctx context.Context, assertion dto.WebAuthnAssertion, userID *uuid.UUID, requireUserVerification bool) (*models.MFAMethod, error)
+	ListCredentials(ctx context.Context, userID uuid.UUID) ([]dto.WebAuthnCredentialResponse, error)
+	RenameCredential(ctx context.Context, userID, methodID uuid.UUID, label string) (*dto.WebAuthnCredentialResponse, error)
+	RevokeCredential(ctx context.Context, userID, methodID uuid.UUID, password string) error
+}
+
+type webAuthnService struct {
+	mfaRepo       repositories.MFARepository
+	userRepo      repositories.UserRepository
+	auditLogRepo  repositories.AuditLogRepository
+	webAuthnCache *cache.WebAuthnCache
+}
+
+func NewWebAuthnService(
+	mfaRepo repositories.MFARepository,
+	userRepo repositories.UserRepository,
+	auditLogRepo repositories.AuditLogRepository,
+	webAuthnCache *cache.WebAuthnCache,
+) WebAuthnService {
+	return &webAuthnService{
+		mfaRepo:       mfaRepo,
+		userRepo:      userRepo,
+		auditLogRepo:  auditLogRepo,
+		webAuthnCache: webAuthnCache,
+	}
+}
+
+// credentialParameters are the credential algorithms offered to authenticators, in order
+// of preference: EdDSA, ES256 and RS256.
+var credentialParameters = webauthn.CredentialParametersRecommendedL3()
+
+func relyingParty(cfg *config.Config) (*webauthn.WebAuthn, error) {
+	return webauthn.New(&webauthn.Config{
+		RPID:                  cfg.WebAuthn.RPID,
+		RPDisplayName:         cfg.WebAuthn.RPName,
+		RPOrigins:             cfg.WebAuthn.Origins,
+		AttestationPreference: protocol.PreferNoAttestation,
+	})
+}
+
+// webAuthnUser is the account a ceremony is verified for, with the passkeys it may use.
+type webAuthnUser struct {
+	id          uuid.UUID
+	credentials []webauthn.Credential
+}
+
+func (u webAuthnUser) WebAuthnID() []byte                         { return u.id[:] }
+func (u webAuthnUser) WebAuthnName() string                       { return "" }
+func (u webAuthnUser) WebAuthnDisplayName() string                { return "" }
+func (u webAuthnUser) WebAuthnCredentials() []webauthn.Credential { return u.credentials }
+
+// BeginRegistration issues a creation challenge for a new passkey. Passkeys the user
+// already has are excluded so the same authenticator is not registered twice.
+func (s *webAuthnService) BeginRegistration(ctx context.Context, userID uuid.UUID, label string) (*dto.WebAuthnRegistrationBeginResponse, error) {
+	cfg := config.GetConfig()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.ErrUserNotFound
+	}
+
+	existing, err := s.mfaRepo.GetWebAuthnByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	challengeID, challenge, err := s.newChallenge(ctx, cache.WebAuthnChallenge{
+		Purpose: cache.WebAuthnPurposeRegister,
+		UserID:  &userID,
+		Label:   strings.TrimSpace(label),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	params := make([]dto.WebAuthnCredentialParameter, 0, len(credentialParameters))
+	for _, p := range credentialParameters {
+		params = append(params, dto.WebAuthnCredentialParameter{Type: string(p.Type), Alg: int64(p.Algorithm)})
+	}
+
+	displayName := user.Profile.DisplayName
+	if displayName == "" {
+		displayName = user.Email
+	}
+
+	return &dto.WebAuthnRegistrationBeginResponse{
+		ChallengeID: challengeID,
+		PublicKey: dto.WebAuthnCreationOptions{
+			Challenge: encodeWebAuthnID(challenge),
+			RP:        dto.WebAuthnRelyingParty{ID: cfg.WebAuthn.RPID, Name: cfg.WebAuthn.RPName},
+			User: dto.WebAuthnUserEntity{
+				ID:          encodeWebAuthnID(userID[:]),
+				Name:        user.Email,
+				DisplayName: displayName,
+			},
+			PubKeyCredParams:   params,
+			Timeout:            cfg.WebAuthn.ChallengeTTL.Milliseconds(),
+			ExcludeCredentials: credentialDescriptors(existing),
+			AuthenticatorSelection: dto.WebAuthnAuthenticatorSelection{
+				ResidentKey:      "preferred",
+				UserVerification: "preferred",
+			},
+			Attestation: "none",
+		},
+	}, nil
+}
+
+// FinishRegistration verifies the new credential against the registration challenge and
+// stores it.
+func (s *webAuthnService) FinishRegistration(ctx context.Context, userID uuid.UUID, req dto.WebAuthnRegistrationFinishRequest) (*dto.WebAuthnCredentialResponse, error) {
+	cfg := config.GetConfig()
+
+	challenge, err := s.consumeChallenge(ctx, req.ChallengeID, cache.WebAuthnPurposeRegister)
+	if err != nil {
+		return nil, err
+	}
+	if challenge.UserID == nil || *challenge.UserID != userID {
+		return nil, errors.ErrWebAuthnChallengeExpired
+	}
+
+	rp, err := relyingParty(cfg)
+	if err != nil {
+		return nil, err
+	}
+	credential, err := verifyRegistration(rp, challenge.Challenge, userID, req.Credential)
+	if err != nil {
+		return nil, errors.ErrWebAuthnVerificationFailed
+	}
+
+	credentialID := encodeWebAuthnID(credential.ID)
+	if _, err := s.mfaRepo.GetByCredentialID(ctx, credentialID); err == nil {
+		return nil, errors.ErrWebAuthnCredentialExists
+	} else if !stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	aaguid, _ := uuid.FromBytes(credential.Authenticator.AAGUID)
+	label := challenge.Label
+	if label == "" {
+		label = "Passkey"
+	}
+
+	method := &models.MFAMethod{
+		ID:           ids.New(),
+		UserID:       userID,
+		Type:         models.MFATypeWebAuthn,
+		Label:        label,
+		WebAuthnPub:  encodeWebAuthnID(credential.PublicKey),
+		CredentialID: &credentialID,
+		SignCount:    int64(credential.Authenticator.SignCount),
+		Transports:   strings.Join(req.Credential.Response.Transports, ","),
+		AAGUID:       aaguid.String(),
+		AddedAt:      time.Now(),
+	}
+	if err := s.mfaRepo.Create(ctx, method); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, userID, "mfa.webauthn.registered", map[string]any{
+		"method_id":     method.ID,
+		"label":         method.Label,
+		"aaguid":        method.AAGUID,
+		"user_verified": credential.Flags.UserVerified,
+		"backed_up":     credential.Flags.BackupState,
+	})
+
+	resp := toWebAuthnCredentialResponse(*method)
+	return &resp, nil
+}
+
+// BeginLogin issues an assertion challenge. With the email of an account that has
+// passkeys, only its passkeys are allowed; otherwise the browser may offer any
+// discoverable passkey for this site.
+func (s *webAuthnService) BeginLogin(ctx context.Context, email string) (*dto.WebAuthnLoginBeginResponse, error) {
+	cfg := config.GetConfig()
+
+	pending := cache.WebAuthnChallenge{Purpose: cache.WebAuthnPurposeLogin}
+	var allowed []models.MFAMethod
+	if email != "" {
+		if user, err := s.userRepo.GetUserByEmail(ctx, email); err == nil {
+			methods, err := s.mfaRepo.GetWebAuthnByUserID(ctx, user.ID)
+			if err != nil {
+				return nil, err
+			}
+			if len(methods) > 0 {
+				pending.UserID = &user.ID
+				allowed = methods
+			}
+		}
+	}
+
+	challengeID, challenge, err := s.newChallenge(ctx, pending)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.WebAuthnLoginBeginResponse{
+		ChallengeID: challengeID,
+		PublicKey: dto.WebAuthnRequestOptions{
+			Challenge:        encodeWebAuthnID(challenge),
+			RPID:             cfg.WebAuthn.RPID,
+			Timeout:          cfg.WebAuthn.ChallengeTTL.Milliseconds(),
+			AllowCredentials: credentialDescriptors(allowed),
+			UserVerification: "preferred",
+		},
+	}, nil
+}
+
+// VerifyAssertion checks a login assertion and returns the passkey that signed it. When
+// userID is set the passkey must belong to that user. Passwordless logins pass
+// requireUserVerification, since the passkey is then the only factor.
+func (s *webAuthnService) VerifyAssertion(ctx context.Context, assertion dto.WebAuthnAssertion, userID *uuid.UUID, requireUserVerification bool) (*models.MFAMethod, error) {
+	cfg := config.GetConfig()
+
+	challenge, err := s.consumeChallenge(ctx, assertion.ChallengeID, cache.WebAuthnPurposeLogin)
+	if err != nil {
+		return nil, err
+	}
+
+	rawID, err := decodeWebAuthnID(assertion.Credential.ID)
+	if err != nil {
+		return nil, errors.ErrWebAuthnVerificationFailed
+	}
+	method, err := s.mfaRepo.GetByCredentialID(ctx, encodeWebAuthnID(rawID))
+	if err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrWebAuthnVerificationFailed
+		}
+		return nil, err
+	}
+
+	if userID != nil && method.UserID != *userID {
+		return nil, errors.ErrWebAuthnVerificationFailed
+	}
+	if challenge.UserID != nil && method.UserID != *challenge.UserID {
+		return nil, errors.ErrWebAuthnVerificationFailed
+	}
+
+	rp, err := relyingParty(cfg)
+	if err != nil {
+		return nil, err
+	}
+	credential, err := verifyAssertion(rp, challenge.Challenge, method, assertion.Credential, requireUserVerification)
+	if err != nil {
+		return nil, errors.ErrWebAuthnVerificationFailed
+	}
+	if credential.Authenticator.CloneWarning {
+		slog.WarnContext(ctx, "passkey reported a stale signature counter, possible clone", "mfa_method_id", method.ID, "user_id", method.UserID)
+		s.audit(ctx, method.UserID, "mfa.webauthn.clone_suspected", map[string]any{
+			"method_id":  method.ID,
+			"sign_count": method.SignCount,
+		})
+		return nil, errors.ErrWebAuthnVerificationFailed
+	}
+
+	signCount := credential.Authenticator.SignCount
+
+	if err := s.mfaRepo.UpdateSignCount(ctx, method.ID, int64(signCount)); err != nil {
+		return nil, err
+	}
+	method.SignCount = int64(signCount)
+	return method, nil
+}
+
+// verifyRegistration checks the response to a credential creation ceremony for userID
+// and returns the credential to store.
+func verifyRegistration(rp *webauthn.WebAuthn, challenge []byte, userID uuid.UUID, cred dto.WebAuthnRegistrationCredential) (*webauthn.Credential, error) {
+	rawID, err := decodeWebAuthnID(cred.ID)
+	if err != nil {
+		return nil, err
+	}
+	clientDataJSON, err := decodeWebAuthnID(cred.Response.ClientDataJSON)
+	if err != nil {
+		return nil, err
+	}
+	attestationObject, err := decodeWebAuthnID(cred.Response.AttestationObject)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.CredentialCreationResponse{
+		PublicKeyCredential: protocol.PublicKeyCredential{
+			Credential: protocol.Credential{ID: encodeWebAuthnID(rawID), Type: cred.Type},
+			RawID:      rawID,
+		},
+		AttestationResponse: protocol.AuthenticatorAttestationResponse{
+			AuthenticatorResponse: protocol.AuthenticatorResponse{ClientDataJSON: clientDataJSON},
+			AttestationObject:     attestationObject,
+			Transports:            cred.Response.Transports,
+		},
+	}.Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	session := webauthn.SessionData{
+		Challenge:        encodeWebAuthnID(challenge),
+		RelyingPartyID:   rp.Config.RPID,
+		UserID:           userID[:],
+		UserVerification: protocol.VerificationPreferred,
+		CredParams:       credentialParameters,
+	}
+	return rp.CreateCredential(webAuthnUser{id: userID}, session, parsed)
+}
+
+// verifyAssertion checks the response to an authentication ceremony against the stored
+// passkey method. The returned credential carries the new signature counter, or a clone
+// warning when the counter did not increase; authenticators that always report zero are
+// accepted.
+func verifyAssertion(rp *webauthn.WebAuthn, challenge []byte, method *models.MFAMethod, cred dto.WebAuthnAssertionCredential, requireUserVerification bool) (*webauthn.Credential, error) {
+	var decoded [6][]byte
+	for i, field := range []string{
+		cred.ID,
+		cred.Response.ClientDataJSON,
+		cred.Response.AuthenticatorData,
+		cred.Response.Signature,
+		cred.Response.UserHandle,
+		method.WebAuthnPub,
+	} {
+		var err error
+		if decoded[i], err = decodeWebAuthnID(field); err != nil {
+			return nil, err
+		}
+	}
+
+	parsed, err := protocol.CredentialAssertionResponse{
+		PublicKeyCredential: protocol.PublicKeyCredential{
+			Credential: protocol.Credential{ID: encodeWebAuthnID(decoded[0]), Type: cred.Type},
+			RawID:      decoded[0],
+		},
+		AssertionResponse: protocol.AuthenticatorAssertionResponse{
+			AuthenticatorResponse: protocol.AuthenticatorResponse{ClientDataJSON: decoded[1]},
+			AuthenticatorData:     decoded[2],
+			Signature:             decoded[3],
+			UserHandle:            decoded[4],
+		},
+	}.Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	userVerification := protocol.VerificationPreferred
+	if requireUserVerification {
+		userVerification = protocol.VerificationRequired
+	}
+	session := webauthn.SessionData{
+		Challenge:        encodeWebAuthnID(challenge),
+		RelyingPartyID:   rp.Config.RPID,
+		UserID:           method.UserID[:],
+		UserVerification: userVerification,
+	}
+
+	// The backup flags of a passkey are not stored, so they are taken from the assertion.
+	flags := parsed.Response.AuthenticatorData.Flags
+	user := webAuthnUser{id: method.UserID, credentials: []webauthn.Credential{{
+		ID:            decoded[0],
+		PublicKey:     decoded[5],
+		Flags:         webauthn.NewCredentialFlags(flags),
+		Authenticator: webauthn.Authenticator{SignCount: uint32(method.SignCount)},
+	}}}
+	return rp.ValidateLogin(user, session, parsed)
+}
+
+// encodeWebAuthnID encodes credential IDs, user handles and challenges the way browsers
+// do in JSON: unpadded base64url.
+func encodeWebAuthnID(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeWebAuthnID reverses encodeWebAuthnID. Padded input is accepted since some
+// clients send it.
+func decodeWebAuthnID(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// ListCredentials returns the user's passkeys, oldest first.
+func (s *webAuthnService) ListCredentials(ctx context.Context, userID uuid.UUID) ([]dto.WebAuthnCredentialResponse, error) {
+	methods, err := s.mfaRepo.GetWebAuthnByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]dto.WebAuthnCredentialResponse, 0, len(methods))
+	for _, m := range methods {
+		resp = append(resp, toWebAuthnCredentialResponse(m))
+	}
+	return resp, nil
+}
+
+// RenameCredential changes the nickname of one of the user's passkeys.
+func (s *webAuthnService) RenameCredential(ctx context.Context, userID, methodID uuid.UUID, label string) (*dto.WebAuthnCredentialResponse, error) {
+	method, err := s.ownedCredential(ctx, userID, methodID)
+	if err != nil {
+		return nil, err
+	}
+
+	method.Label = strings.TrimSpace(label)
+	if err := s.mfaRepo.UpdateLabel(ctx, method.ID, method.Label); err != nil {
+		return nil, err
+	}
+
+	resp := toWebAuthnCredentialResponse(*method)
+	return &resp, nil
+}
+
+// RevokeCredential deletes one of the user's passkeys after re-checking the password,
+// like disabling any other MFA method.
+func (s *webAuthnService) RevokeCredential(ctx context.Context, userID, methodID uuid.UUID, password string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return errors.ErrUserNotFound
+	}
+	if err := utils.CheckPassword(user.PasswordHash, password); err != nil {
+		return errors.ErrInvalidCredentials
+	}
+
+	method, err := s.ownedCredential(ctx, userID, methodID)
+	if err != nil {
+		return err
+	}
+	if err := s.mfaRepo.Delete(ctx, method.ID); err != nil {
+		return err
+	}
+
+	s.audit(ctx, userID, "mfa.webauthn.revoked", map[string]any{
+		"method_id": method.ID,
+		"label":     method.Label,
+	})
+	return nil
+}
+
+func (s *webAuthnService) ownedCredential(ctx context.Context, userID, methodID uuid.UUID) (*models.MFAMethod, error) {
+	method, err := s.mfaRepo.GetByID(ctx, methodID)
+	if err != nil || method.UserID != userID || method.Type != models.MFATypeWebAuthn {
+		return nil, errors.ErrMFAMethodNotFound
+	}
+	return method, nil
+}
+
+func (s *webAuthnService) newChallenge(ctx context.Context, pending cache.WebAuthnChallenge) (uuid.UUID, []byte, error) {
+	challenge := make([]byte, webAuthnChallengeSize)
+	if _, err := rand.Read(challenge); err != nil {
+		return uuid.Nil, nil, err
+	}
+	pending.Challenge = challenge
+
+	challengeID := uuid.New()
+	if err := s.webAuthnCache.StoreChallenge(ctx, challengeID, pending, config.GetConfig().WebAuthn.ChallengeTTL); err != nil {
+		return uuid.Nil, nil, err
+	}
+	return challengeID, challenge, nil
+}
+
+func (s *webAuthnService) consumeChallenge(ctx context.Context, challengeID uuid.UUID, purpose string) (*cache.WebAuthnChallenge, error) {
+	challenge, err := s.webAuthnCache.ConsumeChallenge(ctx, challengeID)
+	if err != nil {
+		return nil, err
+	}
+	if challenge == nil || challenge.Purpose != purpose {
+		return nil, errors.ErrWebAuthnChallengeExpired
+	}
+	return challenge, nil
+}
+
+func (s *webAuthnService) audit(ctx context.Context, userID uuid.UUID, action string, metadata map[string]any) {
+	_ = s.auditLogRepo.Create(ctx, &models.AuditLog{
+		UserID:    &userID,
+		Action:    action,
+		Metadata:  metadata,
+		CreatedAt: time.Now(),
+	})
+}
+
+func credentialDescriptors(methods []models.MFAMethod) []dto.WebAuthnCredentialDescriptor {
+	descriptors := make([]dto.WebAuthnCredentialDescriptor, 0, len(methods))
+	for _, m := range methods {
+		if m.CredentialID == nil {
+			continue
+		}
+		descriptors = append(descriptors, dto.WebAuthnCredentialDescriptor{
+			Type:       "public-key",
+			ID:         *m.CredentialID,
+			Transports: splitTransports(m.Transports),
+		})
+	}
+	return descriptors
+}
+
+func toWebAuthnCredentialResponse(m models.MFAMethod) dto.WebAuthnCredentialResponse {
+	resp := dto.WebAuthnCredentialResponse{
+		ID:         m.ID,
+		Label:      m.Label,
+		Transports: splitTransports(m.Transports),
+		AddedAt:    m.AddedAt.Format(time.RFC3339),
+	}
+	if m.LastUsedAt.Valid {
+		resp.LastUsedAt = m.LastUsedAt.Time.Format(time.RFC3339)
+	}
+	return resp
+}
+
+func splitTransports(transports string) []string {
+	if transports == "" {
+		return nil
+	}
+	return strings.Split(transports, ",")
+}
@@ -0,0 +1,327 @@
+package services
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"user-services/internal/api/dto"
+	"user-services/internal/models"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+)
+
+const (
+	testRPID         = "learn.example.com"
+	testOrigin       = "https://learn.example.com"
+	flagUserPresent  = byte(protocol.FlagUserPresent)
+	flagUserVerified = byte(protocol.FlagUserVerified)
+	flagBackup       = byte(protocol.FlagBackupEligible | protocol.FlagBackupState)
+)
+
+var testAAGUID = bytes.Repeat([]byte{0xaa}, 16)
+
+func testRelyingParty(t *testing.T) *webauthn.WebAuthn {
+	t.Helper()
+	rp, err := webauthn.New(&webauthn.Config{
+		RPID:          testRPID,
+		RPDisplayName: "Learn",
+		RPOrigins:     []string{testOrigin, "https://app.learn.example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rp
+}
+
+// testAuthenticator is a software authenticator holding one credential.
+type testAuthenticator struct {
+	credentialID []byte
+	coseKey      []byte
+	sign         func(message []byte) []byte
+}
+
+func newTestAuthenticator(t *testing.T, alg int64) *testAuthenticator {
+	t.Helper()
+	a := &testAuthenticator{credentialID: []byte("test-credential-id")}
+
+	var key map[int]any
+	switch alg {
+	case -7: // ES256
+		priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key = map[int]any{1: 2, 3: alg, -1: 1, -2: priv.X.FillBytes(make([]byte, 32)), -3: priv.Y.FillBytes(make([]byte, 32))}
+		a.sign = func(message []byte) []byte {
+			digest := sha256.Sum256(message)
+			signature, _ := ecdsa.SignASN1(rand.Reader, priv, digest[:])
+			return signature
+		}
+	case -8: // EdDSA
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key = map[int]any{1: 1, 3: alg, -1: 6, -2: []byte(pub)}
+		a.sign = func(message []byte) []byte { return ed25519.Sign(priv, message) }
+	case -257: // RS256
+		priv, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key = map[int]any{1: 3, 3: alg, -1: priv.N.Bytes(), -2: big.NewInt(int64(priv.E)).Bytes()}
+		a.sign = func(message []byte) []byte {
+			digest := sha256.Sum256(message)
+			signature, _ := rsa.SignPKCS1v15(rand.Reader, priv, crypto.SHA256, digest[:])
+			return signature
+		}
+	default:
+		t.Fatalf("unsupported algorithm %d", alg)
+	}
+
+	var err error
+	if a.coseKey, err = webauthncbor.Marshal(key); err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+// authData builds authenticator data for rpID; with attested set it carries the
+// credential, as in a registration.
+func (a *testAuthenticator) authData(rpID string, flags byte, signCount uint32, attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	data := append([]byte(nil), rpIDHash[:]...)
+	if attested {
+		flags |= byte(protocol.FlagAttestedCredentialData)
+	}
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, signCount)
+	if attested {
+		data = append(data, testAAGUID...)
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+		data = append(data, a.credentialID...)
+		data = append(data, a.coseKey...)
+	}
+	return data
+}
+
+func (a *testAuthenticator) registration(t *testing.T, clientData, authData []byte) dto.WebAuthnRegistrationCredential {
+	t.Helper()
+	attestationObject, err := webauthncbor.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": authData,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dto.WebAuthnRegistrationCredential{
+		ID:   encodeWebAuthnID(a.credentialID),
+		Type: "public-key",
+		Response: dto.WebAuthnAttestationResponse{
+			ClientDataJSON:    encodeWebAuthnID(clientData),
+			AttestationObject: encodeWebAuthnID(attestationObject),
+		},
+	}
+}
+
+func (a *testAuthenticator) assertion(clientData, authData, signature, userHandle []byte) dto.WebAuthnAssertionCredential {
+	return dto.WebAuthnAssertionCredential{
+		ID:   encodeWebAuthnID(a.credentialID),
+		Type: "public-key",
+		Response: dto.WebAuthnAssertionResponse{
+			ClientDataJSON:    encodeWebAuthnID(clientData),
+			AuthenticatorData: encodeWebAuthnID(authData),
+			Signature:         encodeWebAuthnID(signature),
+			UserHandle:        encodeWebAuthnID(userHandle),
+		},
+	}
+}
+
+func clientData(typ string, challenge []byte, origin string) []byte {
+	data, _ := json.Marshal(map[string]any{
+		"type":        typ,
+		"challenge":   encodeWebAuthnID(challenge),
+		"origin":      origin,
+		"crossOrigin": false,
+	})
+	return data
+}
+
+func TestVerifyRegistration(t *testing.T) {
+	rp := testRelyingParty(t)
+	challenge := []byte("registration-challenge-0123456789")
+	userID := uuid.New()
+
+	for _, tc := range []struct {
+		name string
+		alg  int64
+	}{{"ES256", -7}, {"EdDSA", -8}, {"RS256", -257}} {
+		t.Run(tc.name, func(t *testing.T) {
+			a := newTestAuthenticator(t, tc.alg)
+			cred := a.registration(t, clientData("webauthn.create", challenge, "https://app.learn.example.com"),
+				a.authData(testRPID, flagUserPresent|flagUserVerified|flagBackup, 0, true))
+
+			credential, err := verifyRegistration(rp, challenge, userID, cred)
+			if err != nil {
+				t.Fatalf("verifyRegistration: %v", err)
+			}
+			if !bytes.Equal(credential.ID, a.credentialID) {
+				t.Errorf("ID = %q, want %q", credential.ID, a.credentialID)
+			}
+			if !bytes.Equal(credential.PublicKey, a.coseKey) {
+				t.Error("PublicKey is not the COSE key of the authenticator")
+			}
+			if !bytes.Equal(credential.Authenticator.AAGUID, testAAGUID) {
+				t.Errorf("AAGUID = %x, want %x", credential.Authenticator.AAGUID, testAAGUID)
+			}
+			if !credential.Flags.UserVerified || !credential.Flags.BackupState {
+				t.Errorf("Flags = %+v, want user verified and backed up", credential.Flags)
+			}
+		})
+	}
+}
+
+func TestVerifyRegistrationRejects(t *testing.T) {
+	rp := testRelyingParty(t)
+	challenge := []byte("registration-challenge-0123456789")
+	a := newTestAuthenticator(t, -7)
+	valid := a.authData(testRPID, flagUserPresent|flagUserVerified, 0, true)
+
+	cases := []struct {
+		name       string
+		clientData []byte
+		authData   []byte
+	}{
+		{"WrongRPID", clientData("webauthn.create", challenge, testOrigin), a.authData("evil.example.com", flagUserPresent, 0, true)},
+		{"WrongOrigin", clientData("webauthn.create", challenge, "https://evil.example.com"), valid},
+		{"OriginWithPort", clientData("webauthn.create", challenge, testOrigin+":8443"), valid},
+		{"AssertionClientData", clientData("webauthn.get", challenge, testOrigin), valid},
+		{"MalformedClientData", []byte(`{"type":`), valid},
+		{"ChallengeMismatch", clientData("webauthn.create", []byte("another-challenge"), testOrigin), valid},
+		{"UserNotPresent", clientData("webauthn.create", challenge, testOrigin), a.authData(testRPID, flagUserVerified, 0, true)},
+		{"NoAttestedCredential", clientData("webauthn.create", challenge, testOrigin), a.authData(testRPID, flagUserPresent, 0, false)},
+		{"TruncatedAuthData", clientData("webauthn.create", challenge, testOrigin), valid[:len(valid)-4]},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := verifyRegistration(rp, challenge, uuid.New(), a.registration(t, tc.clientData, tc.authData)); err == nil {
+				t.Fatal("verifyRegistration accepted the credential")
+			}
+		})
+	}
+}
+
+func TestVerifyAssertion(t *testing.T) {
+	rp := testRelyingParty(t)
+	challenge := []byte("assertion-challenge-0123456789")
+	a := newTestAuthenticator(t, -7)
+	other := newTestAuthenticator(t, -8)
+	owner := uuid.New()
+
+	cases := []struct {
+		name            string
+		rpID            string
+		flags           byte
+		signCount       uint32
+		storedSignCount int64
+		clientData      []byte
+		signer          *testAuthenticator
+		userHandle      uuid.UUID
+		tamper          bool
+		requireUV       bool
+		wantErr         bool
+		wantClone       bool
+	}{
+		{name: "CounterIncreases", signCount: 2, storedSignCount: 1},
+		{name: "ZeroCountersAccepted"},
+		{name: "BackedUpPasskey", flags: flagUserPresent | flagUserVerified | flagBackup, signCount: 2, storedSignCount: 1},
+		{name: "UserHandleMatches", userHandle: owner, signCount: 2, storedSignCount: 1},
+		{name: "WrongRPID", rpID: "evil.example.com", signCount: 2, storedSignCount: 1, wantErr: true},
+		{name: "WrongOrigin", clientData: clientData("webauthn.get", challenge, "http://learn.example.com"), wantErr: true},
+		{name: "RegistrationClientData", clientData: clientData("webauthn.create", challenge, testOrigin), wantErr: true},
+		{name: "ChallengeMismatch", clientData: clientData("webauthn.get", []byte("stale-challenge"), testOrigin), wantErr: true},
+		{name: "UserNotPresent", flags: flagUserVerified, wantErr: true},
+		{name: "UserNotVerified", flags: flagUserPresent, requireUV: true, wantErr: true},
+		{name: "UserHandleOfAnotherUser", userHandle: uuid.New(), wantErr: true},
+		{name: "TamperedSignature", tamper: true, wantErr: true},
+		{name: "SignedByAnotherKey", signer: other, wantErr: true},
+		{name: "SignCountRegression", signCount: 4, storedSignCount: 5, wantClone: true},
+		{name: "SignCountRepeated", signCount: 5, storedSignCount: 5, wantClone: true},
+		{name: "SignCountResetToZero", signCount: 0, storedSignCount: 5, wantClone: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rpID := tc.rpID
+			if rpID == "" {
+				rpID = testRPID
+			}
+			flags := tc.flags
+			if flags == 0 {
+				flags = flagUserPresent | flagUserVerified
+			}
+			cd := tc.clientData
+			if cd == nil {
+				cd = clientData("webauthn.get", challenge, testOrigin)
+			}
+			signer := tc.signer
+			if signer == nil {
+				signer = a
+			}
+
+			var userHandle []byte
+			if tc.userHandle != uuid.Nil {
+				userHandle = tc.userHandle[:]
+			}
+			method := &models.MFAMethod{UserID: owner, WebAuthnPub: encodeWebAuthnID(a.coseKey), SignCount: tc.storedSignCount}
+
+			authData := a.authData(rpID, flags, tc.signCount, false)
+			cdHash := sha256.Sum256(cd)
+			signature := signer.sign(append(append([]byte(nil), authData...), cdHash[:]...))
+			if tc.tamper {
+				authData[len(authData)-1] ^= 0x01
+			}
+
+			credential, err := verifyAssertion(rp, challenge, method, a.assertion(cd, authData, signature, userHandle), tc.requireUV)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("verifyAssertion accepted the assertion")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("verifyAssertion: %v", err)
+			}
+			if credential.Authenticator.CloneWarning != tc.wantClone {
+				t.Fatalf("CloneWarning = %v, want %v", credential.Authenticator.CloneWarning, tc.wantClone)
+			}
+			if !tc.wantClone && credential.Authenticator.SignCount != tc.signCount {
+				t.Fatalf("SignCount = %d, want %d", credential.Authenticator.SignCount, tc.signCount)
+			}
+		})
+	}
+}
+
+func TestDecodeWebAuthnID(t *testing.T) {
+	id := []byte{0xfb, 0xff, 0x01}
+	for _, s := range []string{encodeWebAuthnID(id), encodeWebAuthnID(id) + "="} {
+		got, err := decodeWebAuthnID(s)
+		if err != nil || !bytes.Equal(got, id) {
+			t.Fatalf("decodeWebAuthnID(%q) = %x, %v, want %x", s, got, err, id)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Package audit carries who made a request, and from where, through the request context
+// so audit log entries can be attributed without threading it through every service.
+package audit
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Request describes the origin of the request an audit entry is recorded for.
+type Request struct {
+	// ActorID is the authenticated user performing the action; nil for anonymous
+	// requests such as login or password reset
+	ActorID   *uuid.UUID
+	IPAddr    string
+	UserAgent string
+	// Impersonating means ActorID is an administrator acting as another user through an
+	// impersonation session
+	Impersonating bool
+}
+
+type contextKey struct{}
+
+// WithRequest stores the request origin on ctx.
+func WithRequest(ctx context.Context, req Request) context.Context {
+	return context.WithValue(ctx, contextKey{}, req)
+}
+
+// WithActor records the authenticated user on ctx, keeping any IP and user agent
+// already stored.
+func WithActor(ctx context.Context, actorID uuid.UUID) context.Context {
+	req, _ := FromContext(ctx)
+	req.ActorID = &actorID
+	return WithRequest(ctx, req)
+}
+
+// WithImpersonator records the administrator acting as the authenticated user of an
+// impersonation session on ctx; entries are attributed to them rather than the user.
+func WithImpersonator(ctx context.Context, impersonatorID uuid.UUID) context.Context {
+	req, _ := FromContext(ctx)
+	req.ActorID = &impersonatorID
+	req.Impersonating = true
+	return WithRequest(ctx, req)
+}
+
+// FromContext returns the request origin stored on ctx, if any.
+func FromContext(ctx context.Context) (Request, bool) {
+	if ctx == nil {
+		return Request{}, false
+	}
+	req, ok := ctx.Value(contextKey{}).(Request)
+	return req, ok
+}
+
+// Actor returns the ID of the user performing the request on ctx, "" for anonymous
+// requests.
+func Actor(ctx context.Context) string {
+	if req, ok := FromContext(ctx); ok && req.ActorID != nil {
+		return req.ActorID.String()
+	}
+	return ""
+}
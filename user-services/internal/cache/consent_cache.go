@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// PolicyVersionsKey is the hash of the policy documents users must have accepted, one
+// JSON-encoded RequiredPolicy per document. The BFF reads it to decide whether a session
+// may be used, so the format must stay in sync with bff-services/internal/consent.
+const PolicyVersionsKey = "policy_versions"
+
+// RequiredPolicy is the current version of a policy document. Users must have accepted
+// the document at or after RequiredSince, when the latest version requiring consent was
+// published.
+type RequiredPolicy struct {
+	Version       string    `json:"version"`
+	URL           string    `json:"url"`
+	RequiredSince time.Time `json:"required_since"`
+}
+
+// SetRequiredPolicies replaces the policy documents users must have accepted.
+func (sc *SessionCache) SetRequiredPolicies(ctx context.Context, policies map[string]RequiredPolicy) error {
+	fields := make(map[string]any, len(policies))
+	for document, policy := range policies {
+		value, err := json.Marshal(policy)
+		if err != nil {
+			return fmt.Errorf("failed to marshal policy %s: %w", document, err)
+		}
+		fields[document] = value
+	}
+
+	_, err := sc.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, PolicyVersionsKey)
+		if len(fields) > 0 {
+			pipe.HSet(ctx, PolicyVersionsKey, fields)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store policy versions in Redis: %w", err)
+	}
+	return nil
+}
+
+// SetSessionConsents updates the acceptances recorded in the given sessions, keeping
+// their TTL. Sessions no longer in Redis are skipped.
+func (sc *SessionCache) SetSessionConsents(ctx context.Context, sessionIDs []uuid.UUID, consents map[string]time.Time) error {
+	for _, sessionID := range sessionIDs {
+		key := fmt.Sprintf("session:%s", sessionID.String())
+		val, err := sc.client.Get(ctx, key).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get session from Redis: %w", err)
+		}
+
+		var data SessionData
+		if err := json.Unmarshal([]byte(val), &data); err != nil {
+			return fmt.Errorf("failed to unmarshal session data: %w", err)
+		}
+		if data.ImpersonatorID != nil {
+			continue
+		}
+		data.Consents = consents
+
+		jsonData, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("failed to marshal session data: %w", err)
+		}
+		if err := sc.client.SetArgs(ctx, key, jsonData, redis.SetArgs{KeepTTL: true, Mode: "XX"}).Err(); err != nil && err != redis.Nil {
+			return fmt.Errorf("failed to store session in Redis: %w", err)
+		}
+	}
+	return nil
+}
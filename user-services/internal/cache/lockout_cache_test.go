@@ -0,0 +1,218 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return mr, client
+}
+
+var testLockoutPolicy = LockoutPolicy{
+	MaxAttempts:  3,
+	Window:       10 * time.Minute,
+	BaseDuration: time.Minute,
+	MaxDuration:  4 * time.Minute,
+	StrikeMemory: time.Hour,
+}
+
+// failUntilLocked records failures until the scope locks, checking it takes exactly
+// the policy's MaxAttempts, and returns how long the lockout lasts.
+func failUntilLocked(t *testing.T, lc *LockoutCache, id string) time.Duration {
+	t.Helper()
+	ctx := context.Background()
+
+	for i := 1; i < testLockoutPolicy.MaxAttempts; i++ {
+		until, err := lc.RecordFailure(ctx, LockoutScopeAccount, id, testLockoutPolicy)
+		if err != nil {
+			t.Fatalf("RecordFailure: %v", err)
+		}
+		if !until.IsZero() {
+			t.Fatalf("locked after %d failures, want %d", i, testLockoutPolicy.MaxAttempts)
+		}
+	}
+
+	until, err := lc.RecordFailure(ctx, LockoutScopeAccount, id, testLockoutPolicy)
+	if err != nil {
+		t.Fatalf("RecordFailure: %v", err)
+	}
+	if until.IsZero() {
+		t.Fatalf("not locked after %d failures", testLockoutPolicy.MaxAttempts)
+	}
+
+	stored, err := lc.LockedUntil(ctx, LockoutScopeAccount, id)
+	if err != nil || !stored.Equal(until) {
+		t.Fatalf("LockedUntil = %v, %v, want %v", stored, err, until)
+	}
+	return time.Until(until).Round(time.Minute)
+}
+
+// TestLockoutEscalates checks that each lockout within the strike memory doubles the
+// previous one, capped at the maximum, and that the lockout lifts when it runs out.
+func TestLockoutEscalates(t *testing.T) {
+	mr, client := newTestRedis(t)
+	lc := NewLockoutCache(client)
+	ctx := context.Background()
+
+	for _, want := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 4 * time.Minute} {
+		if got := failUntilLocked(t, lc, "ada@example.com"); got != want {
+			t.Fatalf("lockout lasts %v, want %v", got, want)
+		}
+
+		mr.FastForward(want)
+		until, err := lc.LockedUntil(ctx, LockoutScopeAccount, "ada@example.com")
+		if err != nil || !until.IsZero() {
+			t.Fatalf("after %v: LockedUntil = %v, %v, want no lockout", want, until, err)
+		}
+	}
+}
+
+func TestLockoutStrikesAreForgotten(t *testing.T) {
+	mr, client := newTestRedis(t)
+	lc := NewLockoutCache(client)
+
+	failUntilLocked(t, lc, "ada@example.com")
+	mr.FastForward(testLockoutPolicy.StrikeMemory + time.Second)
+
+	if got := failUntilLocked(t, lc, "ada@example.com"); got != testLockoutPolicy.BaseDuration {
+		t.Fatalf("lockout after the strike memory lasts %v, want %v", got, testLockoutPolicy.BaseDuration)
+	}
+}
+
+func TestLockoutFailuresExpireWithWindow(t *testing.T) {
+	mr, client := newTestRedis(t)
+	lc := NewLockoutCache(client)
+	ctx := context.Background()
+
+	for i := 1; i < testLockoutPolicy.MaxAttempts; i++ {
+		if _, err := lc.RecordFailure(ctx, LockoutScopeAccount, "ada@example.com", testLockoutPolicy); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mr.FastForward(testLockoutPolicy.Window + time.Second)
+
+	until, err := lc.RecordFailure(ctx, LockoutScopeAccount, "ada@example.com", testLockoutPolicy)
+	if err != nil || !until.IsZero() {
+		t.Fatalf("failure after the window: until = %v, %v, want no lockout", until, err)
+	}
+}
+
+// TestLockoutScopesAreSeparate checks that failures of one account or IP do not count
+// against another.
+func TestLockoutScopesAreSeparate(t *testing.T) {
+	_, client := newTestRedis(t)
+	lc := NewLockoutCache(client)
+	ctx := context.Background()
+
+	failUntilLocked(t, lc, "ada@example.com")
+
+	for _, scope := range []struct{ scope, id string }{
+		{LockoutScopeAccount, "grace@example.com"},
+		{LockoutScopeIP, "ada@example.com"},
+	} {
+		until, err := lc.LockedUntil(ctx, scope.scope, scope.id)
+		if err != nil || !until.IsZero() {
+			t.Fatalf("%s %s: LockedUntil = %v, %v, want no lockout", scope.scope, scope.id, until, err)
+		}
+	}
+}
+
+// TestResetFailures checks that a successful login clears the failure count and the
+// strikes, but leaves an active lockout in place.
+func TestResetFailures(t *testing.T) {
+	mr, client := newTestRedis(t)
+	lc := NewLockoutCache(client)
+	ctx := context.Background()
+
+	failUntilLocked(t, lc, "ada@example.com")
+	if _, err := lc.RecordFailure(ctx, LockoutScopeAccount, "ada@example.com", testLockoutPolicy); err != nil {
+		t.Fatal(err)
+	}
+	if err := lc.ResetFailures(ctx, LockoutScopeAccount, "ada@example.com"); err != nil {
+		t.Fatalf("ResetFailures: %v", err)
+	}
+
+	until, err := lc.LockedUntil(ctx, LockoutScopeAccount, "ada@example.com")
+	if err != nil || until.IsZero() {
+		t.Fatalf("LockedUntil after reset = %v, %v, want the active lockout", until, err)
+	}
+
+	mr.FastForward(testLockoutPolicy.BaseDuration)
+	if got := failUntilLocked(t, lc, "ada@example.com"); got != testLockoutPolicy.BaseDuration {
+		t.Fatalf("lockout after reset lasts %v, want %v", got, testLockoutPolicy.BaseDuration)
+	}
+}
+
+func TestUnlock(t *testing.T) {
+	_, client := newTestRedis(t)
+	lc := NewLockoutCache(client)
+	ctx := context.Background()
+
+	failUntilLocked(t, lc, "ada@example.com")
+	failUntilLocked(t, lc, "ada@example.com")
+	if err := lc.Unlock(ctx, LockoutScopeAccount, "ada@example.com"); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+
+	until, err := lc.LockedUntil(ctx, LockoutScopeAccount, "ada@example.com")
+	if err != nil || !until.IsZero() {
+		t.Fatalf("LockedUntil after unlock = %v, %v, want no lockout", until, err)
+	}
+	if got := failUntilLocked(t, lc, "ada@example.com"); got != testLockoutPolicy.BaseDuration {
+		t.Fatalf("lockout after unlock lasts %v, want %v", got, testLockoutPolicy.BaseDuration)
+	}
+}
+
+func TestUnlockToken(t *testing.T) {
+	mr, client := newTestRedis(t)
+	lc := NewLockoutCache(client)
+	ctx := context.Background()
+
+	if err := lc.StoreUnlockToken(ctx, "hash-1", "ada@example.com", time.Hour); err != nil {
+		t.Fatalf("StoreUnlockToken: %v", err)
+	}
+	for i, want := range []string{"ada@example.com", ""} {
+		email, err := lc.ConsumeUnlockToken(ctx, "hash-1")
+		if err != nil || email != want {
+			t.Fatalf("ConsumeUnlockToken #%d = %q, %v, want %q", i+1, email, err, want)
+		}
+	}
+
+	if err := lc.StoreUnlockToken(ctx, "hash-2", "ada@example.com", time.Hour); err != nil {
+		t.Fatalf("StoreUnlockToken: %v", err)
+	}
+	mr.FastForward(time.Hour + time.Second)
+	if email, err := lc.ConsumeUnlockToken(ctx, "hash-2"); err != nil || email != "" {
+		t.Fatalf("expired token: ConsumeUnlockToken = %q, %v, want none", email, err)
+	}
+}
+
+func TestLockoutDuration(t *testing.T) {
+	cases := []struct {
+		policy  LockoutPolicy
+		strikes int64
+		want    time.Duration
+	}{
+		{policy: testLockoutPolicy, strikes: 1, want: time.Minute},
+		{policy: testLockoutPolicy, strikes: 2, want: 2 * time.Minute},
+		{policy: testLockoutPolicy, strikes: 3, want: 4 * time.Minute},
+		{policy: testLockoutPolicy, strikes: 50, want: 4 * time.Minute},
+		{policy: LockoutPolicy{BaseDuration: 30 * time.Minute, MaxDuration: 45 * time.Minute}, strikes: 2, want: 45 * time.Minute},
+		{policy: LockoutPolicy{BaseDuration: 30 * time.Minute, MaxDuration: 10 * time.Minute}, strikes: 1, want: 10 * time.Minute},
+	}
+
+	for _, tc := range cases {
+		if got := lockoutDuration(tc.policy, tc.strikes); got != tc.want {
+			t.Errorf("lockoutDuration(%v, %d) = %v, want %v", tc.policy, tc.strikes, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// OTPCache stores hashed SMS/voice codes per MFA method and throttles how often codes
+// are sent to a phone number.
+type OTPCache struct {
+	client *redis.Client
+}
+
+// NewOTPCache creates a new OTP cache instance
+func NewOTPCache(client *redis.Client) *OTPCache {
+	return &OTPCache{
+		client: client,
+	}
+}
+
+func otpCodeKey(methodID uuid.UUID) string {
+	return fmt.Sprintf("otp_code:%s", methodID.String())
+}
+
+func otpAttemptsKey(methodID uuid.UUID) string {
+	return fmt.Sprintf("otp_attempts:%s", methodID.String())
+}
+
+func otpCooldownKey(phone string) string {
+	return fmt.Sprintf("otp_send_cooldown:%s", phone)
+}
+
+func otpSendCountKey(phone string) string {
+	return fmt.Sprintf("otp_send_count:%s", phone)
+}
+
+// StoreCode saves the hash of a newly sent code for ttl, replacing any earlier code for
+// the method and resetting its attempt counter.
+func (oc *OTPCache) StoreCode(ctx context.Context, methodID uuid.UUID, codeHash string, ttl time.Duration) error {
+	pipe := oc.client.TxPipeline()
+	pipe.Set(ctx, otpCodeKey(methodID), codeHash, ttl)
+	pipe.Del(ctx, otpAttemptsKey(methodID))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to store otp code in Redis: %w", err)
+	}
+	return nil
+}
+
+// VerifyCode compares codeHash with the stored code. Every call counts as an attempt;
+// once maxAttempts is exceeded the code is discarded. A matching code is consumed.
+func (oc *OTPCache) VerifyCode(ctx context.Context, methodID uuid.UUID, codeHash string, maxAttempts int, ttl time.Duration) (bool, error) {
+	codeKey := otpCodeKey(methodID)
+	attemptsKey := otpAttemptsKey(methodID)
+
+	pipe := oc.client.TxPipeline()
+	incr := pipe.Incr(ctx, attemptsKey)
+	pipe.ExpireNX(ctx, attemptsKey, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, fmt.Errorf("failed to count otp attempt: %w", err)
+	}
+	if incr.Val() > int64(maxAttempts) {
+		if err := oc.client.Del(ctx, codeKey).Err(); err != nil {
+			return false, fmt.Errorf("failed to discard otp code: %w", err)
+		}
+		return false, nil
+	}
+
+	stored, err := oc.client.Get(ctx, codeKey).Result()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get otp code from Redis: %w", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(stored), []byte(codeHash)) != 1 {
+		return false, nil
+	}
+
+	if err := oc.client.Del(ctx, codeKey, attemptsKey).Err(); err != nil {
+		return false, fmt.Errorf("failed to consume otp code: %w", err)
+	}
+	return true, nil
+}
+
+// ReserveSend claims a send to phone. It returns how long the caller must wait when the
+// number is in its resend cooldown or has used up its hourly allowance, zero otherwise.
+func (oc *OTPCache) ReserveSend(ctx context.Context, phone string, cooldown time.Duration, maxPerHour int) (time.Duration, error) {
+	cooldownKey := otpCooldownKey(phone)
+	ok, err := oc.client.SetNX(ctx, cooldownKey, 1, cooldown).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to check otp cooldown: %w", err)
+	}
+	if !ok {
+		return oc.remaining(ctx, cooldownKey, cooldown)
+	}
+
+	countKey := otpSendCountKey(phone)
+	pipe := oc.client.TxPipeline()
+	incr := pipe.Incr(ctx, countKey)
+	pipe.ExpireNX(ctx, countKey, time.Hour)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to count otp send: %w", err)
+	}
+	if incr.Val() > int64(maxPerHour) {
+		return oc.remaining(ctx, countKey, time.Hour)
+	}
+	return 0, nil
+}
+
+func (oc *OTPCache) remaining(ctx context.Context, key string, fallback time.Duration) (time.Duration, error) {
+	ttl, err := oc.client.TTL(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get otp throttle TTL: %w", err)
+	}
+	if ttl <= 0 {
+		return fallback, nil
+	}
+	return ttl, nil
+}
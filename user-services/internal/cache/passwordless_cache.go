@@ -0,0 +1,148 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Passwordless login methods
+const (
+	PasswordlessMethodLink = "link"
+	PasswordlessMethodCode = "code"
+)
+
+// PasswordlessChallenge is a pending passwordless login. SecretHash is the hash of the
+// nonce of the emailed link or of the emailed code; DeviceHash, when set, is the hash of
+// the device token handed to the client that requested the login.
+type PasswordlessChallenge struct {
+	Method     string    `json:"method"`
+	SecretHash string    `json:"secret_hash"`
+	DeviceHash string    `json:"device_hash,omitempty"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// PasswordlessCache stores the pending passwordless login of each user, at most one, and
+// throttles how often sign-in emails are sent to an address.
+type PasswordlessCache struct {
+	client *redis.Client
+}
+
+// NewPasswordlessCache creates a new passwordless login cache instance
+func NewPasswordlessCache(client *redis.Client) *PasswordlessCache {
+	return &PasswordlessCache{
+		client: client,
+	}
+}
+
+func passwordlessChallengeKey(userID uuid.UUID) string {
+	return fmt.Sprintf("passwordless_challenge:%s", userID.String())
+}
+
+func passwordlessAttemptsKey(userID uuid.UUID) string {
+	return fmt.Sprintf("passwordless_attempts:%s", userID.String())
+}
+
+func passwordlessCooldownKey(email string) string {
+	return fmt.Sprintf("passwordless_cooldown:%s", email)
+}
+
+// StoreChallenge saves a newly sent login for ttl, replacing any earlier one of the user
+// and resetting its attempt counter.
+func (pc *PasswordlessCache) StoreChallenge(ctx context.Context, userID uuid.UUID, challenge PasswordlessChallenge, ttl time.Duration) error {
+	data, err := json.Marshal(challenge)
+	if err != nil {
+		return fmt.Errorf("failed to marshal passwordless challenge: %w", err)
+	}
+
+	pipe := pc.client.TxPipeline()
+	pipe.Set(ctx, passwordlessChallengeKey(userID), data, ttl)
+	pipe.Del(ctx, passwordlessAttemptsKey(userID))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to store passwordless challenge in Redis: %w", err)
+	}
+	return nil
+}
+
+// GetChallenge returns the pending login of the user without consuming it. Every call
+// counts as an attempt; once maxAttempts is exceeded the login is discarded. An unknown,
+// expired or discarded login yields nil.
+func (pc *PasswordlessCache) GetChallenge(ctx context.Context, userID uuid.UUID, maxAttempts int, ttl time.Duration) (*PasswordlessChallenge, error) {
+	challengeKey := passwordlessChallengeKey(userID)
+	attemptsKey := passwordlessAttemptsKey(userID)
+
+	pipe := pc.client.TxPipeline()
+	incr := pipe.Incr(ctx, attemptsKey)
+	pipe.ExpireNX(ctx, attemptsKey, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to count passwordless attempt: %w", err)
+	}
+	if incr.Val() > int64(maxAttempts) {
+		if err := pc.client.Del(ctx, challengeKey).Err(); err != nil {
+			return nil, fmt.Errorf("failed to discard passwordless challenge: %w", err)
+		}
+		return nil, nil
+	}
+
+	data, err := pc.client.Get(ctx, challengeKey).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get passwordless challenge from Redis: %w", err)
+	}
+
+	var challenge PasswordlessChallenge
+	if err := json.Unmarshal(data, &challenge); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal passwordless challenge: %w", err)
+	}
+	return &challenge, nil
+}
+
+// ConsumeChallenge deletes the pending login of the user, so every link or code signs in
+// once, and reports whether it was still the one with secretHash.
+func (pc *PasswordlessCache) ConsumeChallenge(ctx context.Context, userID uuid.UUID, secretHash string) (bool, error) {
+	data, err := pc.client.GetDel(ctx, passwordlessChallengeKey(userID)).Bytes()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to consume passwordless challenge: %w", err)
+	}
+	_ = pc.client.Del(ctx, passwordlessAttemptsKey(userID)).Err()
+
+	var challenge PasswordlessChallenge
+	if err := json.Unmarshal(data, &challenge); err != nil {
+		return false, fmt.Errorf("failed to unmarshal passwordless challenge: %w", err)
+	}
+	return challenge.SecretHash == secretHash, nil
+}
+
+// ReserveSend claims a sign-in email to email. It returns how long the caller must wait
+// when the address is in its resend cooldown, zero otherwise.
+func (pc *PasswordlessCache) ReserveSend(ctx context.Context, email string, cooldown time.Duration) (time.Duration, error) {
+	if cooldown <= 0 {
+		return 0, nil
+	}
+	key := passwordlessCooldownKey(email)
+	ok, err := pc.client.SetNX(ctx, key, 1, cooldown).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to check passwordless cooldown: %w", err)
+	}
+	if ok {
+		return 0, nil
+	}
+
+	ttl, err := pc.client.TTL(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get passwordless cooldown TTL: %w", err)
+	}
+	if ttl <= 0 {
+		return cooldown, nil
+	}
+	return ttl, nil
+}
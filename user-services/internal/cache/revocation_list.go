@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The revocation list holds sessions whose tokens must no longer be accepted, even if
+// the access token itself has not expired yet. Entries only need to outlive the
+// longest-lived access token, so they are stored with that TTL. The BFF reads the same
+// keys, so the format must stay in sync with bff-services/internal/cache.
+func revokedSessionKey(sessionID uuid.UUID) string {
+	return fmt.Sprintf("revoked_session:%s", sessionID.String())
+}
+
+// RevokeSession adds a session (the family of tokens issued for one login) to the
+// revocation list for ttl.
+func (sc *SessionCache) RevokeSession(ctx context.Context, sessionID uuid.UUID, ttl time.Duration) error {
+	err := sc.client.Set(ctx, revokedSessionKey(sessionID), time.Now().UTC().Format(time.RFC3339), ttl).Err()
+	if err != nil {
+		return fmt.Errorf("failed to add session to revocation list: %w", err)
+	}
+	return nil
+}
+
+// IsSessionRevoked reports whether a session is on the revocation list.
+func (sc *SessionCache) IsSessionRevoked(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	exists, err := sc.client.Exists(ctx, revokedSessionKey(sessionID)).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check revocation list: %w", err)
+	}
+	return exists > 0, nil
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"time"
 
+	"user-services/internal/metrics"
+
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 )
@@ -14,9 +16,20 @@ import (
 type SessionData struct {
 	UserID    uuid.UUID `json:"user_id"`
 	Email     string    `json:"email"`
+	Role      string    `json:"role,omitempty"`
 	UserAgent string    `json:"user_agent"`
 	IPAddr    string    `json:"ip_addr"`
 	CreatedAt time.Time `json:"created_at"`
+	// LastSeenAt is maintained by the BFF when sliding expiry is enabled.
+	LastSeenAt time.Time `json:"last_seen_at,omitzero"`
+	// ImpersonatorID is the administrator acting as the user in an impersonation
+	// session, which ends at ExpiresAt however active it is.
+	ImpersonatorID *uuid.UUID `json:"impersonator_id,omitempty"`
+	ExpiresAt      time.Time  `json:"expires_at,omitzero"`
+	// Consents is when the user last accepted each policy document. The BFF asks for
+	// consent again when a version published since then requires it. It is nil for
+	// impersonation sessions, which are not checked.
+	Consents map[string]time.Time `json:"consents"`
 }
 
 // SessionCache provides Redis operations for session management
@@ -54,11 +67,14 @@ func (sc *SessionCache) GetSession(ctx context.Context, sessionID uuid.UUID) (*S
 
 	val, err := sc.client.Get(ctx, key).Result()
 	if err == redis.Nil {
+		metrics.SessionCacheLookupsTotal.WithLabelValues("get", "miss").Inc()
 		return nil, fmt.Errorf("session not found")
 	}
 	if err != nil {
+		metrics.SessionCacheLookupsTotal.WithLabelValues("get", "error").Inc()
 		return nil, fmt.Errorf("failed to get session from Redis: %w", err)
 	}
+	metrics.SessionCacheLookupsTotal.WithLabelValues("get", "hit").Inc()
 
 	var data SessionData
 	if err := json.Unmarshal([]byte(val), &data); err != nil {
@@ -106,8 +122,14 @@ func (sc *SessionCache) SessionExists(ctx context.Context, sessionID uuid.UUID)
 
 	exists, err := sc.client.Exists(ctx, key).Result()
 	if err != nil {
+		metrics.SessionCacheLookupsTotal.WithLabelValues("exists", "error").Inc()
 		return false, fmt.Errorf("failed to check session existence: %w", err)
 	}
+	if exists > 0 {
+		metrics.SessionCacheLookupsTotal.WithLabelValues("exists", "hit").Inc()
+	} else {
+		metrics.SessionCacheLookupsTotal.WithLabelValues("exists", "miss").Inc()
+	}
 
 	return exists > 0, nil
 }
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// WebAuthn ceremony purposes
+const (
+	WebAuthnPurposeRegister = "register"
+	WebAuthnPurposeLogin    = "login"
+)
+
+// WebAuthnChallenge is the server side of a pending WebAuthn ceremony. UserID is set
+// for registrations and for logins started with an email; discoverable (usernameless)
+// logins leave it empty.
+type WebAuthnChallenge struct {
+	Challenge []byte     `json:"challenge"`
+	Purpose   string     `json:"purpose"`
+	UserID    *uuid.UUID `json:"user_id,omitempty"`
+	Label     string     `json:"label,omitempty"`
+}
+
+// WebAuthnCache stores pending WebAuthn challenges in Redis until the client answers them.
+type WebAuthnCache struct {
+	client *redis.Client
+}
+
+// NewWebAuthnCache creates a new WebAuthn challenge cache instance
+func NewWebAuthnCache(client *redis.Client) *WebAuthnCache {
+	return &WebAuthnCache{
+		client: client,
+	}
+}
+
+func webAuthnChallengeKey(challengeID uuid.UUID) string {
+	return fmt.Sprintf("webauthn_challenge:%s", challengeID.String())
+}
+
+// StoreChallenge saves a pending ceremony under challengeID for ttl.
+func (wc *WebAuthnCache) StoreChallenge(ctx context.Context, challengeID uuid.UUID, challenge WebAuthnChallenge, ttl time.Duration) error {
+	data, err := json.Marshal(challenge)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webauthn challenge: %w", err)
+	}
+
+	if err := wc.client.Set(ctx, webAuthnChallengeKey(challengeID), data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to store webauthn challenge in Redis: %w", err)
+	}
+	return nil
+}
+
+// ConsumeChallenge returns a pending ceremony and deletes it, so every challenge can be
+// answered once. An unknown or expired challenge yields nil.
+func (wc *WebAuthnCache) ConsumeChallenge(ctx context.Context, challengeID uuid.UUID) (*WebAuthnChallenge, error) {
+	data, err := wc.client.GetDel(ctx, webAuthnChallengeKey(challengeID)).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume webauthn challenge: %w", err)
+	}
+
+	var challenge WebAuthnChallenge
+	if err := json.Unmarshal([]byte(data), &challenge); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal webauthn challenge: %w", err)
+	}
+	return &challenge, nil
+}
@@ -2,11 +2,14 @@ package config
 
 import (
 	"fmt"
-	"os"
-	"strconv"
 	"time"
 
-	"github.com/joho/godotenv"
+	"github.com/ductan2/microservice-app/shared/envconfig"
+	"github.com/ductan2/microservice-app/shared/internalauth"
+	"github.com/ductan2/microservice-app/shared/ratelimit"
+	"github.com/ductan2/microservice-app/shared/requestmeta"
+	"github.com/ductan2/microservice-app/shared/retention"
+	"github.com/ductan2/microservice-app/shared/scheduler"
 )
 
 // Config holds all application configuration
@@ -20,169 +23,429 @@ type Config struct {
 	Email       EmailConfig
 	Security    SecurityConfig
 	RateLimit   RateLimitConfig
-	Environment string
+	WebAuthn    WebAuthnConfig
+	OTP         OTPConfig
+	DataExport  DataExportConfig
+	Erasure     ErasureConfig
+	Avatar      AvatarConfig
+	Outbox      OutboxConfig
+	Import      ImportConfig
+	Invitation  InvitationConfig
+	AccessToken AccessTokenConfig
+	PushTokens  PushTokenConfig
+	Merge       AccountMergeConfig
+	Impersonate ImpersonationConfig
+	MagicLogin  PasswordlessConfig
+	Reset       PasswordResetConfig
+	Activity    ActivityRollupConfig
+	Retention   RetentionConfig
+	GeoIP       GeoIPConfig
+	Environment string `env:"ENVIRONMENT" envDefault:"development"`
 }
 
 // ServerConfig contains server-related configuration
 type ServerConfig struct {
-	Port         string
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
-	IdleTimeout  time.Duration
+	Port string `env:"PORT" envDefault:"8001"`
+	// GRPCPort serves the internal gRPC identity API; empty disables it
+	GRPCPort     string        `env:"GRPC_PORT" envDefault:"9001"`
+	ReadTimeout  time.Duration `env:"SERVER_READ_TIMEOUT" envDefault:"15s"`
+	WriteTimeout time.Duration `env:"SERVER_WRITE_TIMEOUT" envDefault:"15s"`
+	IdleTimeout  time.Duration `env:"SERVER_IDLE_TIMEOUT" envDefault:"60s"`
+	// TrustedProxies are the networks whose X-Forwarded-For is trusted, the BFF's; the
+	// client IP of sessions, audit logs and rate limits is read past them
+	TrustedProxies requestmeta.Proxies `env:"TRUSTED_PROXIES" envDefault:"127.0.0.0/8,::1/128,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16,fc00::/7"`
 }
 
 // DatabaseConfig contains database connection configuration
 type DatabaseConfig struct {
-	User     string
-	Password string
-	Host     string
-	Port     string
-	DBName   string
-	SSLMode  string
-	MaxOpenConns int
-	MaxIdleConns int
-	MaxIdleTime  time.Duration
+	User         string        `env:"DB_USER" envDefault:"user"`
+	Password     string        `env:"DB_PASSWORD,secret" envDefault:"password"`
+	Host         string        `env:"DB_HOST" envDefault:"postgres"`
+	Port         string        `env:"DB_PORT" envDefault:"5432"`
+	DBName       string        `env:"DB_NAME" envDefault:"lms_user_services"`
+	SSLMode      string        `env:"POSTGRES_SSLMODE" envDefault:"disable"`
+	MaxOpenConns int           `env:"DB_MAX_OPEN_CONNS" envDefault:"25"`
+	MaxIdleConns int           `env:"DB_MAX_IDLE_CONNS" envDefault:"5"`
+	MaxIdleTime  time.Duration `env:"DB_MAX_IDLE_TIME" envDefault:"5m"`
+	// MigrateOnStart applies pending migrations at startup; when unset, migrations are
+	// left to cmd/migrate and the server only refuses to start on drift
+	MigrateOnStart bool `env:"MIGRATE_ON_START" envDefault:"true"`
+	// ReplicaURLs are read replicas (postgres:// URLs) serving the user listings and data
+	// exports; without any, every query goes to the primary
+	ReplicaURLs []string `env:"DB_REPLICA_URLS,secret"`
+	// ReplicaMaxLag takes a replica further behind the primary out of the rotation
+	ReplicaMaxLag time.Duration `env:"DB_REPLICA_MAX_LAG" envDefault:"30s"`
 }
 
 // RedisConfig contains Redis connection configuration
 type RedisConfig struct {
+	Host string `env:"REDIS_HOST" envDefault:"redis"`
+	Port string `env:"REDIS_PORT" envDefault:"6379"`
+	// Addr is Host:Port
 	Addr         string
-	Password     string
-	DB           int
-	PoolSize     int
-	MinIdleConns int
-	DialTimeout  time.Duration
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
+	Password     string        `env:"REDIS_PASSWORD,secret"`
+	DB           int           `env:"REDIS_DB"`
+	PoolSize     int           `env:"REDIS_POOL_SIZE" envDefault:"10"`
+	MinIdleConns int           `env:"REDIS_MIN_IDLE_CONNS" envDefault:"3"`
+	DialTimeout  time.Duration `env:"REDIS_DIAL_TIMEOUT" envDefault:"5s"`
+	ReadTimeout  time.Duration `env:"REDIS_READ_TIMEOUT" envDefault:"3s"`
+	WriteTimeout time.Duration `env:"REDIS_WRITE_TIMEOUT" envDefault:"3s"`
 }
 
 // RabbitConfig contains RabbitMQ connection configuration
 type RabbitConfig struct {
-	URL              string
-	User             string
-	Password         string
-	Host             string
-	Port             string
-	VHost            string
-	ExchangeName     string
-	ReconnectDelay   time.Duration
-	Heartbeat        time.Duration
+	URL          string `env:"RABBITMQ_URL,secret"`
+	User         string `env:"RABBITMQ_USER" envDefault:"user"`
+	Password     string `env:"RABBITMQ_PASSWORD,secret" envDefault:"password"`
+	Host         string `env:"RABBITMQ_HOST" envDefault:"rabbitmq"`
+	Port         string `env:"RABBITMQ_PORT" envDefault:"5672"`
+	VHost        string `env:"RABBITMQ_VHOST" envDefault:"/"`
+	ExchangeName string `env:"RABBITMQ_EXCHANGE" envDefault:"notifications"`
+	// SecurityQueue is declared and bound to the security event routing key at startup
+	// so events wait for the SIEM shipper; empty leaves binding to the consumer
+	SecurityQueue  string        `env:"RABBITMQ_SECURITY_EVENTS_QUEUE" envDefault:"security.events"`
+	ReconnectDelay time.Duration `env:"RABBITMQ_RECONNECT_DELAY" envDefault:"5s"`
+	Heartbeat      time.Duration `env:"RABBITMQ_HEARTBEAT" envDefault:"10s"`
 }
 
 // JWTConfig contains JWT configuration
 type JWTConfig struct {
-	Secret           string
-	ExpiresIn        time.Duration
-	RefreshExpiresIn time.Duration
-	Issuer           string
+	Secret           string        `env:"JWT_SECRET,secret" envDefault:"change-me-dev-secret"`
+	ExpiresIn        time.Duration `env:"JWT_EXPIRES_IN" envDefault:"15m"`
+	RefreshExpiresIn time.Duration `env:"JWT_REFRESH_EXPIRES_IN" envDefault:"1440h"`
+	Issuer           string        `env:"JWT_ISSUER" envDefault:"user-services"`
 }
 
 // SessionConfig contains session configuration
 type SessionConfig struct {
-	Expiry        time.Duration
-	CleanupPeriod time.Duration
-	MaxSessions   int
+	Expiry        time.Duration `env:"SESSION_EXPIRY" envDefault:"720h"`
+	CleanupPeriod time.Duration `env:"SESSION_CLEANUP_PERIOD" envDefault:"1h"`
+	MaxSessions   int           `env:"SESSION_MAX_SESSIONS" envDefault:"10"`
 }
 
 // EmailConfig contains email configuration
 type EmailConfig struct {
-	FrontendURL       string
-	VerificationExpiry time.Duration
-	PasswordResetExpiry time.Duration
+	FrontendURL         string        `env:"FRONTEND_URL" envDefault:"http://localhost:3001"`
+	VerificationExpiry  time.Duration `env:"EMAIL_VERIFICATION_EXPIRY" envDefault:"24h"`
+	PasswordResetExpiry time.Duration `env:"EMAIL_PASSWORD_RESET_EXPIRY" envDefault:"15m"`
+	AccountUnlockExpiry time.Duration `env:"EMAIL_ACCOUNT_UNLOCK_EXPIRY" envDefault:"1h"`
 }
 
 // SecurityConfig contains security-related configuration
 type SecurityConfig struct {
-	PasswordMinLength     int
-	PasswordRequireUpper   bool
-	PasswordRequireLower   bool
-	PasswordRequireDigit   bool
-	PasswordRequireSpecial bool
-	MaxLoginAttempts       int
-	LoginAttemptWindow     time.Duration
-	LockoutDuration        time.Duration
+	PasswordMinLength      int  `env:"SECURITY_PASSWORD_MIN_LENGTH" envDefault:"8"`
+	PasswordRequireUpper   bool `env:"SECURITY_PASSWORD_REQUIRE_UPPER" envDefault:"true"`
+	PasswordRequireLower   bool `env:"SECURITY_PASSWORD_REQUIRE_LOWER" envDefault:"true"`
+	PasswordRequireDigit   bool `env:"SECURITY_PASSWORD_REQUIRE_DIGIT" envDefault:"true"`
+	PasswordRequireSpecial bool `env:"SECURITY_PASSWORD_REQUIRE_SPECIAL" envDefault:"true"`
+	// PasswordMaxLength is capped at 72, the most bcrypt can hash
+	PasswordMaxLength int `env:"SECURITY_PASSWORD_MAX_LENGTH" envDefault:"72"`
+	// PasswordMinEntropyBits rejects predictable passwords; 0 disables the check
+	PasswordMinEntropyBits int  `env:"SECURITY_PASSWORD_MIN_ENTROPY" envDefault:"30"`
+	PasswordDenyCommon     bool `env:"SECURITY_PASSWORD_DENY_COMMON" envDefault:"true"`
+	// PasswordBreachCheck looks new passwords up in the Have I Been Pwned range API
+	PasswordBreachCheck     bool          `env:"SECURITY_PASSWORD_BREACH_CHECK" envDefault:"true"`
+	PasswordBreachThreshold int           `env:"SECURITY_PASSWORD_BREACH_THRESHOLD" envDefault:"1"`
+	PasswordBreachAPIURL    string        `env:"SECURITY_PASSWORD_BREACH_API_URL" envDefault:"https://api.pwnedpasswords.com"`
+	PasswordBreachTimeout   time.Duration `env:"SECURITY_PASSWORD_BREACH_TIMEOUT" envDefault:"2s"`
+	MaxLoginAttempts        int           `env:"SECURITY_MAX_LOGIN_ATTEMPTS" envDefault:"5"`
+	LoginAttemptWindow      time.Duration `env:"SECURITY_LOGIN_ATTEMPT_WINDOW" envDefault:"15m"`
+	LockoutDuration         time.Duration `env:"SECURITY_LOCKOUT_DURATION" envDefault:"30m"`
+	// MaxLockoutDuration caps the exponential lockout window for repeat offenders
+	MaxLockoutDuration time.Duration `env:"SECURITY_MAX_LOCKOUT_DURATION" envDefault:"24h"`
+	// MaxIPLoginAttempts is the failure threshold for a single client IP across accounts
+	MaxIPLoginAttempts int `env:"SECURITY_MAX_IP_LOGIN_ATTEMPTS" envDefault:"20"`
+	// InternalAuth holds the keys of the service tokens other services present
+	InternalAuth internalauth.Config `env:"-"`
+}
+
+// WebAuthnConfig contains passkey (WebAuthn) relying party configuration
+type WebAuthnConfig struct {
+	// RPID is the domain passkeys are bound to; it must match the frontend's host
+	RPID   string `env:"WEBAUTHN_RP_ID" envDefault:"localhost"`
+	RPName string `env:"WEBAUTHN_RP_NAME" envDefault:"MyApp"`
+	// Origins lists the exact frontend origins allowed to run WebAuthn ceremonies
+	Origins      []string      `env:"WEBAUTHN_ORIGINS"`
+	ChallengeTTL time.Duration `env:"WEBAUTHN_CHALLENGE_TTL" envDefault:"5m"`
+}
+
+// OTPConfig contains SMS/voice one-time code configuration
+type OTPConfig struct {
+	// Provider selects the gateway: "log" (development only), "twilio" or "sns"
+	Provider    string        `env:"OTP_PROVIDER" envDefault:"log"`
+	AppName     string        `env:"OTP_APP_NAME" envDefault:"MyApp"`
+	CodeLength  int           `env:"OTP_CODE_LENGTH" envDefault:"6"`
+	CodeTTL     time.Duration `env:"OTP_CODE_TTL" envDefault:"5m"`
+	MaxAttempts int           `env:"OTP_MAX_ATTEMPTS" envDefault:"5"`
+	// ResendCooldown and MaxSendsPerHour apply per phone number
+	ResendCooldown  time.Duration `env:"OTP_RESEND_COOLDOWN" envDefault:"60s"`
+	MaxSendsPerHour int           `env:"OTP_MAX_SENDS_PER_HOUR" envDefault:"5"`
+
+	TwilioAccountSID string `env:"OTP_TWILIO_ACCOUNT_SID"`
+	TwilioAuthToken  string `env:"OTP_TWILIO_AUTH_TOKEN,secret"`
+	TwilioFromNumber string `env:"OTP_TWILIO_FROM_NUMBER"`
+
+	SNSRegion string `env:"OTP_SNS_REGION"`
+	// SNSAccessKeyID and SNSSecretAccessKey are optional; without them the AWS SDK's
+	// default credential chain is used, such as the ECS task role or EKS IRSA
+	SNSAccessKeyID     string `env:"OTP_SNS_ACCESS_KEY_ID"`
+	SNSSecretAccessKey string `env:"OTP_SNS_SECRET_ACCESS_KEY,secret"`
+	SNSSenderID        string `env:"OTP_SNS_SENDER_ID"`
+}
+
+// DataExportConfig contains self-service data export (takeout) configuration
+type DataExportConfig struct {
+	// Dir is where finished archives are written; it must be shared by all replicas
+	Dir string `env:"DATA_EXPORT_DIR" envDefault:"/var/lib/user-services/exports"`
+	// Sources names the other services asked for their share of the user's data
+	Sources []string `env:"DATA_EXPORT_SOURCES" envDefault:"orders,progress"`
+	// CollectTimeout is how long to wait for every source before building without it
+	CollectTimeout time.Duration `env:"DATA_EXPORT_COLLECT_TIMEOUT" envDefault:"10m"`
+	// TTL is how long a finished archive stays downloadable
+	TTL time.Duration `env:"DATA_EXPORT_TTL" envDefault:"168h"`
+	// Cooldown is the minimum time between two exports for the same user
+	Cooldown     time.Duration `env:"DATA_EXPORT_COOLDOWN" envDefault:"24h"`
+	MaxPartBytes int64         `env:"DATA_EXPORT_MAX_PART_BYTES" envDefault:"16777216"`
+	PollInterval time.Duration `env:"DATA_EXPORT_POLL_INTERVAL" envDefault:"30s"`
+}
+
+// ErasureConfig contains right-to-erasure configuration
+type ErasureConfig struct {
+	// RetentionWindow is how long a deleted account can still be restored before its
+	// personal data is anonymized
+	RetentionWindow time.Duration `env:"ERASURE_RETENTION_WINDOW" envDefault:"720h"`
+	// DeactivationWindow is the cooling-off period during which a user who deactivated
+	// their account can reactivate it by signing in, before it is erased
+	DeactivationWindow time.Duration `env:"ACCOUNT_DEACTIVATION_WINDOW" envDefault:"720h"`
+	// SoftDeleteRetention is how long an account deleted without an erasure request,
+	// such as by an admin, is kept before it is erased too; zero keeps it forever
+	SoftDeleteRetention time.Duration `env:"ERASURE_SOFT_DELETE_RETENTION" envDefault:"2160h"`
+	// Services names the other services that must confirm they scrubbed their copies
+	Services []string `env:"ERASURE_SERVICES" envDefault:"orders,lessons,notifications"`
+	// AckTimeout is how long to wait for every confirmation before reporting the
+	// erasure incomplete
+	AckTimeout   time.Duration `env:"ERASURE_ACK_TIMEOUT" envDefault:"72h"`
+	PollInterval time.Duration `env:"ERASURE_POLL_INTERVAL" envDefault:"1m"`
+}
+
+// ActivityRollupConfig contains study time rollup configuration
+type ActivityRollupConfig struct {
+	// PollInterval is how often changed activity sessions are rolled up
+	PollInterval time.Duration `env:"ACTIVITY_ROLLUP_POLL_INTERVAL" envDefault:"5m"`
+	// Overlap is how long before the last rollup changed sessions are looked for again,
+	// covering sessions committed while it ran
+	Overlap time.Duration `env:"ACTIVITY_ROLLUP_OVERLAP" envDefault:"10m"`
+	// MaxRange bounds the period one rollup query may cover
+	MaxRange time.Duration `env:"ACTIVITY_ROLLUP_MAX_RANGE" envDefault:"8784h"`
+}
+
+// RetentionConfig contains the data retention job, see shared/retention
+type RetentionConfig struct {
+	// Schedule is when the retention.purge job runs
+	Schedule scheduler.Spec `env:"RETENTION_SCHEDULE" envDefault:"0 3 * * *"`
+	// DryRun only logs how many rows each rule would purge
+	DryRun bool `env:"RETENTION_DRY_RUN" envDefault:"false"`
+	// Overrides change the age of rules or turn them off, e.g. audit_logs=8760h
+	Overrides retention.Overrides `env:"RETENTION_OVERRIDES"`
+}
+
+// GeoIPConfig contains the location lookup of session IP addresses
+type GeoIPConfig struct {
+	// ServiceURL is an ip-api compatible endpoint; sessions get no location when empty
+	ServiceURL string `env:"GEOIP_SERVICE_URL"`
+	// Timeout bounds a lookup, which delays the sign-in it is made for
+	Timeout time.Duration `env:"GEOIP_TIMEOUT" envDefault:"2s"`
+	// CacheTTL is how long a resolved location is reused for the same address
+	CacheTTL time.Duration `env:"GEOIP_CACHE_TTL" envDefault:"24h"`
+}
+
+// AvatarConfig contains avatar upload and storage configuration
+type AvatarConfig struct {
+	// S3Endpoint is optional and defaults to Amazon S3 in S3Region; set it (and
+	// S3UsePathStyle) for MinIO
+	S3Endpoint string `env:"AVATAR_S3_ENDPOINT"`
+	S3Region   string `env:"AVATAR_S3_REGION" envDefault:"us-east-1"`
+	S3Bucket   string `env:"AVATAR_S3_BUCKET"`
+	// S3AccessKeyID and S3SecretAccessKey are optional; without them the AWS SDK's
+	// default credential chain is used, such as the ECS task role or EKS IRSA
+	S3AccessKeyID     string `env:"AVATAR_S3_ACCESS_KEY_ID"`
+	S3SecretAccessKey string `env:"AVATAR_S3_SECRET_ACCESS_KEY,secret"`
+	S3UsePathStyle    bool   `env:"AVATAR_S3_USE_PATH_STYLE"`
+	// PublicBaseURL is where stored avatars are served from, such as a CDN in front of
+	// the bucket; it defaults to the bucket URL
+	PublicBaseURL string `env:"AVATAR_PUBLIC_BASE_URL"`
+	MaxBytes      int64  `env:"AVATAR_MAX_BYTES" envDefault:"5242880"`
+	// MinDimension and MaxDimension bound the width and height of an uploaded image
+	MinDimension int `env:"AVATAR_MIN_DIMENSION" envDefault:"64"`
+	MaxDimension int `env:"AVATAR_MAX_DIMENSION" envDefault:"4096"`
+	// Size is the width and height avatars are stored at
+	Size int `env:"AVATAR_SIZE" envDefault:"256"`
+	// UploadTTL is how long a presigned upload URL is valid
+	UploadTTL       time.Duration `env:"AVATAR_UPLOAD_TTL" envDefault:"15m"`
+	CleanupInterval time.Duration `env:"AVATAR_CLEANUP_INTERVAL" envDefault:"1m"`
+}
+
+// Enabled reports whether avatar storage is configured.
+func (c AvatarConfig) Enabled() bool {
+	return c.S3Bucket != ""
+}
+
+// OutboxConfig contains outbox processor configuration
+type OutboxConfig struct {
+	PollInterval time.Duration `env:"OUTBOX_POLL_INTERVAL" envDefault:"5s"`
+	BatchSize    int           `env:"OUTBOX_BATCH_SIZE" envDefault:"10"`
+	// MaxAttempts is how many times an event is published before it is parked as failed
+	MaxAttempts int `env:"OUTBOX_MAX_ATTEMPTS" envDefault:"10"`
+	// BackoffBase is the delay after the first failed publish; it doubles with every
+	// further failure up to BackoffMax
+	BackoffBase time.Duration `env:"OUTBOX_BACKOFF_BASE" envDefault:"5s"`
+	BackoffMax  time.Duration `env:"OUTBOX_BACKOFF_MAX" envDefault:"10m"`
+	// ConfirmTimeout is how long a publish waits for RabbitMQ to confirm the event
+	ConfirmTimeout time.Duration `env:"OUTBOX_CONFIRM_TIMEOUT" envDefault:"5s"`
+	// Retention is how long published events are kept; 0 keeps them
+	Retention time.Duration `env:"OUTBOX_RETENTION"`
+	// Lease is how long a batch claimed by a replica is hidden from the other relays of
+	// the outbox, including the standalone outbox-relay
+	Lease time.Duration `env:"OUTBOX_LEASE" envDefault:"1m"`
+	// RelayEnabled runs the relay in the service; turned off when outbox-relay publishes
+	// the events
+	RelayEnabled bool `env:"OUTBOX_RELAY_ENABLED" envDefault:"true"`
+}
+
+// ImportConfig contains bulk user import configuration
+type ImportConfig struct {
+	// MaxRows bounds the number of users one import may create
+	MaxRows int `env:"IMPORT_MAX_ROWS" envDefault:"1000"`
+	// InviteExpiry is how long the invitation link sent to an imported user is valid
+	InviteExpiry time.Duration `env:"IMPORT_INVITE_EXPIRY" envDefault:"168h"`
+}
+
+// InvitationConfig contains invitation configuration
+type InvitationConfig struct {
+	// SigningSecret keys the HMAC signing invitation tokens; defaults to JWT_SECRET
+	SigningSecret string `env:"INVITATION_SIGNING_SECRET,secret"`
+	// Expiry is how long an invitation can be accepted after it was sent or last resent
+	Expiry time.Duration `env:"INVITATION_EXPIRY" envDefault:"168h"`
+	// ResendCooldown is the minimum time between two emails for the same invitation
+	ResendCooldown time.Duration `env:"INVITATION_RESEND_COOLDOWN" envDefault:"1m"`
+}
+
+// AccessTokenConfig contains personal access token configuration
+type AccessTokenConfig struct {
+	// MaxPerUser bounds the active (unrevoked, unexpired) tokens one user may hold
+	MaxPerUser int `env:"ACCESS_TOKEN_MAX_PER_USER" envDefault:"20"`
+	// MaxLifetime caps the expiry a token may be given; 0 allows tokens that never expire
+	MaxLifetime time.Duration `env:"ACCESS_TOKEN_MAX_LIFETIME" envDefault:"8760h"`
+	// LastUsedInterval is how stale last_used_at may get before a use rewrites it
+	LastUsedInterval time.Duration `env:"ACCESS_TOKEN_LAST_USED_INTERVAL" envDefault:"1m"`
+}
+
+// PushTokenConfig contains push notification token configuration
+type PushTokenConfig struct {
+	// MaxPerUser bounds the tokens one user may register; registering another deletes
+	// the least recently registered
+	MaxPerUser int `env:"PUSH_TOKEN_MAX_PER_USER" envDefault:"20"`
+}
+
+// AccountMergeConfig contains account merge configuration
+type AccountMergeConfig struct {
+	// CodeTTL is how long the codes emailed to both accounts can be used
+	CodeTTL time.Duration `env:"ACCOUNT_MERGE_CODE_TTL" envDefault:"15m"`
+	// MaxAttempts bounds wrong code submissions before the merge is cancelled
+	MaxAttempts int `env:"ACCOUNT_MERGE_MAX_ATTEMPTS" envDefault:"5"`
+	// RequestCooldown is the minimum time between two merge requests of one user, each
+	// of which emails both accounts
+	RequestCooldown time.Duration `env:"ACCOUNT_MERGE_REQUEST_COOLDOWN" envDefault:"1m"`
+}
+
+// ImpersonationConfig contains support impersonation configuration
+type ImpersonationConfig struct {
+	// AllowedRoles are the roles permitted to impersonate users
+	AllowedRoles []string `env:"IMPERSONATION_ALLOWED_ROLES" envDefault:"super-admin"`
+	// DefaultDuration is how long an impersonation session lasts when none is requested
+	DefaultDuration time.Duration `env:"IMPERSONATION_DEFAULT_DURATION" envDefault:"15m"`
+	// MaxDuration caps the duration an impersonation session may be given
+	MaxDuration time.Duration `env:"IMPERSONATION_MAX_DURATION" envDefault:"1h"`
+}
+
+// PasswordlessConfig contains emailed login link and login code configuration
+type PasswordlessConfig struct {
+	// SigningSecret keys the HMAC signing login links; defaults to JWT_SECRET
+	SigningSecret string `env:"PASSWORDLESS_SIGNING_SECRET,secret"`
+	// LinkTTL is how long an emailed login link can be used
+	LinkTTL time.Duration `env:"PASSWORDLESS_LINK_TTL" envDefault:"15m"`
+	// CodeTTL is how long an emailed login code can be used
+	CodeTTL time.Duration `env:"PASSWORDLESS_CODE_TTL" envDefault:"10m"`
+	// MaxAttempts bounds verification attempts before a pending login is discarded
+	MaxAttempts int `env:"PASSWORDLESS_MAX_ATTEMPTS" envDefault:"5"`
+	// ResendCooldown is the minimum time between two sign-in emails to one address
+	ResendCooldown time.Duration `env:"PASSWORDLESS_RESEND_COOLDOWN" envDefault:"1m"`
+	// RequireDeviceBinding makes every login usable only by the client that requested
+	// it, even when the client did not ask for it
+	RequireDeviceBinding bool `env:"PASSWORDLESS_REQUIRE_DEVICE_BINDING"`
+}
+
+// PasswordResetConfig contains what reset links are bound to. Their lifetime is
+// EmailConfig.PasswordResetExpiry.
+type PasswordResetConfig struct {
+	// BindUserAgent makes a reset link usable only from the browser that requested it
+	BindUserAgent bool `env:"PASSWORD_RESET_BIND_USER_AGENT" envDefault:"true"`
+	// BindNetwork makes a reset link usable only from the network it was requested from:
+	// the same /24 for IPv4, the same /64 for IPv6
+	BindNetwork bool `env:"PASSWORD_RESET_BIND_NETWORK" envDefault:"true"`
+	// MaxAttempts bounds confirmations from another browser or network before the link
+	// is used up
+	MaxAttempts int `env:"PASSWORD_RESET_MAX_ATTEMPTS" envDefault:"3"`
 }
 
 // RateLimitConfig contains rate limiting configuration
 type RateLimitConfig struct {
 	// Authentication endpoints
-	AuthRequestsPerMinute    int           `env:"RATE_LIMIT_AUTH_REQUESTS" envDefault:"10"`
-	AuthWindow               time.Duration `env:"RATE_LIMIT_AUTH_WINDOW" envDefault:"1m"`
+	AuthRequestsPerMinute int           `env:"RATE_LIMIT_AUTH_REQUESTS" envDefault:"10"`
+	AuthWindow            time.Duration `env:"RATE_LIMIT_AUTH_WINDOW" envDefault:"1m"`
+	AuthAccountRequests   int           `env:"RATE_LIMIT_AUTH_ACCOUNT_REQUESTS" envDefault:"5"`
+	AuthAccountWindow     time.Duration `env:"RATE_LIMIT_AUTH_ACCOUNT_WINDOW" envDefault:"1m"`
+
+	// MFA verification endpoints
+	MFAVerifyRequests int           `env:"RATE_LIMIT_MFA_VERIFY" envDefault:"5"`
+	MFAVerifyWindow   time.Duration `env:"RATE_LIMIT_MFA_VERIFY_WINDOW" envDefault:"5m"`
+
+	// SMS/voice code sends
+	OTPSendRequests int           `env:"RATE_LIMIT_OTP_SEND" envDefault:"3"`
+	OTPSendWindow   time.Duration `env:"RATE_LIMIT_OTP_SEND_WINDOW" envDefault:"15m"`
 
 	// Password reset endpoints
-	PasswordResetPerHour     int           `env:"RATE_LIMIT_PASSWORD_RESET" envDefault:"3"`
-	PasswordResetWindow      time.Duration `env:"RATE_LIMIT_PASSWORD_WINDOW" envDefault:"1h"`
+	PasswordResetPerHour int           `env:"RATE_LIMIT_PASSWORD_RESET" envDefault:"3"`
+	PasswordResetWindow  time.Duration `env:"RATE_LIMIT_PASSWORD_WINDOW" envDefault:"1h"`
 
 	// Registration endpoints
-	RegisterPerHour          int           `env:"RATE_LIMIT_REGISTER" envDefault:"5"`
-	RegisterWindow           time.Duration `env:"RATE_LIMIT_REGISTER_WINDOW" envDefault:"1h"`
+	RegisterPerHour int           `env:"RATE_LIMIT_REGISTER" envDefault:"5"`
+	RegisterWindow  time.Duration `env:"RATE_LIMIT_REGISTER_WINDOW" envDefault:"1h"`
 
 	// Account-specific limits
-	AccountFailureThreshold  int           `env:"RATE_LIMIT_ACCOUNT_FAILURES" envDefault:"5"`
-	AccountFailureWindow     time.Duration `env:"RATE_LIMIT_ACCOUNT_WINDOW" envDefault:"15m"`
-	AccountBlockDuration     time.Duration `env:"RATE_LIMIT_ACCOUNT_BLOCK" envDefault:"30m"`
+	AccountFailureThreshold int           `env:"RATE_LIMIT_ACCOUNT_FAILURES" envDefault:"5"`
+	AccountFailureWindow    time.Duration `env:"RATE_LIMIT_ACCOUNT_WINDOW" envDefault:"15m"`
+	AccountBlockDuration    time.Duration `env:"RATE_LIMIT_ACCOUNT_BLOCK" envDefault:"30m"`
 
 	// Progressive backoff settings
-	EnableProgressiveBackoff bool          `env:"RATE_LIMIT_PROGRESSIVE_BACKOFF" envDefault:"true"`
+	EnableProgressiveBackoff bool `env:"RATE_LIMIT_PROGRESSIVE_BACKOFF" envDefault:"true"`
+
+	// Policies override the limits above by policy name, e.g. auth.login=20/1m (see
+	// shared/ratelimit)
+	Policies ratelimit.Policies `env:"RATE_LIMITS"`
 }
 
-// Load loads configuration from environment variables
+// Load loads configuration from environment variables and the .env file. Defaults are
+// in the env tags of the fields; a value that does not parse is an error. On an error
+// the configuration read so far is returned too, for "config validate" to print.
 func Load() (*Config, error) {
-	// Load .env file if present (non-fatal if missing)
-	if err := godotenv.Load(); err != nil {
-		// It's okay if .env is not present; environment variables can be provided directly
-	}
-
-	cfg := &Config{
-		Environment: getEnv("ENVIRONMENT", "development"),
-	}
-
-	// Load server configuration
-	cfg.Server = ServerConfig{
-		Port:         getEnv("PORT", "8001"),
-		ReadTimeout:  getDurationEnv("SERVER_READ_TIMEOUT", 15*time.Second),
-		WriteTimeout: getDurationEnv("SERVER_WRITE_TIMEOUT", 15*time.Second),
-		IdleTimeout:  getDurationEnv("SERVER_IDLE_TIMEOUT", 60*time.Second),
-	}
-
-	// Load database configuration
-	cfg.Database = DatabaseConfig{
-		User:         getEnv("DB_USER", "user"),
-		Password:     getEnv("DB_PASSWORD", "password"),
-		Host:         getEnv("DB_HOST", "postgres"),
-		Port:         getEnv("DB_PORT", "5432"),
-		DBName:       getEnv("DB_NAME", "lms_user_services"),
-		SSLMode:      getEnv("POSTGRES_SSLMODE", "disable"),
-		MaxOpenConns: getIntEnv("DB_MAX_OPEN_CONNS", 25),
-		MaxIdleConns: getIntEnv("DB_MAX_IDLE_CONNS", 5),
-		MaxIdleTime:  getDurationEnv("DB_MAX_IDLE_TIME", 5*time.Minute),
-	}
-
-	// Load Redis configuration
-	cfg.Redis = RedisConfig{
-		Addr:         getEnv("REDIS_HOST", "redis") + ":" + getEnv("REDIS_PORT", "6379"),
-		Password:     getEnv("REDIS_PASSWORD", ""),
-		DB:           getIntEnv("REDIS_DB", 0),
-		PoolSize:     getIntEnv("REDIS_POOL_SIZE", 10),
-		MinIdleConns: getIntEnv("REDIS_MIN_IDLE_CONNS", 3),
-		DialTimeout:  getDurationEnv("REDIS_DIAL_TIMEOUT", 5*time.Second),
-		ReadTimeout:  getDurationEnv("REDIS_READ_TIMEOUT", 3*time.Second),
-		WriteTimeout: getDurationEnv("REDIS_WRITE_TIMEOUT", 3*time.Second),
-	}
-
-	// Load RabbitMQ configuration
-	cfg.RabbitMQ = RabbitConfig{
-		URL:            getEnv("RABBITMQ_URL", ""),
-		User:           getEnv("RABBITMQ_USER", "user"),
-		Password:       getEnv("RABBITMQ_PASSWORD", "password"),
-		Host:           getEnv("RABBITMQ_HOST", "rabbitmq"),
-		Port:           getEnv("RABBITMQ_PORT", "5672"),
-		VHost:          getEnv("RABBITMQ_VHOST", "/"),
-		ExchangeName:   getEnv("RABBITMQ_EXCHANGE", "notifications"),
-		ReconnectDelay: getDurationEnv("RABBITMQ_RECONNECT_DELAY", 5*time.Second),
-		Heartbeat:      getDurationEnv("RABBITMQ_HEARTBEAT", 10*time.Second),
+	cfg := &Config{}
+	if err := envconfig.Load(cfg); err != nil {
+		return cfg, err
 	}
 
+	cfg.Redis.Addr = cfg.Redis.Host + ":" + cfg.Redis.Port
+
 	// Build RabbitMQ URL if not provided
 	if cfg.RabbitMQ.URL == "" {
 		cfg.RabbitMQ.URL = fmt.Sprintf("amqp://%s:%s@%s:%s/%s",
@@ -194,53 +457,24 @@ func Load() (*Config, error) {
 		)
 	}
 
-	// Load JWT configuration
-	cfg.JWT = JWTConfig{
-		Secret:           getEnv("JWT_SECRET", "change-me-dev-secret"),
-		ExpiresIn:        getDurationEnv("JWT_EXPIRES_IN", 24*time.Hour),
-		RefreshExpiresIn: getDurationEnv("JWT_REFRESH_EXPIRES_IN", 60*24*time.Hour),
-		Issuer:           getEnv("JWT_ISSUER", "user-services"),
-	}
-
-	// Load session configuration
-	cfg.Session = SessionConfig{
-		Expiry:        getDurationEnv("SESSION_EXPIRY", 30*24*time.Hour), // 30 days
-		CleanupPeriod: getDurationEnv("SESSION_CLEANUP_PERIOD", 1*time.Hour),
-		MaxSessions:   getIntEnv("SESSION_MAX_SESSIONS", 10),
+	// Passkeys are bound to the frontend unless other origins are listed
+	if len(cfg.WebAuthn.Origins) == 0 {
+		cfg.WebAuthn.Origins = []string{cfg.Email.FrontendURL}
 	}
 
-	// Load email configuration
-	cfg.Email = EmailConfig{
-		FrontendURL:        getEnv("FRONTEND_URL", "http://localhost:3001"),
-		VerificationExpiry: getDurationEnv("EMAIL_VERIFICATION_EXPIRY", 24*time.Hour),
-		PasswordResetExpiry: getDurationEnv("EMAIL_PASSWORD_RESET_EXPIRY", 2*time.Hour),
+	// Invitation tokens and login links are signed with the JWT secret by default
+	if cfg.Invitation.SigningSecret == "" {
+		cfg.Invitation.SigningSecret = cfg.JWT.Secret
 	}
-
-	// Load security configuration
-	cfg.Security = SecurityConfig{
-		PasswordMinLength:     getIntEnv("SECURITY_PASSWORD_MIN_LENGTH", 8),
-		PasswordRequireUpper:   getBoolEnv("SECURITY_PASSWORD_REQUIRE_UPPER", true),
-		PasswordRequireLower:   getBoolEnv("SECURITY_PASSWORD_REQUIRE_LOWER", true),
-		PasswordRequireDigit:   getBoolEnv("SECURITY_PASSWORD_REQUIRE_DIGIT", true),
-		PasswordRequireSpecial: getBoolEnv("SECURITY_PASSWORD_REQUIRE_SPECIAL", true),
-		MaxLoginAttempts:       getIntEnv("SECURITY_MAX_LOGIN_ATTEMPTS", 5),
-		LoginAttemptWindow:     getDurationEnv("SECURITY_LOGIN_ATTEMPT_WINDOW", 15*time.Minute),
-		LockoutDuration:        getDurationEnv("SECURITY_LOCKOUT_DURATION", 30*time.Minute),
+	if cfg.MagicLogin.SigningSecret == "" {
+		cfg.MagicLogin.SigningSecret = cfg.JWT.Secret
 	}
 
-	// Load rate limiting configuration
-	cfg.RateLimit = RateLimitConfig{
-		AuthRequestsPerMinute:    getIntEnv("RATE_LIMIT_AUTH_REQUESTS", 10),
-		AuthWindow:               getDurationEnv("RATE_LIMIT_AUTH_WINDOW", 1*time.Minute),
-		PasswordResetPerHour:     getIntEnv("RATE_LIMIT_PASSWORD_RESET", 3),
-		PasswordResetWindow:      getDurationEnv("RATE_LIMIT_PASSWORD_WINDOW", 1*time.Hour),
-		RegisterPerHour:          getIntEnv("RATE_LIMIT_REGISTER", 5),
-		RegisterWindow:           getDurationEnv("RATE_LIMIT_REGISTER_WINDOW", 1*time.Hour),
-		AccountFailureThreshold:  getIntEnv("RATE_LIMIT_ACCOUNT_FAILURES", 5),
-		AccountFailureWindow:     getDurationEnv("RATE_LIMIT_ACCOUNT_WINDOW", 15*time.Minute),
-		AccountBlockDuration:     getDurationEnv("RATE_LIMIT_ACCOUNT_BLOCK", 30*time.Minute),
-		EnableProgressiveBackoff: getBoolEnv("RATE_LIMIT_PROGRESSIVE_BACKOFF", true),
+	internalAuth, err := internalauth.ConfigFromEnv("user-services")
+	if err != nil {
+		return cfg, err
 	}
+	cfg.Security.InternalAuth = internalAuth
 
 	return cfg, nil
 }
@@ -256,6 +490,130 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	switch c.OTP.Provider {
+	case "twilio":
+		if c.OTP.TwilioAccountSID == "" || c.OTP.TwilioAuthToken == "" || c.OTP.TwilioFromNumber == "" {
+			return fmt.Errorf("OTP_TWILIO_ACCOUNT_SID, OTP_TWILIO_AUTH_TOKEN and OTP_TWILIO_FROM_NUMBER are required when OTP_PROVIDER=twilio")
+		}
+	case "sns":
+		if c.OTP.SNSRegion == "" {
+			return fmt.Errorf("OTP_SNS_REGION is required when OTP_PROVIDER=sns")
+		}
+	case "log":
+	default:
+		return fmt.Errorf("unknown OTP_PROVIDER %q", c.OTP.Provider)
+	}
+
+	if c.Security.PasswordMaxLength < c.Security.PasswordMinLength {
+		return fmt.Errorf("SECURITY_PASSWORD_MAX_LENGTH must not be less than SECURITY_PASSWORD_MIN_LENGTH")
+	}
+
+	if c.DataExport.Dir == "" {
+		return fmt.Errorf("DATA_EXPORT_DIR is required")
+	}
+	if c.DataExport.PollInterval <= 0 {
+		return fmt.Errorf("DATA_EXPORT_POLL_INTERVAL must be positive")
+	}
+	if c.Erasure.PollInterval <= 0 {
+		return fmt.Errorf("ERASURE_POLL_INTERVAL must be positive")
+	}
+	if c.Erasure.DeactivationWindow <= 0 {
+		return fmt.Errorf("ACCOUNT_DEACTIVATION_WINDOW must be positive")
+	}
+	if c.Erasure.SoftDeleteRetention < 0 {
+		return fmt.Errorf("ERASURE_SOFT_DELETE_RETENTION must not be negative")
+	}
+	if c.Avatar.Size <= 0 || c.Avatar.MinDimension <= 0 || c.Avatar.MaxDimension < c.Avatar.MinDimension {
+		return fmt.Errorf("AVATAR_SIZE and AVATAR_MIN_DIMENSION must be positive and AVATAR_MAX_DIMENSION must not be less than AVATAR_MIN_DIMENSION")
+	}
+	if c.Avatar.UploadTTL <= 0 || c.Avatar.UploadTTL > 7*24*time.Hour {
+		return fmt.Errorf("AVATAR_UPLOAD_TTL must be positive and at most 7 days")
+	}
+	if c.Avatar.CleanupInterval <= 0 {
+		return fmt.Errorf("AVATAR_CLEANUP_INTERVAL must be positive")
+	}
+	if c.Outbox.PollInterval <= 0 {
+		return fmt.Errorf("OUTBOX_POLL_INTERVAL must be positive")
+	}
+	if c.Outbox.BatchSize < 1 || c.Outbox.BatchSize > 1000 {
+		return fmt.Errorf("OUTBOX_BATCH_SIZE must be between 1 and 1000")
+	}
+	if c.Outbox.MaxAttempts < 1 {
+		return fmt.Errorf("OUTBOX_MAX_ATTEMPTS must be at least 1")
+	}
+	if c.Outbox.BackoffBase <= 0 || c.Outbox.BackoffMax < c.Outbox.BackoffBase {
+		return fmt.Errorf("OUTBOX_BACKOFF_BASE must be positive and OUTBOX_BACKOFF_MAX must not be less than OUTBOX_BACKOFF_BASE")
+	}
+	if c.Outbox.ConfirmTimeout <= 0 {
+		return fmt.Errorf("OUTBOX_CONFIRM_TIMEOUT must be positive")
+	}
+	if c.Outbox.Retention < 0 {
+		return fmt.Errorf("OUTBOX_RETENTION must not be negative")
+	}
+	if c.Activity.PollInterval <= 0 || c.Activity.MaxRange <= 0 {
+		return fmt.Errorf("ACTIVITY_ROLLUP_POLL_INTERVAL and ACTIVITY_ROLLUP_MAX_RANGE must be positive")
+	}
+	if c.Activity.Overlap < 0 {
+		return fmt.Errorf("ACTIVITY_ROLLUP_OVERLAP must not be negative")
+	}
+	if c.GeoIP.Timeout <= 0 || c.GeoIP.CacheTTL < 0 {
+		return fmt.Errorf("GEOIP_TIMEOUT must be positive and GEOIP_CACHE_TTL must not be negative")
+	}
+	if c.Import.MaxRows < 1 || c.Import.MaxRows > 10000 {
+		return fmt.Errorf("IMPORT_MAX_ROWS must be between 1 and 10000")
+	}
+	if c.Import.InviteExpiry <= 0 {
+		return fmt.Errorf("IMPORT_INVITE_EXPIRY must be positive")
+	}
+	if c.Invitation.Expiry <= 0 {
+		return fmt.Errorf("INVITATION_EXPIRY must be positive")
+	}
+	if c.Invitation.ResendCooldown < 0 {
+		return fmt.Errorf("INVITATION_RESEND_COOLDOWN must not be negative")
+	}
+	if c.IsProduction() && len(c.Invitation.SigningSecret) < 32 {
+		return fmt.Errorf("INVITATION_SIGNING_SECRET must be at least 32 characters in production")
+	}
+	if c.AccessToken.MaxPerUser < 1 {
+		return fmt.Errorf("ACCESS_TOKEN_MAX_PER_USER must be positive")
+	}
+	if c.AccessToken.MaxLifetime < 0 || c.AccessToken.LastUsedInterval < 0 {
+		return fmt.Errorf("ACCESS_TOKEN_MAX_LIFETIME and ACCESS_TOKEN_LAST_USED_INTERVAL must not be negative")
+	}
+	if c.PushTokens.MaxPerUser < 1 {
+		return fmt.Errorf("PUSH_TOKEN_MAX_PER_USER must be positive")
+	}
+	if c.Merge.CodeTTL <= 0 {
+		return fmt.Errorf("ACCOUNT_MERGE_CODE_TTL must be positive")
+	}
+	if c.Merge.MaxAttempts < 1 {
+		return fmt.Errorf("ACCOUNT_MERGE_MAX_ATTEMPTS must be positive")
+	}
+	if c.Merge.RequestCooldown < 0 {
+		return fmt.Errorf("ACCOUNT_MERGE_REQUEST_COOLDOWN must not be negative")
+	}
+	if c.Impersonate.DefaultDuration <= 0 || c.Impersonate.MaxDuration < c.Impersonate.DefaultDuration {
+		return fmt.Errorf("IMPERSONATION_DEFAULT_DURATION must be positive and not exceed IMPERSONATION_MAX_DURATION")
+	}
+	if c.MagicLogin.LinkTTL <= 0 || c.MagicLogin.CodeTTL <= 0 {
+		return fmt.Errorf("PASSWORDLESS_LINK_TTL and PASSWORDLESS_CODE_TTL must be positive")
+	}
+	if c.MagicLogin.MaxAttempts < 1 {
+		return fmt.Errorf("PASSWORDLESS_MAX_ATTEMPTS must be positive")
+	}
+	if c.MagicLogin.ResendCooldown < 0 {
+		return fmt.Errorf("PASSWORDLESS_RESEND_COOLDOWN must not be negative")
+	}
+	if c.Email.PasswordResetExpiry <= 0 {
+		return fmt.Errorf("EMAIL_PASSWORD_RESET_EXPIRY must be positive")
+	}
+	if c.Reset.MaxAttempts < 1 {
+		return fmt.Errorf("PASSWORD_RESET_MAX_ATTEMPTS must be positive")
+	}
+	if c.IsProduction() && len(c.MagicLogin.SigningSecret) < 32 {
+		return fmt.Errorf("PASSWORDLESS_SIGNING_SECRET must be at least 32 characters in production")
+	}
+
 	if c.Database.User == "" {
 		return fmt.Errorf("database user is required")
 	}
@@ -276,39 +634,3 @@ func (c *Config) IsDevelopment() bool {
 func (c *Config) IsProduction() bool {
 	return c.Environment == "production"
 }
-
-// Helper functions for environment variable parsing
-
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
-func getIntEnv(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue
-		}
-	}
-	return defaultValue
-}
-
-func getBoolEnv(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
-		if boolValue, err := strconv.ParseBool(value); err == nil {
-			return boolValue
-		}
-	}
-	return defaultValue
-}
-
-func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
-		if duration, err := time.ParseDuration(value); err == nil {
-			return duration
-		}
-	}
-	return defaultValue
-}
\ No newline at end of file
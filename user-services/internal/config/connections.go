@@ -2,6 +2,9 @@ package config
 
 import (
 	"fmt"
+	"net"
+
+	"github.com/ductan2/microservice-app/shared/envconfig"
 )
 
 // GetPostgresConfig returns the PostgreSQL configuration from the global config
@@ -29,3 +32,23 @@ func GetRedisConfig() RedisConfig {
 func GetRabbitConfig() RabbitConfig {
 	return GetConfig().RabbitMQ
 }
+
+// Endpoints lists the dependencies the service connects to, dialed by
+// "user-services config validate -connect".
+func (c *Config) Endpoints() []envconfig.Endpoint {
+	endpoints := []envconfig.Endpoint{
+		{Name: "postgres", Address: net.JoinHostPort(c.Database.Host, c.Database.Port)},
+		{Name: "redis", Address: c.Redis.Addr},
+		{Name: "rabbitmq", Address: c.RabbitMQ.URL},
+	}
+	for i, replica := range c.Database.ReplicaURLs {
+		endpoints = append(endpoints, envconfig.Endpoint{Name: fmt.Sprintf("postgres-replica-%d", i+1), Address: replica})
+	}
+	if c.Security.PasswordBreachCheck {
+		endpoints = append(endpoints, envconfig.Endpoint{Name: "pwned-passwords", Address: c.Security.PasswordBreachAPIURL})
+	}
+	if c.GeoIP.ServiceURL != "" {
+		endpoints = append(endpoints, envconfig.Endpoint{Name: "geoip", Address: c.GeoIP.ServiceURL})
+	}
+	return endpoints
+}
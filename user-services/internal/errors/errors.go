@@ -1,160 +1,271 @@
+// Package errors holds the errors of the user service. AppError is the error model shared
+// by the Go services (shared/apperr); the constructors below pick its canonical code, and
+// the predefined errors add the reason clients switch on.
 package errors
 
 import (
 	"fmt"
-	"net/http"
+	"log/slog"
+	"time"
 
+	"github.com/ductan2/microservice-app/shared/apperr"
 	"github.com/gin-gonic/gin"
 )
 
-// ErrorType represents different categories of errors
-type ErrorType string
-
-const (
-	ErrorTypeValidation    ErrorType = "validation"
-	ErrorTypeAuthentication ErrorType = "authentication"
-	ErrorTypeAuthorization  ErrorType = "authorization"
-	ErrorTypeNotFound      ErrorType = "not_found"
-	ErrorTypeConflict      ErrorType = "conflict"
-	ErrorTypeRateLimit     ErrorType = "rate_limit"
-	ErrorTypeInternal      ErrorType = "internal"
-	ErrorTypeExternal      ErrorType = "external"
-)
+// AppError is a structured application error, see apperr.Error.
+type AppError = apperr.Error
 
-// AppError represents a structured application error
-type AppError struct {
-	Type       ErrorType     `json:"type"`
-	Message    string        `json:"message"`
-	Code       string        `json:"code,omitempty"`
-	HTTPStatus int           `json:"-"`
-	Details    interface{}   `json:"details,omitempty"`
-	Cause      error         `json:"-"`
-	Context    map[string]interface{} `json:"-"`
-}
-
-// Error implements the error interface
-func (e *AppError) Error() string {
-	if e.Cause != nil {
-		return fmt.Sprintf("%s: %s (caused by: %v)", e.Type, e.Message, e.Cause)
-	}
-	return fmt.Sprintf("%s: %s", e.Type, e.Message)
+// Predefined error constructors
+func NewValidationError(message string) *AppError {
+	return apperr.New(apperr.BadRequest, message)
 }
 
-// Unwrap returns the underlying cause
-func (e *AppError) Unwrap() error {
-	return e.Cause
+func NewAuthenticationError(message string) *AppError {
+	return apperr.New(apperr.Unauthorized, message)
 }
 
-// NewAppError creates a new application error
-func NewAppError(errorType ErrorType, message string, httpStatus int) *AppError {
-	return &AppError{
-		Type:       errorType,
-		Message:    message,
-		HTTPStatus: httpStatus,
-		Context:    make(map[string]interface{}),
-	}
+func NewAuthorizationError(message string) *AppError {
+	return apperr.New(apperr.Forbidden, message)
 }
 
-// WithCode adds an error code
-func (e *AppError) WithCode(code string) *AppError {
-	e.Code = code
-	return e
+func NewNotFoundError(resource string) *AppError {
+	return apperr.Newf(apperr.NotFound, "%s not found", resource)
 }
 
-// WithCause adds a cause error
-func (e *AppError) WithCause(cause error) *AppError {
-	e.Cause = cause
-	return e
+func NewConflictError(message string) *AppError {
+	return apperr.New(apperr.Conflict, message)
 }
 
-// WithDetails adds error details
-func (e *AppError) WithDetails(details interface{}) *AppError {
-	e.Details = details
-	return e
+func NewRateLimitError(message string) *AppError {
+	return apperr.New(apperr.RateLimited, message)
 }
 
-// WithContext adds context information
-func (e *AppError) WithContext(key string, value interface{}) *AppError {
-	if e.Context == nil {
-		e.Context = make(map[string]interface{})
-	}
-	e.Context[key] = value
-	return e
+func NewInternalError(message string) *AppError {
+	return apperr.New(apperr.Internal, message)
 }
 
-// Predefined error constructors
-func NewValidationError(message string) *AppError {
-	return NewAppError(ErrorTypeValidation, message, http.StatusBadRequest)
+func NewExternalServiceError(service string, message string) *AppError {
+	return apperr.New(apperr.Upstream, fmt.Sprintf("%s service error: %s", service, message))
 }
 
-func NewAuthenticationError(message string) *AppError {
-	return NewAppError(ErrorTypeAuthentication, message, http.StatusUnauthorized)
+// Common application errors
+var (
+	ErrInvalidCredentials         = NewAuthenticationError("Invalid email or password").WithReason("INVALID_CREDENTIALS")
+	ErrEmailNotVerified           = NewAuthenticationError("Email address not verified").WithReason("EMAIL_NOT_VERIFIED")
+	ErrInvalidMFACode             = NewAuthenticationError("Invalid or expired MFA code").WithReason("INVALID_MFA_CODE")
+	ErrMFANotSetup                = NewAuthenticationError("MFA not setup for this account").WithReason("MFA_NOT_SETUP")
+	ErrSessionExpired             = NewAuthenticationError("Session has expired").WithReason("SESSION_EXPIRED")
+	ErrTokenInvalid               = NewAuthenticationError("Invalid authentication token").WithReason("TOKEN_INVALID")
+	ErrRefreshTokenInvalid        = NewAuthenticationError("Invalid or expired refresh token").WithReason("REFRESH_TOKEN_INVALID")
+	ErrRefreshTokenReused         = NewAuthenticationError("Refresh token has already been used").WithReason("REFRESH_TOKEN_REUSED")
+	ErrAccountLocked              = NewAuthenticationError("Account has been locked").WithReason("ACCOUNT_LOCKED")
+	ErrAccountDisabled            = NewAuthenticationError("Account has been disabled").WithReason("ACCOUNT_DISABLED")
+	ErrWebAuthnChallengeExpired   = NewAuthenticationError("Passkey challenge is invalid or has expired").WithReason("WEBAUTHN_CHALLENGE_EXPIRED")
+	ErrWebAuthnVerificationFailed = NewAuthenticationError("Passkey verification failed").WithReason("WEBAUTHN_VERIFICATION_FAILED")
+	ErrInvalidLoginLink           = NewAuthenticationError("Invalid or expired login link").WithReason("INVALID_LOGIN_LINK")
+	ErrInvalidLoginCode           = NewAuthenticationError("Invalid or expired login code").WithReason("INVALID_LOGIN_CODE")
+	ErrLoginDeviceMismatch        = NewAuthenticationError("The login must be completed on the device that requested it").WithReason("LOGIN_DEVICE_MISMATCH")
+	ErrInvalidResetToken          = NewValidationError("Invalid or expired reset link").WithReason("INVALID_RESET_TOKEN")
+	ErrResetDeviceMismatch        = NewAuthorizationError("The reset link must be opened in the browser and network it was requested from").WithReason("RESET_DEVICE_MISMATCH")
+
+	ErrEmailExists              = NewConflictError("Email address already exists").WithReason("EMAIL_EXISTS")
+	ErrWebAuthnCredentialExists = NewConflictError("Passkey is already registered").WithReason("WEBAUTHN_CREDENTIAL_EXISTS")
+	ErrPhoneMFAExists           = NewConflictError("A phone number is already set up for MFA").WithReason("PHONE_MFA_EXISTS")
+	ErrUsernameTaken            = NewConflictError("This username is already taken").WithReason("USERNAME_TAKEN")
+	ErrPhoneNumberTaken         = NewConflictError("This phone number is already used by another account").WithReason("PHONE_NUMBER_TAKEN")
+	ErrInvalidPhoneCode         = NewValidationError("Invalid or expired verification code").WithReason("INVALID_PHONE_CODE")
+	ErrOTPChannelUnsupported    = NewValidationError("Codes cannot be delivered on this channel").WithReason("OTP_CHANNEL_UNSUPPORTED")
+	ErrWeakPassword             = NewValidationError("Password does not meet security requirements").WithReason("WEAK_PASSWORD")
+	ErrInvalidEmail             = NewValidationError("Invalid email address format").WithReason("INVALID_EMAIL")
+	ErrPasswordMismatch         = NewValidationError("Passwords do not match").WithReason("PASSWORD_MISMATCH")
+	InvalidVerificationToken    = NewValidationError("Invalid or expired verification token").WithReason("INVALID_VERIFICATION_TOKEN")
+	InvalidPasswordResetToken   = NewValidationError("Invalid or expired password reset token").WithReason("INVALID_PASSWORD_RESET_TOKEN")
+
+	ErrUserNotFound                 = NewNotFoundError("User").WithReason("USER_NOT_FOUND")
+	ErrSessionNotFound              = NewNotFoundError("Session").WithReason("SESSION_NOT_FOUND")
+	ErrMFAMethodNotFound            = NewNotFoundError("MFA method").WithReason("MFA_METHOD_NOT_FOUND")
+	ErrDataExportNotFound           = NewNotFoundError("Data export").WithReason("DATA_EXPORT_NOT_FOUND")
+	ErrDataExportNotReady           = NewConflictError("Data export is not ready yet").WithReason("DATA_EXPORT_NOT_READY")
+	ErrDataExportExpired            = apperr.New(apperr.Gone, "Data export has expired").WithReason("DATA_EXPORT_EXPIRED")
+	ErrErasureNotFound              = NewNotFoundError("Erasure request").WithReason("ERASURE_NOT_FOUND")
+	ErrAccountErased                = NewConflictError("Account has been erased").WithReason("ACCOUNT_ERASED")
+	ErrAccountNotRecoverable        = NewConflictError("Account cannot be recovered").WithReason("ACCOUNT_NOT_RECOVERABLE")
+	ErrOnboardingStepNotMet         = NewConflictError("The onboarding step has not been done yet").WithReason("ONBOARDING_STEP_NOT_MET")
+	ErrAvatarUploadNotFound         = NewNotFoundError("Avatar upload").WithReason("AVATAR_UPLOAD_NOT_FOUND")
+	ErrAvatarUploadClosed           = NewConflictError("Avatar upload is already completed or has expired").WithReason("AVATAR_UPLOAD_CLOSED")
+	ErrAvatarNotUploaded            = NewValidationError("The image has not been uploaded yet").WithReason("AVATAR_NOT_UPLOADED")
+	ErrAvatarUploadsThrottled       = NewRateLimitError("Too many avatar uploads in progress. Please try again later.").WithReason("AVATAR_UPLOADS_THROTTLED")
+	ErrImportEmpty                  = NewValidationError("At least one user is required").WithReason("IMPORT_EMPTY")
+	ErrOrganizationNotFound         = NewNotFoundError("Organization").WithReason("ORGANIZATION_NOT_FOUND")
+	ErrOrganizationExists           = NewConflictError("An organization with this name already exists").WithReason("ORGANIZATION_EXISTS")
+	ErrOrganizationMemberNotFound   = NewNotFoundError("Organization member").WithReason("ORGANIZATION_MEMBER_NOT_FOUND")
+	ErrOrganizationMemberExists     = NewConflictError("The user is already a member of this organization").WithReason("ORGANIZATION_MEMBER_EXISTS")
+	ErrOrganizationFull             = NewConflictError("The organization has no seats left").WithReason("ORGANIZATION_FULL")
+	ErrSeatLimitBelowUsage          = NewConflictError("The seat limit is lower than the seats already taken").WithReason("SEAT_LIMIT_BELOW_USAGE")
+	ErrLastOrganizationOwner        = NewConflictError("An organization must keep at least one owner").WithReason("LAST_ORGANIZATION_OWNER")
+	ErrOrganizationForbidden        = NewAuthorizationError("You are not allowed to manage this organization").WithReason("ORGANIZATION_FORBIDDEN")
+	ErrInvitationNotFound           = NewNotFoundError("Invitation").WithReason("INVITATION_NOT_FOUND")
+	ErrInvitationPending            = NewConflictError("An invitation is already pending for this email").WithReason("INVITATION_PENDING")
+	ErrInvitationNotPending         = NewConflictError("The invitation has already been accepted or revoked").WithReason("INVITATION_NOT_PENDING")
+	ErrInvalidInvitation            = NewValidationError("Invalid invitation link").WithReason("INVALID_INVITATION_TOKEN")
+	ErrInvitationExpired            = NewValidationError("The invitation has expired").WithReason("INVITATION_EXPIRED")
+	ErrInvitationForbidden          = NewAuthorizationError("You are not allowed to manage this invitation").WithReason("INVITATION_FORBIDDEN")
+	ErrAccessTokenNotFound          = NewNotFoundError("Access token").WithReason("ACCESS_TOKEN_NOT_FOUND")
+	ErrPushTokenNotFound            = NewNotFoundError("Push token").WithReason("PUSH_TOKEN_NOT_FOUND")
+	ErrPolicyNotFound               = NewNotFoundError("Policy").WithReason("POLICY_NOT_FOUND")
+	ErrPolicyVersionExists          = NewConflictError("This version of the policy has already been published").WithReason("POLICY_VERSION_EXISTS")
+	ErrPolicyVersionOutdated        = NewConflictError("A newer version of the policy has been published").WithReason("POLICY_VERSION_OUTDATED")
+	ErrInvalidAccessToken           = NewAuthenticationError("Invalid or expired access token").WithReason("INVALID_ACCESS_TOKEN")
+	ErrAccountMergeNotFound         = NewNotFoundError("Account merge").WithReason("ACCOUNT_MERGE_NOT_FOUND")
+	ErrAccountMergeSelf             = NewValidationError("An account cannot be merged into itself").WithReason("ACCOUNT_MERGE_SELF")
+	ErrAccountMergeForbidden        = NewAuthorizationError("Administrator accounts cannot be merged").WithReason("ACCOUNT_MERGE_FORBIDDEN")
+	ErrAccountMergeNotPending       = NewConflictError("The account merge has already been completed or cancelled").WithReason("ACCOUNT_MERGE_NOT_PENDING")
+	ErrAccountMergeExpired          = NewValidationError("The account merge codes have expired").WithReason("ACCOUNT_MERGE_EXPIRED")
+	ErrInvalidAccountMergeCode      = NewValidationError("Invalid verification code").WithReason("INVALID_ACCOUNT_MERGE_CODE")
+	ErrAccountMergeUnavailable      = NewConflictError("One of the accounts can no longer be merged").WithReason("ACCOUNT_MERGE_UNAVAILABLE")
+	ErrImpersonationForbidden       = NewAuthorizationError("You are not permitted to impersonate users").WithReason("IMPERSONATION_FORBIDDEN")
+	ErrImpersonationTargetForbidden = NewAuthorizationError("Administrators and your own account cannot be impersonated").WithReason("IMPERSONATION_TARGET_FORBIDDEN")
+	ErrImpersonationTargetInactive  = NewConflictError("Only active accounts can be impersonated").WithReason("IMPERSONATION_TARGET_INACTIVE")
+	ErrImpersonationNotFound        = NewNotFoundError("Impersonation session").WithReason("IMPERSONATION_NOT_FOUND")
+
+	ErrDatabaseConnection    = NewInternalError("Database connection failed").WithReason("DATABASE_CONNECTION_ERROR")
+	ErrCacheConnection       = NewInternalError("Cache connection failed").WithReason("CACHE_CONNECTION_ERROR")
+	ErrQueueConnection       = NewInternalError("Message queue connection failed").WithReason("QUEUE_CONNECTION_ERROR")
+	ErrEmailServiceFailed    = NewExternalServiceError("email", "Failed to send email").WithReason("EMAIL_SERVICE_FAILED")
+	ErrOTPDeliveryFailed     = NewExternalServiceError("otp", "Failed to deliver verification code").WithReason("OTP_DELIVERY_FAILED")
+	ErrAvatarStorageFailed   = NewExternalServiceError("storage", "Failed to store avatar").WithReason("AVATAR_STORAGE_FAILED")
+	ErrAvatarStorageDisabled = apperr.New(apperr.Unavailable, "Avatar uploads are not available").WithReason("AVATAR_STORAGE_DISABLED")
+)
+
+// NewAccountLockedError reports a temporary lockout after repeated failed logins. code is
+// "account_locked" or "ip_locked" depending on what tripped the lockout; unlockAt tells
+// the client when it may try again.
+func NewAccountLockedError(code string, unlockAt time.Time) *AppError {
+	return apperr.New(apperr.Locked, "Too many failed login attempts. Please try again later.").
+		WithReason("ACCOUNT_TEMPORARILY_LOCKED").
+		WithDetails(map[string]any{
+			"code":      code,
+			"unlock_at": unlockAt.UTC(),
+		})
 }
 
-func NewAuthorizationError(message string) *AppError {
-	return NewAppError(ErrorTypeAuthorization, message, http.StatusForbidden)
+// NewOTPThrottledError reports that a code was sent to the phone number too recently or
+// too often; retryAfter tells the client when it may request another.
+func NewOTPThrottledError(retryAfter time.Duration) *AppError {
+	return NewRateLimitError("Too many verification codes requested. Please try again later.").
+		WithReason("OTP_SEND_THROTTLED").
+		WithDetails(map[string]any{
+			"code":        "otp_send_throttled",
+			"retry_after": int(retryAfter.Seconds()) + 1,
+		})
 }
 
-func NewNotFoundError(resource string) *AppError {
-	return NewAppError(ErrorTypeNotFound, fmt.Sprintf("%s not found", resource), http.StatusNotFound)
+// NewWeakPasswordError reports a password that fails the password policy. violations
+// lists every rule it broke so the client can show them all at once.
+func NewWeakPasswordError(violations any) *AppError {
+	return NewValidationError("Password does not meet security requirements").
+		WithReason("WEAK_PASSWORD").
+		WithDetails(map[string]any{
+			"violations": violations,
+		})
 }
 
-func NewConflictError(message string) *AppError {
-	return NewAppError(ErrorTypeConflict, message, http.StatusConflict)
+// NewDataExportTooSoonError reports that the user already requested a data export
+// recently; retryAfter tells the client when it may request another.
+func NewDataExportTooSoonError(retryAfter time.Duration) *AppError {
+	return NewRateLimitError("A data export was requested recently. Please try again later.").
+		WithReason("DATA_EXPORT_TOO_SOON").
+		WithDetails(map[string]any{
+			"code":        "data_export_too_soon",
+			"retry_after": int(retryAfter.Seconds()) + 1,
+		})
 }
 
-func NewRateLimitError(message string) *AppError {
-	return NewAppError(ErrorTypeRateLimit, message, http.StatusTooManyRequests)
+// NewAvatarTooLargeError reports an avatar upload over the size limit.
+func NewAvatarTooLargeError(maxBytes int64) *AppError {
+	return NewValidationError("Image is too large").
+		WithReason("AVATAR_TOO_LARGE").
+		WithDetails(map[string]any{
+			"code":      "avatar_too_large",
+			"max_bytes": maxBytes,
+		})
 }
 
-func NewInternalError(message string) *AppError {
-	return NewAppError(ErrorTypeInternal, message, http.StatusInternalServerError)
+// NewInvalidAvatarError reports an uploaded avatar that is not an acceptable image;
+// reason tells the user what is wrong with it.
+func NewInvalidAvatarError(reason string) *AppError {
+	return NewValidationError("Image cannot be used as an avatar").
+		WithReason("INVALID_AVATAR_IMAGE").
+		WithDetails(map[string]any{
+			"code":   "invalid_avatar_image",
+			"reason": reason,
+		})
 }
 
-func NewExternalServiceError(service string, message string) *AppError {
-	return NewAppError(ErrorTypeExternal, fmt.Sprintf("%s service error: %s", service, message), http.StatusBadGateway)
+// NewImportTooLargeError reports a bulk import with more rows than allowed.
+func NewImportTooLargeError(maxRows int) *AppError {
+	return NewValidationError("Too many users in one import").
+		WithReason("IMPORT_TOO_LARGE").
+		WithDetails(map[string]any{
+			"code":     "import_too_large",
+			"max_rows": maxRows,
+		})
 }
 
-// Common application errors
-var (
-	ErrInvalidCredentials     = NewAuthenticationError("Invalid email or password").WithCode("INVALID_CREDENTIALS")
-	ErrEmailNotVerified       = NewAuthenticationError("Email address not verified").WithCode("EMAIL_NOT_VERIFIED")
-	ErrInvalidMFACode         = NewAuthenticationError("Invalid or expired MFA code").WithCode("INVALID_MFA_CODE")
-	ErrMFANotSetup           = NewAuthenticationError("MFA not setup for this account").WithCode("MFA_NOT_SETUP")
-	ErrSessionExpired        = NewAuthenticationError("Session has expired").WithCode("SESSION_EXPIRED")
-	ErrTokenInvalid          = NewAuthenticationError("Invalid authentication token").WithCode("TOKEN_INVALID")
-	ErrAccountLocked         = NewAuthenticationError("Account has been locked").WithCode("ACCOUNT_LOCKED")
-	ErrAccountDisabled       = NewAuthenticationError("Account has been disabled").WithCode("ACCOUNT_DISABLED")
-
-	ErrEmailExists           = NewConflictError("Email address already exists").WithCode("EMAIL_EXISTS")
-	ErrWeakPassword          = NewValidationError("Password does not meet security requirements").WithCode("WEAK_PASSWORD")
-	ErrInvalidEmail          = NewValidationError("Invalid email address format").WithCode("INVALID_EMAIL")
-	ErrPasswordMismatch      = NewValidationError("Passwords do not match").WithCode("PASSWORD_MISMATCH")
-	InvalidVerificationToken = NewValidationError("Invalid or expired verification token").WithCode("INVALID_VERIFICATION_TOKEN")
-	InvalidPasswordResetToken = NewValidationError("Invalid or expired password reset token").WithCode("INVALID_PASSWORD_RESET_TOKEN")
-
-	ErrUserNotFound          = NewNotFoundError("User").WithCode("USER_NOT_FOUND")
-	ErrSessionNotFound       = NewNotFoundError("Session").WithCode("SESSION_NOT_FOUND")
-	ErrMFAMethodNotFound     = NewNotFoundError("MFA method").WithCode("MFA_METHOD_NOT_FOUND")
-
-	ErrDatabaseConnection    = NewInternalError("Database connection failed").WithCode("DATABASE_CONNECTION_ERROR")
-	ErrCacheConnection       = NewInternalError("Cache connection failed").WithCode("CACHE_CONNECTION_ERROR")
-	ErrQueueConnection       = NewInternalError("Message queue connection failed").WithCode("QUEUE_CONNECTION_ERROR")
-	ErrEmailServiceFailed    = NewExternalServiceError("email", "Failed to send email").WithCode("EMAIL_SERVICE_FAILED")
-)
+// NewInvalidImportCSVError reports a bulk import CSV that cannot be read; line is the
+// line the problem was found on, or 0 when it is not tied to one.
+func NewInvalidImportCSVError(line int, reason string) *AppError {
+	return NewValidationError("Invalid CSV file").
+		WithReason("INVALID_IMPORT_CSV").
+		WithDetails(map[string]any{
+			"code":   "invalid_import_csv",
+			"line":   line,
+			"reason": reason,
+		})
+}
 
-// IsAppError checks if an error is an AppError
-func IsAppError(err error) bool {
-	_, ok := err.(*AppError)
-	return ok
+// NewInvitationResendTooSoonError reports a resend within the cooldown of the last
+// email; retryAfter tells the client when it may resend.
+func NewInvitationResendTooSoonError(retryAfter time.Duration) *AppError {
+	return NewRateLimitError("The invitation was sent recently. Please try again later.").
+		WithReason("INVITATION_RESEND_TOO_SOON").
+		WithDetails(map[string]any{
+			"code":        "invitation_resend_too_soon",
+			"retry_after": int(retryAfter.Seconds()) + 1,
+		})
 }
 
-// GetAppError converts any error to AppError, wrapping if necessary
-func GetAppError(err error) *AppError {
-	if appErr, ok := err.(*AppError); ok {
-		return appErr
-	}
-	return NewInternalError("An unexpected error occurred").WithCause(err)
+// NewAccountMergeTooSoonError reports a merge request within the cooldown of the
+// caller's last one.
+func NewAccountMergeTooSoonError(retryAfter time.Duration) *AppError {
+	return NewRateLimitError("An account merge was requested recently. Please try again later.").
+		WithReason("ACCOUNT_MERGE_TOO_SOON").
+		WithDetails(map[string]any{
+			"code":        "account_merge_too_soon",
+			"retry_after": int(retryAfter.Seconds()) + 1,
+		})
+}
+
+// NewPasswordlessThrottledError reports that a sign-in email was sent to the address too
+// recently; retryAfter tells the client when it may request another.
+func NewPasswordlessThrottledError(retryAfter time.Duration) *AppError {
+	return NewRateLimitError("A sign-in email was sent recently. Please try again later.").
+		WithReason("PASSWORDLESS_THROTTLED").
+		WithDetails(map[string]any{
+			"code":        "passwordless_throttled",
+			"retry_after": int(retryAfter.Seconds()) + 1,
+		})
+}
+
+// NewAccessTokenLimitError reports a user who already holds the most active personal
+// access tokens allowed.
+func NewAccessTokenLimitError(maxTokens int) *AppError {
+	return NewConflictError("Too many active access tokens; revoke one first").
+		WithReason("ACCESS_TOKEN_LIMIT").
+		WithDetails(map[string]any{
+			"code":       "access_token_limit",
+			"max_tokens": maxTokens,
+		})
 }
 
 // ErrorHandler middleware for Gin
@@ -166,7 +277,7 @@ func ErrorHandler() gin.HandlerFunc {
 		case string:
 			err = NewInternalError(e)
 		case error:
-			err = GetAppError(e)
+			err = e
 		default:
 			err = NewInternalError("An unexpected error occurred")
 		}
@@ -175,35 +286,25 @@ func ErrorHandler() gin.HandlerFunc {
 	})
 }
 
-// SendError sends a standardized error response
+// SendError sends a standardized error response. Errors that are not AppErrors are
+// reported as internal errors; internal errors are logged and their message is not
+// exposed to clients.
 func SendError(c *gin.Context, err error) {
-	appErr := GetAppError(err)
-
-	// Log internal errors with context
-	if appErr.Type == ErrorTypeInternal || appErr.Type == ErrorTypeExternal {
-		// In production, you'd want to use a proper logging framework
-		// with structured logging and correlation IDs
-		fmt.Printf("Internal Error: %+v\n", appErr)
-
-		// Don't expose internal error details to clients
-		if appErr.Type == ErrorTypeInternal {
-			appErr.Message = "An internal error occurred"
-			appErr.Details = nil
-		}
+	appErr := apperr.From(err)
+	if appErr.Internal() {
+		slog.ErrorContext(c.Request.Context(), "internal error",
+			"code", appErr.ErrorCode(), "message", appErr.Message, "error", appErr.Cause)
 	}
 
+	body := appErr.Public()
 	response := gin.H{
-		"status": "error",
-		"message": appErr.Message,
+		"status":     "error",
+		"message":    body.Message,
+		"error_code": body.Code,
 	}
-
-	if appErr.Code != "" {
-		response["error_code"] = appErr.Code
+	if body.Details != nil {
+		response["details"] = body.Details
 	}
 
-	if appErr.Details != nil {
-		response["details"] = appErr.Details
-	}
-
-	c.JSON(appErr.HTTPStatus, response)
-}
\ No newline at end of file
+	c.JSON(appErr.HTTPStatus(), response)
+}
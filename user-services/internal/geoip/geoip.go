@@ -0,0 +1,131 @@
+// Package geoip resolves IP addresses to the approximate location shown next to a
+// session, through an ip-api compatible HTTP endpoint.
+package geoip
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"user-services/internal/config"
+
+	"github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/ductan2/microservice-app/shared/telemetry"
+	"github.com/redis/go-redis/v9"
+)
+
+// LocalNetwork is the country reported for private and loopback addresses.
+const LocalNetwork = "Local network"
+
+// Location is the approximate location of an IP address.
+type Location struct {
+	City        string `json:"city,omitempty"`
+	Region      string `json:"region,omitempty"`
+	Country     string `json:"country,omitempty"`
+	CountryCode string `json:"country_code,omitempty"`
+}
+
+// Resolver looks up the location of an IP address. It returns nil when the address
+// cannot be located.
+type Resolver interface {
+	Lookup(ctx context.Context, ip string) (*Location, error)
+}
+
+// Client resolves locations with GET {baseURL}/{ip}, answered with the city, regionName,
+// country and countryCode fields of ip-api. Resolved locations are cached in Redis.
+type Client struct {
+	baseURL     string
+	httpClient  *http.Client
+	redisClient *redis.Client
+	cacheTTL    time.Duration
+}
+
+// NewClient creates a resolver for GEOIP_SERVICE_URL. An empty URL only resolves local
+// addresses; redisClient may be nil to disable caching.
+func NewClient(cfg config.GeoIPConfig, redisClient *redis.Client) *Client {
+	return &Client{
+		baseURL:     strings.TrimRight(cfg.ServiceURL, "/"),
+		httpClient:  &http.Client{Timeout: cfg.Timeout, Transport: metrics.NewTransport("geoip", telemetry.NewTransport(nil))},
+		redisClient: redisClient,
+		cacheTTL:    cfg.CacheTTL,
+	}
+}
+
+// Lookup returns the location of ip. Private and loopback addresses resolve to
+// LocalNetwork without calling the provider.
+func (c *Client) Lookup(ctx context.Context, ip string) (*Location, error) {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return nil, fmt.Errorf("geoip: invalid ip address %q", ip)
+	}
+	if parsed.IsLoopback() || parsed.IsPrivate() || parsed.IsLinkLocalUnicast() {
+		return &Location{Country: LocalNetwork}, nil
+	}
+	if c.baseURL == "" {
+		return nil, nil
+	}
+
+	cacheKey := fmt.Sprintf("geoip:%s", parsed.String())
+	if c.redisClient != nil {
+		if cached, err := c.redisClient.Get(ctx, cacheKey).Bytes(); err == nil {
+			var location Location
+			if err := json.Unmarshal(cached, &location); err == nil {
+				return &location, nil
+			}
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/"+url.PathEscape(parsed.String()), nil)
+	if err != nil {
+		return nil, fmt.Errorf("geoip: create request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("geoip: perform request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return nil, fmt.Errorf("geoip: read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("geoip: provider returned status %d", resp.StatusCode)
+	}
+
+	var payload struct {
+		Status      string `json:"status"`
+		City        string `json:"city"`
+		RegionName  string `json:"regionName"`
+		Country     string `json:"country"`
+		CountryCode string `json:"countryCode"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("geoip: decode response: %w", err)
+	}
+	if payload.Status != "" && payload.Status != "success" {
+		return nil, nil
+	}
+
+	location := &Location{
+		City:        payload.City,
+		Region:      payload.RegionName,
+		Country:     payload.Country,
+		CountryCode: payload.CountryCode,
+	}
+	if c.redisClient != nil && c.cacheTTL > 0 {
+		if data, err := json.Marshal(location); err == nil {
+			if err := c.redisClient.Set(ctx, cacheKey, data, c.cacheTTL).Err(); err != nil {
+				slog.WarnContext(ctx, "failed to cache geoip lookup", "error", err)
+			}
+		}
+	}
+	return location, nil
+}
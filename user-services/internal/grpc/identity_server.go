@@ -0,0 +1,140 @@
+package grpc
+
+import (
+	"context"
+
+	"user-services/internal/api/services"
+	"user-services/internal/grpc/identityv1"
+	"user-services/internal/models"
+
+	"github.com/ductan2/microservice-app/shared/batch"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxBatchUsers caps the IDs one BatchGetUsers call may ask for, as for the REST batch
+// endpoints.
+const maxBatchUsers = batch.MaxSize
+
+var sessionStatuses = map[string]identityv1.SessionStatus{
+	services.SessionStateActive:       identityv1.SessionStatus_SESSION_STATUS_ACTIVE,
+	services.SessionStateNotFound:     identityv1.SessionStatus_SESSION_STATUS_NOT_FOUND,
+	services.SessionStateRevoked:      identityv1.SessionStatus_SESSION_STATUS_REVOKED,
+	services.SessionStateExpired:      identityv1.SessionStatus_SESSION_STATUS_EXPIRED,
+	services.SessionStateUserMismatch: identityv1.SessionStatus_SESSION_STATUS_USER_MISMATCH,
+	services.SessionStateUserInactive: identityv1.SessionStatus_SESSION_STATUS_USER_INACTIVE,
+}
+
+// IdentityServer implements identity.v1.UserIdentityService.
+type IdentityServer struct {
+	identityv1.UnimplementedUserIdentityServiceServer
+
+	identityService services.IdentityService
+}
+
+func NewIdentityServer(identityService services.IdentityService) *IdentityServer {
+	return &IdentityServer{
+		identityService: identityService,
+	}
+}
+
+// Register adds the UserIdentityService methods to s.
+func (i *IdentityServer) Register(s *Server) {
+	identityv1.RegisterUserIdentityServiceServer(s, i)
+}
+
+func (i *IdentityServer) GetUserByID(ctx context.Context, req *identityv1.GetUserByIDRequest) (*identityv1.GetUserByIDResponse, error) {
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "user_id must be a UUID")
+	}
+
+	// ErrUserNotFound is reported as NOT_FOUND
+	user, err := i.identityService.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &identityv1.GetUserByIDResponse{User: toIdentityUser(user)}, nil
+}
+
+func (i *IdentityServer) BatchGetUsers(ctx context.Context, req *identityv1.BatchGetUsersRequest) (*identityv1.BatchGetUsersResponse, error) {
+	if len(req.GetUserIds()) > maxBatchUsers {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d user_ids may be requested at once", maxBatchUsers)
+	}
+
+	resp := &identityv1.BatchGetUsersResponse{}
+	requested := make(map[string]bool, len(req.GetUserIds()))
+	var userIDs []uuid.UUID
+	for _, id := range req.GetUserIds() {
+		if requested[id] {
+			continue
+		}
+		requested[id] = true
+		userID, err := uuid.Parse(id)
+		if err != nil {
+			resp.MissingUserIds = append(resp.MissingUserIds, id)
+			continue
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	users, err := i.identityService.GetUsers(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[uuid.UUID]bool, len(users))
+	for idx := range users {
+		found[users[idx].ID] = true
+		resp.Users = append(resp.Users, toIdentityUser(&users[idx]))
+	}
+	for _, userID := range userIDs {
+		if !found[userID] {
+			resp.MissingUserIds = append(resp.MissingUserIds, userID.String())
+		}
+	}
+	return resp, nil
+}
+
+func (i *IdentityServer) ValidateSession(ctx context.Context, req *identityv1.ValidateSessionRequest) (*identityv1.ValidateSessionResponse, error) {
+	sessionID, err := uuid.Parse(req.GetSessionId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "session_id must be a UUID")
+	}
+	var userID *uuid.UUID
+	if req.GetUserId() != "" {
+		parsed, err := uuid.Parse(req.GetUserId())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "user_id must be a UUID")
+		}
+		userID = &parsed
+	}
+
+	result, err := i.identityService.ValidateSession(ctx, sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &identityv1.ValidateSessionResponse{
+		Valid:  result.State == services.SessionStateActive,
+		Status: sessionStatuses[result.State],
+	}
+	if result.User != nil {
+		resp.User = toIdentityUser(result.User)
+		resp.ExpiresAt = timestamppb.New(result.ExpiresAt)
+	}
+	return resp, nil
+}
+
+func toIdentityUser(user *models.User) *identityv1.User {
+	return &identityv1.User{
+		Id:            user.ID.String(),
+		Email:         user.Email,
+		DisplayName:   user.Profile.DisplayName,
+		AvatarUrl:     user.Profile.AvatarURL,
+		Role:          user.Role,
+		Status:        user.Status,
+		EmailVerified: user.EmailVerified,
+	}
+}
@@ -0,0 +1,117 @@
+// Package grpc serves the internal gRPC API with the gRPC runtime and the service code
+// generated from proto/ by buf.
+package grpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ductan2/microservice-app/shared/apperr"
+	"github.com/ductan2/microservice-app/shared/internalauth"
+	"github.com/ductan2/microservice-app/shared/lifecycle"
+)
+
+// Server serves the services registered on it. Every call must present a service token
+// issued for user-services.
+type Server struct {
+	server   *gogrpc.Server
+	verifier *internalauth.Verifier
+}
+
+// NewServer creates a server that accepts calls authenticated by verifier.
+func NewServer(verifier *internalauth.Verifier) *Server {
+	s := &Server{verifier: verifier}
+	s.server = gogrpc.NewServer(
+		gogrpc.StatsHandler(otelgrpc.NewServerHandler()),
+		gogrpc.ChainUnaryInterceptor(s.authenticate, reportErrors),
+	)
+	return s
+}
+
+// RegisterService registers a service implementation, as the generated Register
+// functions do.
+func (s *Server) RegisterService(desc *gogrpc.ServiceDesc, impl any) {
+	s.server.RegisterService(desc, impl)
+}
+
+// Component serves s on addr. Stopping it stops accepting calls and waits for the calls
+// in flight, until the stop times out.
+func (s *Server) Component(name, addr string) lifecycle.Component {
+	return lifecycle.Component{
+		Name: name,
+		Run: func(context.Context) error {
+			lis, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+			if err := s.server.Serve(lis); !errors.Is(err, gogrpc.ErrServerStopped) {
+				return err
+			}
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			done := make(chan struct{})
+			go func() {
+				s.server.GracefulStop()
+				close(done)
+			}()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				s.server.Stop()
+				return ctx.Err()
+			}
+		},
+	}
+}
+
+// authenticate checks the service token of the call, like ServiceAuth does for the
+// internal REST routes. The token and the identity headers it binds travel as metadata.
+func (s *Server) authenticate(ctx context.Context, req any, info *gogrpc.UnaryServerInfo, handler gogrpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	header := make(http.Header, len(md))
+	for key, values := range md {
+		header[http.CanonicalHeaderKey(key)] = values
+	}
+
+	token := header.Get(internalauth.Header)
+	if token == "" {
+		return nil, status.Error(codes.Unauthenticated, "invalid internal service credentials")
+	}
+	if _, err := s.verifier.Verify(token, header); err != nil {
+		slog.WarnContext(ctx, "rejected service token", "error", err, "method", info.FullMethod)
+		return nil, status.Error(codes.Unauthenticated, "invalid internal service credentials")
+	}
+	return handler(ctx, req)
+}
+
+// reportErrors maps the errors of the handlers that are not gRPC statuses with apperr:
+// internal ones are logged and reported without their details.
+func reportErrors(ctx context.Context, req any, info *gogrpc.UnaryServerInfo, handler gogrpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	if err == nil {
+		return resp, nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return nil, err
+	}
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() != nil {
+		return nil, status.Error(codes.DeadlineExceeded, "deadline exceeded")
+	}
+
+	appErr := apperr.From(err)
+	if appErr.Internal() {
+		slog.ErrorContext(ctx, "gRPC handler error", "error", err, "method", info.FullMethod)
+	}
+	return nil, status.Error(codes.Code(appErr.GRPCCode()), appErr.Public().Message)
+}
@@ -0,0 +1,83 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ductan2/microservice-app/shared/apperr"
+	"github.com/ductan2/microservice-app/shared/internalauth"
+)
+
+func testAuthConfig(service string) internalauth.Config {
+	return internalauth.Config{Service: service, Keys: map[string][]byte{"test": []byte("identity-server-test-key-32-bytes")}, KeyID: "test"}
+}
+
+func TestAuthenticate(t *testing.T) {
+	verifier, err := internalauth.NewVerifier(testAuthConfig("user-services"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := internalauth.NewSigner(testAuthConfig("bff-services"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(verifier)
+	info := &gogrpc.UnaryServerInfo{FullMethod: "/identity.v1.UserIdentityService/GetUserByID"}
+	handler := func(context.Context, any) (any, error) { return "ok", nil }
+
+	sign := func(audience string, header http.Header) string {
+		token, err := signer.Sign(audience, header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	tests := []struct {
+		name string
+		md   metadata.MD
+		want codes.Code
+	}{
+		{"valid token", metadata.Pairs("x-service-token", sign("user-services", nil)), codes.OK},
+		{"identity bound", metadata.Pairs("x-service-token", sign("user-services", http.Header{"X-User-Id": {"u1"}}), "x-user-id", "u1"), codes.OK},
+		{"missing token", metadata.MD{}, codes.Unauthenticated},
+		{"other audience", metadata.Pairs("x-service-token", sign("order-services", nil)), codes.Unauthenticated},
+		{"identity changed", metadata.Pairs("x-service-token", sign("user-services", http.Header{"X-User-Id": {"u1"}}), "x-user-id", "u2"), codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+		_, err := s.authenticate(ctx, nil, info, handler)
+		if got := status.Code(err); got != tt.want {
+			t.Errorf("%s: code = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReportErrors(t *testing.T) {
+	info := &gogrpc.UnaryServerInfo{FullMethod: "/identity.v1.UserIdentityService/GetUserByID"}
+	tests := []struct {
+		name        string
+		err         error
+		wantCode    codes.Code
+		wantMessage string
+	}{
+		{"status", status.Error(codes.InvalidArgument, "user_id must be a UUID"), codes.InvalidArgument, "user_id must be a UUID"},
+		{"app error", apperr.New(apperr.NotFound, "user not found"), codes.NotFound, "user not found"},
+		{"internal error", errors.New("connection refused"), codes.Internal, "An internal error occurred"},
+	}
+	for _, tt := range tests {
+		_, err := reportErrors(context.Background(), nil, info, func(context.Context, any) (any, error) {
+			return nil, tt.err
+		})
+		got := status.Convert(err)
+		if got.Code() != tt.wantCode || got.Message() != tt.wantMessage {
+			t.Errorf("%s: status = %s %q, want %s %q", tt.name, got.Code(), got.Message(), tt.wantCode, tt.wantMessage)
+		}
+	}
+}
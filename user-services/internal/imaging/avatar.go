@@ -0,0 +1,113 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+
+	// Registered decoders; together with jpeg these are the accepted upload formats
+	_ "image/gif"
+	_ "image/png"
+)
+
+var (
+	// ErrUnsupportedFormat is returned for data that is not a JPEG, PNG or GIF image.
+	ErrUnsupportedFormat = errors.New("image must be a JPEG, PNG or GIF")
+	// ErrTooSmall is returned when either side of the image is under the minimum.
+	ErrTooSmall = errors.New("image is too small")
+	// ErrTooLarge is returned when either side of the image is over the maximum.
+	ErrTooLarge = errors.New("image dimensions are too large")
+)
+
+// jpegQuality is the quality avatars are re-encoded with.
+const jpegQuality = 85
+
+// Limits bounds the width and height of an accepted image, in pixels.
+type Limits struct {
+	MinDimension int
+	MaxDimension int
+}
+
+// SquareAvatar turns an uploaded image into a size x size JPEG: the largest centred
+// square is cropped out and scaled down, transparency is flattened onto white and only
+// the first frame of an animated GIF is kept. Re-encoding also drops any metadata the
+// original carried, such as EXIF location. Images smaller than size are not scaled up.
+func SquareAvatar(data []byte, size int, limits Limits) ([]byte, error) {
+	// The header is checked before decoding so an oversized image is never expanded in
+	// memory
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+	if format != "jpeg" && format != "png" && format != "gif" {
+		return nil, ErrUnsupportedFormat
+	}
+	if cfg.Width < limits.MinDimension || cfg.Height < limits.MinDimension {
+		return nil, ErrTooSmall
+	}
+	if cfg.Width > limits.MaxDimension || cfg.Height > limits.MaxDimension {
+		return nil, ErrTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+
+	bounds := src.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	crop := image.Rect(0, 0, side, side)
+	origin := image.Pt(bounds.Min.X+(bounds.Dx()-side)/2, bounds.Min.Y+(bounds.Dy()-side)/2)
+
+	square := image.NewRGBA(crop)
+	draw.Draw(square, crop, image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(square, crop, src, origin, draw.Over)
+
+	out := square
+	if side > size {
+		out = downscale(square, size)
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, out, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// downscale shrinks a square image to size x size, averaging the source pixels that
+// fall into each target pixel.
+func downscale(src *image.RGBA, size int) *image.RGBA {
+	side := src.Bounds().Dx()
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+
+	for y := 0; y < size; y++ {
+		y0, y1 := y*side/size, (y+1)*side/size
+		for x := 0; x < size; x++ {
+			x0, x1 := x*side/size, (x+1)*side/size
+
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				i := src.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += int(src.Pix[i])
+					g += int(src.Pix[i+1])
+					b += int(src.Pix[i+2])
+					a += int(src.Pix[i+3])
+					n++
+					i += 4
+				}
+			}
+
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,88 @@
+// Package metrics holds the families specific to the user service. They are registered
+// next to the HTTP, pool, queue and build families shared by every service.
+package metrics
+
+import (
+	"strconv"
+
+	sharedmetrics "github.com/ductan2/microservice-app/shared/metrics"
+	"github.com/ductan2/microservice-app/shared/outbox"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Default is the registry exposed on /metrics, shared with the common families.
+var Default = sharedmetrics.Default
+
+// QueueDepth is the shared queue_depth family; the outbox backlog is its "outbox" queue.
+var QueueDepth = sharedmetrics.QueueDepth
+
+var (
+	// AuthAttemptsTotal counts sign-in attempts by result (success or failure) and the
+	// reason recorded in login_attempts, such as invalid_credentials or success_passkey.
+	AuthAttemptsTotal = promauto.With(Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "user_service_auth_attempts_total",
+		Help: "Total sign-in attempts handled by the user service.",
+	}, []string{"result", "reason"})
+	// SessionCacheLookupsTotal counts Redis session lookups by result: hit, miss or error.
+	SessionCacheLookupsTotal = promauto.With(Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "user_service_session_cache_lookups_total",
+		Help: "Total session lookups in the Redis session cache.",
+	}, []string{"operation", "result"})
+	// DependencyUp is 1 when the last check of a dependency succeeded, 0 otherwise. It is
+	// refreshed on every scrape and readiness check.
+	DependencyUp = promauto.With(Default).NewGaugeVec(prometheus.GaugeOpts{
+		Name: "user_service_dependency_up",
+		Help: "Whether the last connectivity check of a dependency succeeded.",
+	}, []string{"dependency"})
+	// DependencyCheckDuration is how long the last check of a dependency took.
+	DependencyCheckDuration = promauto.With(Default).NewGaugeVec(prometheus.GaugeOpts{
+		Name: "user_service_dependency_check_duration_seconds",
+		Help: "Duration of the last connectivity check of a dependency.",
+	}, []string{"dependency"})
+	// OutboxPendingEvents, OutboxRetryingEvents, OutboxFailedEvents and OutboxLag describe
+	// the outbox backlog. They are refreshed on every scrape.
+	OutboxPendingEvents = promauto.With(Default).NewGauge(prometheus.GaugeOpts{
+		Name: "user_service_outbox_pending_events",
+		Help: "Outbox events waiting to be published, including those backing off after a failure.",
+	})
+	OutboxRetryingEvents = promauto.With(Default).NewGauge(prometheus.GaugeOpts{
+		Name: "user_service_outbox_retrying_events",
+		Help: "Pending outbox events that failed at least one publish.",
+	})
+	OutboxFailedEvents = promauto.With(Default).NewGauge(prometheus.GaugeOpts{
+		Name: "user_service_outbox_failed_events",
+		Help: "Outbox events parked after OUTBOX_MAX_ATTEMPTS failed publishes.",
+	})
+	OutboxLag = promauto.With(Default).NewGauge(prometheus.GaugeOpts{
+		Name: "user_service_outbox_lag_seconds",
+		Help: "Age of the oldest pending outbox event, 0 when none is pending.",
+	})
+	// OutboxPublishedTotal and OutboxPublishFailuresTotal count the publishes of the
+	// outbox relay by topic; parked is true for the failure that parks an event.
+	OutboxPublishedTotal = promauto.With(Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "user_service_outbox_published_total",
+		Help: "Outbox events published and confirmed by RabbitMQ.",
+	}, []string{"topic"})
+	OutboxPublishFailuresTotal = promauto.With(Default).NewCounterVec(prometheus.CounterOpts{
+		Name: "user_service_outbox_publish_failures_total",
+		Help: "Failed publishes of outbox events.",
+	}, []string{"topic", "parked"})
+)
+
+// OutboxRelay records the publishes of the outbox relay.
+type OutboxRelay struct{}
+
+func (OutboxRelay) Published(msg outbox.Message) {
+	OutboxPublishedTotal.WithLabelValues(msg.Topic).Inc()
+}
+
+func (OutboxRelay) Failed(msg outbox.Message, parked bool) {
+	OutboxPublishFailuresTotal.WithLabelValues(msg.Topic, strconv.FormatBool(parked)).Inc()
+}
+
+// Handler serves the default registry in the Prometheus text exposition format.
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(sharedmetrics.Handler())
+}
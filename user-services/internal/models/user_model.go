@@ -4,9 +4,13 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
+	"strings"
 	"time"
 
+	"github.com/ductan2/microservice-app/shared/softdelete/gormsoft"
+	"github.com/ductan2/microservice-app/shared/telemetry"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // JSONBMap is a custom type for handling JSONB columns in PostgreSQL
@@ -54,15 +58,21 @@ type User struct {
 	EmailVerified           bool         `gorm:"default:false;not null" json:"email_verified"`
 	EmailVerificationToken  string       `gorm:"type:text" json:"-"`
 	EmailVerificationExpiry sql.NullTime `gorm:"type:timestamptz" json:"-"`
-	Status                  string       `gorm:"type:text;default:'active';not null;check:status IN ('active','locked','disabled','deleted')" json:"status"`
+	Status                  string       `gorm:"type:text;default:'active';not null;check:status IN ('active','invited','locked','disabled','deleted')" json:"status"`
 	Role                    string       `gorm:"type:text;default:'student';not null;check:role IN ('student','teacher','admin','super-admin')" json:"role"`
 	CreatedAt               time.Time    `json:"created_at"`
 	UpdatedAt               time.Time    `json:"updated_at"`
 	Profile                 UserProfile  `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:UserID;references:ID" json:"profile"`
-	DeletedAt               sql.NullTime `gorm:"type:timestamptz" json:"deleted_at,omitempty"`
 	LastLoginAt             sql.NullTime `gorm:"type:timestamptz" json:"last_login_at,omitempty"`
 	LastLoginIP             *string      `gorm:"type:inet" json:"last_login_ip,omitempty"`
 	LockoutUntil            sql.NullTime `gorm:"type:timestamptz" json:"lockout_until,omitempty"`
+	OrganizationID          *uuid.UUID   `gorm:"type:uuid" json:"organization_id,omitempty"`
+	MergedIntoID            *uuid.UUID   `gorm:"type:uuid" json:"merged_into_id,omitempty"` // set on accounts merged into another
+	Username                *string      `gorm:"type:text" json:"username,omitempty"`       // lowercase; an alternative login
+	PhoneNumber             *string      `gorm:"type:text" json:"-"`                        // E.164, verified; an alternative login
+	PhoneVerifiedAt         sql.NullTime `gorm:"type:timestamptz" json:"phone_verified_at,omitempty"`
+	// deleted_at and deleted_by; Status is deleted too while they are set
+	gormsoft.Fields
 }
 
 const (
@@ -74,30 +84,74 @@ const (
 
 const (
 	StatusActive   = "active"
+	StatusInvited  = "invited" // imported, waiting for the user to accept the invitation
 	StatusLocked   = "locked"
 	StatusDisabled = "disabled"
 	StatusDeleted  = "deleted"
 )
 
+// Organization is a customer whose accounts are provisioned together, such as a school
+// or a company. Names are unique ignoring case. SeatLimit caps the learners it may have;
+// nil means unlimited.
+type Organization struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	Name      string    `gorm:"type:text;not null" json:"name"`
+	SeatLimit *int      `gorm:"type:integer" json:"seat_limit,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Organization membership roles. Owners manage the organization and all its members,
+// managers manage its learners, and only learners take up a seat.
+const (
+	OrgRoleOwner   = "owner"
+	OrgRoleManager = "manager"
+	OrgRoleLearner = "learner"
+)
+
+// OrganizationMember is a user's membership of an organization.
+type OrganizationMember struct {
+	OrganizationID uuid.UUID    `gorm:"type:uuid;primaryKey" json:"organization_id"`
+	UserID         uuid.UUID    `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Role           string       `gorm:"type:text;not null;check:role IN ('owner','manager','learner')" json:"role"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+	User           User         `gorm:"foreignKey:UserID;references:ID" json:"-"`
+	Organization   Organization `gorm:"foreignKey:OrganizationID;references:ID" json:"-"`
+}
+
 // UserProfile stores non-auth PII
 type UserProfile struct {
 	UserID      uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
 	DisplayName string    `gorm:"type:text" json:"display_name,omitempty"`
 	AvatarURL   string    `gorm:"type:text" json:"avatar_url,omitempty"`
+	AvatarKey   string    `gorm:"type:text;not null" json:"-"` // object store key; empty when AvatarURL was set by hand
 	Locale      string    `gorm:"type:text;default:'en'" json:"locale"`
 	TimeZone    string    `gorm:"type:text;default:'UTC'" json:"time_zone"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
-// Session represents server-side JWT tracking
+// Session represents server-side JWT tracking. ImpersonatorID is set on sessions an
+// administrator opened as the user for support; ImpersonationReason says why. DeviceType,
+// Browser and OS are parsed from UserAgent, and the Geo fields resolved from IPAddr, when
+// the session is created.
 type Session struct {
-	ID        uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
-	UserID    uuid.UUID    `gorm:"type:uuid;not null;index:sessions_user_expires_idx;constraint:OnDelete:CASCADE" json:"user_id"`
-	UserAgent string       `gorm:"type:text" json:"user_agent,omitempty"`
-	IPAddr    *string      `gorm:"type:inet" json:"ip_addr,omitempty"`
-	CreatedAt time.Time    `json:"created_at"`
-	ExpiresAt time.Time    `gorm:"not null;index:sessions_user_expires_idx" json:"expires_at"`
-	RevokedAt sql.NullTime `json:"revoked_at,omitempty"`
+	ID                  uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	UserID              uuid.UUID    `gorm:"type:uuid;not null;index:sessions_user_expires_idx;constraint:OnDelete:CASCADE" json:"user_id"`
+	UserAgent           string       `gorm:"type:text" json:"user_agent,omitempty"`
+	IPAddr              *string      `gorm:"type:inet" json:"ip_addr,omitempty"`
+	ImpersonatorID      *uuid.UUID   `gorm:"type:uuid" json:"impersonator_id,omitempty"`
+	ImpersonationReason string       `gorm:"type:text;not null;default:''" json:"impersonation_reason,omitempty"`
+	DeviceType          string       `gorm:"type:text;not null;default:''" json:"device_type,omitempty"`
+	Browser             string       `gorm:"type:text;not null;default:''" json:"browser,omitempty"`
+	OS                  string       `gorm:"column:os;type:text;not null;default:''" json:"os,omitempty"`
+	GeoCity             string       `gorm:"type:text;not null;default:''" json:"geo_city,omitempty"`
+	GeoRegion           string       `gorm:"type:text;not null;default:''" json:"geo_region,omitempty"`
+	GeoCountry          string       `gorm:"type:text;not null;default:''" json:"geo_country,omitempty"`
+	GeoCountryCode      string       `gorm:"type:text;not null;default:''" json:"geo_country_code,omitempty"`
+	CreatedAt           time.Time    `json:"created_at"`
+	ExpiresAt           time.Time    `gorm:"not null;index:sessions_user_expires_idx" json:"expires_at"`
+	RevokedAt           sql.NullTime `json:"revoked_at,omitempty"`
 }
 
 // RefreshToken implements rotating refresh tokens
@@ -111,18 +165,35 @@ type RefreshToken struct {
 	RevokedAt  sql.NullTime `json:"revoked_at,omitempty"`
 }
 
-// MFAMethod supports TOTP and WebAuthn
+// MFAMethod supports TOTP, WebAuthn and phone (SMS/voice) codes. For WebAuthn
+// credentials Label is the device nickname, WebAuthnPub the base64url COSE public key and
+// CredentialID the base64url credential ID. Phone methods only count once VerifiedAt is
+// set. Priority orders a user's methods, lowest first, to pick the primary and fallbacks.
 type MFAMethod struct {
-	ID          uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
-	UserID      uuid.UUID    `gorm:"type:uuid;not null;constraint:OnDelete:CASCADE" json:"user_id"`
-	Type        string       `gorm:"type:text;not null;check:type IN ('totp','webauthn')" json:"type"`
-	Label       string       `gorm:"type:text" json:"label,omitempty"`
-	Secret      string       `gorm:"type:text" json:"-"` // encrypted at rest
-	WebAuthnPub string       `gorm:"type:text" json:"-"`
-	AddedAt     time.Time    `gorm:"default:now();not null" json:"added_at"`
-	LastUsedAt  sql.NullTime `json:"last_used_at,omitempty"`
+	ID           uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	UserID       uuid.UUID    `gorm:"type:uuid;not null;constraint:OnDelete:CASCADE" json:"user_id"`
+	Type         string       `gorm:"type:text;not null;check:type IN ('totp','webauthn','phone')" json:"type"`
+	Label        string       `gorm:"type:text" json:"label,omitempty"`
+	Secret       string       `gorm:"type:text" json:"-"` // encrypted at rest
+	WebAuthnPub  string       `gorm:"type:text" json:"-"`
+	CredentialID *string      `gorm:"type:text;uniqueIndex:mfa_methods_credential_id_idx,where:credential_id IS NOT NULL" json:"-"`
+	SignCount    int64        `gorm:"default:0;not null" json:"-"`
+	Transports   string       `gorm:"type:text" json:"-"`
+	AAGUID       string       `gorm:"column:aaguid;type:text" json:"-"`
+	PhoneNumber  string       `gorm:"type:text" json:"-"` // E.164
+	OTPChannel   string       `gorm:"column:otp_channel;type:text" json:"-"`
+	VerifiedAt   sql.NullTime `gorm:"type:timestamptz" json:"verified_at,omitempty"`
+	Priority     int          `gorm:"default:0;not null" json:"priority"`
+	AddedAt      time.Time    `gorm:"default:now();not null" json:"added_at"`
+	LastUsedAt   sql.NullTime `json:"last_used_at,omitempty"`
 }
 
+const (
+	MFATypeTOTP     = "totp"
+	MFATypeWebAuthn = "webauthn"
+	MFATypePhone    = "phone"
+)
+
 // LoginAttempt tracks login attempts for throttling
 type LoginAttempt struct {
 	ID        int64      `gorm:"primaryKey;autoIncrement" json:"id"`
@@ -136,13 +207,79 @@ type LoginAttempt struct {
 
 // PasswordReset manages password reset flow
 type PasswordReset struct {
-	ID         uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
-	UserID     uuid.UUID    `gorm:"type:uuid;not null;constraint:OnDelete:CASCADE" json:"user_id"`
-	TokenHash  string       `gorm:"type:text;uniqueIndex;not null" json:"-"`
-	ExpiresAt  time.Time    `gorm:"not null" json:"expires_at"`
-	ConsumedAt sql.NullTime `json:"consumed_at,omitempty"`
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;constraint:OnDelete:CASCADE" json:"user_id"`
+	TokenHash string    `gorm:"type:text;uniqueIndex;not null" json:"-"`
+	// FingerprintHash binds the link to the browser and network that requested it; nil
+	// for invitation links
+	FingerprintHash *string      `gorm:"type:text" json:"-"`
+	FailedAttempts  int          `gorm:"not null;default:0" json:"failed_attempts"`
+	ExpiresAt       time.Time    `gorm:"not null" json:"expires_at"`
+	ConsumedAt      sql.NullTime `json:"consumed_at,omitempty"`
+	CreatedAt       time.Time    `gorm:"default:now();not null" json:"created_at"`
+}
+
+// Invitation lets someone without an account register with a role, and optionally an
+// organization membership, chosen by the inviter. NonceHash is the hash of the random
+// part of the latest emailed token.
+type Invitation struct {
+	ID             uuid.UUID     `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	Email          string        `gorm:"type:text;not null" json:"email"`
+	Role           string        `gorm:"type:text;not null" json:"role"`
+	OrganizationID *uuid.UUID    `gorm:"type:uuid" json:"organization_id,omitempty"`
+	OrgRole        *string       `gorm:"type:text" json:"org_role,omitempty"`
+	Status         string        `gorm:"type:text;not null;default:'pending'" json:"status"`
+	NonceHash      string        `gorm:"type:text;not null" json:"-"`
+	ExpiresAt      time.Time     `gorm:"not null" json:"expires_at"`
+	InvitedBy      *uuid.UUID    `gorm:"type:uuid" json:"invited_by,omitempty"`
+	SentCount      int           `gorm:"not null;default:1" json:"sent_count"`
+	LastSentAt     time.Time     `gorm:"not null" json:"last_sent_at"`
+	AcceptedUserID *uuid.UUID    `gorm:"type:uuid" json:"accepted_user_id,omitempty"`
+	AcceptedAt     sql.NullTime  `gorm:"type:timestamptz" json:"accepted_at,omitempty"`
+	RevokedAt      sql.NullTime  `gorm:"type:timestamptz" json:"revoked_at,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+	Organization   *Organization `gorm:"foreignKey:OrganizationID;references:ID" json:"-"`
 }
 
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusRevoked  = "revoked"
+	// InvitationStatusExpired is reported for pending invitations past their expiry; it
+	// is never stored
+	InvitationStatusExpired = "expired"
+)
+
+// AccountMerge merges a user's duplicate account (the source) into the one they are
+// signed in to (the target) once the codes emailed to both accounts are entered. The
+// code hashes are HMACs; Report counts the rows moved per table.
+type AccountMerge struct {
+	ID             uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	TargetUserID   uuid.UUID    `gorm:"type:uuid;not null" json:"target_user_id"`
+	SourceUserID   uuid.UUID    `gorm:"type:uuid;not null" json:"source_user_id"`
+	SourceEmail    string       `gorm:"type:text;not null" json:"source_email"`
+	Status         string       `gorm:"type:text;not null;default:'pending'" json:"status"`
+	TargetCodeHash string       `gorm:"type:text;not null" json:"-"`
+	SourceCodeHash string       `gorm:"type:text;not null" json:"-"`
+	Attempts       int          `gorm:"not null;default:0" json:"attempts"`
+	ExpiresAt      time.Time    `gorm:"not null" json:"expires_at"`
+	Report         JSONBMap     `gorm:"type:jsonb;default:'{}';not null" json:"report"`
+	CompletedAt    sql.NullTime `gorm:"type:timestamptz" json:"completed_at,omitempty"`
+	CancelledAt    sql.NullTime `gorm:"type:timestamptz" json:"cancelled_at,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+const (
+	AccountMergeStatusPending   = "pending"
+	AccountMergeStatusCompleted = "completed"
+	AccountMergeStatusCancelled = "cancelled"
+	// AccountMergeStatusExpired is reported for pending merges past their expiry; it is
+	// never stored
+	AccountMergeStatusExpired = "expired"
+)
+
 // AuditLog append-only audit trail
 type AuditLog struct {
 	ID        int64      `gorm:"primaryKey;autoIncrement" json:"id"`
@@ -150,6 +287,7 @@ type AuditLog struct {
 	ActorID   *uuid.UUID `gorm:"type:uuid" json:"actor_id,omitempty"`
 	Action    string     `gorm:"type:text;not null" json:"action"`
 	IPAddr    *string    `gorm:"type:inet" json:"ip_addr,omitempty"`
+	UserAgent *string    `gorm:"type:text" json:"user_agent,omitempty"`
 	Metadata  JSONBMap   `gorm:"type:jsonb;default:'{}';not null" json:"metadata"`
 	CreatedAt time.Time  `gorm:"default:now();not null;index:audit_logs_user_time_idx" json:"created_at"`
 }
@@ -168,6 +306,95 @@ type UserActivitySession struct {
 	UpdatedAt  time.Time    `gorm:"default:now();not null" json:"updated_at"`
 }
 
+// PersonalAccessToken is a long-lived API token a user created for scripts and
+// integrations. Only the hash of the token is stored; Scopes is space-separated.
+type PersonalAccessToken struct {
+	ID         uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	UserID     uuid.UUID    `gorm:"type:uuid;not null" json:"user_id"`
+	Name       string       `gorm:"type:text;not null" json:"name"`
+	Prefix     string       `gorm:"type:text;not null" json:"prefix"`
+	TokenHash  string       `gorm:"type:text;not null;uniqueIndex" json:"-"`
+	Scopes     string       `gorm:"type:text;not null" json:"scopes"`
+	ExpiresAt  sql.NullTime `gorm:"type:timestamptz" json:"expires_at,omitempty"`
+	LastUsedAt sql.NullTime `gorm:"type:timestamptz" json:"last_used_at,omitempty"`
+	LastUsedIP *string      `gorm:"type:inet" json:"last_used_ip,omitempty"`
+	RevokedAt  sql.NullTime `gorm:"type:timestamptz" json:"revoked_at,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+// PushToken is a device token the notification service pushes to through FCM or APNs.
+// A token belongs to one account at a time; UpdatedAt is when it was last registered.
+type PushToken struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
+	Provider    string    `gorm:"type:text;not null;check:provider IN ('fcm','apns')" json:"provider"`
+	Token       string    `gorm:"type:text;not null" json:"-"`
+	Platform    string    `gorm:"type:text;not null;check:platform IN ('ios','android','web')" json:"platform"`
+	AppID       string    `gorm:"type:text;not null;default:''" json:"app_id"`
+	AppVersion  string    `gorm:"type:text;not null;default:''" json:"app_version"`
+	OSVersion   string    `gorm:"type:text;not null;default:''" json:"os_version"`
+	DeviceModel string    `gorm:"type:text;not null;default:''" json:"device_model"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Push providers
+const (
+	PushProviderFCM  = "fcm"
+	PushProviderAPNs = "apns"
+)
+
+// PolicyVersion is a published version of the terms of service or privacy policy. The
+// current version of a document is the most recently published one; RequiresConsent
+// asks every user to accept it again.
+type PolicyVersion struct {
+	ID              uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	Document        string     `gorm:"type:text;not null;check:document IN ('terms','privacy')" json:"document"`
+	Version         string     `gorm:"type:text;not null" json:"version"`
+	URL             string     `gorm:"type:text;not null" json:"url"`
+	Summary         string     `gorm:"type:text;not null;default:''" json:"summary"`
+	RequiresConsent bool       `gorm:"not null;default:true" json:"requires_consent"`
+	PublishedBy     *uuid.UUID `gorm:"type:uuid" json:"published_by,omitempty"`
+	PublishedAt     time.Time  `gorm:"not null" json:"published_at"`
+}
+
+// UserConsent records a user accepting a version of a policy document. Rows are never
+// updated; the latest per document is the version the user agreed to.
+type UserConsent struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
+	Document   string    `gorm:"type:text;not null;check:document IN ('terms','privacy')" json:"document"`
+	Version    string    `gorm:"type:text;not null" json:"version"`
+	AcceptedAt time.Time `gorm:"not null" json:"accepted_at"`
+	IPAddr     *string   `gorm:"type:inet" json:"ip_addr,omitempty"`
+	UserAgent  string    `gorm:"type:text;not null;default:''" json:"user_agent"`
+}
+
+// Policy documents users consent to
+const (
+	PolicyTerms   = "terms"
+	PolicyPrivacy = "privacy"
+)
+
+// PolicyDocuments lists every policy document, in the order they are shown.
+var PolicyDocuments = []string{PolicyTerms, PolicyPrivacy}
+
+// Scopes a personal access token can be granted. Write implies read.
+const (
+	AccessTokenScopeRead  = "read"
+	AccessTokenScopeWrite = "write"
+)
+
+// ScopeList returns the token's scopes.
+func (t *PersonalAccessToken) ScopeList() []string {
+	return strings.Fields(t.Scopes)
+}
+
+// Active reports whether the token can still authenticate requests at now.
+func (t *PersonalAccessToken) Active(now time.Time) bool {
+	return !t.RevokedAt.Valid && (!t.ExpiresAt.Valid || now.Before(t.ExpiresAt.Time))
+}
+
 // Outbox for cross-service events (transactional outbox pattern)
 type Outbox struct {
 	ID          int64        `gorm:"primaryKey;autoIncrement" json:"id"`
@@ -176,9 +403,230 @@ type Outbox struct {
 	Type        string       `gorm:"type:text;not null" json:"type"`
 	Payload     []byte       `gorm:"type:jsonb"`
 	CreatedAt   time.Time    `gorm:"default:now();not null" json:"created_at"`
-	PublishedAt sql.NullTime `gorm:"index:outbox_unpublished_idx,where:published_at IS NULL" json:"published_at,omitempty"`
+	PublishedAt sql.NullTime `json:"published_at,omitempty"`
+	// Attempts counts failed publishes; the event is retried from NextAttemptAt on and
+	// parked with FailedAt set once OUTBOX_MAX_ATTEMPTS is reached
+	Attempts      int          `gorm:"default:0;not null" json:"attempts"`
+	LastError     string       `gorm:"type:text" json:"last_error,omitempty"`
+	NextAttemptAt time.Time    `gorm:"default:now();not null" json:"next_attempt_at"`
+	FailedAt      sql.NullTime `json:"failed_at,omitempty"`
+	// TraceParent continues the trace of the request that wrote the event when it is
+	// published
+	TraceParent string `gorm:"type:text;not null;default:''" json:"-"`
 }
 
 func (Outbox) TableName() string {
 	return "outbox"
 }
+
+// BeforeCreate records the trace of the request writing the event.
+func (o *Outbox) BeforeCreate(tx *gorm.DB) error {
+	if o.TraceParent == "" {
+		o.TraceParent = telemetry.Traceparent(tx.Statement.Context)
+	}
+	return nil
+}
+
+// DataExport is a self-service takeout job. FilePath points at the zip archive once the
+// job is ready; the archive is deleted and the job marked expired after ExpiresAt. While
+// the job is building, CollectDeadline is the end of the builder's lease.
+type DataExport struct {
+	ID              uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	UserID          uuid.UUID    `gorm:"type:uuid;not null;constraint:OnDelete:CASCADE" json:"user_id"`
+	Status          string       `gorm:"type:text;default:'collecting';not null;check:status IN ('collecting','building','ready','failed','expired')" json:"status"`
+	FilePath        string       `gorm:"type:text" json:"-"`
+	SizeBytes       int64        `gorm:"default:0;not null" json:"size_bytes"`
+	Error           string       `gorm:"type:text" json:"error,omitempty"`
+	RequestedAt     time.Time    `gorm:"default:now();not null" json:"requested_at"`
+	CollectDeadline time.Time    `gorm:"not null" json:"collect_deadline"`
+	CompletedAt     sql.NullTime `gorm:"type:timestamptz" json:"completed_at,omitempty"`
+	ExpiresAt       sql.NullTime `gorm:"type:timestamptz" json:"expires_at,omitempty"`
+}
+
+const (
+	DataExportStatusCollecting = "collecting"
+	DataExportStatusBuilding   = "building"
+	DataExportStatusReady      = "ready"
+	DataExportStatusFailed     = "failed"
+	DataExportStatusExpired    = "expired"
+)
+
+// DataExportPart is one other service's share of a data export. The row is created with
+// the job and filled in when the service posts its data back.
+type DataExportPart struct {
+	ExportID   uuid.UUID    `gorm:"type:uuid;primaryKey" json:"export_id"`
+	Source     string       `gorm:"type:text;primaryKey" json:"source"`
+	Payload    []byte       `gorm:"type:jsonb" json:"-"`
+	ReceivedAt sql.NullTime `gorm:"type:timestamptz" json:"received_at,omitempty"`
+}
+
+// ErasureRequest schedules the anonymization of an account (right to erasure). It waits
+// until ScheduledFor, during which restoring the account cancels it, as does signing in
+// when its Kind is deactivation. LocalReport records how many rows were anonymized in
+// each of this service's tables.
+type ErasureRequest struct {
+	ID           uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	UserID       uuid.UUID    `gorm:"type:uuid;not null" json:"user_id"`
+	Kind         string       `gorm:"type:text;default:'erasure';not null;check:kind IN ('erasure','deactivation')" json:"kind"`
+	Status       string       `gorm:"type:text;default:'scheduled';not null;check:status IN ('scheduled','cancelled','anonymized','completed','incomplete')" json:"status"`
+	Reason       string       `gorm:"type:text" json:"reason,omitempty"`
+	RequestedBy  *uuid.UUID   `gorm:"type:uuid" json:"requested_by,omitempty"`
+	RequestedAt  time.Time    `gorm:"default:now();not null" json:"requested_at"`
+	ScheduledFor time.Time    `gorm:"not null" json:"scheduled_for"`
+	AnonymizedAt sql.NullTime `gorm:"type:timestamptz" json:"anonymized_at,omitempty"`
+	AckDeadline  sql.NullTime `gorm:"type:timestamptz" json:"ack_deadline,omitempty"`
+	CompletedAt  sql.NullTime `gorm:"type:timestamptz" json:"completed_at,omitempty"`
+	CancelledAt  sql.NullTime `gorm:"type:timestamptz" json:"cancelled_at,omitempty"`
+	LocalReport  JSONBMap     `gorm:"type:jsonb;default:'{}';not null" json:"local_report"`
+}
+
+const (
+	ErasureStatusScheduled  = "scheduled"
+	ErasureStatusCancelled  = "cancelled"
+	ErasureStatusAnonymized = "anonymized"
+	ErasureStatusCompleted  = "completed"
+	ErasureStatusIncomplete = "incomplete"
+)
+
+// A deactivation is an erasure its owner can cancel by signing in before it is due
+const (
+	ErasureKindErasure      = "erasure"
+	ErasureKindDeactivation = "deactivation"
+)
+
+// ErasureAck is another service's confirmation that it scrubbed its copies of an erased
+// user's data. The row is created with Status empty when the event is published.
+type ErasureAck struct {
+	RequestID       uuid.UUID    `gorm:"type:uuid;primaryKey" json:"request_id"`
+	Service         string       `gorm:"type:text;primaryKey" json:"service"`
+	Status          string       `gorm:"type:text" json:"status,omitempty"`
+	RecordsAffected int64        `gorm:"default:0;not null" json:"records_affected"`
+	Detail          string       `gorm:"type:text" json:"detail,omitempty"`
+	AcknowledgedAt  sql.NullTime `gorm:"type:timestamptz" json:"acknowledged_at,omitempty"`
+}
+
+const (
+	ErasureAckCompleted = "completed"
+	ErasureAckFailed    = "failed"
+)
+
+// UserPreferences holds a user's notification channels, UI theme and learning goals.
+// Locale and time zone live on UserProfile. ReminderTime is "HH:MM" in the user's time
+// zone, empty when reminders are off; ReminderDays is a comma-separated list of mon..sun.
+// Version increases with every change.
+type UserPreferences struct {
+	UserID            uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Theme             string    `gorm:"type:text;not null;check:theme IN ('system','light','dark')" json:"theme"`
+	NotifyEmail       bool      `gorm:"not null" json:"notify_email"`
+	NotifyPush        bool      `gorm:"not null" json:"notify_push"`
+	NotifySMS         bool      `gorm:"column:notify_sms;not null" json:"notify_sms"`
+	NotifyInApp       bool      `gorm:"not null" json:"notify_in_app"`
+	DailyGoalMinutes  int       `gorm:"not null" json:"daily_goal_minutes"`
+	WeeklyGoalLessons int       `gorm:"not null" json:"weekly_goal_lessons"`
+	ReminderTime      string    `gorm:"type:text;not null" json:"reminder_time"`
+	ReminderDays      string    `gorm:"type:text;not null" json:"reminder_days"`
+	Version           int64     `gorm:"not null" json:"version"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// DefaultUserPreferences returns the preferences of a user who never changed them; they
+// match the column defaults.
+func DefaultUserPreferences(userID uuid.UUID) UserPreferences {
+	return UserPreferences{
+		UserID:            userID,
+		Theme:             ThemeSystem,
+		NotifyEmail:       true,
+		NotifyPush:        true,
+		NotifyInApp:       true,
+		DailyGoalMinutes:  15,
+		WeeklyGoalLessons: 3,
+		ReminderDays:      "mon,tue,wed,thu,fri,sat,sun",
+	}
+}
+
+const (
+	ThemeSystem = "system"
+	ThemeLight  = "light"
+	ThemeDark   = "dark"
+)
+
+// OnboardingStep records a first-run step that is no longer pending. Status is
+// completed or skipped; Source is "user", "system" or the name of the reporting service.
+type OnboardingStep struct {
+	UserID      uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Step        string    `gorm:"type:text;primaryKey" json:"step"`
+	Status      string    `gorm:"type:text;not null;check:status IN ('completed','skipped')" json:"status"`
+	Source      string    `gorm:"type:text;not null" json:"source"`
+	CompletedAt time.Time `gorm:"not null" json:"completed_at"`
+}
+
+const (
+	OnboardingStepEmailVerified      = "email_verified"
+	OnboardingStepProfileCompleted   = "profile_completed"
+	OnboardingStepLevelTestTaken     = "level_test_taken"
+	OnboardingStepFirstLessonStarted = "first_lesson_started"
+)
+
+// OnboardingSteps lists the onboarding steps in the order the app walks through them.
+var OnboardingSteps = []string{
+	OnboardingStepEmailVerified,
+	OnboardingStepProfileCompleted,
+	OnboardingStepLevelTestTaken,
+	OnboardingStepFirstLessonStarted,
+}
+
+const (
+	OnboardingStatusPending    = "pending" // never stored
+	OnboardingStatusCompleted  = "completed"
+	OnboardingStatusSkipped    = "skipped"
+	OnboardingStatusInProgress = "in_progress" // never stored; overall state before every step is done
+)
+
+// AvatarUpload is an avatar image the client uploads to the object store with a
+// presigned URL. ObjectKey is the staging key the image was uploaded to.
+type AvatarUpload struct {
+	ID          uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	UserID      uuid.UUID    `gorm:"type:uuid;not null;constraint:OnDelete:CASCADE" json:"user_id"`
+	ObjectKey   string       `gorm:"type:text;not null" json:"-"`
+	ContentType string       `gorm:"type:text;not null" json:"content_type"`
+	SizeBytes   int64        `gorm:"not null" json:"size_bytes"`
+	Status      string       `gorm:"type:text;default:'pending';not null" json:"status"`
+	Error       string       `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	ExpiresAt   time.Time    `gorm:"not null" json:"expires_at"`
+	CompletedAt sql.NullTime `gorm:"type:timestamptz" json:"completed_at,omitempty"`
+}
+
+const (
+	AvatarUploadStatusPending   = "pending"
+	AvatarUploadStatusCompleted = "completed"
+	AvatarUploadStatusFailed    = "failed"
+	AvatarUploadStatusExpired   = "expired"
+)
+
+// AvatarDeletion is an object store key queued for deletion.
+type AvatarDeletion struct {
+	ObjectKey string    `gorm:"type:text;primaryKey" json:"object_key"`
+	Reason    string    `gorm:"type:text;not null" json:"reason"`
+	Attempts  int       `gorm:"not null" json:"attempts"`
+	LastError string    `gorm:"type:text" json:"last_error,omitempty"`
+	NotBefore time.Time `gorm:"not null" json:"not_before"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ActivityRollup is a user's study time over one UTC day or ISO week, aggregated from
+// their ended activity sessions. PeriodStart is the day, or the Monday of the week.
+type ActivityRollup struct {
+	UserID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Period          string    `gorm:"type:text;primaryKey" json:"period"`
+	PeriodStart     time.Time `gorm:"type:date;primaryKey" json:"period_start"`
+	SessionCount    int       `gorm:"not null" json:"session_count"`
+	TotalDurationMs int64     `gorm:"not null" json:"total_duration_ms"`
+	ActiveDays      int       `gorm:"not null" json:"active_days"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+const (
+	ActivityRollupDay  = "day"
+	ActivityRollupWeek = "week"
+)
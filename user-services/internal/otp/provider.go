@@ -0,0 +1,88 @@
+// Package otp delivers one-time codes by SMS or voice call for phone-based MFA and
+// phone number verification. Providers only transport the message; generating,
+// hashing and checking codes is left to the caller.
+package otp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"user-services/internal/config"
+
+	"github.com/ductan2/microservice-app/shared/metrics"
+)
+
+// Delivery channels
+const (
+	ChannelSMS   = "sms"
+	ChannelVoice = "voice"
+)
+
+var (
+	// ErrChannelUnsupported is returned when a provider cannot deliver on a channel.
+	ErrChannelUnsupported = errors.New("otp: channel not supported by provider")
+	// ErrDeliveryFailed wraps errors reported by the provider's API.
+	ErrDeliveryFailed = errors.New("otp: delivery failed")
+)
+
+// Message is a code to deliver. Text is the SMS body; voice calls read Code out digit
+// by digit instead.
+type Message struct {
+	To      string // E.164 phone number
+	Channel string
+	Code    string
+	Text    string
+}
+
+// Provider sends one-time codes through an SMS/voice gateway.
+type Provider interface {
+	Name() string
+	Supports(channel string) bool
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewProvider builds the provider selected by OTP_PROVIDER.
+func NewProvider(cfg config.OTPConfig) (Provider, error) {
+	client := &http.Client{Timeout: 10 * time.Second, Transport: metrics.NewTransport(cfg.Provider, nil)}
+
+	switch cfg.Provider {
+	case "", "log":
+		return NewLogProvider(), nil
+	case "twilio":
+		if cfg.TwilioAccountSID == "" || cfg.TwilioAuthToken == "" || cfg.TwilioFromNumber == "" {
+			return nil, fmt.Errorf("otp: twilio requires OTP_TWILIO_ACCOUNT_SID, OTP_TWILIO_AUTH_TOKEN and OTP_TWILIO_FROM_NUMBER")
+		}
+		return NewTwilioProvider(cfg.TwilioAccountSID, cfg.TwilioAuthToken, cfg.TwilioFromNumber, client), nil
+	case "sns":
+		if cfg.SNSRegion == "" {
+			return nil, fmt.Errorf("otp: sns requires OTP_SNS_REGION")
+		}
+		return NewSNSProvider(context.Background(), cfg.SNSRegion, cfg.SNSAccessKeyID, cfg.SNSSecretAccessKey, cfg.SNSSenderID, client)
+	default:
+		return nil, fmt.Errorf("otp: unknown provider %q", cfg.Provider)
+	}
+}
+
+// LogProvider prints codes to stdout instead of sending them. It is meant for local
+// development only.
+type LogProvider struct{}
+
+// NewLogProvider creates a provider that logs codes
+func NewLogProvider() *LogProvider {
+	return &LogProvider{}
+}
+
+func (p *LogProvider) Name() string { return "log" }
+
+func (p *LogProvider) Supports(channel string) bool {
+	return channel == ChannelSMS || channel == ChannelVoice
+}
+
+func (p *LogProvider) Send(ctx context.Context, msg Message) error {
+	slog.InfoContext(ctx, "OTP code", "channel", msg.Channel, "to", msg.To, "code", msg.Code)
+	return nil
+}
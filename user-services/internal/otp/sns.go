@@ -0,0 +1,69 @@
+package otp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+)
+
+// SNSProvider sends SMS codes with the Amazon SNS Publish API. SNS cannot place voice
+// calls.
+type SNSProvider struct {
+	client   *sns.Client
+	senderID string
+}
+
+// NewSNSProvider creates an SNS provider. Credentials come from the SDK's default chain,
+// such as the ECS task role or the web identity token of an EKS service account, unless
+// accessKeyID and secretAccessKey are set. senderID is optional and only honoured in
+// countries that support alphanumeric sender IDs.
+func NewSNSProvider(ctx context.Context, region, accessKeyID, secretAccessKey, senderID string, client *http.Client) (*SNSProvider, error) {
+	opts := []func(*awsconfig.LoadOptions) error{awsconfig.WithRegion(region)}
+	if client != nil {
+		opts = append(opts, awsconfig.WithHTTPClient(client))
+	}
+	if accessKeyID != "" {
+		opts = append(opts, awsconfig.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, "")))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("otp: sns: %w", err)
+	}
+	return &SNSProvider{client: sns.NewFromConfig(awsCfg), senderID: senderID}, nil
+}
+
+func (p *SNSProvider) Name() string { return "sns" }
+
+func (p *SNSProvider) Supports(channel string) bool {
+	return channel == ChannelSMS
+}
+
+func (p *SNSProvider) Send(ctx context.Context, msg Message) error {
+	if msg.Channel != ChannelSMS {
+		return ErrChannelUnsupported
+	}
+
+	attributes := map[string]types.MessageAttributeValue{
+		"AWS.SNS.SMS.SMSType": {DataType: aws.String("String"), StringValue: aws.String("Transactional")},
+	}
+	if p.senderID != "" {
+		attributes["AWS.SNS.SMS.SenderID"] = types.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(p.senderID)}
+	}
+
+	_, err := p.client.Publish(ctx, &sns.PublishInput{
+		PhoneNumber:       aws.String(msg.To),
+		Message:           aws.String(msg.Text),
+		MessageAttributes: attributes,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDeliveryFailed, err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package otp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// newTestSNS returns an SNS provider sending to handler with static credentials, hiding
+// the AWS variables and files of the machine running the tests from the SDK.
+func newTestSNS(t *testing.T, senderID string, handler http.HandlerFunc) *SNSProvider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	for _, name := range []string{"AWS_CA_BUNDLE", "AWS_PROFILE", "AWS_ENDPOINT_URL"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+	t.Setenv("AWS_ENDPOINT_URL_SNS", server.URL)
+
+	p, err := NewSNSProvider(context.Background(), "eu-west-1", "AKID", "secret", senderID, server.Client())
+	if err != nil {
+		t.Fatalf("NewSNSProvider: %v", err)
+	}
+	return p
+}
+
+func TestSNSProviderSend(t *testing.T) {
+	var form map[string]string
+	p := newTestSNS(t, "MyApp", func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "/eu-west-1/sns/aws4_request") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_ = r.ParseForm()
+		form = map[string]string{}
+		for name := range r.PostForm {
+			form[name] = r.PostForm.Get(name)
+		}
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(`<PublishResponse xmlns="http://sns.amazonaws.com/doc/2010-03-31/"><PublishResult><MessageId>1</MessageId></PublishResult></PublishResponse>`))
+	})
+
+	err := p.Send(context.Background(), Message{To: "+84901234589", Channel: ChannelSMS, Code: "123456", Text: "Your code is 123456"})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if form["Action"] != "Publish" || form["PhoneNumber"] != "+84901234589" || form["Message"] != "Your code is 123456" {
+		t.Fatalf("request = %v", form)
+	}
+	attributes := map[string]string{}
+	for i := 1; form["MessageAttributes.entry."+strconv.Itoa(i)+".Name"] != ""; i++ {
+		entry := "MessageAttributes.entry." + strconv.Itoa(i)
+		attributes[form[entry+".Name"]] = form[entry+".Value.StringValue"]
+	}
+	if attributes["AWS.SNS.SMS.SMSType"] != "Transactional" || attributes["AWS.SNS.SMS.SenderID"] != "MyApp" {
+		t.Fatalf("message attributes = %v", attributes)
+	}
+}
+
+func TestSNSProviderErrors(t *testing.T) {
+	p := newTestSNS(t, "", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`<ErrorResponse><Error><Type>Sender</Type><Code>InvalidParameter</Code><Message>Invalid parameter: PhoneNumber</Message></Error></ErrorResponse>`))
+	})
+
+	if err := p.Send(context.Background(), Message{To: "+1", Channel: ChannelSMS}); !errors.Is(err, ErrDeliveryFailed) {
+		t.Fatalf("rejected publish: error = %v, want %v", err, ErrDeliveryFailed)
+	}
+	if err := p.Send(context.Background(), Message{To: "+84901234589", Channel: ChannelVoice}); !errors.Is(err, ErrChannelUnsupported) {
+		t.Fatalf("voice: error = %v, want %v", err, ErrChannelUnsupported)
+	}
+}
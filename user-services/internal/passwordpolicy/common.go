@@ -0,0 +1,30 @@
+package passwordpolicy
+
+import (
+	_ "embed"
+	"strings"
+	"sync"
+)
+
+//go:embed common_passwords.txt
+var commonPasswordsFile string
+
+var (
+	commonPasswordsOnce sync.Once
+	commonPasswords     map[string]struct{}
+)
+
+// isCommonPassword reports whether the password, ignoring case, is on the embedded list
+// of frequently used passwords.
+func isCommonPassword(password string) bool {
+	commonPasswordsOnce.Do(func() {
+		commonPasswords = make(map[string]struct{})
+		for _, line := range strings.Split(commonPasswordsFile, "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				commonPasswords[strings.ToLower(line)] = struct{}{}
+			}
+		}
+	})
+	_, found := commonPasswords[strings.ToLower(password)]
+	return found
+}
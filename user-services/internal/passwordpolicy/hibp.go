@@ -0,0 +1,76 @@
+package passwordpolicy
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ductan2/microservice-app/shared/metrics"
+)
+
+// HIBPClient checks passwords against the Have I Been Pwned range API using
+// k-anonymity: only the first five hex characters of the password's SHA-1 hash leave
+// the service, and the matching suffix is looked up locally in the response.
+type HIBPClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewHIBPClient creates a client for the range API at baseURL
+// (https://api.pwnedpasswords.com in production).
+func NewHIBPClient(baseURL string, timeout time.Duration) *HIBPClient {
+	return &HIBPClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout, Transport: metrics.NewTransport("hibp", nil)},
+	}
+}
+
+// BreachCount returns how many times the password appears in the breach corpus.
+func (c *HIBPClient) BreachCount(ctx context.Context, password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/range/"+prefix, nil)
+	if err != nil {
+		return 0, err
+	}
+	// Padding hides the real size of the response from anyone watching the traffic
+	req.Header.Set("Add-Padding", "true")
+	req.Header.Set("User-Agent", "user-services")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("breach range request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("breach range API returned %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		candidate, count, ok := strings.Cut(line, ":")
+		if !ok || !strings.EqualFold(candidate, suffix) {
+			continue
+		}
+		// Padding entries have a count of zero and never match a real suffix
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			return 0, fmt.Errorf("malformed breach range entry %q", line)
+		}
+		return n, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read breach range response: %w", err)
+	}
+	return 0, nil
+}
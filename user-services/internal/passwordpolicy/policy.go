@@ -0,0 +1,281 @@
+// Package passwordpolicy checks new passwords against a configurable policy: length,
+// character classes, estimated entropy, a list of common passwords, the user's own
+// details and, optionally, passwords known from data breaches.
+package passwordpolicy
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"user-services/internal/config"
+	"user-services/internal/errors"
+)
+
+// Violation codes
+const (
+	CodeTooShort         = "too_short"
+	CodeTooLong          = "too_long"
+	CodeMissingUpper     = "missing_uppercase"
+	CodeMissingLower     = "missing_lowercase"
+	CodeMissingDigit     = "missing_digit"
+	CodeMissingSpecial   = "missing_special"
+	CodeLowEntropy       = "low_entropy"
+	CodeCommonPassword   = "common_password"
+	CodeContainsUserInfo = "contains_user_info"
+	CodeBreached         = "breached"
+)
+
+// bcryptMaxBytes is the longest password bcrypt accepts.
+const bcryptMaxBytes = 72
+
+// Violation is one rule a password failed. Params carries the values a client needs to
+// render the message itself, such as the minimum length.
+type Violation struct {
+	Code    string         `json:"code"`
+	Message string         `json:"message"`
+	Params  map[string]any `json:"params,omitempty"`
+}
+
+// Policy lists the rules passwords must satisfy.
+type Policy struct {
+	MinLength      int
+	MaxLength      int
+	RequireUpper   bool
+	RequireLower   bool
+	RequireDigit   bool
+	RequireSpecial bool
+	// MinEntropyBits rejects passwords whose estimated entropy is lower; 0 disables it
+	MinEntropyBits int
+	DenyCommon     bool
+	// BreachThreshold is how many breach sightings reject a password
+	BreachThreshold int
+}
+
+// BreachChecker reports how often a password appears in known data breaches.
+type BreachChecker interface {
+	BreachCount(ctx context.Context, password string) (int, error)
+}
+
+// Engine applies a Policy. The breach checker is optional.
+type Engine struct {
+	policy Policy
+	breach BreachChecker
+}
+
+// NewEngine creates a policy engine. breach may be nil to skip breach checks.
+func NewEngine(policy Policy, breach BreachChecker) *Engine {
+	if policy.MaxLength <= 0 || policy.MaxLength > bcryptMaxBytes {
+		policy.MaxLength = bcryptMaxBytes
+	}
+	if policy.BreachThreshold <= 0 {
+		policy.BreachThreshold = 1
+	}
+	return &Engine{policy: policy, breach: breach}
+}
+
+// NewEngineFromConfig builds the engine described by the SECURITY_PASSWORD_* settings.
+func NewEngineFromConfig(cfg *config.Config) *Engine {
+	policy := Policy{
+		MinLength:       cfg.Security.PasswordMinLength,
+		MaxLength:       cfg.Security.PasswordMaxLength,
+		RequireUpper:    cfg.Security.PasswordRequireUpper,
+		RequireLower:    cfg.Security.PasswordRequireLower,
+		RequireDigit:    cfg.Security.PasswordRequireDigit,
+		RequireSpecial:  cfg.Security.PasswordRequireSpecial,
+		MinEntropyBits:  cfg.Security.PasswordMinEntropyBits,
+		DenyCommon:      cfg.Security.PasswordDenyCommon,
+		BreachThreshold: cfg.Security.PasswordBreachThreshold,
+	}
+
+	var breach BreachChecker
+	if cfg.Security.PasswordBreachCheck {
+		breach = NewHIBPClient(cfg.Security.PasswordBreachAPIURL, cfg.Security.PasswordBreachTimeout)
+	}
+	return NewEngine(policy, breach)
+}
+
+// Policy returns the rules the engine enforces.
+func (e *Engine) Policy() Policy {
+	return e.policy
+}
+
+// Check returns every rule the password violates, or nil if it is acceptable.
+// userInputs are details of the account (email, name) the password must not contain.
+// The breach check only runs when the local rules pass, and is skipped with a warning
+// if the breach service cannot be reached, so an outage does not block sign-ups.
+func (e *Engine) Check(ctx context.Context, password string, userInputs ...string) []Violation {
+	violations := e.checkLocal(password, userInputs)
+	if len(violations) > 0 || e.breach == nil {
+		return violations
+	}
+
+	count, err := e.breach.BreachCount(ctx, password)
+	if err != nil {
+		slog.WarnContext(ctx, "password breach check skipped", "error", err)
+		return nil
+	}
+	if count >= e.policy.BreachThreshold {
+		return []Violation{{
+			Code:    CodeBreached,
+			Message: "This password has appeared in a data breach and cannot be used",
+			Params:  map[string]any{"breach_count": count},
+		}}
+	}
+	return nil
+}
+
+// Validate runs Check and turns any violations into a WEAK_PASSWORD error whose details
+// list them.
+func (e *Engine) Validate(ctx context.Context, password string, userInputs ...string) error {
+	if password == "" {
+		return errors.NewValidationError("Password is required").WithReason("PASSWORD_REQUIRED")
+	}
+	if violations := e.Check(ctx, password, userInputs...); len(violations) > 0 {
+		return errors.NewWeakPasswordError(violations)
+	}
+	return nil
+}
+
+func (e *Engine) checkLocal(password string, userInputs []string) []Violation {
+	p := e.policy
+	var violations []Violation
+
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		violations = append(violations, Violation{
+			Code:    CodeTooShort,
+			Message: fmt.Sprintf("Password must be at least %d characters", p.MinLength),
+			Params:  map[string]any{"min_length": p.MinLength},
+		})
+	}
+	if length > p.MaxLength || len(password) > bcryptMaxBytes {
+		violations = append(violations, Violation{
+			Code:    CodeTooLong,
+			Message: fmt.Sprintf("Password must be at most %d characters", p.MaxLength),
+			Params:  map[string]any{"max_length": p.MaxLength},
+		})
+	}
+
+	classes := characterClasses(password)
+	if p.RequireUpper && !classes.upper {
+		violations = append(violations, Violation{Code: CodeMissingUpper, Message: "Password must contain an uppercase letter"})
+	}
+	if p.RequireLower && !classes.lower {
+		violations = append(violations, Violation{Code: CodeMissingLower, Message: "Password must contain a lowercase letter"})
+	}
+	if p.RequireDigit && !classes.digit {
+		violations = append(violations, Violation{Code: CodeMissingDigit, Message: "Password must contain a digit"})
+	}
+	if p.RequireSpecial && !classes.special {
+		violations = append(violations, Violation{Code: CodeMissingSpecial, Message: "Password must contain a special character"})
+	}
+
+	if p.DenyCommon && isCommonPassword(password) {
+		violations = append(violations, Violation{Code: CodeCommonPassword, Message: "This password is too common"})
+	}
+
+	if containsUserInfo(password, userInputs) {
+		violations = append(violations, Violation{Code: CodeContainsUserInfo, Message: "Password must not contain your name or email"})
+	}
+
+	if p.MinEntropyBits > 0 {
+		if bits := EstimateEntropy(password); bits < float64(p.MinEntropyBits) {
+			violations = append(violations, Violation{
+				Code:    CodeLowEntropy,
+				Message: "Password is too predictable; use a longer or more varied password",
+				Params:  map[string]any{"min_entropy_bits": p.MinEntropyBits, "entropy_bits": int(bits)},
+			})
+		}
+	}
+
+	return violations
+}
+
+type classSet struct {
+	upper, lower, digit, special, other bool
+}
+
+func characterClasses(password string) classSet {
+	var c classSet
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			c.upper = true
+		case unicode.IsLower(r):
+			c.lower = true
+		case unicode.IsDigit(r):
+			c.digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || r == ' ':
+			c.special = true
+		default:
+			c.other = true
+		}
+	}
+	return c
+}
+
+// EstimateEntropy approximates a password's entropy in bits as length times the bits
+// per character of the character classes it uses. Characters that repeat or continue
+// a sequence of the previous character ("aaa", "abc", "321") add nothing.
+func EstimateEntropy(password string) float64 {
+	classes := characterClasses(password)
+	pool := 0
+	if classes.lower {
+		pool += 26
+	}
+	if classes.upper {
+		pool += 26
+	}
+	if classes.digit {
+		pool += 10
+	}
+	if classes.special {
+		pool += 33
+	}
+	if classes.other {
+		pool += 100
+	}
+	if pool == 0 {
+		return 0
+	}
+
+	effective := 0
+	var prev rune
+	for i, r := range []rune(password) {
+		if i > 0 {
+			d := unicode.ToLower(r) - unicode.ToLower(prev)
+			if d >= -1 && d <= 1 {
+				prev = r
+				continue
+			}
+		}
+		effective++
+		prev = r
+	}
+	return float64(effective) * math.Log2(float64(pool))
+}
+
+// containsUserInfo reports whether the password contains a user detail of four or more
+// characters, such as the email's local part or a word of the display name.
+func containsUserInfo(password string, userInputs []string) bool {
+	lowered := strings.ToLower(password)
+	for _, input := range userInputs {
+		input = strings.ToLower(strings.TrimSpace(input))
+		if at := strings.IndexByte(input, '@'); at >= 0 {
+			input = input[:at]
+		}
+		for _, part := range strings.FieldsFunc(input, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			if utf8.RuneCountInString(part) >= 4 && strings.Contains(lowered, part) {
+				return true
+			}
+		}
+	}
+	return false
+}
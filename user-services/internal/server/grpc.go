@@ -0,0 +1,19 @@
+package server
+
+import (
+	"user-services/internal/api/repositories"
+	"user-services/internal/api/services"
+	"user-services/internal/grpc"
+)
+
+// NewGRPCServer wires the internal gRPC API. Calls are authenticated with the internal
+// service token.
+func NewGRPCServer(deps Deps) *grpc.Server {
+	userRepo := repositories.NewUserRepository(deps.DB)
+	sessionRepo := repositories.NewSessionRepository(deps.DB)
+	identityService := services.NewIdentityService(userRepo, sessionRepo)
+
+	s := grpc.NewServer(deps.ServiceVerifier)
+	grpc.NewIdentityServer(identityService).Register(s)
+	return s
+}
@@ -1,6 +1,9 @@
 package server
 
 import (
+	"context"
+	"log/slog"
+
 	"user-services/internal/api/controllers"
 	"user-services/internal/api/middleware"
 	"user-services/internal/api/repositories"
@@ -8,8 +11,16 @@ import (
 	"user-services/internal/api/services"
 	"user-services/internal/cache"
 	"user-services/internal/config"
+	"user-services/internal/geoip"
+	"user-services/internal/otp"
+	"user-services/internal/passwordpolicy"
+	"user-services/internal/storage"
 
+	"github.com/ductan2/microservice-app/shared/health"
+	"github.com/ductan2/microservice-app/shared/internalauth"
+	"github.com/ductan2/microservice-app/shared/outbox/gormstore"
 	"github.com/gin-gonic/gin"
+	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
@@ -17,23 +28,37 @@ import (
 type Deps struct {
 	DB          *gorm.DB
 	RedisClient *redis.Client
+	RabbitConn  *amqp.Connection
+	// ServiceVerifier checks the service tokens of the calls from other services
+	ServiceVerifier *internalauth.Verifier
+	// Health serves /livez, /readyz and /healthz, see services.NewHealthChecker
+	Health *health.Checker
 }
 
 func NewRouter(deps Deps) *gin.Engine {
 	r := gin.New()
+	// Handlers passing the gin.Context on as a context.Context still reach the request
+	// context, where the trace and audit details are stored
+	r.ContextWithFallback = true
+
+	// Load configuration
+	cfg := config.GetConfig()
+
+	// Context.ClientIP reads X-Forwarded-For past the trusted proxies only
+	if err := cfg.Server.TrustedProxies.Configure(r); err != nil {
+		slog.Warn("trusted proxies not set", "error", err)
+	}
 
 	// Middlewares
-	r.Use(gin.Logger())
+	r.Use(middleware.RequestLog())
+	r.Use(middleware.Metrics())
+	r.Use(middleware.Tracing())
+	r.Use(middleware.RequestMeta(cfg.Server.TrustedProxies))
 	r.Use(gin.Recovery())
+	r.Use(middleware.AuditContext())
 
 	r.GET("/health", controllers.Health)
 
-	// Load configuration
-	cfg := config.GetConfig()
-
-	// Initialize rate limiter
-	rateLimiter := middleware.NewRedisRateLimiter(deps.RedisClient, cfg)
-
 	// Initialize repositories
 	userRepo := repositories.NewUserRepository(deps.DB)
 	userProfileRepo := repositories.NewUserProfileRepository(deps.DB)
@@ -45,36 +70,152 @@ func NewRouter(deps Deps) *gin.Engine {
 	loginAttemptRepo := repositories.NewLoginAttemptRepository(deps.DB)
 	passwordResetRepo := repositories.NewPasswordResetRepository(deps.DB)
 	activitySessionRepo := repositories.NewActivitySessionRepository(deps.DB)
+	dataExportRepo := repositories.NewDataExportRepository(deps.DB)
+	erasureRepo := repositories.NewErasureRepository(deps.DB)
+	preferencesRepo := repositories.NewPreferencesRepository(deps.DB)
+	avatarRepo := repositories.NewAvatarRepository(deps.DB)
+	organizationRepo := repositories.NewOrganizationRepository(deps.DB)
+	invitationRepo := repositories.NewInvitationRepository(deps.DB)
+	accessTokenRepo := repositories.NewAccessTokenRepository(deps.DB)
+	pushTokenRepo := repositories.NewPushTokenRepository(deps.DB)
+	consentRepo := repositories.NewConsentRepository(deps.DB)
+	accountMergeRepo := repositories.NewAccountMergeRepository(deps.DB)
+	userImportRepo := repositories.NewUserImportRepository(deps.DB)
+	onboardingRepo := repositories.NewOnboardingRepository(deps.DB)
+	activityRollupRepo := repositories.NewActivityRollupRepository(deps.DB)
+	userMetricsRepo := repositories.NewUserMetricsRepository(deps.DB)
+
+	// Initialize rate limiter
+	rateLimiter := middleware.NewRedisRateLimiter(deps.RedisClient, cfg, auditLogRepo)
+
 	// Initialize Redis session cache
 	sessionCache := cache.NewSessionCache(deps.RedisClient)
+	lockoutCache := cache.NewLockoutCache(deps.RedisClient)
+	webAuthnCache := cache.NewWebAuthnCache(deps.RedisClient)
+	otpCache := cache.NewOTPCache(deps.RedisClient)
+	passwordlessCache := cache.NewPasswordlessCache(deps.RedisClient)
+
+	// Initialize session device and location details
+	sessionDescriber := services.NewSessionDescriber(geoip.NewClient(cfg.GeoIP, deps.RedisClient))
+	if cfg.GeoIP.ServiceURL == "" {
+		slog.Warn("GEOIP_SERVICE_URL is not set; sessions are recorded without a location")
+	}
+
+	// Initialize SMS/voice code delivery
+	otpProvider, err := otp.NewProvider(cfg.OTP)
+	if err != nil {
+		slog.Warn("OTP provider unavailable; SMS/voice codes will only be logged", "error", err)
+		otpProvider = otp.NewLogProvider()
+	}
+	if cfg.IsProduction() && otpProvider.Name() == "log" {
+		slog.Warn("OTP_PROVIDER is \"log\" in production; SMS/voice codes are not delivered")
+	}
+
+	// Initialize avatar storage
+	avatarStore, err := storage.NewAvatarStore(cfg.Avatar)
+	if err != nil {
+		slog.Warn("avatar storage unavailable; avatar uploads are disabled", "error", err)
+	} else if avatarStore == nil {
+		slog.Warn("AVATAR_S3_BUCKET is not set; avatar uploads are disabled")
+	}
+
+	// Initialize password policy (length, entropy, common and breached passwords)
+	passwordPolicy := passwordpolicy.NewEngineFromConfig(cfg)
 
 	// Initialize services
-	authService := services.NewAuthService(userRepo, userProfileRepo, auditLogRepo, outboxRepo, sessionRepo, refreshTokenRepo, mfaRepo, loginAttemptRepo, sessionCache)
-	profileService := services.NewUserProfileService(userProfileRepo)
+	securityEventService := services.NewSecurityEventService(outboxRepo)
+	lockoutService := services.NewLockoutService(lockoutCache, userRepo, userProfileRepo, outboxRepo, auditLogRepo, securityEventService)
+	webAuthnService := services.NewWebAuthnService(mfaRepo, userRepo, auditLogRepo, webAuthnCache)
+	otpService := services.NewOTPService(mfaRepo, userRepo, auditLogRepo, otpCache, otpProvider)
+	sessionService := services.NewSessionService(sessionRepo, sessionCache, sessionDescriber)
+	consentService := services.NewConsentService(consentRepo, sessionRepo, auditLogRepo, sessionCache)
+	if err := consentService.SyncRequiredPolicies(context.Background()); err != nil {
+		slog.Warn("failed to sync required policies; the BFF keeps the policy versions it last saw", "error", err)
+	}
+	erasureService := services.NewErasureService(erasureRepo, userRepo, dataExportRepo, auditLogRepo, outboxRepo, sessionService, cfg.Erasure)
+	authService := services.NewAuthService(userRepo, userProfileRepo, auditLogRepo, outboxRepo, sessionRepo, refreshTokenRepo, mfaRepo, loginAttemptRepo, sessionCache, lockoutService, webAuthnService, otpService, passwordPolicy, organizationRepo, passwordlessCache, sessionDescriber, securityEventService, erasureService, consentService)
+	preferencesService := services.NewPreferencesService(preferencesRepo, userProfileRepo, auditLogRepo)
+	onboardingService := services.NewOnboardingService(onboardingRepo, userRepo)
+	identifierService := services.NewIdentifierService(userRepo, auditLogRepo, otpService)
+	profileService := services.NewUserProfileService(userProfileRepo, auditLogRepo, preferencesService, avatarRepo)
+	avatarService := services.NewAvatarService(avatarRepo, userProfileRepo, auditLogRepo, avatarStore, cfg.Avatar)
 	currentUserService := services.NewCurrentUserService(userRepo)
-	passwordService := services.NewPasswordService(userRepo, passwordResetRepo, auditLogRepo, outboxRepo, userProfileRepo)
-	mfaService := services.NewMFAService(mfaRepo, userRepo)
-	sessionService := services.NewSessionService(sessionRepo, sessionCache)
-	userService := services.NewUserService(userRepo)
+	passwordService := services.NewPasswordService(userRepo, passwordResetRepo, auditLogRepo, outboxRepo, userProfileRepo, sessionService, passwordPolicy)
+	mfaService := services.NewMFAService(mfaRepo, userRepo, auditLogRepo)
+	userService := services.NewUserService(userRepo, lockoutService, auditLogRepo, erasureRepo, securityEventService)
 	activitySessionService := services.NewActivitySessionService(activitySessionRepo, deps.DB)
+	activityRollupService := services.NewActivityRollupService(activityRollupRepo, userRepo, cfg.Activity)
+	auditService := services.NewAuditService(auditLogRepo)
+	userMetricsService := services.NewUserMetricsService(userMetricsRepo)
+	dataExportService := services.NewDataExportService(dataExportRepo, userRepo, sessionRepo, activitySessionRepo, mfaRepo, consentRepo, auditLogRepo, outboxRepo, cfg.DataExport)
+	userImportService := services.NewUserImportService(userImportRepo, organizationRepo, auditLogRepo, cfg.Import)
+	organizationService := services.NewOrganizationService(organizationRepo, userRepo, auditLogRepo)
+	invitationService := services.NewInvitationService(invitationRepo, organizationRepo, userRepo, auditLogRepo, passwordPolicy, cfg.Invitation)
+	accessTokenService := services.NewAccessTokenService(accessTokenRepo, userRepo, organizationRepo, auditLogRepo, cfg.AccessToken)
+	pushTokenService := services.NewPushTokenService(pushTokenRepo, userRepo, auditLogRepo, cfg.PushTokens)
+	accountMergeService := services.NewAccountMergeService(accountMergeRepo, userRepo, auditLogRepo, sessionService, cfg.Merge)
+	// Publishing is left to the outbox relay; the router only reads the backlog
+	outboxService := services.NewOutboxService(gormstore.New(deps.DB, gormstore.DefaultTable))
 	// Initialize services
-	tokenService := services.NewTokenService(refreshTokenRepo, sessionRepo)
+	tokenService := services.NewTokenService(refreshTokenRepo, sessionRepo, userRepo, organizationRepo, sessionCache)
+	impersonationService := services.NewImpersonationService(userRepo, sessionRepo, refreshTokenRepo, organizationRepo, auditLogRepo, sessionCache, tokenService, sessionDescriber, securityEventService, cfg.Impersonate)
 
 	// Initialize controllers
-	userCtrl := controllers.NewUserController(authService, profileService, currentUserService, userService, sessionService, rateLimiter, deps.RedisClient)
+	userCtrl := controllers.NewUserController(authService, profileService, currentUserService, userService, sessionService, lockoutService, webAuthnService, rateLimiter, deps.RedisClient)
 	passwordCtrl := controllers.NewPasswordController(passwordService)
-	mfaCtrl := controllers.NewMFAController(mfaService)
+	mfaCtrl := controllers.NewMFAController(mfaService, webAuthnService, otpService)
 	sessionCtrl := controllers.NewSessionController(sessionService)
 	activitySessionCtrl := controllers.NewActivitySessionController(activitySessionService)
+	activityRollupCtrl := controllers.NewActivityRollupController(activityRollupService)
+	auditCtrl := controllers.NewAuditController(auditService)
+	userMetricsCtrl := controllers.NewUserMetricsController(userMetricsService)
+	dataExportCtrl := controllers.NewDataExportController(dataExportService, cfg.DataExport.MaxPartBytes)
+	erasureCtrl := controllers.NewErasureController(erasureService)
+	preferencesCtrl := controllers.NewPreferencesController(preferencesService)
+	onboardingCtrl := controllers.NewOnboardingController(onboardingService)
+	identifierCtrl := controllers.NewIdentifierController(identifierService)
+	avatarCtrl := controllers.NewAvatarController(avatarService)
+	userImportCtrl := controllers.NewUserImportController(userImportService, cfg.Import.MaxRows)
+	organizationCtrl := controllers.NewOrganizationController(organizationService)
+	invitationCtrl := controllers.NewInvitationController(invitationService)
+	accessTokenCtrl := controllers.NewAccessTokenController(accessTokenService)
+	pushTokenCtrl := controllers.NewPushTokenController(pushTokenService)
+	consentCtrl := controllers.NewConsentController(consentService)
+	accountMergeCtrl := controllers.NewAccountMergeController(accountMergeService)
+	impersonationCtrl := controllers.NewImpersonationController(impersonationService)
+	metricsCtrl := controllers.NewMetricsController(outboxService, deps.Health)
+
+	r.GET("/metrics", metricsCtrl.Metrics)
+	r.GET(health.LivePath, gin.WrapH(deps.Health.Live()))
+	r.GET(health.ReadyPath, gin.WrapH(deps.Health.Ready()))
+	r.GET(health.HealthPath, gin.WrapH(deps.Health.Health()))
 
 	api := r.Group("/api/v1")
+	api.Use(middleware.ServiceAuth(deps.ServiceVerifier))
 	{
 		routers.RegisterUserRoutes(api, userCtrl, rateLimiter, cfg)
 		routers.RegisterPasswordRoutes(api, passwordCtrl, sessionCache, rateLimiter, cfg)
 		routers.RegisterAuthRoutes(api, controllers.NewTokenController(tokenService, rateLimiter), rateLimiter, cfg)
-		routers.RegisterMFARoutes(api, mfaCtrl, sessionCache)
+		routers.RegisterMFARoutes(api, mfaCtrl, sessionCache, rateLimiter, cfg)
 		routers.RegisterSessionRoutes(api, sessionCtrl, sessionCache)
 		routers.RegisterActivitySessionRoutes(api, activitySessionCtrl, sessionCache)
+		routers.RegisterActivityRollupRoutes(api, activityRollupCtrl)
+		routers.RegisterAuditRoutes(api, auditCtrl)
+		routers.RegisterUserMetricsRoutes(api, userMetricsCtrl)
+		routers.RegisterDataExportRoutes(api, dataExportCtrl)
+		routers.RegisterErasureRoutes(api, erasureCtrl, rateLimiter, cfg)
+		routers.RegisterPreferencesRoutes(api, preferencesCtrl)
+		routers.RegisterOnboardingRoutes(api, onboardingCtrl)
+		routers.RegisterIdentifierRoutes(api, identifierCtrl, rateLimiter, cfg)
+		routers.RegisterAvatarRoutes(api, avatarCtrl)
+		routers.RegisterUserImportRoutes(api, userImportCtrl)
+		routers.RegisterOrganizationRoutes(api, organizationCtrl)
+		routers.RegisterInvitationRoutes(api, invitationCtrl, rateLimiter, cfg)
+		routers.RegisterAccessTokenRoutes(api, accessTokenCtrl)
+		routers.RegisterPushTokenRoutes(api, pushTokenCtrl)
+		routers.RegisterConsentRoutes(api, consentCtrl)
+		routers.RegisterAccountMergeRoutes(api, accountMergeCtrl)
+		routers.RegisterImpersonationRoutes(api, impersonationCtrl)
 	}
 
 	return r
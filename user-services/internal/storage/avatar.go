@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"user-services/internal/config"
+
+	"github.com/ductan2/microservice-app/shared/metrics"
+)
+
+// NewAvatarStore creates the S3 client for the avatar bucket. It returns nil, without an
+// error, when avatar storage is not configured.
+func NewAvatarStore(cfg config.AvatarConfig) (*S3Client, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	client, err := NewS3Client(
+		context.Background(),
+		cfg.S3Endpoint,
+		cfg.S3Region,
+		cfg.S3Bucket,
+		cfg.S3AccessKeyID,
+		cfg.S3SecretAccessKey,
+		cfg.S3UsePathStyle,
+		&http.Client{Timeout: 30 * time.Second, Transport: metrics.NewTransport("s3", nil)},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("storage: %w", err)
+	}
+	return client, nil
+}
@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+var (
+	// ErrObjectNotFound is returned when the requested key does not exist in the bucket.
+	ErrObjectNotFound = errors.New("object not found")
+	// ErrObjectTooLarge is returned by Get when the object exceeds the size limit.
+	ErrObjectTooLarge = errors.New("object too large")
+)
+
+// S3Client talks to an S3-compatible bucket (Amazon S3 or MinIO) with the AWS SDK. Only
+// the few object operations the service needs are exposed.
+type S3Client struct {
+	endpoint     *url.URL
+	bucket       string
+	usePathStyle bool
+	client       *s3.Client
+	presign      *s3.PresignClient
+}
+
+// NewS3Client creates an S3 client for bucket. endpoint is optional and defaults to
+// Amazon S3 in region; set usePathStyle for MinIO and other servers that do not serve
+// buckets as subdomains. Credentials come from the SDK's default chain, such as the ECS
+// task role or the web identity token of an EKS service account, unless accessKeyID and
+// secretAccessKey are set.
+func NewS3Client(ctx context.Context, endpoint, region, bucket, accessKeyID, secretAccessKey string, usePathStyle bool, client *http.Client) (*S3Client, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("bucket is required")
+	}
+	custom := endpoint != ""
+	if !custom {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+	u, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", endpoint)
+	}
+
+	opts := []func(*awsconfig.LoadOptions) error{awsconfig.WithRegion(region)}
+	if client != nil {
+		opts = append(opts, awsconfig.WithHTTPClient(client))
+	}
+	if accessKeyID != "" {
+		opts = append(opts, awsconfig.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, "")))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	s3Client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if custom {
+			o.BaseEndpoint = aws.String(u.String())
+		}
+		o.UsePathStyle = usePathStyle
+		// Checksums are only sent when the operation requires one, so presigned uploads
+		// do not need the client to compute one and MinIO accepts the requests
+		o.RequestChecksumCalculation = aws.RequestChecksumCalculationWhenRequired
+	})
+	return &S3Client{
+		endpoint:     u,
+		bucket:       bucket,
+		usePathStyle: usePathStyle,
+		client:       s3Client,
+		presign:      s3.NewPresignClient(s3Client),
+	}, nil
+}
+
+// ObjectURL returns the unsigned URL of key, which only works for public objects.
+func (c *S3Client) ObjectURL(key string) string {
+	escapedKey := escapePath(key)
+	if c.usePathStyle {
+		return c.endpoint.Scheme + "://" + c.endpoint.Host + c.endpoint.EscapedPath() + "/" + c.bucket + "/" + escapedKey
+	}
+	return c.endpoint.Scheme + "://" + c.bucket + "." + c.endpoint.Host + c.endpoint.EscapedPath() + "/" + escapedKey
+}
+
+// PresignPut returns a URL that lets a client upload key without credentials until ttl
+// passes, and the headers the upload must send. The content type and length are part
+// of the signature, so the client cannot upload a different kind or size of file.
+func (c *S3Client) PresignPut(ctx context.Context, key, contentType string, size int64, ttl time.Duration) (string, map[string]string, error) {
+	req, err := c.presign.PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(c.bucket),
+		Key:           aws.String(key),
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", nil, err
+	}
+
+	headers := make(map[string]string, len(req.SignedHeader))
+	for name := range req.SignedHeader {
+		if !strings.EqualFold(name, "Host") {
+			headers[http.CanonicalHeaderKey(name)] = req.SignedHeader.Get(name)
+		}
+	}
+	return req.URL, headers, nil
+}
+
+// Get downloads key. It fails with ErrObjectTooLarge rather than reading more than
+// maxBytes.
+func (c *S3Client) Get(ctx context.Context, key string, maxBytes int64) ([]byte, error) {
+	out, err := c.client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(c.bucket), Key: aws.String(key)})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, err
+	}
+	defer out.Body.Close()
+
+	if aws.ToInt64(out.ContentLength) > maxBytes {
+		return nil, ErrObjectTooLarge
+	}
+
+	body, err := io.ReadAll(io.LimitReader(out.Body, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > maxBytes {
+		return nil, ErrObjectTooLarge
+	}
+	return body, nil
+}
+
+// Put uploads body as key.
+func (c *S3Client) Put(ctx context.Context, key string, body []byte, contentType, cacheControl string) error {
+	in := &s3.PutObjectInput{
+		Bucket:        aws.String(c.bucket),
+		Key:           aws.String(key),
+		Body:          bytes.NewReader(body),
+		ContentLength: aws.Int64(int64(len(body))),
+		ContentType:   aws.String(contentType),
+	}
+	if cacheControl != "" {
+		in.CacheControl = aws.String(cacheControl)
+	}
+	_, err := c.client.PutObject(ctx, in)
+	return err
+}
+
+// Delete removes key. Deleting a key that does not exist succeeds.
+func (c *S3Client) Delete(ctx context.Context, key string) error {
+	_, err := c.client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(c.bucket), Key: aws.String(key)})
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return nil
+	}
+	return err
+}
+
+// escapePath URI-encodes every segment of key, keeping the slashes.
+func escapePath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(url.QueryEscape(segment), "+", "%20")
+	}
+	return strings.Join(segments, "/")
+}
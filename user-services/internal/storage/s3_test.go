@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 keeps the objects of the bucket "avatars", served path-style, and refuses
+// unsigned requests.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	key, ok := strings.CutPrefix(r.URL.Path, "/avatars/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[key] = body
+	case http.MethodGet:
+		body, ok := f.objects[key]
+		if !ok {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`))
+			return
+		}
+		w.Write(body)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func newTestS3(t *testing.T) (*S3Client, *fakeS3) {
+	t.Helper()
+	fake := &fakeS3{objects: map[string][]byte{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	// Hide the AWS variables and files of the machine running the tests from the SDK
+	t.Setenv("AWS_CA_BUNDLE", "")
+	t.Setenv("AWS_PROFILE", "")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+
+	client, err := NewS3Client(context.Background(), server.URL, "us-east-1", "avatars", "AKID", "secret", true, server.Client())
+	if err != nil {
+		t.Fatalf("NewS3Client: %v", err)
+	}
+	return client, fake
+}
+
+func TestS3ClientObjects(t *testing.T) {
+	ctx := context.Background()
+	client, fake := newTestS3(t)
+
+	if err := client.Put(ctx, "avatars/u1/a.jpg", []byte("jpeg"), "image/jpeg", "public, max-age=31536000"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if string(fake.objects["avatars/u1/a.jpg"]) != "jpeg" {
+		t.Fatalf("stored objects = %v", fake.objects)
+	}
+
+	if body, err := client.Get(ctx, "avatars/u1/a.jpg", 4); err != nil || string(body) != "jpeg" {
+		t.Fatalf("Get = %q, %v", body, err)
+	}
+	if _, err := client.Get(ctx, "avatars/u1/a.jpg", 3); !errors.Is(err, ErrObjectTooLarge) {
+		t.Fatalf("Get over the limit: error = %v, want %v", err, ErrObjectTooLarge)
+	}
+	if _, err := client.Get(ctx, "avatars/u1/missing.jpg", 4); !errors.Is(err, ErrObjectNotFound) {
+		t.Fatalf("Get of a missing key: error = %v, want %v", err, ErrObjectNotFound)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := client.Delete(ctx, "avatars/u1/a.jpg"); err != nil {
+			t.Fatalf("Delete #%d: %v", i+1, err)
+		}
+	}
+	if len(fake.objects) != 0 {
+		t.Fatalf("objects left after Delete: %v", fake.objects)
+	}
+}
+
+// TestS3ClientPresignPut checks that the content type and length of an upload are
+// signed, so the uploader cannot change them.
+func TestS3ClientPresignPut(t *testing.T) {
+	client, _ := newTestS3(t)
+
+	rawURL, headers, err := client.PresignPut(context.Background(), "uploads/avatars/u1/x", "image/png", 2048, 15*time.Minute)
+	if err != nil {
+		t.Fatalf("PresignPut: %v", err)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	if u.Path != "/avatars/uploads/avatars/u1/x" || query.Get("X-Amz-Expires") != "900" || query.Get("X-Amz-Signature") == "" {
+		t.Fatalf("presigned URL = %s", rawURL)
+	}
+	if signed := query.Get("X-Amz-SignedHeaders"); signed != "content-length;content-type;host" {
+		t.Fatalf("signed headers = %q", signed)
+	}
+	if headers["Content-Type"] != "image/png" || headers["Content-Length"] != "2048" || len(headers) != 2 {
+		t.Fatalf("upload headers = %v", headers)
+	}
+}
+
+func TestS3ClientObjectURL(t *testing.T) {
+	pathStyle, _ := newTestS3(t)
+	if got := pathStyle.ObjectURL("avatars/u1/a b.jpg"); !strings.HasSuffix(got, "/avatars/avatars/u1/a%20b.jpg") {
+		t.Errorf("path-style ObjectURL = %s", got)
+	}
+
+	virtualHosted, err := NewS3Client(context.Background(), "", "eu-west-1", "avatars", "AKID", "secret", false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := virtualHosted.ObjectURL("avatars/u1/a.jpg"), "https://avatars.s3.eu-west-1.amazonaws.com/avatars/u1/a.jpg"; got != want {
+		t.Errorf("ObjectURL = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+)
+
+// AccessTokenPrefix marks personal access tokens so they are told apart from JWTs and
+// are recognisable to secret scanners.
+const AccessTokenPrefix = "uat_"
+
+// accessTokenDisplayLength is how many characters after the prefix are kept to identify
+// a token to its owner.
+const accessTokenDisplayLength = 8
+
+// GenerateAccessToken returns a new personal access token and the part of it that may be
+// stored and shown in listings.
+func GenerateAccessToken() (token, displayPrefix string, err error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	token = AccessTokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	return token, token[:len(AccessTokenPrefix)+accessTokenDisplayLength], nil
+}
+
+// IsAccessToken reports whether token looks like a personal access token.
+func IsAccessToken(token string) bool {
+	return strings.HasPrefix(token, AccessTokenPrefix)
+}
@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// signedTokenPayloadSize is the ID, the nonce and the expiry in seconds.
+const signedTokenPayloadSize = 16 + 16 + 8
+
+var (
+	ErrInvitationTokenInvalid = errors.New("invalid invitation token")
+	ErrInvitationTokenExpired = errors.New("invitation token has expired")
+)
+
+// InvitationToken is the content of a signed invitation token.
+type InvitationToken struct {
+	InvitationID uuid.UUID
+	// Nonce is the random part, which the invitation stores hashed so a resend can
+	// invalidate earlier links
+	Nonce     string
+	ExpiresAt time.Time
+}
+
+// NewInvitationToken creates a token for the invitation with a fresh nonce and returns
+// it with its signed string form.
+func NewInvitationToken(secret string, invitationID uuid.UUID, expiresAt time.Time) (InvitationToken, string, error) {
+	nonce, signed, err := newSignedToken(secret, "invitation:", invitationID, expiresAt)
+	if err != nil {
+		return InvitationToken{}, "", err
+	}
+	token := InvitationToken{
+		InvitationID: invitationID,
+		Nonce:        nonce,
+		ExpiresAt:    time.Unix(expiresAt.Unix(), 0),
+	}
+	return token, signed, nil
+}
+
+// ParseInvitationToken checks the signature and expiry of a signed invitation token.
+// It returns ErrInvitationTokenInvalid for tokens that were tampered with or not issued
+// with secret, and ErrInvitationTokenExpired, with the content, for expired ones.
+func ParseInvitationToken(secret, signed string) (InvitationToken, error) {
+	id, nonce, expiresAt, ok := parseSignedToken(secret, "invitation:", signed)
+	if !ok {
+		return InvitationToken{}, ErrInvitationTokenInvalid
+	}
+
+	token := InvitationToken{InvitationID: id, Nonce: nonce, ExpiresAt: expiresAt}
+	if time.Now().After(token.ExpiresAt) {
+		return token, ErrInvitationTokenExpired
+	}
+	return token, nil
+}
+
+// newSignedToken signs id, a fresh nonce and the expiry with secret, separating the
+// kinds of tokens by domain so one cannot be passed off as another. It returns the
+// nonce in hex and the signed string form.
+func newSignedToken(secret, domain string, id uuid.UUID, expiresAt time.Time) (string, string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", "", err
+	}
+
+	payload := make([]byte, signedTokenPayloadSize)
+	copy(payload[:16], id[:])
+	copy(payload[16:32], nonce)
+	binary.BigEndian.PutUint64(payload[32:], uint64(expiresAt.Unix()))
+
+	signed := base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(signTokenPayload(secret, domain, payload))
+	return hex.EncodeToString(nonce), signed, nil
+}
+
+// parseSignedToken checks the signature of a token made by newSignedToken and returns
+// its content; expiry is left to the caller.
+func parseSignedToken(secret, domain, signed string) (uuid.UUID, string, time.Time, bool) {
+	encodedPayload, encodedSig, ok := strings.Cut(signed, ".")
+	if !ok {
+		return uuid.Nil, "", time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil || len(payload) != signedTokenPayloadSize {
+		return uuid.Nil, "", time.Time{}, false
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil || !hmac.Equal(sig, signTokenPayload(secret, domain, payload)) {
+		return uuid.Nil, "", time.Time{}, false
+	}
+
+	var id uuid.UUID
+	copy(id[:], payload[:16])
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(payload[32:])), 0)
+	return id, hex.EncodeToString(payload[16:32]), expiresAt, true
+}
+
+func signTokenPayload(secret, domain string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(domain))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
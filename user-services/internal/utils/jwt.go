@@ -12,14 +12,28 @@ import (
 )
 
 type Claims struct {
-	UserID    uuid.UUID `json:"user_id"`
-	Email     string    `json:"email"`
-	SessionID uuid.UUID `json:"session_id"`
+	UserID           uuid.UUID  `json:"user_id"`
+	Email            string     `json:"email"`
+	SessionID        uuid.UUID  `json:"session_id"`
+	OrganizationID   *uuid.UUID `json:"org_id,omitempty"`
+	OrganizationRole string     `json:"org_role,omitempty"`
+	// ImpersonatorID marks tokens of impersonation sessions with the administrator
+	// acting as the user
+	ImpersonatorID *uuid.UUID `json:"impersonator_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenerateJWT creates a signed JWT for the given user id, email, and session id.
-func GenerateJWT(userID uuid.UUID, email string, sessionID uuid.UUID) (string, error) {
+// OrgClaims is the organization context carried in an access token: the user's primary
+// organization and their membership role in it.
+type OrgClaims struct {
+	ID   uuid.UUID
+	Role string
+}
+
+// GenerateJWT creates a signed JWT for the given user id, email, and session id, with
+// the organization context when org is set and the impersonating administrator when
+// impersonatorID is set.
+func GenerateJWT(userID uuid.UUID, email string, sessionID uuid.UUID, org *OrgClaims, impersonatorID *uuid.UUID) (string, error) {
 	cfg := config.GetJWTConfig()
 
 	now := time.Now()
@@ -32,6 +46,11 @@ func GenerateJWT(userID uuid.UUID, email string, sessionID uuid.UUID) (string, e
 			ExpiresAt: jwt.NewNumericDate(now.Add(cfg.ExpiresIn)),
 		},
 	}
+	if org != nil {
+		claims.OrganizationID = &org.ID
+		claims.OrganizationRole = org.Role
+	}
+	claims.ImpersonatorID = impersonatorID
 
 	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return t.SignedString([]byte(cfg.Secret))
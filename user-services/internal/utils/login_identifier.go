@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Kinds of login principal
+const (
+	PrincipalEmail    = "email"
+	PrincipalUsername = "username"
+	PrincipalPhone    = "phone"
+)
+
+var (
+	usernameRegex = regexp.MustCompile(`^[a-z][a-z0-9_.]{1,28}[a-z0-9]$`)
+	e164Regex     = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+	// phoneFormatting is stripped from phone numbers as typed, e.g. "+84 (90) 123-4567"
+	phoneFormatting = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "")
+
+	// reservedUsernames could be mistaken for the service or its staff
+	reservedUsernames = []string{
+		"admin", "administrator", "api", "help", "me", "moderator",
+		"null", "root", "support", "system", "undefined",
+	}
+)
+
+// NormalizeUsername lowercases and trims a username. Usernames are case-insensitive.
+func NormalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// ValidUsername reports whether a normalized username is 3 to 30 characters of letters,
+// digits, dots and underscores, starting with a letter and ending with a letter or digit.
+func ValidUsername(username string) bool {
+	return usernameRegex.MatchString(username)
+}
+
+// ReservedUsername reports whether a normalized username cannot be claimed.
+func ReservedUsername(username string) bool {
+	return slices.Contains(reservedUsernames, username)
+}
+
+// NormalizePhoneNumber strips the formatting from a phone number and returns it in E.164
+// form, or "" when it is not a phone number with its country code.
+func NormalizePhoneNumber(phone string) string {
+	phone = phoneFormatting.Replace(strings.TrimSpace(phone))
+	if strings.HasPrefix(phone, "00") {
+		phone = "+" + phone[2:]
+	} else if !strings.HasPrefix(phone, "+") {
+		phone = "+" + phone
+	}
+	if !e164Regex.MatchString(phone) {
+		return ""
+	}
+	return phone
+}
+
+// ParseLoginPrincipal tells which identifier a user typed to sign in, an email address,
+// a phone number or a username, and normalizes it for lookup.
+func ParseLoginPrincipal(principal string) (kind string, normalized string) {
+	principal = strings.TrimSpace(principal)
+	if strings.Contains(principal, "@") {
+		return PrincipalEmail, strings.ToLower(principal)
+	}
+	if phone := NormalizePhoneNumber(principal); phone != "" {
+		return PrincipalPhone, phone
+	}
+	return PrincipalUsername, NormalizeUsername(principal)
+}
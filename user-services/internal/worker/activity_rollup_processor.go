@@ -0,0 +1,54 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"user-services/internal/api/services"
+)
+
+// ActivityRollupProcessor periodically rolls up the activity sessions changed since its
+// last run into daily and weekly study time per user.
+type ActivityRollupProcessor struct {
+	service  services.ActivityRollupService
+	interval time.Duration
+	stopChan chan struct{}
+}
+
+// NewActivityRollupProcessor creates a new activity rollup processor
+func NewActivityRollupProcessor(service services.ActivityRollupService, interval time.Duration) *ActivityRollupProcessor {
+	return &ActivityRollupProcessor{
+		service:  service,
+		interval: interval,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start begins rolling up activity sessions in the background
+func (p *ActivityRollupProcessor) Start(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	slog.Info("activity rollup processor started", "interval", p.interval)
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.service.ProcessRollups(ctx); err != nil {
+				slog.ErrorContext(ctx, "activity rollup processing failed", "error", err)
+			}
+		case <-p.stopChan:
+			slog.Info("activity rollup processor stopped")
+			return
+		case <-ctx.Done():
+			slog.Info("activity rollup processor context cancelled")
+			return
+		}
+	}
+}
+
+// Stop gracefully stops the processor
+func (p *ActivityRollupProcessor) Stop() {
+	close(p.stopChan)
+}
@@ -0,0 +1,54 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"user-services/internal/api/services"
+)
+
+// AvatarCleanupProcessor periodically expires abandoned avatar uploads and deletes
+// replaced, removed and rejected avatar images from the object store.
+type AvatarCleanupProcessor struct {
+	service  services.AvatarService
+	interval time.Duration
+	stopChan chan struct{}
+}
+
+// NewAvatarCleanupProcessor creates a new avatar cleanup processor
+func NewAvatarCleanupProcessor(service services.AvatarService, interval time.Duration) *AvatarCleanupProcessor {
+	return &AvatarCleanupProcessor{
+		service:  service,
+		interval: interval,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start begins cleaning up avatars in the background
+func (p *AvatarCleanupProcessor) Start(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	slog.Info("avatar cleanup processor started", "interval", p.interval)
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.service.ProcessCleanup(ctx); err != nil {
+				slog.ErrorContext(ctx, "avatar cleanup failed", "error", err)
+			}
+		case <-p.stopChan:
+			slog.Info("avatar cleanup processor stopped")
+			return
+		case <-ctx.Done():
+			slog.Info("avatar cleanup processor context cancelled")
+			return
+		}
+	}
+}
+
+// Stop gracefully stops the processor
+func (p *AvatarCleanupProcessor) Stop() {
+	close(p.stopChan)
+}
@@ -0,0 +1,54 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"user-services/internal/api/services"
+)
+
+// DataExportProcessor periodically builds data export archives whose parts are all in or
+// whose collect timeout has passed, and deletes archives past their expiry.
+type DataExportProcessor struct {
+	service  services.DataExportService
+	interval time.Duration
+	stopChan chan struct{}
+}
+
+// NewDataExportProcessor creates a new data export processor
+func NewDataExportProcessor(service services.DataExportService, interval time.Duration) *DataExportProcessor {
+	return &DataExportProcessor{
+		service:  service,
+		interval: interval,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start begins processing data exports in the background
+func (p *DataExportProcessor) Start(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	slog.Info("data export processor started", "interval", p.interval)
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.service.ProcessDue(ctx); err != nil {
+				slog.ErrorContext(ctx, "data export processing failed", "error", err)
+			}
+		case <-p.stopChan:
+			slog.Info("data export processor stopped")
+			return
+		case <-ctx.Done():
+			slog.Info("data export processor context cancelled")
+			return
+		}
+	}
+}
+
+// Stop gracefully stops the processor
+func (p *DataExportProcessor) Stop() {
+	close(p.stopChan)
+}
@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"user-services/internal/api/services"
+)
+
+// ErasureProcessor periodically schedules the erasure of accounts soft-deleted longer
+// than the retention policy allows, anonymizes accounts whose erasure retention window
+// has ended, and reports erasures still missing acknowledgements past their deadline.
+type ErasureProcessor struct {
+	service  services.ErasureService
+	interval time.Duration
+	stopChan chan struct{}
+}
+
+// NewErasureProcessor creates a new erasure processor
+func NewErasureProcessor(service services.ErasureService, interval time.Duration) *ErasureProcessor {
+	return &ErasureProcessor{
+		service:  service,
+		interval: interval,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start begins processing erasure requests in the background
+func (p *ErasureProcessor) Start(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	slog.Info("erasure processor started", "interval", p.interval)
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.service.ProcessDue(ctx); err != nil {
+				slog.ErrorContext(ctx, "erasure processing failed", "error", err)
+			}
+		case <-p.stopChan:
+			slog.Info("erasure processor stopped")
+			return
+		case <-ctx.Done():
+			slog.Info("erasure processor context cancelled")
+			return
+		}
+	}
+}
+
+// Stop gracefully stops the processor
+func (p *ErasureProcessor) Stop() {
+	close(p.stopChan)
+}
@@ -0,0 +1,17 @@
+-- WebAuthn credentials ------------------------------------------------------------
+-- Passkeys are stored as mfa_methods rows of type 'webauthn'. label holds the device
+-- nickname and webauthn_pub the COSE-encoded public key; secret is unused for them.
+ALTER TABLE mfa_methods
+    ADD COLUMN IF NOT EXISTS credential_id TEXT,
+    ADD COLUMN IF NOT EXISTS sign_count BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS transports TEXT,
+    ADD COLUMN IF NOT EXISTS aaguid TEXT;
+
+ALTER TABLE mfa_methods ALTER COLUMN secret DROP NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS mfa_methods_credential_id_idx
+    ON mfa_methods (credential_id)
+    WHERE credential_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS mfa_methods_user_type_idx
+    ON mfa_methods (user_id, type);
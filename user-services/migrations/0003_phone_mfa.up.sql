@@ -0,0 +1,13 @@
+-- Phone (SMS/voice) MFA -----------------------------------------------------------
+-- Phone numbers are mfa_methods rows of type 'phone'. They only count as a second
+-- factor once the number has been confirmed with a code (verified_at). priority orders
+-- a user's methods, lowest first; the first is the primary, the rest are fallbacks.
+ALTER TABLE mfa_methods DROP CONSTRAINT IF EXISTS mfa_methods_type_check;
+ALTER TABLE mfa_methods
+    ADD CONSTRAINT mfa_methods_type_check CHECK (type IN ('totp','webauthn','phone'));
+
+ALTER TABLE mfa_methods
+    ADD COLUMN IF NOT EXISTS phone_number TEXT,
+    ADD COLUMN IF NOT EXISTS otp_channel TEXT CHECK (otp_channel IN ('sms','voice')),
+    ADD COLUMN IF NOT EXISTS verified_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS priority INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,36 @@
+-- Audit log ------------------------------------------------------------------------
+-- audit_logs is append-only. Each entry records who acted (actor_id, NULL for
+-- anonymous requests such as logins), whose account was affected (user_id) and the
+-- client's IP and user agent. The only change allowed after insert is the ON DELETE
+-- SET NULL of the user foreign keys when an account row is removed.
+ALTER TABLE audit_logs
+    ADD COLUMN IF NOT EXISTS user_agent TEXT;
+
+CREATE INDEX IF NOT EXISTS audit_logs_actor_time_idx
+    ON audit_logs (actor_id, created_at);
+CREATE INDEX IF NOT EXISTS audit_logs_action_time_idx
+    ON audit_logs (action, created_at);
+CREATE INDEX IF NOT EXISTS audit_logs_time_idx
+    ON audit_logs (created_at);
+
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND NEW.id = OLD.id
+        AND NEW.action = OLD.action
+        AND NEW.ip_addr IS NOT DISTINCT FROM OLD.ip_addr
+        AND NEW.user_agent IS NOT DISTINCT FROM OLD.user_agent
+        AND NEW.metadata = OLD.metadata
+        AND NEW.created_at = OLD.created_at
+        AND (NEW.user_id IS NULL OR NEW.user_id = OLD.user_id)
+        AND (NEW.actor_id IS NULL OR NEW.actor_id = OLD.actor_id) THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs;
+CREATE TRIGGER audit_logs_append_only
+    BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
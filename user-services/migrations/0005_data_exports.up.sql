@@ -0,0 +1,35 @@
+-- Data export (takeout) ------------------------------------------------------------
+-- A data_exports row is one self-service export job. Data held by this service is read
+-- when the archive is built; every other service named in data_export_parts is asked
+-- for its share through a user.data_export_requested event and posts it back. The job
+-- is built once every part has arrived or collect_deadline passes, whichever is first.
+-- While building, collect_deadline is the end of the builder's lease; a job still
+-- building after it is picked up again.
+CREATE TABLE IF NOT EXISTS data_exports (
+    id               UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id          UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status           TEXT NOT NULL DEFAULT 'collecting'
+                     CHECK (status IN ('collecting','building','ready','failed','expired')),
+    file_path        TEXT,
+    size_bytes       BIGINT NOT NULL DEFAULT 0,
+    error            TEXT,
+    requested_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    collect_deadline TIMESTAMPTZ NOT NULL,
+    completed_at     TIMESTAMPTZ,
+    expires_at       TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS data_exports_user_time_idx
+    ON data_exports (user_id, requested_at);
+CREATE INDEX IF NOT EXISTS data_exports_pending_idx
+    ON data_exports (collect_deadline) WHERE status IN ('collecting','building');
+CREATE INDEX IF NOT EXISTS data_exports_ready_idx
+    ON data_exports (expires_at) WHERE status = 'ready';
+
+CREATE TABLE IF NOT EXISTS data_export_parts (
+    export_id   UUID NOT NULL REFERENCES data_exports(id) ON DELETE CASCADE,
+    source      TEXT NOT NULL,
+    payload     JSONB,
+    received_at TIMESTAMPTZ,
+    PRIMARY KEY (export_id, source)
+);
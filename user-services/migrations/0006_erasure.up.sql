@@ -0,0 +1,60 @@
+-- Right to erasure -----------------------------------------------------------------
+-- An erasure_requests row schedules the anonymization of an account. While it waits out
+-- the retention window (scheduled_for) the account is soft-deleted and can still be
+-- restored, which cancels the request. Once due, the account's PII is overwritten here
+-- and a user.erasure_requested event asks every other service to scrub or pseudonymize
+-- its copies. Each service acknowledges in erasure_acks; the request is completed when
+-- all have, or incomplete when ack_deadline passes first. user_id deliberately has no
+-- foreign key so the report outlives the account row.
+CREATE TABLE IF NOT EXISTS erasure_requests (
+    id            UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id       UUID NOT NULL,
+    status        TEXT NOT NULL DEFAULT 'scheduled'
+                  CHECK (status IN ('scheduled','cancelled','anonymized','completed','incomplete')),
+    reason        TEXT,
+    requested_by  UUID,
+    requested_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    scheduled_for TIMESTAMPTZ NOT NULL,
+    anonymized_at TIMESTAMPTZ,
+    ack_deadline  TIMESTAMPTZ,
+    completed_at  TIMESTAMPTZ,
+    cancelled_at  TIMESTAMPTZ,
+    local_report  JSONB NOT NULL DEFAULT '{}'::jsonb
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS erasure_requests_open_idx
+    ON erasure_requests (user_id) WHERE status IN ('scheduled','anonymized');
+CREATE INDEX IF NOT EXISTS erasure_requests_due_idx
+    ON erasure_requests (scheduled_for) WHERE status = 'scheduled';
+CREATE INDEX IF NOT EXISTS erasure_requests_status_time_idx
+    ON erasure_requests (status, requested_at);
+
+CREATE TABLE IF NOT EXISTS erasure_acks (
+    request_id       UUID NOT NULL REFERENCES erasure_requests(id) ON DELETE CASCADE,
+    service          TEXT NOT NULL,
+    status           TEXT CHECK (status IN ('completed','failed')),
+    records_affected BIGINT NOT NULL DEFAULT 0,
+    detail           TEXT,
+    acknowledged_at  TIMESTAMPTZ,
+    PRIMARY KEY (request_id, service)
+);
+
+-- Erasure redacts the client IP and user agent of the user's audit entries. The entries
+-- stay, keyed by the now pseudonymous user ID, so besides the ON DELETE SET NULL of the
+-- user foreign keys, setting those two columns to NULL is the only change allowed.
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND NEW.id = OLD.id
+        AND NEW.action = OLD.action
+        AND (NEW.ip_addr IS NULL OR NEW.ip_addr = OLD.ip_addr)
+        AND (NEW.user_agent IS NULL OR NEW.user_agent = OLD.user_agent)
+        AND NEW.metadata = OLD.metadata
+        AND NEW.created_at = OLD.created_at
+        AND (NEW.user_id IS NULL OR NEW.user_id = OLD.user_id)
+        AND (NEW.actor_id IS NULL OR NEW.actor_id = OLD.actor_id) THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
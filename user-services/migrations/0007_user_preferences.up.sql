@@ -0,0 +1,22 @@
+-- User preferences -------------------------------------------------------------------
+-- Notification channels, UI theme and learning goals. Locale and time zone stay on
+-- user_profiles and are edited through the same preferences endpoint. A row is only
+-- written on the first change; until then the column defaults below apply. version
+-- increases with every change and is sent with the user.preferences_updated event so
+-- consumers can drop out-of-order updates. reminder_time is "HH:MM" in the user's time
+-- zone, empty when reminders are off; reminder_days is a comma-separated list of
+-- mon..sun.
+CREATE TABLE IF NOT EXISTS user_preferences (
+    user_id             UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    theme               TEXT NOT NULL DEFAULT 'system' CHECK (theme IN ('system','light','dark')),
+    notify_email        BOOLEAN NOT NULL DEFAULT TRUE,
+    notify_push         BOOLEAN NOT NULL DEFAULT TRUE,
+    notify_sms          BOOLEAN NOT NULL DEFAULT FALSE,
+    notify_in_app       BOOLEAN NOT NULL DEFAULT TRUE,
+    daily_goal_minutes  INTEGER NOT NULL DEFAULT 15 CHECK (daily_goal_minutes BETWEEN 0 AND 1440),
+    weekly_goal_lessons INTEGER NOT NULL DEFAULT 3 CHECK (weekly_goal_lessons BETWEEN 0 AND 100),
+    reminder_time       TEXT NOT NULL DEFAULT '',
+    reminder_days       TEXT NOT NULL DEFAULT 'mon,tue,wed,thu,fri,sat,sun',
+    version             BIGINT NOT NULL DEFAULT 0,
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,43 @@
+-- Avatars --------------------------------------------------------------------------
+-- Avatars are uploaded straight to the object store with a presigned URL. Each upload
+-- is tracked in avatar_uploads until the client reports it done; the image is then
+-- checked, scaled to a square JPEG and stored under its own key, whose public URL
+-- becomes the profile's avatar_url. avatar_key is empty when avatar_url was set by hand
+-- or there is no avatar. A pending upload can be completed until expires_at; the
+-- presigned URL itself is valid for half of that window.
+ALTER TABLE user_profiles ADD COLUMN IF NOT EXISTS avatar_key TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS avatar_uploads (
+    id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id      UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    object_key   TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size_bytes   BIGINT NOT NULL,
+    status       TEXT NOT NULL DEFAULT 'pending'
+                 CHECK (status IN ('pending','completed','failed','expired')),
+    error        TEXT,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at   TIMESTAMPTZ NOT NULL,
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS avatar_uploads_user_idx
+    ON avatar_uploads (user_id, created_at);
+CREATE INDEX IF NOT EXISTS avatar_uploads_pending_idx
+    ON avatar_uploads (expires_at) WHERE status = 'pending';
+
+-- Objects waiting to be removed from the store: replaced and removed avatars, uploads
+-- that were processed, rejected or abandoned, and the avatars of erased accounts. Keys
+-- are queued in the same transaction that stops referencing them and deleted by the
+-- avatar cleanup worker, which retries failures after not_before.
+CREATE TABLE IF NOT EXISTS avatar_deletions (
+    object_key TEXT PRIMARY KEY,
+    reason     TEXT NOT NULL,
+    attempts   INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    not_before TIMESTAMPTZ NOT NULL DEFAULT now(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS avatar_deletions_due_idx
+    ON avatar_deletions (not_before);
@@ -0,0 +1,18 @@
+-- Admin user search ----------------------------------------------------------------
+-- The admin user listing filters on role, verification, MFA, lock state and created /
+-- last login ranges, sorts by any of created_at, last_login_at, email, role and status,
+-- and pages with a keyset cursor on (sort column, id). Each sort column is indexed
+-- together with id so a page is read straight off the index in either direction;
+-- last_login_at puts users who never logged in first ascending and last descending.
+-- Email search is a substring match, served by a trigram index.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS users_created_at_id_idx ON users (created_at, id);
+CREATE INDEX IF NOT EXISTS users_last_login_at_id_idx ON users (last_login_at NULLS FIRST, id);
+CREATE INDEX IF NOT EXISTS users_email_id_idx ON users (email, id);
+CREATE INDEX IF NOT EXISTS users_role_id_idx ON users (role, id);
+CREATE INDEX IF NOT EXISTS users_status_id_idx ON users (status, id);
+CREATE INDEX IF NOT EXISTS users_email_trgm_idx ON users USING gin (email gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS mfa_methods_verified_user_idx
+    ON mfa_methods (user_id) WHERE verified_at IS NOT NULL;
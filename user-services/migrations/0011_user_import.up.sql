@@ -0,0 +1,25 @@
+-- Bulk user import -----------------------------------------------------------------
+-- Admins provision a B2B customer's accounts in bulk. Imported accounts are 'invited':
+-- they have no password and cannot sign in until the user follows the invitation link,
+-- which sets a password, verifies the email and activates the account. The link is a
+-- password_resets token with a longer expiry. Accounts can be assigned to an
+-- organization, whose name is unique ignoring case.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_status_check;
+ALTER TABLE users
+    ADD CONSTRAINT users_status_check CHECK (status IN ('active','invited','locked','disabled','deleted'));
+
+CREATE TABLE IF NOT EXISTS organizations (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name       TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS organizations_name_idx ON organizations (lower(name));
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS organization_id UUID REFERENCES organizations(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS users_organization_idx
+    ON users (organization_id) WHERE organization_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS users_email_lower_idx ON users (lower(email));
@@ -0,0 +1,27 @@
+-- Organization membership -------------------------------------------------------------
+-- Classroom and enterprise customers manage their own accounts. A member is an owner,
+-- who manages the organization and every member, a manager, who manages learners, or a
+-- learner. Only learners take up a seat: an organization with a seat_limit accepts no
+-- more learners once that many are members, and no limit means unlimited seats.
+-- users.organization_id stays the account's primary organization, whose membership is
+-- carried in access tokens. Accounts already assigned to one become its learners.
+ALTER TABLE organizations
+    ADD COLUMN IF NOT EXISTS seat_limit INTEGER CHECK (seat_limit >= 0);
+
+CREATE TABLE IF NOT EXISTS organization_members (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id         UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role            TEXT NOT NULL CHECK (role IN ('owner','manager','learner')),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS organization_members_user_idx ON organization_members (user_id);
+CREATE INDEX IF NOT EXISTS organization_members_role_idx ON organization_members (organization_id, role);
+
+INSERT INTO organization_members (organization_id, user_id, role)
+SELECT organization_id, id, 'learner'
+FROM users
+WHERE organization_id IS NOT NULL
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,33 @@
+-- Invitations ---------------------------------------------------------------------------
+-- Admins, and the owners and managers of an organization, invite people who have no
+-- account yet. Accepting the invitation registers the account with the role, and the
+-- organization and membership role, chosen by the inviter. The emailed token is signed
+-- and carries the invitation and its expiry; nonce_hash holds the hash of its random
+-- part, which every resend replaces, so only the latest link works. Only one invitation
+-- per email and organization can be pending at a time.
+CREATE TABLE IF NOT EXISTS invitations (
+    id               UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    email            TEXT NOT NULL,
+    role             TEXT NOT NULL CHECK (role IN ('student','teacher')),
+    organization_id  UUID REFERENCES organizations(id) ON DELETE CASCADE,
+    org_role         TEXT CHECK (org_role IN ('owner','manager','learner')),
+    status           TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending','accepted','revoked')),
+    nonce_hash       TEXT NOT NULL,
+    expires_at       TIMESTAMPTZ NOT NULL,
+    invited_by       UUID REFERENCES users(id) ON DELETE SET NULL,
+    sent_count       INT NOT NULL DEFAULT 1,
+    last_sent_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    accepted_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    accepted_at      TIMESTAMPTZ,
+    revoked_at       TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK ((organization_id IS NULL) = (org_role IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS invitations_pending_idx
+    ON invitations (lower(email), COALESCE(organization_id, '00000000-0000-0000-0000-000000000000'))
+    WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS invitations_organization_idx
+    ON invitations (organization_id, created_at DESC) WHERE organization_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS invitations_created_idx ON invitations (created_at DESC);
@@ -0,0 +1,21 @@
+-- Personal access tokens ----------------------------------------------------------------
+-- Long-lived API tokens users create for scripts and integrations. Only the SHA-256 hash
+-- of a token is stored; prefix keeps its first characters so users can tell tokens
+-- apart. scopes is a space-separated list of 'read' and 'write'. Revoked tokens are kept
+-- for the audit trail.
+CREATE TABLE IF NOT EXISTS personal_access_tokens (
+    id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id      UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name         TEXT NOT NULL,
+    prefix       TEXT NOT NULL,
+    token_hash   TEXT NOT NULL UNIQUE,
+    scopes       TEXT NOT NULL,
+    expires_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    last_used_ip INET,
+    revoked_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS personal_access_tokens_user_idx
+    ON personal_access_tokens (user_id, created_at DESC);
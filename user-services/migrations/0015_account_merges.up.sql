@@ -0,0 +1,32 @@
+-- Account merges ------------------------------------------------------------------------
+-- A user who ended up with two accounts merges the other one (source) into the one they
+-- are signed in to (target). Both email addresses receive a code, and the merge only
+-- runs once both are entered, proving the user owns both accounts. Only the HMACs of the
+-- codes are stored. Completing a merge moves the source's sessions, MFA methods,
+-- preferences and organization memberships to the target and tombstones the source:
+-- users.merged_into_id points at the target, which lets anything still holding the old
+-- ID find the surviving account. report counts the rows moved per table.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS merged_into_id UUID REFERENCES users(id);
+
+CREATE TABLE IF NOT EXISTS account_merges (
+    id                UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    target_user_id    UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    source_user_id    UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    source_email      TEXT NOT NULL,
+    status            TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending','completed','cancelled')),
+    target_code_hash  TEXT NOT NULL,
+    source_code_hash  TEXT NOT NULL,
+    attempts          INT NOT NULL DEFAULT 0,
+    expires_at        TIMESTAMPTZ NOT NULL,
+    report            JSONB NOT NULL DEFAULT '{}',
+    completed_at      TIMESTAMPTZ,
+    cancelled_at      TIMESTAMPTZ,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (target_user_id <> source_user_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS account_merges_pending_idx
+    ON account_merges (target_user_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS account_merges_target_idx ON account_merges (target_user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS account_merges_source_idx ON account_merges (source_user_id);
@@ -0,0 +1,10 @@
+-- Impersonation --------------------------------------------------------------------------
+-- Support staff reproduce a user's problem by opening a session as that user. Such a
+-- session records the administrator who opened it and why, is marked in its access
+-- tokens, and ends at a fixed time no matter how active it is. Every request made
+-- through it is recorded in the audit log with the administrator as the actor.
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS impersonator_id UUID REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS impersonation_reason TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS sessions_impersonator_idx ON sessions (impersonator_id, created_at DESC)
+    WHERE impersonator_id IS NOT NULL;
@@ -0,0 +1,15 @@
+-- Onboarding -----------------------------------------------------------------------------
+-- The first-run steps a new user goes through: verifying their email, completing their
+-- profile, taking the level test and starting their first lesson. A row records a step
+-- that moved out of pending, to completed or, for the level test only, skipped; a skipped
+-- step can still be completed later. source is "user" when the app reported the step,
+-- "system" when it was derived from the account, or the name of the service that
+-- reported it. Each transition is published as a user.onboarding_step_advanced event.
+CREATE TABLE IF NOT EXISTS onboarding_steps (
+    user_id      UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    step         TEXT NOT NULL CHECK (step IN ('email_verified','profile_completed','level_test_taken','first_lesson_started')),
+    status       TEXT NOT NULL CHECK (status IN ('completed','skipped')),
+    source       TEXT NOT NULL,
+    completed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, step)
+);
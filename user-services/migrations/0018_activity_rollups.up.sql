@@ -0,0 +1,21 @@
+-- Activity rollups ---------------------------------------------------------------------
+-- Per-user study time aggregated from user_activity_sessions by UTC day and by ISO week
+-- (starting Monday), so other services can read totals without scanning sessions. A
+-- session counts towards the day it started on once it has ended. Day rows have
+-- active_days 1; week rows count the days of the week with any study time. Rows are
+-- recomputed by the rollup worker for the days whose sessions changed.
+CREATE TABLE IF NOT EXISTS activity_rollups (
+    user_id           UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    period            TEXT NOT NULL CHECK (period IN ('day','week')),
+    period_start      DATE NOT NULL,
+    session_count     INTEGER NOT NULL DEFAULT 0,
+    total_duration_ms BIGINT NOT NULL DEFAULT 0,
+    active_days       INTEGER NOT NULL DEFAULT 0,
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, period, period_start)
+);
+
+CREATE INDEX IF NOT EXISTS activity_rollups_updated_idx
+    ON activity_rollups (updated_at);
+CREATE INDEX IF NOT EXISTS activity_sessions_updated_idx
+    ON user_activity_sessions (updated_at);
@@ -0,0 +1,13 @@
+-- Session device and location ---------------------------------------------------------
+-- Sessions keep the browser, operating system and device type parsed from their user
+-- agent, and the approximate location of the IP address they were opened from, so the
+-- sessions list reads "Chrome on Windows, Hanoi". Both are resolved once, when the
+-- session is created. Sessions created before this migration are described from their
+-- user agent when listed and have no location.
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS device_type TEXT NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS browser TEXT NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS os TEXT NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS geo_city TEXT NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS geo_region TEXT NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS geo_country TEXT NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS geo_country_code TEXT NOT NULL DEFAULT '';
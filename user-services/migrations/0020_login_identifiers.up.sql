@@ -0,0 +1,13 @@
+-- Login identifiers ----------------------------------------------------------------------
+-- Besides their email, users can sign in with a username they pick or with a phone
+-- number they verified by SMS or voice code. Usernames are stored lowercase and phone
+-- numbers in E.164, so the unique indexes compare them as users type them. A phone
+-- number is only stored once verified; phone_verified_at records when.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS username TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_number TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_verified_at TIMESTAMPTZ;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_username_key ON users (username)
+    WHERE username IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS users_phone_number_key ON users (phone_number)
+    WHERE phone_number IS NOT NULL;
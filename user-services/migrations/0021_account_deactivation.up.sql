@@ -0,0 +1,7 @@
+-- Account deactivation ------------------------------------------------------------------
+-- A user who deactivates their account is signed out and cannot sign in as before, and
+-- the account is erased once the cooling-off period ends. It is an erasure request of
+-- kind 'deactivation': unlike a requested erasure, signing in with the password or a
+-- passkey before scheduled_for cancels it and reactivates the account.
+ALTER TABLE erasure_requests ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'erasure'
+    CHECK (kind IN ('erasure','deactivation'));
@@ -0,0 +1,24 @@
+-- Push notification tokens --------------------------------------------------------------
+-- Device tokens the mobile and web apps register so the notification service can push
+-- to a user's devices through FCM or APNs. A token identifies one app install, so it
+-- belongs to one account at a time: registering it again moves it to the account that
+-- signed in and refreshes its metadata. app_id is the FCM app or APNs bundle ID. Tokens
+-- the push provider reports as invalid are deleted, as are the least recently registered
+-- ones of a user holding more than PUSH_TOKEN_MAX_PER_USER.
+CREATE TABLE IF NOT EXISTS push_tokens (
+    id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id      UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider     TEXT NOT NULL CHECK (provider IN ('fcm','apns')),
+    token        TEXT NOT NULL,
+    platform     TEXT NOT NULL CHECK (platform IN ('ios','android','web')),
+    app_id       TEXT NOT NULL DEFAULT '',
+    app_version  TEXT NOT NULL DEFAULT '',
+    os_version   TEXT NOT NULL DEFAULT '',
+    device_model TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (provider, token)
+);
+
+CREATE INDEX IF NOT EXISTS push_tokens_user_idx
+    ON push_tokens (user_id, updated_at DESC);
@@ -0,0 +1,35 @@
+-- Terms of service and privacy policy versions -------------------------------------------
+-- Every published version of a legal document users must accept. The current version of
+-- a document is the most recently published one. Publishing with requires_consent set
+-- asks every user to accept it before they can keep using the app; editorial fixes are
+-- published without it and the users' earlier acceptance still counts.
+CREATE TABLE IF NOT EXISTS policy_versions (
+    id               UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    document         TEXT NOT NULL CHECK (document IN ('terms','privacy')),
+    version          TEXT NOT NULL,
+    url              TEXT NOT NULL,
+    summary          TEXT NOT NULL DEFAULT '',
+    requires_consent BOOLEAN NOT NULL DEFAULT TRUE,
+    published_by     UUID REFERENCES users(id) ON DELETE SET NULL,
+    published_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (document, version)
+);
+
+CREATE INDEX IF NOT EXISTS policy_versions_document_idx
+    ON policy_versions (document, published_at DESC);
+
+-- User consents ---------------------------------------------------------------------------
+-- Append-only history of the document versions each user accepted, with where they
+-- accepted from. A user's latest row per document is the version they agreed to.
+CREATE TABLE IF NOT EXISTS user_consents (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    document    TEXT NOT NULL CHECK (document IN ('terms','privacy')),
+    version     TEXT NOT NULL,
+    accepted_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ip_addr     INET,
+    user_agent  TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS user_consents_user_idx
+    ON user_consents (user_id, document, accepted_at DESC);
@@ -0,0 +1,11 @@
+-- Password reset binding -----------------------------------------------------------------
+-- A reset link a user requests only works from the browser and network it was requested
+-- from: fingerprint_hash is the hash of both, NULL for invitation links, which are
+-- unbound. failed_attempts counts confirmations from elsewhere; the link is used up once
+-- they reach PASSWORD_RESET_MAX_ATTEMPTS.
+ALTER TABLE password_resets
+    ADD COLUMN IF NOT EXISTS fingerprint_hash TEXT,
+    ADD COLUMN IF NOT EXISTS failed_attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS password_resets_user_idx ON password_resets (user_id);
@@ -0,0 +1,5 @@
+-- User metrics ---------------------------------------------------------------------------
+-- The admin dashboard counts the users active on each day of a range from the activity
+-- sessions started in it.
+CREATE INDEX IF NOT EXISTS activity_sessions_started_idx
+    ON user_activity_sessions (started_at, user_id);
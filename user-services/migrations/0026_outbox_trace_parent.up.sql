@@ -0,0 +1,5 @@
+-- Outbox trace context --------------------------------------------------------------
+-- Each event keeps the traceparent of the request that wrote it, so the relay publishes
+-- it as part of the same trace.
+ALTER TABLE outbox
+    ADD COLUMN IF NOT EXISTS trace_parent TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,32 @@
+-- UUIDv7 primary keys -----------------------------------------------------------------
+-- New records get UUIDv7 IDs, which start with their creation time in milliseconds, so
+-- inserts append to the primary key indexes and IDs sort by creation. The service sets
+-- them with shared/ids; uuid_generate_v7() gives rows inserted by SQL the same kind of
+-- ID. Existing version 4 IDs are kept: both are plain UUIDs in the same columns.
+CREATE OR REPLACE FUNCTION uuid_generate_v7() RETURNS uuid AS $$
+DECLARE
+    id bytea := uuid_send(gen_random_uuid());
+BEGIN
+    -- 48-bit Unix time in milliseconds, then the version nibble; the variant bits of the
+    -- random version 4 UUID are already those of version 7
+    id := overlay(id PLACING substring(int8send((extract(epoch FROM clock_timestamp()) * 1000)::bigint) FROM 3) FROM 1 FOR 6);
+    id := set_byte(id, 6, (get_byte(id, 6) & 15) | 112);
+    RETURN encode(id, 'hex')::uuid;
+END
+$$ LANGUAGE plpgsql VOLATILE;
+
+DO $$
+DECLARE
+    col record;
+BEGIN
+    FOR col IN
+        SELECT table_schema, table_name, column_name
+        FROM information_schema.columns
+        WHERE table_schema = current_schema()
+          AND column_default = 'uuid_generate_v4()'
+    LOOP
+        EXECUTE format('ALTER TABLE %I.%I ALTER COLUMN %I SET DEFAULT uuid_generate_v7()',
+            col.table_schema, col.table_name, col.column_name);
+    END LOOP;
+END
+$$;
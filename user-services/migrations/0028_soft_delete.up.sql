@@ -0,0 +1,6 @@
+-- Soft deletes -----------------------------------------------------------------------
+-- A deleted account already keeps its row with deleted_at; deleted_by records who
+-- deleted it, following shared/softdelete: the admin, the user erasing their own account,
+-- or "system" for merged accounts. Accounts deleted before have no deleter.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS deleted_by TEXT;
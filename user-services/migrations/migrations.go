@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL migrations of user-services, applied with shared/migrate
+// by the server at startup and by cmd/migrate.
+package migrations
+
+import "embed"
+
+// FS holds the migration files.
+//
+//go:embed *.sql
+var FS embed.FS